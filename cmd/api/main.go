@@ -2,6 +2,7 @@ package main
 
 import (
 	"bookstore-backend/pkg/container"
+	"bookstore-backend/pkg/metrics"
 	"context"
 	"fmt"
 	"log"
@@ -15,6 +16,16 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// OpenAPI spec (make openapi → api/openapi), nguồn để sinh client SDK (make sdk)
+//
+// @title                       Bookstore API
+// @version                     1.0
+// @description                 Storefront + admin API của bookstore-backend
+// @BasePath                    /api/v1
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 "Bearer <access_token>" từ POST /auth/login
 func main() {
 	// ========================================
 	// LOAD ENVIRONMENT VARIABLES
@@ -99,6 +110,9 @@ func Serve() {
 		}
 	}()
 
+	// Prometheus scrape (HTTP, pgxpool, asynq queue, checkout...) trên listener nội bộ, không qua router public
+	metricsSrv := metrics.Serve(appContainer.Config.App.MetricsAddr)
+
 	// ========================================
 	// 5. GRACEFUL SHUTDOWN
 	// ========================================
@@ -114,6 +128,7 @@ func Serve() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Printf("⚠️  Server forced to shutdown: %v", err)
 	}
+	metrics.Shutdown(metricsSrv)
 
 	log.Println("✅ Server exited gracefully")
 }
@@ -1,27 +1,50 @@
 package main
 
 import (
+	fraudModel "bookstore-backend/internal/domains/fraud/model"
 	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/rbac"
 	"bookstore-backend/pkg/container"
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"time"
 
+	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func SetupRouter(c *container.Container) *gin.Engine {
 	router := gin.New()
+	// Chỉ tin X-Forwarded-For / X-Real-IP từ load balancer đã khai báo → c.ClientIP() không bị client giả mạo
+	// (mặc định gin tin mọi proxy). CIDR đã được kiểm tra lúc load config.
+	if err := router.SetTrustedProxies(c.Config.App.TrustedProxies); err != nil {
+		log.Fatalf("❌ Invalid trusted proxies: %v", err)
+	}
 
 	// Global middlewares
 	router.Use(
+		middleware.Metrics(), // ngoài Recovery: request panic vẫn được đếm với status 500
 		middleware.Recovery(),
 		middleware.RequestID(),
+		// Trong Recovery: sentrygin gửi panic rồi panic lại cho Recovery trả 500 (chưa cấu hình SENTRY_DSN → no-op)
+		sentrygin.New(sentrygin.Options{Repanic: true}),
+		middleware.SentryScope(),
+		// Span server cho mỗi request (tiếp tục traceparent của client), sau RequestID để gắn request_id
+		otelgin.Middleware(c.Config.Tracing.ServiceName),
+		middleware.Tracing(),
+		middleware.Chaos(), // no-op khi CHAOS_ENABLED=false
+		// SSE + export/import file lớn chạy lâu hơn deadline chung
+		middleware.Timeout(time.Duration(c.Config.App.RequestTimeoutSeconds)*time.Second,
+			"/stream", "/export", "/bulk-import", "/usage-imports"),
 		middleware.Logger(),
 		middleware.CORS(),
 		middleware.ClientIPMiddleware(),
+		middleware.I18n(c.UserService),
+		middleware.AffiliateAttribution(os.Getenv("ENV") != "development"),
 	)
 
 	// Cart middleware configuration
@@ -31,6 +54,12 @@ func SetupRouter(c *container.Container) *gin.Engine {
 	}
 
 	v1 := router.Group("/api/v1")
+	// Token bucket theo IP / user cho mọi API (health check + webhook đối tác không tính)
+	v1.Use(rateLimit(c, "global",
+		middleware.RateLimitPolicy{PerMinute: c.Config.RateLimit.PublicPerMinute, Burst: c.Config.RateLimit.PublicBurst},
+		middleware.RateLimitPolicy{PerMinute: c.Config.RateLimit.UserPerMinute, Burst: c.Config.RateLimit.UserBurst},
+		"/api/v1/health", "/api/v1/webhooks/",
+	))
 	{
 		// Health check
 		v1.GET("/health", healthCheckHandler(c))
@@ -47,14 +76,26 @@ func SetupRouter(c *container.Container) *gin.Engine {
 		setupWarehouseRoutes(v1, c)
 		setupInventoryRoutes(v1, c)
 		setupCartRoutes(v1, c, &cartMiddlewareConfig)
+		setupWishlistRoutes(v1, c, &cartMiddlewareConfig)
 		setupPromotionRoutes(v1, c)
 		setupOrderRoutes(v1, c)
 		setupPaymentRoutes(v1, c)
 		setupWebhookRoutes(v1, c)
+		setupSandboxRoutes(v1, c)
 		setupAdminOrderRoutes(v1, c)
 		setupAdminPaymentRoutes(v1, c)
+		setupShippingRoutes(v1, c)
+		setupEInvoiceRoutes(v1, c)
+		setupTaxRoutes(v1, c)
 		setupReviewRoutes(v1, c)
+		setupQuestionRoutes(v1, c)
+		setupQuoteRoutes(v1, c)
+		setupTicketRoutes(v1, c)
+		setupAffiliateRoutes(v1, c)
+		setupFlashSaleRoutes(v1, c)
 		setupNotificationRoutes(v1, c)
+		setupAnalyticsRoutes(v1, c)
+		setupFraudRoutes(v1, c)
 	}
 
 	return router
@@ -87,6 +128,21 @@ func setupUserRoutes(v1 *gin.RouterGroup, c *container.Container) {
 		users.GET("/me", c.UserHandler.GetProfile)
 		users.PUT("/me", c.UserHandler.UpdateProfile)
 		users.PUT("/change-password", c.UserHandler.ChangePassword)
+		users.GET("/me/preferences", c.UserHandler.GetPreferences)
+		users.PUT("/me/preferences", c.UserHandler.UpdatePreferences)
+
+		// Personalization
+		users.GET("/me/feed", c.RecommendHandler.GetFeed)
+		users.GET("/me/recently-viewed", c.RecommendHandler.ListRecentlyViewed)
+		users.GET("/me/recommendations", c.RecommendHandler.GetRecommendations)
+
+		// Lịch sử giỏ hàng / đơn hàng (?domain=cart|order)
+		users.GET("/me/activity", c.AnalyticsHandler.GetMyActivity)
+
+		// Wishlist (route cũ, giữ cho client hiện tại; route mới ở /wishlist hỗ trợ cả guest)
+		users.GET("/me/wishlist", c.WishlistHandler.ListWishlist)
+		users.POST("/me/wishlist", c.WishlistHandler.AddItem)
+		users.DELETE("/me/wishlist/:book_id", c.WishlistHandler.RemoveItem)
 	}
 }
 
@@ -94,12 +150,12 @@ func setupUserRoutes(v1 *gin.RouterGroup, c *container.Container) {
 // ADMIN ROUTES
 // ========================================
 func setupAdminRoutes(v1 *gin.RouterGroup, c *container.Container) {
-	// TODO: Add Auth + Role middleware
 	admin := v1.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
 	{
-		admin.GET("/users", c.UserHandler.ListUsers)
-		admin.PUT("/users/:id/role", c.UserHandler.UpdateUserRole)
-		admin.PUT("/users/:id/status", c.UserHandler.UpdateUserStatus)
+		admin.GET("/users", middleware.RequirePermission(rbac.PermCustomerRead), c.UserHandler.ListUsers)
+		admin.PUT("/users/:id/role", middleware.RequirePermission(rbac.PermCustomerManage), c.UserHandler.UpdateUserRole)
+		admin.PUT("/users/:id/status", middleware.RequirePermission(rbac.PermCustomerManage), c.UserHandler.UpdateUserStatus)
 	}
 }
 
@@ -109,20 +165,20 @@ func setupAdminRoutes(v1 *gin.RouterGroup, c *container.Container) {
 func setupCategoryRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	category := v1.Group("/categories")
 	{
-		category.POST("", c.CategoryHandler.Create)
+		category.POST("", withPermission(c, c.CategoryHandler.Create, rbac.PermCatalogWrite)...)
 		category.GET("", c.CategoryHandler.GetAll)
 		category.GET("/tree", c.CategoryHandler.GetTree)
 		category.GET("/:id", c.CategoryHandler.GetByID)
 		category.GET("/:id/breadcrumb", c.CategoryHandler.GetBreadcrumb)
 		category.GET("/by-slug/:slug", c.CategoryHandler.GetBySlug)
-		category.PUT("/:id", c.CategoryHandler.Update)
-		category.PATCH("/:id/parent", c.CategoryHandler.MoveToParent)
-		category.POST("/:id/activate", c.CategoryHandler.Activate)
-		category.POST("/:id/deactivate", c.CategoryHandler.Deactivate)
-		category.DELETE("/:id", c.CategoryHandler.Delete)
-		category.DELETE("/bulk", c.CategoryHandler.BulkDelete)
-		category.POST("/bulk/activate", c.CategoryHandler.BulkActivate)
-		category.POST("/bulk/deactivate", c.CategoryHandler.BulkDeactivate)
+		category.PUT("/:id", withPermission(c, c.CategoryHandler.Update, rbac.PermCatalogWrite)...)
+		category.PATCH("/:id/parent", withPermission(c, c.CategoryHandler.MoveToParent, rbac.PermCatalogWrite)...)
+		category.POST("/:id/activate", withPermission(c, c.CategoryHandler.Activate, rbac.PermCatalogWrite)...)
+		category.POST("/:id/deactivate", withPermission(c, c.CategoryHandler.Deactivate, rbac.PermCatalogWrite)...)
+		category.DELETE("/:id", withPermission(c, c.CategoryHandler.Delete, rbac.PermCatalogWrite)...)
+		category.DELETE("/bulk", withPermission(c, c.CategoryHandler.BulkDelete, rbac.PermCatalogWrite)...)
+		category.POST("/bulk/activate", withPermission(c, c.CategoryHandler.BulkActivate, rbac.PermCatalogWrite)...)
+		category.POST("/bulk/deactivate", withPermission(c, c.CategoryHandler.BulkDeactivate, rbac.PermCatalogWrite)...)
 		category.GET("/:id/books", c.CategoryHandler.GetBooksInCategory)
 		category.GET("/:id/book-count", c.CategoryHandler.GetCategoryBookCount)
 	}
@@ -134,15 +190,20 @@ func setupCategoryRoutes(v1 *gin.RouterGroup, c *container.Container) {
 func setupAuthorRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	author := v1.Group("/authors")
 	{
-		author.POST("", c.AuthorHandler.Create)
+		author.POST("", withPermission(c, c.AuthorHandler.Create, rbac.PermCatalogWrite)...)
 		author.GET("/:id", c.AuthorHandler.GetByID)
 		author.GET("/slug/:slug", c.AuthorHandler.GetBySlug)
 		author.GET("", c.AuthorHandler.GetAll)
 		author.GET("/search", c.AuthorHandler.Search)
-		author.PUT("/:id", c.AuthorHandler.Update)
-		author.DELETE("/:id", c.AuthorHandler.Delete)
-		author.DELETE("/bulk", c.AuthorHandler.BulkDelete)
+		author.PUT("/:id", withPermission(c, c.AuthorHandler.Update, rbac.PermCatalogWrite)...)
+		author.DELETE("/:id", withPermission(c, c.AuthorHandler.Delete, rbac.PermCatalogWrite)...)
+		author.DELETE("/bulk", withPermission(c, c.AuthorHandler.BulkDelete, rbac.PermCatalogWrite)...)
 		author.GET("/:id/books", c.AuthorHandler.GetWithBookCount)
+		// Bút danh / tên phiên âm → tác giả chuẩn; gộp tác giả trùng (tên + slug cũ thành alias)
+		author.GET("/:id/aliases", c.AuthorHandler.ListAliases)
+		author.POST("/:id/aliases", withPermission(c, c.AuthorHandler.CreateAlias, rbac.PermCatalogWrite)...)
+		author.DELETE("/:id/aliases/:alias_id", withPermission(c, c.AuthorHandler.DeleteAlias, rbac.PermCatalogWrite)...)
+		author.POST("/:id/merge", withPermission(c, c.AuthorHandler.Merge, rbac.PermCatalogWrite)...)
 	}
 }
 
@@ -152,14 +213,14 @@ func setupAuthorRoutes(v1 *gin.RouterGroup, c *container.Container) {
 func setupPublisherRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	publisher := v1.Group("/publishers")
 	{
-		publisher.POST("", c.PublisherHandler.CreatePublisher)
+		publisher.POST("", withPermission(c, c.PublisherHandler.CreatePublisher, rbac.PermCatalogWrite)...)
 		publisher.GET("", c.PublisherHandler.ListPublishers)
 		publisher.GET("/books", c.PublisherHandler.ListPublishersWithBooks)
 		publisher.GET("/slug/:slug", c.PublisherHandler.GetPublisherBySlug)
 		publisher.GET("/:id", c.PublisherHandler.GetPublisher)
 		publisher.GET("/:id/books", c.PublisherHandler.GetPublisherWithBooks)
-		publisher.PUT("/:id", c.PublisherHandler.UpdatePublisher)
-		publisher.DELETE("/:id", c.PublisherHandler.DeletePublisher)
+		publisher.PUT("/:id", withPermission(c, c.PublisherHandler.UpdatePublisher, rbac.PermCatalogWrite)...)
+		publisher.DELETE("/:id", withPermission(c, c.PublisherHandler.DeletePublisher, rbac.PermCatalogWrite)...)
 	}
 }
 
@@ -167,12 +228,16 @@ func setupPublisherRoutes(v1 *gin.RouterGroup, c *container.Container) {
 // ADDRESS ROUTES
 // ========================================
 func setupAddressRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Public: frontend kiểm tra khu vực giao hàng trong lúc khách nhập địa chỉ
+	v1.GET("/addresses/serviceability", c.ServiceabilityHandler.Check)
+
 	addresses := v1.Group("/addresses")
 	addresses.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
 	{
 		addresses.POST("", c.AddressHandler.CreateAddress)
 		addresses.GET("", c.AddressHandler.ListUserAddresses)
 		addresses.GET("/default", c.AddressHandler.GetDefaultAddress)
+		addresses.GET("/suggested", c.AddressHandler.GetSuggestedAddress)
 		addresses.GET("/:id", c.AddressHandler.GetAddressById)
 		addresses.PUT("/:id", c.AddressHandler.UpdateAddress)
 		addresses.PUT("/:id/set-default", c.AddressHandler.SetDefaultAddress)
@@ -181,12 +246,28 @@ func setupAddressRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	}
 
 	// Admin address routes
-	// TODO: Add admin middleware
 	adminAddresses := v1.Group("/admin/addresses")
+	adminAddresses.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.RequirePermission(rbac.PermCustomerRead),
+	)
 	{
 		adminAddresses.GET("", c.AddressHandler.ListAllAddresses)
 		adminAddresses.GET("/:id", c.AddressHandler.GetAddressWithUser)
 	}
+
+	// Khu vực giao hàng (tỉnh / quận, có thể theo kho / hãng vận chuyển)
+	adminAreas := v1.Group("/admin/serviceable-areas")
+	adminAreas.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.AdminMiddleware(),
+	)
+	{
+		adminAreas.GET("", c.ServiceabilityHandler.ListAreas)
+		adminAreas.POST("", c.ServiceabilityHandler.CreateArea)
+		adminAreas.PATCH("/:id", c.ServiceabilityHandler.UpdateArea)
+		adminAreas.DELETE("/:id", c.ServiceabilityHandler.DeleteArea)
+	}
 }
 
 // ========================================
@@ -197,12 +278,52 @@ func setupBookRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	{
 		books.GET("", c.BookHandler.ListBooks)
 		books.GET("/search", c.BookHandler.SearchBooks)
-		books.GET("/:id", c.BookHandler.GetBookDetail)
-		books.POST("", c.BookHandler.CreateBook)
-		books.PUT("/:id", c.BookHandler.UpdateBook)
-		books.DELETE("/:id", c.BookHandler.DeleteBook)
-		books.POST("/bulk-import", c.BulkImportHandler.ImportBooks)
-		books.GET("/export", c.BookHandler.ExportBooks)
+		// Slug cũ của sách đã gộp → sách giữ lại
+		books.GET("/slug/:slug", c.MergeHandler.ResolveSlug)
+		books.GET("/:id",
+			middleware.OptionalAuthMiddleware(c.Config.JWT.Secret),
+			c.RecommendHandler.TrackBookView, // ghi recently viewed cho user đã login
+			c.AnalyticsHandler.TrackBookView, // funnel event (user + session ẩn danh)
+			c.BookHandler.GetBookDetail,
+		)
+		books.POST("", withPermission(c, c.BookHandler.CreateBook, rbac.PermCatalogWrite)...)
+		books.PUT("/:id", withPermission(c, c.BookHandler.UpdateBook, rbac.PermCatalogWrite)...)
+		books.DELETE("/:id", withPermission(c, c.BookHandler.DeleteBook, rbac.PermCatalogWrite)...)
+		books.POST("/bulk-import", withPermission(c, c.BulkImportHandler.ImportBooks, rbac.PermCatalogWrite)...)
+		books.GET("/export", withPermission(c, c.BookHandler.ExportBooks, rbac.PermCatalogWrite)...)
+		// Báo cáo chất lượng dữ liệu (thiếu cover / mô tả / danh mục, không có tồn) kèm link sửa
+		books.GET("/data-quality", withPermission(c, c.DataQualityHandler.GetReport, rbac.PermCatalogWrite)...)
+		books.GET("/:id/price-tiers", c.PriceTierHandler.ListTiers)
+		books.GET("/:id/price-history", c.PricingHandler.GetPriceHistory)
+		books.GET("/:id/related", c.RecommendHandler.GetRelatedBooks)
+	}
+
+	// Admin: enrich metadata từ Google Books/OpenLibrary
+	adminBooks := v1.Group("/admin/books")
+	adminBooks.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.AdminMiddleware(),
+	)
+	{
+		adminBooks.POST("/:id/enrich", c.MetadataHandler.EnrichBook)
+		adminBooks.GET("/:id/metadata-suggestions", c.MetadataHandler.ListSuggestions)
+		adminBooks.POST("/metadata-suggestions/:suggestion_id/accept", c.MetadataHandler.AcceptSuggestion)
+		adminBooks.POST("/metadata-suggestions/:suggestion_id/reject", c.MetadataHandler.RejectSuggestion)
+
+		// Giá theo số lượng (mua 5+ giảm 5%, 10+ giảm 10%)
+		adminBooks.GET("/:id/price-tiers", c.PriceTierHandler.ListTiers)
+		adminBooks.PUT("/:id/price-tiers", c.PriceTierHandler.ReplaceTiers)
+
+		// Hẹn giờ đổi giá (worker áp đúng giờ, lịch sử ghi vào price_history)
+		adminBooks.GET("/:id/price-schedules", c.PricingHandler.ListSchedules)
+		adminBooks.POST("/:id/price-schedules", c.PricingHandler.CreateSchedule)
+		adminBooks.DELETE("/:id/price-schedules/:schedule_id", c.PricingHandler.CancelSchedule)
+
+		// Sách trùng (cùng ISBN / tên + tác giả gần giống) → gộp vào :id, slug cũ redirect
+		adminBooks.GET("/duplicates", c.MergeHandler.ListDuplicates)
+		adminBooks.POST("/duplicates/dismiss", c.MergeHandler.DismissDuplicate)
+		adminBooks.POST("/:id/merge", c.MergeHandler.MergeBooks)
+		adminBooks.POST("/:id/restore", c.BookHandler.RestoreBook)
 	}
 }
 
@@ -212,17 +333,42 @@ func setupBookRoutes(v1 *gin.RouterGroup, c *container.Container) {
 func setupWarehouseRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	warehouses := v1.Group("/warehouses")
 	{
-		warehouses.POST("", c.WarehouseHandler.CreateWarehouse)
+		warehouses.POST("", withPermission(c, c.WarehouseHandler.CreateWarehouse, rbac.PermWarehouseManage)...)
 		warehouses.GET("", c.WarehouseHandler.ListWarehouses)
 		warehouses.GET("/active", c.WarehouseHandler.ListActiveWarehouses)
 		warehouses.GET("/nearest-with-stock", c.WarehouseHandler.FindNearestWarehouseWithStock)
 		warehouses.GET("/validate-stock", c.WarehouseHandler.ValidateWarehouseHasStock)
 		warehouses.GET("/code/:code", c.WarehouseHandler.GetWarehouseByCode)
 		warehouses.GET("/:id", c.WarehouseHandler.GetWarehouseByID)
-		warehouses.GET("/:id/performance", c.InventoryHandler.GetWarehousePerformance)
-		warehouses.PUT("/:id", c.WarehouseHandler.UpdateWarehouse)
-		warehouses.DELETE("/:id", c.WarehouseHandler.SoftDeleteWarehouse)
-		warehouses.DELETE("/deactive", c.InventoryHandler.DeactivateWarehouse)
+		warehouses.GET("/:id/performance", withPermission(c, c.InventoryHandler.GetWarehousePerformance, rbac.PermWarehouseRead)...)
+		warehouses.PUT("/:id", withPermission(c, c.WarehouseHandler.UpdateWarehouse, rbac.PermWarehouseManage)...)
+		warehouses.DELETE("/:id", withPermission(c, c.WarehouseHandler.SoftDeleteWarehouse, rbac.PermWarehouseManage)...)
+		warehouses.DELETE("/deactive", withPermission(c, c.InventoryHandler.DeactivateWarehouse, rbac.PermWarehouseManage)...)
+	}
+
+	// Admin: gán nhân viên kho, lịch ngày nghỉ, SLA xử lý đơn
+	adminWarehouses := v1.Group("/admin/warehouses")
+	adminWarehouses.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.AdminMiddleware(),
+	)
+	{
+		adminWarehouses.GET("/:id/staff", c.WarehouseHandler.ListStaff)
+		adminWarehouses.POST("/:id/staff", c.WarehouseHandler.AssignStaff)
+		adminWarehouses.DELETE("/:id/staff/:user_id", c.WarehouseHandler.UnassignStaff)
+
+		// Safety stock (giữ tồn cho nhận tại cửa hàng) + trọng số ưu tiên khi chọn kho cho đơn online
+		adminWarehouses.PUT("/:id/allocation", c.WarehouseHandler.UpdateAllocationSettings)
+		adminWarehouses.GET("/:id/safety-stock/:book_id", c.WarehouseHandler.GetBookSafetyStock)
+		adminWarehouses.PUT("/:id/safety-stock/:book_id", c.WarehouseHandler.SetBookSafetyStock)
+
+		// Ngày nghỉ toàn quốc / tỉnh / kho (ước tính giao hàng + SLA bỏ qua ngày nghỉ)
+		adminWarehouses.GET("/holidays", c.HolidayHandler.ListHolidays)
+		adminWarehouses.POST("/holidays", c.HolidayHandler.CreateHoliday)
+		adminWarehouses.POST("/holidays/seed", c.HolidayHandler.SeedNationalHolidays)
+		adminWarehouses.PUT("/holidays/:holiday_id", c.HolidayHandler.UpdateHoliday)
+		adminWarehouses.DELETE("/holidays/:holiday_id", c.HolidayHandler.DeleteHoliday)
+		adminWarehouses.GET("/processing-sla/breaches", c.HolidayHandler.GetProcessingSLABreaches)
 	}
 }
 
@@ -231,39 +377,118 @@ func setupWarehouseRoutes(v1 *gin.RouterGroup, c *container.Container) {
 // ========================================
 func setupInventoryRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	inventory := v1.Group("/inventories")
+
+	// Public: khách xác thực bản giới hạn / có chữ ký theo serial
+	v1.GET("/serials/:serial/verify", c.InventoryHandler.VerifySerial)
+
+	// Staff chỉ được thao tác trên kho được gán, admin không bị giới hạn
+	warehouseScope := []gin.HandlerFunc{
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.WarehouseScopeMiddleware(c.WarehouseService),
+	}
+	scoped := func(h ...gin.HandlerFunc) []gin.HandlerFunc {
+		return append(append([]gin.HandlerFunc{}, warehouseScope...), h...)
+	}
+	// Ghi tồn: ngoài phạm vi kho còn cần quyền inventory:write
+	scopedWrite := func(h gin.HandlerFunc) []gin.HandlerFunc {
+		return scoped(middleware.RequirePermission(rbac.PermInventoryWrite), h)
+	}
 	{
 		// CRUD
-		inventory.POST("", c.InventoryHandler.CreateInventory)
-		inventory.GET("", c.InventoryHandler.ListInventories)
-		inventory.GET("/:warehouse_id/:book_id", c.InventoryHandler.GetInventoryByWarehouseAndBook)
-		inventory.PATCH("/:warehouse_id/:book_id", c.InventoryHandler.UpdateInventory)
-		inventory.DELETE("/:warehouse_id/:book_id", c.InventoryHandler.DeleteInventory)
+		inventory.POST("", scopedWrite(c.InventoryHandler.CreateInventory)...)
+		inventory.GET("", scoped(c.InventoryHandler.ListInventories)...)
+		inventory.GET("/:warehouse_id/:book_id", scoped(c.InventoryHandler.GetInventoryByWarehouseAndBook)...)
+		inventory.PATCH("/:warehouse_id/:book_id", scopedWrite(c.InventoryHandler.UpdateInventory)...)
+		inventory.DELETE("/:warehouse_id/:book_id", scopedWrite(c.InventoryHandler.DeleteInventory)...)
 
 		// Stock operations
-		inventory.POST("/reserve", c.InventoryHandler.ReserveStock)
-		inventory.POST("/release", c.InventoryHandler.ReleaseStock)
-		inventory.POST("/complete-sale", c.InventoryHandler.CompleteSale)
+		inventory.POST("/reserve", scopedWrite(c.InventoryHandler.ReserveStock)...)
+		inventory.POST("/release", scopedWrite(c.InventoryHandler.ReleaseStock)...)
+		inventory.POST("/complete-sale", scopedWrite(c.InventoryHandler.CompleteSale)...)
 		inventory.POST("/find-warehouse", c.InventoryHandler.FindOptimalWarehouse)
 		inventory.POST("/check-availability", c.InventoryHandler.CheckAvailability)
 		inventory.GET("/summary/:book_id", c.InventoryHandler.GetStockSummary)
 
 		// Stock adjustment
-		inventory.POST("/adjust", c.InventoryHandler.AdjustStock)
-		inventory.POST("/restock", c.InventoryHandler.RestockInventory)
-		inventory.POST("/bulk-update", c.InventoryHandler.BulkUpdateStock)
-		inventory.GET("/bulk-update/:job_id", c.InventoryHandler.GetBulkUpdateStatus)
+		inventory.POST("/adjust", scopedWrite(c.InventoryHandler.AdjustStock)...)
+		inventory.POST("/restock", scopedWrite(c.InventoryHandler.RestockInventory)...)
+
+		// Adjustment approval (admin thứ 2 duyệt adjustment vượt ngưỡng)
+		adjustments := inventory.Group("/adjustments")
+		adjustments.Use(
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+		)
+		{
+			adjustments.GET("", c.InventoryHandler.ListAdjustmentRequests)
+			adjustments.POST("/:id/approve", c.InventoryHandler.ApproveAdjustment)
+			adjustments.POST("/:id/reject", c.InventoryHandler.RejectAdjustment)
+		}
+
+		// Supplier return (RTV): trả hàng hỏng / dư về NCC + credit note
+		rtv := inventory.Group("/rtv")
+		rtv.Use(
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+		)
+		{
+			rtv.POST("", c.InventoryHandler.CreateSupplierReturn)
+			rtv.GET("", c.InventoryHandler.ListSupplierReturns)
+			rtv.GET("/reports/suppliers", c.InventoryHandler.GetRTVReport)
+			rtv.GET("/:id", c.InventoryHandler.GetSupplierReturn)
+			rtv.POST("/:id/credit-notes", c.InventoryHandler.RecordCreditNote)
+			rtv.POST("/:id/close", c.InventoryHandler.CloseSupplierReturn)
+		}
+
+		// Chuyển kho: xuất trừ kho nguồn (TRANSFER_OUT), nhận cộng kho đích (TRANSFER_IN)
+		inventory.POST("/transfers", scopedWrite(c.InventoryHandler.CreateStockTransfer)...)
+		inventory.GET("/transfers", scoped(c.InventoryHandler.ListStockTransfers)...)
+		inventory.GET("/transfers/:id", scoped(c.InventoryHandler.GetStockTransfer)...)
+		inventory.POST("/transfers/:id/ship", scopedWrite(c.InventoryHandler.ShipStockTransfer)...)
+		inventory.POST("/transfers/:id/receive", scopedWrite(c.InventoryHandler.ReceiveStockTransfer)...)
+		inventory.POST("/transfers/:id/cancel", scopedWrite(c.InventoryHandler.CancelStockTransfer)...)
+
+		// Serial / lot bản giới hạn: nhập kèm serial qua /restock, gán serial cho order item lúc soạn hàng
+		inventory.GET("/serials", scoped(c.InventoryHandler.ListSerialUnits)...)
+		inventory.GET("/serials/:serial", scoped(c.InventoryHandler.GetSerialUnit)...)
+		inventory.POST("/serials/orders/:order_id/items/:item_id", scopedWrite(c.InventoryHandler.AssignOrderItemSerials)...)
+		inventory.DELETE("/serials/orders/:order_id/items/:item_id/:serial", scopedWrite(c.InventoryHandler.UnassignOrderItemSerial)...)
+
+		inventory.POST("/bulk-update", scopedWrite(c.InventoryHandler.BulkUpdateStock)...)
+		inventory.GET("/bulk-update/:job_id", withPermission(c, c.InventoryHandler.GetBulkUpdateStatus, rbac.PermInventoryRead)...)
 
 		// Audit & alerts
-		inventory.GET("/audit", c.InventoryHandler.GetAuditTrail)
-		inventory.GET("/:warehouse_id/:book_id/history", c.InventoryHandler.GetInventoryHistory)
-		inventory.POST("/audit/export", c.InventoryHandler.ExportAuditLog)
-		inventory.GET("/alerts/low-stock", c.InventoryHandler.GetLowStockAlerts)
-		inventory.GET("/alerts/out-of-stock", c.InventoryHandler.GetOutOfStockItems)
-		inventory.PATCH("/alerts/:alert_id/resolve", c.InventoryHandler.MarkAlertResolved)
+		inventory.GET("/audit", scoped(c.InventoryHandler.GetAuditTrail)...)
+		inventory.GET("/:warehouse_id/:book_id/history", scoped(c.InventoryHandler.GetInventoryHistory)...)
+		inventory.POST("/audit/export", withPermission(c, c.InventoryHandler.ExportAuditLog, rbac.PermInventoryRead)...)
+		inventory.GET("/reports/write-offs", scoped(c.InventoryHandler.GetWriteOffReport)...)
+
+		// Dự báo nhu cầu + gợi ý nhập hàng (job tính lại hàng tuần)
+		inventory.GET("/forecast", scoped(c.InventoryHandler.GetDemandForecast)...)
+		inventory.GET("/replenishment/suggestions", scoped(c.InventoryHandler.GetReplenishmentSuggestions)...)
+		inventory.GET("/alerts/low-stock", withPermission(c, c.InventoryHandler.GetLowStockAlerts, rbac.PermInventoryRead)...)
+		inventory.GET("/alerts/out-of-stock", withPermission(c, c.InventoryHandler.GetOutOfStockItems, rbac.PermInventoryRead)...)
+		inventory.PATCH("/alerts/:alert_id/resolve", withPermission(c, c.InventoryHandler.MarkAlertResolved, rbac.PermInventoryWrite)...)
+
+		// Người nhận cảnh báo tồn thấp (email / Slack webhook, gửi ngay hoặc digest theo giờ)
+		recipients := inventory.Group("/alerts/recipients")
+		recipients.Use(
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+		)
+		{
+			recipients.POST("", c.InventoryHandler.CreateAlertRecipient)
+			recipients.GET("", c.InventoryHandler.ListAlertRecipients)
+			recipients.PATCH("/:id", c.InventoryHandler.UpdateAlertRecipient)
+			recipients.DELETE("/:id", c.InventoryHandler.DeleteAlertRecipient)
+		}
+
+		// Live updates cho admin dashboard (SSE, thay cho polling)
+		inventory.GET("/events/stream", scoped(c.InventoryHandler.StreamEvents)...)
 
 		// Dashboard
-		inventory.GET("/dashboard", c.InventoryHandler.GetDashboardSummary)
-		inventory.GET("/analysis/reservations", c.InventoryHandler.GetReservationAnalysis)
+		inventory.GET("/dashboard", withPermission(c, c.InventoryHandler.GetDashboardSummary, rbac.PermInventoryRead)...)
+		inventory.GET("/analysis/reservations", withPermission(c, c.InventoryHandler.GetReservationAnalysis, rbac.PermInventoryRead)...)
 	}
 }
 
@@ -278,17 +503,68 @@ func setupCartRoutes(v1 *gin.RouterGroup, c *container.Container, config *middle
 	)
 	{
 		cart.GET("", c.CartHandler.GetCart)
-		cart.POST("/items", c.CartHandler.AddItem)
+		cart.POST("/items", antiBot(c, fraudModel.ActionAddToCart, c.Config.AntiBot.AddToCartThreshold), c.CartHandler.AddItem)
 		cart.GET("/items", c.CartHandler.ListItems)
+		cart.PATCH("/items/bulk", c.CartHandler.BulkUpdateItems)
 		cart.PUT("/items/:item_id", c.CartHandler.UpdateItemQuantity)
 		cart.DELETE("/items/:item_id", c.CartHandler.RemoveItem)
 		cart.DELETE("", c.CartHandler.ClearCart)
 		cart.POST("/validate", c.CartHandler.ValidateCart)
-		cart.POST("/apply-promotion", c.CartHandler.ApplyPromoCode)
+		cart.GET("/progress", c.CartHandler.GetProgress)
+		cart.POST("/apply-promotion", promoRateLimit(c), antiBot(c, fraudModel.ActionApplyPromo, c.Config.AntiBot.PromoValidateThreshold), c.CartHandler.ApplyPromoCode)
 		cart.DELETE("/remove-promotion", c.CartHandler.RemovePromoCode)
-		cart.POST("/checkout", c.CartHandler.Checkout)
+		cart.POST("/checkout", checkoutRateLimit(c), idempotency(c, middleware.IdempotencyScopeCartCheckout), c.CartHandler.Checkout)
+		cart.POST("/restore/:order_id", c.CartHandler.RestoreFromOrder)
+		cart.POST("/reorder/:order_id", c.CartHandler.ReorderToCart)
 		cart.GET("/:cart_id/promotions", c.CartHandler.GetAvailablePromotions)
 	}
+	// Admin / CSKH dựng giỏ + đặt đơn thay khách (đơn qua điện thoại)
+	customerCart := v1.Group("/admin/customers/:user_id/cart")
+	customerCart.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.StaffMiddleware(),
+	)
+	{
+		customerCart.GET("", c.CartHandler.AdminGetCustomerCart)
+		customerCart.POST("/items", c.CartHandler.AdminAddItem)
+		customerCart.PUT("/items/:item_id", c.CartHandler.AdminUpdateItemQuantity)
+		customerCart.DELETE("/items/:item_id", c.CartHandler.AdminRemoveItem)
+		customerCart.POST("/apply-promotion", c.CartHandler.AdminApplyPromoCode)
+		customerCart.DELETE("/remove-promotion", c.CartHandler.AdminRemovePromoCode)
+		customerCart.POST("/checkout", c.CartHandler.AdminCheckout)
+	}
+
+	// CSKH tra vì sao checkout của khách thất bại (phase + mã lỗi + response đầy đủ)
+	checkoutFailures := v1.Group("/admin/checkout-failures")
+	checkoutFailures.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.RequirePermission(rbac.PermCustomerRead),
+	)
+	{
+		checkoutFailures.GET("", c.CartHandler.AdminListCheckoutFailures)
+		checkoutFailures.GET("/:id", c.CartHandler.AdminGetCheckoutFailure)
+	}
+}
+
+// ========================================
+// WISHLIST ROUTES
+// ========================================
+func setupWishlistRoutes(v1 *gin.RouterGroup, c *container.Container, config *middleware.CartMiddlewareConfig) {
+	// Guest dùng chung session_id với giỏ hàng ẩn danh; move-to-cart cần cart_id của CartMiddleware
+	wishlist := v1.Group("/wishlist")
+	wishlist.Use(
+		middleware.OptionalAuthMiddleware(c.Config.JWT.Secret),
+		middleware.CartMiddleware(*config),
+	)
+	{
+		wishlist.GET("", c.WishlistHandler.ListWishlist)
+		wishlist.POST("/items", c.WishlistHandler.AddItem)
+		wishlist.DELETE("/items/:book_id", c.WishlistHandler.RemoveItem)
+		wishlist.POST("/items/:book_id/move-to-cart", c.WishlistHandler.MoveToCart)
+	}
+
+	// Merge wishlist guest sau login (login bằng mật khẩu đã tự merge)
+	v1.POST("/wishlist/merge", middleware.AuthMiddleware(c.Config.JWT.Secret), c.WishlistHandler.MergeWishlist)
 }
 
 // ========================================
@@ -298,18 +574,60 @@ func setupPromotionRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	promotion := v1.Group("/promotion")
 	{
 		// Public routes
-		promotion.POST("/validate", c.PublicProHandler.ValidatePromotion)
+		promotion.POST("/validate",
+			middleware.OptionalAuthMiddleware(c.Config.JWT.Secret),
+			promoRateLimit(c),
+			antiBot(c, fraudModel.ActionValidatePromo, c.Config.AntiBot.PromoValidateThreshold),
+			c.PublicProHandler.ValidatePromotion,
+		)
 		promotion.GET("", c.PublicProHandler.ListActivePromotions)
+		// Ví mã của user: mã được gán + mã công khai, kiểm tra sẵn với giỏ hàng
+		promotion.GET("/wallet",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			c.PublicProHandler.GetPromoWallet,
+		)
+
+		// Admin routes
+		promotion.POST("/create", withPermission(c, c.AdminProHandler.CreatePromotion, rbac.PermPromotionManage)...)
+		promotion.GET("/list-promotion", withPermission(c, c.AdminProHandler.ListPromotions, rbac.PermPromotionManage)...)
+		promotion.GET("/:id", withPermission(c, c.AdminProHandler.GetPromotionByID, rbac.PermPromotionManage)...)
+		promotion.PUT("/:id", withPermission(c, c.AdminProHandler.UpdatePromotion, rbac.PermPromotionManage)...)
+		promotion.PATCH("/:id/status", withPermission(c, c.AdminProHandler.UpdatePromotionStatus, rbac.PermPromotionManage)...)
+		promotion.DELETE("/:id", withPermission(c, c.AdminProHandler.DeletePromotion, rbac.PermPromotionManage)...)
+		promotion.GET("/:id/usage", withPermission(c, c.AdminProHandler.GetUsageHistory, rbac.PermPromotionManage)...)
+		promotion.POST("/:id/export", withPermission(c, c.AdminProHandler.ExportUsageReport, rbac.PermPromotionManage)...)
+
+		// Mã cá nhân: gán / thu hồi user
+		promotion.POST("/:id/assignments",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.AdminProHandler.AssignPromotion,
+		)
+		promotion.DELETE("/:id/assignments/:user_id",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.AdminProHandler.UnassignPromotion,
+		)
+
+		// Tặng quà kèm đơn: giỏ đạt điều kiện tự có dòng quà 0đ
+		gifts := promotion.Group("/gifts")
+		gifts.Use(
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+		)
+		{
+			gifts.POST("", c.AdminProHandler.CreateGiftPromotion)
+			gifts.GET("", c.AdminProHandler.ListGiftPromotions)
+			gifts.PATCH("/:id/status", c.AdminProHandler.UpdateGiftPromotionStatus)
+			gifts.DELETE("/:id", c.AdminProHandler.DeleteGiftPromotion)
+		}
 
-		// Admin routes (TODO: add auth middleware)
-		promotion.POST("/create", c.AdminProHandler.CreatePromotion)
-		promotion.GET("/list-promotion", c.AdminProHandler.ListPromotions)
-		promotion.GET("/:id", c.AdminProHandler.GetPromotionByID)
-		promotion.PUT("/:id", c.AdminProHandler.UpdatePromotion)
-		promotion.PATCH("/:id/status", c.AdminProHandler.UpdatePromotionStatus)
-		promotion.DELETE("/:id", c.AdminProHandler.DeletePromotion)
-		promotion.GET("/:id/usage", c.AdminProHandler.GetUsageHistory)
-		promotion.POST("/:id/export", c.AdminProHandler.ExportUsageReport)
+		// Backfill lịch sử dùng mã từ hệ thống cũ (chuyển dữ liệu cửa hàng)
+		promotion.POST("/usage-imports",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.AdminProHandler.ImportPromotionUsage,
+		)
 	}
 }
 
@@ -320,12 +638,23 @@ func setupOrderRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	orders := v1.Group("/orders")
 	orders.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
 	{
-		orders.POST("", c.OrderHandler.CreateOrder)
+		orders.POST("", checkoutRateLimit(c), idempotency(c, middleware.IdempotencyScopeOrderCreate), c.OrderHandler.CreateOrder)
 		orders.GET("", c.OrderHandler.ListOrders)
 		orders.GET("/:id", c.OrderHandler.GetOrderDetail)
+		orders.GET("/:id/invoice", c.OrderHandler.GetInvoice)
+		orders.PATCH("/:id", c.OrderHandler.ModifyOrder)
 		orders.POST("/:id/cancel", c.OrderHandler.CancelOrder)
 		orders.GET("/track/:order_number", c.OrderHandler.GetOrderByNumber)
+		// Hoá đơn điện tử (khách nhập MST để xuất hoá đơn doanh nghiệp)
+		orders.POST("/:id/e-invoice", c.EInvoiceHandler.RequestInvoice)
+		orders.GET("/:id/e-invoices", c.EInvoiceHandler.ListOrderInvoices)
 	}
+
+	// Tra cứu đơn public cho khách vãng lai / COD (mã đơn + 4 số cuối SĐT), chống dò bằng anti-bot
+	v1.POST("/orders/track",
+		antiBot(c, fraudModel.ActionTrackOrder, c.Config.AntiBot.TrackOrderThreshold),
+		c.OrderHandler.TrackOrderPublic,
+	)
 }
 
 // ========================================
@@ -349,6 +678,22 @@ func setupPaymentRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	}
 }
 
+// ========================================
+// SANDBOX ROUTES (PAYMENT_PROVIDER_MODE=fake)
+// ========================================
+// Cổng VNPay giả lập: payment URL / refund của client VNPay trỏ về đây (FAKE_GATEWAY_URL)
+func setupSandboxRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	if c.SandboxHandler == nil {
+		return
+	}
+
+	sandbox := v1.Group("/sandbox/vnpay")
+	{
+		sandbox.GET("/vpcpay.html", c.SandboxHandler.VNPayPay)
+		sandbox.POST("/merchant_webapi/api/transaction", c.SandboxHandler.VNPayRefund)
+	}
+}
+
 // ========================================
 // WEBHOOK ROUTES
 // ========================================
@@ -358,6 +703,13 @@ func setupWebhookRoutes(v1 *gin.RouterGroup, c *container.Container) {
 		webhooks.GET("/vnpay", c.PaymentHandler.VNPayWebhook)
 		webhooks.POST("/vnpay", c.PaymentHandler.VNPayWebhook)
 		webhooks.POST("/momo", c.PaymentHandler.MomoWebhook)
+
+		// Email provider delivery events (?token=EMAIL_WEBHOOK_SECRET)
+		webhooks.POST("/email/ses", c.EmailWebhookHandler.SESWebhook)
+		webhooks.POST("/email/sendgrid", c.EmailWebhookHandler.SendGridWebhook)
+
+		// Carrier tracking status updates: ghn, ghtk (?token=SHIPPING_WEBHOOK_SECRET)
+		webhooks.POST("/shipping/:carrier", c.ShippingHandler.TrackingWebhook)
 	}
 }
 
@@ -365,11 +717,95 @@ func setupWebhookRoutes(v1 *gin.RouterGroup, c *container.Container) {
 // ADMIN ORDER ROUTES
 // ========================================
 func setupAdminOrderRoutes(v1 *gin.RouterGroup, c *container.Container) {
-	// TODO: Add admin middleware
 	adminOrders := v1.Group("/admin/orders")
 	{
-		adminOrders.GET("", c.OrderHandler.ListAllOrders)
-		adminOrders.PATCH("/:id/status", c.OrderHandler.UpdateOrderStatus)
+		adminOrders.GET("", withPermission(c, c.OrderHandler.ListAllOrders, rbac.PermOrderRead)...)
+		adminOrders.PATCH("/:id/status", withPermission(c, c.OrderHandler.UpdateOrderStatus, rbac.PermOrderUpdateStatus)...)
+		// Workflow trạng thái đơn (transition + hook) cấu hình được
+		adminOrders.GET("/workflow",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.GetStatusWorkflow,
+		)
+		adminOrders.PUT("/workflow",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.UpdateStatusWorkflow,
+		)
+		// Rule khách tự huỷ đơn (trạng thái tối đa + cửa sổ giờ, flash sale riêng)
+		adminOrders.GET("/cancellation-rules",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.GetCancellationRules,
+		)
+		adminOrders.PUT("/cancellation-rules",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.UpdateCancellationRules,
+		)
+		adminOrders.GET("/:id/invoice",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.AdminGetInvoice,
+		)
+		// Order detail + lịch sử liên lạc cho support
+		adminOrders.GET("/:id",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.AdminGetOrderDetail,
+		)
+		adminOrders.POST("/:id/communications",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.LogCommunication,
+		)
+		// Packing slip cho kho (staff kho chỉ xem đơn của kho được gán)
+		adminOrders.GET("/:id/packing-slip",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.WarehouseScopeMiddleware(c.WarehouseService),
+			c.OrderHandler.GetPackingSlip,
+		)
+		// Tỷ lệ chọn đóng gói tối giản / không in hoá đơn
+		adminOrders.GET("/reports/packaging",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.GetPackagingReport,
+		)
+		// Gửi bù hàng hỏng / thất lạc (đơn 0đ) + báo cáo theo lý do
+		adminOrders.POST("/:id/replacements",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.CreateReplacement,
+		)
+		adminOrders.GET("/reports/replacements",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.GetReplacementReport,
+		)
+		// Import đơn offline / điện thoại / lịch sử từ CSV (job tạo đơn, xem kết quả từng dòng)
+		adminOrders.POST("/imports",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.CreateOrderImport,
+		)
+		adminOrders.GET("/imports/:id",
+			middleware.AuthMiddleware(c.Config.JWT.Secret),
+			middleware.AdminMiddleware(),
+			c.OrderHandler.GetOrderImport,
+		)
+		// Cập nhật trạng thái hàng loạt từ CSV (staff kho đánh dấu "shipping" + mã vận đơn)
+		adminOrders.POST("/bulk-status", withPermission(c, c.OrderHandler.CreateBulkStatusUpdate, rbac.PermOrderUpdateStatus)...)
+		adminOrders.GET("/bulk-status/:id", withPermission(c, c.OrderHandler.GetBulkStatusUpdate, rbac.PermOrderUpdateStatus)...)
+	}
+
+	// Kênh bán: cấu hình thanh toán / vận chuyển theo kênh + nhận đơn sàn (Shopee / Lazada)
+	// Connector của sàn gọi bằng tài khoản admin, gửi lại cùng external_order_id không tạo đơn trùng
+	adminChannels := v1.Group("/admin/channels")
+	adminChannels.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminChannels.GET("", c.OrderHandler.ListSalesChannels)
+		adminChannels.PATCH("/:code", c.OrderHandler.UpdateSalesChannel)
+		adminChannels.POST("/:code/orders", c.OrderHandler.IngestMarketplaceOrder)
 	}
 }
 
@@ -377,16 +813,34 @@ func setupAdminOrderRoutes(v1 *gin.RouterGroup, c *container.Container) {
 // ADMIN PAYMENT ROUTES
 // ========================================
 func setupAdminPaymentRoutes(v1 *gin.RouterGroup, c *container.Container) {
-	// TODO: Add admin middleware
 	adminPayments := v1.Group("/admin/payments")
+	adminPayments.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.RequirePermission(rbac.PermPaymentRead),
+	)
+	managePayments := middleware.RequirePermission(rbac.PermPaymentManage)
 	{
 		adminPayments.GET("", c.PaymentHandler.AdminListPayments)
 		adminPayments.GET("/:payment_id", c.PaymentHandler.AdminGetPaymentDetail)
-		adminPayments.POST("/:payment_id/reconcile", c.PaymentHandler.AdminReconcilePayment)
+		adminPayments.POST("/:payment_id/reconcile", managePayments, c.PaymentHandler.AdminReconcilePayment)
 		adminPayments.GET("/refunds/pending", c.PaymentHandler.AdminListPendingRefunds)
 		adminPayments.GET("/refunds/:refund_id", c.PaymentHandler.AdminGetRefundDetail)
-		adminPayments.POST("/refunds/:refund_id/approve", c.PaymentHandler.AdminApproveRefund)
-		adminPayments.POST("/refunds/:refund_id/reject", c.PaymentHandler.AdminRejectRefund)
+		adminPayments.POST("/refunds/:refund_id/approve", managePayments, c.PaymentHandler.AdminApproveRefund)
+		adminPayments.POST("/refunds/:refund_id/reject", managePayments, c.PaymentHandler.AdminRejectRefund)
+
+		// Sổ cái thanh toán (double-entry) cho finance
+		adminPayments.GET("/ledger/summary", c.LedgerHandler.AdminGetLedgerSummary)
+		adminPayments.GET("/ledger/export", c.LedgerHandler.AdminExportLedger)
+		adminPayments.GET("/ledger/orders/:order_id", c.LedgerHandler.AdminGetOrderLedger)
+	}
+
+	// Đối soát tiền COD với hãng vận chuyển (cần admin ID để ghi người import)
+	adminCOD := v1.Group("/admin/payments/cod")
+	adminCOD.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminCOD.POST("/remittances", c.CODHandler.AdminImportRemittance)
+		adminCOD.GET("/remittances/:remittance_id", c.CODHandler.AdminGetRemittance)
+		adminCOD.GET("/shipments", c.CODHandler.AdminListShipments)
 	}
 }
 
@@ -410,21 +864,228 @@ func setupReviewRoutes(v1 *gin.RouterGroup, c *container.Container) {
 		userReviews.DELETE("/:id", c.ReviewHandler.DeleteReview)
 		userReviews.GET("/me", c.ReviewHandler.ListMyReviews)
 		userReviews.GET("/books/:book_id/reviews", c.ReviewHandler.GetBookReviews)
+		userReviews.POST("/:id/report", c.ReviewHandler.ReportReview)
 	}
 
 	// Admin review routes
-	// TODO: Add admin middleware
 	adminReviews := v1.Group("/admin/reviews")
+	adminReviews.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
 	{
 		adminReviews.GET("", c.ReviewHandler.AdminListReviews)
+		adminReviews.GET("/queue", c.ReviewHandler.AdminModerationQueue)
 		adminReviews.GET("/:id", c.ReviewHandler.AdminGetReview)
+		adminReviews.GET("/:id/reports", c.ReviewHandler.AdminListReports)
 		adminReviews.GET("/statistics", c.ReviewHandler.AdminGetStatistics)
+		adminReviews.POST("/:id/approve", c.ReviewHandler.AdminApproveReview)
+		adminReviews.POST("/:id/reject", c.ReviewHandler.AdminRejectReview)
+		adminReviews.POST("/:id/flag", c.ReviewHandler.AdminFlagReview)
 		adminReviews.PATCH("/:id/moderate", c.ReviewHandler.AdminModerateReview)
 		adminReviews.PATCH("/:id/feature", c.ReviewHandler.AdminFeatureReview)
 		adminReviews.DELETE("/:id", c.ReviewHandler.AdminDeleteReview)
 	}
 }
 
+// ========================================
+// PRODUCT Q&A ROUTES
+// ========================================
+func setupQuestionRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Public Q&A routes
+	questions := v1.Group("/questions")
+	{
+		questions.GET("", c.QuestionHandler.ListQuestions)
+		questions.GET("/:id", c.QuestionHandler.GetQuestion)
+	}
+
+	// User Q&A routes
+	userQuestions := v1.Group("/questions")
+	userQuestions.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
+	{
+		userQuestions.POST("", c.QuestionHandler.AskQuestion)
+		userQuestions.POST("/:id/answers", c.QuestionHandler.AnswerQuestion)
+		userQuestions.POST("/answers/:answer_id/upvote", c.QuestionHandler.UpvoteAnswer)
+		userQuestions.DELETE("/answers/:answer_id/upvote", c.QuestionHandler.RemoveUpvote)
+	}
+
+	// Admin Q&A moderation routes
+	adminQuestions := v1.Group("/admin/questions")
+	adminQuestions.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminQuestions.GET("", c.QuestionHandler.AdminListQuestions)
+		adminQuestions.GET("/:id", c.QuestionHandler.AdminGetQuestion)
+		adminQuestions.PATCH("/:id/status", c.QuestionHandler.AdminUpdateQuestionStatus)
+		adminQuestions.PATCH("/answers/:answer_id/status", c.QuestionHandler.AdminUpdateAnswerStatus)
+	}
+}
+
+// ========================================
+// B2B QUOTE ROUTES
+// ========================================
+func setupQuoteRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Customer quote routes
+	quotes := v1.Group("/quotes")
+	quotes.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
+	{
+		quotes.POST("", c.QuoteHandler.RequestQuote)
+		quotes.GET("", c.QuoteHandler.ListMyQuotes)
+		quotes.GET("/:id", c.QuoteHandler.GetMyQuote)
+		quotes.POST("/:id/cancel", c.QuoteHandler.CancelQuote)
+		quotes.POST("/:id/convert", c.QuoteHandler.ConvertQuote)
+	}
+
+	// Admin pricing approval routes
+	adminQuotes := v1.Group("/admin/quotes")
+	adminQuotes.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminQuotes.GET("", c.QuoteHandler.AdminListQuotes)
+		adminQuotes.GET("/:id", c.QuoteHandler.AdminGetQuote)
+		adminQuotes.POST("/:id/approve", c.QuoteHandler.ApproveQuote)
+		adminQuotes.POST("/:id/reject", c.QuoteHandler.RejectQuote)
+		adminQuotes.POST("/:id/mark-paid", c.QuoteHandler.MarkInvoicePaid)
+	}
+}
+
+// ========================================
+// ORDER ISSUE TICKET ROUTES
+// ========================================
+func setupTicketRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Customer: khiếu nại đơn hàng (thiếu sách, sách hỏng, ...)
+	tickets := v1.Group("/tickets")
+	tickets.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
+	{
+		tickets.POST("", c.TicketHandler.CreateTicket)
+		tickets.GET("", c.TicketHandler.ListMyTickets)
+		tickets.GET("/:id", c.TicketHandler.GetMyTicket)
+		tickets.POST("/:id/messages", c.TicketHandler.AddCustomerMessage)
+	}
+
+	// Admin / CSKH: xử lý, bồi thường, dashboard SLA
+	adminTickets := v1.Group("/admin/tickets")
+	adminTickets.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.StaffMiddleware())
+	{
+		adminTickets.GET("", c.TicketHandler.AdminListTickets)
+		adminTickets.GET("/dashboard", c.TicketHandler.GetSLADashboard)
+		adminTickets.GET("/:id", c.TicketHandler.AdminGetTicket)
+		adminTickets.PATCH("/:id/assign", c.TicketHandler.AssignTicket)
+		adminTickets.PATCH("/:id/status", c.TicketHandler.UpdateStatus)
+		adminTickets.POST("/:id/messages", c.TicketHandler.AddStaffMessage)
+		adminTickets.POST("/:id/compensations", c.TicketHandler.CreateCompensation)
+	}
+}
+
+// ========================================
+// AFFILIATE ROUTES (ADMIN)
+// ========================================
+func setupAffiliateRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Đối tác + % hoa hồng; báo cáo tháng: duyệt → đánh dấu đã chi
+	adminAffiliates := v1.Group("/admin/affiliates")
+	adminAffiliates.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminAffiliates.GET("/partners", c.AffiliateHandler.ListPartners)
+		adminAffiliates.POST("/partners", c.AffiliateHandler.CreatePartner)
+		adminAffiliates.PATCH("/partners/:id", c.AffiliateHandler.UpdatePartner)
+
+		adminAffiliates.GET("/reports", c.AffiliateHandler.ListReports)
+		adminAffiliates.POST("/reports/generate", c.AffiliateHandler.GenerateReports)
+		adminAffiliates.GET("/reports/:id", c.AffiliateHandler.GetReport)
+		adminAffiliates.GET("/reports/:id/export", c.AffiliateHandler.ExportReport)
+		adminAffiliates.POST("/reports/:id/approve", c.AffiliateHandler.ApproveReport)
+		adminAffiliates.POST("/reports/:id/mark-paid", c.AffiliateHandler.MarkReportPaid)
+	}
+}
+
+// ========================================
+// SHIPPING LABEL ROUTES (ADMIN)
+// ========================================
+func setupShippingRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Mua / xem nhãn của 1 đơn
+	orderShipment := v1.Group("/admin/orders")
+	orderShipment.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		orderShipment.POST("/:id/shipment", c.ShippingHandler.PurchaseLabel)
+		orderShipment.GET("/:id/shipment", c.ShippingHandler.GetOrderShipment)
+	}
+
+	// In nhãn + đợt in hàng loạt
+	shipments := v1.Group("/admin/shipments")
+	shipments.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		shipments.GET("/carriers", c.ShippingHandler.ListCarriers)
+		shipments.GET("/:id/label", c.ShippingHandler.PrintLabel)
+		shipments.POST("/batches", c.ShippingHandler.CreateDispatchBatch)
+		shipments.GET("/batches/:id", c.ShippingHandler.GetDispatchBatch)
+		shipments.GET("/batches/:id/labels", c.ShippingHandler.PrintBatchLabels)
+	}
+}
+
+// ========================================
+// E-INVOICE ROUTES (ADMIN / ACCOUNTING)
+// ========================================
+func setupEInvoiceRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Lập / xem hoá đơn của 1 đơn
+	orderInvoices := v1.Group("/admin/orders")
+	orderInvoices.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		orderInvoices.POST("/:id/e-invoices", c.EInvoiceHandler.AdminRequestInvoice)
+		orderInvoices.GET("/:id/e-invoices", c.EInvoiceHandler.AdminListOrderInvoices)
+	}
+
+	// Kế toán: danh sách, thay thế, điều chỉnh, phát hành lại
+	einvoices := v1.Group("/admin/e-invoices")
+	einvoices.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		einvoices.GET("", c.EInvoiceHandler.ListInvoices)
+		einvoices.GET("/:id", c.EInvoiceHandler.GetInvoice)
+		einvoices.POST("/:id/reissue", c.EInvoiceHandler.ReissueInvoice)
+		einvoices.POST("/:id/adjust", c.EInvoiceHandler.AdjustInvoice)
+		einvoices.POST("/:id/retry", c.EInvoiceHandler.RetryInvoice)
+	}
+}
+
+// ========================================
+// TAX ROUTES
+// ========================================
+func setupTaxRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Rule thuế theo tỉnh / danh mục (checkout + tổng đơn + hoá đơn)
+	taxRules := v1.Group("/admin/tax-rules")
+	taxRules.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		taxRules.GET("", c.TaxHandler.ListRules)
+		taxRules.POST("", c.TaxHandler.CreateRule)
+		taxRules.PUT("/:id", c.TaxHandler.UpdateRule)
+		taxRules.DELETE("/:id", c.TaxHandler.DeleteRule)
+	}
+}
+
+// ========================================
+// FLASH SALE ROUTES
+// ========================================
+func setupFlashSaleRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	// Public flash sale routes
+	flashSales := v1.Group("/flash-sales")
+	{
+		flashSales.GET("", c.FlashSaleHandler.ListFlashSales)
+		flashSales.GET("/:id", c.FlashSaleHandler.GetFlashSale)
+	}
+
+	// User queue routes (purchase token)
+	userFlashSales := v1.Group("/flash-sales")
+	userFlashSales.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
+	{
+		userFlashSales.POST("/:id/queue", c.FlashSaleHandler.JoinQueue)
+		userFlashSales.GET("/:id/queue", c.FlashSaleHandler.GetQueueStatus)
+		userFlashSales.POST("/:id/purchase", c.FlashSaleHandler.Purchase)
+	}
+
+	// Admin flash sale routes
+	adminFlashSales := v1.Group("/admin/flash-sales")
+	adminFlashSales.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminFlashSales.GET("", c.FlashSaleHandler.AdminListFlashSales)
+		adminFlashSales.POST("", c.FlashSaleHandler.AdminCreateFlashSale)
+		adminFlashSales.PATCH("/:id/status", c.FlashSaleHandler.AdminUpdateFlashSaleStatus)
+	}
+}
+
 // ========================================
 // NOTIFICATION ROUTES
 // ========================================
@@ -445,6 +1106,11 @@ func setupNotificationRoutes(v1 *gin.RouterGroup, c *container.Container) {
 		// Preferences
 		notifications.GET("/preferences", c.PreferencesHandler.GetPreferences)
 		notifications.PUT("/preferences", c.PreferencesHandler.UpdatePreferences)
+
+		// Push devices
+		notifications.GET("/devices", c.DeviceHandler.ListDevices)
+		notifications.POST("/devices", c.DeviceHandler.RegisterDevice)
+		notifications.DELETE("/devices", c.DeviceHandler.UnregisterDevice)
 	}
 
 	// ================================================
@@ -452,7 +1118,10 @@ func setupNotificationRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	// ================================================
 
 	admin := v1.Group("/admin")
-	admin.Use(middleware.AuthMiddleware(c.Config.JWT.Secret))
+	admin.Use(
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.RequirePermission(rbac.PermNotificationManage),
+	)
 	{
 		// Templates
 		templates := admin.Group("/notification-templates")
@@ -477,6 +1146,95 @@ func setupNotificationRoutes(v1 *gin.RouterGroup, c *container.Container) {
 	}
 }
 
+// ========================================
+// ANALYTICS ROUTES (Admin dashboards)
+// ========================================
+func setupAnalyticsRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	adminAnalytics := v1.Group("/admin/analytics")
+	adminAnalytics.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminAnalytics.GET("/funnel", c.AnalyticsHandler.AdminGetFunnel)
+		adminAnalytics.GET("/funnel/sources", c.AnalyticsHandler.AdminGetFunnelBySource)
+		adminAnalytics.POST("/funnel/recompute", c.AnalyticsHandler.AdminRecomputeFunnel)
+	}
+}
+
+// ========================================
+// FRAUD REVIEW ROUTES (Admin)
+// ========================================
+func setupFraudRoutes(v1 *gin.RouterGroup, c *container.Container) {
+	adminFraud := v1.Group("/admin/fraud")
+	adminFraud.Use(middleware.AuthMiddleware(c.Config.JWT.Secret), middleware.AdminMiddleware())
+	{
+		adminFraud.GET("/device-logs", c.FraudHandler.AdminListDeviceLogs)
+		adminFraud.GET("/shared-devices", c.FraudHandler.AdminListSharedDevices)
+	}
+}
+
+// antiBot - CAPTCHA cho khách vãng lai vượt ngưỡng (khi ANTIBOT_ENABLED) + log device fingerprint
+func antiBot(c *container.Container, action string, threshold int) gin.HandlerFunc {
+	cfg := middleware.AntiBotConfig{
+		Action:    action,
+		Enabled:   c.Config.AntiBot.Enabled,
+		Threshold: threshold,
+		Window:    time.Duration(c.Config.AntiBot.WindowSeconds) * time.Second,
+		PassTTL:   time.Duration(c.Config.AntiBot.PassTTLSeconds) * time.Second,
+		Cache:     c.Cache,
+		Logger:    c.FraudService,
+	}
+	// Tránh interface non-nil bọc con trỏ nil khi tắt anti-bot
+	if c.CaptchaVerifier != nil {
+		cfg.Verifier = c.CaptchaVerifier
+	}
+	return middleware.AntiBot(cfg)
+}
+
+// rateLimit - token bucket trong Redis theo IP (khách vãng lai) / user (có token hợp lệ), tắt khi RATE_LIMIT_ENABLED=false
+func rateLimit(c *container.Container, scope string, anonymous, authenticated middleware.RateLimitPolicy, skipPrefixes ...string) gin.HandlerFunc {
+	cfg := middleware.RateLimitConfig{
+		Scope:         scope,
+		Anonymous:     anonymous,
+		Authenticated: authenticated,
+		JWTSecret:     c.Config.JWT.Secret,
+		SkipPrefixes:  skipPrefixes,
+	}
+	// Cache không phải Redis → không có bucket phân tán, bỏ qua
+	if store, ok := c.Cache.(middleware.RateLimitStore); ok && c.Config.RateLimit.Enabled {
+		cfg.Store = store
+	}
+	return middleware.RateLimit(cfg)
+}
+
+// promoRateLimit - chặn dò mã giảm giá (validate + áp mã), cùng hạn mức cho IP và user
+func promoRateLimit(c *container.Container) gin.HandlerFunc {
+	policy := middleware.RateLimitPolicy{PerMinute: c.Config.RateLimit.PromoPerMinute, Burst: c.Config.RateLimit.PromoBurst}
+	return rateLimit(c, "promo", policy, policy)
+}
+
+// checkoutRateLimit - chặn bấm checkout / tạo đơn dồn dập (mỗi lần giữ hàng + gọi cổng thanh toán)
+func checkoutRateLimit(c *container.Container) gin.HandlerFunc {
+	policy := middleware.RateLimitPolicy{PerMinute: c.Config.RateLimit.CheckoutPerMinute, Burst: c.Config.RateLimit.CheckoutBurst}
+	return rateLimit(c, "checkout", policy, policy)
+}
+
+// withPermission Auth + kiểm tra permission trước handler (route quản trị nằm trong group public)
+func withPermission(c *container.Container, h gin.HandlerFunc, permissions ...rbac.Permission) []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		middleware.AuthMiddleware(c.Config.JWT.Secret),
+		middleware.RequirePermission(permissions...),
+		h,
+	}
+}
+
+// idempotency - retry cùng Idempotency-Key trong 24h trả lại response gốc (không tạo đơn trùng)
+func idempotency(c *container.Container, scope string) gin.HandlerFunc {
+	return middleware.Idempotency(middleware.IdempotencyConfig{
+		Scope: scope,
+		TTL:   middleware.DefaultIdempotencyTTL,
+		Store: c.IdempotencyRepo,
+	})
+}
+
 // ========================================
 // HEALTH CHECK HANDLER
 // ========================================
@@ -517,9 +1275,17 @@ func healthCheckHandler(appCtx *container.Container) gin.HandlerFunc {
 			}
 		}
 
+		// Storefront breaker mở: đang chạy degraded mode (browse từ cache, chặn checkout)
+		storefrontStatus := "ok"
+		if appCtx.StorefrontBreaker != nil && appCtx.StorefrontBreaker.Degraded() {
+			storefrontStatus = appCtx.StorefrontBreaker.State()
+			health["status"] = "degraded"
+		}
+
 		health["services"] = gin.H{
-			"database": dbStatus,
-			"redis":    redisStatus,
+			"database":   dbStatus,
+			"redis":      redisStatus,
+			"storefront": storefrontStatus,
 		}
 
 		statusCode := http.StatusOK
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/config"
+	inventoryHandler "bookstore-backend/internal/domains/inventory/handler"
+	warehouseService "bookstore-backend/internal/domains/warehouse/service"
+	"bookstore-backend/internal/shared/rbac"
+	"bookstore-backend/pkg/container"
+)
+
+// ========================================
+// PERMISSION MATRIX: role × inventory route
+// ========================================
+// Chỉ kiểm tra lớp phân quyền (AuthMiddleware, RequirePermission, WarehouseScopeMiddleware,
+// CanAccessWarehouse trong handler). Service là nil: request qua được phân quyền sẽ panic ở
+// service, recovery trả statusReachedService → tính là "được phép".
+
+const (
+	testJWTSecret        = "permission-matrix-secret"
+	statusReachedService = 599
+)
+
+// stubWarehouseService staff được gán đúng 1 kho
+type stubWarehouseService struct {
+	warehouseService.Service
+	assigned []uuid.UUID
+}
+
+func (s stubWarehouseService) ListAssignedWarehouseIDs(context.Context, uuid.UUID) ([]uuid.UUID, error) {
+	return s.assigned, nil
+}
+
+func newInventoryTestRouter(assigned uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	c := &container.Container{
+		Config:           &config.Config{JWT: config.JWTConfig{Secret: testJWTSecret}},
+		WarehouseService: stubWarehouseService{assigned: []uuid.UUID{assigned}},
+		InventoryHandler: inventoryHandler.NewHandler(nil, nil),
+	}
+
+	router := gin.New()
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ any) {
+		c.AbortWithStatus(statusReachedService)
+	}))
+	setupInventoryRoutes(router.Group("/api/v1"), c)
+	return router
+}
+
+func testToken(t *testing.T, role rbac.Role) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": uuid.NewString(),
+		"role":    string(role),
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func jsonBody(t *testing.T, v interface{}) (io.Reader, string) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	return bytes.NewReader(data), "application/json"
+}
+
+// csvUpload multipart CSV với 1 dòng cho warehouseID (file tạm handler ghi vào /tmp được dọn sau test)
+func csvUpload(t *testing.T, warehouseID uuid.UUID) (io.Reader, string) {
+	t.Helper()
+	filename := fmt.Sprintf("permission-matrix-%s.csv", uuid.NewString())
+	t.Cleanup(func() { os.Remove(filepath.Join("/tmp", filename)) })
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+	header.Set("Content-Type", "text/csv")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		t.Fatalf("create part: %v", err)
+	}
+	fmt.Fprintf(part, "warehouse_id,book_id,quantity\n%s,%s,5\n", warehouseID, uuid.New())
+	w.Close()
+	return &buf, w.FormDataContentType()
+}
+
+type inventoryRouteCase struct {
+	name    string
+	method  string
+	path    func(warehouseID uuid.UUID) string
+	body    func(t *testing.T, warehouseID uuid.UUID) (io.Reader, string)
+	unowned bool // request không chỉ định kho (tạo cho mọi kho / tự chọn kho): staff bị từ chối
+}
+
+func inventoryRouteCases() []inventoryRouteCase {
+	bookID := uuid.New()
+	stockBody := func(t *testing.T, warehouseID uuid.UUID) (io.Reader, string) {
+		return jsonBody(t, map[string]interface{}{
+			"warehouse_id": warehouseID,
+			"book_id":      bookID,
+			"quantity":     1,
+			"reference_id": uuid.New(),
+		})
+	}
+	byKey := func(warehouseID uuid.UUID) string {
+		return fmt.Sprintf("/api/v1/inventories/%s/%s", warehouseID, bookID)
+	}
+	path := func(p string) func(uuid.UUID) string {
+		return func(uuid.UUID) string { return "/api/v1/inventories" + p }
+	}
+
+	return []inventoryRouteCase{
+		{name: "create", method: http.MethodPost, path: path(""), body: stockBody},
+		{name: "create all warehouses", method: http.MethodPost, path: path(""), unowned: true,
+			body: func(t *testing.T, _ uuid.UUID) (io.Reader, string) {
+				return jsonBody(t, map[string]interface{}{"book_id": bookID, "quantity": 1, "create_for_all_warehouses": true})
+			}},
+		{name: "get", method: http.MethodGet, path: byKey},
+		{name: "update", method: http.MethodPatch, path: byKey,
+			body: func(t *testing.T, _ uuid.UUID) (io.Reader, string) {
+				return jsonBody(t, map[string]interface{}{"quantity": 3, "version": 1})
+			}},
+		{name: "delete", method: http.MethodDelete, path: byKey},
+		{name: "reserve", method: http.MethodPost, path: path("/reserve"), body: stockBody},
+		{name: "reserve auto warehouse", method: http.MethodPost, path: path("/reserve"), unowned: true,
+			body: func(t *testing.T, _ uuid.UUID) (io.Reader, string) {
+				return jsonBody(t, map[string]interface{}{"book_id": bookID, "quantity": 1, "reference_id": uuid.New()})
+			}},
+		{name: "release", method: http.MethodPost, path: path("/release"), body: stockBody},
+		{name: "complete sale", method: http.MethodPost, path: path("/complete-sale"), body: stockBody},
+		{name: "adjust", method: http.MethodPost, path: path("/adjust"),
+			body: func(t *testing.T, warehouseID uuid.UUID) (io.Reader, string) {
+				return jsonBody(t, map[string]interface{}{
+					"warehouse_id": warehouseID, "book_id": bookID, "new_quantity": 1,
+					"reason": "recount after audit", "reason_category": "recount",
+				})
+			}},
+		{name: "restock", method: http.MethodPost, path: path("/restock"), body: stockBody},
+		{name: "bulk update", method: http.MethodPost, path: path("/bulk-update"), body: csvUpload},
+	}
+}
+
+func TestInventoryRoutePermissionMatrix(t *testing.T) {
+	assigned, other := uuid.New(), uuid.New()
+	router := newInventoryTestRouter(assigned)
+
+	type target struct {
+		name        string
+		warehouseID uuid.UUID
+	}
+	targets := []target{{"assigned warehouse", assigned}, {"other warehouse", other}}
+
+	// allowed(role, target, unowned) - kỳ vọng của ma trận
+	allowed := func(role rbac.Role, warehouseID uuid.UUID, unowned bool) bool {
+		switch role {
+		case rbac.RoleAdmin:
+			return true
+		case rbac.RoleWarehouseStaff:
+			return !unowned && warehouseID == assigned
+		default:
+			return false
+		}
+	}
+
+	for _, route := range inventoryRouteCases() {
+		for _, role := range []rbac.Role{rbac.RoleAdmin, rbac.RoleWarehouseStaff, rbac.RoleSupport, rbac.RoleCustomer} {
+			for _, tg := range targets {
+				t.Run(fmt.Sprintf("%s/%s/%s", route.name, role, tg.name), func(t *testing.T) {
+					var body io.Reader
+					var contentType string
+					if route.body != nil {
+						body, contentType = route.body(t, tg.warehouseID)
+					}
+					req := httptest.NewRequest(route.method, route.path(tg.warehouseID), body)
+					if contentType != "" {
+						req.Header.Set("Content-Type", contentType)
+					}
+					req.Header.Set("Authorization", "Bearer "+testToken(t, role))
+
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, req)
+
+					denied := w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden
+					if want := allowed(role, tg.warehouseID, route.unowned); want == denied {
+						t.Errorf("%s %s as %s: status %d, want allowed=%v (body %s)",
+							route.method, route.path(tg.warehouseID), role, w.Code, want, w.Body.String())
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestInventoryRoutesRequireAuth(t *testing.T) {
+	router := newInventoryTestRouter(uuid.New())
+	for _, route := range inventoryRouteCases() {
+		req := httptest.NewRequest(route.method, route.path(uuid.New()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: anonymous status %d, want 401", route.name, w.Code)
+		}
+	}
+}
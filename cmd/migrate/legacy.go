@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	orderModel "bookstore-backend/internal/domains/order/model"
+)
+
+// =====================================================
+// LEGACY EXPORT FORMAT
+// =====================================================
+// Thư mục export gồm 3 file JSON Lines (1 object / dòng), thiếu file nào thì bỏ qua phần đó:
+//   - users.jsonl:     khách hàng
+//   - addresses.jsonl: sổ địa chỉ
+//   - orders.jsonl:    đơn + dòng hàng + lịch sử trạng thái (nếu nền tảng cũ có)
+//
+// Id bên cũ giữ dạng string (nền tảng cũ dùng int / uuid đều được).
+
+const (
+	legacyUsersFile     = "users.jsonl"
+	legacyAddressesFile = "addresses.jsonl"
+	legacyOrdersFile    = "orders.jsonl"
+
+	// Dòng JSON lớn nhất (đơn nhiều dòng hàng + lịch sử dài)
+	legacyMaxLineBytes = 4 * 1024 * 1024
+)
+
+type legacyUser struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	Phone    string `json:"phone"`
+	// Chỉ giữ hash bcrypt ($2a/$2b/$2y); thuật toán khác → khách đặt lại mật khẩu
+	PasswordHash string     `json:"password_hash"`
+	IsVerified   bool       `json:"is_verified"`
+	CreatedAt    *time.Time `json:"created_at"`
+}
+
+type legacyAddress struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	RecipientName string     `json:"recipient_name"`
+	Phone         string     `json:"phone"`
+	Province      string     `json:"province"`
+	District      string     `json:"district"`
+	Ward          string     `json:"ward"`
+	Street        string     `json:"street"`
+	IsDefault     bool       `json:"is_default"`
+	Notes         string     `json:"notes"`
+	CreatedAt     *time.Time `json:"created_at"`
+}
+
+type legacyOrder struct {
+	ID          string `json:"id"`
+	OrderNumber string `json:"order_number"`
+	UserID      string `json:"user_id"`
+	// Đơn khách vãng lai: không có user_id, tra user theo email
+	CustomerEmail string `json:"customer_email"`
+	AddressID     string `json:"address_id"`
+	// Địa chỉ snapshot trên đơn, dùng khi không có address_id
+	ShippingAddress *legacyAddress `json:"shipping_address"`
+
+	Status        string `json:"status"`
+	PaymentMethod string `json:"payment_method"`
+	PaymentStatus string `json:"payment_status"`
+
+	Subtotal    decimal.Decimal `json:"subtotal"`
+	ShippingFee decimal.Decimal `json:"shipping_fee"`
+	Discount    decimal.Decimal `json:"discount"`
+	Total       decimal.Decimal `json:"total"`
+
+	TrackingNumber     string `json:"tracking_number"`
+	CustomerNote       string `json:"customer_note"`
+	CancellationReason string `json:"cancellation_reason"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	PaidAt      *time.Time `json:"paid_at"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+
+	Items   []legacyOrderItem     `json:"items"`
+	History []legacyStatusHistory `json:"history"`
+}
+
+type legacyOrderItem struct {
+	ISBN     string          `json:"isbn"`
+	Title    string          `json:"title"`
+	Quantity int             `json:"quantity"`
+	Price    decimal.Decimal `json:"price"`
+	// Rỗng → price * quantity
+	Subtotal *decimal.Decimal `json:"subtotal"`
+}
+
+type legacyStatusHistory struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+	Note   string    `json:"note"`
+}
+
+// readLegacyFile đọc từng dòng JSON, fn lỗi ở 1 dòng không dừng cả file (ghi vào báo cáo)
+// Trả os.ErrNotExist khi file không có trong thư mục export.
+func readLegacyFile(dir, name string, fn func(lineNo int, raw []byte) error) error {
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), legacyMaxLineBytes)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if err := fn(lineNo, []byte(line)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s line %d: %w", name, lineNo+1, err)
+	}
+	return nil
+}
+
+// =====================================================
+// STATUS / PAYMENT MAPPING
+// =====================================================
+// Tên trạng thái bên cũ (Woo / Magento / tự viết) → trạng thái hệ thống mới.
+// Key đã chuẩn hoá bởi normalizeLegacyValue.
+
+var legacyOrderStatuses = map[string]string{
+	"pending":          orderModel.OrderStatusPending,
+	"new":              orderModel.OrderStatusPending,
+	"on_hold":          orderModel.OrderStatusPending,
+	"awaiting_payment": orderModel.OrderStatusPending,
+	"pending_payment":  orderModel.OrderStatusPending,
+
+	"confirmed": orderModel.OrderStatusConfirmed,
+	"accepted":  orderModel.OrderStatusConfirmed,
+
+	"processing": orderModel.OrderStatusProcessing,
+	"packing":    orderModel.OrderStatusProcessing,
+	"packed":     orderModel.OrderStatusProcessing,
+
+	"shipping":   orderModel.OrderStatusShipping,
+	"shipped":    orderModel.OrderStatusShipping,
+	"in_transit": orderModel.OrderStatusShipping,
+
+	"delivered": orderModel.OrderStatusDelivered,
+	"completed": orderModel.OrderStatusDelivered,
+	"complete":  orderModel.OrderStatusDelivered,
+
+	"cancelled": orderModel.OrderStatusCancelled,
+	"canceled":  orderModel.OrderStatusCancelled,
+	"failed":    orderModel.OrderStatusCancelled,
+
+	"returned": orderModel.OrderStatusReturned,
+	"refunded": orderModel.OrderStatusReturned,
+}
+
+var legacyPaymentStatuses = map[string]string{
+	"paid":     orderModel.PaymentStatusPaid,
+	"captured": orderModel.PaymentStatusPaid,
+	"success":  orderModel.PaymentStatusPaid,
+
+	"pending": orderModel.PaymentStatusPending,
+	"unpaid":  orderModel.PaymentStatusPending,
+
+	"failed": orderModel.PaymentStatusFailed,
+
+	"refunded":           orderModel.PaymentStatusRefunded,
+	"partially_refunded": orderModel.PaymentStatusRefunded,
+}
+
+var legacyPaymentMethods = map[string]string{
+	"cod":              orderModel.PaymentMethodCOD,
+	"cash_on_delivery": orderModel.PaymentMethodCOD,
+
+	"vnpay": orderModel.PaymentMethodVNPay,
+	"momo":  orderModel.PaymentMethodMomo,
+
+	"bank_transfer": orderModel.PaymentMethodBankTransfer,
+	"bacs":          orderModel.PaymentMethodBankTransfer,
+	"transfer":      orderModel.PaymentMethodBankTransfer,
+}
+
+func normalizeLegacyValue(v string) string {
+	v = strings.ToLower(strings.TrimSpace(v))
+	return strings.NewReplacer("-", "_", " ", "_").Replace(v)
+}
+
+func mapLegacyStatus(v string) (string, error) {
+	if status, ok := legacyOrderStatuses[normalizeLegacyValue(v)]; ok {
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown order status %q", v)
+}
+
+// mapLegacyPayment payment_status rỗng → suy ra từ trạng thái đơn:
+// đơn đã giao coi như đã thu tiền (COD shipper thu), còn lại pending
+func mapLegacyPayment(method, status, orderStatus string) (string, string, error) {
+	mappedMethod, ok := legacyPaymentMethods[normalizeLegacyValue(method)]
+	if !ok {
+		return "", "", fmt.Errorf("unknown payment method %q", method)
+	}
+
+	if strings.TrimSpace(status) == "" {
+		if orderStatus == orderModel.OrderStatusDelivered {
+			return mappedMethod, orderModel.PaymentStatusPaid, nil
+		}
+		return mappedMethod, orderModel.PaymentStatusPending, nil
+	}
+	mappedStatus, ok := legacyPaymentStatuses[normalizeLegacyValue(status)]
+	if !ok {
+		return "", "", fmt.Errorf("unknown payment status %q", status)
+	}
+	return mappedMethod, mappedStatus, nil
+}
+
+// =====================================================
+// VALIDATION
+// =====================================================
+
+var errLegacyTotals = errors.New("totals mismatch")
+
+// validateLegacyTotals đối chiếu tiền trước khi ghi (làm tròn 2 số như cột NUMERIC):
+//   - subtotal dòng = price * quantity (khi export có subtotal dòng)
+//   - subtotal đơn = tổng subtotal dòng
+//   - total = subtotal + shipping_fee - discount
+//
+// Lệch → bỏ đơn, không tự sửa: sai số thường do export thiếu dòng hàng hoặc phí bên cũ
+// không có trong file (phí gói quà...), cần người kiểm tra.
+func validateLegacyTotals(o *legacyOrder) error {
+	if len(o.Items) == 0 {
+		return errors.New("order has no items")
+	}
+
+	itemsTotal := decimal.Zero
+	for i := range o.Items {
+		item := &o.Items[i]
+		if item.Quantity <= 0 {
+			return fmt.Errorf("item %d: quantity must be positive", i+1)
+		}
+		if item.Price.IsNegative() {
+			return fmt.Errorf("item %d: negative price", i+1)
+		}
+		lineTotal := item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))).Round(2)
+		if item.Subtotal != nil && !item.Subtotal.Round(2).Equal(lineTotal) {
+			return fmt.Errorf("%w: item %d subtotal %s != %s x %d",
+				errLegacyTotals, i+1, item.Subtotal.StringFixed(2), item.Price.StringFixed(2), item.Quantity)
+		}
+		itemsTotal = itemsTotal.Add(lineTotal)
+	}
+
+	if !o.Subtotal.Round(2).Equal(itemsTotal) {
+		return fmt.Errorf("%w: subtotal %s != items %s", errLegacyTotals, o.Subtotal.StringFixed(2), itemsTotal.StringFixed(2))
+	}
+	if o.ShippingFee.IsNegative() || o.Discount.IsNegative() {
+		return errors.New("shipping_fee and discount must not be negative")
+	}
+	expected := o.Subtotal.Add(o.ShippingFee).Sub(o.Discount).Round(2)
+	if !o.Total.Round(2).Equal(expected) {
+		return fmt.Errorf("%w: total %s != subtotal + shipping - discount %s", errLegacyTotals, o.Total.StringFixed(2), expected.StringFixed(2))
+	}
+	return nil
+}
+
+// =====================================================
+// STATUS HISTORY
+// =====================================================
+
+type statusHistoryEntry struct {
+	FromStatus *string
+	ToStatus   string
+	Notes      string
+	ChangedAt  time.Time
+}
+
+// buildStatusHistory dựng order_status_history cho đơn import:
+//   - Export có lịch sử: map từng mốc, sắp theo thời gian, gộp mốc trùng liên tiếp
+//     (vd on_hold → pending_payment đều thành pending)
+//   - Không có: pending lúc tạo đơn → trạng thái cuối tại mốc thời gian phù hợp nhất
+//
+// Mốc cuối luôn là trạng thái hiện tại của đơn (lịch sử bên cũ thiếu mốc cuối thì thêm vào).
+func buildStatusHistory(o *legacyOrder, finalStatus string) ([]statusHistoryEntry, error) {
+	type point struct {
+		status string
+		at     time.Time
+		note   string
+	}
+
+	points := []point{{status: orderModel.OrderStatusPending, at: o.CreatedAt, note: "Order placed on legacy platform"}}
+	if len(o.History) > 0 {
+		history := make([]legacyStatusHistory, len(o.History))
+		copy(history, o.History)
+		sort.SliceStable(history, func(i, j int) bool { return history[i].At.Before(history[j].At) })
+
+		points = points[:0]
+		for _, h := range history {
+			status, err := mapLegacyStatus(h.Status)
+			if err != nil {
+				return nil, fmt.Errorf("history: %w", err)
+			}
+			at := h.At
+			if at.IsZero() {
+				at = o.CreatedAt
+			}
+			points = append(points, point{status: status, at: at, note: h.Note})
+		}
+	}
+
+	if last := points[len(points)-1]; last.status != finalStatus {
+		points = append(points, point{status: finalStatus, at: finalStatusTime(o, finalStatus, last.at)})
+	}
+
+	entries := make([]statusHistoryEntry, 0, len(points))
+	var prev *string
+	for _, p := range points {
+		if prev != nil && *prev == p.status {
+			continue
+		}
+		notes := "Legacy import"
+		if p.note != "" {
+			notes += ": " + p.note
+		}
+		entries = append(entries, statusHistoryEntry{
+			FromStatus: prev,
+			ToStatus:   p.status,
+			Notes:      notes,
+			ChangedAt:  p.at,
+		})
+		status := p.status
+		prev = &status
+	}
+	return entries, nil
+}
+
+func finalStatusTime(o *legacyOrder, status string, notBefore time.Time) time.Time {
+	var at *time.Time
+	switch status {
+	case orderModel.OrderStatusDelivered:
+		at = o.DeliveredAt
+	case orderModel.OrderStatusCancelled:
+		at = o.CancelledAt
+	case orderModel.OrderStatusConfirmed:
+		at = o.PaidAt
+	}
+	if at == nil {
+		at = o.UpdatedAt
+	}
+	if at == nil || at.Before(notBefore) {
+		return notBefore
+	}
+	return *at
+}
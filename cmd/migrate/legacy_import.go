@@ -0,0 +1,608 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	orderModel "bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// LEGACY IMPORT
+// =====================================================
+// Thứ tự: users → addresses → orders (đơn tham chiếu khách + địa chỉ đã import).
+// Mỗi bản ghi 1 transaction: lỗi 1 đơn không rollback cả file, ghi vào báo cáo rồi đi tiếp.
+//
+// Idempotent theo id bên cũ (legacy_id_map): chạy lại cùng file / file export mới hơn
+// → bản ghi đã import bị bỏ qua (không cập nhật), chỉ bản ghi mới / lần trước lỗi được ghi.
+//
+// Không đụng tồn kho, sold_count, điểm thưởng, sổ cái thanh toán, không enqueue email:
+// đơn lịch sử đã hoàn tất bên hệ thống cũ, chỉ cần có để khách xem lại + báo cáo doanh thu.
+
+const (
+	legacyEntityUser    = "user"
+	legacyEntityAddress = "address"
+	legacyEntityOrder   = "order"
+
+	legacyChannel           = "legacy"
+	legacyOrderNumberPrefix = "LGC-"
+
+	// password_hash không phải bcrypt → compare luôn lỗi, khách dùng "quên mật khẩu" để đặt lại
+	legacyUnusablePassword = "!legacy-reset-required"
+
+	legacyProgressEvery = 500
+)
+
+type importOutcome int
+
+const (
+	outcomeCreated importOutcome = iota
+	outcomeLinked
+	outcomeSkipped
+)
+
+// txBeginner pool (chạy thật) hoặc tx ngoài (dry-run: mỗi bản ghi là 1 savepoint, cuối cùng rollback hết)
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type legacyEntityStats struct {
+	Created int `json:"created"`
+	Linked  int `json:"linked"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+func (s *legacyEntityStats) record(outcome importOutcome) {
+	switch outcome {
+	case outcomeCreated:
+		s.Created++
+	case outcomeLinked:
+		s.Linked++
+	case outcomeSkipped:
+		s.Skipped++
+	}
+}
+
+type legacyImportFailure struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	LegacyID string `json:"legacy_id,omitempty"`
+	Error    string `json:"error"`
+}
+
+type legacyImportReport struct {
+	DryRun    bool                  `json:"dry_run"`
+	Users     legacyEntityStats     `json:"users"`
+	Addresses legacyEntityStats     `json:"addresses"`
+	Orders    legacyEntityStats     `json:"orders"`
+	Failures  []legacyImportFailure `json:"failures"`
+}
+
+func (r *legacyImportReport) failed() int {
+	return r.Users.Failed + r.Addresses.Failed + r.Orders.Failed
+}
+
+type legacyBook struct {
+	ID         uuid.UUID
+	Title      string
+	Slug       string
+	CoverURL   *string
+	AuthorName *string
+}
+
+type legacyImporter struct {
+	db     txBeginner
+	report *legacyImportReport
+
+	// Cache ISBN → sách (export vài chục nghìn đơn lặp lại cùng đầu sách)
+	books map[string]*legacyBook
+}
+
+func newLegacyImporter(db txBeginner, dryRun bool) *legacyImporter {
+	return &legacyImporter{
+		db:     db,
+		report: &legacyImportReport{DryRun: dryRun},
+		books:  make(map[string]*legacyBook),
+	}
+}
+
+// Run import lần lượt 3 file; chỉ trả lỗi hệ thống (đọc file, mất kết nối), lỗi bản ghi nằm trong report
+func (im *legacyImporter) Run(ctx context.Context, dir string) (*legacyImportReport, error) {
+	steps := []struct {
+		file  string
+		stats *legacyEntityStats
+		fn    func(ctx context.Context, raw []byte) (string, importOutcome, error)
+	}{
+		{legacyUsersFile, &im.report.Users, im.importUser},
+		{legacyAddressesFile, &im.report.Addresses, im.importAddress},
+		{legacyOrdersFile, &im.report.Orders, im.importOrder},
+	}
+
+	for _, step := range steps {
+		processed := 0
+		err := readLegacyFile(dir, step.file, func(lineNo int, raw []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			legacyID, outcome, err := step.fn(ctx, raw)
+			if err != nil {
+				step.stats.Failed++
+				im.report.Failures = append(im.report.Failures, legacyImportFailure{
+					File:     step.file,
+					Line:     lineNo,
+					LegacyID: legacyID,
+					Error:    err.Error(),
+				})
+			} else {
+				step.stats.record(outcome)
+			}
+
+			processed++
+			if processed%legacyProgressEvery == 0 {
+				log.Printf("[LegacyImport] %s: %d records processed", step.file, processed)
+			}
+			return nil
+		})
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("[LegacyImport] %s not found, skipped", step.file)
+			continue
+		}
+		if err != nil {
+			return im.report, err
+		}
+		log.Printf("[LegacyImport] ✓ %s: %+v", step.file, *step.stats)
+	}
+	return im.report, nil
+}
+
+// inTx chạy fn trong transaction riêng của bản ghi (savepoint khi dry-run)
+func (im *legacyImporter) inTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := im.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = database.Rollback(ctx, tx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// =====================================================
+// USERS
+// =====================================================
+
+func (im *legacyImporter) importUser(ctx context.Context, raw []byte) (string, importOutcome, error) {
+	var u legacyUser
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return "", 0, fmt.Errorf("invalid json: %w", err)
+	}
+	if u.ID == "" {
+		return "", 0, errors.New("id is required")
+	}
+	email := strings.ToLower(strings.TrimSpace(u.Email))
+	if !strings.Contains(email, "@") {
+		return u.ID, 0, fmt.Errorf("invalid email %q", u.Email)
+	}
+
+	var outcome importOutcome
+	err := im.inTx(ctx, func(tx pgx.Tx) error {
+		if _, found, err := findLegacyID(ctx, tx, legacyEntityUser, u.ID); err != nil || found {
+			outcome = outcomeSkipped
+			return err
+		}
+
+		// Khách đã tự đăng ký lại bên mới → gắn lịch sử vào tài khoản đó
+		var existingID uuid.UUID
+		err := tx.QueryRow(ctx, `SELECT id FROM users WHERE LOWER(email) = $1`, email).Scan(&existingID)
+		if err == nil {
+			outcome = outcomeLinked
+			return saveLegacyID(ctx, tx, legacyEntityUser, u.ID, existingID, true)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("find user by email: %w", err)
+		}
+
+		fullName := strings.TrimSpace(u.FullName)
+		if fullName == "" {
+			fullName = email[:strings.Index(email, "@")]
+		}
+		passwordHash := legacyUnusablePassword
+		if isBcryptHash(u.PasswordHash) {
+			passwordHash = u.PasswordHash
+		}
+
+		userID := uuid.New()
+		_, err = tx.Exec(ctx, `
+			INSERT INTO users (id, email, password_hash, full_name, phone, role, is_active, is_verified, created_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), 'user', TRUE, $6, COALESCE($7, NOW()))
+		`, userID, email, passwordHash, fullName, strings.TrimSpace(u.Phone), u.IsVerified, u.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert user: %w", err)
+		}
+		outcome = outcomeCreated
+		return saveLegacyID(ctx, tx, legacyEntityUser, u.ID, userID, false)
+	})
+	return u.ID, outcome, err
+}
+
+// isBcryptHash golang bcrypt đọc được $2a$ / $2b$ / $2y$ (PHP password_hash)
+func isBcryptHash(hash string) bool {
+	return len(hash) == 60 && (strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$"))
+}
+
+// =====================================================
+// ADDRESSES
+// =====================================================
+
+func (im *legacyImporter) importAddress(ctx context.Context, raw []byte) (string, importOutcome, error) {
+	var a legacyAddress
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", 0, fmt.Errorf("invalid json: %w", err)
+	}
+	if a.ID == "" {
+		return "", 0, errors.New("id is required")
+	}
+
+	var outcome importOutcome
+	err := im.inTx(ctx, func(tx pgx.Tx) error {
+		if _, found, err := findLegacyID(ctx, tx, legacyEntityAddress, a.ID); err != nil || found {
+			outcome = outcomeSkipped
+			return err
+		}
+		userID, found, err := findLegacyID(ctx, tx, legacyEntityUser, a.UserID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("user %q not imported", a.UserID)
+		}
+		if _, err := insertLegacyAddress(ctx, tx, userID, a.ID, &a); err != nil {
+			return err
+		}
+		outcome = outcomeCreated
+		return nil
+	})
+	return a.ID, outcome, err
+}
+
+// insertLegacyAddress ghi địa chỉ + map (legacyID "order:<id>" với địa chỉ snapshot trên đơn)
+func insertLegacyAddress(ctx context.Context, tx pgx.Tx, userID uuid.UUID, legacyID string, a *legacyAddress) (uuid.UUID, error) {
+	fields := map[string]string{
+		"recipient_name": a.RecipientName,
+		"phone":          a.Phone,
+		"province":       a.Province,
+		"district":       a.District,
+		"ward":           a.Ward,
+		"street":         a.Street,
+	}
+	for _, name := range []string{"recipient_name", "phone", "province", "district", "ward", "street"} {
+		if strings.TrimSpace(fields[name]) == "" {
+			return uuid.Nil, fmt.Errorf("address %s is required", name)
+		}
+	}
+
+	addressID := uuid.New()
+	_, err := tx.Exec(ctx, `
+		INSERT INTO addresses (id, user_id, recipient_name, phone, province, district, ward, street, is_default, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), COALESCE($11, NOW()))
+	`, addressID, userID,
+		strings.TrimSpace(a.RecipientName), strings.TrimSpace(a.Phone),
+		strings.TrimSpace(a.Province), strings.TrimSpace(a.District),
+		strings.TrimSpace(a.Ward), strings.TrimSpace(a.Street),
+		a.IsDefault, strings.TrimSpace(a.Notes), a.CreatedAt)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("insert address: %w", err)
+	}
+	return addressID, saveLegacyID(ctx, tx, legacyEntityAddress, legacyID, addressID, false)
+}
+
+// =====================================================
+// ORDERS
+// =====================================================
+
+func (im *legacyImporter) importOrder(ctx context.Context, raw []byte) (string, importOutcome, error) {
+	var o legacyOrder
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return "", 0, fmt.Errorf("invalid json: %w", err)
+	}
+	if o.ID == "" {
+		return "", 0, errors.New("id is required")
+	}
+	if o.CreatedAt.IsZero() {
+		return o.ID, 0, errors.New("created_at is required")
+	}
+
+	// Map + kiểm tra trước khi mở transaction (lỗi dữ liệu không tốn round-trip DB)
+	status, err := mapLegacyStatus(o.Status)
+	if err != nil {
+		return o.ID, 0, err
+	}
+	paymentMethod, paymentStatus, err := mapLegacyPayment(o.PaymentMethod, o.PaymentStatus, status)
+	if err != nil {
+		return o.ID, 0, err
+	}
+	if err := validateLegacyTotals(&o); err != nil {
+		return o.ID, 0, err
+	}
+	history, err := buildStatusHistory(&o, status)
+	if err != nil {
+		return o.ID, 0, err
+	}
+
+	var outcome importOutcome
+	err = im.inTx(ctx, func(tx pgx.Tx) error {
+		if _, found, err := findLegacyID(ctx, tx, legacyEntityOrder, o.ID); err != nil || found {
+			outcome = outcomeSkipped
+			return err
+		}
+
+		// Map bị xoá tay nhưng đơn vẫn còn → ghi lại map, không tạo đơn trùng
+		var existingID uuid.UUID
+		err := tx.QueryRow(ctx,
+			`SELECT id FROM orders WHERE channel = $1 AND external_order_id = $2`,
+			legacyChannel, o.ID,
+		).Scan(&existingID)
+		if err == nil {
+			outcome = outcomeSkipped
+			return saveLegacyID(ctx, tx, legacyEntityOrder, o.ID, existingID, false)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("find existing order: %w", err)
+		}
+
+		userID, err := resolveLegacyCustomer(ctx, tx, &o)
+		if err != nil {
+			return err
+		}
+		addressID, err := resolveLegacyAddress(ctx, tx, userID, &o)
+		if err != nil {
+			return err
+		}
+		books := make([]*legacyBook, len(o.Items))
+		for i, item := range o.Items {
+			if books[i], err = im.findBook(ctx, tx, item.ISBN); err != nil {
+				return fmt.Errorf("item %d: %w", i+1, err)
+			}
+		}
+
+		if err := insertLegacyOrder(ctx, tx, &o, userID, addressID, status, paymentMethod, paymentStatus, history, books); err != nil {
+			return err
+		}
+		outcome = outcomeCreated
+		return nil
+	})
+	return o.ID, outcome, err
+}
+
+func resolveLegacyCustomer(ctx context.Context, tx pgx.Tx, o *legacyOrder) (uuid.UUID, error) {
+	if o.UserID != "" {
+		userID, found, err := findLegacyID(ctx, tx, legacyEntityUser, o.UserID)
+		if err != nil || found {
+			return userID, err
+		}
+		if o.CustomerEmail == "" {
+			return uuid.Nil, fmt.Errorf("user %q not imported", o.UserID)
+		}
+	}
+	if o.CustomerEmail == "" {
+		return uuid.Nil, errors.New("order has neither user_id nor customer_email")
+	}
+
+	var userID uuid.UUID
+	err := tx.QueryRow(ctx,
+		`SELECT id FROM users WHERE LOWER(email) = $1`,
+		strings.ToLower(strings.TrimSpace(o.CustomerEmail)),
+	).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, fmt.Errorf("customer %q not found (add guest customers to %s)", o.CustomerEmail, legacyUsersFile)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("find customer by email: %w", err)
+	}
+	return userID, nil
+}
+
+// resolveLegacyAddress địa chỉ trong sổ địa chỉ (address_id) hoặc snapshot trên đơn
+func resolveLegacyAddress(ctx context.Context, tx pgx.Tx, userID uuid.UUID, o *legacyOrder) (uuid.UUID, error) {
+	if o.AddressID != "" {
+		addressID, found, err := findLegacyID(ctx, tx, legacyEntityAddress, o.AddressID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if !found {
+			return uuid.Nil, fmt.Errorf("address %q not imported", o.AddressID)
+		}
+		var owner uuid.UUID
+		if err := tx.QueryRow(ctx, `SELECT user_id FROM addresses WHERE id = $1`, addressID).Scan(&owner); err != nil {
+			return uuid.Nil, fmt.Errorf("load address: %w", err)
+		}
+		if owner != userID {
+			return uuid.Nil, fmt.Errorf("address %q belongs to another customer", o.AddressID)
+		}
+		return addressID, nil
+	}
+
+	if o.ShippingAddress == nil {
+		return uuid.Nil, errors.New("order has neither address_id nor shipping_address")
+	}
+	snapshotID := "order:" + o.ID
+	if addressID, found, err := findLegacyID(ctx, tx, legacyEntityAddress, snapshotID); err != nil || found {
+		return addressID, err
+	}
+	return insertLegacyAddress(ctx, tx, userID, snapshotID, o.ShippingAddress)
+}
+
+// findBook theo ISBN (bỏ dấu gạch), gồm cả sách đã ẩn / xoá mềm: đơn lịch sử vẫn phải trỏ đúng sách
+func (im *legacyImporter) findBook(ctx context.Context, tx pgx.Tx, isbn string) (*legacyBook, error) {
+	key := strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(isbn))
+	if key == "" {
+		return nil, errors.New("isbn is required")
+	}
+	if book, ok := im.books[key]; ok {
+		return book, nil
+	}
+
+	book := &legacyBook{}
+	err := tx.QueryRow(ctx, `
+		SELECT b.id, b.title, b.slug, b.cover_url, a.name
+		FROM books b
+		LEFT JOIN authors a ON a.id = b.author_id
+		WHERE REPLACE(b.isbn, '-', '') = $1
+		ORDER BY b.deleted_at NULLS FIRST
+		LIMIT 1
+	`, key).Scan(&book.ID, &book.Title, &book.Slug, &book.CoverURL, &book.AuthorName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("book with isbn %q not found", isbn)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find book: %w", err)
+	}
+	im.books[key] = book
+	return book, nil
+}
+
+func insertLegacyOrder(
+	ctx context.Context,
+	tx pgx.Tx,
+	o *legacyOrder,
+	userID, addressID uuid.UUID,
+	status, paymentMethod, paymentStatus string,
+	history []statusHistoryEntry,
+	books []*legacyBook,
+) error {
+	orderNumber := o.OrderNumber
+	if orderNumber == "" {
+		orderNumber = o.ID
+	}
+	// Mốc cuối của lịch sử = lúc đơn vào trạng thái hiện tại
+	statusAt := history[len(history)-1].ChangedAt
+
+	var paidAt, deliveredAt, cancelledAt *time.Time
+	if paymentStatus == orderModel.PaymentStatusPaid || paymentStatus == orderModel.PaymentStatusRefunded {
+		paidAt = firstTime(o.PaidAt, o.DeliveredAt, &o.CreatedAt)
+	}
+	if status == orderModel.OrderStatusDelivered || status == orderModel.OrderStatusReturned {
+		deliveredAt = firstTime(o.DeliveredAt, &statusAt)
+	}
+	if status == orderModel.OrderStatusCancelled {
+		cancelledAt = firstTime(o.CancelledAt, &statusAt)
+	}
+	updatedAt := firstTime(o.UpdatedAt, &statusAt)
+
+	orderID := uuid.New()
+	_, err := tx.Exec(ctx, `
+		INSERT INTO orders (
+			id, order_number, user_id, address_id,
+			subtotal, shipping_fee, discount_amount, total,
+			payment_method, payment_status, paid_at,
+			status, tracking_number, delivered_at,
+			customer_note, admin_note, cancellation_reason, cancelled_at,
+			channel, external_order_id, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4,
+			$5, $6, $7, $8,
+			$9, $10, $11,
+			$12, NULLIF($13, ''), $14,
+			NULLIF($15, ''), $16, NULLIF($17, ''), $18,
+			$19, $20, $21, $22
+		)
+	`,
+		orderID, legacyOrderNumberPrefix+orderNumber, userID, addressID,
+		o.Subtotal.Round(2), o.ShippingFee.Round(2), o.Discount.Round(2), o.Total.Round(2),
+		paymentMethod, paymentStatus, paidAt,
+		status, strings.TrimSpace(o.TrackingNumber), deliveredAt,
+		strings.TrimSpace(o.CustomerNote), "Imported from legacy platform (order "+orderNumber+")",
+		strings.TrimSpace(o.CancellationReason), cancelledAt,
+		legacyChannel, o.ID, o.CreatedAt, updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert order: %w", err)
+	}
+
+	for i, item := range o.Items {
+		book := books[i]
+		title := strings.TrimSpace(item.Title)
+		if title == "" {
+			title = book.Title
+		}
+		lineTotal := item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))).Round(2)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO order_items (
+				order_id, book_id, book_title, book_slug, book_cover_url, author_name,
+				quantity, price, list_price, subtotal, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, $9, $10)
+		`, orderID, book.ID, title, book.Slug, book.CoverURL, book.AuthorName,
+			item.Quantity, item.Price.Round(2), lineTotal, o.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert item %d: %w", i+1, err)
+		}
+	}
+
+	for _, h := range history {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO order_status_history (order_id, from_status, to_status, notes, changed_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, orderID, h.FromStatus, h.ToStatus, h.Notes, h.ChangedAt)
+		if err != nil {
+			return fmt.Errorf("insert status history: %w", err)
+		}
+	}
+
+	return saveLegacyID(ctx, tx, legacyEntityOrder, o.ID, orderID, false)
+}
+
+// =====================================================
+// LEGACY ID MAP
+// =====================================================
+
+func findLegacyID(ctx context.Context, tx pgx.Tx, entity, legacyID string) (uuid.UUID, bool, error) {
+	if legacyID == "" {
+		return uuid.Nil, false, nil
+	}
+	var id uuid.UUID
+	err := tx.QueryRow(ctx,
+		`SELECT entity_id FROM legacy_id_map WHERE entity_type = $1 AND legacy_id = $2`,
+		entity, legacyID,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("lookup legacy %s id: %w", entity, err)
+	}
+	return id, true, nil
+}
+
+func saveLegacyID(ctx context.Context, tx pgx.Tx, entity, legacyID string, id uuid.UUID, linkedExisting bool) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO legacy_id_map (entity_type, legacy_id, entity_id, linked_existing)
+		VALUES ($1, $2, $3, $4)
+	`, entity, legacyID, id, linkedExisting)
+	if err != nil {
+		return fmt.Errorf("save legacy %s id: %w", entity, err)
+	}
+	return nil
+}
+
+func firstTime(candidates ...*time.Time) *time.Time {
+	for _, t := range candidates {
+		if t != nil && !t.IsZero() {
+			return t
+		}
+	}
+	return nil
+}
@@ -1,8 +1,133 @@
 package main
 
-import "log"
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"bookstore-backend/internal/config"
+	"bookstore-backend/internal/infrastructure/database"
+)
+
+// Schema migration chạy bằng golang-migrate (make migrate-up); binary này chứa các lệnh
+// chuyển dữ liệu 1 lần cần logic Go (map trạng thái, kiểm tra tiền...) thay vì SQL thuần.
+//
+// Usage:
+//
+//	migrate legacy-import -dir ./legacy-export [-dry-run] [-report report.json]
 func main() {
-	log.Println("Migration service is starting...")
-	// ...migration initialization code...
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "legacy-import":
+		os.Exit(runLegacyImport(os.Args[2:]))
+	case "-h", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: migrate <command> [flags]
+
+Commands:
+  legacy-import   Import users, addresses and orders from a legacy platform export
+                  (users.jsonl, addresses.jsonl, orders.jsonl); safe to re-run`)
+}
+
+func runLegacyImport(args []string) int {
+	fs := flag.NewFlagSet("legacy-import", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory containing the legacy export (*.jsonl)")
+	dryRun := fs.Bool("dry-run", false, "validate and write inside a transaction that is rolled back")
+	reportPath := fs.String("report", "", "write the JSON report (counts + failed records) to this file")
+	_ = fs.Parse(args)
+
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "-dir is required")
+		fs.Usage()
+		return 2
+	}
+	if info, err := os.Stat(*dir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "export directory %q not found\n", *dir)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbConfig, err := config.LoadDatabaseConfig()
+	if err != nil {
+		log.Printf("[LegacyImport] ❌ Load database config: %v", err)
+		return 1
+	}
+	db := database.NewPostgresDB(dbConfig)
+	connectCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	err = db.Connect(connectCtx)
+	cancel()
+	if err != nil {
+		log.Printf("[LegacyImport] ❌ Connect database: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	var beginner txBeginner = db.Pool
+	if *dryRun {
+		// Dry-run: mọi bản ghi ghi trong 1 transaction ngoài rồi rollback
+		// → kiểm tra được cả ràng buộc DB (FK, CHECK, UNIQUE) mà không để lại dữ liệu
+		outer, err := db.Pool.Begin(ctx)
+		if err != nil {
+			log.Printf("[LegacyImport] ❌ Begin dry-run transaction: %v", err)
+			return 1
+		}
+		defer outer.Rollback(context.Background())
+		beginner = outer
+		log.Println("[LegacyImport] Dry-run: no changes will be committed")
+	}
+
+	started := time.Now()
+	report, err := newLegacyImporter(beginner, *dryRun).Run(ctx, *dir)
+
+	log.Printf("[LegacyImport] Users: %+v", report.Users)
+	log.Printf("[LegacyImport] Addresses: %+v", report.Addresses)
+	log.Printf("[LegacyImport] Orders: %+v", report.Orders)
+	log.Printf("[LegacyImport] Finished in %s, %d failed records", time.Since(started).Round(time.Second), report.failed())
+
+	if *reportPath != "" {
+		if writeErr := writeLegacyReport(*reportPath, report); writeErr != nil {
+			log.Printf("[LegacyImport] ❌ Write report: %v", writeErr)
+		}
+	} else {
+		for _, f := range report.Failures {
+			log.Printf("[LegacyImport] ✗ %s:%d (id=%s): %s", f.File, f.Line, f.LegacyID, f.Error)
+		}
+	}
+
+	if err != nil {
+		log.Printf("[LegacyImport] ❌ Aborted: %v", err)
+		return 1
+	}
+	if report.failed() > 0 {
+		return 1
+	}
+	return 0
+}
+
+func writeLegacyReport(path string, report *legacyImportReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
 }
@@ -7,22 +7,25 @@ import (
 )
 
 // Config holds all configuration for the worker
+// Redis (cả topology sentinel / cluster) đọc từ config.RedisConfig của container
 type Config struct {
-	RedisAddr string
-	SMTPHost  string
-	SMTPPort  string
+	SMTPHost string
+	SMTPPort string
+
+	// MetricsAddr địa chỉ server /metrics của worker (Prometheus scrape)
+	MetricsAddr string
 }
 
 // loadConfig loads configuration from environment variables
 func loadConfig() *Config {
 	cfg := &Config{
-		RedisAddr: utils.GetEnvVariable("REDIS_HOST", "localhost:6379"),
-		SMTPHost:  utils.GetEnvVariable("SMTP_HOST", "localhost"),
-		SMTPPort:  utils.GetEnvVariable("SMTP_PORT", "1025"),
+		SMTPHost: utils.GetEnvVariable("SMTP_HOST", "localhost"),
+		SMTPPort: utils.GetEnvVariable("SMTP_PORT", "1025"),
+
+		MetricsAddr: utils.GetEnvVariable("WORKER_METRICS_ADDR", ":9091"),
 	}
 
-	log.Printf("[Config] Redis: %s, SMTP: %s:%s",
-		cfg.RedisAddr, cfg.SMTPHost, cfg.SMTPPort)
+	log.Printf("[Config] SMTP: %s:%s", cfg.SMTPHost, cfg.SMTPPort)
 
 	return cfg
 }
@@ -3,13 +3,25 @@ package main
 import (
 	"github.com/hibiken/asynq"
 
+	affiliateJob "bookstore-backend/internal/domains/affiliate/job"
+	analyticsJob "bookstore-backend/internal/domains/analytics/job"
 	bookJob "bookstore-backend/internal/domains/book/job"
 	cartJob "bookstore-backend/internal/domains/cart/job"
+	einvoiceJob "bookstore-backend/internal/domains/einvoice/job"
+	fraudJob "bookstore-backend/internal/domains/fraud/job"
 	inventoryJob "bookstore-backend/internal/domains/inventory/job"
 	notificationJob "bookstore-backend/internal/domains/notification/job"
+	orderJob "bookstore-backend/internal/domains/order/job"
+	recommendationJob "bookstore-backend/internal/domains/recommendation/job"
+	shippingJob "bookstore-backend/internal/domains/shipping/job"
+	ticketJob "bookstore-backend/internal/domains/ticket/job"
 	"bookstore-backend/internal/domains/user/job"
+	warehouseJob "bookstore-backend/internal/domains/warehouse/job"
 	"bookstore-backend/internal/infrastructure/email"
 	emailjob "bookstore-backend/internal/infrastructure/email/job"
+	"bookstore-backend/internal/infrastructure/joboutcome"
+	joboutcomeJob "bookstore-backend/internal/infrastructure/joboutcome/job"
+	"bookstore-backend/internal/infrastructure/slack"
 	"bookstore-backend/internal/shared"
 	"bookstore-backend/pkg/container"
 )
@@ -29,13 +41,39 @@ type HandlerRegistry struct {
 
 	processBookImage *bookJob.ProcessImageHandler
 	deleteBookImages *bookJob.DeleteImagesHandler
+	enrichMetadata   *bookJob.EnrichMetadataHandler
+
+	// Lịch đổi giá đến hạn (mỗi phút)
+	applyPriceSchedules *bookJob.ApplyPriceSchedulesHandler
+
+	// Sách thiếu cover / mô tả / danh mục / tồn (mỗi đêm)
+	scanBookDataQuality *bookJob.ScanDataQualityHandler
+
+	// Cảnh báo tồn thấp → email / Slack (gửi ngay + digest theo giờ)
+	dispatchLowStockAlerts *inventoryJob.DispatchLowStockAlertsHandler
+	lowStockDigest         *inventoryJob.LowStockDigestHandler
+	// Dự báo nhu cầu theo tuần (gợi ý nhập hàng), tính lại mỗi tuần
+	computeDemandForecasts *inventoryJob.ComputeDemandForecastsHandler
 
 	inventorySync          *inventoryJob.InventorySyncHandler
 	clearCart              *cartJob.ClearCartHandler
 	sendOrderConfirmation  *cartJob.SendOrderConfirmationHandler
+	sendPaymentLink        *cartJob.SendPaymentLinkHandler
 	autoReleaseReservation *cartJob.AutoReleaseReservationHandler
 	trackCheckout          *cartJob.TrackCheckoutHandler
 
+	// Chẩn đoán checkout thất bại: dọn bản ghi quá hạn giữ
+	cleanupCheckoutFailures *cartJob.CleanupCheckoutFailuresHandler
+
+	// Giỏ ẩn danh rỗng không hoạt động (chống bot tạo giỏ vô hạn)
+	cleanupAnonymousCarts *cartJob.CleanupAnonymousCartsHandler
+
+	// Nhắc giỏ user còn hàng sắp hết hạn
+	warnExpiringCarts *cartJob.WarnExpiringCartsHandler
+
+	// Đối soát checkout saga (bù trừ lại) + trả tồn đơn quá hạn thanh toán
+	reconcileCheckouts *cartJob.ReconcileCheckoutsHandler
+
 	// WHY THIS HANDLER?
 	// - Automatically removes expired/invalid promotions from carts
 	// - Runs every 3 hours with smart scheduling based on user activity
@@ -44,44 +82,117 @@ type HandlerRegistry struct {
 	sendPendingNotifications *notificationJob.SendPendingNotificationsHandler
 	cleanupOldNotifications  *notificationJob.CleanupOldNotificationsHandler // NEW
 	retryFailedDeliveries    *notificationJob.RetryFailedDeliveriesHandler
+	orderStatusPush          *notificationJob.OrderStatusPushHandler
+
+	refreshFeeds *recommendationJob.RefreshFeedsHandler
+	// Cặp sách mua cùng ("customers also bought"), tính lại mỗi đêm
+	computeCoPurchases *recommendationJob.ComputeCoPurchasesHandler
+
+	// Analytics: funnel events + session → user stitching
+	trackEvent         *analyticsJob.TrackEventHandler
+	stitchIdentity     *analyticsJob.StitchIdentityHandler
+	backfillIdentities *analyticsJob.BackfillIdentitiesHandler
+	computeFunnel      *analyticsJob.ComputeFunnelHandler
+	recordActivity     *analyticsJob.RecordActivityHandler // feed hoạt động giỏ / đơn của khách
+
+	// Fraud review: device fingerprint từ AntiBot middleware
+	logDevice *fraudJob.LogDeviceHandler
+
+	// Shipping: mua nhãn hàng loạt theo đợt xuất kho + tạo vận đơn khi đơn được xác nhận
+	generateDispatchLabels *shippingJob.GenerateDispatchLabelsHandler
+	createOrderShipment    *shippingJob.CreateOrderShipmentHandler
+
+	// E-invoice: phát hành hoá đơn điện tử qua nhà cung cấp
+	issueEInvoice *einvoiceJob.IssueEInvoiceHandler
+
+	// Warehouse: lịch ngày lễ quốc gia cho ước tính giao hàng / SLA xử lý
+	seedHolidays *warehouseJob.SeedHolidaysHandler
+
+	// Ticket: đánh dấu khiếu nại trễ SLA
+	checkTicketSLA *ticketJob.CheckSLAHandler
+
+	// Affiliate: báo cáo hoa hồng tháng
+	generateAffiliateCommissions *affiliateJob.GenerateCommissionReportsHandler
+
+	// Marketplace: đẩy tồn + giá lên sàn
+	syncMarketplaceStock      *orderJob.SyncMarketplaceStockHandler
+	reconcileMarketplaceStock *orderJob.ReconcileMarketplaceStockHandler
+	importOrders              *orderJob.ImportOrdersHandler
+	bulkUpdateOrderStatus     *orderJob.BulkUpdateOrderStatusHandler
+
+	// Idempotency-Key hết hạn (checkout, tạo đơn)
+	cleanupIdempotencyKeys *orderJob.CleanupIdempotencyKeysHandler
+
+	// Digest vận hành hằng ngày: kết quả nghiệp vụ của job (giữ hàng đã trả, email bỏ qua...)
+	sendOpsDigest *joboutcomeJob.SendOpsDigestHandler
 }
 
 // initializeHandlers creates all job handlers with their dependencies
 func initializeHandlers(c *container.Container, cfg *Config) *HandlerRegistry {
 	// Initialize services
-	emailSvc := email.NewDevEmailService(cfg.SMTPHost, cfg.SMTPPort)
+	// Bỏ qua địa chỉ đã hard bounce / complaint (email_suppressions)
+	emailSvc := email.NewSuppressingEmailService(
+		email.NewDevEmailService(cfg.SMTPHost, cfg.SMTPPort),
+		c.EmailEventRepo,
+	)
+	slackWebhook := slack.NewWebhookClient()
+
+	// Kết quả nghiệp vụ của job: Prometheus + counter theo ngày trong Redis (digest đọc lại)
+	// Cache không phải Redis → chỉ đếm Prometheus, digest báo lỗi thiếu store
+	var outcomeStore joboutcome.CounterStore
+	if store, ok := c.Cache.(joboutcome.CounterStore); ok {
+		outcomeStore = store
+	}
+	outcomes := joboutcome.NewRecorder(outcomeStore)
 
 	// Create handlers
 	return &HandlerRegistry{
 		// Email handlers
-		emailVerification: emailjob.NewEmailVerificationHandler(emailSvc),
-		resetPassword:     emailjob.NewResetPasswordEmailHandler(emailSvc),
+		// Template theo users.locale (notification_templates), fallback nội dung hardcode
+		emailVerification: emailjob.NewEmailVerificationHandler(emailSvc, c.TemplateService, outcomes),
+		resetPassword:     emailjob.NewResetPasswordEmailHandler(emailSvc, c.TemplateService, outcomes),
 
 		// Security handlers
-		securityAlert: job.NewSecurityAlertHandler(emailSvc, c.UserRepo),
+		securityAlert: job.NewSecurityAlertHandler(emailSvc, c.UserRepo, outcomes),
 		failedLogin:   job.NewFailedLoginHandler(c.Cache, c.UserRepo, c.AsynqClient),
 
 		// Maintenance handlers
 		cleanup:          job.NewCleanupExpiredTokenHandler(c.UserRepo),
 		processBookImage: bookJob.NewProcessImageHandler(c.ImageBookService),
 		deleteBookImages: bookJob.NewDeleteImagesHandler(c.ImageBookService),
+		enrichMetadata:   bookJob.NewEnrichMetadataHandler(c.MetadataService),
+
+		applyPriceSchedules: bookJob.NewApplyPriceSchedulesHandler(c.PricingService),
+		scanBookDataQuality: bookJob.NewScanDataQualityHandler(c.DataQualityService),
+
 		inventorySync: inventoryJob.NewInventorySyncHandler(
 			c.InventoryRepo,
 			c.Cache,
+			c.AsynqClient,
+			outcomes,
 		),
+		dispatchLowStockAlerts: inventoryJob.NewDispatchLowStockAlertsHandler(c.InventoryRepo, emailSvc, slackWebhook),
+		lowStockDigest:         inventoryJob.NewLowStockDigestHandler(c.InventoryRepo, emailSvc, slackWebhook),
+		computeDemandForecasts: inventoryJob.NewComputeDemandForecastsHandler(c.InventoryService),
 
 		// Cart handlers
 		clearCart:              cartJob.NewClearCartHandler(c.CartRepo),
-		sendOrderConfirmation:  cartJob.NewSendOrderConfirmationHandler(emailSvc),
-		autoReleaseReservation: cartJob.NewAutoReleaseReservationHandler(c.OrderRepo, c.InventoryService),
-		trackCheckout:          cartJob.NewTrackCheckoutHandler(),
+		sendOrderConfirmation:  cartJob.NewSendOrderConfirmationHandler(emailSvc, c.TemplateService, c.OrderRepo, outcomes),
+		sendPaymentLink:        cartJob.NewSendPaymentLinkHandler(emailSvc, c.OrderRepo, outcomes),
+		autoReleaseReservation: cartJob.NewAutoReleaseReservationHandler(c.OrderRepo, c.InventoryService, outcomes),
+		trackCheckout:          cartJob.NewTrackCheckoutHandler(c.AnalyticsService),
+
+		cleanupCheckoutFailures: cartJob.NewCleanupCheckoutFailuresHandler(c.CartRepo),
+		cleanupAnonymousCarts:   cartJob.NewCleanupAnonymousCartsHandler(c.CartRepo, c.AnonymousCartPolicy().EmptyMaxAge),
+		warnExpiringCarts:       cartJob.NewWarnExpiringCartsHandler(c.CartRepo, c.NotificationService, c.CartExpirationPolicy()),
+		reconcileCheckouts:      cartJob.NewReconcileCheckoutsHandler(c.CartService),
 
 		// WHY CART REPO + NOTIFICATION SERVICE?
 		// - Cart repo: Query carts and update them
 		// - Notification service: Create notifications when promotions removed
 		// - User info comes from JOIN query (no separate user repo needed)
 		// - Promotion validation done in model methods (no promotion service needed)
-		removeExpiredPromotions:  cartJob.NewRemoveExpiredPromotionsHandler(c.CartRepo, c.NotificationService),
+		removeExpiredPromotions:  cartJob.NewRemoveExpiredPromotionsHandler(c.CartRepo, c.NotificationService, c.AnalyticsService),
 		sendPendingNotifications: notificationJob.NewSendPendingNotificationsHandler(c.NotificationService, c.JobConfig),
 		cleanupOldNotifications: notificationJob.NewCleanupOldNotificationsHandler(
 			c.NotificationService,
@@ -91,6 +202,36 @@ func initializeHandlers(c *container.Container, cfg *Config) *HandlerRegistry {
 			c.DeliveryService,
 			c.JobConfig,
 		),
+		orderStatusPush: notificationJob.NewOrderStatusPushHandler(c.DeviceService),
+
+		refreshFeeds:       recommendationJob.NewRefreshFeedsHandler(c.RecommendService),
+		computeCoPurchases: recommendationJob.NewComputeCoPurchasesHandler(c.RecommendService),
+
+		trackEvent:         analyticsJob.NewTrackEventHandler(c.AnalyticsService),
+		stitchIdentity:     analyticsJob.NewStitchIdentityHandler(c.AnalyticsService),
+		backfillIdentities: analyticsJob.NewBackfillIdentitiesHandler(c.AnalyticsService),
+		computeFunnel:      analyticsJob.NewComputeFunnelHandler(c.AnalyticsService),
+		recordActivity:     analyticsJob.NewRecordActivityHandler(c.AnalyticsService),
+
+		logDevice: fraudJob.NewLogDeviceHandler(c.FraudService),
+
+		generateDispatchLabels: shippingJob.NewGenerateDispatchLabelsHandler(c.ShippingService),
+		createOrderShipment:    shippingJob.NewCreateOrderShipmentHandler(c.ShippingService),
+		issueEInvoice:          einvoiceJob.NewIssueEInvoiceHandler(c.EInvoiceService),
+
+		seedHolidays: warehouseJob.NewSeedHolidaysHandler(c.HolidayService),
+
+		checkTicketSLA: ticketJob.NewCheckSLAHandler(c.TicketService),
+
+		generateAffiliateCommissions: affiliateJob.NewGenerateCommissionReportsHandler(c.AffiliateService),
+
+		syncMarketplaceStock:      orderJob.NewSyncMarketplaceStockHandler(c.MarketplaceSync),
+		reconcileMarketplaceStock: orderJob.NewReconcileMarketplaceStockHandler(c.MarketplaceSync),
+		importOrders:              orderJob.NewImportOrdersHandler(c.OrderService),
+		bulkUpdateOrderStatus:     orderJob.NewBulkUpdateOrderStatusHandler(c.OrderService),
+		cleanupIdempotencyKeys:    orderJob.NewCleanupIdempotencyKeysHandler(c.IdempotencyRepo),
+
+		sendOpsDigest: joboutcomeJob.NewSendOpsDigestHandler(outcomes, emailSvc, slackWebhook, c.JobConfig),
 	}
 }
 
@@ -108,14 +249,25 @@ func (h *HandlerRegistry) RegisterHandlers(mux *asynq.ServeMux) {
 	mux.HandleFunc(shared.TypeCleanupExpiredToken, h.cleanup.ProcessTask)
 	mux.HandleFunc(shared.TypeProcessBookImage, h.processBookImage.ProcessTask)
 	mux.HandleFunc(shared.TypeDeleteBookImages, h.deleteBookImages.ProcessTask)
+	mux.HandleFunc(shared.TypeEnrichBookMetadata, h.enrichMetadata.ProcessTask)
+	mux.HandleFunc(shared.TypeApplyPriceSchedules, h.applyPriceSchedules.ProcessTask)
+	mux.HandleFunc(shared.TypeScanBookDataQuality, h.scanBookDataQuality.ProcessTask)
 	// Inventory
 	mux.HandleFunc(shared.TypeInventorySyncBookStock, h.inventorySync.ProcessTask)
+	mux.HandleFunc(shared.TypeDispatchLowStockAlerts, h.dispatchLowStockAlerts.ProcessTask)
+	mux.HandleFunc(shared.TypeSendLowStockDigest, h.lowStockDigest.ProcessTask)
+	mux.HandleFunc(shared.TypeComputeDemandForecasts, h.computeDemandForecasts.ProcessTask)
 
 	// Cart tasks
 	mux.HandleFunc(shared.TypeClearCart, h.clearCart.ProcessTask)
 	mux.HandleFunc(shared.TypeSendOrderConfirmation, h.sendOrderConfirmation.ProcessTask)
+	mux.HandleFunc(shared.TypeSendPaymentLink, h.sendPaymentLink.ProcessTask)
 	mux.HandleFunc(shared.TypeAutoReleaseReservation, h.autoReleaseReservation.ProcessTask)
 	mux.HandleFunc(shared.TypeTrackCheckout, h.trackCheckout.ProcessTask)
+	mux.HandleFunc(shared.TypeCleanupCheckoutFailures, h.cleanupCheckoutFailures.ProcessTask)
+	mux.HandleFunc(shared.TypeCleanupAnonymousCarts, h.cleanupAnonymousCarts.ProcessTask)
+	mux.HandleFunc(shared.TypeWarnExpiringCarts, h.warnExpiringCarts.ProcessTask)
+	mux.HandleFunc(shared.TypeReconcileCheckouts, h.reconcileCheckouts.ProcessTask)
 
 	// WHY REGISTER?
 	// - Maps task type to handler function
@@ -125,5 +277,46 @@ func (h *HandlerRegistry) RegisterHandlers(mux *asynq.ServeMux) {
 	mux.HandleFunc(shared.TypeSendPendingNotifications, h.sendPendingNotifications.ProcessTask)
 	mux.HandleFunc(shared.TypeCleanupOldNotifications, h.cleanupOldNotifications.ProcessTask)
 	mux.HandleFunc(shared.TypeRetryFailedDeliveries, h.retryFailedDeliveries.ProcessTask)
+	mux.HandleFunc(shared.TypeSendOrderStatusPush, h.orderStatusPush.ProcessTask)
+
+	// Personalized feed
+	mux.HandleFunc(shared.TypeRefreshFeeds, h.refreshFeeds.ProcessTask)
+	mux.HandleFunc(shared.TypeComputeCoPurchases, h.computeCoPurchases.ProcessTask)
+
+	// Analytics identity stitching
+	mux.HandleFunc(shared.TypeTrackEvent, h.trackEvent.ProcessTask)
+	mux.HandleFunc(shared.TypeRecordActivity, h.recordActivity.ProcessTask)
+	mux.HandleFunc(shared.TypeStitchIdentity, h.stitchIdentity.ProcessTask)
+	mux.HandleFunc(shared.TypeBackfillIdentities, h.backfillIdentities.ProcessTask)
+	mux.HandleFunc(shared.TypeComputeFunnel, h.computeFunnel.ProcessTask)
+
+	// Fraud review
+	mux.HandleFunc(shared.TypeLogDeviceFingerprint, h.logDevice.ProcessTask)
+
+	// Shipping labels
+	mux.HandleFunc(shared.TypeGenerateDispatchLabels, h.generateDispatchLabels.ProcessTask)
+	mux.HandleFunc(shared.TypeCreateOrderShipment, h.createOrderShipment.ProcessTask)
+
+	// E-invoice
+	mux.HandleFunc(shared.TypeIssueEInvoice, h.issueEInvoice.ProcessTask)
+
+	// Warehouse holiday calendar
+	mux.HandleFunc(shared.TypeSeedNationalHolidays, h.seedHolidays.ProcessTask)
+
+	// Ticket SLA
+	mux.HandleFunc(shared.TypeCheckTicketSLA, h.checkTicketSLA.ProcessTask)
+
+	// Affiliate commission reports
+	mux.HandleFunc(shared.TypeGenerateAffiliateCommissions, h.generateAffiliateCommissions.ProcessTask)
+
+	// Marketplace stock sync
+	mux.HandleFunc(shared.TypeMarketplaceSyncStock, h.syncMarketplaceStock.ProcessTask)
+	mux.HandleFunc(shared.TypeMarketplaceReconcileStock, h.reconcileMarketplaceStock.ProcessTask)
+	mux.HandleFunc(shared.TypeImportOrders, h.importOrders.ProcessTask)
+	mux.HandleFunc(shared.TypeBulkUpdateOrderStatus, h.bulkUpdateOrderStatus.ProcessTask)
+	mux.HandleFunc(shared.TypeCleanupIdempotencyKeys, h.cleanupIdempotencyKeys.ProcessTask)
+
+	// Ops digest
+	mux.HandleFunc(shared.TypeSendOpsDigest, h.sendOpsDigest.ProcessTask)
 
 }
@@ -2,12 +2,17 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"bookstore-backend/internal/infrastructure/outbox"
 	"bookstore-backend/pkg/container"
+	"bookstore-backend/pkg/metrics"
 )
 
 func main() {
@@ -24,28 +29,43 @@ func main() {
 	// Initialize handlers
 	handlers := initializeHandlers(c, cfg)
 
-	// Setup Asynq server
-	srv := setupAsynqServer(cfg, handlers)
+	// Setup Asynq server (Redis standalone / sentinel / cluster theo REDIS_MODE)
+	srv := setupAsynqServer(c.AsynqRedisOpt(), handlers)
 
 	// Setup scheduler
-	scheduler := setupScheduler(cfg, c.JobConfig)
+	scheduler := setupScheduler(c.AsynqRedisOpt(), c.JobConfig)
 
 	// ✅ Perform health checks and log startup
-	if err := startServices(srv, scheduler, cfg); err != nil {
+	if err := startServices(srv, scheduler, c.Config.Redis); err != nil {
 		log.Fatalf("[Startup] Health check failed: %v", err)
 	}
 
+	// Đơn quá hạn thanh toán nhưng task auto-release bị lỡ khi worker ngừng → release ngay
+	reconcileCtx, cancelReconcile := context.WithTimeout(context.Background(), 2*time.Minute)
+	reconcileDanglingReservations(reconcileCtx, c)
+	cancelReconcile()
+
+	// Outbox relay: đẩy task hậu commit (checkout, huỷ đơn) từ Postgres sang asynq
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relay := outbox.NewRelay(c.DB.Pool, c.AsynqClient, time.Second, 100)
+	go relay.Run(relayCtx)
+
+	// Prometheus scrape: queue depth, pgxpool, metric nghiệp vụ
+	metricsSrv := metrics.Serve(cfg.MetricsAddr)
+
 	// Wait for shutdown signal
-	waitForShutdown(srv, scheduler)
+	waitForShutdown(srv, scheduler, stopRelay, metricsSrv)
 }
 
-func waitForShutdown(srv *asynqServer, scheduler *asynqScheduler) {
+func waitForShutdown(srv *asynqServer, scheduler *asynqScheduler, stopRelay context.CancelFunc, metricsSrv *http.Server) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
 	log.Println("[Shutdown] Gracefully stopping...")
+	stopRelay()
 	scheduler.Shutdown()
 	srv.Shutdown()
+	metrics.Shutdown(metricsSrv)
 	log.Println("[Shutdown] ✓ Stopped")
 }
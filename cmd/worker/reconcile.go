@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	cartModel "bookstore-backend/internal/domains/cart/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/container"
+)
+
+const (
+	reconcileBatchSize = 200
+	// reconcileRetention giữ task đã xong để TaskID chặn worker khác (khởi động cùng lúc) enqueue trùng
+	reconcileRetention = time.Hour
+)
+
+// reconcileDanglingReservations đối soát giữ hàng khi worker khởi động
+//
+// WHY: task auto-release được hẹn giờ trong Redis. Worker ngừng lâu (hoặc Redis mất dữ liệu)
+// → task không chạy, đơn chưa thanh toán giữ hàng mãi. Quét đơn quá hạn thanh toán và
+// enqueue release ngay.
+//
+// Idempotent:
+//   - TaskID theo order id: worker khởi động lại / nhiều replica cùng quét → không enqueue trùng
+//   - Task gốc vẫn còn trong Redis thì cả 2 cùng chạy: handler bỏ qua đơn đã huỷ / đã paid
+//     → không release 2 lần
+func reconcileDanglingReservations(ctx context.Context, c *container.Container) {
+	now := time.Now()
+	windows := []struct {
+		methods []string
+		window  time.Duration
+	}{
+		{
+			methods: []string{orderModel.PaymentMethodVNPay, orderModel.PaymentMethodMomo, orderModel.PaymentMethodBankTransfer},
+			window:  cartModel.OnlinePaymentWindow,
+		},
+		{
+			methods: []string{orderModel.PaymentMethodPayLink},
+			window:  c.PayLinkPolicy().TTL,
+		},
+	}
+
+	enqueued, skipped := 0, 0
+	for _, w := range windows {
+		afterID := uuid.Nil
+		for {
+			orders, err := c.OrderRepo.ListUnpaidOrdersCreatedBefore(ctx, w.methods, now.Add(-w.window), afterID, reconcileBatchSize)
+			if err != nil {
+				log.Printf("[Reconcile] ❌ List expired unpaid orders failed: %v", err)
+				return
+			}
+
+			for _, o := range orders {
+				ok, err := enqueueImmediateRelease(ctx, c.AsynqClient, o)
+				if err != nil {
+					log.Printf("[Reconcile] ⚠️  Enqueue release for order %s failed: %v", o.OrderNumber, err)
+					continue
+				}
+				if ok {
+					enqueued++
+				} else {
+					skipped++
+				}
+			}
+
+			if len(orders) < reconcileBatchSize {
+				break
+			}
+			afterID = orders[len(orders)-1].ID
+		}
+	}
+
+	log.Printf("[Reconcile] ✓ Dangling reservations: %d release(s) enqueued, %d already queued", enqueued, skipped)
+}
+
+// enqueueImmediateRelease false nếu task release của đơn đã có trong queue
+func enqueueImmediateRelease(ctx context.Context, client *asynq.Client, o orderModel.UnpaidOrderRef) (bool, error) {
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeAutoReleaseReservation, cartModel.AutoReleaseReservationPayload{
+		OrderID:     o.ID,
+		OrderNumber: o.OrderNumber,
+		UserID:      o.UserID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.Enqueue(task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.MaxRetry(3),
+		asynq.TaskID("reconcile_release:"+o.ID.String()),
+		asynq.Retention(reconcileRetention),
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"bookstore-backend/internal/config"
 	"bookstore-backend/internal/infrastructure/queue"
+
+	"github.com/hibiken/asynq"
 )
 
 // asynqScheduler wraps queue.Scheduler with additional functionality
@@ -13,8 +15,8 @@ type asynqScheduler struct {
 }
 
 // setupScheduler creates and configures the scheduler
-func setupScheduler(cfg *Config, jobConfig config.JobConfig) *asynqScheduler {
-	scheduler := queue.NewScheduler(cfg.RedisAddr, jobConfig)
+func setupScheduler(redisOpt asynq.RedisConnOpt, jobConfig config.JobConfig) *asynqScheduler {
+	scheduler := queue.NewScheduler(redisOpt, jobConfig)
 
 	// Register cron jobs
 	if err := scheduler.RegisterCleanupJobs(); err != nil {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	types "bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/tracing"
 
 	"github.com/hibiken/asynq"
 )
@@ -16,14 +17,16 @@ type asynqServer struct {
 }
 
 // setupAsynqServer creates and configures the Asynq server
-func setupAsynqServer(cfg *Config, handlers *HandlerRegistry) *asynqServer {
+func setupAsynqServer(redisOpt asynq.RedisConnOpt, handlers *HandlerRegistry) *asynqServer {
 	// Create ServeMux
 	mux := asynq.NewServeMux()
+	// Span consumer cho mọi task, nối vào trace của request đã enqueue (traceparent trong header task)
+	mux.Use(tracing.AsynqMiddleware)
 
 	// Register all handlers
 	handlers.RegisterHandlers(mux)
 	srv := asynq.NewServer(
-		asynq.RedisClientOpt{Addr: cfg.RedisAddr},
+		redisOpt,
 		asynq.Config{
 			Queues: map[string]int{
 				types.QueuePayment:      10, // Ưu tiên cao nhất
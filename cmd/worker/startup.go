@@ -8,31 +8,30 @@ import (
 	"net/http"
 	"time"
 
+	"bookstore-backend/internal/config"
+	"bookstore-backend/pkg/cache"
+
 	"github.com/redis/go-redis/v9"
-	"github.com/redis/go-redis/v9/maintnotifications"
 )
 
 // HealthChecker performs startup health checks
 type HealthChecker struct {
-	redisClient *redis.Client
+	redisClient redis.UniversalClient
 }
 
 // startServices performs health checks and logs startup information
-func startServices(srv *asynqServer, scheduler *asynqScheduler, cfg *Config) error {
+func startServices(srv *asynqServer, scheduler *asynqScheduler, redisCfg config.RedisConfig) error {
 	log.Println("============================================")
 	log.Println("🚀 Bookstore Worker Starting...")
 	log.Println("============================================")
 
 	// ✅ 1. Perform Health Checks
 	checker := &HealthChecker{
-		redisClient: redis.NewClient(&redis.Options{
-			Addr: cfg.RedisAddr,
-			MaintNotificationsConfig: &maintnotifications.Config{
-				Mode: maintnotifications.ModeDisabled, // ✅ No warnings
-			},
-		}),
+		redisClient: cache.NewRedisClient(redisCfg.ClientOptions()),
 	}
+	defer checker.redisClient.Close()
 
+	log.Printf("[Startup] Redis mode: %s", redisCfg.Mode)
 	if err := checker.checkAll(); err != nil {
 		log.Printf("❌ Health check failed: %v\n", err)
 		return err
@@ -66,12 +65,12 @@ func (h *HealthChecker) checkAll() error {
 	return nil
 }
 
-// checkRedis verifies Redis connection
+// checkRedis verifies Redis connection (sentinel: master hiện tại, cluster: mọi shard)
 func (h *HealthChecker) checkRedis() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	return h.redisClient.Ping(ctx).Err()
+	return cache.PingRedis(ctx, h.redisClient)
 }
 
 // checkAsynq verifies Asynq can connect to Redis
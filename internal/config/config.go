@@ -2,27 +2,182 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"bookstore-backend/pkg/cache"
 )
 
 // Config chứa toàn bộ application configuration
 // Struct này được populate từ environment variables
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Email    EmailConfig
-	VNPay    VNPayConfig
-	Momo     MomoConfig
-	MinIO    MinIOConfig
-	Job      JobConfig
+	App       AppConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	Email     EmailConfig
+	VNPay     VNPayConfig
+	Momo      MomoConfig
+	MinIO     MinIOConfig
+	Job       JobConfig
+	Inventory InventoryConfig
+	Order     OrderConfig
+	BookMeta  BookMetadataConfig
+	Stock     StockDisplayConfig
+	AntiBot   AntiBotConfig
+	RateLimit RateLimitConfig
+	COD       CODConfig
+	Shipping  ShippingConfig
+	EInvoice  EInvoiceConfig
+	Market    MarketplaceConfig
+	Breaker   BreakerConfig
+	Sentry    SentryConfig
+	Tracing   TracingConfig
+	Cart      CartConfig
+	Push      PushConfig
+	Provider  ProviderConfig
+	Chaos     ChaosConfig
+}
+
+type CODConfig struct {
+	// true: đơn COD chỉ chuyển payment_status=paid khi file đối soát của hãng vận chuyển khớp đủ tiền
+	// false: paid ngay khi giao thành công (shipper đã thu tiền)
+	MarkPaidOnRemittance bool
+}
+
+type ShippingConfig struct {
+	// Hãng + định dạng nhãn dùng khi admin không chỉ định và cho hook trigger_shipment
+	DefaultCarrier     string
+	DefaultLabelFormat string // pdf | zpl
+	// Token + base URL từng hãng (base URL trống → URL mặc định theo SHIPPING_PROVIDER_MODE)
+	GHTKToken   string
+	GHTKBaseURL string
+	GHNToken    string
+	GHNShopID   int
+	GHNBaseURL  string
+	// Checkout báo giá phí ship với hãng mặc định (lỗi → phí cố định ORDER_SHIPPING_FEE)
+	QuoteRates bool
+	// Tạo vận đơn ngay khi đơn được xác nhận (false → kho mua nhãn tay / theo đợt)
+	CreateOnConfirm bool
+	// Token trong URL webhook trạng thái vận đơn (?token=), rỗng = tắt webhook
+	WebhookSecret string
+	// Người gửi in trên nhãn; đơn gán kho thì lấy địa chỉ kho
+	SenderName     string
+	SenderPhone    string
+	SenderStreet   string
+	SenderWard     string
+	SenderDistrict string
+	SenderProvince string
+}
+
+type EInvoiceConfig struct {
+	// Nhà cung cấp hoá đơn điện tử (misa)
+	Provider string
+	// true: dùng nhà cung cấp giả lập (dev/staging), không gửi cơ quan thuế
+	UseMock bool
+	// Mẫu số + ký hiệu đã đăng ký với cơ quan thuế
+	TemplateCode string
+	Series       string
+	// % VAT của sách (giá bán đã gồm VAT)
+	VATRate int
+	// MISA meInvoice
+	MISABaseURL  string
+	MISAAppID    string
+	MISATaxCode  string
+	MISAUsername string
+	MISAPassword string
+}
+
+type MarketplaceConfig struct {
+	// true: dùng sàn giả lập (dev/staging), không gọi service connector
+	UseMock bool
+	// Service connector sàn (ký + gọi Open API Shopee / Lazada), cùng secret ký 2 chiều
+	ConnectorBaseURL string
+	ConnectorSecret  string
+	// Số lần gọi connector tối đa / phút / kênh khi đẩy tồn (<= 0: không giới hạn)
+	SyncRequestsPerMinute int
+}
+
+type BreakerConfig struct {
+	// Storefront breaker: số lỗi DB / inventory (timeout, mất kết nối) liên tiếp thì mở
+	StorefrontFailureThreshold int
+	// Mở bao lâu (giây) trước khi cho 1 request thử; cũng là Retry-After trả cho client
+	StorefrontOpenSeconds int
+}
+
+type AntiBotConfig struct {
+	// Bật kiểm tra CAPTCHA (Turnstile) cho action rủi ro cao của khách vãng lai; tắt thì chỉ log fingerprint
+	Enabled            bool
+	TurnstileSecretKey string
+	TurnstileVerifyURL string
+	// Số request trong cửa sổ WindowSeconds trước khi bắt buộc CAPTCHA
+	AddToCartThreshold     int
+	PromoValidateThreshold int
+	TrackOrderThreshold    int
+	WindowSeconds          int
+	// Giải CAPTCHA xong được miễn kiểm tra trong khoảng này
+	PassTTLSeconds int
+}
+
+// RateLimitConfig token bucket trong Redis (chung mọi instance API)
+// Mỗi bucket: Burst request liên tiếp, nạp lại PerMinute request / phút (PerMinute <= 0: tắt bucket đó)
+type RateLimitConfig struct {
+	Enabled bool
+	// Toàn bộ /api/v1: theo IP cho khách vãng lai, theo user khi có token hợp lệ
+	PublicPerMinute int
+	PublicBurst     int
+	UserPerMinute   int
+	UserBurst       int
+	// Chặt hơn: validate / áp mã giảm giá (dò mã) và checkout / tạo đơn (bấm dồn dập)
+	PromoPerMinute    int
+	PromoBurst        int
+	CheckoutPerMinute int
+	CheckoutBurst     int
+}
+
+type BookMetadataConfig struct {
+	GoogleBooksAPIKey string // optional, không có key thì dùng quota anonymous
+}
+
+type InventoryConfig struct {
+	// |new - old| vượt ngưỡng này thì adjustment cần admin thứ 2 duyệt (<= 0: tắt)
+	AdjustmentApprovalThreshold int
+}
+type OrderConfig struct {
+	// Deadline tổng cho CreateOrder (validate + transaction), quá hạn trả ORD019 (<= 0: tắt)
+	CreateTimeoutSeconds int
+	// Khách tự sửa địa chỉ / ghi chú trong N phút sau khi đặt, quá hạn trả ORD021 (<= 0: tắt)
+	ModificationWindowMinutes int
+	// Tiền hàng (VND) tối thiểu để checkout, thiếu trả ORD008 (<= 0: không giới hạn)
+	MinOrderAmount int
+	// Phí ship cố định (VND) và ngưỡng tiền hàng được miễn phí ship (<= 0: không freeship theo ngưỡng)
+	ShippingFee           int
+	FreeShippingThreshold int
+	// Bậc giảm ship tự động "min_subtotal:percent,..." (VD: "200000:30,300000:50"), rỗng = tắt
+	ShippingDiscountTiers string
+	// Số ngày làm việc (bỏ qua ngày nghỉ của kho) từ lúc thanh toán / đặt COD tới lúc bàn giao vận chuyển (<= 0: tắt)
+	ProcessingSLADays int
+	// Đơn CSKH đặt thay khách (pay_link): trang thanh toán của frontend + số giờ giữ hàng chờ khách trả
+	PayLinkBaseURL  string
+	PayLinkTTLHours int
+}
+type StockDisplayConfig struct {
+	// available <= ngưỡng → khách thấy "Only N left" (<= 0: tắt)
+	LowStockThreshold int
+	// available > ngưỡng → số tồn trả cho khách bị cap ở ngưỡng (<= 0: hiển thị số thật)
+	MaxVisibleQuantity int
 }
 type JobConfig struct {
 	SendPendingLimit     int
 	RetryFailedLimit     int
 	CleanupRetentionDays int
+
+	// Digest vận hành hằng ngày (kết quả nghiệp vụ của background job), trống cả 2 = chỉ ghi log
+	OpsDigestEmails       []string
+	OpsDigestSlackWebhook string
 }
 
 type VNPayConfig struct {
@@ -57,6 +212,89 @@ type AppConfig struct {
 	Environment string // development, staging, production
 	Port        string
 	Version     string
+	// Deadline tổng cho 1 HTTP request, quá hạn trả 504 SYS_002 (<= 0: tắt)
+	RequestTimeoutSeconds int
+	// Listener nội bộ cho Prometheus scrape /metrics, tách khỏi port public (rỗng: tắt)
+	MetricsAddr string
+	// CIDR / IP của load balancer được tin header X-Forwarded-For / X-Real-IP (rỗng: không tin proxy nào,
+	// client IP = RemoteAddr)
+	TrustedProxies []string
+}
+
+type SentryConfig struct {
+	// Rỗng = không gửi lỗi lên Sentry (chỉ log)
+	DSN string
+	// % event lỗi được gửi (1-100); <= 0 coi như tắt (SDK hiểu SampleRate 0 là gửi hết)
+	SamplePercent int
+}
+
+type CartConfig struct {
+	// Số giỏ ẩn danh 1 IP được tạo trong AnonymousCreateWindowSeconds (<= 0: tắt quota)
+	AnonymousCreatePerIP         int
+	AnonymousCreateWindowSeconds int
+	// Giỏ ẩn danh rỗng không hoạt động quá N giờ bị job dọn
+	AnonymousEmptyMaxAgeHours int
+
+	// Hạn giỏ tính từ lần hoạt động gần nhất: giỏ khách / giỏ user (ngày)
+	GuestTTLDays int
+	UserTTLDays  int
+	// Gia hạn tối đa 1 lần / N phút (giảm UPDATE carts khi GET giỏ liên tục)
+	RenewIntervalMinutes int
+	// Nhắc giỏ user còn hàng trước khi hết hạn N giờ (0: tắt)
+	ExpiryWarningHours int
+
+	// Checkout saga: saga 'started' quá N phút coi như kẹt (phải > ORDER_CREATE_TIMEOUT_SECONDS),
+	// đơn chưa thanh toán quá hạn + N phút mà còn giữ hàng → job đối soát trả tồn
+	CheckoutSagaStaleMinutes        int
+	CheckoutHoldGraceMinutes        int
+	CheckoutCompensationMaxAttempts int
+
+	// Giữ giá wishlist khi chuyển sang giỏ (mặc định tắt)
+	WishlistPriceGuaranteeEnabled bool
+	// Sách thêm wishlist trong N giờ gần nhất mới được giữ giá; giỏ giữ giá N giờ
+	WishlistPriceWindowHours int
+	WishlistPriceHoldHours   int
+	// Chống lạm dụng: số lần / user / N ngày, số cuốn / dòng, mức giảm tối đa (%)
+	WishlistPriceMaxPerUser     int
+	WishlistPricePeriodDays     int
+	WishlistPriceMaxQuantity    int
+	WishlistPriceMaxDiscountPct int
+}
+
+type PushConfig struct {
+	// true: dùng MockPushService (chỉ log), không gọi FCM/APNs
+	UseMock bool
+	// Service account JSON của Firebase (trống: không gửi FCM)
+	FCMCredentialsFile string
+	// APNs token-based auth (.p8); thiếu KeyFile/KeyID/TeamID/Topic: không gửi APNs
+	APNsKeyFile    string
+	APNsKeyID      string
+	APNsTeamID     string
+	APNsTopic      string
+	APNsProduction bool
+}
+
+type TracingConfig struct {
+	// "" (tắt) | "log" | "otlp"
+	Exporter string
+	// Base URL OTLP/HTTP collector, vd http://otel-collector:4318
+	OTLPEndpoint string
+	ServiceName  string
+	// % root trace được ghi (0-100)
+	SamplePercent int
+}
+
+// ChaosConfig fault injection cho test resilience (checkout compensation, saga); cấm bật ở production
+type ChaosConfig struct {
+	Enabled bool
+	// Mỗi query Postgres chậm thêm N ms với xác suất %
+	DBLatencyMs      int
+	DBLatencyPercent int
+	// % lần reserve_stock trả lỗi giả
+	ReserveStockFailPercent int
+	// Mỗi lần enqueue task asynq chậm thêm N ms với xác suất %
+	EnqueueDelayMs      int
+	EnqueueDelayPercent int
 }
 
 type DatabaseConfig struct {
@@ -74,6 +312,43 @@ type RedisConfig struct {
 	Host     string
 	Password string
 	DB       int
+
+	// Topology HA: standalone (Host) | sentinel | cluster (Addrs)
+	Mode             string
+	Addrs            []string // sentinel: địa chỉ sentinel, cluster: seed nodes
+	MasterName       string
+	Username         string
+	SentinelPassword string
+
+	PoolSize        int
+	MinIdleConns    int
+	MaxRedirects    int
+	ConnMaxIdleSecs int
+}
+
+// ClientOptions options dùng chung cho cache + asynq (client, server, scheduler, inspector)
+func (r RedisConfig) ClientOptions() cache.RedisOptions {
+	addrs := r.Addrs
+	if cache.RedisMode(r.Mode) == cache.RedisModeStandalone {
+		addrs = []string{r.Host}
+	}
+	return cache.RedisOptions{
+		Mode:             cache.RedisMode(r.Mode),
+		Addrs:            addrs,
+		MasterName:       r.MasterName,
+		Username:         r.Username,
+		Password:         r.Password,
+		SentinelPassword: r.SentinelPassword,
+		DB:               r.DB,
+		PoolSize:         r.PoolSize,
+		MinIdleConns:     r.MinIdleConns,
+		MaxRetries:       3,
+		MaxRedirects:     r.MaxRedirects,
+		DialTimeout:      5 * time.Second,
+		ReadTimeout:      3 * time.Second,
+		WriteTimeout:     3 * time.Second,
+		ConnMaxIdleTime:  time.Duration(r.ConnMaxIdleSecs) * time.Second,
+	}
 }
 
 type JWTConfig struct {
@@ -83,9 +358,10 @@ type JWTConfig struct {
 }
 
 type EmailConfig struct {
-	Provider string // ses, sendgrid
-	APIKey   string
-	From     string
+	Provider      string // ses, sendgrid
+	APIKey        string
+	From          string
+	WebhookSecret string // token trong URL webhook delivery event (?token=), rỗng = tắt webhook
 }
 
 // Load đọc config từ environment variables
@@ -103,6 +379,10 @@ func Load() (*Config, error) {
 			Environment: getEnv("APP_ENV", "development"),
 			Port:        getEnv("APP_PORT", "8080"),
 			Version:     getEnv("APP_VERSION", "1.0.0"),
+
+			RequestTimeoutSeconds: getEnvInt("APP_REQUEST_TIMEOUT_SECONDS", 30),
+			MetricsAddr:           getEnv("APP_METRICS_ADDR", ":9090"),
+			TrustedProxies:        getEnvList("APP_TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -118,6 +398,16 @@ func Load() (*Config, error) {
 			Host:     getEnv("REDIS_HOST", "localhost:6379"),
 			Password: getEnv("REDIS_PASSWORD", "redispassword"),
 			DB:       getEnvInt("REDIS_DB", 0),
+
+			Mode:             strings.ToLower(getEnv("REDIS_MODE", string(cache.RedisModeStandalone))),
+			Addrs:            getEnvList("REDIS_ADDRS"),
+			MasterName:       getEnv("REDIS_MASTER_NAME", ""),
+			Username:         getEnv("REDIS_USERNAME", ""),
+			SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+			PoolSize:         getEnvInt("REDIS_POOL_SIZE", 10),
+			MinIdleConns:     getEnvInt("REDIS_MIN_IDLE_CONNS", 5),
+			MaxRedirects:     getEnvInt("REDIS_CLUSTER_MAX_REDIRECTS", 3),
+			ConnMaxIdleSecs:  getEnvInt("REDIS_CONN_MAX_IDLE_SECONDS", 300),
 		},
 		JWT: JWTConfig{
 			Secret:             getEnv("JWT_ACCESS_SECRET", "jwt_access_secret"),
@@ -125,14 +415,15 @@ func Load() (*Config, error) {
 			RefreshTokenExpiry: getEnvInt("JWT_REFRESH_EXPIRY", 72), // 3 days
 		},
 		Email: EmailConfig{
-			Provider: getEnv("EMAIL_PROVIDER", "ses"),
-			APIKey:   getEnv("EMAIL_API_KEY", ""),
-			From:     getEnv("EMAIL_FROM", "noreply@bookstore.com"),
+			Provider:      getEnv("EMAIL_PROVIDER", "ses"),
+			APIKey:        getEnv("EMAIL_API_KEY", ""),
+			From:          getEnv("EMAIL_FROM", "noreply@bookstore.com"),
+			WebhookSecret: getEnv("EMAIL_WEBHOOK_SECRET", ""),
 		},
 		VNPay: VNPayConfig{
 			TmnCode:    getEnv("VNPAY_TMN_CODE", "QIU6VGVK"),
 			HashSecret: getEnv("VNPAY_HASH_SECRET", "9GGINJLAY7SROX68AJRSQ4862SEZ11O2"),
-			APIURL:     getEnv("VNPAY_API_URL", "https://sandbox.vnpayment.vn/paymentv2"),
+			APIURL:     getEnv("VNPAY_API_URL", ""),
 			ReturnURL:  getEnv("VNPAY_RETURN_URL", "http://localhost:5173/payment/callback"),
 			IPNURL:     getEnv("VNPAY_IPN_URL", "https://quick-pandas-tease.loca.lt/api/v1/webhooks/vnpay"),
 		},
@@ -144,7 +435,7 @@ func Load() (*Config, error) {
 			PartnerCode: getEnv("MOMO_PARTNER_CODE", ""),
 			AccessKey:   getEnv("MOMO_ACCESS_KEY", ""),
 			SecretKey:   getEnv("MOMO_SECRET_KEY", ""),
-			APIURL:      getEnv("MOMO_API_URL", "https://test-payment.momo.vn"),
+			APIURL:      getEnv("MOMO_API_URL", ""),
 			ReturnURL:   getEnv("MOMO_RETURN_URL", "http://localhost:3000/payment/callback"),
 			IPNURL:      getEnv("MOMO_IPN_URL", "http://localhost:8080/api/v1/webhooks/momo"),
 		},
@@ -152,9 +443,150 @@ func Load() (*Config, error) {
 			SendPendingLimit:     getEnvInt("SEND_PENDING_LIMIT", 100),
 			RetryFailedLimit:     getEnvInt("RETRY_FAILED_LIMIT", 50),
 			CleanupRetentionDays: getEnvInt("CLEANUP_RETENTION_DAYS", 30),
+
+			OpsDigestEmails:       getEnvList("OPS_DIGEST_EMAILS"),
+			OpsDigestSlackWebhook: getEnv("OPS_DIGEST_SLACK_WEBHOOK", ""),
+		},
+		Inventory: InventoryConfig{
+			AdjustmentApprovalThreshold: getEnvInt("INVENTORY_ADJUSTMENT_APPROVAL_THRESHOLD", 100),
+		},
+		Order: OrderConfig{
+			CreateTimeoutSeconds:      getEnvInt("ORDER_CREATE_TIMEOUT_SECONDS", 10),
+			ModificationWindowMinutes: getEnvInt("ORDER_MODIFICATION_WINDOW_MINUTES", 30),
+			MinOrderAmount:            getEnvInt("ORDER_MIN_AMOUNT", 0),
+			ShippingFee:               getEnvInt("ORDER_SHIPPING_FEE", 0),
+			FreeShippingThreshold:     getEnvInt("ORDER_FREE_SHIPPING_THRESHOLD", 0),
+			ShippingDiscountTiers:     getEnv("ORDER_SHIPPING_DISCOUNT_TIERS", ""),
+			ProcessingSLADays:         getEnvInt("ORDER_PROCESSING_SLA_DAYS", 2),
+			PayLinkBaseURL:            getEnv("ORDER_PAY_LINK_BASE_URL", "https://bookstore.com/orders"),
+			PayLinkTTLHours:           getEnvInt("ORDER_PAY_LINK_TTL_HOURS", 24),
+		},
+		BookMeta: BookMetadataConfig{
+			GoogleBooksAPIKey: getEnv("GOOGLE_BOOKS_API_KEY", ""),
+		},
+		Stock: StockDisplayConfig{
+			LowStockThreshold:  getEnvInt("STOCK_DISPLAY_LOW_THRESHOLD", 5),
+			MaxVisibleQuantity: getEnvInt("STOCK_DISPLAY_MAX_VISIBLE", 50),
+		},
+		AntiBot: AntiBotConfig{
+			Enabled:                getEnvBool("ANTIBOT_ENABLED", false),
+			TurnstileSecretKey:     getEnv("TURNSTILE_SECRET_KEY", ""),
+			TurnstileVerifyURL:     getEnv("TURNSTILE_VERIFY_URL", "https://challenges.cloudflare.com/turnstile/v0/siteverify"),
+			AddToCartThreshold:     getEnvInt("ANTIBOT_ADD_TO_CART_THRESHOLD", 20),
+			PromoValidateThreshold: getEnvInt("ANTIBOT_PROMO_VALIDATE_THRESHOLD", 5),
+			TrackOrderThreshold:    getEnvInt("ANTIBOT_TRACK_ORDER_THRESHOLD", 5),
+			WindowSeconds:          getEnvInt("ANTIBOT_WINDOW_SECONDS", 60),
+			PassTTLSeconds:         getEnvInt("ANTIBOT_PASS_TTL_SECONDS", 1800),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           getEnvBool("RATE_LIMIT_ENABLED", true),
+			PublicPerMinute:   getEnvInt("RATE_LIMIT_PUBLIC_PER_MINUTE", 120),
+			PublicBurst:       getEnvInt("RATE_LIMIT_PUBLIC_BURST", 60),
+			UserPerMinute:     getEnvInt("RATE_LIMIT_USER_PER_MINUTE", 300),
+			UserBurst:         getEnvInt("RATE_LIMIT_USER_BURST", 100),
+			PromoPerMinute:    getEnvInt("RATE_LIMIT_PROMO_PER_MINUTE", 10),
+			PromoBurst:        getEnvInt("RATE_LIMIT_PROMO_BURST", 5),
+			CheckoutPerMinute: getEnvInt("RATE_LIMIT_CHECKOUT_PER_MINUTE", 6),
+			CheckoutBurst:     getEnvInt("RATE_LIMIT_CHECKOUT_BURST", 3),
+		},
+		COD: CODConfig{
+			MarkPaidOnRemittance: getEnvBool("COD_MARK_PAID_ON_REMITTANCE", false),
+		},
+		Shipping: ShippingConfig{
+			DefaultCarrier:     getEnv("SHIPPING_DEFAULT_CARRIER", "ghtk"),
+			DefaultLabelFormat: getEnv("SHIPPING_DEFAULT_LABEL_FORMAT", "pdf"),
+			GHTKToken:          getEnv("GHTK_API_TOKEN", ""),
+			GHTKBaseURL:        getEnv("GHTK_BASE_URL", ""),
+			GHNToken:           getEnv("GHN_API_TOKEN", ""),
+			GHNShopID:          getEnvInt("GHN_SHOP_ID", 0),
+			GHNBaseURL:         getEnv("GHN_BASE_URL", ""),
+			QuoteRates:         getEnvBool("SHIPPING_QUOTE_RATES", true),
+			CreateOnConfirm:    getEnvBool("SHIPPING_CREATE_ON_CONFIRM", true),
+			WebhookSecret:      getEnv("SHIPPING_WEBHOOK_SECRET", ""),
+			SenderName:         getEnv("SHIPPING_SENDER_NAME", "Bookstore"),
+			SenderPhone:        getEnv("SHIPPING_SENDER_PHONE", ""),
+			SenderStreet:       getEnv("SHIPPING_SENDER_STREET", ""),
+			SenderWard:         getEnv("SHIPPING_SENDER_WARD", ""),
+			SenderDistrict:     getEnv("SHIPPING_SENDER_DISTRICT", ""),
+			SenderProvince:     getEnv("SHIPPING_SENDER_PROVINCE", ""),
+		},
+		EInvoice: EInvoiceConfig{
+			Provider:     getEnv("EINVOICE_PROVIDER", "misa"),
+			UseMock:      getEnvBool("USE_MOCK_EINVOICE", true),
+			TemplateCode: getEnv("EINVOICE_TEMPLATE_CODE", "1"),
+			Series:       getEnv("EINVOICE_SERIES", "C25TBS"),
+			VATRate:      getEnvInt("EINVOICE_VAT_RATE", 5),
+			MISABaseURL:  getEnv("MISA_EINVOICE_BASE_URL", ""),
+			MISAAppID:    getEnv("MISA_EINVOICE_APP_ID", ""),
+			MISATaxCode:  getEnv("MISA_EINVOICE_TAX_CODE", ""),
+			MISAUsername: getEnv("MISA_EINVOICE_USERNAME", ""),
+			MISAPassword: getEnv("MISA_EINVOICE_PASSWORD", ""),
+		},
+		Market: MarketplaceConfig{
+			UseMock:               getEnvBool("USE_MOCK_MARKETPLACE", true),
+			ConnectorBaseURL:      getEnv("MARKETPLACE_CONNECTOR_BASE_URL", ""),
+			ConnectorSecret:       getEnv("MARKETPLACE_CONNECTOR_SECRET", ""),
+			SyncRequestsPerMinute: getEnvInt("MARKETPLACE_SYNC_REQUESTS_PER_MINUTE", 30),
+		},
+		Sentry: SentryConfig{
+			DSN:           getEnv("SENTRY_DSN", ""),
+			SamplePercent: getEnvInt("SENTRY_SAMPLE_PERCENT", 100),
+		},
+		Cart: CartConfig{
+			AnonymousCreatePerIP:         getEnvInt("CART_ANON_CREATE_PER_IP", 30),
+			AnonymousCreateWindowSeconds: getEnvInt("CART_ANON_CREATE_WINDOW_SECONDS", 3600),
+			AnonymousEmptyMaxAgeHours:    getEnvInt("CART_ANON_EMPTY_MAX_AGE_HOURS", 24),
+
+			GuestTTLDays:         getEnvInt("CART_GUEST_TTL_DAYS", 30),
+			UserTTLDays:          getEnvInt("CART_USER_TTL_DAYS", 90),
+			RenewIntervalMinutes: getEnvInt("CART_RENEW_INTERVAL_MINUTES", 60),
+			ExpiryWarningHours:   getEnvInt("CART_EXPIRY_WARNING_HOURS", 48),
+
+			CheckoutSagaStaleMinutes:        getEnvInt("CHECKOUT_SAGA_STALE_MINUTES", 10),
+			CheckoutHoldGraceMinutes:        getEnvInt("CHECKOUT_HOLD_GRACE_MINUTES", 15),
+			CheckoutCompensationMaxAttempts: getEnvInt("CHECKOUT_COMPENSATION_MAX_ATTEMPTS", 5),
+
+			WishlistPriceGuaranteeEnabled: getEnvBool("WISHLIST_PRICE_GUARANTEE_ENABLED", false),
+			WishlistPriceWindowHours:      getEnvInt("WISHLIST_PRICE_WINDOW_HOURS", 24),
+			WishlistPriceHoldHours:        getEnvInt("WISHLIST_PRICE_HOLD_HOURS", 24),
+			WishlistPriceMaxPerUser:       getEnvInt("WISHLIST_PRICE_MAX_PER_USER", 5),
+			WishlistPricePeriodDays:       getEnvInt("WISHLIST_PRICE_PERIOD_DAYS", 30),
+			WishlistPriceMaxQuantity:      getEnvInt("WISHLIST_PRICE_MAX_QUANTITY", 2),
+			WishlistPriceMaxDiscountPct:   getEnvInt("WISHLIST_PRICE_MAX_DISCOUNT_PERCENT", 30),
+		},
+		Push: PushConfig{
+			UseMock:            getEnvBool("USE_MOCK_PUSH", true),
+			FCMCredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
+			APNsKeyFile:        getEnv("APNS_KEY_FILE", ""),
+			APNsKeyID:          getEnv("APNS_KEY_ID", ""),
+			APNsTeamID:         getEnv("APNS_TEAM_ID", ""),
+			APNsTopic:          getEnv("APNS_TOPIC", ""),
+			APNsProduction:     getEnvBool("APNS_PRODUCTION", false),
+		},
+		Tracing: TracingConfig{
+			Exporter:      getEnv("TRACING_EXPORTER", ""),
+			OTLPEndpoint:  getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			ServiceName:   getEnv("OTEL_SERVICE_NAME", "bookstore-backend"),
+			SamplePercent: getEnvInt("TRACING_SAMPLE_PERCENT", 100),
+		},
+		Chaos: ChaosConfig{
+			Enabled:                 getEnvBool("CHAOS_ENABLED", false),
+			DBLatencyMs:             getEnvInt("CHAOS_DB_LATENCY_MS", 0),
+			DBLatencyPercent:        getEnvInt("CHAOS_DB_LATENCY_PERCENT", 100),
+			ReserveStockFailPercent: getEnvInt("CHAOS_RESERVE_STOCK_FAIL_PERCENT", 0),
+			EnqueueDelayMs:          getEnvInt("CHAOS_ENQUEUE_DELAY_MS", 0),
+			EnqueueDelayPercent:     getEnvInt("CHAOS_ENQUEUE_DELAY_PERCENT", 100),
+		},
+		Breaker: BreakerConfig{
+			StorefrontFailureThreshold: getEnvInt("STOREFRONT_BREAKER_FAILURE_THRESHOLD", 5),
+			StorefrontOpenSeconds:      getEnvInt("STOREFRONT_BREAKER_OPEN_SECONDS", 30),
 		},
 	}
 
+	// live / sandbox / fake theo APP_ENV (URL hãng để trống → URL mặc định của mode)
+	cfg.Provider = loadProviderConfig(cfg.App.Environment)
+	cfg.applyProviderEndpoints()
+
 	// Validate critical config
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -165,6 +597,33 @@ func Load() (*Config, error) {
 
 // Validate kiểm tra config có hợp lệ không
 func (c *Config) Validate() error {
+	// Cap phải lớn hơn ngưỡng low stock, nếu không "Only N left" sẽ lộ số vượt cap
+	if c.Stock.MaxVisibleQuantity > 0 && c.Stock.MaxVisibleQuantity <= c.Stock.LowStockThreshold {
+		return fmt.Errorf("STOCK_DISPLAY_MAX_VISIBLE must be greater than STOCK_DISPLAY_LOW_THRESHOLD")
+	}
+
+	if err := c.validateProviderModes(); err != nil {
+		return err
+	}
+
+	if err := c.validateChaos(); err != nil {
+		return err
+	}
+
+	if err := c.validateRedis(); err != nil {
+		return err
+	}
+
+	for _, proxy := range c.App.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("APP_TRUSTED_PROXIES: invalid CIDR or IP %q", proxy)
+		}
+	}
+
+	if c.AntiBot.Enabled && c.AntiBot.TurnstileSecretKey == "" {
+		return fmt.Errorf("TURNSTILE_SECRET_KEY must be set when ANTIBOT_ENABLED=true")
+	}
+
 	// Production environment phải có JWT secret
 	if c.App.Environment == "production" {
 		if c.JWT.Secret == "your-secret-key-change-in-production" {
@@ -186,6 +645,52 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (c *Config) validateChaos() error {
+	if !c.Chaos.Enabled {
+		return nil
+	}
+	// Fault injection làm hỏng đơn thật → chỉ dev / staging
+	if c.App.Environment == "production" {
+		return fmt.Errorf("CHAOS_ENABLED must be false in production")
+	}
+
+	percents := []struct {
+		name  string
+		value int
+	}{
+		{"CHAOS_DB_LATENCY_PERCENT", c.Chaos.DBLatencyPercent},
+		{"CHAOS_RESERVE_STOCK_FAIL_PERCENT", c.Chaos.ReserveStockFailPercent},
+		{"CHAOS_ENQUEUE_DELAY_PERCENT", c.Chaos.EnqueueDelayPercent},
+	}
+	for _, p := range percents {
+		if p.value < 0 || p.value > 100 {
+			return fmt.Errorf("%s must be between 0 and 100 (got %d)", p.name, p.value)
+		}
+	}
+	if c.Chaos.DBLatencyMs < 0 || c.Chaos.EnqueueDelayMs < 0 {
+		return fmt.Errorf("CHAOS_DB_LATENCY_MS and CHAOS_ENQUEUE_DELAY_MS must not be negative")
+	}
+	return nil
+}
+
+// validateRedis topology khớp với biến môi trường (sai cấu hình HA phải fail lúc khởi động, không phải lúc failover)
+func (c *Config) validateRedis() error {
+	if err := c.Redis.ClientOptions().Validate(); err != nil {
+		switch cache.RedisMode(c.Redis.Mode) {
+		case cache.RedisModeSentinel:
+			return fmt.Errorf("REDIS_MODE=sentinel requires REDIS_ADDRS (sentinels) and REDIS_MASTER_NAME: %w", err)
+		case cache.RedisModeCluster:
+			return fmt.Errorf("REDIS_MODE=cluster requires REDIS_ADDRS (seed nodes) and REDIS_DB=0: %w", err)
+		default:
+			return fmt.Errorf("REDIS_MODE / REDIS_HOST: %w", err)
+		}
+	}
+	if c.Redis.PoolSize <= 0 || c.Redis.MinIdleConns < 0 || c.Redis.MinIdleConns > c.Redis.PoolSize {
+		return fmt.Errorf("REDIS_POOL_SIZE must be positive and REDIS_MIN_IDLE_CONNS within [0, REDIS_POOL_SIZE]")
+	}
+	return nil
+}
+
 // Helper functions
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -206,3 +711,26 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return value
 }
+
+// getEnvList danh sách phân tách bằng dấu phẩy ("a:26379, b:26379"), bỏ phần tử rỗng
+func getEnvList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
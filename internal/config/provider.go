@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// =====================================================
+// PROVIDER MODE (thanh toán / vận chuyển)
+// =====================================================
+// - live:    endpoint production của hãng, tiền / vận đơn thật
+// - sandbox: endpoint test của hãng (VNPay sandbox, Momo test, GHN dev, GHTK staging)
+// - fake:    provider giả lập chạy trong process, kịch bản success / failure / timeout
+//            chọn cố định theo số tiền → E2E checkout trên staging không cần tài khoản hãng
+//
+// Mặc định theo APP_ENV: production → live, staging → sandbox, còn lại → fake.
+// URL cấu hình tay (VNPAY_API_URL, GHN_BASE_URL...) thắng URL mặc định của mode (trừ cổng VNPay
+// ở mode fake: luôn là FAKE_GATEWAY_URL).
+
+const (
+	ProviderModeLive    = "live"
+	ProviderModeSandbox = "sandbox"
+	ProviderModeFake    = "fake"
+)
+
+type ProviderConfig struct {
+	PaymentMode  string
+	ShippingMode string
+	// Base URL cổng VNPay giả lập (mode fake): trình duyệt được chuyển tới đây, trỏ về chính API
+	FakeGatewayURL string
+	// Kịch bản timeout: hãng giả lập treo N giây rồi mới trả lỗi
+	FakeTimeoutSeconds int
+}
+
+// Endpoint mặc định theo mode (live / sandbox), fake không gọi ra ngoài
+var providerEndpoints = map[string]map[string]string{
+	ProviderModeLive: {
+		"vnpay": "https://pay.vnpay.vn",
+		"momo":  "https://payment.momo.vn",
+		"ghn":   "https://online-gateway.ghn.vn/shiip/public-api",
+		"ghtk":  "https://services.giaohangtietkiem.vn",
+	},
+	ProviderModeSandbox: {
+		"vnpay": "https://sandbox.vnpayment.vn/paymentv2",
+		"momo":  "https://test-payment.momo.vn",
+		"ghn":   "https://dev-online-gateway.ghn.vn/shiip/public-api",
+		"ghtk":  "https://services-staging.ghtklab.com",
+	},
+}
+
+func loadProviderConfig(environment string) ProviderConfig {
+	defaultMode := ProviderModeFake
+	switch environment {
+	case "production":
+		defaultMode = ProviderModeLive
+	case "staging":
+		defaultMode = ProviderModeSandbox
+	}
+
+	// USE_MOCK_CARRIER (cũ) vẫn được tôn trọng khi chưa set SHIPPING_PROVIDER_MODE
+	shippingDefault := defaultMode
+	if os.Getenv("USE_MOCK_CARRIER") != "" {
+		if getEnvBool("USE_MOCK_CARRIER", true) {
+			shippingDefault = ProviderModeFake
+		} else if shippingDefault == ProviderModeFake {
+			shippingDefault = ProviderModeLive
+		}
+	}
+
+	return ProviderConfig{
+		PaymentMode:        getEnv("PAYMENT_PROVIDER_MODE", defaultMode),
+		ShippingMode:       getEnv("SHIPPING_PROVIDER_MODE", shippingDefault),
+		FakeGatewayURL:     getEnv("FAKE_GATEWAY_URL", "http://localhost:8080/api/v1/sandbox"),
+		FakeTimeoutSeconds: getEnvInt("FAKE_PROVIDER_TIMEOUT_SECONDS", 5),
+	}
+}
+
+// applyProviderEndpoints điền URL hãng còn trống theo mode
+func (c *Config) applyProviderEndpoints() {
+	if endpoints, ok := providerEndpoints[c.Provider.PaymentMode]; ok {
+		if c.VNPay.APIURL == "" {
+			c.VNPay.APIURL = endpoints["vnpay"]
+		}
+		if c.Momo.APIURL == "" {
+			c.Momo.APIURL = endpoints["momo"]
+		}
+	}
+	if c.Provider.PaymentMode == ProviderModeFake {
+		// Cổng giả lập ký / kiểm chữ ký như VNPay thật → vẫn cần merchant + secret (không phải của hãng)
+		c.VNPay.APIURL = c.Provider.FakeGatewayURL + "/vnpay"
+	}
+
+	if endpoints, ok := providerEndpoints[c.Provider.ShippingMode]; ok {
+		if c.Shipping.GHNBaseURL == "" {
+			c.Shipping.GHNBaseURL = endpoints["ghn"]
+		}
+		if c.Shipping.GHTKBaseURL == "" {
+			c.Shipping.GHTKBaseURL = endpoints["ghtk"]
+		}
+	}
+}
+
+func (c *Config) validateProviderModes() error {
+	modes := []struct{ name, mode string }{
+		{"PAYMENT_PROVIDER_MODE", c.Provider.PaymentMode},
+		{"SHIPPING_PROVIDER_MODE", c.Provider.ShippingMode},
+	}
+	for _, m := range modes {
+		name, mode := m.name, m.mode
+		switch mode {
+		case ProviderModeLive, ProviderModeSandbox, ProviderModeFake:
+		default:
+			return fmt.Errorf("%s must be one of live, sandbox, fake (got %q)", name, mode)
+		}
+
+		// Production không bao giờ chạy hãng test / giả lập (đơn "đã thanh toán" mà không có tiền)
+		if c.App.Environment == "production" && mode != ProviderModeLive {
+			return fmt.Errorf("%s must be live in production (got %q)", name, mode)
+		}
+	}
+	return nil
+}
@@ -115,6 +115,25 @@ func (h *AddressHandler) GetDefaultAddress(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Default address retrieved successfully", result)
 }
 
+// GetSuggestedAddress handles GET /addresses/suggested
+// Checkout dùng để điền sẵn địa chỉ khi user chưa có địa chỉ mặc định
+func (h *AddressHandler) GetSuggestedAddress(c *gin.Context) {
+	userID, err := getUserContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, err.Error(), nil)
+		return
+	}
+
+	result, err := h.service.GetSuggestedAddress(c.Request.Context(), userID)
+	if err != nil {
+		statusCode, message, code := model.GetErrorResponse(err)
+		response.Error(c, statusCode, message, code)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Suggested address retrieved successfully", result)
+}
+
 // UpdateAddress handles PUT /addresses/:id
 func (h *AddressHandler) UpdateAddress(c *gin.Context) {
 	userID, err := getUserContext(c)
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/address/model"
+	"bookstore-backend/internal/domains/address/service"
+	"bookstore-backend/internal/shared/response"
+)
+
+type ServiceabilityHandler struct {
+	service service.ServiceabilityService
+}
+
+func NewServiceabilityHandler(service service.ServiceabilityService) *ServiceabilityHandler {
+	return &ServiceabilityHandler{
+		service: service,
+	}
+}
+
+// Check handles GET /addresses/serviceability?province=&district=&carrier=&warehouse_id=
+// Public - frontend gọi trong lúc khách nhập địa chỉ
+func (h *ServiceabilityHandler) Check(c *gin.Context) {
+	var req model.ServiceabilityCheckRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+	if raw := c.Query("warehouse_id"); raw != "" {
+		warehouseID, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid warehouse ID", err.Error())
+			return
+		}
+		req.WarehouseID = &warehouseID
+	}
+
+	result, err := h.service.Check(c.Request.Context(), req)
+	if err != nil {
+		statusCode, message, code := model.GetErrorResponse(err)
+		response.Error(c, statusCode, message, code)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serviceability checked", result)
+}
+
+// ListAreas handles GET /admin/serviceable-areas
+func (h *ServiceabilityHandler) ListAreas(c *gin.Context) {
+	var filter model.ListServiceableAreasFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	areas, err := h.service.ListAreas(c.Request.Context(), filter)
+	if err != nil {
+		statusCode, message, code := model.GetErrorResponse(err)
+		response.Error(c, statusCode, message, code)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serviceable areas retrieved successfully", areas)
+}
+
+// CreateArea handles POST /admin/serviceable-areas
+func (h *ServiceabilityHandler) CreateArea(c *gin.Context) {
+	var req model.CreateServiceableAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if v, exists := c.Get("user_id"); exists {
+		if id, ok := v.(uuid.UUID); ok {
+			createdBy = &id
+		}
+	}
+
+	area, err := h.service.CreateArea(c.Request.Context(), req, createdBy)
+	if err != nil {
+		statusCode, message, code := model.GetErrorResponse(err)
+		response.Error(c, statusCode, message, code)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Serviceable area created successfully", area)
+}
+
+// UpdateArea handles PATCH /admin/serviceable-areas/:id
+func (h *ServiceabilityHandler) UpdateArea(c *gin.Context) {
+	id, ok := getServiceableAreaID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateServiceableAreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	area, err := h.service.UpdateArea(c.Request.Context(), id, req)
+	if err != nil {
+		statusCode, message, code := model.GetErrorResponse(err)
+		response.Error(c, statusCode, message, code)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serviceable area updated successfully", area)
+}
+
+// DeleteArea handles DELETE /admin/serviceable-areas/:id
+func (h *ServiceabilityHandler) DeleteArea(c *gin.Context) {
+	id, ok := getServiceableAreaID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteArea(c.Request.Context(), id); err != nil {
+		statusCode, message, code := model.GetErrorResponse(err)
+		response.Error(c, statusCode, message, code)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serviceable area deleted successfully", nil)
+}
+
+func getServiceableAreaID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid serviceable area ID", err.Error())
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -261,6 +261,38 @@ func NewDeleteAddressError(err error) *AddressError {
 	}
 }
 
+// NewAddressNotServiceable tạo error "khu vực chưa giao hàng"
+func NewAddressNotServiceable(province, district string) *AddressError {
+	return &AddressError{
+		Code:    "ADDRESS_NOT_SERVICEABLE",
+		Message: fmt.Sprintf("We do not ship to %s, %s yet", district, province),
+	}
+}
+
+// NewServiceableAreaNotFound tạo error "serviceable area not found"
+func NewServiceableAreaNotFound() *AddressError {
+	return &AddressError{
+		Code:    "SERVICEABLE_AREA_NOT_FOUND",
+		Message: "Serviceable area not found",
+	}
+}
+
+// NewServiceableAreaExists tạo error "khu vực đã khai báo"
+func NewServiceableAreaExists() *AddressError {
+	return &AddressError{
+		Code:    "SERVICEABLE_AREA_EXISTS",
+		Message: "Serviceable area already declared for this warehouse/carrier",
+	}
+}
+
+// NewInvalidServiceableArea tạo error "invalid serviceable area"
+func NewInvalidServiceableArea(message string) *AddressError {
+	return &AddressError{
+		Code:    "INVALID_SERVICEABLE_AREA",
+		Message: message,
+	}
+}
+
 // ============================================
 // ERROR CHECKING FUNCTIONS
 // ============================================
@@ -327,8 +359,14 @@ func MapErrorToHTTP(err error) (int, string, interface{}) {
 		case "INVALID_ADDRESS_ID", "INVALID_USER_ID", "INVALID_PHONE", "INVALID_RECIPIENT_NAME",
 			"INVALID_PROVINCE", "INVALID_DISTRICT", "INVALID_WARD", "INVALID_STREET", "INVALID_ADDRESS_TYPE":
 			return http.StatusBadRequest, GetErrorMessage(err), GetErrorCode(err)
-		case "USER_HAS_NO_ADDRESS":
+		case "USER_HAS_NO_ADDRESS", "SERVICEABLE_AREA_NOT_FOUND":
 			return http.StatusNotFound, GetErrorMessage(err), GetErrorCode(err)
+		case "INVALID_SERVICEABLE_AREA":
+			return http.StatusBadRequest, GetErrorMessage(err), GetErrorCode(err)
+		case "SERVICEABLE_AREA_EXISTS":
+			return http.StatusConflict, GetErrorMessage(err), GetErrorCode(err)
+		case "ADDRESS_NOT_SERVICEABLE":
+			return http.StatusUnprocessableEntity, GetErrorMessage(err), GetErrorCode(err)
 		default:
 			return http.StatusInternalServerError, GetErrorMessage(err), GetErrorCode(err)
 		}
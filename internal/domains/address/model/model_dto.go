@@ -39,12 +39,12 @@ type Address struct {
 
 // AddressCreateRequest DTO for creating a new address
 type AddressCreateRequest struct {
-	RecipientName string `json:"recipient_name" binding:"required,min=2,max=255"`
-	Phone         string `json:"phone" binding:"required"`
+	RecipientName string `json:"recipient_name" binding:"required,min=2,max=255" pii:"name"`
+	Phone         string `json:"phone" binding:"required" pii:"phone"`
 	Province      string `json:"province" binding:"required,min=1,max=100"`
-	District      string `json:"district" binding:"required,min=1,max=100"`
-	Ward          string `json:"ward" binding:"required,min=1,max=100"`
-	Street        string `json:"street" binding:"required,min=1,max=500"`
+	District      string `json:"district" binding:"required,min=1,max=100" pii:"address"`
+	Ward          string `json:"ward" binding:"required,min=1,max=100" pii:"address"`
+	Street        string `json:"street" binding:"required,min=1,max=500" pii:"address"`
 	AddressType   string `json:"address_type" binding:"required,oneof=home office other"`
 	Notes         string `json:"notes" binding:"omitempty,max=500"`
 	Longitude     string `json:"longitude" binding:"required"`
@@ -53,12 +53,12 @@ type AddressCreateRequest struct {
 
 // AddressUpdateRequest DTO for updating an address
 type AddressUpdateRequest struct {
-	RecipientName string `json:"recipient_name" binding:"omitempty,min=2,max=255"`
-	Phone         string `json:"phone" binding:"omitempty"`
+	RecipientName string `json:"recipient_name" binding:"omitempty,min=2,max=255" pii:"name"`
+	Phone         string `json:"phone" binding:"omitempty" pii:"phone"`
 	Province      string `json:"province" binding:"omitempty,min=1,max=100"`
-	District      string `json:"district" binding:"omitempty,min=1,max=100"`
-	Ward          string `json:"ward" binding:"omitempty,min=1,max=100"`
-	Street        string `json:"street" binding:"omitempty,min=1,max=500"`
+	District      string `json:"district" binding:"omitempty,min=1,max=100" pii:"address"`
+	Ward          string `json:"ward" binding:"omitempty,min=1,max=100" pii:"address"`
+	Street        string `json:"street" binding:"omitempty,min=1,max=500" pii:"address"`
 	AddressType   string `json:"address_type" binding:"omitempty,oneof=home office other"`
 	Notes         string `json:"notes" binding:"omitempty,max=500"`
 	Longitude     string `json:"longitude" binding:"required"`
@@ -69,12 +69,12 @@ type AddressUpdateRequest struct {
 type AddressResponse struct {
 	ID            uuid.UUID `json:"id"`
 	UserID        uuid.UUID `json:"user_id"`
-	RecipientName string    `json:"recipient_name"`
-	Phone         string    `json:"phone"`
+	RecipientName string    `json:"recipient_name" pii:"name"`
+	Phone         string    `json:"phone" pii:"phone"`
 	Province      string    `json:"province"`
-	District      string    `json:"district"`
-	Ward          string    `json:"ward"`
-	Street        string    `json:"street"`
+	District      string    `json:"district" pii:"address"`
+	Ward          string    `json:"ward" pii:"address"`
+	Street        string    `json:"street" pii:"address"`
 	AddressType   string    `json:"address_type"`
 	IsDefault     bool      `json:"is_default"`
 	Notes         string    `json:"notes"`
@@ -84,18 +84,30 @@ type AddressResponse struct {
 	Latitude      *string   `json:"latitude"`
 }
 
+// Nguồn của địa chỉ gợi ý khi checkout
+const (
+	AddressSuggestionDefault       = "default"        // địa chỉ mặc định user đã đặt
+	AddressSuggestionLastDelivered = "last_delivered" // địa chỉ của đơn giao thành công gần nhất
+)
+
+// SuggestedAddressResponse DTO - địa chỉ điền sẵn khi checkout
+type SuggestedAddressResponse struct {
+	Address *AddressResponse `json:"address"`
+	Source  string           `json:"source"`
+}
+
 // AddressWithUserResponse DTO - Address with user information
 type AddressWithUserResponse struct {
 	ID            uuid.UUID `json:"id"`
 	UserID        uuid.UUID `json:"user_id"`
-	UserName      string    `json:"user_name"`  // User's full name
-	UserEmail     string    `json:"user_email"` // User's email
-	RecipientName string    `json:"recipient_name"`
-	Phone         string    `json:"phone"`
+	UserName      string    `json:"user_name"`              // User's full name
+	UserEmail     string    `json:"user_email" pii:"email"` // User's email
+	RecipientName string    `json:"recipient_name" pii:"name"`
+	Phone         string    `json:"phone" pii:"phone"`
 	Province      string    `json:"province"`
-	District      string    `json:"district"`
-	Ward          string    `json:"ward"`
-	Street        string    `json:"street"`
+	District      string    `json:"district" pii:"address"`
+	Ward          string    `json:"ward" pii:"address"`
+	Street        string    `json:"street" pii:"address"`
 	AddressType   string    `json:"address_type"`
 	IsDefault     bool      `json:"is_default"`
 	Notes         string    `json:"notes"`
@@ -0,0 +1,207 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"bookstore-backend/internal/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+// ServiceableArea entity (map bảng serviceable_areas)
+// District nil = cả tỉnh; WarehouseID / Carrier nil = mọi kho / mọi hãng
+type ServiceableArea struct {
+	ID          uuid.UUID  `json:"id"`
+	Province    string     `json:"province"`
+	District    *string    `json:"district,omitempty"`
+	WarehouseID *uuid.UUID `json:"warehouse_id,omitempty"`
+	Carrier     *string    `json:"carrier,omitempty"`
+	IsActive    bool       `json:"is_active"`
+	Note        *string    `json:"note,omitempty"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Tên đã chuẩn hoá để so khớp (cột province_key / district_key)
+	ProvinceKey string  `json:"-"`
+	DistrictKey *string `json:"-"`
+}
+
+// areaPrefixes tiền tố hành chính bỏ đi khi so khớp ("TP. Hồ Chí Minh" = "Hồ Chí Minh")
+var areaPrefixes = []string{
+	"thanh pho ", "tp. ", "tp.", "tp ",
+	"tinh ",
+	"quan ", "huyen ", "thi xa ", "thi tran ",
+}
+
+// NormalizeAreaName chuẩn hoá tên tỉnh / quận: lowercase, bỏ dấu, gộp khoảng trắng, bỏ tiền tố hành chính
+func NormalizeAreaName(name string) string {
+	s := utils.RemoveDiacritics(strings.ToLower(strings.TrimSpace(name)))
+	s = strings.Join(strings.Fields(s), " ")
+	for _, prefix := range areaPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(s, prefix))
+		}
+	}
+	return s
+}
+
+// Matches rule có phủ địa chỉ không
+// warehouseID nil / carrier rỗng = caller không giới hạn kho / hãng
+func (a *ServiceableArea) Matches(provinceKey, districtKey string, warehouseID *uuid.UUID, carrier string) bool {
+	if !a.IsActive || a.ProvinceKey != provinceKey {
+		return false
+	}
+	if a.DistrictKey != nil && *a.DistrictKey != districtKey {
+		return false
+	}
+	if warehouseID != nil && a.WarehouseID != nil && *a.WarehouseID != *warehouseID {
+		return false
+	}
+	if carrier != "" && a.Carrier != nil && *a.Carrier != carrier {
+		return false
+	}
+	return true
+}
+
+// ServiceableAreas - tập rule active
+type ServiceableAreas []ServiceableArea
+
+// Check kiểm tra địa chỉ có nằm trong khu vực giao hàng
+// Chưa có rule nào → giao toàn quốc
+func (areas ServiceableAreas) Check(req ServiceabilityCheckRequest) *ServiceabilityResult {
+	result := &ServiceabilityResult{
+		Province: strings.TrimSpace(req.Province),
+		District: strings.TrimSpace(req.District),
+	}
+	if len(areas) == 0 {
+		result.Serviceable = true
+		return result
+	}
+	result.Restricted = true
+
+	provinceKey := NormalizeAreaName(req.Province)
+	districtKey := NormalizeAreaName(req.District)
+
+	anyCarrier, anyWarehouse := false, false
+	carriers := make(map[string]struct{})
+	warehouses := make(map[uuid.UUID]struct{})
+	districts := make(map[string]struct{})
+	for i := range areas {
+		if !areas[i].Matches(provinceKey, districtKey, req.WarehouseID, req.Carrier) {
+			// Khách mới nhập tỉnh, tỉnh chỉ giao 1 số quận → trả danh sách quận để frontend gợi ý
+			if districtKey == "" && areas[i].District != nil &&
+				areas[i].Matches(provinceKey, *areas[i].DistrictKey, req.WarehouseID, req.Carrier) {
+				districts[*areas[i].District] = struct{}{}
+			}
+			continue
+		}
+		result.Serviceable = true
+
+		if areas[i].Carrier == nil {
+			anyCarrier = true
+		} else {
+			carriers[*areas[i].Carrier] = struct{}{}
+		}
+		if areas[i].WarehouseID == nil {
+			anyWarehouse = true
+		} else {
+			warehouses[*areas[i].WarehouseID] = struct{}{}
+		}
+	}
+
+	if !result.Serviceable {
+		if len(districts) > 0 {
+			for d := range districts {
+				result.Districts = append(result.Districts, d)
+			}
+			sort.Strings(result.Districts)
+			result.Message = "We only ship to some districts of this province"
+			return result
+		}
+		result.Message = "We do not ship to this area yet"
+		return result
+	}
+
+	// Có rule không giới hạn → không liệt kê (nil = mọi hãng / mọi kho)
+	if !anyCarrier {
+		for c := range carriers {
+			result.Carriers = append(result.Carriers, c)
+		}
+		sort.Strings(result.Carriers)
+	}
+	if !anyWarehouse {
+		for id := range warehouses {
+			result.WarehouseIDs = append(result.WarehouseIDs, id)
+		}
+	}
+	return result
+}
+
+// ServiceabilityCheckRequest - GET /addresses/serviceability?province=&district=&carrier=&warehouse_id=
+type ServiceabilityCheckRequest struct {
+	Province    string     `form:"province" binding:"required"`
+	District    string     `form:"district"`
+	Carrier     string     `form:"carrier"`
+	WarehouseID *uuid.UUID `form:"-"` // parse từ query warehouse_id ở handler
+}
+
+// ServiceabilityResult kết quả kiểm tra khu vực giao hàng
+type ServiceabilityResult struct {
+	Serviceable bool   `json:"serviceable"`
+	Restricted  bool   `json:"restricted"` // false = chưa cấu hình khu vực, giao toàn quốc
+	Province    string `json:"province"`
+	District    string `json:"district,omitempty"`
+	// Tỉnh chỉ giao 1 số quận (khi request chưa có district)
+	Districts []string `json:"districts,omitempty"`
+	// Hãng / kho phục vụ được khu vực (rỗng = không giới hạn)
+	Carriers     []string    `json:"carriers,omitempty"`
+	WarehouseIDs []uuid.UUID `json:"warehouse_ids,omitempty"`
+	Message      string      `json:"message,omitempty"`
+}
+
+// CreateServiceableAreaRequest - POST /admin/serviceable-areas
+type CreateServiceableAreaRequest struct {
+	Province    string     `json:"province" binding:"required,max=100"`
+	District    *string    `json:"district,omitempty" binding:"omitempty,max=100"`
+	WarehouseID *uuid.UUID `json:"warehouse_id,omitempty"`
+	Carrier     *string    `json:"carrier,omitempty" binding:"omitempty,max=20"`
+	Note        *string    `json:"note,omitempty" binding:"omitempty,max=500"`
+}
+
+// Normalize trim input, chuỗi rỗng coi như không giới hạn
+func (r *CreateServiceableAreaRequest) Normalize() {
+	r.Province = strings.TrimSpace(r.Province)
+	r.District = trimOptional(r.District)
+	r.Note = trimOptional(r.Note)
+	r.Carrier = trimOptional(r.Carrier)
+	if r.Carrier != nil {
+		carrier := strings.ToLower(*r.Carrier)
+		r.Carrier = &carrier
+	}
+}
+
+// UpdateServiceableAreaRequest - PATCH /admin/serviceable-areas/:id (bật / tắt rule, sửa ghi chú)
+type UpdateServiceableAreaRequest struct {
+	IsActive *bool   `json:"is_active,omitempty"`
+	Note     *string `json:"note,omitempty" binding:"omitempty,max=500"`
+}
+
+// ListServiceableAreasFilter - GET /admin/serviceable-areas?province=&include_inactive=
+type ListServiceableAreasFilter struct {
+	Province        string `form:"province"`
+	IncludeInactive bool   `form:"include_inactive"`
+}
+
+func trimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"bookstore-backend/internal/domains/address/model"
+	"bookstore-backend/pkg/database"
 	"context"
 	"errors"
 	"strconv"
@@ -151,6 +152,39 @@ func (r *postgresRepository) GetDefaultByUserID(ctx context.Context, userID uuid
 	return &addr, nil
 }
 
+// GetLastDeliveredByUserID địa chỉ của đơn giao thành công gần nhất, còn tồn tại trong sổ địa chỉ của user
+// Dùng làm gợi ý / fallback khi user chưa đặt địa chỉ mặc định
+func (r *postgresRepository) GetLastDeliveredByUserID(ctx context.Context, userID uuid.UUID) (*model.Address, error) {
+	query := `
+        SELECT a.id, a.user_id, a.recipient_name, a.phone, a.province, a.district, a.ward, a.street, a.address_type, a.is_default, a.notes, a.latitude, a.longitude, a.created_at, a.updated_at
+        FROM orders o
+        JOIN addresses a ON a.id = o.address_id AND a.user_id = o.user_id
+        WHERE o.user_id = $1 AND o.status = 'delivered'
+        ORDER BY o.delivered_at DESC NULLS LAST, o.created_at DESC
+        LIMIT 1
+    `
+
+	row := r.pool.QueryRow(ctx, query, userID)
+
+	var addr model.Address
+	err := row.Scan(
+		&addr.ID, &addr.UserID, &addr.RecipientName, &addr.Phone,
+		&addr.Province, &addr.District, &addr.Ward, &addr.Street,
+		&addr.AddressType, &addr.IsDefault, &addr.Notes,
+		&addr.Latitude, &addr.Longitude,
+		&addr.CreatedAt, &addr.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, model.NewCreateAddressError(err)
+	}
+
+	return &addr, nil
+}
+
 // List retrieves all addresses (for admin use) (bao gồm latitude/longitude)
 func (r *postgresRepository) List(ctx context.Context, offset, limit int) ([]*model.Address, error) {
 	query := `
@@ -277,7 +311,7 @@ func (r *postgresRepository) SetDefault(ctx context.Context, addressID, userID u
 	if err != nil {
 		return model.NewUpdateAddressError(err)
 	}
-	defer tx.Rollback(ctx)
+	defer database.Rollback(ctx, tx)
 
 	// Unset all other addresses as default
 	_, err = tx.Exec(ctx, `UPDATE addresses SET is_default = false WHERE user_id = $1 AND id != $2`, userID, addressID)
@@ -21,6 +21,9 @@ type RepositoryInterface interface {
 	// GetDefaultByUserID retrieves default address for a user
 	GetDefaultByUserID(ctx context.Context, userID uuid.UUID) (*model.Address, error)
 
+	// GetLastDeliveredByUserID địa chỉ của đơn giao thành công gần nhất (nil nếu chưa có)
+	GetLastDeliveredByUserID(ctx context.Context, userID uuid.UUID) (*model.Address, error)
+
 	// List retrieves all addresses (for admin use)
 	List(ctx context.Context, offset, limit int) ([]*model.Address, error)
 
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/address/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ServiceableAreaRepository interface {
+	Create(ctx context.Context, req model.CreateServiceableAreaRequest, createdBy *uuid.UUID) (*model.ServiceableArea, error)
+	Update(ctx context.Context, id uuid.UUID, req model.UpdateServiceableAreaRequest) (*model.ServiceableArea, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter model.ListServiceableAreasFilter) ([]model.ServiceableArea, error)
+	// ListActive toàn bộ rule đang bật (bảng nhỏ, service cache lại)
+	ListActive(ctx context.Context) (model.ServiceableAreas, error)
+}
+
+type serviceableAreaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewServiceableAreaRepository(pool *pgxpool.Pool) ServiceableAreaRepository {
+	return &serviceableAreaRepository{pool: pool}
+}
+
+const serviceableAreaColumns = `id, province, province_key, district, district_key, warehouse_id, carrier, is_active, note, created_by, created_at, updated_at`
+
+func scanServiceableArea(row pgx.Row) (*model.ServiceableArea, error) {
+	var a model.ServiceableArea
+	err := row.Scan(
+		&a.ID,
+		&a.Province,
+		&a.ProvinceKey,
+		&a.District,
+		&a.DistrictKey,
+		&a.WarehouseID,
+		&a.Carrier,
+		&a.IsActive,
+		&a.Note,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *serviceableAreaRepository) Create(ctx context.Context, req model.CreateServiceableAreaRequest, createdBy *uuid.UUID) (*model.ServiceableArea, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	var districtKey *string
+	if req.District != nil {
+		key := model.NormalizeAreaName(*req.District)
+		districtKey = &key
+	}
+
+	query := `
+		INSERT INTO serviceable_areas (province, province_key, district, district_key, warehouse_id, carrier, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + serviceableAreaColumns
+
+	area, err := scanServiceableArea(r.pool.QueryRow(ctx, query,
+		req.Province,
+		model.NormalizeAreaName(req.Province),
+		req.District,
+		districtKey,
+		req.WarehouseID,
+		req.Carrier,
+		req.Note,
+		createdBy,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // uq_serviceable_areas_scope
+				return nil, model.NewServiceableAreaExists()
+			case "23503": // warehouse_id không tồn tại
+				return nil, model.NewInvalidServiceableArea("Warehouse not found")
+			}
+		}
+		return nil, fmt.Errorf("failed to create serviceable area: %w", err)
+	}
+	return area, nil
+}
+
+func (r *serviceableAreaRepository) Update(ctx context.Context, id uuid.UUID, req model.UpdateServiceableAreaRequest) (*model.ServiceableArea, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE serviceable_areas
+		SET is_active = COALESCE($2, is_active),
+		    note = COALESCE($3, note),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + serviceableAreaColumns
+
+	area, err := scanServiceableArea(r.pool.QueryRow(ctx, query, id, req.IsActive, req.Note))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NewServiceableAreaNotFound()
+		}
+		return nil, fmt.Errorf("failed to update serviceable area: %w", err)
+	}
+	return area, nil
+}
+
+func (r *serviceableAreaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM serviceable_areas WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete serviceable area: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return model.NewServiceableAreaNotFound()
+	}
+	return nil
+}
+
+func (r *serviceableAreaRepository) List(ctx context.Context, filter model.ListServiceableAreasFilter) ([]model.ServiceableArea, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	where := []string{"1=1"}
+	args := []interface{}{}
+	if !filter.IncludeInactive {
+		where = append(where, "is_active = TRUE")
+	}
+	if filter.Province != "" {
+		args = append(args, model.NormalizeAreaName(filter.Province))
+		where = append(where, fmt.Sprintf("province_key = $%d", len(args)))
+	}
+
+	query := `SELECT ` + serviceableAreaColumns + ` FROM serviceable_areas WHERE ` +
+		strings.Join(where, " AND ") + ` ORDER BY province_key, district_key NULLS FIRST, created_at`
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *serviceableAreaRepository) ListActive(ctx context.Context) (model.ServiceableAreas, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + serviceableAreaColumns + ` FROM serviceable_areas WHERE is_active = TRUE`
+	return r.query(ctx, query)
+}
+
+func (r *serviceableAreaRepository) query(ctx context.Context, query string, args ...interface{}) ([]model.ServiceableArea, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list serviceable areas: %w", err)
+	}
+	defer rows.Close()
+
+	areas := make([]model.ServiceableArea, 0)
+	for rows.Next() {
+		area, err := scanServiceableArea(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan serviceable area: %w", err)
+		}
+		areas = append(areas, *area)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate serviceable areas: %w", err)
+	}
+	return areas, nil
+}
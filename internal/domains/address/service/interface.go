@@ -21,6 +21,9 @@ type ServiceInterface interface {
 	// GetDefaultAddress retrieves default address for a user
 	GetDefaultAddress(ctx context.Context, userID uuid.UUID) (*model.AddressResponse, error)
 
+	// GetSuggestedAddress địa chỉ gợi ý khi checkout: mặc định, không có thì địa chỉ giao thành công gần nhất
+	GetSuggestedAddress(ctx context.Context, userID uuid.UUID) (*model.SuggestedAddressResponse, error)
+
 	// UpdateAddress updates an address
 	UpdateAddress(ctx context.Context, userID, addressID uuid.UUID, req *model.AddressUpdateRequest) (*model.AddressResponse, error)
 
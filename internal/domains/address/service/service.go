@@ -13,12 +13,14 @@ import (
 )
 
 type addressService struct {
-	repo repo.RepositoryInterface
+	repo           repo.RepositoryInterface
+	serviceability ServiceabilityService
 }
 
-func NewAddressService(repo repo.RepositoryInterface) ServiceInterface {
+func NewAddressService(repo repo.RepositoryInterface, serviceability ServiceabilityService) ServiceInterface {
 	return &addressService{
-		repo: repo,
+		repo:           repo,
+		serviceability: serviceability,
 	}
 }
 
@@ -29,6 +31,11 @@ func (s *addressService) CreateAddress(ctx context.Context, userID uuid.UUID, re
 		return nil, err
 	}
 
+	// Chặn địa chỉ ngoài khu vực giao hàng ngay từ lúc lưu
+	if err := s.serviceability.ValidateAddress(ctx, req.Province, req.District); err != nil {
+		return nil, err
+	}
+
 	// Parse latitude/longitude từ string sang float64
 	lat, err := strconv.ParseFloat(strings.TrimSpace(req.Latitude), 64)
 	if err != nil {
@@ -117,6 +124,34 @@ func (s *addressService) GetDefaultAddress(ctx context.Context, userID uuid.UUID
 	return s.modelToResponse(addr), nil
 }
 
+// GetSuggestedAddress địa chỉ điền sẵn khi checkout
+// User chưa đặt mặc định → gợi ý địa chỉ đã giao thành công gần nhất thay vì bắt nhập lại
+func (s *addressService) GetSuggestedAddress(ctx context.Context, userID uuid.UUID) (*model.SuggestedAddressResponse, error) {
+	addr, err := s.repo.GetDefaultByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if addr != nil {
+		return &model.SuggestedAddressResponse{
+			Address: s.modelToResponse(addr),
+			Source:  model.AddressSuggestionDefault,
+		}, nil
+	}
+
+	addr, err = s.repo.GetLastDeliveredByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if addr == nil {
+		return nil, model.NewAddressNotFound()
+	}
+
+	return &model.SuggestedAddressResponse{
+		Address: s.modelToResponse(addr),
+		Source:  model.AddressSuggestionLastDelivered,
+	}, nil
+}
+
 // UpdateAddress - Optimized version (bao gồm latitude/longitude)
 func (s *addressService) UpdateAddress(ctx context.Context, userID, addressID uuid.UUID, req *model.AddressUpdateRequest) (*model.AddressResponse, error) {
 	// Validate request (includes nil check)
@@ -144,6 +179,11 @@ func (s *addressService) UpdateAddress(ctx context.Context, userID, addressID uu
 	// Merge request with existing values
 	updateAddr := s.mergeAddressUpdate(req, existing, lat, lon)
 
+	// Kiểm tra trên tỉnh / quận sau khi merge (request có thể chỉ đổi 1 trong 2)
+	if err := s.serviceability.ValidateAddress(ctx, updateAddr.Province, updateAddr.District); err != nil {
+		return nil, err
+	}
+
 	// Update in repository
 	updatedAddr, err := s.repo.Update(ctx, addressID, updateAddr)
 	if err != nil {
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/address/model"
+	repo "bookstore-backend/internal/domains/address/repository"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceabilityService quản lý khu vực giao hàng và kiểm tra địa chỉ
+type ServiceabilityService interface {
+	// CRUD admin
+	CreateArea(ctx context.Context, req model.CreateServiceableAreaRequest, createdBy *uuid.UUID) (*model.ServiceableArea, error)
+	UpdateArea(ctx context.Context, id uuid.UUID, req model.UpdateServiceableAreaRequest) (*model.ServiceableArea, error)
+	DeleteArea(ctx context.Context, id uuid.UUID) error
+	ListAreas(ctx context.Context, filter model.ListServiceableAreasFilter) ([]model.ServiceableArea, error)
+
+	// Check kiểm tra khu vực (frontend gọi trong lúc khách nhập địa chỉ)
+	Check(ctx context.Context, req model.ServiceabilityCheckRequest) (*model.ServiceabilityResult, error)
+
+	// ValidateAddress trả ADDRESS_NOT_SERVICEABLE nếu địa chỉ ngoài khu vực giao hàng
+	ValidateAddress(ctx context.Context, province, district string) error
+}
+
+// Rule ít thay đổi, Check được gọi liên tục khi khách gõ → cache toàn bộ rule active
+const serviceableAreaCacheTTL = 5 * time.Minute
+
+type serviceabilityService struct {
+	repo repo.ServiceableAreaRepository
+
+	mu       sync.RWMutex
+	active   model.ServiceableAreas
+	loadedAt time.Time
+}
+
+func NewServiceabilityService(repo repo.ServiceableAreaRepository) ServiceabilityService {
+	return &serviceabilityService{repo: repo}
+}
+
+func (s *serviceabilityService) CreateArea(ctx context.Context, req model.CreateServiceableAreaRequest, createdBy *uuid.UUID) (*model.ServiceableArea, error) {
+	req.Normalize()
+	if model.NormalizeAreaName(req.Province) == "" {
+		return nil, model.NewInvalidServiceableArea("Province is required")
+	}
+
+	area, err := s.repo.Create(ctx, req, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	logger.Info("Serviceable area created", map[string]interface{}{
+		"area_id":  area.ID.String(),
+		"province": area.Province,
+	})
+	return area, nil
+}
+
+func (s *serviceabilityService) UpdateArea(ctx context.Context, id uuid.UUID, req model.UpdateServiceableAreaRequest) (*model.ServiceableArea, error) {
+	area, err := s.repo.Update(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return area, nil
+}
+
+func (s *serviceabilityService) DeleteArea(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *serviceabilityService) ListAreas(ctx context.Context, filter model.ListServiceableAreasFilter) ([]model.ServiceableArea, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *serviceabilityService) Check(ctx context.Context, req model.ServiceabilityCheckRequest) (*model.ServiceabilityResult, error) {
+	areas, err := s.activeAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return areas.Check(req), nil
+}
+
+func (s *serviceabilityService) ValidateAddress(ctx context.Context, province, district string) error {
+	result, err := s.Check(ctx, model.ServiceabilityCheckRequest{Province: province, District: district})
+	if err != nil {
+		return err
+	}
+	if !result.Serviceable {
+		return model.NewAddressNotServiceable(province, district)
+	}
+	return nil
+}
+
+// activeAreas rule active, reload khi hết TTL
+// Slice trả về là snapshot read-only, caller không được sửa
+func (s *serviceabilityService) activeAreas(ctx context.Context) (model.ServiceableAreas, error) {
+	s.mu.RLock()
+	if s.active != nil && time.Since(s.loadedAt) < serviceableAreaCacheTTL {
+		areas := s.active
+		s.mu.RUnlock()
+		return areas, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != nil && time.Since(s.loadedAt) < serviceableAreaCacheTTL {
+		return s.active, nil
+	}
+
+	areas, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.active = areas
+	s.loadedAt = time.Now()
+	return areas, nil
+}
+
+// invalidate buộc lần kiểm tra tiếp theo reload rule từ DB (gọi sau mutation của admin)
+func (s *serviceabilityService) invalidate() {
+	s.mu.Lock()
+	s.active = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/affiliate/model"
+	"bookstore-backend/internal/domains/affiliate/service"
+)
+
+// =====================================================
+// AFFILIATE HANDLER (ADMIN)
+// =====================================================
+
+type AffiliateHandler struct {
+	affiliateService service.ServiceInterface
+}
+
+func NewAffiliateHandler(affiliateService service.ServiceInterface) *AffiliateHandler {
+	return &AffiliateHandler{
+		affiliateService: affiliateService,
+	}
+}
+
+// =====================================================
+// PARTNERS
+// =====================================================
+
+// CreatePartner creates affiliate partner
+// POST /api/v1/admin/affiliates/partners
+func (h *AffiliateHandler) CreatePartner(c *gin.Context) {
+	var req model.CreatePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.affiliateService.CreatePartner(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// ListPartners lists affiliate partners
+// GET /api/v1/admin/affiliates/partners
+func (h *AffiliateHandler) ListPartners(c *gin.Context) {
+	response, err := h.affiliateService.ListPartners(c.Request.Context())
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// UpdatePartner updates partner info / commission rate / active flag
+// PATCH /api/v1/admin/affiliates/partners/:id
+func (h *AffiliateHandler) UpdatePartner(c *gin.Context) {
+	partnerID, ok := parseID(c, "Invalid partner ID")
+	if !ok {
+		return
+	}
+
+	var req model.UpdatePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.affiliateService.UpdatePartner(c.Request.Context(), partnerID, req)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// =====================================================
+// COMMISSION REPORTS
+// =====================================================
+
+// GenerateReports (re)computes commission reports of a completed month
+// POST /api/v1/admin/affiliates/reports/generate
+func (h *AffiliateHandler) GenerateReports(c *gin.Context) {
+	var req model.GenerateReportsRequest
+	// Body rỗng = tháng trước
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+
+	response, err := h.affiliateService.GenerateReports(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// ListReports lists commission reports
+// GET /api/v1/admin/affiliates/reports?period=YYYY-MM&partner_id=&status=
+func (h *AffiliateHandler) ListReports(c *gin.Context) {
+	var req model.ListReportsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.affiliateService.ListReports(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetReport gets report with its orders
+// GET /api/v1/admin/affiliates/reports/:id
+func (h *AffiliateHandler) GetReport(c *gin.Context) {
+	reportID, ok := parseID(c, "Invalid report ID")
+	if !ok {
+		return
+	}
+
+	response, err := h.affiliateService.GetReport(c.Request.Context(), reportID)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// ExportReport downloads report orders as CSV
+// GET /api/v1/admin/affiliates/reports/:id/export
+func (h *AffiliateHandler) ExportReport(c *gin.Context) {
+	reportID, ok := parseID(c, "Invalid report ID")
+	if !ok {
+		return
+	}
+
+	// Ghi vào buffer trước: lỗi giữa chừng vẫn trả được JSON error thay vì file CSV dở dang
+	var buf bytes.Buffer
+	filename, err := h.affiliateService.ExportReportCSV(c.Request.Context(), reportID, &buf)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// ApproveReport approves pending commission report
+// POST /api/v1/admin/affiliates/reports/:id/approve
+func (h *AffiliateHandler) ApproveReport(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	reportID, ok := parseID(c, "Invalid report ID")
+	if !ok {
+		return
+	}
+
+	response, err := h.affiliateService.ApproveReport(c.Request.Context(), adminID, reportID)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// MarkReportPaid records payout of approved report
+// POST /api/v1/admin/affiliates/reports/:id/mark-paid
+func (h *AffiliateHandler) MarkReportPaid(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	reportID, ok := parseID(c, "Invalid report ID")
+	if !ok {
+		return
+	}
+
+	var req model.MarkPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.affiliateService.MarkReportPaid(c.Request.Context(), adminID, reportID, req)
+	if err != nil {
+		statusCode, errCode := mapAffiliateError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// getUserID extracts user ID from JWT claims
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+}
+
+func parseID(c *gin.Context, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// mapAffiliateError maps affiliate error to HTTP status code
+func mapAffiliateError(err error) (int, string) {
+	var aErr *model.AffiliateError
+	if errors.As(err, &aErr) {
+		switch aErr.Code {
+		case model.ErrCodePartnerNotFound, model.ErrCodeReportNotFound:
+			return http.StatusNotFound, aErr.Code
+		case model.ErrCodePartnerExists, model.ErrCodeInvalidTransition:
+			return http.StatusConflict, aErr.Code
+		case model.ErrCodeInvalidRequest:
+			return http.StatusBadRequest, aErr.Code
+		default:
+			return http.StatusInternalServerError, "INTERNAL_ERROR"
+		}
+	}
+	return http.StatusInternalServerError, "INTERNAL_ERROR"
+}
+
+// respondSuccess sends success response
+func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// respondError sends error response
+func respondError(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/affiliate/model"
+	"bookstore-backend/internal/domains/affiliate/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// GenerateCommissionReportsHandler tính hoa hồng affiliate của tháng trước (chạy đầu tháng).
+// Báo cáo tạo ra ở trạng thái pending_approval, admin duyệt rồi mới đánh dấu đã chi.
+type GenerateCommissionReportsHandler struct {
+	service service.ServiceInterface
+}
+
+func NewGenerateCommissionReportsHandler(service service.ServiceInterface) *GenerateCommissionReportsHandler {
+	return &GenerateCommissionReportsHandler{service: service}
+}
+
+func (h *GenerateCommissionReportsHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	periodStart, _ := model.PreviousMonthPeriod(time.Now())
+
+	result, err := h.service.GenerateMonthlyReports(ctx, periodStart)
+	if err != nil {
+		logger.Error("Failed to generate affiliate commission reports", err)
+		return err
+	}
+
+	logger.Info("Affiliate commission job completed", map[string]interface{}{
+		"period":    result.Period,
+		"generated": result.Generated,
+		"skipped":   result.Skipped,
+	})
+	return nil
+}
@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Commission report statuses
+// pending_approval → approved → paid; chỉ báo cáo pending_approval được tính lại
+const (
+	ReportStatusPendingApproval = "pending_approval" // job vừa tính, chờ admin duyệt
+	ReportStatusApproved        = "approved"         // admin đã duyệt số tiền, chờ chi
+	ReportStatusPaid            = "paid"             // đã chi cho đối tác
+)
+
+var ValidReportStatuses = []string{
+	ReportStatusPendingApproval,
+	ReportStatusApproved,
+	ReportStatusPaid,
+}
+
+// PeriodLayout định dạng kỳ báo cáo (tháng) trong request / tên file CSV
+const PeriodLayout = "2006-01"
+
+// Limits
+const (
+	MaxNameLength   = 200
+	MaxNotesLength  = 1000
+	MaxPayoutRefLen = 100
+)
+
+// Pagination defaults
+const (
+	DefaultPage  = 1
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// IsValidReportStatus checks commission report status
+func IsValidReportStatus(status string) bool {
+	for _, s := range ValidReportStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// MonthPeriod [đầu tháng, đầu tháng sau) theo giờ VN
+func MonthPeriod(t time.Time) (time.Time, time.Time) {
+	t = t.In(vietnamTZ)
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, vietnamTZ)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// PreviousMonthPeriod kỳ của tháng trước (job chạy đầu tháng)
+func PreviousMonthPeriod(now time.Time) (time.Time, time.Time) {
+	start, _ := MonthPeriod(now)
+	return MonthPeriod(start.AddDate(0, -1, 0))
+}
+
+// ParsePeriod "2026-09" → kỳ tháng 9/2026
+func ParsePeriod(period string) (time.Time, time.Time, error) {
+	t, err := time.ParseInLocation(PeriodLayout, period, vietnamTZ)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start, end := MonthPeriod(t)
+	return start, end, nil
+}
+
+// vietnamTZ - kỳ hoa hồng chốt theo giờ VN (đơn giao 23h ngày 31 vẫn thuộc tháng đó)
+var vietnamTZ = time.FixedZone("ICT", 7*60*60)
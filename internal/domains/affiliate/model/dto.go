@@ -0,0 +1,276 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/shared"
+)
+
+// =====================================================
+// PARTNER REQUEST DTOs
+// =====================================================
+
+// CreatePartnerRequest admin tạo đối tác affiliate
+type CreatePartnerRequest struct {
+	Code           string          `json:"code" binding:"required"`
+	Name           string          `json:"name" binding:"required"`
+	ContactEmail   *string         `json:"contact_email,omitempty"`
+	CommissionRate decimal.Decimal `json:"commission_rate" binding:"required"`
+	Notes          *string         `json:"notes,omitempty"`
+}
+
+func (r *CreatePartnerRequest) Validate() error {
+	code := shared.NormalizeAffiliateCode(r.Code)
+	if code == "" {
+		return NewInvalidRequestError("code must be 3-32 characters of A-Z, 0-9, '_' or '-'")
+	}
+	r.Code = code
+
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || utf8.RuneCountInString(r.Name) > MaxNameLength {
+		return NewInvalidRequestError(fmt.Sprintf("name must be between 1 and %d characters", MaxNameLength))
+	}
+	return validatePartnerFields(r.ContactEmail, &r.CommissionRate, r.Notes)
+}
+
+// UpdatePartnerRequest admin sửa đối tác (mã không đổi được: đã gắn vào đơn)
+type UpdatePartnerRequest struct {
+	Name           *string          `json:"name,omitempty"`
+	ContactEmail   *string          `json:"contact_email,omitempty"`
+	CommissionRate *decimal.Decimal `json:"commission_rate,omitempty"`
+	IsActive       *bool            `json:"is_active,omitempty"`
+	Notes          *string          `json:"notes,omitempty"`
+}
+
+func (r *UpdatePartnerRequest) Validate() error {
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+			return NewInvalidRequestError(fmt.Sprintf("name must be between 1 and %d characters", MaxNameLength))
+		}
+		r.Name = &name
+	}
+	return validatePartnerFields(r.ContactEmail, r.CommissionRate, r.Notes)
+}
+
+func validatePartnerFields(email *string, rate *decimal.Decimal, notes *string) error {
+	if email != nil {
+		*email = strings.TrimSpace(*email)
+		if _, err := mail.ParseAddress(*email); err != nil {
+			return NewInvalidRequestError("contact_email is invalid")
+		}
+	}
+	if rate != nil && (rate.IsNegative() || rate.GreaterThan(decimal.NewFromInt(100))) {
+		return NewInvalidRequestError("commission_rate must be between 0 and 100")
+	}
+	if notes != nil && utf8.RuneCountInString(*notes) > MaxNotesLength {
+		return NewInvalidRequestError(fmt.Sprintf("notes must be at most %d characters", MaxNotesLength))
+	}
+	return nil
+}
+
+// =====================================================
+// REPORT REQUEST DTOs
+// =====================================================
+
+// ListReportsRequest filter theo kỳ / đối tác / trạng thái
+type ListReportsRequest struct {
+	Period    string  `form:"period"` // YYYY-MM
+	PartnerID string  `form:"partner_id"`
+	Status    *string `form:"status"`
+	Page      int     `form:"page"`
+	Limit     int     `form:"limit"`
+
+	// Parse từ Period / PartnerID ở Validate
+	PeriodStart   *time.Time `form:"-"`
+	PartnerFilter *uuid.UUID `form:"-"`
+}
+
+func (r *ListReportsRequest) Validate() error {
+	if r.Period != "" {
+		start, _, err := ParsePeriod(r.Period)
+		if err != nil {
+			return NewInvalidRequestError("period must be in YYYY-MM format")
+		}
+		r.PeriodStart = &start
+	}
+	if r.PartnerID != "" {
+		partnerID, err := uuid.Parse(r.PartnerID)
+		if err != nil {
+			return NewInvalidRequestError("partner_id is invalid")
+		}
+		r.PartnerFilter = &partnerID
+	}
+	if r.Status != nil && !IsValidReportStatus(*r.Status) {
+		return NewInvalidRequestError("invalid status filter")
+	}
+	r.Page, r.Limit = normalizePagination(r.Page, r.Limit)
+	return nil
+}
+
+// GenerateReportsRequest admin tính lại hoa hồng 1 kỳ (mặc định tháng trước)
+type GenerateReportsRequest struct {
+	Period string `json:"period"` // YYYY-MM
+}
+
+// MarkPaidRequest admin xác nhận đã chi hoa hồng
+type MarkPaidRequest struct {
+	PayoutReference string `json:"payout_reference" binding:"required"`
+}
+
+func (r *MarkPaidRequest) Validate() error {
+	r.PayoutReference = strings.TrimSpace(r.PayoutReference)
+	if r.PayoutReference == "" || utf8.RuneCountInString(r.PayoutReference) > MaxPayoutRefLen {
+		return NewInvalidRequestError(fmt.Sprintf("payout_reference must be between 1 and %d characters", MaxPayoutRefLen))
+	}
+	return nil
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// PartnerResponse đối tác affiliate
+type PartnerResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	Code           string          `json:"code"`
+	Name           string          `json:"name"`
+	ContactEmail   *string         `json:"contact_email,omitempty"`
+	CommissionRate decimal.Decimal `json:"commission_rate"`
+	IsActive       bool            `json:"is_active"`
+	Notes          *string         `json:"notes,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// CommissionReportResponse báo cáo hoa hồng (+ lines ở màn chi tiết)
+type CommissionReportResponse struct {
+	ID               uuid.UUID                `json:"id"`
+	PartnerID        uuid.UUID                `json:"partner_id"`
+	PartnerCode      string                   `json:"partner_code"`
+	PartnerName      string                   `json:"partner_name"`
+	Period           string                   `json:"period"`
+	OrderCount       int                      `json:"order_count"`
+	NetSales         decimal.Decimal          `json:"net_sales"`
+	CommissionRate   decimal.Decimal          `json:"commission_rate"`
+	CommissionAmount decimal.Decimal          `json:"commission_amount"`
+	Status           string                   `json:"status"`
+	GeneratedAt      time.Time                `json:"generated_at"`
+	ApprovedBy       *uuid.UUID               `json:"approved_by,omitempty"`
+	ApprovedAt       *time.Time               `json:"approved_at,omitempty"`
+	PaidBy           *uuid.UUID               `json:"paid_by,omitempty"`
+	PaidAt           *time.Time               `json:"paid_at,omitempty"`
+	PayoutReference  *string                  `json:"payout_reference,omitempty"`
+	Lines            []CommissionLineResponse `json:"lines,omitempty"`
+}
+
+// CommissionLineResponse 1 đơn trong báo cáo
+type CommissionLineResponse struct {
+	OrderID          uuid.UUID       `json:"order_id"`
+	OrderNumber      string          `json:"order_number"`
+	DeliveredAt      time.Time       `json:"delivered_at"`
+	NetSales         decimal.Decimal `json:"net_sales"`
+	CommissionAmount decimal.Decimal `json:"commission_amount"`
+}
+
+// ListReportsResponse paginated reports
+type ListReportsResponse struct {
+	Reports    []CommissionReportResponse `json:"reports"`
+	Pagination PaginationMeta             `json:"pagination"`
+}
+
+// GenerateReportsResponse kết quả tính hoa hồng 1 kỳ
+type GenerateReportsResponse struct {
+	Period    string `json:"period"`
+	Generated int    `json:"generated"` // báo cáo pending_approval tạo mới / tính lại
+	Skipped   int    `json:"skipped"`   // đã duyệt / đã chi → giữ nguyên
+}
+
+// PaginationMeta pagination metadata
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
+
+// NewPaginationMeta builds pagination metadata
+func NewPaginationMeta(page, limit, total int) PaginationMeta {
+	totalPages := (total + limit - 1) / limit
+	return PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+func normalizePagination(page, limit int) (int, int) {
+	if page < 1 {
+		page = DefaultPage
+	}
+	if limit < 1 || limit > MaxLimit {
+		limit = DefaultLimit
+	}
+	return page, limit
+}
+
+// =====================================================
+// MAPPERS
+// =====================================================
+
+func (p *Partner) ToResponse() PartnerResponse {
+	return PartnerResponse{
+		ID:             p.ID,
+		Code:           p.Code,
+		Name:           p.Name,
+		ContactEmail:   p.ContactEmail,
+		CommissionRate: p.CommissionRate,
+		IsActive:       p.IsActive,
+		Notes:          p.Notes,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+	}
+}
+
+func (r *CommissionReport) ToResponse() CommissionReportResponse {
+	return CommissionReportResponse{
+		ID:               r.ID,
+		PartnerID:        r.PartnerID,
+		PartnerCode:      r.PartnerCode,
+		PartnerName:      r.PartnerName,
+		Period:           r.PeriodStart.Format(PeriodLayout),
+		OrderCount:       r.OrderCount,
+		NetSales:         r.NetSales,
+		CommissionRate:   r.CommissionRate,
+		CommissionAmount: r.CommissionAmount,
+		Status:           r.Status,
+		GeneratedAt:      r.GeneratedAt,
+		ApprovedBy:       r.ApprovedBy,
+		ApprovedAt:       r.ApprovedAt,
+		PaidBy:           r.PaidBy,
+		PaidAt:           r.PaidAt,
+		PayoutReference:  r.PayoutReference,
+	}
+}
+
+func (l *CommissionLine) ToResponse() CommissionLineResponse {
+	return CommissionLineResponse{
+		OrderID:          l.OrderID,
+		OrderNumber:      l.OrderNumber,
+		DeliveredAt:      l.DeliveredAt,
+		NetSales:         l.NetSales,
+		CommissionAmount: l.CommissionAmount,
+	}
+}
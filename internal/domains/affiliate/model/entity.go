@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Partner - đối tác affiliate (affiliate_partners)
+type Partner struct {
+	ID             uuid.UUID
+	Code           string
+	Name           string
+	ContactEmail   *string
+	CommissionRate decimal.Decimal // % trên doanh thu thuần
+	IsActive       bool
+	Notes          *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// CommissionReport - báo cáo hoa hồng tháng của 1 đối tác (affiliate_commission_reports)
+type CommissionReport struct {
+	ID               uuid.UUID
+	PartnerID        uuid.UUID
+	PeriodStart      time.Time
+	PeriodEnd        time.Time // exclusive
+	OrderCount       int
+	NetSales         decimal.Decimal
+	CommissionRate   decimal.Decimal // rate tại thời điểm tính
+	CommissionAmount decimal.Decimal
+	Status           string
+	GeneratedAt      time.Time
+	ApprovedBy       *uuid.UUID
+	ApprovedAt       *time.Time
+	PaidBy           *uuid.UUID
+	PaidAt           *time.Time
+	PayoutReference  *string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	// Join từ affiliate_partners (chỉ đọc)
+	PartnerCode string
+	PartnerName string
+}
+
+// CommissionLine - 1 đơn được tính hoa hồng (affiliate_commission_lines)
+type CommissionLine struct {
+	ID               uuid.UUID
+	ReportID         uuid.UUID
+	OrderID          uuid.UUID
+	OrderNumber      string
+	DeliveredAt      time.Time
+	NetSales         decimal.Decimal
+	CommissionAmount decimal.Decimal
+}
+
+// CommissionCandidate - đơn đã giao trong kỳ có gắn đối tác, chưa nằm trong báo cáo đã duyệt
+type CommissionCandidate struct {
+	PartnerID   uuid.UUID
+	OrderID     uuid.UUID
+	OrderNumber string
+	DeliveredAt time.Time
+	NetSales    decimal.Decimal // subtotal - discount, không tính phí ship
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodePartnerNotFound   = "AFF001"
+	ErrCodeInvalidRequest    = "AFF002"
+	ErrCodePartnerExists     = "AFF003"
+	ErrCodeReportNotFound    = "AFF004"
+	ErrCodeInvalidTransition = "AFF005"
+)
+
+// Errors
+var (
+	ErrPartnerNotFound   = errors.New("affiliate partner not found")
+	ErrInvalidRequest    = errors.New("invalid affiliate request")
+	ErrPartnerExists     = errors.New("affiliate code already exists")
+	ErrReportNotFound    = errors.New("commission report not found")
+	ErrInvalidTransition = errors.New("invalid commission report status transition")
+)
+
+// AffiliateError custom error type
+type AffiliateError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *AffiliateError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *AffiliateError) Unwrap() error {
+	return e.Err
+}
+
+// Error constructors
+func NewPartnerNotFoundError() *AffiliateError {
+	return &AffiliateError{
+		Code:    ErrCodePartnerNotFound,
+		Message: "Affiliate partner not found",
+		Err:     ErrPartnerNotFound,
+	}
+}
+
+func NewInvalidRequestError(message string) *AffiliateError {
+	return &AffiliateError{
+		Code:    ErrCodeInvalidRequest,
+		Message: message,
+		Err:     ErrInvalidRequest,
+	}
+}
+
+func NewPartnerExistsError(code string) *AffiliateError {
+	return &AffiliateError{
+		Code:    ErrCodePartnerExists,
+		Message: fmt.Sprintf("Affiliate code already exists: %s", code),
+		Err:     ErrPartnerExists,
+	}
+}
+
+func NewReportNotFoundError() *AffiliateError {
+	return &AffiliateError{
+		Code:    ErrCodeReportNotFound,
+		Message: "Commission report not found",
+		Err:     ErrReportNotFound,
+	}
+}
+
+func NewInvalidTransitionError(from, action string) *AffiliateError {
+	return &AffiliateError{
+		Code:    ErrCodeInvalidTransition,
+		Message: fmt.Sprintf("Cannot %s a commission report in status %s", action, from),
+		Err:     ErrInvalidTransition,
+	}
+}
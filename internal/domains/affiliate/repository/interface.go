@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/affiliate/model"
+)
+
+// =====================================================
+// AFFILIATE REPOSITORY INTERFACE
+// =====================================================
+
+type AffiliateRepository interface {
+	// ========================================
+	// PARTNERS
+	// ========================================
+
+	// CreatePartner trả ErrPartnerExists nếu trùng code
+	CreatePartner(ctx context.Context, partner *model.Partner) error
+
+	// GetPartnerByID gets partner
+	GetPartnerByID(ctx context.Context, id uuid.UUID) (*model.Partner, error)
+
+	// ListPartners lists all partners (active first)
+	ListPartners(ctx context.Context) ([]model.Partner, error)
+
+	// UpdatePartner updates name, contact, rate, is_active, notes
+	UpdatePartner(ctx context.Context, partner *model.Partner) error
+
+	// ========================================
+	// COMMISSION REPORTS
+	// ========================================
+
+	// ListCommissionCandidates đơn đã giao trong [from, to), có đối tác,
+	// chưa nằm trong báo cáo đã duyệt / đã chi
+	ListCommissionCandidates(ctx context.Context, from, to time.Time) ([]model.CommissionCandidate, error)
+
+	// ReplacePendingReport ghi (hoặc tính lại) báo cáo pending_approval của đối tác cho kỳ
+	// Trả false nếu báo cáo kỳ đó đã duyệt / đã chi (giữ nguyên)
+	ReplacePendingReport(ctx context.Context, report *model.CommissionReport, lines []model.CommissionLine) (bool, error)
+
+	// GetReportByID gets report (join partner)
+	GetReportByID(ctx context.Context, id uuid.UUID) (*model.CommissionReport, error)
+
+	// ListReports lists reports with filters (period_start, partner_id, status)
+	ListReports(ctx context.Context, filters map[string]interface{}, page, limit int) ([]model.CommissionReport, int, error)
+
+	// ListReportLines lists orders of a report
+	ListReportLines(ctx context.Context, reportID uuid.UUID) ([]model.CommissionLine, error)
+
+	// ApproveReport pending_approval → approved
+	ApproveReport(ctx context.Context, id, adminID uuid.UUID) error
+
+	// MarkReportPaid approved → paid
+	MarkReportPaid(ctx context.Context, id, adminID uuid.UUID, payoutReference string) error
+}
@@ -0,0 +1,426 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/affiliate/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresAffiliateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresAffiliateRepository(pool *pgxpool.Pool) AffiliateRepository {
+	return &postgresAffiliateRepository{pool: pool}
+}
+
+const partnerColumns = `
+	id, code, name, contact_email, commission_rate, is_active, notes, created_at, updated_at`
+
+func scanPartner(row pgx.Row, partner *model.Partner) error {
+	return row.Scan(
+		&partner.ID,
+		&partner.Code,
+		&partner.Name,
+		&partner.ContactEmail,
+		&partner.CommissionRate,
+		&partner.IsActive,
+		&partner.Notes,
+		&partner.CreatedAt,
+		&partner.UpdatedAt,
+	)
+}
+
+const reportColumns = `
+	r.id, r.partner_id, r.period_start, r.period_end, r.order_count,
+	r.net_sales, r.commission_rate, r.commission_amount, r.status, r.generated_at,
+	r.approved_by, r.approved_at, r.paid_by, r.paid_at, r.payout_reference,
+	r.created_at, r.updated_at, p.code, p.name`
+
+func scanReport(row pgx.Row, report *model.CommissionReport) error {
+	return row.Scan(
+		&report.ID,
+		&report.PartnerID,
+		&report.PeriodStart,
+		&report.PeriodEnd,
+		&report.OrderCount,
+		&report.NetSales,
+		&report.CommissionRate,
+		&report.CommissionAmount,
+		&report.Status,
+		&report.GeneratedAt,
+		&report.ApprovedBy,
+		&report.ApprovedAt,
+		&report.PaidBy,
+		&report.PaidAt,
+		&report.PayoutReference,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+		&report.PartnerCode,
+		&report.PartnerName,
+	)
+}
+
+// =====================================================
+// PARTNERS
+// =====================================================
+
+func (r *postgresAffiliateRepository) CreatePartner(ctx context.Context, partner *model.Partner) error {
+	query := `
+		INSERT INTO affiliate_partners (id, code, name, contact_email, commission_rate, is_active, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		partner.ID,
+		partner.Code,
+		partner.Name,
+		partner.ContactEmail,
+		partner.CommissionRate,
+		partner.IsActive,
+		partner.Notes,
+	).Scan(&partner.CreatedAt, &partner.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return model.ErrPartnerExists
+		}
+		return fmt.Errorf("failed to create affiliate partner: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresAffiliateRepository) GetPartnerByID(ctx context.Context, id uuid.UUID) (*model.Partner, error) {
+	query := `SELECT ` + partnerColumns + ` FROM affiliate_partners WHERE id = $1`
+
+	partner := &model.Partner{}
+	if err := scanPartner(r.pool.QueryRow(ctx, query, id), partner); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrPartnerNotFound
+		}
+		return nil, fmt.Errorf("failed to get affiliate partner: %w", err)
+	}
+	return partner, nil
+}
+
+func (r *postgresAffiliateRepository) ListPartners(ctx context.Context) ([]model.Partner, error) {
+	query := `SELECT ` + partnerColumns + ` FROM affiliate_partners ORDER BY is_active DESC, code`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list affiliate partners: %w", err)
+	}
+	defer rows.Close()
+
+	var partners []model.Partner
+	for rows.Next() {
+		var partner model.Partner
+		if err := scanPartner(rows, &partner); err != nil {
+			return nil, fmt.Errorf("failed to scan affiliate partner: %w", err)
+		}
+		partners = append(partners, partner)
+	}
+	return partners, rows.Err()
+}
+
+func (r *postgresAffiliateRepository) UpdatePartner(ctx context.Context, partner *model.Partner) error {
+	query := `
+		UPDATE affiliate_partners
+		SET name = $2, contact_email = $3, commission_rate = $4, is_active = $5, notes = $6
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		partner.ID,
+		partner.Name,
+		partner.ContactEmail,
+		partner.CommissionRate,
+		partner.IsActive,
+		partner.Notes,
+	).Scan(&partner.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrPartnerNotFound
+		}
+		return fmt.Errorf("failed to update affiliate partner: %w", err)
+	}
+	return nil
+}
+
+// =====================================================
+// COMMISSION REPORTS
+// =====================================================
+
+func (r *postgresAffiliateRepository) ListCommissionCandidates(
+	ctx context.Context,
+	from, to time.Time,
+) ([]model.CommissionCandidate, error) {
+	// Đơn đã hoàn tiền không tính; đơn đã nằm trong báo cáo đã duyệt / đã chi (kỳ khác) không tính lại
+	query := `
+		SELECT o.affiliate_partner_id, o.id, o.order_number, o.delivered_at,
+			GREATEST(o.subtotal - COALESCE(o.discount_amount, 0), 0)
+		FROM orders o
+		WHERE o.affiliate_partner_id IS NOT NULL
+			AND o.status = 'delivered'
+			AND o.payment_status <> 'refunded'
+			AND o.delivered_at >= $1
+			AND o.delivered_at < $2
+			AND NOT EXISTS (
+				SELECT 1
+				FROM affiliate_commission_lines l
+				JOIN affiliate_commission_reports cr ON cr.id = l.report_id
+				WHERE l.order_id = o.id AND cr.status <> 'pending_approval'
+			)
+		ORDER BY o.affiliate_partner_id, o.delivered_at, o.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commission candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []model.CommissionCandidate
+	for rows.Next() {
+		var c model.CommissionCandidate
+		if err := rows.Scan(&c.PartnerID, &c.OrderID, &c.OrderNumber, &c.DeliveredAt, &c.NetSales); err != nil {
+			return nil, fmt.Errorf("failed to scan commission candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func (r *postgresAffiliateRepository) ReplacePendingReport(
+	ctx context.Context,
+	report *model.CommissionReport,
+	lines []model.CommissionLine,
+) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Khoá báo cáo cũ của kỳ: admin duyệt song song với job chạy lại → 1 bên chờ
+	var existingID uuid.UUID
+	var existingStatus string
+	err = tx.QueryRow(ctx, `
+		SELECT id, status FROM affiliate_commission_reports
+		WHERE partner_id = $1 AND period_start = $2
+		FOR UPDATE
+	`, report.PartnerID, report.PeriodStart).Scan(&existingID, &existingStatus)
+	switch {
+	case err == nil:
+		if existingStatus != model.ReportStatusPendingApproval {
+			return false, nil
+		}
+		// Lines xoá theo CASCADE
+		if _, err := tx.Exec(ctx, `DELETE FROM affiliate_commission_reports WHERE id = $1`, existingID); err != nil {
+			return false, fmt.Errorf("failed to delete pending commission report: %w", err)
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+	default:
+		return false, fmt.Errorf("failed to lock commission report: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO affiliate_commission_reports (
+			id, partner_id, period_start, period_end, order_count,
+			net_sales, commission_rate, commission_amount, status, generated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at, updated_at
+	`,
+		report.ID,
+		report.PartnerID,
+		report.PeriodStart,
+		report.PeriodEnd,
+		report.OrderCount,
+		report.NetSales,
+		report.CommissionRate,
+		report.CommissionAmount,
+		report.Status,
+		report.GeneratedAt,
+	).Scan(&report.CreatedAt, &report.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create commission report: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO affiliate_commission_lines (
+			id, report_id, order_id, order_number, delivered_at, net_sales, commission_amount
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, line := range lines {
+		if _, err := tx.Exec(ctx, lineQuery,
+			line.ID,
+			report.ID,
+			line.OrderID,
+			line.OrderNumber,
+			line.DeliveredAt,
+			line.NetSales,
+			line.CommissionAmount,
+		); err != nil {
+			return false, fmt.Errorf("failed to create commission line: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit commission report: %w", err)
+	}
+	return true, nil
+}
+
+func (r *postgresAffiliateRepository) GetReportByID(ctx context.Context, id uuid.UUID) (*model.CommissionReport, error) {
+	query := `SELECT ` + reportColumns + `
+		FROM affiliate_commission_reports r
+		JOIN affiliate_partners p ON p.id = r.partner_id
+		WHERE r.id = $1`
+
+	report := &model.CommissionReport{}
+	if err := scanReport(r.pool.QueryRow(ctx, query, id), report); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get commission report: %w", err)
+	}
+	return report, nil
+}
+
+func (r *postgresAffiliateRepository) ListReports(
+	ctx context.Context,
+	filters map[string]interface{},
+	page, limit int,
+) ([]model.CommissionReport, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if periodStart, ok := filters["period_start"].(time.Time); ok {
+		where += fmt.Sprintf(" AND r.period_start = $%d", argCount)
+		args = append(args, periodStart)
+		argCount++
+	}
+
+	if partnerID, ok := filters["partner_id"].(uuid.UUID); ok {
+		where += fmt.Sprintf(" AND r.partner_id = $%d", argCount)
+		args = append(args, partnerID)
+		argCount++
+	}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += fmt.Sprintf(" AND r.status = $%d", argCount)
+		args = append(args, status)
+		argCount++
+	}
+
+	from := " FROM affiliate_commission_reports r JOIN affiliate_partners p ON p.id = r.partner_id"
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*)"+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count commission reports: %w", err)
+	}
+
+	query := `SELECT ` + reportColumns + from + where +
+		" ORDER BY r.period_start DESC, p.code" +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list commission reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []model.CommissionReport
+	for rows.Next() {
+		var report model.CommissionReport
+		if err := scanReport(rows, &report); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan commission report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, total, rows.Err()
+}
+
+func (r *postgresAffiliateRepository) ListReportLines(ctx context.Context, reportID uuid.UUID) ([]model.CommissionLine, error) {
+	query := `
+		SELECT id, report_id, order_id, order_number, delivered_at, net_sales, commission_amount
+		FROM affiliate_commission_lines
+		WHERE report_id = $1
+		ORDER BY delivered_at, order_number
+	`
+
+	rows, err := r.pool.Query(ctx, query, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commission lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []model.CommissionLine
+	for rows.Next() {
+		var line model.CommissionLine
+		if err := rows.Scan(
+			&line.ID,
+			&line.ReportID,
+			&line.OrderID,
+			&line.OrderNumber,
+			&line.DeliveredAt,
+			&line.NetSales,
+			&line.CommissionAmount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan commission line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func (r *postgresAffiliateRepository) ApproveReport(ctx context.Context, id, adminID uuid.UUID) error {
+	query := `
+		UPDATE affiliate_commission_reports
+		SET status = 'approved', approved_by = $2, approved_at = NOW()
+		WHERE id = $1 AND status = 'pending_approval'
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, adminID)
+	if err != nil {
+		return fmt.Errorf("failed to approve commission report: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrInvalidTransition
+	}
+	return nil
+}
+
+func (r *postgresAffiliateRepository) MarkReportPaid(ctx context.Context, id, adminID uuid.UUID, payoutReference string) error {
+	query := `
+		UPDATE affiliate_commission_reports
+		SET status = 'paid', paid_by = $2, paid_at = NOW(), payout_reference = $3
+		WHERE id = $1 AND status = 'approved'
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, adminID, payoutReference)
+	if err != nil {
+		return fmt.Errorf("failed to mark commission report paid: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrInvalidTransition
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/affiliate/model"
+)
+
+// =====================================================
+// AFFILIATE SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ========================================
+	// PARTNERS (ADMIN)
+	// ========================================
+
+	CreatePartner(ctx context.Context, req model.CreatePartnerRequest) (*model.PartnerResponse, error)
+	ListPartners(ctx context.Context) ([]model.PartnerResponse, error)
+	UpdatePartner(ctx context.Context, partnerID uuid.UUID, req model.UpdatePartnerRequest) (*model.PartnerResponse, error)
+
+	// ========================================
+	// COMMISSION REPORTS
+	// ========================================
+
+	// GenerateMonthlyReports tính hoa hồng kỳ chứa periodStart cho mọi đối tác có đơn giao trong kỳ
+	GenerateMonthlyReports(ctx context.Context, periodStart time.Time) (*model.GenerateReportsResponse, error)
+
+	// GenerateReports admin chạy tay (period rỗng = tháng trước)
+	GenerateReports(ctx context.Context, req model.GenerateReportsRequest) (*model.GenerateReportsResponse, error)
+
+	ListReports(ctx context.Context, req model.ListReportsRequest) (*model.ListReportsResponse, error)
+	GetReport(ctx context.Context, reportID uuid.UUID) (*model.CommissionReportResponse, error)
+
+	// ApproveReport pending_approval → approved
+	ApproveReport(ctx context.Context, adminID, reportID uuid.UUID) (*model.CommissionReportResponse, error)
+
+	// MarkReportPaid approved → paid (phải duyệt trước khi chi)
+	MarkReportPaid(ctx context.Context, adminID, reportID uuid.UUID, req model.MarkPaidRequest) (*model.CommissionReportResponse, error)
+
+	// ExportReportCSV ghi các đơn của báo cáo ra CSV, trả tên file
+	ExportReportCSV(ctx context.Context, reportID uuid.UUID, w io.Writer) (string, error)
+}
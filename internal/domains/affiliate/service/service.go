@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/affiliate/model"
+	"bookstore-backend/internal/domains/affiliate/repository"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type affiliateService struct {
+	repo repository.AffiliateRepository
+}
+
+func NewAffiliateService(repo repository.AffiliateRepository) ServiceInterface {
+	return &affiliateService{repo: repo}
+}
+
+// =====================================================
+// PARTNERS
+// =====================================================
+
+func (s *affiliateService) CreatePartner(ctx context.Context, req model.CreatePartnerRequest) (*model.PartnerResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	partner := &model.Partner{
+		ID:             uuid.New(),
+		Code:           req.Code,
+		Name:           req.Name,
+		ContactEmail:   req.ContactEmail,
+		CommissionRate: req.CommissionRate,
+		IsActive:       true,
+		Notes:          req.Notes,
+	}
+	if err := s.repo.CreatePartner(ctx, partner); err != nil {
+		if errors.Is(err, model.ErrPartnerExists) {
+			return nil, model.NewPartnerExistsError(partner.Code)
+		}
+		return nil, err
+	}
+
+	response := partner.ToResponse()
+	return &response, nil
+}
+
+func (s *affiliateService) ListPartners(ctx context.Context) ([]model.PartnerResponse, error) {
+	partners, err := s.repo.ListPartners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.PartnerResponse, len(partners))
+	for i := range partners {
+		responses[i] = partners[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// UpdatePartner - đổi % chỉ áp dụng cho báo cáo tính sau đó (báo cáo đã duyệt giữ rate cũ)
+func (s *affiliateService) UpdatePartner(
+	ctx context.Context,
+	partnerID uuid.UUID,
+	req model.UpdatePartnerRequest,
+) (*model.PartnerResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	partner, err := s.repo.GetPartnerByID(ctx, partnerID)
+	if err != nil {
+		if errors.Is(err, model.ErrPartnerNotFound) {
+			return nil, model.NewPartnerNotFoundError()
+		}
+		return nil, err
+	}
+
+	if req.Name != nil {
+		partner.Name = *req.Name
+	}
+	if req.ContactEmail != nil {
+		partner.ContactEmail = req.ContactEmail
+	}
+	if req.CommissionRate != nil {
+		partner.CommissionRate = *req.CommissionRate
+	}
+	if req.IsActive != nil {
+		partner.IsActive = *req.IsActive
+	}
+	if req.Notes != nil {
+		partner.Notes = req.Notes
+	}
+
+	if err := s.repo.UpdatePartner(ctx, partner); err != nil {
+		if errors.Is(err, model.ErrPartnerNotFound) {
+			return nil, model.NewPartnerNotFoundError()
+		}
+		return nil, err
+	}
+
+	response := partner.ToResponse()
+	return &response, nil
+}
+
+// =====================================================
+// COMMISSION REPORTS
+// =====================================================
+
+// GenerateMonthlyReports - gom đơn đã giao trong kỳ theo đối tác, hoa hồng = net × rate / 100 (làm tròn từng đơn)
+// Chạy lại an toàn: báo cáo pending_approval được tính lại, báo cáo đã duyệt / đã chi giữ nguyên
+func (s *affiliateService) GenerateMonthlyReports(ctx context.Context, periodStart time.Time) (*model.GenerateReportsResponse, error) {
+	from, to := model.MonthPeriod(periodStart)
+	response := &model.GenerateReportsResponse{Period: from.Format(model.PeriodLayout)}
+
+	candidates, err := s.repo.ListCommissionCandidates(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return response, nil
+	}
+
+	partners, err := s.repo.ListPartners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[uuid.UUID]decimal.Decimal, len(partners))
+	for _, p := range partners {
+		rates[p.ID] = p.CommissionRate
+	}
+
+	byPartner := make(map[uuid.UUID][]model.CommissionCandidate)
+	var partnerOrder []uuid.UUID
+	for _, c := range candidates {
+		if _, ok := byPartner[c.PartnerID]; !ok {
+			partnerOrder = append(partnerOrder, c.PartnerID)
+		}
+		byPartner[c.PartnerID] = append(byPartner[c.PartnerID], c)
+	}
+
+	now := time.Now()
+	hundred := decimal.NewFromInt(100)
+	for _, partnerID := range partnerOrder {
+		rate := rates[partnerID]
+		report := &model.CommissionReport{
+			ID:               uuid.New(),
+			PartnerID:        partnerID,
+			PeriodStart:      from,
+			PeriodEnd:        to,
+			NetSales:         decimal.Zero,
+			CommissionRate:   rate,
+			CommissionAmount: decimal.Zero,
+			Status:           model.ReportStatusPendingApproval,
+			GeneratedAt:      now,
+		}
+
+		orders := byPartner[partnerID]
+		lines := make([]model.CommissionLine, len(orders))
+		for i, c := range orders {
+			amount := c.NetSales.Mul(rate).Div(hundred).Round(2)
+			lines[i] = model.CommissionLine{
+				ID:               uuid.New(),
+				ReportID:         report.ID,
+				OrderID:          c.OrderID,
+				OrderNumber:      c.OrderNumber,
+				DeliveredAt:      c.DeliveredAt,
+				NetSales:         c.NetSales,
+				CommissionAmount: amount,
+			}
+			report.NetSales = report.NetSales.Add(c.NetSales)
+			report.CommissionAmount = report.CommissionAmount.Add(amount)
+		}
+		report.OrderCount = len(lines)
+
+		written, err := s.repo.ReplacePendingReport(ctx, report, lines)
+		if err != nil {
+			return nil, fmt.Errorf("partner %s: %w", partnerID, err)
+		}
+		if written {
+			response.Generated++
+		} else {
+			response.Skipped++
+		}
+	}
+
+	logger.Info("Affiliate commission reports generated", map[string]interface{}{
+		"period":    response.Period,
+		"orders":    len(candidates),
+		"generated": response.Generated,
+		"skipped":   response.Skipped,
+	})
+	return response, nil
+}
+
+func (s *affiliateService) GenerateReports(ctx context.Context, req model.GenerateReportsRequest) (*model.GenerateReportsResponse, error) {
+	if req.Period == "" {
+		from, _ := model.PreviousMonthPeriod(time.Now())
+		return s.GenerateMonthlyReports(ctx, from)
+	}
+
+	from, _, err := model.ParsePeriod(req.Period)
+	if err != nil {
+		return nil, model.NewInvalidRequestError("period must be in YYYY-MM format")
+	}
+	// Tháng hiện tại chưa chốt: đơn còn đang giao
+	if current, _ := model.MonthPeriod(time.Now()); !from.Before(current) {
+		return nil, model.NewInvalidRequestError("period must be a completed month")
+	}
+	return s.GenerateMonthlyReports(ctx, from)
+}
+
+func (s *affiliateService) ListReports(ctx context.Context, req model.ListReportsRequest) (*model.ListReportsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{}
+	if req.PeriodStart != nil {
+		filters["period_start"] = *req.PeriodStart
+	}
+	if req.PartnerFilter != nil {
+		filters["partner_id"] = *req.PartnerFilter
+	}
+	if req.Status != nil {
+		filters["status"] = *req.Status
+	}
+
+	reports, total, err := s.repo.ListReports(ctx, filters, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.CommissionReportResponse, len(reports))
+	for i := range reports {
+		responses[i] = reports[i].ToResponse()
+	}
+
+	return &model.ListReportsResponse{
+		Reports:    responses,
+		Pagination: model.NewPaginationMeta(req.Page, req.Limit, total),
+	}, nil
+}
+
+func (s *affiliateService) GetReport(ctx context.Context, reportID uuid.UUID) (*model.CommissionReportResponse, error) {
+	report, err := s.getReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.repo.ListReportLines(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := report.ToResponse()
+	response.Lines = make([]model.CommissionLineResponse, len(lines))
+	for i := range lines {
+		response.Lines[i] = lines[i].ToResponse()
+	}
+	return &response, nil
+}
+
+func (s *affiliateService) ApproveReport(ctx context.Context, adminID, reportID uuid.UUID) (*model.CommissionReportResponse, error) {
+	report, err := s.getReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ApproveReport(ctx, reportID, adminID); err != nil {
+		if errors.Is(err, model.ErrInvalidTransition) {
+			return nil, model.NewInvalidTransitionError(report.Status, "approve")
+		}
+		return nil, err
+	}
+
+	logger.Info("Affiliate commission report approved", map[string]interface{}{
+		"report_id": reportID,
+		"partner":   report.PartnerCode,
+		"amount":    report.CommissionAmount.String(),
+		"admin_id":  adminID,
+	})
+	return s.GetReport(ctx, reportID)
+}
+
+func (s *affiliateService) MarkReportPaid(
+	ctx context.Context,
+	adminID, reportID uuid.UUID,
+	req model.MarkPaidRequest,
+) (*model.CommissionReportResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	report, err := s.getReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.MarkReportPaid(ctx, reportID, adminID, req.PayoutReference); err != nil {
+		if errors.Is(err, model.ErrInvalidTransition) {
+			return nil, model.NewInvalidTransitionError(report.Status, "mark paid")
+		}
+		return nil, err
+	}
+
+	logger.Info("Affiliate commission report paid", map[string]interface{}{
+		"report_id":        reportID,
+		"partner":          report.PartnerCode,
+		"amount":           report.CommissionAmount.String(),
+		"payout_reference": req.PayoutReference,
+		"admin_id":         adminID,
+	})
+	return s.GetReport(ctx, reportID)
+}
+
+// ExportReportCSV - 1 dòng / đơn, gửi đối tác đối chiếu trước khi chi
+func (s *affiliateService) ExportReportCSV(ctx context.Context, reportID uuid.UUID, w io.Writer) (string, error) {
+	report, err := s.getReport(ctx, reportID)
+	if err != nil {
+		return "", err
+	}
+
+	lines, err := s.repo.ListReportLines(ctx, reportID)
+	if err != nil {
+		return "", err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"partner_code", "period", "order_number", "delivered_at", "net_sales", "commission_rate", "commission_amount",
+	}); err != nil {
+		return "", fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	period := report.PeriodStart.Format(model.PeriodLayout)
+	for _, line := range lines {
+		if err := writer.Write([]string{
+			report.PartnerCode,
+			period,
+			line.OrderNumber,
+			line.DeliveredAt.Format(time.RFC3339),
+			line.NetSales.StringFixed(2),
+			report.CommissionRate.StringFixed(2),
+			line.CommissionAmount.StringFixed(2),
+		}); err != nil {
+			return "", fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("affiliate_commission_%s_%s.csv", report.PartnerCode, period), nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+func (s *affiliateService) getReport(ctx context.Context, reportID uuid.UUID) (*model.CommissionReport, error) {
+	report, err := s.repo.GetReportByID(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, model.ErrReportNotFound) {
+			return nil, model.NewReportNotFoundError()
+		}
+		return nil, err
+	}
+	return report, nil
+}
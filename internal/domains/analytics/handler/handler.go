@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/analytics/service"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/response"
+	"bookstore-backend/pkg/logger"
+)
+
+type Handler struct {
+	service service.ServiceInterface
+}
+
+func NewHandler(service service.ServiceInterface) *Handler {
+	return &Handler{service: service}
+}
+
+// TrackBookView - middleware gắn trước GetBookDetail (cần OptionalAuthMiddleware).
+// Ghi event cho cả user đã login lẫn khách có session cookie.
+func (h *Handler) TrackBookView(c *gin.Context) {
+	c.Next()
+
+	if c.Writer.Status() < http.StatusOK || c.Writer.Status() >= http.StatusMultipleChoices {
+		return
+	}
+
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	event := model.Event{
+		EventType:  model.EventBookView,
+		BookID:     &bookID,
+		OccurredAt: time.Now(),
+	}
+	if userID, ok := middleware.GetAuthenticatedUserID(c); ok {
+		event.UserID = userID
+	}
+	if sessionID := middleware.GetSessionIDFromCookie(c); sessionID != "" {
+		event.SessionID = &sessionID
+	}
+	if event.UserID == nil && event.SessionID == nil {
+		return // khách chưa có session → không stitch được
+	}
+	if source := trafficSource(c); source != "" {
+		event.TrafficSource = &source
+	}
+
+	go func(event model.Event) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.service.TrackEvent(ctx, event); err != nil {
+			logger.Error("Failed to track book view event", err)
+		}
+	}(event)
+}
+
+// trafficSource - utm_source nếu có, nếu không thì host của referrer ngoài site.
+// Điều hướng nội bộ (referrer cùng host) không tính là source.
+func trafficSource(c *gin.Context) string {
+	source := strings.ToLower(strings.TrimSpace(c.Query("utm_source")))
+
+	if source == "" {
+		referer, err := url.Parse(c.Request.Referer())
+		if err != nil || referer.Hostname() == "" {
+			return ""
+		}
+		host := strings.TrimPrefix(strings.ToLower(referer.Hostname()), "www.")
+		if host == strings.TrimPrefix(strings.ToLower(strings.Split(c.Request.Host, ":")[0]), "www.") {
+			return ""
+		}
+		source = host
+	}
+
+	if len(source) > model.MaxTrafficSourceLength {
+		source = source[:model.MaxTrafficSourceLength]
+	}
+	return source
+}
+
+// =====================================================
+// CUSTOMER: ACTIVITY FEED
+// =====================================================
+
+// GetMyActivity - GET /users/me/activity?domain=cart|order&page=&limit=
+// Lịch sử giỏ (thêm / bỏ sách, mã giảm giá) và đơn hàng (đặt, đổi trạng thái, huỷ) của chính khách
+func (h *Handler) GetMyActivity(c *gin.Context) {
+	userID, ok := middleware.GetAuthenticatedUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "User ID required")
+		return
+	}
+
+	var req model.ListActivityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+	req.UserID = *userID
+
+	result, err := h.service.ListActivity(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, model.ErrUnknownActivity) {
+			response.Error(c, http.StatusBadRequest, "Invalid activity domain", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get activity", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get activity successfully", result)
+}
+
+// =====================================================
+// ADMIN: FUNNEL METRICS
+// =====================================================
+
+// AdminGetFunnel - GET /admin/analytics/funnel?from=&to=&source=
+func (h *Handler) AdminGetFunnel(c *gin.Context) {
+	var req model.FunnelQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.GetFunnelTimeSeries(c.Request.Context(), req)
+	if err != nil {
+		handleFunnelError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get funnel metrics successfully", result)
+}
+
+// AdminGetFunnelBySource - GET /admin/analytics/funnel/sources?from=&to=
+func (h *Handler) AdminGetFunnelBySource(c *gin.Context) {
+	var req model.FunnelQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.GetFunnelBySource(c.Request.Context(), req)
+	if err != nil {
+		handleFunnelError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get funnel metrics by source successfully", result)
+}
+
+// AdminRecomputeFunnel - POST /admin/analytics/funnel/recompute
+func (h *Handler) AdminRecomputeFunnel(c *gin.Context) {
+	var req model.RecomputeFunnelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	result, err := h.service.EnqueueFunnelRecompute(c.Request.Context(), req)
+	if err != nil {
+		handleFunnelError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Funnel recompute enqueued", result)
+}
+
+func handleFunnelError(c *gin.Context, err error) {
+	if errors.Is(err, model.ErrInvalidDateRange) {
+		response.Error(c, http.StatusBadRequest, "Invalid date range", err.Error())
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, "Failed to process funnel metrics", err.Error())
+}
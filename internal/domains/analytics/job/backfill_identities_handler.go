@@ -0,0 +1,42 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/analytics/service"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// BackfillIdentitiesHandler - event ghi async có thể tới SAU khi session đã stitch,
+// job định kỳ này điền user_id cho những event đó.
+type BackfillIdentitiesHandler struct {
+	service service.ServiceInterface
+}
+
+func NewBackfillIdentitiesHandler(service service.ServiceInterface) *BackfillIdentitiesHandler {
+	return &BackfillIdentitiesHandler{service: service}
+}
+
+func (h *BackfillIdentitiesHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.BackfillIdentitiesPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		logger.Error("Failed to unmarshal backfill_identities payload, using default batch size", err)
+	}
+
+	attributed, err := h.service.BackfillIdentities(ctx, payload.BatchSize)
+	if err != nil {
+		return fmt.Errorf("backfill identities: %w", err)
+	}
+
+	logger.Info("Completed BackfillIdentities job", map[string]interface{}{
+		"batch_size": payload.BatchSize,
+		"attributed": attributed,
+	})
+
+	return nil
+}
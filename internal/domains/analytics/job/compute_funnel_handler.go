@@ -0,0 +1,60 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/analytics/service"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// ComputeFunnelHandler tính cart conversion funnel theo ngày + traffic source.
+// Scheduled: tính lại `Days` ngày gần nhất. Admin recompute: khoảng From/To.
+type ComputeFunnelHandler struct {
+	service service.ServiceInterface
+}
+
+func NewComputeFunnelHandler(service service.ServiceInterface) *ComputeFunnelHandler {
+	return &ComputeFunnelHandler{service: service}
+}
+
+func (h *ComputeFunnelHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.ComputeFunnelPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	var from, to time.Time
+	if payload.From != "" || payload.To != "" {
+		var err error
+		from, to, err = service.ParseFunnelRange(payload.From, payload.To)
+		if err != nil {
+			return fmt.Errorf("invalid funnel range: %w", err)
+		}
+	} else {
+		days := payload.Days
+		if days <= 0 {
+			days = model.FunnelRecomputeDays
+		}
+		to = time.Now().UTC().Truncate(24 * time.Hour)
+		from = to.AddDate(0, 0, -(days - 1))
+	}
+
+	rows, err := h.service.ComputeFunnel(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("compute funnel: %w", err)
+	}
+
+	logger.Info("Completed ComputeFunnel job", map[string]interface{}{
+		"from": from.Format(model.FunnelDateLayout),
+		"to":   to.Format(model.FunnelDateLayout),
+		"rows": rows,
+	})
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/analytics/service"
+	"bookstore-backend/internal/shared/utils"
+)
+
+// RecordActivityHandler ghi event giỏ / đơn vào feed hoạt động của khách
+type RecordActivityHandler struct {
+	service service.ServiceInterface
+}
+
+func NewRecordActivityHandler(service service.ServiceInterface) *RecordActivityHandler {
+	return &RecordActivityHandler{service: service}
+}
+
+func (h *RecordActivityHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.RecordActivityPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	if err := h.service.RecordActivity(ctx, payload.Activity); err != nil {
+		// Payload sai không sửa được bằng retry
+		if errors.Is(err, model.ErrUnknownActivity) || errors.Is(err, model.ErrMissingIdentity) {
+			return fmt.Errorf("record customer activity: %v: %w", err, asynq.SkipRetry)
+		}
+		return fmt.Errorf("record customer activity: %w", err)
+	}
+
+	return nil
+}
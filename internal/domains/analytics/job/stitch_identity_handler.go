@@ -0,0 +1,42 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/analytics/service"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// StitchIdentityHandler gắn session ẩn danh vào user sau login / MergeCart
+type StitchIdentityHandler struct {
+	service service.ServiceInterface
+}
+
+func NewStitchIdentityHandler(service service.ServiceInterface) *StitchIdentityHandler {
+	return &StitchIdentityHandler{service: service}
+}
+
+func (h *StitchIdentityHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.StitchIdentityPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	attributed, err := h.service.StitchIdentity(ctx, payload.SessionID, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("stitch identity: %w", err)
+	}
+
+	logger.Info("Stitched anonymous session to user", map[string]interface{}{
+		"session_id": payload.SessionID,
+		"user_id":    payload.UserID,
+		"attributed": attributed,
+	})
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/analytics/service"
+	"bookstore-backend/internal/shared/utils"
+)
+
+// TrackEventHandler ghi event từ request path vào analytics_events
+type TrackEventHandler struct {
+	service service.ServiceInterface
+}
+
+func NewTrackEventHandler(service service.ServiceInterface) *TrackEventHandler {
+	return &TrackEventHandler{service: service}
+}
+
+func (h *TrackEventHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.TrackEventPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	if err := h.service.RecordEvent(ctx, payload.Event); err != nil {
+		return fmt.Errorf("record analytics event: %w", err)
+	}
+
+	return nil
+}
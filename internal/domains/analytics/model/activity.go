@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// CUSTOMER ACTIVITY (lịch sử giỏ / đơn trong trang tài khoản)
+// ========================================
+// Cart / order service publish qua task analytics:record_activity, worker ghi customer_activity_events
+
+// Activity domains
+const (
+	ActivityDomainCart  = "cart"
+	ActivityDomainOrder = "order"
+)
+
+// Activity event types
+const (
+	ActivityCartItemAdded    = "item_added"
+	ActivityCartItemRemoved  = "item_removed"
+	ActivityCartPromoApplied = "promo_applied"
+	ActivityCartPromoRemoved = "promo_removed"
+	ActivityCartPromoExpired = "promo_expired" // job gỡ mã hết hạn / hết lượt
+
+	ActivityOrderPlaced        = "order_placed"
+	ActivityOrderStatusChanged = "status_changed"
+	ActivityOrderCancelled     = "order_cancelled"
+)
+
+// activityTypes - event type hợp lệ theo domain
+var activityTypes = map[string][]string{
+	ActivityDomainCart: {
+		ActivityCartItemAdded, ActivityCartItemRemoved,
+		ActivityCartPromoApplied, ActivityCartPromoRemoved, ActivityCartPromoExpired,
+	},
+	ActivityDomainOrder: {
+		ActivityOrderPlaced, ActivityOrderStatusChanged, ActivityOrderCancelled,
+	},
+}
+
+// Retention + paging
+const (
+	// ActivityRetentionPerUser - mỗi (user, domain) giữ tối đa N event mới nhất, cắt lúc ghi
+	ActivityRetentionPerUser = 200
+	DefaultActivityPageSize  = 20
+	MaxActivityPageSize      = 100
+)
+
+// ActivityEvent represents customer_activity_events table
+type ActivityEvent struct {
+	ID         uuid.UUID              `json:"id"`
+	UserID     uuid.UUID              `json:"-"`
+	Domain     string                 `json:"domain"`
+	EventType  string                 `json:"event_type"`
+	CartID     *uuid.UUID             `json:"cart_id,omitempty"`
+	OrderID    *uuid.UUID             `json:"order_id,omitempty"`
+	BookID     *uuid.UUID             `json:"book_id,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"` // book_title, promo_code, order_number, from_status, to_status...
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// IsValidActivity kiểm tra domain + event type
+func IsValidActivity(domain, eventType string) bool {
+	for _, t := range activityTypes[domain] {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordActivityPayload - task analytics:record_activity
+type RecordActivityPayload struct {
+	Activity ActivityEvent `json:"activity"`
+}
+
+// ListActivityRequest - Domain rỗng = cả giỏ lẫn đơn
+type ListActivityRequest struct {
+	Domain string    `form:"domain"`
+	Page   int       `form:"page"`
+	Limit  int       `form:"limit"`
+	UserID uuid.UUID `form:"-"`
+}
+
+type ListActivityResponse struct {
+	Items      []ActivityEvent `json:"items"`
+	TotalItems int             `json:"total_items"`
+	TotalPages int             `json:"total_pages"`
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+}
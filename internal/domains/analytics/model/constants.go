@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Event types
+const (
+	EventBookView          = "book_view"
+	EventCartCreated       = "cart_created"
+	EventCartAdd           = "cart_add"
+	EventCartRenewed       = "cart_renewed" // khách quay lại giỏ → gia hạn expires_at
+	EventCheckoutStarted   = "checkout_started"
+	EventCheckoutCompleted = "checkout_completed"
+)
+
+// Traffic source
+const (
+	TrafficSourceDirect    = "direct" // không có utm_source / referrer ngoài
+	MaxTrafficSourceLength = 50
+	// AttributionWindow - tìm first-touch source trong 30 ngày trước ngày tính
+	AttributionWindow = 30 * 24 * time.Hour
+)
+
+// Funnel metrics
+const (
+	FunnelDateLayout       = "2006-01-02"
+	DefaultFunnelRangeDays = 30
+	MaxFunnelRangeDays     = 366
+	// FunnelRecomputeDays - job mỗi giờ tính lại hôm nay + hôm qua (đơn thanh toán muộn, event tới muộn)
+	FunnelRecomputeDays = 2
+)
+
+// Backfill
+const (
+	DefaultBackfillBatchSize = 500
+	// BackfillWindow - chỉ quét identity được stitch trong 30 ngày (session cookie sống 30 ngày)
+	BackfillWindow = 30 * 24 * time.Hour
+)
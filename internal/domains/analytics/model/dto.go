@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FunnelQueryRequest - query params cho admin funnel endpoints
+type FunnelQueryRequest struct {
+	From   string `form:"from"` // YYYY-MM-DD, default: 30 ngày trước
+	To     string `form:"to"`   // YYYY-MM-DD, default: hôm nay
+	Source string `form:"source"`
+}
+
+// RecomputeFunnelRequest - admin yêu cầu tính lại funnel
+type RecomputeFunnelRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// FunnelStages - số liệu từng bước + tỉ lệ chuyển đổi giữa các bước
+type FunnelStages struct {
+	CartsCreated     int             `json:"carts_created"`
+	ItemsAdded       int             `json:"items_added"`
+	CheckoutsStarted int             `json:"checkouts_started"`
+	OrdersCreated    int             `json:"orders_created"`
+	OrdersPaid       int             `json:"orders_paid"`
+	PaidRevenue      decimal.Decimal `json:"paid_revenue"`
+
+	// Conversion rates (%), 0 khi bước trước = 0
+	CartToItemRate      float64 `json:"cart_to_item_rate"`
+	ItemToCheckoutRate  float64 `json:"item_to_checkout_rate"`
+	CheckoutToOrderRate float64 `json:"checkout_to_order_rate"`
+	OrderToPaidRate     float64 `json:"order_to_paid_rate"`
+	OverallRate         float64 `json:"overall_rate"` // carts_created → paid
+}
+
+// FunnelDayResponse - 1 ngày trong time series
+type FunnelDayResponse struct {
+	Date string `json:"date"`
+	FunnelStages
+}
+
+// FunnelSourceResponse - tổng theo traffic source trong khoảng ngày
+type FunnelSourceResponse struct {
+	TrafficSource string `json:"traffic_source"`
+	FunnelStages
+}
+
+// FunnelTimeSeriesResponse - GET /admin/analytics/funnel
+type FunnelTimeSeriesResponse struct {
+	From   string              `json:"from"`
+	To     string              `json:"to"`
+	Source string              `json:"source,omitempty"`
+	Total  FunnelStages        `json:"total"`
+	Days   []FunnelDayResponse `json:"days"`
+}
+
+// FunnelBySourceResponse - GET /admin/analytics/funnel/sources
+type FunnelBySourceResponse struct {
+	From    string                 `json:"from"`
+	To      string                 `json:"to"`
+	Sources []FunnelSourceResponse `json:"sources"`
+}
+
+// RecomputeFunnelResponse - POST /admin/analytics/funnel/recompute
+type RecomputeFunnelResponse struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
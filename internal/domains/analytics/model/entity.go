@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Event - 1 bước trong funnel (xem sách, thêm giỏ, checkout).
+// Khách vãng lai chỉ có SessionID; UserID được điền sau khi stitch.
+type Event struct {
+	ID         uuid.UUID              `json:"id"`
+	EventType  string                 `json:"event_type"`
+	SessionID  *string                `json:"session_id,omitempty"`
+	UserID     *uuid.UUID             `json:"user_id,omitempty"`
+	BookID     *uuid.UUID             `json:"book_id,omitempty"`
+	OrderID    *uuid.UUID             `json:"order_id,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	// TrafficSource - utm_source / referrer ngoài (chỉ có ở event từ request path)
+	TrafficSource *string `json:"traffic_source,omitempty"`
+}
+
+// Identity - mapping session_id → user_id (ghi lúc login)
+type Identity struct {
+	SessionID        string     `json:"session_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	StitchedAt       time.Time  `json:"stitched_at"`
+	LastBackfilledAt *time.Time `json:"last_backfilled_at,omitempty"`
+}
+
+// FunnelDailyMetric - 1 row của cart_funnel_daily_metrics
+type FunnelDailyMetric struct {
+	MetricDate       time.Time       `json:"metric_date"`
+	TrafficSource    string          `json:"traffic_source"`
+	CartsCreated     int             `json:"carts_created"`
+	ItemsAdded       int             `json:"items_added"`
+	CheckoutsStarted int             `json:"checkouts_started"`
+	OrdersCreated    int             `json:"orders_created"`
+	OrdersPaid       int             `json:"orders_paid"`
+	PaidRevenue      decimal.Decimal `json:"paid_revenue"`
+	ComputedAt       time.Time       `json:"computed_at"`
+}
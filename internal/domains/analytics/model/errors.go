@@ -0,0 +1,21 @@
+package model
+
+import "errors"
+
+var (
+	ErrMissingIdentity  = errors.New("analytics event requires session_id or user_id")
+	ErrUnknownEventType = errors.New("unknown analytics event type")
+	ErrInvalidDateRange = errors.New("invalid date range")
+
+	// Customer activity
+	ErrUnknownActivity = errors.New("unknown activity domain or event type")
+)
+
+// IsValidEventType kiểm tra event type có được hỗ trợ
+func IsValidEventType(eventType string) bool {
+	switch eventType {
+	case EventBookView, EventCartCreated, EventCartAdd, EventCartRenewed, EventCheckoutStarted, EventCheckoutCompleted:
+		return true
+	}
+	return false
+}
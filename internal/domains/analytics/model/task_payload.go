@@ -0,0 +1,27 @@
+package model
+
+import "github.com/google/uuid"
+
+// TrackEventPayload - ghi 1 event async (không làm chậm request)
+type TrackEventPayload struct {
+	Event Event `json:"event"`
+}
+
+// StitchIdentityPayload - gắn session ẩn danh vào user sau login
+type StitchIdentityPayload struct {
+	SessionID string    `json:"session_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+// BackfillIdentitiesPayload - scheduled job, điền user_id cho event tới muộn
+type BackfillIdentitiesPayload struct {
+	BatchSize int `json:"batch_size"`
+}
+
+// ComputeFunnelPayload - tính lại funnel cho khoảng ngày [From, To] (YYYY-MM-DD, UTC).
+// Để trống From/To → tính lại `Days` ngày gần nhất (scheduled job)
+type ComputeFunnelPayload struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	Days int    `json:"days,omitempty"`
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// CUSTOMER ACTIVITY
+// =====================================================
+
+func (r *postgresRepository) InsertActivity(ctx context.Context, activity *model.ActivityEvent, keep int) error {
+	if activity.ID == uuid.Nil {
+		activity.ID = uuid.New()
+	}
+	if activity.OccurredAt.IsZero() {
+		activity.OccurredAt = time.Now()
+	}
+	if activity.Properties == nil {
+		activity.Properties = map[string]interface{}{}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin activity tx: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO customer_activity_events (
+			id, user_id, domain, event_type, cart_id, order_id, book_id, properties, occurred_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		activity.ID, activity.UserID, activity.Domain, activity.EventType,
+		activity.CartID, activity.OrderID, activity.BookID, activity.Properties, activity.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert customer activity: %w", err)
+	}
+
+	// Retention theo user: giữ `keep` event mới nhất của (user, domain)
+	_, err = tx.Exec(ctx, `
+		DELETE FROM customer_activity_events
+		WHERE user_id = $1 AND domain = $2
+		  AND id NOT IN (
+			SELECT id FROM customer_activity_events
+			WHERE user_id = $1 AND domain = $2
+			ORDER BY occurred_at DESC
+			LIMIT $3
+		  )
+	`, activity.UserID, activity.Domain, keep)
+	if err != nil {
+		return fmt.Errorf("trim customer activity: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit activity tx: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListActivity(ctx context.Context, req model.ListActivityRequest) ([]model.ActivityEvent, int, error) {
+	where := "user_id = $1"
+	args := []interface{}{req.UserID}
+	if req.Domain != "" {
+		args = append(args, req.Domain)
+		where += fmt.Sprintf(" AND domain = $%d", len(args))
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM customer_activity_events WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count customer activity: %w", err)
+	}
+
+	args = append(args, req.Limit, (req.Page-1)*req.Limit)
+	query := fmt.Sprintf(`
+		SELECT id, user_id, domain, event_type, cart_id, order_id, book_id, properties, occurred_at
+		FROM customer_activity_events
+		WHERE %s
+		ORDER BY occurred_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list customer activity: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.ActivityEvent, 0)
+	for rows.Next() {
+		var a model.ActivityEvent
+		if err := rows.Scan(
+			&a.ID, &a.UserID, &a.Domain, &a.EventType,
+			&a.CartID, &a.OrderID, &a.BookID, &a.Properties, &a.OccurredAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan customer activity: %w", err)
+		}
+		items = append(items, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate customer activity: %w", err)
+	}
+
+	return items, total, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/analytics/model"
+)
+
+// Repository - funnel events + session/user identity mapping
+type Repository interface {
+	// InsertEvent ghi event; nếu session đã được stitch thì tự gắn user_id
+	InsertEvent(ctx context.Context, event *model.Event) error
+
+	// UpsertIdentity map session → user (login lại trên cùng session sẽ ghi đè)
+	UpsertIdentity(ctx context.Context, sessionID string, userID uuid.UUID) error
+
+	// BackfillSession điền user_id cho event chưa gắn user của 1 session
+	BackfillSession(ctx context.Context, sessionID string, userID uuid.UUID) (int64, error)
+
+	// BackfillPending điền user_id cho event tới muộn của các session đã stitch từ `since`
+	BackfillPending(ctx context.Context, since time.Time, limit int) (int64, error)
+
+	// Funnel metrics
+	ComputeFunnelDay(ctx context.Context, day time.Time) (int, error)
+	ListFunnelMetrics(ctx context.Context, from, to time.Time, source string) ([]model.FunnelDailyMetric, error)
+
+	// Customer activity feed
+	// InsertActivity ghi event rồi cắt bớt event cũ của (user, domain) vượt keep
+	InsertActivity(ctx context.Context, activity *model.ActivityEvent, keep int) error
+	ListActivity(ctx context.Context, req model.ListActivityRequest) ([]model.ActivityEvent, int, error)
+}
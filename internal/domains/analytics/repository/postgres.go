@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/pkg/database"
+)
+
+type postgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) Repository {
+	return &postgresRepository{pool: pool}
+}
+
+// =====================================================
+// EVENTS
+// =====================================================
+
+func (r *postgresRepository) InsertEvent(ctx context.Context, event *model.Event) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Properties == nil {
+		event.Properties = map[string]interface{}{}
+	}
+
+	// Session đã login trước đó → gắn user_id ngay, không cần chờ backfill
+	query := `
+		INSERT INTO analytics_events (
+			id, event_type, session_id, user_id, book_id, order_id, properties, occurred_at, traffic_source
+		)
+		VALUES (
+			$1, $2, $3,
+			COALESCE($4, (SELECT user_id FROM analytics_identities WHERE session_id = $3)),
+			$5, $6, $7, $8, $9
+		)
+		RETURNING user_id
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		event.ID, event.EventType, event.SessionID, event.UserID,
+		event.BookID, event.OrderID, event.Properties, event.OccurredAt, event.TrafficSource,
+	).Scan(&event.UserID)
+	if err != nil {
+		return fmt.Errorf("insert analytics event: %w", err)
+	}
+
+	return nil
+}
+
+// =====================================================
+// IDENTITY STITCHING
+// =====================================================
+
+func (r *postgresRepository) UpsertIdentity(ctx context.Context, sessionID string, userID uuid.UUID) error {
+	query := `
+		INSERT INTO analytics_identities (session_id, user_id, stitched_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (session_id) DO UPDATE
+		SET user_id = EXCLUDED.user_id,
+			stitched_at = NOW()
+	`
+	if _, err := r.pool.Exec(ctx, query, sessionID, userID); err != nil {
+		return fmt.Errorf("upsert analytics identity: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) BackfillSession(ctx context.Context, sessionID string, userID uuid.UUID) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE analytics_events
+		SET user_id = $2
+		WHERE session_id = $1 AND user_id IS NULL
+	`, sessionID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("backfill session events: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE analytics_identities
+		SET last_backfilled_at = NOW()
+		WHERE session_id = $1
+	`, sessionID); err != nil {
+		return 0, fmt.Errorf("mark identity backfilled: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *postgresRepository) BackfillPending(ctx context.Context, since time.Time, limit int) (int64, error) {
+	// SKIP LOCKED: an toàn khi 2 worker chạy trùng
+	query := `
+		WITH pending AS (
+			SELECT e.id, i.user_id
+			FROM analytics_events e
+			JOIN analytics_identities i ON i.session_id = e.session_id
+			WHERE e.user_id IS NULL
+			  AND i.stitched_at >= $1
+			LIMIT $2
+			FOR UPDATE OF e SKIP LOCKED
+		),
+		updated AS (
+			UPDATE analytics_events e
+			SET user_id = p.user_id
+			FROM pending p
+			WHERE e.id = p.id
+			RETURNING e.session_id
+		),
+		touched AS (
+			UPDATE analytics_identities i
+			SET last_backfilled_at = NOW()
+			WHERE i.session_id IN (SELECT DISTINCT session_id FROM updated)
+		)
+		SELECT COUNT(*) FROM updated
+	`
+
+	var count int64
+	if err := r.pool.QueryRow(ctx, query, since, limit).Scan(&count); err != nil {
+		return 0, fmt.Errorf("backfill pending events: %w", err)
+	}
+
+	return count, nil
+}
+
+// =====================================================
+// FUNNEL METRICS
+// =====================================================
+
+// ComputeFunnelDay tính lại funnel cho 1 ngày (UTC) và ghi đè các row của ngày đó.
+//
+// Identity = user_id (nếu đã login/stitch) hoặc session_id → cùng 1 người chỉ đếm 1 lần mỗi bước.
+// Traffic source = first-touch trong AttributionWindow, không có → 'direct'.
+// Bước order/paid đếm theo số đơn (cohort theo ngày tạo đơn).
+func (r *postgresRepository) ComputeFunnelDay(ctx context.Context, day time.Time) (int, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	attributionStart := start.Add(-model.AttributionWindow)
+
+	query := `
+		WITH ev AS (
+			SELECT DISTINCT COALESCE(e.user_id::text, 's:' || e.session_id) AS identity, e.event_type AS stage
+			FROM analytics_events e
+			WHERE e.occurred_at >= $1 AND e.occurred_at < $2
+			  AND e.event_type IN ('cart_created', 'cart_add', 'checkout_started')
+		),
+		ord AS (
+			SELECT o.user_id::text AS identity, o.paid_at IS NOT NULL AS is_paid, o.total
+			FROM orders o
+			WHERE o.created_at >= $1 AND o.created_at < $2
+		),
+		stages AS (
+			SELECT identity, stage, NULL::numeric AS revenue FROM ev
+			UNION ALL
+			SELECT identity, 'order_created', NULL FROM ord
+			UNION ALL
+			SELECT identity, 'order_paid', total FROM ord WHERE is_paid
+		),
+		first_touch AS (
+			SELECT DISTINCT ON (identity) identity, traffic_source
+			FROM (
+				SELECT COALESCE(e.user_id::text, 's:' || e.session_id) AS identity, e.traffic_source, e.occurred_at
+				FROM analytics_events e
+				WHERE e.traffic_source IS NOT NULL
+				  AND e.occurred_at >= $3 AND e.occurred_at < $2
+			) t
+			ORDER BY identity, occurred_at ASC
+		)
+		INSERT INTO cart_funnel_daily_metrics (
+			metric_date, traffic_source,
+			carts_created, items_added, checkouts_started,
+			orders_created, orders_paid, paid_revenue, computed_at
+		)
+		SELECT
+			$1::date,
+			COALESCE(ft.traffic_source, 'direct'),
+			COUNT(DISTINCT s.identity) FILTER (WHERE s.stage = 'cart_created'),
+			COUNT(DISTINCT s.identity) FILTER (WHERE s.stage = 'cart_add'),
+			COUNT(DISTINCT s.identity) FILTER (WHERE s.stage = 'checkout_started'),
+			COUNT(*) FILTER (WHERE s.stage = 'order_created'),
+			COUNT(*) FILTER (WHERE s.stage = 'order_paid'),
+			COALESCE(SUM(s.revenue) FILTER (WHERE s.stage = 'order_paid'), 0),
+			NOW()
+		FROM stages s
+		LEFT JOIN first_touch ft ON ft.identity = s.identity
+		GROUP BY COALESCE(ft.traffic_source, 'direct')
+	`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Xoá row cũ của ngày → source không còn xuất hiện cũng bị dọn
+	if _, err := tx.Exec(ctx, `DELETE FROM cart_funnel_daily_metrics WHERE metric_date = $1::date`, start); err != nil {
+		return 0, fmt.Errorf("clear funnel day: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, query, start, end, attributionStart)
+	if err != nil {
+		return 0, fmt.Errorf("compute funnel day: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *postgresRepository) ListFunnelMetrics(ctx context.Context, from, to time.Time, source string) ([]model.FunnelDailyMetric, error) {
+	query := `
+		SELECT metric_date, traffic_source,
+			carts_created, items_added, checkouts_started,
+			orders_created, orders_paid, paid_revenue, computed_at
+		FROM cart_funnel_daily_metrics
+		WHERE metric_date >= $1::date AND metric_date <= $2::date
+		  AND ($3 = '' OR traffic_source = $3)
+		ORDER BY metric_date ASC, traffic_source ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, from, to, source)
+	if err != nil {
+		return nil, fmt.Errorf("list funnel metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := make([]model.FunnelDailyMetric, 0)
+	for rows.Next() {
+		var m model.FunnelDailyMetric
+		if err := rows.Scan(
+			&m.MetricDate, &m.TrafficSource,
+			&m.CartsCreated, &m.ItemsAdded, &m.CheckoutsStarted,
+			&m.OrdersCreated, &m.OrdersPaid, &m.PaidRevenue, &m.ComputedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan funnel metric: %w", err)
+		}
+		metrics = append(metrics, m)
+	}
+
+	return metrics, rows.Err()
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/analytics/model"
+)
+
+// =====================================================
+// CUSTOMER ACTIVITY
+// =====================================================
+
+func (s *service) RecordActivity(ctx context.Context, activity model.ActivityEvent) error {
+	if activity.UserID == uuid.Nil {
+		return model.ErrMissingIdentity
+	}
+	if !model.IsValidActivity(activity.Domain, activity.EventType) {
+		return model.ErrUnknownActivity
+	}
+	return s.repo.InsertActivity(ctx, &activity, model.ActivityRetentionPerUser)
+}
+
+func (s *service) ListActivity(ctx context.Context, req model.ListActivityRequest) (*model.ListActivityResponse, error) {
+	if req.Domain != "" && req.Domain != model.ActivityDomainCart && req.Domain != model.ActivityDomainOrder {
+		return nil, model.ErrUnknownActivity
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = model.DefaultActivityPageSize
+	}
+	if req.Limit > model.MaxActivityPageSize {
+		req.Limit = model.MaxActivityPageSize
+	}
+
+	items, totalItems, err := s.repo.ListActivity(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (totalItems + req.Limit - 1) / req.Limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &model.ListActivityResponse{
+		Items:      items,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// COMPUTE (worker)
+// =====================================================
+
+func (s *service) ComputeFunnel(ctx context.Context, from, to time.Time) (int, error) {
+	if to.Before(from) {
+		return 0, model.ErrInvalidDateRange
+	}
+
+	total := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		rows, err := s.repo.ComputeFunnelDay(ctx, day)
+		if err != nil {
+			return total, fmt.Errorf("compute funnel %s: %w", day.Format(model.FunnelDateLayout), err)
+		}
+		total += rows
+	}
+
+	return total, nil
+}
+
+// =====================================================
+// ADMIN QUERIES
+// =====================================================
+
+func (s *service) GetFunnelTimeSeries(ctx context.Context, req model.FunnelQueryRequest) (*model.FunnelTimeSeriesResponse, error) {
+	from, to, err := ParseFunnelRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.repo.ListFunnelMetrics(ctx, from, to, req.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gộp các source theo ngày (khi không lọc source)
+	byDate := make(map[string]*model.FunnelStages)
+	var total model.FunnelStages
+	for _, m := range metrics {
+		key := m.MetricDate.Format(model.FunnelDateLayout)
+		stages, ok := byDate[key]
+		if !ok {
+			stages = &model.FunnelStages{PaidRevenue: decimal.Zero}
+			byDate[key] = stages
+		}
+		addMetric(stages, m)
+		addMetric(&total, m)
+	}
+
+	// Trả đủ mọi ngày trong khoảng (ngày không có data = 0) để chart không bị hở
+	days := make([]model.FunnelDayResponse, 0, int(to.Sub(from).Hours()/24)+1)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(model.FunnelDateLayout)
+		stages := model.FunnelStages{PaidRevenue: decimal.Zero}
+		if existing, ok := byDate[key]; ok {
+			stages = *existing
+		}
+		calculateRates(&stages)
+		days = append(days, model.FunnelDayResponse{Date: key, FunnelStages: stages})
+	}
+	calculateRates(&total)
+
+	return &model.FunnelTimeSeriesResponse{
+		From:   from.Format(model.FunnelDateLayout),
+		To:     to.Format(model.FunnelDateLayout),
+		Source: req.Source,
+		Total:  total,
+		Days:   days,
+	}, nil
+}
+
+func (s *service) GetFunnelBySource(ctx context.Context, req model.FunnelQueryRequest) (*model.FunnelBySourceResponse, error) {
+	from, to, err := ParseFunnelRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.repo.ListFunnelMetrics(ctx, from, to, req.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	bySource := make(map[string]*model.FunnelStages)
+	for _, m := range metrics {
+		stages, ok := bySource[m.TrafficSource]
+		if !ok {
+			stages = &model.FunnelStages{PaidRevenue: decimal.Zero}
+			bySource[m.TrafficSource] = stages
+		}
+		addMetric(stages, m)
+	}
+
+	sources := make([]model.FunnelSourceResponse, 0, len(bySource))
+	for source, stages := range bySource {
+		calculateRates(stages)
+		sources = append(sources, model.FunnelSourceResponse{TrafficSource: source, FunnelStages: *stages})
+	}
+
+	// Source nhiều cart nhất lên đầu
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].CartsCreated != sources[j].CartsCreated {
+			return sources[i].CartsCreated > sources[j].CartsCreated
+		}
+		return sources[i].TrafficSource < sources[j].TrafficSource
+	})
+
+	return &model.FunnelBySourceResponse{
+		From:    from.Format(model.FunnelDateLayout),
+		To:      to.Format(model.FunnelDateLayout),
+		Sources: sources,
+	}, nil
+}
+
+func (s *service) EnqueueFunnelRecompute(ctx context.Context, req model.RecomputeFunnelRequest) (*model.RecomputeFunnelResponse, error) {
+	from, to, err := ParseFunnelRange(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := model.ComputeFunnelPayload{
+		From: from.Format(model.FunnelDateLayout),
+		To:   to.Format(model.FunnelDateLayout),
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeComputeFunnel, payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal compute funnel task: %w", err)
+	}
+
+	if _, err := s.asynqClient.EnqueueContext(ctx, task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(1),
+		asynq.Timeout(30*time.Minute),
+	); err != nil {
+		return nil, fmt.Errorf("enqueue compute funnel task: %w", err)
+	}
+
+	logger.Info("Enqueued funnel recompute", map[string]interface{}{
+		"from": payload.From,
+		"to":   payload.To,
+	})
+
+	return &model.RecomputeFunnelResponse{
+		From:       payload.From,
+		To:         payload.To,
+		EnqueuedAt: time.Now(),
+	}, nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+// ParseFunnelRange parse from/to (YYYY-MM-DD, UTC, inclusive).
+// Default: DefaultFunnelRangeDays ngày gần nhất
+func ParseFunnelRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	to := today
+	if toStr != "" {
+		parsed, err := time.Parse(model.FunnelDateLayout, toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be YYYY-MM-DD", model.ErrInvalidDateRange)
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(model.DefaultFunnelRangeDays - 1))
+	if fromStr != "" {
+		parsed, err := time.Parse(model.FunnelDateLayout, fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be YYYY-MM-DD", model.ErrInvalidDateRange)
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be before to", model.ErrInvalidDateRange)
+	}
+	if to.Sub(from) > time.Duration(model.MaxFunnelRangeDays-1)*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: range must not exceed %d days", model.ErrInvalidDateRange, model.MaxFunnelRangeDays)
+	}
+
+	return from, to, nil
+}
+
+func addMetric(stages *model.FunnelStages, m model.FunnelDailyMetric) {
+	stages.CartsCreated += m.CartsCreated
+	stages.ItemsAdded += m.ItemsAdded
+	stages.CheckoutsStarted += m.CheckoutsStarted
+	stages.OrdersCreated += m.OrdersCreated
+	stages.OrdersPaid += m.OrdersPaid
+	stages.PaidRevenue = stages.PaidRevenue.Add(m.PaidRevenue)
+}
+
+func calculateRates(stages *model.FunnelStages) {
+	stages.CartToItemRate = rate(stages.ItemsAdded, stages.CartsCreated)
+	stages.ItemToCheckoutRate = rate(stages.CheckoutsStarted, stages.ItemsAdded)
+	stages.CheckoutToOrderRate = rate(stages.OrdersCreated, stages.CheckoutsStarted)
+	stages.OrderToPaidRate = rate(stages.OrdersPaid, stages.OrdersCreated)
+	stages.OverallRate = rate(stages.OrdersPaid, stages.CartsCreated)
+}
+
+// rate trả % làm tròn 2 chữ số
+func rate(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(int(float64(numerator)*10000/float64(denominator)+0.5)) / 100
+}
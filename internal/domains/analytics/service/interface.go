@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/analytics/model"
+)
+
+type ServiceInterface interface {
+	// TrackEvent enqueue event sang worker (dùng trong request path)
+	TrackEvent(ctx context.Context, event model.Event) error
+
+	// RecordEvent ghi event trực tiếp vào DB (worker)
+	RecordEvent(ctx context.Context, event model.Event) error
+
+	// StitchIdentity map session → user và backfill event cũ của session
+	StitchIdentity(ctx context.Context, sessionID string, userID uuid.UUID) (int64, error)
+
+	// BackfillIdentities điền user_id cho event tới muộn (scheduled job)
+	BackfillIdentities(ctx context.Context, batchSize int) (int64, error)
+
+	// ComputeFunnel tính lại funnel metrics cho từng ngày trong [from, to] (worker)
+	ComputeFunnel(ctx context.Context, from, to time.Time) (int, error)
+
+	// Admin dashboard
+	GetFunnelTimeSeries(ctx context.Context, req model.FunnelQueryRequest) (*model.FunnelTimeSeriesResponse, error)
+	GetFunnelBySource(ctx context.Context, req model.FunnelQueryRequest) (*model.FunnelBySourceResponse, error)
+	EnqueueFunnelRecompute(ctx context.Context, req model.RecomputeFunnelRequest) (*model.RecomputeFunnelResponse, error)
+
+	// Customer activity feed (lịch sử giỏ / đơn trong trang tài khoản)
+	// RecordActivity ghi trực tiếp + cắt retention theo user (worker)
+	RecordActivity(ctx context.Context, activity model.ActivityEvent) error
+	ListActivity(ctx context.Context, req model.ListActivityRequest) (*model.ListActivityResponse, error)
+}
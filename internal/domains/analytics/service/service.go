@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/analytics/repository"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+)
+
+type service struct {
+	repo        repository.Repository
+	asynqClient *asynq.Client
+}
+
+func NewService(repo repository.Repository, asynqClient *asynq.Client) ServiceInterface {
+	return &service{
+		repo:        repo,
+		asynqClient: asynqClient,
+	}
+}
+
+func validateEvent(event *model.Event) error {
+	if !model.IsValidEventType(event.EventType) {
+		return model.ErrUnknownEventType
+	}
+	if event.SessionID != nil && *event.SessionID == "" {
+		event.SessionID = nil
+	}
+	if event.SessionID == nil && event.UserID == nil {
+		return model.ErrMissingIdentity
+	}
+	return nil
+}
+
+func (s *service) TrackEvent(ctx context.Context, event model.Event) error {
+	if err := validateEvent(&event); err != nil {
+		return err
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeTrackEvent, model.TrackEventPayload{Event: event})
+	if err != nil {
+		return fmt.Errorf("marshal track event task: %w", err)
+	}
+
+	_, err = s.asynqClient.EnqueueContext(ctx, task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(1),
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue track event task: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) RecordEvent(ctx context.Context, event model.Event) error {
+	if err := validateEvent(&event); err != nil {
+		return err
+	}
+	return s.repo.InsertEvent(ctx, &event)
+}
+
+func (s *service) StitchIdentity(ctx context.Context, sessionID string, userID uuid.UUID) (int64, error) {
+	if sessionID == "" {
+		return 0, model.ErrMissingIdentity
+	}
+
+	if err := s.repo.UpsertIdentity(ctx, sessionID, userID); err != nil {
+		return 0, err
+	}
+
+	return s.repo.BackfillSession(ctx, sessionID, userID)
+}
+
+func (s *service) BackfillIdentities(ctx context.Context, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = model.DefaultBackfillBatchSize
+	}
+
+	since := time.Now().Add(-model.BackfillWindow)
+	return s.repo.BackfillPending(ctx, since, batchSize)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -317,7 +318,139 @@ func (h *AuthorHandler) GetWithBookCount(c *gin.Context) {
 		}
 		return
 	}
-	response.Success(c, http.StatusOK, "Success", a.ToDetailResponse(bookCount))
+	aliases, err := h.service.ListAliases(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	res := a.ToDetailResponse(bookCount)
+	res.Aliases = make([]string, len(aliases))
+	for i, alias := range aliases {
+		res.Aliases[i] = alias.Name
+	}
+	response.Success(c, http.StatusOK, "Success", res)
+}
+
+// ════════════════════════════════════════════════════════════════
+// ALIASES: GET /v1/authors/:id/aliases
+// ════════════════════════════════════════════════════════════════
+
+func (h *AuthorHandler) ListAliases(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Bad Request", "Invalid UUID format")
+		return
+	}
+
+	aliases, err := h.service.ListAliases(c.Request.Context(), id)
+	if err != nil {
+		response.Error(c, model.ToHTTPStatus(err), model.ToErrorCode(err), err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Success", aliases)
+}
+
+// ════════════════════════════════════════════════════════════════
+// ALIASES: POST /v1/authors/:id/aliases
+// ════════════════════════════════════════════════════════════════
+
+func (h *AuthorHandler) CreateAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Bad Request", "Invalid UUID format")
+		return
+	}
+
+	adminID, ok := adminIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	alias, err := h.service.CreateAlias(c.Request.Context(), id, adminID, &req)
+	if err != nil {
+		response.Error(c, model.ToHTTPStatus(err), model.ToErrorCode(err), err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Create author alias successfully", alias)
+}
+
+// ════════════════════════════════════════════════════════════════
+// ALIASES: DELETE /v1/authors/:id/aliases/:alias_id
+// ════════════════════════════════════════════════════════════════
+
+func (h *AuthorHandler) DeleteAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Bad Request", "Invalid UUID format")
+		return
+	}
+	aliasID, err := uuid.Parse(c.Param("alias_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Bad Request", "Invalid alias UUID format")
+		return
+	}
+
+	if err := h.service.DeleteAlias(c.Request.Context(), id, aliasID); err != nil {
+		response.Error(c, model.ToHTTPStatus(err), model.ToErrorCode(err), err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Delete author alias successfully", nil)
+}
+
+// ════════════════════════════════════════════════════════════════
+// MERGE: POST /v1/authors/:id/merge
+// :id = tác giả giữ lại, body.source_author_id = tác giả bị gộp
+// ════════════════════════════════════════════════════════════════
+
+func (h *AuthorHandler) Merge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Bad Request", "Invalid UUID format")
+		return
+	}
+
+	adminID, ok := adminIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req model.MergeAuthorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Bad Request", err.Error())
+		return
+	}
+
+	result, err := h.service.MergeAuthors(c.Request.Context(), id, adminID, &req)
+	if err != nil {
+		response.Error(c, model.ToHTTPStatus(err), model.ToErrorCode(err), err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Merge authors successfully", result)
+}
+
+func adminIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user_id not found in context")
+		return uuid.Nil, false
+	}
+	adminID, err := uuid.Parse(fmt.Sprint(userID))
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "invalid user_id in context")
+		return uuid.Nil, false
+	}
+	return adminID, true
 }
 
 // ════════════════════════════════════════════════════════════════
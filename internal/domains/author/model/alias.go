@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// AUTHOR ALIASES + MERGE
+// ========================================
+// 1 tác giả chuẩn (authors) + nhiều alias (bút danh, tên phiên âm / không dấu).
+// Search sách / tác giả theo alias ra tác giả chuẩn; gộp tác giả trùng → tên + slug cũ thành alias
+
+// Alias sources
+const (
+	AliasSourceManual = "manual" // admin thêm
+	AliasSourceMerge  = "merge"  // tên tác giả bị gộp
+)
+
+// AuthorAlias represents author_aliases table
+type AuthorAlias struct {
+	ID       uuid.UUID `json:"id"`
+	AuthorID uuid.UUID `json:"author_id"`
+	Name     string    `json:"name"`
+	// NormalizedName bỏ dấu + lowercase + gộp khoảng trắng (DB generated), unique toàn hệ thống
+	NormalizedName string     `json:"normalized_name"`
+	Slug           *string    `json:"slug,omitempty"` // slug tác giả đã gộp
+	Source         string     `json:"source"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// AuthorMergeResult kết quả gộp tác giả
+type AuthorMergeResult struct {
+	SourceAuthorID uuid.UUID   `json:"source_author_id"`
+	TargetAuthorID uuid.UUID   `json:"target_author_id"`
+	BooksMoved     int         `json:"books_moved"`
+	AliasesMoved   int         `json:"aliases_moved"`
+	Alias          AuthorAlias `json:"alias"` // tên + slug của tác giả bị gộp
+	// BookIDs sách đã chuyển (xoá cache detail)
+	BookIDs []uuid.UUID `json:"-"`
+}
+
+// CreateAliasRequest - POST /v1/authors/:id/aliases
+type CreateAliasRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// MergeAuthorsRequest - POST /v1/authors/:id/merge (:id = tác giả giữ lại)
+type MergeAuthorsRequest struct {
+	SourceAuthorID uuid.UUID `json:"source_author_id" binding:"required"`
+}
@@ -88,7 +88,8 @@ type AuthorResponse struct {
 // AuthorDetailResponse - Detailed author with relationships
 type AuthorDetailResponse struct {
 	AuthorResponse
-	BookCount int `json:"book_count"` // Aggregated data
+	BookCount int      `json:"book_count"` // Aggregated data
+	Aliases   []string `json:"aliases"`    // Bút danh / tên phiên âm
 }
 
 // AuthorListResponse - Paginated list response
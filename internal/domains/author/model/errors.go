@@ -15,6 +15,12 @@ var (
 	ErrAuthorHasBooks  = errors.New("cannot delete author with linked books")
 	ErrVersionMismatch = errors.New("author version mismatch - conflict detected")
 
+	// Alias / merge
+	ErrAliasNotFound = errors.New("author alias not found")
+	// Tên (đã chuẩn hoá) đã là alias hoặc tên của tác giả khác → gộp tác giả thay vì tạo trùng
+	ErrAliasTaken   = errors.New("name is already used by another author or alias")
+	ErrInvalidMerge = errors.New("invalid author merge")
+
 	// Database Errors
 	ErrDatabaseConnection = errors.New("database connection error")
 	ErrDatabaseQuery      = errors.New("database query error")
@@ -40,6 +46,12 @@ func ToErrorCode(err error) string {
 		return "VERSION_CONFLICT"
 	case ErrInvalidName:
 		return "INVALID_NAME"
+	case ErrAliasNotFound:
+		return "ALIAS_NOT_FOUND"
+	case ErrAliasTaken:
+		return "ALIAS_TAKEN"
+	case ErrInvalidMerge:
+		return "INVALID_MERGE"
 	default:
 		return "INTERNAL_ERROR"
 	}
@@ -48,15 +60,15 @@ func ToErrorCode(err error) string {
 // ToHTTPStatus converts error to HTTP status code
 func ToHTTPStatus(err error) int {
 	switch err {
-	case ErrAuthorNotFound:
+	case ErrAuthorNotFound, ErrAliasNotFound:
 		return 404
 	case ErrDuplicateSlug:
 		return 409
 	case ErrVersionMismatch:
 		return 409
-	case ErrAuthorHasBooks:
+	case ErrAuthorHasBooks, ErrAliasTaken:
 		return 409
-	case ErrInvalidName, ErrNameTooLong, ErrInvalidSlug, ErrBioTooLong:
+	case ErrInvalidName, ErrNameTooLong, ErrInvalidSlug, ErrBioTooLong, ErrInvalidMerge:
 		return 400
 	default:
 		return 500
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/author/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const aliasColumns = `id, author_id, name, normalized_name, slug, source, created_by, created_at`
+
+func scanAlias(row pgx.Row) (*model.AuthorAlias, error) {
+	var a model.AuthorAlias
+	err := row.Scan(&a.ID, &a.AuthorID, &a.Name, &a.NormalizedName, &a.Slug, &a.Source, &a.CreatedBy, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListAliases alias của 1 tác giả (cũ trước)
+func (r *postgresRepository) ListAliases(ctx context.Context, authorID uuid.UUID) ([]model.AuthorAlias, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+aliasColumns+`
+		FROM author_aliases
+		WHERE author_id = $1
+		ORDER BY created_at, name
+	`, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list author aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []model.AuthorAlias{}
+	for rows.Next() {
+		a, err := scanAlias(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan author alias: %w", err)
+		}
+		aliases = append(aliases, *a)
+	}
+	return aliases, rows.Err()
+}
+
+// CreateAlias thêm alias; trùng normalized_name với alias khác → ErrAliasTaken
+func (r *postgresRepository) CreateAlias(ctx context.Context, alias *model.AuthorAlias) (*model.AuthorAlias, error) {
+	created, err := scanAlias(r.pool.QueryRow(ctx, `
+		INSERT INTO author_aliases (author_id, name, source, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+aliasColumns,
+		alias.AuthorID, alias.Name, alias.Source, alias.CreatedBy,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505": // unique_violation
+				return nil, model.ErrAliasTaken
+			case "23503": // foreign_key_violation
+				return nil, model.ErrAuthorNotFound
+			}
+		}
+		return nil, fmt.Errorf("failed to create author alias: %w", err)
+	}
+
+	r.invalidateListCache(ctx)
+	return created, nil
+}
+
+// DeleteAlias xoá alias của tác giả
+func (r *postgresRepository) DeleteAlias(ctx context.Context, authorID, aliasID uuid.UUID) error {
+	cmdTag, err := r.pool.Exec(ctx, `DELETE FROM author_aliases WHERE id = $1 AND author_id = $2`, aliasID, authorID)
+	if err != nil {
+		return fmt.Errorf("failed to delete author alias: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return model.ErrAliasNotFound
+	}
+
+	r.invalidateListCache(ctx)
+	return nil
+}
+
+// NameUsedByOtherAuthor tên (đã chuẩn hoá) trùng tên hoặc alias của tác giả khác excludeID
+func (r *postgresRepository) NameUsedByOtherAuthor(ctx context.Context, name string, excludeID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM authors
+			WHERE normalize_author_name(name) = normalize_author_name($1) AND id <> $2
+		) OR EXISTS(
+			SELECT 1 FROM author_aliases
+			WHERE normalized_name = normalize_author_name($1) AND author_id <> $2
+		)
+	`
+
+	var used bool
+	if err := r.pool.QueryRow(ctx, query, name, excludeID).Scan(&used); err != nil {
+		return false, fmt.Errorf("failed to check author name: %w", err)
+	}
+	return used, nil
+}
+
+// GetByAliasSlug slug của tác giả đã gộp → tác giả chuẩn
+func (r *postgresRepository) GetByAliasSlug(ctx context.Context, slug string) (*model.Author, error) {
+	var authorID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT author_id FROM author_aliases WHERE slug = $1`, slug).Scan(&authorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrAuthorNotFound
+		}
+		return nil, fmt.Errorf("failed to get author by alias slug: %w", err)
+	}
+	return r.GetByID(ctx, authorID)
+}
+
+// MergeAuthors gộp sourceID vào targetID trong 1 transaction:
+// chuyển sách + alias của source, tên + slug source thành alias của target, xoá source
+func (r *postgresRepository) MergeAuthors(ctx context.Context, sourceID, targetID, adminID uuid.UUID) (*model.AuthorMergeResult, error) {
+	result, err := database.WithTransactionResult(ctx, r.pool, func(tx pgx.Tx) (*model.AuthorMergeResult, error) {
+		// Khoá theo thứ tự id tránh deadlock khi 2 admin gộp chéo
+		rows, err := tx.Query(ctx, `
+			SELECT id, name, slug FROM authors
+			WHERE id = ANY($1)
+			ORDER BY id
+			FOR UPDATE
+		`, []uuid.UUID{sourceID, targetID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock authors: %w", err)
+		}
+		var sourceName, sourceSlug string
+		found := 0
+		for rows.Next() {
+			var id uuid.UUID
+			var name, slug string
+			if err := rows.Scan(&id, &name, &slug); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan author: %w", err)
+			}
+			if id == sourceID {
+				sourceName, sourceSlug = name, slug
+			}
+			found++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to lock authors: %w", err)
+		}
+		if found < 2 {
+			return nil, model.ErrAuthorNotFound
+		}
+
+		result := &model.AuthorMergeResult{SourceAuthorID: sourceID, TargetAuthorID: targetID}
+
+		// 1. Alias của source → target (trước khi chuyển sách để trigger build vector đủ alias)
+		tag, err := tx.Exec(ctx, `UPDATE author_aliases SET author_id = $2 WHERE author_id = $1`, sourceID, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move author aliases: %w", err)
+		}
+		result.AliasesMoved = int(tag.RowsAffected())
+
+		// 2. Tên + slug source thành alias (tên đã là alias của target → gắn thêm slug)
+		alias, err := scanAlias(tx.QueryRow(ctx, `
+			INSERT INTO author_aliases (author_id, name, slug, source, created_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (normalized_name) DO UPDATE
+			SET slug = EXCLUDED.slug, source = EXCLUDED.source
+			RETURNING `+aliasColumns,
+			targetID, sourceName, sourceSlug, model.AliasSourceMerge, adminID,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create merge alias: %w", err)
+		}
+		result.Alias = *alias
+
+		// 3. Sách của source (kể cả sách đã xoá mềm, FK RESTRICT) → target
+		bookRows, err := tx.Query(ctx, `
+			UPDATE books SET author_id = $2, updated_at = NOW()
+			WHERE author_id = $1
+			RETURNING id
+		`, sourceID, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move books: %w", err)
+		}
+		for bookRows.Next() {
+			var bookID uuid.UUID
+			if err := bookRows.Scan(&bookID); err != nil {
+				bookRows.Close()
+				return nil, fmt.Errorf("failed to scan moved book: %w", err)
+			}
+			result.BookIDs = append(result.BookIDs, bookID)
+		}
+		bookRows.Close()
+		if err := bookRows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to move books: %w", err)
+		}
+		result.BooksMoved = len(result.BookIDs)
+
+		// 4. Xoá source
+		if _, err := tx.Exec(ctx, `DELETE FROM authors WHERE id = $1`, sourceID); err != nil {
+			return nil, fmt.Errorf("failed to delete merged author: %w", err)
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Invalidate caches: 2 tác giả, danh sách tác giả, sách đã đổi tác giả
+	if result.Alias.Slug != nil {
+		r.invalidateAuthorCache(ctx, sourceID, *result.Alias.Slug)
+	}
+	r.cache.Delete(ctx, authorCacheKeyPrefix+targetID.String())
+	r.invalidateListCache(ctx)
+	for _, bookID := range result.BookIDs {
+		r.cache.Delete(ctx, "book:detail:"+bookID.String())
+	}
+	r.cache.DeletePattern(ctx, "books:list:*")
+
+	return result, nil
+}
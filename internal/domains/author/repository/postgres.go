@@ -2,6 +2,7 @@ package repository
 
 import (
 	"bookstore-backend/internal/domains/author/model"
+	"bookstore-backend/pkg/database"
 	"context"
 	"encoding/json"
 	"errors"
@@ -194,9 +195,9 @@ func (r *postgresRepository) GetAll(ctx context.Context, filter model.AuthorFilt
 	args := []interface{}{}
 	argPos := 1
 
-	// Add search filter if provided
+	// Add search filter if provided (tên hoặc alias, alias so khớp cả bản không dấu)
 	if filter.Search != "" {
-		queryBuilder.WriteString(fmt.Sprintf(" AND name ILIKE $%d", argPos))
+		queryBuilder.WriteString(fmt.Sprintf(" AND (name ILIKE $%d OR %s)", argPos, aliasMatchCondition(argPos)))
 		args = append(args, "%"+filter.Search+"%")
 		argPos++
 	}
@@ -257,7 +258,7 @@ func (r *postgresRepository) GetAll(ctx context.Context, filter model.AuthorFilt
 	countArgs := []interface{}{}
 
 	if filter.Search != "" {
-		countQuery += " AND name ILIKE $1"
+		countQuery += " AND (name ILIKE $1 OR " + aliasMatchCondition(1) + ")"
 		countArgs = append(countArgs, "%"+filter.Search+"%")
 	}
 
@@ -393,7 +394,7 @@ func (r *postgresRepository) BulkDelete(ctx context.Context, ids []uuid.UUID) (i
 	if err != nil {
 		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback(ctx)
+	defer database.Rollback(ctx, tx)
 
 	// Delete each author individually
 	for _, id := range ids {
@@ -485,6 +486,7 @@ func (r *postgresRepository) GetBookCount(ctx context.Context, authorID uuid.UUI
 
 // Search performs full-text search with relevance ranking
 // Search performs full-text search on slug using PostgreSQL engine
+// Alias (bút danh, tên phiên âm) match → trả về tác giả chuẩn
 func (r *postgresRepository) Search(ctx context.Context, query string, filter model.AuthorFilter) ([]model.Author, int64, error) {
 	sanitizedQuery := normalizeTSQuery(query)
 
@@ -516,6 +518,11 @@ func (r *postgresRepository) Search(ctx context.Context, query string, filter mo
 				ts_rank(to_tsvector('simple', slug), plainto_tsquery('simple', $1)) as rank
 			FROM authors
 			WHERE to_tsvector('simple', slug) @@ plainto_tsquery('simple', $1)
+				OR EXISTS (
+					SELECT 1 FROM author_aliases aa
+					WHERE aa.author_id = authors.id
+						AND to_tsvector('simple', aa.normalized_name) @@ plainto_tsquery('simple', immutable_unaccent($1))
+				)
 		) search_results
 		ORDER BY 
 			rank DESC,
@@ -572,6 +579,11 @@ func (r *postgresRepository) Search(ctx context.Context, query string, filter mo
 		SELECT COUNT(*)
 		FROM authors
 		WHERE to_tsvector('simple', slug) @@ plainto_tsquery('simple', $1)
+			OR EXISTS (
+				SELECT 1 FROM author_aliases aa
+				WHERE aa.author_id = authors.id
+					AND to_tsvector('simple', aa.normalized_name) @@ plainto_tsquery('simple', immutable_unaccent($1))
+			)
 	`
 
 	var total int64
@@ -582,6 +594,15 @@ func (r *postgresRepository) Search(ctx context.Context, query string, filter mo
 	return authors, total, nil
 }
 
+// aliasMatchCondition tác giả có alias khớp pattern $argPos (ILIKE hoặc bản không dấu)
+func aliasMatchCondition(argPos int) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM author_aliases aa
+		WHERE aa.author_id = authors.id
+			AND (aa.name ILIKE $%d OR aa.normalized_name LIKE normalize_author_name($%d))
+	)`, argPos, argPos)
+}
+
 // normalizeTSQuery sanitizes input for PostgreSQL full-text search
 func normalizeTSQuery(input string) string {
 	input = strings.TrimSpace(input)
@@ -610,14 +631,21 @@ func (r *postgresRepository) invalidateListCache(ctx context.Context) {
 	r.cache.DeletePattern(ctx, authorListKeyPrefix+"*")
 }
 
-// FindByNameCaseInsensitive tìm author by name (case-insensitive, trimmed)
+// FindByNameCaseInsensitive tìm author by name hoặc alias (bỏ dấu, case-insensitive, trimmed)
+// Tên chuẩn khớp trước alias
 func (r *postgresRepository) FindByNameCaseInsensitive(ctx context.Context, name string) (*model.Author, error) {
 	query := `
         SELECT id, name, slug, bio, 
           created_at, updated_at
-        FROM authors
-        WHERE LOWER(TRIM(name)) = LOWER(TRIM($1))
-          AND deleted_at IS NULL
+        FROM (
+            SELECT a.*, 0 AS priority FROM authors a
+            WHERE normalize_author_name(a.name) = normalize_author_name($1)
+            UNION ALL
+            SELECT a.*, 1 AS priority FROM authors a
+            JOIN author_aliases aa ON aa.author_id = a.id
+            WHERE aa.normalized_name = normalize_author_name($1)
+        ) matched
+        ORDER BY priority
         LIMIT 1
     `
 
@@ -65,4 +65,26 @@ type RepositoryInterface interface {
 	// Search performs full-text search on author names
 	// Supports: partial matching, pagination
 	Search(ctx context.Context, query string, filter model.AuthorFilter) ([]model.Author, int64, error)
+
+	// ListAliases bút danh / tên phiên âm của tác giả
+	ListAliases(ctx context.Context, authorID uuid.UUID) ([]model.AuthorAlias, error)
+
+	// CreateAlias thêm alias
+	// Errors: ErrAliasTaken nếu tên chuẩn hoá đã là alias, ErrAuthorNotFound
+	CreateAlias(ctx context.Context, alias *model.AuthorAlias) (*model.AuthorAlias, error)
+
+	// DeleteAlias xoá alias
+	// Errors: ErrAliasNotFound
+	DeleteAlias(ctx context.Context, authorID, aliasID uuid.UUID) error
+
+	// NameUsedByOtherAuthor tên đã chuẩn hoá (bỏ dấu, lowercase) trùng tên / alias của tác giả khác
+	NameUsedByOtherAuthor(ctx context.Context, name string, excludeID uuid.UUID) (bool, error)
+
+	// GetByAliasSlug slug của tác giả đã gộp → tác giả chuẩn
+	// Errors: ErrAuthorNotFound
+	GetByAliasSlug(ctx context.Context, slug string) (*model.Author, error)
+
+	// MergeAuthors chuyển sách + alias của source sang target, source thành alias, xoá source
+	// Errors: ErrAuthorNotFound
+	MergeAuthors(ctx context.Context, sourceID, targetID, adminID uuid.UUID) (*model.AuthorMergeResult, error)
 }
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/author/model"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ════════════════════════════════════════════════════════════════
+// ALIASES + MERGE
+// ════════════════════════════════════════════════════════════════
+
+// normalizeAuthorName gộp khoảng trắng thừa ("Nguyễn  Nhật Ánh " → "Nguyễn Nhật Ánh")
+// So khớp bỏ dấu / lowercase làm ở DB (normalize_author_name)
+func normalizeAuthorName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func (s *authorService) ListAliases(ctx context.Context, authorID uuid.UUID) ([]model.AuthorAlias, error) {
+	if _, err := s.repo.GetByID(ctx, authorID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAliases(ctx, authorID)
+}
+
+func (s *authorService) CreateAlias(ctx context.Context, authorID, adminID uuid.UUID, req *model.CreateAliasRequest) (*model.AuthorAlias, error) {
+	name := normalizeAuthorName(req.Name)
+	if len(name) < model.MinNameLength {
+		return nil, model.ErrInvalidName
+	}
+	if len(name) > model.MaxNameLength {
+		return nil, model.ErrNameTooLong
+	}
+
+	// Alias trùng tên / alias của tác giả khác → 2 bản ghi cùng 1 người, cần gộp tác giả
+	used, err := s.repo.NameUsedByOtherAuthor(ctx, name, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, model.ErrAliasTaken
+	}
+
+	return s.repo.CreateAlias(ctx, &model.AuthorAlias{
+		AuthorID:  authorID,
+		Name:      name,
+		Source:    model.AliasSourceManual,
+		CreatedBy: &adminID,
+	})
+}
+
+func (s *authorService) DeleteAlias(ctx context.Context, authorID, aliasID uuid.UUID) error {
+	return s.repo.DeleteAlias(ctx, authorID, aliasID)
+}
+
+func (s *authorService) MergeAuthors(ctx context.Context, targetID, adminID uuid.UUID, req *model.MergeAuthorsRequest) (*model.AuthorMergeResult, error) {
+	if req.SourceAuthorID == uuid.Nil || req.SourceAuthorID == targetID {
+		return nil, model.ErrInvalidMerge
+	}
+
+	result, err := s.repo.MergeAuthors(ctx, req.SourceAuthorID, targetID, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Authors merged", map[string]interface{}{
+		"source_author_id": result.SourceAuthorID.String(),
+		"target_author_id": result.TargetAuthorID.String(),
+		"admin_id":         adminID.String(),
+		"books_moved":      result.BooksMoved,
+		"aliases_moved":    result.AliasesMoved,
+	})
+	return result, nil
+}
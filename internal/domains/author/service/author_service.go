@@ -69,7 +69,12 @@ func (s *authorService) GetBySlug(ctx context.Context, slug string) (*model.Auth
 	}
 
 	// Repository handles cache + DB
-	return s.repo.GetBySlug(ctx, slug)
+	a, err := s.repo.GetBySlug(ctx, slug)
+	if err == model.ErrAuthorNotFound {
+		// Slug của tác giả đã gộp → tác giả chuẩn
+		return s.repo.GetByAliasSlug(ctx, slug)
+	}
+	return a, err
 }
 
 func (s *authorService) Create(ctx context.Context, req *model.CreateAuthorRequest) (*model.Author, error) {
@@ -89,6 +94,15 @@ func (s *authorService) Create(ctx context.Context, req *model.CreateAuthorReque
 		return nil, fmt.Errorf("bio too long maximum %d length", model.MaxBioLength)
 	}
 
+	// Tên đã là tác giả / alias khác (vd "Nguyen Nhat Anh" của "Nguyễn Nhật Ánh") → không tạo trùng
+	used, err := s.repo.NameUsedByOtherAuthor(ctx, name, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, model.ErrAliasTaken
+	}
+
 	baseSlug := utils.GenerateSlug(req.Name)
 	exists, err := s.repo.ExistsBySlug(ctx, baseSlug)
 	if err != nil || exists {
@@ -206,6 +220,14 @@ func (s *authorService) Update(ctx context.Context, id uuid.UUID, req *model.Upd
 
 		// If name changes, regenerate slug
 		if name != currentAuthor.Name {
+			used, err := s.repo.NameUsedByOtherAuthor(ctx, name, id)
+			if err != nil {
+				return nil, err
+			}
+			if used {
+				return nil, model.ErrAliasTaken
+			}
+
 			newSlug := utils.GenerateSlug(name)
 
 			// Check if new slug already exists (excluding current author)
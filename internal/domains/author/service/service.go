@@ -80,4 +80,26 @@ type ServiceInterface interface {
 	// Returns: Author + book count (denormalized)
 	// Errors: ErrAuthorNotFound
 	GetWithBookCount(ctx context.Context, id uuid.UUID) (*model.Author, int, error)
+
+	// ListAliases bút danh / tên phiên âm của tác giả
+	// Errors: ErrAuthorNotFound
+	ListAliases(ctx context.Context, authorID uuid.UUID) ([]model.AuthorAlias, error)
+
+	// CreateAlias thêm alias cho tác giả chuẩn
+	// Business rules:
+	// - Tên được gộp khoảng trắng, so khớp bỏ dấu + case-insensitive
+	// - Không trùng tên / alias của tác giả khác (trùng → gộp tác giả)
+	// Errors: ErrInvalidName, ErrNameTooLong, ErrAliasTaken, ErrAuthorNotFound
+	CreateAlias(ctx context.Context, authorID, adminID uuid.UUID, req *model.CreateAliasRequest) (*model.AuthorAlias, error)
+
+	// DeleteAlias xoá alias
+	// Errors: ErrAliasNotFound
+	DeleteAlias(ctx context.Context, authorID, aliasID uuid.UUID) error
+
+	// MergeAuthors gộp tác giả trùng vào targetID
+	// Business rules:
+	// - Sách + alias của tác giả bị gộp chuyển sang targetID (1 transaction)
+	// - Tên + slug tác giả bị gộp thành alias → vẫn search được, link slug cũ vẫn ra targetID
+	// Errors: ErrInvalidMerge, ErrAuthorNotFound
+	MergeAuthors(ctx context.Context, targetID, adminID uuid.UUID, req *model.MergeAuthorsRequest) (*model.AuthorMergeResult, error)
 }
@@ -12,33 +12,66 @@ import (
 
 	"bookstore-backend/internal/domains/book/model"
 	service "bookstore-backend/internal/domains/book/service"
+	"bookstore-backend/internal/infrastructure/breaker"
 	"bookstore-backend/internal/infrastructure/storage"
+	"bookstore-backend/internal/shared/pagination"
 	"bookstore-backend/internal/shared/response"
+	"bookstore-backend/internal/shared/stockdisplay"
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/cache"
 	"bookstore-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // Handler - HTTP Handler (single file)
 type Handler struct {
 	service        service.ServiceInterface
 	cache          cache.Cache
+	loader         *cache.Loader           // book detail: coalescing + refresh sớm cho sách đang hot
 	imageProcessor *storage.ImageProcessor // ✅ Inject qua DI
+	stockPolicy    stockdisplay.Policy     // che số tồn chính xác ở response cho khách
+	breaker        *breaker.Breaker        // storefront breaker: mở → tồn kho "unknown"
 }
 
 // NewHandler - Constructor with DI
-func NewHandler(service service.ServiceInterface, cache cache.Cache, imageProcessor *storage.ImageProcessor) *Handler {
+func NewHandler(service service.ServiceInterface, redisCache cache.Cache, imageProcessor *storage.ImageProcessor, stockPolicy stockdisplay.Policy, storefrontBreaker *breaker.Breaker) *Handler {
 	return &Handler{
 		service:        service,
 		imageProcessor: imageProcessor,
-		cache:          cache,
+		cache:          redisCache,
+		loader:         cache.NewLoader(redisCache),
+		stockPolicy:    stockPolicy,
+		breaker:        storefrontBreaker,
 	}
 }
 
+// stockDisplay áp policy lên số tồn; degraded mode (breaker mở) thì số trong cache không tin được → "unknown"
+func (h *Handler) stockDisplay(available int, degraded bool) stockdisplay.Display {
+	if degraded {
+		return stockdisplay.Unknown()
+	}
+	return h.stockPolicy.Apply(available)
+}
+
+// respondUnavailable 503 + Retry-After khi breaker mở và cache không có dữ liệu
+func respondUnavailable(c *gin.Context, err error) bool {
+	var openErr *breaker.OpenError
+	if errors.As(err, &openErr) {
+		response.ServiceUnavailable(c, openErr.RetryAfter, "Service temporarily unavailable", "Please retry later")
+		return true
+	}
+	if breaker.IsUnavailable(err) {
+		response.Error(c, http.StatusServiceUnavailable, "Service temporarily unavailable", "Please retry later")
+		return true
+	}
+	return false
+}
+
 // ListBooks - GET /v1/books
 // Query params: search, category, price_min, price_max, language, sort, page, limit
+// Cursor mode: pagination=cursor (trang đầu), cursor=<next_cursor> (trang sau), page bị bỏ qua
 func (h *Handler) ListBooks(c *gin.Context) {
 	// Parse query parameters
 	req := model.ListBooksRequest{
@@ -48,6 +81,8 @@ func (h *Handler) ListBooks(c *gin.Context) {
 		Sort:       c.DefaultQuery("sort", "newest"),
 		Page:       1,
 		Limit:      20,
+		Pagination: c.Query("pagination"),
+		Cursor:     c.Query("cursor"),
 	}
 
 	// Parse numeric parameters
@@ -84,13 +119,27 @@ func (h *Handler) ListBooks(c *gin.Context) {
 
 	data, meta, err := h.service.ListBooks(c.Request.Context(), req)
 	if err != nil {
+		if respondUnavailable(c, err) {
+			return
+		}
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Internal server error", err.Error())
 		return
 	}
 
+	degraded := h.breaker.Degraded()
+	for i := range data {
+		stock := h.stockDisplay(data[i].TotalStock, degraded)
+		data[i].TotalStock, data[i].StockStatus, data[i].StockMessage = stock.Quantity, stock.Status, stock.Message
+	}
+
 	response.Success(c, http.StatusOK, "Get book successfully", model.ListBooksAPIResponse{
 		Books:      data,
 		Pagination: *meta,
+		Degraded:   degraded,
 	})
 }
 
@@ -104,37 +153,37 @@ func (h *Handler) GetBookDetail(c *gin.Context) {
 		return
 	}
 
-	// 2. Check cache first
-	cacheKey := model.GenerateBookDetailCacheKey(id)
-	var cachedDetail model.BookDetailResponse
-	found, err := h.cache.Get(c.Request.Context(), cacheKey, &cachedDetail)
-
-	// Cache hit - return immediately
-	if found {
-		response.Success(c, http.StatusAccepted, "Get book successfully", &cachedDetail)
+	// 2. Cache (TTL 10 phút) qua loader: sách hot hết hạn cache chỉ 1 request gọi service / DB
+	var detail model.BookDetailResponse
+	err := h.loader.GetOrLoad(c.Request.Context(), model.GenerateBookDetailCacheKey(id), &detail, 10*time.Minute,
+		func(ctx context.Context) (interface{}, error) {
+			return h.service.GetBookDetail(ctx, id)
+		})
+	if respondUnavailable(c, err) {
 		return
 	}
 
-	// Log nếu có error (nhưng vẫn tiếp tục query DB)
-	if err != nil {
-		log.Printf("[Handler] Cache error for key %s: %v", cacheKey, err)
+	var moved *model.BookMovedError
+	if errors.As(err, &moved) {
+		c.Redirect(http.StatusMovedPermanently, "/api/v1/books/"+moved.TargetID.String())
+		return
 	}
 
-	// 3. Cache MISS - fetch from service
-	detail, err := h.service.GetBookDetail(c.Request.Context(), id)
-
 	isInvalid := model.HandleBookError(c, err)
 	if isInvalid {
 		return
 	}
 
-	// 4. Cache the result (TTL 10 minutes = 600 seconds)
-	// Cache.Set tự động marshal sang JSON
-	if err := h.cache.Set(c.Request.Context(), cacheKey, detail, 10*time.Minute); err != nil {
-		log.Printf("[Handler] Failed to cache book detail: %v", err)
-	}
+	h.applyDetailStockPolicy(&detail)
+	response.Success(c, http.StatusOK, "Get book successfully", &detail)
+}
 
-	response.Success(c, http.StatusOK, "Get book successfully", detail)
+// applyDetailStockPolicy che số tồn chính xác của book detail trước khi trả cho khách
+// Tồn kho theo từng kho (quantity/reserved) là dữ liệu nội bộ → bỏ khỏi response public
+func (h *Handler) applyDetailStockPolicy(detail *model.BookDetailResponse) {
+	stock := h.stockDisplay(detail.TotalStock, h.breaker.Degraded())
+	detail.TotalStock, detail.StockStatus, detail.StockMessage = stock.Quantity, stock.Status, stock.Message
+	detail.Inventories = []model.InventoryDetailDTO{}
 }
 
 func (h *Handler) CreateBook(c *gin.Context) {
@@ -302,12 +351,22 @@ func (h *Handler) DeleteBook(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "Bad request", errors.New("Invalid book id"))
 		return
 	}
-	_, exist := c.Get("user_id")
+	userID, exist := c.Get("user_id")
 	if !exist {
 		response.Error(c, http.StatusUnauthorized, "Unauthorized", errors.New("User not authenticated"))
 		return
 	}
-	deleteResponse, err := h.service.DeleteBook(c.Request.Context(), bookId)
+	// ?replacement_book_id= gợi ý sách thay thế cho giỏ / reorder đang chứa sách này
+	var req model.ArchiveBookRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		return
+	}
+	var archivedBy *uuid.UUID
+	if id, err := uuid.Parse(fmt.Sprint(userID)); err == nil {
+		archivedBy = &id
+	}
+	deleteResponse, err := h.service.DeleteBook(c.Request.Context(), bookId, archivedBy, req)
 	isInvalid := model.HandleBookError(c, err)
 	if isInvalid == true {
 		return
@@ -315,9 +374,24 @@ func (h *Handler) DeleteBook(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Book deleted successfully", deleteResponse)
 }
 
+// RestoreBook - POST /admin/books/:id/restore
+// Mở bán lại sách đã lưu trữ (DeleteBook)
+func (h *Handler) RestoreBook(c *gin.Context) {
+	bookId := c.Param("id")
+	if !utils.IsValidUUID(bookId) {
+		response.Error(c, http.StatusBadRequest, "Bad request", errors.New("Invalid book id"))
+		return
+	}
+	restored, err := h.service.RestoreBook(c.Request.Context(), bookId)
+	if model.HandleBookError(c, err) {
+		return
+	}
+	response.Success(c, http.StatusOK, "Book restored successfully", restored)
+}
+
 // ================ SEARCH BOOK =========================
-// SearchBooks - GET /v1/books/search?q=keyword&limit=10
-// Full-text search using PostgreSQL tsvector
+// SearchBooks - GET /v1/books/search?q=keyword&category=&price_min=&price_max=&in_stock=true&page=1&limit=10
+// Full-text search using PostgreSQL tsvector (title, author, description) + trigram fallback
 func (h *Handler) SearchBooks(c *gin.Context) {
 	startTime := time.Now()
 
@@ -329,8 +403,15 @@ func (h *Handler) SearchBooks(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "Invalid search parameters", err.Error())
 		return
 	}
+	if err := req.Validate(); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid search parameters", err.Error())
+		return
+	}
 
-	// 2. Set default limit
+	// 2. Set default page / limit
+	if req.Page == 0 {
+		req.Page = 1
+	}
 	if req.Limit == 0 {
 		req.Limit = 10
 	}
@@ -342,13 +423,23 @@ func (h *Handler) SearchBooks(c *gin.Context) {
 	}
 
 	// 4. Call service
-	results, err := h.service.SearchBooks(c.Request.Context(), req)
+	result, err := h.service.SearchBooks(c.Request.Context(), req)
 	if err != nil {
+		if respondUnavailable(c, err) {
+			return
+		}
 		log.Printf("[Handler] Error searching books: %v", err)
 		response.Error(c, http.StatusInternalServerError, "Search failed", "Internal server error")
 		return
 	}
 
+	results := result.Books
+	degraded := h.breaker.Degraded()
+	for i := range results {
+		stock := h.stockDisplay(results[i].TotalStock, degraded)
+		results[i].TotalStock, results[i].StockStatus, results[i].StockMessage = stock.Quantity, stock.Status, stock.Message
+	}
+
 	// 5. Calculate query time
 	tookMs := time.Since(startTime).Milliseconds()
 
@@ -356,11 +447,15 @@ func (h *Handler) SearchBooks(c *gin.Context) {
 	meta := &model.SearchMeta{
 		Query:       req.Query,
 		ResultCount: len(results),
+		Total:       result.Total,
+		Page:        req.Page,
+		Limit:       req.Limit,
 		TookMs:      tookMs,
+		Degraded:    degraded,
 	}
 
 	// Log for analytics (phase sau sẽ save vào DB)
-	log.Printf("[Search] Query: %q, Results: %d, Took: %dms", req.Query, len(results), tookMs)
+	log.Printf("[Search] Query: %q, Results: %d/%d, Took: %dms", req.Query, len(results), result.Total, tookMs)
 
 	response.Success(c, http.StatusOK, "Search completed successfully", map[string]interface{}{
 		"results": results,
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bookstore-backend/internal/domains/book/model"
+	bookService "bookstore-backend/internal/domains/book/service"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DataQualityHandler struct {
+	service bookService.DataQualityService
+}
+
+// NewDataQualityHandler tạo handler mới
+func NewDataQualityHandler(service bookService.DataQualityService) *DataQualityHandler {
+	return &DataQualityHandler{
+		service: service,
+	}
+}
+
+// GetReport - GET /v1/books/data-quality?issue=missing_cover&active_only=true&page=1&limit=50
+// Sách thiếu cover / mô tả / danh mục hoặc không có tồn ở kho nào (kết quả job quét đêm), kèm fix_links
+func (h *DataQualityHandler) GetReport(c *gin.Context) {
+	var req model.DataQualityReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	report, err := h.service.GetReport(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidDataQualityRequest) {
+			response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get data quality report", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Data quality report retrieved", report)
+}
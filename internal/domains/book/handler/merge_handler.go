@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bookstore-backend/internal/domains/book/model"
+	bookService "bookstore-backend/internal/domains/book/service"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MergeHandler struct {
+	service bookService.MergeService
+}
+
+// NewMergeHandler tạo handler mới
+func NewMergeHandler(service bookService.MergeService) *MergeHandler {
+	return &MergeHandler{
+		service: service,
+	}
+}
+
+// ListDuplicates - GET /v1/admin/books/duplicates?min_score=0.6&limit=50
+func (h *MergeHandler) ListDuplicates(c *gin.Context) {
+	var req model.ListDuplicatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	candidates, err := h.service.ListDuplicates(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to list duplicate books")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Duplicate books retrieved", candidates)
+}
+
+// DismissDuplicate - POST /v1/admin/books/duplicates/dismiss
+func (h *MergeHandler) DismissDuplicate(c *gin.Context) {
+	adminID, ok := adminUUIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req model.DismissDuplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.service.DismissDuplicate(c.Request.Context(), adminID, req); err != nil {
+		h.handleError(c, err, "Failed to dismiss duplicate")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Duplicate dismissed", nil)
+}
+
+// MergeBooks - POST /v1/admin/books/:id/merge
+// :id = sách giữ lại, body.source_book_id = sách bị gộp (xoá mềm, slug cũ redirect về :id)
+func (h *MergeHandler) MergeBooks(c *gin.Context) {
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+
+	adminID, ok := adminUUIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req model.MergeBooksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	result, err := h.service.MergeBooks(c.Request.Context(), targetID, adminID, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to merge books")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Books merged", result)
+}
+
+// ResolveSlug - GET /v1/books/slug/:slug
+func (h *MergeHandler) ResolveSlug(c *gin.Context) {
+	resolved, err := h.service.ResolveSlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		h.handleError(c, err, "Failed to resolve slug")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Slug resolved", resolved)
+}
+
+func (h *MergeHandler) handleError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrBookNotFound):
+		response.Error(c, http.StatusNotFound, "Book not found", err.Error())
+	case errors.Is(err, model.ErrInvalidDuplicateRequest), errors.Is(err, model.ErrInvalidMerge):
+		response.Error(c, http.StatusBadRequest, msg, err.Error())
+	case errors.Is(err, model.ErrMergeSourceReserved):
+		response.Error(c, http.StatusConflict, msg, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"bookstore-backend/internal/domains/book/model"
+	bookService "bookstore-backend/internal/domains/book/service"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetadataEnrichmentHandler struct {
+	service bookService.MetadataEnrichmentService
+}
+
+// NewMetadataEnrichmentHandler tạo handler mới
+func NewMetadataEnrichmentHandler(service bookService.MetadataEnrichmentService) *MetadataEnrichmentHandler {
+	return &MetadataEnrichmentHandler{
+		service: service,
+	}
+}
+
+// EnrichBook - POST /v1/admin/books/:id/enrich
+// Enqueue job lấy metadata theo ISBN, kết quả xem ở /metadata-suggestions
+func (h *MetadataEnrichmentHandler) EnrichBook(c *gin.Context) {
+	result, err := h.service.RequestEnrichment(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to request metadata enrichment")
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Metadata enrichment queued", result)
+}
+
+// ListSuggestions - GET /v1/admin/books/:id/metadata-suggestions?status=pending
+func (h *MetadataEnrichmentHandler) ListSuggestions(c *gin.Context) {
+	suggestions, err := h.service.ListSuggestions(c.Request.Context(), c.Param("id"), c.Query("status"))
+	if err != nil {
+		h.handleError(c, err, "Failed to list metadata suggestions")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Metadata suggestions retrieved", suggestions)
+}
+
+// AcceptSuggestion - POST /v1/admin/books/metadata-suggestions/:suggestion_id/accept
+func (h *MetadataEnrichmentHandler) AcceptSuggestion(c *gin.Context) {
+	reviewerID, ok := reviewerIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	suggestion, err := h.service.AcceptSuggestion(c.Request.Context(), c.Param("suggestion_id"), reviewerID)
+	if err != nil {
+		h.handleError(c, err, "Failed to accept metadata suggestion")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Metadata suggestion accepted", suggestion)
+}
+
+// RejectSuggestion - POST /v1/admin/books/metadata-suggestions/:suggestion_id/reject
+func (h *MetadataEnrichmentHandler) RejectSuggestion(c *gin.Context) {
+	reviewerID, ok := reviewerIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RejectSuggestion(c.Request.Context(), c.Param("suggestion_id"), reviewerID); err != nil {
+		h.handleError(c, err, "Failed to reject metadata suggestion")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Metadata suggestion rejected", nil)
+}
+
+func (h *MetadataEnrichmentHandler) handleError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrBookNotFound):
+		response.Error(c, http.StatusNotFound, "Book not found", err.Error())
+	case errors.Is(err, model.ErrSuggestionNotFound):
+		response.Error(c, http.StatusNotFound, "Metadata suggestion not found", err.Error())
+	case errors.Is(err, model.ErrBookHasNoISBN), errors.Is(err, model.ErrInvalidSuggestionValue):
+		response.Error(c, http.StatusBadRequest, msg, err.Error())
+	case errors.Is(err, model.ErrSuggestionNotPending):
+		response.Error(c, http.StatusConflict, "Metadata suggestion already reviewed", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
+
+// reviewerIDFromContext lấy user_id (AuthMiddleware set) dạng string
+func reviewerIDFromContext(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user_id not found in context")
+		return "", false
+	}
+	return fmt.Sprint(userID), true
+}
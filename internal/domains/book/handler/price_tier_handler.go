@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bookstore-backend/internal/domains/book/model"
+	bookService "bookstore-backend/internal/domains/book/service"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PriceTierHandler struct {
+	service bookService.PriceTierService
+}
+
+// NewPriceTierHandler tạo handler mới
+func NewPriceTierHandler(service bookService.PriceTierService) *PriceTierHandler {
+	return &PriceTierHandler{
+		service: service,
+	}
+}
+
+// ListTiers - GET /v1/books/:id/price-tiers (public) và /v1/admin/books/:id/price-tiers
+func (h *PriceTierHandler) ListTiers(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+
+	tiers, err := h.service.ListTiers(c.Request.Context(), bookID)
+	if err != nil {
+		h.handleError(c, err, "Failed to list price tiers")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Price tiers retrieved", tiers)
+}
+
+// ReplaceTiers - PUT /v1/admin/books/:id/price-tiers
+// Body: {"tiers": [{"min_quantity": 5, "discount_percent": "5"}, {"min_quantity": 10, "discount_percent": "10"}]}
+func (h *PriceTierHandler) ReplaceTiers(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+
+	reviewerID, ok := reviewerIDFromContext(c)
+	if !ok {
+		return
+	}
+	adminID, err := uuid.Parse(reviewerID)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "invalid user_id in context")
+		return
+	}
+
+	var req model.ReplacePriceTiersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	tiers, err := h.service.ReplaceTiers(c.Request.Context(), bookID, adminID, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update price tiers")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Price tiers updated", tiers)
+}
+
+func (h *PriceTierHandler) handleError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrBookNotFound):
+		response.Error(c, http.StatusNotFound, "Book not found", err.Error())
+	case errors.Is(err, model.ErrInvalidPriceTier):
+		response.Error(c, http.StatusBadRequest, msg, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
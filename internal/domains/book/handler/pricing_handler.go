@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bookstore-backend/internal/domains/book/model"
+	bookService "bookstore-backend/internal/domains/book/service"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PricingHandler struct {
+	service bookService.PricingService
+}
+
+// NewPricingHandler tạo handler mới
+func NewPricingHandler(service bookService.PricingService) *PricingHandler {
+	return &PricingHandler{
+		service: service,
+	}
+}
+
+// GetPriceHistory - GET /v1/books/:id/price-history?days=90 (public)
+// Trả kèm lowest_price_30d cho storefront hiện "giá thấp nhất 30 ngày"
+func (h *PricingHandler) GetPriceHistory(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+
+	days := 0
+	if v := c.Query("days"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid days", err.Error())
+			return
+		}
+	}
+
+	history, err := h.service.GetPriceHistory(c.Request.Context(), bookID, days)
+	if err != nil {
+		h.handleError(c, err, "Failed to get price history")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Price history retrieved", history)
+}
+
+// CreateSchedule - POST /v1/admin/books/:id/price-schedules
+// Body: {"price": 80000, "compare_at_price": 100000, "effective_at": "2026-11-27T00:00:00+07:00", "note": "Black Friday"}
+func (h *PricingHandler) CreateSchedule(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+
+	adminID, ok := adminUUIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreatePriceScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	schedule, err := h.service.CreateSchedule(c.Request.Context(), bookID, adminID, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to schedule price change")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Price change scheduled", schedule)
+}
+
+// ListSchedules - GET /v1/admin/books/:id/price-schedules?status=pending
+func (h *PricingHandler) ListSchedules(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+
+	var req model.ListPriceSchedulesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	schedules, err := h.service.ListSchedules(c.Request.Context(), bookID, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to list price schedules")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Price schedules retrieved", schedules)
+}
+
+// CancelSchedule - DELETE /v1/admin/books/:id/price-schedules/:schedule_id
+func (h *PricingHandler) CancelSchedule(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+	scheduleID, err := uuid.Parse(c.Param("schedule_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid schedule ID", err.Error())
+		return
+	}
+
+	adminID, ok := adminUUIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.service.CancelSchedule(c.Request.Context(), bookID, scheduleID, adminID)
+	if err != nil {
+		h.handleError(c, err, "Failed to cancel price schedule")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Price schedule cancelled", schedule)
+}
+
+func (h *PricingHandler) handleError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrBookNotFound):
+		response.Error(c, http.StatusNotFound, "Book not found", err.Error())
+	case errors.Is(err, model.ErrPriceScheduleNotFound):
+		response.Error(c, http.StatusNotFound, "Price schedule not found", err.Error())
+	case errors.Is(err, model.ErrPriceScheduleNotPending):
+		response.Error(c, http.StatusConflict, msg, err.Error())
+	case errors.Is(err, model.ErrInvalidPriceSchedule), errors.Is(err, model.ErrInvalidPriceHistoryRange):
+		response.Error(c, http.StatusBadRequest, msg, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
+
+// adminUUIDFromContext user_id của admin đang gọi dạng UUID (đã ghi response lỗi nếu không có)
+func adminUUIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	reviewerID, ok := reviewerIDFromContext(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+	adminID, err := uuid.Parse(reviewerID)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "invalid user_id in context")
+		return uuid.Nil, false
+	}
+	return adminID, true
+}
@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	bookService "bookstore-backend/internal/domains/book/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// ApplyPriceSchedulesHandler áp các lịch đổi giá đến hạn (book:apply_price_schedules, mỗi phút)
+type ApplyPriceSchedulesHandler struct {
+	pricingService bookService.PricingService
+}
+
+func NewApplyPriceSchedulesHandler(pricingService bookService.PricingService) *ApplyPriceSchedulesHandler {
+	return &ApplyPriceSchedulesHandler{
+		pricingService: pricingService,
+	}
+}
+
+func (h *ApplyPriceSchedulesHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	result, err := h.pricingService.ApplyDueSchedules(ctx)
+	if err != nil {
+		return fmt.Errorf("apply price schedules: %w", err)
+	}
+
+	if result.Applied > 0 || result.Failed > 0 {
+		logger.Info("Completed ApplyPriceSchedules job", map[string]interface{}{
+			"applied": result.Applied,
+			"failed":  result.Failed,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+
+	"bookstore-backend/internal/domains/book/model"
+	bookService "bookstore-backend/internal/domains/book/service"
+)
+
+// EnrichMetadataHandler lấy metadata từ Google Books/OpenLibrary và lưu thành suggestion
+type EnrichMetadataHandler struct {
+	enrichmentService bookService.MetadataEnrichmentService
+}
+
+func NewEnrichMetadataHandler(enrichmentService bookService.MetadataEnrichmentService) *EnrichMetadataHandler {
+	return &EnrichMetadataHandler{
+		enrichmentService: enrichmentService,
+	}
+}
+
+// ProcessTask xử lý background job enrich metadata
+func (h *EnrichMetadataHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload struct {
+		BookID string `json:"book_id"`
+	}
+
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		log.Error().Err(err).Msg("Failed to unmarshal EnrichMetadata payload")
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	err := h.enrichmentService.EnrichBook(ctx, payload.BookID)
+	if err != nil {
+		// Book bị xóa / không có ISBN: retry cũng vô ích
+		if errors.Is(err, model.ErrBookNotFound) || errors.Is(err, model.ErrBookHasNoISBN) {
+			log.Warn().Err(err).Str("book_id", payload.BookID).Msg("Skip metadata enrichment")
+			return fmt.Errorf("enrich metadata: %v: %w", err, asynq.SkipRetry)
+		}
+
+		log.Error().
+			Err(err).
+			Str("book_id", payload.BookID).
+			Msg("Failed to enrich book metadata")
+		return fmt.Errorf("enrich metadata: %w", err)
+	}
+
+	return nil
+}
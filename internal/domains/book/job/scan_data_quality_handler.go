@@ -0,0 +1,28 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	bookService "bookstore-backend/internal/domains/book/service"
+)
+
+// ScanDataQualityHandler quét sách thiếu dữ liệu / không có tồn (book:scan_data_quality, mỗi đêm)
+type ScanDataQualityHandler struct {
+	dataQualityService bookService.DataQualityService
+}
+
+func NewScanDataQualityHandler(dataQualityService bookService.DataQualityService) *ScanDataQualityHandler {
+	return &ScanDataQualityHandler{
+		dataQualityService: dataQualityService,
+	}
+}
+
+func (h *ScanDataQualityHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	if _, err := h.dataQualityService.ScanBooks(ctx); err != nil {
+		return fmt.Errorf("scan book data quality: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const googleBooksAPIURL = "https://www.googleapis.com/books/v1/volumes"
+
+// GoogleBooksProvider - Google Books API (API key optional, có quota thấp hơn khi không có key)
+type GoogleBooksProvider struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewGoogleBooksProvider(apiKey string) *GoogleBooksProvider {
+	return &GoogleBooksProvider{
+		apiKey:     apiKey,
+		httpClient: newHTTPClient(),
+	}
+}
+
+func (p *GoogleBooksProvider) Name() string {
+	return SourceGoogleBooks
+}
+
+type googleBooksResponse struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		SelfLink   string `json:"selfLink"`
+		VolumeInfo struct {
+			Description string `json:"description"`
+			PageCount   int    `json:"pageCount"`
+			InfoLink    string `json:"infoLink"`
+			ImageLinks  struct {
+				Thumbnail string `json:"thumbnail"`
+				Large     string `json:"large"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+func (p *GoogleBooksProvider) FetchByISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	params := url.Values{}
+	params.Set("q", "isbn:"+isbn)
+	if p.apiKey != "" {
+		params.Set("key", p.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleBooksAPIURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("google books: build request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("google books: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("google books: unexpected status %d", resp.StatusCode)
+	}
+
+	var body googleBooksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("google books: decode response: %w", err)
+	}
+
+	if body.TotalItems == 0 || len(body.Items) == 0 {
+		return nil, ErrNotFound
+	}
+
+	item := body.Items[0]
+	info := item.VolumeInfo
+
+	result := &BookMetadata{
+		Source:      SourceGoogleBooks,
+		SourceURL:   item.SelfLink,
+		Description: strPtr(info.Description),
+	}
+	if info.InfoLink != "" {
+		result.SourceURL = info.InfoLink
+	}
+	if info.PageCount > 0 {
+		pages := info.PageCount
+		result.Pages = &pages
+	}
+
+	cover := info.ImageLinks.Large
+	if cover == "" {
+		cover = info.ImageLinks.Thumbnail
+	}
+	// Google trả về http:// -> ép https để tránh mixed content ở frontend
+	result.CoverURL = strPtr(strings.Replace(cover, "http://", "https://", 1))
+
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	openLibraryAPIURL   = "https://openlibrary.org/api/books"
+	openLibraryCoverURL = "https://covers.openlibrary.org/b/id/%d-L.jpg"
+)
+
+// OpenLibraryProvider - OpenLibrary Books API (không cần API key)
+type OpenLibraryProvider struct {
+	httpClient *http.Client
+}
+
+func NewOpenLibraryProvider() *OpenLibraryProvider {
+	return &OpenLibraryProvider{
+		httpClient: newHTTPClient(),
+	}
+}
+
+func (p *OpenLibraryProvider) Name() string {
+	return SourceOpenLibrary
+}
+
+type openLibraryRecord struct {
+	InfoURL string `json:"info_url"`
+	Details struct {
+		NumberOfPages int             `json:"number_of_pages"`
+		Covers        []int           `json:"covers"`
+		Description   json.RawMessage `json:"description"` // string hoặc {"type": ..., "value": ...}
+	} `json:"details"`
+}
+
+func (p *OpenLibraryProvider) FetchByISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	bibKey := "ISBN:" + isbn
+
+	params := url.Values{}
+	params.Set("bibkeys", bibKey)
+	params.Set("format", "json")
+	params.Set("jscmd", "details")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, openLibraryAPIURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("open library: build request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("open library: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("open library: unexpected status %d", resp.StatusCode)
+	}
+
+	var body map[string]openLibraryRecord
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("open library: decode response: %w", err)
+	}
+
+	record, ok := body[bibKey]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	result := &BookMetadata{
+		Source:      SourceOpenLibrary,
+		SourceURL:   record.InfoURL,
+		Description: parseOpenLibraryDescription(record.Details.Description),
+	}
+	if record.Details.NumberOfPages > 0 {
+		pages := record.Details.NumberOfPages
+		result.Pages = &pages
+	}
+	if len(record.Details.Covers) > 0 && record.Details.Covers[0] > 0 {
+		cover := fmt.Sprintf(openLibraryCoverURL, record.Details.Covers[0])
+		result.CoverURL = &cover
+	}
+
+	return result, nil
+}
+
+func parseOpenLibraryDescription(raw json.RawMessage) *string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strPtr(text)
+	}
+
+	var typed struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &typed); err == nil {
+		return strPtr(typed.Value)
+	}
+
+	return nil
+}
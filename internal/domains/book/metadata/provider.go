@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// =====================================================
+// PROVIDER INTERFACE
+// =====================================================
+
+// Provider fetches book metadata from an external catalogue by ISBN
+type Provider interface {
+	// Name returns source identifier stored as provenance (google_books, open_library)
+	Name() string
+
+	// FetchByISBN returns ErrNotFound if the catalogue has no record for the ISBN
+	FetchByISBN(ctx context.Context, isbn string) (*BookMetadata, error)
+}
+
+// Source identifiers
+const (
+	SourceGoogleBooks = "google_books"
+	SourceOpenLibrary = "open_library"
+)
+
+// ErrNotFound is returned when provider has no record for the ISBN
+var ErrNotFound = errors.New("metadata not found for isbn")
+
+// BookMetadata - các field provider trả về (nil = provider không có)
+type BookMetadata struct {
+	Source      string
+	SourceURL   string
+	CoverURL    *string
+	Pages       *int
+	Description *string
+}
+
+const defaultTimeout = 10 * time.Second
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultTimeout}
+}
+
+// NormalizeISBN bỏ dấu gạch/khoảng trắng: "978-604-1-00000-0" -> "9786041000000"
+func NormalizeISBN(isbn string) string {
+	r := strings.NewReplacer("-", "", " ", "")
+	return strings.ToUpper(r.Replace(strings.TrimSpace(isbn)))
+}
+
+func strPtr(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ========================================
+// ARCHIVE (NGỪNG BÁN) + RESTORE
+// ========================================
+// DELETE /books/:id lưu trữ sách (archived_at, is_active = false) thay vì ẩn hẳn:
+// giỏ hàng / đơn vẫn đọc được sách để báo ITEM_DISCONTINUED kèm gợi ý thay thế.
+// POST /admin/books/:id/restore mở bán lại.
+
+// Replacement reasons (khớp literal trong query FindReplacement)
+const (
+	ReplacementReasonReplacement = "replacement" // admin chọn khi ngừng bán
+	ReplacementReasonMerged      = "merged"      // sách đã gộp vào sách khác (book_redirects)
+)
+
+// ArchiveBookRequest - DELETE /v1/books/:id?replacement_book_id=
+type ArchiveBookRequest struct {
+	ReplacementBookID *uuid.UUID `form:"replacement_book_id"`
+}
+
+// ReplacementSuggestion sách gợi ý thay cho sách đã ngừng bán
+type ReplacementSuggestion struct {
+	BookID uuid.UUID       `json:"book_id"`
+	Title  string          `json:"title"`
+	Slug   string          `json:"slug"`
+	Price  decimal.Decimal `json:"price"`
+	Reason string          `json:"reason"`
+}
+
+// RestoreBookResponse - POST /v1/admin/books/:id/restore
+type RestoreBookResponse struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	RestoredAt time.Time `json:"restored_at"`
+}
+
+// IsDiscontinued sách đã ngừng bán (archived)
+func (b *BookCheckoutResponse) IsDiscontinued() bool {
+	return b.ArchivedAt != nil
+}
+
+var (
+	ErrBookNotArchived    = errors.New("book is not archived")
+	ErrInvalidReplacement = errors.New("replacement book must be another active book")
+)
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// BOOK DATA QUALITY (sách thiếu dữ liệu / không có tồn)
+// ========================================
+// Job book:scan_data_quality quét hằng đêm → book_data_quality_issues,
+// GET /books/data-quality trả báo cáo kèm link sửa cho catalog manager.
+
+// Data quality issues (book_data_quality_issues.issue)
+const (
+	DataQualityMissingCover       = "missing_cover"       // không có cover_url và không có ảnh cover đã xử lý xong
+	DataQualityMissingDescription = "missing_description" // description trống
+	DataQualityMissingCategory    = "missing_category"    // category_id NULL
+	DataQualityNoInventory        = "no_inventory"        // không có dòng warehouse_inventory ở kho nào
+)
+
+// DataQualityIssues thứ tự hiển thị trong summary
+var DataQualityIssues = []string{
+	DataQualityMissingCover,
+	DataQualityMissingDescription,
+	DataQualityMissingCategory,
+	DataQualityNoInventory,
+}
+
+const (
+	DataQualityDefaultLimit = 50
+	DataQualityMaxLimit     = 200
+)
+
+// DataQualityScanResult kết quả 1 lần quét (số sách đang có từng vấn đề)
+type DataQualityScanResult struct {
+	Counts   map[string]int
+	Resolved int // vấn đề lần trước đã được sửa
+}
+
+// DataQualityFixLink endpoint để sửa 1 vấn đề (đường dẫn API, admin UI tự map sang màn hình)
+type DataQualityFixLink struct {
+	Issue  string `json:"issue"`
+	Label  string `json:"label"`
+	Method string `json:"method"`
+	Href   string `json:"href"`
+}
+
+// BookDataQualityItem 1 sách trong báo cáo + mọi vấn đề của sách
+type BookDataQualityItem struct {
+	BookID   uuid.UUID `json:"book_id"`
+	Title    string    `json:"title"`
+	Slug     string    `json:"slug"`
+	ISBN     *string   `json:"isbn,omitempty"`
+	IsActive bool      `json:"is_active"`
+	Issues   []string  `json:"issues"`
+	// DetectedAt lần đầu phát hiện vấn đề lâu nhất của sách
+	DetectedAt time.Time            `json:"detected_at"`
+	FixLinks   []DataQualityFixLink `json:"fix_links"`
+}
+
+// ========================================
+// REQUESTS / RESPONSES
+// ========================================
+
+// DataQualityReportRequest - GET /books/data-quality?issue=missing_cover&active_only=true&page=1&limit=50
+type DataQualityReportRequest struct {
+	Issue      string `form:"issue"`
+	ActiveOnly bool   `form:"active_only"`
+	Page       int    `form:"page"`
+	Limit      int    `form:"limit"`
+}
+
+func (r *DataQualityReportRequest) Validate() error {
+	if r.Issue != "" {
+		valid := false
+		for _, issue := range DataQualityIssues {
+			if r.Issue == issue {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("%w: unknown issue %q", ErrInvalidDataQualityRequest, r.Issue)
+		}
+	}
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.Limit == 0 {
+		r.Limit = DataQualityDefaultLimit
+	}
+	if r.Page < 1 || r.Limit < 1 || r.Limit > DataQualityMaxLimit {
+		return fmt.Errorf("%w: page must be >= 1 and limit between 1 and %d", ErrInvalidDataQualityRequest, DataQualityMaxLimit)
+	}
+	return nil
+}
+
+// DataQualityReportResponse - tổng số sách theo vấn đề + danh sách sách (vấn đề lâu nhất trước)
+type DataQualityReportResponse struct {
+	Summary map[string]int `json:"summary"`
+	// LastScannedAt lần quét gần nhất (nil = job chưa chạy)
+	LastScannedAt *time.Time            `json:"last_scanned_at,omitempty"`
+	Books         []BookDataQualityItem `json:"books"`
+	Pagination    PaginationMeta        `json:"pagination"`
+}
+
+var ErrInvalidDataQualityRequest = errors.New("invalid data quality request")
@@ -3,7 +3,9 @@ package model
 import (
 	"bookstore-backend/internal/shared/utils"
 	"database/sql"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -90,6 +92,9 @@ type ListBooksRequest struct {
 	Page       int     `form:"page" default:"1"`      // Pagination
 	Limit      int     `form:"limit" default:"20"`    // Max 100
 	IsActive   *bool   `form:"is_active"`             // Optional: filter active/inactive
+	// Cursor mode: ?pagination=cursor (trang đầu) hoặc ?cursor=<next_cursor>; page bị bỏ qua
+	Pagination string `form:"pagination"`
+	Cursor     string `form:"cursor"`
 }
 
 // ListBooksResponse - Response data
@@ -110,6 +115,8 @@ type ListBooksResponse struct {
 	SoldCount       int              `json:"sold_count"`
 	IsFeatured      bool             `json:"is_featured"`
 	TotalStock      int              `json:"total_stock"`
+	StockStatus     string           `json:"stock_status,omitempty"`
+	StockMessage    string           `json:"stock_message,omitempty"`
 	CreatedAt       time.Time        `json:"created_at"`
 	CategoryName    string           `json:"category_name"`
 	Images          []string         `json:"images"`
@@ -124,12 +131,17 @@ type PaginationMeta struct {
 	PageSize  int `json:"page_size"`
 	Total     int `json:"total"`
 	TotalPage int `json:"total_page"`
+	// Cursor mode (page / total / total_page = 0): rỗng = hết danh sách
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more,omitempty"`
 }
 
 // ListBooksAPIResponse - Wrapper response
 type ListBooksAPIResponse struct {
 	Books      []ListBooksResponse `json:"books"`
 	Pagination PaginationMeta      `json:"pagination"`
+	// true: DB / inventory đang lỗi, dữ liệu từ cache, tồn kho "unknown"
+	Degraded bool `json:"degraded,omitempty"`
 }
 
 // Helper: Validate list request
@@ -320,7 +332,9 @@ type BaseBookResponse struct {
 type DeleteBookResponse struct {
 	ID        string    `json:"id"`
 	Title     string    `json:"title"`
-	DeletedAt time.Time `json:"deleted_at"`
+	DeletedAt time.Time `json:"deleted_at"` // thời điểm lưu trữ (archived_at)
+
+	ReplacementBookID *uuid.UUID `json:"replacement_book_id,omitempty"`
 }
 
 // BookSearchQuery represents search/filter parameters
@@ -512,8 +526,14 @@ type BookCheckoutResponse struct {
 	CoverURL      *string         `json:"cover_url,omitempty"`
 	AuthorName    string          `json:"author_name"`
 	PublisherName string          `json:"publisher_name"`
+	CategoryID    *uuid.UUID      `json:"category_id,omitempty"`
 	CategoryName  string          `json:"category_name"`
 	Description   *string         `json:"description,omitempty"`
+	IsActive      bool            `json:"is_active"`
+
+	// Ngừng bán: ArchivedAt != nil (is_active = false), ReplacementBookID do admin chọn
+	ArchivedAt        *time.Time `json:"archived_at,omitempty"`
+	ReplacementBookID *uuid.UUID `json:"replacement_book_id,omitempty"`
 }
 
 // book detail response
@@ -538,6 +558,8 @@ type BookDetailResponse struct {
 	PublishedYear   *int                 `json:"published_year,omitempty"`
 	Format          *string              `json:"format,omitempty"`
 	TotalStock      int                  `json:"total_stock"`
+	StockStatus     string               `json:"stock_status,omitempty"`
+	StockMessage    string               `json:"stock_message,omitempty"`
 	IsActive        bool                 `json:"is_active" db:"is_active"`
 	Inventories     []InventoryDetailDTO `json:"inventories"`
 	Images          []string             `json:"images" db:"images"`
@@ -547,6 +569,10 @@ type BookDetailResponse struct {
 	MetaDescription *string              `json:"meta_description" db:"meta_description"`
 	MetaKeywords    []string             `json:"meta_keywords" db:"meta_keywords"`
 	Reviews         []ReviewDTO          `json:"reviews"`
+
+	// Sách ngừng bán vẫn xem được (trang "ngừng kinh doanh" + sách thay thế)
+	ArchivedAt        *time.Time `json:"archived_at,omitempty"`
+	ReplacementBookID *uuid.UUID `json:"replacement_book_id,omitempty"`
 }
 type BookFilter struct {
 	Search     string
@@ -812,9 +838,29 @@ func ApplyUpdates(existing Book, req UpdateBookRequest, newSlug string) {
 //
 // SearchBooksRequest - Query parameters for search
 type SearchBooksRequest struct {
-	Query    string `form:"q" binding:"required,min=2,max=200"`
-	Language string `form:"language" binding:"omitempty,oneof=vi en"`
-	Limit    int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	Query      string  `form:"q" binding:"required,min=2,max=200"`
+	Language   string  `form:"language" binding:"omitempty,oneof=vi en"`
+	CategoryID string  `form:"category" binding:"omitempty,uuid"` // gồm cả danh mục con trực tiếp
+	PriceMin   float64 `form:"price_min" binding:"omitempty,min=0"`
+	PriceMax   float64 `form:"price_max" binding:"omitempty,min=0"`
+	InStock    bool    `form:"in_stock"` // chỉ sách còn hàng (available > 0)
+	Page       int     `form:"page" binding:"omitempty,min=1"`
+	Limit      int     `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// Validate - kiểm tra chéo sau khi bind (binding tag không so sánh được 2 field)
+func (r *SearchBooksRequest) Validate() error {
+	if r.PriceMin > 0 && r.PriceMax > 0 && r.PriceMin > r.PriceMax {
+		return ErrInvalidPriceRange
+	}
+	return nil
+}
+
+// SearchTerms tách query thành các token chữ/số (bỏ ký tự đặc biệt của tsquery: & | ! : * ( ) ')
+func (r *SearchBooksRequest) SearchTerms() []string {
+	return strings.FieldsFunc(strings.ToLower(r.Query), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
 }
 
 // BookSearchResponse - Simplified book info for search results
@@ -827,6 +873,10 @@ type BookSearchResponse struct {
 	Price      float64 `json:"price"`
 	Language   string  `json:"language"`
 	Rank       float64 `json:"rank"` // Relevance score for debugging
+
+	TotalStock   int    `json:"total_stock"`
+	StockStatus  string `json:"stock_status,omitempty"`
+	StockMessage string `json:"stock_message,omitempty"`
 }
 
 // SearchBooksAPIResponse - Wrapper for search results
@@ -841,7 +891,17 @@ type SearchBooksAPIResponse struct {
 type SearchMeta struct {
 	Query       string `json:"query"`
 	ResultCount int    `json:"result_count"`
+	Total       int    `json:"total"`
+	Page        int    `json:"page"`
+	Limit       int    `json:"limit"`
 	TookMs      int64  `json:"took_ms"` // Query execution time
+	Degraded    bool   `json:"degraded,omitempty"`
+}
+
+// SearchBooksResult - 1 trang kết quả + tổng số sách khớp (cache nguyên struct)
+type SearchBooksResult struct {
+	Books []BookSearchResponse `json:"books"`
+	Total int                  `json:"total"`
 }
 type BookDetailRes struct {
 	// Core book fields from books table (b.*)
@@ -881,6 +941,9 @@ type BookDetailRes struct {
 	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
 	DeletedAt       sql.NullTime     `json:"deleted_at" db:"deleted_at"`
 
+	ArchivedAt        *time.Time `json:"archived_at" db:"archived_at"`
+	ReplacementBookID *uuid.UUID `json:"replacement_book_id" db:"replacement_book_id"`
+
 	// Author fields (prefixed with author_)
 	AuthorName *string `json:"author_name" db:"author_name"`
 	AuthorSlug *string `json:"author_slug" db:"author_slug"`
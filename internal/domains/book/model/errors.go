@@ -66,6 +66,26 @@ var bookErrorMap = map[error]struct {
 		Title:   "Book not found",
 		Message: "The specified book does not exist",
 	},
+	ErrBookHasActiveOrders: {
+		Status:  http.StatusConflict,
+		Title:   "Book has active orders",
+		Message: "The book has orders in progress and cannot be archived yet",
+	},
+	ErrBookHasReservedInventory: {
+		Status:  http.StatusConflict,
+		Title:   "Book has reserved inventory",
+		Message: "The book has reserved stock and cannot be archived yet",
+	},
+	ErrBookNotArchived: {
+		Status:  http.StatusConflict,
+		Title:   "Book is not archived",
+		Message: "Only archived books can be restored",
+	},
+	ErrInvalidReplacement: {
+		Status:  http.StatusBadRequest,
+		Title:   "Invalid replacement book",
+		Message: "The replacement must be another active book",
+	},
 	ErrVersionConflict:   {Status: http.StatusConflict, Title: "Version conflict", Message: "The book has been modified by another user. Please refresh and try again"},
 	ErrISBNAlreadyExists: {Status: http.StatusConflict, Title: "ISBN already exists", Message: "This ISBN is already used by another book"},
 	ErrAuthorNotFound:    {Status: http.StatusBadRequest, Title: "Author not found", Message: "The specified author does not exist"},
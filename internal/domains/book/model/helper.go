@@ -22,6 +22,8 @@ func GenerateCacheKey(prefix string, req ListBooksRequest) string {
 		req.Sort,
 		strconv.Itoa(req.Page),
 		strconv.Itoa(req.Limit),
+		req.Pagination,
+		req.Cursor,
 	}
 	// Hash this to create a short cache key
 	keyStr := strings.Join(parts, ":")
@@ -121,6 +123,9 @@ func ToBookDetailResponse(b BookDetailRes, inventories []InventoryDetailDTO, rev
 		MetaDescription: b.MetaDescription,
 		MetaKeywords:    b.MetaKeywords,
 		Images:          b.Images,
+
+		ArchivedAt:        b.ArchivedAt,
+		ReplacementBookID: b.ReplacementBookID,
 	}
 }
 
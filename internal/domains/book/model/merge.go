@@ -0,0 +1,148 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ========================================
+// DUPLICATE DETECTION + MERGE
+// ========================================
+// GET /admin/books/duplicates: cặp sách nghi trùng (cùng ISBN sau khi chuẩn hoá ISBN-10/13,
+// hoặc tên gần giống + cùng / gần giống tác giả). POST /admin/books/:id/merge gộp sách nguồn vào :id.
+
+// Duplicate reasons
+const (
+	DuplicateReasonISBN        = "isbn"         // cùng ISBN (bỏ gạch nối, ISBN-10 = ISBN-13 tương ứng)
+	DuplicateReasonTitleAuthor = "title_author" // tên sách gần giống + cùng / gần giống tác giả
+)
+
+// Limits
+const (
+	// Ngưỡng trigram similarity của tên sách; >= 0.3 để dùng được index (ngưỡng mặc định của %)
+	DuplicateMinTitleScore     = 0.3
+	DuplicateDefaultTitleScore = 0.6
+	// Tác giả khác id nhưng tên gần giống ("Nguyễn Nhật Ánh" / "Nguyen Nhat Anh")
+	DuplicateAuthorScore  = 0.6
+	DuplicateDefaultLimit = 50
+	DuplicateMaxLimit     = 200
+)
+
+// DuplicateBookSummary thông tin đủ để admin chọn sách giữ lại
+type DuplicateBookSummary struct {
+	ID         uuid.UUID       `json:"id"`
+	Title      string          `json:"title"`
+	Slug       string          `json:"slug"`
+	ISBN       *string         `json:"isbn,omitempty"`
+	AuthorName string          `json:"author_name"`
+	Price      decimal.Decimal `json:"price"`
+	IsActive   bool            `json:"is_active"`
+	SoldCount  int             `json:"sold_count"`
+	TotalStock int             `json:"total_stock"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// DuplicateCandidate 1 cặp nghi trùng
+type DuplicateCandidate struct {
+	Reason string               `json:"reason"`
+	Score  float64              `json:"score"` // 1 với ISBN, similarity tên sách với title_author
+	BookA  DuplicateBookSummary `json:"book_a"`
+	BookB  DuplicateBookSummary `json:"book_b"`
+	// SuggestedTargetID sách nên giữ lại: bán nhiều hơn, bằng nhau thì tạo trước
+	SuggestedTargetID uuid.UUID `json:"suggested_target_id"`
+}
+
+// SuggestTarget chọn sách giữ lại cho cặp
+func (d *DuplicateCandidate) SuggestTarget() {
+	a, b := d.BookA, d.BookB
+	if b.SoldCount > a.SoldCount || (b.SoldCount == a.SoldCount && b.CreatedAt.Before(a.CreatedAt)) {
+		d.SuggestedTargetID = b.ID
+		return
+	}
+	d.SuggestedTargetID = a.ID
+}
+
+// BookMerge represents book_merges table
+type BookMerge struct {
+	ID                    uuid.UUID  `json:"id"`
+	SourceBookID          uuid.UUID  `json:"source_book_id"`
+	TargetBookID          uuid.UUID  `json:"target_book_id"`
+	CartItemsMoved        int        `json:"cart_items_moved"`
+	CartItemsCombined     int        `json:"cart_items_combined"`
+	OrderItemsMoved       int        `json:"order_items_moved"`
+	ReviewsMoved          int        `json:"reviews_moved"`
+	ReviewsSkipped        int        `json:"reviews_skipped"`
+	InventoryRowsMoved    int        `json:"inventory_rows_moved"`
+	InventoryRowsCombined int        `json:"inventory_rows_combined"`
+	MergedBy              *uuid.UUID `json:"merged_by,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// BookMergeResult kết quả gộp + slug cũ đã redirect
+type BookMergeResult struct {
+	BookMerge
+	RedirectedSlug string `json:"redirected_slug"`
+}
+
+// ========================================
+// REQUESTS / RESPONSES
+// ========================================
+
+// ListDuplicatesRequest - GET /admin/books/duplicates?min_score=0.6&limit=50
+type ListDuplicatesRequest struct {
+	MinScore float64 `form:"min_score"`
+	Limit    int     `form:"limit"`
+}
+
+func (r *ListDuplicatesRequest) Validate() error {
+	if r.MinScore == 0 {
+		r.MinScore = DuplicateDefaultTitleScore
+	}
+	if r.Limit == 0 {
+		r.Limit = DuplicateDefaultLimit
+	}
+	if r.MinScore < DuplicateMinTitleScore || r.MinScore > 1 {
+		return fmt.Errorf("%w: min_score must be between %.1f and 1", ErrInvalidDuplicateRequest, DuplicateMinTitleScore)
+	}
+	if r.Limit < 1 || r.Limit > DuplicateMaxLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidDuplicateRequest, DuplicateMaxLimit)
+	}
+	return nil
+}
+
+// MergeBooksRequest - POST /admin/books/:id/merge (:id = sách giữ lại)
+type MergeBooksRequest struct {
+	SourceBookID uuid.UUID `json:"source_book_id" binding:"required"`
+}
+
+// DismissDuplicateRequest - POST /admin/books/duplicates/dismiss (cặp không phải trùng)
+type DismissDuplicateRequest struct {
+	BookIDs [2]uuid.UUID `json:"book_ids" binding:"required"`
+}
+
+// ResolveSlugResponse - GET /books/slug/:slug (slug cũ của sách đã gộp → sách giữ lại)
+type ResolveSlugResponse struct {
+	BookID     uuid.UUID `json:"book_id"`
+	Slug       string    `json:"slug"`
+	Redirected bool      `json:"redirected"`
+}
+
+// BookMovedError sách đã bị gộp vào sách khác (handler trả 301)
+type BookMovedError struct {
+	TargetID uuid.UUID
+}
+
+func (e *BookMovedError) Error() string {
+	return fmt.Sprintf("book merged into %s", e.TargetID)
+}
+
+var (
+	ErrInvalidDuplicateRequest = errors.New("invalid duplicate request")
+	ErrInvalidMerge            = errors.New("invalid book merge")
+	// Tồn đang giữ cho đơn chưa xong (task release / sale đang chạy theo book id cũ) → gộp sau
+	ErrMergeSourceReserved = errors.New("source book has reserved stock")
+)
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// MetadataSuggestion represents book_metadata_suggestions table
+// Metadata lấy từ Google Books/OpenLibrary, chờ admin accept trước khi ghi vào books
+type MetadataSuggestion struct {
+	ID             string     `json:"id" db:"id"`
+	BookID         string     `json:"book_id" db:"book_id"`
+	Field          string     `json:"field" db:"field"` // cover_url | pages | description
+	SuggestedValue string     `json:"suggested_value" db:"suggested_value"`
+	CurrentValue   *string    `json:"current_value,omitempty" db:"current_value"`
+	Source         string     `json:"source" db:"source"` // google_books | open_library
+	SourceURL      *string    `json:"source_url,omitempty" db:"source_url"`
+	SourceISBN     string     `json:"source_isbn" db:"source_isbn"`
+	FetchedAt      time.Time  `json:"fetched_at" db:"fetched_at"`
+	Status         string     `json:"status" db:"status"` // pending | accepted | rejected
+	ReviewedBy     *string    `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Enrichable fields
+const (
+	MetadataFieldCoverURL    = "cover_url"
+	MetadataFieldPages       = "pages"
+	MetadataFieldDescription = "description"
+)
+
+// Suggestion status constants
+const (
+	SuggestionStatusPending  = "pending"
+	SuggestionStatusAccepted = "accepted"
+	SuggestionStatusRejected = "rejected"
+)
+
+// BookMetadataSnapshot - giá trị hiện tại của các field có thể enrich
+type BookMetadataSnapshot struct {
+	BookID      string
+	ISBN        *string
+	CoverURL    *string
+	Pages       *int
+	Description *string
+}
+
+type EnrichBookResponse struct {
+	BookID string `json:"book_id"`
+	ISBN   string `json:"isbn"`
+	Status string `json:"status"` // queued
+}
+
+var (
+	ErrBookHasNoISBN          = errors.New("book has no ISBN to enrich from")
+	ErrSuggestionNotFound     = errors.New("metadata suggestion not found")
+	ErrSuggestionNotPending   = errors.New("metadata suggestion is not pending")
+	ErrInvalidSuggestionValue = errors.New("metadata suggestion value is invalid for field")
+)
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PriceTier represents book_price_tiers table
+// Mua >= MinQuantity cuốn cùng 1 sách → giảm DiscountPercent trên giá bìa
+type PriceTier struct {
+	ID              uuid.UUID       `json:"id"`
+	BookID          uuid.UUID       `json:"book_id"`
+	MinQuantity     int             `json:"min_quantity"`
+	DiscountPercent decimal.Decimal `json:"discount_percent"`
+	UpdatedBy       *uuid.UUID      `json:"updated_by,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// Giới hạn cấu hình bậc giá
+const (
+	MaxPriceTiersPerBook        = 5
+	MinPriceTierQuantity        = 2
+	MaxPriceTierDiscountPercent = 90
+)
+
+// PriceTiers - các bậc của 1 sách, sắp theo MinQuantity tăng dần
+type PriceTiers []PriceTier
+
+// Apply trả về đơn giá sau khi áp bậc cao nhất mà quantity đạt được (nil = không bậc nào áp dụng)
+// Làm tròn 2 chữ số như cột NUMERIC(12,2) của cart_items.price
+func (t PriceTiers) Apply(listPrice decimal.Decimal, quantity int) (decimal.Decimal, *PriceTier) {
+	var matched *PriceTier
+	for i := range t {
+		if quantity >= t[i].MinQuantity && (matched == nil || t[i].MinQuantity > matched.MinQuantity) {
+			matched = &t[i]
+		}
+	}
+	if matched == nil {
+		return listPrice, nil
+	}
+
+	factor := decimal.NewFromInt(100).Sub(matched.DiscountPercent).Div(decimal.NewFromInt(100))
+	return listPrice.Mul(factor).Round(2), matched
+}
+
+// Next bậc kế tiếp khách chưa đạt (nil = đã ở bậc cao nhất hoặc sách không có bậc)
+func (t PriceTiers) Next(quantity int) *PriceTier {
+	var next *PriceTier
+	for i := range t {
+		if t[i].MinQuantity > quantity && (next == nil || t[i].MinQuantity < next.MinQuantity) {
+			next = &t[i]
+		}
+	}
+	return next
+}
+
+// ReplacePriceTiersRequest - thay toàn bộ bậc giá của 1 sách (PUT), danh sách rỗng = bỏ giá theo số lượng
+type ReplacePriceTiersRequest struct {
+	Tiers []PriceTierInput `json:"tiers" binding:"dive"`
+}
+
+type PriceTierInput struct {
+	MinQuantity     int             `json:"min_quantity" binding:"required"`
+	DiscountPercent decimal.Decimal `json:"discount_percent"`
+}
+
+// Validate kiểm tra bậc giá: không trùng số lượng, mua nhiều hơn phải giảm nhiều hơn
+func (r *ReplacePriceTiersRequest) Validate() error {
+	if len(r.Tiers) > MaxPriceTiersPerBook {
+		return fmt.Errorf("%w: at most %d tiers per book", ErrInvalidPriceTier, MaxPriceTiersPerBook)
+	}
+
+	tiers := make([]PriceTierInput, len(r.Tiers))
+	copy(tiers, r.Tiers)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinQuantity < tiers[j].MinQuantity })
+
+	maxDiscount := decimal.NewFromInt(MaxPriceTierDiscountPercent)
+	for i, tier := range tiers {
+		if tier.MinQuantity < MinPriceTierQuantity {
+			return fmt.Errorf("%w: min_quantity must be >= %d", ErrInvalidPriceTier, MinPriceTierQuantity)
+		}
+		if !tier.DiscountPercent.IsPositive() || tier.DiscountPercent.GreaterThan(maxDiscount) {
+			return fmt.Errorf("%w: discount_percent must be in (0, %d]", ErrInvalidPriceTier, MaxPriceTierDiscountPercent)
+		}
+		if i == 0 {
+			continue
+		}
+		if tier.MinQuantity == tiers[i-1].MinQuantity {
+			return fmt.Errorf("%w: duplicate min_quantity %d", ErrInvalidPriceTier, tier.MinQuantity)
+		}
+		if !tier.DiscountPercent.GreaterThan(tiers[i-1].DiscountPercent) {
+			return fmt.Errorf("%w: discount must increase with min_quantity", ErrInvalidPriceTier)
+		}
+	}
+
+	r.Tiers = tiers
+	return nil
+}
+
+var ErrInvalidPriceTier = errors.New("invalid price tier")
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ========================================
+// PRICE HISTORY + SCHEDULED PRICE CHANGES
+// ========================================
+// price_history do trigger trên books ghi (mọi đường đổi giá), price_schedules do admin hẹn giờ,
+// job book:apply_price_schedules áp lịch đến hạn mỗi phút.
+
+// Price change sources (price_history.source)
+const (
+	PriceSourceInitial   = "initial"   // giá lúc tạo sách / trước khi có lịch sử
+	PriceSourceManual    = "manual"    // admin sửa sách
+	PriceSourceScheduled = "scheduled" // job áp lịch đổi giá
+)
+
+// Price schedule statuses
+const (
+	PriceScheduleStatusPending   = "pending"
+	PriceScheduleStatusApplied   = "applied"
+	PriceScheduleStatusCancelled = "cancelled"
+	PriceScheduleStatusFailed    = "failed" // sách đã xoá / giá gạch thấp hơn giá bán lúc áp
+)
+
+// Limits
+const (
+	PriceHistoryDefaultDays   = 90
+	PriceHistoryMaxDays       = 365
+	LowestPriceWindowDays     = 30 // "giá thấp nhất 30 ngày" trên storefront
+	PriceScheduleMaxAheadDays = 366
+	PriceScheduleApplyBatch   = 100 // số lịch đến hạn áp mỗi lần chạy job
+	PriceScheduleMaxNoteLen   = 500
+)
+
+// PriceHistoryEntry represents price_history table
+type PriceHistoryEntry struct {
+	ID                uuid.UUID        `json:"id"`
+	BookID            uuid.UUID        `json:"book_id"`
+	OldPrice          *decimal.Decimal `json:"old_price,omitempty"`
+	NewPrice          decimal.Decimal  `json:"new_price"`
+	OldCompareAtPrice *decimal.Decimal `json:"old_compare_at_price,omitempty"`
+	NewCompareAtPrice *decimal.Decimal `json:"new_compare_at_price,omitempty"`
+	Source            string           `json:"source"`
+	ScheduleID        *uuid.UUID       `json:"schedule_id,omitempty"`
+	ChangedAt         time.Time        `json:"changed_at"`
+}
+
+// PriceSchedule represents price_schedules table
+type PriceSchedule struct {
+	ID                uuid.UUID        `json:"id"`
+	BookID            uuid.UUID        `json:"book_id"`
+	Price             *decimal.Decimal `json:"price,omitempty"` // nil = giữ giá hiện tại
+	CompareAtPrice    *decimal.Decimal `json:"compare_at_price,omitempty"`
+	SetCompareAtPrice bool             `json:"set_compare_at_price"` // true + CompareAtPrice nil = bỏ giá gạch
+	EffectiveAt       time.Time        `json:"effective_at"`
+	Status            string           `json:"status"`
+	Note              *string          `json:"note,omitempty"`
+	FailureReason     *string          `json:"failure_reason,omitempty"`
+	CreatedBy         *uuid.UUID       `json:"created_by,omitempty"`
+	CancelledBy       *uuid.UUID       `json:"cancelled_by,omitempty"`
+	AppliedAt         *time.Time       `json:"applied_at,omitempty"`
+	CancelledAt       *time.Time       `json:"cancelled_at,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// AppliedPriceSchedule kết quả áp 1 lịch (job dùng để invalidate cache + đồng bộ giá lên sàn)
+type AppliedPriceSchedule struct {
+	ScheduleID   uuid.UUID
+	BookID       uuid.UUID
+	Applied      bool
+	PriceChanged bool
+	Reason       string // lý do failed
+}
+
+// ApplyPriceSchedulesResult kết quả 1 lần chạy job
+type ApplyPriceSchedulesResult struct {
+	Applied int
+	Failed  int
+}
+
+// ========================================
+// REQUESTS / RESPONSES
+// ========================================
+
+// CreatePriceScheduleRequest - hẹn giờ đổi giá
+// Ví dụ sale cuối tuần: {"price": 80000, "compare_at_price": 100000, "effective_at": "...T00:00:00+07:00"}
+// rồi lịch kết thúc: {"price": 100000, "clear_compare_at_price": true, "effective_at": "..."}
+type CreatePriceScheduleRequest struct {
+	Price               *float64  `json:"price" binding:"omitempty,gt=0"`
+	CompareAtPrice      *float64  `json:"compare_at_price" binding:"omitempty,gt=0"`
+	ClearCompareAtPrice bool      `json:"clear_compare_at_price"`
+	EffectiveAt         time.Time `json:"effective_at" binding:"required"`
+	Note                *string   `json:"note"`
+}
+
+func (r *CreatePriceScheduleRequest) Validate(now time.Time) error {
+	if r.Price == nil && r.CompareAtPrice == nil && !r.ClearCompareAtPrice {
+		return fmt.Errorf("%w: price, compare_at_price or clear_compare_at_price is required", ErrInvalidPriceSchedule)
+	}
+	if r.CompareAtPrice != nil && r.ClearCompareAtPrice {
+		return fmt.Errorf("%w: compare_at_price and clear_compare_at_price are mutually exclusive", ErrInvalidPriceSchedule)
+	}
+	if r.Price != nil && r.CompareAtPrice != nil && *r.CompareAtPrice < *r.Price {
+		return fmt.Errorf("%w: compare_at_price must be >= price", ErrInvalidPriceSchedule)
+	}
+	if !r.EffectiveAt.After(now) {
+		return fmt.Errorf("%w: effective_at must be in the future", ErrInvalidPriceSchedule)
+	}
+	if r.EffectiveAt.After(now.AddDate(0, 0, PriceScheduleMaxAheadDays)) {
+		return fmt.Errorf("%w: effective_at must be within %d days", ErrInvalidPriceSchedule, PriceScheduleMaxAheadDays)
+	}
+	if r.Note != nil && len(*r.Note) > PriceScheduleMaxNoteLen {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrInvalidPriceSchedule, PriceScheduleMaxNoteLen)
+	}
+	return nil
+}
+
+// ToSchedule dựng lịch pending từ request
+func (r *CreatePriceScheduleRequest) ToSchedule(bookID, createdBy uuid.UUID) *PriceSchedule {
+	schedule := &PriceSchedule{
+		ID:                uuid.New(),
+		BookID:            bookID,
+		SetCompareAtPrice: r.CompareAtPrice != nil || r.ClearCompareAtPrice,
+		EffectiveAt:       r.EffectiveAt,
+		Status:            PriceScheduleStatusPending,
+		Note:              r.Note,
+		CreatedBy:         &createdBy,
+	}
+	if r.Price != nil {
+		price := decimal.NewFromFloat(*r.Price).Round(2)
+		schedule.Price = &price
+	}
+	if r.CompareAtPrice != nil {
+		compareAt := decimal.NewFromFloat(*r.CompareAtPrice).Round(2)
+		schedule.CompareAtPrice = &compareAt
+	}
+	return schedule
+}
+
+// ListPriceSchedulesRequest - lịch đổi giá của 1 sách (status rỗng = mọi trạng thái)
+type ListPriceSchedulesRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending applied cancelled failed"`
+}
+
+// PriceHistoryResponse - lịch sử giá N ngày gần nhất + giá thấp nhất 30 ngày
+type PriceHistoryResponse struct {
+	BookID         uuid.UUID        `json:"book_id"`
+	CurrentPrice   decimal.Decimal  `json:"current_price"`
+	CompareAtPrice *decimal.Decimal `json:"compare_at_price,omitempty"`
+	// LowestPrice30d giá bán thấp nhất có hiệu lực trong 30 ngày qua (kể cả giá hiện tại)
+	LowestPrice30d decimal.Decimal     `json:"lowest_price_30d"`
+	Days           int                 `json:"days"`
+	History        []PriceHistoryEntry `json:"history"`
+}
+
+var (
+	ErrInvalidPriceSchedule     = errors.New("invalid price schedule")
+	ErrPriceScheduleNotFound    = errors.New("price schedule not found")
+	ErrPriceScheduleNotPending  = errors.New("price schedule is no longer pending")
+	ErrInvalidPriceHistoryRange = errors.New("invalid price history range")
+)
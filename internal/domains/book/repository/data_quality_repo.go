@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DataQualityRepository interface {
+	// ScanIssues quét lại toàn bộ sách chưa xoá trong 1 transaction: thêm vấn đề mới (giữ detected_at
+	// của vấn đề cũ), xoá vấn đề đã được sửa
+	ScanIssues(ctx context.Context) (*model.DataQualityScanResult, error)
+	// CountIssues số sách theo từng vấn đề + thời điểm quét gần nhất
+	CountIssues(ctx context.Context, activeOnly bool) (map[string]int, *time.Time, error)
+	// ListBooksWithIssues sách có vấn đề (lọc theo issue nếu có), vấn đề lâu nhất trước
+	ListBooksWithIssues(ctx context.Context, req model.DataQualityReportRequest) ([]model.BookDataQualityItem, int, error)
+}
+
+type dataQualityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDataQualityRepository(pool *pgxpool.Pool) DataQualityRepository {
+	return &dataQualityRepository{pool: pool}
+}
+
+// scanDataQualityQuery mỗi dòng = (book_id, issue) đang tồn tại ở thời điểm quét
+// Cover: cover_url trống vẫn OK nếu đã có ảnh cover xử lý xong trong book_images
+const scanDataQualityQuery = `
+	SELECT b.id, 'missing_cover'
+	FROM books b
+	WHERE b.deleted_at IS NULL
+		AND NULLIF(BTRIM(b.cover_url), '') IS NULL
+		AND NOT EXISTS (
+			SELECT 1 FROM book_images bi
+			WHERE bi.book_id = b.id AND bi.is_cover = true AND bi.status = 'ready'
+		)
+	UNION ALL
+	SELECT b.id, 'missing_description'
+	FROM books b
+	WHERE b.deleted_at IS NULL AND NULLIF(BTRIM(b.description), '') IS NULL
+	UNION ALL
+	SELECT b.id, 'missing_category'
+	FROM books b
+	WHERE b.deleted_at IS NULL AND b.category_id IS NULL
+	UNION ALL
+	SELECT b.id, 'no_inventory'
+	FROM books b
+	WHERE b.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM warehouse_inventory wi WHERE wi.book_id = b.id)
+`
+
+func (r *dataQualityRepository) ScanIssues(ctx context.Context) (*model.DataQualityScanResult, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin data quality scan: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// NOW() cố định trong transaction → dòng không được chạm tới ở bước upsert là vấn đề đã sửa
+	_, err = tx.Exec(ctx, `
+		INSERT INTO book_data_quality_issues (book_id, issue, detected_at, last_checked_at)
+		SELECT id, issue, NOW(), NOW()
+		FROM (`+scanDataQualityQuery+`) AS current_issues(id, issue)
+		ON CONFLICT (book_id, issue) DO UPDATE SET last_checked_at = EXCLUDED.last_checked_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("upsert data quality issues: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM book_data_quality_issues WHERE last_checked_at < NOW()`)
+	if err != nil {
+		return nil, fmt.Errorf("delete resolved data quality issues: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT issue, COUNT(*) FROM book_data_quality_issues GROUP BY issue`)
+	if err != nil {
+		return nil, fmt.Errorf("count data quality issues: %w", err)
+	}
+	counts := make(map[string]int, len(model.DataQualityIssues))
+	for rows.Next() {
+		var issue string
+		var count int
+		if err := rows.Scan(&issue, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan data quality count: %w", err)
+		}
+		counts[issue] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("count data quality issues: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit data quality scan: %w", err)
+	}
+
+	return &model.DataQualityScanResult{Counts: counts, Resolved: int(tag.RowsAffected())}, nil
+}
+
+func (r *dataQualityRepository) CountIssues(ctx context.Context, activeOnly bool) (map[string]int, *time.Time, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT q.issue, COUNT(*), MAX(q.last_checked_at)
+		FROM book_data_quality_issues q
+		JOIN books b ON b.id = q.book_id
+		WHERE b.deleted_at IS NULL AND ($1 = false OR b.is_active = true)
+		GROUP BY q.issue
+	`, activeOnly)
+	if err != nil {
+		return nil, nil, fmt.Errorf("count data quality issues: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(model.DataQualityIssues))
+	for _, issue := range model.DataQualityIssues {
+		counts[issue] = 0
+	}
+	var lastScanned *time.Time
+	for rows.Next() {
+		var issue string
+		var count int
+		var checkedAt time.Time
+		if err := rows.Scan(&issue, &count, &checkedAt); err != nil {
+			return nil, nil, fmt.Errorf("scan data quality count: %w", err)
+		}
+		counts[issue] = count
+		if lastScanned == nil || checkedAt.After(*lastScanned) {
+			t := checkedAt
+			lastScanned = &t
+		}
+	}
+	return counts, lastScanned, rows.Err()
+}
+
+func (r *dataQualityRepository) ListBooksWithIssues(ctx context.Context, req model.DataQualityReportRequest) ([]model.BookDataQualityItem, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	// Lọc theo issue = sách có vấn đề đó, nhưng vẫn trả đủ mọi vấn đề của sách
+	rows, err := r.pool.Query(ctx, `
+		WITH flagged AS (
+			SELECT q.book_id,
+				ARRAY_AGG(q.issue ORDER BY q.issue) AS issues,
+				MIN(q.detected_at) AS detected_at
+			FROM book_data_quality_issues q
+			GROUP BY q.book_id
+			HAVING $1 = '' OR BOOL_OR(q.issue = $1)
+		)
+		SELECT b.id, b.title, b.slug, b.isbn, COALESCE(b.is_active, false), f.issues, f.detected_at,
+			COUNT(*) OVER () AS total
+		FROM flagged f
+		JOIN books b ON b.id = f.book_id
+		WHERE b.deleted_at IS NULL AND ($2 = false OR b.is_active = true)
+		ORDER BY f.detected_at, b.title
+		LIMIT $3 OFFSET $4
+	`, req.Issue, req.ActiveOnly, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list books with data quality issues: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.BookDataQualityItem, 0)
+	total := 0
+	for rows.Next() {
+		var item model.BookDataQualityItem
+		if err := rows.Scan(
+			&item.BookID, &item.Title, &item.Slug, &item.ISBN, &item.IsActive, &item.Issues, &item.DetectedAt, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan data quality book: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, total, rows.Err()
+}
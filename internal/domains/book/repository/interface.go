@@ -2,6 +2,7 @@ package repository
 
 import (
 	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/shared/pagination"
 	"context"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 // RepositoryInterface - Định nghĩa data access methods
 type RepositoryInterface interface {
 	ListBooks(ctx context.Context, filter *model.BookFilter) ([]model.Book, int, error)
+	ListBooksAfterCursor(ctx context.Context, filter *model.BookFilter, after *pagination.Cursor, limit int) ([]model.Book, error)
 	GetBaseBookByID(ctx context.Context, id string) (*model.BaseBookResponse, error)
 	GetBookByID(ctx context.Context, id string) (*model.BookDetailRes, []model.InventoryDetailDTO, error)
 	GetBookByIDForUpdate(ctx context.Context, id string) (*model.Book, error)
@@ -20,8 +22,11 @@ type RepositoryInterface interface {
 	UpdateBook(ctx context.Context, book *model.Book) error
 	CheckBookHasReservedInventory(ctx context.Context, bookID string) (bool, error)
 	CheckBookHasActiveOrders(ctx context.Context, bookID string) (bool, error)
-	SoftDeleteBook(ctx context.Context, bookID string, deletedAt time.Time) error
-	SearchBooks(ctx context.Context, req model.SearchBooksRequest) ([]model.BookSearchResponse, error)
+	ArchiveBook(ctx context.Context, bookID string, archivedBy, replacementID *uuid.UUID, archivedAt time.Time) error
+	RestoreBook(ctx context.Context, bookID string) error
+	IsReplacementCandidate(ctx context.Context, bookID uuid.UUID) (bool, error)
+	FindReplacement(ctx context.Context, bookID uuid.UUID) (*model.ReplacementSuggestion, error)
+	SearchBooks(ctx context.Context, req model.SearchBooksRequest) ([]model.BookSearchResponse, int, error)
 	CheckISBNExists(ctx context.Context, isbn string) (bool, error)
 	GenerateUniqueSlug(ctx context.Context, baseSlug string) (string, error)
 	IncrementViewCount(ctx context.Context, bookID string) error
@@ -34,6 +39,8 @@ type RepositoryInterface interface {
 	FindBySlugWithTx(ctx context.Context, tx pgx.Tx, slug string) (*model.Book, error)
 	GetBooksByIDs(ctx context.Context, ids []string) ([]model.Book, error)
 	GetBooksCheckout(ctx context.Context, ids []string) ([]model.BookCheckoutResponse, error)
+	// FindMergedInto sách đã bị gộp → id sách giữ lại (false nếu không có redirect)
+	FindMergedInto(ctx context.Context, bookID string) (uuid.UUID, bool, error)
 }
 
 // BookFilter - Filter object for database query
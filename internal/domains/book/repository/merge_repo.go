@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MergeRepository interface {
+	// FindDuplicates cặp sách chưa xoá nghi trùng (ISBN trùng trước, rồi similarity giảm dần), bỏ cặp đã dismiss
+	FindDuplicates(ctx context.Context, req model.ListDuplicatesRequest) ([]model.DuplicateCandidate, error)
+	// DismissDuplicate đánh dấu cặp không trùng (gọi lại không lỗi)
+	DismissDuplicate(ctx context.Context, bookA, bookB, adminID uuid.UUID) error
+	// MergeBooks chuyển giỏ hàng / dòng đơn / review / tồn của source sang target, xoá mềm source,
+	// ghi redirect slug + id trong 1 transaction
+	// Returns ErrBookNotFound, ErrMergeSourceReserved
+	MergeBooks(ctx context.Context, sourceID, targetID, adminID uuid.UUID) (*model.BookMergeResult, error)
+	// ResolveSlug slug hiện tại hoặc slug cũ đã redirect → sách đang hiển thị
+	// Returns ErrBookNotFound
+	ResolveSlug(ctx context.Context, slug string) (*model.ResolveSlugResponse, error)
+}
+
+type mergeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMergeRepository(pool *pgxpool.Pool) MergeRepository {
+	return &mergeRepository{pool: pool}
+}
+
+// isbnKeySQL chuẩn hoá ISBN để so khớp: bỏ ký tự ngoài 0-9/X; ISBN-10 và ISBN-13 (978) cùng 1 sách
+// có chung 12 ký tự đầu khi bỏ check digit ('978' + 9 số đầu của ISBN-10)
+const isbnKeySQL = `(CASE
+	WHEN length(%[1]s) = 10 THEN '978' || left(%[1]s, 9)
+	WHEN length(%[1]s) = 13 THEN left(%[1]s, 12)
+	ELSE %[1]s
+END)`
+
+func isbnKey(column string) string {
+	normalized := fmt.Sprintf(`regexp_replace(upper(%s), '[^0-9X]', '', 'g')`, column)
+	return fmt.Sprintf(isbnKeySQL, normalized)
+}
+
+func (r *mergeRepository) FindDuplicates(ctx context.Context, req model.ListDuplicatesRequest) ([]model.DuplicateCandidate, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	// Tên sách: % dùng GIN trigram index idx_books_title_trgm (ngưỡng mặc định 0.3), lọc lại theo min_score
+	query := `
+		WITH live AS (
+			SELECT b.id, b.author_id, a.name AS author_name,
+				immutable_unaccent(lower(b.title)) AS title_key,
+				` + isbnKey("b.isbn") + ` AS isbn_key
+			FROM books b
+			JOIN authors a ON a.id = b.author_id
+			WHERE b.deleted_at IS NULL
+		),
+		candidates AS (
+			SELECT x.id AS a_id, y.id AS b_id, 'isbn' AS reason, 1.0::float8 AS score
+			FROM live x
+			JOIN live y ON x.isbn_key = y.isbn_key AND x.id < y.id
+			WHERE x.isbn_key <> ''
+			UNION ALL
+			SELECT x.id, y.id, 'title_author', similarity(x.title_key, y.title_key)::float8
+			FROM live x
+			JOIN live y ON x.title_key % y.title_key AND x.id < y.id
+			WHERE similarity(x.title_key, y.title_key) >= $1
+				AND (x.author_id = y.author_id
+					OR similarity(immutable_unaccent(lower(x.author_name)), immutable_unaccent(lower(y.author_name))) >= $2)
+		),
+		pairs AS (
+			SELECT DISTINCT ON (a_id, b_id) a_id, b_id, reason, score
+			FROM candidates c
+			WHERE NOT EXISTS (
+				SELECT 1 FROM book_duplicate_dismissals d WHERE d.book_a_id = c.a_id AND d.book_b_id = c.b_id
+			)
+			ORDER BY a_id, b_id, (reason = 'isbn') DESC
+		)
+		SELECT p.reason, p.score, p.a_id, p.b_id
+		FROM pairs p
+		ORDER BY (p.reason = 'isbn') DESC, p.score DESC, p.a_id, p.b_id
+		LIMIT $3
+	`
+	rows, err := r.pool.Query(ctx, query, req.MinScore, model.DuplicateAuthorScore, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("find duplicate books: %w", err)
+	}
+
+	candidates := make([]model.DuplicateCandidate, 0)
+	bookIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var c model.DuplicateCandidate
+		if err := rows.Scan(&c.Reason, &c.Score, &c.BookA.ID, &c.BookB.ID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan duplicate pair: %w", err)
+		}
+		candidates = append(candidates, c)
+		bookIDs = append(bookIDs, c.BookA.ID, c.BookB.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("find duplicate books: %w", err)
+	}
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	summaries, err := r.bookSummaries(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		candidates[i].BookA = summaries[candidates[i].BookA.ID]
+		candidates[i].BookB = summaries[candidates[i].BookB.ID]
+		candidates[i].SuggestTarget()
+	}
+	return candidates, nil
+}
+
+func (r *mergeRepository) bookSummaries(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]model.DuplicateBookSummary, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT b.id, b.title, b.slug, b.isbn, a.name, b.price, COALESCE(b.is_active, false),
+			COALESCE(b.sold_count, 0), COALESCE(s.total, 0), b.created_at
+		FROM books b
+		JOIN authors a ON a.id = b.author_id
+		LEFT JOIN (
+			SELECT book_id, SUM(quantity)::int AS total
+			FROM warehouse_inventory
+			WHERE book_id = ANY($1)
+			GROUP BY book_id
+		) s ON s.book_id = b.id
+		WHERE b.id = ANY($1)
+	`, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load duplicate book summaries: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]model.DuplicateBookSummary, len(bookIDs))
+	for rows.Next() {
+		var s model.DuplicateBookSummary
+		if err := rows.Scan(
+			&s.ID, &s.Title, &s.Slug, &s.ISBN, &s.AuthorName, &s.Price, &s.IsActive,
+			&s.SoldCount, &s.TotalStock, &s.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan duplicate book summary: %w", err)
+		}
+		result[s.ID] = s
+	}
+	return result, rows.Err()
+}
+
+func (r *mergeRepository) DismissDuplicate(ctx context.Context, bookA, bookB, adminID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	// Lưu theo thứ tự (a < b) như cặp trong FindDuplicates
+	if bookB.String() < bookA.String() {
+		bookA, bookB = bookB, bookA
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO book_duplicate_dismissals (book_a_id, book_b_id, dismissed_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (book_a_id, book_b_id) DO NOTHING
+	`, bookA, bookB, adminID)
+	if err != nil {
+		return fmt.Errorf("dismiss duplicate: %w", err)
+	}
+	return nil
+}
+
+func (r *mergeRepository) MergeBooks(ctx context.Context, sourceID, targetID, adminID uuid.UUID) (*model.BookMergeResult, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	return database.WithTransactionResult(ctx, r.pool, func(tx pgx.Tx) (*model.BookMergeResult, error) {
+		// 1. Khoá 2 sách theo thứ tự id (2 admin gộp chéo nhau không deadlock)
+		rows, err := tx.Query(ctx, `
+			SELECT id, slug FROM books
+			WHERE id = ANY($1) AND deleted_at IS NULL
+			ORDER BY id
+			FOR UPDATE
+		`, []uuid.UUID{sourceID, targetID})
+		if err != nil {
+			return nil, fmt.Errorf("lock books: %w", err)
+		}
+		slugs := make(map[uuid.UUID]string, 2)
+		for rows.Next() {
+			var id uuid.UUID
+			var slug string
+			if err := rows.Scan(&id, &slug); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan locked book: %w", err)
+			}
+			slugs[id] = slug
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("lock books: %w", err)
+		}
+		if len(slugs) != 2 {
+			return nil, model.ErrBookNotFound
+		}
+
+		var reserved int
+		if err := tx.QueryRow(ctx,
+			`SELECT COALESCE(SUM(reserved), 0) FROM warehouse_inventory WHERE book_id = $1`, sourceID,
+		).Scan(&reserved); err != nil {
+			return nil, fmt.Errorf("check reserved stock: %w", err)
+		}
+		if reserved > 0 {
+			return nil, model.ErrMergeSourceReserved
+		}
+
+		merge := model.BookMerge{ID: uuid.New(), SourceBookID: sourceID, TargetBookID: targetID, MergedBy: &adminID}
+		exec := func(count *int, sql string, args ...interface{}) error {
+			tag, err := tx.Exec(ctx, sql, args...)
+			if err != nil {
+				return err
+			}
+			if count != nil {
+				*count = int(tag.RowsAffected())
+			}
+			return nil
+		}
+
+		// 2. Giỏ hàng: giỏ đã có sách giữ lại → cộng số lượng, còn lại đổi book_id (giá theo sách giữ lại)
+		if err := exec(&merge.CartItemsCombined, `
+			UPDATE cart_items t
+			SET quantity = t.quantity + s.quantity, updated_at = NOW()
+			FROM cart_items s
+			WHERE s.book_id = $1 AND t.book_id = $2 AND t.cart_id = s.cart_id
+		`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("combine cart items: %w", err)
+		}
+		if err := exec(nil, `
+			DELETE FROM cart_items s
+			WHERE s.book_id = $1
+				AND EXISTS (SELECT 1 FROM cart_items t WHERE t.cart_id = s.cart_id AND t.book_id = $2)
+		`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("delete combined cart items: %w", err)
+		}
+		if err := exec(&merge.CartItemsMoved, `
+			UPDATE cart_items
+			SET book_id = $2, price = (SELECT price FROM books WHERE id = $2), updated_at = NOW()
+			WHERE book_id = $1
+		`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("move cart items: %w", err)
+		}
+
+		// 3. Lịch sử đơn: dòng đơn giữ snapshot tên / giá lúc mua, chỉ đổi book_id
+		if err := exec(&merge.OrderItemsMoved,
+			`UPDATE order_items SET book_id = $2 WHERE book_id = $1`, sourceID, targetID,
+		); err != nil {
+			return nil, fmt.Errorf("move order items: %w", err)
+		}
+
+		// 4. Review: khách đã review sách giữ lại → giữ review cũ trên sách bị gộp (UNIQUE user_id, book_id)
+		if err := exec(&merge.ReviewsMoved, `
+			UPDATE reviews r
+			SET book_id = $2
+			WHERE r.book_id = $1
+				AND NOT EXISTS (SELECT 1 FROM reviews t WHERE t.book_id = $2 AND t.user_id = r.user_id)
+		`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("move reviews: %w", err)
+		}
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM reviews WHERE book_id = $1`, sourceID).Scan(&merge.ReviewsSkipped); err != nil {
+			return nil, fmt.Errorf("count skipped reviews: %w", err)
+		}
+		// Trigger rating chỉ chạy khi đổi rating / is_approved → tính lại cho sách giữ lại
+		if err := exec(nil, `
+			UPDATE books
+			SET rating_average = COALESCE((SELECT ROUND(AVG(rating)::numeric, 1) FROM reviews WHERE book_id = $1 AND is_approved = true), 0),
+				rating_count = (SELECT COUNT(*) FROM reviews WHERE book_id = $1 AND is_approved = true)
+			WHERE id = $1
+		`, targetID); err != nil {
+			return nil, fmt.Errorf("refresh rating: %w", err)
+		}
+
+		// 5. Tồn kho: kho đã có dòng của sách giữ lại → cộng dồn (trigger ghi audit), còn lại đổi book_id
+		if err := exec(&merge.InventoryRowsCombined, `
+			UPDATE warehouse_inventory t
+			SET quantity = t.quantity + s.quantity, reserved = t.reserved + s.reserved, updated_by = $3
+			FROM warehouse_inventory s
+			WHERE s.book_id = $1 AND t.book_id = $2 AND t.warehouse_id = s.warehouse_id
+		`, sourceID, targetID, adminID); err != nil {
+			return nil, fmt.Errorf("combine inventory: %w", err)
+		}
+		if err := exec(nil, `
+			DELETE FROM warehouse_inventory s
+			WHERE s.book_id = $1
+				AND EXISTS (SELECT 1 FROM warehouse_inventory t WHERE t.warehouse_id = s.warehouse_id AND t.book_id = $2)
+		`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("delete combined inventory: %w", err)
+		}
+		if err := exec(&merge.InventoryRowsMoved, `
+			UPDATE warehouse_inventory SET book_id = $2, updated_by = $3 WHERE book_id = $1
+		`, sourceID, targetID, adminID); err != nil {
+			return nil, fmt.Errorf("move inventory: %w", err)
+		}
+		// Serial đi theo tồn; cảnh báo tồn thấp của sách cũ không còn ý nghĩa
+		if err := exec(nil, `UPDATE inventory_serial_units SET book_id = $2 WHERE book_id = $1`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("move serial units: %w", err)
+		}
+		if err := exec(nil, `
+			UPDATE low_stock_alerts SET is_resolved = true, resolved_at = NOW()
+			WHERE book_id = $1 AND is_resolved = false
+		`, sourceID); err != nil {
+			return nil, fmt.Errorf("resolve low stock alerts: %w", err)
+		}
+
+		// 6. Xoá mềm sách bị gộp
+		if err := exec(nil, `
+			UPDATE books SET deleted_at = NOW(), is_active = false, updated_at = NOW() WHERE id = $1
+		`, sourceID); err != nil {
+			return nil, fmt.Errorf("soft delete source book: %w", err)
+		}
+
+		// 7. Ghi lần gộp + redirect (redirect cũ trỏ về sách bị gộp → trỏ thẳng sang sách giữ lại)
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO book_merges (
+				id, source_book_id, target_book_id, cart_items_moved, cart_items_combined, order_items_moved,
+				reviews_moved, reviews_skipped, inventory_rows_moved, inventory_rows_combined, merged_by
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING created_at
+		`,
+			merge.ID, merge.SourceBookID, merge.TargetBookID, merge.CartItemsMoved, merge.CartItemsCombined,
+			merge.OrderItemsMoved, merge.ReviewsMoved, merge.ReviewsSkipped, merge.InventoryRowsMoved,
+			merge.InventoryRowsCombined, merge.MergedBy,
+		).Scan(&merge.CreatedAt); err != nil {
+			return nil, fmt.Errorf("record book merge: %w", err)
+		}
+		if err := exec(nil, `UPDATE book_redirects SET book_id = $2 WHERE book_id = $1`, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("repoint redirects: %w", err)
+		}
+		if err := exec(nil, `
+			INSERT INTO book_redirects (old_book_id, old_slug, book_id, merge_id) VALUES ($1, $2, $3, $4)
+		`, sourceID, slugs[sourceID], targetID, merge.ID); err != nil {
+			return nil, fmt.Errorf("create redirect: %w", err)
+		}
+
+		return &model.BookMergeResult{BookMerge: merge, RedirectedSlug: slugs[sourceID]}, nil
+	})
+}
+
+func (r *mergeRepository) ResolveSlug(ctx context.Context, slug string) (*model.ResolveSlugResponse, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var res model.ResolveSlugResponse
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, slug, false FROM books WHERE slug = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT b.id, b.slug, true
+		FROM book_redirects r
+		JOIN books b ON b.id = r.book_id AND b.deleted_at IS NULL
+		WHERE r.old_slug = $1
+		LIMIT 1
+	`, slug).Scan(&res.BookID, &res.Slug, &res.Redirected)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.ErrBookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve slug: %w", err)
+	}
+	return &res, nil
+}
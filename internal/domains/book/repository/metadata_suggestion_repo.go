@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MetadataSuggestionRepository interface {
+	// GetBookMetadataSnapshot lấy ISBN + giá trị hiện tại của các field enrich được
+	GetBookMetadataSnapshot(ctx context.Context, bookID string) (*model.BookMetadataSnapshot, error)
+	// UpsertPending tạo mới hoặc refresh suggestion pending của (book, field, source)
+	UpsertPending(ctx context.Context, s *model.MetadataSuggestion) error
+	GetByID(ctx context.Context, id string) (*model.MetadataSuggestion, error)
+	ListByBook(ctx context.Context, bookID, status string) ([]model.MetadataSuggestion, error)
+	// Accept ghi suggested_value vào books.<field> và reject các suggestion pending khác cùng field (1 transaction)
+	Accept(ctx context.Context, id, reviewedBy string) (*model.MetadataSuggestion, error)
+	Reject(ctx context.Context, id, reviewedBy string) error
+}
+
+type metadataSuggestionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMetadataSuggestionRepository(pool *pgxpool.Pool) MetadataSuggestionRepository {
+	return &metadataSuggestionRepository{pool: pool}
+}
+
+const metadataSuggestionColumns = `
+	id, book_id, field, suggested_value, current_value, source, source_url,
+	source_isbn, fetched_at, status, reviewed_by, reviewed_at, created_at
+`
+
+// bookColumnUpdates - whitelist field -> câu UPDATE (không build SQL từ input)
+var bookColumnUpdates = map[string]string{
+	model.MetadataFieldCoverURL:    `UPDATE books SET cover_url = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`,
+	model.MetadataFieldPages:       `UPDATE books SET pages = $2::int, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`,
+	model.MetadataFieldDescription: `UPDATE books SET description = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`,
+}
+
+func (r *metadataSuggestionRepository) GetBookMetadataSnapshot(ctx context.Context, bookID string) (*model.BookMetadataSnapshot, error) {
+	query := `
+		SELECT id, isbn, cover_url, pages, description
+		FROM books
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var snap model.BookMetadataSnapshot
+	err := r.pool.QueryRow(ctx, query, bookID).Scan(
+		&snap.BookID,
+		&snap.ISBN,
+		&snap.CoverURL,
+		&snap.Pages,
+		&snap.Description,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("get book metadata snapshot: %w", err)
+	}
+
+	return &snap, nil
+}
+
+func (r *metadataSuggestionRepository) UpsertPending(ctx context.Context, s *model.MetadataSuggestion) error {
+	query := `
+		INSERT INTO book_metadata_suggestions (
+			book_id, field, suggested_value, current_value,
+			source, source_url, source_isbn, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending')
+		ON CONFLICT (book_id, field, source) WHERE status = 'pending'
+		DO UPDATE SET
+			suggested_value = EXCLUDED.suggested_value,
+			current_value = EXCLUDED.current_value,
+			source_url = EXCLUDED.source_url,
+			source_isbn = EXCLUDED.source_isbn,
+			fetched_at = NOW()
+		RETURNING ` + metadataSuggestionColumns
+
+	saved, err := scanMetadataSuggestion(r.pool.QueryRow(ctx, query,
+		s.BookID,
+		s.Field,
+		s.SuggestedValue,
+		s.CurrentValue,
+		s.Source,
+		s.SourceURL,
+		s.SourceISBN,
+	))
+	if err != nil {
+		return fmt.Errorf("upsert metadata suggestion: %w", err)
+	}
+
+	*s = *saved
+	return nil
+}
+
+func (r *metadataSuggestionRepository) GetByID(ctx context.Context, id string) (*model.MetadataSuggestion, error) {
+	query := `SELECT ` + metadataSuggestionColumns + ` FROM book_metadata_suggestions WHERE id = $1`
+
+	s, err := scanMetadataSuggestion(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrSuggestionNotFound
+		}
+		return nil, fmt.Errorf("get metadata suggestion: %w", err)
+	}
+	return s, nil
+}
+
+func (r *metadataSuggestionRepository) ListByBook(ctx context.Context, bookID, status string) ([]model.MetadataSuggestion, error) {
+	query := `SELECT ` + metadataSuggestionColumns + `
+		FROM book_metadata_suggestions
+		WHERE book_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY field ASC, fetched_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, bookID, status)
+	if err != nil {
+		return nil, fmt.Errorf("list metadata suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]model.MetadataSuggestion, 0)
+	for rows.Next() {
+		s, err := scanMetadataSuggestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan metadata suggestion: %w", err)
+		}
+		suggestions = append(suggestions, *s)
+	}
+
+	return suggestions, rows.Err()
+}
+
+func (r *metadataSuggestionRepository) Accept(ctx context.Context, id, reviewedBy string) (*model.MetadataSuggestion, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	s, err := r.markReviewed(ctx, tx, id, model.SuggestionStatusAccepted, reviewedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	update, ok := bookColumnUpdates[s.Field]
+	if !ok {
+		return nil, model.ErrInvalidSuggestionValue
+	}
+
+	tag, err := tx.Exec(ctx, update, s.BookID, s.SuggestedValue)
+	if err != nil {
+		return nil, fmt.Errorf("apply metadata suggestion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, model.ErrBookNotFound
+	}
+
+	// Field đã có giá trị được chọn -> các gợi ý pending khác cùng field không còn ý nghĩa
+	_, err = tx.Exec(ctx, `
+		UPDATE book_metadata_suggestions
+		SET status = 'rejected', reviewed_by = $3, reviewed_at = NOW()
+		WHERE book_id = $1 AND field = $2 AND status = 'pending'
+	`, s.BookID, s.Field, reviewedBy)
+	if err != nil {
+		return nil, fmt.Errorf("reject sibling suggestions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return s, nil
+}
+
+func (r *metadataSuggestionRepository) Reject(ctx context.Context, id, reviewedBy string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	if _, err := r.markReviewed(ctx, tx, id, model.SuggestionStatusRejected, reviewedBy); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// markReviewed chuyển suggestion pending sang accepted/rejected
+// status = 'pending' guard tránh 2 admin review cùng lúc
+func (r *metadataSuggestionRepository) markReviewed(ctx context.Context, tx pgx.Tx, id, status, reviewedBy string) (*model.MetadataSuggestion, error) {
+	query := `
+		UPDATE book_metadata_suggestions
+		SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + metadataSuggestionColumns
+
+	s, err := scanMetadataSuggestion(tx.QueryRow(ctx, query, id, status, reviewedBy))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Phân biệt không tồn tại vs đã review
+			var exists bool
+			if checkErr := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM book_metadata_suggestions WHERE id = $1)", id).Scan(&exists); checkErr == nil && !exists {
+				return nil, model.ErrSuggestionNotFound
+			}
+			return nil, model.ErrSuggestionNotPending
+		}
+		return nil, fmt.Errorf("review metadata suggestion: %w", err)
+	}
+	return s, nil
+}
+
+func scanMetadataSuggestion(row pgx.Row) (*model.MetadataSuggestion, error) {
+	var s model.MetadataSuggestion
+	err := row.Scan(
+		&s.ID,
+		&s.BookID,
+		&s.Field,
+		&s.SuggestedValue,
+		&s.CurrentValue,
+		&s.Source,
+		&s.SourceURL,
+		&s.SourceISBN,
+		&s.FetchedAt,
+		&s.Status,
+		&s.ReviewedBy,
+		&s.ReviewedAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
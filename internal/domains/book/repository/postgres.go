@@ -2,6 +2,7 @@ package repository
 
 import (
 	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/shared/pagination"
 	"bookstore-backend/pkg/cache"
 	"context"
 	"encoding/json"
@@ -32,15 +33,33 @@ func NewPostgresRepository(pool *pgxpool.Pool, cache cache.Cache) RepositoryInte
 
 // ========================= SEARCH BOOK =====================
 // SearchBooks - Full-text search using PostgreSQL tsvector + GIN index
-func (r *postgresRepository) SearchBooks(ctx context.Context, req model.SearchBooksRequest) ([]model.BookSearchResponse, error) {
+// Match: prefix tsquery (từ cuối gõ dở vẫn ra) trên title/author/description đã bỏ dấu,
+// fallback trigram word_similarity trên title / tên tác giả / alias tác giả khi gõ sai chính tả
+// Rank: ts_rank_cd + similarity (match đúng token luôn xếp trên match gần đúng)
+func (r *postgresRepository) SearchBooks(ctx context.Context, req model.SearchBooksRequest) ([]model.BookSearchResponse, int, error) {
+	// "mat bie" → "mat:* & bie:*"
+	terms := req.SearchTerms()
+	prefixTerms := make([]string, len(terms))
+	for i, term := range terms {
+		prefixTerms[i] = term + ":*"
+	}
+
+	args := []interface{}{strings.Join(prefixTerms, " & "), strings.Join(terms, " ")}
+	argIndex := 3
+
 	// Build WHERE clause
 	whereConditions := []string{
 		"b.deleted_at IS NULL",
+		"b.archived_at IS NULL",
 		"b.is_active = true",
-		"b.search_vector @@ websearch_to_tsquery('simple', $1)",
+		`(b.search_vector @@ q.tsq
+			OR q.plain <% immutable_unaccent(lower(b.title))
+			OR q.plain <% immutable_unaccent(lower(a.name))
+			OR EXISTS (
+				SELECT 1 FROM author_aliases aa
+				WHERE aa.author_id = b.author_id AND q.plain <% aa.normalized_name
+			))`,
 	}
-	args := []interface{}{req.Query}
-	argIndex := 2
 
 	// Filter by language if specified
 	if req.Language != "" {
@@ -49,16 +68,39 @@ func (r *postgresRepository) SearchBooks(ctx context.Context, req model.SearchBo
 		argIndex++
 	}
 
-	whereClause := ""
-	for i, cond := range whereConditions {
-		if i > 0 {
-			whereClause += " AND "
-		}
-		whereClause += cond
+	// Danh mục + danh mục con trực tiếp
+	if req.CategoryID != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf(
+			"b.category_id IN (SELECT id FROM categories WHERE id = $%d OR parent_id = $%d)", argIndex, argIndex))
+		args = append(args, req.CategoryID)
+		argIndex++
+	}
+
+	if req.PriceMin > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf("b.price >= $%d", argIndex))
+		args = append(args, req.PriceMin)
+		argIndex++
+	}
+
+	if req.PriceMax > 0 {
+		whereConditions = append(whereConditions, fmt.Sprintf("b.price <= $%d", argIndex))
+		args = append(args, req.PriceMax)
+		argIndex++
 	}
 
-	// Build main query with ts_rank_cd for relevance scoring
+	if req.InStock {
+		whereConditions = append(whereConditions, "COALESCE(bts.available, 0) > 0")
+	}
+
+	whereClause := strings.Join(whereConditions, " AND ")
+
+	// Build main query; COUNT(*) OVER() lấy tổng số kết quả trong cùng 1 lần query
 	query := fmt.Sprintf(`
+		WITH q AS (
+			SELECT
+				to_tsquery('simple', immutable_unaccent($1)) AS tsq,
+				immutable_unaccent($2) AS plain
+		)
 		SELECT 
 			b.id,
 			b.title,
@@ -67,25 +109,32 @@ func (r *postgresRepository) SearchBooks(ctx context.Context, req model.SearchBo
 			b.cover_url,
 			b.language,
 			a.name AS author_name,
-			ts_rank_cd(b.search_vector, websearch_to_tsquery('simple', $1), 32) AS rank
+			ts_rank_cd(b.search_vector, q.tsq, 32)
+				+ 0.5 * word_similarity(q.plain, immutable_unaccent(lower(b.title)))
+				+ 0.3 * word_similarity(q.plain, immutable_unaccent(lower(a.name))) AS rank,
+			COALESCE(bts.available, 0) AS total_stock,
+			COUNT(*) OVER() AS total_count
 		FROM books b
+		CROSS JOIN q
 		LEFT JOIN authors a ON b.author_id = a.id
+		LEFT JOIN books_total_stock bts ON b.id = bts.book_id
 		WHERE %s
-		ORDER BY rank DESC, b.view_count DESC
-		LIMIT $%d
-	`, whereClause, argIndex)
+		ORDER BY rank DESC, b.view_count DESC, b.id
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argIndex, argIndex+1)
 
-	args = append(args, req.Limit)
+	args = append(args, req.Limit, (req.Page-1)*req.Limit)
 
 	// Execute query
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		log.Printf("[Repository] Search query error: %v", err)
-		return nil, fmt.Errorf("search query failed: %w", err)
+		return nil, 0, fmt.Errorf("search query failed: %w", err)
 	}
 	defer rows.Close()
 
 	// Scan results
+	total := 0
 	results := make([]model.BookSearchResponse, 0, req.Limit)
 	for rows.Next() {
 		var result model.BookSearchResponse
@@ -98,6 +147,8 @@ func (r *postgresRepository) SearchBooks(ctx context.Context, req model.SearchBo
 			&result.Language,
 			&result.AuthorName,
 			&result.Rank,
+			&result.TotalStock,
+			&total,
 		)
 		if err != nil {
 			log.Printf("[Repository] Scan error: %v", err)
@@ -107,10 +158,10 @@ func (r *postgresRepository) SearchBooks(ctx context.Context, req model.SearchBo
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+		return nil, 0, fmt.Errorf("rows error: %w", err)
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 // ============================================
@@ -128,8 +179,8 @@ func (r *postgresRepository) ListBooks(ctx context.Context, filter *model.BookFi
 		return nil, 0, err
 	}
 
-	// Build main query with JOINs
-	query := r.buildListBooksQuery(whereClause, 1)
+	// Build main query with JOINs (LIMIT/OFFSET nối sau tham số của WHERE)
+	query := r.buildListBooksQuery(whereClause, len(args)+1)
 	// Append pagination args
 	args = append(args, filter.Limit, filter.Offset)
 
@@ -142,6 +193,22 @@ func (r *postgresRepository) ListBooks(ctx context.Context, filter *model.BookFi
 	return books, totalCount, nil
 }
 
+// ListBooksAfterCursor - Keyset: sách cũ hơn cursor (created_at, id), không COUNT
+// after nil = trang đầu; service gọi với limit+1 để biết còn trang sau
+func (r *postgresRepository) ListBooksAfterCursor(ctx context.Context, filter *model.BookFilter, after *pagination.Cursor, limit int) ([]model.Book, error) {
+	whereClause, args := r.buildWhereClause(filter)
+	if after != nil {
+		condition, cursorArgs := after.Condition([]string{"b.created_at", "b.id"}, len(args)+1)
+		whereClause += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	query := r.buildListBooksQuery(whereClause, len(args)+1)
+	args = append(args, limit, 0)
+
+	return r.executeListQuery(ctx, query, args)
+}
+
 // GetBookByIDForUpdate - Get book với SELECT FOR UPDATE (lock row)
 func (r *postgresRepository) GetBookByIDForUpdate(ctx context.Context, id string) (*model.Book, error) {
 	query := `
@@ -198,6 +265,7 @@ func (r *postgresRepository) CheckISBNExistsExcept(ctx context.Context, isbn, ex
 func (r *postgresRepository) buildWhereClause(filter *model.BookFilter) (string, []interface{}) {
 	conditions := []string{
 		"b.deleted_at IS NULL",
+		"b.archived_at IS NULL",
 		"b.is_active = true",
 	}
 	args := []interface{}{}
@@ -270,7 +338,7 @@ func (r *postgresRepository) buildListBooksQuery(whereClause string, paramCount
 		LEFT JOIN publishers p ON b.publisher_id = p.id
 		LEFT JOIN books_total_stock bts ON b.id = bts.book_id
 		WHERE %s
-		ORDER BY b.created_at DESC
+		ORDER BY b.created_at DESC, b.id DESC
 		LIMIT $%d OFFSET $%d
 	`, whereClause, paramCount, paramCount+1)
 }
@@ -328,6 +396,7 @@ func (r *postgresRepository) GetBookByID(ctx context.Context, id string) (*model
         b.version, 
         COALESCE(b.images, ARRAY[]::text[]) AS images,
         b.created_at, b.updated_at, b.deleted_at,
+        b.archived_at, b.replacement_book_id,
         a.name AS author_name, a.slug AS author_slug, a.bio AS author_bio,
         c.name AS category_name, c.slug AS category_slug,
         p.name AS publisher_name, p.slug AS publisher_slug, p.website AS publisher_website,
@@ -407,6 +476,8 @@ func (r *postgresRepository) GetBookByID(ctx context.Context, id string) (*model
 		&book.CreatedAt,
 		&book.UpdatedAt,
 		&book.DeletedAt,
+		&book.ArchivedAt,
+		&book.ReplacementBookID,
 
 		// Author fields (3 cột)
 		&book.AuthorName,
@@ -612,6 +683,18 @@ func (r *postgresRepository) GetBaseBookByID(ctx context.Context, id string) (*m
 	return &book, nil
 }
 
+func (r *postgresRepository) FindMergedInto(ctx context.Context, bookID string) (uuid.UUID, bool, error) {
+	var targetID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT book_id FROM book_redirects WHERE old_book_id = $1`, bookID).Scan(&targetID)
+	if err == pgx.ErrNoRows {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to find book redirect: %w", err)
+	}
+	return targetID, true, nil
+}
+
 func (r *postgresRepository) GenerateUniqueSlug(ctx context.Context, baseSlug string) (string, error) {
 	slug := baseSlug
 	counter := 1
@@ -637,16 +720,22 @@ func (r *postgresRepository) GenerateUniqueSlug(ctx context.Context, baseSlug st
 	}
 }
 
-func (r *postgresRepository) SoftDeleteBook(ctx context.Context, bookID string, deletedAt time.Time) error {
+// ArchiveBook ngừng bán: archived_at + is_active = false (khôi phục bằng RestoreBook)
+// Lưu trữ lại sách đã archived chỉ cập nhật sách thay thế, giữ archived_at cũ
+func (r *postgresRepository) ArchiveBook(ctx context.Context, bookID string, archivedBy, replacementID *uuid.UUID, archivedAt time.Time) error {
 	query := `
 		UPDATE books
-		SET deleted_at = $1, updated_at = $1
-		WHERE id = $2 AND deleted_at IS NULL
+		SET archived_at = COALESCE(archived_at, $1),
+			archived_by = $2,
+			replacement_book_id = $3,
+			is_active = false,
+			updated_at = $1
+		WHERE id = $4 AND deleted_at IS NULL
 	`
 
-	result, err := r.pool.Exec(ctx, query, deletedAt, bookID)
+	result, err := r.pool.Exec(ctx, query, archivedAt, archivedBy, replacementID, bookID)
 	if err != nil {
-		return fmt.Errorf("failed to soft delete book: %w", err)
+		return fmt.Errorf("failed to archive book: %w", err)
 	}
 
 	rowsAffected := result.RowsAffected()
@@ -657,6 +746,72 @@ func (r *postgresRepository) SoftDeleteBook(ctx context.Context, bookID string,
 	return nil
 }
 
+// RestoreBook mở bán lại sách đã lưu trữ
+func (r *postgresRepository) RestoreBook(ctx context.Context, bookID string) error {
+	query := `
+		UPDATE books
+		SET archived_at = NULL,
+			archived_by = NULL,
+			replacement_book_id = NULL,
+			is_active = true,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND archived_at IS NOT NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, bookID)
+	if err != nil {
+		return fmt.Errorf("failed to restore book: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrBookNotArchived
+	}
+	return nil
+}
+
+// IsReplacementCandidate sách thay thế phải đang bán (không xoá, không lưu trữ)
+func (r *postgresRepository) IsReplacementCandidate(ctx context.Context, bookID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(
+		SELECT 1 FROM books
+		WHERE id = $1 AND deleted_at IS NULL AND archived_at IS NULL AND is_active = true
+	)`
+
+	var ok bool
+	if err := r.pool.QueryRow(ctx, query, bookID).Scan(&ok); err != nil {
+		return false, fmt.Errorf("failed to check replacement book: %w", err)
+	}
+	return ok, nil
+}
+
+// FindReplacement sách thay thế cho sách ngừng bán:
+// replacement_book_id admin chọn trước, sau đó sách đã gộp vào (book_redirects); nil nếu không có
+func (r *postgresRepository) FindReplacement(ctx context.Context, bookID uuid.UUID) (*model.ReplacementSuggestion, error) {
+	query := `
+		SELECT t.id, t.title, t.slug, t.price, src.reason
+		FROM (
+			SELECT replacement_book_id AS target_id, 'replacement' AS reason, 0 AS priority
+			FROM books WHERE id = $1 AND replacement_book_id IS NOT NULL
+			UNION ALL
+			SELECT book_id, 'merged', 1 FROM book_redirects WHERE old_book_id = $1
+		) src
+		JOIN books t ON t.id = src.target_id
+			AND t.deleted_at IS NULL AND t.archived_at IS NULL AND t.is_active = true
+		ORDER BY src.priority
+		LIMIT 1
+	`
+
+	var suggestion model.ReplacementSuggestion
+	err := r.pool.QueryRow(ctx, query, bookID).Scan(
+		&suggestion.BookID, &suggestion.Title, &suggestion.Slug, &suggestion.Price, &suggestion.Reason,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find replacement book: %w", err)
+	}
+	return &suggestion, nil
+}
+
 // CheckBookHasActiveOrders - UNCHANGED (no inventory reference)
 func (r *postgresRepository) CheckBookHasActiveOrders(ctx context.Context, bookID string) (bool, error) {
 	query := `
@@ -831,9 +986,12 @@ func (p *postgresRepository) GetBooksCheckout(ctx context.Context, ids []string)
 		SELECT 
 			b.id, b.title, b.price,
 			b.cover_url, b.description,
+			COALESCE(b.is_active, false) AS is_active,
 			a.name AS author_name,
+			b.category_id,
 			c.name AS category_name,
-			p.name AS publisher_name
+			p.name AS publisher_name,
+			b.archived_at, b.replacement_book_id
 		FROM books b
 		LEFT JOIN authors a ON b.author_id = a.id
 		LEFT JOIN categories c ON b.category_id = c.id
@@ -856,9 +1014,13 @@ func (p *postgresRepository) GetBooksCheckout(ctx context.Context, ids []string)
 			&book.Price,
 			&book.CoverURL,
 			&book.Description,
+			&book.IsActive,
 			&book.AuthorName,
+			&book.CategoryID,
 			&book.CategoryName,
 			&book.PublisherName,
+			&book.ArchivedAt,
+			&book.ReplacementBookID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
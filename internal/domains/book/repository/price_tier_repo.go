@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PriceTierRepository interface {
+	// BookExists kiểm tra sách tồn tại (chưa xoá mềm) trước khi cấu hình bậc giá
+	BookExists(ctx context.Context, bookID uuid.UUID) (bool, error)
+	ListByBook(ctx context.Context, bookID uuid.UUID) (model.PriceTiers, error)
+	// ListByBooks trả về map book_id → bậc giá (sách không có bậc không có trong map)
+	ListByBooks(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]model.PriceTiers, error)
+	// Replace xoá bậc cũ và ghi bậc mới của sách (1 transaction)
+	Replace(ctx context.Context, bookID uuid.UUID, tiers []model.PriceTierInput, updatedBy uuid.UUID) error
+}
+
+type priceTierRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPriceTierRepository(pool *pgxpool.Pool) PriceTierRepository {
+	return &priceTierRepository{pool: pool}
+}
+
+const priceTierColumns = `id, book_id, min_quantity, discount_percent, updated_by, created_at`
+
+func (r *priceTierRepository) BookExists(ctx context.Context, bookID uuid.UUID) (bool, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM books WHERE id = $1 AND deleted_at IS NULL)`,
+		bookID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check book exists: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *priceTierRepository) ListByBook(ctx context.Context, bookID uuid.UUID) (model.PriceTiers, error) {
+	tiers, err := r.ListByBooks(ctx, []uuid.UUID{bookID})
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := tiers[bookID]; ok {
+		return t, nil
+	}
+	return model.PriceTiers{}, nil
+}
+
+func (r *priceTierRepository) ListByBooks(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]model.PriceTiers, error) {
+	result := make(map[uuid.UUID]model.PriceTiers)
+	if len(bookIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + priceTierColumns + `
+		FROM book_price_tiers
+		WHERE book_id = ANY($1)
+		ORDER BY book_id, min_quantity
+	`
+
+	rows, err := r.pool.Query(ctx, query, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list price tiers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t model.PriceTier
+		if err := rows.Scan(
+			&t.ID,
+			&t.BookID,
+			&t.MinQuantity,
+			&t.DiscountPercent,
+			&t.UpdatedBy,
+			&t.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan price tier: %w", err)
+		}
+		result[t.BookID] = append(result[t.BookID], t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate price tiers: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *priceTierRepository) Replace(ctx context.Context, bookID uuid.UUID, tiers []model.PriceTierInput, updatedBy uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	return database.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM book_price_tiers WHERE book_id = $1`, bookID); err != nil {
+			return fmt.Errorf("delete price tiers: %w", err)
+		}
+
+		for _, t := range tiers {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO book_price_tiers (book_id, min_quantity, discount_percent, updated_by)
+				VALUES ($1, $2, $3, $4)
+			`, bookID, t.MinQuantity, t.DiscountPercent, updatedBy)
+			if err != nil {
+				return fmt.Errorf("insert price tier: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type PricingRepository interface {
+	// GetCurrentPrice giá + giá gạch hiện tại của sách (chưa xoá mềm)
+	// Returns ErrBookNotFound if not exists
+	GetCurrentPrice(ctx context.Context, bookID uuid.UUID) (decimal.Decimal, *decimal.Decimal, error)
+	// ListHistory các lần đổi giá từ since, mới nhất trước
+	ListHistory(ctx context.Context, bookID uuid.UUID, since time.Time) ([]model.PriceHistoryEntry, error)
+	// LowestPriceSince giá bán thấp nhất có hiệu lực từ since tới nay
+	// (giá đang áp dụng lúc since + mọi giá đặt sau đó + giá hiện tại)
+	LowestPriceSince(ctx context.Context, bookID uuid.UUID, since time.Time) (decimal.Decimal, error)
+
+	CreateSchedule(ctx context.Context, schedule *model.PriceSchedule) error
+	// ListSchedules lịch của sách, effective_at mới nhất trước (status rỗng = tất cả)
+	ListSchedules(ctx context.Context, bookID uuid.UUID, status string) ([]model.PriceSchedule, error)
+	// CancelSchedule huỷ lịch pending của sách
+	// Returns ErrPriceScheduleNotFound, ErrPriceScheduleNotPending
+	CancelSchedule(ctx context.Context, bookID, scheduleID, cancelledBy uuid.UUID) (*model.PriceSchedule, error)
+	// ApplyNextDueSchedule áp 1 lịch pending đến hạn (effective_at <= now, cũ nhất trước) trong 1 transaction.
+	// SKIP LOCKED: nhiều worker không áp trùng. nil = không còn lịch đến hạn
+	ApplyNextDueSchedule(ctx context.Context, now time.Time) (*model.AppliedPriceSchedule, error)
+}
+
+type pricingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPricingRepository(pool *pgxpool.Pool) PricingRepository {
+	return &pricingRepository{pool: pool}
+}
+
+const priceScheduleColumns = `
+	id, book_id, price, compare_at_price, set_compare_at_price, effective_at, status, note,
+	failure_reason, created_by, cancelled_by, applied_at, cancelled_at, created_at, updated_at
+`
+
+func scanPriceSchedule(row pgx.Row) (*model.PriceSchedule, error) {
+	var s model.PriceSchedule
+	err := row.Scan(
+		&s.ID, &s.BookID, &s.Price, &s.CompareAtPrice, &s.SetCompareAtPrice, &s.EffectiveAt, &s.Status, &s.Note,
+		&s.FailureReason, &s.CreatedBy, &s.CancelledBy, &s.AppliedAt, &s.CancelledAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *pricingRepository) GetCurrentPrice(ctx context.Context, bookID uuid.UUID) (decimal.Decimal, *decimal.Decimal, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var price decimal.Decimal
+	var compareAt *decimal.Decimal
+	err := r.pool.QueryRow(ctx,
+		`SELECT price, compare_at_price FROM books WHERE id = $1 AND deleted_at IS NULL`,
+		bookID,
+	).Scan(&price, &compareAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return decimal.Zero, nil, model.ErrBookNotFound
+	}
+	if err != nil {
+		return decimal.Zero, nil, fmt.Errorf("get current price: %w", err)
+	}
+	return price, compareAt, nil
+}
+
+func (r *pricingRepository) ListHistory(ctx context.Context, bookID uuid.UUID, since time.Time) ([]model.PriceHistoryEntry, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, book_id, old_price, new_price, old_compare_at_price, new_compare_at_price,
+			source, schedule_id, changed_at
+		FROM price_history
+		WHERE book_id = $1 AND changed_at >= $2
+		ORDER BY changed_at DESC
+	`, bookID, since)
+	if err != nil {
+		return nil, fmt.Errorf("list price history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]model.PriceHistoryEntry, 0)
+	for rows.Next() {
+		var h model.PriceHistoryEntry
+		if err := rows.Scan(
+			&h.ID, &h.BookID, &h.OldPrice, &h.NewPrice, &h.OldCompareAtPrice, &h.NewCompareAtPrice,
+			&h.Source, &h.ScheduleID, &h.ChangedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan price history: %w", err)
+		}
+		history = append(history, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate price history: %w", err)
+	}
+
+	return history, nil
+}
+
+func (r *pricingRepository) LowestPriceSince(ctx context.Context, bookID uuid.UUID, since time.Time) (decimal.Decimal, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var lowest decimal.Decimal
+	err := r.pool.QueryRow(ctx, `
+		SELECT MIN(p) FROM (
+			SELECT new_price AS p FROM price_history WHERE book_id = $1 AND changed_at >= $2
+			UNION ALL
+			(SELECT new_price FROM price_history WHERE book_id = $1 AND changed_at < $2
+			 ORDER BY changed_at DESC LIMIT 1)
+			UNION ALL
+			SELECT price FROM books WHERE id = $1
+		) prices
+	`, bookID, since).Scan(&lowest)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("get lowest price: %w", err)
+	}
+	return lowest, nil
+}
+
+func (r *pricingRepository) CreateSchedule(ctx context.Context, schedule *model.PriceSchedule) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO price_schedules (
+			id, book_id, price, compare_at_price, set_compare_at_price, effective_at, status, note, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at
+	`,
+		schedule.ID, schedule.BookID, schedule.Price, schedule.CompareAtPrice, schedule.SetCompareAtPrice,
+		schedule.EffectiveAt, schedule.Status, schedule.Note, schedule.CreatedBy,
+	).Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create price schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *pricingRepository) ListSchedules(ctx context.Context, bookID uuid.UUID, status string) ([]model.PriceSchedule, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+priceScheduleColumns+`
+		FROM price_schedules
+		WHERE book_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY effective_at DESC
+	`, bookID, status)
+	if err != nil {
+		return nil, fmt.Errorf("list price schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]model.PriceSchedule, 0)
+	for rows.Next() {
+		s, err := scanPriceSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan price schedule: %w", err)
+		}
+		schedules = append(schedules, *s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate price schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (r *pricingRepository) CancelSchedule(ctx context.Context, bookID, scheduleID, cancelledBy uuid.UUID) (*model.PriceSchedule, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	schedule, err := scanPriceSchedule(r.pool.QueryRow(ctx, `
+		UPDATE price_schedules
+		SET status = 'cancelled', cancelled_by = $3, cancelled_at = NOW()
+		WHERE id = $1 AND book_id = $2 AND status = 'pending'
+		RETURNING `+priceScheduleColumns,
+		scheduleID, bookID, cancelledBy,
+	))
+	if err == nil {
+		return schedule, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("cancel price schedule: %w", err)
+	}
+
+	// Không cập nhật được: không tồn tại hay đã áp / huỷ
+	var exists bool
+	if err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM price_schedules WHERE id = $1 AND book_id = $2)`,
+		scheduleID, bookID,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check price schedule: %w", err)
+	}
+	if !exists {
+		return nil, model.ErrPriceScheduleNotFound
+	}
+	return nil, model.ErrPriceScheduleNotPending
+}
+
+func (r *pricingRepository) ApplyNextDueSchedule(ctx context.Context, now time.Time) (*model.AppliedPriceSchedule, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	return database.WithTransactionResult(ctx, r.pool, func(tx pgx.Tx) (*model.AppliedPriceSchedule, error) {
+		schedule, err := scanPriceSchedule(tx.QueryRow(ctx, `
+			SELECT `+priceScheduleColumns+`
+			FROM price_schedules
+			WHERE status = 'pending' AND effective_at <= $1
+			ORDER BY effective_at, created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		`, now))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("claim price schedule: %w", err)
+		}
+
+		result := &model.AppliedPriceSchedule{ScheduleID: schedule.ID, BookID: schedule.BookID}
+
+		var price decimal.Decimal
+		var compareAt *decimal.Decimal
+		err = tx.QueryRow(ctx,
+			`SELECT price, compare_at_price FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+			schedule.BookID,
+		).Scan(&price, &compareAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			result.Reason = "book not found"
+			return result, markScheduleFailed(ctx, tx, schedule.ID, result.Reason)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("lock book: %w", err)
+		}
+
+		newPrice, newCompareAt := price, compareAt
+		if schedule.Price != nil {
+			newPrice = *schedule.Price
+		}
+		if schedule.SetCompareAtPrice {
+			newCompareAt = schedule.CompareAtPrice
+		}
+
+		// Giá gạch đang có có thể thấp hơn giá mới (admin đổi giá sau khi tạo lịch) → không áp nửa vời
+		if newCompareAt != nil && newCompareAt.LessThan(newPrice) {
+			result.Reason = fmt.Sprintf("compare_at_price %s is below price %s", newCompareAt.String(), newPrice.String())
+			return result, markScheduleFailed(ctx, tx, schedule.ID, result.Reason)
+		}
+
+		result.PriceChanged = !newPrice.Equal(price)
+		if result.PriceChanged || !decimalPtrEqual(newCompareAt, compareAt) {
+			// Trigger log_book_price_change() đọc nguồn đổi giá từ setting của transaction
+			if _, err := tx.Exec(ctx,
+				`SELECT set_config('app.price_change_source', $1, true), set_config('app.price_schedule_id', $2, true)`,
+				model.PriceSourceScheduled, schedule.ID.String(),
+			); err != nil {
+				return nil, fmt.Errorf("set price change source: %w", err)
+			}
+
+			if _, err := tx.Exec(ctx, `
+				UPDATE books
+				SET price = $2, compare_at_price = $3, version = version + 1, updated_at = NOW()
+				WHERE id = $1
+			`, schedule.BookID, newPrice, newCompareAt); err != nil {
+				return nil, fmt.Errorf("apply scheduled price: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE price_schedules SET status = 'applied', applied_at = NOW() WHERE id = $1`,
+			schedule.ID,
+		); err != nil {
+			return nil, fmt.Errorf("mark price schedule applied: %w", err)
+		}
+
+		result.Applied = true
+		return result, nil
+	})
+}
+
+func markScheduleFailed(ctx context.Context, tx pgx.Tx, scheduleID uuid.UUID, reason string) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE price_schedules SET status = 'failed', failure_reason = $2 WHERE id = $1`,
+		scheduleID, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("mark price schedule failed: %w", err)
+	}
+	return nil
+}
+
+func decimalPtrEqual(a, b *decimal.Decimal) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
@@ -3,15 +3,19 @@ package service
 import (
 	model "bookstore-backend/internal/domains/book/model"
 	"bookstore-backend/internal/domains/book/repository"
+	"bookstore-backend/internal/infrastructure/breaker"
 	"bookstore-backend/internal/infrastructure/storage"
 	types "bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/pagination"
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/cache"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	_ "image/jpeg" // Registers JPEG decoder
@@ -22,7 +26,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -31,27 +37,33 @@ type BookService struct {
 	repo           repository.RepositoryInterface
 	imageRepo      repository.BookImageRepository
 	cache          cache.Cache
+	loader         *cache.Loader // get-or-load chống stampede cho key nóng
 	imageProcessor *storage.ImageProcessor
 	minio          *storage.MinIOStorage
 	asynqClient    *asynq.Client
+	// Storefront breaker: DB lỗi liên tiếp → cache miss trả 503 ngay thay vì chờ timeout
+	breaker *breaker.Breaker
 }
 
 // NewService - Constructor with DI
 func NewService(
 	repo repository.RepositoryInterface,
-	cache cache.Cache,
+	redisCache cache.Cache,
 	imageProcessor *storage.ImageProcessor,
 	minio *storage.MinIOStorage,
 	imageRepo repository.BookImageRepository,
 	asynqClient *asynq.Client,
+	storefrontBreaker *breaker.Breaker,
 ) ServiceInterface {
 	return &BookService{
 		repo:           repo,
-		cache:          cache,
+		cache:          redisCache,
+		loader:         cache.NewLoader(redisCache),
 		imageProcessor: imageProcessor,
 		minio:          minio,
 		imageRepo:      imageRepo,
 		asynqClient:    asynqClient,
+		breaker:        storefrontBreaker,
 	}
 }
 
@@ -61,27 +73,43 @@ func (s *BookService) ListBooks(ctx context.Context, req model.ListBooksRequest)
 	if err := model.ValidateListRequest(req); err != nil {
 		return nil, nil, err
 	}
-	// 1) Định nghĩa type rõ ràng (dễ debug hơn)
-	type BooksCache struct {
-		Data       []model.ListBooksResponse `json:"data"`
-		Pagination model.PaginationMeta      `json:"pagination"`
+	cursorMode := pagination.IsCursorMode(req.Pagination, req.Cursor)
+	var after *pagination.Cursor
+	if req.Cursor != "" {
+		var err error
+		if after, err = pagination.Decode(req.Cursor, 1); err != nil {
+			return nil, nil, err
+		}
 	}
-	var result BooksCache
-
 	// Generate cache key from request parameters
+	// Loader: key hết hạn dưới tải (trang chủ, danh sách bán chạy sort=popular) chỉ 1 request query DB,
+	// key nóng được refresh nền trước khi hết hạn
 	cacheKey := model.GenerateCacheKey("books:list", req)
-	found, err := s.cache.Get(ctx, cacheKey, &result)
-	// Try to get from cache first
+	var result booksListCache
+	err := s.loader.GetOrLoad(ctx, cacheKey, &result, 24*time.Hour, func(ctx context.Context) (interface{}, error) {
+		log.Printf("Cache MISS for key: %s", cacheKey)
+		return s.listBooksFromDB(ctx, req, cursorMode, after)
+	})
 	if err != nil {
+		if errors.Is(err, errEmptyBookList) {
+			return []model.ListBooksResponse{}, &model.PaginationMeta{}, nil
+		}
 		return nil, nil, err
 	}
-	if found {
-		return result.Data, &result.Pagination, nil
-	}
 
-	// Cache MISS - query database
-	log.Printf("Cache MISS for key: %s", cacheKey)
+	return result.Data, &result.Pagination, nil
+}
+
+// booksListCache giá trị cache của 1 trang danh sách sách
+type booksListCache struct {
+	Data       []model.ListBooksResponse `json:"data"`
+	Pagination model.PaginationMeta      `json:"pagination"`
+}
+
+// errEmptyBookList lỗi DB khi chưa có kết quả: trả danh sách rỗng, không cache
+var errEmptyBookList = errors.New("empty book list")
 
+func (s *BookService) listBooksFromDB(ctx context.Context, req model.ListBooksRequest, cursorMode bool, after *pagination.Cursor) (*booksListCache, error) {
 	// Build filter for repository
 	filter := &model.BookFilter{
 		Search:     req.Search,
@@ -94,13 +122,47 @@ func (s *BookService) ListBooks(ctx context.Context, req model.ListBooksRequest)
 		Limit:      req.Limit,
 	}
 
-	// Query database
-	books, totalCount, err := s.repo.ListBooks(ctx, filter)
+	// Query database (qua breaker: đang mở thì trả ErrOpen ngay)
+	var books []model.Book
+	var totalCount int
+	err := s.breaker.Do(func() error {
+		var err error
+		if cursorMode {
+			// limit+1: sách dư chỉ để biết còn trang sau
+			books, err = s.repo.ListBooksAfterCursor(ctx, filter, after, req.Limit+1)
+			return err
+		}
+		books, totalCount, err = s.repo.ListBooks(ctx, filter)
+		return err
+	})
 	if err != nil {
+		if errors.Is(err, breaker.ErrOpen) || breaker.IsUnavailable(err) {
+			return nil, err
+		}
 		if totalCount == 0 {
-			return []model.ListBooksResponse{}, &model.PaginationMeta{}, nil
+			return nil, errEmptyBookList
+		}
+		return nil, fmt.Errorf("list books error: %w", err)
+	}
+
+	// Calculate pagination metadata
+	var meta *model.PaginationMeta
+	if cursorMode {
+		meta = &model.PaginationMeta{PageSize: req.Limit}
+		if len(books) > req.Limit {
+			books = books[:req.Limit]
+			last := books[len(books)-1]
+			meta.HasMore = true
+			meta.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, IDs: []uuid.UUID{last.ID}}.Encode()
+		}
+	} else {
+		totalPages := (totalCount + req.Limit - 1) / req.Limit
+		meta = &model.PaginationMeta{
+			Page:      req.Page,
+			PageSize:  req.Limit,
+			Total:     totalCount,
+			TotalPage: totalPages,
 		}
-		return nil, nil, fmt.Errorf("list books error: %w", err)
 	}
 
 	// Map entities to DTOs
@@ -109,31 +171,28 @@ func (s *BookService) ListBooks(ctx context.Context, req model.ListBooksRequest)
 		responses[i] = model.BookToListDTO(book)
 	}
 
-	// Calculate pagination metadata
-	totalPages := (totalCount + req.Limit - 1) / req.Limit
-	meta := &model.PaginationMeta{
-		Page:      req.Page,
-		PageSize:  req.Limit,
-		Total:     totalCount,
-		TotalPage: totalPages,
-	}
-
-	// Cache the result
-	cacheData := BooksCache{
+	return &booksListCache{
 		Data:       responses,
 		Pagination: *meta,
-	}
-
-	if err := s.cache.Set(ctx, cacheKey, cacheData, 24*time.Hour); err != nil {
-		log.Printf("Cache SET error for key %s: %v", cacheKey, err)
-	}
-
-	return responses, meta, nil
+	}, nil
 }
+
 func (s *BookService) GetBookDetail(ctx context.Context, id string) (*model.BookDetailResponse, error) {
 	// Lấy dữ liệu chi tiết sách
-	b, inventories, err := s.repo.GetBookByID(ctx, id)
+	var b *model.BookDetailRes
+	var inventories []model.InventoryDetailDTO
+	err := s.breaker.Do(func() error {
+		var err error
+		b, inventories, err = s.repo.GetBookByID(ctx, id)
+		return err
+	})
 
+	if errors.Is(err, model.ErrBookNotFound) {
+		// Sách đã bị gộp → handler trả 301 sang sách giữ lại
+		if targetID, ok, rerr := s.repo.FindMergedInto(ctx, id); rerr == nil && ok {
+			return nil, &model.BookMovedError{TargetID: targetID}
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -225,12 +284,12 @@ func (s *BookService) CreateBook(ctx context.Context, req model.CreateBookReques
 		// 4. Enqueue job xử lý (resize/upload các variant)
 		p := map[string]string{"image_id": imgRec.ID}
 		payload, _ := json.Marshal(p)
-		job := asynq.NewTask(types.TypeProcessBookImage, payload)
+		job := tracing.NewTask(ctx, types.TypeProcessBookImage, payload)
 		s.asynqClient.Enqueue(job, asynq.Queue(types.QueueBook), asynq.MaxRetry(2))
 	}
 
 	// 7. Invalidate list cache (xóa cache danh sách sách)
-	if err := s.cache.Delete(ctx, "books:list:*"); err != nil {
+	if err := s.cache.DeletePattern(ctx, "books:list:*"); err != nil {
 		log.Printf("[Service] Failed to invalidate list cache: %v", err)
 	}
 
@@ -371,20 +430,42 @@ func (s *BookService) UpdateBook(ctx context.Context, id string, req model.Updat
 	if err := s.cache.Delete(ctx, cacheKey); err != nil {
 		log.Printf("[Service] Failed to delete cache: %v", err)
 	}
-	if err := s.cache.Delete(ctx, "books:list:*"); err != nil {
+	if err := s.cache.DeletePattern(ctx, "books:list:*"); err != nil {
 		log.Printf("[Service] Failed to invalidate list cache: %v", err)
 	}
 
-	// 9. Return updated detail
+	// 9. Đổi giá → job inventory sync đẩy giá mới lên các sàn đang đồng bộ
+	if req.Price != nil && !existing.Price.Equal(decimal.NewFromFloat(*req.Price)) {
+		payload, _ := json.Marshal(types.InventorySyncPayload{BookID: id, Source: "PRICE_CHANGE"})
+		task := tracing.NewTask(ctx, types.TypeInventorySyncBookStock, payload)
+		if _, err := s.asynqClient.Enqueue(task, asynq.Queue(types.QueueInventory)); err != nil {
+			log.Printf("[Service] Failed to enqueue inventory sync after price change: %v", err)
+		}
+	}
+
+	// 10. Return updated detail
 	return s.GetBookDetail(ctx, id)
 }
 
 // DELETE
-func (s *BookService) DeleteBook(c context.Context, bookID string) (*model.DeleteBookResponse, error) {
+// DeleteBook lưu trữ sách (ngừng bán): vẫn đọc được cho cart / đơn (ITEM_DISCONTINUED), RestoreBook mở bán lại
+func (s *BookService) DeleteBook(c context.Context, bookID string, archivedBy *uuid.UUID, req model.ArchiveBookRequest) (*model.DeleteBookResponse, error) {
 	book, err := s.repo.GetBaseBookByID(c, bookID)
 	if err != nil {
 		return nil, err
 	}
+	if req.ReplacementBookID != nil {
+		if req.ReplacementBookID.String() == bookID {
+			return nil, model.ErrInvalidReplacement
+		}
+		ok, err := s.repo.IsReplacementCandidate(c, *req.ReplacementBookID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, model.ErrInvalidReplacement
+		}
+	}
 	hasActiveOrders, err := s.repo.CheckBookHasActiveOrders(c, bookID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check active orders: %w", err)
@@ -401,9 +482,12 @@ func (s *BookService) DeleteBook(c context.Context, bookID string) (*model.Delet
 		return nil, model.ErrBookHasReservedInventory
 	}
 
-	// 4. Perform soft delete
+	// 4. Archive (soft delete)
 	deletedAt := time.Now()
-	if err := s.repo.SoftDeleteBook(c, bookID, deletedAt); err != nil {
+	if err := s.repo.ArchiveBook(c, bookID, archivedBy, req.ReplacementBookID, deletedAt); err != nil {
+		if errors.Is(err, model.ErrBookNotFound) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to delete book: %w", err)
 	}
 
@@ -420,23 +504,66 @@ func (s *BookService) DeleteBook(c context.Context, bookID string) (*model.Delet
 
 	// 7. Return deleted book info
 	return &model.DeleteBookResponse{
-		ID:        bookID,
-		Title:     book.Title,
-		DeletedAt: deletedAt,
+		ID:                bookID,
+		Title:             book.Title,
+		DeletedAt:         deletedAt,
+		ReplacementBookID: req.ReplacementBookID,
 	}, nil
 }
 
+// RestoreBook mở bán lại sách đã lưu trữ
+func (s *BookService) RestoreBook(ctx context.Context, bookID string) (*model.RestoreBookResponse, error) {
+	book, err := s.repo.GetBaseBookByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RestoreBook(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Delete(ctx, model.GenerateBookDetailCacheKey(bookID)); err != nil {
+		log.Printf("[Service] Failed to delete cache: %v", err)
+	}
+	if err := s.cache.DeletePattern(ctx, "books:list:*"); err != nil {
+		log.Printf("[Service] Failed to invalidate list cache: %v", err)
+	}
+
+	return &model.RestoreBookResponse{
+		ID:         bookID,
+		Title:      book.Title,
+		RestoredAt: time.Now(),
+	}, nil
+}
+
+// SuggestReplacement hook gợi ý sách thay thế cho sách ngừng bán (cart / reorder báo ITEM_DISCONTINUED)
+// Hiện tại: sách admin chọn khi lưu trữ, sau đó sách đã gộp vào; nil nếu không có gợi ý
+func (s *BookService) SuggestReplacement(ctx context.Context, bookID uuid.UUID) (*model.ReplacementSuggestion, error) {
+	return s.repo.FindReplacement(ctx, bookID)
+}
+
 // ====================== SEARCH BOOK SERVICE ==============================
-func (s *BookService) SearchBooks(ctx context.Context, req model.SearchBooksRequest) ([]model.BookSearchResponse, error) {
+func (s *BookService) SearchBooks(ctx context.Context, req model.SearchBooksRequest) (*model.SearchBooksResult, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 10
+	}
+
+	// Query toàn ký tự đặc biệt → không có token để tìm
+	if len(req.SearchTerms()) == 0 {
+		return &model.SearchBooksResult{Books: []model.BookSearchResponse{}}, nil
+	}
+
 	// 1. Generate cache key
 	cacheKey := generateSearchCacheKey(req)
 
 	// 2. Try to get from cache
-	var cachedResults []model.BookSearchResponse
-	found, err := s.cache.Get(ctx, cacheKey, &cachedResults)
+	var cachedResult model.SearchBooksResult
+	found, err := s.cache.Get(ctx, cacheKey, &cachedResult)
 	if found {
 		log.Printf("[Service] Search cache HIT: %s", cacheKey)
-		return cachedResults, nil
+		return &cachedResult, nil
 	}
 	if err != nil {
 		log.Printf("[Service] Search cache error: %v", err)
@@ -444,24 +571,33 @@ func (s *BookService) SearchBooks(ctx context.Context, req model.SearchBooksRequ
 
 	// 3. Cache MISS - query database
 	log.Printf("[Service] Search cache MISS: %s", cacheKey)
-	results, err := s.repo.SearchBooks(ctx, req)
+	var books []model.BookSearchResponse
+	var total int
+	err = s.breaker.Do(func() error {
+		var err error
+		books, total, err = s.repo.SearchBooks(ctx, req)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search books: %w", err)
 	}
+	result := &model.SearchBooksResult{Books: books, Total: total}
 
 	// 4. Cache the results (TTL 1 hour = 3600 seconds)
-	if err := s.cache.Set(ctx, cacheKey, results, 60*time.Minute); err != nil {
+	if err := s.cache.Set(ctx, cacheKey, result, 60*time.Minute); err != nil {
 		log.Printf("[Service] Failed to cache search results: %v", err)
 		// Don't fail request if cache write fails
 	}
 
-	return results, nil
+	return result, nil
 }
 
 // generateSearchCacheKey - Create consistent cache key for search params
 func generateSearchCacheKey(req model.SearchBooksRequest) string {
 	// Create hash from query params
-	data := fmt.Sprintf("q=%s|lang=%s|limit=%d", req.Query, req.Language, req.Limit)
+	data := fmt.Sprintf("q=%s|lang=%s|cat=%s|min=%v|max=%v|stock=%t|page=%d|limit=%d",
+		strings.ToLower(strings.TrimSpace(req.Query)), req.Language, req.CategoryID,
+		req.PriceMin, req.PriceMax, req.InStock, req.Page, req.Limit)
 	hash := md5.Sum([]byte(data))
 	return fmt.Sprintf("books:search:%x", hash)
 }
@@ -26,6 +26,7 @@ import (
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/database"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
@@ -1027,7 +1028,7 @@ func (s *bulkImportService) processBookImages(
 		// Enqueue job xử lý variant (defer processing)
 		payload := map[string]string{"image_id": imageRecord.ID}
 		payloadBytes, _ := json.Marshal(payload)
-		task := asynq.NewTask(shared.TypeProcessBookImage, payloadBytes)
+		task := tracing.NewTask(ctx, shared.TypeProcessBookImage, payloadBytes)
 
 		_, err = s.asynqClient.Enqueue(task, asynq.Queue(shared.QueueBook), asynq.MaxRetry(2))
 		if err != nil {
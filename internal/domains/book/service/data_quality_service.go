@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/domains/book/repository"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"fmt"
+)
+
+type DataQualityService interface {
+	// ScanBooks quét lại vấn đề dữ liệu của toàn bộ sách (được gọi từ Worker mỗi đêm)
+	ScanBooks(ctx context.Context) (*model.DataQualityScanResult, error)
+	// GetReport báo cáo cho catalog manager: tổng theo vấn đề + sách kèm link sửa
+	GetReport(ctx context.Context, req model.DataQualityReportRequest) (*model.DataQualityReportResponse, error)
+}
+
+type dataQualityService struct {
+	repo repository.DataQualityRepository
+}
+
+func NewDataQualityService(repo repository.DataQualityRepository) DataQualityService {
+	return &dataQualityService{repo: repo}
+}
+
+func (s *dataQualityService) ScanBooks(ctx context.Context) (*model.DataQualityScanResult, error) {
+	result, err := s.repo.ScanIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{"resolved": result.Resolved}
+	for _, issue := range model.DataQualityIssues {
+		fields[issue] = result.Counts[issue]
+	}
+	logger.Info("Book data quality scan completed", fields)
+
+	return result, nil
+}
+
+func (s *dataQualityService) GetReport(ctx context.Context, req model.DataQualityReportRequest) (*model.DataQualityReportResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	summary, lastScanned, err := s.repo.CountIssues(ctx, req.ActiveOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	books, total, err := s.repo.ListBooksWithIssues(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for i := range books {
+		books[i].FixLinks = dataQualityFixLinks(&books[i])
+	}
+
+	return &model.DataQualityReportResponse{
+		Summary:       summary,
+		LastScannedAt: lastScanned,
+		Books:         books,
+		Pagination: model.PaginationMeta{
+			Page:      req.Page,
+			PageSize:  req.Limit,
+			Total:     total,
+			TotalPage: (total + req.Limit - 1) / req.Limit,
+		},
+	}, nil
+}
+
+// dataQualityFixLinks endpoint sửa từng vấn đề của sách
+// - Thiếu cover / mô tả: lấy gợi ý từ Google Books/OpenLibrary (có ISBN) trước, hoặc sửa tay
+// - Không có tồn: tạo dòng tồn ở kho
+func dataQualityFixLinks(item *model.BookDataQualityItem) []model.DataQualityFixLink {
+	bookPath := fmt.Sprintf("/api/v1/books/%s", item.BookID)
+	enrichPath := fmt.Sprintf("/api/v1/admin/books/%s/enrich", item.BookID)
+
+	links := make([]model.DataQualityFixLink, 0, len(item.Issues)+1)
+	for _, issue := range item.Issues {
+		switch issue {
+		case model.DataQualityMissingCover:
+			if item.ISBN != nil {
+				links = append(links, model.DataQualityFixLink{Issue: issue, Label: "Fetch cover suggestion", Method: "POST", Href: enrichPath})
+			}
+			links = append(links, model.DataQualityFixLink{Issue: issue, Label: "Set cover", Method: "PUT", Href: bookPath})
+		case model.DataQualityMissingDescription:
+			if item.ISBN != nil {
+				links = append(links, model.DataQualityFixLink{Issue: issue, Label: "Fetch description suggestion", Method: "POST", Href: enrichPath})
+			}
+			links = append(links, model.DataQualityFixLink{Issue: issue, Label: "Write description", Method: "PUT", Href: bookPath})
+		case model.DataQualityMissingCategory:
+			links = append(links, model.DataQualityFixLink{Issue: issue, Label: "Assign category", Method: "PUT", Href: bookPath})
+		case model.DataQualityNoInventory:
+			links = append(links, model.DataQualityFixLink{Issue: issue, Label: "Create inventory", Method: "POST", Href: "/api/v1/inventories"})
+		}
+	}
+	return links
+}
@@ -4,6 +4,7 @@ import (
 	"bookstore-backend/internal/domains/book/model"
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -13,9 +14,11 @@ type ServiceInterface interface {
 	GetBookDetail(ctx context.Context, id string) (*model.BookDetailResponse, error)
 	CreateBook(ctx context.Context, req model.CreateBookRequest) error
 	UpdateBook(ctx context.Context, id string, req model.UpdateBookRequest) (*model.BookDetailResponse, error)
-	DeleteBook(ctx context.Context, id string) (*model.DeleteBookResponse, error)
+	DeleteBook(ctx context.Context, id string, archivedBy *uuid.UUID, req model.ArchiveBookRequest) (*model.DeleteBookResponse, error)
+	RestoreBook(ctx context.Context, id string) (*model.RestoreBookResponse, error)
+	SuggestReplacement(ctx context.Context, bookID uuid.UUID) (*model.ReplacementSuggestion, error)
 	ExportBooksToExcel(ctx context.Context, req model.ListBooksRequest) (*excelize.File, *[]model.ListBooksResponse, error)
-	SearchBooks(ctx context.Context, req model.SearchBooksRequest) ([]model.BookSearchResponse, error)
+	SearchBooks(ctx context.Context, req model.SearchBooksRequest) (*model.SearchBooksResult, error)
 	GetBooksByIDs(ctx context.Context, ids []string) ([]model.BookDetailResponse, error)
 	GetBooksCheckout(ctx context.Context, ids []string) ([]model.BookCheckoutResponse, error)
 }
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/domains/book/repository"
+	types "bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+type MergeService interface {
+	// ListDuplicates cặp sách nghi trùng cho admin xem xét
+	ListDuplicates(ctx context.Context, req model.ListDuplicatesRequest) ([]model.DuplicateCandidate, error)
+	// DismissDuplicate admin xác nhận cặp không trùng
+	DismissDuplicate(ctx context.Context, adminID uuid.UUID, req model.DismissDuplicateRequest) error
+	// MergeBooks gộp req.SourceBookID vào targetID (sách giữ lại)
+	MergeBooks(ctx context.Context, targetID, adminID uuid.UUID, req model.MergeBooksRequest) (*model.BookMergeResult, error)
+	// ResolveSlug slug (kể cả slug cũ của sách đã gộp) → sách đang hiển thị (public)
+	ResolveSlug(ctx context.Context, slug string) (*model.ResolveSlugResponse, error)
+}
+
+type mergeService struct {
+	repo        repository.MergeRepository
+	cache       cache.Cache
+	asynqClient *asynq.Client
+}
+
+func NewMergeService(
+	repo repository.MergeRepository,
+	cache cache.Cache,
+	asynqClient *asynq.Client,
+) MergeService {
+	return &mergeService{
+		repo:        repo,
+		cache:       cache,
+		asynqClient: asynqClient,
+	}
+}
+
+func (s *mergeService) ListDuplicates(ctx context.Context, req model.ListDuplicatesRequest) ([]model.DuplicateCandidate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.FindDuplicates(ctx, req)
+}
+
+func (s *mergeService) DismissDuplicate(ctx context.Context, adminID uuid.UUID, req model.DismissDuplicateRequest) error {
+	if req.BookIDs[0] == uuid.Nil || req.BookIDs[1] == uuid.Nil || req.BookIDs[0] == req.BookIDs[1] {
+		return fmt.Errorf("%w: book_ids must be 2 different books", model.ErrInvalidDuplicateRequest)
+	}
+	return s.repo.DismissDuplicate(ctx, req.BookIDs[0], req.BookIDs[1], adminID)
+}
+
+func (s *mergeService) MergeBooks(
+	ctx context.Context,
+	targetID, adminID uuid.UUID,
+	req model.MergeBooksRequest,
+) (*model.BookMergeResult, error) {
+	if req.SourceBookID == targetID {
+		return nil, fmt.Errorf("%w: cannot merge a book into itself", model.ErrInvalidMerge)
+	}
+
+	result, err := s.repo.MergeBooks(ctx, req.SourceBookID, targetID, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Books merged", map[string]interface{}{
+		"merge_id":               result.ID.String(),
+		"source_book_id":         result.SourceBookID.String(),
+		"target_book_id":         result.TargetBookID.String(),
+		"admin_id":               adminID.String(),
+		"cart_items_moved":       result.CartItemsMoved + result.CartItemsCombined,
+		"order_items_moved":      result.OrderItemsMoved,
+		"reviews_moved":          result.ReviewsMoved,
+		"inventory_rows_moved":   result.InventoryRowsMoved + result.InventoryRowsCombined,
+		"reviews_left_on_source": result.ReviewsSkipped,
+	})
+
+	s.afterMerge(ctx, result)
+	return result, nil
+}
+
+// afterMerge xoá cache 2 sách + đồng bộ lại tồn (sách bị gộp về 0, sách giữ lại cộng thêm)
+func (s *mergeService) afterMerge(ctx context.Context, result *model.BookMergeResult) {
+	for _, bookID := range []uuid.UUID{result.SourceBookID, result.TargetBookID} {
+		if err := s.cache.Delete(ctx, model.GenerateBookDetailCacheKey(bookID.String())); err != nil {
+			log.Printf("[Merge] Failed to delete cache: %v", err)
+		}
+	}
+	if err := s.cache.DeletePattern(ctx, "books:list:*"); err != nil {
+		log.Printf("[Merge] Failed to invalidate list cache: %v", err)
+	}
+
+	if result.InventoryRowsMoved+result.InventoryRowsCombined == 0 {
+		return
+	}
+	for _, bookID := range []uuid.UUID{result.SourceBookID, result.TargetBookID} {
+		payload, _ := json.Marshal(types.InventorySyncPayload{BookID: bookID.String(), Source: "BOOK_MERGE"})
+		task := tracing.NewTask(ctx, types.TypeInventorySyncBookStock, payload)
+		if _, err := s.asynqClient.Enqueue(task, asynq.Queue(types.QueueInventory)); err != nil {
+			log.Printf("[Merge] Failed to enqueue inventory sync after merge: %v", err)
+		}
+	}
+}
+
+func (s *mergeService) ResolveSlug(ctx context.Context, slug string) (*model.ResolveSlugResponse, error) {
+	return s.repo.ResolveSlug(ctx, slug)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/book/metadata"
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/domains/book/repository"
+	types "bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hibiken/asynq"
+)
+
+type MetadataEnrichmentService interface {
+	// RequestEnrichment enqueue job enrich metadata theo ISBN của book (admin)
+	RequestEnrichment(ctx context.Context, bookID string) (*model.EnrichBookResponse, error)
+	// EnrichBook gọi các provider và lưu suggestion (được gọi từ Worker)
+	EnrichBook(ctx context.Context, bookID string) error
+	ListSuggestions(ctx context.Context, bookID, status string) ([]model.MetadataSuggestion, error)
+	AcceptSuggestion(ctx context.Context, suggestionID, reviewerID string) (*model.MetadataSuggestion, error)
+	RejectSuggestion(ctx context.Context, suggestionID, reviewerID string) error
+}
+
+type metadataEnrichmentService struct {
+	repo        repository.MetadataSuggestionRepository
+	providers   []metadata.Provider
+	cache       cache.Cache
+	asynqClient *asynq.Client
+}
+
+func NewMetadataEnrichmentService(
+	repo repository.MetadataSuggestionRepository,
+	providers []metadata.Provider,
+	cache cache.Cache,
+	asynqClient *asynq.Client,
+) MetadataEnrichmentService {
+	return &metadataEnrichmentService{
+		repo:        repo,
+		providers:   providers,
+		cache:       cache,
+		asynqClient: asynqClient,
+	}
+}
+
+func (s *metadataEnrichmentService) RequestEnrichment(ctx context.Context, bookID string) (*model.EnrichBookResponse, error) {
+	snap, err := s.repo.GetBookMetadataSnapshot(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if snap.ISBN == nil || metadata.NormalizeISBN(*snap.ISBN) == "" {
+		return nil, model.ErrBookHasNoISBN
+	}
+
+	payload, _ := json.Marshal(map[string]string{"book_id": bookID})
+	task := tracing.NewTask(ctx, types.TypeEnrichBookMetadata, payload)
+	if _, err := s.asynqClient.Enqueue(task, asynq.Queue(types.QueueBook), asynq.MaxRetry(3)); err != nil {
+		return nil, fmt.Errorf("enqueue enrichment job: %w", err)
+	}
+
+	return &model.EnrichBookResponse{
+		BookID: bookID,
+		ISBN:   *snap.ISBN,
+		Status: "queued",
+	}, nil
+}
+
+func (s *metadataEnrichmentService) EnrichBook(ctx context.Context, bookID string) error {
+	snap, err := s.repo.GetBookMetadataSnapshot(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if snap.ISBN == nil {
+		return model.ErrBookHasNoISBN
+	}
+	isbn := metadata.NormalizeISBN(*snap.ISBN)
+
+	var (
+		saved     int
+		failed    int
+		lastError error
+	)
+	for _, provider := range s.providers {
+		data, err := provider.FetchByISBN(ctx, isbn)
+		if err != nil {
+			if errors.Is(err, metadata.ErrNotFound) {
+				continue
+			}
+			// 1 provider lỗi không chặn provider còn lại
+			failed++
+			lastError = err
+			logger.Info("Metadata provider failed", map[string]interface{}{
+				"book_id": bookID,
+				"source":  provider.Name(),
+				"error":   err.Error(),
+			})
+			continue
+		}
+
+		for _, suggestion := range buildSuggestions(snap, isbn, data) {
+			if err := s.repo.UpsertPending(ctx, &suggestion); err != nil {
+				return err
+			}
+			saved++
+		}
+	}
+
+	// Tất cả provider đều lỗi -> trả lỗi để asynq retry
+	if failed > 0 && failed == len(s.providers) {
+		return fmt.Errorf("all metadata providers failed: %w", lastError)
+	}
+
+	logger.Info("Book metadata enrichment completed", map[string]interface{}{
+		"book_id":     bookID,
+		"isbn":        isbn,
+		"suggestions": saved,
+	})
+	return nil
+}
+
+func (s *metadataEnrichmentService) ListSuggestions(ctx context.Context, bookID, status string) ([]model.MetadataSuggestion, error) {
+	return s.repo.ListByBook(ctx, bookID, status)
+}
+
+func (s *metadataEnrichmentService) AcceptSuggestion(ctx context.Context, suggestionID, reviewerID string) (*model.MetadataSuggestion, error) {
+	suggestion, err := s.repo.GetByID(ctx, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != model.SuggestionStatusPending {
+		return nil, model.ErrSuggestionNotPending
+	}
+
+	// books.pages có CHECK (pages > 0)
+	if suggestion.Field == model.MetadataFieldPages {
+		if pages, err := strconv.Atoi(suggestion.SuggestedValue); err != nil || pages <= 0 {
+			return nil, model.ErrInvalidSuggestionValue
+		}
+	}
+
+	accepted, err := s.repo.Accept(ctx, suggestionID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Delete(ctx, model.GenerateBookDetailCacheKey(accepted.BookID)); err != nil {
+		log.Printf("[MetadataEnrichment] Failed to delete cache: %v", err)
+	}
+	if err := s.cache.DeletePattern(ctx, "books:list:*"); err != nil {
+		log.Printf("[MetadataEnrichment] Failed to invalidate list cache: %v", err)
+	}
+
+	return accepted, nil
+}
+
+func (s *metadataEnrichmentService) RejectSuggestion(ctx context.Context, suggestionID, reviewerID string) error {
+	return s.repo.Reject(ctx, suggestionID, reviewerID)
+}
+
+// buildSuggestions chỉ tạo suggestion cho field provider có giá trị và khác giá trị hiện tại
+func buildSuggestions(snap *model.BookMetadataSnapshot, isbn string, data *metadata.BookMetadata) []model.MetadataSuggestion {
+	var sourceURL *string
+	if data.SourceURL != "" {
+		sourceURL = &data.SourceURL
+	}
+
+	newSuggestion := func(field, value string, current *string) model.MetadataSuggestion {
+		return model.MetadataSuggestion{
+			BookID:         snap.BookID,
+			Field:          field,
+			SuggestedValue: value,
+			CurrentValue:   current,
+			Source:         data.Source,
+			SourceURL:      sourceURL,
+			SourceISBN:     isbn,
+		}
+	}
+
+	var suggestions []model.MetadataSuggestion
+
+	if data.CoverURL != nil && !equalStringPtr(snap.CoverURL, *data.CoverURL) {
+		suggestions = append(suggestions, newSuggestion(model.MetadataFieldCoverURL, *data.CoverURL, snap.CoverURL))
+	}
+
+	if data.Pages != nil && (snap.Pages == nil || *snap.Pages != *data.Pages) {
+		var current *string
+		if snap.Pages != nil {
+			v := strconv.Itoa(*snap.Pages)
+			current = &v
+		}
+		suggestions = append(suggestions, newSuggestion(model.MetadataFieldPages, strconv.Itoa(*data.Pages), current))
+	}
+
+	if data.Description != nil && !equalStringPtr(snap.Description, *data.Description) {
+		suggestions = append(suggestions, newSuggestion(model.MetadataFieldDescription, *data.Description, snap.Description))
+	}
+
+	return suggestions
+}
+
+func equalStringPtr(current *string, value string) bool {
+	return current != nil && *current == value
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/domains/book/repository"
+	"bookstore-backend/pkg/logger"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type PriceTierService interface {
+	ListTiers(ctx context.Context, bookID uuid.UUID) (model.PriceTiers, error)
+	// ReplaceTiers thay toàn bộ bậc giá của sách (admin); cart item cũ được cập nhật giá khi khách sửa cart
+	ReplaceTiers(ctx context.Context, bookID, adminID uuid.UUID, req model.ReplacePriceTiersRequest) (model.PriceTiers, error)
+	// GetTiersForBooks dùng cho cart / order để tính đơn giá theo số lượng
+	GetTiersForBooks(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]model.PriceTiers, error)
+}
+
+type priceTierService struct {
+	repo repository.PriceTierRepository
+}
+
+func NewPriceTierService(repo repository.PriceTierRepository) PriceTierService {
+	return &priceTierService{repo: repo}
+}
+
+func (s *priceTierService) ListTiers(ctx context.Context, bookID uuid.UUID) (model.PriceTiers, error) {
+	if err := s.ensureBook(ctx, bookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByBook(ctx, bookID)
+}
+
+func (s *priceTierService) ReplaceTiers(
+	ctx context.Context,
+	bookID, adminID uuid.UUID,
+	req model.ReplacePriceTiersRequest,
+) (model.PriceTiers, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.ensureBook(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Replace(ctx, bookID, req.Tiers, adminID); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Book price tiers updated", map[string]interface{}{
+		"book_id":  bookID.String(),
+		"admin_id": adminID.String(),
+		"tiers":    len(req.Tiers),
+	})
+
+	return s.repo.ListByBook(ctx, bookID)
+}
+
+func (s *priceTierService) GetTiersForBooks(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]model.PriceTiers, error) {
+	return s.repo.ListByBooks(ctx, bookIDs)
+}
+
+func (s *priceTierService) ensureBook(ctx context.Context, bookID uuid.UUID) error {
+	exists, err := s.repo.BookExists(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return model.ErrBookNotFound
+	}
+	return nil
+}
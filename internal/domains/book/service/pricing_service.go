@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/domains/book/repository"
+	types "bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+)
+
+type PricingService interface {
+	// GetPriceHistory lịch sử giá N ngày gần nhất + giá thấp nhất 30 ngày (public, storefront)
+	GetPriceHistory(ctx context.Context, bookID uuid.UUID, days int) (*model.PriceHistoryResponse, error)
+	// CreateSchedule hẹn giờ đổi price / compare_at_price (admin)
+	CreateSchedule(ctx context.Context, bookID, adminID uuid.UUID, req model.CreatePriceScheduleRequest) (*model.PriceSchedule, error)
+	ListSchedules(ctx context.Context, bookID uuid.UUID, req model.ListPriceSchedulesRequest) ([]model.PriceSchedule, error)
+	// CancelSchedule huỷ lịch chưa áp
+	CancelSchedule(ctx context.Context, bookID, scheduleID, adminID uuid.UUID) (*model.PriceSchedule, error)
+	// ApplyDueSchedules áp các lịch đến hạn (được gọi từ Worker mỗi phút)
+	ApplyDueSchedules(ctx context.Context) (*model.ApplyPriceSchedulesResult, error)
+}
+
+type pricingService struct {
+	repo        repository.PricingRepository
+	cache       cache.Cache
+	asynqClient *asynq.Client
+}
+
+func NewPricingService(
+	repo repository.PricingRepository,
+	cache cache.Cache,
+	asynqClient *asynq.Client,
+) PricingService {
+	return &pricingService{
+		repo:        repo,
+		cache:       cache,
+		asynqClient: asynqClient,
+	}
+}
+
+func (s *pricingService) GetPriceHistory(ctx context.Context, bookID uuid.UUID, days int) (*model.PriceHistoryResponse, error) {
+	if days == 0 {
+		days = model.PriceHistoryDefaultDays
+	}
+	if days < 1 || days > model.PriceHistoryMaxDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", model.ErrInvalidPriceHistoryRange, model.PriceHistoryMaxDays)
+	}
+
+	price, compareAt, err := s.repo.GetCurrentPrice(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	history, err := s.repo.ListHistory(ctx, bookID, now.AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	lowest, err := s.repo.LowestPriceSince(ctx, bookID, now.AddDate(0, 0, -model.LowestPriceWindowDays))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PriceHistoryResponse{
+		BookID:         bookID,
+		CurrentPrice:   price,
+		CompareAtPrice: compareAt,
+		LowestPrice30d: lowest,
+		Days:           days,
+		History:        history,
+	}, nil
+}
+
+func (s *pricingService) CreateSchedule(
+	ctx context.Context,
+	bookID, adminID uuid.UUID,
+	req model.CreatePriceScheduleRequest,
+) (*model.PriceSchedule, error) {
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+	// Sách phải còn tồn tại lúc hẹn giờ (lúc áp kiểm tra lại, xoá sau đó → lịch failed)
+	if _, _, err := s.repo.GetCurrentPrice(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	schedule := req.ToSchedule(bookID, adminID)
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Book price change scheduled", map[string]interface{}{
+		"book_id":      bookID.String(),
+		"schedule_id":  schedule.ID.String(),
+		"admin_id":     adminID.String(),
+		"effective_at": schedule.EffectiveAt,
+	})
+
+	return schedule, nil
+}
+
+func (s *pricingService) ListSchedules(ctx context.Context, bookID uuid.UUID, req model.ListPriceSchedulesRequest) ([]model.PriceSchedule, error) {
+	return s.repo.ListSchedules(ctx, bookID, req.Status)
+}
+
+func (s *pricingService) CancelSchedule(ctx context.Context, bookID, scheduleID, adminID uuid.UUID) (*model.PriceSchedule, error) {
+	schedule, err := s.repo.CancelSchedule(ctx, bookID, scheduleID, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Book price schedule cancelled", map[string]interface{}{
+		"book_id":     bookID.String(),
+		"schedule_id": scheduleID.String(),
+		"admin_id":    adminID.String(),
+	})
+
+	return schedule, nil
+}
+
+// ApplyDueSchedules áp tối đa PriceScheduleApplyBatch lịch / lần, mỗi lịch 1 transaction.
+// Lịch không áp được (sách đã xoá, giá gạch < giá bán) đánh dấu failed, không chặn lịch sau
+func (s *pricingService) ApplyDueSchedules(ctx context.Context) (*model.ApplyPriceSchedulesResult, error) {
+	result := &model.ApplyPriceSchedulesResult{}
+	now := time.Now()
+
+	for i := 0; i < model.PriceScheduleApplyBatch; i++ {
+		applied, err := s.repo.ApplyNextDueSchedule(ctx, now)
+		if err != nil {
+			return result, err
+		}
+		if applied == nil {
+			break
+		}
+
+		if !applied.Applied {
+			result.Failed++
+			logger.Info("Book price schedule failed", map[string]interface{}{
+				"book_id":     applied.BookID.String(),
+				"schedule_id": applied.ScheduleID.String(),
+				"reason":      applied.Reason,
+			})
+			continue
+		}
+		result.Applied++
+
+		s.afterPriceChange(ctx, applied)
+	}
+
+	return result, nil
+}
+
+// afterPriceChange giống UpdateBook: xoá cache sách + đẩy giá mới lên các sàn đang đồng bộ
+func (s *pricingService) afterPriceChange(ctx context.Context, applied *model.AppliedPriceSchedule) {
+	bookID := applied.BookID.String()
+	if err := s.cache.Delete(ctx, model.GenerateBookDetailCacheKey(bookID)); err != nil {
+		log.Printf("[Pricing] Failed to delete cache: %v", err)
+	}
+	if err := s.cache.DeletePattern(ctx, "books:list:*"); err != nil {
+		log.Printf("[Pricing] Failed to invalidate list cache: %v", err)
+	}
+
+	if !applied.PriceChanged {
+		return
+	}
+	payload, _ := json.Marshal(types.InventorySyncPayload{BookID: bookID, Source: "PRICE_CHANGE"})
+	task := tracing.NewTask(ctx, types.TypeInventorySyncBookStock, payload)
+	if _, err := s.asynqClient.Enqueue(task, asynq.Queue(types.QueueInventory)); err != nil {
+		log.Printf("[Pricing] Failed to enqueue inventory sync after price change: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package cart
+
+import (
+	"errors"
+	"net/http"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ===================================
+// ADMIN CART: /admin/customers/:user_id/cart
+// Nhân viên (admin / cskh) dựng giỏ + đặt đơn thay khách gọi điện tới
+// ===================================
+
+// AdminGetCustomerCart handles GET /admin/customers/:user_id/cart
+func (h *Handler) AdminGetCustomerCart(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.service.GetCustomerCart(c.Request.Context(), customerID)
+	if err != nil {
+		handleAdminCartError(c, err, "Failed to get customer cart")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Customer cart retrieved successfully", cart)
+}
+
+// AdminAddItem handles POST /admin/customers/:user_id/cart/items
+func (h *Handler) AdminAddItem(c *gin.Context) {
+	cartID, ok := h.resolveCustomerCart(c)
+	if !ok {
+		return
+	}
+
+	var req model.AddToCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	item, err := h.service.AddItem(c.Request.Context(), cartID, req)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to add item", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Item added to customer cart", item)
+}
+
+// AdminUpdateItemQuantity handles PUT /admin/customers/:user_id/cart/items/:item_id
+func (h *Handler) AdminUpdateItemQuantity(c *gin.Context) {
+	cartID, ok := h.resolveCustomerCart(c)
+	if !ok {
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	var req model.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	item, err := h.service.UpdateItemQuantity(c.Request.Context(), cartID, itemID, req.Quantity)
+	if err != nil {
+		handleAdminCartError(c, err, "Failed to update item")
+		return
+	}
+	if item == nil {
+		response.Success(c, http.StatusOK, "Item removed from customer cart", nil)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Item quantity updated", item)
+}
+
+// AdminRemoveItem handles DELETE /admin/customers/:user_id/cart/items/:item_id
+func (h *Handler) AdminRemoveItem(c *gin.Context) {
+	cartID, ok := h.resolveCustomerCart(c)
+	if !ok {
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	if err := h.service.RemoveItem(c.Request.Context(), cartID, itemID); err != nil {
+		handleAdminCartError(c, err, "Failed to remove item")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AdminApplyPromoCode handles POST /admin/customers/:user_id/cart/apply-promotion
+// override = true chỉ dành cho role admin (CSKH áp promo theo điều kiện như khách)
+func (h *Handler) AdminApplyPromoCode(c *gin.Context) {
+	staffID, ok := getStaffID(c)
+	if !ok {
+		return
+	}
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var req model.AdminApplyPromoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+	if req.Override {
+		if role, _ := c.Get("role"); role != "admin" {
+			response.Error(c, http.StatusForbidden, "Access denied", "Promo override requires admin role")
+			return
+		}
+	}
+
+	cartID, err := h.service.GetCustomerCartID(c.Request.Context(), customerID)
+	if err != nil {
+		handleAdminCartError(c, err, "Failed to get customer cart")
+		return
+	}
+
+	result, err := h.service.ApplyPromoCodeForCustomer(c.Request.Context(), cartID, customerID, staffID, req.PromoCode, req.Override)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid promo code", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Promo applied successfully", result)
+}
+
+// AdminRemovePromoCode handles DELETE /admin/customers/:user_id/cart/remove-promotion
+func (h *Handler) AdminRemovePromoCode(c *gin.Context) {
+	cartID, ok := h.resolveCustomerCart(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemovePromoCode(c.Request.Context(), cartID); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to remove promo", err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AdminCheckout handles POST /admin/customers/:user_id/cart/checkout
+// payment_method = pay_link: đơn chờ thanh toán, khách nhận link qua email (payment_info.redirect_url)
+func (h *Handler) AdminCheckout(c *gin.Context) {
+	staffID, ok := getStaffID(c)
+	if !ok {
+		return
+	}
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var req model.AssistedCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	result, err := h.service.CheckoutForCustomer(c.Request.Context(), staffID, customerID, req)
+	if err != nil {
+		handleAdminCartError(c, err, "Checkout failed")
+		return
+	}
+
+	statusCode := http.StatusCreated
+	if !result.Success {
+		statusCode = http.StatusUnprocessableEntity
+	}
+
+	response.Success(c, statusCode, "Checkout completed", result)
+}
+
+// resolveCustomerCart cart ID của khách trong path (tạo nếu chưa có), lỗi đã được response
+func (h *Handler) resolveCustomerCart(c *gin.Context) (uuid.UUID, bool) {
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	cartID, err := h.service.GetCustomerCartID(c.Request.Context(), customerID)
+	if err != nil {
+		handleAdminCartError(c, err, "Failed to get customer cart")
+		return uuid.Nil, false
+	}
+	return cartID, true
+}
+
+func parseCustomerID(c *gin.Context) (uuid.UUID, bool) {
+	customerID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return uuid.Nil, false
+	}
+	return customerID, true
+}
+
+func getStaffID(c *gin.Context) (uuid.UUID, bool) {
+	userIDValue, exists := c.Get(middleware.ContextKeyUserID)
+	staffID, ok := userIDValue.(uuid.UUID)
+	if !exists || !ok {
+		response.Error(c, http.StatusUnauthorized, "Not authenticated", "User ID required")
+		return uuid.Nil, false
+	}
+	return staffID, true
+}
+
+func handleAdminCartError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrCustomerNotFound):
+		response.Error(c, http.StatusNotFound, "Customer not found", err.Error())
+	case errors.Is(err, model.ErrCartNotFound):
+		response.Error(c, http.StatusNotFound, "Cart not found", err.Error())
+	case errors.Is(err, model.ErrInvalidQuantity):
+		response.Error(c, http.StatusBadRequest, "Invalid quantity", err.Error())
+	case errors.Is(err, model.ErrCartItemNotFound):
+		response.Error(c, http.StatusNotFound, "Item not found", err.Error())
+	case errors.Is(err, model.ErrGiftItemNotEditable):
+		response.Error(c, http.StatusUnprocessableEntity, "Gift item cannot be changed", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
@@ -7,7 +7,9 @@ import (
 
 	"bookstore-backend/internal/domains/cart/model"
 	"bookstore-backend/internal/domains/cart/service"
+	orderModel "bookstore-backend/internal/domains/order/model"
 	promotionService "bookstore-backend/internal/domains/promotion/service"
+	"bookstore-backend/internal/infrastructure/breaker"
 	"bookstore-backend/internal/shared/middleware"
 	cartMiddleware "bookstore-backend/internal/shared/middleware"
 	"bookstore-backend/internal/shared/response"
@@ -69,6 +71,11 @@ func (h *Handler) GetCart(c *gin.Context) {
 	// Get or create cart
 	cart, err := h.service.GetOrCreateCart(c.Request.Context(), uid, sid)
 	if err != nil {
+		var quotaErr *model.AnonymousCartQuotaError
+		if errors.As(err, &quotaErr) {
+			response.TooManyRequests(c, quotaErr.RetryAfter(), "Too many carts created, please retry later", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to get cart", err.Error())
 		return
 	}
@@ -102,6 +109,9 @@ func (h *Handler) AddItem(c *gin.Context) {
 	// Add item
 	item, err := h.service.AddItem(c.Request.Context(), cartID, req)
 	if err != nil {
+		if respondDiscontinued(c, err) {
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to add item", err.Error())
 		return
 	}
@@ -183,11 +193,16 @@ func (h *Handler) UpdateItemQuantity(c *gin.Context) {
 	// Update item
 	item, err := h.service.UpdateItemQuantity(c.Request.Context(), cartID, itemID, req.Quantity)
 	if err != nil {
+		if respondDiscontinued(c, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, model.ErrInvalidQuantity):
 			response.Error(c, http.StatusBadRequest, "Invalid quantity", err.Error())
 		case errors.Is(err, model.ErrCartItemNotFound):
 			response.Error(c, http.StatusNotFound, "Item not found", err.Error())
+		case errors.Is(err, model.ErrGiftItemNotEditable):
+			response.Error(c, http.StatusUnprocessableEntity, "Gift item cannot be changed", err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "Failed to update item", err.Error())
 		}
@@ -205,6 +220,142 @@ func (h *Handler) UpdateItemQuantity(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Item quantity updated", item)
 }
 
+// BulkUpdateItems handles PATCH /cart/items/bulk
+// @Summary Bulk update cart item quantities
+// @Description Applies multiple quantity updates/removals (quantity=0) in one transaction. All-or-nothing.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param request body model.BulkUpdateCartItemsRequest true "Bulk Update Request"
+// @Success 200 {object} SuccessResponse{data=model.CartResponse}
+// @Router /cart/items/bulk [patch]
+func (h *Handler) BulkUpdateItems(c *gin.Context) {
+	// Get cart_id from middleware
+	cartID, err := middleware.GetCartID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid cart", err.Error())
+		return
+	}
+
+	// Parse request
+	var req model.BulkUpdateCartItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	cart, err := h.service.BulkUpdateItems(c.Request.Context(), cartID, req)
+	if err != nil {
+		if respondDiscontinued(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, model.ErrInvalidQuantity),
+			errors.Is(err, model.ErrBulkDuplicateItem),
+			errors.Is(err, model.ErrBulkTooManyItems):
+			response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		case errors.Is(err, model.ErrCartNotFound),
+			errors.Is(err, model.ErrCartItemNotFound):
+			response.Error(c, http.StatusNotFound, "Item not found", err.Error())
+		case errors.Is(err, model.ErrCartExpired):
+			response.Error(c, http.StatusGone, "Cart has expired", err.Error())
+		case errors.Is(err, model.ErrInsufficientStock),
+			errors.Is(err, model.ErrBookNotAvailable),
+			errors.Is(err, model.ErrGiftItemNotEditable):
+			response.Error(c, http.StatusUnprocessableEntity, "Cannot update items", err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to update items", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Cart items updated", cart)
+}
+
+// RestoreFromOrder handles POST /cart/restore/:order_id
+// @Summary Restore items of a cancelled order to cart
+// @Description Rebuilds cart items from order snapshot (within restore window), validating current stock/prices
+// @Tags Cart
+// @Produce json
+// @Param order_id path string true "Order ID (UUID)"
+// @Success 200 {object} SuccessResponse{data=model.OrderToCartResponse}
+// @Router /cart/restore/{order_id} [post]
+func (h *Handler) RestoreFromOrder(c *gin.Context) {
+	userID, orderID, ok := parseOrderToCartParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.RestoreCartFromOrder(c.Request.Context(), userID, orderID)
+	if err != nil {
+		writeOrderToCartError(c, err, "Failed to restore cart")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Cart restored", result)
+}
+
+// ReorderToCart handles POST /cart/reorder/:order_id
+// @Summary Copy a past order's items into cart
+// @Description Alternative to POST /orders/reorder: items go to the cart (validated against current stock/prices) so user can edit before checkout
+// @Tags Cart
+// @Produce json
+// @Param order_id path string true "Order ID (UUID)"
+// @Success 200 {object} SuccessResponse{data=model.OrderToCartResponse}
+// @Router /cart/reorder/{order_id} [post]
+func (h *Handler) ReorderToCart(c *gin.Context) {
+	userID, orderID, ok := parseOrderToCartParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ReorderToCart(c.Request.Context(), userID, orderID)
+	if err != nil {
+		writeOrderToCartError(c, err, "Failed to reorder to cart")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Order items added to cart", result)
+}
+
+// parseOrderToCartParams lấy user_id (bắt buộc đăng nhập) + order_id từ path, tự ghi response lỗi
+func parseOrderToCartParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userIDValue, exists := c.Get(middleware.ContextKeyUserID)
+	if !exists || userIDValue == nil {
+		response.Error(c, http.StatusUnauthorized, "Not authenticated", "User ID required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := userIDValue.(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Invalid user ID", "User ID must be UUID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, orderID, true
+}
+
+// writeOrderToCartError map lỗi của restore/reorder-to-cart sang HTTP status
+func writeOrderToCartError(c *gin.Context, err error, fallbackMsg string) {
+	var orderErr *orderModel.OrderError
+	switch {
+	case errors.As(err, &orderErr) && orderErr.Code == orderModel.ErrCodeOrderNotFound,
+		errors.Is(err, orderModel.ErrOrderNotFound):
+		response.Error(c, http.StatusNotFound, "Order not found", err.Error())
+	case errors.Is(err, model.ErrRestoreOrderNotCancelled):
+		response.Error(c, http.StatusUnprocessableEntity, "Cannot restore cart", err.Error())
+	case errors.Is(err, model.ErrRestoreWindowExpired):
+		response.Error(c, http.StatusGone, "Restore window expired", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, fallbackMsg, err.Error())
+	}
+}
+
 // domains/cart/handler.go
 
 // RemoveItem handles DELETE /me/cart/items/{item_id}
@@ -236,6 +387,8 @@ func (h *Handler) RemoveItem(c *gin.Context) {
 		switch {
 		case errors.Is(err, model.ErrCartItemNotFound):
 			response.Error(c, http.StatusNotFound, "Item not found", err.Error())
+		case errors.Is(err, model.ErrGiftItemNotEditable):
+			response.Error(c, http.StatusUnprocessableEntity, "Gift item cannot be changed", err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "Failed to remove item", err.Error())
 		}
@@ -319,6 +472,30 @@ func (h *Handler) ValidateCart(c *gin.Context) {
 	response.Success(c, statusCode, "Cart validation completed", result)
 }
 
+// GetProgress handles GET /cart/progress
+// @Summary Minimum order / free-shipping progress
+// @Description Còn thiếu bao nhiêu để đạt đơn tối thiểu và miễn phí ship (message theo Accept-Language)
+func (h *Handler) GetProgress(c *gin.Context) {
+	cartID, err := middleware.GetCartID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid cart", err.Error())
+		return
+	}
+
+	result, err := h.service.GetProgress(c.Request.Context(), cartID, middleware.GetLocale(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrCartNotFound):
+			response.Error(c, http.StatusNotFound, "Cart not found", nil)
+		default:
+			response.Error(c, http.StatusInternalServerError, "Failed to get cart progress", err.Error())
+		}
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Cart progress retrieved", result)
+}
+
 // domains/cart/handler.go
 
 // ApplyPromoCode handles POST /cart/apply-promotion
@@ -492,6 +669,9 @@ func (h *Handler) Checkout(c *gin.Context) {
 			err.Error())
 		return
 	}
+	if req.AffiliateCode == "" {
+		req.AffiliateCode = middleware.GetAffiliateCode(c)
+	}
 
 	// ===================================
 	// STEP 4: Validate request (struct validation)
@@ -508,6 +688,14 @@ func (h *Handler) Checkout(c *gin.Context) {
 	// ===================================
 	result, err := h.service.Checkout(c.Request.Context(), userID, cartID, req)
 	if err != nil {
+		// Storefront degraded (breaker mở): 503 + Retry-After, client hiển thị "thử lại sau"
+		var openErr *breaker.OpenError
+		if errors.As(err, &openErr) {
+			response.ServiceUnavailable(c, openErr.RetryAfter,
+				"Checkout temporarily unavailable",
+				"We are experiencing technical issues, please retry later")
+			return
+		}
 		// System error (not validation error)
 		response.Error(c, http.StatusInternalServerError,
 			"Checkout failed",
@@ -526,3 +714,13 @@ func (h *Handler) Checkout(c *gin.Context) {
 
 	response.Success(c, statusCode, "Checkout completed", result)
 }
+
+// respondDiscontinued trả 422 ITEM_DISCONTINUED kèm gợi ý thay thế (nếu có)
+func respondDiscontinued(c *gin.Context, err error) bool {
+	var discontinued *model.ItemDiscontinuedError
+	if !errors.As(err, &discontinued) {
+		return false
+	}
+	response.Error(c, http.StatusUnprocessableEntity, "Book has been discontinued", discontinued.Detail())
+	return true
+}
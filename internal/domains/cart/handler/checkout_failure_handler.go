@@ -0,0 +1,57 @@
+package cart
+
+import (
+	"errors"
+	"net/http"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ===================================
+// CHECKOUT FAILURE DIAGNOSTICS: /admin/checkout-failures
+// CSKH tra phase nào làm checkout của khách thất bại
+// ===================================
+
+// AdminListCheckoutFailures handles GET /admin/checkout-failures
+// Query: user_id, cart_id, phase, from, to (YYYY-MM-DD), page, limit
+func (h *Handler) AdminListCheckoutFailures(c *gin.Context) {
+	var req model.ListCheckoutFailuresRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.ListCheckoutFailures(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list checkout failures", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Checkout failures retrieved", result)
+}
+
+// AdminGetCheckoutFailure handles GET /admin/checkout-failures/:id
+// Trả kèm CheckoutResponse đầy đủ (phases, errors, warnings) lúc khách checkout
+func (h *Handler) AdminGetCheckoutFailure(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid checkout failure ID", err.Error())
+		return
+	}
+
+	failure, err := h.service.GetCheckoutFailure(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrCheckoutFailureNotFound) {
+			response.Error(c, http.StatusNotFound, "Checkout failure not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get checkout failure", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Checkout failure retrieved", failure)
+}
@@ -6,6 +6,7 @@ import (
 	inventoryService "bookstore-backend/internal/domains/inventory/service"
 	orderModel "bookstore-backend/internal/domains/order/model"
 	orderRepo "bookstore-backend/internal/domains/order/repository"
+	"bookstore-backend/internal/infrastructure/joboutcome"
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/logger"
 	"context"
@@ -17,15 +18,18 @@ import (
 type AutoReleaseReservationHandler struct {
 	orderRepo        orderRepo.OrderRepository
 	inventoryService inventoryService.ServiceInterface
+	outcomes         *joboutcome.Recorder
 }
 
 func NewAutoReleaseReservationHandler(
 	orderRepo orderRepo.OrderRepository,
 	inventoryService inventoryService.ServiceInterface,
+	outcomes *joboutcome.Recorder,
 ) *AutoReleaseReservationHandler {
 	return &AutoReleaseReservationHandler{
 		orderRepo:        orderRepo,
 		inventoryService: inventoryService,
+		outcomes:         outcomes,
 	}
 }
 
@@ -56,6 +60,7 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 			"order_id": payload.OrderID,
 			"status":   order.Status,
 		})
+		h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeSkippedPaid, 1)
 		return nil
 	}
 
@@ -65,6 +70,7 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 			"order_id": payload.OrderID,
 			"status":   order.Status,
 		})
+		h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeSkippedClosed, 1)
 		return nil
 	}
 
@@ -74,6 +80,7 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 			"order_id": payload.OrderID,
 			"status":   order.Status,
 		})
+		h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeSkippedIneligible, 1)
 		return nil
 	}
 
@@ -88,6 +95,8 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 	}
 
 	// 5. Release từng item (reservation)
+	// Đếm giữ hàng thực sự trả lại (task thành công chưa chắc đã trả được dòng nào)
+	var released, releasedUnits, releaseFailed int
 	for _, item := range orderItems {
 		if item.WarehouseID == nil {
 			continue
@@ -102,7 +111,8 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 			Reason:      stringPtr("payment_timeout"),
 		}
 
-		if _, err := h.inventoryService.ReleaseStock(ctx, releaseReq); err != nil {
+		resp, err := h.inventoryService.ReleaseStock(ctx, releaseReq)
+		if err != nil {
 			logger.Info("Failed to release stock", map[string]interface{}{
 				"order_id":     payload.OrderID,
 				"book_id":      item.BookID,
@@ -110,8 +120,11 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 				"error":        err.Error(),
 			})
 			// Tiếp tục với item khác, không return error
+			releaseFailed++
 			continue
 		}
+		released++
+		releasedUnits += resp.ReleasedQuantity
 
 		logger.Info("Released stock", map[string]interface{}{
 			"order_id": payload.OrderID,
@@ -119,6 +132,10 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 		})
 	}
 
+	h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeReservationsReleased, released)
+	h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeUnitsReleased, releasedUnits)
+	h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeReleaseFailed, releaseFailed)
+
 	// 6. Update order status to cancelled (auto-cancel)
 	err = h.orderRepo.CancelOrder(ctx, payload.OrderID, "Payment timeout - auto-cancelled", order.Version)
 	if err != nil {
@@ -126,12 +143,16 @@ func (h *AutoReleaseReservationHandler) ProcessTask(ctx context.Context, t *asyn
 			"order_id": payload.OrderID,
 			"error":    err.Error(),
 		})
+		h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeCancelFailed, 1)
 		return fmt.Errorf("cancel order: %w", err)
 	}
+	h.outcomes.Record(ctx, joboutcome.JobAutoReleaseReservation, joboutcome.OutcomeOrderCancelled, 1)
 
 	logger.Info("Auto-released reservations and cancelled order", map[string]interface{}{
 		"order_id":     payload.OrderID,
 		"order_number": payload.OrderNumber,
+		"released":     released,
+		"failed":       releaseFailed,
 	})
 
 	return nil
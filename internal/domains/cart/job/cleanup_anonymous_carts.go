@@ -0,0 +1,61 @@
+package job
+
+import (
+	"bookstore-backend/internal/domains/cart/repository"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	anonymousCartCleanupBatch     = 1000
+	anonymousCartCleanupMaxRounds = 100 // tối đa 100k giỏ/lần chạy, phần còn lại để lần sau
+)
+
+var anonymousCartsCleanedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "anonymous_carts_cleaned_total",
+	Help: "Empty anonymous carts deleted by the cleanup job.",
+})
+
+// CleanupAnonymousCartsHandler xoá giỏ ẩn danh rỗng có updated_at cũ hơn maxAge
+type CleanupAnonymousCartsHandler struct {
+	cartRepo repository.RepositoryInterface
+	maxAge   time.Duration
+}
+
+func NewCleanupAnonymousCartsHandler(cartRepo repository.RepositoryInterface, maxAge time.Duration) *CleanupAnonymousCartsHandler {
+	return &CleanupAnonymousCartsHandler{
+		cartRepo: cartRepo,
+		maxAge:   maxAge,
+	}
+}
+
+func (h *CleanupAnonymousCartsHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	before := time.Now().Add(-h.maxAge)
+
+	var total int64
+	for round := 0; round < anonymousCartCleanupMaxRounds; round++ {
+		deleted, err := h.cartRepo.DeleteEmptyAnonymousCarts(ctx, before, anonymousCartCleanupBatch)
+		if err != nil {
+			logger.Error("Failed to cleanup anonymous carts", err)
+			return fmt.Errorf("cleanup anonymous carts: %w", err)
+		}
+		total += deleted
+		anonymousCartsCleanedTotal.Add(float64(deleted))
+		if deleted < anonymousCartCleanupBatch {
+			break
+		}
+	}
+
+	logger.Info("Cleaned up empty anonymous carts", map[string]interface{}{
+		"before":        before,
+		"deleted_count": total,
+	})
+
+	return nil
+}
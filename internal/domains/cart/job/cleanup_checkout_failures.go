@@ -0,0 +1,40 @@
+package job
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/domains/cart/repository"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// CleanupCheckoutFailuresHandler xoá chẩn đoán checkout thất bại cũ hơn CheckoutFailureRetention
+type CleanupCheckoutFailuresHandler struct {
+	cartRepo repository.RepositoryInterface
+}
+
+func NewCleanupCheckoutFailuresHandler(cartRepo repository.RepositoryInterface) *CleanupCheckoutFailuresHandler {
+	return &CleanupCheckoutFailuresHandler{
+		cartRepo: cartRepo,
+	}
+}
+
+func (h *CleanupCheckoutFailuresHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	before := time.Now().Add(-model.CheckoutFailureRetention)
+
+	deleted, err := h.cartRepo.DeleteCheckoutFailuresBefore(ctx, before)
+	if err != nil {
+		logger.Error("Failed to cleanup checkout failures", err)
+		return fmt.Errorf("cleanup checkout failures: %w", err)
+	}
+
+	logger.Info("Cleaned up checkout failures", map[string]interface{}{
+		"before":        before,
+		"deleted_count": deleted,
+	})
+
+	return nil
+}
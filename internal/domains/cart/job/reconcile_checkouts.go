@@ -0,0 +1,46 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// CheckoutReconciler đối soát checkout saga (implement bởi cart service)
+type CheckoutReconciler interface {
+	ReconcileCheckouts(ctx context.Context) (*model.CheckoutReconcileResult, error)
+}
+
+// ReconcileCheckoutsHandler định kỳ đóng saga kẹt, bù trừ lại saga lỗi và trả tồn đơn quá hạn thanh toán
+//
+// WHY: auto-release là task hẹn giờ, bù trừ lúc checkout lỗi chạy trong request - cả hai đều có thể
+// mất (worker ngừng lâu, Redis mất dữ liệu, process chết giữa chừng) → tồn bị giữ không bao giờ trả
+type ReconcileCheckoutsHandler struct {
+	reconciler CheckoutReconciler
+}
+
+func NewReconcileCheckoutsHandler(reconciler CheckoutReconciler) *ReconcileCheckoutsHandler {
+	return &ReconcileCheckoutsHandler{
+		reconciler: reconciler,
+	}
+}
+
+func (h *ReconcileCheckoutsHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	result, err := h.reconciler.ReconcileCheckouts(ctx)
+	if err != nil {
+		logger.Error("Failed to reconcile checkouts", err)
+		return fmt.Errorf("reconcile checkouts: %w", err)
+	}
+
+	logger.Info("Checkouts reconciled", map[string]interface{}{
+		"compensated":    result.Compensated,
+		"failed":         result.Failed,
+		"holds_released": result.HoldsReleased,
+		"pruned":         result.Pruned,
+	})
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/hibiken/asynq"
 
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
+	analyticsService "bookstore-backend/internal/domains/analytics/service"
 	"bookstore-backend/internal/domains/cart/model"
 	cartRepo "bookstore-backend/internal/domains/cart/repository"
 	notificationModel "bookstore-backend/internal/domains/notification/model"
@@ -29,16 +31,19 @@ import (
 type RemoveExpiredPromotionsHandler struct {
 	cartRepo            cartRepo.RepositoryInterface
 	notificationService notificationService.NotificationService // ✅ UPDATED: Use correct interface
+	analyticsService    analyticsService.ServiceInterface       // lịch sử giỏ của khách (promo_expired)
 }
 
 // NewRemoveExpiredPromotionsHandler creates a new handler instance
 func NewRemoveExpiredPromotionsHandler(
 	cartRepo cartRepo.RepositoryInterface,
 	notificationService notificationService.NotificationService, // ✅ UPDATED
+	analyticsService analyticsService.ServiceInterface,
 ) *RemoveExpiredPromotionsHandler {
 	return &RemoveExpiredPromotionsHandler{
 		cartRepo:            cartRepo,
 		notificationService: notificationService,
+		analyticsService:    analyticsService,
 	}
 }
 
@@ -172,6 +177,7 @@ func (h *RemoveExpiredPromotionsHandler) processCart(
 
 		// ✅ UPDATED: Create notification using SendNotification method
 		h.sendPromotionRemovedNotification(ctx, cart, reason, metadata)
+		h.recordPromotionExpiredActivity(ctx, cart, reason)
 
 		stats.Removed++
 	} else {
@@ -242,6 +248,25 @@ func (h *RemoveExpiredPromotionsHandler) buildRemovalMetadata(
 // - Multi-language support ready
 // - Consistent notification styling
 // - Variable substitution (promo_code, reason, etc.)
+// recordPromotionExpiredActivity ghi lịch sử giỏ của khách (đang chạy trong worker → ghi thẳng, không enqueue)
+func (h *RemoveExpiredPromotionsHandler) recordPromotionExpiredActivity(ctx context.Context, cart *model.CartWithPromoInfo, reason string) {
+	cartID := cart.CartID
+	err := h.analyticsService.RecordActivity(ctx, analyticsModel.ActivityEvent{
+		UserID:    cart.UserID,
+		Domain:    analyticsModel.ActivityDomainCart,
+		EventType: analyticsModel.ActivityCartPromoExpired,
+		CartID:    &cartID,
+		Properties: map[string]interface{}{
+			"promo_code": cart.PromoCode,
+			"reason":     reason,
+		},
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		logger.Error("Failed to record promotion expired activity", err)
+	}
+}
+
 func (h *RemoveExpiredPromotionsHandler) sendPromotionRemovedNotification(
 	ctx context.Context,
 	cart *model.CartWithPromoInfo,
@@ -2,22 +2,38 @@ package job
 
 import (
 	"bookstore-backend/internal/domains/cart/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
 	emailInfra "bookstore-backend/internal/infrastructure/email"
+	"bookstore-backend/internal/infrastructure/joboutcome"
+	"bookstore-backend/internal/shared/locale"
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/logger"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hibiken/asynq"
 )
 
+// CommunicationLogger ghi lịch sử liên lạc của đơn (implement bởi order repository)
+type CommunicationLogger interface {
+	CreateOrderCommunication(ctx context.Context, comm *orderModel.OrderCommunication) error
+}
+
 type SendOrderConfirmationHandler struct {
 	emailService emailInfra.EmailService
+	templates    emailInfra.TemplateRenderer
+	comms        CommunicationLogger
+	outcomes     *joboutcome.Recorder
 }
 
-func NewSendOrderConfirmationHandler(emailService emailInfra.EmailService) *SendOrderConfirmationHandler {
+func NewSendOrderConfirmationHandler(emailService emailInfra.EmailService, templates emailInfra.TemplateRenderer, comms CommunicationLogger, outcomes *joboutcome.Recorder) *SendOrderConfirmationHandler {
 	return &SendOrderConfirmationHandler{
 		emailService: emailService,
+		templates:    templates,
+		comms:        comms,
+		outcomes:     outcomes,
 	}
 }
 
@@ -31,18 +47,29 @@ func (h *SendOrderConfirmationHandler) ProcessTask(ctx context.Context, t *asynq
 		"order_id":     payload.OrderID,
 		"order_number": payload.OrderNumber,
 		"email":        payload.UserEmail,
+		"locale":       payload.Locale,
 	})
 
-	// Build email content
-	subject := fmt.Sprintf("Đơn hàng #%s đã được đặt thành công", payload.OrderNumber)
-	body := h.buildEmailBody(payload)
+	// Khách không có email (đơn tạo tay / import thiếu địa chỉ) → retry vô ích, chỉ đếm lại cho digest vận hành
+	if strings.TrimSpace(payload.UserEmail) == "" {
+		logger.Info("No recipient email, skip sending order confirmation", map[string]interface{}{
+			"order_id": payload.OrderID,
+		})
+		h.outcomes.Record(ctx, joboutcome.JobOrderConfirmationEmail, joboutcome.OutcomeSkippedMissingAddress, 1)
+		return nil
+	}
+
+	// Build email content theo ngôn ngữ của user
+	subject, body, isHTML := h.buildEmail(ctx, payload)
 
 	// Send email
+	// Message-ID mới cho mỗi lần gửi (kể cả retry) → webhook delivered/bounce khớp đúng dòng contact history
 	emailReq := emailInfra.EmailRequest{
-		To:      []string{payload.UserEmail},
-		Subject: subject,
-		Body:    body,
-		IsHTML:  false,
+		To:        []string{payload.UserEmail},
+		Subject:   subject,
+		Body:      body,
+		IsHTML:    isHTML,
+		MessageID: emailInfra.NewMessageID(),
 	}
 
 	if err := h.emailService.SendEmail(ctx, emailReq); err != nil {
@@ -51,8 +78,18 @@ func (h *SendOrderConfirmationHandler) ProcessTask(ctx context.Context, t *asynq
 			"email":    payload.UserEmail,
 			"error":    err.Error(),
 		})
+		h.logCommunication(ctx, payload, subject, "", err)
+
+		// Địa chỉ đã hard bounce / complaint → retry vô ích
+		if errors.Is(err, emailInfra.ErrRecipientSuppressed) {
+			h.outcomes.Record(ctx, joboutcome.JobOrderConfirmationEmail, joboutcome.OutcomeSuppressed, 1)
+			return fmt.Errorf("send email: %w: %w", err, asynq.SkipRetry)
+		}
+		h.outcomes.Record(ctx, joboutcome.JobOrderConfirmationEmail, joboutcome.OutcomeFailed, 1)
 		return fmt.Errorf("send email: %w", err)
 	}
+	h.logCommunication(ctx, payload, subject, emailReq.MessageID, nil)
+	h.outcomes.Record(ctx, joboutcome.JobOrderConfirmationEmail, joboutcome.OutcomeSent, 1)
 
 	logger.Info("Sent order confirmation email successfully", map[string]interface{}{
 		"order_id": payload.OrderID,
@@ -62,19 +99,63 @@ func (h *SendOrderConfirmationHandler) ProcessTask(ctx context.Context, t *asynq
 	return nil
 }
 
-func (h *SendOrderConfirmationHandler) buildEmailBody(payload model.SendOrderConfirmationPayload) string {
-	paymentMethodText := map[string]string{
-		"cash_on_delivery": "Thanh toán khi nhận hàng (COD)",
-		"bank_transfer":    "Chuyển khoản ngân hàng",
-		"e_wallet":         "Ví điện tử",
-		"credit_card":      "Thẻ tín dụng",
+// logCommunication ghi mỗi lần gửi (kể cả lỗi, mỗi retry 1 dòng) vào contact history của đơn
+// Lỗi ghi log không làm fail task: email đã gửi thì không được gửi lại chỉ vì log lỗi
+func (h *SendOrderConfirmationHandler) logCommunication(ctx context.Context, payload model.SendOrderConfirmationPayload, subject, messageID string, sendErr error) {
+	comm := &orderModel.OrderCommunication{
+		OrderID:   payload.OrderID,
+		Channel:   orderModel.CommunicationChannelEmail,
+		Purpose:   orderModel.CommunicationPurposeOrderConfirmation,
+		Status:    orderModel.CommunicationStatusSent,
+		Recipient: &payload.UserEmail,
+		Subject:   &subject,
+	}
+	if messageID != "" {
+		comm.ProviderMessageID = &messageID
+	}
+	if sendErr != nil {
+		errMsg := sendErr.Error()
+		comm.Status = orderModel.CommunicationStatusFailed
+		comm.ErrorMessage = &errMsg
+	}
+
+	if err := h.comms.CreateOrderCommunication(ctx, comm); err != nil {
+		logger.Error("Failed to log order confirmation communication", err)
+	}
+}
+
+// buildEmail render template order_confirmation theo locale của user (HTML)
+// Template lỗi → fallback nội dung tiếng Việt plain text, email xác nhận đơn không được phép mất
+func (h *SendOrderConfirmationHandler) buildEmail(ctx context.Context, payload model.SendOrderConfirmationPayload) (string, string, bool) {
+	userLocale := locale.Normalize(payload.Locale)
+	data := map[string]interface{}{
+		"order_number":       payload.OrderNumber,
+		"order_date":         payload.OrderCreatedAt,
+		"total_amount":       payload.Total.String(),
+		"payment_method":     locale.PaymentMethod(userLocale, payload.PaymentMethod),
+		"estimated_delivery": locale.FormatDayRange(userLocale, payload.EstimatedDeliveryMinDays, payload.EstimatedDeliveryMaxDays),
+		"order_url":          orderURL(payload.OrderNumber),
 	}
 
-	method := paymentMethodText[payload.PaymentMethod]
-	if method == "" {
-		method = payload.PaymentMethod
+	subject, body, err := h.templates.RenderTemplate(ctx, emailInfra.TemplateOrderConfirmation, userLocale, emailInfra.TemplateChannel, data)
+	if err == nil {
+		return subject, body, true
 	}
 
+	logger.Info("Render order confirmation template failed, falling back to default content", map[string]interface{}{
+		"order_id": payload.OrderID,
+		"locale":   userLocale,
+		"error":    err.Error(),
+	})
+	subject = fmt.Sprintf("Đơn hàng #%s đã được đặt thành công", payload.OrderNumber)
+	return subject, h.buildEmailBody(payload), false
+}
+
+func orderURL(orderNumber string) string {
+	return "https://bookstore.com/orders/" + orderNumber
+}
+
+func (h *SendOrderConfirmationHandler) buildEmailBody(payload model.SendOrderConfirmationPayload) string {
 	return fmt.Sprintf(`Chào bạn,
 
 Cảm ơn bạn đã đặt hàng tại Bookstore!
@@ -87,15 +168,15 @@ Chi tiết đơn hàng:
 
 Dự kiến giao hàng: %s
 
-Theo dõi đơn hàng của bạn tại: https://bookstore.com/orders/%s
+Theo dõi đơn hàng của bạn tại: %s
 
 Trân trọng,
 Bookstore Team`,
 		payload.OrderNumber,
 		payload.OrderCreatedAt,
 		payload.Total.String(),
-		method,
-		payload.EstimatedDelivery,
-		payload.OrderNumber,
+		locale.PaymentMethod(locale.Vietnamese, payload.PaymentMethod),
+		locale.FormatDayRange(locale.Vietnamese, payload.EstimatedDeliveryMinDays, payload.EstimatedDeliveryMaxDays),
+		orderURL(payload.OrderNumber),
 	)
 }
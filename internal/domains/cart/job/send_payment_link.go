@@ -0,0 +1,154 @@
+package job
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	emailInfra "bookstore-backend/internal/infrastructure/email"
+	"bookstore-backend/internal/infrastructure/joboutcome"
+	"bookstore-backend/internal/shared/locale"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// SendPaymentLinkHandler gửi link thanh toán cho đơn nhân viên đặt thay khách (pay_link)
+type SendPaymentLinkHandler struct {
+	emailService emailInfra.EmailService
+	comms        CommunicationLogger
+	outcomes     *joboutcome.Recorder
+}
+
+func NewSendPaymentLinkHandler(emailService emailInfra.EmailService, comms CommunicationLogger, outcomes *joboutcome.Recorder) *SendPaymentLinkHandler {
+	return &SendPaymentLinkHandler{
+		emailService: emailService,
+		comms:        comms,
+		outcomes:     outcomes,
+	}
+}
+
+func (h *SendPaymentLinkHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.SendPaymentLinkPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	logger.Info("Processing send payment link task", map[string]interface{}{
+		"order_id":     payload.OrderID,
+		"order_number": payload.OrderNumber,
+		"email":        payload.UserEmail,
+	})
+
+	// Khách không có email (đơn tạo tay / import thiếu địa chỉ) → retry vô ích, chỉ đếm lại cho digest vận hành
+	if strings.TrimSpace(payload.UserEmail) == "" {
+		logger.Info("No recipient email, skip sending payment link", map[string]interface{}{
+			"order_id": payload.OrderID,
+		})
+		h.outcomes.Record(ctx, joboutcome.JobPaymentLinkEmail, joboutcome.OutcomeSkippedMissingAddress, 1)
+		return nil
+	}
+
+	// Link hết hạn thì đơn đã bị auto-release huỷ → gửi muộn chỉ gây nhầm lẫn
+	if time.Now().After(payload.ExpiresAt) {
+		logger.Info("Payment link expired, skip sending", map[string]interface{}{
+			"order_id":   payload.OrderID,
+			"expires_at": payload.ExpiresAt,
+		})
+		h.outcomes.Record(ctx, joboutcome.JobPaymentLinkEmail, joboutcome.OutcomeSkippedExpired, 1)
+		return nil
+	}
+
+	subject, body := buildPaymentLinkEmail(payload)
+	emailReq := emailInfra.EmailRequest{
+		To:        []string{payload.UserEmail},
+		Subject:   subject,
+		Body:      body,
+		IsHTML:    false,
+		MessageID: emailInfra.NewMessageID(),
+	}
+
+	if err := h.emailService.SendEmail(ctx, emailReq); err != nil {
+		h.logCommunication(ctx, payload, subject, "", err)
+		if errors.Is(err, emailInfra.ErrRecipientSuppressed) {
+			h.outcomes.Record(ctx, joboutcome.JobPaymentLinkEmail, joboutcome.OutcomeSuppressed, 1)
+			return fmt.Errorf("send email: %w: %w", err, asynq.SkipRetry)
+		}
+		h.outcomes.Record(ctx, joboutcome.JobPaymentLinkEmail, joboutcome.OutcomeFailed, 1)
+		return fmt.Errorf("send email: %w", err)
+	}
+	h.logCommunication(ctx, payload, subject, emailReq.MessageID, nil)
+	h.outcomes.Record(ctx, joboutcome.JobPaymentLinkEmail, joboutcome.OutcomeSent, 1)
+
+	logger.Info("Sent payment link email successfully", map[string]interface{}{
+		"order_id": payload.OrderID,
+		"email":    payload.UserEmail,
+	})
+	return nil
+}
+
+// logCommunication ghi lần gửi link vào contact history của đơn (CSKH xem được khách đã nhận link chưa)
+func (h *SendPaymentLinkHandler) logCommunication(ctx context.Context, payload model.SendPaymentLinkPayload, subject, messageID string, sendErr error) {
+	comm := &orderModel.OrderCommunication{
+		OrderID:   payload.OrderID,
+		Channel:   orderModel.CommunicationChannelEmail,
+		Purpose:   orderModel.CommunicationPurposePaymentLink,
+		Status:    orderModel.CommunicationStatusSent,
+		Recipient: &payload.UserEmail,
+		Subject:   &subject,
+	}
+	if messageID != "" {
+		comm.ProviderMessageID = &messageID
+	}
+	if sendErr != nil {
+		errMsg := sendErr.Error()
+		comm.Status = orderModel.CommunicationStatusFailed
+		comm.ErrorMessage = &errMsg
+	}
+
+	if err := h.comms.CreateOrderCommunication(ctx, comm); err != nil {
+		logger.Error("Failed to log payment link communication", err)
+	}
+}
+
+// Giờ hết hạn hiển thị theo giờ Việt Nam (worker chạy UTC)
+var paymentLinkTZ = time.FixedZone("ICT", 7*60*60)
+
+// buildPaymentLinkEmail nội dung plain text theo ngôn ngữ của khách
+func buildPaymentLinkEmail(payload model.SendPaymentLinkPayload) (string, string) {
+	expiresAt := payload.ExpiresAt.In(paymentLinkTZ).Format("15:04 02/01/2006") + " (GMT+7)"
+
+	if locale.Normalize(payload.Locale) == locale.English {
+		subject := fmt.Sprintf("Payment link for order #%s", payload.OrderNumber)
+		body := fmt.Sprintf(`Hello,
+
+Our customer service team has placed order %s for you.
+Total: %s VND
+
+Please complete your payment here: %s
+
+The link is valid until %s. After that the order will be cancelled automatically.
+
+Best regards,
+Bookstore Team`, payload.OrderNumber, payload.Total, payload.PaymentURL, expiresAt)
+		return subject, body
+	}
+
+	subject := fmt.Sprintf("Link thanh toán cho đơn hàng #%s", payload.OrderNumber)
+	body := fmt.Sprintf(`Chào bạn,
+
+Bộ phận chăm sóc khách hàng đã đặt đơn hàng %s giúp bạn.
+Tổng tiền: %s VND
+
+Vui lòng thanh toán tại: %s
+
+Link có hiệu lực đến %s, sau thời gian này đơn hàng sẽ tự động bị huỷ.
+
+Trân trọng,
+Bookstore Team`, payload.OrderNumber, payload.Total, payload.PaymentURL, expiresAt)
+	return subject, body
+}
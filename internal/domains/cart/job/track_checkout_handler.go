@@ -1,21 +1,24 @@
 package job
 
 import (
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
+	analyticsService "bookstore-backend/internal/domains/analytics/service"
 	"bookstore-backend/internal/domains/cart/model"
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/logger"
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/hibiken/asynq"
 )
 
 type TrackCheckoutHandler struct {
-	// Add analytics service here if you have one
+	analyticsService analyticsService.ServiceInterface
 }
 
-func NewTrackCheckoutHandler() *TrackCheckoutHandler {
-	return &TrackCheckoutHandler{}
+func NewTrackCheckoutHandler(analyticsService analyticsService.ServiceInterface) *TrackCheckoutHandler {
+	return &TrackCheckoutHandler{analyticsService: analyticsService}
 }
 
 func (h *TrackCheckoutHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
@@ -35,8 +38,34 @@ func (h *TrackCheckoutHandler) ProcessTask(ctx context.Context, t *asynq.Task) e
 		"discount":       payload.Discount.String(),
 	})
 
-	// TODO: Send to analytics service (Google Analytics, Mixpanel, etc.)
-	// Example: h.analyticsService.TrackCheckout(ctx, payload)
+	// Checkout luôn có user_id → nối với event ẩn danh đã stitch của user này
+	properties := map[string]interface{}{
+		"order_number":   payload.OrderNumber,
+		"total":          payload.Total.String(),
+		"item_count":     payload.ItemCount,
+		"payment_method": payload.PaymentMethod,
+		"discount":       payload.Discount.String(),
+	}
+	if payload.PromoCode != nil {
+		properties["promo_code"] = *payload.PromoCode
+	}
+	// Nguồn kênh (utm_*, affiliate_id, app_version...) đi kèm event để phân tích theo kênh
+	for key, value := range payload.Metadata {
+		if _, exists := properties[key]; !exists {
+			properties[key] = value
+		}
+	}
+
+	err := h.analyticsService.RecordEvent(ctx, analyticsModel.Event{
+		EventType:  analyticsModel.EventCheckoutCompleted,
+		UserID:     &payload.UserID,
+		OrderID:    &payload.OrderID,
+		Properties: properties,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("record checkout event: %w", err)
+	}
 
 	logger.Info("Tracked checkout successfully", map[string]interface{}{
 		"order_id": payload.OrderID,
@@ -0,0 +1,112 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/domains/cart/repository"
+	notificationModel "bookstore-backend/internal/domains/notification/model"
+	notificationService "bookstore-backend/internal/domains/notification/service"
+	"bookstore-backend/pkg/logger"
+)
+
+const (
+	expiringCartBatch     = 200
+	expiringCartMaxRounds = 50 // tối đa 10k giỏ/lần chạy, phần còn lại để lần sau
+)
+
+var cartExpiryWarningsTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "cart_expiry_warnings_total",
+	Help: "Pre-expiry notifications sent for user carts.",
+})
+
+// WarnExpiringCartsHandler nhắc khách có giỏ còn hàng sắp hết hạn (mỗi lần gia hạn nhắc tối đa 1 lần)
+type WarnExpiringCartsHandler struct {
+	cartRepo            repository.RepositoryInterface
+	notificationService notificationService.NotificationService
+	policy              model.CartExpirationPolicy
+}
+
+func NewWarnExpiringCartsHandler(
+	cartRepo repository.RepositoryInterface,
+	notificationService notificationService.NotificationService,
+	policy model.CartExpirationPolicy,
+) *WarnExpiringCartsHandler {
+	return &WarnExpiringCartsHandler{
+		cartRepo:            cartRepo,
+		notificationService: notificationService,
+		policy:              policy,
+	}
+}
+
+// ProcessTask - nhận giỏ (MarkExpiryWarned) trước rồi mới gửi: job chạy chồng / retry không nhắc 2 lần.
+// Gửi lỗi chỉ log (nhắc là phụ, giỏ vẫn còn tới khi hết hạn)
+func (h *WarnExpiringCartsHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	if h.policy.WarnBefore <= 0 {
+		return nil
+	}
+	before := time.Now().Add(h.policy.WarnBefore)
+
+	var warned, skipped int
+	for round := 0; round < expiringCartMaxRounds; round++ {
+		carts, err := h.cartRepo.GetExpiringCarts(ctx, before, expiringCartBatch)
+		if err != nil {
+			logger.Error("Failed to fetch expiring carts", err)
+			return fmt.Errorf("fetch expiring carts: %w", err)
+		}
+
+		for _, cart := range carts {
+			claimed, err := h.cartRepo.MarkExpiryWarned(ctx, cart.CartID, cart.ExpiresAt)
+			if err != nil {
+				return fmt.Errorf("mark cart expiry warned: %w", err)
+			}
+			if !claimed {
+				skipped++
+				continue
+			}
+			if h.sendWarning(ctx, cart) {
+				warned++
+			}
+		}
+
+		if len(carts) < expiringCartBatch {
+			break
+		}
+	}
+
+	logger.Info("Cart expiry warnings processed", map[string]interface{}{
+		"expiring_before": before,
+		"warned":          warned,
+		"skipped":         skipped,
+	})
+	return nil
+}
+
+func (h *WarnExpiringCartsHandler) sendWarning(ctx context.Context, cart model.ExpiringCart) bool {
+	priority := notificationModel.PriorityMedium
+	_, err := h.notificationService.SendNotification(ctx, notificationModel.SendNotificationRequest{
+		UserID:       cart.UserID,
+		TemplateCode: "cart_expiring",
+		Data: map[string]interface{}{
+			"items_count": cart.ItemsCount,
+			"subtotal":    cart.Subtotal.StringFixed(0),
+			"expires_at":  cart.ExpiresAt.Format("02/01/2006 15:04"),
+			"cart_id":     cart.CartID.String(),
+		},
+		ReferenceType: stringPtr("cart"),
+		ReferenceID:   &cart.CartID,
+		Priority:      &priority,
+	})
+	if err != nil {
+		logger.Error("Failed to send cart expiry warning", err)
+		return false
+	}
+	cartExpiryWarningsTotal.Inc()
+	return true
+}
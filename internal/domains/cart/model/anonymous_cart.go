@@ -0,0 +1,38 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// =====================================================
+// ANONYMOUS CART QUOTA
+// =====================================================
+// Mỗi request ẩn danh chưa có giỏ sẽ INSERT 1 row carts → bot không giữ cookie tạo giỏ vô hạn.
+// - Quota tạo giỏ theo IP trong cửa sổ cố định (counter Redis), vượt → 429 + Retry-After
+// - Giỏ đã có của session không bị ảnh hưởng (quota chỉ tính lúc tạo mới)
+// - Giỏ ẩn danh rỗng không hoạt động quá EmptyMaxAge bị job dọn
+
+// AnonymousCartPolicy cấu hình quota + dọn giỏ ẩn danh
+type AnonymousCartPolicy struct {
+	// Số giỏ 1 IP được tạo trong Window (<= 0: tắt quota)
+	MaxCreatesPerIP int
+	Window          time.Duration
+	// Giỏ rỗng có updated_at cũ hơn khoảng này bị xoá
+	EmptyMaxAge time.Duration
+}
+
+// AnonymousCartQuotaError IP đã tạo quá MaxCreatesPerIP giỏ trong cửa sổ hiện tại
+type AnonymousCartQuotaError struct {
+	Limit int
+	Retry time.Duration
+}
+
+func (e *AnonymousCartQuotaError) Error() string {
+	return fmt.Sprintf("anonymous cart creation limit reached (%d per window), retry after %s", e.Limit, e.Retry.Round(time.Second))
+}
+
+// RetryAfter thời gian tới khi cửa sổ quota reset (middleware đọc qua interface, không import model)
+func (e *AnonymousCartQuotaError) RetryAfter() time.Duration {
+	return e.Retry
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	orderModel "bookstore-backend/internal/domains/order/model"
+
+	"github.com/google/uuid"
+)
+
+// Đơn đặt thay khách (CSKH nhận đơn qua điện thoại)
+const (
+	// PaymentMethodPayLink - checkout thay khách, khách tự thanh toán qua link gửi email
+	PaymentMethodPayLink = "pay_link"
+
+	// Key trong carts.promo_metadata khi admin override điều kiện promo
+	// Order service đọc key này để không validate lại (min order, lượt dùng, hạn...)
+	PromoMetadataOverride       = "override"
+	PromoMetadataOverriddenBy   = "overridden_by"
+	PromoMetadataOverrideReason = "override_reason"
+)
+
+// PayLinkPolicy cấu hình link thanh toán gửi khách
+type PayLinkPolicy struct {
+	// Trang thanh toán của frontend, link = BaseURL/{order_number}/pay
+	BaseURL string
+	// Thời hạn giữ hàng chờ khách thanh toán, quá hạn đơn bị huỷ + trả tồn
+	TTL time.Duration
+}
+
+// URL link thanh toán của đơn
+func (p PayLinkPolicy) URL(orderNumber string) string {
+	return strings.TrimRight(p.BaseURL, "/") + "/" + orderNumber + "/pay"
+}
+
+// AdminApplyPromoRequest - POST /admin/customers/:user_id/cart/promo
+type AdminApplyPromoRequest struct {
+	PromoCode string `json:"promo_code" binding:"required,min=3,max=50"`
+	// Override bỏ qua điều kiện promo (min order, lượt dùng, hết hạn, first order) - chỉ role admin
+	Override bool `json:"override"`
+}
+
+// AssistedCheckoutRequest - POST /admin/customers/:user_id/cart/checkout
+type AssistedCheckoutRequest struct {
+	ShippingAddressID uuid.UUID                        `json:"shipping_address_id" binding:"required"`
+	PaymentMethod     string                           `json:"payment_method" binding:"required,oneof=pay_link cash_on_delivery"`
+	ShippingMethod    string                           `json:"shipping_method" binding:"required,oneof=standard express overnight"`
+	Packaging         *orderModel.PackagingPreferences `json:"packaging,omitempty"`
+	CustomerNotes     *string                          `json:"customer_notes,omitempty" binding:"omitempty,max=500"`
+}
+
+// ToCheckoutRequest chuyển sang request checkout thường, gắn nhân viên đặt thay
+func (r AssistedCheckoutRequest) ToCheckoutRequest(staffID uuid.UUID) CheckoutRequest {
+	return CheckoutRequest{
+		ShippingAddressID: r.ShippingAddressID,
+		PaymentMethod:     r.PaymentMethod,
+		ShippingMethod:    r.ShippingMethod,
+		Packaging:         r.Packaging,
+		CustomerNotes:     r.CustomerNotes,
+		PlacedBy:          &staffID,
+	}
+}
+
+// SendPaymentLinkPayload gửi link thanh toán cho khách sau khi nhân viên đặt đơn thay
+type SendPaymentLinkPayload struct {
+	OrderID     uuid.UUID `json:"order_id"`
+	OrderNumber string    `json:"order_number"`
+	UserID      uuid.UUID `json:"user_id"`
+	UserEmail   string    `json:"user_email" pii:"email"`
+	Locale      string    `json:"locale"`
+	Total       string    `json:"total"`
+	PaymentURL  string    `json:"payment_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"time"
 
+	bookModel "bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/shared/stockdisplay"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -19,6 +22,18 @@ type UpdateCartItemRequest struct {
 	Quantity int `json:"quantity" validate:"required,gte=1,lte=100"`
 }
 
+// BulkUpdateCartItemsRequest - nhiều thay đổi quantity/xoá item trong 1 request
+// Frontend gom các click của quantity stepper rồi gửi 1 lần, thay vì 1 request/click
+type BulkUpdateCartItemsRequest struct {
+	Items []BulkCartItemUpdate `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkCartItemUpdate - quantity = 0 nghĩa là xoá item khỏi cart
+type BulkCartItemUpdate struct {
+	ItemID   uuid.UUID `json:"item_id" binding:"required"`
+	Quantity int       `json:"quantity" binding:"gte=0,lte=100"`
+}
+
 // CartResponse represents the full cart response with items
 type CartResponse struct {
 	ID         uuid.UUID          `json:"id"`
@@ -27,6 +42,8 @@ type CartResponse struct {
 	Items      []CartItemResponse `json:"items"`
 	ItemsCount int                `json:"items_count"`
 	Subtotal   decimal.Decimal    `json:"subtotal"`
+	// Tổng tiết kiệm nhờ giá theo số lượng của các item trong response
+	TierSavings decimal.Decimal `json:"tier_savings"`
 
 	// Promo information (if applied)
 	PromoCode      *string                `json:"promo_code,omitempty"`
@@ -64,12 +81,28 @@ type CartItemResponse struct {
 	CurrentPrice   decimal.Decimal `json:"current_price"` // Current book price (may differ from snapshot)
 	IsAvailable    bool            `json:"is_available"`
 	AvailableStock int             `json:"available_stock"`
+	StockStatus    string          `json:"stock_status,omitempty"`
+	StockMessage   string          `json:"stock_message,omitempty"`
 	IsActive       bool            `json:"is_active"`
+	IsDiscontinued bool            `json:"is_discontinued"` // ngừng bán: xoá khỏi giỏ / đổi sang sách thay thế
 	CreatedAt      time.Time       `json:"created_at"`
 	TotalStock     int             `json:"total_stock"`
 	UpdatedAt      time.Time       `json:"updated_at"`
 	CategoryName   *string         `json:"category_name"`
 	CategoryID     *uuid.UUID      `json:"category_id"`
+
+	// Giá theo số lượng: Price đã áp bậc, Savings = (CurrentPrice - đơn giá bậc) * Quantity
+	TierMinQuantity     *int                 `json:"tier_min_quantity,omitempty"`
+	TierDiscountPercent *decimal.Decimal     `json:"tier_discount_percent,omitempty"`
+	Savings             decimal.Decimal      `json:"savings"`
+	NextTier            *bookModel.PriceTier `json:"next_tier,omitempty"` // mua thêm để lên bậc kế tiếp
+
+	// Dòng quà tặng kèm đơn (price = 0, không sửa/xoá được, tự gỡ khi giỏ hết đủ điều kiện)
+	IsGift          bool       `json:"is_gift"`
+	GiftPromotionID *uuid.UUID `json:"gift_promotion_id,omitempty"`
+
+	// Dòng đang hưởng giá giữ từ wishlist: Price là giá lúc thêm wishlist tới thời điểm này
+	PriceGuaranteedUntil *time.Time `json:"price_guaranteed_until,omitempty"`
 }
 
 // CartItemWithBook is used for query with JOIN
@@ -85,6 +118,29 @@ type CartItemWithBook struct {
 	TotalStock     int             `db:"total_stock"`
 	CategoryName   *string         `db:"category_name"`
 	CategoryID     *uuid.UUID      `db:"category_id"`
+	Discontinued   bool            `db:"discontinued"` // sách đã ngừng bán (archived) hoặc gộp vào sách khác
+}
+
+// CheckoutCartItem lean projection cho validate/checkout/merge
+// Chỉ các field cần để kiểm tra giá + tồn kho (không join authors/categories, không window count)
+type CheckoutCartItem struct {
+	ID           uuid.UUID       `db:"id"`
+	BookID       uuid.UUID       `db:"book_id"`
+	Quantity     int             `db:"quantity"`
+	Price        decimal.Decimal `db:"price"`
+	BookTitle    string          `db:"book_title"`
+	CurrentPrice decimal.Decimal `db:"current_price"`
+	IsActive     bool            `db:"is_active"`
+	TotalStock   int             `db:"total_stock"`
+
+	GiftPromotionID *uuid.UUID `db:"gift_promotion_id"` // NOT NULL = dòng quà 0đ
+	Discontinued    bool       `db:"discontinued"`      // sách đã ngừng bán (archived) hoặc gộp vào sách khác
+	CategoryID      *uuid.UUID `db:"category_id"`       // chọn rule thuế theo danh mục
+}
+
+// IsGift dòng quà tặng kèm đơn
+func (ci *CheckoutCartItem) IsGift() bool {
+	return ci.GiftPromotionID != nil
 }
 
 // ToResponse converts Cart to CartResponse
@@ -106,15 +162,15 @@ func (c *Cart) ToResponse(items []CartItemResponse) *CartResponse {
 	}
 }
 
-// ToItemResponse converts CartItemWithBook to CartItemResponse
-func (ci *CartItemWithBook) ToItemResponse() *CartItemResponse {
+// ToItemResponse converts CartItemWithBook to CartItemResponse (stock đã áp display policy)
+func (ci *CartItemWithBook) ToItemResponse(stockPolicy stockdisplay.Policy) *CartItemResponse {
 	subtotal := ci.Price.Mul(decimal.NewFromInt(int64(ci.Quantity)))
 	availableStock := ci.TotalStock
 	if availableStock < 0 {
 		availableStock = 0
 	}
 
-	return &CartItemResponse{
+	resp := &CartItemResponse{
 		ID:             ci.ID,
 		BookID:         ci.BookID,
 		Quantity:       ci.Quantity,
@@ -132,7 +188,13 @@ func (ci *CartItemWithBook) ToItemResponse() *CartItemResponse {
 		UpdatedAt:      ci.UpdatedAt,
 		CategoryName:   ci.CategoryName,
 		CategoryID:     ci.CategoryID,
+		IsDiscontinued: ci.Discontinued,
+
+		IsGift:          ci.IsGift(),
+		GiftPromotionID: ci.GiftPromotionID,
 	}
+	resp.ApplyStockPolicy(stockPolicy)
+	return resp
 }
 
 // IsExpired checks if cart has expired
@@ -150,9 +212,9 @@ func (c *Cart) IsGuest() bool {
 	return c.SessionID != nil
 }
 
-// ExtendExpiration extends cart expiration by 30 days (for authenticated users)
-func (c *Cart) ExtendExpiration() {
-	c.ExpiresAt = time.Now().Add(30 * 24 * time.Hour)
+// ExtendExpiration extends cart expiration by the policy TTL for its owner type
+func (c *Cart) ExtendExpiration(policy CartExpirationPolicy) {
+	c.ExpiresAt = policy.ExpiresAt(c.UserID, time.Now())
 }
 
 // Validate validates cart data
@@ -206,6 +268,37 @@ func (cir *CartItemResponse) PriceChangeAmount() decimal.Decimal {
 	return cir.CurrentPrice.Sub(cir.Price)
 }
 
+// ApplyStockPolicy che số tồn chính xác (available_stock/total_stock) trước khi trả cho khách
+// Cap không thấp hơn quantity đang có trong cart: khách đã biết có ít nhất ngần ấy,
+// tránh UI báo "không đủ hàng" chỉ vì số bị cap
+func (cir *CartItemResponse) ApplyStockPolicy(policy stockdisplay.Policy) {
+	available := cir.AvailableStock
+	if cir.TotalStock > available {
+		available = cir.TotalStock
+	}
+
+	stock := policy.Apply(available)
+	visible := stock.Quantity
+	if visible < cir.Quantity && available >= cir.Quantity {
+		visible = cir.Quantity
+	}
+
+	cir.StockStatus, cir.StockMessage = stock.Status, stock.Message
+	cir.AvailableStock = min(cir.AvailableStock, visible)
+	cir.TotalStock = min(cir.TotalStock, visible)
+}
+
+// ApplyPriceTiers gắn bậc giá đang áp dụng (theo giá hiện tại + quantity) và bậc kế tiếp
+func (cir *CartItemResponse) ApplyPriceTiers(tiers bookModel.PriceTiers) {
+	unitPrice, tier := tiers.Apply(cir.CurrentPrice, cir.Quantity)
+	if tier != nil {
+		cir.TierMinQuantity = &tier.MinQuantity
+		cir.TierDiscountPercent = &tier.DiscountPercent
+		cir.Savings = cir.CurrentPrice.Sub(unitPrice).Mul(decimal.NewFromInt(int64(cir.Quantity)))
+	}
+	cir.NextTier = tiers.Next(cir.Quantity)
+}
+
 // IsStockSufficient checks if available stock is sufficient for quantity
 func (cir *CartItemResponse) IsStockSufficient() bool {
 	return cir.IsAvailable && cir.AvailableStock >= cir.Quantity
@@ -224,4 +317,11 @@ var (
 	ErrCartItemNotFound  = errors.New("cart item not found")
 	ErrInsufficientStock = errors.New("insufficient stock available")
 	ErrBookNotAvailable  = errors.New("book is not available")
+	ErrBulkDuplicateItem = errors.New("duplicate item_id in bulk update")
+	ErrBulkTooManyItems  = errors.New("too many items in bulk update")
+
+	ErrGiftItemNotEditable = errors.New("gift items are managed automatically and cannot be changed")
+
+	ErrRestoreOrderNotCancelled = errors.New("only cancelled orders can be restored to cart")
+	ErrRestoreWindowExpired     = errors.New("order was cancelled too long ago to restore")
 )
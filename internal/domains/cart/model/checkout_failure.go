@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// CHECKOUT FAILURE DIAGNOSTICS
+// ========================================
+// CSKH tra "vì sao khách không checkout được": lưu nguyên CheckoutResponse của lần thất bại
+
+// CheckoutFailureRetention thời gian giữ bản ghi, job cart:cleanup_checkout_failures xoá phần cũ hơn
+const CheckoutFailureRetention = 30 * 24 * time.Hour
+
+// CheckoutFailure represents checkout_failures table
+type CheckoutFailure struct {
+	ID            uuid.UUID         `json:"id"`
+	UserID        uuid.UUID         `json:"user_id"`
+	CartID        uuid.UUID         `json:"cart_id"`
+	FailedPhase   *string           `json:"failed_phase,omitempty"` // phase failed cuối cùng, nil = lỗi trước phase đầu (giỏ, user)
+	ErrorCodes    []string          `json:"error_codes"`
+	PaymentMethod *string           `json:"payment_method,omitempty"`
+	PlacedBy      *uuid.UUID        `json:"placed_by,omitempty"`
+	Response      *CheckoutResponse `json:"response,omitempty"`
+	ErrorMessage  *string           `json:"error_message,omitempty"` // lỗi hạ tầng thay vì response (breaker mở...)
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// NewCheckoutFailure build bản ghi từ kết quả checkout (response failed hoặc err != nil)
+func NewCheckoutFailure(userID, cartID uuid.UUID, req CheckoutRequest, resp *CheckoutResponse, err error) *CheckoutFailure {
+	f := &CheckoutFailure{
+		ID:         uuid.New(),
+		UserID:     userID,
+		CartID:     cartID,
+		ErrorCodes: []string{},
+		PlacedBy:   req.PlacedBy,
+		Response:   resp,
+	}
+	if req.PaymentMethod != "" {
+		f.PaymentMethod = &req.PaymentMethod
+	}
+	if err != nil {
+		msg := err.Error()
+		f.ErrorMessage = &msg
+	}
+	if resp == nil {
+		return f
+	}
+
+	seen := make(map[string]bool)
+	addCode := func(code string) {
+		if code != "" && !seen[code] {
+			seen[code] = true
+			f.ErrorCodes = append(f.ErrorCodes, code)
+		}
+	}
+	for _, e := range resp.Errors {
+		addCode(e.Code)
+	}
+	for _, p := range resp.Phases {
+		if p.Status != "failed" {
+			continue
+		}
+		phase := p.Phase
+		f.FailedPhase = &phase
+		for _, e := range p.Errors {
+			addCode(e.Code)
+		}
+	}
+	return f
+}
+
+// ListCheckoutFailuresRequest - lọc theo khách / giỏ / phase, mới nhất trước
+type ListCheckoutFailuresRequest struct {
+	UserID *uuid.UUID `form:"user_id"`
+	CartID *uuid.UUID `form:"cart_id"`
+	Phase  string     `form:"phase"`
+	From   *time.Time `form:"from" time_format:"2006-01-02"`
+	To     *time.Time `form:"to" time_format:"2006-01-02"`
+	Page   int        `form:"page"`
+	Limit  int        `form:"limit"`
+}
+
+type ListCheckoutFailuresResponse struct {
+	Items      []CheckoutFailure `json:"items"`
+	TotalItems int               `json:"total_items"`
+	TotalPages int               `json:"total_pages"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+}
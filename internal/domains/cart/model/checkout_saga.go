@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// CHECKOUT SAGA
+// ========================================
+// Checkout đi qua ORDER_CREATION = 1 saga: order id đặt trước, tạo đơn (giữ tồn + promotion + xoá giỏ
+// trong 1 transaction). Tạo đơn lỗi → bù trừ theo thứ tự ngược: huỷ đơn (trả tồn, hoàn promotion)
+// rồi khôi phục giỏ. Saga kẹt (process chết) / bù trừ lỗi → job cart:reconcile_checkouts xử lý lại.
+
+const (
+	CheckoutSagaStarted            = "started"
+	CheckoutSagaCompleted          = "completed"
+	CheckoutSagaCompensated        = "compensated"
+	CheckoutSagaCompensationFailed = "compensation_failed"
+)
+
+// Bước của saga (ghi vào checkout_sagas.step)
+const (
+	CheckoutSagaStepOrderCreation = "ORDER_CREATION"
+	CheckoutSagaStepCancelOrder   = "CANCEL_ORDER"
+	CheckoutSagaStepRestoreCart   = "RESTORE_CART"
+)
+
+// CheckoutSagaRetention thời gian giữ saga đã xong, job đối soát xoá phần cũ hơn
+const CheckoutSagaRetention = 7 * 24 * time.Hour
+
+// CheckoutSaga represents checkout_sagas table
+type CheckoutSaga struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	CartID      uuid.UUID
+	OrderID     uuid.UUID // đặt trước, truyền vào CreateOrderRequest.OrderID
+	Status      string
+	Step        string
+	Attempts    int
+	LastError   *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+
+	// OrderExists đơn đặt trước đã được tạo (transaction tạo đơn đã commit), chỉ có khi list đối soát
+	OrderExists bool
+}
+
+// NewCheckoutSaga saga mới cho 1 lần checkout
+func NewCheckoutSaga(userID, cartID uuid.UUID) *CheckoutSaga {
+	return &CheckoutSaga{
+		ID:      uuid.New(),
+		UserID:  userID,
+		CartID:  cartID,
+		OrderID: uuid.New(),
+		Status:  CheckoutSagaStarted,
+		Step:    CheckoutSagaStepOrderCreation,
+	}
+}
+
+// CheckoutSagaPolicy cấu hình đối soát checkout
+type CheckoutSagaPolicy struct {
+	// Saga 'started' lâu hơn StaleAfter coi như process đã chết giữa chừng (phải > timeout tạo đơn)
+	StaleAfter time.Duration
+	// Đơn chưa thanh toán quá hạn + HoldGrace mà còn giữ hàng → task auto-release bị mất, job release
+	HoldGrace time.Duration
+	// Số lần bù trừ tối đa, quá thì để admin xử lý tay (last_error)
+	MaxAttempts int
+}
+
+// CheckoutReconcileResult kết quả 1 lần chạy job đối soát
+type CheckoutReconcileResult struct {
+	Compensated   int
+	Failed        int
+	HoldsReleased int
+	Pruned        int64
+}
@@ -5,11 +5,19 @@ const (
 	// MaxItemsPerProduct is the maximum quantity allowed for a single product in cart
 	MaxItemsPerProduct = 100
 
-	// DefaultCartExpirationDays is the default number of days before a cart expires
+	// DefaultCartExpirationDays - TTL mặc định giỏ khách (CART_GUEST_TTL_DAYS không hợp lệ)
 	DefaultCartExpirationDays = 30
+	// DefaultUserCartExpirationDays - TTL mặc định giỏ user (CART_USER_TTL_DAYS không hợp lệ)
+	DefaultUserCartExpirationDays = 90
 
 	// CartCacheExpirationMinutes is how long to cache cart data
 	CartCacheExpirationMinutes = 5
+
+	// MaxBulkItemUpdates is the maximum number of item operations in one bulk request
+	MaxBulkItemUpdates = 100
+
+	// RestoreCartWindowHours - order huỷ trong khoảng này mới được khôi phục items về cart
+	RestoreCartWindowHours = 24
 )
 
 // Pagination defaults
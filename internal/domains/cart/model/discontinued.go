@@ -0,0 +1,39 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	bookModel "bookstore-backend/internal/domains/book/model"
+
+	"github.com/google/uuid"
+)
+
+// ErrItemDiscontinued sách trong giỏ đã ngừng bán (thay cho "book is not available" chung chung)
+var ErrItemDiscontinued = errors.New("item discontinued")
+
+// ItemDiscontinuedError mang gợi ý thay thế để client đổi sách ngay trong giỏ
+type ItemDiscontinuedError struct {
+	BookID      uuid.UUID
+	Replacement *bookModel.ReplacementSuggestion
+}
+
+func (e *ItemDiscontinuedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrItemDiscontinued, e.BookID)
+}
+
+func (e *ItemDiscontinuedError) Unwrap() error {
+	return ErrItemDiscontinued
+}
+
+// Detail payload cho response lỗi (code ITEM_DISCONTINUED)
+func (e *ItemDiscontinuedError) Detail() map[string]interface{} {
+	detail := map[string]interface{}{
+		"code":    ErrCheckoutItemDiscontinued,
+		"book_id": e.BookID,
+	}
+	if e.Replacement != nil {
+		detail["replacement"] = e.Replacement
+	}
+	return detail
+}
@@ -3,6 +3,10 @@ package model
 import (
 	"time"
 
+	bookModel "bookstore-backend/internal/domains/book/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	taxModel "bookstore-backend/internal/domains/tax/model"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -22,6 +26,7 @@ type CartValidationResult struct {
 	TotalValue      decimal.Decimal         `json:"total_value"`
 	EstimatedTotal  decimal.Decimal         `json:"estimated_total"`
 	SnapshotTotal   decimal.Decimal         `json:"snapshot_total"`
+	TierSavings     decimal.Decimal         `json:"tier_savings"` // tiết kiệm nhờ giá theo số lượng (theo giá hiện tại)
 }
 type ClearCartResponse struct {
 	DeletedCount int    `json:"deleted_count"`
@@ -42,19 +47,59 @@ type CartValidationWarning struct {
 	Details map[string]interface{} `json:"details,omitempty"`
 }
 
+// OrderToCartResponse - kết quả copy items từ order về cart (restore sau khi huỷ / reorder vào cart)
+// Warnings: giá thay đổi, giảm quantity do tồn kho, item bị bỏ qua (hết hàng/ngừng bán)
+type OrderToCartResponse struct {
+	OrderID      uuid.UUID               `json:"order_id"`
+	AddedItems   int                     `json:"added_items"`
+	SkippedItems int                     `json:"skipped_items"`
+	Warnings     []CartValidationWarning `json:"warnings"`
+	Cart         *CartResponse           `json:"cart"`
+}
+
 // ItemValidation represents individual item validation
 type ItemValidation struct {
 	ItemID           uuid.UUID       `json:"item_id"`
 	BookID           uuid.UUID       `json:"book_id"`
 	BookTitle        string          `json:"book_title"`
 	SnapshotPrice    decimal.Decimal `json:"snapshot_price"`    // Price when added
-	CurrentPrice     decimal.Decimal `json:"current_price"`     // Current price
+	CurrentPrice     decimal.Decimal `json:"current_price"`     // Current price (đã áp bậc giá theo quantity)
+	ListPrice        decimal.Decimal `json:"list_price"`        // Giá bìa hiện tại
 	SnapshotQuantity int             `json:"snapshot_quantity"` // Qty in cart
 	AvailableStock   int             `json:"available_stock"`   // Stock now
 	IsAvailable      bool            `json:"is_available"`
 	PriceMatch       bool            `json:"price_match"` // snapshot == current
 	StockSufficient  bool            `json:"stock_sufficient"`
 	Warnings         []string        `json:"warnings,omitempty"`
+
+	TierDiscountPercent *decimal.Decimal `json:"tier_discount_percent,omitempty"`
+	Savings             decimal.Decimal  `json:"savings"`
+
+	IsGift bool `json:"is_gift,omitempty"` // dòng quà 0đ: không kiểm tra giá
+
+	// ITEM_DISCONTINUED: sách ngừng bán, Replacement = gợi ý thay thế (nếu có)
+	Code        string                           `json:"code,omitempty"`
+	Replacement *bookModel.ReplacementSuggestion `json:"replacement,omitempty"`
+}
+
+// CartProgressResponse - tiến độ đạt đơn tối thiểu / miễn phí ship (GET /cart/progress)
+// Tính trên subtotal của cart (đã áp giá theo số lượng, trước promo)
+type CartProgressResponse struct {
+	Subtotal decimal.Decimal `json:"subtotal"`
+
+	MinOrderAmount  decimal.Decimal `json:"min_order_amount"` // 0 = không giới hạn
+	MeetsMinimum    bool            `json:"meets_minimum"`
+	AmountToMinimum decimal.Decimal `json:"amount_to_minimum"`
+
+	ShippingFee           decimal.Decimal  `json:"shipping_fee"` // Phí ship với subtotal hiện tại
+	FreeShippingThreshold decimal.Decimal  `json:"free_shipping_threshold"`
+	FreeShipping          bool             `json:"free_shipping"`
+	AmountToFreeShipping  *decimal.Decimal `json:"amount_to_free_shipping,omitempty"` // nil = không có freeship theo ngưỡng
+	FreeShippingProgress  int              `json:"free_shipping_progress"`            // 0-100, cho progress bar
+	ShippingDiscount      decimal.Decimal  `json:"shipping_discount"`                 // Giảm ship theo bậc tự động (chưa tính mã)
+
+	// Message hiển thị: ưu tiên đơn tối thiểu, sau đó freeship ("Add 50,000 VND more for free shipping")
+	Message string `json:"message,omitempty"`
 }
 
 // ApplyPromoRequest represents request to apply promo code
@@ -69,11 +114,34 @@ type ApplyPromoResponse struct {
 	PromoDescription string          `json:"promo_description"`
 	DiscountType     string          `json:"discount_type"` // "percent" or "fixed"
 	DiscountValue    decimal.Decimal `json:"discount_value"`
-	DiscountAmount   decimal.Decimal `json:"discount_amount"` // Actual discount in VND
+	DiscountAmount   decimal.Decimal `json:"discount_amount"`   // Actual discount in VND
+	ShippingDiscount decimal.Decimal `json:"shipping_discount"` // Mã giảm ship: số tiền giảm trên phí ship hiện tại
 	OriginalSubtotal decimal.Decimal `json:"original_subtotal"`
 	DiscountedTotal  decimal.Decimal `json:"discounted_total"`
 	ExpiresAt        *time.Time      `json:"expires_at,omitempty"`
 	AppliedAt        time.Time       `json:"applied_at"`
+	// Admin override điều kiện promo (đơn đặt thay khách)
+	Overridden     bool   `json:"overridden,omitempty"`
+	OverrideReason string `json:"override_reason,omitempty"`
+	// Tập promotion thực sự được áp: mã vừa nhập + promotion tự động cộng dồn theo luật stacking
+	AppliedPromotions []AppliedPromotion `json:"applied_promotions"`
+}
+
+// PromoMetadataApplied key trong carts.promo_metadata chứa []AppliedPromotion
+const PromoMetadataApplied = "applied_promotions"
+
+// AppliedPromotion 1 promotion trong tập được áp lên giỏ
+type AppliedPromotion struct {
+	PromotionID      uuid.UUID        `json:"promotion_id"`
+	Code             string           `json:"code"`
+	Name             string           `json:"name"`
+	Automatic        bool             `json:"automatic"`
+	Stackable        bool             `json:"stackable"`
+	DiscountType     string           `json:"discount_type"`
+	DiscountValue    decimal.Decimal  `json:"discount_value"`
+	MaxDiscount      *decimal.Decimal `json:"max_discount,omitempty"`
+	DiscountAmount   decimal.Decimal  `json:"discount_amount"`   // giảm tiền hàng
+	ShippingDiscount decimal.Decimal  `json:"shipping_discount"` // giảm phí ship (theo phí ship hiện tại)
 }
 
 // Cart promo fields (add to Cart model)
@@ -104,13 +172,28 @@ type CheckoutRequest struct {
 	ShippingMethod string     `json:"shipping_method" binding:"required,oneof=standard express overnight" validate:"required"`
 	DeliveryDate   *time.Time `json:"delivery_date,omitempty"` // Requested delivery date
 
+	// Gift - giao tới người nhận khác (shipping_address_id vẫn là địa chỉ người mua)
+	Gift *orderModel.GiftOptionsRequest `json:"gift,omitempty"`
+
+	// Packaging - đóng gói tối giản / không in hoá đơn giấy (nil = mặc định)
+	Packaging *orderModel.PackagingPreferences `json:"packaging,omitempty"`
+
 	// Additional
 	PromoCode     *string `json:"promo_code,omitempty"` // Re-validate promo
 	CustomerNotes *string `json:"customer_notes,omitempty" validate:"max=500"`
 
+	// Attribution - nguồn kênh (utm_*, affiliate_id, app_version...) lưu vào orders.metadata,
+	// item_metadata theo book_id lưu vào order_items.metadata
+	Metadata     orderModel.Metadata               `json:"metadata,omitempty"`
+	ItemMetadata map[uuid.UUID]orderModel.Metadata `json:"item_metadata,omitempty"`
+
+	// AffiliateCode - mã đối tác giới thiệu, rỗng → lấy từ ?aff= / cookie aff_code
+	AffiliateCode string `json:"affiliate_code,omitempty"`
+
 	// Internal use (set by system)
-	UserAgent string `json:"-"` // Track device type
-	IPAddress string `json:"-"` // Track location
+	UserAgent string     `json:"-"` // Track device type
+	IPAddress string     `json:"-"` // Track location
+	PlacedBy  *uuid.UUID `json:"-"` // Nhân viên đặt thay khách (admin cart), nil = khách tự checkout
 }
 
 // PaymentDetails represents payment method details
@@ -126,12 +209,13 @@ type PaymentDetails struct {
 
 // CheckoutPhaseResult represents each phase result
 type CheckoutPhaseResult struct {
-	Phase     string            `json:"phase"`  // "validation", "reservation", "order_creation", etc
-	Status    string            `json:"status"` // "success", "failed", "warning"
-	Message   string            `json:"message"`
-	Errors    []CheckoutError   `json:"errors,omitempty"`
-	Warnings  []CheckoutWarning `json:"warnings,omitempty"`
-	Timestamp time.Time         `json:"timestamp"`
+	Phase      string            `json:"phase"`  // "validation", "reservation", "order_creation", etc
+	Status     string            `json:"status"` // "success", "failed", "warning"
+	Message    string            `json:"message"`
+	Errors     []CheckoutError   `json:"errors,omitempty"`
+	Warnings   []CheckoutWarning `json:"warnings,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`   // lúc bắt đầu phase
+	DurationMs float64           `json:"duration_ms"` // thời gian chạy phase (export histogram checkout_phase_duration_seconds)
 }
 
 // CheckoutError represents checkout error
@@ -234,8 +318,11 @@ type PricingBreakdown struct {
 	VolumeDiscount decimal.Decimal `json:"volume_discount,omitempty"` // Bulk discount
 	ManualDiscount decimal.Decimal `json:"manual_discount,omitempty"` // Admin discount
 
+	// Shipping deduction (promo giảm ship / bậc tự động), trừ vào Shipping chứ không vào Subtotal
+	ShippingDiscount decimal.Decimal `json:"shipping_discount"`
+
 	// Additions
-	Tax       decimal.Decimal `json:"tax"`                 // VAT (10%)
+	Tax       decimal.Decimal `json:"tax"`                 // Tổng thuế các dòng (inclusive chỉ hiển thị, không cộng vào Total)
 	Shipping  decimal.Decimal `json:"shipping"`            // Delivery fee
 	Insurance decimal.Decimal `json:"insurance,omitempty"` // Optional
 
@@ -244,7 +331,10 @@ type PricingBreakdown struct {
 
 	// Additional info
 	Currency string          `json:"currency"` // "VND"
-	TaxRate  decimal.Decimal `json:"tax_rate"` // e.g., 0.10 for 10%
+	TaxRate  decimal.Decimal `json:"tax_rate"` // e.g., 0.10 for 10%; 0 khi giỏ có nhiều thuế suất (xem TaxLines)
+
+	// Dòng thuế theo rule (tỉnh giao hàng / danh mục sách)
+	TaxLines []taxModel.TaxLine `json:"tax_lines"`
 }
 
 // ItemCheckoutResult represents each item result
@@ -300,6 +390,11 @@ type PromotionValidationResult struct {
 	DiscountValue decimal.Decimal
 	MaxDiscount   *decimal.Decimal
 
+	// Stacking
+	IsAutomatic bool
+	Stackable   bool
+	Priority    int
+
 	// Constraints
 	MinOrderAmount        decimal.Decimal
 	ApplicableCategoryIDs []uuid.UUID
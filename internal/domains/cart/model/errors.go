@@ -18,6 +18,9 @@ const (
 	ErrCheckoutPartialStock      = "PARTIAL_STOCK"
 	ErrCheckoutOutOfStock        = "OUT_OF_STOCK"
 
+	// Catalog: sách ngừng bán (archived) / đã gộp vào sách khác
+	ErrCheckoutItemDiscontinued = "ITEM_DISCONTINUED"
+
 	// Price
 	ErrCheckoutPriceChanged = "PRICE_CHANGED"
 
@@ -42,4 +45,11 @@ const (
 var (
 	ErrItemNotFound        = errors.New("item not found")
 	ErrItemNotBelongToCart = errors.New("item does not belong to cart")
+
+	// Admin cart (đặt thay khách)
+	ErrCustomerNotFound    = errors.New("customer not found")
+	ErrPromoNotOverridable = errors.New("promo code cannot be overridden")
+
+	// Checkout failure diagnostics
+	ErrCheckoutFailureNotFound = errors.New("checkout failure not found")
 )
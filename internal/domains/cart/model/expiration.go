@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// CART EXPIRATION POLICY
+// =====================================================
+// - Giỏ khách (session) và giỏ user có TTL riêng, tính từ lần hoạt động gần nhất
+// - Mỗi lần khách mở giỏ → gia hạn, nhưng tối đa 1 lần / RenewInterval (GET giỏ liên tục không UPDATE carts mỗi request)
+// - Giỏ user còn hàng sắp hết hạn (trong WarnBefore) → gửi nhắc 1 lần; gia hạn xoá mốc đã nhắc
+
+// CartExpirationPolicy cấu hình hạn giỏ
+type CartExpirationPolicy struct {
+	GuestTTL time.Duration
+	UserTTL  time.Duration
+	// Khoảng tối thiểu giữa 2 lần gia hạn
+	RenewInterval time.Duration
+	// Nhắc trước khi hết hạn bao lâu (<= 0: tắt nhắc)
+	WarnBefore time.Duration
+}
+
+// TTLFor TTL theo loại giỏ (userID nil = giỏ khách)
+func (p CartExpirationPolicy) TTLFor(userID *uuid.UUID) time.Duration {
+	if userID != nil {
+		return p.UserTTL
+	}
+	return p.GuestTTL
+}
+
+// ExpiresAt hạn mới của giỏ nếu hoạt động tại now
+func (p CartExpirationPolicy) ExpiresAt(userID *uuid.UUID, now time.Time) time.Time {
+	return now.Add(p.TTLFor(userID))
+}
+
+// ShouldRenew true khi lần gia hạn trước đã cách now ít nhất RenewInterval
+// (hạn hiện tại = lần gia hạn trước + TTL)
+func (p CartExpirationPolicy) ShouldRenew(cart *Cart, now time.Time) bool {
+	return p.ExpiresAt(cart.UserID, now).Sub(cart.ExpiresAt) >= p.RenewInterval
+}
+
+// ExpiringCart - giỏ user còn hàng sắp hết hạn, chưa được nhắc
+type ExpiringCart struct {
+	CartID     uuid.UUID
+	UserID     uuid.UUID
+	ItemsCount int
+	Subtotal   decimal.Decimal
+	ExpiresAt  time.Time
+}
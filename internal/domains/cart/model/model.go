@@ -43,6 +43,14 @@ type CartItem struct {
 	Price     decimal.Decimal `json:"price" db:"price"` // Snapshot price at time of adding
 	CreatedAt time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Dòng quà tặng (price = 0) do hệ thống thêm theo gift_promotions, user không sửa/xoá được
+	GiftPromotionID *uuid.UUID `json:"gift_promotion_id,omitempty" db:"gift_promotion_id"`
+}
+
+// IsGift dòng quà tặng kèm đơn (không tính vào điều kiện / giảm giá của promotion)
+func (ci *CartItem) IsGift() bool {
+	return ci.GiftPromotionID != nil
 }
 
 // ReservedItem tracks inventory reservation for rollback
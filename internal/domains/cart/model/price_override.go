@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// WISHLIST PRICE GUARANTEE (cart_price_overrides)
+// =====================================================
+// Sách thêm vào wishlist trong Window, chuyển sang giỏ khi giá đã tăng → giỏ giữ giá lúc thêm wishlist trong HoldTTL.
+// - Server cấp override (giá lấy từ wishlist, không nhận giá từ client)
+// - Giá giỏ, ValidateCart và order service đều đọc override còn hiệu lực
+// - Hết hạn → dòng giỏ tính lại giá thường (checkout / xem giỏ)
+// - Chống lạm dụng: chỉ user đăng nhập, MaxPerUser lần / Period, MaxQuantity cuốn, mức giảm <= MaxDiscountPercent
+
+const (
+	PriceOverrideSourceWishlist = "wishlist"
+
+	PriceOverrideStatusActive   = "active"
+	PriceOverrideStatusConsumed = "consumed"
+	PriceOverrideStatusExpired  = "expired"
+)
+
+// WishlistPriceGuaranteePolicy cấu hình giữ giá wishlist (Enabled=false: tắt hẳn)
+type WishlistPriceGuaranteePolicy struct {
+	Enabled bool
+	// Sách phải được thêm vào wishlist trong khoảng này trước lúc chuyển sang giỏ
+	Window time.Duration
+	// Thời gian giỏ giữ giá kể từ lúc cấp
+	HoldTTL time.Duration
+	// Số lần được cấp / user trong Period
+	MaxPerUser int
+	Period     time.Duration
+	// Số cuốn tối đa hưởng giá giữ (dòng giỏ vượt → tính giá thường cho cả dòng)
+	MaxQuantity int
+	// Giá wishlist thấp hơn giá hiện tại quá % này → không cấp (giá sai lúc thêm wishlist)
+	MaxDiscountPercent int
+}
+
+// PriceOverride đơn giá server cấp cho 1 sách trong giỏ
+type PriceOverride struct {
+	ID          uuid.UUID       `json:"id"`
+	CartID      uuid.UUID       `json:"cart_id"`
+	UserID      uuid.UUID       `json:"user_id"`
+	BookID      uuid.UUID       `json:"book_id"`
+	Source      string          `json:"source"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	ListPrice   decimal.Decimal `json:"list_price"`
+	MaxQuantity int             `json:"max_quantity"`
+	Status      string          `json:"status"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	OrderID     *uuid.UUID      `json:"order_id,omitempty"`
+	ConsumedAt  *time.Time      `json:"consumed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// UnitPriceFor đơn giá áp cho dòng giỏ: giá giữ nếu còn hạn + quantity trong giới hạn,
+// bậc giá theo số lượng thấp hơn thì lấy bậc giá (không cộng dồn)
+func (o *PriceOverride) UnitPriceFor(tierPrice decimal.Decimal, quantity int, now time.Time) (decimal.Decimal, bool) {
+	if o == nil || o.Status != PriceOverrideStatusActive || !now.Before(o.ExpiresAt) || quantity > o.MaxQuantity {
+		return tierPrice, false
+	}
+	if tierPrice.LessThan(o.UnitPrice) {
+		return tierPrice, false
+	}
+	return o.UnitPrice, true
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -16,14 +18,22 @@ type SendOrderConfirmationPayload struct {
 	OrderID           uuid.UUID       `json:"order_id"`
 	OrderNumber       string          `json:"order_number"`
 	UserID            uuid.UUID       `json:"user_id"`
-	UserEmail         string          `json:"user_email"`
+	UserEmail         string          `json:"user_email" pii:"email"`
 	Total             decimal.Decimal `json:"total"`
 	PaymentMethod     string          `json:"payment_method"`
-	EstimatedDelivery string          `json:"estimated_delivery"`
 	ShippingAddressID uuid.UUID       `json:"shipping_address_id"`
 	OrderCreatedAt    string          `json:"order_created_at"` // RFC3339 format
+
+	// Dữ liệu trung lập, job format theo ngôn ngữ template ("3-5 ngày" / "3-5 days")
+	EstimatedDeliveryMinDays int    `json:"estimated_delivery_min_days"`
+	EstimatedDeliveryMaxDays int    `json:"estimated_delivery_max_days"`
+	Locale                   string `json:"locale"` // users.locale
 }
 
+// OnlinePaymentWindow thời gian giữ hàng chờ thanh toán online trước khi auto-release
+// (đơn pay_link giữ theo PayLinkPolicy.TTL)
+const OnlinePaymentWindow = 15 * time.Minute
+
 // AutoReleaseReservationPayload for auto-releasing inventory if payment not completed
 type AutoReleaseReservationPayload struct {
 	OrderID     uuid.UUID `json:"order_id"`
@@ -41,6 +51,8 @@ type TrackCheckoutPayload struct {
 	PaymentMethod string          `json:"payment_method"`
 	PromoCode     *string         `json:"promo_code,omitempty"`
 	Discount      decimal.Decimal `json:"discount"`
+	// Nguồn kênh của đơn (orders.metadata), chuyển thành properties của event
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // RemoveExpiredPromotionsPayload for scheduled job to remove expired promotions
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DeleteEmptyAnonymousCarts implements RepositoryInterface.DeleteEmptyAnonymousCarts
+// Xoá theo batch (job gọi lặp) để không giữ lock lâu trên carts khi bot đã tạo hàng trăm nghìn row
+func (r *postgresRepository) DeleteEmptyAnonymousCarts(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM carts
+		WHERE id IN (
+			SELECT c.id
+			FROM carts c
+			WHERE c.user_id IS NULL
+			  AND c.updated_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM cart_items ci WHERE ci.cart_id = c.id)
+			LIMIT $2
+		)
+	`
+
+	tag, err := r.pool.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete empty anonymous carts: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ========================================
+// CHECKOUT FAILURE DIAGNOSTICS
+// ========================================
+
+const checkoutFailureColumns = `
+	id, user_id, cart_id, failed_phase, error_codes, payment_method,
+	placed_by, response, error_message, created_at`
+
+// SaveCheckoutFailure implements RepositoryInterface.SaveCheckoutFailure
+func (r *postgresRepository) SaveCheckoutFailure(ctx context.Context, failure *model.CheckoutFailure) error {
+	var responseJSON []byte
+	if failure.Response != nil {
+		var err error
+		if responseJSON, err = json.Marshal(failure.Response); err != nil {
+			return fmt.Errorf("marshal checkout response: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO checkout_failures (
+			id, user_id, cart_id, failed_phase, error_codes, payment_method,
+			placed_by, response, error_message
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`
+	err := r.pool.QueryRow(ctx, query,
+		failure.ID,
+		failure.UserID,
+		failure.CartID,
+		failure.FailedPhase,
+		failure.ErrorCodes,
+		failure.PaymentMethod,
+		failure.PlacedBy,
+		responseJSON,
+		failure.ErrorMessage,
+	).Scan(&failure.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert checkout failure: %w", err)
+	}
+	return nil
+}
+
+// ListCheckoutFailures implements RepositoryInterface.ListCheckoutFailures
+func (r *postgresRepository) ListCheckoutFailures(ctx context.Context, req model.ListCheckoutFailuresRequest) ([]model.CheckoutFailure, int, error) {
+	conditions := []string{"1=1"}
+	args := []interface{}{}
+	addArg := func(cond string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if req.UserID != nil {
+		addArg("user_id = $%d", *req.UserID)
+	}
+	if req.CartID != nil {
+		addArg("cart_id = $%d", *req.CartID)
+	}
+	if req.Phase != "" {
+		addArg("failed_phase = $%d", req.Phase)
+	}
+	if req.From != nil {
+		addArg("created_at >= $%d", *req.From)
+	}
+	if req.To != nil {
+		// To là ngày (date), lấy hết ngày đó
+		addArg("created_at < $%d", req.To.AddDate(0, 0, 1))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM checkout_failures WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count checkout failures: %w", err)
+	}
+
+	args = append(args, req.Limit, (req.Page-1)*req.Limit)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM checkout_failures
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, checkoutFailureColumns, where, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list checkout failures: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.CheckoutFailure, 0)
+	for rows.Next() {
+		failure, err := scanCheckoutFailure(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, *failure)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate checkout failures: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// GetCheckoutFailure implements RepositoryInterface.GetCheckoutFailure
+func (r *postgresRepository) GetCheckoutFailure(ctx context.Context, id uuid.UUID) (*model.CheckoutFailure, error) {
+	query := `SELECT ` + checkoutFailureColumns + ` FROM checkout_failures WHERE id = $1`
+
+	failure, err := scanCheckoutFailure(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrCheckoutFailureNotFound
+		}
+		return nil, err
+	}
+	return failure, nil
+}
+
+// DeleteCheckoutFailuresBefore implements RepositoryInterface.DeleteCheckoutFailuresBefore
+func (r *postgresRepository) DeleteCheckoutFailuresBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM checkout_failures WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete checkout failures: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanCheckoutFailure(row pgx.Row) (*model.CheckoutFailure, error) {
+	var failure model.CheckoutFailure
+	var responseJSON []byte
+
+	err := row.Scan(
+		&failure.ID,
+		&failure.UserID,
+		&failure.CartID,
+		&failure.FailedPhase,
+		&failure.ErrorCodes,
+		&failure.PaymentMethod,
+		&failure.PlacedBy,
+		&responseJSON,
+		&failure.ErrorMessage,
+		&failure.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan checkout failure: %w", err)
+	}
+
+	if len(responseJSON) > 0 {
+		failure.Response = &model.CheckoutResponse{}
+		if err := json.Unmarshal(responseJSON, failure.Response); err != nil {
+			return nil, fmt.Errorf("unmarshal checkout response: %w", err)
+		}
+	}
+	return &failure, nil
+}
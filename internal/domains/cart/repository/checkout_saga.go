@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/database"
+)
+
+// ========================================
+// CHECKOUT SAGAS
+// ========================================
+
+// CreateCheckoutSaga implements RepositoryInterface.CreateCheckoutSaga
+func (r *postgresRepository) CreateCheckoutSaga(ctx context.Context, saga *model.CheckoutSaga) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO checkout_sagas (id, user_id, cart_id, order_id, status, step)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`, saga.ID, saga.UserID, saga.CartID, saga.OrderID, saga.Status, saga.Step,
+	).Scan(&saga.CreatedAt, &saga.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert checkout saga: %w", err)
+	}
+	return nil
+}
+
+// UpdateCheckoutSagaStatus implements RepositoryInterface.UpdateCheckoutSagaStatus
+// Trạng thái cuối (completed / compensated) ghi completed_at để job retention dọn
+func (r *postgresRepository) UpdateCheckoutSagaStatus(ctx context.Context, sagaID uuid.UUID, status, step string, lastError *string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE checkout_sagas
+		SET status = $2,
+			step = $3,
+			last_error = $4,
+			attempts = attempts + CASE WHEN $2 IN ($5, $6) THEN 1 ELSE 0 END,
+			completed_at = CASE WHEN $2 IN ($5, $7) THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $1
+	`, sagaID, status, step, lastError,
+		model.CheckoutSagaCompensated, model.CheckoutSagaCompensationFailed, model.CheckoutSagaCompleted)
+	if err != nil {
+		return fmt.Errorf("update checkout saga: %w", err)
+	}
+	return nil
+}
+
+// ListPendingCheckoutSagas implements RepositoryInterface.ListPendingCheckoutSagas
+func (r *postgresRepository) ListPendingCheckoutSagas(ctx context.Context, startedBefore time.Time, maxAttempts, limit int) ([]model.CheckoutSaga, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.id, s.user_id, s.cart_id, s.order_id, s.status, s.step, s.attempts, s.last_error,
+			s.created_at, s.updated_at, s.completed_at,
+			EXISTS (SELECT 1 FROM orders o WHERE o.id = s.order_id) AS order_exists
+		FROM checkout_sagas s
+		WHERE s.created_at < $1
+		  AND (s.status = $2 OR (s.status = $3 AND s.attempts < $4))
+		ORDER BY s.created_at
+		LIMIT $5
+	`, startedBefore, model.CheckoutSagaStarted, model.CheckoutSagaCompensationFailed, maxAttempts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query pending checkout sagas: %w", err)
+	}
+	defer rows.Close()
+
+	sagas := make([]model.CheckoutSaga, 0)
+	for rows.Next() {
+		var s model.CheckoutSaga
+		if err := rows.Scan(
+			&s.ID, &s.UserID, &s.CartID, &s.OrderID, &s.Status, &s.Step, &s.Attempts, &s.LastError,
+			&s.CreatedAt, &s.UpdatedAt, &s.CompletedAt, &s.OrderExists,
+		); err != nil {
+			return nil, fmt.Errorf("scan checkout saga: %w", err)
+		}
+		sagas = append(sagas, s)
+	}
+	return sagas, rows.Err()
+}
+
+// DeleteFinishedCheckoutSagas implements RepositoryInterface.DeleteFinishedCheckoutSagas
+func (r *postgresRepository) DeleteFinishedCheckoutSagas(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM checkout_sagas WHERE completed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete checkout sagas: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
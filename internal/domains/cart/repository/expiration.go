@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/database"
+)
+
+// GetExpiringCarts implements RepositoryInterface.GetExpiringCarts
+// Giỏ đã hết hạn không nhắc nữa (expires_at > NOW()); giỏ khách không có người nhận
+func (r *postgresRepository) GetExpiringCarts(ctx context.Context, before time.Time, limit int) ([]model.ExpiringCart, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, items_count, subtotal, expires_at
+		FROM carts
+		WHERE user_id IS NOT NULL
+		  AND expiry_warned_at IS NULL
+		  AND items_count > 0
+		  AND expires_at > NOW()
+		  AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query expiring carts: %w", err)
+	}
+	defer rows.Close()
+
+	carts := make([]model.ExpiringCart, 0)
+	for rows.Next() {
+		var c model.ExpiringCart
+		if err := rows.Scan(&c.CartID, &c.UserID, &c.ItemsCount, &c.Subtotal, &c.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scan expiring cart: %w", err)
+		}
+		carts = append(carts, c)
+	}
+	return carts, rows.Err()
+}
+
+// MarkExpiryWarned implements RepositoryInterface.MarkExpiryWarned
+func (r *postgresRepository) MarkExpiryWarned(ctx context.Context, cartID uuid.UUID, expiresAt time.Time) (bool, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE carts
+		SET expiry_warned_at = NOW()
+		WHERE id = $1 AND expires_at = $2 AND expiry_warned_at IS NULL
+	`, cartID, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("mark cart expiry warned: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"bookstore-backend/internal/domains/cart/model"
 	promo "bookstore-backend/internal/domains/promotion/model"
@@ -26,8 +27,8 @@ type RepositoryInterface interface {
 	// Create creates new cart
 	Create(ctx context.Context, cart *model.Cart) error
 	CreateOrGet(ctx context.Context, cart *model.Cart) (*model.Cart, error)
-	// UpdateExpiration extends cart expiration by 30 days
-	UpdateExpiration(ctx context.Context, cartID uuid.UUID) error
+	// UpdateExpiration gia hạn giỏ tới expiresAt (xoá mốc đã nhắc hết hạn)
+	UpdateExpiration(ctx context.Context, cartID uuid.UUID, expiresAt time.Time) error
 
 	// AddItem adds or updates item in cart
 	AddItem(ctx context.Context, item *model.CartItem) (*model.CartItem, error)
@@ -36,6 +37,9 @@ type RepositoryInterface interface {
 	// Returns: items, total_count, error
 	GetItemsWithBooks(ctx context.Context, cartID uuid.UUID, page int, limit int) ([]*model.CartItemWithBook, int, error)
 
+	// GetCheckoutItems retrieves lean item projection for validate/checkout/merge (all items)
+	GetCheckoutItems(ctx context.Context, cartID uuid.UUID) ([]*model.CheckoutCartItem, error)
+
 	// GetItemByID retrieves single cart item by ID
 	GetItemByID(ctx context.Context, itemID uuid.UUID) (*model.CartItem, error)
 
@@ -56,6 +60,7 @@ type RepositoryInterface interface {
 	// DeleteCart removes cart and all its items (CASCADE)
 	DeleteCart(ctx context.Context, cartID uuid.UUID) error
 	GetPromoByCode(ctx context.Context, code string) (*promo.Promotion, error)
+	ListAutomaticPromos(ctx context.Context) ([]*promo.Promotion, error)
 	CountUserUsage(ctx context.Context, promotionID uuid.UUID, userID uuid.UUID) (int, error)
 	UserHasCompletedOrders(ctx context.Context, userID uuid.UUID) (bool, error)
 	// TransferItem moves item from one cart to another
@@ -66,9 +71,14 @@ type RepositoryInterface interface {
 	// UpdateCartPromo updates cart with promo code and discount
 	GetCartAndItem(ctx context.Context, cartID uuid.UUID, itemID uuid.UUID) (*model.Cart, *model.CartItem, error)
 	// RemoveCartPromo removes promo from cart
-	GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error)
+	// GetUserContact trả về (email, locale) để gửi email xác nhận đơn đúng ngôn ngữ
+	GetUserContact(ctx context.Context, userID uuid.UUID) (string, string, error)
 	RemoveCartPromo(ctx context.Context, cartID uuid.UUID) error
 	GetItemWithBookByID(ctx context.Context, itemID uuid.UUID) (*model.CartItemWithBook, error)
+
+	// Gift-with-purchase: chương trình giỏ đang đủ điều kiện + upsert dòng quà 0đ
+	GetEligibleGiftPromotions(ctx context.Context, cartID uuid.UUID) ([]promo.GiftPromotion, error)
+	UpsertGiftItem(ctx context.Context, cartID uuid.UUID, gift promo.GiftPromotion) error
 	// Transaction-aware methods
 	BeginTx(ctx context.Context) (pgx.Tx, error)
 	CommitTx(ctx context.Context, tx pgx.Tx) error
@@ -79,6 +89,8 @@ type RepositoryInterface interface {
 	UpdateItemWithTx(ctx context.Context, tx pgx.Tx, item *model.CartItem) error
 	AddItemWithTx(ctx context.Context, tx pgx.Tx, item *model.CartItem) error
 	DeleteCartWithTx(ctx context.Context, tx pgx.Tx, cartID uuid.UUID) error
+	// DeleteItemsWithTx xoá nhiều item của 1 cart trong transaction (bulk update quantity = 0)
+	DeleteItemsWithTx(ctx context.Context, tx pgx.Tx, cartID uuid.UUID, itemIDs []uuid.UUID) (int, error)
 
 	// ================================================
 	// PROMOTION REMOVAL JOB METHODS
@@ -109,4 +121,52 @@ type RepositoryInterface interface {
 	// - Used to store last_checked_at timestamp for smart scheduling
 	// - Avoids race conditions with other cart updates
 	UpdatePromoMetadata(ctx context.Context, cartID uuid.UUID, metadata map[string]interface{}) error
+
+	// ================================================
+	// CHECKOUT FAILURE DIAGNOSTICS
+	// ================================================
+
+	// SaveCheckoutFailure lưu response của lần checkout thất bại (CSKH tra cứu)
+	SaveCheckoutFailure(ctx context.Context, failure *model.CheckoutFailure) error
+	ListCheckoutFailures(ctx context.Context, req model.ListCheckoutFailuresRequest) ([]model.CheckoutFailure, int, error)
+	GetCheckoutFailure(ctx context.Context, id uuid.UUID) (*model.CheckoutFailure, error)
+	// DeleteCheckoutFailuresBefore xoá bản ghi cũ hơn before (retention job), trả số dòng đã xoá
+	DeleteCheckoutFailuresBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// Anonymous cart cleanup
+	// DeleteEmptyAnonymousCarts xoá tối đa limit giỏ ẩn danh không có item, updated_at < before
+	DeleteEmptyAnonymousCarts(ctx context.Context, before time.Time, limit int) (int64, error)
+
+	// Cart expiry warnings
+	// GetExpiringCarts giỏ user còn hàng, hết hạn trước before, chưa nhắc
+	GetExpiringCarts(ctx context.Context, before time.Time, limit int) ([]model.ExpiringCart, error)
+	// MarkExpiryWarned nhận giỏ để nhắc; false nếu giỏ vừa được gia hạn (expires_at đổi) / đã có job khác nhắc
+	MarkExpiryWarned(ctx context.Context, cartID uuid.UUID, expiresAt time.Time) (bool, error)
+
+	// ================================================
+	// CHECKOUT SAGAS (bù trừ checkout lỗi)
+	// ================================================
+
+	CreateCheckoutSaga(ctx context.Context, saga *model.CheckoutSaga) error
+	// UpdateCheckoutSagaStatus chuyển trạng thái saga; compensation tăng attempts, lastError nil = xoá lỗi cũ
+	UpdateCheckoutSagaStatus(ctx context.Context, sagaID uuid.UUID, status, step string, lastError *string) error
+	// ListPendingCheckoutSagas saga 'started' tạo trước startedBefore + 'compensation_failed' còn lượt thử
+	ListPendingCheckoutSagas(ctx context.Context, startedBefore time.Time, maxAttempts, limit int) ([]model.CheckoutSaga, error)
+	// DeleteFinishedCheckoutSagas xoá saga đã xong trước before (retention), trả số dòng đã xoá
+	DeleteFinishedCheckoutSagas(ctx context.Context, before time.Time) (int64, error)
+
+	// ================================================
+	// PRICE OVERRIDES (giữ giá wishlist)
+	// ================================================
+
+	// CreatePriceOverride cấp giá giữ; đã có override active cho (cart, book) → trả override đó, created=false
+	CreatePriceOverride(ctx context.Context, override *model.PriceOverride) (*model.PriceOverride, bool, error)
+	// ListActivePriceOverrides override status=active của giỏ (có thể đã quá expires_at, caller tự kiểm)
+	ListActivePriceOverrides(ctx context.Context, cartID uuid.UUID) ([]model.PriceOverride, error)
+	// CountPriceOverridesSince số override user được cấp từ since (quota chống lạm dụng)
+	CountPriceOverridesSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	// ExpirePriceOverrides chuyển override quá hạn sang expired, trả book_id để tính lại giá dòng giỏ
+	ExpirePriceOverrides(ctx context.Context, cartID uuid.UUID, now time.Time) ([]uuid.UUID, error)
+	// ConsumePriceOverridesWithTx gắn override vào đơn vừa tạo (cùng transaction tạo đơn)
+	ConsumePriceOverridesWithTx(ctx context.Context, tx pgx.Tx, ids []uuid.UUID, orderID uuid.UUID) error
 }
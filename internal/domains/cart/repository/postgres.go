@@ -4,6 +4,7 @@ import (
 	"bookstore-backend/internal/domains/cart/model"
 	promo "bookstore-backend/internal/domains/promotion/model"
 	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/database"
 	"bookstore-backend/pkg/logger"
 	"context"
 	"errors"
@@ -30,6 +31,9 @@ func NewPostgresRepository(pool *pgxpool.Pool, cache cache.Cache) RepositoryInte
 
 // GetByUserID implements RepositoryInterface.GetByUserID
 func (r *postgresRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*model.Cart, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	// Check cache first
 	// cacheKey := fmt.Sprintf(model.CacheKeyCartByUser, userID.String())
 	// var cachedCart model.Cart
@@ -81,6 +85,9 @@ func (r *postgresRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 }
 
 func (r *postgresRepository) GetBySessionID(ctx context.Context, sessionID string) (*model.Cart, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	// Check cache first
 	cacheKey := fmt.Sprintf(model.CacheKeyCartBySession, sessionID)
 	var cachedCart model.Cart
@@ -130,6 +137,9 @@ func (r *postgresRepository) GetBySessionID(ctx context.Context, sessionID strin
 
 // Create implements RepositoryInterface.Create
 func (r *postgresRepository) Create(ctx context.Context, cart *model.Cart) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO carts (id, user_id, session_id, items_count, subtotal, version, created_at, updated_at, expires_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
@@ -155,15 +165,17 @@ func (r *postgresRepository) Create(ctx context.Context, cart *model.Cart) error
 }
 
 // UpdateExpiration implements RepositoryInterface.UpdateExpiration
-func (r *postgresRepository) UpdateExpiration(ctx context.Context, cartID uuid.UUID) error {
+func (r *postgresRepository) UpdateExpiration(ctx context.Context, cartID uuid.UUID, expiresAt time.Time) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
 
 	query := `
     UPDATE carts
-    SET expires_at = NOW() + INTERVAL '90 days', updated_at = NOW()
+    SET expires_at = $2, expiry_warned_at = NULL, updated_at = NOW()
     WHERE id = $1
   `
 
-	result, err := r.pool.Exec(ctx, query, cartID)
+	result, err := r.pool.Exec(ctx, query, cartID, expiresAt)
 
 	// ✅ Kiểm tra lỗi TRƯỚC khi dùng result
 	if err != nil {
@@ -185,6 +197,9 @@ func (r *postgresRepository) UpdateExpiration(ctx context.Context, cartID uuid.U
 
 // CreateOrGet với DO UPDATE (recommended)
 func (r *postgresRepository) CreateOrGet(ctx context.Context, cart *model.Cart) (*model.Cart, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	var conflictClause string
 	if cart.UserID != nil {
 		// Phải có CẢ 2 điều kiện giống index
@@ -256,10 +271,13 @@ func (r *postgresRepository) CreateOrGet(ctx context.Context, cart *model.Cart)
 // AddItem implements RepositoryInterface.AddItem
 // INSERT or UPDATE if item already exists
 func (r *postgresRepository) AddItem(ctx context.Context, item *model.CartItem) (*model.CartItem, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO cart_items (cart_id, book_id, quantity, price, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6)
-        ON CONFLICT (cart_id, book_id) DO UPDATE SET
+        ON CONFLICT (cart_id, book_id) WHERE gift_promotion_id IS NULL DO UPDATE SET
             quantity = EXCLUDED.quantity,
             price = EXCLUDED.price,
             updated_at = EXCLUDED.updated_at
@@ -291,47 +309,81 @@ func (r *postgresRepository) AddItem(ctx context.Context, item *model.CartItem)
 	return &result, nil
 }
 
-func (r *postgresRepository) GetItemsWithBooks(ctx context.Context, cartID uuid.UUID, page int, limit int) ([]*model.CartItemWithBook, int, error) {
-	// Handle fetch all case
-	var limitClause string
-
-	var args []interface{}
-
-	if limit > 0 && page > 0 {
-		offset := (page - 1) * limit
-		limitClause = fmt.Sprintf("LIMIT $%d OFFSET $%d", len(args)+2, len(args)+3)
-		args = append(args, cartID, limit, offset)
-	} else {
-		// Fetch all - no limit/offset
-		limitClause = ""
-
-		args = []interface{}{cartID}
-	}
-
-	// Single query with window function for count + optimized join
-	query := `
-        SELECT 
-            ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price, 
-            ci.created_at, ci.updated_at,
+// Cart item queries là hằng số (không ghép chuỗi động) để pgx cache prepared statement
+// theo đúng SQL text: mỗi connection chỉ parse/plan 1 lần (QueryExecModeCacheStatement).
+//
+// Tồn kho dùng LATERAL SUM trên warehouse_inventory thay vì join view books_total_stock:
+// view GROUP BY toàn bảng nên planner phải aggregate mọi book rồi mới join,
+// LATERAL chỉ đọc các row của book trong cart (index idx_inventory_book_covering).
+const (
+	cartItemsWithBooksSelect = `
+        SELECT
+            ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price,
+            ci.created_at, ci.updated_at, ci.gift_promotion_id,
             b.title, b.slug, b.cover_url,
             a.name as book_author,
             b.price as current_price,
             b.is_active,
-						b.compare_at_price,
-            COALESCE(bts.available, 0) as total_stock,
+            b.compare_at_price,
+            COALESCE(st.available, 0) as total_stock,
             c.name as category_name,
-						c.id as category_id,
-            COUNT(*) OVER() as total_count
+            c.id as category_id,
+            (b.archived_at IS NOT NULL OR b.deleted_at IS NOT NULL) as discontinued`
+
+	cartItemsWithBooksFrom = `
         FROM cart_items ci
         LEFT JOIN books b ON ci.book_id = b.id
         LEFT JOIN authors a ON b.author_id = a.id
-        LEFT JOIN books_total_stock bts ON b.id = bts.book_id
-				LEFT JOIN categories c ON b.category_id = c.id
+        LEFT JOIN LATERAL (
+            SELECT SUM(wi.quantity - wi.reserved) AS available
+            FROM warehouse_inventory wi
+            WHERE wi.book_id = ci.book_id
+        ) st ON TRUE
+        LEFT JOIN categories c ON b.category_id = c.id
+        WHERE ci.cart_id = $1
+        ORDER BY ci.created_at DESC`
+
+	// Paged: cần total_count cho pagination → window function
+	cartItemsWithBooksPagedQuery = cartItemsWithBooksSelect + `,
+            COUNT(*) OVER() as total_count` + cartItemsWithBooksFrom + `
+        LIMIT $2 OFFSET $3`
+
+	// Fetch all: total = len(items), bỏ COUNT(*) OVER() (tránh materialize toàn bộ result)
+	cartItemsWithBooksAllQuery = cartItemsWithBooksSelect + cartItemsWithBooksFrom
+
+	// Lean checkout: 1 join + lateral stock, chỉ field cần cho validate giá/tồn kho
+	cartCheckoutItemsQuery = `
+        SELECT
+            ci.id, ci.book_id, ci.quantity, ci.price,
+            b.title, b.price, COALESCE(b.is_active, false),
+            COALESCE(st.available, 0), ci.gift_promotion_id,
+            (b.archived_at IS NOT NULL OR b.deleted_at IS NOT NULL),
+            b.category_id
+        FROM cart_items ci
+        JOIN books b ON b.id = ci.book_id
+        LEFT JOIN LATERAL (
+            SELECT SUM(wi.quantity - wi.reserved) AS available
+            FROM warehouse_inventory wi
+            WHERE wi.book_id = ci.book_id
+        ) st ON TRUE
         WHERE ci.cart_id = $1
-        ORDER BY ci.created_at DESC
-        ` + limitClause
+        ORDER BY ci.created_at DESC`
+)
+
+func (r *postgresRepository) GetItemsWithBooks(ctx context.Context, cartID uuid.UUID, page int, limit int) ([]*model.CartItemWithBook, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	paged := limit > 0 && page > 0
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	var rows pgx.Rows
+	var err error
+	if paged {
+		offset := (page - 1) * limit
+		rows, err = r.pool.Query(ctx, cartItemsWithBooksPagedQuery, cartID, limit, offset)
+	} else {
+		rows, err = r.pool.Query(ctx, cartItemsWithBooksAllQuery, cartID)
+	}
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query items: %w", err)
 	}
@@ -342,7 +394,7 @@ func (r *postgresRepository) GetItemsWithBooks(ctx context.Context, cartID uuid.
 
 	for rows.Next() {
 		var item model.CartItemWithBook
-		err := rows.Scan(
+		dest := []interface{}{
 			&item.ID,
 			&item.CartID,
 			&item.BookID,
@@ -350,6 +402,7 @@ func (r *postgresRepository) GetItemsWithBooks(ctx context.Context, cartID uuid.
 			&item.Price,
 			&item.CreatedAt,
 			&item.UpdatedAt,
+			&item.GiftPromotionID,
 			&item.BookTitle,
 			&item.BookSlug,
 			&item.BookCoverURL,
@@ -360,9 +413,13 @@ func (r *postgresRepository) GetItemsWithBooks(ctx context.Context, cartID uuid.
 			&item.TotalStock,
 			&item.CategoryName,
 			&item.CategoryID,
-			&totalCount, // ✅ Scan total count from window function
-		)
-		if err != nil {
+			&item.Discontinued,
+		}
+		if paged {
+			dest = append(dest, &totalCount) // ✅ Scan total count from window function
+		}
+
+		if err := rows.Scan(dest...); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan item: %w", err)
 		}
 		items = append(items, &item)
@@ -372,13 +429,59 @@ func (r *postgresRepository) GetItemsWithBooks(ctx context.Context, cartID uuid.
 		return nil, 0, fmt.Errorf("error iterating items: %w", err)
 	}
 
+	if !paged {
+		totalCount = len(items)
+	}
+
 	return items, totalCount, nil
 }
 
+// GetCheckoutItems implements RepositoryInterface.GetCheckoutItems
+func (r *postgresRepository) GetCheckoutItems(ctx context.Context, cartID uuid.UUID) ([]*model.CheckoutCartItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, cartCheckoutItemsQuery, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checkout items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*model.CheckoutCartItem
+	for rows.Next() {
+		var item model.CheckoutCartItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.BookID,
+			&item.Quantity,
+			&item.Price,
+			&item.BookTitle,
+			&item.CurrentPrice,
+			&item.IsActive,
+			&item.TotalStock,
+			&item.GiftPromotionID,
+			&item.Discontinued,
+			&item.CategoryID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan checkout item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating checkout items: %w", err)
+	}
+
+	return items, nil
+}
+
 // GetItemByID implements RepositoryInterface.GetItemByID
 func (r *postgresRepository) GetItemByID(ctx context.Context, itemID uuid.UUID) (*model.CartItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, cart_id, book_id, quantity, price, created_at, updated_at
+		SELECT id, cart_id, book_id, quantity, price, created_at, updated_at, gift_promotion_id
 		FROM cart_items
 		WHERE id = $1
 	`
@@ -392,6 +495,7 @@ func (r *postgresRepository) GetItemByID(ctx context.Context, itemID uuid.UUID)
 		&item.Price,
 		&item.CreatedAt,
 		&item.UpdatedAt,
+		&item.GiftPromotionID,
 	)
 
 	if err != nil {
@@ -406,10 +510,13 @@ func (r *postgresRepository) GetItemByID(ctx context.Context, itemID uuid.UUID)
 
 // GetItemByBookInCart implements RepositoryInterface.GetItemByBookInCart
 func (r *postgresRepository) GetItemByBookInCart(ctx context.Context, cartID uuid.UUID, bookID uuid.UUID) (*model.CartItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT id, cart_id, book_id, quantity, price, created_at, updated_at
 		FROM cart_items
-		WHERE cart_id = $1 AND book_id = $2
+		WHERE cart_id = $1 AND book_id = $2 AND gift_promotion_id IS NULL
 	`
 
 	var item model.CartItem
@@ -435,6 +542,9 @@ func (r *postgresRepository) GetItemByBookInCart(ctx context.Context, cartID uui
 
 // DeleteExpiredCarts implements RepositoryInterface.DeleteExpiredCarts
 func (r *postgresRepository) DeleteExpiredCarts(ctx context.Context) (int, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM carts WHERE expires_at < NOW()`
 
 	result, err := r.pool.Exec(ctx, query)
@@ -449,6 +559,9 @@ func (r *postgresRepository) DeleteExpiredCarts(ctx context.Context) (int, error
 
 // UpdateItem implements RepositoryInterface.UpdateItem
 func (r *postgresRepository) UpdateItem(ctx context.Context, item *model.CartItem) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE cart_items
 		SET quantity = $2, price = $3, updated_at = $4
@@ -471,6 +584,9 @@ func (r *postgresRepository) UpdateItem(ctx context.Context, item *model.CartIte
 
 // DeleteItem implements RepositoryInterface.DeleteItem
 func (r *postgresRepository) DeleteItem(ctx context.Context, itemID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM cart_items WHERE id = $1`
 	result, err := r.pool.Exec(ctx, query, itemID)
 	if err != nil {
@@ -486,6 +602,9 @@ func (r *postgresRepository) DeleteItem(ctx context.Context, itemID uuid.UUID) e
 
 // DeleteCart implements RepositoryInterface.DeleteCart
 func (r *postgresRepository) DeleteCart(ctx context.Context, cartID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM carts WHERE id = $1`
 
 	_, err := r.pool.Exec(ctx, query, cartID)
@@ -498,6 +617,9 @@ func (r *postgresRepository) DeleteCart(ctx context.Context, cartID uuid.UUID) e
 
 // TransferItem implements RepositoryInterface.TransferItem
 func (r *postgresRepository) TransferItem(ctx context.Context, item *model.CartItem, targetCartID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE cart_items
 		SET cart_id = $2, updated_at = $3
@@ -517,6 +639,9 @@ func (r *postgresRepository) TransferItem(ctx context.Context, item *model.CartI
 	return nil
 }
 func (r *postgresRepository) ClearCartItems(ctx context.Context, cartID uuid.UUID) (int, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM cart_items WHERE cart_id = $1`
 
 	result, err := r.pool.Exec(ctx, query, cartID)
@@ -532,6 +657,9 @@ func (r *postgresRepository) ClearCartItems(ctx context.Context, cartID uuid.UUI
 // UpdateCartPromo implements RepositoryInterface.UpdateCartPromo
 // UpdateCartPromo updates cart with promo code and metadata
 func (r *postgresRepository) UpdateCartPromo(ctx context.Context, cartID uuid.UUID, version int, promoCode *string, discountAmount decimal.Decimal, metadata map[string]interface{}) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	// Convert metadata to JSONB
 	var metadataJSON interface{}
 	if metadata != nil {
@@ -563,6 +691,9 @@ func (r *postgresRepository) UpdateCartPromo(ctx context.Context, cartID uuid.UU
 
 // RemoveCartPromo implements RepositoryInterface.RemoveCartPromo
 func (r *postgresRepository) RemoveCartPromo(ctx context.Context, cartID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE carts
 		SET 
@@ -582,6 +713,9 @@ func (r *postgresRepository) RemoveCartPromo(ctx context.Context, cartID uuid.UU
 }
 
 func (r *postgresRepository) GetByID(ctx context.Context, cartID uuid.UUID) (*model.Cart, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT 
             id, user_id, session_id, items_count, subtotal, version,
@@ -637,15 +771,9 @@ func (r *postgresRepository) CommitTx(ctx context.Context, tx pgx.Tx) error {
 	return nil
 }
 
-// RollbackTx rolls back the transaction
+// RollbackTx rolls back the transaction (kể cả khi ctx đã cancel, bỏ qua ErrTxClosed)
 func (r *postgresRepository) RollbackTx(ctx context.Context, tx pgx.Tx) error {
-	if err := tx.Rollback(ctx); err != nil {
-		// Ignore "transaction already committed/rolled back" error
-		if !errors.Is(err, pgx.ErrTxClosed) {
-			return fmt.Errorf("failed to rollback transaction: %w", err)
-		}
-	}
-	return nil
+	return database.Rollback(ctx, tx)
 }
 
 // ==================== TRANSACTION-AWARE CART OPERATIONS ====================
@@ -747,7 +875,7 @@ func (r *postgresRepository) CreateOrGetWithTx(ctx context.Context, tx pgx.Tx, c
 func (r *postgresRepository) GetItemsByCartIDWithTx(ctx context.Context, tx pgx.Tx, cartID uuid.UUID) ([]model.CartItem, error) {
 	query := `
         SELECT 
-            id, cart_id, book_id, quantity, price, created_at, updated_at
+            id, cart_id, book_id, quantity, price, created_at, updated_at, gift_promotion_id
         FROM cart_items
         WHERE cart_id = $1
         FOR UPDATE -- Lock rows for transaction
@@ -770,6 +898,7 @@ func (r *postgresRepository) GetItemsByCartIDWithTx(ctx context.Context, tx pgx.
 			&item.Price,
 			&item.CreatedAt,
 			&item.UpdatedAt,
+			&item.GiftPromotionID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan cart item: %w", err)
@@ -786,9 +915,12 @@ func (r *postgresRepository) GetItemsByCartIDWithTx(ctx context.Context, tx pgx.
 
 // GetItemsByCartIDWithTx retrieves all items in a cart within transaction
 func (r *postgresRepository) GetItemsByCartID(ctx context.Context, cartID uuid.UUID) ([]model.CartItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT 
-            id, cart_id, book_id, quantity, price, created_at, updated_at
+            id, cart_id, book_id, quantity, price, created_at, updated_at, gift_promotion_id
         FROM cart_items
         WHERE cart_id = $1
     `
@@ -810,6 +942,7 @@ func (r *postgresRepository) GetItemsByCartID(ctx context.Context, cartID uuid.U
 			&item.Price,
 			&item.CreatedAt,
 			&item.UpdatedAt,
+			&item.GiftPromotionID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan cart item: %w", err)
@@ -824,6 +957,19 @@ func (r *postgresRepository) GetItemsByCartID(ctx context.Context, cartID uuid.U
 	return items, nil
 }
 
+// DeleteItemsWithTx deletes multiple cart items within transaction
+// cart_id trong WHERE đảm bảo không xoá nhầm item của cart khác
+func (r *postgresRepository) DeleteItemsWithTx(ctx context.Context, tx pgx.Tx, cartID uuid.UUID, itemIDs []uuid.UUID) (int, error) {
+	query := `DELETE FROM cart_items WHERE cart_id = $1 AND id = ANY($2)`
+
+	result, err := tx.Exec(ctx, query, cartID, itemIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete items: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
 // UpdateItemWithTx updates a cart item within transaction
 func (r *postgresRepository) UpdateItemWithTx(ctx context.Context, tx pgx.Tx, item *model.CartItem) error {
 	query := `
@@ -855,7 +1001,7 @@ func (r *postgresRepository) AddItemWithTx(ctx context.Context, tx pgx.Tx, item
 	query := `
         INSERT INTO cart_items (cart_id, book_id, quantity, price, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6)
-        ON CONFLICT (cart_id, book_id) DO UPDATE SET
+        ON CONFLICT (cart_id, book_id) WHERE gift_promotion_id IS NULL DO UPDATE SET
             quantity = EXCLUDED.quantity,
             price = EXCLUDED.price,
             updated_at = EXCLUDED.updated_at
@@ -900,7 +1046,7 @@ func (r *postgresRepository) GetItemByBookInCartWithTx(ctx context.Context, tx p
 	query := `
         SELECT id, cart_id, book_id, quantity, price, created_at, updated_at
         FROM cart_items
-        WHERE cart_id = $1 AND book_id = $2
+        WHERE cart_id = $1 AND book_id = $2 AND gift_promotion_id IS NULL
         FOR UPDATE -- Lock for transaction
     `
 
@@ -927,11 +1073,15 @@ func (r *postgresRepository) GetItemByBookInCartWithTx(ctx context.Context, tx p
 
 // GetCartAndItem retrieves cart and item in single query (optimized)
 func (r *postgresRepository) GetCartAndItem(ctx context.Context, cartID uuid.UUID, itemID uuid.UUID) (*model.Cart, *model.CartItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT 
             c.id, c.user_id, c.session_id, c.items_count, c.subtotal, c.version,
             c.created_at, c.updated_at, c.expires_at,
-            ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price, ci.created_at, ci.updated_at
+            ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price, ci.created_at, ci.updated_at,
+            ci.gift_promotion_id
         FROM carts c
         INNER JOIN cart_items ci ON c.id = ci.cart_id
         WHERE c.id = $1 AND ci.id = $2
@@ -944,6 +1094,7 @@ func (r *postgresRepository) GetCartAndItem(ctx context.Context, cartID uuid.UUI
 		&cart.ID, &cart.UserID, &cart.SessionID, &cart.ItemsCount, &cart.Subtotal, &cart.Version,
 		&cart.CreatedAt, &cart.UpdatedAt, &cart.ExpiresAt,
 		&item.ID, &item.CartID, &item.BookID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt,
+		&item.GiftPromotionID,
 	)
 
 	if err != nil {
@@ -958,11 +1109,15 @@ func (r *postgresRepository) GetCartAndItem(ctx context.Context, cartID uuid.UUI
 
 // GetItemWithBookByID retrieves cart item with book details by item ID
 func (r *postgresRepository) GetItemWithBookByID(ctx context.Context, itemID uuid.UUID) (*model.CartItemWithBook, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT 
-            ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price, ci.created_at, ci.updated_at,
+            ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price, ci.created_at, ci.updated_at, ci.gift_promotion_id,
             b.title, b.slug, b.cover_url, a.name as author_name, b.price as current_price, b.is_active,
-            COALESCE(bts.available, 0) as total_stock
+            COALESCE(bts.available, 0) as total_stock,
+            (b.archived_at IS NOT NULL OR b.deleted_at IS NOT NULL) as discontinued
         FROM cart_items ci
         LEFT JOIN books b ON ci.book_id = b.id
         LEFT JOIN authors a ON b.author_id = a.id
@@ -972,8 +1127,9 @@ func (r *postgresRepository) GetItemWithBookByID(ctx context.Context, itemID uui
 
 	var item model.CartItemWithBook
 	err := r.pool.QueryRow(ctx, query, itemID).Scan(
-		&item.ID, &item.CartID, &item.BookID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt,
+		&item.ID, &item.CartID, &item.BookID, &item.Quantity, &item.Price, &item.CreatedAt, &item.UpdatedAt, &item.GiftPromotionID,
 		&item.BookTitle, &item.BookSlug, &item.BookCoverURL, &item.BookAuthor, &item.CurrentPrice, &item.IsActive, &item.TotalStock,
+		&item.Discontinued,
 	)
 
 	if err != nil {
@@ -988,6 +1144,9 @@ func (r *postgresRepository) GetItemWithBookByID(ctx context.Context, itemID uui
 
 // GetByCode retrieves promotion by code (case-insensitive)
 func (r *postgresRepository) GetPromoByCode(ctx context.Context, code string) (*promo.Promotion, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT 
             id, code, name, description,
@@ -995,7 +1154,8 @@ func (r *postgresRepository) GetPromoByCode(ctx context.Context, code string) (*
             min_order_amount, applicable_category_ids, first_order_only,
             max_uses, max_uses_per_user, current_uses,
             starts_at, expires_at, is_active,
-            created_at, updated_at
+            created_at, updated_at,
+            is_automatic, stackable, priority
         FROM promotions
         WHERE LOWER(code) = LOWER($1)
     `
@@ -1020,6 +1180,9 @@ func (r *postgresRepository) GetPromoByCode(ctx context.Context, code string) (*
 		&promo.IsActive,
 		&promo.CreatedAt,
 		&promo.UpdatedAt,
+		&promo.IsAutomatic,
+		&promo.Stackable,
+		&promo.Priority,
 	)
 
 	if err != nil {
@@ -1032,8 +1195,59 @@ func (r *postgresRepository) GetPromoByCode(ctx context.Context, code string) (*
 	return &promo, nil
 }
 
+// ListAutomaticPromos promotion tự động đang chạy, còn lượt toàn cục (priority cao trước)
+func (r *postgresRepository) ListAutomaticPromos(ctx context.Context) ([]*promo.Promotion, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT
+            id, code, name, description,
+            discount_type, discount_value, max_discount_amount,
+            min_order_amount, applicable_category_ids, first_order_only,
+            max_uses, max_uses_per_user, current_uses,
+            starts_at, expires_at, is_active,
+            created_at, updated_at,
+            is_automatic, stackable, priority
+        FROM promotions
+        WHERE is_automatic = true
+            AND is_active = true
+            AND starts_at <= NOW()
+            AND expires_at >= NOW()
+            AND (max_uses IS NULL OR current_uses < max_uses)
+        ORDER BY priority DESC, code ASC
+    `
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automatic promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var promos []*promo.Promotion
+	for rows.Next() {
+		var p promo.Promotion
+		if err := rows.Scan(
+			&p.ID, &p.Code, &p.Name, &p.Description,
+			&p.DiscountType, &p.DiscountValue, &p.MaxDiscountAmount,
+			&p.MinOrderAmount, &p.ApplicableCategoryIDs, &p.FirstOrderOnly,
+			&p.MaxUses, &p.MaxUsesPerUser, &p.CurrentUses,
+			&p.StartsAt, &p.ExpiresAt, &p.IsActive,
+			&p.CreatedAt, &p.UpdatedAt,
+			&p.IsAutomatic, &p.Stackable, &p.Priority,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan automatic promotion: %w", err)
+		}
+		promos = append(promos, &p)
+	}
+	return promos, rows.Err()
+}
+
 // CountUserUsage counts how many times user has used a promotion
 func (r *postgresRepository) CountUserUsage(ctx context.Context, promotionID uuid.UUID, userID uuid.UUID) (int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT COUNT(*)
         FROM promotion_usage
@@ -1051,6 +1265,9 @@ func (r *postgresRepository) CountUserUsage(ctx context.Context, promotionID uui
 
 // UserHasCompletedOrders checks if user has any completed orders
 func (r *postgresRepository) UserHasCompletedOrders(ctx context.Context, userID uuid.UUID) (bool, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT EXISTS(
             SELECT 1 FROM orders
@@ -1067,6 +1284,9 @@ func (r *postgresRepository) UserHasCompletedOrders(ctx context.Context, userID
 	return hasOrders, nil
 }
 func (r *postgresRepository) ClearCartPromo(ctx context.Context, cartID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
         UPDATE carts
         SET 
@@ -1089,20 +1309,23 @@ func (r *postgresRepository) ClearCartPromo(ctx context.Context, cartID uuid.UUI
 	return nil
 }
 
-// GetUserEmail retrieves user email by user ID
-func (r *postgresRepository) GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error) {
-	query := `SELECT email FROM users WHERE id = $1`
+// GetUserContact retrieves user email and notification locale by user ID
+func (r *postgresRepository) GetUserContact(ctx context.Context, userID uuid.UUID) (string, string, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
 
-	var email string
-	err := r.pool.QueryRow(ctx, query, userID).Scan(&email)
+	query := `SELECT email, locale FROM users WHERE id = $1`
+
+	var email, locale string
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&email, &locale)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", fmt.Errorf("user not found")
+			return "", "", fmt.Errorf("user not found")
 		}
-		return "", fmt.Errorf("get user email: %w", err)
+		return "", "", fmt.Errorf("get user contact: %w", err)
 	}
 
-	return email, nil
+	return email, locale, nil
 }
 
 // ================================================
@@ -1116,6 +1339,9 @@ func (r *postgresRepository) GetUserEmail(ctx context.Context, userID uuid.UUID)
 // - Pagination: Process in batches to avoid memory issues with large datasets
 // - Filter authenticated users only: Guest carts can't have promotions (business rule)
 func (r *postgresRepository) GetCartsWithPromotions(ctx context.Context, limit int, offset int) ([]*model.CartWithPromoInfo, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
 	// QUERY EXPLANATION:
 	// 1. SELECT from carts WHERE promo_code IS NOT NULL (only carts with promotions)
 	// 2. INNER JOIN users: Get user activity (last_login_at) for smart scheduling
@@ -1199,13 +1425,16 @@ func (r *postgresRepository) RemovePromotionWithLog(
 	reason string,
 	metadata map[string]interface{},
 ) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	// Start transaction
 	// WHY? Ensure cart update and log creation happen atomically
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback(ctx) // Rollback if not committed
+	defer database.Rollback(ctx, tx) // Rollback if not committed
 
 	// Step 1: Remove promotion from cart
 	// WHY SET discount=0 and promo_metadata=NULL?
@@ -1288,6 +1517,9 @@ func (r *postgresRepository) RemovePromotionWithLog(
 // After checking a cart (whether promotion removed or not), update last_checked_at
 // This enables smart scheduling: inactive users only checked every 24h
 func (r *postgresRepository) UpdatePromoMetadata(ctx context.Context, cartID uuid.UUID, metadata map[string]interface{}) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	// WHY COALESCE?
 	// - Merge new metadata with existing metadata
 	// - Preserves other fields in JSONB while updating last_checked_at
@@ -1312,3 +1544,79 @@ func (r *postgresRepository) UpdatePromoMetadata(ctx context.Context, cartID uui
 
 	return nil
 }
+
+// ================================================
+// GIFT-WITH-PURCHASE
+// ================================================
+
+// GetEligibleGiftPromotions chương trình tặng quà mà giỏ đang đủ điều kiện
+// Điều kiện tính trên dòng hàng trả tiền (gift_promotion_id IS NULL): subtotal + danh mục;
+// sách quà phải còn bán (tồn kho do service kiểm tra)
+func (r *postgresRepository) GetEligibleGiftPromotions(ctx context.Context, cartID uuid.UUID) ([]promo.GiftPromotion, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+        WITH paid AS (
+            SELECT
+                COALESCE(SUM(ci.quantity * ci.price), 0) AS subtotal,
+                COALESCE(ARRAY_AGG(DISTINCT b.category_id) FILTER (WHERE b.category_id IS NOT NULL), '{}') AS category_ids
+            FROM cart_items ci
+            JOIN books b ON b.id = ci.book_id
+            WHERE ci.cart_id = $1 AND ci.gift_promotion_id IS NULL
+        )
+        SELECT g.id, g.name, g.gift_book_id, g.gift_quantity, g.min_order_amount
+        FROM gift_promotions g
+        JOIN books gb ON gb.id = g.gift_book_id AND gb.is_active = true AND gb.deleted_at IS NULL
+        CROSS JOIN paid p
+        WHERE g.is_active = true
+            AND g.starts_at <= NOW()
+            AND g.expires_at >= NOW()
+            AND p.subtotal > 0
+            AND p.subtotal >= g.min_order_amount
+            AND (CARDINALITY(g.applicable_category_ids) = 0 OR g.applicable_category_ids && p.category_ids)
+        ORDER BY g.created_at ASC
+    `
+
+	rows, err := r.pool.Query(ctx, query, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gift promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var gifts []promo.GiftPromotion
+	for rows.Next() {
+		var g promo.GiftPromotion
+		if err := rows.Scan(&g.ID, &g.Name, &g.GiftBookID, &g.GiftQuantity, &g.MinOrderAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan gift promotion: %w", err)
+		}
+		gifts = append(gifts, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating gift promotions: %w", err)
+	}
+
+	return gifts, nil
+}
+
+// UpsertGiftItem thêm/cập nhật dòng quà 0đ của 1 chương trình (mỗi chương trình tối đa 1 dòng / giỏ)
+func (r *postgresRepository) UpsertGiftItem(ctx context.Context, cartID uuid.UUID, gift promo.GiftPromotion) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+        INSERT INTO cart_items (cart_id, book_id, quantity, price, gift_promotion_id, created_at, updated_at)
+        VALUES ($1, $2, $3, 0, $4, NOW(), NOW())
+        ON CONFLICT (cart_id, gift_promotion_id) WHERE gift_promotion_id IS NOT NULL DO UPDATE SET
+            book_id = EXCLUDED.book_id,
+            quantity = EXCLUDED.quantity,
+            updated_at = EXCLUDED.updated_at
+    `
+
+	if _, err := r.pool.Exec(ctx, query, cartID, gift.GiftBookID, gift.GiftQuantity, gift.ID); err != nil {
+		return fmt.Errorf("failed to upsert gift item: %w", err)
+	}
+
+	return nil
+}
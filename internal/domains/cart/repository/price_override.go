@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bookstore-backend/internal/domains/cart/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ========================================
+// PRICE OVERRIDES (giữ giá wishlist)
+// ========================================
+
+const priceOverrideColumns = `
+	id, cart_id, user_id, book_id, source, unit_price, list_price, max_quantity,
+	status, expires_at, order_id, consumed_at, created_at`
+
+func scanPriceOverride(row pgx.Row) (*model.PriceOverride, error) {
+	var o model.PriceOverride
+	err := row.Scan(
+		&o.ID, &o.CartID, &o.UserID, &o.BookID, &o.Source, &o.UnitPrice, &o.ListPrice, &o.MaxQuantity,
+		&o.Status, &o.ExpiresAt, &o.OrderID, &o.ConsumedAt, &o.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// CreatePriceOverride implements RepositoryInterface.CreatePriceOverride
+// Sách đã có override active trong giỏ → trả override cũ (created=false), không cấp thêm lượt
+func (r *postgresRepository) CreatePriceOverride(ctx context.Context, override *model.PriceOverride) (*model.PriceOverride, bool, error) {
+	query := `
+		INSERT INTO cart_price_overrides (
+			cart_id, user_id, book_id, source, unit_price, list_price, max_quantity, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (cart_id, book_id) WHERE status = 'active' DO NOTHING
+		RETURNING ` + priceOverrideColumns
+
+	created, err := scanPriceOverride(r.pool.QueryRow(ctx, query,
+		override.CartID,
+		override.UserID,
+		override.BookID,
+		override.Source,
+		override.UnitPrice,
+		override.ListPrice,
+		override.MaxQuantity,
+		override.ExpiresAt,
+	))
+	if err == nil {
+		return created, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("insert price override: %w", err)
+	}
+
+	existing, err := scanPriceOverride(r.pool.QueryRow(ctx, `
+		SELECT `+priceOverrideColumns+`
+		FROM cart_price_overrides
+		WHERE cart_id = $1 AND book_id = $2 AND status = 'active'
+	`, override.CartID, override.BookID))
+	if err != nil {
+		return nil, false, fmt.Errorf("get active price override: %w", err)
+	}
+	return existing, false, nil
+}
+
+// ListActivePriceOverrides implements RepositoryInterface.ListActivePriceOverrides
+func (r *postgresRepository) ListActivePriceOverrides(ctx context.Context, cartID uuid.UUID) ([]model.PriceOverride, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+priceOverrideColumns+`
+		FROM cart_price_overrides
+		WHERE cart_id = $1 AND status = 'active'
+	`, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("list price overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []model.PriceOverride
+	for rows.Next() {
+		o, err := scanPriceOverride(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan price override: %w", err)
+		}
+		overrides = append(overrides, *o)
+	}
+	return overrides, rows.Err()
+}
+
+// CountPriceOverridesSince implements RepositoryInterface.CountPriceOverridesSince
+// Đếm cả dòng đã dùng / hết hạn: quota tính theo lượt được cấp
+func (r *postgresRepository) CountPriceOverridesSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM cart_price_overrides WHERE user_id = $1 AND created_at >= $2
+	`, userID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count price overrides: %w", err)
+	}
+	return count, nil
+}
+
+// ExpirePriceOverrides implements RepositoryInterface.ExpirePriceOverrides
+func (r *postgresRepository) ExpirePriceOverrides(ctx context.Context, cartID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE cart_price_overrides
+		SET status = 'expired'
+		WHERE cart_id = $1 AND status = 'active' AND expires_at <= $2
+		RETURNING book_id
+	`, cartID, now)
+	if err != nil {
+		return nil, fmt.Errorf("expire price overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var bookIDs []uuid.UUID
+	for rows.Next() {
+		var bookID uuid.UUID
+		if err := rows.Scan(&bookID); err != nil {
+			return nil, fmt.Errorf("scan expired override: %w", err)
+		}
+		bookIDs = append(bookIDs, bookID)
+	}
+	return bookIDs, rows.Err()
+}
+
+// ConsumePriceOverridesWithTx implements RepositoryInterface.ConsumePriceOverridesWithTx
+func (r *postgresRepository) ConsumePriceOverridesWithTx(ctx context.Context, tx pgx.Tx, ids []uuid.UUID, orderID uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+		UPDATE cart_price_overrides
+		SET status = 'consumed', order_id = $2, consumed_at = NOW()
+		WHERE id = ANY($1) AND status = 'active'
+	`, ids, orderID)
+	if err != nil {
+		return fmt.Errorf("consume price overrides: %w", err)
+	}
+	return nil
+}
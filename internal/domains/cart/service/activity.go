@@ -0,0 +1,52 @@
+package service
+
+import (
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// =====================================================
+// CUSTOMER ACTIVITY (lịch sử giỏ trong trang tài khoản)
+// =====================================================
+// Best effort: enqueue lỗi chỉ log, không ảnh hưởng thao tác giỏ
+
+// enqueueCartActivity publish event giỏ của khách đã login (giỏ ẩn danh không có feed)
+func (s *CartService) enqueueCartActivity(ctx context.Context, cart *model.Cart, eventType string, activity analyticsModel.ActivityEvent) {
+	if cart == nil || cart.UserID == nil {
+		return
+	}
+	activity.UserID = *cart.UserID
+	activity.Domain = analyticsModel.ActivityDomainCart
+	activity.EventType = eventType
+	activity.CartID = &cart.ID
+	if activity.OccurredAt.IsZero() {
+		activity.OccurredAt = time.Now()
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeRecordActivity, analyticsModel.RecordActivityPayload{Activity: activity})
+	if err != nil {
+		logger.Info("Failed to marshal cart activity task", map[string]interface{}{
+			"event_type": eventType,
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(2),
+	); err != nil {
+		logger.Info("Failed to enqueue cart activity task", map[string]interface{}{
+			"event_type": eventType,
+			"cart_id":    cart.ID,
+			"error":      err.Error(),
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/logger"
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const anonCartQuotaKeyPrefix = "cart:anon_quota:"
+
+var (
+	anonymousCartsCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "anonymous_carts_created_total",
+		Help: "Anonymous (session) carts created.",
+	})
+	anonymousCartQuotaRejectedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "anonymous_cart_quota_rejected_total",
+		Help: "Anonymous cart creations rejected by the per-IP quota.",
+	})
+)
+
+// checkAnonymousCartQuota đếm lượt tạo giỏ ẩn danh của IP trong cửa sổ hiện tại (fixed window).
+// Redis lỗi / không có IP → cho qua: quota là lớp chống bot, không được chặn khách thật khi cache down.
+func (s *CartService) checkAnonymousCartQuota(ctx context.Context) error {
+	policy := s.anonCartQuota
+	if policy.MaxCreatesPerIP <= 0 || policy.Window <= 0 || s.cache == nil {
+		return nil
+	}
+	ip, _ := ctx.Value("client_ip").(string)
+	if ip == "" {
+		return nil
+	}
+
+	key := anonCartQuotaKeyPrefix + ip
+	count, err := s.cache.Increment(ctx, key)
+	if err != nil {
+		logger.Error("Failed to increment anonymous cart quota", err)
+		return nil
+	}
+	if count == 1 {
+		if err := s.cache.Expire(ctx, key, policy.Window); err != nil {
+			logger.Error("Failed to set anonymous cart quota window", err)
+		}
+	}
+	if count <= int64(policy.MaxCreatesPerIP) {
+		return nil
+	}
+
+	retry, err := s.cache.TTL(ctx, key)
+	if err != nil || retry <= 0 {
+		// Expire lần đầu bị lỗi → key không có TTL, đặt lại để IP không bị chặn vĩnh viễn
+		_ = s.cache.Expire(ctx, key, policy.Window)
+		retry = policy.Window
+	}
+
+	anonymousCartQuotaRejectedTotal.Inc()
+	logger.Info("Anonymous cart quota exceeded", map[string]interface{}{
+		"ip":    ip,
+		"count": count,
+		"limit": policy.MaxCreatesPerIP,
+	})
+	return &model.AnonymousCartQuotaError{Limit: policy.MaxCreatesPerIP, Retry: retry}
+}
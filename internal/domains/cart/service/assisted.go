@@ -0,0 +1,198 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// ADMIN CART - NHÂN VIÊN DỰNG GIỎ + ĐẶT ĐƠN THAY KHÁCH (ĐƠN QUA ĐIỆN THOẠI)
+// =====================================================
+// Giỏ dùng chung với khách (carts.user_id): khách login vẫn thấy giỏ nhân viên đang dựng.
+// Checkout đi qua đúng flow Checkout của khách, chỉ gắn thêm PlacedBy.
+
+// GetCustomerCart lấy (hoặc tạo) giỏ của khách
+func (s *CartService) GetCustomerCart(ctx context.Context, customerID uuid.UUID) (*model.CartResponse, error) {
+	if err := s.ensureCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	return s.GetOrCreateCart(ctx, &customerID, nil)
+}
+
+// GetCustomerCartID cart ID của khách, chưa có thì tạo (dùng cho các thao tác item / promo của admin)
+func (s *CartService) GetCustomerCartID(ctx context.Context, customerID uuid.UUID) (uuid.UUID, error) {
+	cartID, err := s.GetUserCartID(ctx, customerID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if cartID != uuid.Nil {
+		return cartID, nil
+	}
+
+	cart, err := s.GetCustomerCart(ctx, customerID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return cart.ID, nil
+}
+
+// ApplyPromoCodeForCustomer áp promo vào giỏ của khách
+// override = true (chỉ admin): promo không đạt điều kiện (min order, lượt dùng, hết hạn...) vẫn được áp,
+// miễn là promo tồn tại và đang bật. Lý do override ghi vào promo_metadata để đối soát.
+func (s *CartService) ApplyPromoCodeForCustomer(ctx context.Context, cartID, customerID, staffID uuid.UUID, promoCode string, override bool) (*model.ApplyPromoResponse, error) {
+	if !override {
+		return s.ApplyPromoCode(ctx, cartID, promoCode, customerID)
+	}
+
+	cart, err := s.repository.GetByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart == nil {
+		return nil, model.ErrCartNotFound
+	}
+	if cart.IsExpired() {
+		return nil, model.ErrCartExpired
+	}
+	if cart.UserID == nil || *cart.UserID != customerID {
+		return nil, fmt.Errorf("cart does not belong to user")
+	}
+	if cart.ItemsCount == 0 {
+		return nil, fmt.Errorf("cannot apply promo to empty cart")
+	}
+
+	result, err := s.ValidatePromoCode(ctx, &model.ValidatePromoRequest{
+		PromoCode: promoCode,
+		UserID:    customerID,
+		CartTotal: cart.Subtotal,
+		CartID:    cartID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate promo: %w", err)
+	}
+
+	// Promo hợp lệ sẵn → áp như bình thường, không ghi override
+	var extraMetadata map[string]interface{}
+	overrideReason := ""
+	if !result.IsValid {
+		promo, err := s.repository.GetPromoByCode(ctx, strings.ToUpper(strings.TrimSpace(promoCode)))
+		if err != nil || promo == nil {
+			return nil, fmt.Errorf("%w: promo code not found", model.ErrPromoNotOverridable)
+		}
+		// Promo admin đã tắt là chủ ý dừng chương trình → không cho override
+		if !promo.IsActive {
+			return nil, fmt.Errorf("%w: promo code is not active", model.ErrPromoNotOverridable)
+		}
+
+		overrideReason = result.Reason
+		result = newPromoValidationResult(promo, 0)
+		extraMetadata = map[string]interface{}{
+			model.PromoMetadataOverride:       true,
+			model.PromoMetadataOverriddenBy:   staffID.String(),
+			model.PromoMetadataOverrideReason: overrideReason,
+		}
+	}
+
+	if cart.HasPromo() {
+		if err := s.repository.ClearCartPromo(ctx, cartID); err != nil {
+			return nil, fmt.Errorf("failed to clear old promo: %w", err)
+		}
+	}
+
+	resp, err := s.attachPromo(ctx, cart, promoCode, result, extraMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if extraMetadata != nil {
+		resp.Overridden = true
+		resp.OverrideReason = overrideReason
+		logger.Info("Promo applied with admin override", map[string]interface{}{
+			"cart_id":     cartID,
+			"customer_id": customerID,
+			"staff_id":    staffID,
+			"promo_code":  promoCode,
+			"reason":      overrideReason,
+		})
+	}
+	return resp, nil
+}
+
+// CheckoutForCustomer nhân viên checkout giỏ của khách
+// pay_link: đơn pending, khách nhận link thanh toán qua email; cash_on_delivery: như khách tự đặt COD
+func (s *CartService) CheckoutForCustomer(ctx context.Context, staffID, customerID uuid.UUID, req model.AssistedCheckoutRequest) (*model.CheckoutResponse, error) {
+	if err := s.ensureCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	cartID, err := s.GetUserCartID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if cartID == uuid.Nil {
+		return nil, model.ErrCartNotFound
+	}
+
+	response, err := s.Checkout(ctx, customerID, cartID, req.ToCheckoutRequest(staffID))
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Success {
+		logger.Info("Order placed on behalf of customer", map[string]interface{}{
+			"order_id":       response.OrderID,
+			"order_number":   response.OrderNumber,
+			"customer_id":    customerID,
+			"staff_id":       staffID,
+			"payment_method": req.PaymentMethod,
+		})
+	}
+	return response, nil
+}
+
+// ensureCustomer khách phải tồn tại (tránh tạo giỏ mồ côi cho user_id sai)
+func (s *CartService) ensureCustomer(ctx context.Context, customerID uuid.UUID) error {
+	if _, _, err := s.repository.GetUserContact(ctx, customerID); err != nil {
+		return fmt.Errorf("%w: %s", model.ErrCustomerNotFound, customerID)
+	}
+	return nil
+}
+
+// sendPaymentLinkMessage email gửi link thanh toán cho khách
+func (s *CartService) sendPaymentLinkMessage(
+	orderID uuid.UUID,
+	orderNumber string,
+	userID uuid.UUID,
+	userEmail string,
+	userLocale string,
+	total decimal.Decimal,
+) (outbox.Message, error) {
+	payload := model.SendPaymentLinkPayload{
+		OrderID:     orderID,
+		OrderNumber: orderNumber,
+		UserID:      userID,
+		UserEmail:   userEmail,
+		Locale:      userLocale,
+		Total:       total.String(),
+		PaymentURL:  s.payLink.URL(orderNumber),
+		ExpiresAt:   time.Now().Add(s.payLink.TTL),
+	}
+
+	message, err := outbox.NewMessage(shared.TypeSendPaymentLink, payload, shared.QueueOrder)
+	if err != nil {
+		return outbox.Message{}, err
+	}
+	return message.
+		WithMaxRetry(3).
+		WithTimeout(30 * time.Second).
+		WithDedupKey("payment_link:" + orderID.String()), nil
+}
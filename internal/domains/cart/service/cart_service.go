@@ -1,7 +1,9 @@
 package service
 
 import (
+	addressModel "bookstore-backend/internal/domains/address/model"
 	addressService "bookstore-backend/internal/domains/address/service"
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
 	bookModel "bookstore-backend/internal/domains/book/model"
 	bookS "bookstore-backend/internal/domains/book/service"
 	"bookstore-backend/internal/domains/cart/model"
@@ -11,10 +13,17 @@ import (
 	inveService "bookstore-backend/internal/domains/inventory/service"
 	orderModel "bookstore-backend/internal/domains/order/model"
 	orderS "bookstore-backend/internal/domains/order/service"
+	promoModel "bookstore-backend/internal/domains/promotion/model"
+	"bookstore-backend/internal/infrastructure/breaker"
+	"bookstore-backend/internal/infrastructure/outbox"
 	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/stockdisplay"
 	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/database"
 	"bookstore-backend/pkg/logger"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -34,9 +43,27 @@ type CartService struct {
 	inventoryService inveService.ServiceInterface
 	inventoryRepo    inveRepo.RepositoryInterface
 	address          addressService.ServiceInterface
+	serviceability   addressService.ServiceabilityService
 	bookService      bookS.ServiceInterface
 	orderService     orderS.OrderService
 	asynqClient      *asynq.Client
+	stockPolicy      stockdisplay.Policy
+	priceTierService bookS.PriceTierService
+	checkoutPolicy   orderModel.CheckoutPolicy
+	payLink          model.PayLinkPolicy
+	// Storefront breaker: DB / inventory lỗi liên tiếp → chặn checkout bằng 503 thay vì timeout
+	breaker *breaker.Breaker
+	// Quota tạo giỏ ẩn danh theo IP (counter trong cache)
+	cache         cache.Cache
+	anonCartQuota model.AnonymousCartPolicy
+	// Giữ giá wishlist khi chuyển sang giỏ (cart_price_overrides)
+	wishlistPrice model.WishlistPriceGuaranteePolicy
+	// Hạn giỏ khách / user + nhịp gia hạn
+	expiration model.CartExpirationPolicy
+	// Đối soát checkout saga (saga kẹt, bù trừ lỗi, đơn quá hạn còn giữ hàng)
+	sagaPolicy model.CheckoutSagaPolicy
+	// Thuế theo tỉnh / danh mục (cùng engine với order service), nil = 0%
+	taxCalculator orderS.TaxCalculator
 	// promotionService PromotionServiceInterface
 }
 
@@ -48,7 +75,17 @@ func NewCartService(
 	book bookS.ServiceInterface,
 	orderService orderS.OrderService,
 	asynqClient *asynq.Client,
-
+	stockPolicy stockdisplay.Policy,
+	priceTierService bookS.PriceTierService,
+	checkoutPolicy orderModel.CheckoutPolicy,
+	serviceability addressService.ServiceabilityService,
+	payLink model.PayLinkPolicy,
+	storefrontBreaker *breaker.Breaker,
+	quotaCache cache.Cache,
+	anonCartQuota model.AnonymousCartPolicy,
+	wishlistPrice model.WishlistPriceGuaranteePolicy,
+	expiration model.CartExpirationPolicy,
+	sagaPolicy model.CheckoutSagaPolicy,
 ) ServiceInterface {
 
 	return &CartService{
@@ -59,9 +96,25 @@ func NewCartService(
 		bookService:      book,
 		orderService:     orderService,
 		asynqClient:      asynqClient,
+		stockPolicy:      stockPolicy,
+		priceTierService: priceTierService,
+		checkoutPolicy:   checkoutPolicy,
+		serviceability:   serviceability,
+		payLink:          payLink,
+		breaker:          storefrontBreaker,
+		cache:            quotaCache,
+		anonCartQuota:    anonCartQuota,
+		wishlistPrice:    wishlistPrice,
+		expiration:       expiration,
+		sagaPolicy:       sagaPolicy,
 	}
 }
 
+// SetTaxCalculator sets the tax engine used for checkout pricing breakdown
+func (s *CartService) SetTaxCalculator(calculator orderS.TaxCalculator) {
+	s.taxCalculator = calculator
+}
+
 // func (s *CartService) SetPromotionService(p PromotionServiceInterface) {
 // 	s.promotionService = p
 // }
@@ -91,6 +144,11 @@ func (s *CartService) ValidatePromoCode(ctx context.Context, req *model.Validate
 		}, nil
 	}
 
+	return s.evaluatePromo(ctx, promo, req.CartTotal, req.UserID)
+}
+
+// evaluatePromo kiểm tra điều kiện áp dụng của promotion (Step 3-9), dùng cho cả mã nhập tay và promotion tự động
+func (s *CartService) evaluatePromo(ctx context.Context, promo *promoModel.Promotion, cartTotal decimal.Decimal, userID uuid.UUID) (*model.PromotionValidationResult, error) {
 	// Step 3: Check if promotion is active
 	if !promo.IsActive {
 		return &model.PromotionValidationResult{
@@ -115,11 +173,11 @@ func (s *CartService) ValidatePromoCode(ctx context.Context, req *model.Validate
 	}
 
 	// Step 5: Check minimum order amount
-	if req.CartTotal.LessThan(promo.MinOrderAmount) {
+	if cartTotal.LessThan(promo.MinOrderAmount) {
 		return &model.PromotionValidationResult{
 			IsValid: false,
 			Reason: fmt.Sprintf("Minimum order amount is %s (current: %s)",
-				promo.MinOrderAmount.String(), req.CartTotal.String()),
+				promo.MinOrderAmount.String(), cartTotal.String()),
 		}, nil
 	}
 
@@ -132,7 +190,7 @@ func (s *CartService) ValidatePromoCode(ctx context.Context, req *model.Validate
 	}
 
 	// Step 7: Check user usage limit
-	userUsageCount, err := s.repository.CountUserUsage(ctx, promo.ID, req.UserID)
+	userUsageCount, err := s.repository.CountUserUsage(ctx, promo.ID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check user usage: %w", err)
 	}
@@ -146,7 +204,7 @@ func (s *CartService) ValidatePromoCode(ctx context.Context, req *model.Validate
 
 	// Step 8: Check first order only constraint
 	if promo.FirstOrderOnly {
-		hasOrders, err := s.repository.UserHasCompletedOrders(ctx, req.UserID)
+		hasOrders, err := s.repository.UserHasCompletedOrders(ctx, userID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check user orders: %w", err)
 		}
@@ -159,15 +217,27 @@ func (s *CartService) ValidatePromoCode(ctx context.Context, req *model.Validate
 	}
 
 	// Step 9: All validations passed - return valid result
+	return newPromoValidationResult(promo, userUsageCount), nil
+}
+
+// newPromoValidationResult build kết quả hợp lệ từ promotion entity
+func newPromoValidationResult(promo *promoModel.Promotion, userUsageCount int) *model.PromotionValidationResult {
+	description := ""
+	if promo.Description != nil {
+		description = *promo.Description
+	}
 	return &model.PromotionValidationResult{
 		IsValid:               true,
 		PromotionID:           promo.ID,
 		Code:                  promo.Code,
 		Name:                  promo.Name,
-		Description:           *promo.Description,
+		Description:           description,
 		DiscountType:          string(promo.DiscountType),
 		DiscountValue:         promo.DiscountValue,
 		MaxDiscount:           promo.MaxDiscountAmount,
+		IsAutomatic:           promo.IsAutomatic,
+		Stackable:             promo.Stackable,
+		Priority:              promo.Priority,
 		MinOrderAmount:        promo.MinOrderAmount,
 		ApplicableCategoryIDs: promo.ApplicableCategoryIDs,
 		FirstOrderOnly:        promo.FirstOrderOnly,
@@ -177,7 +247,7 @@ func (s *CartService) ValidatePromoCode(ctx context.Context, req *model.Validate
 		UserUsageCount:        userUsageCount,
 		StartsAt:              promo.StartsAt,
 		ExpiresAt:             promo.ExpiresAt,
-	}, nil
+	}
 }
 
 func (s *CartService) GetOrCreateCart(ctx context.Context, userID *uuid.UUID, sessionID *string) (*model.CartResponse, error) {
@@ -209,6 +279,11 @@ func (s *CartService) GetOrCreateCart(ctx context.Context, userID *uuid.UUID, se
 	// Step 4: Create new cart if not exists
 	var createdCart *model.Cart
 	if cart == nil {
+		if userID == nil {
+			if err := s.checkAnonymousCartQuota(ctx); err != nil {
+				return nil, err
+			}
+		}
 		cart = &model.Cart{
 			UserID:     userID,
 			SessionID:  sessionID,
@@ -217,7 +292,7 @@ func (s *CartService) GetOrCreateCart(ctx context.Context, userID *uuid.UUID, se
 			Version:    1,
 			CreatedAt:  time.Now(),
 			UpdatedAt:  time.Now(),
-			ExpiresAt:  time.Now().Add(30 * 24 * time.Hour),
+			ExpiresAt:  s.expiration.ExpiresAt(userID, time.Now()),
 		}
 
 		// Use INSERT ... ON CONFLICT to prevent duplicate cart
@@ -225,11 +300,25 @@ func (s *CartService) GetOrCreateCart(ctx context.Context, userID *uuid.UUID, se
 		if err != nil {
 			return nil, fmt.Errorf("failed to create cart: %w", err)
 		}
+		if userID == nil {
+			anonymousCartsCreatedTotal.Inc()
+		}
+		s.enqueueTrackEvent(ctx, analyticsModel.Event{
+			EventType:  analyticsModel.EventCartCreated,
+			SessionID:  sessionID,
+			UserID:     userID,
+			Properties: map[string]interface{}{"cart_id": createdCart.ID},
+		})
 	} else {
 		// Step 5: Update expiration (keep-alive)
-		if err := s.repository.UpdateExpiration(ctx, cart.ID); err != nil {
-			// Log warning but don't fail request
-			logger.Error("Failed to update cart expiration", err)
+		s.renewCart(ctx, cart)
+		// Step 5b: Giá giữ wishlist quá hạn → dòng giỏ về giá thường (subtotal do trigger tính lại)
+		if repriced, err := s.repriceExpiredPriceOverrides(ctx, cart.ID); err != nil {
+			logger.Error("Failed to reprice expired price overrides", err)
+		} else if repriced {
+			if fresh, err := s.repository.GetByID(ctx, cart.ID); err == nil && fresh != nil {
+				cart = fresh
+			}
 		}
 	}
 
@@ -251,10 +340,18 @@ func (s *CartService) GetOrCreateCart(ctx context.Context, userID *uuid.UUID, se
 	// Convert items to responses
 	itemResponses := make([]model.CartItemResponse, len(items))
 	for i, item := range items {
-		itemResponses[i] = *item.ToItemResponse()
+		itemResponses[i] = *item.ToItemResponse(s.stockPolicy)
+	}
+
+	tierSavings, err := s.applyPriceTiers(ctx, itemResponses)
+	if err != nil {
+		return nil, err
 	}
+	applyPriceGuarantees(s.priceOverridesFor(ctx, cartID), itemResponses, time.Now())
 
-	return cart.ToResponse(itemResponses), nil
+	response := cart.ToResponse(itemResponses)
+	response.TierSavings = tierSavings
+	return response, nil
 }
 
 func (s *CartService) AddItem(ctx context.Context, cartID uuid.UUID, req model.AddToCartRequest) (*model.CartItemResponse, error) {
@@ -280,8 +377,15 @@ func (s *CartService) AddItem(ctx context.Context, cartID uuid.UUID, req model.A
 	book, err := s.bookService.GetBookDetail(ctx, req.BookID.String())
 
 	if err != nil {
+		var moved *bookModel.BookMovedError
+		if errors.As(err, &moved) {
+			return nil, s.discontinuedError(ctx, req.BookID)
+		}
 		return nil, fmt.Errorf("book not found: %w", err)
 	}
+	if book.ArchivedAt != nil {
+		return nil, s.discontinuedError(ctx, req.BookID)
+	}
 	if !book.IsActive {
 		return nil, fmt.Errorf("book is not available")
 	}
@@ -336,12 +440,20 @@ func (s *CartService) AddItem(ctx context.Context, cartID uuid.UUID, req model.A
 		}
 	}
 
-	// Step 7: Add or update item
+	// Step 7: Add or update item (đơn giá theo bậc số lượng của tổng quantity)
+	tiers, err := s.priceTiersFor(ctx, []uuid.UUID{req.BookID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Giá giữ wishlist (nếu có) thay giá hiện tại trong hạn + giới hạn số lượng
+	overrides := s.priceOverridesFor(ctx, cartID)
+	tierPrice := tierUnitPrice(tiers, req.BookID, book.Price, finalQuantity)
 	item := &model.CartItem{
 		CartID:    cartID,
 		BookID:    req.BookID,
 		Quantity:  finalQuantity,
-		Price:     book.Price, // Always use current price
+		Price:     guaranteedUnitPrice(overrides, req.BookID, tierPrice, finalQuantity, time.Now()), // Always use current price
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -356,6 +468,7 @@ func (s *CartService) AddItem(ctx context.Context, cartID uuid.UUID, req model.A
 	if err != nil {
 		return nil, fmt.Errorf("failed to save item: %w", err)
 	}
+	s.refreshGiftItems(ctx, cartID)
 	// Step 8: Build response
 	response := &model.CartItemResponse{
 		ID:           savedItem.ID,
@@ -375,6 +488,28 @@ func (s *CartService) AddItem(ctx context.Context, cartID uuid.UUID, req model.A
 	// Fetch total stock separately (errors are non-critical for response)
 	totalStock, _ := s.getTotalAvailableStock(ctx, req.BookID)
 	response.TotalStock = totalStock
+	response.ApplyStockPolicy(s.stockPolicy)
+	response.ApplyPriceTiers(tiers[req.BookID])
+	applyPriceGuarantees(overrides, []model.CartItemResponse{*response}, time.Now())
+
+	s.enqueueTrackEvent(ctx, analyticsModel.Event{
+		EventType: analyticsModel.EventCartAdd,
+		SessionID: cart.SessionID,
+		UserID:    cart.UserID,
+		BookID:    &req.BookID,
+		Properties: map[string]interface{}{
+			"cart_id":  cart.ID,
+			"quantity": req.Quantity,
+			"price":    book.Price.String(),
+		},
+	})
+	s.enqueueCartActivity(ctx, cart, analyticsModel.ActivityCartItemAdded, analyticsModel.ActivityEvent{
+		BookID: &req.BookID,
+		Properties: map[string]interface{}{
+			"book_title": book.Title,
+			"quantity":   req.Quantity,
+		},
+	})
 
 	return response, nil
 }
@@ -428,12 +563,18 @@ func (s *CartService) ListItems(ctx context.Context, cartID uuid.UUID, page int,
 	// Step 4: Convert to responses
 	itemResponses := make([]model.CartItemResponse, len(items))
 	for i, item := range items {
-		itemResponses[i] = *item.ToItemResponse()
+		itemResponses[i] = *item.ToItemResponse(s.stockPolicy)
+	}
+
+	tierSavings, err := s.applyPriceTiers(ctx, itemResponses)
+	if err != nil {
+		return nil, err
 	}
 
 	// Step 5: Build response
 	// ✅ Use cart from DB (has correct ItemsCount, Subtotal, etc from triggers)
 	response := cart.ToResponse(itemResponses)
+	response.TierSavings = tierSavings
 
 	// Add pagination metadata
 	response.Page = page
@@ -487,14 +628,15 @@ func (s *CartService) GetOrCreateCartBySession(ctx context.Context, sessionID st
 			cart = nil
 		} else {
 			// Update expiration (keep-alive)
-			if err := s.repository.UpdateExpiration(ctx, cart.ID); err != nil {
-				logger.Error("Failed to update cart expiration", err)
-			}
+			s.renewCart(ctx, cart)
 			return cart.ID, nil
 		}
 	}
 
 	// Step 3: Create new cart (with race condition protection)
+	if err := s.checkAnonymousCartQuota(ctx); err != nil {
+		return uuid.Nil, err
+	}
 	newCart := &model.Cart{
 		UserID:     nil,
 		SessionID:  &sessionID,
@@ -503,7 +645,7 @@ func (s *CartService) GetOrCreateCartBySession(ctx context.Context, sessionID st
 		Version:    1,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
-		ExpiresAt:  time.Now().Add(30 * 24 * time.Hour),
+		ExpiresAt:  s.expiration.ExpiresAt(nil, time.Now()),
 	}
 
 	// Use CreateOrGet instead of Create (handles race condition)
@@ -511,6 +653,12 @@ func (s *CartService) GetOrCreateCartBySession(ctx context.Context, sessionID st
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to create session cart: %w", err)
 	}
+	anonymousCartsCreatedTotal.Inc()
+	s.enqueueTrackEvent(ctx, analyticsModel.Event{
+		EventType:  analyticsModel.EventCartCreated,
+		SessionID:  &sessionID,
+		Properties: map[string]interface{}{"cart_id": createdCart.ID},
+	})
 
 	return createdCart.ID, nil
 }
@@ -519,6 +667,9 @@ func (s *CartService) GetOrCreateCartBySession(ctx context.Context, sessionID st
 
 // MergeCart implements ServiceInterface.MergeCart
 func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uuid.UUID) error {
+	// Gắn event ẩn danh của session vào user (kể cả khi không có cart để merge)
+	s.enqueueStitchIdentity(ctx, sessionID, userID)
+
 	// Step 1: Get anonymous cart
 	anonymousCart, err := s.repository.GetBySessionID(ctx, sessionID)
 	if err != nil {
@@ -535,7 +686,7 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 	}
 
 	// Step 2: Get anonymous cart items
-	anonymousItems, _, err := s.repository.GetItemsWithBooks(ctx, anonymousCart.ID, 0, 0) // Fetch all
+	anonymousItems, err := s.repository.GetCheckoutItems(ctx, anonymousCart.ID) // Lean: chỉ cần book_id + quantity
 	if err != nil {
 		return fmt.Errorf("failed to get anonymous cart items: %w", err)
 	}
@@ -546,6 +697,10 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 	}
 
 	// ===== BEGIN TRANSACTION =====
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.repository.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -568,7 +723,7 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 			Version:    1,
 			CreatedAt:  time.Now(),
 			UpdatedAt:  time.Now(),
-			ExpiresAt:  time.Now().Add(30 * 24 * time.Hour),
+			ExpiresAt:  s.expiration.ExpiresAt(&userID, time.Now()),
 		}
 		// Use CreateOrGetWithTx to handle race condition
 		userCart, err = s.repository.CreateOrGetWithTx(ctx, tx, newCart)
@@ -585,6 +740,9 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 
 	userItemsByBook := make(map[uuid.UUID]*model.CartItem)
 	for i := range userItems {
+		if userItems[i].IsGift() {
+			continue // Dòng quà được đồng bộ lại sau merge
+		}
 		userItemsByBook[userItems[i].BookID] = &userItems[i]
 	}
 
@@ -605,14 +763,39 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 		booksMap[b.ID.String()] = b
 	}
 
+	anonBookIDs := make([]uuid.UUID, len(anonymousItems))
+	for i, item := range anonymousItems {
+		anonBookIDs[i] = item.BookID
+	}
+	tiers, err := s.priceTiersFor(ctx, anonBookIDs)
+	if err != nil {
+		return err
+	}
+	// Giá giữ wishlist thuộc giỏ user (giỏ khách không có override)
+	overrides := s.priceOverridesFor(ctx, userCart.ID)
+	now := time.Now()
+
 	for _, anonItem := range anonymousItems {
+		if anonItem.IsGift() {
+			continue // Quà của giỏ khách không chuyển sang, giỏ user tự đồng bộ quà theo điều kiện mới
+		}
+
 		// Validate book still active
+		// Sách ngừng bán vẫn chuyển sang giỏ user (giữ giá snapshot) để ValidateCart báo ITEM_DISCONTINUED
+		// kèm gợi ý thay thế, thay vì item biến mất âm thầm sau khi đăng nhập
 		book, exists := booksMap[anonItem.BookID.String()]
-		if !exists || !book.IsActive {
+		discontinued := anonItem.Discontinued
+		if !discontinued && (!exists || !book.IsActive) {
 			// Skip inactive books
 			logger.Error("Skipping inactive/missing book in merge", nil)
 			continue
 		}
+		unitPrice := func(qty int) decimal.Decimal {
+			if discontinued {
+				return anonItem.Price
+			}
+			return guaranteedUnitPrice(overrides, anonItem.BookID, tierUnitPrice(tiers, anonItem.BookID, book.Price, qty), qty, now)
+		}
 
 		existingUserItem, exists := userItemsByBook[anonItem.BookID]
 
@@ -629,7 +812,7 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 				CartID:    userCart.ID,
 				BookID:    anonItem.BookID,
 				Quantity:  newQty,
-				Price:     book.Price, // Use current price
+				Price:     unitPrice(newQty), // Use current price
 				UpdatedAt: time.Now(),
 			}
 
@@ -642,7 +825,7 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 				CartID:    userCart.ID,
 				BookID:    anonItem.BookID,
 				Quantity:  anonItem.Quantity,
-				Price:     book.Price, // Use current price
+				Price:     unitPrice(anonItem.Quantity), // Use current price
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
 			}
@@ -662,6 +845,7 @@ func (s *CartService) MergeCart(ctx context.Context, sessionID string, userID uu
 	if err := s.repository.CommitTx(ctx, tx); err != nil {
 		return fmt.Errorf("failed to commit merge: %w", err)
 	}
+	s.refreshGiftItems(ctx, userCart.ID)
 
 	return nil
 }
@@ -688,12 +872,16 @@ func (s *CartService) UpdateItemQuantity(ctx context.Context, cartID uuid.UUID,
 	if cart.IsExpired() {
 		return nil, fmt.Errorf("cart has expired")
 	}
+	if item.IsGift() {
+		return nil, model.ErrGiftItemNotEditable
+	}
 
 	// Step 3: Handle quantity = 0 (remove item)
 	if quantity == 0 {
 		if err := s.repository.DeleteItem(ctx, itemID); err != nil {
 			return nil, fmt.Errorf("failed to remove item: %w", err)
 		}
+		s.refreshGiftItems(ctx, cartID)
 		// Return response indicating deletion
 		return &model.CartItemResponse{
 			ID:       itemID,
@@ -704,8 +892,15 @@ func (s *CartService) UpdateItemQuantity(ctx context.Context, cartID uuid.UUID,
 	// Step 4: Validate book still active and get current price
 	book, err := s.bookService.GetBookDetail(ctx, item.BookID.String())
 	if err != nil {
+		var moved *bookModel.BookMovedError
+		if errors.As(err, &moved) {
+			return nil, s.discontinuedError(ctx, item.BookID)
+		}
 		return nil, fmt.Errorf("book not found: %w", err)
 	}
+	if book.ArchivedAt != nil {
+		return nil, s.discontinuedError(ctx, item.BookID)
+	}
 	if !book.IsActive {
 		return nil, fmt.Errorf("book is no longer available")
 	}
@@ -725,13 +920,20 @@ func (s *CartService) UpdateItemQuantity(ctx context.Context, cartID uuid.UUID,
 	}
 
 	// Step 6: Update item
+	tiers, err := s.priceTiersFor(ctx, []uuid.UUID{item.BookID})
+	if err != nil {
+		return nil, err
+	}
+	overrides := s.priceOverridesFor(ctx, cartID)
 	item.Quantity = quantity
-	item.Price = book.Price // Update to current price (business decision)
+	item.Price = tierUnitPrice(tiers, item.BookID, book.Price, quantity) // Update to current price (business decision)
+	item.Price = guaranteedUnitPrice(overrides, item.BookID, item.Price, quantity, time.Now())
 	item.UpdatedAt = time.Now()
 
 	if err := s.repository.UpdateItem(ctx, item); err != nil {
 		return nil, fmt.Errorf("failed to update item: %w", err)
 	}
+	s.refreshGiftItems(ctx, cartID)
 
 	// Step 7: Fetch updated item with book details
 	updatedItem, err := s.repository.GetItemWithBookByID(ctx, itemID)
@@ -739,7 +941,407 @@ func (s *CartService) UpdateItemQuantity(ctx context.Context, cartID uuid.UUID,
 		return nil, fmt.Errorf("failed to fetch updated item: %w", err)
 	}
 
-	return updatedItem.ToItemResponse(), nil
+	response := updatedItem.ToItemResponse(s.stockPolicy)
+	response.ApplyPriceTiers(tiers[item.BookID])
+	items := []model.CartItemResponse{*response}
+	applyPriceGuarantees(overrides, items, time.Now())
+	return &items[0], nil
+}
+
+// BulkUpdateItems implements ServiceInterface.BulkUpdateItems
+func (s *CartService) BulkUpdateItems(ctx context.Context, cartID uuid.UUID, req model.BulkUpdateCartItemsRequest) (*model.CartResponse, error) {
+	// Step 1: Validate request (quantity range, duplicate item)
+	if len(req.Items) == 0 {
+		return nil, model.ErrInvalidQuantity
+	}
+	if len(req.Items) > model.MaxBulkItemUpdates {
+		return nil, model.ErrBulkTooManyItems
+	}
+	seen := make(map[uuid.UUID]struct{}, len(req.Items))
+	for _, op := range req.Items {
+		if op.Quantity < 0 || op.Quantity > model.MaxItemsPerProduct {
+			return nil, model.ErrInvalidQuantity
+		}
+		if _, dup := seen[op.ItemID]; dup {
+			return nil, fmt.Errorf("%w: %s", model.ErrBulkDuplicateItem, op.ItemID)
+		}
+		seen[op.ItemID] = struct{}{}
+	}
+
+	// Step 2: Validate cart
+	cart, err := s.repository.GetByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart == nil {
+		return nil, model.ErrCartNotFound
+	}
+	if cart.IsExpired() {
+		return nil, model.ErrCartExpired
+	}
+
+	// Step 3: Giá hiện tại + trạng thái + tồn kho của tất cả items trong 1 query
+	checkoutItems, err := s.repository.GetCheckoutItems(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+	bookInfo := make(map[uuid.UUID]*model.CheckoutCartItem, len(checkoutItems))
+	bookIDs := make([]uuid.UUID, 0, len(checkoutItems))
+	for _, item := range checkoutItems {
+		bookInfo[item.ID] = item
+		bookIDs = append(bookIDs, item.BookID)
+	}
+	tiers, err := s.priceTiersFor(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	overrides := s.priceOverridesFor(ctx, cartID)
+
+	// ===== BEGIN TRANSACTION =====
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.repository.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.repository.RollbackTx(ctx, tx)
+
+	// Step 4: Lock items của cart (FOR UPDATE) → request song song không ghi đè lẫn nhau
+	lockedItems, err := s.repository.GetItemsByCartIDWithTx(ctx, tx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock cart items: %w", err)
+	}
+	itemsByID := make(map[uuid.UUID]model.CartItem, len(lockedItems))
+	for _, item := range lockedItems {
+		itemsByID[item.ID] = item
+	}
+
+	// Step 5: Validate + apply từng operation (all-or-nothing)
+	var toDelete []uuid.UUID
+	now := time.Now()
+	for _, op := range req.Items {
+		item, ok := itemsByID[op.ItemID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", model.ErrCartItemNotFound, op.ItemID)
+		}
+		if item.IsGift() {
+			return nil, fmt.Errorf("%w: %s", model.ErrGiftItemNotEditable, op.ItemID)
+		}
+
+		if op.Quantity == 0 {
+			toDelete = append(toDelete, item.ID)
+			continue
+		}
+		if op.Quantity == item.Quantity {
+			continue
+		}
+
+		info, ok := bookInfo[item.ID]
+		if ok && info.Discontinued {
+			return nil, s.discontinuedError(ctx, item.BookID)
+		}
+		if !ok || !info.IsActive {
+			return nil, fmt.Errorf("%w: %s", model.ErrBookNotAvailable, item.BookID)
+		}
+
+		// Check stock khi tăng quantity (giống UpdateItemQuantity)
+		if op.Quantity > item.Quantity {
+			additionalQty := op.Quantity - item.Quantity
+			if info.TotalStock < additionalQty {
+				return nil, fmt.Errorf("%w: book %s need %d more, only %d available",
+					model.ErrInsufficientStock, item.BookID, additionalQty, info.TotalStock)
+			}
+		}
+
+		item.Quantity = op.Quantity
+		item.Price = tierUnitPrice(tiers, item.BookID, info.CurrentPrice, op.Quantity) // Update to current price (business decision)
+		item.Price = guaranteedUnitPrice(overrides, item.BookID, item.Price, op.Quantity, now)
+		item.UpdatedAt = now
+		if err := s.repository.UpdateItemWithTx(ctx, tx, &item); err != nil {
+			return nil, fmt.Errorf("failed to update item %s: %w", item.ID, err)
+		}
+	}
+
+	if len(toDelete) > 0 {
+		if _, err := s.repository.DeleteItemsWithTx(ctx, tx, cartID, toDelete); err != nil {
+			return nil, err
+		}
+	}
+
+	// ===== COMMIT TRANSACTION =====
+	if err := s.repository.CommitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk update: %w", err)
+	}
+	s.refreshGiftItems(ctx, cartID)
+
+	// Step 6: Refreshed cart (items_count/subtotal đã được trigger cập nhật)
+	return s.buildCartResponse(ctx, cartID)
+}
+
+// RestoreCartFromOrder implements ServiceInterface.RestoreCartFromOrder
+func (s *CartService) RestoreCartFromOrder(ctx context.Context, userID uuid.UUID, orderID uuid.UUID) (*model.OrderToCartResponse, error) {
+	// Step 1: Order phải thuộc user, đã huỷ, trong cửa sổ khôi phục
+	order, err := s.orderService.GetOrderDetail(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != orderModel.OrderStatusCancelled {
+		return nil, model.ErrRestoreOrderNotCancelled
+	}
+	cancelledAt := order.UpdatedAt
+	if order.CancelledAt != nil {
+		cancelledAt = *order.CancelledAt
+	}
+	if time.Since(cancelledAt) > model.RestoreCartWindowHours*time.Hour {
+		return nil, model.ErrRestoreWindowExpired
+	}
+
+	return s.copyOrderItemsToCart(ctx, userID, order)
+}
+
+// ReorderToCart implements ServiceInterface.ReorderToCart
+func (s *CartService) ReorderToCart(ctx context.Context, userID uuid.UUID, orderID uuid.UUID) (*model.OrderToCartResponse, error) {
+	// Order phải thuộc user; không giới hạn status (giống ReorderFromExisting)
+	order, err := s.orderService.GetOrderDetail(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.copyOrderItemsToCart(ctx, userID, order)
+}
+
+// copyOrderItemsToCart rebuild cart items của user từ order_items snapshot trong 1 transaction
+// Giá luôn lấy giá hiện tại; book ngừng bán/hết hàng bị bỏ qua; quantity bị cap theo tồn kho
+func (s *CartService) copyOrderItemsToCart(ctx context.Context, userID uuid.UUID, order *orderModel.OrderDetailResponse) (*model.OrderToCartResponse, error) {
+	result := &model.OrderToCartResponse{
+		OrderID:  order.ID,
+		Warnings: []model.CartValidationWarning{},
+	}
+	if len(order.Items) == 0 {
+		return result, nil
+	}
+
+	// Step 1: Giá hiện tại + trạng thái book (1 query) và tồn kho (1 query)
+	bookIDs := make([]string, len(order.Items))
+	availabilityItems := make([]inventoryModel.CheckAvailabilityItem, len(order.Items))
+	for i, item := range order.Items {
+		bookIDs[i] = item.BookID.String()
+		availabilityItems[i] = inventoryModel.CheckAvailabilityItem{BookID: item.BookID, Quantity: item.Quantity}
+	}
+
+	books, err := s.bookService.GetBooksCheckout(ctx, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+	booksByID := make(map[uuid.UUID]bookModel.BookCheckoutResponse, len(books))
+	tierBookIDs := make([]uuid.UUID, 0, len(books))
+	for _, b := range books {
+		booksByID[b.ID] = b
+		tierBookIDs = append(tierBookIDs, b.ID)
+	}
+	tiers, err := s.priceTiersFor(ctx, tierBookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, err := s.inventoryService.CheckAvailability(ctx, inventoryModel.CheckAvailabilityRequest{Items: availabilityItems})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stock: %w", err)
+	}
+
+	// ===== BEGIN TRANSACTION =====
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.repository.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.repository.RollbackTx(ctx, tx)
+
+	// Step 2: Get or create user cart (within transaction)
+	userCart, err := s.repository.GetByUserIDWithTx(ctx, tx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user cart: %w", err)
+	}
+	if userCart == nil {
+		newCart := &model.Cart{
+			ID:         uuid.New(),
+			UserID:     &userID,
+			ItemsCount: 0,
+			Subtotal:   decimal.Zero,
+			Version:    1,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+			ExpiresAt:  s.expiration.ExpiresAt(&userID, time.Now()),
+		}
+		// Use CreateOrGetWithTx to handle race condition
+		userCart, err = s.repository.CreateOrGetWithTx(ctx, tx, newCart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user cart: %w", err)
+		}
+	}
+
+	// Step 3: Items hiện có trong cart (lock) để merge thay vì tạo trùng
+	cartItems, err := s.repository.GetItemsByCartIDWithTx(ctx, tx, userCart.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+	cartItemsByBook := make(map[uuid.UUID]model.CartItem, len(cartItems))
+	for _, item := range cartItems {
+		if item.IsGift() {
+			continue
+		}
+		cartItemsByBook[item.BookID] = item
+	}
+	overrides := s.priceOverridesFor(ctx, userCart.ID)
+
+	// Step 4: Rebuild từng item từ snapshot, validate giá/tồn kho hiện tại
+	now := time.Now()
+	for i, orderItem := range order.Items {
+		if orderItem.GiftPromotionID != nil {
+			continue // Quà của đơn cũ không copy, giỏ tự đồng bộ quà theo chương trình hiện tại
+		}
+
+		book, ok := booksByID[orderItem.BookID]
+		if !ok || book.IsDiscontinued() {
+			// Ngừng bán / đã gộp: báo ITEM_DISCONTINUED kèm sách thay thế để khách tự thêm
+			if replacement := s.suggestReplacement(ctx, orderItem.BookID); ok || replacement != nil {
+				result.SkippedItems++
+				result.Warnings = append(result.Warnings, discontinuedWarning(orderItem.BookID, orderItem.BookTitle, replacement))
+				continue
+			}
+		}
+		if !ok || !book.IsActive {
+			result.SkippedItems++
+			result.Warnings = append(result.Warnings, model.CartValidationWarning{
+				Code:    "BOOK_UNAVAILABLE",
+				Message: fmt.Sprintf("%s is no longer available", orderItem.BookTitle),
+				Details: map[string]interface{}{"book_id": orderItem.BookID},
+			})
+			continue
+		}
+
+		available := availability.Items[i].TotalAvailable
+		if available <= 0 {
+			result.SkippedItems++
+			result.Warnings = append(result.Warnings, model.CartValidationWarning{
+				Code:    "OUT_OF_STOCK",
+				Message: fmt.Sprintf("%s is out of stock", book.Title),
+				Details: map[string]interface{}{"book_id": book.ID},
+			})
+			continue
+		}
+
+		existing, inCart := cartItemsByBook[book.ID]
+		quantity := orderItem.Quantity
+		if inCart && existing.Quantity > quantity {
+			quantity = existing.Quantity // Strategy giống MergeCart: giữ quantity cao hơn
+		}
+
+		maxQty := available
+		if maxQty > model.MaxItemsPerProduct {
+			maxQty = model.MaxItemsPerProduct
+		}
+		if quantity > maxQty {
+			result.Warnings = append(result.Warnings, model.CartValidationWarning{
+				Code:    "QUANTITY_ADJUSTED",
+				Message: fmt.Sprintf("Only %d of %s can be restored", maxQty, book.Title),
+				Details: map[string]interface{}{
+					"book_id":            book.ID,
+					"requested_quantity": quantity,
+					"restored_quantity":  maxQty,
+				},
+			})
+			quantity = maxQty
+		}
+
+		// So giá bìa: orderItem.Price đã áp bậc giá theo số lượng của đơn cũ
+		if !book.Price.Equal(orderItem.ListPrice) {
+			result.Warnings = append(result.Warnings, model.CartValidationWarning{
+				Code:    "PRICE_CHANGED",
+				Message: fmt.Sprintf("Price for %s changed", book.Title),
+				Details: map[string]interface{}{
+					"book_id":    book.ID,
+					"old_price":  orderItem.ListPrice,
+					"new_price":  book.Price,
+					"difference": book.Price.Sub(orderItem.ListPrice),
+				},
+			})
+		}
+
+		unitPrice := tierUnitPrice(tiers, book.ID, book.Price, quantity)
+		unitPrice = guaranteedUnitPrice(overrides, book.ID, unitPrice, quantity, now)
+		if inCart {
+			existing.Quantity = quantity
+			existing.Price = unitPrice // Use current price
+			existing.UpdatedAt = now
+			if err := s.repository.UpdateItemWithTx(ctx, tx, &existing); err != nil {
+				return nil, fmt.Errorf("failed to update item: %w", err)
+			}
+		} else {
+			restoredItem := &model.CartItem{
+				CartID:    userCart.ID,
+				BookID:    book.ID,
+				Quantity:  quantity,
+				Price:     unitPrice, // Use current price
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := s.repository.AddItemWithTx(ctx, tx, restoredItem); err != nil {
+				return nil, fmt.Errorf("failed to restore item: %w", err)
+			}
+		}
+		result.AddedItems++
+	}
+
+	// ===== COMMIT TRANSACTION =====
+	if err := s.repository.CommitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit cart restore: %w", err)
+	}
+	s.refreshGiftItems(ctx, userCart.ID)
+
+	cart, err := s.buildCartResponse(ctx, userCart.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.Cart = cart
+
+	return result, nil
+}
+
+// buildCartResponse - cart + items with book details sau khi thay đổi
+func (s *CartService) buildCartResponse(ctx context.Context, cartID uuid.UUID) (*model.CartResponse, error) {
+	cart, err := s.repository.GetByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart == nil {
+		return nil, model.ErrCartNotFound
+	}
+
+	items, _, err := s.repository.GetItemsWithBooks(ctx, cartID, 0, 0) // 0,0 = fetch all
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	itemResponses := make([]model.CartItemResponse, len(items))
+	for i, item := range items {
+		itemResponses[i] = *item.ToItemResponse(s.stockPolicy)
+	}
+
+	tierSavings, err := s.applyPriceTiers(ctx, itemResponses)
+	if err != nil {
+		return nil, err
+	}
+	applyPriceGuarantees(s.priceOverridesFor(ctx, cartID), itemResponses, time.Now())
+
+	response := cart.ToResponse(itemResponses)
+	response.TierSavings = tierSavings
+	return response, nil
 }
 
 // domains/cart/service_impl.go
@@ -769,11 +1371,21 @@ func (s *CartService) RemoveItem(ctx context.Context, cartID uuid.UUID, itemID u
 	if item.CartID != cartID {
 		return model.ErrItemNotBelongToCart // Custom error code
 	}
+	if item.IsGift() {
+		return model.ErrGiftItemNotEditable
+	}
 
 	// Delete
 	if err := s.repository.DeleteItem(ctx, itemID); err != nil {
 		return fmt.Errorf("failed to delete item: %w", err)
 	}
+	s.refreshGiftItems(ctx, cartID)
+	s.enqueueCartActivity(ctx, cart, analyticsModel.ActivityCartItemRemoved, analyticsModel.ActivityEvent{
+		BookID: &item.BookID,
+		Properties: map[string]interface{}{
+			"quantity": item.Quantity,
+		},
+	})
 
 	return nil
 }
@@ -860,7 +1472,7 @@ func (s *CartService) ValidateCart(ctx context.Context, cartID uuid.UUID, userID
 	}
 
 	// Step 4: Get all items with book details
-	items, _, err := s.repository.GetItemsWithBooks(ctx, cartID, 0, 0) // Fetch all
+	items, err := s.repository.GetCheckoutItems(ctx, cartID) // Lean projection: giá + tồn kho
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch cart items: %w", err)
 	}
@@ -875,29 +1487,71 @@ func (s *CartService) ValidateCart(ctx context.Context, cartID uuid.UUID, userID
 		return result, nil
 	}
 
+	bookIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+	tiers, err := s.priceTiersFor(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	overrides := s.priceOverridesFor(ctx, cartID)
+	now := time.Now()
+
 	// Step 5: Validate each item
 	var totalValue decimal.Decimal
 	var snapshotTotal decimal.Decimal
+	var tierSavings decimal.Decimal
 	var hasErrors bool
 	var hasWarnings bool
 
 	for _, item := range items {
+		// Giá hiện tại = giá bìa sau khi áp bậc theo quantity trong cart
+		expectedPrice, tier := tiers[item.BookID].Apply(item.CurrentPrice, item.Quantity)
+		if item.IsGift() {
+			expectedPrice, tier = decimal.Zero, nil // Dòng quà luôn 0đ, không so với giá bìa
+		} else if guaranteed, ok := overrides[item.BookID].UnitPriceFor(expectedPrice, item.Quantity, now); ok {
+			expectedPrice, tier = guaranteed, nil // Giá giữ wishlist còn hạn: không cộng dồn bậc giá
+		}
+
 		itemValidation := model.ItemValidation{
 			ItemID:           item.ID,
 			BookID:           item.BookID,
 			BookTitle:        item.BookTitle,
 			SnapshotPrice:    item.Price,
-			CurrentPrice:     item.CurrentPrice,
+			CurrentPrice:     expectedPrice,
+			ListPrice:        item.CurrentPrice,
 			SnapshotQuantity: item.Quantity,
 			AvailableStock:   item.TotalStock,
 			IsAvailable:      item.IsActive && item.TotalStock > 0,
-			PriceMatch:       item.Price.Equal(item.CurrentPrice),
+			PriceMatch:       item.Price.Equal(expectedPrice),
 			StockSufficient:  item.TotalStock >= item.Quantity,
 			Warnings:         []string{},
+			Savings:          item.CurrentPrice.Sub(expectedPrice).Mul(decimal.NewFromInt(int64(item.Quantity))),
+			IsGift:           item.IsGift(),
+		}
+		if item.IsGift() {
+			itemValidation.Savings = decimal.Zero
+		}
+		if tier != nil {
+			itemValidation.TierDiscountPercent = &tier.DiscountPercent
 		}
+		tierSavings = tierSavings.Add(itemValidation.Savings)
 
-		// Check availability
-		if !itemValidation.IsAvailable {
+		// Ngừng bán: lỗi riêng ITEM_DISCONTINUED + gợi ý thay thế (không phải "not available" chung)
+		if item.Discontinued {
+			hasErrors = true
+			itemValidation.IsAvailable = false
+			itemValidation.Code = model.ErrCheckoutItemDiscontinued
+			itemValidation.Replacement = s.suggestReplacement(ctx, item.BookID)
+			itemValidation.Warnings = append(itemValidation.Warnings, "Book has been discontinued")
+			result.Errors = append(result.Errors, model.CartValidationError{
+				Code:     model.ErrCheckoutItemDiscontinued,
+				Message:  fmt.Sprintf("%s has been discontinued", item.BookTitle),
+				Severity: "error",
+			})
+		} else if !itemValidation.IsAvailable {
+			// Check availability
 			hasErrors = true
 			itemValidation.Warnings = append(itemValidation.Warnings,
 				fmt.Sprintf("Book not available (active: %v, stock: %d)", item.IsActive, item.TotalStock))
@@ -913,9 +1567,9 @@ func (s *CartService) ValidateCart(ctx context.Context, cartID uuid.UUID, userID
 		// Check price change
 		if !itemValidation.PriceMatch {
 			hasWarnings = true
-			priceDiff := item.CurrentPrice.Sub(item.Price)
+			priceDiff := expectedPrice.Sub(item.Price)
 			itemValidation.Warnings = append(itemValidation.Warnings,
-				fmt.Sprintf("Price changed: %s → %s (%s)", item.Price, item.CurrentPrice, priceDiff))
+				fmt.Sprintf("Price changed: %s → %s (%s)", item.Price, expectedPrice, priceDiff))
 
 			result.Warnings = append(result.Warnings, model.CartValidationWarning{
 				Code:    "PRICE_CHANGED",
@@ -923,14 +1577,14 @@ func (s *CartService) ValidateCart(ctx context.Context, cartID uuid.UUID, userID
 				Details: map[string]interface{}{
 					"item_id":    item.ID,
 					"old_price":  item.Price,
-					"new_price":  item.CurrentPrice,
+					"new_price":  expectedPrice,
 					"difference": priceDiff,
 				},
 			})
 		}
 
 		// Calculate totals
-		itemCurrentTotal := decimal.NewFromInt(int64(item.Quantity)).Mul(item.CurrentPrice)
+		itemCurrentTotal := decimal.NewFromInt(int64(item.Quantity)).Mul(expectedPrice)
 		itemSnapshotTotal := decimal.NewFromInt(int64(item.Quantity)).Mul(item.Price)
 		totalValue = totalValue.Add(itemCurrentTotal)
 		snapshotTotal = snapshotTotal.Add(itemSnapshotTotal)
@@ -950,6 +1604,7 @@ func (s *CartService) ValidateCart(ctx context.Context, cartID uuid.UUID, userID
 	result.TotalValue = totalValue       // Current price total
 	result.SnapshotTotal = snapshotTotal // Snapshot price total (for comparison)
 	result.EstimatedTotal = totalValue
+	result.TierSavings = tierSavings
 
 	return result, nil
 }
@@ -1012,8 +1667,16 @@ func (s *CartService) ApplyPromoCode(ctx context.Context, cartID uuid.UUID, prom
 		return nil, fmt.Errorf("invalid promo code: %s", promo.Reason)
 	}
 
-	// Step 4: Calculate discount
-	discountAmount := s.calculatePromoDiscount(cart.Subtotal, promo)
+	return s.attachPromo(ctx, cart, promoCode, promo, nil)
+}
+
+// attachPromo tính giảm giá + ghi promo vào cart (Step 4-7 của ApplyPromoCode)
+// extraMetadata: thông tin thêm vào promo_metadata (vd: admin override)
+func (s *CartService) attachPromo(ctx context.Context, cart *model.Cart, promoCode string, promo *model.PromotionValidationResult, extraMetadata map[string]interface{}) (*model.ApplyPromoResponse, error) {
+	// Step 4: Calculate discount (mã + promotion tự động cộng dồn theo luật stacking)
+	shippingFee := s.checkoutPolicy.ShippingFeeFor(cart.Subtotal)
+	applied := s.resolvePromoStack(ctx, cart, promo, shippingFee)
+	discountAmount, shippingDiscount := sumAppliedPromotions(applied, cart.Subtotal, shippingFee)
 
 	// Step 5: Build promo metadata
 	promoMetadata := map[string]interface{}{
@@ -1024,12 +1687,25 @@ func (s *CartService) ApplyPromoCode(ctx context.Context, cartID uuid.UUID, prom
 		"value":        promo.DiscountValue.String(),
 		"applied_at":   time.Now().Format(time.RFC3339),
 	}
+	if promo.MaxDiscount != nil {
+		promoMetadata["max_discount"] = promo.MaxDiscount.String()
+	}
+	promoMetadata[model.PromoMetadataApplied] = applied
+	for k, v := range extraMetadata {
+		promoMetadata[k] = v
+	}
 
 	// Step 6: Update cart with promo (with optimistic locking)
-	err = s.repository.UpdateCartPromo(ctx, cartID, cart.Version, &promoCode, discountAmount, promoMetadata)
+	err := s.repository.UpdateCartPromo(ctx, cart.ID, cart.Version, &promoCode, discountAmount, promoMetadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to apply promo: %w", err)
 	}
+	s.enqueueCartActivity(ctx, cart, analyticsModel.ActivityCartPromoApplied, analyticsModel.ActivityEvent{
+		Properties: map[string]interface{}{
+			"promo_code":      promo.Code,
+			"discount_amount": discountAmount.String(),
+		},
+	})
 
 	// Step 7: Return response
 	return &model.ApplyPromoResponse{
@@ -1039,12 +1715,43 @@ func (s *CartService) ApplyPromoCode(ctx context.Context, cartID uuid.UUID, prom
 		DiscountType:     promo.DiscountType,
 		DiscountValue:    promo.DiscountValue,
 		DiscountAmount:   discountAmount,
+		ShippingDiscount: shippingDiscount,
 		OriginalSubtotal: cart.Subtotal,
 		DiscountedTotal:  cart.Subtotal.Sub(discountAmount),
 		AppliedAt:        time.Now(),
+
+		AppliedPromotions: applied,
 	}, nil
 }
 
+// cartPromoShippingDiscount giảm ship của mã đang gắn với cart (đọc lại từ promo_metadata, 0 nếu mã giảm tiền hàng)
+// Có applied_promotions: cộng giảm ship của cả tập đã áp, tính lại theo phí ship hiện tại
+func (s *CartService) cartPromoShippingDiscount(cart *model.Cart, shippingFee decimal.Decimal) decimal.Decimal {
+	if cart.PromoMetadata == nil {
+		return decimal.Zero
+	}
+	if applied, ok := appliedPromotionsFromMetadata(cart.PromoMetadata); ok {
+		total := decimal.Zero
+		for _, a := range applied {
+			total = total.Add(orderModel.ShippingPromoDiscount(a.DiscountType, a.DiscountValue, a.MaxDiscount, shippingFee))
+		}
+		return decimal.Min(total, shippingFee)
+	}
+	discountType, _ := cart.PromoMetadata["type"].(string)
+	valueStr, _ := cart.PromoMetadata["value"].(string)
+	value, err := decimal.NewFromString(valueStr)
+	if err != nil {
+		return decimal.Zero
+	}
+	var maxDiscount *decimal.Decimal
+	if maxStr, ok := cart.PromoMetadata["max_discount"].(string); ok {
+		if parsed, err := decimal.NewFromString(maxStr); err == nil {
+			maxDiscount = &parsed
+		}
+	}
+	return orderModel.ShippingPromoDiscount(discountType, value, maxDiscount, shippingFee)
+}
+
 // Helper: Calculate discount based on promo type
 func (s *CartService) calculatePromoDiscount(subtotal decimal.Decimal, promo *model.PromotionValidationResult) decimal.Decimal {
 	var discount decimal.Decimal
@@ -1075,10 +1782,21 @@ func (s *CartService) calculatePromoDiscount(subtotal decimal.Decimal, promo *mo
 
 // RemovePromoCode implements ServiceInterface.RemovePromoCode
 func (s *CartService) RemovePromoCode(ctx context.Context, cartID uuid.UUID) error {
+	// Lấy mã đang gắn trước khi gỡ để ghi lịch sử (lỗi đọc không chặn việc gỡ)
+	cart, _ := s.repository.GetByID(ctx, cartID)
+
 	err := s.repository.RemoveCartPromo(ctx, cartID)
 	if err != nil {
 		return fmt.Errorf("failed to remove promo: %w", err)
 	}
+
+	if cart != nil && cart.PromoCode != nil {
+		s.enqueueCartActivity(ctx, cart, analyticsModel.ActivityCartPromoRemoved, analyticsModel.ActivityEvent{
+			Properties: map[string]interface{}{
+				"promo_code": *cart.PromoCode,
+			},
+		})
+	}
 	return nil
 }
 
@@ -1089,7 +1807,8 @@ type ReservationInfo struct {
 	Quantity    int
 }
 
-func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uuid.UUID, req model.CheckoutRequest) (*model.CheckoutResponse, error) {
+// checkout thực thi các phase; Checkout bọc ngoài để lưu chẩn đoán khi thất bại
+func (s *CartService) checkout(ctx context.Context, userID uuid.UUID, cartID uuid.UUID, req model.CheckoutRequest) (*model.CheckoutResponse, error) {
 	response := &model.CheckoutResponse{
 		Success:     false,
 		Status:      "pending",
@@ -1105,8 +1824,17 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 	}
 
 	// ==================== PHASE 1: Get & Validate Cart ====================
+	// Breaker mở → trả ErrOpen ngay (handler → 503 + Retry-After), không chờ DB timeout
 	phaseStart := time.Now()
-	cart, err := s.repository.GetByID(ctx, cartID)
+	var cart *model.Cart
+	err := s.breaker.Do(func() error {
+		var err error
+		cart, err = s.repository.GetByID(ctx, cartID)
+		return err
+	})
+	if errors.Is(err, breaker.ErrOpen) {
+		return nil, err
+	}
 	if err != nil {
 		return s.failCheckout(response, "CART_NOT_FOUND", "Cannot find your cart: "+err.Error(), "")
 	}
@@ -1114,8 +1842,34 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 		return s.failCheckout(response, "CART_EXPIRED", "Your cart is expired or not found", "")
 	}
 
+	// Funnel: ghi nhận checkout started kể cả khi các phase sau fail
+	s.enqueueTrackEvent(ctx, analyticsModel.Event{
+		EventType:  analyticsModel.EventCheckoutStarted,
+		SessionID:  cart.SessionID,
+		UserID:     &userID,
+		Properties: map[string]interface{}{"cart_id": cart.ID},
+	})
+
+	// Quà tặng theo điều kiện tại thời điểm đặt (chương trình có thể đã bật/tắt/hết hạn từ lần sửa giỏ cuối)
+	if err := s.syncGiftItems(ctx, cart.ID); err != nil {
+		return s.failCheckout(response, "GIFT_SYNC_FAILED", "Cannot update gift items: "+err.Error(), "")
+	}
+
+	// Giá giữ wishlist quá hạn → dòng giỏ về giá thường trước khi đọc subtotal
+	if repriced, err := s.repriceExpiredPriceOverrides(ctx, cart.ID); err != nil {
+		return s.failCheckout(response, "PRICE_OVERRIDE_FAILED", "Cannot update cart prices: "+err.Error(), "")
+	} else if repriced {
+		if cart, err = s.repository.GetByID(ctx, cart.ID); err != nil || cart == nil {
+			return s.failCheckout(response, "CART_NOT_FOUND", "Cannot find your cart", "")
+		}
+		response.Warnings = append(response.Warnings, model.CheckoutWarning{
+			Code:    "PRICE_GUARANTEE_EXPIRED",
+			Message: "Wishlist price guarantee expired for some items, current prices apply",
+		})
+	}
+
 	// Get all items (no pagination)
-	cartItems, _, err := s.repository.GetItemsWithBooks(ctx, cart.ID, 1, 1000) // ✅ Use high limit instead of 0,0
+	cartItems, err := s.repository.GetCheckoutItems(ctx, cart.ID) // Lean projection, fetch all
 	if err != nil || len(cartItems) == 0 {
 		return s.failCheckout(response, "EMPTY_CART", "Cart is empty", "")
 	}
@@ -1142,21 +1896,23 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 			})
 		}
 		response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-			Phase:     "CART_VALIDATION",
-			Status:    "failed",
-			Message:   "Cart validation failed",
-			Timestamp: phaseStart,
-			Errors:    convertToCheckoutErrors(validation.Errors),
+			Phase:      "CART_VALIDATION",
+			Status:     "failed",
+			Message:    "Cart validation failed",
+			Timestamp:  phaseStart,
+			DurationMs: phaseDurationMs(phaseStart),
+			Errors:     convertToCheckoutErrors(validation.Errors),
 		})
 		response.Status = "failed"
 		return response, nil
 	}
 
 	response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-		Phase:     "CART_VALIDATION",
-		Status:    "success",
-		Message:   "Cart validated",
-		Timestamp: phaseStart,
+		Phase:      "CART_VALIDATION",
+		Status:     "success",
+		Message:    "Cart validated",
+		Timestamp:  phaseStart,
+		DurationMs: phaseDurationMs(phaseStart),
 	})
 
 	// ==================== PHASE 2: Validate Address ====================
@@ -1165,10 +1921,11 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 	shippingAddr, err := s.address.GetAddressByID(ctx, userID, req.ShippingAddressID)
 	if err != nil {
 		response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-			Phase:     "ADDRESS_VALIDATION",
-			Status:    "failed",
-			Message:   "Shipping address validation failed",
-			Timestamp: phaseStart,
+			Phase:      "ADDRESS_VALIDATION",
+			Status:     "failed",
+			Message:    "Shipping address validation failed",
+			Timestamp:  phaseStart,
+			DurationMs: phaseDurationMs(phaseStart),
 			Errors: []model.CheckoutError{{
 				Code:     "INVALID_SHIPPING_ADDRESS",
 				Message:  err.Error(),
@@ -1179,6 +1936,36 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 		return response, nil
 	}
 
+	// Địa chỉ lưu trước khi admin thu hẹp khu vực giao hàng vẫn có thể nằm ngoài vùng
+	serviceable, err := s.serviceability.Check(ctx, addressModel.ServiceabilityCheckRequest{
+		Province: shippingAddr.Province,
+		District: shippingAddr.District,
+	})
+	if err != nil {
+		return s.failCheckoutPhase(response, "SERVICEABILITY_CHECK_FAILED", "Cannot verify delivery area: "+err.Error(), "ADDRESS_VALIDATION", phaseStart)
+	}
+	if !serviceable.Serviceable {
+		response.Phases = append(response.Phases, model.CheckoutPhaseResult{
+			Phase:      "ADDRESS_VALIDATION",
+			Status:     "failed",
+			Message:    "Shipping address is outside our delivery area",
+			Timestamp:  phaseStart,
+			DurationMs: phaseDurationMs(phaseStart),
+			Errors: []model.CheckoutError{{
+				Code:     "ADDRESS_NOT_SERVICEABLE",
+				Message:  serviceable.Message,
+				Severity: "critical",
+				Details: map[string]interface{}{
+					"province":  serviceable.Province,
+					"district":  serviceable.District,
+					"districts": serviceable.Districts,
+				},
+			}},
+		})
+		response.Status = "failed"
+		return response, nil
+	}
+
 	if shippingAddr.Latitude == nil || shippingAddr.Longitude == nil {
 		response.Warnings = append(response.Warnings, model.CheckoutWarning{
 			Code:    "MISSING_COORDINATES",
@@ -1188,10 +1975,11 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 
 	// ✅ Complete phase 2
 	response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-		Phase:     "ADDRESS_VALIDATION",
-		Status:    "success",
-		Message:   "Address validated",
-		Timestamp: phaseStart,
+		Phase:      "ADDRESS_VALIDATION",
+		Status:     "success",
+		Message:    "Address validated",
+		Timestamp:  phaseStart,
+		DurationMs: phaseDurationMs(phaseStart),
 	})
 
 	// ==================== PHASE 3: Promo Validation ====================
@@ -1207,25 +1995,72 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 	subtotal := cart.Subtotal
 	discount := promoDiscount
 
+	// Đơn tối thiểu tính trên tiền hàng (trước promo), khớp ORD008 bên order service
+	if !s.checkoutPolicy.MeetsMinimum(subtotal) {
+		response.Errors = append(response.Errors, model.CheckoutError{
+			Code:     "MIN_ORDER_AMOUNT_NOT_MET",
+			Message:  fmt.Sprintf("Order subtotal must be at least %s VND", s.checkoutPolicy.MinOrderAmount.String()),
+			Severity: "error",
+			Details: map[string]interface{}{
+				"min_order_amount":  s.checkoutPolicy.MinOrderAmount,
+				"subtotal":          subtotal,
+				"amount_to_minimum": s.checkoutPolicy.AmountToMinimum(subtotal),
+			},
+		})
+		response.Phases = append(response.Phases, model.CheckoutPhaseResult{
+			Phase:      "PRICING_CALCULATION",
+			Status:     "failed",
+			Message:    "Order subtotal below minimum",
+			Timestamp:  phaseStart,
+			DurationMs: phaseDurationMs(phaseStart),
+		})
+		response.Status = "failed"
+		return response, nil
+	}
+
 	// Clamp discount
 	if discount.GreaterThan(subtotal) {
 		discount = subtotal
 	}
 
-	tax := decimal.Zero
-	shipping := decimal.Zero // 15k VND
+	// Thuế theo tỉnh giao hàng + danh mục, khớp calculateTaxLines bên order service
+	taxes, err := s.calculateCheckoutTax(ctx, shippingAddr.Province, cartItems, discount)
+	if err != nil {
+		logger.Error("Failed to calculate checkout tax", err)
+		response.Errors = append(response.Errors, model.CheckoutError{
+			Code:     "TAX_CALCULATION_FAILED",
+			Message:  "Unable to calculate tax for this order, please try again",
+			Severity: "error",
+		})
+		response.Phases = append(response.Phases, model.CheckoutPhaseResult{
+			Phase:      "PRICING_CALCULATION",
+			Status:     "failed",
+			Message:    "Tax calculation failed",
+			Timestamp:  phaseStart,
+			DurationMs: phaseDurationMs(phaseStart),
+		})
+		response.Status = "failed"
+		return response, nil
+	}
+	tax := taxes.TotalTax
+	shipping := s.checkoutPolicy.ShippingFeeFor(subtotal)
+	// Giảm ship (mã giảm ship / bậc tự động) là dòng riêng, khớp CalculateOrderAmounts bên order service
+	shippingDiscount := s.checkoutPolicy.ShippingDiscountFor(subtotal, shipping, s.cartPromoShippingDiscount(cart, shipping))
 	codFee := decimal.Zero
 
-	total := subtotal.Sub(discount).Add(tax).Add(shipping).Add(codFee)
+	// Chỉ thuế exclusive cộng vào tổng, thuế inclusive đã nằm trong giá
+	total := subtotal.Sub(discount).Add(taxes.AddedTax).Add(shipping).Sub(shippingDiscount).Add(codFee)
 
 	response.PricingBreakdown = model.PricingBreakdown{
-		Subtotal:      subtotal,
-		PromoDiscount: discount,
-		Tax:           tax,
-		Shipping:      shipping,
-		Total:         total,
-		Currency:      "VND",
-		TaxRate:       decimal.Zero,
+		Subtotal:         subtotal,
+		PromoDiscount:    discount,
+		ShippingDiscount: shippingDiscount,
+		Tax:              tax,
+		Shipping:         shipping,
+		Total:            total,
+		Currency:         "VND",
+		TaxRate:          taxes.EffectiveRate(),
+		TaxLines:         taxes.Lines,
 	}
 
 	response.CartSummary.EstimatedTax = tax
@@ -1233,10 +2068,11 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 	response.CartSummary.Total = total
 
 	response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-		Phase:     "PRICING_CALCULATION",
-		Status:    "success",
-		Message:   "Pricing calculated",
-		Timestamp: phaseStart,
+		Phase:      "PRICING_CALCULATION",
+		Status:     "success",
+		Message:    "Pricing calculated",
+		Timestamp:  phaseStart,
+		DurationMs: phaseDurationMs(phaseStart),
 	})
 
 	// ==================== PHASE 5: Warehouse Selection (1 LẦN DUY NHẤT) ====================
@@ -1261,9 +2097,17 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 	}
 
 	// ✅ Call CheckAvailability MỘT LẦN DUY NHẤT
-	availability, err := s.inventoryService.CheckAvailability(ctx, availabilityReq)
+	var availability *inventoryModel.CheckAvailabilityResponse
+	err = s.breaker.Do(func() error {
+		var err error
+		availability, err = s.inventoryService.CheckAvailability(ctx, availabilityReq)
+		return err
+	})
+	if errors.Is(err, breaker.ErrOpen) {
+		return nil, err
+	}
 	if err != nil {
-		return s.failCheckout(response, "AVAILABILITY_CHECK_FAILED", "Cannot check stock: "+err.Error(), "WAREHOUSE_SELECTION")
+		return s.failCheckoutPhase(response, "AVAILABILITY_CHECK_FAILED", "Cannot check stock: "+err.Error(), "WAREHOUSE_SELECTION", phaseStart)
 	}
 
 	if !availability.Overall {
@@ -1286,10 +2130,11 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 		}
 
 		response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-			Phase:     "WAREHOUSE_SELECTION",
-			Status:    "failed",
-			Message:   "Insufficient stock",
-			Timestamp: phaseStart,
+			Phase:      "WAREHOUSE_SELECTION",
+			Status:     "failed",
+			Message:    "Insufficient stock",
+			Timestamp:  phaseStart,
+			DurationMs: phaseDurationMs(phaseStart),
 		})
 		response.Status = "failed"
 		return response, nil
@@ -1305,10 +2150,11 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 	}
 
 	response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-		Phase:     "WAREHOUSE_SELECTION",
-		Status:    "success",
-		Message:   "Warehouse selected and stock available",
-		Timestamp: phaseStart,
+		Phase:      "WAREHOUSE_SELECTION",
+		Status:     "success",
+		Message:    "Warehouse selected and stock available",
+		Timestamp:  phaseStart,
+		DurationMs: phaseDurationMs(phaseStart),
 	})
 
 	// ==================== PHASE 6: CREATE ORDER QUA ORDER SERVICE ====================
@@ -1320,21 +2166,62 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 		PaymentMethod: mapCartPaymentMethod(req.PaymentMethod), // e.g. "cash_on_delivery" -> "cod"
 		PromoCode:     cart.PromoCode,                          // promo gắn với cart
 		CustomerNote:  req.CustomerNotes,
+		Gift:          req.Gift,
+		Packaging:     req.Packaging,
+		PlacedBy:      req.PlacedBy,
+		Metadata:      req.Metadata,
+		ItemMetadata:  req.ItemMetadata,
+		AffiliateCode: req.AffiliateCode,
 		Items:         nil,
 		// Items sẽ được override bên trong orderService từ cart_items
 	}
+
+	// Task hậu checkout (email, auto-release, analytics) ghi outbox cùng TX tạo đơn
+	userEmail, userLocale, err := s.repository.GetUserContact(ctx, userID)
+	if err != nil {
+		logger.Info("Failed to get user email for order confirmation", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		userEmail = "" // Bỏ qua email, các task khác vẫn ghi
+	}
+	itemCount := len(cartItems)
+	createReq.PostCommitTasks = func(order *orderModel.Order) ([]outbox.Message, error) {
+		return s.postCheckoutMessages(order.ID, order.OrderNumber, userID, userEmail, userLocale, req, total, itemCount, promoDiscount, appliedPromo)
+	}
+
+	// Saga: order id đặt trước, CreateOrder lỗi → bù trừ (đơn có thể đã commit dù trả lỗi)
+	saga, err := s.beginCheckoutSaga(ctx, userID, cartID)
+	if err != nil {
+		return s.failCheckoutPhase(response, "ORDER_CREATION_FAILED", "Failed to start checkout: "+err.Error(), "ORDER_CREATION", phaseStart)
+	}
+	createReq.OrderID = saga.OrderID
+
 	// Gọi order service (use case duy nhất)
-	orderResp, err := s.orderService.CreateOrder(ctx, userID, createReq)
+	var orderResp *orderModel.CreateOrderResponse
+	err = s.breaker.Do(func() error {
+		var err error
+		orderResp, err = s.orderService.CreateOrder(ctx, userID, createReq)
+		return err
+	})
+	if err != nil {
+		s.abortCheckoutSaga(ctx, saga, err)
+	}
+	if errors.Is(err, breaker.ErrOpen) {
+		return nil, err
+	}
 	if err != nil {
-		return s.failCheckout(response, "ORDER_CREATION_FAILED", "Failed to create order: "+err.Error(), "ORDER_CREATION")
+		return s.failCheckoutPhase(response, "ORDER_CREATION_FAILED", "Failed to create order: "+err.Error(), "ORDER_CREATION", phaseStart)
 	}
+	s.completeCheckoutSaga(ctx, saga)
 
 	// Ghi phase kết quả
 	response.Phases = append(response.Phases, model.CheckoutPhaseResult{
-		Phase:     "ORDER_CREATION",
-		Status:    "success",
-		Message:   "Order created: " + orderResp.OrderNumber,
-		Timestamp: phaseStart,
+		Phase:      "ORDER_CREATION",
+		Status:     "success",
+		Message:    "Order created: " + orderResp.OrderNumber,
+		Timestamp:  phaseStart,
+		DurationMs: phaseDurationMs(phaseStart),
 	})
 
 	// Build success response từ orderResp + dữ liệu đã có
@@ -1354,7 +2241,6 @@ func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uui
 		now,
 		req.PaymentMethod,
 	)
-	go s.enqueuePostCheckoutTasks(context.Background(), orderResp.OrderID, orderResp.OrderNumber, userID, cartID, req, total, len(cartItems), promoDiscount, appliedPromo)
 	// ==================== Build Success Response ====================
 	return response, nil
 }
@@ -1368,6 +2254,8 @@ func mapCartPaymentMethod(method string) string {
 		return orderModel.PaymentMethodBankTransfer
 	case "credit_card":
 		return orderModel.PaymentMethodVNPay // giả sử dùng VNPay cho credit
+	case model.PaymentMethodPayLink:
+		return orderModel.PaymentMethodPayLink
 	default:
 		return orderModel.PaymentMethodCOD
 	}
@@ -1407,6 +2295,20 @@ func (s *CartService) failCheckout(response *model.CheckoutResponse, code, messa
 	return response, nil
 }
 
+// failCheckoutPhase như failCheckout, ghi thêm thời điểm + thời gian chạy của phase thất bại
+func (s *CartService) failCheckoutPhase(response *model.CheckoutResponse, code, message, phase string, phaseStart time.Time) (*model.CheckoutResponse, error) {
+	response, err := s.failCheckout(response, code, message, phase)
+	last := &response.Phases[len(response.Phases)-1]
+	last.Timestamp = phaseStart
+	last.DurationMs = phaseDurationMs(phaseStart)
+	return response, err
+}
+
+// phaseDurationMs thời gian từ lúc bắt đầu phase, mili giây (giữ phần lẻ micro giây cho phase nhanh)
+func phaseDurationMs(phaseStart time.Time) float64 {
+	return float64(time.Since(phaseStart).Microseconds()) / 1000
+}
+
 func (s *CartService) validateAndApplyPromo(ctx context.Context, req model.CheckoutRequest, cart *model.Cart, cartID, userID uuid.UUID, response *model.CheckoutResponse, phaseStart time.Time) (decimal.Decimal, *string, map[string]interface{}) {
 	var promoDiscount decimal.Decimal = decimal.Zero
 	var appliedPromo *string
@@ -1437,7 +2339,7 @@ func (s *CartService) extractWarehouseID(reservations []ReservationInfo) *uuid.U
 	return nil
 }
 
-func (s *CartService) buildSuccessResponse(response *model.CheckoutResponse, order *orderModel.Order, cartItems []*model.CartItemWithBook, total, codFee decimal.Decimal, now time.Time, paymentMethod string) *model.CheckoutResponse {
+func (s *CartService) buildSuccessResponse(response *model.CheckoutResponse, order *orderModel.Order, cartItems []*model.CheckoutCartItem, total, codFee decimal.Decimal, now time.Time, paymentMethod string) *model.CheckoutResponse {
 	completedAt := now
 	response.Success = true
 	response.Status = "completed"
@@ -1460,8 +2362,23 @@ func (s *CartService) buildSuccessResponse(response *model.CheckoutResponse, ord
 			"Your order has been placed. Pay on delivery.",
 			"Track your order: " + order.OrderNumber,
 		}
+	} else if paymentMethod == model.PaymentMethodPayLink {
+		// Đơn đặt thay khách: giữ hàng tới khi link hết hạn
+		expiresAt := now.Add(s.payLink.TTL)
+		paymentURL := s.payLink.URL(order.OrderNumber)
+		response.ExpiresAt = &expiresAt
+		response.PaymentInfo = &model.PaymentCheckoutInfo{
+			PaymentMethod: paymentMethod,
+			Status:        "pending",
+			ExpiresAt:     &expiresAt,
+			RedirectURL:   &paymentURL,
+		}
+		response.NextActions = []string{
+			"Payment link sent to the customer's email",
+			"Order is held until " + expiresAt.Format(time.RFC3339),
+		}
 	} else {
-		expiresAt := now.Add(15 * time.Minute)
+		expiresAt := now.Add(model.OnlinePaymentWindow)
 		response.ExpiresAt = &expiresAt
 		response.NextActions = []string{
 			"Complete payment within 15 minutes to confirm order",
@@ -1472,169 +2389,117 @@ func (s *CartService) buildSuccessResponse(response *model.CheckoutResponse, ord
 	return response
 }
 
-// enqueuePostCheckoutTasks enqueues all background tasks after successful checkout
-func (s *CartService) enqueuePostCheckoutTasks(
-	ctx context.Context,
+// postCheckoutMessages build các task hậu checkout, ghi outbox trong TX tạo đơn
+func (s *CartService) postCheckoutMessages(
 	orderID uuid.UUID,
 	orderNumber string,
 	userID uuid.UUID,
-	cartID uuid.UUID,
+	userEmail string,
+	userLocale string,
 	req model.CheckoutRequest,
 	total decimal.Decimal,
 	itemCount int,
 	discount decimal.Decimal,
 	promoCode *string,
-) {
-	// Get user email (ignore error, task will retry)
-	userEmail, err := s.repository.GetUserEmail(ctx, userID)
-	if err != nil {
-		logger.Info("Failed to get user email for order confirmation", map[string]interface{}{
-			"order_id": orderID,
-			"user_id":  userID,
-			"error":    err.Error(),
-		})
-		userEmail = "" // Task will skip email if empty
-	}
-
-	// Task 1: Clear cart (low priority, delay 30s)
-	// s.enqueueClearCart(cartID, userID)
+) ([]outbox.Message, error) {
+	var builders []func() (outbox.Message, error)
 
-	// Task 2: Send order confirmation email (default priority, immediate)
+	// Task 1: Send order confirmation email (default priority, immediate)
 	if userEmail != "" && req.PaymentMethod == "cash_on_delivery" {
-		s.enqueueSendOrderConfirmation(orderID, orderNumber, userID, userEmail, total, req, itemCount)
+		builders = append(builders, func() (outbox.Message, error) {
+			return orderConfirmationMessage(orderID, orderNumber, userID, userEmail, userLocale, total, req)
+		})
 	}
 
-	// Task 3: Auto-release reservation if not COD (high priority, delay 15 min)
-	if req.PaymentMethod != "cash_on_delivery" {
-		s.enqueueAutoReleaseReservation(orderID, orderNumber, userID)
+	switch req.PaymentMethod {
+	case model.PaymentMethodPayLink:
+		// Task 1b: Đơn đặt thay khách → gửi link thanh toán, giữ hàng tới hết hạn link
+		if userEmail != "" {
+			builders = append(builders, func() (outbox.Message, error) {
+				return s.sendPaymentLinkMessage(orderID, orderNumber, userID, userEmail, userLocale, total)
+			})
+		}
+		builders = append(builders, func() (outbox.Message, error) {
+			return autoReleaseReservationMessage(orderID, orderNumber, userID, s.payLink.TTL)
+		})
+	case "cash_on_delivery":
+	default:
+		// Task 2: Auto-release reservation if not COD (high priority, delay 15 min)
+		builders = append(builders, func() (outbox.Message, error) {
+			return autoReleaseReservationMessage(orderID, orderNumber, userID, model.OnlinePaymentWindow)
+		})
 	}
 
-	// Task 4: Track checkout analytics (low priority, immediate)
-	s.enqueueTrackCheckout(orderID, orderNumber, userID, total, itemCount, req.PaymentMethod, promoCode, discount)
-}
+	// Task 3: Track checkout analytics (low priority, immediate)
+	builders = append(builders, func() (outbox.Message, error) {
+		return trackCheckoutMessage(orderID, orderNumber, userID, total, itemCount, req.PaymentMethod, promoCode, discount, req.Metadata)
+	})
 
-// enqueueClearCart enqueues task to clear cart
-// func (s *CartService) enqueueClearCart(cartID, userID uuid.UUID) {
-// 	payload := model.ClearCartPayload{
-// 		CartID: cartID,
-// 		UserID: userID,
-// 	}
-
-// 	task, err := utils.MarshalTask(shared.TypeClearCart, payload)
-// 	if err != nil {
-// 		logger.Info("Failed to marshal clear cart task", map[string]interface{}{
-// 			"cart_id": cartID,
-// 			"error":   err.Error(),
-// 		})
-// 		return
-// 	}
-
-// 	_, err = s.asynqClient.Enqueue(task,
-// 		asynq.Queue("low"),
-// 		asynq.MaxRetry(2),
-// 		asynq.ProcessIn(30*time.Second), // Delay 30s
-// 	)
-
-// 	if err != nil {
-// 		logger.Info("Failed to enqueue clear cart task", map[string]interface{}{
-// 			"cart_id": cartID,
-// 			"error":   err.Error(),
-// 		})
-// 	} else {
-// 		logger.Info("Enqueued clear cart task", map[string]interface{}{
-// 			"cart_id": cartID,
-// 		})
-// 	}
-// }
+	messages := make([]outbox.Message, 0, len(builders))
+	for _, build := range builders {
+		message, err := build()
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
 
-// enqueueSendOrderConfirmation enqueues order confirmation email
-func (s *CartService) enqueueSendOrderConfirmation(
+// orderConfirmationMessage order confirmation email
+func orderConfirmationMessage(
 	orderID uuid.UUID,
 	orderNumber string,
 	userID uuid.UUID,
 	userEmail string,
+	userLocale string,
 	total decimal.Decimal,
 	req model.CheckoutRequest,
-	itemCount int,
-) {
+) (outbox.Message, error) {
 	payload := model.SendOrderConfirmationPayload{
-		OrderID:           orderID,
-		OrderNumber:       orderNumber,
-		UserID:            userID,
-		UserEmail:         userEmail,
-		Total:             total,
-		PaymentMethod:     req.PaymentMethod,
-		EstimatedDelivery: "3-5 ngày",
-		ShippingAddressID: req.ShippingAddressID,
-		OrderCreatedAt:    time.Now().Format(time.RFC3339),
-	}
-
-	task, err := utils.MarshalTask(shared.TypeSendOrderConfirmation, payload)
-	if err != nil {
-		logger.Info("Failed to marshal send email task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
-		})
-		return
-	}
-
-	_, err = s.asynqClient.Enqueue(task,
-		asynq.Queue(shared.QueueOrder),
-		asynq.MaxRetry(2),
-		asynq.Timeout(30*time.Second),
-	)
-
+		OrderID:                  orderID,
+		OrderNumber:              orderNumber,
+		UserID:                   userID,
+		UserEmail:                userEmail,
+		Total:                    total,
+		PaymentMethod:            req.PaymentMethod,
+		ShippingAddressID:        req.ShippingAddressID,
+		OrderCreatedAt:           time.Now().Format(time.RFC3339),
+		EstimatedDeliveryMinDays: 3,
+		EstimatedDeliveryMaxDays: 5,
+		Locale:                   userLocale,
+	}
+
+	message, err := outbox.NewMessage(shared.TypeSendOrderConfirmation, payload, shared.QueueOrder)
 	if err != nil {
-		logger.Info("Failed to enqueue send email task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
-		})
-	} else {
-		logger.Info("Enqueued send order confirmation email", map[string]interface{}{
-			"order_id": orderID,
-			"email":    userEmail,
-		})
+		return outbox.Message{}, err
 	}
+	return message.
+		WithMaxRetry(2).
+		WithTimeout(30 * time.Second).
+		WithDedupKey("order_confirmation:" + orderID.String()), nil
 }
 
-// enqueueAutoReleaseReservation schedules auto-release if payment not completed
-func (s *CartService) enqueueAutoReleaseReservation(orderID uuid.UUID, orderNumber string, userID uuid.UUID) {
+// autoReleaseReservationMessage schedules auto-release if payment not completed
+func autoReleaseReservationMessage(orderID uuid.UUID, orderNumber string, userID uuid.UUID, delay time.Duration) (outbox.Message, error) {
 	payload := model.AutoReleaseReservationPayload{
 		OrderID:     orderID,
 		OrderNumber: orderNumber,
 		UserID:      userID,
 	}
 
-	task, err := utils.MarshalTask(shared.TypeAutoReleaseReservation, payload)
+	message, err := outbox.NewMessage(shared.TypeAutoReleaseReservation, payload, shared.QueueInventory)
 	if err != nil {
-		logger.Info("Failed to marshal auto-release task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
-		})
-		return
-	}
-
-	_, err = s.asynqClient.Enqueue(task,
-		asynq.Queue(shared.QueueInventory), // High priority
-		asynq.MaxRetry(3),                  // Critical task
-		asynq.ProcessIn(15*time.Minute),    // Execute after 15 minutes
-	)
-
-	if err != nil {
-		logger.Info("Failed to enqueue auto-release task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
-		})
-	} else {
-		logger.Info("Enqueued auto-release reservation task", map[string]interface{}{
-			"order_id":   orderID,
-			"execute_at": time.Now().Add(15 * time.Minute).Format(time.RFC3339),
-		})
+		return outbox.Message{}, err
 	}
+	return message.
+		WithMaxRetry(3).  // Critical task
+		WithDelay(delay). // Hết hạn thanh toán (15 phút, pay link lâu hơn)
+		WithDedupKey("auto_release:" + orderID.String()), nil
 }
 
-// enqueueTrackCheckout enqueues analytics tracking
-func (s *CartService) enqueueTrackCheckout(
+// trackCheckoutMessage analytics tracking
+func trackCheckoutMessage(
 	orderID uuid.UUID,
 	orderNumber string,
 	userID uuid.UUID,
@@ -1643,7 +2508,8 @@ func (s *CartService) enqueueTrackCheckout(
 	paymentMethod string,
 	promoCode *string,
 	discount decimal.Decimal,
-) {
+	metadata orderModel.Metadata,
+) (outbox.Message, error) {
 	payload := model.TrackCheckoutPayload{
 		OrderID:       orderID,
 		OrderNumber:   orderNumber,
@@ -1653,30 +2519,65 @@ func (s *CartService) enqueueTrackCheckout(
 		PaymentMethod: paymentMethod,
 		PromoCode:     promoCode,
 		Discount:      discount,
+		Metadata:      metadata,
 	}
 
-	task, err := utils.MarshalTask(shared.TypeTrackCheckout, payload)
+	message, err := outbox.NewMessage(shared.TypeTrackCheckout, payload, shared.QueueAnalytics)
 	if err != nil {
-		logger.Info("Failed to marshal track checkout task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
+		return outbox.Message{}, err
+	}
+	return message.
+		WithMaxRetry(0). // Don't retry analytics
+		WithDedupKey("track_checkout:" + orderID.String()), nil
+}
+
+// enqueueTrackEvent enqueues funnel event (cart created, item added, checkout started)
+func (s *CartService) enqueueTrackEvent(ctx context.Context, event analyticsModel.Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeTrackEvent, analyticsModel.TrackEventPayload{Event: event})
+	if err != nil {
+		logger.Info("Failed to marshal track event task", map[string]interface{}{
+			"event_type": event.EventType,
+			"error":      err.Error(),
 		})
 		return
 	}
 
-	_, err = s.asynqClient.Enqueue(task,
+	if _, err := s.asynqClient.Enqueue(task,
 		asynq.Queue(shared.QueueAnalytics),
-		asynq.MaxRetry(0), // Don't retry analytics
-	)
+		asynq.MaxRetry(1),
+	); err != nil {
+		logger.Info("Failed to enqueue track event task", map[string]interface{}{
+			"event_type": event.EventType,
+			"error":      err.Error(),
+		})
+	}
+}
 
+// enqueueStitchIdentity enqueues session → user identity stitching after login
+func (s *CartService) enqueueStitchIdentity(ctx context.Context, sessionID string, userID uuid.UUID) {
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeStitchIdentity, analyticsModel.StitchIdentityPayload{
+		SessionID: sessionID,
+		UserID:    userID,
+	})
 	if err != nil {
-		logger.Info("Failed to enqueue track checkout task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
+		logger.Info("Failed to marshal stitch identity task", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
 		})
-	} else {
-		logger.Info("Enqueued track checkout task", map[string]interface{}{
-			"order_id": orderID,
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(3),
+	); err != nil {
+		logger.Info("Failed to enqueue stitch identity task", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
 		})
 	}
 }
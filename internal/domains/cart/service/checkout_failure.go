@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/infrastructure/breaker"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// =====================================================
+// CHECKOUT FAILURE DIAGNOSTICS
+// =====================================================
+// Checkout thất bại (response failed hoặc lỗi hệ thống) → lưu nguyên CheckoutResponse theo user/cart
+// để CSKH tra "vì sao khách không đặt được": phase nào fail (address, promo, stock, order creation), mã lỗi gì.
+
+const saveCheckoutFailureTimeout = 3 * time.Second
+
+// Checkout implements ServiceInterface.Checkout
+func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uuid.UUID, req model.CheckoutRequest) (*model.CheckoutResponse, error) {
+	ctx, span := tracing.Start(ctx, "checkout", trace.WithAttributes(
+		attribute.String("cart.id", cartID.String()),
+		attribute.String("checkout.payment_method", req.PaymentMethod),
+	))
+
+	response, err := s.checkout(ctx, userID, cartID, req)
+	observeCheckout(req, response, err)
+
+	traceCheckoutPhases(ctx, response)
+	tracing.RecordError(span, err)
+	if response != nil {
+		span.SetAttributes(attribute.Bool("checkout.success", response.Success))
+		if response.OrderID != uuid.Nil {
+			span.SetAttributes(attribute.String("order.id", response.OrderID.String()))
+		}
+	}
+	span.End()
+
+	failed := err != nil || (response != nil && response.Status == "failed")
+	// Breaker mở = DB đang lỗi, ghi thêm cũng fail; UNAUTHENTICATED không có user để gắn
+	if failed && userID != uuid.Nil && !errors.Is(err, breaker.ErrOpen) {
+		s.recordCheckoutFailure(ctx, model.NewCheckoutFailure(userID, cartID, req, response, err))
+	}
+
+	return response, err
+}
+
+// recordCheckoutFailure lưu chẩn đoán, lỗi chỉ log: không được làm hỏng response trả cho khách
+func (s *CartService) recordCheckoutFailure(ctx context.Context, failure *model.CheckoutFailure) {
+	// Request có thể đã bị huỷ (client đóng kết nối) nhưng vẫn cần lưu lại
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveCheckoutFailureTimeout)
+	defer cancel()
+
+	if err := s.repository.SaveCheckoutFailure(saveCtx, failure); err != nil {
+		logger.Error("Failed to save checkout failure", err)
+		return
+	}
+
+	logger.Info("Checkout failure recorded", map[string]interface{}{
+		"failure_id":   failure.ID,
+		"user_id":      failure.UserID,
+		"cart_id":      failure.CartID,
+		"failed_phase": failure.FailedPhase,
+		"error_codes":  failure.ErrorCodes,
+	})
+}
+
+// ListCheckoutFailures implements ServiceInterface.ListCheckoutFailures
+func (s *CartService) ListCheckoutFailures(ctx context.Context, req model.ListCheckoutFailuresRequest) (*model.ListCheckoutFailuresResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	items, totalItems, err := s.repository.ListCheckoutFailures(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (totalItems + req.Limit - 1) / req.Limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &model.ListCheckoutFailuresResponse{
+		Items:      items,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// GetCheckoutFailure implements ServiceInterface.GetCheckoutFailure
+func (s *CartService) GetCheckoutFailure(ctx context.Context, id uuid.UUID) (*model.CheckoutFailure, error) {
+	return s.repository.GetCheckoutFailure(ctx, id)
+}
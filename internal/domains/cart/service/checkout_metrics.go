@@ -0,0 +1,55 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// checkoutPhaseDuration latency từng phase checkout (CART_VALIDATION, WAREHOUSE_SELECTION, ORDER_CREATION...)
+// status tách success / failed: phase fail sớm (vd hết hàng) không kéo lệch latency phase thành công
+var checkoutPhaseDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "checkout_phase_duration_seconds",
+	Help:    "Duration of each checkout phase in seconds.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"phase", "status"})
+
+var (
+	checkoutSuccessTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "checkout_success_total",
+		Help: "Checkouts that created an order, by payment method.",
+	}, []string{"payment_method"})
+	// phase = phase failed cuối cùng ("none" khi fail trước phase đầu: giỏ trống, hết hạn...; "error" khi lỗi hệ thống)
+	checkoutFailedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "checkout_failed_total",
+		Help: "Checkouts that did not create an order, by failed phase.",
+	}, []string{"phase"})
+)
+
+// observeCheckout export duration các phase đã chạy + kết quả của 1 lần checkout
+func observeCheckout(req model.CheckoutRequest, response *model.CheckoutResponse, err error) {
+	if response != nil {
+		for _, phase := range response.Phases {
+			checkoutPhaseDuration.WithLabelValues(phase.Phase, phase.Status).Observe(phase.DurationMs / 1000)
+		}
+	}
+
+	switch {
+	case err != nil || response == nil:
+		checkoutFailedTotal.WithLabelValues("error").Inc()
+	case response.Success:
+		checkoutSuccessTotal.WithLabelValues(req.PaymentMethod).Inc()
+	default:
+		checkoutFailedTotal.WithLabelValues(lastFailedPhase(response)).Inc()
+	}
+}
+
+func lastFailedPhase(response *model.CheckoutResponse) string {
+	for i := len(response.Phases) - 1; i >= 0; i-- {
+		if response.Phases[i].Status == "failed" {
+			return response.Phases[i].Phase
+		}
+	}
+	return "none"
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bookstore-backend/internal/domains/cart/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// CHECKOUT SAGA (ORCHESTRATOR + COMPENSATION)
+// =====================================================
+// Bước xuôi:  ORDER_CREATION - giữ tồn + ghi promotion_usage + xoá giỏ trong 1 transaction (order service)
+// Bù trừ (ngược thứ tự): CANCEL_ORDER (huỷ đơn, trả tồn, hoàn promotion) → RESTORE_CART (đưa hàng về giỏ)
+//
+// CreateOrder lỗi nhưng transaction có thể đã commit (timeout lúc COMMIT, client huỷ request)
+// → order id đặt trước để bù trừ đúng đơn. Bù trừ lỗi / process chết → job đối soát chạy lại.
+
+const (
+	// compensateCheckoutTimeout bù trừ chạy cả khi request đã bị huỷ
+	compensateCheckoutTimeout = 10 * time.Second
+	reconcileSagaBatch        = 100
+	// reconcileHoldLimit số đơn quá hạn tối đa trả tồn mỗi lần chạy, phần còn lại để lần sau
+	reconcileHoldLimit = 500
+)
+
+// checkoutSagaTotal kết quả saga: completed, compensated, compensation_failed, abandoned (đối soát, đơn chưa tạo)
+var checkoutSagaTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "checkout_saga_total",
+	Help: "Checkout sagas by outcome.",
+}, []string{"outcome"})
+
+var reservationHoldsReleasedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "reservation_holds_released_total",
+	Help: "Expired unpaid orders cancelled by reconciliation to release reserved stock.",
+})
+
+// beginCheckoutSaga ghi saga trước khi tạo đơn: không ghi được → không tạo đơn (không có gì để bù trừ)
+func (s *CartService) beginCheckoutSaga(ctx context.Context, userID, cartID uuid.UUID) (*model.CheckoutSaga, error) {
+	saga := model.NewCheckoutSaga(userID, cartID)
+	if err := s.repository.CreateCheckoutSaga(ctx, saga); err != nil {
+		return nil, err
+	}
+	return saga, nil
+}
+
+// completeCheckoutSaga đơn đã tạo: lỗi chỉ log, job đối soát thấy đơn tồn tại sẽ đánh dấu completed
+func (s *CartService) completeCheckoutSaga(ctx context.Context, saga *model.CheckoutSaga) {
+	if err := s.repository.UpdateCheckoutSagaStatus(ctx, saga.ID, model.CheckoutSagaCompleted, saga.Step, nil); err != nil {
+		logger.Error("Failed to mark checkout saga completed", err)
+		return
+	}
+	checkoutSagaTotal.WithLabelValues(model.CheckoutSagaCompleted).Inc()
+}
+
+// abortCheckoutSaga bù trừ sau khi CreateOrder lỗi (ctx request có thể đã huỷ → tách ctx riêng)
+func (s *CartService) abortCheckoutSaga(ctx context.Context, saga *model.CheckoutSaga, cause error) {
+	compensateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensateCheckoutTimeout)
+	defer cancel()
+
+	s.compensateCheckoutSaga(compensateCtx, saga, "Checkout failed: "+cause.Error())
+}
+
+// compensateCheckoutSaga chạy bù trừ theo thứ tự ngược, ghi kết quả vào saga
+func (s *CartService) compensateCheckoutSaga(ctx context.Context, saga *model.CheckoutSaga, reason string) bool {
+	status, step := model.CheckoutSagaCompensated, model.CheckoutSagaStepCancelOrder
+	var lastError *string
+
+	// CANCEL_ORDER: đơn chưa tạo (tx đã rollback) → tồn / promotion / giỏ chưa bị động tới
+	cancelled, err := s.orderService.CompensateCheckout(ctx, saga.OrderID, reason)
+	if err != nil {
+		status = model.CheckoutSagaCompensationFailed
+		msg := err.Error()
+		lastError = &msg
+	} else if cancelled {
+		// RESTORE_CART: best effort, khách vẫn tự khôi phục được từ đơn đã huỷ
+		step = model.CheckoutSagaStepRestoreCart
+		if _, err := s.RestoreCartFromOrder(ctx, saga.UserID, saga.OrderID); err != nil {
+			logger.Info("Failed to restore cart after checkout compensation", map[string]interface{}{
+				"saga_id":  saga.ID,
+				"order_id": saga.OrderID,
+				"error":    err.Error(),
+			})
+		}
+	}
+
+	if err := s.repository.UpdateCheckoutSagaStatus(ctx, saga.ID, status, step, lastError); err != nil {
+		logger.Error("Failed to update checkout saga status", err)
+	}
+	checkoutSagaTotal.WithLabelValues(status).Inc()
+
+	logger.Info("Checkout saga compensated", map[string]interface{}{
+		"saga_id":         saga.ID,
+		"order_id":        saga.OrderID,
+		"status":          status,
+		"order_cancelled": cancelled,
+		"reason":          reason,
+	})
+	return status == model.CheckoutSagaCompensated
+}
+
+// ReconcileCheckouts implements ServiceInterface.ReconcileCheckouts
+//  1. Saga kẹt 'started' (process chết giữa chừng): đơn đã tạo → completed (đơn tự đi theo vòng đời,
+//     auto-release lo phần chưa thanh toán); đơn chưa tạo → tx đã rollback, không có gì để trả
+//  2. Saga 'compensation_failed' còn lượt → bù trừ lại
+//  3. Đơn chưa thanh toán quá hạn + HoldGrace còn giữ hàng (task auto-release bị mất) → huỷ, trả tồn
+//  4. Dọn saga đã xong quá CheckoutSagaRetention
+func (s *CartService) ReconcileCheckouts(ctx context.Context) (*model.CheckoutReconcileResult, error) {
+	result := &model.CheckoutReconcileResult{}
+	now := time.Now()
+
+	sagas, err := s.repository.ListPendingCheckoutSagas(ctx, now.Add(-s.sagaPolicy.StaleAfter), s.sagaPolicy.MaxAttempts, reconcileSagaBatch)
+	if err != nil {
+		return nil, fmt.Errorf("list pending checkout sagas: %w", err)
+	}
+	for i := range sagas {
+		saga := &sagas[i]
+		if saga.Status == model.CheckoutSagaStarted {
+			s.settleStaleCheckoutSaga(ctx, saga)
+			continue
+		}
+		if s.compensateCheckoutSaga(ctx, saga, "Checkout compensation retry (reconcile)") {
+			result.Compensated++
+		} else {
+			result.Failed++
+		}
+	}
+
+	holdWindows := []struct {
+		methods []string
+		window  time.Duration
+	}{
+		{
+			methods: []string{orderModel.PaymentMethodVNPay, orderModel.PaymentMethodMomo, orderModel.PaymentMethodBankTransfer},
+			window:  model.OnlinePaymentWindow,
+		},
+		{
+			methods: []string{orderModel.PaymentMethodPayLink},
+			window:  s.payLink.TTL,
+		},
+	}
+	for _, w := range holdWindows {
+		released, err := s.orderService.ReleaseExpiredReservationHolds(ctx, w.methods, now.Add(-w.window-s.sagaPolicy.HoldGrace), reconcileHoldLimit)
+		result.HoldsReleased += released
+		if err != nil {
+			return result, fmt.Errorf("release expired reservation holds: %w", err)
+		}
+	}
+	reservationHoldsReleasedTotal.Add(float64(result.HoldsReleased))
+
+	result.Pruned, err = s.repository.DeleteFinishedCheckoutSagas(ctx, now.Add(-model.CheckoutSagaRetention))
+	if err != nil {
+		return result, fmt.Errorf("delete finished checkout sagas: %w", err)
+	}
+
+	return result, nil
+}
+
+// settleStaleCheckoutSaga đóng saga kẹt theo việc đơn đặt trước có tồn tại hay không
+func (s *CartService) settleStaleCheckoutSaga(ctx context.Context, saga *model.CheckoutSaga) {
+	status, outcome := model.CheckoutSagaCompleted, model.CheckoutSagaCompleted
+	if !saga.OrderExists {
+		status, outcome = model.CheckoutSagaCompensated, "abandoned"
+	}
+	if err := s.repository.UpdateCheckoutSagaStatus(ctx, saga.ID, status, saga.Step, nil); err != nil {
+		logger.Error("Failed to settle stale checkout saga", err)
+		return
+	}
+	checkoutSagaTotal.WithLabelValues(outcome).Inc()
+}
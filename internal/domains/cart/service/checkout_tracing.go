@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceCheckoutPhases dựng span con cho từng phase từ Timestamp + DurationMs đã đo trong response
+// (query DB/Redis của phase nằm dưới span "checkout", phase span cho thấy thời gian từng bước)
+func traceCheckoutPhases(ctx context.Context, response *model.CheckoutResponse) {
+	if response == nil || !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+	for _, phase := range response.Phases {
+		attrs := []attribute.KeyValue{
+			attribute.String("checkout.phase", phase.Phase),
+			attribute.String("checkout.phase.status", phase.Status),
+		}
+		if len(phase.Errors) > 0 {
+			attrs = append(attrs, attribute.String("checkout.phase.error_code", phase.Errors[0].Code))
+		}
+		_, span := tracing.Start(ctx, "checkout.phase "+phase.Phase,
+			trace.WithTimestamp(phase.Timestamp),
+			trace.WithAttributes(attrs...),
+		)
+		span.End(trace.WithTimestamp(phase.Timestamp.Add(time.Duration(phase.DurationMs * float64(time.Millisecond)))))
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	bookModel "bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// ITEM_DISCONTINUED (sách ngừng bán / đã gộp)
+// ========================================
+
+// suggestReplacement hook gợi ý sách thay thế (book service quyết định nguồn gợi ý).
+// Lỗi chỉ làm mất gợi ý, không chặn luồng giỏ hàng
+func (s *CartService) suggestReplacement(ctx context.Context, bookID uuid.UUID) *bookModel.ReplacementSuggestion {
+	replacement, err := s.bookService.SuggestReplacement(ctx, bookID)
+	if err != nil {
+		logger.Error("Failed to suggest replacement for discontinued book", err)
+		return nil
+	}
+	return replacement
+}
+
+func (s *CartService) discontinuedError(ctx context.Context, bookID uuid.UUID) error {
+	return &model.ItemDiscontinuedError{
+		BookID:      bookID,
+		Replacement: s.suggestReplacement(ctx, bookID),
+	}
+}
+
+// discontinuedWarning cảnh báo khi restore / reorder đơn có sách đã ngừng bán
+func discontinuedWarning(bookID uuid.UUID, title string, replacement *bookModel.ReplacementSuggestion) model.CartValidationWarning {
+	details := map[string]interface{}{"book_id": bookID}
+	if replacement != nil {
+		details["replacement"] = replacement
+	}
+	return model.CartValidationWarning{
+		Code:    model.ErrCheckoutItemDiscontinued,
+		Message: fmt.Sprintf("%s has been discontinued", title),
+		Details: details,
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var cartsRenewedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "carts_renewed_total",
+	Help: "Cart expirations extended on customer activity.",
+})
+
+// =====================================================
+// CART EXPIRATION (keep-alive)
+// =====================================================
+
+// renewCart gia hạn giỏ theo policy khi khách hoạt động (best effort: lỗi chỉ log)
+// Gia hạn trong RenewInterval gần nhất → bỏ qua, không UPDATE / không phát event
+func (s *CartService) renewCart(ctx context.Context, cart *model.Cart) {
+	now := time.Now()
+	if !s.expiration.ShouldRenew(cart, now) {
+		return
+	}
+
+	previous := cart.ExpiresAt
+	expiresAt := s.expiration.ExpiresAt(cart.UserID, now)
+	if err := s.repository.UpdateExpiration(ctx, cart.ID, expiresAt); err != nil {
+		// Log warning but don't fail request
+		logger.Error("Failed to update cart expiration", err)
+		return
+	}
+	cart.ExpiresAt = expiresAt
+	cartsRenewedTotal.Inc()
+
+	s.enqueueTrackEvent(ctx, analyticsModel.Event{
+		EventType: analyticsModel.EventCartRenewed,
+		SessionID: cart.SessionID,
+		UserID:    cart.UserID,
+		Properties: map[string]interface{}{
+			"cart_id":             cart.ID,
+			"items_count":         cart.ItemsCount,
+			"previous_expires_at": previous,
+			"expires_at":          expiresAt,
+			// Khách quay lại sau khi đã được nhắc giỏ sắp hết hạn
+			"after_warning": s.expiration.WarnBefore > 0 && previous.Sub(now) <= s.expiration.WarnBefore,
+		},
+		OccurredAt: now,
+	})
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// =====================================================
+// GIFT-WITH-PURCHASE
+// =====================================================
+// Dòng quà (cart_items.gift_promotion_id, price = 0) do hệ thống quản lý:
+// - Giỏ đạt điều kiện của gift_promotions + sách quà đủ tồn → tự thêm
+// - Hết điều kiện / chương trình tắt / hết hàng quà → tự gỡ
+// price = 0 nên subtotal (trigger DB), giảm giá và order total không bị ảnh hưởng.
+// Đồng bộ sau mỗi thay đổi giỏ (best-effort) và bắt buộc trước checkout.
+
+// syncGiftItems đồng bộ dòng quà với điều kiện hiện tại của giỏ
+func (s *CartService) syncGiftItems(ctx context.Context, cartID uuid.UUID) error {
+	eligible, err := s.repository.GetEligibleGiftPromotions(ctx, cartID)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.repository.GetCheckoutItems(ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	giftLines := make(map[uuid.UUID]*model.CheckoutCartItem)
+	paidQty := make(map[uuid.UUID]int, len(items))
+	stockByBook := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		stockByBook[item.BookID] = item.TotalStock
+		if item.IsGift() {
+			giftLines[*item.GiftPromotionID] = item
+			continue
+		}
+		paidQty[item.BookID] += item.Quantity
+	}
+
+	keep := make(map[uuid.UUID]struct{}, len(eligible))
+	for _, gift := range eligible {
+		stock, ok := stockByBook[gift.GiftBookID]
+		if !ok {
+			if stock, err = s.getTotalAvailableStock(ctx, gift.GiftBookID); err != nil {
+				return err
+			}
+		}
+		// Sách quà cũng đang được mua → tồn phải đủ cho cả 2 dòng
+		if stock < paidQty[gift.GiftBookID]+gift.GiftQuantity {
+			continue
+		}
+
+		keep[gift.ID] = struct{}{}
+		if line, ok := giftLines[gift.ID]; ok && line.BookID == gift.GiftBookID && line.Quantity == gift.GiftQuantity {
+			continue
+		}
+		if err := s.repository.UpsertGiftItem(ctx, cartID, gift); err != nil {
+			return err
+		}
+	}
+
+	for giftID, line := range giftLines {
+		if _, ok := keep[giftID]; ok {
+			continue
+		}
+		if err := s.repository.DeleteItem(ctx, line.ID); err != nil {
+			return fmt.Errorf("failed to remove gift item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// refreshGiftItems đồng bộ quà sau khi giỏ thay đổi, lỗi chỉ log (checkout sẽ đồng bộ lại)
+func (s *CartService) refreshGiftItems(ctx context.Context, cartID uuid.UUID) {
+	if err := s.syncGiftItems(ctx, cartID); err != nil {
+		logger.Error("Failed to sync gift items", err)
+	}
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"bookstore-backend/internal/domains/cart/model"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type ServiceInterface interface {
@@ -18,6 +20,10 @@ type ServiceInterface interface {
 	// Validates: book exists, has stock
 	AddItem(ctx context.Context, cartID uuid.UUID, req model.AddToCartRequest) (*model.CartItemResponse, error)
 
+	// GrantWishlistPrice giữ giá lúc thêm wishlist cho sách sắp thêm vào giỏ (gọi trước AddItem)
+	// Returns: nil, nil khi tính năng tắt / không đủ điều kiện / hết lượt (sách vẫn thêm với giá thường)
+	GrantWishlistPrice(ctx context.Context, cartID, userID, bookID uuid.UUID, wishlistPrice decimal.Decimal, wishlistedAt time.Time) (*model.PriceOverride, error)
+
 	// ListItems returns paginated cart items with book details
 	ListItems(ctx context.Context, cartID uuid.UUID, page int, limit int) (*model.CartResponse, error)
 
@@ -45,6 +51,20 @@ type ServiceInterface interface {
 	// Returns: error if item not found
 	RemoveItem(ctx context.Context, cartID uuid.UUID, itemID uuid.UUID) error
 
+	// BulkUpdateItems applies multiple quantity updates/removals in ONE transaction
+	// quantity = 0 → removes item; all-or-nothing (1 item lỗi → rollback toàn bộ)
+	// Returns: refreshed cart with items
+	BulkUpdateItems(ctx context.Context, cartID uuid.UUID, req model.BulkUpdateCartItemsRequest) (*model.CartResponse, error)
+
+	// RestoreCartFromOrder rebuilds cart items from a recently cancelled order (order_items snapshot)
+	// Validates current price/stock: price changed → warning, thiếu hàng → giảm quantity hoặc bỏ qua
+	// Idempotent: book đã có trong cart → quantity = max(cart, order)
+	RestoreCartFromOrder(ctx context.Context, userID uuid.UUID, orderID uuid.UUID) (*model.OrderToCartResponse, error)
+
+	// ReorderToCart copies items of a past order (any status) into the user's cart for editing before checkout
+	// Cùng rule validate/merge với RestoreCartFromOrder, không tạo order
+	ReorderToCart(ctx context.Context, userID uuid.UUID, orderID uuid.UUID) (*model.OrderToCartResponse, error)
+
 	// ClearCart removes all items from cart but keeps cart itself
 	// Used when user wants to empty cart
 	// Returns: error if failed
@@ -54,6 +74,9 @@ type ServiceInterface interface {
 	// Does NOT modify cart
 	ValidateCart(ctx context.Context, cartID uuid.UUID, userId uuid.UUID) (*model.CartValidationResult, error)
 
+	// GetProgress - còn thiếu bao nhiêu để đạt đơn tối thiểu / freeship (message theo locale)
+	GetProgress(ctx context.Context, cartID uuid.UUID, locale string) (*model.CartProgressResponse, error)
+
 	// ApplyPromoCode applies promo code to cart
 	// Returns: discount info if valid, error if invalid/expired
 	ApplyPromoCode(ctx context.Context, cartID uuid.UUID, promoCode string, userId uuid.UUID) (*model.ApplyPromoResponse, error)
@@ -79,4 +102,31 @@ type ServiceInterface interface {
 	//   7. PAYMENT_PROCESSING - Process payment (async ok)
 	//   8. CLEANUP - Clear cart, send confirmations
 	Checkout(ctx context.Context, userID uuid.UUID, cartID uuid.UUID, req model.CheckoutRequest) (*model.CheckoutResponse, error)
+
+	// ===== ADMIN CART (nhân viên đặt đơn thay khách qua điện thoại) =====
+
+	// GetCustomerCart lấy (hoặc tạo) giỏ của khách, khách không tồn tại → ErrCustomerNotFound
+	GetCustomerCart(ctx context.Context, customerID uuid.UUID) (*model.CartResponse, error)
+
+	// GetCustomerCartID cart ID của khách (tạo nếu chưa có)
+	GetCustomerCartID(ctx context.Context, customerID uuid.UUID) (uuid.UUID, error)
+
+	// ApplyPromoCodeForCustomer áp promo vào giỏ khách; override = bỏ qua điều kiện promo (quyền admin)
+	ApplyPromoCodeForCustomer(ctx context.Context, cartID, customerID, staffID uuid.UUID, promoCode string, override bool) (*model.ApplyPromoResponse, error)
+
+	// CheckoutForCustomer checkout thay khách (pay_link → gửi link thanh toán cho khách)
+	CheckoutForCustomer(ctx context.Context, staffID, customerID uuid.UUID, req model.AssistedCheckoutRequest) (*model.CheckoutResponse, error)
+
+	// ===== CHECKOUT FAILURE DIAGNOSTICS (CSKH tra vì sao checkout thất bại) =====
+
+	// ListCheckoutFailures lọc theo user / cart / phase, mới nhất trước
+	ListCheckoutFailures(ctx context.Context, req model.ListCheckoutFailuresRequest) (*model.ListCheckoutFailuresResponse, error)
+
+	// GetCheckoutFailure chi tiết 1 lần thất bại (kèm CheckoutResponse đầy đủ), không có → ErrCheckoutFailureNotFound
+	GetCheckoutFailure(ctx context.Context, id uuid.UUID) (*model.CheckoutFailure, error)
+
+	// ===== CHECKOUT SAGA =====
+
+	// ReconcileCheckouts đóng saga kẹt, bù trừ lại saga lỗi, trả tồn đơn chưa thanh toán quá hạn (job định kỳ)
+	ReconcileCheckouts(ctx context.Context) (*model.CheckoutReconcileResult, error)
 }
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// WISHLIST PRICE GUARANTEE
+// =====================================================
+// Wishlist service gọi GrantWishlistPrice trước AddItem khi chuyển sách sang giỏ.
+// Không đủ điều kiện → không cấp, sách vẫn vào giỏ với giá thường (không trả lỗi cho khách).
+
+var wishlistPriceOverridesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "wishlist_price_overrides_total",
+	Help: "Wishlist price guarantee decisions by outcome.",
+}, []string{"outcome"})
+
+const (
+	priceOverrideGranted       = "granted"
+	priceOverrideOutsideWindow = "outside_window"
+	priceOverrideNoIncrease    = "no_price_increase"
+	priceOverrideTooDeep       = "discount_too_deep"
+	priceOverrideQuotaExceeded = "quota_exceeded"
+)
+
+// GrantWishlistPrice implements ServiceInterface.GrantWishlistPrice
+// Trả nil, nil khi tính năng tắt hoặc sách không đủ điều kiện
+func (s *CartService) GrantWishlistPrice(
+	ctx context.Context,
+	cartID, userID, bookID uuid.UUID,
+	wishlistPrice decimal.Decimal,
+	wishlistedAt time.Time,
+) (*model.PriceOverride, error) {
+	policy := s.wishlistPrice
+	if !policy.Enabled || userID == uuid.Nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if now.Sub(wishlistedAt) > policy.Window {
+		wishlistPriceOverridesTotal.WithLabelValues(priceOverrideOutsideWindow).Inc()
+		return nil, nil
+	}
+
+	cart, err := s.repository.GetByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart == nil || cart.UserID == nil || *cart.UserID != userID {
+		return nil, model.ErrCartNotFound
+	}
+
+	book, err := s.bookService.GetBookDetail(ctx, bookID.String())
+	if err != nil {
+		return nil, fmt.Errorf("book not found: %w", err)
+	}
+	if !wishlistPrice.LessThan(book.Price) {
+		wishlistPriceOverridesTotal.WithLabelValues(priceOverrideNoIncrease).Inc()
+		return nil, nil
+	}
+	// Giá wishlist quá thấp so với hiện tại thường là giá nhập sai lúc đó → không giữ
+	maxDiscount := book.Price.Mul(decimal.NewFromInt(int64(policy.MaxDiscountPercent))).Div(decimal.NewFromInt(100))
+	if book.Price.Sub(wishlistPrice).GreaterThan(maxDiscount) {
+		wishlistPriceOverridesTotal.WithLabelValues(priceOverrideTooDeep).Inc()
+		return nil, nil
+	}
+
+	granted, err := s.repository.CountPriceOverridesSince(ctx, userID, now.Add(-policy.Period))
+	if err != nil {
+		return nil, err
+	}
+	if granted >= policy.MaxPerUser {
+		wishlistPriceOverridesTotal.WithLabelValues(priceOverrideQuotaExceeded).Inc()
+		logger.Info("Wishlist price guarantee quota exceeded", map[string]interface{}{
+			"user_id": userID,
+			"book_id": bookID,
+			"granted": granted,
+		})
+		return nil, nil
+	}
+
+	override, created, err := s.repository.CreatePriceOverride(ctx, &model.PriceOverride{
+		CartID:      cartID,
+		UserID:      userID,
+		BookID:      bookID,
+		Source:      model.PriceOverrideSourceWishlist,
+		UnitPrice:   wishlistPrice,
+		ListPrice:   book.Price,
+		MaxQuantity: policy.MaxQuantity,
+		ExpiresAt:   now.Add(policy.HoldTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		wishlistPriceOverridesTotal.WithLabelValues(priceOverrideGranted).Inc()
+	}
+	return override, nil
+}
+
+// priceOverridesFor override đang active của giỏ theo book_id.
+// Lỗi đọc → map rỗng (giá thường), không chặn thao tác giỏ
+func (s *CartService) priceOverridesFor(ctx context.Context, cartID uuid.UUID) map[uuid.UUID]*model.PriceOverride {
+	overrides := make(map[uuid.UUID]*model.PriceOverride)
+	if !s.wishlistPrice.Enabled {
+		return overrides
+	}
+	list, err := s.repository.ListActivePriceOverrides(ctx, cartID)
+	if err != nil {
+		logger.Error("Failed to list cart price overrides", err)
+		return overrides
+	}
+	for i := range list {
+		overrides[list[i].BookID] = &list[i]
+	}
+	return overrides
+}
+
+// guaranteedUnitPrice đơn giá lưu vào cart_items.price khi sách có giá giữ
+func guaranteedUnitPrice(overrides map[uuid.UUID]*model.PriceOverride, bookID uuid.UUID, tierPrice decimal.Decimal, quantity int, now time.Time) decimal.Decimal {
+	price, _ := overrides[bookID].UnitPriceFor(tierPrice, quantity, now)
+	return price
+}
+
+// applyPriceGuarantees gắn hạn giữ giá vào item response (dòng đang hưởng giá giữ)
+func applyPriceGuarantees(overrides map[uuid.UUID]*model.PriceOverride, items []model.CartItemResponse, now time.Time) {
+	for i := range items {
+		override := overrides[items[i].BookID]
+		if override == nil || items[i].IsGift {
+			continue
+		}
+		if _, ok := override.UnitPriceFor(items[i].CurrentPrice, items[i].Quantity, now); ok && items[i].Price.Equal(override.UnitPrice) {
+			expiresAt := override.ExpiresAt
+			items[i].PriceGuaranteedUntil = &expiresAt
+		}
+	}
+}
+
+// repriceExpiredPriceOverrides đóng override quá hạn và trả dòng giỏ về giá thường (theo bậc).
+// Gọi trước khi đọc subtotal để checkout / hiển thị giỏ không giữ giá quá hạn.
+func (s *CartService) repriceExpiredPriceOverrides(ctx context.Context, cartID uuid.UUID) (bool, error) {
+	if !s.wishlistPrice.Enabled {
+		return false, nil
+	}
+	now := time.Now()
+	expired, err := s.repository.ExpirePriceOverrides(ctx, cartID, now)
+	if err != nil || len(expired) == 0 {
+		return false, err
+	}
+	expiredBooks := make(map[uuid.UUID]struct{}, len(expired))
+	for _, bookID := range expired {
+		expiredBooks[bookID] = struct{}{}
+	}
+
+	items, err := s.repository.GetCheckoutItems(ctx, cartID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get cart items: %w", err)
+	}
+	tiers, err := s.priceTiersFor(ctx, expired)
+	if err != nil {
+		return false, err
+	}
+
+	repriced := false
+	for _, item := range items {
+		if _, ok := expiredBooks[item.BookID]; !ok || item.IsGift() {
+			continue
+		}
+		price := tierUnitPrice(tiers, item.BookID, item.CurrentPrice, item.Quantity)
+		if price.Equal(item.Price) {
+			continue
+		}
+		if err := s.repository.UpdateItem(ctx, &model.CartItem{
+			ID:        item.ID,
+			Quantity:  item.Quantity,
+			Price:     price,
+			UpdatedAt: now,
+		}); err != nil {
+			return repriced, err
+		}
+		repriced = true
+	}
+	return repriced, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	bookModel "bookstore-backend/internal/domains/book/model"
+	"bookstore-backend/internal/domains/cart/model"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// QUANTITY TIER PRICING
+// =====================================================
+// cart_items.price lưu đơn giá ĐÃ áp bậc → subtotal (trigger DB), promo và order dùng thẳng giá này.
+// Mọi chỗ ghi cart item (add, update, bulk, merge, restore) phải tính lại đơn giá theo quantity mới.
+
+// priceTiersFor lấy bậc giá của các sách (1 query)
+func (s *CartService) priceTiersFor(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]bookModel.PriceTiers, error) {
+	tiers, err := s.priceTierService.GetTiersForBooks(ctx, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price tiers: %w", err)
+	}
+	return tiers, nil
+}
+
+// tierUnitPrice đơn giá lưu vào cart_items.price cho quantity hiện tại
+func tierUnitPrice(tiers map[uuid.UUID]bookModel.PriceTiers, bookID uuid.UUID, listPrice decimal.Decimal, quantity int) decimal.Decimal {
+	unitPrice, _ := tiers[bookID].Apply(listPrice, quantity)
+	return unitPrice
+}
+
+// applyPriceTiers gắn bậc giá / tiết kiệm vào từng item, trả về tổng tiết kiệm
+func (s *CartService) applyPriceTiers(ctx context.Context, items []model.CartItemResponse) (decimal.Decimal, error) {
+	if len(items) == 0 {
+		return decimal.Zero, nil
+	}
+
+	bookIDs := make([]uuid.UUID, len(items))
+	for i := range items {
+		bookIDs[i] = items[i].BookID
+	}
+	tiers, err := s.priceTiersFor(ctx, bookIDs)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	savings := decimal.Zero
+	for i := range items {
+		if items[i].IsGift {
+			continue // Dòng quà 0đ: không có bậc giá
+		}
+		items[i].ApplyPriceTiers(tiers[items[i].BookID])
+		savings = savings.Add(items[i].Savings)
+	}
+	return savings, nil
+}
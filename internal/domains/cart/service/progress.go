@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/shared/locale"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// CHECKOUT PROGRESS (ĐƠN TỐI THIỂU / FREESHIP)
+// =====================================================
+
+var progressMessages = map[string]struct {
+	belowMinimum     string
+	toFreeShipping   string
+	freeShippingDone string
+}{
+	locale.Vietnamese: {
+		belowMinimum:     "Mua thêm %s để đạt giá trị đơn tối thiểu %s",
+		toFreeShipping:   "Mua thêm %s để được miễn phí vận chuyển",
+		freeShippingDone: "Đơn hàng của bạn được miễn phí vận chuyển",
+	},
+	locale.English: {
+		belowMinimum:     "Add %s more to reach the minimum order of %s",
+		toFreeShipping:   "Add %s more for free shipping",
+		freeShippingDone: "Your order qualifies for free shipping",
+	},
+}
+
+// GetProgress implements ServiceInterface.GetProgress
+func (s *CartService) GetProgress(ctx context.Context, cartID uuid.UUID, loc string) (*model.CartProgressResponse, error) {
+	cart, err := s.repository.GetByID(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart == nil {
+		return nil, model.ErrCartNotFound
+	}
+
+	policy := s.checkoutPolicy
+	subtotal := cart.Subtotal
+
+	resp := &model.CartProgressResponse{
+		Subtotal:              subtotal,
+		MinOrderAmount:        policy.MinOrderAmount,
+		MeetsMinimum:          policy.MeetsMinimum(subtotal),
+		AmountToMinimum:       policy.AmountToMinimum(subtotal),
+		ShippingFee:           policy.ShippingFeeFor(subtotal),
+		FreeShippingThreshold: policy.FreeShippingThreshold,
+		FreeShipping:          policy.QualifiesFreeShipping(subtotal),
+		AmountToFreeShipping:  policy.AmountToFreeShipping(subtotal),
+	}
+	resp.ShippingDiscount = policy.ShippingDiscountFor(subtotal, resp.ShippingFee, decimal.Zero)
+	resp.FreeShippingProgress = progressPercent(subtotal, policy.FreeShippingThreshold, resp.FreeShipping)
+
+	msgs := progressMessages[locale.Normalize(loc)]
+	switch {
+	case !resp.MeetsMinimum:
+		resp.Message = fmt.Sprintf(msgs.belowMinimum,
+			formatVND(loc, resp.AmountToMinimum), formatVND(loc, policy.MinOrderAmount))
+	case resp.AmountToFreeShipping != nil && !resp.FreeShipping:
+		resp.Message = fmt.Sprintf(msgs.toFreeShipping, formatVND(loc, *resp.AmountToFreeShipping))
+	case resp.FreeShipping && policy.ShippingFee.IsPositive() && subtotal.IsPositive():
+		resp.Message = msgs.freeShippingDone
+	}
+
+	return resp, nil
+}
+
+// progressPercent % tiến độ tới ngưỡng freeship (làm tròn xuống, tối đa 100)
+func progressPercent(subtotal, threshold decimal.Decimal, reached bool) int {
+	if reached {
+		return 100
+	}
+	if !threshold.IsPositive() {
+		return 0
+	}
+	percent := subtotal.Mul(decimal.NewFromInt(100)).Div(threshold).Floor().IntPart()
+	if percent > 100 {
+		percent = 100
+	}
+	return int(percent)
+}
+
+// formatVND làm tròn lên đồng để "mua thêm X" luôn đủ đạt ngưỡng
+func formatVND(loc string, amount decimal.Decimal) string {
+	return locale.FormatVND(loc, amount.Ceil().IntPart())
+}
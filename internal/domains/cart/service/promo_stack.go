@@ -0,0 +1,109 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/cart/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	promoModel "bookstore-backend/internal/domains/promotion/model"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ========================================
+// PROMOTION STACKING (mã nhập tay + promotion tự động)
+// ========================================
+// Cart chỉ tính để hiển thị khi áp mã; order service tự chọn lại tập promotion lúc tạo đơn
+// (cùng promoModel.ResolveStack) nên giỏ cũ không làm sai tiền đơn.
+
+// resolvePromoStack mã vừa nhập + promotion tự động đủ điều kiện với giỏ hiện tại
+// Lỗi đọc promotion tự động không chặn áp mã: chỉ áp mã
+func (s *CartService) resolvePromoStack(ctx context.Context, cart *model.Cart, code *model.PromotionValidationResult, shippingFee decimal.Decimal) []model.AppliedPromotion {
+	codeApplied := newAppliedPromotion(s.calculatePromoDiscount(cart.Subtotal, code), code, shippingFee)
+	if cart.UserID == nil {
+		return []model.AppliedPromotion{codeApplied}
+	}
+
+	automatic, err := s.repository.ListAutomaticPromos(ctx)
+	if err != nil {
+		logger.Error("Failed to list automatic promotions", err)
+		return []model.AppliedPromotion{codeApplied}
+	}
+
+	byID := map[uuid.UUID]model.AppliedPromotion{codeApplied.PromotionID: codeApplied}
+	candidates := make([]promoModel.StackCandidate, 0, len(automatic))
+	for _, p := range automatic {
+		result, err := s.evaluatePromo(ctx, p, cart.Subtotal, *cart.UserID)
+		if err != nil || !result.IsValid {
+			continue
+		}
+		applied := newAppliedPromotion(s.calculatePromoDiscount(cart.Subtotal, result), result, shippingFee)
+		byID[applied.PromotionID] = applied
+		candidates = append(candidates, appliedStackCandidate(result, applied))
+	}
+
+	codeCandidate := appliedStackCandidate(code, codeApplied)
+	stack := promoModel.ResolveStack(&codeCandidate, candidates)
+
+	applied := make([]model.AppliedPromotion, 0, len(stack))
+	for _, c := range stack {
+		applied = append(applied, byID[c.PromotionID])
+	}
+	return applied
+}
+
+func newAppliedPromotion(discount decimal.Decimal, r *model.PromotionValidationResult, shippingFee decimal.Decimal) model.AppliedPromotion {
+	return model.AppliedPromotion{
+		PromotionID:      r.PromotionID,
+		Code:             r.Code,
+		Name:             r.Name,
+		Automatic:        r.IsAutomatic,
+		Stackable:        r.Stackable,
+		DiscountType:     r.DiscountType,
+		DiscountValue:    r.DiscountValue,
+		MaxDiscount:      r.MaxDiscount,
+		DiscountAmount:   discount,
+		ShippingDiscount: orderModel.ShippingPromoDiscount(r.DiscountType, r.DiscountValue, r.MaxDiscount, shippingFee),
+	}
+}
+
+func appliedStackCandidate(r *model.PromotionValidationResult, a model.AppliedPromotion) promoModel.StackCandidate {
+	return promoModel.StackCandidate{
+		PromotionID: r.PromotionID,
+		Code:        r.Code,
+		Automatic:   r.IsAutomatic,
+		Stackable:   r.Stackable,
+		Priority:    r.Priority,
+		Discount:    a.DiscountAmount.Add(a.ShippingDiscount),
+	}
+}
+
+// sumAppliedPromotions tổng giảm tiền hàng (≤ subtotal) và giảm ship (≤ phí ship)
+func sumAppliedPromotions(applied []model.AppliedPromotion, subtotal, shippingFee decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	discount, shippingDiscount := decimal.Zero, decimal.Zero
+	for _, a := range applied {
+		discount = discount.Add(a.DiscountAmount)
+		shippingDiscount = shippingDiscount.Add(a.ShippingDiscount)
+	}
+	return decimal.Min(discount, subtotal), decimal.Min(shippingDiscount, shippingFee)
+}
+
+// appliedPromotionsFromMetadata đọc lại tập promotion đã áp từ carts.promo_metadata (JSONB)
+// ok = false với giỏ áp mã trước khi có stacking (chỉ có key top-level của mã)
+func appliedPromotionsFromMetadata(metadata map[string]interface{}) ([]model.AppliedPromotion, bool) {
+	raw, ok := metadata[model.PromoMetadataApplied]
+	if !ok || raw == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false
+	}
+	var applied []model.AppliedPromotion
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return nil, false
+	}
+	return applied, true
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"bookstore-backend/internal/domains/cart/model"
+	taxModel "bookstore-backend/internal/domains/tax/model"
+
+	"github.com/shopspring/decimal"
+)
+
+// calculateCheckoutTax thuế của giỏ theo tỉnh giao hàng; chưa wire engine → 0% (hành vi cũ)
+func (s *CartService) calculateCheckoutTax(
+	ctx context.Context,
+	province string,
+	items []*model.CheckoutCartItem,
+	discount decimal.Decimal,
+) (*taxModel.Result, error) {
+	if s.taxCalculator == nil {
+		return taxModel.ZeroResult(), nil
+	}
+
+	taxable := make([]taxModel.TaxableItem, 0, len(items))
+	for _, item := range items {
+		taxable = append(taxable, taxModel.TaxableItem{
+			BookID:     item.BookID,
+			CategoryID: item.CategoryID,
+			Amount:     item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))),
+		})
+	}
+
+	return s.taxCalculator.Calculate(ctx, taxModel.CalculateRequest{
+		Province: province,
+		Items:    taxable,
+		Discount: discount,
+	})
+}
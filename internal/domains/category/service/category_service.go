@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"bookstore-backend/internal/domains/book/model"
 	"bookstore-backend/internal/domains/category"
+	"bookstore-backend/pkg/cache"
 	"bookstore-backend/pkg/logger"
 
 	"github.com/google/uuid"
@@ -14,11 +16,15 @@ import (
 
 type categoryServiceImpl struct {
 	repository category.CategoryRepository
+	cache      cache.Cache
+	loader     *cache.Loader // category tree: menu mọi trang đều gọi → chống stampede khi hết hạn
 }
 
-func NewCategoryService(repo category.CategoryRepository) category.CategoryService {
+func NewCategoryService(repo category.CategoryRepository, redisCache cache.Cache) category.CategoryService {
 	return &categoryServiceImpl{
 		repository: repo,
+		cache:      redisCache,
+		loader:     cache.NewLoader(redisCache),
 	}
 }
 
@@ -155,6 +161,7 @@ func (s *categoryServiceImpl) Create(ctx context.Context, req *category.CreateCa
 	logger.Info("create category", map[string]interface{}{
 		"created.ID": fmt.Sprintf("Create: category created successfully: %s", created.ID.String()),
 	})
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
@@ -287,26 +294,44 @@ func (s *categoryServiceImpl) GetAll(
 }
 
 // ========== READ: GetTree ==========
+const (
+	categoryTreeCacheKey = "categories:tree"
+	categoryTreeCacheTTL = 30 * time.Minute
+)
+
 func (s *categoryServiceImpl) GetTree(ctx context.Context) ([]category.CategoryTreeItemResp, error) {
-	// ========== Fetch from Repository ==========
-	// Repository.GetTree() uses Materialized View
-	// Already pre-computed, just SELECT
-	entities, err := s.repository.GetTree(ctx)
+	var resps []category.CategoryTreeItemResp
+	err := s.loader.GetOrLoad(ctx, categoryTreeCacheKey, &resps, categoryTreeCacheTTL, func(ctx context.Context) (interface{}, error) {
+		// ========== Fetch from Repository ==========
+		// Repository.GetTree() uses Materialized View
+		// Already pre-computed, just SELECT
+		entities, err := s.repository.GetTree(ctx)
+		if err != nil {
+			logger.Info("GetTree failed", map[string]interface{}{
+				"error": fmt.Sprintf("GetTree: repository get failed: %v", err),
+			})
+			return nil, fmt.Errorf("get category tree: failed to fetch")
+		}
+
+		// ========== Map to Response DTOs ==========
+		// Returns tree items (not full category response)
+		// Includes level, full_path for breadcrumb
+		return category.CategoriesToTreeItems(entities), nil
+	})
 	if err != nil {
-		logger.Info("GetTree failed", map[string]interface{}{
-			"error": fmt.Sprintf("GetTree: repository get failed: %v", err),
-		})
-		return nil, fmt.Errorf("get category tree: failed to fetch")
+		return nil, err
 	}
 
-	// ========== Map to Response DTOs ==========
-	// Returns tree items (not full category response)
-	// Includes level, full_path for breadcrumb
-	resps := category.CategoriesToTreeItems(entities)
-
 	return resps, nil
 }
 
+// invalidateTree xoá cache category tree sau khi ghi (tạo / sửa / chuyển cha / bật tắt / xoá)
+func (s *categoryServiceImpl) invalidateTree(ctx context.Context) {
+	if err := s.cache.Delete(ctx, categoryTreeCacheKey); err != nil {
+		logger.Error("Failed to invalidate category tree cache", err)
+	}
+}
+
 // ========== READ: GetBreadcrumb ==========
 func (s *categoryServiceImpl) GetBreadcrumb(ctx context.Context, categoryID uuid.UUID) (*category.CategoryBreadcrumbResp, error) {
 	// ========== Validate Input ==========
@@ -464,6 +489,7 @@ func (s *categoryServiceImpl) Update(
 	// ========== Map to Response DTO ==========
 	resp := category.CategoryToResp(updated)
 
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
@@ -570,6 +596,7 @@ func (s *categoryServiceImpl) MoveToParent(
 	// ========== Map to Response DTO ==========
 	resp := category.CategoryToResp(updated)
 
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
@@ -622,6 +649,7 @@ func (s *categoryServiceImpl) Activate(ctx context.Context, categoryID uuid.UUID
 	// ========== Map to Response DTO ==========
 	resp := category.CategoryToResp(updated)
 
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
@@ -660,6 +688,7 @@ func (s *categoryServiceImpl) Deactivate(ctx context.Context, categoryID uuid.UU
 	// ========== Map to Response DTO ==========
 	resp := category.CategoryToResp(updated)
 
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
@@ -712,6 +741,7 @@ func (s *categoryServiceImpl) Delete(ctx context.Context, categoryID uuid.UUID)
 	}
 
 	// ========== Success ==========
+	s.invalidateTree(ctx)
 	return nil
 }
 
@@ -742,6 +772,7 @@ func (s *categoryServiceImpl) BulkActivate(ctx context.Context, req *category.Bu
 		FailedItems: []category.BulkActionFailedItem{},
 	}
 
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
@@ -766,6 +797,7 @@ func (s *categoryServiceImpl) BulkDeactivate(ctx context.Context, req *category.
 		Failed:      failed,
 		FailedItems: []category.BulkActionFailedItem{},
 	}
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
@@ -841,6 +873,7 @@ func (s *categoryServiceImpl) BulkDelete(ctx context.Context, req *category.Bulk
 		FailedItems: failedItems,
 	}
 
+	s.invalidateTree(ctx)
 	return resp, nil
 }
 
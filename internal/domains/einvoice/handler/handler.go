@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/einvoice/model"
+	"bookstore-backend/internal/domains/einvoice/service"
+)
+
+// =====================================================
+// E-INVOICE HANDLER
+// =====================================================
+
+type EInvoiceHandler struct {
+	einvoiceService service.ServiceInterface
+}
+
+func NewEInvoiceHandler(einvoiceService service.ServiceInterface) *EInvoiceHandler {
+	return &EInvoiceHandler{
+		einvoiceService: einvoiceService,
+	}
+}
+
+// =====================================================
+// CUSTOMER
+// =====================================================
+
+// RequestInvoice yêu cầu xuất hoá đơn điện tử (có MST → hoá đơn cho doanh nghiệp)
+// POST /api/v1/orders/:id/e-invoice
+func (h *EInvoiceHandler) RequestInvoice(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	orderID, ok := parseUUIDParam(c, "id", "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	var req model.RequestEInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	invoice, err := h.einvoiceService.RequestInvoice(c.Request.Context(), orderID, &userID, userID, req)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, invoice)
+}
+
+// ListOrderInvoices lists e-invoices of own order
+// GET /api/v1/orders/:id/e-invoices
+func (h *EInvoiceHandler) ListOrderInvoices(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	orderID, ok := parseUUIDParam(c, "id", "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	invoices, err := h.einvoiceService.ListOrderInvoices(c.Request.Context(), orderID, &userID)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, invoices)
+}
+
+// =====================================================
+// ADMIN / ACCOUNTING
+// =====================================================
+
+// AdminRequestInvoice lập hoá đơn thay khách (khách gọi điện / gửi email yêu cầu)
+// POST /api/v1/admin/orders/:id/e-invoices
+func (h *EInvoiceHandler) AdminRequestInvoice(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	orderID, ok := parseUUIDParam(c, "id", "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	var req model.RequestEInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	invoice, err := h.einvoiceService.RequestInvoice(c.Request.Context(), orderID, nil, adminID, req)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, invoice)
+}
+
+// AdminListOrderInvoices lists all e-invoices of order (gốc, thay thế, điều chỉnh)
+// GET /api/v1/admin/orders/:id/e-invoices
+func (h *EInvoiceHandler) AdminListOrderInvoices(c *gin.Context) {
+	orderID, ok := parseUUIDParam(c, "id", "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	invoices, err := h.einvoiceService.ListOrderInvoices(c.Request.Context(), orderID, nil)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, invoices)
+}
+
+// ListInvoices lists e-invoices for accounting
+// GET /api/v1/admin/e-invoices?status=&invoice_type=&page=&limit=
+func (h *EInvoiceHandler) ListInvoices(c *gin.Context) {
+	var filter model.ListEInvoicesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	result, err := h.einvoiceService.ListInvoices(c.Request.Context(), filter)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, result)
+}
+
+// GetInvoice gets e-invoice
+// GET /api/v1/admin/e-invoices/:id
+func (h *EInvoiceHandler) GetInvoice(c *gin.Context) {
+	invoiceID, ok := parseUUIDParam(c, "id", "Invalid invoice ID")
+	if !ok {
+		return
+	}
+
+	invoice, err := h.einvoiceService.GetInvoice(c.Request.Context(), invoiceID)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, invoice)
+}
+
+// ReissueInvoice lập hoá đơn thay thế
+// POST /api/v1/admin/e-invoices/:id/reissue
+func (h *EInvoiceHandler) ReissueInvoice(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	invoiceID, ok := parseUUIDParam(c, "id", "Invalid invoice ID")
+	if !ok {
+		return
+	}
+
+	var req model.ReissueEInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	invoice, err := h.einvoiceService.ReissueInvoice(c.Request.Context(), adminID, invoiceID, req)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, invoice)
+}
+
+// AdjustInvoice lập hoá đơn điều chỉnh tăng / giảm
+// POST /api/v1/admin/e-invoices/:id/adjust
+func (h *EInvoiceHandler) AdjustInvoice(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	invoiceID, ok := parseUUIDParam(c, "id", "Invalid invoice ID")
+	if !ok {
+		return
+	}
+
+	var req model.AdjustEInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	invoice, err := h.einvoiceService.AdjustInvoice(c.Request.Context(), adminID, invoiceID, req)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, invoice)
+}
+
+// RetryInvoice phát hành lại hoá đơn lỗi
+// POST /api/v1/admin/e-invoices/:id/retry
+func (h *EInvoiceHandler) RetryInvoice(c *gin.Context) {
+	invoiceID, ok := parseUUIDParam(c, "id", "Invalid invoice ID")
+	if !ok {
+		return
+	}
+
+	invoice, err := h.einvoiceService.RetryInvoice(c.Request.Context(), invoiceID)
+	if err != nil {
+		statusCode, errCode := mapEInvoiceError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, invoice)
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// getUserID extracts user ID from JWT claims
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondSuccess sends success response
+func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// respondError sends error response
+func respondError(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// mapEInvoiceError maps e-invoice error to HTTP status code
+func mapEInvoiceError(err error) (int, string) {
+	var eErr *model.EInvoiceError
+	if errors.As(err, &eErr) {
+		switch eErr.Code {
+		case model.ErrCodeInvoiceNotFound, model.ErrCodeOrderNotFound:
+			return http.StatusNotFound, eErr.Code
+		case model.ErrCodeInvoiceExists, model.ErrCodeAlreadyReplaced, model.ErrCodeInvoiceNotEffective:
+			return http.StatusConflict, eErr.Code
+		case model.ErrCodeOrderNotEligible:
+			return http.StatusUnprocessableEntity, eErr.Code
+		case model.ErrCodeInvalidRequest, model.ErrCodeInvalidTaxCode:
+			return http.StatusBadRequest, eErr.Code
+		case model.ErrCodeProviderUnavailable:
+			return http.StatusServiceUnavailable, eErr.Code
+		case model.ErrCodeProviderError:
+			return http.StatusBadGateway, eErr.Code
+		default:
+			return http.StatusInternalServerError, "INTERNAL_ERROR"
+		}
+	}
+
+	return http.StatusInternalServerError, "INTERNAL_ERROR"
+}
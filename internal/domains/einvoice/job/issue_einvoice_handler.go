@@ -0,0 +1,59 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+
+	"bookstore-backend/internal/domains/einvoice/model"
+	einvoiceService "bookstore-backend/internal/domains/einvoice/service"
+)
+
+// IssueEInvoiceHandler phát hành hoá đơn điện tử qua nhà cung cấp
+type IssueEInvoiceHandler struct {
+	einvoiceService einvoiceService.ServiceInterface
+}
+
+func NewIssueEInvoiceHandler(einvoiceService einvoiceService.ServiceInterface) *IssueEInvoiceHandler {
+	return &IssueEInvoiceHandler{
+		einvoiceService: einvoiceService,
+	}
+}
+
+// ProcessTask xử lý background job phát hành hoá đơn
+func (h *IssueEInvoiceHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload struct {
+		InvoiceID string `json:"invoice_id"`
+	}
+
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		log.Error().Err(err).Msg("Failed to unmarshal IssueEInvoice payload")
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	invoiceID, err := uuid.Parse(payload.InvoiceID)
+	if err != nil {
+		return fmt.Errorf("invalid invoice id %q: %v: %w", payload.InvoiceID, err, asynq.SkipRetry)
+	}
+
+	if err := h.einvoiceService.ProcessInvoice(ctx, invoiceID); err != nil {
+		// Hoá đơn bị xoá / nhà cung cấp không còn cấu hình: retry cũng vô ích
+		if errors.Is(err, model.ErrInvoiceNotFound) || errors.Is(err, model.ErrProviderUnavailable) {
+			log.Warn().Err(err).Str("invoice_id", payload.InvoiceID).Msg("Skip e-invoice")
+			return fmt.Errorf("issue e-invoice: %v: %w", err, asynq.SkipRetry)
+		}
+
+		log.Error().
+			Err(err).
+			Str("invoice_id", payload.InvoiceID).
+			Msg("Failed to issue e-invoice")
+		return fmt.Errorf("issue e-invoice: %w", err)
+	}
+
+	return nil
+}
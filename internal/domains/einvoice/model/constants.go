@@ -0,0 +1,28 @@
+package model
+
+// Loại hoá đơn (Nghị định 123/2020: hoá đơn sai sót thì lập hoá đơn thay thế hoặc điều chỉnh)
+const (
+	TypeOriginal    = "original"    // hoá đơn gốc khách yêu cầu
+	TypeReplacement = "replacement" // thay thế hoá đơn sai (thông tin người mua, mã số thuế...)
+	TypeAdjustment  = "adjustment"  // điều chỉnh tăng / giảm tiền (giảm giá sau bán, trả hàng một phần)
+)
+
+// Trạng thái hoá đơn
+const (
+	StatusPending  = "pending"  // chờ worker phát hành qua nhà cung cấp
+	StatusIssued   = "issued"   // đã ký số + có số hoá đơn
+	StatusFailed   = "failed"   // nhà cung cấp từ chối / lỗi, admin xem lỗi rồi retry
+	StatusReplaced = "replaced" // đã bị hoá đơn thay thế (không còn hiệu lực)
+)
+
+// Trạng thái đơn được xuất hoá đơn (đã giao hoặc đã thanh toán, chưa huỷ / hoàn)
+var IneligibleOrderStatuses = []string{"cancelled", "returned"}
+
+// MaxIssueAttempts - số lần worker thử phát hành trước khi dừng retry tự động
+const MaxIssueAttempts = 5
+
+// List limits
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
@@ -0,0 +1,148 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// REQUEST DTOs
+// =====================================================
+
+// taxCodePattern - MST doanh nghiệp 10 số, chi nhánh 10 số + "-" + 3 số, cá nhân 12 số (CCCD)
+var taxCodePattern = regexp.MustCompile(`^(\d{10}(-\d{3})?|\d{12})$`)
+
+// BuyerInfo thông tin người mua in trên hoá đơn
+type BuyerInfo struct {
+	Name    string  `json:"buyer_name" binding:"required,max=255"`
+	Company *string `json:"company_name,omitempty" binding:"omitempty,max=255"`
+	TaxCode *string `json:"tax_code,omitempty" binding:"omitempty,max=14"`
+	Address string  `json:"address" binding:"required,max=500"`
+	Email   *string `json:"email,omitempty" binding:"omitempty,email,max=255" pii:"email"`
+}
+
+// Normalize trim input, chuỗi rỗng coi như không có
+func (b *BuyerInfo) Normalize() {
+	b.Name = strings.TrimSpace(b.Name)
+	b.Address = strings.TrimSpace(b.Address)
+	b.Company = trimOptional(b.Company)
+	b.TaxCode = trimOptional(b.TaxCode)
+	b.Email = trimOptional(b.Email)
+}
+
+// Validate - có MST thì phải có tên doanh nghiệp (hoá đơn xuất cho tổ chức)
+func (b *BuyerInfo) Validate() error {
+	if b.Name == "" || b.Address == "" {
+		return NewInvalidRequestError("buyer_name and address are required")
+	}
+	if b.TaxCode != nil {
+		if !taxCodePattern.MatchString(*b.TaxCode) {
+			return NewInvalidTaxCodeError(*b.TaxCode)
+		}
+		if b.Company == nil {
+			return NewInvalidRequestError("company_name is required when tax_code is provided")
+		}
+	}
+	return nil
+}
+
+// RequestEInvoiceRequest - POST /orders/:id/e-invoice (khách) | /admin/orders/:id/e-invoices (admin)
+type RequestEInvoiceRequest struct {
+	BuyerInfo
+}
+
+// ReissueEInvoiceRequest - POST /admin/e-invoices/:id/reissue
+// Lập hoá đơn thay thế; thông tin người mua bỏ trống → giữ như hoá đơn cũ
+type ReissueEInvoiceRequest struct {
+	Reason  string  `json:"reason" binding:"required,max=500"`
+	Name    *string `json:"buyer_name,omitempty" binding:"omitempty,max=255"`
+	Company *string `json:"company_name,omitempty" binding:"omitempty,max=255"`
+	TaxCode *string `json:"tax_code,omitempty" binding:"omitempty,max=14"`
+	Address *string `json:"address,omitempty" binding:"omitempty,max=500"`
+	Email   *string `json:"email,omitempty" binding:"omitempty,email,max=255" pii:"email"`
+}
+
+// ApplyTo ghi đè thông tin người mua của hoá đơn cũ
+func (r *ReissueEInvoiceRequest) ApplyTo(buyer BuyerInfo) BuyerInfo {
+	if v := trimOptional(r.Name); v != nil {
+		buyer.Name = *v
+	}
+	if v := trimOptional(r.Company); v != nil {
+		buyer.Company = v
+	}
+	if v := trimOptional(r.TaxCode); v != nil {
+		buyer.TaxCode = v
+	}
+	if v := trimOptional(r.Address); v != nil {
+		buyer.Address = *v
+	}
+	if v := trimOptional(r.Email); v != nil {
+		buyer.Email = v
+	}
+	return buyer
+}
+
+// AdjustEInvoiceRequest - POST /admin/e-invoices/:id/adjust
+// Amount đã gồm VAT: âm = điều chỉnh giảm (giảm giá sau bán, trả hàng một phần), dương = điều chỉnh tăng
+type AdjustEInvoiceRequest struct {
+	Reason string          `json:"reason" binding:"required,max=500"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+func (r *AdjustEInvoiceRequest) Validate(original *EInvoice) error {
+	if r.Amount.IsZero() {
+		return NewInvalidRequestError("amount must not be zero")
+	}
+	if r.Amount.Round(0).Cmp(r.Amount) != 0 {
+		return NewInvalidRequestError("amount must be a whole number of VND")
+	}
+	if r.Amount.IsNegative() && r.Amount.Abs().GreaterThan(original.TotalAmount) {
+		return NewInvalidRequestError("decrease amount exceeds invoice total")
+	}
+	return nil
+}
+
+// ListEInvoicesFilter - GET /admin/e-invoices?status=&invoice_type=&page=&limit=
+type ListEInvoicesFilter struct {
+	Status      string `form:"status"`
+	InvoiceType string `form:"invoice_type"`
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+}
+
+func (f *ListEInvoicesFilter) Normalize() {
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultListLimit
+	}
+	if f.Limit > MaxListLimit {
+		f.Limit = MaxListLimit
+	}
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// EInvoiceListResponse danh sách hoá đơn cho kế toán
+type EInvoiceListResponse struct {
+	Invoices []EInvoice `json:"invoices"`
+	Total    int        `json:"total"`
+	Page     int        `json:"page"`
+	Limit    int        `json:"limit"`
+}
+
+func trimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
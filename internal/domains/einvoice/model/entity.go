@@ -0,0 +1,123 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// ENTITY: EInvoice
+// =====================================================
+// Hoá đơn điện tử phát hành qua nhà cung cấp (MISA meInvoice, ...)
+// Số tiền của hoá đơn điều chỉnh là phần chênh lệch (âm = điều chỉnh giảm)
+type EInvoice struct {
+	ID                uuid.UUID  `json:"id"`
+	OrderID           uuid.UUID  `json:"order_id"`
+	OrderNumber       string     `json:"order_number,omitempty"`
+	InvoiceType       string     `json:"invoice_type"`
+	OriginalInvoiceID *uuid.UUID `json:"original_invoice_id,omitempty"`
+	Status            string     `json:"status"`
+	Provider          string     `json:"provider"`
+
+	// Mẫu số + ký hiệu + số hoá đơn (số chỉ có sau khi phát hành)
+	TemplateCode  string  `json:"template_code"`
+	InvoiceSeries string  `json:"invoice_series"`
+	InvoiceNumber *string `json:"invoice_number,omitempty"`
+	// Mã giao dịch phía nhà cung cấp + mã tra cứu cho người mua
+	TransactionID *string `json:"transaction_id,omitempty"`
+	LookupCode    *string `json:"lookup_code,omitempty"`
+
+	BuyerName    string  `json:"buyer_name"`
+	BuyerCompany *string `json:"buyer_company,omitempty"`
+	BuyerTaxCode *string `json:"buyer_tax_code,omitempty"`
+	BuyerAddress string  `json:"buyer_address"`
+	BuyerEmail   *string `json:"buyer_email,omitempty"`
+
+	AmountBeforeTax decimal.Decimal `json:"amount_before_tax"`
+	VATRate         int             `json:"vat_rate"`
+	TaxAmount       decimal.Decimal `json:"tax_amount"`
+	TotalAmount     decimal.Decimal `json:"total_amount"`
+
+	Reason       *string    `json:"reason,omitempty"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	Attempts     int        `json:"attempts"`
+	RequestedBy  *uuid.UUID `json:"requested_by,omitempty"`
+	IssuedAt     *time.Time `json:"issued_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IsEffective hoá đơn đã phát hành và chưa bị thay thế (được phép thay thế / điều chỉnh)
+func (e *EInvoice) IsEffective() bool {
+	return e.Status == StatusIssued
+}
+
+// CanRetry hoá đơn phát hành lỗi được thử lại
+func (e *EInvoice) CanRetry() bool {
+	return e.Status == StatusFailed || e.Status == StatusPending
+}
+
+// Buyer thông tin người mua in trên hoá đơn
+func (e *EInvoice) Buyer() BuyerInfo {
+	return BuyerInfo{
+		Name:    e.BuyerName,
+		Company: e.BuyerCompany,
+		TaxCode: e.BuyerTaxCode,
+		Address: e.BuyerAddress,
+		Email:   e.BuyerEmail,
+	}
+}
+
+// =====================================================
+// ORDER SNAPSHOT
+// =====================================================
+
+// OrderSnapshot dữ liệu đơn cần để lập hoá đơn
+type OrderSnapshot struct {
+	OrderID          uuid.UUID
+	OrderNumber      string
+	UserID           uuid.UUID
+	UserEmail        string
+	Status           string
+	PaymentMethod    string
+	PaymentStatus    string
+	Subtotal         decimal.Decimal
+	ShippingFee      decimal.Decimal
+	ShippingDiscount decimal.Decimal
+	DiscountAmount   decimal.Decimal
+	Total            decimal.Decimal
+	PaidAt           *time.Time
+	DeliveredAt      *time.Time
+	Items            []OrderLine
+}
+
+// OrderLine 1 dòng sách trong đơn (đơn giá đã gồm VAT)
+type OrderLine struct {
+	Title    string
+	Quantity int
+	Price    decimal.Decimal
+	Subtotal decimal.Decimal
+}
+
+// IsEligible đơn đã giao hoặc đã thanh toán và không bị huỷ / hoàn
+// (thời điểm lập hoá đơn là lúc giao hàng hoặc thu tiền)
+func (o *OrderSnapshot) IsEligible() bool {
+	for _, s := range IneligibleOrderStatuses {
+		if o.Status == s {
+			return false
+		}
+	}
+	return o.Status == "delivered" || o.PaymentStatus == "paid"
+}
+
+// SplitVAT tách tiền trước thuế / tiền thuế từ số tiền đã gồm VAT (làm tròn đến đồng)
+func SplitVAT(gross decimal.Decimal, vatRate int) (beforeTax, tax decimal.Decimal) {
+	if vatRate <= 0 {
+		return gross, decimal.Zero
+	}
+	divisor := decimal.NewFromInt(int64(100 + vatRate)).Div(decimal.NewFromInt(100))
+	beforeTax = gross.Div(divisor).Round(0)
+	return beforeTax, gross.Sub(beforeTax)
+}
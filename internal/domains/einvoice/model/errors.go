@@ -0,0 +1,134 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodeInvoiceNotFound     = "EIV001"
+	ErrCodeOrderNotFound       = "EIV002"
+	ErrCodeOrderNotEligible    = "EIV003"
+	ErrCodeInvoiceExists       = "EIV004"
+	ErrCodeInvalidRequest      = "EIV005"
+	ErrCodeInvalidTaxCode      = "EIV006"
+	ErrCodeInvoiceNotEffective = "EIV007"
+	ErrCodeProviderUnavailable = "EIV008"
+	ErrCodeProviderError       = "EIV009"
+	ErrCodeAlreadyReplaced     = "EIV010"
+)
+
+// Errors
+var (
+	ErrInvoiceNotFound     = errors.New("e-invoice not found")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderNotEligible    = errors.New("order not eligible for e-invoice")
+	ErrInvoiceExists       = errors.New("e-invoice already requested for order")
+	ErrInvalidRequest      = errors.New("invalid e-invoice request")
+	ErrInvalidTaxCode      = errors.New("invalid tax code")
+	ErrInvoiceNotEffective = errors.New("e-invoice is not in effect")
+	ErrProviderUnavailable = errors.New("e-invoice provider not configured")
+	ErrProviderError       = errors.New("e-invoice provider request failed")
+	ErrAlreadyReplaced     = errors.New("e-invoice already has a replacement")
+)
+
+// EInvoiceError custom error type
+type EInvoiceError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *EInvoiceError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *EInvoiceError) Unwrap() error {
+	return e.Err
+}
+
+// Error constructors
+func NewInvoiceNotFoundError() *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeInvoiceNotFound,
+		Message: "E-invoice not found",
+		Err:     ErrInvoiceNotFound,
+	}
+}
+
+func NewOrderNotFoundError() *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeOrderNotFound,
+		Message: "Order not found",
+		Err:     ErrOrderNotFound,
+	}
+}
+
+func NewOrderNotEligibleError(status, paymentStatus string) *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeOrderNotEligible,
+		Message: fmt.Sprintf("Order (status %s, payment %s) must be delivered or paid before issuing an e-invoice", status, paymentStatus),
+		Err:     ErrOrderNotEligible,
+	}
+}
+
+func NewInvoiceExistsError() *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeInvoiceExists,
+		Message: "An e-invoice has already been requested for this order",
+		Err:     ErrInvoiceExists,
+	}
+}
+
+func NewInvalidRequestError(message string) *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeInvalidRequest,
+		Message: message,
+		Err:     ErrInvalidRequest,
+	}
+}
+
+func NewInvalidTaxCodeError(taxCode string) *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeInvalidTaxCode,
+		Message: fmt.Sprintf("Tax code %s is invalid (expected 10 digits, 10 digits-3 digits or 12 digits)", taxCode),
+		Err:     ErrInvalidTaxCode,
+	}
+}
+
+func NewInvoiceNotEffectiveError(status string) *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeInvoiceNotEffective,
+		Message: fmt.Sprintf("Only issued e-invoices can be replaced or adjusted (current status %s)", status),
+		Err:     ErrInvoiceNotEffective,
+	}
+}
+
+func NewProviderUnavailableError(provider string) *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeProviderUnavailable,
+		Message: fmt.Sprintf("E-invoice provider %s is not configured", provider),
+		Err:     ErrProviderUnavailable,
+	}
+}
+
+// NewProviderError wraps lỗi từ nhà cung cấp (giữ message để kế toán biết lý do: sai MST, hết dải số...)
+func NewProviderError(err error) *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeProviderError,
+		Message: err.Error(),
+		Err:     ErrProviderError,
+	}
+}
+
+func NewAlreadyReplacedError() *EInvoiceError {
+	return &EInvoiceError{
+		Code:    ErrCodeAlreadyReplaced,
+		Message: "This e-invoice already has a replacement invoice",
+		Err:     ErrAlreadyReplaced,
+	}
+}
@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const defaultMISABaseURL = "https://api.meinvoice.vn/api/integration"
+
+// MISA token sống 14 ngày, lấy lại sớm hơn cho chắc
+const misaTokenTTL = 12 * time.Hour
+
+// MISA ReferenceType của hoá đơn liên quan
+const (
+	misaReferenceReplacement = 1
+	misaReferenceAdjustment  = 2
+)
+
+// MISAConfig thông tin kết nối meInvoice (cấp khi đăng ký tích hợp)
+type MISAConfig struct {
+	BaseURL  string
+	AppID    string
+	TaxCode  string // MST người bán
+	Username string
+	Password string
+}
+
+// MISAProvider - MISA meInvoice, phát hành bằng chữ ký số HSM (SignType = 2)
+type MISAProvider struct {
+	cfg        MISAConfig
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	token    string
+	tokenExp time.Time
+}
+
+// NewMISAProvider creates meInvoice client; BaseURL rỗng → production
+func NewMISAProvider(cfg MISAConfig) *MISAProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultMISABaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &MISAProvider{
+		cfg:        cfg,
+		httpClient: newHTTPClient(),
+	}
+}
+
+func (p *MISAProvider) Code() string {
+	return CodeMISA
+}
+
+type misaResponse struct {
+	Success       bool   `json:"success"`
+	ErrorCode     string `json:"errorCode"`
+	Description   string `json:"descriptionErrorCode"`
+	Data          string `json:"data"`
+	PublishResult string `json:"publishInvoiceResult"`
+}
+
+type misaInvoiceDetail struct {
+	ItemType        int             `json:"ItemType"` // 1: hàng hoá, 3: chiết khấu thương mại
+	SortOrder       int             `json:"SortOrder"`
+	ItemName        string          `json:"ItemName"`
+	UnitName        string          `json:"UnitName"`
+	Quantity        int             `json:"Quantity"`
+	UnitPrice       decimal.Decimal `json:"UnitPrice"`
+	AmountOC        decimal.Decimal `json:"AmountOC"`
+	VATRateName     string          `json:"VATRateName"`
+	IsPriceIncludes bool            `json:"UnitPriceAfterTax"`
+}
+
+type misaInvoice struct {
+	RefID                   string              `json:"RefID"`
+	InvSeries               string              `json:"InvSeries"`
+	InvDate                 string              `json:"InvDate"`
+	CurrencyCode            string              `json:"CurrencyCode"`
+	ExchangeRate            int                 `json:"ExchangeRate"`
+	PaymentMethodName       string              `json:"PaymentMethodName"`
+	BuyerFullName           string              `json:"BuyerFullName"`
+	BuyerLegalName          string              `json:"BuyerLegalName,omitempty"`
+	BuyerTaxCode            string              `json:"BuyerTaxCode,omitempty"`
+	BuyerAddress            string              `json:"BuyerAddress"`
+	ReceiverEmail           string              `json:"ReceiverEmail,omitempty"`
+	TotalAmountWithoutVATOC decimal.Decimal     `json:"TotalAmountWithoutVATOC"`
+	TotalVATAmountOC        decimal.Decimal     `json:"TotalVATAmountOC"`
+	TotalAmountOC           decimal.Decimal     `json:"TotalAmountOC"`
+	OriginalInvoiceDetail   []misaInvoiceDetail `json:"OriginalInvoiceDetail"`
+	CustomField1            string              `json:"CustomField1,omitempty"` // mã đơn hàng
+
+	// Hoá đơn thay thế / điều chỉnh
+	ReferenceType  int    `json:"ReferenceType,omitempty"`
+	OrgInvTemplate string `json:"OrgInvTemplateNo,omitempty"`
+	OrgInvSeries   string `json:"OrgInvSeries,omitempty"`
+	OrgInvNo       string `json:"OrgInvNo,omitempty"`
+	OrgInvDate     string `json:"OrgInvDate,omitempty"`
+	ReasonText     string `json:"InvoiceNote,omitempty"`
+}
+
+type misaPublishResult struct {
+	RefID         string `json:"RefID"`
+	TransactionID string `json:"TransactionID"`
+	InvNo         string `json:"InvNo"`
+	InvDate       string `json:"InvDate"`
+	ErrorCode     string `json:"ErrorCode"`
+}
+
+func (p *MISAProvider) Publish(ctx context.Context, inv Invoice) (*PublishResult, error) {
+	token, err := p.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"SignType":    2, // HSM: MISA ký thay, không cần USB token
+		"InvoiceData": []misaInvoice{p.buildInvoice(inv)},
+	}
+
+	var resp misaResponse
+	if err := p.post(ctx, "/invoice", token, body, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("misa: publish rejected: %s %s", resp.ErrorCode, resp.Description)
+	}
+
+	var results []misaPublishResult
+	if err := json.Unmarshal([]byte(resp.PublishResult), &results); err != nil || len(results) == 0 {
+		return nil, fmt.Errorf("misa: unexpected publish result: %s", resp.PublishResult)
+	}
+	result := results[0]
+	// DuplicateRefID: đã phát hành ở lần gọi trước (timeout phía mình) → MISA trả lại số cũ
+	if result.ErrorCode != "" && result.ErrorCode != "DuplicateRefID" {
+		return nil, fmt.Errorf("misa: publish failed: %s", result.ErrorCode)
+	}
+	if result.InvNo == "" {
+		return nil, fmt.Errorf("misa: publish returned no invoice number (%s)", result.ErrorCode)
+	}
+
+	issuedAt := time.Now()
+	if t, err := time.Parse("2006-01-02", result.InvDate); err == nil {
+		issuedAt = t
+	}
+	return &PublishResult{
+		InvoiceNumber: result.InvNo,
+		TransactionID: result.TransactionID,
+		LookupCode:    result.TransactionID, // tra cứu trên meinvoice.vn/tra-cuu bằng mã giao dịch
+		IssuedAt:      issuedAt,
+	}, nil
+}
+
+func (p *MISAProvider) buildInvoice(inv Invoice) misaInvoice {
+	vatRate := fmt.Sprintf("%d%%", inv.VATRate)
+	details := make([]misaInvoiceDetail, len(inv.Lines))
+	for i, line := range inv.Lines {
+		itemType := 1
+		if line.IsDiscount {
+			itemType = 3
+		}
+		details[i] = misaInvoiceDetail{
+			ItemType:        itemType,
+			SortOrder:       i + 1,
+			ItemName:        line.Name,
+			UnitName:        line.Unit,
+			Quantity:        line.Quantity,
+			UnitPrice:       line.UnitPrice,
+			AmountOC:        line.Amount,
+			VATRateName:     vatRate,
+			IsPriceIncludes: true,
+		}
+	}
+
+	result := misaInvoice{
+		RefID:                   inv.RefID,
+		InvSeries:               inv.TemplateCode + inv.Series, // MISA ghép mẫu số + ký hiệu (1C25TBS)
+		InvDate:                 inv.IssueDate.Format("2006-01-02"),
+		CurrencyCode:            "VND",
+		ExchangeRate:            1,
+		PaymentMethodName:       inv.PaymentMethod,
+		BuyerFullName:           inv.Buyer.Name,
+		BuyerLegalName:          inv.Buyer.Company,
+		BuyerTaxCode:            inv.Buyer.TaxCode,
+		BuyerAddress:            inv.Buyer.Address,
+		ReceiverEmail:           inv.Buyer.Email,
+		TotalAmountWithoutVATOC: inv.AmountBeforeTax,
+		TotalVATAmountOC:        inv.TaxAmount,
+		TotalAmountOC:           inv.TotalAmount,
+		OriginalInvoiceDetail:   details,
+		CustomField1:            inv.OrderNumber,
+	}
+
+	if inv.Original != nil {
+		result.ReferenceType = misaReferenceReplacement
+		if inv.Kind == KindAdjustment {
+			result.ReferenceType = misaReferenceAdjustment
+		}
+		result.OrgInvTemplate = inv.Original.TemplateCode
+		result.OrgInvSeries = inv.Original.Series
+		result.OrgInvNo = inv.Original.InvoiceNumber
+		result.OrgInvDate = inv.Original.IssuedAt.Format("2006-01-02")
+		result.ReasonText = inv.Reason
+	}
+	return result
+}
+
+// getToken lấy token (cache đến khi gần hết hạn)
+func (p *MISAProvider) getToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExp) {
+		return p.token, nil
+	}
+
+	body := map[string]string{
+		"appid":    p.cfg.AppID,
+		"taxcode":  p.cfg.TaxCode,
+		"username": p.cfg.Username,
+		"password": p.cfg.Password,
+	}
+	var resp misaResponse
+	if err := p.post(ctx, "/auth/token", "", body, &resp); err != nil {
+		return "", err
+	}
+	if !resp.Success || resp.Data == "" {
+		return "", fmt.Errorf("misa: authentication failed: %s %s", resp.ErrorCode, resp.Description)
+	}
+
+	p.token = resp.Data
+	p.tokenExp = time.Now().Add(misaTokenTTL)
+	return p.token, nil
+}
+
+func (p *MISAProvider) post(ctx context.Context, path, token string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("misa: marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("misa: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("misa: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("misa: decode response (status %d): %w", resp.StatusCode, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MockProvider - nhà cung cấp giả lập cho dev/staging (USE_MOCK_EINVOICE=true), không gửi CQT
+// Số hoá đơn cấp tăng dần trong bộ nhớ, idempotent theo RefID
+type MockProvider struct {
+	code string
+
+	mu      sync.Mutex
+	next    int
+	results map[string]*PublishResult
+}
+
+// NewMockProvider creates mock impersonating a provider code (để qua CHECK của einvoices.provider)
+func NewMockProvider(code string) *MockProvider {
+	return &MockProvider{
+		code:    code,
+		next:    1,
+		results: make(map[string]*PublishResult),
+	}
+}
+
+func (p *MockProvider) Code() string {
+	return p.code
+}
+
+func (p *MockProvider) Publish(ctx context.Context, inv Invoice) (*PublishResult, error) {
+	if inv.Kind != KindOriginal && inv.Original == nil {
+		return nil, fmt.Errorf("%s invoice requires original invoice reference", inv.Kind)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if result, ok := p.results[inv.RefID]; ok {
+		return result, nil
+	}
+
+	result := &PublishResult{
+		InvoiceNumber: fmt.Sprintf("%08d", p.next),
+		TransactionID: "MOCK" + strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:12]),
+		LookupCode:    strings.ToUpper(uuid.NewString()[:10]),
+		IssuedAt:      time.Now(),
+	}
+	p.next++
+	p.results[inv.RefID] = result
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// PROVIDER INTERFACE
+// =====================================================
+
+// Provider phát hành hoá đơn điện tử qua nhà cung cấp được Tổng cục Thuế chấp thuận (MISA, VNPT, ...)
+// Nhà cung cấp ký số (HSM) + gửi CQT, trả về số hoá đơn
+type Provider interface {
+	// Code returns provider identifier stored on einvoices (misa, vnpt, ...)
+	Code() string
+
+	// Publish phát hành 1 hoá đơn (gốc / thay thế / điều chỉnh)
+	// RefID là idempotency key: gọi lại với cùng RefID trả lại hoá đơn đã phát hành, không cấp số mới
+	Publish(ctx context.Context, inv Invoice) (*PublishResult, error)
+}
+
+// Provider codes (khớp CHECK của einvoices.provider)
+const (
+	CodeMISA = "misa"
+	CodeVNPT = "vnpt"
+)
+
+// Invoice kinds
+const (
+	KindOriginal    = "original"
+	KindReplacement = "replacement"
+	KindAdjustment  = "adjustment"
+)
+
+// Buyer người mua (Company + TaxCode rỗng = cá nhân)
+type Buyer struct {
+	Name    string
+	Company string
+	TaxCode string
+	Address string
+	Email   string // nhà cung cấp gửi email hoá đơn cho người mua
+}
+
+// Line 1 dòng hàng hoá / dịch vụ; đơn giá + thành tiền đã gồm VAT
+type Line struct {
+	Name      string
+	Unit      string
+	Quantity  int
+	UnitPrice decimal.Decimal
+	Amount    decimal.Decimal
+	// true: dòng chiết khấu thương mại (trừ vào tổng)
+	IsDiscount bool
+}
+
+// OriginalRef hoá đơn bị thay thế / điều chỉnh
+type OriginalRef struct {
+	TemplateCode  string
+	Series        string
+	InvoiceNumber string
+	IssuedAt      time.Time
+}
+
+// Invoice dữ liệu gửi nhà cung cấp
+type Invoice struct {
+	RefID           string // einvoices.id
+	Kind            string
+	TemplateCode    string
+	Series          string
+	IssueDate       time.Time
+	OrderNumber     string
+	PaymentMethod   string // TM/CK
+	Buyer           Buyer
+	Lines           []Line
+	VATRate         int
+	AmountBeforeTax decimal.Decimal
+	TaxAmount       decimal.Decimal
+	TotalAmount     decimal.Decimal
+
+	// Chỉ có với hoá đơn thay thế / điều chỉnh
+	Original *OriginalRef
+	Reason   string
+}
+
+// PublishResult hoá đơn đã phát hành
+type PublishResult struct {
+	InvoiceNumber string
+	TransactionID string
+	LookupCode    string
+	IssuedAt      time.Time
+}
+
+const defaultTimeout = 30 * time.Second
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultTimeout}
+}
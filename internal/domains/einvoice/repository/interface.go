@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/einvoice/model"
+	"bookstore-backend/internal/domains/einvoice/provider"
+)
+
+// =====================================================
+// E-INVOICE REPOSITORY INTERFACE
+// =====================================================
+
+type EInvoiceRepository interface {
+	// GetOrderSnapshot gets order data needed for an invoice (totals, items, buyer email)
+	GetOrderSnapshot(ctx context.Context, orderID uuid.UUID) (*model.OrderSnapshot, error)
+
+	// Create stores pending invoice
+	// ErrInvoiceExists: đơn đã có hoá đơn gốc; ErrAlreadyReplaced: hoá đơn đã có hoá đơn thay thế
+	Create(ctx context.Context, invoice *model.EInvoice) error
+
+	// GetByID gets invoice
+	GetByID(ctx context.Context, id uuid.UUID) (*model.EInvoice, error)
+
+	// ListByOrder lists invoices of order (gốc, thay thế, điều chỉnh - cũ nhất trước)
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]model.EInvoice, error)
+
+	// List lists invoices for accounting (mới nhất trước)
+	List(ctx context.Context, filter model.ListEInvoicesFilter) ([]model.EInvoice, int, error)
+
+	// MarkIssued records invoice number in one transaction:
+	// hoá đơn gốc / thay thế → ghi số hoá đơn lên orders; thay thế → hoá đơn cũ chuyển replaced
+	MarkIssued(ctx context.Context, invoice *model.EInvoice, result *provider.PublishResult) error
+
+	// MarkFailed records provider error (attempts + 1)
+	MarkFailed(ctx context.Context, id uuid.UUID, message string) error
+
+	// ResetForRetry moves failed invoice back to pending
+	ResetForRetry(ctx context.Context, id uuid.UUID) error
+}
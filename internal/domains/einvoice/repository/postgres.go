@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/einvoice/model"
+	"bookstore-backend/internal/domains/einvoice/provider"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresEInvoiceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresEInvoiceRepository(pool *pgxpool.Pool) EInvoiceRepository {
+	return &postgresEInvoiceRepository{pool: pool}
+}
+
+const einvoiceColumns = `
+	e.id, e.order_id, o.order_number, e.invoice_type, e.original_invoice_id, e.status, e.provider,
+	e.template_code, e.invoice_series, e.invoice_number, e.transaction_id, e.lookup_code,
+	e.buyer_name, e.buyer_company, e.buyer_tax_code, e.buyer_address, e.buyer_email,
+	e.amount_before_tax, e.vat_rate, e.tax_amount, e.total_amount,
+	e.reason, e.error_message, e.attempts, e.requested_by, e.issued_at, e.created_at, e.updated_at`
+
+func scanEInvoice(row pgx.Row, e *model.EInvoice) error {
+	return row.Scan(
+		&e.ID,
+		&e.OrderID,
+		&e.OrderNumber,
+		&e.InvoiceType,
+		&e.OriginalInvoiceID,
+		&e.Status,
+		&e.Provider,
+		&e.TemplateCode,
+		&e.InvoiceSeries,
+		&e.InvoiceNumber,
+		&e.TransactionID,
+		&e.LookupCode,
+		&e.BuyerName,
+		&e.BuyerCompany,
+		&e.BuyerTaxCode,
+		&e.BuyerAddress,
+		&e.BuyerEmail,
+		&e.AmountBeforeTax,
+		&e.VATRate,
+		&e.TaxAmount,
+		&e.TotalAmount,
+		&e.Reason,
+		&e.ErrorMessage,
+		&e.Attempts,
+		&e.RequestedBy,
+		&e.IssuedAt,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+}
+
+// =====================================================
+// ORDER SNAPSHOT
+// =====================================================
+
+func (r *postgresEInvoiceRepository) GetOrderSnapshot(ctx context.Context, orderID uuid.UUID) (*model.OrderSnapshot, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			o.id, o.order_number, o.user_id, COALESCE(u.email, ''), o.status,
+			o.payment_method, o.payment_status,
+			o.subtotal, COALESCE(o.shipping_fee, 0), o.shipping_discount, COALESCE(o.discount_amount, 0), o.total,
+			o.paid_at, o.delivered_at
+		FROM orders o
+		LEFT JOIN users u ON u.id = o.user_id
+		WHERE o.id = $1
+	`
+
+	var s model.OrderSnapshot
+	err := r.pool.QueryRow(ctx, query, orderID).Scan(
+		&s.OrderID,
+		&s.OrderNumber,
+		&s.UserID,
+		&s.UserEmail,
+		&s.Status,
+		&s.PaymentMethod,
+		&s.PaymentStatus,
+		&s.Subtotal,
+		&s.ShippingFee,
+		&s.ShippingDiscount,
+		&s.DiscountAmount,
+		&s.Total,
+		&s.PaidAt,
+		&s.DeliveredAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order for e-invoice: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT book_title, quantity, price, subtotal
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY created_at, id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items for e-invoice: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line model.OrderLine
+		if err := rows.Scan(&line.Title, &line.Quantity, &line.Price, &line.Subtotal); err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		s.Items = append(s.Items, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate order items: %w", err)
+	}
+
+	return &s, nil
+}
+
+// =====================================================
+// E-INVOICES
+// =====================================================
+
+func (r *postgresEInvoiceRepository) Create(ctx context.Context, e *model.EInvoice) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO einvoices (
+			id, order_id, invoice_type, original_invoice_id, status, provider,
+			template_code, invoice_series,
+			buyer_name, buyer_company, buyer_tax_code, buyer_address, buyer_email,
+			amount_before_tax, vat_rate, tax_amount, total_amount,
+			reason, requested_by, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+			$14, $15, $16, $17, $18, $19, $20, $21
+		)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		e.ID,
+		e.OrderID,
+		e.InvoiceType,
+		e.OriginalInvoiceID,
+		e.Status,
+		e.Provider,
+		e.TemplateCode,
+		e.InvoiceSeries,
+		e.BuyerName,
+		e.BuyerCompany,
+		e.BuyerTaxCode,
+		e.BuyerAddress,
+		e.BuyerEmail,
+		e.AmountBeforeTax,
+		e.VATRate,
+		e.TaxAmount,
+		e.TotalAmount,
+		e.Reason,
+		e.RequestedBy,
+		e.CreatedAt,
+		e.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			switch pgErr.ConstraintName {
+			case "uq_einvoices_original_per_order":
+				return model.ErrInvoiceExists
+			case "uq_einvoices_replacement_per_original":
+				return model.ErrAlreadyReplaced
+			}
+		}
+		return fmt.Errorf("failed to create e-invoice: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresEInvoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.EInvoice, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + einvoiceColumns + `
+		FROM einvoices e
+		JOIN orders o ON o.id = e.order_id
+		WHERE e.id = $1`
+
+	var e model.EInvoice
+	if err := scanEInvoice(r.pool.QueryRow(ctx, query, id), &e); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get e-invoice: %w", err)
+	}
+	return &e, nil
+}
+
+func (r *postgresEInvoiceRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]model.EInvoice, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + einvoiceColumns + `
+		FROM einvoices e
+		JOIN orders o ON o.id = e.order_id
+		WHERE e.order_id = $1
+		ORDER BY e.created_at ASC`
+
+	return r.query(ctx, query, orderID)
+}
+
+func (r *postgresEInvoiceRepository) List(ctx context.Context, filter model.ListEInvoicesFilter) ([]model.EInvoice, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	where := []string{"1=1"}
+	args := []interface{}{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where = append(where, fmt.Sprintf("e.status = $%d", len(args)))
+	}
+	if filter.InvoiceType != "" {
+		args = append(args, filter.InvoiceType)
+		where = append(where, fmt.Sprintf("e.invoice_type = $%d", len(args)))
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM einvoices e WHERE `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count e-invoices: %w", err)
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := `SELECT ` + einvoiceColumns + `
+		FROM einvoices e
+		JOIN orders o ON o.id = e.order_id
+		WHERE ` + whereClause + fmt.Sprintf(`
+		ORDER BY e.created_at DESC
+		LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	invoices, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return invoices, total, nil
+}
+
+func (r *postgresEInvoiceRepository) MarkIssued(ctx context.Context, e *model.EInvoice, result *provider.PublishResult) error {
+	return database.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			UPDATE einvoices
+			SET status = $2,
+			    invoice_number = $3,
+			    transaction_id = $4,
+			    lookup_code = $5,
+			    issued_at = $6,
+			    error_message = NULL,
+			    attempts = attempts + 1,
+			    updated_at = NOW()
+			WHERE id = $1
+		`, e.ID, model.StatusIssued, result.InvoiceNumber, result.TransactionID, result.LookupCode, result.IssuedAt)
+		if err != nil {
+			return fmt.Errorf("failed to mark e-invoice issued: %w", err)
+		}
+
+		// Hoá đơn điều chỉnh không đổi số hoá đơn của đơn (vẫn là hoá đơn gốc / thay thế)
+		if e.InvoiceType == model.TypeAdjustment {
+			return nil
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE orders
+			SET einvoice_template_code = $2,
+			    einvoice_series = $3,
+			    einvoice_number = $4,
+			    einvoice_issued_at = $5,
+			    updated_at = NOW()
+			WHERE id = $1
+		`, e.OrderID, e.TemplateCode, e.InvoiceSeries, result.InvoiceNumber, result.IssuedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store e-invoice number on order: %w", err)
+		}
+
+		if e.InvoiceType == model.TypeReplacement && e.OriginalInvoiceID != nil {
+			_, err = tx.Exec(ctx, `
+				UPDATE einvoices SET status = $2, updated_at = NOW() WHERE id = $1
+			`, *e.OriginalInvoiceID, model.StatusReplaced)
+			if err != nil {
+				return fmt.Errorf("failed to mark original e-invoice replaced: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresEInvoiceRepository) MarkFailed(ctx context.Context, id uuid.UUID, message string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE einvoices
+		SET status = $2, error_message = $3, attempts = attempts + 1, updated_at = NOW()
+		WHERE id = $1 AND status <> $4
+	`, id, model.StatusFailed, message, model.StatusIssued)
+	if err != nil {
+		return fmt.Errorf("failed to mark e-invoice failed: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresEInvoiceRepository) ResetForRetry(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE einvoices SET status = $2, updated_at = NOW() WHERE id = $1 AND status = $3
+	`, id, model.StatusPending, model.StatusFailed)
+	if err != nil {
+		return fmt.Errorf("failed to reset e-invoice: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresEInvoiceRepository) query(ctx context.Context, query string, args ...interface{}) ([]model.EInvoice, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list e-invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := make([]model.EInvoice, 0)
+	for rows.Next() {
+		var e model.EInvoice
+		if err := scanEInvoice(rows, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan e-invoice: %w", err)
+		}
+		invoices = append(invoices, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate e-invoices: %w", err)
+	}
+	return invoices, nil
+}
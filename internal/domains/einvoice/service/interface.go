@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/einvoice/model"
+)
+
+// =====================================================
+// E-INVOICE SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ========================================
+	// REQUEST (khách / admin)
+	// ========================================
+
+	// RequestInvoice lập hoá đơn gốc cho đơn đã giao / đã thanh toán, phát hành nền qua worker
+	// userID != nil: khách chỉ yêu cầu được cho đơn của mình; nil: admin lập thay
+	RequestInvoice(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID, requestedBy uuid.UUID, req model.RequestEInvoiceRequest) (*model.EInvoice, error)
+
+	// ListOrderInvoices lists invoices of order (userID != nil → kiểm tra chủ đơn)
+	ListOrderInvoices(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID) ([]model.EInvoice, error)
+
+	// ========================================
+	// ACCOUNTING (admin)
+	// ========================================
+
+	// ListInvoices lists invoices by status / type
+	ListInvoices(ctx context.Context, filter model.ListEInvoicesFilter) (*model.EInvoiceListResponse, error)
+
+	// GetInvoice gets invoice
+	GetInvoice(ctx context.Context, id uuid.UUID) (*model.EInvoice, error)
+
+	// ReissueInvoice lập hoá đơn thay thế (sai thông tin người mua / MST), hoá đơn cũ thành replaced khi phát hành xong
+	ReissueInvoice(ctx context.Context, adminID, invoiceID uuid.UUID, req model.ReissueEInvoiceRequest) (*model.EInvoice, error)
+
+	// AdjustInvoice lập hoá đơn điều chỉnh tăng / giảm tiền
+	AdjustInvoice(ctx context.Context, adminID, invoiceID uuid.UUID, req model.AdjustEInvoiceRequest) (*model.EInvoice, error)
+
+	// RetryInvoice phát hành lại hoá đơn lỗi
+	RetryInvoice(ctx context.Context, invoiceID uuid.UUID) (*model.EInvoice, error)
+
+	// ========================================
+	// WORKER
+	// ========================================
+
+	// ProcessInvoice phát hành hoá đơn pending / failed qua nhà cung cấp
+	ProcessInvoice(ctx context.Context, invoiceID uuid.UUID) error
+}
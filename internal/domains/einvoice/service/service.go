@@ -0,0 +1,492 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/einvoice/model"
+	"bookstore-backend/internal/domains/einvoice/provider"
+	"bookstore-backend/internal/domains/einvoice/repository"
+	types "bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+)
+
+// Config - nhà cung cấp + mẫu số / ký hiệu hoá đơn đã đăng ký với cơ quan thuế
+type Config struct {
+	Provider     string
+	TemplateCode string // mẫu số (1 = hoá đơn GTGT)
+	Series       string // ký hiệu (vd: C25TBS)
+	VATRate      int    // % VAT của sách bán ra (giá bán đã gồm VAT)
+}
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type einvoiceService struct {
+	repo        repository.EInvoiceRepository
+	providers   map[string]provider.Provider
+	asynqClient *asynq.Client
+	cfg         Config
+}
+
+func NewEInvoiceService(
+	repo repository.EInvoiceRepository,
+	providers []provider.Provider,
+	asynqClient *asynq.Client,
+	cfg Config,
+) ServiceInterface {
+	byCode := make(map[string]provider.Provider, len(providers))
+	for _, p := range providers {
+		byCode[p.Code()] = p
+	}
+	return &einvoiceService{
+		repo:        repo,
+		providers:   byCode,
+		asynqClient: asynqClient,
+		cfg:         cfg,
+	}
+}
+
+// =====================================================
+// REQUEST
+// =====================================================
+
+func (s *einvoiceService) RequestInvoice(
+	ctx context.Context,
+	orderID uuid.UUID,
+	userID *uuid.UUID,
+	requestedBy uuid.UUID,
+	req model.RequestEInvoiceRequest,
+) (*model.EInvoice, error) {
+	if _, ok := s.providers[s.cfg.Provider]; !ok {
+		return nil, model.NewProviderUnavailableError(s.cfg.Provider)
+	}
+
+	order, err := s.getOrder(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.IsEligible() {
+		return nil, model.NewOrderNotEligibleError(order.Status, order.PaymentStatus)
+	}
+
+	buyer := req.BuyerInfo
+	buyer.Normalize()
+	if buyer.Email == nil && order.UserEmail != "" {
+		buyer.Email = &order.UserEmail
+	}
+	if err := buyer.Validate(); err != nil {
+		return nil, err
+	}
+
+	invoice := s.newInvoice(order.OrderID, model.TypeOriginal, buyer, order.Total, &requestedBy)
+	if err := s.repo.Create(ctx, invoice); err != nil {
+		if errors.Is(err, model.ErrInvoiceExists) {
+			return nil, model.NewInvoiceExistsError()
+		}
+		return nil, err
+	}
+	invoice.OrderNumber = order.OrderNumber
+
+	if err := s.enqueueIssue(ctx, invoice.ID); err != nil {
+		return nil, err
+	}
+
+	logger.Info("E-invoice requested", map[string]interface{}{
+		"invoice_id":   invoice.ID.String(),
+		"order_id":     orderID.String(),
+		"has_tax_code": buyer.TaxCode != nil,
+		"requested_by": requestedBy.String(),
+	})
+	return invoice, nil
+}
+
+func (s *einvoiceService) ListOrderInvoices(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID) ([]model.EInvoice, error) {
+	if userID != nil {
+		if _, err := s.getOrder(ctx, orderID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.ListByOrder(ctx, orderID)
+}
+
+// =====================================================
+// ACCOUNTING
+// =====================================================
+
+func (s *einvoiceService) ListInvoices(ctx context.Context, filter model.ListEInvoicesFilter) (*model.EInvoiceListResponse, error) {
+	filter.Normalize()
+	invoices, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &model.EInvoiceListResponse{
+		Invoices: invoices,
+		Total:    total,
+		Page:     filter.Page,
+		Limit:    filter.Limit,
+	}, nil
+}
+
+func (s *einvoiceService) GetInvoice(ctx context.Context, id uuid.UUID) (*model.EInvoice, error) {
+	invoice, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, model.ErrInvoiceNotFound) {
+			return nil, model.NewInvoiceNotFoundError()
+		}
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *einvoiceService) ReissueInvoice(
+	ctx context.Context,
+	adminID, invoiceID uuid.UUID,
+	req model.ReissueEInvoiceRequest,
+) (*model.EInvoice, error) {
+	original, err := s.getAdjustableInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	buyer := req.ApplyTo(original.Buyer())
+	if err := buyer.Validate(); err != nil {
+		return nil, err
+	}
+
+	invoice := s.newInvoice(original.OrderID, model.TypeReplacement, buyer, original.TotalAmount, &adminID)
+	invoice.OriginalInvoiceID = &original.ID
+	invoice.Reason = &req.Reason
+
+	return s.createDerived(ctx, invoice, original)
+}
+
+func (s *einvoiceService) AdjustInvoice(
+	ctx context.Context,
+	adminID, invoiceID uuid.UUID,
+	req model.AdjustEInvoiceRequest,
+) (*model.EInvoice, error) {
+	original, err := s.getAdjustableInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Validate(original); err != nil {
+		return nil, err
+	}
+
+	invoice := s.newInvoice(original.OrderID, model.TypeAdjustment, original.Buyer(), req.Amount, &adminID)
+	// Điều chỉnh theo thuế suất của hoá đơn gốc
+	invoice.VATRate = original.VATRate
+	invoice.AmountBeforeTax, invoice.TaxAmount = model.SplitVAT(req.Amount, original.VATRate)
+	invoice.OriginalInvoiceID = &original.ID
+	invoice.Reason = &req.Reason
+
+	return s.createDerived(ctx, invoice, original)
+}
+
+func (s *einvoiceService) RetryInvoice(ctx context.Context, invoiceID uuid.UUID) (*model.EInvoice, error) {
+	invoice, err := s.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if !invoice.CanRetry() {
+		return nil, model.NewInvalidRequestError(fmt.Sprintf("E-invoice in status %s cannot be retried", invoice.Status))
+	}
+
+	if err := s.repo.ResetForRetry(ctx, invoice.ID); err != nil {
+		return nil, err
+	}
+	if err := s.enqueueIssue(ctx, invoice.ID); err != nil {
+		return nil, err
+	}
+
+	invoice.Status = model.StatusPending
+	return invoice, nil
+}
+
+// =====================================================
+// WORKER
+// =====================================================
+
+// ProcessInvoice gọi nhà cung cấp với RefID = invoice ID (retry không cấp 2 số cho 1 hoá đơn)
+func (s *einvoiceService) ProcessInvoice(ctx context.Context, invoiceID uuid.UUID) error {
+	invoice, err := s.repo.GetByID(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+	if !invoice.CanRetry() {
+		return nil
+	}
+
+	p, ok := s.providers[invoice.Provider]
+	if !ok {
+		return model.NewProviderUnavailableError(invoice.Provider)
+	}
+
+	inv, err := s.buildProviderInvoice(ctx, invoice)
+	if err != nil {
+		return err
+	}
+
+	result, err := p.Publish(ctx, *inv)
+	if err != nil {
+		if markErr := s.repo.MarkFailed(ctx, invoice.ID, err.Error()); markErr != nil {
+			logger.Error("Failed to record e-invoice error", markErr)
+		}
+		return model.NewProviderError(err)
+	}
+
+	// Từ đây hoá đơn đã có số bên nhà cung cấp: lỗi ghi DB → retry gọi lại cùng RefID, nhận lại số cũ
+	if err := s.repo.MarkIssued(ctx, invoice, result); err != nil {
+		logger.Info("E-invoice published but not saved", map[string]interface{}{
+			"invoice_id":     invoice.ID.String(),
+			"invoice_number": result.InvoiceNumber,
+			"transaction_id": result.TransactionID,
+		})
+		return err
+	}
+
+	logger.Info("E-invoice issued", map[string]interface{}{
+		"invoice_id":     invoice.ID.String(),
+		"order_id":       invoice.OrderID.String(),
+		"invoice_type":   invoice.InvoiceType,
+		"invoice_series": invoice.TemplateCode + invoice.InvoiceSeries,
+		"invoice_number": result.InvoiceNumber,
+	})
+	return nil
+}
+
+// buildProviderInvoice - hoá đơn gốc / thay thế kê từng dòng sách của đơn, điều chỉnh chỉ 1 dòng chênh lệch
+func (s *einvoiceService) buildProviderInvoice(ctx context.Context, invoice *model.EInvoice) (*provider.Invoice, error) {
+	buyer := invoice.Buyer()
+	inv := &provider.Invoice{
+		RefID:        invoice.ID.String(),
+		Kind:         invoice.InvoiceType,
+		TemplateCode: invoice.TemplateCode,
+		Series:       invoice.InvoiceSeries,
+		IssueDate:    time.Now(),
+		OrderNumber:  invoice.OrderNumber,
+		// Bán lẻ online: tiền mặt (COD) hoặc chuyển khoản / cổng thanh toán
+		PaymentMethod: "TM/CK",
+		Buyer: provider.Buyer{
+			Name:    buyer.Name,
+			Company: derefString(buyer.Company),
+			TaxCode: derefString(buyer.TaxCode),
+			Address: buyer.Address,
+			Email:   derefString(buyer.Email),
+		},
+		VATRate:         invoice.VATRate,
+		AmountBeforeTax: invoice.AmountBeforeTax,
+		TaxAmount:       invoice.TaxAmount,
+		TotalAmount:     invoice.TotalAmount,
+		Reason:          derefString(invoice.Reason),
+	}
+
+	if invoice.OriginalInvoiceID != nil {
+		original, err := s.repo.GetByID(ctx, *invoice.OriginalInvoiceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load original e-invoice: %w", err)
+		}
+		if original.InvoiceNumber == nil || original.IssuedAt == nil {
+			return nil, fmt.Errorf("original e-invoice %s has not been issued", original.ID)
+		}
+		inv.Original = &provider.OriginalRef{
+			TemplateCode:  original.TemplateCode,
+			Series:        original.InvoiceSeries,
+			InvoiceNumber: *original.InvoiceNumber,
+			IssuedAt:      *original.IssuedAt,
+		}
+	}
+
+	if invoice.InvoiceType == model.TypeAdjustment {
+		direction := "tăng"
+		if invoice.TotalAmount.IsNegative() {
+			direction = "giảm"
+		}
+		inv.Lines = []provider.Line{{
+			Name:      fmt.Sprintf("Điều chỉnh %s đơn hàng %s: %s", direction, invoice.OrderNumber, derefString(invoice.Reason)),
+			Quantity:  1,
+			UnitPrice: invoice.TotalAmount,
+			Amount:    invoice.TotalAmount,
+		}}
+		return inv, nil
+	}
+
+	order, err := s.repo.GetOrderSnapshot(ctx, invoice.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	inv.Lines = buildOrderLines(order)
+	return inv, nil
+}
+
+// buildOrderLines dòng sách + phí ship + giảm phí ship + chiết khấu; phần còn lại của total (phí COD) thành 1 dòng phí
+func buildOrderLines(order *model.OrderSnapshot) []provider.Line {
+	lines := make([]provider.Line, 0, len(order.Items)+4)
+	for _, item := range order.Items {
+		lines = append(lines, provider.Line{
+			Name:      item.Title,
+			Unit:      "Cuốn",
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Amount:    item.Subtotal,
+		})
+	}
+	if order.ShippingFee.IsPositive() {
+		lines = append(lines, provider.Line{
+			Name:      "Phí vận chuyển",
+			Unit:      "Lần",
+			Quantity:  1,
+			UnitPrice: order.ShippingFee,
+			Amount:    order.ShippingFee,
+		})
+	}
+	if order.ShippingDiscount.IsPositive() {
+		lines = append(lines, provider.Line{
+			Name:       "Giảm phí vận chuyển",
+			Quantity:   1,
+			UnitPrice:  order.ShippingDiscount,
+			Amount:     order.ShippingDiscount,
+			IsDiscount: true,
+		})
+	}
+	if order.DiscountAmount.IsPositive() {
+		lines = append(lines, provider.Line{
+			Name:       "Chiết khấu thương mại",
+			Quantity:   1,
+			UnitPrice:  order.DiscountAmount,
+			Amount:     order.DiscountAmount,
+			IsDiscount: true,
+		})
+	}
+
+	listed := order.Subtotal.Add(order.ShippingFee).Sub(order.ShippingDiscount).Sub(order.DiscountAmount)
+	if other := order.Total.Sub(listed); other.IsPositive() {
+		lines = append(lines, provider.Line{
+			Name:      "Phí thu hộ (COD)",
+			Unit:      "Lần",
+			Quantity:  1,
+			UnitPrice: other,
+			Amount:    other,
+		})
+	}
+	return lines
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+// getOrder - khách xem đơn của người khác → not found (không lộ đơn tồn tại)
+func (s *einvoiceService) getOrder(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID) (*model.OrderSnapshot, error) {
+	order, err := s.repo.GetOrderSnapshot(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, model.ErrOrderNotFound) {
+			return nil, model.NewOrderNotFoundError()
+		}
+		return nil, err
+	}
+	if userID != nil && order.UserID != *userID {
+		return nil, model.NewOrderNotFoundError()
+	}
+	return order, nil
+}
+
+// getAdjustableInvoice hoá đơn gốc / thay thế đang có hiệu lực
+func (s *einvoiceService) getAdjustableInvoice(ctx context.Context, invoiceID uuid.UUID) (*model.EInvoice, error) {
+	original, err := s.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if !original.IsEffective() {
+		return nil, model.NewInvoiceNotEffectiveError(original.Status)
+	}
+	if original.InvoiceType == model.TypeAdjustment {
+		return nil, model.NewInvalidRequestError("Adjustment invoices cannot be replaced or adjusted, use the original invoice")
+	}
+	if _, ok := s.providers[s.cfg.Provider]; !ok {
+		return nil, model.NewProviderUnavailableError(s.cfg.Provider)
+	}
+	return original, nil
+}
+
+// createDerived lưu hoá đơn thay thế / điều chỉnh rồi đưa vào hàng đợi phát hành
+func (s *einvoiceService) createDerived(ctx context.Context, invoice, original *model.EInvoice) (*model.EInvoice, error) {
+	if err := s.repo.Create(ctx, invoice); err != nil {
+		if errors.Is(err, model.ErrAlreadyReplaced) {
+			return nil, model.NewAlreadyReplacedError()
+		}
+		return nil, err
+	}
+	invoice.OrderNumber = original.OrderNumber
+
+	if err := s.enqueueIssue(ctx, invoice.ID); err != nil {
+		return nil, err
+	}
+
+	logger.Info("E-invoice correction requested", map[string]interface{}{
+		"invoice_id":          invoice.ID.String(),
+		"original_invoice_id": original.ID.String(),
+		"invoice_type":        invoice.InvoiceType,
+		"total_amount":        invoice.TotalAmount.String(),
+	})
+	return invoice, nil
+}
+
+// newInvoice hoá đơn pending theo mẫu số / ký hiệu hiện hành, tách VAT từ số tiền đã gồm thuế
+func (s *einvoiceService) newInvoice(
+	orderID uuid.UUID,
+	invoiceType string,
+	buyer model.BuyerInfo,
+	total decimal.Decimal,
+	requestedBy *uuid.UUID,
+) *model.EInvoice {
+	now := time.Now()
+	beforeTax, tax := model.SplitVAT(total, s.cfg.VATRate)
+	return &model.EInvoice{
+		ID:              uuid.New(),
+		OrderID:         orderID,
+		InvoiceType:     invoiceType,
+		Status:          model.StatusPending,
+		Provider:        s.cfg.Provider,
+		TemplateCode:    s.cfg.TemplateCode,
+		InvoiceSeries:   s.cfg.Series,
+		BuyerName:       buyer.Name,
+		BuyerCompany:    buyer.Company,
+		BuyerTaxCode:    buyer.TaxCode,
+		BuyerAddress:    buyer.Address,
+		BuyerEmail:      buyer.Email,
+		AmountBeforeTax: beforeTax,
+		VATRate:         s.cfg.VATRate,
+		TaxAmount:       tax,
+		TotalAmount:     total,
+		RequestedBy:     requestedBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+func (s *einvoiceService) enqueueIssue(ctx context.Context, invoiceID uuid.UUID) error {
+	payload, _ := json.Marshal(map[string]string{"invoice_id": invoiceID.String()})
+	task := tracing.NewTask(ctx, types.TypeIssueEInvoice, payload)
+	if _, err := s.asynqClient.Enqueue(task, asynq.Queue(types.QueueOrder), asynq.MaxRetry(model.MaxIssueAttempts)); err != nil {
+		return fmt.Errorf("enqueue e-invoice job: %w", err)
+	}
+	return nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/flashsale/model"
+	"bookstore-backend/internal/domains/flashsale/service"
+	orderModel "bookstore-backend/internal/domains/order/model"
+)
+
+// =====================================================
+// FLASH SALE HANDLER
+// =====================================================
+
+type FlashSaleHandler struct {
+	flashSaleService service.ServiceInterface
+}
+
+func NewFlashSaleHandler(flashSaleService service.ServiceInterface) *FlashSaleHandler {
+	return &FlashSaleHandler{
+		flashSaleService: flashSaleService,
+	}
+}
+
+// =====================================================
+// PUBLIC ENDPOINTS
+// =====================================================
+
+// ListFlashSales lists active and upcoming flash sales
+// GET /api/v1/flash-sales
+func (h *FlashSaleHandler) ListFlashSales(c *gin.Context) {
+	response, err := h.flashSaleService.ListUpcomingFlashSales(c.Request.Context())
+	if err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetFlashSale gets flash sale detail
+// GET /api/v1/flash-sales/:id
+func (h *FlashSaleHandler) GetFlashSale(c *gin.Context) {
+	saleID, ok := parseSaleID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.flashSaleService.GetFlashSale(c.Request.Context(), saleID)
+	if err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// =====================================================
+// USER ENDPOINTS
+// =====================================================
+
+// JoinQueue takes a ticket in flash sale queue
+// POST /api/v1/flash-sales/:id/queue
+func (h *FlashSaleHandler) JoinQueue(c *gin.Context) {
+	userID, saleID, ok := parseUserSaleParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.flashSaleService.JoinQueue(c.Request.Context(), userID, saleID)
+	if err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetQueueStatus returns queue position or purchase token
+// GET /api/v1/flash-sales/:id/queue
+func (h *FlashSaleHandler) GetQueueStatus(c *gin.Context) {
+	userID, saleID, ok := parseUserSaleParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.flashSaleService.GetQueueStatus(c.Request.Context(), userID, saleID)
+	if err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// Purchase redeems purchase token and creates order
+// POST /api/v1/flash-sales/:id/purchase
+func (h *FlashSaleHandler) Purchase(c *gin.Context) {
+	userID, saleID, ok := parseUserSaleParams(c)
+	if !ok {
+		return
+	}
+
+	var req model.PurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.flashSaleService.Purchase(c.Request.Context(), userID, saleID, req)
+	if err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// =====================================================
+// ADMIN ENDPOINTS
+// =====================================================
+
+// AdminCreateFlashSale creates flash sale
+// POST /api/v1/admin/flash-sales
+func (h *FlashSaleHandler) AdminCreateFlashSale(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.CreateFlashSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.flashSaleService.CreateFlashSale(c.Request.Context(), adminID, req)
+	if err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// AdminListFlashSales lists all flash sales
+// GET /api/v1/admin/flash-sales
+func (h *FlashSaleHandler) AdminListFlashSales(c *gin.Context) {
+	var req model.ListFlashSalesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.flashSaleService.AdminListFlashSales(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AdminUpdateFlashSaleStatus enables/disables flash sale
+// PATCH /api/v1/admin/flash-sales/:id/status
+func (h *FlashSaleHandler) AdminUpdateFlashSaleStatus(c *gin.Context) {
+	saleID, ok := parseSaleID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateFlashSaleStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.flashSaleService.UpdateFlashSaleStatus(c.Request.Context(), saleID, *req.IsActive); err != nil {
+		statusCode, errCode := mapFlashSaleError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Flash sale status updated successfully",
+	})
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// getUserID extracts user ID from JWT claims
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+}
+
+func parseSaleID(c *gin.Context) (uuid.UUID, bool) {
+	saleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid flash sale ID")
+		return uuid.Nil, false
+	}
+	return saleID, true
+}
+
+func parseUserSaleParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	saleID, ok := parseSaleID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, saleID, true
+}
+
+// respondSuccess sends success response
+func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// respondError sends error response
+func respondError(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// mapFlashSaleError maps flash sale/order error to HTTP status code
+func mapFlashSaleError(err error) (int, string) {
+	var fErr *model.FlashSaleError
+	if errors.As(err, &fErr) {
+		switch fErr.Code {
+		case model.ErrCodeFlashSaleNotFound, model.ErrCodeNotInQueue, model.ErrCodeBookNotFound:
+			return http.StatusNotFound, fErr.Code
+		case model.ErrCodeSaleNotOpen, model.ErrCodeSoldOut, model.ErrCodeAlreadyPurchased:
+			return http.StatusConflict, fErr.Code
+		case model.ErrCodeInvalidToken:
+			return http.StatusForbidden, fErr.Code
+		case model.ErrCodeInvalidRequest:
+			return http.StatusBadRequest, fErr.Code
+		default:
+			return http.StatusInternalServerError, "INTERNAL_ERROR"
+		}
+	}
+
+	// Lỗi khi tạo order (hết hàng trong kho, địa chỉ, payment method...)
+	var oErr *orderModel.OrderError
+	if errors.As(err, &oErr) {
+		if oErr.Code == orderModel.ErrCodeInsufficientStock {
+			return http.StatusConflict, oErr.Code
+		}
+		return http.StatusBadRequest, oErr.Code
+	}
+
+	return http.StatusInternalServerError, "INTERNAL_ERROR"
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Queue entry statuses
+const (
+	QueueStatusWaiting  = "waiting"  // đang xếp hàng
+	QueueStatusAdmitted = "admitted" // có purchase token, chưa dùng
+	QueueStatusUsed     = "used"     // đã dùng token để mua
+	QueueStatusExpired  = "expired"  // token hết hạn chưa dùng → có thể xếp hàng lại
+)
+
+// Admission
+const (
+	// AdmissionThrottle - mỗi flash sale chỉ chạy admission tối đa 1 lần / khoảng này,
+	// dù có hàng nghìn user poll trạng thái cùng lúc
+	AdmissionThrottle = 2 * time.Second
+
+	// AdmissionCacheKey flash_sale:admission:{sale_id}
+	AdmissionCacheKey = "flash_sale:admission:%s"
+
+	// PollIntervalSeconds gợi ý client poll lại queue status
+	PollIntervalSeconds = 3
+)
+
+// Limits
+const (
+	DefaultTokenTTLSeconds = 300
+	MinTokenTTLSeconds     = 30
+	MaxTokenTTLSeconds     = 3600
+	MaxPerUserLimit        = 10
+)
+
+// Pagination defaults
+const (
+	DefaultPage  = 1
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// USER REQUEST DTOs
+// =====================================================
+
+// PurchaseRequest dùng purchase token để mua
+type PurchaseRequest struct {
+	Token         uuid.UUID `json:"token" binding:"required"`
+	Quantity      int       `json:"quantity" binding:"required"`
+	AddressID     uuid.UUID `json:"address_id" binding:"required"`
+	PaymentMethod string    `json:"payment_method" binding:"required"`
+}
+
+// =====================================================
+// ADMIN REQUEST DTOs
+// =====================================================
+
+// CreateFlashSaleRequest admin tạo flash sale
+type CreateFlashSaleRequest struct {
+	Name            string          `json:"name" binding:"required"`
+	BookID          uuid.UUID       `json:"book_id" binding:"required"`
+	SalePrice       decimal.Decimal `json:"sale_price" binding:"required"`
+	StockLimit      int             `json:"stock_limit" binding:"required"`
+	PerUserLimit    int             `json:"per_user_limit"`
+	TokenTTLSeconds int             `json:"token_ttl_seconds"`
+	StartsAt        time.Time       `json:"starts_at" binding:"required"`
+	EndsAt          time.Time       `json:"ends_at" binding:"required"`
+}
+
+func (r *CreateFlashSaleRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return NewInvalidRequestError("name is required")
+	}
+	if r.SalePrice.IsNegative() {
+		return NewInvalidRequestError("sale_price must not be negative")
+	}
+	if r.StockLimit < 1 {
+		return NewInvalidRequestError("stock_limit must be positive")
+	}
+	if r.PerUserLimit == 0 {
+		r.PerUserLimit = 1
+	}
+	if r.PerUserLimit < 1 || r.PerUserLimit > MaxPerUserLimit {
+		return NewInvalidRequestError(fmt.Sprintf("per_user_limit must be between 1 and %d", MaxPerUserLimit))
+	}
+	if r.TokenTTLSeconds == 0 {
+		r.TokenTTLSeconds = DefaultTokenTTLSeconds
+	}
+	if r.TokenTTLSeconds < MinTokenTTLSeconds || r.TokenTTLSeconds > MaxTokenTTLSeconds {
+		return NewInvalidRequestError(fmt.Sprintf("token_ttl_seconds must be between %d and %d", MinTokenTTLSeconds, MaxTokenTTLSeconds))
+	}
+	if !r.EndsAt.After(r.StartsAt) {
+		return NewInvalidRequestError("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// UpdateFlashSaleStatusRequest bật/tắt flash sale
+type UpdateFlashSaleStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+// ListFlashSalesRequest admin list
+type ListFlashSalesRequest struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
+
+func (r *ListFlashSalesRequest) Validate() error {
+	if r.Page < 1 {
+		r.Page = DefaultPage
+	}
+	if r.Limit < 1 || r.Limit > MaxLimit {
+		r.Limit = DefaultLimit
+	}
+	return nil
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// FlashSaleResponse flash sale
+type FlashSaleResponse struct {
+	ID              uuid.UUID       `json:"id"`
+	Name            string          `json:"name"`
+	BookID          uuid.UUID       `json:"book_id"`
+	SalePrice       decimal.Decimal `json:"sale_price"`
+	StockLimit      int             `json:"stock_limit"`
+	RemainingUnits  int             `json:"remaining_units"`
+	PerUserLimit    int             `json:"per_user_limit"`
+	TokenTTLSeconds int             `json:"token_ttl_seconds"`
+	StartsAt        time.Time       `json:"starts_at"`
+	EndsAt          time.Time       `json:"ends_at"`
+	IsActive        bool            `json:"is_active"`
+	IsOpen          bool            `json:"is_open"`
+}
+
+// QueueStatusResponse trạng thái của user trong hàng đợi
+type QueueStatusResponse struct {
+	FlashSaleID    uuid.UUID  `json:"flash_sale_id"`
+	Status         string     `json:"status"`
+	Ticket         int64      `json:"ticket"`
+	Position       int        `json:"position,omitempty"` // số người đứng trước (khi waiting)
+	Token          *uuid.UUID `json:"token,omitempty"`
+	TokenExpiresAt *time.Time `json:"token_expires_at,omitempty"`
+	OrderID        *uuid.UUID `json:"order_id,omitempty"`
+	RemainingUnits int        `json:"remaining_units"`
+	PollAfterSecs  int        `json:"poll_after_seconds,omitempty"`
+}
+
+// ListFlashSalesResponse paginated flash sales
+type ListFlashSalesResponse struct {
+	FlashSales []FlashSaleResponse `json:"flash_sales"`
+	Total      int                 `json:"total"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+}
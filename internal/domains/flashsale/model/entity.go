@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FlashSale - đợt flash sale 1 sách, số lượng giới hạn
+type FlashSale struct {
+	ID              uuid.UUID
+	Name            string
+	BookID          uuid.UUID
+	SalePrice       decimal.Decimal
+	StockLimit      int
+	SoldUnits       int
+	PerUserLimit    int
+	TokenTTLSeconds int
+	StartsAt        time.Time
+	EndsAt          time.Time
+	IsActive        bool
+	CreatedBy       *uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// IsOpen đang trong khung giờ bán
+func (s *FlashSale) IsOpen(now time.Time) bool {
+	return s.IsActive && !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// RemainingUnits số cuốn còn lại
+func (s *FlashSale) RemainingUnits() int {
+	return s.StockLimit - s.SoldUnits
+}
+
+// QueueEntry - vị trí của user trong hàng đợi
+type QueueEntry struct {
+	FlashSaleID    uuid.UUID
+	UserID         uuid.UUID
+	Ticket         int64
+	Status         string
+	Token          *uuid.UUID
+	TokenExpiresAt *time.Time
+	OrderID        *uuid.UUID
+	JoinedAt       time.Time
+	AdmittedAt     *time.Time
+	UsedAt         *time.Time
+}
+
+// HasValidToken token admitted còn hạn
+func (e *QueueEntry) HasValidToken(now time.Time) bool {
+	return e.Status == QueueStatusAdmitted && e.TokenExpiresAt != nil && now.Before(*e.TokenExpiresAt)
+}
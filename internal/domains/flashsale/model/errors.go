@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodeFlashSaleNotFound = "FLS001"
+	ErrCodeSaleNotOpen       = "FLS002"
+	ErrCodeSoldOut           = "FLS003"
+	ErrCodeNotInQueue        = "FLS004"
+	ErrCodeInvalidToken      = "FLS005"
+	ErrCodeInvalidRequest    = "FLS006"
+	ErrCodeAlreadyPurchased  = "FLS007"
+	ErrCodeBookNotFound      = "FLS008"
+)
+
+// Errors
+var (
+	ErrFlashSaleNotFound = errors.New("flash sale not found")
+	ErrSaleNotOpen       = errors.New("flash sale is not open")
+	ErrSoldOut           = errors.New("flash sale sold out")
+	ErrNotInQueue        = errors.New("not in queue")
+	ErrInvalidToken      = errors.New("invalid or expired purchase token")
+	ErrInvalidRequest    = errors.New("invalid request")
+	ErrAlreadyPurchased  = errors.New("already purchased")
+	ErrBookNotFound      = errors.New("book not found")
+)
+
+// FlashSaleError custom error type
+type FlashSaleError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *FlashSaleError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *FlashSaleError) Unwrap() error {
+	return e.Err
+}
+
+// Error constructors
+func NewFlashSaleNotFoundError() *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeFlashSaleNotFound,
+		Message: "Flash sale not found",
+		Err:     ErrFlashSaleNotFound,
+	}
+}
+
+func NewSaleNotOpenError() *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeSaleNotOpen,
+		Message: "Flash sale is not open",
+		Err:     ErrSaleNotOpen,
+	}
+}
+
+func NewSoldOutError() *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeSoldOut,
+		Message: "Flash sale is sold out",
+		Err:     ErrSoldOut,
+	}
+}
+
+func NewNotInQueueError() *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeNotInQueue,
+		Message: "You have not joined the queue for this flash sale",
+		Err:     ErrNotInQueue,
+	}
+}
+
+func NewInvalidTokenError() *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeInvalidToken,
+		Message: "Purchase token is invalid or expired, please rejoin the queue",
+		Err:     ErrInvalidToken,
+	}
+}
+
+func NewInvalidRequestError(message string) *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeInvalidRequest,
+		Message: message,
+		Err:     ErrInvalidRequest,
+	}
+}
+
+func NewAlreadyPurchasedError() *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeAlreadyPurchased,
+		Message: "You have already purchased in this flash sale",
+		Err:     ErrAlreadyPurchased,
+	}
+}
+
+func NewBookNotFoundError() *FlashSaleError {
+	return &FlashSaleError{
+		Code:    ErrCodeBookNotFound,
+		Message: "Book not found",
+		Err:     ErrBookNotFound,
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/flashsale/model"
+)
+
+// =====================================================
+// FLASH SALE REPOSITORY INTERFACE
+// =====================================================
+
+type FlashSaleRepository interface {
+	// ========================================
+	// FLASH SALES
+	// ========================================
+
+	// CreateFlashSale creates flash sale
+	CreateFlashSale(ctx context.Context, sale *model.FlashSale) error
+
+	// GetFlashSaleByID gets flash sale
+	GetFlashSaleByID(ctx context.Context, id uuid.UUID) (*model.FlashSale, error)
+
+	// ListUpcomingFlashSales lists active sales not yet ended
+	ListUpcomingFlashSales(ctx context.Context, now time.Time) ([]*model.FlashSale, error)
+
+	// ListFlashSales lists all sales (admin)
+	ListFlashSales(ctx context.Context, page, limit int) ([]*model.FlashSale, int, error)
+
+	// UpdateFlashSaleActive enables/disables sale
+	UpdateFlashSaleActive(ctx context.Context, id uuid.UUID, isActive bool) error
+
+	// BookExists checks book
+	BookExists(ctx context.Context, bookID uuid.UUID) (bool, error)
+
+	// ========================================
+	// QUEUE
+	// ========================================
+
+	// JoinQueue adds user to queue (idempotent); expired entry rejoins at the back
+	JoinQueue(ctx context.Context, saleID, userID uuid.UUID) (*model.QueueEntry, error)
+
+	// GetQueueEntry gets user's entry
+	GetQueueEntry(ctx context.Context, saleID, userID uuid.UUID) (*model.QueueEntry, error)
+
+	// CountAhead counts waiting entries with smaller ticket
+	CountAhead(ctx context.Context, saleID uuid.UUID, ticket int64) (int, error)
+
+	// AdmitNext expires stale tokens and admits next waiting users while stock allows.
+	// Returns number admitted (0 if another admission is running).
+	AdmitNext(ctx context.Context, saleID uuid.UUID) (int, error)
+
+	// ========================================
+	// PURCHASE
+	// ========================================
+
+	// RedeemToken marks token used and claims units atomically
+	RedeemToken(ctx context.Context, saleID, userID, token uuid.UUID, quantity int) error
+
+	// RestoreToken reverts RedeemToken when order creation fails
+	RestoreToken(ctx context.Context, saleID, userID uuid.UUID, quantity int) error
+
+	// SetQueueOrder links created order to queue entry
+	SetQueueOrder(ctx context.Context, saleID, userID, orderID uuid.UUID) error
+}
@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/flashsale/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresFlashSaleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresFlashSaleRepository(pool *pgxpool.Pool) FlashSaleRepository {
+	return &postgresFlashSaleRepository{pool: pool}
+}
+
+const flashSaleColumns = `
+	id, name, book_id, sale_price, stock_limit, sold_units, per_user_limit,
+	token_ttl_seconds, starts_at, ends_at, is_active, created_by, created_at, updated_at`
+
+const queueColumns = `
+	flash_sale_id, user_id, ticket, status, token, token_expires_at,
+	order_id, joined_at, admitted_at, used_at`
+
+func scanFlashSale(row pgx.Row, sale *model.FlashSale) error {
+	return row.Scan(
+		&sale.ID,
+		&sale.Name,
+		&sale.BookID,
+		&sale.SalePrice,
+		&sale.StockLimit,
+		&sale.SoldUnits,
+		&sale.PerUserLimit,
+		&sale.TokenTTLSeconds,
+		&sale.StartsAt,
+		&sale.EndsAt,
+		&sale.IsActive,
+		&sale.CreatedBy,
+		&sale.CreatedAt,
+		&sale.UpdatedAt,
+	)
+}
+
+func scanQueueEntry(row pgx.Row, entry *model.QueueEntry) error {
+	return row.Scan(
+		&entry.FlashSaleID,
+		&entry.UserID,
+		&entry.Ticket,
+		&entry.Status,
+		&entry.Token,
+		&entry.TokenExpiresAt,
+		&entry.OrderID,
+		&entry.JoinedAt,
+		&entry.AdmittedAt,
+		&entry.UsedAt,
+	)
+}
+
+// =====================================================
+// FLASH SALES
+// =====================================================
+
+func (r *postgresFlashSaleRepository) CreateFlashSale(ctx context.Context, sale *model.FlashSale) error {
+	query := `
+		INSERT INTO flash_sales (
+			id, name, book_id, sale_price, stock_limit, per_user_limit,
+			token_ttl_seconds, starts_at, ends_at, is_active, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		sale.ID,
+		sale.Name,
+		sale.BookID,
+		sale.SalePrice,
+		sale.StockLimit,
+		sale.PerUserLimit,
+		sale.TokenTTLSeconds,
+		sale.StartsAt,
+		sale.EndsAt,
+		sale.IsActive,
+		sale.CreatedBy,
+	).Scan(&sale.CreatedAt, &sale.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create flash sale: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresFlashSaleRepository) GetFlashSaleByID(ctx context.Context, id uuid.UUID) (*model.FlashSale, error) {
+	query := `SELECT ` + flashSaleColumns + ` FROM flash_sales WHERE id = $1`
+
+	sale := &model.FlashSale{}
+	if err := scanFlashSale(r.pool.QueryRow(ctx, query, id), sale); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrFlashSaleNotFound
+		}
+		return nil, fmt.Errorf("failed to get flash sale: %w", err)
+	}
+
+	return sale, nil
+}
+
+func (r *postgresFlashSaleRepository) ListUpcomingFlashSales(ctx context.Context, now time.Time) ([]*model.FlashSale, error) {
+	query := `SELECT ` + flashSaleColumns + `
+		FROM flash_sales
+		WHERE is_active = TRUE AND ends_at > $1
+		ORDER BY starts_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flash sales: %w", err)
+	}
+	defer rows.Close()
+
+	var sales []*model.FlashSale
+	for rows.Next() {
+		sale := &model.FlashSale{}
+		if err := scanFlashSale(rows, sale); err != nil {
+			return nil, fmt.Errorf("failed to scan flash sale: %w", err)
+		}
+		sales = append(sales, sale)
+	}
+
+	return sales, rows.Err()
+}
+
+func (r *postgresFlashSaleRepository) ListFlashSales(ctx context.Context, page, limit int) ([]*model.FlashSale, int, error) {
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM flash_sales`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count flash sales: %w", err)
+	}
+
+	query := `SELECT ` + flashSaleColumns + `
+		FROM flash_sales
+		ORDER BY starts_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list flash sales: %w", err)
+	}
+	defer rows.Close()
+
+	var sales []*model.FlashSale
+	for rows.Next() {
+		sale := &model.FlashSale{}
+		if err := scanFlashSale(rows, sale); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan flash sale: %w", err)
+		}
+		sales = append(sales, sale)
+	}
+
+	return sales, total, rows.Err()
+}
+
+func (r *postgresFlashSaleRepository) UpdateFlashSaleActive(ctx context.Context, id uuid.UUID, isActive bool) error {
+	result, err := r.pool.Exec(ctx, `UPDATE flash_sales SET is_active = $2 WHERE id = $1`, id, isActive)
+	if err != nil {
+		return fmt.Errorf("failed to update flash sale: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrFlashSaleNotFound
+	}
+
+	return nil
+}
+
+func (r *postgresFlashSaleRepository) BookExists(ctx context.Context, bookID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM books WHERE id = $1 AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, bookID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check book: %w", err)
+	}
+
+	return exists, nil
+}
+
+// =====================================================
+// QUEUE
+// =====================================================
+
+func (r *postgresFlashSaleRepository) JoinQueue(ctx context.Context, saleID, userID uuid.UUID) (*model.QueueEntry, error) {
+	// Rejoin chỉ khi token cũ đã expired → lấy ticket mới (xếp cuối hàng).
+	// waiting/admitted/used: giữ nguyên (DO UPDATE ... WHERE không match → không RETURNING)
+	query := `
+		INSERT INTO flash_sale_queue (flash_sale_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (flash_sale_id, user_id) DO UPDATE
+		SET ticket = nextval('flash_sale_ticket_seq'),
+			status = 'waiting',
+			token = NULL,
+			token_expires_at = NULL,
+			joined_at = NOW(),
+			admitted_at = NULL
+		WHERE flash_sale_queue.status = 'expired'
+		RETURNING ` + queueColumns
+
+	entry := &model.QueueEntry{}
+	err := scanQueueEntry(r.pool.QueryRow(ctx, query, saleID, userID), entry)
+	if err == nil {
+		return entry, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to join queue: %w", err)
+	}
+
+	return r.GetQueueEntry(ctx, saleID, userID)
+}
+
+func (r *postgresFlashSaleRepository) GetQueueEntry(ctx context.Context, saleID, userID uuid.UUID) (*model.QueueEntry, error) {
+	query := `SELECT ` + queueColumns + ` FROM flash_sale_queue WHERE flash_sale_id = $1 AND user_id = $2`
+
+	entry := &model.QueueEntry{}
+	if err := scanQueueEntry(r.pool.QueryRow(ctx, query, saleID, userID), entry); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrNotInQueue
+		}
+		return nil, fmt.Errorf("failed to get queue entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (r *postgresFlashSaleRepository) CountAhead(ctx context.Context, saleID uuid.UUID, ticket int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM flash_sale_queue
+		WHERE flash_sale_id = $1 AND status = 'waiting' AND ticket < $2
+	`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, saleID, ticket).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count queue position: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *postgresFlashSaleRepository) AdmitNext(ctx context.Context, saleID uuid.UUID) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Step 1: Lock sale row; SKIP LOCKED → instance khác đang admit thì bỏ qua
+	var stockLimit, soldUnits, perUserLimit, ttlSeconds int
+	err = tx.QueryRow(ctx, `
+		SELECT stock_limit, sold_units, per_user_limit, token_ttl_seconds
+		FROM flash_sales
+		WHERE id = $1 AND is_active = TRUE AND NOW() >= starts_at AND NOW() < ends_at
+		FOR UPDATE SKIP LOCKED
+	`, saleID).Scan(&stockLimit, &soldUnits, &perUserLimit, &ttlSeconds)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to lock flash sale: %w", err)
+	}
+
+	// Step 2: Token hết hạn chưa dùng → trả slot
+	if _, err := tx.Exec(ctx, `
+		UPDATE flash_sale_queue
+		SET status = 'expired'
+		WHERE flash_sale_id = $1 AND status = 'admitted' AND token_expires_at <= NOW()
+	`, saleID); err != nil {
+		return 0, fmt.Errorf("failed to expire tokens: %w", err)
+	}
+
+	// Step 3: Slot trống = số người còn mua được tối đa - token đang active
+	var active int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM flash_sale_queue WHERE flash_sale_id = $1 AND status = 'admitted'
+	`, saleID).Scan(&active); err != nil {
+		return 0, fmt.Errorf("failed to count active tokens: %w", err)
+	}
+
+	slots := (stockLimit-soldUnits)/perUserLimit - active
+	if slots <= 0 {
+		return 0, tx.Commit(ctx)
+	}
+
+	// Step 4: Admit theo ticket tăng dần (FIFO)
+	result, err := tx.Exec(ctx, `
+		UPDATE flash_sale_queue q
+		SET status = 'admitted',
+			token = uuid_generate_v4(),
+			token_expires_at = NOW() + $3::int * INTERVAL '1 second',
+			admitted_at = NOW()
+		FROM (
+			SELECT user_id
+			FROM flash_sale_queue
+			WHERE flash_sale_id = $1 AND status = 'waiting'
+			ORDER BY ticket
+			LIMIT $2
+		) next
+		WHERE q.flash_sale_id = $1 AND q.user_id = next.user_id
+	`, saleID, slots, ttlSeconds)
+	if err != nil {
+		return 0, fmt.Errorf("failed to admit queue: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit admission: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// =====================================================
+// PURCHASE
+// =====================================================
+
+func (r *postgresFlashSaleRepository) RedeemToken(
+	ctx context.Context,
+	saleID, userID, token uuid.UUID,
+	quantity int,
+) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Token: đúng user, đúng sale, còn hạn, chưa dùng
+	result, err := tx.Exec(ctx, `
+		UPDATE flash_sale_queue
+		SET status = 'used', used_at = NOW()
+		WHERE flash_sale_id = $1 AND user_id = $2 AND token = $3
+			AND status = 'admitted' AND token_expires_at > NOW()
+	`, saleID, userID, token)
+	if err != nil {
+		return fmt.Errorf("failed to redeem token: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrInvalidToken
+	}
+
+	// Claim units (guard oversell dù admission đã tính slot)
+	result, err = tx.Exec(ctx, `
+		UPDATE flash_sales
+		SET sold_units = sold_units + $2
+		WHERE id = $1 AND sold_units + $2 <= stock_limit AND $2 <= per_user_limit
+	`, saleID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to claim flash sale units: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrSoldOut
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit token redemption: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresFlashSaleRepository) RestoreToken(ctx context.Context, saleID, userID uuid.UUID, quantity int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Token quay về admitted (nếu đã quá hạn, lần admission tiếp theo sẽ expire)
+	result, err := tx.Exec(ctx, `
+		UPDATE flash_sale_queue
+		SET status = 'admitted', used_at = NULL
+		WHERE flash_sale_id = $1 AND user_id = $2 AND status = 'used' AND order_id IS NULL
+	`, saleID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to restore token: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE flash_sales SET sold_units = GREATEST(sold_units - $2, 0) WHERE id = $1
+	`, saleID, quantity); err != nil {
+		return fmt.Errorf("failed to release flash sale units: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit token restore: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresFlashSaleRepository) SetQueueOrder(ctx context.Context, saleID, userID, orderID uuid.UUID) error {
+	query := `UPDATE flash_sale_queue SET order_id = $3 WHERE flash_sale_id = $1 AND user_id = $2`
+
+	if _, err := r.pool.Exec(ctx, query, saleID, userID, orderID); err != nil {
+		return fmt.Errorf("failed to link flash sale order: %w", err)
+	}
+
+	return nil
+}
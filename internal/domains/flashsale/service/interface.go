@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/flashsale/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+)
+
+// =====================================================
+// FLASH SALE SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ========================================
+	// PUBLIC
+	// ========================================
+
+	// ListUpcomingFlashSales lists active and upcoming sales
+	ListUpcomingFlashSales(ctx context.Context) ([]model.FlashSaleResponse, error)
+
+	// GetFlashSale gets sale detail
+	GetFlashSale(ctx context.Context, saleID uuid.UUID) (*model.FlashSaleResponse, error)
+
+	// ========================================
+	// USER QUEUE
+	// ========================================
+
+	// JoinQueue takes a FIFO ticket (idempotent)
+	JoinQueue(ctx context.Context, userID, saleID uuid.UUID) (*model.QueueStatusResponse, error)
+
+	// GetQueueStatus returns position or purchase token once admitted
+	GetQueueStatus(ctx context.Context, userID, saleID uuid.UUID) (*model.QueueStatusResponse, error)
+
+	// Purchase redeems purchase token and creates order at sale price
+	Purchase(ctx context.Context, userID, saleID uuid.UUID, req model.PurchaseRequest) (*orderModel.CreateOrderResponse, error)
+
+	// ========================================
+	// ADMIN
+	// ========================================
+
+	// CreateFlashSale creates sale
+	CreateFlashSale(ctx context.Context, adminID uuid.UUID, req model.CreateFlashSaleRequest) (*model.FlashSaleResponse, error)
+
+	// AdminListFlashSales lists all sales
+	AdminListFlashSales(ctx context.Context, req model.ListFlashSalesRequest) (*model.ListFlashSalesResponse, error)
+
+	// UpdateFlashSaleStatus enables/disables sale
+	UpdateFlashSaleStatus(ctx context.Context, saleID uuid.UUID, isActive bool) error
+}
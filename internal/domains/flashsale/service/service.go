@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/flashsale/model"
+	"bookstore-backend/internal/domains/flashsale/repository"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	orderService "bookstore-backend/internal/domains/order/service"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type flashSaleService struct {
+	flashSaleRepo repository.FlashSaleRepository
+	orderService  orderService.OrderService
+	cache         cache.Cache
+}
+
+func NewFlashSaleService(
+	flashSaleRepo repository.FlashSaleRepository,
+	orderService orderService.OrderService,
+	cache cache.Cache,
+) ServiceInterface {
+	return &flashSaleService{
+		flashSaleRepo: flashSaleRepo,
+		orderService:  orderService,
+		cache:         cache,
+	}
+}
+
+// =====================================================
+// PUBLIC
+// =====================================================
+
+func (s *flashSaleService) ListUpcomingFlashSales(ctx context.Context) ([]model.FlashSaleResponse, error) {
+	now := time.Now()
+	sales, err := s.flashSaleRepo.ListUpcomingFlashSales(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.FlashSaleResponse, len(sales))
+	for i, sale := range sales {
+		responses[i] = toFlashSaleResponse(sale, now)
+	}
+	return responses, nil
+}
+
+func (s *flashSaleService) GetFlashSale(ctx context.Context, saleID uuid.UUID) (*model.FlashSaleResponse, error) {
+	sale, err := s.getSale(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toFlashSaleResponse(sale, time.Now())
+	return &response, nil
+}
+
+// =====================================================
+// USER QUEUE
+// =====================================================
+
+// JoinQueue - lấy số thứ tự. Chỉ mở khi sale đang diễn ra (không có lợi thế xếp hàng trước giờ mở)
+func (s *flashSaleService) JoinQueue(ctx context.Context, userID, saleID uuid.UUID) (*model.QueueStatusResponse, error) {
+	sale, err := s.getSale(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if !sale.IsOpen(time.Now()) {
+		return nil, model.NewSaleNotOpenError()
+	}
+	if sale.RemainingUnits() < 1 {
+		return nil, model.NewSoldOutError()
+	}
+
+	entry, err := s.flashSaleRepo.JoinQueue(ctx, saleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("User joined flash sale queue", map[string]interface{}{
+		"flash_sale_id": saleID,
+		"user_id":       userID,
+		"ticket":        entry.Ticket,
+		"status":        entry.Status,
+	})
+
+	sale, entry = s.advanceQueue(ctx, sale, entry)
+	return s.buildQueueStatus(ctx, sale, entry)
+}
+
+// GetQueueStatus - client poll; mỗi lần poll có thể kích hoạt admission (đã throttle)
+func (s *flashSaleService) GetQueueStatus(ctx context.Context, userID, saleID uuid.UUID) (*model.QueueStatusResponse, error) {
+	sale, err := s.getSale(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.getEntry(ctx, saleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sale, entry = s.advanceQueue(ctx, sale, entry)
+	return s.buildQueueStatus(ctx, sale, entry)
+}
+
+// Purchase - dùng purchase token để mua với giá flash sale
+func (s *flashSaleService) Purchase(
+	ctx context.Context,
+	userID, saleID uuid.UUID,
+	req model.PurchaseRequest,
+) (*orderModel.CreateOrderResponse, error) {
+	sale, err := s.getSale(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if !sale.IsOpen(time.Now()) {
+		return nil, model.NewSaleNotOpenError()
+	}
+	if req.Quantity < 1 || req.Quantity > sale.PerUserLimit {
+		return nil, model.NewInvalidRequestError(fmt.Sprintf("quantity must be between 1 and %d", sale.PerUserLimit))
+	}
+
+	entry, err := s.getEntry(ctx, saleID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status == model.QueueStatusUsed {
+		return nil, model.NewAlreadyPurchasedError()
+	}
+
+	// Step 1: Redeem token + claim units (atomic) → chỉ người có token mới tới được reserve_stock
+	if err := s.flashSaleRepo.RedeemToken(ctx, saleID, userID, req.Token, req.Quantity); err != nil {
+		switch {
+		case errors.Is(err, model.ErrInvalidToken):
+			return nil, model.NewInvalidTokenError()
+		case errors.Is(err, model.ErrSoldOut):
+			return nil, model.NewSoldOutError()
+		}
+		return nil, err
+	}
+
+	// Step 2: Tạo order (reserve stock trong kho) với giá flash sale
+	order, err := s.orderService.CreateFlashSaleOrder(ctx, userID, orderModel.CreateFlashSaleOrderRequest{
+		FlashSaleName: sale.Name,
+		BookID:        sale.BookID,
+		Quantity:      req.Quantity,
+		UnitPrice:     sale.SalePrice,
+		AddressID:     req.AddressID,
+		PaymentMethod: req.PaymentMethod,
+	})
+	if err != nil {
+		// Trả token + units để user thử lại trong thời hạn token
+		if restoreErr := s.flashSaleRepo.RestoreToken(ctx, saleID, userID, req.Quantity); restoreErr != nil {
+			logger.Error("Failed to restore flash sale token", restoreErr)
+		}
+		return nil, err
+	}
+
+	if err := s.flashSaleRepo.SetQueueOrder(ctx, saleID, userID, order.OrderID); err != nil {
+		logger.Error("Failed to link flash sale order", err)
+	}
+
+	logger.Info("Flash sale purchase completed", map[string]interface{}{
+		"flash_sale_id": saleID,
+		"user_id":       userID,
+		"order_id":      order.OrderID,
+		"quantity":      req.Quantity,
+	})
+
+	return order, nil
+}
+
+// =====================================================
+// ADMIN
+// =====================================================
+
+func (s *flashSaleService) CreateFlashSale(
+	ctx context.Context,
+	adminID uuid.UUID,
+	req model.CreateFlashSaleRequest,
+) (*model.FlashSaleResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.flashSaleRepo.BookExists(ctx, req.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, model.NewBookNotFoundError()
+	}
+
+	sale := &model.FlashSale{
+		ID:              uuid.New(),
+		Name:            req.Name,
+		BookID:          req.BookID,
+		SalePrice:       req.SalePrice,
+		StockLimit:      req.StockLimit,
+		PerUserLimit:    req.PerUserLimit,
+		TokenTTLSeconds: req.TokenTTLSeconds,
+		StartsAt:        req.StartsAt,
+		EndsAt:          req.EndsAt,
+		IsActive:        true,
+		CreatedBy:       &adminID,
+	}
+
+	if err := s.flashSaleRepo.CreateFlashSale(ctx, sale); err != nil {
+		return nil, err
+	}
+
+	response := toFlashSaleResponse(sale, time.Now())
+	return &response, nil
+}
+
+func (s *flashSaleService) AdminListFlashSales(
+	ctx context.Context,
+	req model.ListFlashSalesRequest,
+) (*model.ListFlashSalesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	sales, total, err := s.flashSaleRepo.ListFlashSales(ctx, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]model.FlashSaleResponse, len(sales))
+	for i, sale := range sales {
+		responses[i] = toFlashSaleResponse(sale, now)
+	}
+
+	return &model.ListFlashSalesResponse{
+		FlashSales: responses,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+func (s *flashSaleService) UpdateFlashSaleStatus(ctx context.Context, saleID uuid.UUID, isActive bool) error {
+	err := s.flashSaleRepo.UpdateFlashSaleActive(ctx, saleID, isActive)
+	if errors.Is(err, model.ErrFlashSaleNotFound) {
+		return model.NewFlashSaleNotFoundError()
+	}
+	return err
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+// advanceQueue - entry còn chờ hoặc token sắp/đã hết hạn → chạy admission để hàng đợi tiến lên,
+// sau đó đọc lại trạng thái mới nhất
+func (s *flashSaleService) advanceQueue(
+	ctx context.Context,
+	sale *model.FlashSale,
+	entry *model.QueueEntry,
+) (*model.FlashSale, *model.QueueEntry) {
+	if entry.Status != model.QueueStatusWaiting && entry.Status != model.QueueStatusAdmitted {
+		return sale, entry
+	}
+	if !s.runAdmission(ctx, sale) {
+		return sale, entry
+	}
+
+	if refreshed, err := s.getEntry(ctx, sale.ID, entry.UserID); err == nil {
+		entry = refreshed
+	}
+	if refreshedSale, err := s.getSale(ctx, sale.ID); err == nil {
+		sale = refreshedSale
+	}
+	return sale, entry
+}
+
+// runAdmission chạy admission tối đa 1 lần / AdmissionThrottle cho mỗi sale.
+// INCR trên Redis: chỉ request đầu tiên trong cửa sổ (count == 1) được chạy,
+// còn lại đọc trạng thái hiện có → DB không bị hammer dù poll dày.
+func (s *flashSaleService) runAdmission(ctx context.Context, sale *model.FlashSale) bool {
+	if !sale.IsOpen(time.Now()) {
+		return false
+	}
+
+	key := fmt.Sprintf(model.AdmissionCacheKey, sale.ID)
+	count, err := s.cache.Increment(ctx, key)
+	if err != nil {
+		// Redis lỗi → vẫn admit (DB đã có SKIP LOCKED chống chạy trùng)
+		logger.Error("Failed to throttle flash sale admission", err)
+	} else {
+		if count == 1 {
+			if err := s.cache.Expire(ctx, key, model.AdmissionThrottle); err != nil {
+				logger.Error("Failed to set flash sale admission throttle TTL", err)
+			}
+		} else {
+			// Phòng trường hợp key mất TTL (Expire lỗi) → khoá admission vĩnh viễn
+			if ttl, err := s.cache.TTL(ctx, key); err == nil && ttl < 0 {
+				_ = s.cache.Expire(ctx, key, model.AdmissionThrottle)
+			}
+			return false
+		}
+	}
+
+	admitted, err := s.flashSaleRepo.AdmitNext(ctx, sale.ID)
+	if err != nil {
+		logger.Error("Failed to admit flash sale queue", err)
+		return false
+	}
+
+	if admitted > 0 {
+		logger.Info("Flash sale queue admitted", map[string]interface{}{
+			"flash_sale_id": sale.ID,
+			"admitted":      admitted,
+		})
+	}
+	return true
+}
+
+func (s *flashSaleService) buildQueueStatus(
+	ctx context.Context,
+	sale *model.FlashSale,
+	entry *model.QueueEntry,
+) (*model.QueueStatusResponse, error) {
+	now := time.Now()
+	response := &model.QueueStatusResponse{
+		FlashSaleID:    sale.ID,
+		Status:         entry.Status,
+		Ticket:         entry.Ticket,
+		OrderID:        entry.OrderID,
+		RemainingUnits: sale.RemainingUnits(),
+	}
+
+	switch entry.Status {
+	case model.QueueStatusWaiting:
+		position, err := s.flashSaleRepo.CountAhead(ctx, sale.ID, entry.Ticket)
+		if err != nil {
+			return nil, err
+		}
+		response.Position = position
+		response.PollAfterSecs = model.PollIntervalSeconds
+
+	case model.QueueStatusAdmitted:
+		// Token quá hạn nhưng admission chưa chạy → hiển thị expired, không trả token
+		if !entry.HasValidToken(now) {
+			response.Status = model.QueueStatusExpired
+			break
+		}
+		response.Token = entry.Token
+		response.TokenExpiresAt = entry.TokenExpiresAt
+	}
+
+	return response, nil
+}
+
+func (s *flashSaleService) getSale(ctx context.Context, saleID uuid.UUID) (*model.FlashSale, error) {
+	sale, err := s.flashSaleRepo.GetFlashSaleByID(ctx, saleID)
+	if err != nil {
+		if errors.Is(err, model.ErrFlashSaleNotFound) {
+			return nil, model.NewFlashSaleNotFoundError()
+		}
+		return nil, err
+	}
+	return sale, nil
+}
+
+func (s *flashSaleService) getEntry(ctx context.Context, saleID, userID uuid.UUID) (*model.QueueEntry, error) {
+	entry, err := s.flashSaleRepo.GetQueueEntry(ctx, saleID, userID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotInQueue) {
+			return nil, model.NewNotInQueueError()
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+func toFlashSaleResponse(sale *model.FlashSale, now time.Time) model.FlashSaleResponse {
+	return model.FlashSaleResponse{
+		ID:              sale.ID,
+		Name:            sale.Name,
+		BookID:          sale.BookID,
+		SalePrice:       sale.SalePrice,
+		StockLimit:      sale.StockLimit,
+		RemainingUnits:  sale.RemainingUnits(),
+		PerUserLimit:    sale.PerUserLimit,
+		TokenTTLSeconds: sale.TokenTTLSeconds,
+		StartsAt:        sale.StartsAt,
+		EndsAt:          sale.EndsAt,
+		IsActive:        sale.IsActive,
+		IsOpen:          sale.IsOpen(now),
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/domains/fraud/model"
+	"bookstore-backend/internal/domains/fraud/service"
+	"bookstore-backend/internal/shared/response"
+)
+
+type Handler struct {
+	service service.ServiceInterface
+}
+
+func NewHandler(service service.ServiceInterface) *Handler {
+	return &Handler{service: service}
+}
+
+// AdminListDeviceLogs - GET /admin/fraud/device-logs?fingerprint=&ip=&user_id=&action=&from=&to=&page=&limit=
+func (h *Handler) AdminListDeviceLogs(c *gin.Context) {
+	var req model.DeviceLogQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.ListDeviceLogs(c.Request.Context(), req)
+	if err != nil {
+		handleFraudError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get device fingerprint logs successfully", result)
+}
+
+// AdminListSharedDevices - GET /admin/fraud/shared-devices?from=&to=&min_sessions=&limit=
+func (h *Handler) AdminListSharedDevices(c *gin.Context) {
+	var req model.SharedDeviceQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.ListSharedDevices(c.Request.Context(), req)
+	if err != nil {
+		handleFraudError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get shared devices successfully", result)
+}
+
+func handleFraudError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidDateRange), errors.Is(err, model.ErrUnknownAction),
+		errors.Is(err, model.ErrInvalidUserID):
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "Failed to load fraud review data", err.Error())
+	}
+}
@@ -0,0 +1,34 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/fraud/model"
+	"bookstore-backend/internal/domains/fraud/service"
+	"bookstore-backend/internal/shared/utils"
+)
+
+// LogDeviceHandler ghi fingerprint log từ AntiBot middleware vào device_fingerprint_logs
+type LogDeviceHandler struct {
+	service service.ServiceInterface
+}
+
+func NewLogDeviceHandler(service service.ServiceInterface) *LogDeviceHandler {
+	return &LogDeviceHandler{service: service}
+}
+
+func (h *LogDeviceHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.LogDevicePayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	if err := h.service.RecordDevice(ctx, payload.Event); err != nil {
+		return fmt.Errorf("record device fingerprint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// High-risk actions được bảo vệ bởi AntiBot middleware
+const (
+	ActionAddToCart     = "add_to_cart"
+	ActionApplyPromo    = "apply_promo"
+	ActionValidatePromo = "validate_promo"
+	ActionTrackOrder    = "track_order" // tra cứu đơn public (mã đơn + 4 số cuối SĐT)
+)
+
+// Admin review
+const (
+	DefaultLogLimit = 50
+	MaxLogLimit     = 200
+	// DefaultReviewWindow - mặc định xem 7 ngày gần nhất
+	DefaultReviewWindow = 7 * 24 * time.Hour
+	// DefaultMinSessions - fingerprint xuất hiện ở >= N session/user thì đáng nghi
+	DefaultMinSessions = 3
+)
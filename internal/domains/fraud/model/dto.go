@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceLogQuery - GET /admin/fraud/device-logs
+type DeviceLogQuery struct {
+	Fingerprint string `form:"fingerprint"`
+	IPAddress   string `form:"ip"`
+	UserID      string `form:"user_id"`
+	Action      string `form:"action"`
+	From        string `form:"from"` // RFC3339, default: 7 ngày trước
+	To          string `form:"to"`   // RFC3339, default: bây giờ
+	Page        int    `form:"page"`
+	Limit       int    `form:"limit"`
+}
+
+// SharedDeviceQuery - GET /admin/fraud/shared-devices
+type SharedDeviceQuery struct {
+	From        string `form:"from"`
+	To          string `form:"to"`
+	MinSessions int    `form:"min_sessions"`
+	Limit       int    `form:"limit"`
+}
+
+// DeviceLogListResponse - danh sách log có phân trang
+type DeviceLogListResponse struct {
+	Logs  []DeviceFingerprintLog `json:"logs"`
+	Total int                    `json:"total"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+}
+
+// SharedDeviceListResponse - fingerprint nghi vấn trong khoảng thời gian
+type SharedDeviceListResponse struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Devices []SharedDevice `json:"devices"`
+}
+
+// DeviceLogFilter - điều kiện lọc đã parse (service → repository)
+type DeviceLogFilter struct {
+	Fingerprint string
+	IPAddress   string
+	UserID      *uuid.UUID
+	Action      string
+	From        time.Time
+	To          time.Time
+	Offset      int
+	Limit       int
+}
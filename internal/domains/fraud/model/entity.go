@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceFingerprintLog - 1 request của action rủi ro cao kèm fingerprint thiết bị
+type DeviceFingerprintLog struct {
+	ID                uuid.UUID  `json:"id"`
+	Action            string     `json:"action"`
+	Fingerprint       string     `json:"fingerprint"`
+	FingerprintSource string     `json:"fingerprint_source"`
+	SessionID         *string    `json:"session_id,omitempty"`
+	UserID            *uuid.UUID `json:"user_id,omitempty"`
+	IPAddress         string     `json:"ip_address"`
+	UserAgent         string     `json:"user_agent"`
+	AcceptLanguage    string     `json:"accept_language"`
+	Path              string     `json:"path"`
+	RequestCount      int        `json:"request_count"`
+	CaptchaRequired   bool       `json:"captcha_required"`
+	CaptchaPassed     *bool      `json:"captcha_passed,omitempty"`
+	OccurredAt        time.Time  `json:"occurred_at"`
+}
+
+// SharedDevice - 1 fingerprint dùng chung bởi nhiều session / tài khoản / IP
+type SharedDevice struct {
+	Fingerprint     string    `json:"fingerprint"`
+	SessionCount    int       `json:"session_count"`
+	UserCount       int       `json:"user_count"`
+	IPCount         int       `json:"ip_count"`
+	RequestCount    int       `json:"request_count"`
+	CaptchaFailures int       `json:"captcha_failures"`
+	FirstSeenAt     time.Time `json:"first_seen_at"`
+	LastSeenAt      time.Time `json:"last_seen_at"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "errors"
+
+var (
+	ErrUnknownAction      = errors.New("unknown fraud action")
+	ErrMissingFingerprint = errors.New("device fingerprint is required")
+	ErrInvalidDateRange   = errors.New("invalid date range")
+	ErrInvalidUserID      = errors.New("invalid user_id")
+)
+
+// IsValidAction kiểm tra action có được hỗ trợ
+func IsValidAction(action string) bool {
+	switch action {
+	case ActionAddToCart, ActionApplyPromo, ActionValidatePromo, ActionTrackOrder:
+		return true
+	}
+	return false
+}
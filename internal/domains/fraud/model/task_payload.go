@@ -0,0 +1,8 @@
+package model
+
+import "bookstore-backend/internal/shared"
+
+// LogDevicePayload - ghi 1 fingerprint log async (không làm chậm request)
+type LogDevicePayload struct {
+	Event shared.DeviceFingerprintEvent `json:"event"`
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"bookstore-backend/internal/domains/fraud/model"
+)
+
+// Repository - device fingerprint logs cho fraud review
+type Repository interface {
+	// InsertDeviceLog ghi 1 log (worker)
+	InsertDeviceLog(ctx context.Context, log *model.DeviceFingerprintLog) error
+
+	// ListDeviceLogs - admin tra cứu theo fingerprint / IP / user, mới nhất trước
+	ListDeviceLogs(ctx context.Context, filter model.DeviceLogFilter) ([]model.DeviceFingerprintLog, int, error)
+
+	// ListSharedDevices - fingerprint xuất hiện ở >= minSessions session/user trong [from, to]
+	ListSharedDevices(ctx context.Context, from, to time.Time, minSessions, limit int) ([]model.SharedDevice, error)
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/fraud/model"
+)
+
+type postgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) Repository {
+	return &postgresRepository{pool: pool}
+}
+
+func (r *postgresRepository) InsertDeviceLog(ctx context.Context, log *model.DeviceFingerprintLog) error {
+	if log.ID == uuid.Nil {
+		log.ID = uuid.New()
+	}
+	if log.OccurredAt.IsZero() {
+		log.OccurredAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO device_fingerprint_logs (
+			id, action, fingerprint, fingerprint_source, session_id, user_id,
+			ip_address, user_agent, accept_language, path,
+			request_count, captcha_required, captcha_passed, occurred_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		log.ID, log.Action, log.Fingerprint, log.FingerprintSource, log.SessionID, log.UserID,
+		log.IPAddress, log.UserAgent, log.AcceptLanguage, log.Path,
+		log.RequestCount, log.CaptchaRequired, log.CaptchaPassed, log.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert device fingerprint log: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresRepository) ListDeviceLogs(ctx context.Context, filter model.DeviceLogFilter) ([]model.DeviceFingerprintLog, int, error) {
+	where := `
+		WHERE occurred_at >= $1 AND occurred_at <= $2
+		  AND ($3 = '' OR fingerprint = $3)
+		  AND ($4 = '' OR ip_address = $4)
+		  AND ($5::uuid IS NULL OR user_id = $5)
+		  AND ($6 = '' OR action = $6)
+	`
+	args := []interface{}{filter.From, filter.To, filter.Fingerprint, filter.IPAddress, filter.UserID, filter.Action}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM device_fingerprint_logs `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count device fingerprint logs: %w", err)
+	}
+
+	query := `
+		SELECT id, action, fingerprint, fingerprint_source, session_id, user_id,
+			ip_address, user_agent, accept_language, path,
+			request_count, captcha_required, captcha_passed, occurred_at
+		FROM device_fingerprint_logs
+	` + where + `
+		ORDER BY occurred_at DESC
+		LIMIT $7 OFFSET $8
+	`
+
+	rows, err := r.pool.Query(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list device fingerprint logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]model.DeviceFingerprintLog, 0)
+	for rows.Next() {
+		var l model.DeviceFingerprintLog
+		if err := rows.Scan(
+			&l.ID, &l.Action, &l.Fingerprint, &l.FingerprintSource, &l.SessionID, &l.UserID,
+			&l.IPAddress, &l.UserAgent, &l.AcceptLanguage, &l.Path,
+			&l.RequestCount, &l.CaptchaRequired, &l.CaptchaPassed, &l.OccurredAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan device fingerprint log: %w", err)
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, total, rows.Err()
+}
+
+func (r *postgresRepository) ListSharedDevices(ctx context.Context, from, to time.Time, minSessions, limit int) ([]model.SharedDevice, error) {
+	// "Session" = session ẩn danh hoặc tài khoản (user đăng nhập không nhất thiết có session cookie)
+	// Chỉ xét fingerprint client gửi: fingerprint derived (UA + ngôn ngữ) trùng nhau giữa hàng nghìn khách thật
+	query := `
+		SELECT fingerprint,
+			COUNT(DISTINCT session_id) AS session_count,
+			COUNT(DISTINCT user_id) AS user_count,
+			COUNT(DISTINCT ip_address) AS ip_count,
+			COUNT(*) AS request_count,
+			COUNT(*) FILTER (WHERE captcha_passed = FALSE) AS captcha_failures,
+			MIN(occurred_at) AS first_seen_at,
+			MAX(occurred_at) AS last_seen_at
+		FROM device_fingerprint_logs
+		WHERE occurred_at >= $1 AND occurred_at <= $2
+		  AND fingerprint_source = 'client'
+		GROUP BY fingerprint
+		HAVING COUNT(DISTINCT COALESCE(user_id::text, session_id)) >= $3
+		ORDER BY COUNT(DISTINCT COALESCE(user_id::text, session_id)) DESC, request_count DESC
+		LIMIT $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, from, to, minSessions, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list shared devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := make([]model.SharedDevice, 0)
+	for rows.Next() {
+		var d model.SharedDevice
+		if err := rows.Scan(
+			&d.Fingerprint, &d.SessionCount, &d.UserCount, &d.IPCount,
+			&d.RequestCount, &d.CaptchaFailures, &d.FirstSeenAt, &d.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan shared device: %w", err)
+		}
+		devices = append(devices, d)
+	}
+
+	return devices, rows.Err()
+}
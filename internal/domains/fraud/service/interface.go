@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"bookstore-backend/internal/domains/fraud/model"
+	"bookstore-backend/internal/shared"
+)
+
+type ServiceInterface interface {
+	// LogDevice enqueue fingerprint log sang worker (AntiBot middleware, request path)
+	LogDevice(ctx context.Context, event shared.DeviceFingerprintEvent) error
+
+	// RecordDevice ghi fingerprint log trực tiếp vào DB (worker)
+	RecordDevice(ctx context.Context, event shared.DeviceFingerprintEvent) error
+
+	// Admin fraud review
+	ListDeviceLogs(ctx context.Context, query model.DeviceLogQuery) (*model.DeviceLogListResponse, error)
+	ListSharedDevices(ctx context.Context, query model.SharedDeviceQuery) (*model.SharedDeviceListResponse, error)
+}
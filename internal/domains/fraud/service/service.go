@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/fraud/model"
+	"bookstore-backend/internal/domains/fraud/repository"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+)
+
+type service struct {
+	repo        repository.Repository
+	asynqClient *asynq.Client
+}
+
+func NewService(repo repository.Repository, asynqClient *asynq.Client) ServiceInterface {
+	return &service{
+		repo:        repo,
+		asynqClient: asynqClient,
+	}
+}
+
+func validateDeviceEvent(event shared.DeviceFingerprintEvent) error {
+	if !model.IsValidAction(event.Action) {
+		return model.ErrUnknownAction
+	}
+	if event.Fingerprint == "" {
+		return model.ErrMissingFingerprint
+	}
+	return nil
+}
+
+func (s *service) LogDevice(ctx context.Context, event shared.DeviceFingerprintEvent) error {
+	if err := validateDeviceEvent(event); err != nil {
+		return err
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeLogDeviceFingerprint, model.LogDevicePayload{Event: event})
+	if err != nil {
+		return fmt.Errorf("marshal log device task: %w", err)
+	}
+
+	_, err = s.asynqClient.EnqueueContext(ctx, task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(1),
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue log device task: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) RecordDevice(ctx context.Context, event shared.DeviceFingerprintEvent) error {
+	if err := validateDeviceEvent(event); err != nil {
+		return err
+	}
+
+	log := &model.DeviceFingerprintLog{
+		Action:            event.Action,
+		Fingerprint:       event.Fingerprint,
+		FingerprintSource: event.FingerprintSource,
+		IPAddress:         event.IPAddress,
+		UserAgent:         event.UserAgent,
+		AcceptLanguage:    event.AcceptLanguage,
+		Path:              event.Path,
+		RequestCount:      int(event.RequestCount),
+		CaptchaRequired:   event.CaptchaRequired,
+		CaptchaPassed:     event.CaptchaPassed,
+		OccurredAt:        event.OccurredAt,
+	}
+	if event.SessionID != "" {
+		log.SessionID = &event.SessionID
+	}
+	if event.UserID != "" {
+		userID, err := uuid.Parse(event.UserID)
+		if err != nil {
+			return fmt.Errorf("parse user id: %w", err)
+		}
+		log.UserID = &userID
+	}
+
+	return s.repo.InsertDeviceLog(ctx, log)
+}
+
+func (s *service) ListDeviceLogs(ctx context.Context, query model.DeviceLogQuery) (*model.DeviceLogListResponse, error) {
+	from, to, err := parseReviewRange(query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Action != "" && !model.IsValidAction(query.Action) {
+		return nil, model.ErrUnknownAction
+	}
+
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = model.DefaultLogLimit
+	}
+	if limit > model.MaxLogLimit {
+		limit = model.MaxLogLimit
+	}
+
+	filter := model.DeviceLogFilter{
+		Fingerprint: query.Fingerprint,
+		IPAddress:   query.IPAddress,
+		Action:      query.Action,
+		From:        from,
+		To:          to,
+		Offset:      (page - 1) * limit,
+		Limit:       limit,
+	}
+	if query.UserID != "" {
+		userID, err := uuid.Parse(query.UserID)
+		if err != nil {
+			return nil, model.ErrInvalidUserID
+		}
+		filter.UserID = &userID
+	}
+
+	logs, total, err := s.repo.ListDeviceLogs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeviceLogListResponse{
+		Logs:  logs,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+func (s *service) ListSharedDevices(ctx context.Context, query model.SharedDeviceQuery) (*model.SharedDeviceListResponse, error) {
+	from, to, err := parseReviewRange(query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	minSessions := query.MinSessions
+	if minSessions < 2 {
+		minSessions = model.DefaultMinSessions
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = model.DefaultLogLimit
+	}
+	if limit > model.MaxLogLimit {
+		limit = model.MaxLogLimit
+	}
+
+	devices, err := s.repo.ListSharedDevices(ctx, from, to, minSessions, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SharedDeviceListResponse{
+		From:    from.Format(time.RFC3339),
+		To:      to.Format(time.RFC3339),
+		Devices: devices,
+	}, nil
+}
+
+// parseReviewRange - from/to dạng RFC3339, mặc định DefaultReviewWindow gần nhất
+func parseReviewRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	to := time.Now()
+	if toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, model.ErrInvalidDateRange
+		}
+		to = parsed
+	}
+
+	from := to.Add(-model.DefaultReviewWindow)
+	if fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, model.ErrInvalidDateRange
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, model.ErrInvalidDateRange
+	}
+
+	return from, to, nil
+}
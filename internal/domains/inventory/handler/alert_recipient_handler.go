@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ========================================
+// LOW STOCK ALERT RECIPIENT HANDLERS
+// ========================================
+
+// CreateAlertRecipient handles POST /api/v1/inventories/alerts/recipients
+// @Summary Register low stock alert recipient (admin only)
+// @Description Email or Slack incoming webhook receiving low stock alerts of one warehouse (or every warehouse when warehouse_id is omitted), immediately or as an hourly digest
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param request body model.CreateAlertRecipientRequest true "Create Alert Recipient Request"
+// @Success 201 {object} response.SuccessResponse{data=model.AlertRecipient}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse "Warehouse not found"
+// @Failure 409 {object} response.ErrorResponse "Recipient already registered"
+// @Router /api/v1/inventories/alerts/recipients [post]
+func (h *Handler) CreateAlertRecipient(c *gin.Context) {
+	var req model.CreateAlertRecipientRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+	req.CreatedBy = userID
+
+	result, err := h.service.CreateAlertRecipient(c.Request.Context(), req)
+	if err != nil {
+		h.handleAlertRecipientError(c, err, "Failed to create alert recipient")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Alert recipient created", result)
+}
+
+// ListAlertRecipients handles GET /api/v1/inventories/alerts/recipients
+// @Summary List low stock alert recipients (admin only)
+// @Tags Alerts
+// @Produce json
+// @Param warehouse_id query string false "Warehouse ID (also returns all-warehouse recipients)"
+// @Param channel query string false "email, slack"
+// @Param mode query string false "immediate, digest"
+// @Success 200 {object} response.SuccessResponse{data=[]model.AlertRecipient}
+// @Router /api/v1/inventories/alerts/recipients [get]
+func (h *Handler) ListAlertRecipients(c *gin.Context) {
+	var req model.ListAlertRecipientsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.ListAlertRecipients(c.Request.Context(), req)
+	if err != nil {
+		h.handleAlertRecipientError(c, err, "Failed to list alert recipients")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Alert recipients retrieved", result)
+}
+
+// UpdateAlertRecipient handles PATCH /api/v1/inventories/alerts/recipients/:id
+// @Summary Update low stock alert recipient (admin only)
+// @Description Changes target, mode or is_active; warehouse and channel are fixed (delete and re-create instead)
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipient ID"
+// @Param request body model.UpdateAlertRecipientRequest true "Update Alert Recipient Request"
+// @Success 200 {object} response.SuccessResponse{data=model.AlertRecipient}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /api/v1/inventories/alerts/recipients/{id} [patch]
+func (h *Handler) UpdateAlertRecipient(c *gin.Context) {
+	id, ok := parseAlertRecipientID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateAlertRecipientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	result, err := h.service.UpdateAlertRecipient(c.Request.Context(), id, req)
+	if err != nil {
+		h.handleAlertRecipientError(c, err, "Failed to update alert recipient")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Alert recipient updated", result)
+}
+
+// DeleteAlertRecipient handles DELETE /api/v1/inventories/alerts/recipients/:id
+// @Summary Delete low stock alert recipient (admin only)
+// @Tags Alerts
+// @Param id path string true "Recipient ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/inventories/alerts/recipients/{id} [delete]
+func (h *Handler) DeleteAlertRecipient(c *gin.Context) {
+	id, ok := parseAlertRecipientID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAlertRecipient(c.Request.Context(), id); err != nil {
+		h.handleAlertRecipientError(c, err, "Failed to delete alert recipient")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func parseAlertRecipientID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid alert recipient ID", err.Error())
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *Handler) handleAlertRecipientError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrInvalidAlertRecipient):
+		response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+	case errors.Is(err, model.ErrAlertRecipientNotFound):
+		response.Error(c, http.StatusNotFound, "Alert recipient not found", err.Error())
+	case errors.Is(err, model.ErrWarehouseNotFound):
+		response.Error(c, http.StatusNotFound, "Warehouse not found", err.Error())
+	case errors.Is(err, model.ErrAlertRecipientExists):
+		response.Error(c, http.StatusConflict, "Alert recipient already registered", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
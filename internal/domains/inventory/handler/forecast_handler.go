@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ========================================
+// DEMAND FORECAST HANDLERS
+// ========================================
+// Staff kho chỉ thấy dự báo / gợi ý nhập hàng của kho được gán
+
+// GetDemandForecast handles GET /api/v1/inventories/forecast
+// @Summary Get demand forecast of a book
+// @Description Predicted weekly demand for the next N weeks per warehouse with 95% confidence intervals (recomputed weekly)
+// @Tags Demand Forecast
+// @Produce json
+// @Param book_id query string true "Book ID"
+// @Param warehouse_id query string false "Warehouse ID"
+// @Param weeks query int false "Number of weeks (1-12)" default(4)
+// @Success 200 {object} response.SuccessResponse{data=model.DemandForecastResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/v1/inventories/forecast [get]
+func (h *Handler) GetDemandForecast(c *gin.Context) {
+	var req model.DemandForecastRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		if req.WarehouseID != nil && !middleware.CanAccessWarehouse(c, *req.WarehouseID) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+			return
+		}
+		req.ScopeWarehouseIDs = scope
+	}
+
+	result, err := h.service.GetDemandForecast(c.Request.Context(), req)
+	if err != nil {
+		if model.IsValidationError(err) {
+			response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get demand forecast", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Demand forecast retrieved", result)
+}
+
+// GetReplenishmentSuggestions handles GET /api/v1/inventories/replenishment/suggestions
+// @Summary Get replenishment suggestions
+// @Description Restock quantities so available + in-transit stock covers the upper bound of forecast demand for the next N weeks plus safety stock
+// @Tags Demand Forecast
+// @Produce json
+// @Param warehouse_id query string false "Warehouse ID"
+// @Param weeks query int false "Number of weeks (1-12)" default(4)
+// @Success 200 {object} response.SuccessResponse{data=model.ReplenishmentResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/v1/inventories/replenishment/suggestions [get]
+func (h *Handler) GetReplenishmentSuggestions(c *gin.Context) {
+	var req model.ReplenishmentRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		if req.WarehouseID != nil && !middleware.CanAccessWarehouse(c, *req.WarehouseID) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+			return
+		}
+		req.ScopeWarehouseIDs = scope
+	}
+
+	result, err := h.service.GetReplenishmentSuggestions(c.Request.Context(), req)
+	if err != nil {
+		if model.IsValidationError(err) {
+			response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get replenishment suggestions", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Replenishment suggestions retrieved", result)
+}
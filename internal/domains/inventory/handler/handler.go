@@ -4,10 +4,18 @@ package handler
 import (
 	"bookstore-backend/internal/domains/inventory/model"
 	"bookstore-backend/internal/domains/inventory/service"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/pagination"
 	"bookstore-backend/internal/shared/response"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,15 +23,20 @@ import (
 
 type Handler struct {
 	service service.ServiceInterface
+	events  *service.EventStream
 }
 
 // NewHandler creates a new inventory handler
-func NewHandler(service service.ServiceInterface) *Handler {
+func NewHandler(service service.ServiceInterface, events *service.EventStream) *Handler {
 	return &Handler{
 		service: service,
+		events:  events,
 	}
 }
 
+// streamHeartbeatInterval - comment ping giữ kết nối SSE qua proxy/load balancer idle timeout
+const streamHeartbeatInterval = 15 * time.Second
+
 // ========================================
 // INVENTORY CRUD HANDLERS
 // ========================================
@@ -47,6 +60,12 @@ func (h *Handler) CreateInventory(c *gin.Context) {
 		return
 	}
 
+	// Staff không được tạo cho mọi kho (warehouse_id rỗng)
+	if !canAccessOptionalWarehouse(c, req.WarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	inventories, err := h.service.CreateInventory(c.Request.Context(), req)
 	if err != nil {
 		switch {
@@ -91,6 +110,11 @@ func (h *Handler) GetInventoryByWarehouseAndBook(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessWarehouse(c, warehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	res, err := h.service.GetInventoryByWarehouseAndBook(c.Request.Context(), warehouseID, bookID)
 	if err != nil {
 		if model.IsNotFoundError(err) {
@@ -109,8 +133,10 @@ func (h *Handler) GetInventoryByWarehouseAndBook(c *gin.Context) {
 // @Description Retrieves paginated list of inventories
 // @Tags Inventory
 // @Produce json
-// @Param page query int true "Page number (min: 1)" default(1)
-// @Param limit query int true "Items per page (1-100)" default(20)
+// @Param page query int false "Page number (min: 1)" default(1)
+// @Param limit query int false "Items per page (1-100)" default(20)
+// @Param pagination query string false "cursor: keyset pagination (first page), page is ignored"
+// @Param cursor query string false "next_cursor of the previous page (implies pagination=cursor)"
 // @Param book_id query string false "Filter by Book ID (UUID)"
 // @Param warehouse_id query string false "Filter by Warehouse ID (UUID)"
 // @Param is_low_stock query bool false "Filter by low stock status"
@@ -126,8 +152,17 @@ func (h *Handler) ListInventories(c *gin.Context) {
 		return
 	}
 
+	// Staff chỉ thấy inventory của kho được gán
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		req.ScopeWarehouseIDs = scope
+	}
+
 	result, err := h.service.ListInventories(c.Request.Context(), req)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to list inventories", err.Error())
 		return
 	}
@@ -162,6 +197,11 @@ func (h *Handler) UpdateInventory(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessWarehouse(c, warehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	var req model.UpdateInventoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
@@ -210,6 +250,11 @@ func (h *Handler) DeleteInventory(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessWarehouse(c, warehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	err = h.service.DeleteInventory(c.Request.Context(), warehouseID, bookID)
 	if err != nil {
 		switch {
@@ -250,6 +295,12 @@ func (h *Handler) ReserveStock(c *gin.Context) {
 		return
 	}
 
+	// Staff phải chỉ định kho của mình (không auto-chọn kho gần nhất ngoài phạm vi)
+	if !canAccessOptionalWarehouse(c, req.WarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	result, err := h.service.ReserveStock(c.Request.Context(), req)
 	if err != nil {
 		switch {
@@ -287,6 +338,11 @@ func (h *Handler) ReleaseStock(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessWarehouse(c, req.WarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	result, err := h.service.ReleaseStock(c.Request.Context(), req)
 	if err != nil {
 		switch {
@@ -322,6 +378,11 @@ func (h *Handler) CompleteSale(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessWarehouse(c, req.WarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	result, err := h.service.CompleteSale(c.Request.Context(), req)
 	if err != nil {
 		switch {
@@ -450,8 +511,14 @@ func (h *Handler) AdjustStock(c *gin.Context) {
 		return
 	}
 
-	// TODO: Get changed_by from JWT claims
-	// req.ChangedBy = c.GetString("user_id")
+	if !middleware.CanAccessWarehouse(c, req.WarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
+	if userID, ok := currentUserID(c); ok {
+		req.ChangedBy = userID
+	}
 
 	result, err := h.service.AdjustStock(c.Request.Context(), req)
 	if err != nil {
@@ -462,18 +529,144 @@ func (h *Handler) AdjustStock(c *gin.Context) {
 			response.Error(c, http.StatusConflict, "Version conflict", err.Error())
 		case model.IsValidationError(err):
 			response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+		case model.IsAdjustmentApprovalError(err):
+			response.Error(c, http.StatusConflict, "Adjustment approval conflict", err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "Failed to adjust stock", err.Error())
 		}
 		return
 	}
 
+	if result.PendingApproval {
+		response.Success(c, http.StatusAccepted, "Adjustment pending approval", result)
+		return
+	}
+
 	response.Success(c, http.StatusOK, "Stock adjusted successfully", result)
 }
 
+// ListAdjustmentRequests handles GET /api/v1/inventories/adjustments
+// @Summary List adjustment requests (admin only)
+// @Tags Stock Adjustment
+// @Produce json
+// @Param status query string false "pending (default), approved, rejected"
+// @Success 200 {object} response.SuccessResponse{data=model.ListAdjustmentRequestsResponse}
+// @Router /api/v1/inventories/adjustments [get]
+func (h *Handler) ListAdjustmentRequests(c *gin.Context) {
+	var req model.ListAdjustmentRequestsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.ListAdjustmentRequests(c.Request.Context(), req)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list adjustment requests", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Adjustment requests retrieved", result)
+}
+
+// ApproveAdjustment handles POST /api/v1/inventories/adjustments/:id/approve
+// @Summary Approve pending adjustment (second admin)
+// @Tags Stock Adjustment
+// @Accept json
+// @Produce json
+// @Param id path string true "Adjustment Request ID"
+// @Param request body model.ReviewAdjustmentRequest false "Review note"
+// @Success 200 {object} response.SuccessResponse{data=model.AdjustStockResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Already reviewed / self approval / version conflict"
+// @Router /api/v1/inventories/adjustments/{id}/approve [post]
+func (h *Handler) ApproveAdjustment(c *gin.Context) {
+	requestID, req, ok := h.bindReviewAdjustment(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ApproveAdjustment(c.Request.Context(), requestID, req)
+	if err != nil {
+		h.handleReviewAdjustmentError(c, err, "Failed to approve adjustment")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Adjustment approved", result)
+}
+
+// RejectAdjustment handles POST /api/v1/inventories/adjustments/:id/reject
+// @Summary Reject pending adjustment (second admin)
+// @Tags Stock Adjustment
+// @Accept json
+// @Produce json
+// @Param id path string true "Adjustment Request ID"
+// @Param request body model.ReviewAdjustmentRequest false "Review note"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Already reviewed / self approval"
+// @Router /api/v1/inventories/adjustments/{id}/reject [post]
+func (h *Handler) RejectAdjustment(c *gin.Context) {
+	requestID, req, ok := h.bindReviewAdjustment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RejectAdjustment(c.Request.Context(), requestID, req); err != nil {
+		h.handleReviewAdjustmentError(c, err, "Failed to reject adjustment")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Adjustment rejected", nil)
+}
+
+func (h *Handler) bindReviewAdjustment(c *gin.Context) (uuid.UUID, model.ReviewAdjustmentRequest, bool) {
+	var req model.ReviewAdjustmentRequest
+
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid adjustment request ID", err.Error())
+		return uuid.Nil, req, false
+	}
+
+	// Body optional (chỉ chứa note)
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+			return uuid.Nil, req, false
+		}
+	}
+
+	reviewerID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return uuid.Nil, req, false
+	}
+	req.ReviewedBy = reviewerID
+
+	return requestID, req, true
+}
+
+func (h *Handler) handleReviewAdjustmentError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrAdjustmentRequestNotFound):
+		response.Error(c, http.StatusNotFound, "Adjustment request not found", err.Error())
+	case errors.Is(err, model.ErrAdjustmentSelfApproval):
+		response.Error(c, http.StatusForbidden, "Self approval not allowed", err.Error())
+	case model.IsAdjustmentApprovalError(err):
+		response.Error(c, http.StatusConflict, "Adjustment already reviewed", err.Error())
+	case model.IsOptimisticLockError(err):
+		response.Error(c, http.StatusConflict, "Inventory changed since request was created", err.Error())
+	case model.IsNotFoundError(err):
+		response.Error(c, http.StatusNotFound, "Inventory not found", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
+
 // RestockInventory handles POST /api/v1/inventories/restock
 // @Summary Restock inventory
-// @Description Adds stock from supplier with reason
+// @Description Adds stock from supplier with reason, optionally registering one serial number per copy (+ lot) for limited / signed editions
 // @Tags Stock Adjustment
 // @Accept json
 // @Produce json
@@ -490,11 +683,26 @@ func (h *Handler) RestockInventory(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessWarehouse(c, req.WarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
+	if userID, ok := currentUserID(c); ok {
+		req.UpdatedBy = &userID
+	}
+
 	result, err := h.service.RestockInventory(c.Request.Context(), req)
 	if err != nil {
 		switch {
 		case model.IsNotFoundError(err):
 			response.Error(c, http.StatusNotFound, "Inventory not found", err.Error())
+		case model.IsValidationError(err):
+			response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+		case model.IsOptimisticLockError(err):
+			response.Error(c, http.StatusConflict, "Inventory changed, please retry", err.Error())
+		case errors.Is(err, model.ErrSerialExists):
+			response.Error(c, http.StatusConflict, "Serial number already registered", err.Error())
 		default:
 			response.Error(c, http.StatusInternalServerError, "Failed to restock", err.Error())
 		}
@@ -534,8 +742,22 @@ func (h *Handler) BulkUpdateStock(c *gin.Context) {
 		return
 	}
 
-	// TODO: Get user ID from JWT claims
-	uploadedBy := uuid.New()
+	// Staff: mọi dòng CSV phải thuộc kho được gán
+	if row, err := checkBulkUpdateScope(c, csvPath); err != nil {
+		os.Remove(csvPath)
+		if errors.Is(err, model.ErrWarehouseAccessDenied) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", fmt.Sprintf("row %d: %s", row, err.Error()))
+			return
+		}
+		response.Error(c, http.StatusBadRequest, "Invalid CSV file", err.Error())
+		return
+	}
+
+	uploadedBy, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "User ID required")
+		return
+	}
 
 	result, err := h.service.BulkUpdateStock(c.Request.Context(), csvPath, uploadedBy)
 	if err != nil {
@@ -546,6 +768,65 @@ func (h *Handler) BulkUpdateStock(c *gin.Context) {
 	response.Success(c, http.StatusAccepted, "Bulk update job created", result)
 }
 
+// canAccessOptionalWarehouse warehouse_id không bắt buộc (nil = mọi kho / tự chọn kho): chỉ admin được để trống
+func canAccessOptionalWarehouse(c *gin.Context, warehouseID *uuid.UUID) bool {
+	if warehouseID == nil {
+		_, restricted := middleware.GetWarehouseScope(c)
+		return !restricted
+	}
+	return middleware.CanAccessWarehouse(c, *warehouseID)
+}
+
+// checkBulkUpdateScope kiểm tra cột warehouse_id của từng dòng CSV với phạm vi kho của caller,
+// trả số dòng (tính cả header) bị từ chối. Admin không bị giới hạn → không đọc file.
+func checkBulkUpdateScope(c *gin.Context, csvPath string) (int, error) {
+	if _, restricted := middleware.GetWarehouseScope(c); !restricted {
+		return 0, nil
+	}
+
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return 1, fmt.Errorf("read header: %w", err)
+	}
+	column := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), "warehouse_id") {
+			column = i
+			break
+		}
+	}
+	if column < 0 {
+		return 1, errors.New("missing warehouse_id column")
+	}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return row, fmt.Errorf("row %d: %w", row, err)
+		}
+		if column >= len(record) {
+			return row, fmt.Errorf("row %d: missing warehouse_id", row)
+		}
+		warehouseID, err := uuid.Parse(strings.TrimSpace(record[column]))
+		if err != nil {
+			return row, fmt.Errorf("row %d: invalid warehouse_id: %w", row, err)
+		}
+		if !middleware.CanAccessWarehouse(c, warehouseID) {
+			return row, model.ErrWarehouseAccessDenied
+		}
+	}
+}
+
 // GetBulkUpdateStatus handles GET /api/v1/inventories/bulk-update/:job_id
 // @Summary Get bulk update job status
 // @Description Checks processing status of CSV import job
@@ -576,6 +857,75 @@ func (h *Handler) GetBulkUpdateStatus(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Job status retrieved", result)
 }
 
+// ========================================
+// EVENT STREAM (SSE)
+// ========================================
+
+// StreamEvents handles GET /api/v1/inventories/events/stream
+// @Summary Stream inventory changes
+// @Description Server-Sent Events: restock, reserve, release, sale, adjustment, low stock alert. Filter by warehouse_id (repeatable); staff chỉ nhận event của kho được gán
+// @Tags Inventory
+// @Produce text/event-stream
+// @Param warehouse_id query []string false "Warehouse IDs (UUID)"
+// @Success 200 {object} model.InventoryEvent
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/v1/inventories/events/stream [get]
+func (h *Handler) StreamEvents(c *gin.Context) {
+	var warehouseIDs []uuid.UUID // nil = mọi kho
+	for _, raw := range c.QueryArray("warehouse_id") {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid warehouse ID format", err.Error())
+			return
+		}
+		if !middleware.CanAccessWarehouse(c, id) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+			return
+		}
+		warehouseIDs = append(warehouseIDs, id)
+	}
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted && warehouseIDs == nil {
+		warehouseIDs = append([]uuid.UUID{}, scope...)
+	}
+
+	// Stream sống lâu hơn WriteTimeout của http.Server → bỏ write deadline cho riêng request này
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Streaming not supported", err.Error())
+		return
+	}
+
+	events, unsubscribe := h.events.Subscribe(warehouseIDs)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx: không buffer SSE
+	c.Status(http.StatusOK)
+	_, _ = io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("inventory", event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
 // ========================================
 // ALERTS HANDLERS (FR-INV-004)
 // ========================================
@@ -671,6 +1021,14 @@ func (h *Handler) GetAuditTrail(c *gin.Context) {
 		return
 	}
 
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		if req.WarehouseID != nil && !middleware.CanAccessWarehouse(c, *req.WarehouseID) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+			return
+		}
+		req.ScopeWarehouseIDs = scope
+	}
+
 	result, err := h.service.GetAuditTrail(c.Request.Context(), req)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Failed to get audit trail", err.Error())
@@ -680,6 +1038,47 @@ func (h *Handler) GetAuditTrail(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Audit trail retrieved", result)
 }
 
+// GetWriteOffReport handles GET /api/v1/inventories/reports/write-offs
+// @Summary Write-off report
+// @Description Damaged / lost / expired / sample adjustments per warehouse per category, valued at cost price
+// @Tags Audit
+// @Produce json
+// @Param warehouse_id query string false "Filter by Warehouse ID"
+// @Param from_date query string false "From date (YYYY-MM-DD), default 30 days ago"
+// @Param to_date query string false "To date (YYYY-MM-DD), default now"
+// @Success 200 {object} response.SuccessResponse{data=model.WriteOffReportResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/v1/inventories/reports/write-offs [get]
+func (h *Handler) GetWriteOffReport(c *gin.Context) {
+	var req model.WriteOffReportRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		if req.WarehouseID != nil && !middleware.CanAccessWarehouse(c, *req.WarehouseID) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+			return
+		}
+		req.ScopeWarehouseIDs = scope
+	}
+
+	result, err := h.service.GetWriteOffReport(c.Request.Context(), req)
+	if err != nil {
+		if model.IsValidationError(err) {
+			response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get write-off report", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Write-off report retrieved", result)
+}
+
 // GetInventoryHistory handles GET /api/v1/inventories/:warehouse_id/:book_id/history
 // @Summary Get inventory history
 // @Description Full audit history for specific warehouse+book
@@ -705,6 +1104,11 @@ func (h *Handler) GetInventoryHistory(c *gin.Context) {
 		return
 	}
 
+	if !middleware.CanAccessWarehouse(c, warehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset := (page - 1) * limit
@@ -1019,3 +1423,17 @@ func (h *Handler) DeactivateWarehouse(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// ========================================
+// HELPERS
+// ========================================
+
+// currentUserID reads user_id set by AuthMiddleware
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	v, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, false
+	}
+	id, ok := v.(uuid.UUID)
+	return id, ok
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ========================================
+// SUPPLIER RETURN (RTV) HANDLERS
+// ========================================
+
+// CreateSupplierReturn handles POST /api/v1/inventories/rtv
+// @Summary Create supplier return (admin only)
+// @Description Returns damaged / excess stock of one warehouse to its supplier (publisher), stock is decremented immediately
+// @Tags Supplier Return
+// @Accept json
+// @Produce json
+// @Param request body model.CreateRTVRequest true "Create RTV Request"
+// @Success 201 {object} response.SuccessResponse{data=model.SupplierReturn}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Insufficient available stock"
+// @Router /api/v1/inventories/rtv [post]
+func (h *Handler) CreateSupplierReturn(c *gin.Context) {
+	var req model.CreateRTVRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+	req.CreatedBy = userID
+
+	result, err := h.service.CreateSupplierReturn(c.Request.Context(), req)
+	if err != nil {
+		h.handleRTVError(c, err, "Failed to create supplier return")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Supplier return created", result)
+}
+
+// ListSupplierReturns handles GET /api/v1/inventories/rtv
+// @Summary List supplier returns (admin only)
+// @Tags Supplier Return
+// @Produce json
+// @Param status query string false "open, partially_credited, credited, closed"
+// @Param supplier_id query string false "Supplier (publisher) ID"
+// @Param warehouse_id query string false "Warehouse ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=model.ListRTVResponse}
+// @Router /api/v1/inventories/rtv [get]
+func (h *Handler) ListSupplierReturns(c *gin.Context) {
+	var req model.ListRTVRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.ListSupplierReturns(c.Request.Context(), req)
+	if err != nil {
+		h.handleRTVError(c, err, "Failed to list supplier returns")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Supplier returns retrieved", result)
+}
+
+// GetSupplierReturn handles GET /api/v1/inventories/rtv/:id
+// @Summary Get supplier return with items and credit notes (admin only)
+// @Tags Supplier Return
+// @Produce json
+// @Param id path string true "RTV ID"
+// @Success 200 {object} response.SuccessResponse{data=model.SupplierReturn}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/inventories/rtv/{id} [get]
+func (h *Handler) GetSupplierReturn(c *gin.Context) {
+	returnID, ok := parseRTVID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.GetSupplierReturn(c.Request.Context(), returnID)
+	if err != nil {
+		h.handleRTVError(c, err, "Failed to get supplier return")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Supplier return retrieved", result)
+}
+
+// RecordCreditNote handles POST /api/v1/inventories/rtv/:id/credit-notes
+// @Summary Record supplier credit note (admin only)
+// @Tags Supplier Return
+// @Accept json
+// @Produce json
+// @Param id path string true "RTV ID"
+// @Param request body model.RecordCreditNoteRequest true "Credit note"
+// @Success 200 {object} response.SuccessResponse{data=model.SupplierReturn}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Return closed / credit note already recorded"
+// @Router /api/v1/inventories/rtv/{id}/credit-notes [post]
+func (h *Handler) RecordCreditNote(c *gin.Context) {
+	returnID, ok := parseRTVID(c)
+	if !ok {
+		return
+	}
+
+	var req model.RecordCreditNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+	req.RecordedBy = userID
+
+	result, err := h.service.RecordCreditNote(c.Request.Context(), returnID, req)
+	if err != nil {
+		h.handleRTVError(c, err, "Failed to record credit note")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Credit note recorded", result)
+}
+
+// CloseSupplierReturn handles POST /api/v1/inventories/rtv/:id/close
+// @Summary Close supplier return without full credit (admin only)
+// @Tags Supplier Return
+// @Accept json
+// @Produce json
+// @Param id path string true "RTV ID"
+// @Param request body model.CloseRTVRequest true "Close reason"
+// @Success 200 {object} response.SuccessResponse{data=model.SupplierReturn}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Already closed / fully credited"
+// @Router /api/v1/inventories/rtv/{id}/close [post]
+func (h *Handler) CloseSupplierReturn(c *gin.Context) {
+	returnID, ok := parseRTVID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CloseRTVRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+	req.ClosedBy = userID
+
+	result, err := h.service.CloseSupplierReturn(c.Request.Context(), returnID, req)
+	if err != nil {
+		h.handleRTVError(c, err, "Failed to close supplier return")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Supplier return closed", result)
+}
+
+// GetRTVReport handles GET /api/v1/inventories/rtv/reports/suppliers
+// @Summary RTV totals per supplier (admin only)
+// @Tags Supplier Return
+// @Produce json
+// @Param from_date query string false "From date (YYYY-MM-DD), default 30 days ago"
+// @Param to_date query string false "To date (YYYY-MM-DD), default now"
+// @Success 200 {object} response.SuccessResponse{data=model.RTVReportResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/v1/inventories/rtv/reports/suppliers [get]
+func (h *Handler) GetRTVReport(c *gin.Context) {
+	var req model.RTVReportRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	result, err := h.service.GetRTVReport(c.Request.Context(), req)
+	if err != nil {
+		h.handleRTVError(c, err, "Failed to get RTV report")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "RTV report retrieved", result)
+}
+
+func parseRTVID(c *gin.Context) (uuid.UUID, bool) {
+	returnID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid supplier return ID", err.Error())
+		return uuid.Nil, false
+	}
+	return returnID, true
+}
+
+func (h *Handler) handleRTVError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrRTVNotFound):
+		response.Error(c, http.StatusNotFound, "Supplier return not found", err.Error())
+	case model.IsNotFoundError(err), errors.Is(err, model.ErrBookNotFound):
+		response.Error(c, http.StatusNotFound, "Inventory not found", err.Error())
+	case model.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+	case model.IsInsufficientStockError(err):
+		response.Error(c, http.StatusConflict, "Insufficient available stock", err.Error())
+	case model.IsRTVConflictError(err):
+		response.Error(c, http.StatusConflict, "Supplier return conflict", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ========================================
+// SERIAL / LOT TRACKING HANDLERS
+// ========================================
+
+// ListSerialUnits handles GET /api/v1/inventories/serials
+// @Summary List serial units (admin / warehouse staff)
+// @Tags Serial Tracking
+// @Produce json
+// @Param warehouse_id query string false "Warehouse ID"
+// @Param book_id query string false "Book ID"
+// @Param order_id query string false "Order ID"
+// @Param lot_number query string false "Lot number"
+// @Param status query string false "in_stock, assigned"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=model.ListSerialsResponse}
+// @Router /api/v1/inventories/serials [get]
+func (h *Handler) ListSerialUnits(c *gin.Context) {
+	var req model.ListSerialsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		if req.WarehouseID != nil && !middleware.CanAccessWarehouse(c, *req.WarehouseID) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+			return
+		}
+		req.ScopeWarehouseIDs = scope
+	}
+
+	result, err := h.service.ListSerialUnits(c.Request.Context(), req)
+	if err != nil {
+		h.handleSerialError(c, err, "Failed to list serial units")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serial units retrieved", result)
+}
+
+// GetSerialUnit handles GET /api/v1/inventories/serials/:serial
+// @Summary Get serial unit with warehouse, lot and order (admin / warehouse staff)
+// @Tags Serial Tracking
+// @Produce json
+// @Param serial path string true "Serial number"
+// @Success 200 {object} response.SuccessResponse{data=model.SerialUnit}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/inventories/serials/{serial} [get]
+func (h *Handler) GetSerialUnit(c *gin.Context) {
+	result, err := h.service.GetSerialUnit(c.Request.Context(), c.Param("serial"))
+	if err != nil {
+		h.handleSerialError(c, err, "Failed to get serial unit")
+		return
+	}
+
+	if !middleware.CanAccessWarehouse(c, result.WarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serial unit retrieved", result)
+}
+
+// VerifySerial handles GET /api/v1/serials/:serial/verify
+// @Summary Verify authenticity of a limited / signed edition copy (public)
+// @Description Returns book + lot of a registered serial; authentic=false if the serial was never received
+// @Tags Serial Tracking
+// @Produce json
+// @Param serial path string true "Serial number"
+// @Success 200 {object} response.SuccessResponse{data=model.SerialVerification}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /api/v1/serials/{serial}/verify [get]
+func (h *Handler) VerifySerial(c *gin.Context) {
+	result, err := h.service.VerifySerial(c.Request.Context(), c.Param("serial"))
+	if err != nil {
+		h.handleSerialError(c, err, "Failed to verify serial number")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serial number verified", result)
+}
+
+// AssignOrderItemSerials handles POST /api/v1/inventories/serials/orders/:order_id/items/:item_id
+// @Summary Assign picked serial numbers to an order item (admin / warehouse staff)
+// @Tags Serial Tracking
+// @Accept json
+// @Produce json
+// @Param order_id path string true "Order ID"
+// @Param item_id path string true "Order item ID"
+// @Param request body model.AssignSerialsRequest true "Serial numbers"
+// @Success 200 {object} response.SuccessResponse{data=[]model.SerialUnit}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Serial not in stock / exceeds item quantity / order not picking"
+// @Router /api/v1/inventories/serials/orders/{order_id}/items/{item_id} [post]
+func (h *Handler) AssignOrderItemSerials(c *gin.Context) {
+	orderID, itemID, ok := parseOrderItemIDs(c)
+	if !ok {
+		return
+	}
+
+	var req model.AssignSerialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+	req.AssignedBy = userID
+
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		req.ScopeWarehouseIDs = scope
+	}
+
+	result, err := h.service.AssignSerialsToOrderItem(c.Request.Context(), orderID, itemID, req)
+	if err != nil {
+		h.handleSerialError(c, err, "Failed to assign serial numbers")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serial numbers assigned", result)
+}
+
+// UnassignOrderItemSerial handles DELETE /api/v1/inventories/serials/orders/:order_id/items/:item_id/:serial
+// @Summary Remove a wrongly picked serial from an order item (admin / warehouse staff)
+// @Tags Serial Tracking
+// @Produce json
+// @Param order_id path string true "Order ID"
+// @Param item_id path string true "Order item ID"
+// @Param serial path string true "Serial number"
+// @Success 200 {object} response.SuccessResponse{data=[]model.SerialUnit}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Order not picking"
+// @Router /api/v1/inventories/serials/orders/{order_id}/items/{item_id}/{serial} [delete]
+func (h *Handler) UnassignOrderItemSerial(c *gin.Context) {
+	orderID, itemID, ok := parseOrderItemIDs(c)
+	if !ok {
+		return
+	}
+
+	var scope []uuid.UUID
+	if s, restricted := middleware.GetWarehouseScope(c); restricted {
+		scope = s
+	}
+
+	result, err := h.service.UnassignSerialFromOrderItem(c.Request.Context(), orderID, itemID, c.Param("serial"), scope)
+	if err != nil {
+		h.handleSerialError(c, err, "Failed to remove serial number")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Serial number removed", result)
+}
+
+func parseOrderItemIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid order item ID", err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orderID, itemID, true
+}
+
+func (h *Handler) handleSerialError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrSerialNotFound):
+		response.Error(c, http.StatusNotFound, "Serial number not found", err.Error())
+	case errors.Is(err, model.ErrOrderItemNotFound):
+		response.Error(c, http.StatusNotFound, "Order item not found", err.Error())
+	case errors.Is(err, model.ErrWarehouseAccessDenied):
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", err.Error())
+	case model.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+	case model.IsSerialConflictError(err):
+		response.Error(c, http.StatusConflict, "Serial number conflict", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ========================================
+// STOCK TRANSFER HANDLERS
+// ========================================
+// Staff kho: tạo / xuất / huỷ phiếu khi kho nguồn thuộc phạm vi, nhận hàng khi kho đích thuộc phạm vi
+
+// CreateStockTransfer handles POST /api/v1/inventories/transfers
+// @Summary Create stock transfer between warehouses
+// @Description Creates a pending transfer; source stock is decremented when the transfer is shipped
+// @Tags Stock Transfer
+// @Accept json
+// @Produce json
+// @Param request body model.CreateTransferRequest true "Create Transfer Request"
+// @Success 201 {object} response.SuccessResponse{data=model.StockTransfer}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Source warehouse not in staff scope"
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Insufficient available stock"
+// @Router /api/v1/inventories/transfers [post]
+func (h *Handler) CreateStockTransfer(c *gin.Context) {
+	var req model.CreateTransferRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	if !middleware.CanAccessWarehouse(c, req.SourceWarehouseID) {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+	req.CreatedBy = userID
+
+	result, err := h.service.CreateStockTransfer(c.Request.Context(), req)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to create stock transfer")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Stock transfer created", result)
+}
+
+// ListStockTransfers handles GET /api/v1/inventories/transfers
+// @Summary List stock transfers / transfer history of a warehouse
+// @Tags Stock Transfer
+// @Produce json
+// @Param warehouse_id query string false "Warehouse ID (source or destination)"
+// @Param direction query string false "out (warehouse is source), in (warehouse is destination)"
+// @Param status query string false "pending, in_transit, received, cancelled"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.SuccessResponse{data=model.ListTransfersResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Router /api/v1/inventories/transfers [get]
+func (h *Handler) ListStockTransfers(c *gin.Context) {
+	var req model.ListTransfersRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+
+	if scope, restricted := middleware.GetWarehouseScope(c); restricted {
+		if req.WarehouseID != nil && !middleware.CanAccessWarehouse(c, *req.WarehouseID) {
+			response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+			return
+		}
+		req.ScopeWarehouseIDs = scope
+	}
+
+	result, err := h.service.ListStockTransfers(c.Request.Context(), req)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to list stock transfers")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Stock transfers retrieved", result)
+}
+
+// GetStockTransfer handles GET /api/v1/inventories/transfers/:id
+// @Summary Get stock transfer with items
+// @Tags Stock Transfer
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} response.SuccessResponse{data=model.StockTransfer}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/v1/inventories/transfers/{id} [get]
+func (h *Handler) GetStockTransfer(c *gin.Context) {
+	transfer, ok := h.loadScopedTransfer(c, false, false)
+	if !ok {
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Stock transfer retrieved", transfer)
+}
+
+// ShipStockTransfer handles POST /api/v1/inventories/transfers/:id/ship
+// @Summary Mark stock transfer in transit (decrements source warehouse)
+// @Tags Stock Transfer
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} response.SuccessResponse{data=model.StockTransfer}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Not pending / insufficient available stock"
+// @Router /api/v1/inventories/transfers/{id}/ship [post]
+func (h *Handler) ShipStockTransfer(c *gin.Context) {
+	transfer, ok := h.loadScopedTransfer(c, true, false)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+
+	result, err := h.service.ShipStockTransfer(c.Request.Context(), transfer.ID, userID)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to ship stock transfer")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Stock transfer in transit", result)
+}
+
+// ReceiveStockTransfer handles POST /api/v1/inventories/transfers/:id/receive
+// @Summary Receive stock transfer (increments destination warehouse)
+// @Tags Stock Transfer
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} response.SuccessResponse{data=model.StockTransfer}
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Not in transit"
+// @Router /api/v1/inventories/transfers/{id}/receive [post]
+func (h *Handler) ReceiveStockTransfer(c *gin.Context) {
+	transfer, ok := h.loadScopedTransfer(c, false, true)
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+
+	result, err := h.service.ReceiveStockTransfer(c.Request.Context(), transfer.ID, userID)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to receive stock transfer")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Stock transfer received", result)
+}
+
+// CancelStockTransfer handles POST /api/v1/inventories/transfers/:id/cancel
+// @Summary Cancel stock transfer (in-transit stock returns to the source warehouse)
+// @Tags Stock Transfer
+// @Accept json
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Param request body model.CancelTransferRequest true "Cancel reason"
+// @Success 200 {object} response.SuccessResponse{data=model.StockTransfer}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Already received / cancelled"
+// @Router /api/v1/inventories/transfers/{id}/cancel [post]
+func (h *Handler) CancelStockTransfer(c *gin.Context) {
+	transfer, ok := h.loadScopedTransfer(c, true, false)
+	if !ok {
+		return
+	}
+
+	var req model.CancelTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not found in context")
+		return
+	}
+	req.CancelledBy = userID
+
+	result, err := h.service.CancelStockTransfer(c.Request.Context(), transfer.ID, req)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to cancel stock transfer")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Stock transfer cancelled", result)
+}
+
+// loadScopedTransfer lấy phiếu theo :id rồi kiểm tra phạm vi kho của staff
+// needSource / needDestination: kho nào phải thuộc phạm vi; cả 2 false = 1 trong 2 kho là đủ (xem phiếu)
+func (h *Handler) loadScopedTransfer(c *gin.Context, needSource, needDestination bool) (*model.StockTransfer, bool) {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid stock transfer ID", err.Error())
+		return nil, false
+	}
+
+	transfer, err := h.service.GetStockTransfer(c.Request.Context(), transferID)
+	if err != nil {
+		h.handleTransferError(c, err, "Failed to get stock transfer")
+		return nil, false
+	}
+
+	canSource := middleware.CanAccessWarehouse(c, transfer.SourceWarehouseID)
+	canDestination := middleware.CanAccessWarehouse(c, transfer.DestinationWarehouseID)
+	allowed := canSource || canDestination
+	if needSource {
+		allowed = canSource
+	}
+	if needDestination {
+		allowed = canDestination
+	}
+	if !allowed {
+		response.Error(c, http.StatusForbidden, "Warehouse access denied", model.ErrWarehouseAccessDenied.Error())
+		return nil, false
+	}
+
+	return transfer, true
+}
+
+func (h *Handler) handleTransferError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrTransferNotFound):
+		response.Error(c, http.StatusNotFound, "Stock transfer not found", err.Error())
+	case model.IsNotFoundError(err), errors.Is(err, model.ErrBookNotFound):
+		response.Error(c, http.StatusNotFound, "Inventory not found", err.Error())
+	case model.IsValidationError(err):
+		response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+	case model.IsInsufficientStockError(err):
+		response.Error(c, http.StatusConflict, "Insufficient available stock", err.Error())
+	case errors.Is(err, model.ErrTransferStatusConflict):
+		response.Error(c, http.StatusConflict, "Stock transfer conflict", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
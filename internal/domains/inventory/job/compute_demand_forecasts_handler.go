@@ -0,0 +1,37 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/inventory/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// ComputeDemandForecastsHandler fit lại dự báo nhu cầu theo tuần (demand_forecasts)
+// cho GET /inventories/forecast và gợi ý nhập hàng
+type ComputeDemandForecastsHandler struct {
+	service service.ServiceInterface
+}
+
+func NewComputeDemandForecastsHandler(service service.ServiceInterface) *ComputeDemandForecastsHandler {
+	return &ComputeDemandForecastsHandler{service: service}
+}
+
+func (h *ComputeDemandForecastsHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	stats, err := h.service.ComputeDemandForecasts(ctx)
+	if err != nil {
+		return fmt.Errorf("compute demand forecasts: %w", err)
+	}
+
+	logger.Info("Completed ComputeDemandForecasts job", map[string]interface{}{
+		"series":      stats.Series,
+		"skipped":     stats.Skipped,
+		"forecasts":   stats.Forecasts,
+		"computed_at": stats.ComputedAt,
+	})
+
+	return nil
+}
@@ -3,34 +3,47 @@ package job
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hibiken/asynq"
 
 	repo "bookstore-backend/internal/domains/inventory/repository"
+	"bookstore-backend/internal/infrastructure/joboutcome"
 	"bookstore-backend/internal/shared"
 	"bookstore-backend/pkg/cache"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 )
 
 // InventorySyncHandler xử lý job đồng bộ tổng tồn của book ra Redis.
+// Sau đó enqueue job đẩy tồn + giá lên các sàn (marketplace:sync_stock).
 type InventorySyncHandler struct {
-	repo  repo.RepositoryInterface
-	cache cache.Cache
+	repo     repo.RepositoryInterface
+	cache    cache.Cache
+	client   *asynq.Client
+	outcomes *joboutcome.Recorder
 }
 
 // NewInventorySyncHandler tạo handler mới với dependency từ container.
 func NewInventorySyncHandler(
 	repo repo.RepositoryInterface,
 	cache cache.Cache,
+	client *asynq.Client,
+	outcomes *joboutcome.Recorder,
 ) *InventorySyncHandler {
 	return &InventorySyncHandler{
-		repo:  repo,
-		cache: cache,
+		repo:     repo,
+		cache:    cache,
+		client:   client,
+		outcomes: outcomes,
 	}
 }
 
+// marketplaceSyncDebounce gom các thay đổi tồn liên tiếp của 1 sách (checkout dồn dập) thành 1 lần đẩy lên sàn
+const marketplaceSyncDebounce = 10 * time.Second
+
 // bookStockCacheDTO là cấu trúc JSON lưu trong Redis.
 type bookStockCacheDTO struct {
 	BookID              string    `json:"book_id"`
@@ -46,6 +59,7 @@ type bookStockCacheDTO struct {
 // 1. Parse payload.
 // 2. Đọc tổng tồn từ view books_total_stock.
 // 3. Ghi JSON vào Redis key inventory:book:{book_id}:total (không TTL).
+// 4. Enqueue đẩy tồn lên sàn.
 func (h *InventorySyncHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
 	// 1. Parse payload
 	var payload shared.InventorySyncPayload
@@ -106,6 +120,12 @@ func (h *InventorySyncHandler) ProcessTask(ctx context.Context, task *asynq.Task
 		// Dù Redis lỗi, không nên fail job vì DB vẫn là source of truth.
 		// Tuy nhiên, nếu bạn muốn retry khi Redis lỗi thực sự, cần sửa RedisCache.Set để trả error.
 		// Với implement hiện tại, err hầu như sẽ là nil.
+		h.outcomes.Record(ctx, joboutcome.JobInventorySync, joboutcome.OutcomeCacheWriteFailed, 1)
+	} else {
+		h.outcomes.Record(ctx, joboutcome.JobInventorySync, joboutcome.OutcomeCacheUpdated, 1)
+	}
+	if cacheDTO.Available <= 0 {
+		h.outcomes.Record(ctx, joboutcome.JobInventorySync, joboutcome.OutcomeOutOfStock, 1)
 	}
 
 	// Logging info để quan sát
@@ -118,5 +138,34 @@ func (h *InventorySyncHandler) ProcessTask(ctx context.Context, task *asynq.Task
 		"correlation": payload.CorrelationID,
 	})
 
+	// 4. Đẩy tồn lên sàn: debounce + unique theo book, task đang chờ sẽ đọc tồn mới nhất lúc chạy
+	h.enqueueMarketplaceSync(ctx, payload)
+
 	return nil
 }
+
+func (h *InventorySyncHandler) enqueueMarketplaceSync(ctx context.Context, payload shared.InventorySyncPayload) {
+	if h.client == nil {
+		return
+	}
+
+	b, _ := json.Marshal(shared.MarketplaceStockSyncPayload{
+		BookID: payload.BookID,
+		Source: payload.Source,
+	})
+	task := tracing.NewTask(ctx, shared.TypeMarketplaceSyncStock, b)
+	_, err := h.client.Enqueue(task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.ProcessIn(marketplaceSyncDebounce),
+		asynq.Unique(time.Minute),
+		asynq.MaxRetry(10),
+	)
+	if err != nil {
+		if !errors.Is(err, asynq.ErrDuplicateTask) {
+			// Không fail job: reconcile đêm sẽ đẩy bù
+			logger.Error("InventorySync: failed to enqueue marketplace stock sync", err)
+		}
+		return
+	}
+	h.outcomes.Record(ctx, joboutcome.JobInventorySync, joboutcome.OutcomeMarketplaceEnqueued, 1)
+}
@@ -0,0 +1,245 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bookstore-backend/internal/domains/inventory/model"
+	repo "bookstore-backend/internal/domains/inventory/repository"
+	"bookstore-backend/internal/infrastructure/email"
+	"bookstore-backend/pkg/logger"
+)
+
+var lowStockAlertDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "low_stock_alert_deliveries_total",
+	Help: "Low stock alert messages sent to warehouse staff.",
+}, []string{"channel", "mode", "outcome"})
+
+// AlertEmailSender gửi email (implement bởi email.EmailService)
+type AlertEmailSender interface {
+	SendEmail(ctx context.Context, req email.EmailRequest) error
+}
+
+// AlertSlackSender gửi tin qua Slack incoming webhook (implement bởi slack.WebhookClient)
+type AlertSlackSender interface {
+	Send(ctx context.Context, webhookURL, text string) error
+}
+
+// ========================================
+// IMMEDIATE: inventory:dispatch_low_stock_alerts (mỗi phút)
+// ========================================
+
+// DispatchLowStockAlertsHandler gửi cảnh báo tồn thấp mới tạo cho người nhận chế độ immediate.
+// Mỗi người nhận 1 tin / batch (liệt kê mọi cảnh báo thuộc kho của họ), không phải 1 tin / cảnh báo
+type DispatchLowStockAlertsHandler struct {
+	repo   repo.RepositoryInterface
+	sender *alertSender
+}
+
+func NewDispatchLowStockAlertsHandler(
+	repo repo.RepositoryInterface,
+	emails AlertEmailSender,
+	slack AlertSlackSender,
+) *DispatchLowStockAlertsHandler {
+	return &DispatchLowStockAlertsHandler{
+		repo:   repo,
+		sender: &alertSender{emails: emails, slack: slack},
+	}
+}
+
+// ProcessTask - claim (notified_at) trước rồi mới gửi: job chạy chồng / retry không gửi trùng.
+// Gửi lỗi chỉ log (cảnh báo vẫn hiện trên dashboard + vào digest)
+func (h *DispatchLowStockAlertsHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	recipients, err := h.repo.ListActiveAlertRecipients(ctx, model.AlertModeImmediate)
+	if err != nil {
+		logger.Error("Failed to list low stock alert recipients", err)
+		return fmt.Errorf("list alert recipients: %w", err)
+	}
+
+	var result model.AlertDispatchResult
+	for round := 0; round < model.AlertDispatchMaxRounds; round++ {
+		// Không ai nhận immediate vẫn claim: người nhận đăng ký sau không bị dồn cảnh báo cũ
+		alerts, err := h.repo.ClaimAlertsForNotify(ctx, model.AlertDispatchBatch)
+		if err != nil {
+			logger.Error("Failed to claim low stock alerts", err)
+			return fmt.Errorf("claim low stock alerts: %w", err)
+		}
+		result.Claimed += len(alerts)
+
+		h.sender.deliver(ctx, model.AlertModeImmediate, recipients, alerts, &result, func(covered []model.AlertNotification) (string, string) {
+			subject := fmt.Sprintf("[Tồn kho thấp] %d sách dưới ngưỡng cảnh báo", len(covered))
+			return subject, formatAlertLines(covered, len(covered))
+		})
+
+		if len(alerts) < model.AlertDispatchBatch {
+			break
+		}
+	}
+
+	if result.Claimed > 0 {
+		logger.Info("Low stock alerts dispatched", map[string]interface{}{
+			"claimed": result.Claimed,
+			"sent":    result.Sent,
+			"failed":  result.Failed,
+			"skipped": result.Skipped,
+		})
+	}
+	return nil
+}
+
+// ========================================
+// DIGEST: inventory:send_low_stock_digest (mỗi giờ)
+// ========================================
+
+// LowStockDigestHandler gom cảnh báo tạo trong giờ qua thành 1 tin tổng hợp cho người nhận chế độ digest
+type LowStockDigestHandler struct {
+	repo   repo.RepositoryInterface
+	sender *alertSender
+}
+
+func NewLowStockDigestHandler(
+	repo repo.RepositoryInterface,
+	emails AlertEmailSender,
+	slack AlertSlackSender,
+) *LowStockDigestHandler {
+	return &LowStockDigestHandler{
+		repo:   repo,
+		sender: &alertSender{emails: emails, slack: slack},
+	}
+}
+
+// ProcessTask - claim toàn bộ cảnh báo chưa vào digest (digested_at), gửi 1 tin / người nhận
+func (h *LowStockDigestHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	recipients, err := h.repo.ListActiveAlertRecipients(ctx, model.AlertModeDigest)
+	if err != nil {
+		logger.Error("Failed to list low stock digest recipients", err)
+		return fmt.Errorf("list alert recipients: %w", err)
+	}
+
+	var alerts []model.AlertNotification
+	for round := 0; round < model.AlertDispatchMaxRounds; round++ {
+		batch, err := h.repo.ClaimAlertsForDigest(ctx, model.AlertDispatchBatch)
+		if err != nil {
+			logger.Error("Failed to claim low stock alerts for digest", err)
+			return fmt.Errorf("claim low stock alerts for digest: %w", err)
+		}
+		alerts = append(alerts, batch...)
+
+		if len(batch) < model.AlertDispatchBatch {
+			break
+		}
+	}
+
+	result := model.AlertDispatchResult{Claimed: len(alerts)}
+	generatedAt := time.Now().Format("02/01/2006 15:04")
+	h.sender.deliver(ctx, model.AlertModeDigest, recipients, alerts, &result, func(covered []model.AlertNotification) (string, string) {
+		subject := fmt.Sprintf("[Tồn kho thấp] Tổng hợp %s - %d sách dưới ngưỡng", generatedAt, len(covered))
+		return subject, formatAlertLines(covered, model.AlertDigestMaxLines)
+	})
+
+	if result.Claimed > 0 {
+		logger.Info("Low stock alert digest sent", map[string]interface{}{
+			"claimed": result.Claimed,
+			"sent":    result.Sent,
+			"failed":  result.Failed,
+			"skipped": result.Skipped,
+		})
+	}
+	return nil
+}
+
+// ========================================
+// DELIVERY
+// ========================================
+
+type alertSender struct {
+	emails AlertEmailSender
+	slack  AlertSlackSender
+}
+
+// deliver gửi cho từng người nhận các cảnh báo thuộc kho của họ (bỏ cảnh báo đã resolve trước khi kịp gửi)
+func (s *alertSender) deliver(
+	ctx context.Context,
+	mode string,
+	recipients []model.AlertRecipient,
+	alerts []model.AlertNotification,
+	result *model.AlertDispatchResult,
+	render func(covered []model.AlertNotification) (subject, body string),
+) {
+	open := make([]model.AlertNotification, 0, len(alerts))
+	for _, alert := range alerts {
+		if !alert.IsResolved {
+			open = append(open, alert)
+		}
+	}
+
+	delivered := make(map[int]bool, len(open))
+	for _, recipient := range recipients {
+		covered := make([]model.AlertNotification, 0, len(open))
+		for i, alert := range open {
+			if recipient.Covers(alert.WarehouseID) {
+				covered = append(covered, alert)
+				delivered[i] = true
+			}
+		}
+		if len(covered) == 0 {
+			continue
+		}
+
+		subject, body := render(covered)
+		if err := s.send(ctx, recipient, subject, body); err != nil {
+			logger.Error(fmt.Sprintf("Failed to send low stock alert via %s (recipient %s)", recipient.Channel, recipient.ID), err)
+			lowStockAlertDeliveriesTotal.WithLabelValues(recipient.Channel, mode, "failed").Inc()
+			result.Failed++
+			continue
+		}
+		lowStockAlertDeliveriesTotal.WithLabelValues(recipient.Channel, mode, "sent").Inc()
+		result.Sent++
+	}
+
+	result.Skipped += len(alerts) - len(delivered)
+}
+
+func (s *alertSender) send(ctx context.Context, recipient model.AlertRecipient, subject, body string) error {
+	switch recipient.Channel {
+	case model.AlertChannelEmail:
+		return s.emails.SendEmail(ctx, email.EmailRequest{
+			To:      []string{recipient.Target},
+			Subject: subject,
+			Body:    body,
+		})
+	case model.AlertChannelSlack:
+		return s.slack.Send(ctx, recipient.Target, "*"+subject+"*\n"+body)
+	default:
+		return fmt.Errorf("unsupported alert channel %q", recipient.Channel)
+	}
+}
+
+// formatAlertLines 1 dòng / cảnh báo, nặng nhất (còn ít nhất) lên đầu, quá maxLines thì rút gọn
+func formatAlertLines(alerts []model.AlertNotification, maxLines int) string {
+	sorted := make([]model.AlertNotification, len(alerts))
+	copy(sorted, alerts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CurrentQuantity < sorted[j].CurrentQuantity
+	})
+
+	var b strings.Builder
+	for i, alert := range sorted {
+		if i == maxLines {
+			fmt.Fprintf(&b, "... và %d cảnh báo khác (xem /inventories/alerts/low-stock)\n", len(sorted)-maxLines)
+			break
+		}
+		fmt.Fprintf(&b, "- [%s] %s - %s: còn %d (ngưỡng %d)\n",
+			model.AlertPriority(alert.CurrentQuantity, alert.AlertThreshold),
+			alert.BookTitle, alert.WarehouseName, alert.CurrentQuantity, alert.AlertThreshold,
+		)
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// LOW STOCK ALERT DELIVERY
+// ========================================
+
+// Kênh gửi cảnh báo
+const (
+	AlertChannelEmail = "email" // target = địa chỉ email
+	AlertChannelSlack = "slack" // target = Slack incoming webhook URL
+)
+
+// Chế độ gửi
+const (
+	AlertModeImmediate = "immediate" // gửi ngay khi cảnh báo được tạo (worker quét mỗi phút)
+	AlertModeDigest    = "digest"    // gộp cảnh báo trong giờ thành 1 bản tổng hợp
+)
+
+// Limits
+const (
+	AlertRecipientMaxTargetLength = 500
+	AlertDispatchBatch            = 200
+	AlertDispatchMaxRounds        = 20 // tối đa 4k cảnh báo/lần chạy, phần còn lại để lần sau
+	AlertDigestMaxLines           = 100
+)
+
+// AlertPriority mức độ cảnh báo: hết hàng → critical, còn <= nửa ngưỡng → high
+func AlertPriority(currentQuantity, alertThreshold int) string {
+	switch {
+	case currentQuantity == 0:
+		return "critical"
+	case currentQuantity <= alertThreshold/2:
+		return "high"
+	default:
+		return "medium"
+	}
+}
+
+// AlertRecipient represents low_stock_alert_recipients table
+type AlertRecipient struct {
+	ID            uuid.UUID  `json:"id"`
+	WarehouseID   *uuid.UUID `json:"warehouse_id,omitempty"`   // nil: mọi kho
+	WarehouseName string     `json:"warehouse_name,omitempty"` // join, chỉ đọc
+	Channel       string     `json:"channel"`
+	Target        string     `json:"target"`
+	Mode          string     `json:"mode"`
+	IsActive      bool       `json:"is_active"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Covers người nhận có nhận cảnh báo của kho này không
+func (r *AlertRecipient) Covers(warehouseID uuid.UUID) bool {
+	return r.WarehouseID == nil || *r.WarehouseID == warehouseID
+}
+
+// AlertNotification - cảnh báo đã nhận (claim) để gửi, kèm tên sách / kho
+type AlertNotification struct {
+	AlertID         uuid.UUID
+	WarehouseID     uuid.UUID
+	WarehouseName   string
+	BookID          uuid.UUID
+	BookTitle       string
+	CurrentQuantity int
+	AlertThreshold  int
+	IsResolved      bool // đã hết thiếu trước khi kịp gửi → bỏ qua
+	CreatedAt       time.Time
+}
+
+// AlertDispatchResult kết quả 1 lần chạy dispatcher / digest
+type AlertDispatchResult struct {
+	Claimed int `json:"claimed"` // cảnh báo đã đánh dấu notified / digested
+	Sent    int `json:"sent"`    // số tin gửi thành công (1 tin / người nhận)
+	Failed  int `json:"failed"`  // số tin gửi lỗi (chỉ log, không gửi lại)
+	Skipped int `json:"skipped"` // cảnh báo đã resolve hoặc không có người nhận
+}
+
+// ========================================
+// REQUESTS
+// ========================================
+
+type CreateAlertRecipientRequest struct {
+	WarehouseID *uuid.UUID `json:"warehouse_id,omitempty"`
+	Channel     string     `json:"channel" validate:"required,oneof=email slack"`
+	Target      string     `json:"target" validate:"required,max=500"`
+	Mode        string     `json:"mode,omitempty" validate:"omitempty,oneof=immediate digest"`
+	CreatedBy   uuid.UUID  `json:"-"`
+}
+
+// Validate chuẩn hoá + kiểm tra target theo kênh (mode trống → immediate)
+func (r *CreateAlertRecipientRequest) Validate() error {
+	r.Target = strings.TrimSpace(r.Target)
+	if r.Mode == "" {
+		r.Mode = AlertModeImmediate
+	}
+	if r.Mode != AlertModeImmediate && r.Mode != AlertModeDigest {
+		return fmt.Errorf("%w: mode must be immediate or digest", ErrInvalidAlertRecipient)
+	}
+	return validateAlertTarget(r.Channel, r.Target)
+}
+
+type UpdateAlertRecipientRequest struct {
+	Target   *string `json:"target,omitempty" validate:"omitempty,max=500"`
+	Mode     *string `json:"mode,omitempty" validate:"omitempty,oneof=immediate digest"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// Apply ghi đè các field được gửi lên rồi validate lại theo kênh hiện tại
+func (r *UpdateAlertRecipientRequest) Apply(recipient *AlertRecipient) error {
+	if r.Target != nil {
+		recipient.Target = strings.TrimSpace(*r.Target)
+	}
+	if r.Mode != nil {
+		if *r.Mode != AlertModeImmediate && *r.Mode != AlertModeDigest {
+			return fmt.Errorf("%w: mode must be immediate or digest", ErrInvalidAlertRecipient)
+		}
+		recipient.Mode = *r.Mode
+	}
+	if r.IsActive != nil {
+		recipient.IsActive = *r.IsActive
+	}
+	return validateAlertTarget(recipient.Channel, recipient.Target)
+}
+
+type ListAlertRecipientsRequest struct {
+	WarehouseID *uuid.UUID `form:"warehouse_id"`
+	Channel     string     `form:"channel" validate:"omitempty,oneof=email slack"`
+	Mode        string     `form:"mode" validate:"omitempty,oneof=immediate digest"`
+}
+
+func validateAlertTarget(channel, target string) error {
+	if target == "" || len(target) > AlertRecipientMaxTargetLength {
+		return fmt.Errorf("%w: target is required (max %d characters)", ErrInvalidAlertRecipient, AlertRecipientMaxTargetLength)
+	}
+
+	switch channel {
+	case AlertChannelEmail:
+		addr, err := mail.ParseAddress(target)
+		if err != nil || addr.Address != target {
+			return fmt.Errorf("%w: target must be a plain email address", ErrInvalidAlertRecipient)
+		}
+	case AlertChannelSlack:
+		// Chỉ nhận webhook của Slack: worker POST nội dung tồn kho ra ngoài, không để admin trỏ tới host tuỳ ý
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme != "https" || u.Host != "hooks.slack.com" {
+			return fmt.Errorf("%w: target must be a https://hooks.slack.com incoming webhook URL", ErrInvalidAlertRecipient)
+		}
+	default:
+		return fmt.Errorf("%w: channel must be email or slack", ErrInvalidAlertRecipient)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -33,8 +34,14 @@ type ListInventoryRequest struct {
 	WarehouseID       *string `form:"warehouse_id" json:"warehouse_id,omitempty"`
 	IsLowStock        *bool   `form:"is_low_stock" json:"is_low_stock,omitempty"`
 	HasAvailableStock *bool   `form:"has_available_stock" json:"has_available_stock,omitempty"`
-	Page              int     `form:"page" json:"page" binding:"required,gte=1"`
-	Limit             int     `form:"limit" json:"limit" binding:"required,gte=1,lte=100"`
+	Page              int     `form:"page" json:"page" binding:"omitempty,gte=1"`
+	Limit             int     `form:"limit" json:"limit" binding:"omitempty,gte=1,lte=100"`
+	// Cursor mode: ?pagination=cursor (trang đầu) hoặc ?cursor=<next_cursor>; page bị bỏ qua
+	Pagination string `form:"pagination" json:"-"`
+	Cursor     string `form:"cursor" json:"-"`
+
+	// Set by handler from warehouse scope (staff), nil = all warehouses
+	ScopeWarehouseIDs []uuid.UUID `form:"-" json:"-"`
 }
 
 // ========================================
@@ -75,9 +82,23 @@ type AdjustStockRequest struct {
 	BookID      uuid.UUID `json:"book_id" validate:"required"`
 	NewQuantity int       `json:"new_quantity" validate:"required,gte=0"`
 	Reason      string    `json:"reason" validate:"required,min=10"` // Mandatory for audit
-	Version     int       `json:"version" validate:"required"`
-	ChangedBy   uuid.UUID `json:"changed_by" validate:"required"` // Admin user
-	IPAddress   *string   `json:"ip_address,omitempty"`
+	// damaged, lost, expired, sample (write-off, chỉ khi giảm tồn), recount, other
+	ReasonCategory string    `json:"reason_category" validate:"required,oneof=damaged lost expired sample recount other"`
+	Version        int       `json:"version" validate:"required"`
+	ChangedBy      uuid.UUID `json:"changed_by" validate:"required"` // Admin user
+	IPAddress      *string   `json:"ip_address,omitempty"`
+}
+
+// ReviewAdjustmentRequest approves/rejects a pending adjustment
+type ReviewAdjustmentRequest struct {
+	Note       *string   `json:"note,omitempty"`
+	ReviewedBy uuid.UUID `json:"-"`
+}
+
+type ListAdjustmentRequestsRequest struct {
+	Status string `form:"status"` // pending (default), approved, rejected
+	Page   int    `form:"page" validate:"omitempty,gte=1"`
+	Limit  int    `form:"limit" validate:"omitempty,gte=1,lte=100"`
 }
 
 type RestockRequest struct {
@@ -86,6 +107,10 @@ type RestockRequest struct {
 	QuantityToAdd int        `json:"quantity_to_add" validate:"required,gte=1"`
 	Reason        *string    `json:"reason,omitempty"`
 	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty"`
+
+	// Tuỳ chọn cho bản giới hạn / có chữ ký: mỗi cuốn 1 serial (số serial = quantity_to_add)
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+	LotNumber     *string  `json:"lot_number,omitempty"`
 }
 
 // ========================================
@@ -133,6 +158,9 @@ type AuditTrailRequest struct {
 	EndDate     *time.Time `json:"end_date,omitempty" form:"end_date"`
 	Page        int        `json:"page" validate:"required,gte=1" form:"page"`
 	Limit       int        `json:"limit" validate:"required,gte=1,lte=100" form:"limit"`
+
+	// Set by handler from warehouse scope (staff), nil = all warehouses
+	ScopeWarehouseIDs []uuid.UUID `json:"-" form:"-"`
 }
 
 type ExportAuditRequest struct {
@@ -142,6 +170,41 @@ type ExportAuditRequest struct {
 	Format      string     `json:"format" validate:"required,oneof=csv xlsx"`
 }
 
+// Write-off report range (ngày)
+const (
+	WriteOffReportDefaultRangeDays = 30
+	WriteOffReportMaxRangeDays     = 366
+)
+
+// WriteOffReportRequest - hao hụt theo kho + category trong khoảng ngày (mặc định 30 ngày gần nhất)
+type WriteOffReportRequest struct {
+	WarehouseID *uuid.UUID `form:"warehouse_id"`
+	FromDate    *time.Time `form:"from_date" time_format:"2006-01-02"`
+	ToDate      *time.Time `form:"to_date" time_format:"2006-01-02"`
+
+	// Set by handler from warehouse scope (staff), nil = all warehouses
+	ScopeWarehouseIDs []uuid.UUID `form:"-"`
+}
+
+// Validate điền khoảng ngày mặc định + giới hạn độ dài khoảng
+func (r *WriteOffReportRequest) Validate() error {
+	now := time.Now()
+	if r.ToDate == nil {
+		r.ToDate = &now
+	}
+	if r.FromDate == nil {
+		from := r.ToDate.AddDate(0, 0, -WriteOffReportDefaultRangeDays)
+		r.FromDate = &from
+	}
+	if r.FromDate.After(*r.ToDate) {
+		return fmt.Errorf("from_date must be before to_date")
+	}
+	if r.ToDate.Sub(*r.FromDate) > time.Duration(WriteOffReportMaxRangeDays)*24*time.Hour {
+		return fmt.Errorf("date range must not exceed %d days", WriteOffReportMaxRangeDays)
+	}
+	return nil
+}
+
 // ========================================
 // WAREHOUSE REQUESTS
 // ========================================
@@ -194,6 +257,9 @@ type ListInventoryResponse struct {
 	TotalPages int                 `json:"total_pages"`
 	Page       int                 `json:"page"`
 	Limit      int                 `json:"limit"`
+	// Cursor mode (total_items / total_pages / page = 0): rỗng = hết danh sách
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more,omitempty"`
 }
 
 type ReserveStockResponse struct {
@@ -234,6 +300,10 @@ type AdjustStockResponse struct {
 	QuantityChange int       `json:"quantity_change"`
 	AuditLogID     uuid.UUID `json:"audit_log_id"`
 	Message        string    `json:"message"`
+
+	// Set when change exceeds approval threshold (adjustment not applied yet)
+	PendingApproval     bool       `json:"pending_approval"`
+	AdjustmentRequestID *uuid.UUID `json:"adjustment_request_id,omitempty"`
 }
 
 type RestockResponse struct {
@@ -243,6 +313,8 @@ type RestockResponse struct {
 	QuantityAdded int       `json:"quantity_added"`
 	NewQuantity   int       `json:"new_quantity"`
 	LastRestockAt time.Time `json:"last_restocked_at"`
+	SerialNumbers []string  `json:"serial_numbers,omitempty"`
+	LotNumber     *string   `json:"lot_number,omitempty"`
 	Message       string    `json:"message"`
 }
 
@@ -251,7 +323,9 @@ type WarehouseRecommendation struct {
 	WarehouseName     string    `json:"warehouse_name"`
 	DistanceKM        float64   `json:"distance_km"`
 	AvailableQuantity int       `json:"available_quantity"`
-	EstimatedDelivery string    `json:"estimated_delivery"` // "1-2 days", "3-5 days"
+	EstimatedDelivery string    `json:"estimated_delivery"` // "1-2 days", "3-5 days" (đã cộng ngày nghỉ)
+	// Số ngày nghỉ của kho (Tết, lễ, nghỉ riêng) làm giao chậm hơn bình thường
+	HolidayDelayDays int `json:"holiday_delay_days,omitempty"`
 }
 
 type CheckAvailabilityResponse struct {
@@ -330,6 +404,44 @@ type AuditTrailResponse struct {
 	Limit      int             `json:"limit"`
 }
 
+type ListAdjustmentRequestsResponse struct {
+	Items      []AdjustmentRequest `json:"items"`
+	TotalItems int                 `json:"total_items"`
+	TotalPages int                 `json:"total_pages"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+}
+
+// WriteOffStat - 1 dòng tổng hợp (kho, category) từ audit log ADJUSTMENT giảm tồn
+// Value tính theo giá vốn (books.cost_price), sách chưa có giá vốn tính 0
+type WriteOffStat struct {
+	WarehouseID   uuid.UUID `json:"-"`
+	WarehouseCode string    `json:"-"`
+	WarehouseName string    `json:"-"`
+	Category      string    `json:"category"`
+	Entries       int       `json:"entries"`
+	Quantity      int       `json:"quantity"`
+	Value         float64   `json:"value"`
+}
+
+type WarehouseWriteOff struct {
+	WarehouseID   uuid.UUID      `json:"warehouse_id"`
+	WarehouseCode string         `json:"warehouse_code"`
+	WarehouseName string         `json:"warehouse_name"`
+	TotalQuantity int            `json:"total_quantity"`
+	TotalValue    float64        `json:"total_value"`
+	ByCategory    []WriteOffStat `json:"by_category"`
+}
+
+type WriteOffReportResponse struct {
+	FromDate      time.Time           `json:"from_date"`
+	ToDate        time.Time           `json:"to_date"`
+	TotalQuantity int                 `json:"total_quantity"`
+	TotalValue    float64             `json:"total_value"`
+	ByCategory    map[string]int      `json:"by_category"` // quantity theo category, toàn bộ kho
+	Warehouses    []WarehouseWriteOff `json:"warehouses"`
+}
+
 type InventoryHistoryResponse struct {
 	WarehouseID uuid.UUID       `json:"warehouse_id"`
 	BookID      uuid.UUID       `json:"book_id"`
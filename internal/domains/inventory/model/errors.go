@@ -59,6 +59,90 @@ var (
 	ErrWarehouseNotFound              = errors.New("warehouse not found")
 	ErrWarehouseCodeExists            = errors.New("warehouse code already exists")
 	ErrCannotDeleteWarehouseWithStock = errors.New("cannot delete warehouse with existing stock")
+
+	// ErrWarehouseAccessDenied is returned when staff acts on a warehouse they are not assigned to
+	ErrWarehouseAccessDenied = errors.New("access denied: warehouse is not assigned to this user")
+
+	// ErrAdjustmentRequestNotFound is returned when adjustment request does not exist
+	ErrAdjustmentRequestNotFound = errors.New("adjustment request not found")
+
+	// ErrAdjustmentNotPending is returned when reviewing an already approved/rejected request
+	ErrAdjustmentNotPending = errors.New("adjustment request is not pending")
+
+	// ErrAdjustmentSelfApproval is returned when requester tries to review own request
+	ErrAdjustmentSelfApproval = errors.New("adjustment request must be reviewed by a different admin")
+
+	// ErrAdjustmentAlreadyPending is returned when inventory already has a pending request
+	ErrAdjustmentAlreadyPending = errors.New("inventory already has a pending adjustment request")
+
+	// ErrInvalidAdjustmentCategory is returned when reason category is not one of AdjustmentCategories
+	ErrInvalidAdjustmentCategory = errors.New("invalid reason category, must be one of: damaged, lost, expired, sample, recount, other")
+
+	// ErrWriteOffRequiresDecrease is returned when a write-off category is used for a non-decreasing adjustment
+	ErrWriteOffRequiresDecrease = errors.New("write-off categories (damaged, lost, expired, sample) require a quantity decrease")
+
+	// ErrInvalidRTV is returned when a supplier return request is invalid
+	ErrInvalidRTV = errors.New("invalid supplier return")
+
+	// ErrRTVNotFound is returned when supplier return does not exist
+	ErrRTVNotFound = errors.New("supplier return not found")
+
+	// ErrRTVClosed is returned when recording credit notes on / closing a closed supplier return
+	ErrRTVClosed = errors.New("supplier return is closed")
+
+	// ErrRTVNotClosable is returned when closing a supplier return that is not open / partially credited
+	ErrRTVNotClosable = errors.New("only open or partially credited supplier returns can be closed")
+
+	// ErrInvalidCreditNote is returned when credit note data is invalid
+	ErrInvalidCreditNote = errors.New("invalid credit note")
+
+	// ErrCreditNoteExists is returned when the credit note number was already recorded for the return
+	ErrCreditNoteExists = errors.New("credit note already recorded for this supplier return")
+
+	// ErrInvalidSerial is returned when serial / lot numbers are invalid
+	ErrInvalidSerial = errors.New("invalid serial number")
+
+	// ErrSerialNotFound is returned when serial number does not exist
+	ErrSerialNotFound = errors.New("serial number not found")
+
+	// ErrSerialExists is returned when receiving a serial number that is already registered
+	ErrSerialExists = errors.New("serial number already registered")
+
+	// ErrSerialNotAvailable is returned when picking a serial that is not in stock for the book / warehouse
+	ErrSerialNotAvailable = errors.New("serial number is not in stock for this book at the order warehouse")
+
+	// ErrSerialOverAssign is returned when assigning more serials than the order item quantity
+	ErrSerialOverAssign = errors.New("serial numbers exceed order item quantity")
+
+	// ErrOrderItemNotFound is returned when order item does not exist in the order
+	ErrOrderItemNotFound = errors.New("order item not found")
+
+	// ErrOrderNotPickable is returned when serials are assigned / removed outside the picking stage
+	ErrOrderNotPickable = errors.New("serials can only be changed while the order is confirmed or processing")
+
+	// ErrInvalidReportRange is returned when report date range is invalid
+	ErrInvalidReportRange = errors.New("invalid report range")
+
+	// ErrInvalidTransfer is returned when a stock transfer request is invalid
+	ErrInvalidTransfer = errors.New("invalid stock transfer")
+
+	// ErrInvalidForecastRequest is returned when demand forecast / replenishment params are invalid
+	ErrInvalidForecastRequest = errors.New("invalid forecast request")
+
+	// ErrTransferNotFound is returned when stock transfer does not exist
+	ErrTransferNotFound = errors.New("stock transfer not found")
+
+	// ErrTransferStatusConflict is returned when the transfer is not in a status allowing the action
+	ErrTransferStatusConflict = errors.New("stock transfer status does not allow this action")
+
+	// ErrInvalidAlertRecipient is returned when a low stock alert recipient is invalid
+	ErrInvalidAlertRecipient = errors.New("invalid low stock alert recipient")
+
+	// ErrAlertRecipientNotFound is returned when low stock alert recipient does not exist
+	ErrAlertRecipientNotFound = errors.New("low stock alert recipient not found")
+
+	// ErrAlertRecipientExists is returned when the target is already registered for the warehouse + channel + mode
+	ErrAlertRecipientExists = errors.New("low stock alert recipient already registered")
 )
 
 // ===================================
@@ -98,7 +182,15 @@ func IsValidationError(err error) bool {
 	return errors.Is(err, ErrInvalidWarehouseLocation) ||
 		errors.Is(err, ErrInvalidQuantity) ||
 		errors.Is(err, ErrReservedExceedsQuantity) ||
-		errors.Is(err, ErrCannotDeleteNonEmptyInventory)
+		errors.Is(err, ErrCannotDeleteNonEmptyInventory) ||
+		errors.Is(err, ErrInvalidAdjustmentCategory) ||
+		errors.Is(err, ErrWriteOffRequiresDecrease) ||
+		errors.Is(err, ErrInvalidReportRange) ||
+		errors.Is(err, ErrInvalidRTV) ||
+		errors.Is(err, ErrInvalidCreditNote) ||
+		errors.Is(err, ErrInvalidSerial) ||
+		errors.Is(err, ErrInvalidTransfer) ||
+		errors.Is(err, ErrInvalidForecastRequest)
 }
 
 // NewInsufficientStockError creates error with stock details
@@ -121,3 +213,25 @@ func IsMovementError(err error) bool {
 	return errors.Is(err, ErrInvalidMovementType) ||
 		errors.Is(err, ErrInvalidAdjustmentQuantity)
 }
+
+// IsAdjustmentApprovalError checks if error is about the adjustment approval workflow
+func IsAdjustmentApprovalError(err error) bool {
+	return errors.Is(err, ErrAdjustmentNotPending) ||
+		errors.Is(err, ErrAdjustmentSelfApproval) ||
+		errors.Is(err, ErrAdjustmentAlreadyPending)
+}
+
+// IsRTVConflictError checks if error is a supplier return state conflict
+func IsRTVConflictError(err error) bool {
+	return errors.Is(err, ErrRTVClosed) ||
+		errors.Is(err, ErrRTVNotClosable) ||
+		errors.Is(err, ErrCreditNoteExists)
+}
+
+// IsSerialConflictError checks if error is a serial unit state conflict
+func IsSerialConflictError(err error) bool {
+	return errors.Is(err, ErrSerialExists) ||
+		errors.Is(err, ErrSerialNotAvailable) ||
+		errors.Is(err, ErrSerialOverAssign) ||
+		errors.Is(err, ErrOrderNotPickable)
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// DEMAND FORECAST (dự báo nhu cầu) + REPLENISHMENT SUGGESTIONS
+// ========================================
+// Job hàng tuần fit model cho từng (sách, kho) từ order_items, lưu demand_forecasts.
+// API đọc dự báo N tuần tới + gợi ý nhập hàng dựa trên cận trên khoảng tin cậy.
+
+// Forecast models
+const (
+	ForecastModelSeasonal = "seasonal" // mức nền × chỉ số mùa vụ theo tháng (đủ 52 tuần lịch sử)
+	ForecastModelLevel    = "level"    // chỉ mức nền (lịch sử ngắn, chưa thấy đủ 1 năm)
+)
+
+// Forecast parameters
+const (
+	ForecastHistoryWeeks     = 104 // lịch sử tối đa dùng để fit (2 năm)
+	ForecastMinHistoryWeeks  = 4   // chuỗi ngắn hơn (sách mới bán) không dự báo
+	ForecastSeasonalMinWeeks = 52  // cần thấy mỗi tháng ít nhất 1 lần mới tính mùa vụ
+	ForecastHorizonWeeks     = 12  // số tuần job dự báo trước = weeks tối đa của API
+	ForecastDefaultWeeks     = 4
+	ForecastSmoothingAlpha   = 0.3  // trọng số tuần gần nhất trong mức nền
+	ForecastZ95              = 1.96 // khoảng tin cậy 95%
+)
+
+// WeeklyDemand số lượng bán 1 tuần của 1 (sách, kho), từ order_items
+type WeeklyDemand struct {
+	BookID      uuid.UUID
+	WarehouseID uuid.UUID
+	WeekStart   time.Time
+	Quantity    int
+}
+
+// DemandForecast represents demand_forecasts table
+type DemandForecast struct {
+	BookID       uuid.UUID `json:"book_id"`
+	WarehouseID  uuid.UUID `json:"warehouse_id"`
+	WeekStart    time.Time `json:"week_start"`
+	Predicted    float64   `json:"predicted"`
+	LowerBound   float64   `json:"lower_bound"`
+	UpperBound   float64   `json:"upper_bound"`
+	Model        string    `json:"model"`
+	HistoryWeeks int       `json:"history_weeks"`
+	ComputedAt   time.Time `json:"computed_at"`
+}
+
+// ForecastRunStats kết quả 1 lần chạy job
+type ForecastRunStats struct {
+	Series     int       `json:"series"`  // số (sách, kho) được dự báo
+	Skipped    int       `json:"skipped"` // chuỗi quá ngắn
+	Forecasts  int64     `json:"forecasts"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// WeekStartOf thứ 2 (UTC) của tuần chứa t, khớp date_trunc('week', ...) của Postgres
+func WeekStartOf(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7 // Monday = 0
+	return day.AddDate(0, 0, -offset)
+}
+
+// ========================================
+// REQUESTS / RESPONSES
+// ========================================
+
+// DemandForecastRequest - dự báo N tuần tới của 1 sách (mọi kho, hoặc 1 kho)
+type DemandForecastRequest struct {
+	BookID      uuid.UUID  `form:"book_id" binding:"required"`
+	WarehouseID *uuid.UUID `form:"warehouse_id"`
+	Weeks       int        `form:"weeks"`
+
+	// Set by handler from warehouse scope (staff), nil = all warehouses
+	ScopeWarehouseIDs []uuid.UUID `form:"-"`
+}
+
+func (r *DemandForecastRequest) Validate() error {
+	return validateForecastWeeks(&r.Weeks)
+}
+
+// ReplenishmentRequest - gợi ý nhập hàng cho N tuần tới (mọi kho trong phạm vi, hoặc 1 kho)
+type ReplenishmentRequest struct {
+	WarehouseID *uuid.UUID `form:"warehouse_id"`
+	Weeks       int        `form:"weeks"`
+
+	// Set by handler from warehouse scope (staff), nil = all warehouses
+	ScopeWarehouseIDs []uuid.UUID `form:"-"`
+}
+
+func (r *ReplenishmentRequest) Validate() error {
+	return validateForecastWeeks(&r.Weeks)
+}
+
+func validateForecastWeeks(weeks *int) error {
+	if *weeks == 0 {
+		*weeks = ForecastDefaultWeeks
+	}
+	if *weeks < 1 || *weeks > ForecastHorizonWeeks {
+		return fmt.Errorf("%w: weeks must be between 1 and %d", ErrInvalidForecastRequest, ForecastHorizonWeeks)
+	}
+	return nil
+}
+
+// WeekForecast 1 tuần trong response
+type WeekForecast struct {
+	WeekStart  string  `json:"week_start"` // YYYY-MM-DD (thứ 2)
+	Predicted  float64 `json:"predicted"`
+	LowerBound float64 `json:"lower_bound"`
+	UpperBound float64 `json:"upper_bound"`
+}
+
+// WarehouseForecast dự báo của 1 sách tại 1 kho
+// Total*: cộng dồn cả horizon, khoảng tin cậy gộp theo phương sai (không cộng thẳng cận trên từng tuần)
+type WarehouseForecast struct {
+	WarehouseID    uuid.UUID      `json:"warehouse_id"`
+	WarehouseName  string         `json:"warehouse_name"`
+	Model          string         `json:"model"`
+	HistoryWeeks   int            `json:"history_weeks"`
+	TotalPredicted float64        `json:"total_predicted"`
+	TotalLower     float64        `json:"total_lower"`
+	TotalUpper     float64        `json:"total_upper"`
+	Weeks          []WeekForecast `json:"weeks"`
+}
+
+type DemandForecastResponse struct {
+	BookID     uuid.UUID           `json:"book_id"`
+	Weeks      int                 `json:"weeks"`
+	ComputedAt *time.Time          `json:"computed_at,omitempty"` // nil = chưa có dự báo (job chưa chạy / sách chưa đủ lịch sử)
+	Warehouses []WarehouseForecast `json:"warehouses"`
+}
+
+// ForecastRow 1 tuần dự báo kèm tên kho (join, chỉ đọc)
+type ForecastRow struct {
+	DemandForecast
+	WarehouseName string
+}
+
+// ReplenishmentInput tồn hiện tại + dự báo cộng dồn horizon của 1 (sách, kho), đọc từ repository
+type ReplenishmentInput struct {
+	BookID        uuid.UUID
+	BookTitle     string
+	WarehouseID   uuid.UUID
+	WarehouseName string
+	Available     int // quantity - reserved (0 khi kho chưa có dòng tồn)
+	InTransit     int // đang chuyển tới kho (phiếu chuyển kho in_transit)
+	SafetyStock   int // override của sách, không có thì mức mặc định của kho
+	Predicted     float64
+	// Variance tổng phương sai các tuần ((upper - predicted) / z)^2, để gộp khoảng tin cậy
+	Variance float64
+}
+
+// ReplenishmentSuggestion số lượng nên nhập để đủ bán tới hết horizon (theo cận trên) + giữ safety stock
+// SuggestedQuantity = ceil(demand_upper + safety_stock - available - in_transit), chỉ trả khi > 0
+type ReplenishmentSuggestion struct {
+	BookID            uuid.UUID `json:"book_id"`
+	BookTitle         string    `json:"book_title"`
+	WarehouseID       uuid.UUID `json:"warehouse_id"`
+	WarehouseName     string    `json:"warehouse_name"`
+	Available         int       `json:"available"`
+	InTransit         int       `json:"in_transit"`
+	SafetyStock       int       `json:"safety_stock"`
+	DemandPredicted   float64   `json:"demand_predicted"`
+	DemandLower       float64   `json:"demand_lower"`
+	DemandUpper       float64   `json:"demand_upper"`
+	SuggestedQuantity int       `json:"suggested_quantity"`
+	// StockoutLikely tồn hiện có không đủ cả mức dự báo trung tâm (không chỉ cận trên)
+	StockoutLikely bool `json:"stockout_likely"`
+}
+
+type ReplenishmentResponse struct {
+	Weeks       int                       `json:"weeks"`
+	Suggestions []ReplenishmentSuggestion `json:"suggestions"`
+}
@@ -15,6 +15,14 @@ type NearestWarehouse struct {
 	DistanceKM        float64   `json:"distance_km"`
 }
 
+// AvailabilityRow 1 dòng kết quả batch availability: (item, warehouse, available)
+// Tên kho/toạ độ/trạng thái active lấy từ warehouse cache ở service layer
+type AvailabilityRow struct {
+	ItemIndex   int // vị trí item trong request (0-based)
+	WarehouseID uuid.UUID
+	Available   int
+}
+
 type TotalStockResponse struct {
 	BookID         uuid.UUID `json:"book_id"`
 	TotalQuantity  int       `json:"total_quantity"`
@@ -68,6 +76,7 @@ type Inventory struct {
 	LastRestockAt *time.Time `json:"last_restocked_at,omitempty" db:"last_restocked_at"`
 	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 
 	// Computed fields (not in DB)
 	AvailableQuantity int    `json:"available" db:"-"`
@@ -112,14 +121,112 @@ type AuditLogEntry struct {
 	ID             uuid.UUID  `json:"id" db:"id"`
 	WarehouseID    uuid.UUID  `json:"warehouse_id" db:"warehouse_id"`
 	BookID         uuid.UUID  `json:"book_id" db:"book_id"`
-	Action         string     `json:"action" db:"action"` // RESTOCK, RESERVE, RELEASE, ADJUSTMENT, SALE
+	Action         string     `json:"action" db:"action"` // RESTOCK, RESERVE, RELEASE, ADJUSTMENT, SALE, ADJUSTMENT_*
 	OldQuantity    int        `json:"old_quantity" db:"old_quantity"`
 	NewQuantity    int        `json:"new_quantity" db:"new_quantity"`
 	OldReserved    int        `json:"old_reserved" db:"old_reserved"`
 	NewReserved    int        `json:"new_reserved" db:"new_reserved"`
 	QuantityChange int        `json:"quantity_change" db:"quantity_change"`
 	Reason         *string    `json:"reason,omitempty" db:"reason"`
+	ReasonCategory *string    `json:"reason_category,omitempty" db:"reason_category"` // chỉ có với ADJUSTMENT*
 	ChangedBy      *uuid.UUID `json:"changed_by,omitempty" db:"changed_by"`
 	IPAddress      *string    `json:"ip_address,omitempty" db:"ip_address"`
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
 }
+
+// InventoryEvent - 1 thay đổi tồn kho được stream cho admin UI (payload NOTIFY inventory_events)
+// Event: action của audit log (RESTOCK, RESERVE, RELEASE, SALE, ADJUSTMENT*) hoặc LOW_STOCK_ALERT
+type InventoryEvent struct {
+	Event          string     `json:"event"`
+	WarehouseID    uuid.UUID  `json:"warehouse_id"`
+	BookID         uuid.UUID  `json:"book_id"`
+	OldQuantity    int        `json:"old_quantity"`
+	NewQuantity    int        `json:"new_quantity"`
+	OldReserved    int        `json:"old_reserved"`
+	NewReserved    int        `json:"new_reserved"`
+	QuantityChange int        `json:"quantity_change"`
+	AlertThreshold *int       `json:"alert_threshold,omitempty"` // chỉ có với LOW_STOCK_ALERT
+	ChangedBy      *uuid.UUID `json:"changed_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+const EventLowStockAlert = "LOW_STOCK_ALERT"
+
+// Adjustment request statuses
+const (
+	AdjustmentStatusPending  = "pending"
+	AdjustmentStatusApproved = "approved"
+	AdjustmentStatusRejected = "rejected"
+)
+
+// Audit actions of the adjustment approval workflow
+const (
+	AuditActionAdjustmentRequested = "ADJUSTMENT_REQUESTED"
+	AuditActionAdjustmentApproved  = "ADJUSTMENT_APPROVED"
+	AuditActionAdjustmentRejected  = "ADJUSTMENT_REJECTED"
+)
+
+// Adjustment reason categories (reason vẫn là ghi chú chi tiết)
+const (
+	AdjustmentCategoryDamaged = "damaged" // rách, ướt, móp
+	AdjustmentCategoryLost    = "lost"    // thất lạc, mất trong kho
+	AdjustmentCategoryExpired = "expired" // hết hạn dùng (lịch, đề thi, ấn phẩm theo năm)
+	AdjustmentCategorySample  = "sample"  // xuất làm mẫu / tặng
+	AdjustmentCategoryRecount = "recount" // kiểm kê lệch sổ (tăng hoặc giảm)
+	AdjustmentCategoryOther   = "other"
+)
+
+var AdjustmentCategories = []string{
+	AdjustmentCategoryDamaged,
+	AdjustmentCategoryLost,
+	AdjustmentCategoryExpired,
+	AdjustmentCategorySample,
+	AdjustmentCategoryRecount,
+	AdjustmentCategoryOther,
+}
+
+// WriteOffCategories - category tính là hao hụt (chỉ đi với adjustment giảm tồn)
+var WriteOffCategories = []string{
+	AdjustmentCategoryDamaged,
+	AdjustmentCategoryLost,
+	AdjustmentCategoryExpired,
+	AdjustmentCategorySample,
+}
+
+// IsValidAdjustmentCategory checks adjustment reason category
+func IsValidAdjustmentCategory(category string) bool {
+	return containsString(AdjustmentCategories, category)
+}
+
+// IsWriteOffCategory checks if category is a write-off (damaged, lost, expired, sample)
+func IsWriteOffCategory(category string) bool {
+	return containsString(WriteOffCategories, category)
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// AdjustmentRequest represents inventory_adjustment_requests table
+// Large adjustments wait here until a second admin approves
+type AdjustmentRequest struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	WarehouseID     uuid.UUID  `json:"warehouse_id" db:"warehouse_id"`
+	BookID          uuid.UUID  `json:"book_id" db:"book_id"`
+	OldQuantity     int        `json:"old_quantity" db:"old_quantity"`
+	NewQuantity     int        `json:"new_quantity" db:"new_quantity"`
+	ExpectedVersion int        `json:"expected_version" db:"expected_version"`
+	Reason          string     `json:"reason" db:"reason"`
+	ReasonCategory  string     `json:"reason_category" db:"reason_category"`
+	Status          string     `json:"status" db:"status"` // pending, approved, rejected
+	RequestedBy     uuid.UUID  `json:"requested_by" db:"requested_by"`
+	ReviewedBy      *uuid.UUID `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNote      *string    `json:"review_note,omitempty" db:"review_note"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
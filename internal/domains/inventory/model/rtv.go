@@ -0,0 +1,279 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ========================================
+// SUPPLIER RETURN (RTV - return to vendor)
+// ========================================
+
+// Nhà cung cấp = nhà xuất bản (books.publisher_id)
+
+// Audit action khi trừ kho trả NCC (không tính là write-off)
+const AuditActionRTV = "RTV"
+
+// RTV statuses
+const (
+	RTVStatusOpen              = "open"               // đã trừ kho, chờ credit note
+	RTVStatusPartiallyCredited = "partially_credited" // NCC mới trả 1 phần
+	RTVStatusCredited          = "credited"           // credit note đủ giá trị phiếu
+	RTVStatusClosed            = "closed"             // đóng tay (NCC không trả phần còn lại)
+)
+
+var RTVStatuses = []string{
+	RTVStatusOpen,
+	RTVStatusPartiallyCredited,
+	RTVStatusCredited,
+	RTVStatusClosed,
+}
+
+// RTV item conditions
+const (
+	RTVConditionDamaged = "damaged" // hỏng, lỗi in
+	RTVConditionExcess  = "excess"  // tồn dư, NCC nhận lại
+)
+
+// Limits
+const (
+	RTVMaxItems               = 200
+	RTVMaxNoteLength          = 1000
+	RTVMaxCreditNoteNumLength = 50
+	RTVMinCloseNoteLength     = 10
+	RTVReportDefaultDays      = 30
+	RTVReportMaxRangeDays     = 366
+)
+
+// SupplierReturn represents supplier_returns table
+type SupplierReturn struct {
+	ID             uuid.UUID       `json:"id"`
+	RTVNumber      string          `json:"rtv_number"`
+	WarehouseID    uuid.UUID       `json:"warehouse_id"`
+	WarehouseName  string          `json:"warehouse_name,omitempty"` // join, chỉ đọc
+	SupplierID     uuid.UUID       `json:"supplier_id"`
+	SupplierName   string          `json:"supplier_name,omitempty"` // join, chỉ đọc
+	Status         string          `json:"status"`
+	TotalQuantity  int             `json:"total_quantity"`
+	TotalValue     decimal.Decimal `json:"total_value"`
+	CreditedAmount decimal.Decimal `json:"credited_amount"`
+	Note           *string         `json:"note,omitempty"`
+	CloseNote      *string         `json:"close_note,omitempty"`
+	CreatedBy      uuid.UUID       `json:"created_by"`
+	ClosedBy       *uuid.UUID      `json:"closed_by,omitempty"`
+	ClosedAt       *time.Time      `json:"closed_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+
+	Items       []SupplierReturnItem `json:"items,omitempty"`
+	CreditNotes []SupplierCreditNote `json:"credit_notes,omitempty"`
+}
+
+// Outstanding giá trị NCC còn nợ (0 nếu đã đủ / vượt)
+func (r *SupplierReturn) Outstanding() decimal.Decimal {
+	outstanding := r.TotalValue.Sub(r.CreditedAmount)
+	if outstanding.IsNegative() {
+		return decimal.Zero
+	}
+	return outstanding
+}
+
+// AcceptsCreditNotes phiếu còn nhận credit note (chưa đóng tay)
+func (r *SupplierReturn) AcceptsCreditNotes() bool {
+	return r.Status != RTVStatusClosed
+}
+
+// StatusForCredited trạng thái theo số tiền đã được credit
+func (r *SupplierReturn) StatusForCredited(credited decimal.Decimal) string {
+	switch {
+	case credited.GreaterThanOrEqual(r.TotalValue):
+		return RTVStatusCredited
+	case credited.IsPositive():
+		return RTVStatusPartiallyCredited
+	default:
+		return RTVStatusOpen
+	}
+}
+
+// SupplierReturnItem represents supplier_return_items table
+type SupplierReturnItem struct {
+	ID        uuid.UUID       `json:"id"`
+	ReturnID  uuid.UUID       `json:"return_id"`
+	BookID    uuid.UUID       `json:"book_id"`
+	BookTitle string          `json:"book_title,omitempty"` // join, chỉ đọc
+	Quantity  int             `json:"quantity"`
+	UnitCost  decimal.Decimal `json:"unit_cost"`
+	Condition string          `json:"condition"`
+}
+
+// SupplierCreditNote represents supplier_credit_notes table
+type SupplierCreditNote struct {
+	ID               uuid.UUID       `json:"id"`
+	ReturnID         uuid.UUID       `json:"return_id"`
+	CreditNoteNumber string          `json:"credit_note_number"`
+	Amount           decimal.Decimal `json:"amount"`
+	IssuedDate       time.Time       `json:"issued_date"`
+	Note             *string         `json:"note,omitempty"`
+	RecordedBy       uuid.UUID       `json:"recorded_by"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+// RTVBookInfo - sách chọn trả: NCC + giá vốn + tồn khả dụng tại kho
+type RTVBookInfo struct {
+	BookID       uuid.UUID
+	Title        string
+	SupplierID   *uuid.UUID
+	CostPrice    *decimal.Decimal
+	Available    int  // quantity - reserved tại kho
+	HasInventory bool // có dòng warehouse_inventory tại kho
+}
+
+// ========================================
+// REQUESTS
+// ========================================
+
+type CreateRTVItemRequest struct {
+	BookID    uuid.UUID `json:"book_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,gte=1"`
+	Condition string    `json:"condition" validate:"required,oneof=damaged excess"`
+}
+
+type CreateRTVRequest struct {
+	WarehouseID uuid.UUID              `json:"warehouse_id" validate:"required"`
+	SupplierID  uuid.UUID              `json:"supplier_id" validate:"required"`
+	Items       []CreateRTVItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+	Note        *string                `json:"note,omitempty"`
+	CreatedBy   uuid.UUID              `json:"-"`
+}
+
+// Validate kiểm tra items (không trùng sách, condition hợp lệ)
+func (r *CreateRTVRequest) Validate() error {
+	if r.WarehouseID == uuid.Nil || r.SupplierID == uuid.Nil {
+		return fmt.Errorf("%w: warehouse_id and supplier_id are required", ErrInvalidRTV)
+	}
+	if len(r.Items) == 0 || len(r.Items) > RTVMaxItems {
+		return fmt.Errorf("%w: items must contain 1-%d books", ErrInvalidRTV, RTVMaxItems)
+	}
+	if r.Note != nil && len(*r.Note) > RTVMaxNoteLength {
+		return fmt.Errorf("%w: note must not exceed %d characters", ErrInvalidRTV, RTVMaxNoteLength)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(r.Items))
+	for _, item := range r.Items {
+		if item.BookID == uuid.Nil || item.Quantity < 1 {
+			return fmt.Errorf("%w: each item needs book_id and quantity >= 1", ErrInvalidRTV)
+		}
+		if item.Condition != RTVConditionDamaged && item.Condition != RTVConditionExcess {
+			return fmt.Errorf("%w: condition must be damaged or excess", ErrInvalidRTV)
+		}
+		if seen[item.BookID] {
+			return fmt.Errorf("%w: book %s listed more than once", ErrInvalidRTV, item.BookID)
+		}
+		seen[item.BookID] = true
+	}
+	return nil
+}
+
+type RecordCreditNoteRequest struct {
+	CreditNoteNumber string          `json:"credit_note_number" validate:"required,max=50"`
+	Amount           decimal.Decimal `json:"amount" validate:"required"`
+	IssuedDate       string          `json:"issued_date" validate:"required"` // YYYY-MM-DD
+	Note             *string         `json:"note,omitempty"`
+	RecordedBy       uuid.UUID       `json:"-"`
+}
+
+// ParseIssuedDate validate + parse ngày trên chứng từ NCC
+func (r *RecordCreditNoteRequest) ParseIssuedDate() (time.Time, error) {
+	if r.CreditNoteNumber == "" || len(r.CreditNoteNumber) > RTVMaxCreditNoteNumLength {
+		return time.Time{}, fmt.Errorf("%w: credit_note_number is required (max %d characters)", ErrInvalidCreditNote, RTVMaxCreditNoteNumLength)
+	}
+	if !r.Amount.IsPositive() {
+		return time.Time{}, fmt.Errorf("%w: amount must be positive", ErrInvalidCreditNote)
+	}
+	issued, err := time.Parse("2006-01-02", r.IssuedDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: issued_date must be YYYY-MM-DD", ErrInvalidCreditNote)
+	}
+	if issued.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%w: issued_date cannot be in the future", ErrInvalidCreditNote)
+	}
+	return issued, nil
+}
+
+type CloseRTVRequest struct {
+	Note     string    `json:"note" validate:"required,min=10"` // lý do NCC không trả phần còn lại
+	ClosedBy uuid.UUID `json:"-"`
+}
+
+type ListRTVRequest struct {
+	Status      string     `form:"status"`
+	SupplierID  *uuid.UUID `form:"supplier_id"`
+	WarehouseID *uuid.UUID `form:"warehouse_id"`
+	Page        int        `form:"page"`
+	Limit       int        `form:"limit"`
+}
+
+// RTVReportRequest - tổng RTV theo NCC trong khoảng ngày tạo phiếu (mặc định 30 ngày gần nhất)
+type RTVReportRequest struct {
+	FromDate *time.Time `form:"from_date" time_format:"2006-01-02"`
+	ToDate   *time.Time `form:"to_date" time_format:"2006-01-02"`
+}
+
+// Validate điền khoảng ngày mặc định + giới hạn độ dài khoảng
+func (r *RTVReportRequest) Validate() error {
+	now := time.Now()
+	if r.ToDate == nil {
+		r.ToDate = &now
+	}
+	if r.FromDate == nil {
+		from := r.ToDate.AddDate(0, 0, -RTVReportDefaultDays)
+		r.FromDate = &from
+	}
+	if r.FromDate.After(*r.ToDate) {
+		return fmt.Errorf("%w: from_date must be before to_date", ErrInvalidReportRange)
+	}
+	if r.ToDate.Sub(*r.FromDate) > time.Duration(RTVReportMaxRangeDays)*24*time.Hour {
+		return fmt.Errorf("%w: date range must not exceed %d days", ErrInvalidReportRange, RTVReportMaxRangeDays)
+	}
+	return nil
+}
+
+// ========================================
+// RESPONSES
+// ========================================
+
+type ListRTVResponse struct {
+	Items      []SupplierReturn `json:"items"`
+	TotalItems int              `json:"total_items"`
+	TotalPages int              `json:"total_pages"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+}
+
+// SupplierRTVStat - tổng RTV của 1 NCC
+// Outstanding chỉ tính phiếu chưa đóng (phiếu closed là NCC đã từ chối phần còn lại)
+type SupplierRTVStat struct {
+	SupplierID     uuid.UUID       `json:"supplier_id"`
+	SupplierName   string          `json:"supplier_name"`
+	Returns        int             `json:"returns"`
+	Quantity       int             `json:"quantity"`
+	DamagedQty     int             `json:"damaged_quantity"`
+	ExcessQty      int             `json:"excess_quantity"`
+	TotalValue     decimal.Decimal `json:"total_value"`
+	CreditedAmount decimal.Decimal `json:"credited_amount"`
+	Outstanding    decimal.Decimal `json:"outstanding"`
+}
+
+type RTVReportResponse struct {
+	FromDate       time.Time         `json:"from_date"`
+	ToDate         time.Time         `json:"to_date"`
+	Returns        int               `json:"returns"`
+	Quantity       int               `json:"quantity"`
+	TotalValue     decimal.Decimal   `json:"total_value"`
+	CreditedAmount decimal.Decimal   `json:"credited_amount"`
+	Outstanding    decimal.Decimal   `json:"outstanding"`
+	Suppliers      []SupplierRTVStat `json:"suppliers"`
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// SERIAL / LOT TRACKING (bản giới hạn, có chữ ký)
+// ========================================
+
+// Tracking là tuỳ chọn: chỉ sách nhập kho kèm serial mới có unit,
+// số lượng tồn vẫn nằm ở warehouse_inventory
+
+// Serial unit statuses
+const (
+	SerialStatusInStock  = "in_stock" // còn trong kho
+	SerialStatusAssigned = "assigned" // đã gán cho order item lúc soạn hàng
+)
+
+var SerialStatuses = []string{
+	SerialStatusInStock,
+	SerialStatusAssigned,
+}
+
+// Limits
+const (
+	SerialMaxLength     = 64
+	LotMaxLength        = 64
+	SerialMaxPerRequest = 500
+)
+
+// Đơn chỉ được gán / gỡ serial khi đang soạn hàng (chưa bàn giao vận chuyển)
+var SerialPickableOrderStatuses = []string{"confirmed", "processing"}
+
+// SerialUnit represents inventory_serial_units table
+type SerialUnit struct {
+	ID            uuid.UUID  `json:"id"`
+	SerialNumber  string     `json:"serial_number"`
+	LotNumber     *string    `json:"lot_number,omitempty"`
+	BookID        uuid.UUID  `json:"book_id"`
+	BookTitle     string     `json:"book_title,omitempty"` // join, chỉ đọc
+	WarehouseID   uuid.UUID  `json:"warehouse_id"`
+	WarehouseName string     `json:"warehouse_name,omitempty"` // join, chỉ đọc
+	Status        string     `json:"status"`
+	OrderID       *uuid.UUID `json:"order_id,omitempty"`
+	OrderNumber   *string    `json:"order_number,omitempty"` // join, chỉ đọc
+	OrderItemID   *uuid.UUID `json:"order_item_id,omitempty"`
+	ReceivedBy    *uuid.UUID `json:"received_by,omitempty"`
+	ReceivedAt    time.Time  `json:"received_at"`
+	AssignedBy    *uuid.UUID `json:"assigned_by,omitempty"`
+	AssignedAt    *time.Time `json:"assigned_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// NormalizeSerials trim + kiểm tra độ dài, trùng lặp (không phân biệt hoa thường)
+func NormalizeSerials(serials []string) ([]string, error) {
+	if len(serials) > SerialMaxPerRequest {
+		return nil, fmt.Errorf("%w: at most %d serial numbers per request", ErrInvalidSerial, SerialMaxPerRequest)
+	}
+
+	seen := make(map[string]struct{}, len(serials))
+	result := make([]string, 0, len(serials))
+	for _, s := range serials {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil, fmt.Errorf("%w: serial number must not be empty", ErrInvalidSerial)
+		}
+		if len(s) > SerialMaxLength {
+			return nil, fmt.Errorf("%w: serial number %q exceeds %d characters", ErrInvalidSerial, s, SerialMaxLength)
+		}
+		key := strings.ToUpper(s)
+		if _, dup := seen[key]; dup {
+			return nil, fmt.Errorf("%w: duplicate serial number %q", ErrInvalidSerial, s)
+		}
+		seen[key] = struct{}{}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// NormalizeLotNumber trim lot number, rỗng = không ghi lot
+func NormalizeLotNumber(lot *string) (*string, error) {
+	if lot == nil {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*lot)
+	if v == "" {
+		return nil, nil
+	}
+	if len(v) > LotMaxLength {
+		return nil, fmt.Errorf("%w: lot number exceeds %d characters", ErrInvalidSerial, LotMaxLength)
+	}
+	return &v, nil
+}
+
+// ========================================
+// REQUESTS
+// ========================================
+
+// AssignSerialsRequest - gán serial cho 1 order item lúc soạn hàng
+type AssignSerialsRequest struct {
+	SerialNumbers []string  `json:"serial_numbers" binding:"required,min=1"`
+	AssignedBy    uuid.UUID `json:"-"`
+
+	// Warehouse scope của staff (nil = admin, không giới hạn)
+	ScopeWarehouseIDs []uuid.UUID `json:"-"`
+}
+
+// SerialOrderItem - order item đang soạn (đọc từ order_items + orders)
+type SerialOrderItem struct {
+	OrderID     uuid.UUID
+	OrderItemID uuid.UUID
+	BookID      uuid.UUID
+	Quantity    int
+	WarehouseID *uuid.UUID // nil = đơn cũ chưa gán kho
+	OrderStatus string
+	Assigned    int // số serial đã gán
+}
+
+// ListSerialsRequest - GET /inventories/serials
+type ListSerialsRequest struct {
+	WarehouseID *uuid.UUID `form:"warehouse_id"`
+	BookID      *uuid.UUID `form:"book_id"`
+	OrderID     *uuid.UUID `form:"order_id"`
+	LotNumber   string     `form:"lot_number"`
+	Status      string     `form:"status"`
+	Page        int        `form:"page"`
+	Limit       int        `form:"limit"`
+
+	// Warehouse scope của staff (nil = admin, không giới hạn)
+	ScopeWarehouseIDs []uuid.UUID `form:"-"`
+}
+
+// ========================================
+// RESPONSES
+// ========================================
+
+type ListSerialsResponse struct {
+	Items      []SerialUnit `json:"items"`
+	TotalItems int          `json:"total_items"`
+	TotalPages int          `json:"total_pages"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+}
+
+// SerialVerification - tra cứu công khai để xác thực bản giới hạn (không lộ thông tin đơn / khách)
+type SerialVerification struct {
+	SerialNumber string     `json:"serial_number"`
+	Authentic    bool       `json:"authentic"`
+	BookID       *uuid.UUID `json:"book_id,omitempty"`
+	BookTitle    *string    `json:"book_title,omitempty"`
+	LotNumber    *string    `json:"lot_number,omitempty"`
+	ReceivedAt   *time.Time `json:"received_at,omitempty"`
+	Sold         bool       `json:"sold"`
+}
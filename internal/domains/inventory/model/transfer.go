@@ -0,0 +1,155 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// STOCK TRANSFER (chuyển kho)
+// ========================================
+
+// Audit action: trừ kho nguồn lúc xuất / cộng kho đích lúc nhận (hoàn kho nguồn khi huỷ phiếu đang chuyển)
+const (
+	AuditActionTransferOut = "TRANSFER_OUT"
+	AuditActionTransferIn  = "TRANSFER_IN"
+)
+
+// Transfer statuses
+const (
+	TransferStatusPending   = "pending"    // đã tạo phiếu, chưa xuất kho
+	TransferStatusInTransit = "in_transit" // đã trừ kho nguồn, hàng đang trên đường
+	TransferStatusReceived  = "received"   // kho đích đã nhận, đã cộng tồn
+	TransferStatusCancelled = "cancelled"
+)
+
+var TransferStatuses = []string{
+	TransferStatusPending,
+	TransferStatusInTransit,
+	TransferStatusReceived,
+	TransferStatusCancelled,
+}
+
+// Hướng chuyển khi xem lịch sử theo 1 kho
+const (
+	TransferDirectionOut = "out" // kho là nguồn
+	TransferDirectionIn  = "in"  // kho là đích
+)
+
+// Limits
+const (
+	TransferMaxItems           = 200
+	TransferMaxNoteLength      = 1000
+	TransferMinCancelReasonLen = 5
+	TransferMaxCancelReasonLen = 500
+)
+
+// StockTransfer represents stock_transfers table
+type StockTransfer struct {
+	ID                       uuid.UUID  `json:"id"`
+	TransferNumber           string     `json:"transfer_number"`
+	SourceWarehouseID        uuid.UUID  `json:"source_warehouse_id"`
+	SourceWarehouseName      string     `json:"source_warehouse_name,omitempty"` // join, chỉ đọc
+	DestinationWarehouseID   uuid.UUID  `json:"destination_warehouse_id"`
+	DestinationWarehouseName string     `json:"destination_warehouse_name,omitempty"` // join, chỉ đọc
+	Status                   string     `json:"status"`
+	TotalQuantity            int        `json:"total_quantity"`
+	Note                     *string    `json:"note,omitempty"`
+	CancelReason             *string    `json:"cancel_reason,omitempty"`
+	CreatedBy                uuid.UUID  `json:"created_by"`
+	ShippedBy                *uuid.UUID `json:"shipped_by,omitempty"`
+	ShippedAt                *time.Time `json:"shipped_at,omitempty"`
+	ReceivedBy               *uuid.UUID `json:"received_by,omitempty"`
+	ReceivedAt               *time.Time `json:"received_at,omitempty"`
+	CancelledBy              *uuid.UUID `json:"cancelled_by,omitempty"`
+	CancelledAt              *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt                time.Time  `json:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at"`
+
+	Items []StockTransferItem `json:"items,omitempty"`
+}
+
+// StockTransferItem represents stock_transfer_items table
+type StockTransferItem struct {
+	ID         uuid.UUID `json:"id"`
+	TransferID uuid.UUID `json:"transfer_id"`
+	BookID     uuid.UUID `json:"book_id"`
+	BookTitle  string    `json:"book_title,omitempty"` // join, chỉ đọc
+	Quantity   int       `json:"quantity"`
+}
+
+// ========================================
+// REQUESTS
+// ========================================
+
+type CreateTransferItemRequest struct {
+	BookID   uuid.UUID `json:"book_id" validate:"required"`
+	Quantity int       `json:"quantity" validate:"required,gte=1"`
+}
+
+type CreateTransferRequest struct {
+	SourceWarehouseID      uuid.UUID                   `json:"source_warehouse_id" validate:"required"`
+	DestinationWarehouseID uuid.UUID                   `json:"destination_warehouse_id" validate:"required"`
+	Items                  []CreateTransferItemRequest `json:"items" validate:"required,min=1,max=200,dive"`
+	Note                   *string                     `json:"note,omitempty"`
+	CreatedBy              uuid.UUID                   `json:"-"`
+}
+
+// Validate kiểm tra 2 kho khác nhau, items không trùng sách
+func (r *CreateTransferRequest) Validate() error {
+	if r.SourceWarehouseID == uuid.Nil || r.DestinationWarehouseID == uuid.Nil {
+		return fmt.Errorf("%w: source_warehouse_id and destination_warehouse_id are required", ErrInvalidTransfer)
+	}
+	if r.SourceWarehouseID == r.DestinationWarehouseID {
+		return fmt.Errorf("%w: source and destination warehouse must differ", ErrInvalidTransfer)
+	}
+	if len(r.Items) == 0 || len(r.Items) > TransferMaxItems {
+		return fmt.Errorf("%w: items must contain 1-%d books", ErrInvalidTransfer, TransferMaxItems)
+	}
+	if r.Note != nil && len(*r.Note) > TransferMaxNoteLength {
+		return fmt.Errorf("%w: note must not exceed %d characters", ErrInvalidTransfer, TransferMaxNoteLength)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(r.Items))
+	for _, item := range r.Items {
+		if item.BookID == uuid.Nil || item.Quantity < 1 {
+			return fmt.Errorf("%w: each item needs book_id and quantity >= 1", ErrInvalidTransfer)
+		}
+		if seen[item.BookID] {
+			return fmt.Errorf("%w: book %s listed more than once", ErrInvalidTransfer, item.BookID)
+		}
+		seen[item.BookID] = true
+	}
+	return nil
+}
+
+type CancelTransferRequest struct {
+	Reason      string    `json:"reason" validate:"required,min=5,max=500"`
+	CancelledBy uuid.UUID `json:"-"`
+}
+
+// ListTransfersRequest - WarehouseID + Direction = lịch sử chuyển kho của 1 kho
+type ListTransfersRequest struct {
+	WarehouseID *uuid.UUID `form:"warehouse_id"`
+	Direction   string     `form:"direction"` // out | in, rỗng = cả 2 chiều
+	Status      string     `form:"status"`
+	Page        int        `form:"page"`
+	Limit       int        `form:"limit"`
+
+	// Staff kho: chỉ phiếu có kho nguồn hoặc đích thuộc phạm vi (handler điền từ middleware)
+	ScopeWarehouseIDs []uuid.UUID `form:"-"`
+}
+
+// ========================================
+// RESPONSES
+// ========================================
+
+type ListTransfersResponse struct {
+	Items      []StockTransfer `json:"items"`
+	TotalItems int             `json:"total_items"`
+	TotalPages int             `json:"total_pages"`
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const adjustmentRequestColumns = `
+	id, warehouse_id, book_id, old_quantity, new_quantity, expected_version,
+	reason, reason_category, status, requested_by, reviewed_by, review_note, reviewed_at, created_at
+`
+
+// CreateAdjustmentRequest implements Repository.CreateAdjustmentRequest
+func (r *postgresRepository) CreateAdjustmentRequest(ctx context.Context, req *model.AdjustmentRequest, reserved int) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	query := `
+		INSERT INTO inventory_adjustment_requests (
+			warehouse_id, book_id, old_quantity, new_quantity,
+			expected_version, reason, reason_category, status, requested_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, query,
+		req.WarehouseID,
+		req.BookID,
+		req.OldQuantity,
+		req.NewQuantity,
+		req.ExpectedVersion,
+		req.Reason,
+		req.ReasonCategory,
+		model.AdjustmentStatusPending,
+		req.RequestedBy,
+	).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // idx_adjustment_requests_pending_unique
+			return model.ErrAdjustmentAlreadyPending
+		}
+		return fmt.Errorf("failed to create adjustment request: %w", err)
+	}
+	req.Status = model.AdjustmentStatusPending
+
+	requestedBy := req.RequestedBy
+	if err := insertAdjustmentAudit(ctx, tx, req, model.AuditActionAdjustmentRequested, &requestedBy, &req.Reason, reserved); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetAdjustmentRequest implements Repository.GetAdjustmentRequest
+func (r *postgresRepository) GetAdjustmentRequest(ctx context.Context, id uuid.UUID) (*model.AdjustmentRequest, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + adjustmentRequestColumns + ` FROM inventory_adjustment_requests WHERE id = $1`
+
+	req, err := scanAdjustmentRequest(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrAdjustmentRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get adjustment request: %w", err)
+	}
+	return req, nil
+}
+
+// ListAdjustmentRequests implements Repository.ListAdjustmentRequests
+func (r *postgresRepository) ListAdjustmentRequests(ctx context.Context, status string, limit, offset int) ([]model.AdjustmentRequest, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM inventory_adjustment_requests WHERE status = $1"
+	if err := r.pool.QueryRow(ctx, countQuery, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count adjustment requests: %w", err)
+	}
+
+	query := `SELECT ` + adjustmentRequestColumns + `
+		FROM inventory_adjustment_requests
+		WHERE status = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.pool.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list adjustment requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := make([]model.AdjustmentRequest, 0)
+	for rows.Next() {
+		req, err := scanAdjustmentRequest(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan adjustment request: %w", err)
+		}
+		requests = append(requests, *req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating adjustment requests: %w", err)
+	}
+
+	return requests, total, nil
+}
+
+// ReviewAdjustmentRequest implements Repository.ReviewAdjustmentRequest
+func (r *postgresRepository) ReviewAdjustmentRequest(ctx context.Context, id uuid.UUID, status string, reviewedBy uuid.UUID, note *string, reserved int) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	req, err := claimAdjustmentRequest(ctx, tx, id, status, reviewedBy, note)
+	if err != nil {
+		return err
+	}
+	if err := insertReviewAudit(ctx, tx, req, status, reviewedBy, note, reserved); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ApproveAdjustmentRequest implements Repository.ApproveAdjustmentRequest
+func (r *postgresRepository) ApproveAdjustmentRequest(ctx context.Context, id uuid.UUID, inventory *model.Inventory, note *string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Claim request trước: approval chạy song song dừng ở đây (0 row), không ai apply lần 2
+	req, err := claimAdjustmentRequest(ctx, tx, id, model.AdjustmentStatusApproved, *inventory.UpdatedBy, note)
+	if err != nil {
+		return err
+	}
+
+	if err := r.adjustQuantityTx(ctx, tx, inventory, req.Reason, req.ReasonCategory); err != nil {
+		return err
+	}
+
+	// Ghi sau adjustQuantityTx: tag audit của trigger khớp theo created_at = NOW() của transaction
+	if err := insertReviewAudit(ctx, tx, req, model.AdjustmentStatusApproved, *inventory.UpdatedBy, note, inventory.Reserved); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// claimAdjustmentRequest chuyển request pending → approved/rejected
+// status = 'pending' guard: 2 admins review cùng lúc thì chỉ 1 người thắng
+func claimAdjustmentRequest(ctx context.Context, tx pgx.Tx, id uuid.UUID, status string, reviewedBy uuid.UUID, note *string) (*model.AdjustmentRequest, error) {
+	query := `
+		UPDATE inventory_adjustment_requests
+		SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + adjustmentRequestColumns
+
+	req, err := scanAdjustmentRequest(tx.QueryRow(ctx, query, id, status, reviewedBy, note))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrAdjustmentNotPending
+		}
+		return nil, fmt.Errorf("failed to review adjustment request: %w", err)
+	}
+	return req, nil
+}
+
+// insertReviewAudit ghi ADJUSTMENT_APPROVED / ADJUSTMENT_REJECTED (note của người duyệt thay lý do nếu có)
+func insertReviewAudit(ctx context.Context, tx pgx.Tx, req *model.AdjustmentRequest, status string, reviewedBy uuid.UUID, note *string, reserved int) error {
+	action := model.AuditActionAdjustmentRejected
+	if status == model.AdjustmentStatusApproved {
+		action = model.AuditActionAdjustmentApproved
+	}
+
+	reason := req.Reason
+	if note != nil && *note != "" {
+		reason = *note
+	}
+
+	return insertAdjustmentAudit(ctx, tx, req, action, &reviewedBy, &reason, reserved)
+}
+
+// insertAdjustmentAudit ghi audit entry cho approval workflow
+// Trigger chỉ log thay đổi thực tế trên warehouse_inventory, nên request/review phải ghi tay
+func insertAdjustmentAudit(ctx context.Context, tx pgx.Tx, req *model.AdjustmentRequest, action string, changedBy *uuid.UUID, reason *string, reserved int) error {
+	query := `
+		INSERT INTO inventory_audit_log (
+			warehouse_id, book_id, action,
+			old_quantity, new_quantity, old_reserved, new_reserved,
+			quantity_change, reason, reason_category, changed_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $6, $7, $8, $9, $10)
+	`
+	_, err := tx.Exec(ctx, query,
+		req.WarehouseID,
+		req.BookID,
+		action,
+		req.OldQuantity,
+		req.NewQuantity,
+		reserved,
+		req.NewQuantity-req.OldQuantity,
+		reason,
+		req.ReasonCategory,
+		changedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert adjustment audit log: %w", err)
+	}
+	return nil
+}
+
+func scanAdjustmentRequest(row pgx.Row) (*model.AdjustmentRequest, error) {
+	var req model.AdjustmentRequest
+	err := row.Scan(
+		&req.ID,
+		&req.WarehouseID,
+		&req.BookID,
+		&req.OldQuantity,
+		&req.NewQuantity,
+		&req.ExpectedVersion,
+		&req.Reason,
+		&req.ReasonCategory,
+		&req.Status,
+		&req.RequestedBy,
+		&req.ReviewedBy,
+		&req.ReviewNote,
+		&req.ReviewedAt,
+		&req.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const alertRecipientColumns = `
+	r.id, r.warehouse_id, COALESCE(w.name, ''), r.channel, r.target, r.mode,
+	r.is_active, r.created_by, r.created_at, r.updated_at
+`
+
+const alertRecipientFrom = `
+	FROM low_stock_alert_recipients r
+	LEFT JOIN warehouses w ON w.id = r.warehouse_id
+`
+
+// ClaimAlertsForNotify implements Repository.ClaimAlertsForNotify
+func (r *postgresRepository) ClaimAlertsForNotify(ctx context.Context, limit int) ([]model.AlertNotification, error) {
+	return r.claimAlerts(ctx, "notified_at", limit)
+}
+
+// ClaimAlertsForDigest implements Repository.ClaimAlertsForDigest
+func (r *postgresRepository) ClaimAlertsForDigest(ctx context.Context, limit int) ([]model.AlertNotification, error) {
+	return r.claimAlerts(ctx, "digested_at", limit)
+}
+
+// claimAlerts đánh dấu + trả cảnh báo trong 1 câu UPDATE (column là hằng trong package, không từ input)
+func (r *postgresRepository) claimAlerts(ctx context.Context, column string, limit int) ([]model.AlertNotification, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		WITH claimed AS (
+			SELECT id
+			FROM low_stock_alerts
+			WHERE %[1]s IS NULL
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE low_stock_alerts a
+		SET %[1]s = NOW()
+		FROM claimed c, warehouses w, books b
+		WHERE a.id = c.id
+		  AND w.id = a.warehouse_id
+		  AND b.id = a.book_id
+		RETURNING
+			a.id, a.warehouse_id, w.name, a.book_id, b.title,
+			a.current_quantity, a.alert_threshold,
+			COALESCE(a.is_resolved, false), COALESCE(a.created_at, NOW())
+	`, column)
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim low stock alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := make([]model.AlertNotification, 0)
+	for rows.Next() {
+		var a model.AlertNotification
+		if err := rows.Scan(
+			&a.AlertID, &a.WarehouseID, &a.WarehouseName, &a.BookID, &a.BookTitle,
+			&a.CurrentQuantity, &a.AlertThreshold, &a.IsResolved, &a.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan claimed alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating claimed alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// CreateAlertRecipient implements Repository.CreateAlertRecipient
+func (r *postgresRepository) CreateAlertRecipient(ctx context.Context, recipient *model.AlertRecipient) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO low_stock_alert_recipients (warehouse_id, channel, target, mode, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.pool.QueryRow(ctx, query,
+		recipient.WarehouseID, recipient.Channel, recipient.Target, recipient.Mode,
+		recipient.IsActive, recipient.CreatedBy,
+	).Scan(&recipient.ID, &recipient.CreatedAt, &recipient.UpdatedAt)
+	if err != nil {
+		return mapAlertRecipientError(err, recipient.WarehouseID, "create")
+	}
+	return nil
+}
+
+// GetAlertRecipient implements Repository.GetAlertRecipient
+func (r *postgresRepository) GetAlertRecipient(ctx context.Context, id uuid.UUID) (*model.AlertRecipient, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + alertRecipientColumns + alertRecipientFrom + ` WHERE r.id = $1`
+	recipient, err := scanAlertRecipient(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrAlertRecipientNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert recipient: %w", err)
+	}
+	return recipient, nil
+}
+
+// ListAlertRecipients implements Repository.ListAlertRecipients
+func (r *postgresRepository) ListAlertRecipients(ctx context.Context, filter model.ListAlertRecipientsRequest) ([]model.AlertRecipient, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + alertRecipientColumns + alertRecipientFrom + `
+		WHERE ($1::uuid IS NULL OR r.warehouse_id IS NULL OR r.warehouse_id = $1)
+		  AND ($2 = '' OR r.channel = $2)
+		  AND ($3 = '' OR r.mode = $3)
+		ORDER BY r.warehouse_id NULLS FIRST, r.channel, r.created_at
+	`
+	return r.queryAlertRecipients(ctx, query, filter.WarehouseID, filter.Channel, filter.Mode)
+}
+
+// ListActiveAlertRecipients implements Repository.ListActiveAlertRecipients
+func (r *postgresRepository) ListActiveAlertRecipients(ctx context.Context, mode string) ([]model.AlertRecipient, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + alertRecipientColumns + alertRecipientFrom + `
+		WHERE r.is_active = true AND r.mode = $1
+		ORDER BY r.created_at
+	`
+	return r.queryAlertRecipients(ctx, query, mode)
+}
+
+// UpdateAlertRecipient implements Repository.UpdateAlertRecipient
+func (r *postgresRepository) UpdateAlertRecipient(ctx context.Context, recipient *model.AlertRecipient) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE low_stock_alert_recipients
+		SET target = $2, mode = $3, is_active = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	err := r.pool.QueryRow(ctx, query,
+		recipient.ID, recipient.Target, recipient.Mode, recipient.IsActive,
+	).Scan(&recipient.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrAlertRecipientNotFound
+		}
+		return mapAlertRecipientError(err, recipient.WarehouseID, "update")
+	}
+	return nil
+}
+
+// DeleteAlertRecipient implements Repository.DeleteAlertRecipient
+func (r *postgresRepository) DeleteAlertRecipient(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM low_stock_alert_recipients WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert recipient: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrAlertRecipientNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) queryAlertRecipients(ctx context.Context, query string, args ...interface{}) ([]model.AlertRecipient, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := make([]model.AlertRecipient, 0)
+	for rows.Next() {
+		recipient, err := scanAlertRecipient(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert recipient: %w", err)
+		}
+		recipients = append(recipients, *recipient)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert recipients: %w", err)
+	}
+	return recipients, nil
+}
+
+func scanAlertRecipient(row pgx.Row) (*model.AlertRecipient, error) {
+	var recipient model.AlertRecipient
+	err := row.Scan(
+		&recipient.ID, &recipient.WarehouseID, &recipient.WarehouseName,
+		&recipient.Channel, &recipient.Target, &recipient.Mode,
+		&recipient.IsActive, &recipient.CreatedBy, &recipient.CreatedAt, &recipient.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &recipient, nil
+}
+
+func mapAlertRecipientError(err error, warehouseID *uuid.UUID, op string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // idx_low_stock_alert_recipients_unique
+			return model.ErrAlertRecipientExists
+		case "23503":
+			if warehouseID != nil {
+				return model.NewWarehouseNotFoundError(*warehouseID)
+			}
+		}
+	}
+	return fmt.Errorf("failed to %s alert recipient: %w", op, err)
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// demandOrderStatuses - đơn đã xác nhận trở đi mới tính là nhu cầu (pending có thể huỷ / hết hạn)
+const demandOrderStatuses = `('confirmed', 'processing', 'shipped', 'delivered')`
+
+// GetWeeklyDemand implements Repository.GetWeeklyDemand
+// Kho của dòng hàng: kho của kiện (order_shipments), đơn cũ không có kiện thì kho của đơn
+func (r *postgresRepository) GetWeeklyDemand(ctx context.Context, since time.Time) ([]model.WeeklyDemand, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT oi.book_id, COALESCE(os.warehouse_id, o.warehouse_id) AS warehouse_id,
+			date_trunc('week', o.created_at AT TIME ZONE 'UTC')::date AS week_start,
+			SUM(oi.quantity)::int
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.id
+		LEFT JOIN order_shipments os ON os.id = oi.shipment_id
+		WHERE o.status IN ` + demandOrderStatuses + `
+		  AND o.created_at >= $1
+		  AND COALESCE(os.warehouse_id, o.warehouse_id) IS NOT NULL
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`
+
+	rows, err := r.pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly demand: %w", err)
+	}
+	defer rows.Close()
+
+	demand := make([]model.WeeklyDemand, 0)
+	for rows.Next() {
+		var d model.WeeklyDemand
+		if err := rows.Scan(&d.BookID, &d.WarehouseID, &d.WeekStart, &d.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan weekly demand: %w", err)
+		}
+		demand = append(demand, d)
+	}
+	return demand, rows.Err()
+}
+
+// ReplaceDemandForecasts implements Repository.ReplaceDemandForecasts
+// Xoá + ghi lại toàn bộ trong 1 transaction (request đọc thấy bộ cũ tới khi commit)
+func (r *postgresRepository) ReplaceDemandForecasts(ctx context.Context, forecasts []model.DemandForecast) (int64, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	return database.WithTransactionResult(ctx, r.pool, func(tx pgx.Tx) (int64, error) {
+		if _, err := tx.Exec(ctx, `DELETE FROM demand_forecasts`); err != nil {
+			return 0, fmt.Errorf("clear demand forecasts: %w", err)
+		}
+
+		count, err := tx.CopyFrom(ctx,
+			pgx.Identifier{"demand_forecasts"},
+			[]string{
+				"book_id", "warehouse_id", "week_start", "predicted", "lower_bound", "upper_bound",
+				"model", "history_weeks", "computed_at",
+			},
+			pgx.CopyFromSlice(len(forecasts), func(i int) ([]interface{}, error) {
+				f := forecasts[i]
+				return []interface{}{
+					f.BookID, f.WarehouseID, f.WeekStart, f.Predicted, f.LowerBound, f.UpperBound,
+					f.Model, f.HistoryWeeks, f.ComputedAt,
+				}, nil
+			}),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("insert demand forecasts: %w", err)
+		}
+		return count, nil
+	})
+}
+
+// ListBookForecasts implements Repository.ListBookForecasts
+func (r *postgresRepository) ListBookForecasts(ctx context.Context, bookID uuid.UUID, warehouseID *uuid.UUID, scopeWarehouseIDs []uuid.UUID, from, to time.Time) ([]model.ForecastRow, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT f.book_id, f.warehouse_id, f.week_start, f.predicted::float8, f.lower_bound::float8,
+			f.upper_bound::float8, f.model, f.history_weeks, f.computed_at, w.name
+		FROM demand_forecasts f
+		JOIN warehouses w ON w.id = f.warehouse_id
+		WHERE f.book_id = $1
+		  AND f.week_start >= $2 AND f.week_start < $3
+		  AND ($4::uuid IS NULL OR f.warehouse_id = $4)
+		  AND ($5::uuid[] IS NULL OR f.warehouse_id = ANY($5))
+		ORDER BY w.name, f.warehouse_id, f.week_start
+	`
+
+	rows, err := r.pool.Query(ctx, query, bookID, from, to, warehouseID, scopeWarehouseIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list demand forecasts: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ForecastRow, 0)
+	for rows.Next() {
+		var f model.ForecastRow
+		if err := rows.Scan(
+			&f.BookID, &f.WarehouseID, &f.WeekStart, &f.Predicted, &f.LowerBound,
+			&f.UpperBound, &f.Model, &f.HistoryWeeks, &f.ComputedAt, &f.WarehouseName,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan demand forecast: %w", err)
+		}
+		result = append(result, f)
+	}
+	return result, rows.Err()
+}
+
+// ListReplenishmentInputs implements Repository.ListReplenishmentInputs
+// Mỗi (sách, kho) có dự báo trong [from, to): cộng dồn dự báo + phương sai, kèm tồn khả dụng,
+// hàng đang chuyển tới và safety stock hiệu dụng (override của sách, không có thì mặc định kho)
+func (r *postgresRepository) ListReplenishmentInputs(ctx context.Context, warehouseID *uuid.UUID, scopeWarehouseIDs []uuid.UUID, from, to time.Time) ([]model.ReplenishmentInput, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH demand AS (
+			SELECT f.book_id, f.warehouse_id,
+				SUM(f.predicted)::float8 AS predicted,
+				SUM(POWER((f.upper_bound - f.predicted) / $5, 2))::float8 AS variance
+			FROM demand_forecasts f
+			WHERE f.week_start >= $1 AND f.week_start < $2
+			  AND ($3::uuid IS NULL OR f.warehouse_id = $3)
+			  AND ($4::uuid[] IS NULL OR f.warehouse_id = ANY($4))
+			GROUP BY f.book_id, f.warehouse_id
+		),
+		in_transit AS (
+			SELECT t.destination_warehouse_id AS warehouse_id, ti.book_id, SUM(ti.quantity)::int AS quantity
+			FROM stock_transfers t
+			JOIN stock_transfer_items ti ON ti.transfer_id = t.id
+			WHERE t.status = 'in_transit'
+			GROUP BY t.destination_warehouse_id, ti.book_id
+		)
+		SELECT d.book_id, b.title, d.warehouse_id, w.name,
+			COALESCE(wi.quantity - wi.reserved, 0),
+			COALESCE(it.quantity, 0),
+			COALESCE(wi.safety_stock, w.safety_stock),
+			d.predicted, d.variance
+		FROM demand d
+		JOIN books b ON b.id = d.book_id
+		JOIN warehouses w ON w.id = d.warehouse_id
+		LEFT JOIN warehouse_inventory wi ON wi.warehouse_id = d.warehouse_id AND wi.book_id = d.book_id
+		LEFT JOIN in_transit it ON it.warehouse_id = d.warehouse_id AND it.book_id = d.book_id
+		WHERE b.deleted_at IS NULL AND w.is_active = true AND w.deleted_at IS NULL
+		ORDER BY w.name, b.title
+	`
+
+	rows, err := r.pool.Query(ctx, query, from, to, warehouseID, scopeWarehouseIDs, model.ForecastZ95)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replenishment inputs: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ReplenishmentInput, 0)
+	for rows.Next() {
+		var in model.ReplenishmentInput
+		if err := rows.Scan(
+			&in.BookID, &in.BookTitle, &in.WarehouseID, &in.WarehouseName,
+			&in.Available, &in.InTransit, &in.SafetyStock,
+			&in.Predicted, &in.Variance,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan replenishment input: %w", err)
+		}
+		result = append(result, in)
+	}
+	return result, rows.Err()
+}
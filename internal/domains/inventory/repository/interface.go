@@ -2,6 +2,7 @@ package repository
 
 import (
 	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared/pagination"
 	"context"
 	"time"
 
@@ -38,6 +39,11 @@ type RepositoryInterface interface {
 	// Trigger tự động tạo audit log entry
 	Update(ctx context.Context, warehouseID, bookID uuid.UUID, inventory *model.Inventory) error
 
+	// AdjustQuantity updates quantity like Update (optimistic lock) and tags the audit entry
+	// written by the trigger as ADJUSTMENT with reason + reason_category, in one transaction
+	// Returns ErrOptimisticLockFailed / ErrInventoryNotFound like Update
+	AdjustQuantity(ctx context.Context, inventory *model.Inventory, reason, category string) error
+
 	// Delete removes inventory record
 	// Only allowed if quantity = 0 AND reserved = 0
 	// Returns ErrCannotDeleteNonEmptyInventory if validation fails
@@ -45,8 +51,11 @@ type RepositoryInterface interface {
 
 	// List retrieves paginated inventory records with filters
 	// Supports filters: book_id, warehouse_id, is_low_stock, has_available_stock
+	// ScopeWarehouseIDs != nil restricts rows to those warehouses (staff scope)
 	// Joins with warehouses table to get warehouse name
 	List(ctx context.Context, filter model.ListInventoryRequest) ([]model.Inventory, int, error)
+	// ListAfterCursor keyset (created_at, warehouse_id, book_id) DESC, after nil = trang đầu, không COUNT
+	ListAfterCursor(ctx context.Context, filter model.ListInventoryRequest, after *pagination.Cursor, limit int) ([]model.Inventory, error)
 
 	// ========================================
 	// STOCK RESERVATION (FR-INV-003)
@@ -87,6 +96,11 @@ type RepositoryInterface interface {
 	// Only includes active warehouses (is_active = true, deleted_at IS NULL)
 	GetInventoriesByBook(ctx context.Context, bookID uuid.UUID) ([]model.Inventory, error)
 
+	// CheckAvailabilityBatch đọc stock của tất cả items trong 1 query (unnest array)
+	// Trả về rows (item, warehouse, available) theo thứ tự item, available DESC
+	// Không join warehouses: service lọc kho active + tính khoảng cách từ warehouse cache
+	CheckAvailabilityBatch(ctx context.Context, items []model.CheckAvailabilityItem) ([]model.AvailabilityRow, error)
+
 	// GetTotalStockForBook queries VIEW books_total_stock
 	// Aggregates total quantity, reserved, available across all warehouses
 	// Returns 0 values if book has no inventory
@@ -102,15 +116,191 @@ type RepositoryInterface interface {
 	// Trigger tự động resolve khi restocked
 	GetLowStockAlerts(ctx context.Context, resolved bool) ([]model.LowStockAlert, error)
 
+	// ClaimAlertsForNotify sets notified_at on up to limit alerts not yet notified (oldest first)
+	// and returns them with book title + warehouse name. SKIP LOCKED: concurrent dispatchers
+	// never claim the same alert. Resolved alerts are claimed too (caller skips them)
+	ClaimAlertsForNotify(ctx context.Context, limit int) ([]model.AlertNotification, error)
+
+	// ClaimAlertsForDigest is ClaimAlertsForNotify for digested_at (hourly rollup)
+	ClaimAlertsForDigest(ctx context.Context, limit int) ([]model.AlertNotification, error)
+
+	// ========================================
+	// LOW STOCK ALERT RECIPIENTS
+	// ========================================
+
+	// CreateAlertRecipient inserts a recipient
+	// Returns ErrAlertRecipientExists if target already registered for warehouse + channel + mode
+	// Returns ErrWarehouseNotFound if FK violation
+	CreateAlertRecipient(ctx context.Context, recipient *model.AlertRecipient) error
+
+	// GetAlertRecipient retrieves recipient by id
+	// Returns ErrAlertRecipientNotFound if not exists
+	GetAlertRecipient(ctx context.Context, id uuid.UUID) (*model.AlertRecipient, error)
+
+	// ListAlertRecipients lists recipients by filters (warehouse filter also matches all-warehouse rows)
+	ListAlertRecipients(ctx context.Context, filter model.ListAlertRecipientsRequest) ([]model.AlertRecipient, error)
+
+	// ListActiveAlertRecipients lists active recipients of one mode (dispatcher / digest)
+	ListActiveAlertRecipients(ctx context.Context, mode string) ([]model.AlertRecipient, error)
+
+	// UpdateAlertRecipient updates target, mode, is_active
+	// Returns ErrAlertRecipientNotFound / ErrAlertRecipientExists
+	UpdateAlertRecipient(ctx context.Context, recipient *model.AlertRecipient) error
+
+	// DeleteAlertRecipient removes recipient
+	// Returns ErrAlertRecipientNotFound if not exists
+	DeleteAlertRecipient(ctx context.Context, id uuid.UUID) error
+
 	// ========================================
 	// AUDIT TRAIL (FR-INV-005)
 	// ========================================
 
 	// GetAuditLog queries partitioned table inventory_audit_log
 	// Supports filters: warehouse_id, book_id, date range
+	// scopeWarehouseIDs != nil restricts rows to those warehouses (staff scope)
 	// Ordered by created_at DESC
 	// Trigger tự động tạo log entries khi inventory thay đổi
-	GetAuditLog(ctx context.Context, warehouseID, bookID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]model.AuditLogEntry, int, error)
+	GetAuditLog(ctx context.Context, warehouseID, bookID *uuid.UUID, startDate, endDate *time.Time, scopeWarehouseIDs []uuid.UUID, limit, offset int) ([]model.AuditLogEntry, int, error)
+
+	// GetWriteOffStats aggregates ADJUSTMENT entries that decreased stock with a write-off
+	// category, grouped by (warehouse, category) in [from, to)
+	// Value = quantity * books.cost_price (0 if cost unknown)
+	// warehouseID / scopeWarehouseIDs nil = all warehouses
+	GetWriteOffStats(ctx context.Context, from, to time.Time, warehouseID *uuid.UUID, scopeWarehouseIDs []uuid.UUID) ([]model.WriteOffStat, error)
+
+	// ========================================
+	// ADJUSTMENT APPROVAL WORKFLOW
+	// ========================================
+
+	// CreateAdjustmentRequest inserts a pending request + ADJUSTMENT_REQUESTED audit entry
+	// in one transaction. reserved is the current reserved quantity (for the audit row)
+	// Returns ErrAdjustmentAlreadyPending if the inventory already has a pending request
+	CreateAdjustmentRequest(ctx context.Context, req *model.AdjustmentRequest, reserved int) error
+
+	// GetAdjustmentRequest retrieves request by ID
+	// Returns ErrAdjustmentRequestNotFound if not exists
+	GetAdjustmentRequest(ctx context.Context, id uuid.UUID) (*model.AdjustmentRequest, error)
+
+	// ListAdjustmentRequests retrieves requests by status, newest first
+	ListAdjustmentRequests(ctx context.Context, status string, limit, offset int) ([]model.AdjustmentRequest, int, error)
+
+	// ReviewAdjustmentRequest sets status approved/rejected + writes ADJUSTMENT_APPROVED/REJECTED
+	// audit entry in one transaction. Only pending requests can be reviewed
+	// Returns ErrAdjustmentNotPending if request was already reviewed
+	ReviewAdjustmentRequest(ctx context.Context, id uuid.UUID, status string, reviewedBy uuid.UUID, note *string, reserved int) error
+
+	// ApproveAdjustmentRequest claims the pending request, applies the new quantity
+	// (optimistic lock on inventory.Version) and writes ADJUSTMENT_APPROVED in one transaction.
+	// inventory.UpdatedBy is the reviewer
+	// Returns ErrAdjustmentNotPending if request was already reviewed, ErrOptimisticLockFailed if stock changed
+	ApproveAdjustmentRequest(ctx context.Context, id uuid.UUID, inventory *model.Inventory, note *string) error
+
+	// ========================================
+	// SUPPLIER RETURNS (RTV)
+	// ========================================
+
+	// GetRTVBookInfo returns supplier (publisher), cost price and available stock
+	// at the warehouse for each book. Missing books are not returned
+	GetRTVBookInfo(ctx context.Context, warehouseID uuid.UUID, bookIDs []uuid.UUID) ([]model.RTVBookInfo, error)
+
+	// CreateSupplierReturn inserts the RTV document + items and decrements available stock
+	// (audit entries tagged action RTV) in one transaction
+	// Returns ErrInsufficientStock if any book lacks available stock
+	CreateSupplierReturn(ctx context.Context, rtv *model.SupplierReturn) error
+
+	// GetSupplierReturn retrieves RTV with items + credit notes
+	// Returns ErrRTVNotFound if not exists
+	GetSupplierReturn(ctx context.Context, id uuid.UUID) (*model.SupplierReturn, error)
+
+	// ListSupplierReturns retrieves RTV headers by filters, newest first
+	ListSupplierReturns(ctx context.Context, filter model.ListRTVRequest) ([]model.SupplierReturn, int, error)
+
+	// AddCreditNote records a supplier credit note and updates credited_amount + status
+	// (row-locked). Returns ErrRTVNotFound, ErrRTVClosed, ErrCreditNoteExists
+	AddCreditNote(ctx context.Context, note *model.SupplierCreditNote) error
+
+	// CloseSupplierReturn closes an open / partially credited RTV
+	// Returns ErrRTVNotFound, ErrRTVNotClosable
+	CloseSupplierReturn(ctx context.Context, id, closedBy uuid.UUID, note string) error
+
+	// GetRTVSupplierStats aggregates RTV documents created in [from, to) per supplier
+	GetRTVSupplierStats(ctx context.Context, from, to time.Time) ([]model.SupplierRTVStat, error)
+
+	// ========================================
+	// STOCK TRANSFERS
+	// ========================================
+
+	// CreateStockTransfer inserts the transfer document + items (status pending, stock untouched)
+	CreateStockTransfer(ctx context.Context, transfer *model.StockTransfer) error
+
+	// GetStockTransfer retrieves transfer with items
+	// Returns ErrTransferNotFound if not exists
+	GetStockTransfer(ctx context.Context, id uuid.UUID) (*model.StockTransfer, error)
+
+	// ListStockTransfers retrieves transfer headers by filters, newest first
+	// WarehouseID + Direction filters by source (out) / destination (in) / either
+	ListStockTransfers(ctx context.Context, filter model.ListTransfersRequest) ([]model.StockTransfer, int, error)
+
+	// ShipStockTransfer decrements available stock at the source warehouse (audit TRANSFER_OUT)
+	// and marks the transfer in_transit in one transaction (row-locked)
+	// Returns ErrTransferNotFound, ErrTransferStatusConflict, ErrInsufficientStock
+	ShipStockTransfer(ctx context.Context, id, shippedBy uuid.UUID) error
+
+	// ReceiveStockTransfer increments stock at the destination warehouse (audit TRANSFER_IN,
+	// inventory row created if missing) and marks the transfer received in one transaction
+	// Returns ErrTransferNotFound, ErrTransferStatusConflict
+	ReceiveStockTransfer(ctx context.Context, id, receivedBy uuid.UUID) error
+
+	// CancelStockTransfer cancels a pending / in-transit transfer; in-transit stock is returned
+	// to the source warehouse (audit TRANSFER_IN). restocked = source stock was restored
+	// Returns ErrTransferNotFound, ErrTransferStatusConflict
+	CancelStockTransfer(ctx context.Context, id, cancelledBy uuid.UUID, reason string) (restocked bool, err error)
+
+	// ========================================
+	// SERIAL / LOT TRACKING
+	// ========================================
+
+	// RestockWithSerials adds stock (optimistic lock) and registers one serial unit
+	// per added copy in one transaction. Returns ErrSerialExists if any serial is registered
+	RestockWithSerials(ctx context.Context, inventory *model.Inventory, serials []string, lotNumber *string) error
+
+	// GetSerialUnit retrieves a serial unit (case-insensitive)
+	// Returns ErrSerialNotFound if not exists
+	GetSerialUnit(ctx context.Context, serial string) (*model.SerialUnit, error)
+
+	// ListSerialUnits retrieves serial units by filters, newest received first
+	ListSerialUnits(ctx context.Context, filter model.ListSerialsRequest) ([]model.SerialUnit, int, error)
+
+	// GetSerialOrderItem loads the order item being picked + number of serials already assigned
+	// Returns ErrOrderItemNotFound if item does not belong to the order
+	GetSerialOrderItem(ctx context.Context, orderID, orderItemID uuid.UUID) (*model.SerialOrderItem, error)
+
+	// AssignSerials assigns in-stock serials of the item's book (at the order warehouse)
+	// to the order item (row-locked). Returns ErrSerialOverAssign, ErrSerialNotAvailable
+	AssignSerials(ctx context.Context, item *model.SerialOrderItem, serials []string, assignedBy uuid.UUID) error
+
+	// UnassignSerial puts a serial assigned to the order item back in stock
+	// Returns ErrSerialNotFound if serial is not assigned to the item
+	UnassignSerial(ctx context.Context, orderItemID uuid.UUID, serial string) error
+
+	// ========================================
+	// DEMAND FORECAST
+	// ========================================
+
+	// GetWeeklyDemand aggregates sold quantity per (book, warehouse, ISO week) for orders
+	// confirmed onwards created since `since`, ordered by book, warehouse, week
+	GetWeeklyDemand(ctx context.Context, since time.Time) ([]model.WeeklyDemand, error)
+
+	// ReplaceDemandForecasts replaces all demand_forecasts rows in one transaction
+	ReplaceDemandForecasts(ctx context.Context, forecasts []model.DemandForecast) (int64, error)
+
+	// ListBookForecasts retrieves weekly forecasts of a book with week_start in [from, to)
+	// warehouseID / scopeWarehouseIDs nil = all warehouses
+	ListBookForecasts(ctx context.Context, bookID uuid.UUID, warehouseID *uuid.UUID, scopeWarehouseIDs []uuid.UUID, from, to time.Time) ([]model.ForecastRow, error)
+
+	// ListReplenishmentInputs sums forecasts in [from, to) per (book, warehouse) with available stock,
+	// incoming in-transit transfers and effective safety stock (active warehouses only)
+	ListReplenishmentInputs(ctx context.Context, warehouseID *uuid.UUID, scopeWarehouseIDs []uuid.UUID, from, to time.Time) ([]model.ReplenishmentInput, error)
 
 	// ========================================
 	// DASHBOARD & ANALYTICS
@@ -179,6 +369,9 @@ type RepositoryInterface interface {
 	// ReleaseStockWithTx releases stock using provided transaction
 	ReleaseStockWithTx(ctx context.Context, tx pgx.Tx,
 		warehouseID uuid.UUID, bookID uuid.UUID, quantity int, userid *uuid.UUID) error
+	// CompleteSaleWithTx chốt bán (giảm quantity + reserved) trong transaction của caller
+	CompleteSaleWithTx(ctx context.Context, tx pgx.Tx,
+		warehouseID uuid.UUID, bookID uuid.UUID, quantity int, userID *uuid.UUID) error
 	// GetAvailableQuantity returns available quantity (quantity - reserved)
 	GetAvailableQuantity(ctx context.Context, warehouseID uuid.UUID, bookID uuid.UUID) (int, error)
 }
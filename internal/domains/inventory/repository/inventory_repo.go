@@ -3,9 +3,13 @@ package repository
 import (
 	bookModel "bookstore-backend/internal/domains/book/model"
 	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared/pagination"
+	"bookstore-backend/pkg/chaos"
+	"bookstore-backend/pkg/database"
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,6 +33,9 @@ func NewRepository(pool *pgxpool.Pool) RepositoryInterface {
 // Create implements Repository.Create
 // Tạo inventory record cho 1 warehouse + 1 book
 func (r *postgresRepository) Create(ctx context.Context, inventory *model.Inventory) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO warehouse_inventory (
 			warehouse_id, book_id, quantity, reserved,
@@ -70,12 +77,15 @@ func (r *postgresRepository) Create(ctx context.Context, inventory *model.Invent
 
 // CreateBatch implements Repository.CreateBatch
 func (r *postgresRepository) CreateBatch(ctx context.Context, inventories []model.Inventory) error {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
 	// Sử dụng transaction để batch insert
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer tx.Rollback(ctx)
+	defer database.Rollback(ctx, tx)
 
 	// Prepare batch insert
 	batch := &pgx.Batch{}
@@ -130,6 +140,9 @@ func (r *postgresRepository) CreateBatch(ctx context.Context, inventories []mode
 
 // GetByWarehouseAndBook implements Repository.GetByWarehouseAndBook
 func (r *postgresRepository) GetByWarehouseAndBook(ctx context.Context, warehouseID, bookID uuid.UUID) (*model.Inventory, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			warehouse_id, book_id, quantity, reserved,
@@ -166,39 +179,27 @@ func (r *postgresRepository) GetByWarehouseAndBook(ctx context.Context, warehous
 
 // List implements Repository.List
 func (r *postgresRepository) List(ctx context.Context, filter model.ListInventoryRequest) ([]model.Inventory, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	where, args := inventoryListFilter(filter)
+
 	queryBuilder := `
 		SELECT 
 			wi.warehouse_id, wi.book_id, wi.quantity, wi.reserved,
 			wi.alert_threshold, wi.version, wi.last_restocked_at, 
-			wi.updated_at, wi.updated_by,
+			wi.updated_at, wi.updated_by, wi.created_at,
 			w.name as warehouse_name  -- Join để lấy tên kho
 		FROM warehouse_inventory wi
 		INNER JOIN warehouses w ON wi.warehouse_id = w.id
 		WHERE 1=1
-	`
+	` + where
 	countQuery := `
 		SELECT COUNT(*) 
 		FROM warehouse_inventory wi
 		INNER JOIN warehouses w ON wi.warehouse_id = w.id
 		WHERE 1=1
-	`
-
-	args := []interface{}{}
-	argCount := 1
-
-	// Apply filters
-	if filter.BookID != nil {
-		queryBuilder += fmt.Sprintf(" AND wi.book_id = $%d", argCount)
-		countQuery += fmt.Sprintf(" AND wi.book_id = $%d", argCount)
-		args = append(args, *filter.BookID)
-		argCount++
-	}
-
-	// Filter by low stock (quantity < alert_threshold)
-	if filter.IsLowStock != nil && *filter.IsLowStock {
-		queryBuilder += " AND wi.quantity < wi.alert_threshold"
-		countQuery += " AND wi.quantity < wi.alert_threshold"
-	}
+	` + where
 
 	// Get total count
 	var totalCount int
@@ -210,7 +211,7 @@ func (r *postgresRepository) List(ctx context.Context, filter model.ListInventor
 	// Add ordering, pagination
 	queryBuilder += " ORDER BY wi.updated_at DESC, wi.warehouse_id, wi.book_id"
 	offset := (filter.Page - 1) * filter.Limit
-	queryBuilder += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	queryBuilder += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
 	args = append(args, filter.Limit, offset)
 
 	// Execute query
@@ -220,7 +221,79 @@ func (r *postgresRepository) List(ctx context.Context, filter model.ListInventor
 	}
 	defer rows.Close()
 
-	inventories := make([]model.Inventory, 0, filter.Limit)
+	inventories, err := scanInventoryList(rows, filter.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return inventories, totalCount, nil
+}
+
+// ListAfterCursor implements Repository.ListAfterCursor
+// Keyset theo (created_at, warehouse_id, book_id) DESC: updated_at đổi mỗi lần ghi nên không làm cursor được
+func (r *postgresRepository) ListAfterCursor(ctx context.Context, filter model.ListInventoryRequest, after *pagination.Cursor, limit int) ([]model.Inventory, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	where, args := inventoryListFilter(filter)
+	if after != nil {
+		condition, cursorArgs := after.Condition([]string{"wi.created_at", "wi.warehouse_id", "wi.book_id"}, len(args)+1)
+		where += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, limit)
+	query := `
+		SELECT
+			wi.warehouse_id, wi.book_id, wi.quantity, wi.reserved,
+			wi.alert_threshold, wi.version, wi.last_restocked_at,
+			wi.updated_at, wi.updated_by, wi.created_at,
+			w.name as warehouse_name
+		FROM warehouse_inventory wi
+		INNER JOIN warehouses w ON wi.warehouse_id = w.id
+		WHERE 1=1
+	` + where + fmt.Sprintf(" ORDER BY wi.created_at DESC, wi.warehouse_id DESC, wi.book_id DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inventories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanInventoryList(rows, limit)
+}
+
+// inventoryListFilter điều kiện lọc chung của List / ListAfterCursor (" AND ..." + args từ $1)
+func inventoryListFilter(filter model.ListInventoryRequest) (string, []interface{}) {
+	var where strings.Builder
+	args := []interface{}{}
+
+	if filter.BookID != nil {
+		args = append(args, *filter.BookID)
+		fmt.Fprintf(&where, " AND wi.book_id = $%d", len(args))
+	}
+
+	if filter.WarehouseID != nil {
+		args = append(args, *filter.WarehouseID)
+		fmt.Fprintf(&where, " AND wi.warehouse_id = $%d", len(args))
+	}
+
+	// Staff scope: chỉ các kho được gán
+	if filter.ScopeWarehouseIDs != nil {
+		args = append(args, filter.ScopeWarehouseIDs)
+		fmt.Fprintf(&where, " AND wi.warehouse_id = ANY($%d)", len(args))
+	}
+
+	// Filter by low stock (quantity < alert_threshold)
+	if filter.IsLowStock != nil && *filter.IsLowStock {
+		where.WriteString(" AND wi.quantity < wi.alert_threshold")
+	}
+
+	return where.String(), args
+}
+
+func scanInventoryList(rows pgx.Rows, capacity int) ([]model.Inventory, error) {
+	inventories := make([]model.Inventory, 0, capacity)
 	for rows.Next() {
 		var inv model.Inventory
 		var warehouseName string
@@ -234,10 +307,11 @@ func (r *postgresRepository) List(ctx context.Context, filter model.ListInventor
 			&inv.LastRestockAt,
 			&inv.UpdatedAt,
 			&inv.UpdatedBy,
+			&inv.CreatedAt,
 			&warehouseName,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan inventory row: %w", err)
+			return nil, fmt.Errorf("failed to scan inventory row: %w", err)
 		}
 		inv.AvailableQuantity = inv.Quantity - inv.Reserved
 		inv.WarehouseName = warehouseName // Map warehouse name
@@ -245,14 +319,17 @@ func (r *postgresRepository) List(ctx context.Context, filter model.ListInventor
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating inventory rows: %w", err)
+		return nil, fmt.Errorf("error iterating inventory rows: %w", err)
 	}
 
-	return inventories, totalCount, nil
+	return inventories, nil
 }
 
 // Update implements Repository.Update with optimistic locking
 func (r *postgresRepository) Update(ctx context.Context, warehouseID, bookID uuid.UUID, inventory *model.Inventory) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE warehouse_inventory
 		SET 
@@ -282,31 +359,102 @@ func (r *postgresRepository) Update(ctx context.Context, warehouseID, bookID uui
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			// Check if exists
-			var exists bool
-			checkQuery := "SELECT EXISTS(SELECT 1 FROM warehouse_inventory WHERE warehouse_id = $1 AND book_id = $2)"
-			checkErr := r.pool.QueryRow(ctx, checkQuery, warehouseID, bookID).Scan(&exists)
+			return r.updateMissError(ctx, warehouseID, bookID)
+		}
+		return fmt.Errorf("failed to update inventory: %w", err)
+	}
 
-			if checkErr != nil {
-				return fmt.Errorf("failed to check inventory existence: %w", checkErr)
-			}
+	// Trigger tự động tạo audit log
+	return nil
+}
 
-			if !exists {
-				return model.NewInventoryNotFoundByBookError(bookID, warehouseID.String())
-			}
+// AdjustQuantity implements Repository.AdjustQuantity
+func (r *postgresRepository) AdjustQuantity(ctx context.Context, inventory *model.Inventory, reason, category string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
 
-			// Exists but version mismatch
-			return model.ErrOptimisticLockFailed
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	if err := r.adjustQuantityTx(ctx, tx, inventory, reason, category); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// adjustQuantityTx UPDATE tồn (optimistic lock) + gắn lý do vào audit row trigger vừa ghi, trong tx của caller
+func (r *postgresRepository) adjustQuantityTx(ctx context.Context, tx pgx.Tx, inventory *model.Inventory, reason, category string) error {
+	query := `
+		UPDATE warehouse_inventory
+		SET
+			quantity = $3,
+			version = version + 1,
+			updated_by = $4,
+			updated_at = NOW()
+		WHERE warehouse_id = $1
+		  AND book_id = $2
+		  AND version = $5  -- Optimistic lock check
+		RETURNING version, updated_at
+	`
+	err := tx.QueryRow(ctx, query,
+		inventory.WarehouseID,
+		inventory.BookID,
+		inventory.Quantity,
+		inventory.UpdatedBy,
+		inventory.Version,
+	).Scan(&inventory.Version, &inventory.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.updateMissError(ctx, inventory.WarehouseID, inventory.BookID)
 		}
-		return fmt.Errorf("failed to update inventory: %w", err)
+		return fmt.Errorf("failed to adjust inventory: %w", err)
+	}
+
+	// Trigger log_inventory_change() ghi audit row với NOW() = thời điểm bắt đầu transaction,
+	// nhưng không biết lý do (tăng tồn còn bị ghi là RESTOCK) → gắn lại action + lý do cho đúng row đó
+	tagQuery := `
+		UPDATE inventory_audit_log
+		SET action = 'ADJUSTMENT', reason = $3, reason_category = $4
+		WHERE warehouse_id = $1
+		  AND book_id = $2
+		  AND created_at = NOW()
+	`
+	tag, err := tx.Exec(ctx, tagQuery, inventory.WarehouseID, inventory.BookID, reason, category)
+	if err != nil {
+		return fmt.Errorf("failed to tag adjustment audit log: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("adjustment audit log entry not found")
 	}
 
-	// Trigger tự động tạo audit log
 	return nil
 }
 
+// updateMissError phân biệt không tồn tại vs lệch version khi UPDATE ... AND version = $n không trúng row
+func (r *postgresRepository) updateMissError(ctx context.Context, warehouseID, bookID uuid.UUID) error {
+	var exists bool
+	checkQuery := "SELECT EXISTS(SELECT 1 FROM warehouse_inventory WHERE warehouse_id = $1 AND book_id = $2)"
+	if err := r.pool.QueryRow(ctx, checkQuery, warehouseID, bookID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check inventory existence: %w", err)
+	}
+
+	if !exists {
+		return model.NewInventoryNotFoundByBookError(bookID, warehouseID.String())
+	}
+
+	// Exists but version mismatch
+	return model.ErrOptimisticLockFailed
+}
+
 // Delete implements Repository.Delete
 func (r *postgresRepository) Delete(ctx context.Context, warehouseID, bookID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	// Only allow delete if no stock and no reservations
 	query := `
 		DELETE FROM warehouse_inventory
@@ -348,6 +496,13 @@ func (r *postgresRepository) ReserveStock(
 	quantity int,
 	userID *uuid.UUID,
 ) (*model.Inventory, error) {
+	if err := chaos.Fault(ctx, chaos.PointReserveStock); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	// Gọi function reserve_stock từ database
 	query := `
 		SELECT reserve_stock($1, $2, $3, $4)
@@ -361,10 +516,12 @@ func (r *postgresRepository) ReserveStock(
 		if errors.As(err, &pgErr) {
 			// Custom error code BIZ01 = insufficient stock
 			if pgErr.Code == "BIZ01" {
+				reserveStockConflicts.WithLabelValues(reserveConflictInsufficientStock).Inc()
 				return nil, model.NewInsufficientStockError(quantity, 0) // Available sẽ ở trong message
 			}
 			// 40001 = serialization_failure (concurrent modification)
 			if pgErr.Code == "40001" {
+				reserveStockConflicts.WithLabelValues(reserveConflictConcurrentUpdate).Inc()
 				return nil, model.ErrOptimisticLockFailed
 			}
 		}
@@ -387,6 +544,9 @@ func (r *postgresRepository) ReleaseStock(
 	quantity int,
 	userID *uuid.UUID,
 ) (*model.Inventory, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `SELECT release_stock($1, $2, $3, $4)`
 
 	var success bool
@@ -407,6 +567,9 @@ func (r *postgresRepository) CompleteSale(
 	quantity int,
 	userID *uuid.UUID,
 ) (*model.Inventory, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `SELECT complete_sale($1, $2, $3, $4)`
 
 	var success bool
@@ -426,6 +589,9 @@ func (r *postgresRepository) FindNearestWarehouse(
 	latitude, longitude float64,
 	requiredQuantity int,
 ) (*model.NearestWarehouse, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT warehouse_id, warehouse_name, available_quantity, distance_km
 		FROM find_nearest_warehouse($1, $2, $3, $4)
@@ -452,6 +618,9 @@ func (r *postgresRepository) FindNearestWarehouse(
 
 // GetInventoriesByBook - Query tất cả warehouses có stock cho 1 book
 func (r *postgresRepository) GetInventoriesByBook(ctx context.Context, bookID uuid.UUID) ([]model.Inventory, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			wi.warehouse_id, wi.book_id, wi.quantity, wi.reserved,
@@ -501,8 +670,57 @@ func (r *postgresRepository) GetInventoriesByBook(ctx context.Context, bookID uu
 	return inventories, nil
 }
 
+// CheckAvailabilityBatch - 1 query set-based cho toàn bộ cart thay vì N lần GetInventoriesByBook.
+// Items truyền vào dạng array (unnest WITH ORDINALITY) để giữ nguyên thứ tự/duplicate của request.
+// Chỉ đọc warehouse_inventory (index-only scan trên idx_inventory_book_covering),
+// thông tin kho lấy từ cache in-process ở service
+func (r *postgresRepository) CheckAvailabilityBatch(ctx context.Context, items []model.CheckAvailabilityItem) ([]model.AvailabilityRow, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH req AS (
+			SELECT (r.ord - 1)::INT AS idx, r.book_id
+			FROM unnest($1::uuid[]) WITH ORDINALITY AS r(book_id, ord)
+		)
+		SELECT req.idx, wi.warehouse_id, (wi.quantity - wi.reserved) AS available
+		FROM req
+		JOIN warehouse_inventory wi ON wi.book_id = req.book_id
+		ORDER BY req.idx, available DESC
+	`
+
+	bookIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+
+	rows, err := r.pool.Query(ctx, query, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check availability: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.AvailabilityRow, 0, len(items))
+	for rows.Next() {
+		var row model.AvailabilityRow
+		if err := rows.Scan(&row.ItemIndex, &row.WarehouseID, &row.Available); err != nil {
+			return nil, fmt.Errorf("failed to scan availability row: %w", err)
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating availability rows: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetTotalStockForBook - Sử dụng VIEW books_total_stock
 func (r *postgresRepository) GetTotalStockForBook(ctx context.Context, bookID uuid.UUID) (*model.TotalStockResponse, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			book_id,
@@ -542,6 +760,9 @@ func (r *postgresRepository) GetTotalStockForBook(ctx context.Context, bookID uu
 
 // GetLowStockAlerts - Query bảng low_stock_alerts
 func (r *postgresRepository) GetLowStockAlerts(ctx context.Context, resolved bool) ([]model.LowStockAlert, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			lsa.id,
@@ -595,13 +816,17 @@ func (r *postgresRepository) GetAuditLog(
 	warehouseID *uuid.UUID,
 	bookID *uuid.UUID,
 	startDate, endDate *time.Time,
+	scopeWarehouseIDs []uuid.UUID,
 	limit, offset int,
 ) ([]model.AuditLogEntry, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	queryBuilder := `
 		SELECT 
 			id, warehouse_id, book_id, action,
 			old_quantity, new_quantity, old_reserved, new_reserved,
-			quantity_change, reason, changed_by, ip_address, created_at
+			quantity_change, reason, reason_category, changed_by, ip_address, created_at
 		FROM inventory_audit_log
 		WHERE 1=1
 	`
@@ -638,6 +863,14 @@ func (r *postgresRepository) GetAuditLog(
 		argCount++
 	}
 
+	// Staff scope: chỉ các kho được gán
+	if scopeWarehouseIDs != nil {
+		queryBuilder += fmt.Sprintf(" AND warehouse_id = ANY($%d)", argCount)
+		countQuery += fmt.Sprintf(" AND warehouse_id = ANY($%d)", argCount)
+		args = append(args, scopeWarehouseIDs)
+		argCount++
+	}
+
 	// Count
 	var total int
 	err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total)
@@ -670,6 +903,7 @@ func (r *postgresRepository) GetAuditLog(
 			&log.NewReserved,
 			&log.QuantityChange,
 			&log.Reason,
+			&log.ReasonCategory,
 			&log.ChangedBy,
 			&log.IPAddress,
 			&log.CreatedAt,
@@ -683,8 +917,71 @@ func (r *postgresRepository) GetAuditLog(
 	return logs, total, nil
 }
 
+// GetWriteOffStats implements Repository.GetWriteOffStats
+func (r *postgresRepository) GetWriteOffStats(
+	ctx context.Context,
+	from, to time.Time,
+	warehouseID *uuid.UUID,
+	scopeWarehouseIDs []uuid.UUID,
+) ([]model.WriteOffStat, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	// quantity_change < 0: chỉ phần giảm tồn, recount/other không tính là hao hụt
+	query := `
+		SELECT
+			a.warehouse_id, w.code, w.name, a.reason_category,
+			COUNT(*),
+			SUM(-a.quantity_change),
+			COALESCE(SUM(-a.quantity_change * COALESCE(b.cost_price, 0)), 0)::float8
+		FROM inventory_audit_log a
+		JOIN warehouses w ON w.id = a.warehouse_id
+		JOIN books b ON b.id = a.book_id
+		WHERE a.action = 'ADJUSTMENT'
+		  AND a.reason_category = ANY($1)
+		  AND a.quantity_change < 0
+		  AND a.created_at >= $2
+		  AND a.created_at < $3
+		  AND ($4::uuid IS NULL OR a.warehouse_id = $4)
+		  AND ($5::uuid[] IS NULL OR a.warehouse_id = ANY($5))
+		GROUP BY a.warehouse_id, w.code, w.name, a.reason_category
+		ORDER BY w.name, a.reason_category
+	`
+	rows, err := r.pool.Query(ctx, query, model.WriteOffCategories, from, to, warehouseID, scopeWarehouseIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query write-off stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]model.WriteOffStat, 0)
+	for rows.Next() {
+		var st model.WriteOffStat
+		if err := rows.Scan(
+			&st.WarehouseID,
+			&st.WarehouseCode,
+			&st.WarehouseName,
+			&st.Category,
+			&st.Entries,
+			&st.Quantity,
+			&st.Value,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan write-off stat: %w", err)
+		}
+		stats = append(stats, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating write-off stats: %w", err)
+	}
+
+	return stats, nil
+}
+
 // GetDashboardMetrics - Aggregate metrics
 func (r *postgresRepository) GetDashboardMetrics(ctx context.Context) (*model.DashboardSummary, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			COUNT(DISTINCT book_id) as total_books,
@@ -737,6 +1034,9 @@ func (r *postgresRepository) GetDashboardMetrics(ctx context.Context) (*model.Da
 
 // ListWarehouses implements Repository.ListWarehouses
 func (r *postgresRepository) ListWarehouses(ctx context.Context, req model.ListWarehousesRequest) ([]model.Warehouse, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	queryBuilder := `
 		SELECT 
 			id, name, code, address, province, 
@@ -803,6 +1103,9 @@ func (r *postgresRepository) ListWarehouses(ctx context.Context, req model.ListW
 
 // GetWarehouseByID implements Repository.GetWarehouseByID
 func (r *postgresRepository) GetWarehouseByID(ctx context.Context, warehouseID uuid.UUID) (*model.Warehouse, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			id, name, code, address, province,
@@ -840,6 +1143,9 @@ func (r *postgresRepository) GetWarehouseByID(ctx context.Context, warehouseID u
 
 // CreateWarehouse implements Repository.CreateWarehouse
 func (r *postgresRepository) CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO warehouses (
 			id, name, code, address, province,
@@ -885,6 +1191,9 @@ func (r *postgresRepository) CreateWarehouse(ctx context.Context, warehouse *mod
 
 // UpdateWarehouse implements Repository.UpdateWarehouse
 func (r *postgresRepository) UpdateWarehouse(ctx context.Context, warehouseID uuid.UUID, warehouse *model.Warehouse) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE warehouses
 		SET 
@@ -938,6 +1247,9 @@ func (r *postgresRepository) UpdateWarehouse(ctx context.Context, warehouseID uu
 
 // DeactivateWarehouse implements Repository.DeactivateWarehouse
 func (r *postgresRepository) DeactivateWarehouse(ctx context.Context, warehouseID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	// Check if warehouse has any inventory with quantity > 0
 	checkQuery := `
 		SELECT EXISTS(
@@ -986,6 +1298,9 @@ func (r *postgresRepository) DeactivateWarehouse(ctx context.Context, warehouseI
 
 // GetWarehouseMetrics implements Repository.GetWarehouseMetrics
 func (r *postgresRepository) GetWarehouseMetrics(ctx context.Context) ([]model.WarehouseMetrics, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			w.id,
@@ -1063,6 +1378,9 @@ func (r *postgresRepository) GetWarehouseMetrics(ctx context.Context) ([]model.W
 
 // GetMovementTrends implements Repository.GetMovementTrends
 func (r *postgresRepository) GetMovementTrends(ctx context.Context, days int) ([]model.MovementTrend, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			DATE(created_at) as period,
@@ -1116,6 +1434,9 @@ func (r *postgresRepository) GetMovementTrends(ctx context.Context, days int) ([
 
 // GetReservationMetrics implements Repository.GetReservationMetrics
 func (r *postgresRepository) GetReservationMetrics(ctx context.Context) (*model.ReservationMetrics, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
 	// Summary query
 	summaryQuery := `
 		SELECT 
@@ -1220,6 +1541,11 @@ func (r *postgresRepository) ReserveStockWithTx(
 	quantity int,
 	userID *uuid.UUID,
 ) error {
+	// Dev / staging: lỗi giả giữa checkout → saga phải release các item đã giữ trước đó
+	if err := chaos.Fault(ctx, chaos.PointReserveStock); err != nil {
+		return err
+	}
+
 	query := `SELECT reserve_stock($1, $2, $3, $4)`
 
 	var success bool
@@ -1230,10 +1556,12 @@ func (r *postgresRepository) ReserveStockWithTx(
 		if errors.As(err, &pgErr) {
 			// Custom error code BIZ01 = insufficient stock
 			if pgErr.Code == "BIZ01" {
+				reserveStockConflicts.WithLabelValues(reserveConflictInsufficientStock).Inc()
 				return model.NewInsufficientStockError(quantity, 0) // Available sẽ ở trong message
 			}
 			// 40001 = serialization_failure (concurrent modification)
 			if pgErr.Code == "40001" {
+				reserveStockConflicts.WithLabelValues(reserveConflictConcurrentUpdate).Inc()
 				return model.ErrOptimisticLockFailed
 			}
 		}
@@ -1271,15 +1599,41 @@ func (r *postgresRepository) ReleaseStockWithTx(
 	return nil
 }
 
+// CompleteSaleWithTx completes sale using provided transaction (payment capture)
+func (r *postgresRepository) CompleteSaleWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	warehouseID uuid.UUID,
+	bookID uuid.UUID,
+	quantity int,
+	userID *uuid.UUID,
+) error {
+	query := `SELECT complete_sale($1, $2, $3, $4)`
+
+	var success bool
+	err := tx.QueryRow(ctx, query, warehouseID, bookID, quantity, userID).Scan(&success)
+
+	if err != nil {
+		return fmt.Errorf("failed to complete sale: %w", err)
+	}
+	if !success {
+		return fmt.Errorf("complete_sale returned false for warehouse=%s, book=%s", warehouseID, bookID)
+	}
+	return nil
+}
+
 // GetAvailableQuantity returns available quantity for a book at a warehouse
 func (r *postgresRepository) GetAvailableQuantity(
 	ctx context.Context,
 	warehouseID uuid.UUID,
 	bookID uuid.UUID,
 ) (int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT available_quantity
-		FROM inventories
+		SELECT quantity - reserved
+		FROM warehouse_inventory
 		WHERE warehouse_id = $1 AND book_id = $2
 	`
 
@@ -1299,6 +1653,9 @@ func (r *postgresRepository) GetAvailableQuantity(
 // Đọc tổng tồn cho 1 book từ view books_total_stock.
 // Nếu không có row (book hiện không có inventory ở bất kỳ kho nào), trả về (nil, nil).
 func (r *postgresRepository) GetBookTotalStock(ctx context.Context, bookID string) (*model.BookTotalStock, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT 
             book_id,
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// reserveStockConflicts reserve_stock() bị từ chối: hết hàng (BIZ01) hoặc tranh chấp ghi đồng thời (40001)
+// Đếm ở repository vì mọi luồng giữ hàng (checkout, đổi kho, giao bù) đều đi qua đây
+var reserveStockConflicts = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "reserve_stock_conflicts_total",
+	Help: "Stock reservations rejected by reserve_stock(), by reason.",
+}, []string{"reason"})
+
+const (
+	reserveConflictInsufficientStock = "insufficient_stock"
+	reserveConflictConcurrentUpdate  = "concurrent_update"
+)
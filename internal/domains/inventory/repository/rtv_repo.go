@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const supplierReturnColumns = `
+	r.id, r.rtv_number, r.warehouse_id, w.name, r.supplier_id, p.name, r.status,
+	r.total_quantity, r.total_value, r.credited_amount, r.note, r.close_note,
+	r.created_by, r.closed_by, r.closed_at, r.created_at, r.updated_at
+`
+
+const supplierReturnFrom = `
+	FROM supplier_returns r
+	JOIN warehouses w ON w.id = r.warehouse_id
+	JOIN publishers p ON p.id = r.supplier_id
+`
+
+// GetRTVBookInfo implements Repository.GetRTVBookInfo
+func (r *postgresRepository) GetRTVBookInfo(ctx context.Context, warehouseID uuid.UUID, bookIDs []uuid.UUID) ([]model.RTVBookInfo, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			b.id, b.title, b.publisher_id, b.cost_price,
+			COALESCE(wi.quantity - wi.reserved, 0),
+			wi.book_id IS NOT NULL
+		FROM books b
+		LEFT JOIN warehouse_inventory wi ON wi.book_id = b.id AND wi.warehouse_id = $1
+		WHERE b.id = ANY($2)
+	`
+	rows, err := r.pool.Query(ctx, query, warehouseID, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rtv book info: %w", err)
+	}
+	defer rows.Close()
+
+	books := make([]model.RTVBookInfo, 0, len(bookIDs))
+	for rows.Next() {
+		var b model.RTVBookInfo
+		if err := rows.Scan(&b.BookID, &b.Title, &b.SupplierID, &b.CostPrice, &b.Available, &b.HasInventory); err != nil {
+			return nil, fmt.Errorf("failed to scan rtv book info: %w", err)
+		}
+		books = append(books, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rtv book info: %w", err)
+	}
+
+	return books, nil
+}
+
+// CreateSupplierReturn implements Repository.CreateSupplierReturn
+func (r *postgresRepository) CreateSupplierReturn(ctx context.Context, rtv *model.SupplierReturn) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	query := `
+		INSERT INTO supplier_returns (
+			id, rtv_number, warehouse_id, supplier_id, status,
+			total_quantity, total_value, note, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at, updated_at
+	`
+	err = tx.QueryRow(ctx, query,
+		rtv.ID,
+		rtv.RTVNumber,
+		rtv.WarehouseID,
+		rtv.SupplierID,
+		rtv.Status,
+		rtv.TotalQuantity,
+		rtv.TotalValue,
+		rtv.Note,
+		rtv.CreatedBy,
+	).Scan(&rtv.CreatedAt, &rtv.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create supplier return: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO supplier_return_items (return_id, book_id, quantity, unit_cost, condition)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	// Chỉ trừ phần khả dụng (quantity - reserved): hàng đang giữ cho đơn không được trả NCC
+	stockQuery := `
+		UPDATE warehouse_inventory
+		SET
+			quantity = quantity - $3,
+			version = version + 1,
+			updated_by = $4,
+			updated_at = NOW()
+		WHERE warehouse_id = $1
+		  AND book_id = $2
+		  AND quantity - reserved >= $3
+	`
+	// Trigger ghi audit row (NOW() = thời điểm bắt đầu transaction) → gắn action RTV + số phiếu
+	auditQuery := `
+		UPDATE inventory_audit_log
+		SET action = 'RTV', reason = $3
+		WHERE warehouse_id = $1
+		  AND book_id = $2
+		  AND created_at = NOW()
+	`
+
+	for i := range rtv.Items {
+		item := &rtv.Items[i]
+		item.ReturnID = rtv.ID
+
+		if err := tx.QueryRow(ctx, itemQuery, rtv.ID, item.BookID, item.Quantity, item.UnitCost, item.Condition).Scan(&item.ID); err != nil {
+			return fmt.Errorf("failed to create supplier return item: %w", err)
+		}
+
+		tag, err := tx.Exec(ctx, stockQuery, rtv.WarehouseID, item.BookID, item.Quantity, rtv.CreatedBy)
+		if err != nil {
+			return fmt.Errorf("failed to decrement stock for supplier return: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: book %s", model.ErrInsufficientStock, item.BookID)
+		}
+
+		reason := fmt.Sprintf("%s (%s)", rtv.RTVNumber, item.Condition)
+		if _, err := tx.Exec(ctx, auditQuery, rtv.WarehouseID, item.BookID, reason); err != nil {
+			return fmt.Errorf("failed to tag rtv audit log: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetSupplierReturn implements Repository.GetSupplierReturn
+func (r *postgresRepository) GetSupplierReturn(ctx context.Context, id uuid.UUID) (*model.SupplierReturn, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + supplierReturnColumns + supplierReturnFrom + ` WHERE r.id = $1`
+
+	rtv, err := scanSupplierReturn(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrRTVNotFound
+		}
+		return nil, fmt.Errorf("failed to get supplier return: %w", err)
+	}
+
+	itemsQuery := `
+		SELECT i.id, i.return_id, i.book_id, b.title, i.quantity, i.unit_cost, i.condition
+		FROM supplier_return_items i
+		JOIN books b ON b.id = i.book_id
+		WHERE i.return_id = $1
+		ORDER BY b.title
+	`
+	rows, err := r.pool.Query(ctx, itemsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier return items: %w", err)
+	}
+	rtv.Items = make([]model.SupplierReturnItem, 0)
+	for rows.Next() {
+		var item model.SupplierReturnItem
+		if err := rows.Scan(&item.ID, &item.ReturnID, &item.BookID, &item.BookTitle, &item.Quantity, &item.UnitCost, &item.Condition); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan supplier return item: %w", err)
+		}
+		rtv.Items = append(rtv.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating supplier return items: %w", err)
+	}
+
+	notesQuery := `
+		SELECT id, return_id, credit_note_number, amount, issued_date, note, recorded_by, created_at
+		FROM supplier_credit_notes
+		WHERE return_id = $1
+		ORDER BY issued_date, created_at
+	`
+	rows, err = r.pool.Query(ctx, notesQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier credit notes: %w", err)
+	}
+	defer rows.Close()
+
+	rtv.CreditNotes = make([]model.SupplierCreditNote, 0)
+	for rows.Next() {
+		var note model.SupplierCreditNote
+		if err := rows.Scan(&note.ID, &note.ReturnID, &note.CreditNoteNumber, &note.Amount, &note.IssuedDate, &note.Note, &note.RecordedBy, &note.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan supplier credit note: %w", err)
+		}
+		rtv.CreditNotes = append(rtv.CreditNotes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating supplier credit notes: %w", err)
+	}
+
+	return rtv, nil
+}
+
+// ListSupplierReturns implements Repository.ListSupplierReturns
+func (r *postgresRepository) ListSupplierReturns(ctx context.Context, filter model.ListRTVRequest) ([]model.SupplierReturn, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	where := `
+		WHERE ($1::text = '' OR r.status = $1)
+		  AND ($2::uuid IS NULL OR r.supplier_id = $2)
+		  AND ($3::uuid IS NULL OR r.warehouse_id = $3)
+	`
+	args := []interface{}{filter.Status, filter.SupplierID, filter.WarehouseID}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM supplier_returns r` + where
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count supplier returns: %w", err)
+	}
+
+	query := `SELECT ` + supplierReturnColumns + supplierReturnFrom + where + `
+		ORDER BY r.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := r.pool.Query(ctx, query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list supplier returns: %w", err)
+	}
+	defer rows.Close()
+
+	returns := make([]model.SupplierReturn, 0)
+	for rows.Next() {
+		rtv, err := scanSupplierReturn(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan supplier return: %w", err)
+		}
+		returns = append(returns, *rtv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating supplier returns: %w", err)
+	}
+
+	return returns, total, nil
+}
+
+// AddCreditNote implements Repository.AddCreditNote
+func (r *postgresRepository) AddCreditNote(ctx context.Context, note *model.SupplierCreditNote) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Lock phiếu: 2 kế toán nhập credit note cùng lúc vẫn cộng đúng credited_amount
+	var rtv model.SupplierReturn
+	lockQuery := `
+		SELECT status, total_value, credited_amount
+		FROM supplier_returns
+		WHERE id = $1
+		FOR UPDATE
+	`
+	if err := tx.QueryRow(ctx, lockQuery, note.ReturnID).Scan(&rtv.Status, &rtv.TotalValue, &rtv.CreditedAmount); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrRTVNotFound
+		}
+		return fmt.Errorf("failed to lock supplier return: %w", err)
+	}
+	if !rtv.AcceptsCreditNotes() {
+		return model.ErrRTVClosed
+	}
+
+	insertQuery := `
+		INSERT INTO supplier_credit_notes (return_id, credit_note_number, amount, issued_date, note, recorded_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, insertQuery,
+		note.ReturnID,
+		note.CreditNoteNumber,
+		note.Amount,
+		note.IssuedDate,
+		note.Note,
+		note.RecordedBy,
+	).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // uq_supplier_credit_notes_number
+			return model.ErrCreditNoteExists
+		}
+		return fmt.Errorf("failed to create credit note: %w", err)
+	}
+
+	credited := rtv.CreditedAmount.Add(note.Amount)
+	updateQuery := `
+		UPDATE supplier_returns
+		SET credited_amount = $2, status = $3
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, updateQuery, note.ReturnID, credited, rtv.StatusForCredited(credited)); err != nil {
+		return fmt.Errorf("failed to update supplier return credit: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CloseSupplierReturn implements Repository.CloseSupplierReturn
+func (r *postgresRepository) CloseSupplierReturn(ctx context.Context, id, closedBy uuid.UUID, note string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE supplier_returns
+		SET status = 'closed', close_note = $3, closed_by = $2, closed_at = NOW()
+		WHERE id = $1 AND status IN ('open', 'partially_credited')
+	`
+	tag, err := r.pool.Exec(ctx, query, id, closedBy, note)
+	if err != nil {
+		return fmt.Errorf("failed to close supplier return: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM supplier_returns WHERE id = $1)", id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check supplier return existence: %w", err)
+	}
+	if !exists {
+		return model.ErrRTVNotFound
+	}
+	return model.ErrRTVNotClosable
+}
+
+// GetRTVSupplierStats implements Repository.GetRTVSupplierStats
+func (r *postgresRepository) GetRTVSupplierStats(ctx context.Context, from, to time.Time) ([]model.SupplierRTVStat, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH items AS (
+			SELECT
+				return_id,
+				COALESCE(SUM(quantity) FILTER (WHERE condition = 'damaged'), 0) AS damaged_qty,
+				COALESCE(SUM(quantity) FILTER (WHERE condition = 'excess'), 0) AS excess_qty
+			FROM supplier_return_items
+			GROUP BY return_id
+		)
+		SELECT
+			r.supplier_id, p.name,
+			COUNT(*),
+			SUM(r.total_quantity),
+			SUM(i.damaged_qty),
+			SUM(i.excess_qty),
+			SUM(r.total_value),
+			SUM(r.credited_amount),
+			COALESCE(SUM(GREATEST(r.total_value - r.credited_amount, 0)) FILTER (WHERE r.status <> 'closed'), 0)
+		FROM supplier_returns r
+		JOIN publishers p ON p.id = r.supplier_id
+		JOIN items i ON i.return_id = r.id
+		WHERE r.created_at >= $1 AND r.created_at < $2
+		GROUP BY r.supplier_id, p.name
+		ORDER BY SUM(r.total_value) DESC
+	`
+	rows, err := r.pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rtv supplier stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]model.SupplierRTVStat, 0)
+	for rows.Next() {
+		var st model.SupplierRTVStat
+		if err := rows.Scan(
+			&st.SupplierID,
+			&st.SupplierName,
+			&st.Returns,
+			&st.Quantity,
+			&st.DamagedQty,
+			&st.ExcessQty,
+			&st.TotalValue,
+			&st.CreditedAmount,
+			&st.Outstanding,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan rtv supplier stat: %w", err)
+		}
+		stats = append(stats, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rtv supplier stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func scanSupplierReturn(row pgx.Row) (*model.SupplierReturn, error) {
+	var rtv model.SupplierReturn
+	err := row.Scan(
+		&rtv.ID,
+		&rtv.RTVNumber,
+		&rtv.WarehouseID,
+		&rtv.WarehouseName,
+		&rtv.SupplierID,
+		&rtv.SupplierName,
+		&rtv.Status,
+		&rtv.TotalQuantity,
+		&rtv.TotalValue,
+		&rtv.CreditedAmount,
+		&rtv.Note,
+		&rtv.CloseNote,
+		&rtv.CreatedBy,
+		&rtv.ClosedBy,
+		&rtv.ClosedAt,
+		&rtv.CreatedAt,
+		&rtv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rtv, nil
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const serialUnitColumns = `
+	u.id, u.serial_number, u.lot_number, u.book_id, b.title, u.warehouse_id, w.name,
+	u.status, u.order_id, o.order_number, u.order_item_id,
+	u.received_by, u.received_at, u.assigned_by, u.assigned_at, u.created_at, u.updated_at
+`
+
+const serialUnitFrom = `
+	FROM inventory_serial_units u
+	JOIN books b ON b.id = u.book_id
+	JOIN warehouses w ON w.id = u.warehouse_id
+	LEFT JOIN orders o ON o.id = u.order_id
+`
+
+// RestockWithSerials implements Repository.RestockWithSerials
+func (r *postgresRepository) RestockWithSerials(ctx context.Context, inventory *model.Inventory, serials []string, lotNumber *string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	query := `
+		UPDATE warehouse_inventory
+		SET
+			quantity = $3,
+			last_restocked_at = $4,
+			version = version + 1,
+			updated_by = $5,
+			updated_at = NOW()
+		WHERE warehouse_id = $1
+		  AND book_id = $2
+		  AND version = $6  -- Optimistic lock check
+		RETURNING version, updated_at
+	`
+	err = tx.QueryRow(ctx, query,
+		inventory.WarehouseID,
+		inventory.BookID,
+		inventory.Quantity,
+		inventory.LastRestockAt,
+		inventory.UpdatedBy,
+		inventory.Version,
+	).Scan(&inventory.Version, &inventory.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return r.updateMissError(ctx, inventory.WarehouseID, inventory.BookID)
+		}
+		return fmt.Errorf("failed to restock inventory: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO inventory_serial_units (serial_number, lot_number, book_id, warehouse_id, received_by)
+		SELECT s, $2, $3, $4, $5 FROM UNNEST($1::text[]) AS s
+	`
+	_, err = tx.Exec(ctx, insertQuery, serials, lotNumber, inventory.BookID, inventory.WarehouseID, inventory.UpdatedBy)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // uq_serial_units_serial
+			return r.existingSerialsError(ctx, serials)
+		}
+		return fmt.Errorf("failed to register serial units: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// existingSerialsError liệt kê serial đã đăng ký để người nhập hàng sửa đúng cuốn
+func (r *postgresRepository) existingSerialsError(ctx context.Context, serials []string) error {
+	rows, err := r.pool.Query(ctx, `
+		SELECT serial_number FROM inventory_serial_units
+		WHERE UPPER(serial_number) IN (SELECT UPPER(s) FROM UNNEST($1::text[]) AS s)
+		ORDER BY serial_number
+		LIMIT 20
+	`, serials)
+	if err != nil {
+		return model.ErrSerialExists
+	}
+	defer rows.Close()
+
+	existing := make([]string, 0)
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return model.ErrSerialExists
+		}
+		existing = append(existing, s)
+	}
+	if len(existing) == 0 {
+		return model.ErrSerialExists
+	}
+	return fmt.Errorf("%w: %s", model.ErrSerialExists, strings.Join(existing, ", "))
+}
+
+// GetSerialUnit implements Repository.GetSerialUnit
+func (r *postgresRepository) GetSerialUnit(ctx context.Context, serial string) (*model.SerialUnit, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + serialUnitColumns + serialUnitFrom + `WHERE UPPER(u.serial_number) = UPPER($1)`
+
+	unit, err := scanSerialUnit(r.pool.QueryRow(ctx, query, serial))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrSerialNotFound
+		}
+		return nil, fmt.Errorf("failed to get serial unit: %w", err)
+	}
+	return unit, nil
+}
+
+// ListSerialUnits implements Repository.ListSerialUnits
+func (r *postgresRepository) ListSerialUnits(ctx context.Context, filter model.ListSerialsRequest) ([]model.SerialUnit, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	where := `
+		WHERE ($1::uuid IS NULL OR u.warehouse_id = $1)
+		  AND ($2::uuid IS NULL OR u.book_id = $2)
+		  AND ($3::uuid IS NULL OR u.order_id = $3)
+		  AND ($4::text = '' OR u.lot_number = $4)
+		  AND ($5::text = '' OR u.status = $5)
+		  AND ($6::uuid[] IS NULL OR u.warehouse_id = ANY($6))
+	`
+	args := []interface{}{filter.WarehouseID, filter.BookID, filter.OrderID, filter.LotNumber, filter.Status, filter.ScopeWarehouseIDs}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM inventory_serial_units u`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count serial units: %w", err)
+	}
+
+	query := `SELECT ` + serialUnitColumns + serialUnitFrom + where + `
+		ORDER BY u.received_at DESC, u.serial_number
+		LIMIT $7 OFFSET $8
+	`
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list serial units: %w", err)
+	}
+	defer rows.Close()
+
+	units := make([]model.SerialUnit, 0)
+	for rows.Next() {
+		unit, err := scanSerialUnit(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan serial unit: %w", err)
+		}
+		units = append(units, *unit)
+	}
+	return units, total, rows.Err()
+}
+
+// GetSerialOrderItem implements Repository.GetSerialOrderItem
+func (r *postgresRepository) GetSerialOrderItem(ctx context.Context, orderID, orderItemID uuid.UUID) (*model.SerialOrderItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	return getSerialOrderItem(ctx, r.pool, orderID, orderItemID, false)
+}
+
+// AssignSerials implements Repository.AssignSerials
+func (r *postgresRepository) AssignSerials(ctx context.Context, item *model.SerialOrderItem, serials []string, assignedBy uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Khoá order item: 2 người soạn cùng lúc không gán quá số lượng
+	locked, err := getSerialOrderItem(ctx, tx, item.OrderID, item.OrderItemID, true)
+	if err != nil {
+		return err
+	}
+	if locked.Assigned+len(serials) > locked.Quantity {
+		return fmt.Errorf("%w: quantity=%d, already assigned=%d, requested=%d",
+			model.ErrSerialOverAssign, locked.Quantity, locked.Assigned, len(serials))
+	}
+
+	query := `
+		UPDATE inventory_serial_units
+		SET status = 'assigned', order_id = $2, order_item_id = $3,
+		    assigned_by = $4, assigned_at = NOW()
+		WHERE UPPER(serial_number) IN (SELECT UPPER(s) FROM UNNEST($1::text[]) AS s)
+		  AND book_id = $5
+		  AND status = 'in_stock'
+		  AND ($6::uuid IS NULL OR warehouse_id = $6)
+		RETURNING serial_number
+	`
+	rows, err := tx.Query(ctx, query, serials, locked.OrderID, locked.OrderItemID, assignedBy, locked.BookID, locked.WarehouseID)
+	if err != nil {
+		return fmt.Errorf("failed to assign serial units: %w", err)
+	}
+	assigned := make(map[string]struct{}, len(serials))
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan assigned serial: %w", err)
+		}
+		assigned[strings.ToUpper(s)] = struct{}{}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to assign serial units: %w", err)
+	}
+
+	if len(assigned) != len(serials) {
+		missing := make([]string, 0)
+		for _, s := range serials {
+			if _, ok := assigned[strings.ToUpper(s)]; !ok {
+				missing = append(missing, s)
+			}
+		}
+		return fmt.Errorf("%w: %s", model.ErrSerialNotAvailable, strings.Join(missing, ", "))
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UnassignSerial implements Repository.UnassignSerial
+func (r *postgresRepository) UnassignSerial(ctx context.Context, orderItemID uuid.UUID, serial string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE inventory_serial_units
+		SET status = 'in_stock', order_id = NULL, order_item_id = NULL,
+		    assigned_by = NULL, assigned_at = NULL
+		WHERE order_item_id = $1
+		  AND UPPER(serial_number) = UPPER($2)
+	`
+	result, err := r.pool.Exec(ctx, query, orderItemID, serial)
+	if err != nil {
+		return fmt.Errorf("failed to unassign serial unit: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrSerialNotFound
+	}
+	return nil
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func getSerialOrderItem(ctx context.Context, q queryRower, orderID, orderItemID uuid.UUID, forUpdate bool) (*model.SerialOrderItem, error) {
+	query := `
+		SELECT oi.order_id, oi.id, oi.book_id, oi.quantity, o.warehouse_id, o.status,
+		       (SELECT COUNT(*) FROM inventory_serial_units u WHERE u.order_item_id = oi.id)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE oi.order_id = $1 AND oi.id = $2
+	`
+	if forUpdate {
+		query += ` FOR UPDATE OF oi`
+	}
+
+	var item model.SerialOrderItem
+	err := q.QueryRow(ctx, query, orderID, orderItemID).Scan(
+		&item.OrderID, &item.OrderItemID, &item.BookID, &item.Quantity,
+		&item.WarehouseID, &item.OrderStatus, &item.Assigned,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrOrderItemNotFound
+		}
+		return nil, fmt.Errorf("failed to get order item: %w", err)
+	}
+	return &item, nil
+}
+
+func scanSerialUnit(row pgx.Row) (*model.SerialUnit, error) {
+	var u model.SerialUnit
+	err := row.Scan(
+		&u.ID, &u.SerialNumber, &u.LotNumber, &u.BookID, &u.BookTitle, &u.WarehouseID, &u.WarehouseName,
+		&u.Status, &u.OrderID, &u.OrderNumber, &u.OrderItemID,
+		&u.ReceivedBy, &u.ReceivedAt, &u.AssignedBy, &u.AssignedAt, &u.CreatedAt, &u.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,412 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const stockTransferColumns = `
+	t.id, t.transfer_number, t.source_warehouse_id, sw.name, t.destination_warehouse_id, dw.name,
+	t.status, t.total_quantity, t.note, t.cancel_reason,
+	t.created_by, t.shipped_by, t.shipped_at, t.received_by, t.received_at,
+	t.cancelled_by, t.cancelled_at, t.created_at, t.updated_at
+`
+
+const stockTransferFrom = `
+	FROM stock_transfers t
+	JOIN warehouses sw ON sw.id = t.source_warehouse_id
+	JOIN warehouses dw ON dw.id = t.destination_warehouse_id
+`
+
+// Trigger log_inventory_change() ghi audit row (NOW() = thời điểm bắt đầu transaction)
+// → gắn action TRANSFER_OUT / TRANSFER_IN + số phiếu, giống RTV
+const transferAuditQuery = `
+	UPDATE inventory_audit_log
+	SET action = $3, reason = $4
+	WHERE warehouse_id = $1
+	  AND book_id = $2
+	  AND created_at = NOW()
+`
+
+// CreateStockTransfer implements Repository.CreateStockTransfer
+func (r *postgresRepository) CreateStockTransfer(ctx context.Context, transfer *model.StockTransfer) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	query := `
+		INSERT INTO stock_transfers (
+			id, transfer_number, source_warehouse_id, destination_warehouse_id,
+			status, total_quantity, note, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at
+	`
+	err = tx.QueryRow(ctx, query,
+		transfer.ID,
+		transfer.TransferNumber,
+		transfer.SourceWarehouseID,
+		transfer.DestinationWarehouseID,
+		transfer.Status,
+		transfer.TotalQuantity,
+		transfer.Note,
+		transfer.CreatedBy,
+	).Scan(&transfer.CreatedAt, &transfer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create stock transfer: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO stock_transfer_items (transfer_id, book_id, quantity)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	for i := range transfer.Items {
+		item := &transfer.Items[i]
+		item.TransferID = transfer.ID
+
+		if err := tx.QueryRow(ctx, itemQuery, transfer.ID, item.BookID, item.Quantity).Scan(&item.ID); err != nil {
+			return fmt.Errorf("failed to create stock transfer item: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetStockTransfer implements Repository.GetStockTransfer
+func (r *postgresRepository) GetStockTransfer(ctx context.Context, id uuid.UUID) (*model.StockTransfer, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + stockTransferColumns + stockTransferFrom + ` WHERE t.id = $1`
+
+	transfer, err := scanStockTransfer(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get stock transfer: %w", err)
+	}
+
+	items, err := listStockTransferItems(ctx, r.pool, id)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Items = items
+
+	return transfer, nil
+}
+
+// ListStockTransfers implements Repository.ListStockTransfers
+func (r *postgresRepository) ListStockTransfers(ctx context.Context, filter model.ListTransfersRequest) ([]model.StockTransfer, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	// $2 = direction: out → kho là nguồn, in → kho là đích, rỗng → cả 2
+	where := `
+		WHERE ($1::text = '' OR t.status = $1)
+		  AND ($3::uuid IS NULL
+		       OR ($2::text IN ('', 'out') AND t.source_warehouse_id = $3)
+		       OR ($2::text IN ('', 'in') AND t.destination_warehouse_id = $3))
+		  AND ($4::uuid[] IS NULL
+		       OR t.source_warehouse_id = ANY($4)
+		       OR t.destination_warehouse_id = ANY($4))
+	`
+	args := []interface{}{filter.Status, filter.Direction, filter.WarehouseID, filter.ScopeWarehouseIDs}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM stock_transfers t` + where
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count stock transfers: %w", err)
+	}
+
+	query := `SELECT ` + stockTransferColumns + stockTransferFrom + where + `
+		ORDER BY t.created_at DESC
+		LIMIT $5 OFFSET $6
+	`
+	rows, err := r.pool.Query(ctx, query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list stock transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]model.StockTransfer, 0)
+	for rows.Next() {
+		transfer, err := scanStockTransfer(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan stock transfer: %w", err)
+		}
+		transfers = append(transfers, *transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating stock transfers: %w", err)
+	}
+
+	return transfers, total, nil
+}
+
+// ShipStockTransfer implements Repository.ShipStockTransfer
+func (r *postgresRepository) ShipStockTransfer(ctx context.Context, id, shippedBy uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	transfer, err := lockStockTransfer(ctx, tx, id, model.TransferStatusPending)
+	if err != nil {
+		return err
+	}
+
+	// Chỉ trừ phần khả dụng (quantity - reserved): hàng đang giữ cho đơn không được chuyển đi
+	stockQuery := `
+		UPDATE warehouse_inventory
+		SET
+			quantity = quantity - $3,
+			version = version + 1,
+			updated_by = $4,
+			updated_at = NOW()
+		WHERE warehouse_id = $1
+		  AND book_id = $2
+		  AND quantity - reserved >= $3
+	`
+	for _, item := range transfer.Items {
+		tag, err := tx.Exec(ctx, stockQuery, transfer.SourceWarehouseID, item.BookID, item.Quantity, shippedBy)
+		if err != nil {
+			return fmt.Errorf("failed to decrement source stock: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: book %s", model.ErrInsufficientStock, item.BookID)
+		}
+		if _, err := tx.Exec(ctx, transferAuditQuery, transfer.SourceWarehouseID, item.BookID,
+			model.AuditActionTransferOut, transfer.TransferNumber); err != nil {
+			return fmt.Errorf("failed to tag transfer audit log: %w", err)
+		}
+	}
+
+	statusQuery := `
+		UPDATE stock_transfers
+		SET status = $2, shipped_by = $3, shipped_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, statusQuery, id, model.TransferStatusInTransit, shippedBy); err != nil {
+		return fmt.Errorf("failed to mark stock transfer in transit: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReceiveStockTransfer implements Repository.ReceiveStockTransfer
+func (r *postgresRepository) ReceiveStockTransfer(ctx context.Context, id, receivedBy uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	transfer, err := lockStockTransfer(ctx, tx, id, model.TransferStatusInTransit)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range transfer.Items {
+		if err := addTransferStock(ctx, tx, transfer.DestinationWarehouseID, item, receivedBy, transfer.TransferNumber); err != nil {
+			return err
+		}
+	}
+
+	statusQuery := `
+		UPDATE stock_transfers
+		SET status = $2, received_by = $3, received_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, statusQuery, id, model.TransferStatusReceived, receivedBy); err != nil {
+		return fmt.Errorf("failed to mark stock transfer received: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CancelStockTransfer implements Repository.CancelStockTransfer
+func (r *postgresRepository) CancelStockTransfer(ctx context.Context, id, cancelledBy uuid.UUID, reason string) (restocked bool, err error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	transfer, err := lockStockTransfer(ctx, tx, id, model.TransferStatusPending, model.TransferStatusInTransit)
+	if err != nil {
+		return false, err
+	}
+
+	// Đã xuất kho → hoàn lại kho nguồn
+	restocked = transfer.Status == model.TransferStatusInTransit
+	if restocked {
+		auditReason := transfer.TransferNumber + " (cancelled)"
+		for _, item := range transfer.Items {
+			if err := addTransferStock(ctx, tx, transfer.SourceWarehouseID, item, cancelledBy, auditReason); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	statusQuery := `
+		UPDATE stock_transfers
+		SET status = $2, cancel_reason = $3, cancelled_by = $4, cancelled_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, statusQuery, id, model.TransferStatusCancelled, reason, cancelledBy); err != nil {
+		return false, fmt.Errorf("failed to cancel stock transfer: %w", err)
+	}
+
+	return restocked, tx.Commit(ctx)
+}
+
+// lockStockTransfer khoá phiếu + lấy items; status phải thuộc allowed
+// 2 nhân viên bấm "nhận hàng" cùng lúc → người sau nhận ErrTransferStatusConflict, không cộng kho 2 lần
+func lockStockTransfer(ctx context.Context, tx pgx.Tx, id uuid.UUID, allowed ...string) (*model.StockTransfer, error) {
+	var transfer model.StockTransfer
+	lockQuery := `
+		SELECT id, transfer_number, source_warehouse_id, destination_warehouse_id, status
+		FROM stock_transfers
+		WHERE id = $1
+		FOR UPDATE
+	`
+	err := tx.QueryRow(ctx, lockQuery, id).Scan(
+		&transfer.ID,
+		&transfer.TransferNumber,
+		&transfer.SourceWarehouseID,
+		&transfer.DestinationWarehouseID,
+		&transfer.Status,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to lock stock transfer: %w", err)
+	}
+
+	permitted := false
+	for _, status := range allowed {
+		if transfer.Status == status {
+			permitted = true
+			break
+		}
+	}
+	if !permitted {
+		return nil, fmt.Errorf("%w: transfer is %s", model.ErrTransferStatusConflict, transfer.Status)
+	}
+
+	items, err := listStockTransferItems(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Items = items
+
+	return &transfer, nil
+}
+
+// addTransferStock cộng tồn tại kho (tạo dòng tồn nếu kho chưa có sách) + gắn audit TRANSFER_IN
+func addTransferStock(ctx context.Context, tx pgx.Tx, warehouseID uuid.UUID, item model.StockTransferItem, updatedBy uuid.UUID, auditReason string) error {
+	query := `
+		INSERT INTO warehouse_inventory (warehouse_id, book_id, quantity, reserved, last_restocked_at, updated_by)
+		VALUES ($1, $2, $3, 0, NOW(), $4)
+		ON CONFLICT (warehouse_id, book_id) DO UPDATE
+		SET
+			quantity = warehouse_inventory.quantity + EXCLUDED.quantity,
+			version = warehouse_inventory.version + 1,
+			last_restocked_at = NOW(),
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+	`
+	if _, err := tx.Exec(ctx, query, warehouseID, item.BookID, item.Quantity, updatedBy); err != nil {
+		return fmt.Errorf("failed to increment stock for transfer: %w", err)
+	}
+	if _, err := tx.Exec(ctx, transferAuditQuery, warehouseID, item.BookID, model.AuditActionTransferIn, auditReason); err != nil {
+		return fmt.Errorf("failed to tag transfer audit log: %w", err)
+	}
+	return nil
+}
+
+// listStockTransferItems dùng chung cho pool (đọc) và tx (đang khoá phiếu)
+func listStockTransferItems(ctx context.Context, q interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}, transferID uuid.UUID) ([]model.StockTransferItem, error) {
+	query := `
+		SELECT i.id, i.transfer_id, i.book_id, b.title, i.quantity
+		FROM stock_transfer_items i
+		JOIN books b ON b.id = i.book_id
+		WHERE i.transfer_id = $1
+		ORDER BY b.title
+	`
+	rows, err := q.Query(ctx, query, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock transfer items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.StockTransferItem, 0)
+	for rows.Next() {
+		var item model.StockTransferItem
+		if err := rows.Scan(&item.ID, &item.TransferID, &item.BookID, &item.BookTitle, &item.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan stock transfer item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stock transfer items: %w", err)
+	}
+
+	return items, nil
+}
+
+func scanStockTransfer(row pgx.Row) (*model.StockTransfer, error) {
+	var t model.StockTransfer
+	err := row.Scan(
+		&t.ID,
+		&t.TransferNumber,
+		&t.SourceWarehouseID,
+		&t.SourceWarehouseName,
+		&t.DestinationWarehouseID,
+		&t.DestinationWarehouseName,
+		&t.Status,
+		&t.TotalQuantity,
+		&t.Note,
+		&t.CancelReason,
+		&t.CreatedBy,
+		&t.ShippedBy,
+		&t.ShippedAt,
+		&t.ReceivedBy,
+		&t.ReceivedAt,
+		&t.CancelledBy,
+		&t.CancelledAt,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/logger"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// LOW STOCK ALERT RECIPIENTS
+// ========================================
+
+// Người nhận cảnh báo tồn thấp theo kho; gửi do worker inventory:dispatch_low_stock_alerts
+// (immediate) và inventory:send_low_stock_digest (digest theo giờ)
+
+// CreateAlertRecipient - đăng ký email / Slack webhook nhận cảnh báo
+func (s *InventoryService) CreateAlertRecipient(ctx context.Context, req model.CreateAlertRecipientRequest) (*model.AlertRecipient, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	recipient := &model.AlertRecipient{
+		WarehouseID: req.WarehouseID,
+		Channel:     req.Channel,
+		Target:      req.Target,
+		Mode:        req.Mode,
+		IsActive:    true,
+	}
+	if req.CreatedBy != uuid.Nil {
+		recipient.CreatedBy = &req.CreatedBy
+	}
+
+	if err := s.repo.CreateAlertRecipient(ctx, recipient); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Low stock alert recipient created", map[string]interface{}{
+		"recipient_id": recipient.ID,
+		"warehouse_id": recipient.WarehouseID,
+		"channel":      recipient.Channel,
+		"mode":         recipient.Mode,
+	})
+	return s.repo.GetAlertRecipient(ctx, recipient.ID)
+}
+
+// ListAlertRecipients - danh sách người nhận (lọc theo kho gồm cả người nhận mọi kho)
+func (s *InventoryService) ListAlertRecipients(ctx context.Context, req model.ListAlertRecipientsRequest) ([]model.AlertRecipient, error) {
+	return s.repo.ListAlertRecipients(ctx, req)
+}
+
+// UpdateAlertRecipient - đổi target / mode / bật tắt; đổi kho hoặc kênh thì xoá tạo lại
+func (s *InventoryService) UpdateAlertRecipient(ctx context.Context, id uuid.UUID, req model.UpdateAlertRecipientRequest) (*model.AlertRecipient, error) {
+	recipient, err := s.repo.GetAlertRecipient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.Apply(recipient); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateAlertRecipient(ctx, recipient); err != nil {
+		return nil, err
+	}
+	return recipient, nil
+}
+
+// DeleteAlertRecipient - xoá người nhận
+func (s *InventoryService) DeleteAlertRecipient(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteAlertRecipient(ctx, id)
+}
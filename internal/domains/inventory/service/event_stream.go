@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// InventoryEventChannel - channel NOTIFY từ trigger notify_inventory_event() (migration 000055)
+	InventoryEventChannel = "inventory_events"
+
+	// subscriberBuffer - số event tối đa chờ gửi cho 1 client, client chậm hơn sẽ bị drop event
+	subscriberBuffer = 64
+
+	maxListenBackoff = 30 * time.Second
+)
+
+// EventStream LISTEN channel inventory_events trên 1 connection riêng và fan-out cho các subscriber (SSE)
+//
+// WHY: trigger audit log bắt mọi thay đổi tồn kho (kể cả từ order flow/job không qua InventoryService),
+// NOTIFY chỉ phát khi commit → admin dashboard cập nhật realtime thay vì polling
+type EventStream struct {
+	pool *pgxpool.Pool
+
+	mu   sync.RWMutex
+	subs map[*eventSubscriber]struct{}
+}
+
+type eventSubscriber struct {
+	ch         chan model.InventoryEvent
+	warehouses map[uuid.UUID]struct{} // nil = mọi kho
+}
+
+func NewEventStream(pool *pgxpool.Pool) *EventStream {
+	return &EventStream{
+		pool: pool,
+		subs: make(map[*eventSubscriber]struct{}),
+	}
+}
+
+// Subscribe đăng ký nhận event của các kho warehouseIDs (nil = mọi kho, slice rỗng = không kho nào)
+// Caller phải gọi hàm unsubscribe trả về khi client ngắt kết nối
+func (s *EventStream) Subscribe(warehouseIDs []uuid.UUID) (<-chan model.InventoryEvent, func()) {
+	sub := &eventSubscriber{ch: make(chan model.InventoryEvent, subscriberBuffer)}
+	if warehouseIDs != nil {
+		sub.warehouses = make(map[uuid.UUID]struct{}, len(warehouseIDs))
+		for _, id := range warehouseIDs {
+			sub.warehouses[id] = struct{}{}
+		}
+	}
+
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, sub)
+			close(sub.ch)
+			s.mu.Unlock()
+		})
+	}
+}
+
+// Run giữ LISTEN tới khi ctx bị cancel, tự kết nối lại (exponential backoff) khi mất connection
+// Event phát ra trong lúc mất kết nối sẽ bị mất → client nên reload snapshot khi reconnect
+func (s *EventStream) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		listened, err := s.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if listened {
+			backoff = time.Second
+		}
+		logger.Error("inventory event stream disconnected", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+// listen chiếm 1 connection khỏi pool (Hijack) để LISTEN, trả về listened = true nếu LISTEN thành công
+func (s *EventStream) listen(ctx context.Context) (bool, error) {
+	pooled, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("acquire listen connection: %w", err)
+	}
+	// Connection ở trạng thái LISTEN không được trả lại pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+InventoryEventChannel); err != nil {
+		return false, fmt.Errorf("listen %s: %w", InventoryEventChannel, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		var event model.InventoryEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			logger.Error("invalid inventory event payload", err)
+			continue
+		}
+		s.publish(event)
+	}
+}
+
+// publish gửi non-blocking: subscriber đầy buffer bị bỏ qua event này, không chặn các subscriber khác
+func (s *EventStream) publish(event model.InventoryEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for sub := range s.subs {
+		if sub.warehouses != nil {
+			if _, ok := sub.warehouses[event.WarehouseID]; !ok {
+				continue
+			}
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
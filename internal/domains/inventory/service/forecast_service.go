@@ -0,0 +1,313 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// DEMAND FORECAST (dự báo nhu cầu)
+// ========================================
+// Model cho mỗi (sách, kho), số bán theo tuần y_t:
+//   - chỉ số mùa vụ s_m theo tháng = TB tuần của tháng m / TB mọi tuần (chỉ khi >= 52 tuần lịch sử)
+//   - mức nền: exponential smoothing trên chuỗi đã khử mùa vụ y_t / s_m
+//   - dự báo tuần h: level × s_m(tuần h)
+//   - khoảng 95%: ± z × sigma × sqrt(1 + (h-1)·alpha²), sigma = sai số dự báo 1 bước trên lịch sử
+// Đủ cho gợi ý nhập hàng và nhân viên kho hiểu được; không thay cho model theo khuyến mãi / ra mắt sách.
+
+// minSeasonalIndex tránh chia 0 khi khử mùa vụ (tháng không bán được cuốn nào)
+const minSeasonalIndex = 0.1
+
+// demandSeries chuỗi số bán theo tuần của 1 (sách, kho)
+type demandSeries struct {
+	bookID      uuid.UUID
+	warehouseID uuid.UUID
+	byWeek      map[time.Time]int
+	firstWeek   time.Time
+}
+
+// ComputeDemandForecasts - job hàng tuần: fit model cho mọi (sách, kho) có lịch sử, ghi đè demand_forecasts
+func (s *InventoryService) ComputeDemandForecasts(ctx context.Context) (*model.ForecastRunStats, error) {
+	now := time.Now().UTC()
+	currentWeek := model.WeekStartOf(now)
+	lastWeek := currentWeek.AddDate(0, 0, -7) // tuần đầy đủ gần nhất, tuần hiện tại chưa khép lại
+	since := currentWeek.AddDate(0, 0, -7*model.ForecastHistoryWeeks)
+
+	demand, err := s.repo.GetWeeklyDemand(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &model.ForecastRunStats{ComputedAt: now}
+	forecasts := make([]model.DemandForecast, 0)
+
+	for _, series := range groupDemandSeries(demand, lastWeek) {
+		y := series.weeklyQuantities(lastWeek)
+		if len(y) < model.ForecastMinHistoryWeeks {
+			stats.Skipped++
+			continue
+		}
+		stats.Series++
+
+		fit := fitSeasonalModel(y, series.firstWeek)
+		for h := 1; h <= model.ForecastHorizonWeeks; h++ {
+			week := lastWeek.AddDate(0, 0, 7*h)
+			predicted, lower, upper := fit.forecast(week, h)
+			forecasts = append(forecasts, model.DemandForecast{
+				BookID:       series.bookID,
+				WarehouseID:  series.warehouseID,
+				WeekStart:    week,
+				Predicted:    predicted,
+				LowerBound:   lower,
+				UpperBound:   upper,
+				Model:        fit.model,
+				HistoryWeeks: len(y),
+				ComputedAt:   now,
+			})
+		}
+	}
+
+	count, err := s.repo.ReplaceDemandForecasts(ctx, forecasts)
+	if err != nil {
+		return nil, err
+	}
+	stats.Forecasts = count
+
+	return stats, nil
+}
+
+// GetDemandForecast - dự báo N tuần tới (từ tuần hiện tại) của 1 sách, theo từng kho
+func (s *InventoryService) GetDemandForecast(ctx context.Context, req model.DemandForecastRequest) (*model.DemandForecastResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	from := model.WeekStartOf(time.Now())
+	to := from.AddDate(0, 0, 7*req.Weeks)
+
+	rows, err := s.repo.ListBookForecasts(ctx, req.BookID, req.WarehouseID, req.ScopeWarehouseIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.DemandForecastResponse{
+		BookID:     req.BookID,
+		Weeks:      req.Weeks,
+		Warehouses: make([]model.WarehouseForecast, 0),
+	}
+
+	// rows đã sắp theo kho → gom liên tiếp
+	var current *model.WarehouseForecast
+	var variance float64
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.TotalLower, current.TotalUpper = combinedInterval(current.TotalPredicted, variance)
+		resp.Warehouses = append(resp.Warehouses, *current)
+	}
+
+	for _, row := range rows {
+		if current == nil || current.WarehouseID != row.WarehouseID {
+			flush()
+			current = &model.WarehouseForecast{
+				WarehouseID:   row.WarehouseID,
+				WarehouseName: row.WarehouseName,
+				Model:         row.Model,
+				HistoryWeeks:  row.HistoryWeeks,
+				Weeks:         make([]model.WeekForecast, 0, req.Weeks),
+			}
+			variance = 0
+		}
+		if resp.ComputedAt == nil {
+			computedAt := row.ComputedAt
+			resp.ComputedAt = &computedAt
+		}
+
+		current.Weeks = append(current.Weeks, model.WeekForecast{
+			WeekStart:  row.WeekStart.Format("2006-01-02"),
+			Predicted:  row.Predicted,
+			LowerBound: row.LowerBound,
+			UpperBound: row.UpperBound,
+		})
+		current.TotalPredicted = round2(current.TotalPredicted + row.Predicted)
+		sigma := (row.UpperBound - row.Predicted) / model.ForecastZ95
+		variance += sigma * sigma
+	}
+	flush()
+
+	return resp, nil
+}
+
+// GetReplenishmentSuggestions - số lượng nên nhập để tồn (khả dụng + đang chuyển tới) đủ cận trên
+// nhu cầu N tuần tới và vẫn giữ safety stock. Chỉ trả (sách, kho) cần nhập thêm
+func (s *InventoryService) GetReplenishmentSuggestions(ctx context.Context, req model.ReplenishmentRequest) (*model.ReplenishmentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	from := model.WeekStartOf(time.Now())
+	to := from.AddDate(0, 0, 7*req.Weeks)
+
+	inputs, err := s.repo.ListReplenishmentInputs(ctx, req.WarehouseID, req.ScopeWarehouseIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.ReplenishmentResponse{
+		Weeks:       req.Weeks,
+		Suggestions: make([]model.ReplenishmentSuggestion, 0),
+	}
+	for _, in := range inputs {
+		lower, upper := combinedInterval(in.Predicted, in.Variance)
+		onHand := in.Available + in.InTransit
+
+		suggested := int(math.Ceil(upper + float64(in.SafetyStock) - float64(onHand)))
+		if suggested <= 0 {
+			continue
+		}
+
+		resp.Suggestions = append(resp.Suggestions, model.ReplenishmentSuggestion{
+			BookID:            in.BookID,
+			BookTitle:         in.BookTitle,
+			WarehouseID:       in.WarehouseID,
+			WarehouseName:     in.WarehouseName,
+			Available:         in.Available,
+			InTransit:         in.InTransit,
+			SafetyStock:       in.SafetyStock,
+			DemandPredicted:   round2(in.Predicted),
+			DemandLower:       lower,
+			DemandUpper:       upper,
+			SuggestedQuantity: suggested,
+			StockoutLikely:    float64(onHand) < in.Predicted,
+		})
+	}
+
+	return resp, nil
+}
+
+// ========================================
+// MODEL
+// ========================================
+
+// groupDemandSeries gom dòng (sách, kho, tuần) thành chuỗi; dòng đã sắp theo sách, kho, tuần.
+// Bỏ tuần sau lastWeek (tuần hiện tại chưa khép lại)
+func groupDemandSeries(demand []model.WeeklyDemand, lastWeek time.Time) []*demandSeries {
+	series := make([]*demandSeries, 0)
+	var current *demandSeries
+	for _, d := range demand {
+		week := d.WeekStart.UTC()
+		if week.After(lastWeek) {
+			continue
+		}
+		if current == nil || current.bookID != d.BookID || current.warehouseID != d.WarehouseID {
+			current = &demandSeries{
+				bookID:      d.BookID,
+				warehouseID: d.WarehouseID,
+				byWeek:      make(map[time.Time]int),
+				firstWeek:   week,
+			}
+			series = append(series, current)
+		}
+		current.byWeek[week] += d.Quantity
+	}
+	return series
+}
+
+// weeklyQuantities chuỗi từ tuần bán đầu tiên tới lastWeek, tuần không bán = 0
+func (d *demandSeries) weeklyQuantities(lastWeek time.Time) []float64 {
+	y := make([]float64, 0)
+	for week := d.firstWeek; !week.After(lastWeek); week = week.AddDate(0, 0, 7) {
+		y = append(y, float64(d.byWeek[week]))
+	}
+	return y
+}
+
+// seasonalFit kết quả fit 1 chuỗi
+type seasonalFit struct {
+	model    string
+	level    float64
+	sigma    float64
+	seasonal [12]float64 // theo tháng, index 0 = tháng 1
+}
+
+// fitSeasonalModel fit model trên y (tuần đầu = firstWeek)
+func fitSeasonalModel(y []float64, firstWeek time.Time) *seasonalFit {
+	fit := &seasonalFit{model: model.ForecastModelLevel}
+	for m := range fit.seasonal {
+		fit.seasonal[m] = 1
+	}
+
+	if len(y) >= model.ForecastSeasonalMinWeeks {
+		var total float64
+		var sums [12]float64
+		var counts [12]int
+		for t, v := range y {
+			m := weekMonth(firstWeek.AddDate(0, 0, 7*t))
+			sums[m] += v
+			counts[m]++
+			total += v
+		}
+		if mean := total / float64(len(y)); mean > 0 {
+			fit.model = model.ForecastModelSeasonal
+			for m := range fit.seasonal {
+				if counts[m] > 0 {
+					fit.seasonal[m] = math.Max(sums[m]/float64(counts[m])/mean, minSeasonalIndex)
+				}
+			}
+		}
+	}
+
+	// Mức nền khởi tạo = TB (đã khử mùa vụ) vài tuần đầu
+	initWeeks := min(model.ForecastMinHistoryWeeks, len(y))
+	for t := 0; t < initWeeks; t++ {
+		fit.level += y[t] / fit.seasonalAt(firstWeek.AddDate(0, 0, 7*t))
+	}
+	fit.level /= float64(initWeeks)
+
+	// Sai số dự báo 1 bước (từ tuần thứ 2) → sigma
+	var sse float64
+	for t, v := range y {
+		s := fit.seasonalAt(firstWeek.AddDate(0, 0, 7*t))
+		if t > 0 {
+			e := v - fit.level*s
+			sse += e * e
+		}
+		fit.level = model.ForecastSmoothingAlpha*(v/s) + (1-model.ForecastSmoothingAlpha)*fit.level
+	}
+	fit.sigma = math.Sqrt(sse / float64(len(y)-1))
+
+	return fit
+}
+
+// forecast dự báo tuần week, cách tuần lịch sử cuối h tuần
+func (f *seasonalFit) forecast(week time.Time, h int) (predicted, lower, upper float64) {
+	alpha := model.ForecastSmoothingAlpha
+	predicted = f.level * f.seasonalAt(week)
+	half := model.ForecastZ95 * f.sigma * math.Sqrt(1+float64(h-1)*alpha*alpha)
+	return round2(predicted), round2(math.Max(predicted-half, 0)), round2(predicted + half)
+}
+
+func (f *seasonalFit) seasonalAt(week time.Time) float64 {
+	return f.seasonal[weekMonth(week)]
+}
+
+// weekMonth tháng của tuần (theo thứ 5, tuần vắt 2 tháng tính vào tháng có nhiều ngày hơn), 0-11
+func weekMonth(weekStart time.Time) int {
+	return int(weekStart.AddDate(0, 0, 3).Month()) - 1
+}
+
+// combinedInterval khoảng 95% của tổng nhiều tuần từ tổng phương sai
+// (xấp xỉ: coi sai số các tuần độc lập)
+func combinedInterval(predicted, variance float64) (lower, upper float64) {
+	half := model.ForecastZ95 * math.Sqrt(variance)
+	return round2(math.Max(predicted-half, 0)), round2(predicted + half)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
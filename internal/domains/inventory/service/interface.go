@@ -116,15 +116,100 @@ type ServiceInterface interface {
 	// Creates audit log with:
 	//   - action = 'ADJUSTMENT'
 	//   - reason (required)
+	//   - reason_category (damaged, lost, expired, sample = write-off, only for decreases; recount, other)
 	//   - changed_by (admin user)
 	//   - IP address
 	// Validates: new quantity >= reserved
+	// If |change| exceeds approval threshold: creates pending adjustment request
+	// (ADJUSTMENT_REQUESTED audit entry) instead of updating stock
 	AdjustStock(ctx context.Context, req model.AdjustStockRequest) (*model.AdjustStockResponse, error)
 
+	// ApproveAdjustment applies a pending adjustment request (second admin)
+	// Validates: reviewer != requester, inventory version unchanged since request
+	// Creates audit entries ADJUSTMENT (trigger) + ADJUSTMENT_APPROVED
+	ApproveAdjustment(ctx context.Context, requestID uuid.UUID, req model.ReviewAdjustmentRequest) (*model.AdjustStockResponse, error)
+
+	// RejectAdjustment closes a pending adjustment request without touching stock
+	// Creates audit entry ADJUSTMENT_REJECTED
+	RejectAdjustment(ctx context.Context, requestID uuid.UUID, req model.ReviewAdjustmentRequest) error
+
+	// ListAdjustmentRequests lists adjustment requests by status (default pending)
+	ListAdjustmentRequests(ctx context.Context, req model.ListAdjustmentRequestsRequest) (*model.ListAdjustmentRequestsResponse, error)
+
+	// ========================================
+	// SUPPLIER RETURNS (RTV)
+	// ========================================
+
+	// CreateSupplierReturn creates an RTV document for one supplier (publisher) + one warehouse
+	// Validates: books belong to the supplier, enough available stock (quantity - reserved)
+	// Decrements stock immediately, audit entries with action = 'RTV'
+	// Value snapshot at books.cost_price
+	CreateSupplierReturn(ctx context.Context, req model.CreateRTVRequest) (*model.SupplierReturn, error)
+
+	// GetSupplierReturn gets RTV with items + credit notes
+	GetSupplierReturn(ctx context.Context, id uuid.UUID) (*model.SupplierReturn, error)
+
+	// ListSupplierReturns lists RTV documents (filters: status, supplier, warehouse)
+	ListSupplierReturns(ctx context.Context, req model.ListRTVRequest) (*model.ListRTVResponse, error)
+
+	// RecordCreditNote records a supplier credit note, status becomes
+	// partially_credited / credited by credited amount vs RTV value
+	RecordCreditNote(ctx context.Context, returnID uuid.UUID, req model.RecordCreditNoteRequest) (*model.SupplierReturn, error)
+
+	// CloseSupplierReturn closes an RTV the supplier will not fully credit (stock is not restored)
+	CloseSupplierReturn(ctx context.Context, returnID uuid.UUID, req model.CloseRTVRequest) (*model.SupplierReturn, error)
+
+	// GetRTVReport aggregates RTV totals per supplier in [from_date, to_date)
+	GetRTVReport(ctx context.Context, req model.RTVReportRequest) (*model.RTVReportResponse, error)
+
+	// ========================================
+	// STOCK TRANSFERS
+	// ========================================
+
+	// CreateStockTransfer creates a pending transfer between two warehouses (stock untouched)
+	// Validates: books have inventory + enough available stock at the source
+	CreateStockTransfer(ctx context.Context, req model.CreateTransferRequest) (*model.StockTransfer, error)
+
+	// GetStockTransfer gets transfer with items
+	GetStockTransfer(ctx context.Context, id uuid.UUID) (*model.StockTransfer, error)
+
+	// ListStockTransfers lists transfers (filters: status, warehouse + direction out / in)
+	ListStockTransfers(ctx context.Context, req model.ListTransfersRequest) (*model.ListTransfersResponse, error)
+
+	// ShipStockTransfer decrements the source warehouse (audit TRANSFER_OUT), status in_transit
+	ShipStockTransfer(ctx context.Context, id, shippedBy uuid.UUID) (*model.StockTransfer, error)
+
+	// ReceiveStockTransfer increments the destination warehouse (audit TRANSFER_IN), status received
+	ReceiveStockTransfer(ctx context.Context, id, receivedBy uuid.UUID) (*model.StockTransfer, error)
+
+	// CancelStockTransfer cancels a pending / in-transit transfer, in-transit stock returns to the source
+	CancelStockTransfer(ctx context.Context, id uuid.UUID, req model.CancelTransferRequest) (*model.StockTransfer, error)
+
+	// ========================================
+	// SERIAL / LOT TRACKING
+	// ========================================
+
+	// GetSerialUnit gets full serial details (warehouse, lot, order)
+	GetSerialUnit(ctx context.Context, serial string) (*model.SerialUnit, error)
+
+	// VerifySerial public authenticity check (book + lot only, no order / customer data)
+	VerifySerial(ctx context.Context, serial string) (*model.SerialVerification, error)
+
+	// ListSerialUnits lists serial units (filters: warehouse, book, order, lot, status)
+	ListSerialUnits(ctx context.Context, req model.ListSerialsRequest) (*model.ListSerialsResponse, error)
+
+	// AssignSerialsToOrderItem records which copies ship for an order item at pick time
+	// Order must be confirmed / processing, serials in stock at the order warehouse
+	AssignSerialsToOrderItem(ctx context.Context, orderID, orderItemID uuid.UUID, req model.AssignSerialsRequest) ([]model.SerialUnit, error)
+
+	// UnassignSerialFromOrderItem puts a wrongly picked serial back in stock
+	UnassignSerialFromOrderItem(ctx context.Context, orderID, orderItemID uuid.UUID, serial string, scope []uuid.UUID) ([]model.SerialUnit, error)
+
 	// RestockInventory adds new stock (restock from supplier)
 	// Increases quantity
 	// Updates last_restocked_at timestamp
 	// Creates audit log with action = 'RESTOCK'
+	// Optional serial_numbers (one per copy) + lot_number register serial units
 	RestockInventory(ctx context.Context, req model.RestockRequest) (*model.RestockResponse, error)
 
 	// BulkUpdateStock imports stock updates from CSV (FR-INV-006)
@@ -157,6 +242,19 @@ type ServiceInterface interface {
 	// Normally auto-resolved by trigger
 	MarkAlertResolved(ctx context.Context, alertID uuid.UUID) error
 
+	// CreateAlertRecipient registers an email / Slack webhook recipient of low stock alerts
+	// warehouse_id nil = every warehouse, mode immediate (default) or digest (hourly rollup)
+	CreateAlertRecipient(ctx context.Context, req model.CreateAlertRecipientRequest) (*model.AlertRecipient, error)
+
+	// ListAlertRecipients lists recipients (warehouse filter includes all-warehouse recipients)
+	ListAlertRecipients(ctx context.Context, req model.ListAlertRecipientsRequest) ([]model.AlertRecipient, error)
+
+	// UpdateAlertRecipient changes target / mode / is_active (channel + warehouse are fixed)
+	UpdateAlertRecipient(ctx context.Context, id uuid.UUID, req model.UpdateAlertRecipientRequest) (*model.AlertRecipient, error)
+
+	// DeleteAlertRecipient removes a recipient
+	DeleteAlertRecipient(ctx context.Context, id uuid.UUID) error
+
 	// ========================================
 	// AUDIT & REPORTING (FR-INV-005)
 	// ========================================
@@ -172,6 +270,11 @@ type ServiceInterface interface {
 	// Ordered by created_at DESC
 	GetInventoryHistory(ctx context.Context, warehouseID, bookID uuid.UUID, limit, offset int) (*model.InventoryHistoryResponse, error)
 
+	// GetWriteOffReport aggregates write-off adjustments (damaged, lost, expired, sample)
+	// per warehouse per category in [from_date, to_date), default last 30 days
+	// Value at cost price (books.cost_price)
+	GetWriteOffReport(ctx context.Context, req model.WriteOffReportRequest) (*model.WriteOffReportResponse, error)
+
 	// ExportAuditLog exports audit log to CSV for compliance
 	// Date range required (max 1 year)
 	// Returns CSV file path or download URL
@@ -216,6 +319,22 @@ type ServiceInterface interface {
 	// - Conversion rate (reserved → sale)
 	GetReservationAnalysis(ctx context.Context) (*model.ReservationAnalysisResponse, error)
 
+	// ========================================
+	// DEMAND FORECAST
+	// ========================================
+
+	// ComputeDemandForecasts fits a seasonal model per (book, warehouse) from weekly order history
+	// and replaces demand_forecasts for the next ForecastHorizonWeeks weeks (weekly job)
+	ComputeDemandForecasts(ctx context.Context) (*model.ForecastRunStats, error)
+
+	// GetDemandForecast returns predicted demand of a book for the next N weeks per warehouse
+	// with 95% confidence intervals
+	GetDemandForecast(ctx context.Context, req model.DemandForecastRequest) (*model.DemandForecastResponse, error)
+
+	// GetReplenishmentSuggestions suggests restock quantities so stock covers the upper bound of
+	// forecast demand over the next N weeks plus safety stock
+	GetReplenishmentSuggestions(ctx context.Context, req model.ReplenishmentRequest) (*model.ReplenishmentResponse, error)
+
 	// ========================================
 	// WAREHOUSE MANAGEMENT
 	// ========================================
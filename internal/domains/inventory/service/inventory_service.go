@@ -4,11 +4,15 @@ package service
 import (
 	"bookstore-backend/internal/domains/inventory/model"
 	"bookstore-backend/internal/domains/inventory/repository"
+	warehouseService "bookstore-backend/internal/domains/warehouse/service"
 	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/pagination"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,12 +28,29 @@ const (
 type InventoryService struct {
 	repo  repository.RepositoryInterface
 	asynq *asynq.Client // DI từ container, queue riêng inventory
+
+	// |change| > threshold thì adjustment phải chờ admin thứ 2 duyệt (<= 0: tắt)
+	approvalThreshold int
+
+	// Kho active + toạ độ cho availability check (refresh theo TTL / admin mutation)
+	warehouses *warehouseCache
+
+	// Lịch nghỉ toàn quốc / tỉnh / kho → ước tính giao hàng cộng thêm ngày nghỉ
+	holidays warehouseService.HolidayService
 }
 
-func NewService(repo repository.RepositoryInterface, asynq *asynq.Client) ServiceInterface {
+func NewService(
+	repo repository.RepositoryInterface,
+	asynq *asynq.Client,
+	approvalThreshold int,
+	holidays warehouseService.HolidayService,
+) ServiceInterface {
 	return &InventoryService{
-		repo:  repo,
-		asynq: asynq,
+		repo:              repo,
+		asynq:             asynq,
+		approvalThreshold: approvalThreshold,
+		warehouses:        newWarehouseCache(repo, WarehouseCacheTTL),
+		holidays:          holidays,
 	}
 }
 
@@ -199,7 +220,7 @@ func (s *InventoryService) UpdateInventory(ctx context.Context, warehouseID, boo
 		logger.Error("InventoryService.UpdateStock: payload marshal error", err)
 		// Không cần fail request vì stock đã được cập nhật
 	} else {
-		task := asynq.NewTask(shared.TypeInventorySyncBookStock, b)
+		task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
 		if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
 			logger.Error("InventoryService.UpdateStock: failed to enqueue InventorySyncJob", err)
 			// Không cần fail request, log alert là đủ
@@ -226,6 +247,16 @@ func (s *InventoryService) DeleteInventory(ctx context.Context, warehouseID, boo
 }
 
 func (s *InventoryService) ListInventories(ctx context.Context, req model.ListInventoryRequest) (*model.ListInventoryResponse, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if pagination.IsCursorMode(req.Pagination, req.Cursor) {
+		return s.listInventoriesAfterCursor(ctx, req)
+	}
+
 	inventories, totalItems, err := s.repo.List(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list inventories: %w", err)
@@ -251,6 +282,39 @@ func (s *InventoryService) ListInventories(ctx context.Context, req model.ListIn
 	}, nil
 }
 
+// listInventoriesAfterCursor keyset pagination: lấy limit+1 dòng, dòng dư chỉ để biết còn trang sau
+func (s *InventoryService) listInventoriesAfterCursor(ctx context.Context, req model.ListInventoryRequest) (*model.ListInventoryResponse, error) {
+	var after *pagination.Cursor
+	if req.Cursor != "" {
+		var err error
+		if after, err = pagination.Decode(req.Cursor, 2); err != nil {
+			return nil, err
+		}
+	}
+
+	inventories, err := s.repo.ListAfterCursor(ctx, req, after, req.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inventories: %w", err)
+	}
+
+	result := &model.ListInventoryResponse{Limit: req.Limit}
+	if len(inventories) > req.Limit {
+		inventories = inventories[:req.Limit]
+		last := inventories[len(inventories)-1]
+		result.HasMore = true
+		result.NextCursor = pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			IDs:       []uuid.UUID{last.WarehouseID, last.BookID},
+		}.Encode()
+	}
+
+	result.Items = make([]model.InventoryResponse, len(inventories))
+	for i, inv := range inventories {
+		result.Items[i] = s.inventoryToResponse(inv, inv.WarehouseName)
+	}
+	return result, nil
+}
+
 // ========================================
 // STOCK RESERVATION (FR-INV-003)
 // ========================================
@@ -292,7 +356,7 @@ func (s *InventoryService) ReserveStock(ctx context.Context, req model.ReserveSt
 	}
 	b, err := json.Marshal(payload)
 	if err == nil {
-		task := asynq.NewTask(shared.TypeInventorySyncBookStock, b)
+		task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
 		s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory))
 	}
 
@@ -321,7 +385,7 @@ func (s *InventoryService) ReleaseStock(ctx context.Context, req model.ReleaseSt
 	}
 	b, err := json.Marshal(payload)
 	if err == nil {
-		task := asynq.NewTask(shared.TypeInventorySyncBookStock, b)
+		task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
 		s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory))
 	}
 	return &model.ReleaseStockResponse{
@@ -360,124 +424,181 @@ func (s *InventoryService) FindOptimalWarehouse(ctx context.Context, req model.F
 		return nil, err
 	}
 
-	// Calculate estimated delivery based on distance
-	estimatedDelivery := "1-2 days"
-	if nearest.DistanceKM > 500 {
-		estimatedDelivery = "5-7 days"
-	} else if nearest.DistanceKM > 200 {
-		estimatedDelivery = "3-5 days"
-	}
-
-	return &model.WarehouseRecommendation{
+	recommendation := &model.WarehouseRecommendation{
 		WarehouseID:       nearest.WarehouseID,
 		WarehouseName:     nearest.WarehouseName,
 		DistanceKM:        nearest.DistanceKM,
 		AvailableQuantity: nearest.AvailableQuantity,
-		EstimatedDelivery: estimatedDelivery,
-	}, nil
+	}
+
+	province := ""
+	if warehouses, err := s.warehouses.active(ctx); err == nil {
+		province = warehouses[nearest.WarehouseID].Province
+	}
+	recommendation.EstimatedDelivery, recommendation.HolidayDelayDays = s.estimateDelivery(ctx, nearest.WarehouseID, province, nearest.DistanceKM)
+	return recommendation, nil
 }
 
-func (s *InventoryService) CheckAvailability(ctx context.Context, req model.CheckAvailabilityRequest) (*model.CheckAvailabilityResponse, error) {
-	// Extract book IDs
-	logger.Info("CheckAvailability", map[string]interface{}{
-		"req": req,
-	})
-	bookIDs := make([]uuid.UUID, len(req.Items))
-	for i, item := range req.Items {
-		bookIDs[i] = item.BookID
+// deliveryDays - số ngày làm việc giao hàng theo khoảng cách
+func deliveryDays(distanceKM float64) (int, int) {
+	if distanceKM > 500 {
+		return 5, 7
+	} else if distanceKM > 200 {
+		return 3, 5
 	}
+	return 1, 2
+}
 
-	// Fetch inventories for all books
-	inventoriesByBook := make(map[uuid.UUID][]model.Inventory)
-	for _, bookID := range bookIDs {
-		invs, err := s.repo.GetInventoriesByBook(ctx, bookID)
-		if err != nil {
-			return nil, err
-		}
-		inventoriesByBook[bookID] = invs
+// estimateDelivery - ước tính giao hàng theo khoảng cách, cộng thêm ngày nghỉ của kho rơi vào khoảng giao
+// Lỗi đọc lịch nghỉ không chặn availability check → fallback ước tính theo khoảng cách
+func (s *InventoryService) estimateDelivery(ctx context.Context, warehouseID uuid.UUID, province string, distanceKM float64) (string, int) {
+	minDays, maxDays := deliveryDays(distanceKM)
+
+	calendar, err := s.holidays.CalendarFor(ctx, &warehouseID, province)
+	if err != nil {
+		logger.Error("Failed to load holiday calendar for delivery estimate", err)
+		return fmt.Sprintf("%d-%d days", minDays, maxDays), 0
 	}
 
-	// Process each item
-	itemResponses := make([]model.CheckAvailabilityItemResponse, 0, len(req.Items))
-	overallFulfillable := true
-	warehouseScores := make(map[uuid.UUID]int)
+	now := time.Now()
+	_, minSkipped := calendar.AddWorkingDays(now, minDays)
+	_, maxSkipped := calendar.AddWorkingDays(now, maxDays)
+	return fmt.Sprintf("%d-%d days", minDays+minSkipped, maxDays+maxSkipped), maxSkipped
+}
 
-	for _, item := range req.Items {
-		inventories := inventoriesByBook[item.BookID]
+// parseCoordinate - toạ độ khách hàng lưu dạng string (address.latitude), rỗng/sai format → nil
+func parseCoordinate(v *string) *float64 {
+	if v == nil || *v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(*v, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
 
-		warehouseDetails := make([]model.WarehouseStockDetail, 0, len(inventories))
-		totalAvailable := 0
-		canFulfill := false
+// CheckAvailability resolve availability của tất cả items bằng 1 query (CheckAvailabilityBatch),
+// tên kho/khoảng cách lấy từ warehouse cache → không round trip DB cho thông tin kho
+func (s *InventoryService) CheckAvailability(ctx context.Context, req model.CheckAvailabilityRequest) (*model.CheckAvailabilityResponse, error) {
+	if len(req.Items) == 0 {
+		return &model.CheckAvailabilityResponse{
+			Overall: true,
+			Items:   []model.CheckAvailabilityItemResponse{},
+		}, nil
+	}
 
-		for _, inv := range inventories {
-			available := inv.Quantity - inv.Reserved
-			detail := model.WarehouseStockDetail{
-				WarehouseID:   inv.WarehouseID,
-				WarehouseName: inv.WarehouseName,
-				Available:     available,
-				CanFulfill:    available >= item.Quantity,
-			}
+	warehouses, err := s.warehouses.active(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load warehouses: %w", err)
+	}
 
-			if detail.CanFulfill {
-				canFulfill = true
-				warehouseScores[inv.WarehouseID]++
-			}
+	rows, err := s.repo.CheckAvailabilityBatch(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
 
-			totalAvailable += available
-			warehouseDetails = append(warehouseDetails, detail)
+	// Khoảng cách tính 1 lần mỗi kho (nil nếu thiếu toạ độ khách hàng hoặc kho)
+	customerLat, customerLng := parseCoordinate(req.CustomerLatitude), parseCoordinate(req.CustomerLongitude)
+	distances := make(map[uuid.UUID]*float64, len(warehouses))
+	if customerLat != nil && customerLng != nil {
+		for id, wh := range warehouses {
+			if wh.Latitude != nil && wh.Longitude != nil {
+				d := haversineKM(*customerLat, *customerLng, *wh.Latitude, *wh.Longitude)
+				distances[id] = &d
+			}
 		}
+	}
 
-		itemResp := model.CheckAvailabilityItemResponse{
+	itemResponses := make([]model.CheckAvailabilityItemResponse, len(req.Items))
+	for i, item := range req.Items {
+		itemResponses[i] = model.CheckAvailabilityItemResponse{
 			BookID:            item.BookID,
 			RequestedQuantity: item.Quantity,
-			TotalAvailable:    totalAvailable,
-			Fulfillable:       canFulfill,
-			WarehouseDetails:  warehouseDetails,
+			WarehouseDetails:  make([]model.WarehouseStockDetail, 0),
 		}
+	}
 
-		if !canFulfill {
-			overallFulfillable = false
-			itemResp.Recommendation = fmt.Sprintf("Only %d available, need %d", totalAvailable, item.Quantity)
+	// Group rows theo item (rows đã sort theo idx, available DESC)
+	warehouseScores := make(map[uuid.UUID]int)
+	for _, row := range rows {
+		wh, ok := warehouses[row.WarehouseID]
+		if !ok {
+			continue // kho inactive/đã xoá
+		}
+		itemResp := &itemResponses[row.ItemIndex]
+		detail := model.WarehouseStockDetail{
+			WarehouseID:   row.WarehouseID,
+			WarehouseName: fmt.Sprintf("%s (%s)", wh.Name, wh.Province),
+			Available:     row.Available,
+			CanFulfill:    row.Available >= itemResp.RequestedQuantity,
+			DistanceKM:    distances[row.WarehouseID],
+		}
+		if detail.CanFulfill {
+			itemResp.Fulfillable = true
+			warehouseScores[row.WarehouseID]++
 		}
+		itemResp.TotalAvailable += row.Available
+		itemResp.WarehouseDetails = append(itemResp.WarehouseDetails, detail)
+	}
 
-		itemResponses = append(itemResponses, itemResp)
+	overallFulfillable := true
+	for i := range itemResponses {
+		itemResp := &itemResponses[i]
+		if !itemResp.Fulfillable {
+			overallFulfillable = false
+			itemResp.Recommendation = fmt.Sprintf("Only %d available, need %d", itemResp.TotalAvailable, itemResp.RequestedQuantity)
+		}
 	}
-	logger.Info("itemResponses", map[string]interface{}{
-		"itemResponses": itemResponses,
-	})
-	// Find best warehouse (most items can fulfill)
+
+	// Kho đề xuất: fulfill được nhiều item nhất, hoà thì kho gần hơn (chỉ khi cả đơn fulfill được)
 	var recommendedWarehouse *model.WarehouseRecommendation
+	requiresSplit := false
 	if overallFulfillable {
-		maxScore := 0
-		var bestWarehouseID uuid.UUID
-		for whID, score := range warehouseScores {
-			if score > maxScore {
-				maxScore = score
-				bestWarehouseID = whID
+		var bestID uuid.UUID
+		bestScore := 0
+		for id, score := range warehouseScores {
+			if score > bestScore || (score == bestScore && closer(distances[id], distances[bestID], id, bestID)) {
+				bestID, bestScore = id, score
 			}
 		}
-		logger.Info("bestWarehouseID", map[string]interface{}{
-			"bestWarehouseID": bestWarehouseID,
-		})
-		if maxScore > 0 {
-			wh, _ := s.repo.GetWarehouseByID(ctx, bestWarehouseID)
+
+		if bestScore > 0 {
+			wh := warehouses[bestID]
 			recommendedWarehouse = &model.WarehouseRecommendation{
-				WarehouseID:   bestWarehouseID,
+				WarehouseID:   bestID,
 				WarehouseName: wh.Name,
 			}
+			if d := distances[bestID]; d != nil {
+				recommendedWarehouse.DistanceKM = *d
+				recommendedWarehouse.EstimatedDelivery, recommendedWarehouse.HolidayDelayDays = s.estimateDelivery(ctx, bestID, wh.Province, *d)
+			}
+			// Kho đề xuất không fulfill được hết items → phải tách đơn nhiều kho
+			requiresSplit = bestScore < len(req.Items)
 		}
 	}
-	logger.Info("recommendedWarehouse", map[string]interface{}{
-		"recommendedWarehouse": recommendedWarehouse,
-	})
+
 	return &model.CheckAvailabilityResponse{
 		Overall:              overallFulfillable,
 		Items:                itemResponses,
 		RecommendedWarehouse: recommendedWarehouse,
-		RequiresSplit:        false, // TODO: Calculate if need multiple warehouses
+		RequiresSplit:        requiresSplit,
 	}, nil
 }
 
+// closer - tie-break kho đề xuất: có khoảng cách < không có, gần hơn < xa hơn, cuối cùng theo ID (ổn định)
+func closer(a, b *float64, aID, bID uuid.UUID) bool {
+	switch {
+	case a != nil && b == nil:
+		return true
+	case a == nil && b != nil:
+		return false
+	case a != nil && b != nil && *a != *b:
+		return *a < *b
+	}
+	return aID.String() < bID.String()
+}
+
 func (s *InventoryService) GetStockSummary(ctx context.Context, bookID uuid.UUID) (*model.StockSummaryResponse, error) {
 	// Use VIEW books_total_stock
 	totalStock, err := s.repo.GetTotalStockForBook(ctx, bookID)
@@ -535,22 +656,49 @@ func (s *InventoryService) AdjustStock(ctx context.Context, req model.AdjustStoc
 		return nil, fmt.Errorf("new quantity (%d) cannot be less than reserved (%d)", req.NewQuantity, current.Reserved)
 	}
 
-	// Update
-	updated := &model.Inventory{
-		WarehouseID:    req.WarehouseID,
-		BookID:         req.BookID,
-		Quantity:       req.NewQuantity,
-		Reserved:       current.Reserved,
-		AlertThreshold: current.AlertThreshold,
-		Version:        current.Version,
-		UpdatedBy:      &req.ChangedBy,
+	// Validate: category hợp lệ, write-off (hỏng/mất/hết hạn/mẫu) chỉ đi với giảm tồn
+	if !model.IsValidAdjustmentCategory(req.ReasonCategory) {
+		return nil, model.ErrInvalidAdjustmentCategory
 	}
+	if model.IsWriteOffCategory(req.ReasonCategory) && req.NewQuantity >= current.Quantity {
+		return nil, model.ErrWriteOffRequiresDecrease
+	}
+
+	// Vượt ngưỡng: tạo pending request, chưa đụng vào stock
+	if s.requiresApproval(req.NewQuantity - current.Quantity) {
+		adjReq := &model.AdjustmentRequest{
+			WarehouseID:     req.WarehouseID,
+			BookID:          req.BookID,
+			OldQuantity:     current.Quantity,
+			NewQuantity:     req.NewQuantity,
+			ExpectedVersion: current.Version,
+			Reason:          req.Reason,
+			ReasonCategory:  req.ReasonCategory,
+			RequestedBy:     req.ChangedBy,
+		}
+		if err := s.repo.CreateAdjustmentRequest(ctx, adjReq, current.Reserved); err != nil {
+			return nil, err
+		}
 
-	if err := s.repo.Update(ctx, req.WarehouseID, req.BookID, updated); err != nil {
+		return &model.AdjustStockResponse{
+			Success:             true,
+			WarehouseID:         req.WarehouseID,
+			BookID:              req.BookID,
+			OldQuantity:         current.Quantity,
+			NewQuantity:         current.Quantity,
+			QuantityChange:      0,
+			PendingApproval:     true,
+			AdjustmentRequestID: &adjReq.ID,
+			Message: fmt.Sprintf("Adjustment from %d to %d exceeds approval threshold (%d), waiting for a second admin",
+				current.Quantity, req.NewQuantity, s.approvalThreshold),
+		}, nil
+	}
+
+	if err := s.applyAdjustment(ctx, current, req.NewQuantity, req.ChangedBy, req.Reason, req.ReasonCategory); err != nil {
 		return nil, err
 	}
 
-	// Audit log created automatically by trigger
+	// Audit log created by trigger, tagged with reason + category
 	return &model.AdjustStockResponse{
 		Success:        true,
 		WarehouseID:    req.WarehouseID,
@@ -558,11 +706,159 @@ func (s *InventoryService) AdjustStock(ctx context.Context, req model.AdjustStoc
 		OldQuantity:    current.Quantity,
 		NewQuantity:    req.NewQuantity,
 		QuantityChange: req.NewQuantity - current.Quantity,
-		Message:        fmt.Sprintf("Adjusted stock from %d to %d. Reason: %s", current.Quantity, req.NewQuantity, req.Reason),
+		Message:        fmt.Sprintf("Adjusted stock from %d to %d. Reason (%s): %s", current.Quantity, req.NewQuantity, req.ReasonCategory, req.Reason),
+	}, nil
+}
+
+func (s *InventoryService) ApproveAdjustment(ctx context.Context, requestID uuid.UUID, req model.ReviewAdjustmentRequest) (*model.AdjustStockResponse, error) {
+	adjReq, err := s.getReviewableAdjustment(ctx, requestID, req.ReviewedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetByWarehouseAndBook(ctx, adjReq.WarehouseID, adjReq.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stock đã thay đổi sau khi request được tạo -> request cũ không còn đúng
+	if current.Version != adjReq.ExpectedVersion {
+		return nil, model.NewOptimisticLockError(adjReq.ExpectedVersion, current.Version)
+	}
+
+	if adjReq.NewQuantity < current.Reserved {
+		return nil, fmt.Errorf("new quantity (%d) cannot be less than reserved (%d)", adjReq.NewQuantity, current.Reserved)
+	}
+
+	// Claim request (status = 'pending') + đổi tồn (version check) trong 1 transaction:
+	// lỗi giữa chừng không để tồn đã đổi mà request vẫn pending, 2 approval song song chỉ 1 người apply
+	updated := &model.Inventory{
+		WarehouseID:    current.WarehouseID,
+		BookID:         current.BookID,
+		Quantity:       adjReq.NewQuantity,
+		Reserved:       current.Reserved,
+		AlertThreshold: current.AlertThreshold,
+		Version:        current.Version,
+		UpdatedBy:      &req.ReviewedBy,
+	}
+	if err := s.repo.ApproveAdjustmentRequest(ctx, requestID, updated, req.Note); err != nil {
+		return nil, err
+	}
+
+	return &model.AdjustStockResponse{
+		Success:             true,
+		WarehouseID:         adjReq.WarehouseID,
+		BookID:              adjReq.BookID,
+		OldQuantity:         current.Quantity,
+		NewQuantity:         adjReq.NewQuantity,
+		QuantityChange:      adjReq.NewQuantity - current.Quantity,
+		AdjustmentRequestID: &adjReq.ID,
+		Message:             fmt.Sprintf("Approved adjustment from %d to %d. Reason: %s", current.Quantity, adjReq.NewQuantity, adjReq.Reason),
+	}, nil
+}
+
+func (s *InventoryService) RejectAdjustment(ctx context.Context, requestID uuid.UUID, req model.ReviewAdjustmentRequest) error {
+	adjReq, err := s.getReviewableAdjustment(ctx, requestID, req.ReviewedBy)
+	if err != nil {
+		return err
+	}
+
+	reserved := 0
+	if current, err := s.repo.GetByWarehouseAndBook(ctx, adjReq.WarehouseID, adjReq.BookID); err == nil {
+		reserved = current.Reserved
+	}
+
+	return s.repo.ReviewAdjustmentRequest(ctx, requestID, model.AdjustmentStatusRejected, req.ReviewedBy, req.Note, reserved)
+}
+
+func (s *InventoryService) ListAdjustmentRequests(ctx context.Context, req model.ListAdjustmentRequestsRequest) (*model.ListAdjustmentRequestsResponse, error) {
+	if req.Status == "" {
+		req.Status = model.AdjustmentStatusPending
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	items, totalItems, err := s.repo.ListAdjustmentRequests(ctx, req.Status, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (totalItems + req.Limit - 1) / req.Limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &model.ListAdjustmentRequestsResponse{
+		Items:      items,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+		Page:       req.Page,
+		Limit:      req.Limit,
 	}, nil
 }
 
+// requiresApproval checks if quantity change exceeds the configured threshold
+func (s *InventoryService) requiresApproval(change int) bool {
+	if s.approvalThreshold <= 0 {
+		return false
+	}
+	if change < 0 {
+		change = -change
+	}
+	return change > s.approvalThreshold
+}
+
+// getReviewableAdjustment loads a pending request and rejects self-review
+func (s *InventoryService) getReviewableAdjustment(ctx context.Context, requestID, reviewerID uuid.UUID) (*model.AdjustmentRequest, error) {
+	adjReq, err := s.repo.GetAdjustmentRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if adjReq.Status != model.AdjustmentStatusPending {
+		return nil, model.ErrAdjustmentNotPending
+	}
+	if adjReq.RequestedBy == reviewerID {
+		return nil, model.ErrAdjustmentSelfApproval
+	}
+	return adjReq, nil
+}
+
+// applyAdjustment writes new quantity with optimistic lock (trigger logs ADJUSTMENT, tagged with reason + category)
+func (s *InventoryService) applyAdjustment(ctx context.Context, current *model.Inventory, newQuantity int, changedBy uuid.UUID, reason, category string) error {
+	updated := &model.Inventory{
+		WarehouseID:    current.WarehouseID,
+		BookID:         current.BookID,
+		Quantity:       newQuantity,
+		Reserved:       current.Reserved,
+		AlertThreshold: current.AlertThreshold,
+		Version:        current.Version,
+		UpdatedBy:      &changedBy,
+	}
+
+	return s.repo.AdjustQuantity(ctx, updated, reason, category)
+}
+
 func (s *InventoryService) RestockInventory(ctx context.Context, req model.RestockRequest) (*model.RestockResponse, error) {
+	// Serial / lot tuỳ chọn (bản giới hạn): nếu có thì mỗi cuốn nhập phải có đúng 1 serial
+	serials, err := model.NormalizeSerials(req.SerialNumbers)
+	if err != nil {
+		return nil, err
+	}
+	lotNumber, err := model.NormalizeLotNumber(req.LotNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(serials) > 0 && len(serials) != req.QuantityToAdd {
+		return nil, fmt.Errorf("%w: %d serial numbers for %d copies", model.ErrInvalidSerial, len(serials), req.QuantityToAdd)
+	}
+	if lotNumber != nil && len(serials) == 0 {
+		return nil, fmt.Errorf("%w: lot number requires serial numbers", model.ErrInvalidSerial)
+	}
+
 	// Fetch current
 	current, err := s.repo.GetByWarehouseAndBook(ctx, req.WarehouseID, req.BookID)
 	if err != nil {
@@ -585,11 +881,15 @@ func (s *InventoryService) RestockInventory(ctx context.Context, req model.Resto
 		UpdatedBy:      req.UpdatedBy,
 	}
 
-	if err := s.repo.Update(ctx, req.WarehouseID, req.BookID, updated); err != nil {
+	if len(serials) > 0 {
+		if err := s.repo.RestockWithSerials(ctx, updated, serials, lotNumber); err != nil {
+			return nil, err
+		}
+	} else if err := s.repo.Update(ctx, req.WarehouseID, req.BookID, updated); err != nil {
 		return nil, err
 	}
 
-	return &model.RestockResponse{
+	resp := &model.RestockResponse{
 		Success:       true,
 		WarehouseID:   req.WarehouseID,
 		BookID:        req.BookID,
@@ -597,7 +897,12 @@ func (s *InventoryService) RestockInventory(ctx context.Context, req model.Resto
 		NewQuantity:   newQuantity,
 		LastRestockAt: now,
 		Message:       fmt.Sprintf("Added %d units, total now %d", req.QuantityToAdd, newQuantity),
-	}, nil
+	}
+	if len(serials) > 0 {
+		resp.SerialNumbers = serials
+		resp.LotNumber = lotNumber
+	}
+	return resp, nil
 }
 
 func (s *InventoryService) BulkUpdateStock(ctx context.Context, csvPath string, uploadedBy uuid.UUID) (*model.BulkUpdateJobResponse, error) {
@@ -627,13 +932,7 @@ func (s *InventoryService) GetLowStockAlerts(ctx context.Context) ([]model.LowSt
 
 	// Calculate priority
 	for i := range alerts {
-		if alerts[i].CurrentQuantity == 0 {
-			alerts[i].Priority = "critical"
-		} else if alerts[i].CurrentQuantity <= alerts[i].AlertThreshold/2 {
-			alerts[i].Priority = "high"
-		} else {
-			alerts[i].Priority = "medium"
-		}
+		alerts[i].Priority = model.AlertPriority(alerts[i].CurrentQuantity, alerts[i].AlertThreshold)
 	}
 
 	return alerts, nil
@@ -686,7 +985,7 @@ func (s *InventoryService) MarkAlertResolved(ctx context.Context, alertID uuid.U
 // ========================================
 
 func (s *InventoryService) GetAuditTrail(ctx context.Context, req model.AuditTrailRequest) (*model.AuditTrailResponse, error) {
-	logs, totalItems, err := s.repo.GetAuditLog(ctx, req.WarehouseID, req.BookID, req.StartDate, req.EndDate, req.Limit, (req.Page-1)*req.Limit)
+	logs, totalItems, err := s.repo.GetAuditLog(ctx, req.WarehouseID, req.BookID, req.StartDate, req.EndDate, req.ScopeWarehouseIDs, req.Limit, (req.Page-1)*req.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -705,8 +1004,53 @@ func (s *InventoryService) GetAuditTrail(ctx context.Context, req model.AuditTra
 	}, nil
 }
 
+// GetWriteOffReport - hao hụt (damaged, lost, expired, sample) theo kho + category trong khoảng ngày
+func (s *InventoryService) GetWriteOffReport(ctx context.Context, req model.WriteOffReportRequest) (*model.WriteOffReportResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidReportRange, err)
+	}
+
+	stats, err := s.repo.GetWriteOffStats(ctx, *req.FromDate, *req.ToDate, req.WarehouseID, req.ScopeWarehouseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.WriteOffReportResponse{
+		FromDate:   *req.FromDate,
+		ToDate:     *req.ToDate,
+		ByCategory: make(map[string]int, len(model.WriteOffCategories)),
+		Warehouses: make([]model.WarehouseWriteOff, 0),
+	}
+	for _, category := range model.WriteOffCategories {
+		report.ByCategory[category] = 0
+	}
+
+	// stats đã sort theo kho → gom các dòng liên tiếp cùng kho
+	for _, st := range stats {
+		n := len(report.Warehouses)
+		if n == 0 || report.Warehouses[n-1].WarehouseID != st.WarehouseID {
+			report.Warehouses = append(report.Warehouses, model.WarehouseWriteOff{
+				WarehouseID:   st.WarehouseID,
+				WarehouseCode: st.WarehouseCode,
+				WarehouseName: st.WarehouseName,
+			})
+			n++
+		}
+		wh := &report.Warehouses[n-1]
+		wh.ByCategory = append(wh.ByCategory, st)
+		wh.TotalQuantity += st.Quantity
+		wh.TotalValue += st.Value
+
+		report.ByCategory[st.Category] += st.Quantity
+		report.TotalQuantity += st.Quantity
+		report.TotalValue += st.Value
+	}
+
+	return report, nil
+}
+
 func (s *InventoryService) GetInventoryHistory(ctx context.Context, warehouseID, bookID uuid.UUID, limit, offset int) (*model.InventoryHistoryResponse, error) {
-	logs, totalItems, err := s.repo.GetAuditLog(ctx, &warehouseID, &bookID, nil, nil, limit, offset)
+	logs, totalItems, err := s.repo.GetAuditLog(ctx, &warehouseID, &bookID, nil, nil, nil, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -747,7 +1091,7 @@ func (s *InventoryService) GetDashboardSummary(ctx context.Context) (*model.Dash
 	}
 
 	// Get recent movements (last 10)
-	recentMovements, _, err := s.repo.GetAuditLog(ctx, nil, nil, nil, nil, 10, 0)
+	recentMovements, _, err := s.repo.GetAuditLog(ctx, nil, nil, nil, nil, nil, 10, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -848,6 +1192,7 @@ func (s *InventoryService) CreateWarehouse(ctx context.Context, req model.Create
 	if err := s.repo.CreateWarehouse(ctx, warehouse); err != nil {
 		return nil, fmt.Errorf("failed to create warehouse: %w", err)
 	}
+	s.warehouses.invalidate()
 
 	return &model.WarehouseResponse{
 		ID:        warehouse.ID,
@@ -914,6 +1259,7 @@ func (s *InventoryService) UpdateWarehouse(ctx context.Context, warehouseID uuid
 	if err := s.repo.UpdateWarehouse(ctx, warehouseID, updated); err != nil {
 		return nil, fmt.Errorf("failed to update warehouse: %w", err)
 	}
+	s.warehouses.invalidate()
 
 	return &model.WarehouseResponse{
 		ID:        updated.ID,
@@ -959,7 +1305,11 @@ func (s *InventoryService) ListWarehouses(ctx context.Context, req model.ListWar
 
 // DeactivateWarehouse implements Service.DeactivateWarehouse
 func (s *InventoryService) DeactivateWarehouse(ctx context.Context, warehouseID uuid.UUID) error {
-	return s.repo.DeactivateWarehouse(ctx, warehouseID)
+	if err := s.repo.DeactivateWarehouse(ctx, warehouseID); err != nil {
+		return err
+	}
+	s.warehouses.invalidate()
+	return nil
 }
 
 // GetWarehousePerformance implements Service.GetWarehousePerformance
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
+)
+
+// ========================================
+// SUPPLIER RETURN (RTV)
+// ========================================
+
+// CreateSupplierReturn - tạo phiếu trả NCC + trừ tồn khả dụng ngay (audit action RTV)
+// Mọi sách phải thuộc NCC của phiếu, giá trị phiếu tính theo giá vốn hiện tại
+func (s *InventoryService) CreateSupplierReturn(ctx context.Context, req model.CreateRTVRequest) (*model.SupplierReturn, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	bookIDs := make([]uuid.UUID, 0, len(req.Items))
+	for _, item := range req.Items {
+		bookIDs = append(bookIDs, item.BookID)
+	}
+
+	books, err := s.repo.GetRTVBookInfo(ctx, req.WarehouseID, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]model.RTVBookInfo, len(books))
+	for _, b := range books {
+		byID[b.BookID] = b
+	}
+
+	id := uuid.New()
+	now := time.Now()
+	rtv := &model.SupplierReturn{
+		ID:          id,
+		RTVNumber:   generateRTVNumber(id, now),
+		WarehouseID: req.WarehouseID,
+		SupplierID:  req.SupplierID,
+		Status:      model.RTVStatusOpen,
+		TotalValue:  decimal.Zero,
+		Note:        req.Note,
+		CreatedBy:   req.CreatedBy,
+		Items:       make([]model.SupplierReturnItem, 0, len(req.Items)),
+	}
+
+	for _, item := range req.Items {
+		book, ok := byID[item.BookID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", model.ErrBookNotFound, item.BookID)
+		}
+		if book.SupplierID == nil || *book.SupplierID != req.SupplierID {
+			return nil, fmt.Errorf("%w: book %q is not supplied by this supplier", model.ErrInvalidRTV, book.Title)
+		}
+		if !book.HasInventory {
+			return nil, model.NewInventoryNotFoundByBookError(item.BookID, req.WarehouseID.String())
+		}
+		if book.Available < item.Quantity {
+			return nil, fmt.Errorf("book %q: %w", book.Title, model.NewInsufficientStockError(item.Quantity, book.Available))
+		}
+
+		unitCost := decimal.Zero
+		if book.CostPrice != nil {
+			unitCost = *book.CostPrice
+		}
+
+		rtv.Items = append(rtv.Items, model.SupplierReturnItem{
+			BookID:    item.BookID,
+			BookTitle: book.Title,
+			Quantity:  item.Quantity,
+			UnitCost:  unitCost,
+			Condition: item.Condition,
+		})
+		rtv.TotalQuantity += item.Quantity
+		rtv.TotalValue = rtv.TotalValue.Add(unitCost.Mul(decimal.NewFromInt(int64(item.Quantity))))
+	}
+
+	// Repo kiểm tra lại tồn khả dụng trong transaction (đơn có thể vừa reserve)
+	if err := s.repo.CreateSupplierReturn(ctx, rtv); err != nil {
+		return nil, err
+	}
+
+	for _, item := range rtv.Items {
+		s.enqueueStockSync(ctx, item.BookID, "RTV")
+	}
+
+	logger.Info("Supplier return created", map[string]interface{}{
+		"rtv_id":       rtv.ID,
+		"rtv_number":   rtv.RTVNumber,
+		"warehouse_id": rtv.WarehouseID,
+		"supplier_id":  rtv.SupplierID,
+		"quantity":     rtv.TotalQuantity,
+		"value":        rtv.TotalValue.String(),
+	})
+
+	return s.repo.GetSupplierReturn(ctx, rtv.ID)
+}
+
+func (s *InventoryService) GetSupplierReturn(ctx context.Context, id uuid.UUID) (*model.SupplierReturn, error) {
+	return s.repo.GetSupplierReturn(ctx, id)
+}
+
+func (s *InventoryService) ListSupplierReturns(ctx context.Context, req model.ListRTVRequest) (*model.ListRTVResponse, error) {
+	if req.Status != "" && !containsStatus(model.RTVStatuses, req.Status) {
+		return nil, fmt.Errorf("%w: unknown status %q", model.ErrInvalidRTV, req.Status)
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	items, totalItems, err := s.repo.ListSupplierReturns(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (totalItems + req.Limit - 1) / req.Limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &model.ListRTVResponse{
+		Items:      items,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// RecordCreditNote - ghi nhận credit note NCC gửi cho phiếu, cộng dồn đến khi đủ giá trị phiếu
+func (s *InventoryService) RecordCreditNote(ctx context.Context, returnID uuid.UUID, req model.RecordCreditNoteRequest) (*model.SupplierReturn, error) {
+	issuedDate, err := req.ParseIssuedDate()
+	if err != nil {
+		return nil, err
+	}
+
+	note := &model.SupplierCreditNote{
+		ReturnID:         returnID,
+		CreditNoteNumber: strings.TrimSpace(req.CreditNoteNumber),
+		Amount:           req.Amount,
+		IssuedDate:       issuedDate,
+		Note:             req.Note,
+		RecordedBy:       req.RecordedBy,
+	}
+	if err := s.repo.AddCreditNote(ctx, note); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Supplier credit note recorded", map[string]interface{}{
+		"rtv_id":             returnID,
+		"credit_note_number": note.CreditNoteNumber,
+		"amount":             note.Amount.String(),
+		"recorded_by":        req.RecordedBy,
+	})
+
+	return s.repo.GetSupplierReturn(ctx, returnID)
+}
+
+// CloseSupplierReturn - đóng phiếu khi NCC không trả phần còn lại (không hoàn kho)
+func (s *InventoryService) CloseSupplierReturn(ctx context.Context, returnID uuid.UUID, req model.CloseRTVRequest) (*model.SupplierReturn, error) {
+	note := strings.TrimSpace(req.Note)
+	if len(note) < model.RTVMinCloseNoteLength {
+		return nil, fmt.Errorf("%w: close note must be at least %d characters", model.ErrInvalidRTV, model.RTVMinCloseNoteLength)
+	}
+
+	if err := s.repo.CloseSupplierReturn(ctx, returnID, req.ClosedBy, note); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSupplierReturn(ctx, returnID)
+}
+
+// GetRTVReport - tổng RTV theo NCC (số phiếu, số lượng hỏng / dư, giá trị, đã credit, còn nợ)
+func (s *InventoryService) GetRTVReport(ctx context.Context, req model.RTVReportRequest) (*model.RTVReportResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.GetRTVSupplierStats(ctx, *req.FromDate, *req.ToDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.RTVReportResponse{
+		FromDate:       *req.FromDate,
+		ToDate:         *req.ToDate,
+		TotalValue:     decimal.Zero,
+		CreditedAmount: decimal.Zero,
+		Outstanding:    decimal.Zero,
+		Suppliers:      stats,
+	}
+	for _, st := range stats {
+		report.Returns += st.Returns
+		report.Quantity += st.Quantity
+		report.TotalValue = report.TotalValue.Add(st.TotalValue)
+		report.CreditedAmount = report.CreditedAmount.Add(st.CreditedAmount)
+		report.Outstanding = report.Outstanding.Add(st.Outstanding)
+	}
+
+	return report, nil
+}
+
+// enqueueStockSync đồng bộ tồn tổng của sách sau khi kho thay đổi (lỗi chỉ log)
+func (s *InventoryService) enqueueStockSync(ctx context.Context, bookID uuid.UUID, source string) {
+	b, err := json.Marshal(shared.InventorySyncPayload{
+		BookID: bookID.String(),
+		Source: source,
+	})
+	if err != nil {
+		logger.Error("InventoryService: payload marshal error", err)
+		return
+	}
+	task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
+	if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
+		logger.Error("InventoryService: failed to enqueue InventorySyncJob", err)
+	}
+}
+
+func generateRTVNumber(id uuid.UUID, now time.Time) string {
+	suffix := strings.ToUpper(strings.ReplaceAll(id.String(), "-", "")[:8])
+	return fmt.Sprintf("RTV-%s-%s", now.Format("20060102"), suffix)
+}
+
+func containsStatus(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// SERIAL / LOT TRACKING
+// ========================================
+
+// GetSerialUnit - tra cứu đầy đủ 1 serial (kho, lô, đơn đã giao) cho admin / staff
+func (s *InventoryService) GetSerialUnit(ctx context.Context, serial string) (*model.SerialUnit, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return nil, fmt.Errorf("%w: serial number is required", model.ErrInvalidSerial)
+	}
+	return s.repo.GetSerialUnit(ctx, serial)
+}
+
+// VerifySerial - xác thực công khai: serial có do cửa hàng nhập không, thuộc sách / lô nào
+// Không trả về kho, đơn hay khách hàng
+func (s *InventoryService) VerifySerial(ctx context.Context, serial string) (*model.SerialVerification, error) {
+	unit, err := s.GetSerialUnit(ctx, serial)
+	if err != nil {
+		if errors.Is(err, model.ErrSerialNotFound) {
+			return &model.SerialVerification{SerialNumber: strings.TrimSpace(serial)}, nil
+		}
+		return nil, err
+	}
+
+	return &model.SerialVerification{
+		SerialNumber: unit.SerialNumber,
+		Authentic:    true,
+		BookID:       &unit.BookID,
+		BookTitle:    &unit.BookTitle,
+		LotNumber:    unit.LotNumber,
+		ReceivedAt:   &unit.ReceivedAt,
+		Sold:         unit.Status == model.SerialStatusAssigned,
+	}, nil
+}
+
+func (s *InventoryService) ListSerialUnits(ctx context.Context, req model.ListSerialsRequest) (*model.ListSerialsResponse, error) {
+	if req.Status != "" && !containsStatus(model.SerialStatuses, req.Status) {
+		return nil, fmt.Errorf("%w: unknown status %q", model.ErrInvalidSerial, req.Status)
+	}
+	req.LotNumber = strings.TrimSpace(req.LotNumber)
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	items, totalItems, err := s.repo.ListSerialUnits(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (totalItems + req.Limit - 1) / req.Limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &model.ListSerialsResponse{
+		Items:      items,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// AssignSerialsToOrderItem - lúc soạn hàng, ghi lại cuốn (serial) nào được giao cho order item
+// Serial phải còn trong kho của đơn, đúng sách, tổng số không vượt quantity của item
+func (s *InventoryService) AssignSerialsToOrderItem(ctx context.Context, orderID, orderItemID uuid.UUID, req model.AssignSerialsRequest) ([]model.SerialUnit, error) {
+	serials, err := model.NormalizeSerials(req.SerialNumbers)
+	if err != nil {
+		return nil, err
+	}
+	if len(serials) == 0 {
+		return nil, fmt.Errorf("%w: serial_numbers is required", model.ErrInvalidSerial)
+	}
+
+	item, err := s.loadPickableOrderItem(ctx, orderID, orderItemID, req.ScopeWarehouseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AssignSerials(ctx, item, serials, req.AssignedBy); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Serial units assigned to order item", map[string]interface{}{
+		"order_id":      orderID,
+		"order_item_id": orderItemID,
+		"serials":       len(serials),
+		"assigned_by":   req.AssignedBy,
+	})
+
+	return s.listOrderItemSerials(ctx, orderID, orderItemID)
+}
+
+// UnassignSerialFromOrderItem - gỡ serial soạn nhầm, cuốn quay lại trạng thái in_stock
+func (s *InventoryService) UnassignSerialFromOrderItem(ctx context.Context, orderID, orderItemID uuid.UUID, serial string, scope []uuid.UUID) ([]model.SerialUnit, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return nil, fmt.Errorf("%w: serial number is required", model.ErrInvalidSerial)
+	}
+
+	if _, err := s.loadPickableOrderItem(ctx, orderID, orderItemID, scope); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UnassignSerial(ctx, orderItemID, serial); err != nil {
+		return nil, err
+	}
+
+	return s.listOrderItemSerials(ctx, orderID, orderItemID)
+}
+
+// loadPickableOrderItem kiểm tra item thuộc đơn, đơn đang soạn và nằm trong kho staff được gán
+func (s *InventoryService) loadPickableOrderItem(ctx context.Context, orderID, orderItemID uuid.UUID, scope []uuid.UUID) (*model.SerialOrderItem, error) {
+	item, err := s.repo.GetSerialOrderItem(ctx, orderID, orderItemID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsStatus(model.SerialPickableOrderStatuses, item.OrderStatus) {
+		return nil, fmt.Errorf("%w: order status is %s", model.ErrOrderNotPickable, item.OrderStatus)
+	}
+	if scope != nil {
+		if item.WarehouseID == nil || !containsWarehouse(scope, *item.WarehouseID) {
+			return nil, model.ErrWarehouseAccessDenied
+		}
+	}
+	return item, nil
+}
+
+func (s *InventoryService) listOrderItemSerials(ctx context.Context, orderID, orderItemID uuid.UUID) ([]model.SerialUnit, error) {
+	units, _, err := s.repo.ListSerialUnits(ctx, model.ListSerialsRequest{
+		OrderID: &orderID,
+		Page:    1,
+		Limit:   model.SerialMaxPerRequest,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.SerialUnit, 0, len(units))
+	for _, u := range units {
+		if u.OrderItemID != nil && *u.OrderItemID == orderItemID {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+
+func containsWarehouse(list []uuid.UUID, id uuid.UUID) bool {
+	for _, v := range list {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
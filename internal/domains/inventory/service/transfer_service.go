@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// STOCK TRANSFER (chuyển kho)
+// ========================================
+
+// CreateStockTransfer - tạo phiếu chuyển kho (pending), chưa trừ tồn
+// Kiểm tra sơ bộ tồn khả dụng ở kho nguồn để báo lỗi sớm; xuất kho mới kiểm tra lại trong transaction
+func (s *InventoryService) CreateStockTransfer(ctx context.Context, req model.CreateTransferRequest) (*model.StockTransfer, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	bookIDs := make([]uuid.UUID, 0, len(req.Items))
+	for _, item := range req.Items {
+		bookIDs = append(bookIDs, item.BookID)
+	}
+
+	books, err := s.repo.GetRTVBookInfo(ctx, req.SourceWarehouseID, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]model.RTVBookInfo, len(books))
+	for _, b := range books {
+		byID[b.BookID] = b
+	}
+
+	id := uuid.New()
+	transfer := &model.StockTransfer{
+		ID:                     id,
+		TransferNumber:         generateTransferNumber(id, time.Now()),
+		SourceWarehouseID:      req.SourceWarehouseID,
+		DestinationWarehouseID: req.DestinationWarehouseID,
+		Status:                 model.TransferStatusPending,
+		Note:                   req.Note,
+		CreatedBy:              req.CreatedBy,
+		Items:                  make([]model.StockTransferItem, 0, len(req.Items)),
+	}
+
+	for _, item := range req.Items {
+		book, ok := byID[item.BookID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", model.ErrBookNotFound, item.BookID)
+		}
+		if !book.HasInventory {
+			return nil, model.NewInventoryNotFoundByBookError(item.BookID, req.SourceWarehouseID.String())
+		}
+		if book.Available < item.Quantity {
+			return nil, fmt.Errorf("book %q: %w", book.Title, model.NewInsufficientStockError(item.Quantity, book.Available))
+		}
+
+		transfer.Items = append(transfer.Items, model.StockTransferItem{
+			BookID:    item.BookID,
+			BookTitle: book.Title,
+			Quantity:  item.Quantity,
+		})
+		transfer.TotalQuantity += item.Quantity
+	}
+
+	if err := s.repo.CreateStockTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Stock transfer created", map[string]interface{}{
+		"transfer_id":     transfer.ID,
+		"transfer_number": transfer.TransferNumber,
+		"source":          transfer.SourceWarehouseID,
+		"destination":     transfer.DestinationWarehouseID,
+		"quantity":        transfer.TotalQuantity,
+	})
+
+	return s.repo.GetStockTransfer(ctx, transfer.ID)
+}
+
+func (s *InventoryService) GetStockTransfer(ctx context.Context, id uuid.UUID) (*model.StockTransfer, error) {
+	return s.repo.GetStockTransfer(ctx, id)
+}
+
+func (s *InventoryService) ListStockTransfers(ctx context.Context, req model.ListTransfersRequest) (*model.ListTransfersResponse, error) {
+	if req.Status != "" && !containsStatus(model.TransferStatuses, req.Status) {
+		return nil, fmt.Errorf("%w: unknown status %q", model.ErrInvalidTransfer, req.Status)
+	}
+	if req.Direction != "" && req.Direction != model.TransferDirectionOut && req.Direction != model.TransferDirectionIn {
+		return nil, fmt.Errorf("%w: direction must be out or in", model.ErrInvalidTransfer)
+	}
+	if req.Direction != "" && req.WarehouseID == nil {
+		return nil, fmt.Errorf("%w: direction requires warehouse_id", model.ErrInvalidTransfer)
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	items, totalItems, err := s.repo.ListStockTransfers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := (totalItems + req.Limit - 1) / req.Limit
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &model.ListTransfersResponse{
+		Items:      items,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}, nil
+}
+
+// ShipStockTransfer - xuất kho: trừ tồn kho nguồn, phiếu chuyển sang in_transit
+func (s *InventoryService) ShipStockTransfer(ctx context.Context, id, shippedBy uuid.UUID) (*model.StockTransfer, error) {
+	if err := s.repo.ShipStockTransfer(ctx, id, shippedBy); err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.repo.GetStockTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range transfer.Items {
+		s.enqueueStockSync(ctx, item.BookID, "TRANSFER")
+	}
+
+	logger.Info("Stock transfer shipped", map[string]interface{}{
+		"transfer_id":     id,
+		"transfer_number": transfer.TransferNumber,
+		"shipped_by":      shippedBy,
+	})
+
+	return transfer, nil
+}
+
+// ReceiveStockTransfer - nhận hàng: cộng tồn kho đích, phiếu chuyển sang received
+func (s *InventoryService) ReceiveStockTransfer(ctx context.Context, id, receivedBy uuid.UUID) (*model.StockTransfer, error) {
+	if err := s.repo.ReceiveStockTransfer(ctx, id, receivedBy); err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.repo.GetStockTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range transfer.Items {
+		s.enqueueStockSync(ctx, item.BookID, "TRANSFER")
+	}
+
+	logger.Info("Stock transfer received", map[string]interface{}{
+		"transfer_id":     id,
+		"transfer_number": transfer.TransferNumber,
+		"received_by":     receivedBy,
+	})
+
+	return transfer, nil
+}
+
+// CancelStockTransfer - huỷ phiếu pending / in_transit; đang chuyển thì hoàn tồn kho nguồn
+func (s *InventoryService) CancelStockTransfer(ctx context.Context, id uuid.UUID, req model.CancelTransferRequest) (*model.StockTransfer, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) < model.TransferMinCancelReasonLen || len(reason) > model.TransferMaxCancelReasonLen {
+		return nil, fmt.Errorf("%w: cancel reason must be %d-%d characters", model.ErrInvalidTransfer,
+			model.TransferMinCancelReasonLen, model.TransferMaxCancelReasonLen)
+	}
+
+	restocked, err := s.repo.CancelStockTransfer(ctx, id, req.CancelledBy, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := s.repo.GetStockTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if restocked {
+		for _, item := range transfer.Items {
+			s.enqueueStockSync(ctx, item.BookID, "TRANSFER")
+		}
+	}
+
+	logger.Info("Stock transfer cancelled", map[string]interface{}{
+		"transfer_id":     id,
+		"transfer_number": transfer.TransferNumber,
+		"restocked":       restocked,
+		"cancelled_by":    req.CancelledBy,
+	})
+
+	return transfer, nil
+}
+
+func generateTransferNumber(id uuid.UUID, now time.Time) string {
+	suffix := strings.ToUpper(strings.ReplaceAll(id.String(), "-", "")[:8])
+	return fmt.Sprintf("TRF-%s-%s", now.Format("20060102"), suffix)
+}
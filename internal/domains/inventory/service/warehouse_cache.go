@@ -0,0 +1,84 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/inventory/model"
+	"bookstore-backend/internal/domains/inventory/repository"
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WarehouseCacheTTL - thời gian sống của cache danh sách kho trong process
+// Mutation qua admin API invalidate ngay trên instance xử lý request,
+// các instance khác nhận thay đổi sau tối đa TTL
+const WarehouseCacheTTL = 5 * time.Minute
+
+// warehouseCache giữ danh sách kho active (toạ độ, tên) trong memory
+// Kho gần như không đổi nhưng được đọc ở mọi lần check availability → tránh round trip DB
+type warehouseCache struct {
+	repo repository.RepositoryInterface
+	ttl  time.Duration
+
+	mu       sync.RWMutex
+	byID     map[uuid.UUID]model.Warehouse
+	loadedAt time.Time
+}
+
+func newWarehouseCache(repo repository.RepositoryInterface, ttl time.Duration) *warehouseCache {
+	return &warehouseCache{repo: repo, ttl: ttl}
+}
+
+// active trả về map kho active (is_active = true, deleted_at IS NULL), reload khi hết TTL
+// Map trả về là snapshot read-only, caller không được sửa
+func (c *warehouseCache) active(ctx context.Context) (map[uuid.UUID]model.Warehouse, error) {
+	c.mu.RLock()
+	if c.byID != nil && time.Since(c.loadedAt) < c.ttl {
+		byID := c.byID
+		c.mu.RUnlock()
+		return byID, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Double-check: request khác có thể đã reload trong lúc chờ lock
+	if c.byID != nil && time.Since(c.loadedAt) < c.ttl {
+		return c.byID, nil
+	}
+
+	warehouses, err := c.repo.ListWarehouses(ctx, model.ListWarehousesRequest{
+		IsActive: boolPtr(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]model.Warehouse, len(warehouses))
+	for _, wh := range warehouses {
+		byID[wh.ID] = wh
+	}
+
+	c.byID = byID
+	c.loadedAt = time.Now()
+	return byID, nil
+}
+
+// invalidate buộc lần đọc tiếp theo reload từ DB (gọi sau create/update/deactivate kho)
+func (c *warehouseCache) invalidate() {
+	c.mu.Lock()
+	c.byID = nil
+	c.mu.Unlock()
+}
+
+// haversineKM - khoảng cách đường chim bay (km), cùng công thức với find_nearest_warehouse()
+func haversineKM(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKM = 6371
+	rad := math.Pi / 180
+	cosine := math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Cos(lon2*rad-lon1*rad) +
+		math.Sin(lat1*rad)*math.Sin(lat2*rad)
+	return earthRadiusKM * math.Acos(math.Min(1, math.Max(-1, cosine)))
+}
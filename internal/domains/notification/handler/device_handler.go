@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/domains/notification/model"
+	"bookstore-backend/internal/domains/notification/service"
+	"bookstore-backend/internal/shared/response"
+	"bookstore-backend/pkg/logger"
+)
+
+// ================================================
+// DEVICE HANDLER
+// ================================================
+
+type deviceHandler struct {
+	deviceService service.DeviceService
+}
+
+func NewDeviceHandler(deviceService service.DeviceService) DeviceHandler {
+	return &deviceHandler{
+		deviceService: deviceService,
+	}
+}
+
+// ================================================
+// REGISTER DEVICE
+// POST /api/v1/notifications/devices
+// ================================================
+
+func (h *deviceHandler) RegisterDevice(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req model.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	device, err := h.deviceService.RegisterDevice(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidDeviceToken) {
+			response.Error(c, http.StatusBadRequest, "Invalid device token", err.Error())
+			return
+		}
+		logger.Error("Failed to register device", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to register device", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Device registered successfully", device)
+}
+
+// ================================================
+// UNREGISTER DEVICE
+// DELETE /api/v1/notifications/devices
+// ================================================
+// Token đi trong body (không đặt lên URL để không lộ vào access log)
+
+func (h *deviceHandler) UnregisterDevice(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	var req model.UnregisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.deviceService.UnregisterDevice(c.Request.Context(), userID, req.Token); err != nil {
+		if errors.Is(err, model.ErrDeviceNotFound) {
+			response.Error(c, http.StatusNotFound, "Device not found", err.Error())
+			return
+		}
+		logger.Error("Failed to unregister device", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to unregister device", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Device unregistered successfully", nil)
+}
+
+// ================================================
+// LIST DEVICES
+// GET /api/v1/notifications/devices
+// ================================================
+
+func (h *deviceHandler) ListDevices(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	devices, err := h.deviceService.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to list devices", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to list devices", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Devices retrieved successfully", devices)
+}
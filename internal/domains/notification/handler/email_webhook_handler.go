@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/domains/notification/model"
+	"bookstore-backend/internal/domains/notification/service"
+	"bookstore-backend/internal/shared/response"
+	"bookstore-backend/pkg/logger"
+)
+
+// maxEmailWebhookBody - SendGrid gửi batch tối đa vài nghìn event, 5MB là dư
+const maxEmailWebhookBody = 5 << 20
+
+// ================================================
+// EMAIL WEBHOOK HANDLER (Provider callbacks, public)
+// ================================================
+
+type emailWebhookHandler struct {
+	emailEventService service.EmailEventService
+	secret            string
+}
+
+// NewEmailWebhookHandler - secret là EMAIL_WEBHOOK_SECRET, provider gọi kèm ?token=<secret>
+// (SNS/SendGrid không ký bằng shared secret được → token trong URL đã cấu hình ở provider)
+func NewEmailWebhookHandler(emailEventService service.EmailEventService, secret string) EmailWebhookHandler {
+	return &emailWebhookHandler{
+		emailEventService: emailEventService,
+		secret:            secret,
+	}
+}
+
+// ================================================
+// SES (via SNS)
+// POST /api/v1/webhooks/email/ses?token=...
+// ================================================
+
+func (h *emailWebhookHandler) SESWebhook(c *gin.Context) {
+	h.handle(c, model.EmailProviderSES, h.emailEventService.HandleSESNotification)
+}
+
+// ================================================
+// SENDGRID
+// POST /api/v1/webhooks/email/sendgrid?token=...
+// ================================================
+
+func (h *emailWebhookHandler) SendGridWebhook(c *gin.Context) {
+	h.handle(c, model.EmailProviderSendGrid, h.emailEventService.HandleSendGridEvents)
+}
+
+func (h *emailWebhookHandler) handle(
+	c *gin.Context,
+	provider string,
+	process func(ctx context.Context, body []byte) (*model.EmailWebhookResult, error),
+) {
+	// 1. VERIFY TOKEN (chưa cấu hình secret → đóng endpoint)
+	token := c.Query("token")
+	if h.secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.secret)) != 1 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", model.ErrEmailWebhookForbidden.Error())
+		return
+	}
+
+	// 2. READ RAW BODY (SNS gửi Content-Type text/plain → không bind JSON được)
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxEmailWebhookBody))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	// 3. PROCESS
+	result, err := process(c.Request.Context(), body)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidEmailWebhook) {
+			response.Error(c, http.StatusBadRequest, "Invalid webhook payload", err.Error())
+			return
+		}
+		// 5xx → provider retry
+		logger.Error("Failed to process email webhook", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to process email webhook", err.Error())
+		return
+	}
+
+	logger.Info("Email webhook processed", map[string]interface{}{
+		"provider":  provider,
+		"processed": result.Processed,
+		"ignored":   result.Ignored,
+	})
+
+	// 4. RETURN RESPONSE
+	response.Success(c, http.StatusOK, "Email webhook processed", result)
+}
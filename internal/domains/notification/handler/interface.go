@@ -38,3 +38,16 @@ type CampaignHandler interface {
 	StartCampaign(c *gin.Context)
 	CancelCampaign(c *gin.Context)
 }
+
+type EmailWebhookHandler interface {
+	// Provider delivery events (public, token-protected)
+	SESWebhook(c *gin.Context)
+	SendGridWebhook(c *gin.Context)
+}
+
+type DeviceHandler interface {
+	// Push device token (app gọi sau login/logout)
+	RegisterDevice(c *gin.Context)
+	UnregisterDevice(c *gin.Context)
+	ListDevices(c *gin.Context)
+}
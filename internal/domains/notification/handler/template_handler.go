@@ -10,6 +10,7 @@ import (
 
 	"bookstore-backend/internal/domains/notification/model"
 	"bookstore-backend/internal/domains/notification/service"
+	"bookstore-backend/internal/shared/locale"
 	"bookstore-backend/internal/shared/response"
 	"bookstore-backend/pkg/logger"
 )
@@ -258,6 +259,9 @@ func (h *templateHandler) validateCreateTemplateRequest(req *model.CreateTemplat
 	if req.Language == "" {
 		return fmt.Errorf("language is required")
 	}
+	if !locale.IsSupported(req.Language) {
+		return fmt.Errorf("unsupported language: %s", req.Language)
+	}
 
 	// Validate default channels
 	if len(req.DefaultChannels) == 0 {
@@ -0,0 +1,50 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/notification/model"
+	"bookstore-backend/internal/domains/notification/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// ================================================
+// ORDER STATUS PUSH JOB HANDLER
+// ================================================
+
+// OrderStatusPushHandler gửi push khi đơn đổi trạng thái (order service enqueue qua outbox)
+type OrderStatusPushHandler struct {
+	deviceService service.DeviceService
+}
+
+func NewOrderStatusPushHandler(deviceService service.DeviceService) *OrderStatusPushHandler {
+	return &OrderStatusPushHandler{deviceService: deviceService}
+}
+
+// ProcessTask - lỗi chỉ khi không device nào nhận được do lỗi tạm của provider → asynq retry
+func (h *OrderStatusPushHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.OrderStatusPushPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal OrderStatusPush payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	result, err := h.deviceService.SendOrderStatusPush(ctx, payload)
+	if err != nil {
+		logger.Error("OrderStatusPush: send failed", err)
+		return err
+	}
+
+	logger.Info("OrderStatusPush: completed", map[string]interface{}{
+		"order_id": payload.OrderID.String(),
+		"status":   payload.Status,
+		"sent":     result.Sent,
+		"pruned":   result.Pruned,
+		"failed":   result.Failed,
+		"skipped":  result.Skipped,
+	})
+	return nil
+}
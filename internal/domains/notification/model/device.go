@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ================================================
+// USER DEVICES (push FCM / APNs)
+// ================================================
+
+const (
+	DevicePlatformIOS     = "ios"
+	DevicePlatformAndroid = "android"
+	DevicePlatformWeb     = "web"
+
+	DeviceProviderFCM  = "fcm"
+	DeviceProviderAPNs = "apns"
+
+	// Lỗi tạm liên tiếp tối đa trước khi tắt device (token provider báo không hợp lệ → xoá ngay)
+	MaxDeviceFailures = 5
+	// Số device tối đa nhận push của 1 user (device cũ nhất bị bỏ qua)
+	MaxDevicesPerUser = 10
+)
+
+type UserDevice struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Token        string     `json:"-"`
+	Provider     string     `json:"provider"`
+	Platform     string     `json:"platform"`
+	AppVersion   *string    `json:"app_version,omitempty"`
+	FailureCount int        `json:"failure_count"`
+	LastError    *string    `json:"-"`
+	LastSentAt   *time.Time `json:"last_sent_at,omitempty"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// RegisterDeviceRequest - POST /notifications/devices (app gọi sau login + mỗi lần token đổi)
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" binding:"required,max=4096"`
+	Platform string `json:"platform" binding:"required,oneof=ios android web"`
+	// Trống: fcm (APNs chỉ khi app iOS gửi token APNs gốc)
+	Provider   string  `json:"provider" binding:"omitempty,oneof=fcm apns"`
+	AppVersion *string `json:"app_version" binding:"omitempty,max=32"`
+}
+
+// UnregisterDeviceRequest - DELETE /notifications/devices (logout)
+type UnregisterDeviceRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// OrderStatusPushPayload payload task notification:order_status_push (order service enqueue qua outbox)
+type OrderStatusPushPayload struct {
+	UserID      uuid.UUID `json:"user_id"`
+	OrderID     uuid.UUID `json:"order_id"`
+	OrderNumber string    `json:"order_number"`
+	Status      string    `json:"status"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+}
+
+// PushResult kết quả gửi 1 push tới các device của user
+type PushResult struct {
+	Sent    int    `json:"sent"`
+	Pruned  int    `json:"pruned"`
+	Failed  int    `json:"failed"`
+	Skipped string `json:"skipped,omitempty"` // lý do không gửi (preferences, không có device)
+}
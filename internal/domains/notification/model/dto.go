@@ -13,7 +13,7 @@ import (
 // CreateNotificationRequest - Request to create a notification
 type CreateNotificationRequest struct {
 	UserID        uuid.UUID              `json:"user_id" validate:"required"`
-	Type          string                 `json:"type" validate:"required,oneof=promotion_removed order_status payment new_promotion review_response system_alert"`
+	Type          string                 `json:"type" validate:"required,oneof=promotion_removed order_status payment new_promotion review_response question_answered system_alert"`
 	Title         string                 `json:"title" validate:"required,max=255"`
 	Message       string                 `json:"message" validate:"required"`
 	Data          map[string]interface{} `json:"data,omitempty"`
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// ================================================
+// EMAIL PROVIDER EVENTS (webhook)
+// ================================================
+
+// EmailEvent - event delivery chuẩn hoá từ webhook của provider (SES qua SNS, SendGrid Event Webhook)
+type EmailEvent struct {
+	Provider   string
+	MessageID  string // Message-ID header lúc gửi (không kèm <>)
+	Recipient  string
+	Type       string
+	HardBounce bool   // chỉ có nghĩa với EmailEventBounced
+	Diagnostic string // SMTP diagnostic / lý do bounce
+	OccurredAt time.Time
+}
+
+// Email event types
+const (
+	EmailEventDelivered  = "delivered"
+	EmailEventBounced    = "bounced"
+	EmailEventComplained = "complained"
+)
+
+// Email providers
+const (
+	EmailProviderSES      = "ses"
+	EmailProviderSendGrid = "sendgrid"
+)
+
+// Suppression reasons (email_suppressions.reason)
+const (
+	SuppressionReasonHardBounce = "hard_bounce"
+	SuppressionReasonComplaint  = "complaint"
+)
+
+// ShouldSuppress - hard bounce và complaint chặn gửi tiếp, soft bounce thì không
+func (e EmailEvent) ShouldSuppress() bool {
+	return e.Type == EmailEventComplained || (e.Type == EmailEventBounced && e.HardBounce)
+}
+
+// SuppressionReason trả về reason lưu vào email_suppressions
+func (e EmailEvent) SuppressionReason() string {
+	if e.Type == EmailEventComplained {
+		return SuppressionReasonComplaint
+	}
+	return SuppressionReasonHardBounce
+}
+
+// EmailWebhookResult - kết quả xử lý 1 request webhook (1 request có thể chứa nhiều event)
+type EmailWebhookResult struct {
+	Processed int `json:"processed"`
+	Ignored   int `json:"ignored"`
+}
@@ -44,6 +44,7 @@ const (
 	NotificationTypePayment          = "payment"
 	NotificationTypeNewPromotion     = "new_promotion"
 	NotificationTypeReviewResponse   = "review_response"
+	NotificationTypeQuestionAnswered = "question_answered"
 	NotificationTypeSystemAlert      = "system_alert"
 )
 
@@ -164,6 +165,7 @@ const (
 	DeliveryStatusBounced    = "bounced"
 	DeliveryStatusOpened     = "opened"
 	DeliveryStatusClicked    = "clicked"
+	DeliveryStatusComplained = "complained" // người nhận đánh dấu spam (provider feedback loop)
 )
 
 // ================================================
@@ -23,6 +23,7 @@ var (
 // Preferences errors
 var (
 	ErrPreferencesNotFound = errors.New("notification preferences not found")
+	ErrMarketingNotOptedIn = errors.New("user has not opted in to marketing notifications")
 )
 
 // Template errors
@@ -51,6 +52,18 @@ var (
 	ErrMaxRetriesExceeded  = errors.New("maximum retry attempts exceeded")
 )
 
+// Email webhook errors
+var (
+	ErrInvalidEmailWebhook   = errors.New("invalid email webhook payload")
+	ErrEmailWebhookForbidden = errors.New("invalid email webhook token")
+)
+
+// Device errors
+var (
+	ErrDeviceNotFound     = errors.New("device not found")
+	ErrInvalidDeviceToken = errors.New("invalid device token")
+)
+
 // ================================================
 // ERROR CODES (for API responses)
 // ================================================
@@ -76,6 +89,10 @@ const (
 	// Delivery error codes
 	ErrCodeDeliveryFailed      = "DELIVERY_FAILED"
 	ErrCodeProviderUnavailable = "PROVIDER_UNAVAILABLE"
+
+	// Device error codes
+	ErrCodeDeviceNotFound     = "DEVICE_NOT_FOUND"
+	ErrCodeInvalidDeviceToken = "INVALID_DEVICE_TOKEN"
 )
 
 // ================================================
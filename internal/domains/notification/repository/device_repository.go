@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/notification/model"
+)
+
+// ================================================
+// DEVICE REPOSITORY IMPLEMENTATION (user_devices)
+// ================================================
+
+type deviceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDeviceRepository(db *pgxpool.Pool) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+const deviceColumns = `
+	id, user_id, token, provider, platform, app_version, failure_count, last_error,
+	last_sent_at, disabled_at, last_seen_at, created_at, updated_at`
+
+// Upsert đăng ký token; token đã có (kể cả của user khác) → chuyển sang user hiện tại, bật lại + reset lỗi
+func (r *deviceRepository) Upsert(ctx context.Context, device *model.UserDevice) error {
+	if device.ID == uuid.Nil {
+		device.ID = uuid.New()
+	}
+
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO user_devices (id, user_id, token, provider, platform, app_version)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (token) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			provider = EXCLUDED.provider,
+			platform = EXCLUDED.platform,
+			app_version = EXCLUDED.app_version,
+			failure_count = 0,
+			last_error = NULL,
+			disabled_at = NULL,
+			last_seen_at = NOW(),
+			updated_at = NOW()
+		RETURNING `+deviceColumns,
+		device.ID, device.UserID, device.Token, device.Provider, device.Platform, device.AppVersion,
+	).Scan(
+		&device.ID, &device.UserID, &device.Token, &device.Provider, &device.Platform, &device.AppVersion,
+		&device.FailureCount, &device.LastError, &device.LastSentAt, &device.DisabledAt,
+		&device.LastSeenAt, &device.CreatedAt, &device.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert device: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUserAndToken gỡ token khi logout; false khi token không thuộc user
+func (r *deviceRepository) DeleteByUserAndToken(ctx context.Context, userID uuid.UUID, token string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM user_devices WHERE user_id = $1 AND token = $2`, userID, token)
+	if err != nil {
+		return false, fmt.Errorf("delete device: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListByUserID tất cả device của user (kể cả đã tắt), mới nhất trước
+func (r *deviceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]model.UserDevice, error) {
+	return r.list(ctx, `SELECT `+deviceColumns+` FROM user_devices WHERE user_id = $1 ORDER BY last_seen_at DESC`, userID)
+}
+
+// ListActiveByUserID device đang bật nhận push, tối đa limit device dùng gần nhất
+func (r *deviceRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]model.UserDevice, error) {
+	return r.list(ctx, `
+		SELECT `+deviceColumns+`
+		FROM user_devices
+		WHERE user_id = $1 AND disabled_at IS NULL
+		ORDER BY last_seen_at DESC
+		LIMIT $2
+	`, userID, limit)
+}
+
+func (r *deviceRepository) list(ctx context.Context, query string, args ...interface{}) ([]model.UserDevice, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := make([]model.UserDevice, 0)
+	for rows.Next() {
+		var d model.UserDevice
+		if err := rows.Scan(
+			&d.ID, &d.UserID, &d.Token, &d.Provider, &d.Platform, &d.AppVersion,
+			&d.FailureCount, &d.LastError, &d.LastSentAt, &d.DisabledAt,
+			&d.LastSeenAt, &d.CreatedAt, &d.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan device: %w", err)
+		}
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+// Delete xoá token provider báo không còn hợp lệ
+func (r *deviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM user_devices WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete device: %w", err)
+	}
+	return nil
+}
+
+// MarkSent gửi thành công → reset bộ đếm lỗi
+func (r *deviceRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE user_devices
+		SET failure_count = 0, last_error = NULL, last_sent_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("mark device sent: %w", err)
+	}
+	return nil
+}
+
+// RecordFailure tăng bộ đếm lỗi tạm, đủ maxFailures lần liên tiếp thì tắt device; trả true khi vừa tắt
+func (r *deviceRepository) RecordFailure(ctx context.Context, id uuid.UUID, errMsg string, maxFailures int) (bool, error) {
+	var disabled bool
+	err := r.db.QueryRow(ctx, `
+		UPDATE user_devices
+		SET failure_count = failure_count + 1,
+			last_error = $2,
+			disabled_at = CASE WHEN failure_count + 1 >= $3 THEN NOW() ELSE disabled_at END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING disabled_at IS NOT NULL
+	`, id, errMsg, maxFailures).Scan(&disabled)
+	if err != nil {
+		return false, fmt.Errorf("record device failure: %w", err)
+	}
+	return disabled, nil
+}
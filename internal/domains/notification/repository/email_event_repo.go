@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/notification/model"
+	"bookstore-backend/pkg/database"
+)
+
+// ================================================
+// EMAIL EVENT REPOSITORY IMPLEMENTATION
+// ================================================
+
+type emailEventRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailEventRepository(db *pgxpool.Pool) EmailEventRepository {
+	return &emailEventRepository{db: db}
+}
+
+// IsSuppressed checks whether address hard-bounced or complained before
+func (r *emailEventRepository) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT EXISTS(SELECT 1 FROM email_suppressions WHERE email = $1)`
+
+	var suppressed bool
+	if err := r.db.QueryRow(ctx, query, normalizeEmail(email)).Scan(&suppressed); err != nil {
+		return false, fmt.Errorf("check email suppression: %w", err)
+	}
+
+	return suppressed, nil
+}
+
+// ApplyEmailEvent cập nhật trạng thái theo Message-ID (delivery log của notification + contact history của đơn)
+// và suppress địa chỉ nếu hard bounce / complaint, tất cả trong 1 transaction
+// Trả về số dòng được cập nhật (0 = message không do hệ thống gửi hoặc đã có trạng thái cuối)
+func (r *emailEventRepository) ApplyEmailEvent(ctx context.Context, event model.EmailEvent) (int64, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	var updated int64
+	err := database.WithTransaction(ctx, r.db, func(tx pgx.Tx) error {
+		var diagnostic *string
+		if event.Diagnostic != "" {
+			diagnostic = &event.Diagnostic
+		}
+
+		// Delivered tới sau bounce/complaint (provider gửi không theo thứ tự) không được ghi đè trạng thái xấu
+		logResult, err := tx.Exec(ctx, `
+			UPDATE notification_delivery_logs
+			SET
+				status = $2,
+				delivered_at = CASE WHEN $2::text = 'delivered' THEN $3 ELSE delivered_at END,
+				failed_at = CASE WHEN $2::text = 'delivered' THEN failed_at ELSE $3 END,
+				error_message = COALESCE($4, error_message)
+			WHERE provider_message_id = $1
+			  AND NOT ($2::text = 'delivered' AND status IN ('bounced', 'complained'))
+		`, event.MessageID, event.Type, event.OccurredAt, diagnostic)
+		if err != nil {
+			return fmt.Errorf("update delivery logs: %w", err)
+		}
+
+		commResult, err := tx.Exec(ctx, `
+			UPDATE order_communications
+			SET
+				status = $2,
+				error_message = COALESCE($3, error_message)
+			WHERE provider_message_id = $1
+			  AND NOT ($2::text = 'delivered' AND status IN ('bounced', 'complained'))
+		`, event.MessageID, event.Type, diagnostic)
+		if err != nil {
+			return fmt.Errorf("update order communications: %w", err)
+		}
+
+		updated = logResult.RowsAffected() + commResult.RowsAffected()
+
+		if !event.ShouldSuppress() || event.Recipient == "" {
+			return nil
+		}
+
+		// Giữ lý do suppress đầu tiên
+		_, err = tx.Exec(ctx, `
+			INSERT INTO email_suppressions (email, reason, provider, provider_message_id, diagnostic)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (email) DO NOTHING
+		`, normalizeEmail(event.Recipient), event.SuppressionReason(), event.Provider, event.MessageID, diagnostic)
+		if err != nil {
+			return fmt.Errorf("insert email suppression: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("apply email event: %w", err)
+	}
+
+	return updated, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
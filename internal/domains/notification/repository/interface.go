@@ -73,6 +73,7 @@ type TemplateRepository interface {
 	Create(ctx context.Context, template *model.NotificationTemplate) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.NotificationTemplate, error)
 	GetByCode(ctx context.Context, code string) (*model.NotificationTemplate, error)
+	GetByCodeAndLanguage(ctx context.Context, code, language string) (*model.NotificationTemplate, error)
 	Update(ctx context.Context, template *model.NotificationTemplate) error
 	Delete(ctx context.Context, id uuid.UUID) error
 
@@ -110,6 +111,18 @@ type DeliveryLogRepository interface {
 	CountByStatus(ctx context.Context, status string, from, to time.Time) (int, error)
 }
 
+// ================================================
+// EMAIL EVENT REPOSITORY INTERFACE
+// ================================================
+
+type EmailEventRepository interface {
+	// Suppression list (hard bounce / complaint)
+	IsSuppressed(ctx context.Context, email string) (bool, error)
+
+	// Provider webhook events (delivered, bounced, complained)
+	ApplyEmailEvent(ctx context.Context, event model.EmailEvent) (int64, error)
+}
+
 // ================================================
 // CAMPAIGN REPOSITORY INTERFACE
 // ================================================
@@ -154,3 +167,20 @@ type RateLimitRepository interface {
 	ResetExpiredWindows(ctx context.Context) (int, error)
 	ResetByScope(ctx context.Context, scope, scopeID string) error
 }
+
+// ================================================
+// DEVICE REPOSITORY INTERFACE (push tokens)
+// ================================================
+
+type DeviceRepository interface {
+	// Đăng ký / gỡ token từ app
+	Upsert(ctx context.Context, device *model.UserDevice) error
+	DeleteByUserAndToken(ctx context.Context, userID uuid.UUID, token string) (bool, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]model.UserDevice, error)
+
+	// Gửi push + dọn token hỏng
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]model.UserDevice, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	RecordFailure(ctx context.Context, id uuid.UUID, errMsg string, maxFailures int) (bool, error)
+}
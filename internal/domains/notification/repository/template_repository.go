@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"bookstore-backend/internal/domains/notification/model"
+	"bookstore-backend/internal/shared/locale"
 )
 
 // ================================================
@@ -99,8 +100,14 @@ func (r *templateRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.
 	return &t, nil
 }
 
-// GetByCode retrieves template by code
+// GetByCode retrieves template by code (bản locale mặc định nếu code có nhiều ngôn ngữ)
 func (r *templateRepository) GetByCode(ctx context.Context, code string) (*model.NotificationTemplate, error) {
+	return r.GetByCodeAndLanguage(ctx, code, locale.Default)
+}
+
+// GetByCodeAndLanguage retrieves template by (code, language)
+// Chưa có bản dịch cho language → fallback bản locale mặc định
+func (r *templateRepository) GetByCodeAndLanguage(ctx context.Context, code, language string) (*model.NotificationTemplate, error) {
 	query := `
 		SELECT 
 			id, code, name, description, category,
@@ -111,11 +118,13 @@ func (r *templateRepository) GetByCode(ctx context.Context, code string) (*model
 			default_priority, expires_after_hours, version, is_active,
 			created_by, updated_by, created_at, updated_at
 		FROM notification_templates
-		WHERE code = $1
+		WHERE code = $1 AND language IN ($2, $3)
+		ORDER BY (language = $2) DESC
+		LIMIT 1
 	`
 
 	var t model.NotificationTemplate
-	err := r.db.QueryRow(ctx, query, code).Scan(
+	err := r.db.QueryRow(ctx, query, code, language, locale.Default).Scan(
 		&t.ID, &t.Code, &t.Name, &t.Description, &t.Category,
 		&t.EmailSubject, &t.EmailBodyHTML, &t.EmailBodyText,
 		&t.SMSBody, &t.PushTitle, &t.PushBody,
@@ -129,7 +138,7 @@ func (r *templateRepository) GetByCode(ctx context.Context, code string) (*model
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, model.ErrTemplateNotFound
 		}
-		return nil, fmt.Errorf("get template by code: %w", err)
+		return nil, fmt.Errorf("get template by code and language: %w", err)
 	}
 
 	return &t, nil
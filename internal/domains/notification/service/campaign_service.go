@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -348,6 +349,10 @@ func (s *campaignService) ProcessCampaignBatch(ctx context.Context, campaignID u
 
 		// Send notification
 		_, err := s.notificationService.SendNotification(ctx, notifReq)
+		if errors.Is(err, model.ErrMarketingNotOptedIn) {
+			// User chưa opt-in marketing → bỏ qua, không tính là lỗi gửi
+			continue
+		}
 		if err != nil {
 			logger.Error("Failed to send campaign notification", err)
 			failedCount++
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bookstore-backend/internal/domains/notification/model"
+	"bookstore-backend/internal/domains/notification/repository"
+	user "bookstore-backend/internal/domains/user"
+	"bookstore-backend/internal/infrastructure/push"
+	"bookstore-backend/pkg/logger"
+)
+
+var pushNotificationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "push_notifications_total",
+	Help: "Push notifications sent to devices by provider and outcome (sent, pruned, failed)",
+}, []string{"provider", "outcome"})
+
+// ================================================
+// DEVICE SERVICE IMPLEMENTATION (push FCM / APNs)
+// ================================================
+
+// PushSender gửi push theo provider của device (push.Router)
+type PushSender interface {
+	SendTo(ctx context.Context, provider string, msg push.Message) (string, error)
+}
+
+type deviceService struct {
+	deviceRepo     repository.DeviceRepository
+	userRepository user.Repository
+	prefsService   PreferencesService
+	sender         PushSender
+}
+
+func NewDeviceService(
+	deviceRepo repository.DeviceRepository,
+	userRepository user.Repository,
+	prefsService PreferencesService,
+	sender PushSender,
+) DeviceService {
+	return &deviceService{
+		deviceRepo:     deviceRepo,
+		userRepository: userRepository,
+		prefsService:   prefsService,
+		sender:         sender,
+	}
+}
+
+// ================================================
+// REGISTER / UNREGISTER DEVICE
+// ================================================
+
+func (s *deviceService) RegisterDevice(ctx context.Context, userID uuid.UUID, req model.RegisterDeviceRequest) (*model.UserDevice, error) {
+	provider := req.Provider
+	if provider == "" {
+		provider = model.DeviceProviderFCM
+	}
+	token := strings.TrimSpace(req.Token)
+	if err := validateDeviceToken(provider, req.Platform, token); err != nil {
+		return nil, err
+	}
+
+	device := &model.UserDevice{
+		UserID:     userID,
+		Token:      token,
+		Provider:   provider,
+		Platform:   req.Platform,
+		AppVersion: req.AppVersion,
+	}
+	if err := s.deviceRepo.Upsert(ctx, device); err != nil {
+		return nil, err
+	}
+
+	logger.Info("[DeviceService] Device registered", map[string]interface{}{
+		"user_id":   userID.String(),
+		"device_id": device.ID.String(),
+		"provider":  provider,
+		"platform":  req.Platform,
+	})
+	return device, nil
+}
+
+func (s *deviceService) UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error {
+	deleted, err := s.deviceRepo.DeleteByUserAndToken(ctx, userID, strings.TrimSpace(token))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return model.ErrDeviceNotFound
+	}
+	return nil
+}
+
+func (s *deviceService) ListDevices(ctx context.Context, userID uuid.UUID) ([]model.UserDevice, error) {
+	return s.deviceRepo.ListByUserID(ctx, userID)
+}
+
+// ================================================
+// ORDER STATUS PUSH (worker)
+// ================================================
+
+// SendOrderStatusPush gửi tới mọi device đang bật của user.
+// Token hỏng (provider báo UNREGISTERED / 410) bị xoá; lỗi tạm tăng bộ đếm, đủ ngưỡng thì tắt device.
+// Trả error (asynq retry) chỉ khi không device nào nhận được và có lỗi tạm.
+func (s *deviceService) SendOrderStatusPush(ctx context.Context, payload model.OrderStatusPushPayload) (*model.PushResult, error) {
+	result := &model.PushResult{}
+
+	// 1. Công tắc tổng kênh push (user_preferences) rồi cấu hình theo loại order_status
+	prefs, err := s.userRepository.GetPreferences(ctx, payload.UserID)
+	if err != nil {
+		logger.Error("Error loading user preferences for push", err)
+	} else if !prefs.IsChannelEnabled(model.ChannelPush) {
+		result.Skipped = "push disabled in user preferences"
+		return result, nil
+	}
+
+	allowed, reason, err := s.prefsService.CanSendNotification(ctx, payload.UserID, model.NotificationTypeOrderStatus, model.ChannelPush)
+	if err != nil {
+		return nil, fmt.Errorf("check push preferences: %w", err)
+	}
+	if !allowed {
+		result.Skipped = reason
+		return result, nil
+	}
+
+	// 2. Device của user
+	devices, err := s.deviceRepo.ListActiveByUserID(ctx, payload.UserID, model.MaxDevicesPerUser)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		result.Skipped = "no registered devices"
+		return result, nil
+	}
+
+	msg := push.Message{
+		Title: payload.Title,
+		Body:  payload.Body,
+		Data: map[string]string{
+			"type":         model.NotificationTypeOrderStatus,
+			"order_id":     payload.OrderID.String(),
+			"order_number": payload.OrderNumber,
+			"status":       payload.Status,
+		},
+	}
+
+	// 3. Gửi từng device
+	var lastErr error
+	for _, device := range devices {
+		msg.Token = device.Token
+		_, err := s.sender.SendTo(ctx, device.Provider, msg)
+
+		switch {
+		case err == nil:
+			result.Sent++
+			pushNotificationsTotal.WithLabelValues(device.Provider, "sent").Inc()
+			if err := s.deviceRepo.MarkSent(ctx, device.ID); err != nil {
+				logger.Error("Failed to mark device sent", err)
+			}
+
+		case errors.Is(err, push.ErrInvalidToken):
+			result.Pruned++
+			pushNotificationsTotal.WithLabelValues(device.Provider, "pruned").Inc()
+			if err := s.deviceRepo.Delete(ctx, device.ID); err != nil {
+				logger.Error("Failed to prune invalid device token", err)
+			}
+			logger.Info("[DeviceService] Pruned invalid device token", map[string]interface{}{
+				"user_id":   payload.UserID.String(),
+				"device_id": device.ID.String(),
+				"provider":  device.Provider,
+				"error":     err.Error(),
+			})
+
+		default:
+			result.Failed++
+			lastErr = err
+			pushNotificationsTotal.WithLabelValues(device.Provider, "failed").Inc()
+			disabled, recordErr := s.deviceRepo.RecordFailure(ctx, device.ID, err.Error(), model.MaxDeviceFailures)
+			if recordErr != nil {
+				logger.Error("Failed to record device push failure", recordErr)
+			}
+			if disabled {
+				logger.Info("[DeviceService] Device disabled after repeated push failures", map[string]interface{}{
+					"user_id":   payload.UserID.String(),
+					"device_id": device.ID.String(),
+					"provider":  device.Provider,
+				})
+			}
+		}
+	}
+
+	if result.Sent == 0 && lastErr != nil {
+		return result, fmt.Errorf("push to all devices failed: %w", lastErr)
+	}
+	return result, nil
+}
+
+// validateDeviceToken token APNs là hex (64 ký tự với máy hiện tại), FCM không chứa khoảng trắng
+func validateDeviceToken(provider, platform, token string) error {
+	switch provider {
+	case model.DeviceProviderAPNs:
+		if platform != model.DevicePlatformIOS {
+			return fmt.Errorf("%w: apns tokens are only valid for ios", model.ErrInvalidDeviceToken)
+		}
+		if len(token) < 64 || len(token)%2 != 0 {
+			return fmt.Errorf("%w: apns token must be hex", model.ErrInvalidDeviceToken)
+		}
+		if _, err := hex.DecodeString(token); err != nil {
+			return fmt.Errorf("%w: apns token must be hex", model.ErrInvalidDeviceToken)
+		}
+	default:
+		if len(token) < 32 || strings.ContainsAny(token, " \t\r\n") {
+			return fmt.Errorf("%w: malformed fcm token", model.ErrInvalidDeviceToken)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bookstore-backend/internal/domains/notification/model"
+	"bookstore-backend/internal/domains/notification/repository"
+	emailInfra "bookstore-backend/internal/infrastructure/email"
+	"bookstore-backend/pkg/logger"
+)
+
+// ================================================
+// EMAIL EVENT SERVICE IMPLEMENTATION
+// ================================================
+
+const snsConfirmTimeout = 10 * time.Second
+
+type emailEventService struct {
+	repo       repository.EmailEventRepository
+	httpClient *http.Client
+}
+
+func NewEmailEventService(repo repository.EmailEventRepository) EmailEventService {
+	return &emailEventService{
+		repo:       repo,
+		httpClient: &http.Client{Timeout: snsConfirmTimeout},
+	}
+}
+
+// ================================================
+// AMAZON SES (via SNS)
+// ================================================
+
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	MessageID    string `json:"MessageId"`
+	TopicArn     string `json:"TopicArn"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"` // SNS notification
+	EventType        string `json:"eventType"`        // configuration set event publishing
+	Mail             struct {
+		MessageID     string `json:"messageId"`
+		CommonHeaders struct {
+			MessageID string `json:"messageId"`
+		} `json:"commonHeaders"`
+		Headers []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+	} `json:"mail"`
+	Bounce *struct {
+		BounceType        string    `json:"bounceType"` // Permanent, Transient, Undetermined
+		Timestamp         time.Time `json:"timestamp"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint *struct {
+		ComplaintFeedbackType string    `json:"complaintFeedbackType"`
+		Timestamp             time.Time `json:"timestamp"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+	Delivery *struct {
+		Timestamp  time.Time `json:"timestamp"`
+		Recipients []string  `json:"recipients"`
+	} `json:"delivery"`
+}
+
+// HandleSESNotification xử lý 1 message SNS: SubscriptionConfirmation (tự confirm) hoặc Notification (Bounce/Complaint/Delivery)
+func (s *emailEventService) HandleSESNotification(ctx context.Context, body []byte) (*model.EmailWebhookResult, error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidEmailWebhook, err)
+	}
+
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		if err := s.confirmSNSSubscription(ctx, envelope); err != nil {
+			return nil, err
+		}
+		return &model.EmailWebhookResult{}, nil
+	case "Notification":
+		// xử lý bên dưới
+	default:
+		// UnsubscribeConfirmation, ... → không có gì để làm
+		return &model.EmailWebhookResult{Ignored: 1}, nil
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidEmailWebhook, err)
+	}
+
+	return s.apply(ctx, parseSESEvents(notification))
+}
+
+func parseSESEvents(n sesNotification) []model.EmailEvent {
+	messageID := sesMessageID(n)
+	if messageID == "" {
+		return nil
+	}
+
+	kind := n.NotificationType
+	if kind == "" {
+		kind = n.EventType
+	}
+
+	var events []model.EmailEvent
+	switch kind {
+	case "Bounce":
+		if n.Bounce == nil {
+			return nil
+		}
+		for _, r := range n.Bounce.BouncedRecipients {
+			events = append(events, model.EmailEvent{
+				Provider:   model.EmailProviderSES,
+				MessageID:  messageID,
+				Recipient:  r.EmailAddress,
+				Type:       model.EmailEventBounced,
+				HardBounce: n.Bounce.BounceType == "Permanent",
+				Diagnostic: firstNonEmpty(r.DiagnosticCode, n.Bounce.BounceType+" bounce"),
+				OccurredAt: n.Bounce.Timestamp,
+			})
+		}
+	case "Complaint":
+		if n.Complaint == nil {
+			return nil
+		}
+		for _, r := range n.Complaint.ComplainedRecipients {
+			events = append(events, model.EmailEvent{
+				Provider:   model.EmailProviderSES,
+				MessageID:  messageID,
+				Recipient:  r.EmailAddress,
+				Type:       model.EmailEventComplained,
+				Diagnostic: firstNonEmpty(n.Complaint.ComplaintFeedbackType, "complaint"),
+				OccurredAt: n.Complaint.Timestamp,
+			})
+		}
+	case "Delivery":
+		if n.Delivery == nil {
+			return nil
+		}
+		for _, recipient := range n.Delivery.Recipients {
+			events = append(events, model.EmailEvent{
+				Provider:   model.EmailProviderSES,
+				MessageID:  messageID,
+				Recipient:  recipient,
+				Type:       model.EmailEventDelivered,
+				OccurredAt: n.Delivery.Timestamp,
+			})
+		}
+	}
+
+	return events
+}
+
+// sesMessageID lấy Message-ID header gốc (hệ thống tự sinh lúc gửi), không phải SES messageId
+func sesMessageID(n sesNotification) string {
+	if n.Mail.CommonHeaders.MessageID != "" {
+		return emailInfra.NormalizeMessageID(n.Mail.CommonHeaders.MessageID)
+	}
+	for _, h := range n.Mail.Headers {
+		if strings.EqualFold(h.Name, "Message-ID") {
+			return emailInfra.NormalizeMessageID(h.Value)
+		}
+	}
+	return ""
+}
+
+// confirmSNSSubscription gọi SubscribeURL, chỉ chấp nhận endpoint https của AWS (tránh SSRF qua payload giả)
+func (s *emailEventService) confirmSNSSubscription(ctx context.Context, envelope snsEnvelope) error {
+	subscribeURL, err := url.Parse(envelope.SubscribeURL)
+	if err != nil || subscribeURL.Scheme != "https" || !strings.HasSuffix(subscribeURL.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("%w: untrusted SubscribeURL", model.ErrInvalidEmailWebhook)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("build SNS confirm request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("confirm SNS subscription: unexpected status %d", resp.StatusCode)
+	}
+
+	logger.Info("[EmailEventService] SNS subscription confirmed", map[string]interface{}{
+		"topic_arn": envelope.TopicArn,
+	})
+	return nil
+}
+
+// ================================================
+// SENDGRID EVENT WEBHOOK
+// ================================================
+
+type sendGridEvent struct {
+	Email     string `json:"email" pii:"email"`
+	Timestamp int64  `json:"timestamp"`
+	Event     string `json:"event"`   // delivered, bounce, spamreport, dropped, ...
+	SMTPID    string `json:"smtp-id"` // Message-ID header gốc
+	Type      string `json:"type"`    // bounce (hard) | blocked (soft), chỉ có với event bounce
+	Reason    string `json:"reason"`
+}
+
+// HandleSendGridEvents xử lý batch event của SendGrid (1 request = mảng event)
+func (s *emailEventService) HandleSendGridEvents(ctx context.Context, body []byte) (*model.EmailWebhookResult, error) {
+	var raw []sendGridEvent
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidEmailWebhook, err)
+	}
+
+	events := make([]model.EmailEvent, 0, len(raw))
+	ignored := 0
+	for _, e := range raw {
+		event, ok := parseSendGridEvent(e)
+		if !ok {
+			ignored++
+			continue
+		}
+		events = append(events, event)
+	}
+
+	result, err := s.apply(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	result.Ignored += ignored
+	return result, nil
+}
+
+func parseSendGridEvent(e sendGridEvent) (model.EmailEvent, bool) {
+	event := model.EmailEvent{
+		Provider:   model.EmailProviderSendGrid,
+		MessageID:  emailInfra.NormalizeMessageID(e.SMTPID),
+		Recipient:  e.Email,
+		Diagnostic: e.Reason,
+		OccurredAt: time.Unix(e.Timestamp, 0),
+	}
+	if event.MessageID == "" {
+		return event, false
+	}
+
+	switch e.Event {
+	case "delivered":
+		event.Type = model.EmailEventDelivered
+		event.Diagnostic = ""
+	case "bounce":
+		event.Type = model.EmailEventBounced
+		event.HardBounce = e.Type != "blocked"
+	case "spamreport":
+		event.Type = model.EmailEventComplained
+		event.Diagnostic = "spam report"
+	default:
+		return event, false
+	}
+
+	return event, true
+}
+
+// ================================================
+// SHARED
+// ================================================
+
+// apply ghi từng event, dừng ở lỗi đầu tiên để handler trả 5xx → provider retry cả request
+// (ghi lại event đã xử lý là idempotent: UPDATE cùng trạng thái, suppression ON CONFLICT DO NOTHING)
+func (s *emailEventService) apply(ctx context.Context, events []model.EmailEvent) (*model.EmailWebhookResult, error) {
+	result := &model.EmailWebhookResult{}
+	for _, event := range events {
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now()
+		}
+
+		updated, err := s.repo.ApplyEmailEvent(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("[EmailEventService] Email event applied", map[string]interface{}{
+			"provider":   event.Provider,
+			"message_id": event.MessageID,
+			"type":       event.Type,
+			"suppressed": event.ShouldSuppress(),
+			"updated":    updated,
+		})
+		result.Processed++
+	}
+	return result, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -48,6 +48,16 @@ type PreferencesService interface {
 	IsInQuietHours(ctx context.Context, userID uuid.UUID) (bool, error)
 }
 
+// ================================================
+// EMAIL EVENT SERVICE INTERFACE (Provider webhooks)
+// ================================================
+
+type EmailEventService interface {
+	// Inbound delivery events: delivered, bounced, complained
+	HandleSESNotification(ctx context.Context, body []byte) (*model.EmailWebhookResult, error)
+	HandleSendGridEvents(ctx context.Context, body []byte) (*model.EmailWebhookResult, error)
+}
+
 // ================================================
 // TEMPLATE SERVICE INTERFACE (Admin)
 // ================================================
@@ -62,7 +72,7 @@ type TemplateService interface {
 	ListTemplates(ctx context.Context, category *string, isActive *bool, page, pageSize int) ([]model.TemplateResponse, int64, error)
 
 	// Template operations
-	RenderTemplate(ctx context.Context, templateCode, channel string, data map[string]interface{}) (string, string, error)
+	RenderTemplate(ctx context.Context, templateCode, language, channel string, data map[string]interface{}) (string, string, error)
 	ValidateTemplateVariables(ctx context.Context, templateCode string, data map[string]interface{}) error
 }
 
@@ -102,3 +112,17 @@ type DeliveryService interface {
 	// Retry failed deliveries
 	RetryFailedDeliveries(ctx context.Context, limit int) error
 }
+
+// ================================================
+// DEVICE SERVICE INTERFACE (push tokens)
+// ================================================
+
+type DeviceService interface {
+	// App đăng ký token sau login, gỡ khi logout
+	RegisterDevice(ctx context.Context, userID uuid.UUID, req model.RegisterDeviceRequest) (*model.UserDevice, error)
+	UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error
+	ListDevices(ctx context.Context, userID uuid.UUID) ([]model.UserDevice, error)
+
+	// Push trạng thái đơn (worker, task notification:order_status_push)
+	SendOrderStatusPush(ctx context.Context, payload model.OrderStatusPushPayload) (*model.PushResult, error)
+}
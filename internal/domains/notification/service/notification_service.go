@@ -10,6 +10,7 @@ import (
 	"bookstore-backend/internal/domains/notification/model"
 	"bookstore-backend/internal/domains/notification/repository"
 	user "bookstore-backend/internal/domains/user"
+	"bookstore-backend/internal/shared/locale"
 	"bookstore-backend/pkg/logger"
 )
 
@@ -70,8 +71,10 @@ func (s *notificationService) SendNotification(ctx context.Context, req model.Se
 		"template_code": req.TemplateCode,
 	})
 
-	// 1. VALIDATE TEMPLATE EXISTS AND ACTIVE
-	template, err := s.templateRepo.GetByCode(ctx, req.TemplateCode)
+	// 1. VALIDATE TEMPLATE EXISTS AND ACTIVE (bản theo ngôn ngữ của user)
+	userPrefs := s.loadUserPreferences(ctx, req.UserID)
+	userLocale := locale.Normalize(userPrefs.Language)
+	template, err := s.templateRepo.GetByCodeAndLanguage(ctx, req.TemplateCode, userLocale)
 	if err != nil {
 		return nil, fmt.Errorf("get template: %w", err)
 	}
@@ -80,6 +83,11 @@ func (s *notificationService) SendNotification(ctx context.Context, req model.Se
 		return nil, model.ErrTemplateInactive
 	}
 
+	// 1.1. MARKETING CẦN OPT-IN (user_preferences.marketing_opt_in)
+	if template.Category == model.CategoryMarketing && !userPrefs.MarketingOptIn {
+		return nil, model.ErrMarketingNotOptedIn
+	}
+
 	// 2. VALIDATE TEMPLATE VARIABLES
 	if err := s.templateService.ValidateTemplateVariables(ctx, req.TemplateCode, req.Data); err != nil {
 		return nil, fmt.Errorf("validate template variables: %w", err)
@@ -92,8 +100,17 @@ func (s *notificationService) SendNotification(ctx context.Context, req model.Se
 	}
 
 	// 4. FILTER CHANNELS BASED ON USER PREFERENCES
+	// Công tắc tổng theo kênh (user_preferences) trước, rồi mới tới cấu hình theo loại notification
 	allowedChannels := []string{}
 	for _, channel := range channels {
+		if !userPrefs.IsChannelEnabled(channel) {
+			logger.Info("Channel disabled in user preferences", map[string]interface{}{
+				"user_id": req.UserID.String(),
+				"channel": channel,
+			})
+			continue
+		}
+
 		allowed, reason, err := s.prefsService.CanSendNotification(ctx, req.UserID, template.Code, channel)
 		if err != nil {
 			logger.Error("Error checking channel permission", err)
@@ -136,7 +153,7 @@ func (s *notificationService) SendNotification(ctx context.Context, req model.Se
 	// 6. RENDER TEMPLATES FOR EACH CHANNEL
 	renderedContent := make(map[string]string)
 	for _, channel := range allowedChannels {
-		title, body, err := s.templateService.RenderTemplate(ctx, req.TemplateCode, channel, req.Data)
+		title, body, err := s.templateService.RenderTemplate(ctx, req.TemplateCode, userLocale, channel, req.Data)
 		if err != nil {
 			logger.Error("Error rendering template", err)
 			continue
@@ -234,6 +251,7 @@ func (s *notificationService) CreateNotification(ctx context.Context, req model.
 		model.NotificationTypePayment,
 		model.NotificationTypeNewPromotion,
 		model.NotificationTypeReviewResponse,
+		model.NotificationTypeQuestionAnswered,
 		model.NotificationTypeSystemAlert,
 	}
 
@@ -669,6 +687,17 @@ func (s *notificationService) CleanupOldReadNotifications(ctx context.Context, o
 // HELPER METHODS
 // ================================================
 
+// loadUserPreferences lấy ngôn ngữ, marketing opt-in, công tắc kênh của user
+// Lỗi DB → preferences mặc định, không chặn gửi notification
+func (s *notificationService) loadUserPreferences(ctx context.Context, userID uuid.UUID) *user.Preferences {
+	prefs, err := s.userRepository.GetPreferences(ctx, userID)
+	if err != nil {
+		logger.Error("Error loading user preferences", err)
+		return user.DefaultPreferences(userID, locale.Default)
+	}
+	return prefs
+}
+
 func (s *notificationService) generateIdempotencyKey(notificationType string, referenceID *uuid.UUID, userID uuid.UUID) string {
 	if referenceID == nil {
 		return fmt.Sprintf("%s:%s:%d", notificationType, userID.String(), time.Now().Unix())
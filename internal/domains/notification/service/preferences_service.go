@@ -202,6 +202,11 @@ func (s *preferencesService) createDefaultPreferences(userID uuid.UUID) *model.N
 			"email":  false,
 			"push":   false,
 		},
+		model.NotificationTypeQuestionAnswered: map[string]interface{}{
+			"in_app": true,
+			"email":  false,
+			"push":   false,
+		},
 		model.NotificationTypeSystemAlert: map[string]interface{}{
 			"in_app": true,
 			"email":  true,
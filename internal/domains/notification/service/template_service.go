@@ -10,6 +10,7 @@ import (
 
 	"bookstore-backend/internal/domains/notification/model"
 	"bookstore-backend/internal/domains/notification/repository"
+	"bookstore-backend/internal/shared/locale"
 	"bookstore-backend/pkg/logger"
 )
 
@@ -42,9 +43,10 @@ func (s *templateService) CreateTemplate(ctx context.Context, adminID uuid.UUID,
 		return nil, fmt.Errorf("invalid template code format: use lowercase letters, numbers, and underscores only")
 	}
 
-	// 2. CHECK IF CODE ALREADY EXISTS
-	existing, err := s.templateRepo.GetByCode(ctx, req.Code)
-	if err == nil && existing != nil {
+	// 2. CHECK IF CODE ALREADY EXISTS (mỗi ngôn ngữ 1 bản)
+	// GetByCodeAndLanguage fallback locale mặc định → phải so language để không chặn nhầm bản dịch mới
+	existing, err := s.templateRepo.GetByCodeAndLanguage(ctx, req.Code, req.Language)
+	if err == nil && existing != nil && existing.Language == req.Language {
 		return nil, model.ErrTemplateCodeExists
 	}
 
@@ -287,9 +289,10 @@ func (s *templateService) ListTemplates(ctx context.Context, category *string, i
 // RENDER TEMPLATE
 // ================================================
 
-func (s *templateService) RenderTemplate(ctx context.Context, templateCode, channel string, data map[string]interface{}) (string, string, error) {
+// RenderTemplate render template theo ngôn ngữ của người nhận, chưa có bản dịch thì dùng locale mặc định
+func (s *templateService) RenderTemplate(ctx context.Context, templateCode, language, channel string, data map[string]interface{}) (string, string, error) {
 	// 1. GET TEMPLATE
-	template, err := s.templateRepo.GetByCode(ctx, templateCode)
+	template, err := s.templateRepo.GetByCodeAndLanguage(ctx, templateCode, locale.Normalize(language))
 	if err != nil {
 		return "", "", fmt.Errorf("get template: %w", err)
 	}
@@ -3,12 +3,15 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"path/filepath"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"bookstore-backend/internal/domains/order/model"
 	"bookstore-backend/internal/domains/order/service"
+	"bookstore-backend/internal/shared/middleware"
 	"bookstore-backend/internal/shared/response"
 )
 
@@ -38,16 +41,42 @@ func (h *OrderHandler) RegisterRoutes(router *gin.RouterGroup) {
 		userRoutes.POST("", h.CreateOrder)                         // POST /v1/orders
 		userRoutes.GET("", h.ListOrders)                           // GET /v1/orders?page=1&limit=20&status=pending
 		userRoutes.GET("/:id", h.GetOrderDetail)                   // GET /v1/orders/:id
+		userRoutes.GET("/:id/invoice", h.GetInvoice)               // GET /v1/orders/:id/invoice
 		userRoutes.GET("/number/:orderNumber", h.GetOrderByNumber) // GET /v1/orders/number/ORD-20251108-001
+		userRoutes.PATCH("/:id", h.ModifyOrder)                    // PATCH /v1/orders/:id
 		userRoutes.PATCH("/:id/cancel", h.CancelOrder)             // PATCH /v1/orders/:id/cancel
 		userRoutes.POST("/reorder", h.ReorderFromExisting)         // POST /v1/orders/reorder
 	}
 
+	// Public tracking (không cần đăng nhập, router gắn anti-bot)
+	router.POST("/orders/track", h.TrackOrderPublic) // POST /v1/orders/track
+
 	// Admin routes (protected by admin middleware)
 	adminRoutes := router.Group("/admin/orders")
 	{
-		adminRoutes.GET("", h.ListAllOrders)                  // GET /v1/admin/orders
-		adminRoutes.PATCH("/:id/status", h.UpdateOrderStatus) // PATCH /v1/admin/orders/:id/status
+		adminRoutes.GET("", h.ListAllOrders)                              // GET /v1/admin/orders
+		adminRoutes.GET("/workflow", h.GetStatusWorkflow)                 // GET /v1/admin/orders/workflow
+		adminRoutes.PUT("/workflow", h.UpdateStatusWorkflow)              // PUT /v1/admin/orders/workflow
+		adminRoutes.GET("/cancellation-rules", h.GetCancellationRules)    // GET /v1/admin/orders/cancellation-rules
+		adminRoutes.PUT("/cancellation-rules", h.UpdateCancellationRules) // PUT /v1/admin/orders/cancellation-rules
+		adminRoutes.GET("/:id", h.AdminGetOrderDetail)                    // GET /v1/admin/orders/:id
+		adminRoutes.POST("/:id/communications", h.LogCommunication)       // POST /v1/admin/orders/:id/communications
+		adminRoutes.PATCH("/:id/status", h.UpdateOrderStatus)             // PATCH /v1/admin/orders/:id/status
+		adminRoutes.GET("/:id/invoice", h.AdminGetInvoice)                // GET /v1/admin/orders/:id/invoice
+		adminRoutes.GET("/:id/packing-slip", h.GetPackingSlip)            // GET /v1/admin/orders/:id/packing-slip
+		adminRoutes.GET("/reports/packaging", h.GetPackagingReport)       // GET /v1/admin/orders/reports/packaging
+		adminRoutes.POST("/:id/replacements", h.CreateReplacement)        // POST /v1/admin/orders/:id/replacements
+		adminRoutes.GET("/reports/replacements", h.GetReplacementReport)  // GET /v1/admin/orders/reports/replacements
+		adminRoutes.POST("/imports", h.CreateOrderImport)                 // POST /v1/admin/orders/imports (multipart CSV)
+		adminRoutes.GET("/imports/:id", h.GetOrderImport)                 // GET /v1/admin/orders/imports/:id?status=failed
+	}
+
+	// Kênh bán: cấu hình + nhận đơn sàn (connector Shopee / Lazada dùng tài khoản admin)
+	channelRoutes := router.Group("/admin/channels")
+	{
+		channelRoutes.GET("", h.ListSalesChannels)                    // GET /v1/admin/channels
+		channelRoutes.PATCH("/:code", h.UpdateSalesChannel)           // PATCH /v1/admin/channels/:code
+		channelRoutes.POST("/:code/orders", h.IngestMarketplaceOrder) // POST /v1/admin/channels/:code/orders
 	}
 }
 
@@ -85,6 +114,12 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		})
 		return
 	}
+	if req.AffiliateCode == "" {
+		req.AffiliateCode = middleware.GetAffiliateCode(c)
+	}
+	if req.Channel == "" {
+		req.Channel = c.GetHeader(model.HeaderSalesChannel)
+	}
 
 	// Validate request
 	if err := req.Validate(); err != nil {
@@ -193,6 +228,35 @@ func (h *OrderHandler) GetOrderByNumber(c *gin.Context) {
 	response.Success(c, http.StatusOK, "OK", result)
 }
 
+// TrackOrderPublic godoc
+// @Summary Track order without login
+// @Description Guest/COD customers look up order status, shipment and ETA by order number + last 4 digits of recipient phone
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param request body model.TrackOrderRequest true "Order number and phone last 4 digits"
+// @Success 200 {object} response.SuccessResponse{data=model.PublicTrackingResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /orders/track [post]
+func (h *OrderHandler) TrackOrderPublic(c *gin.Context) {
+	var req model.TrackOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.TrackOrderPublic(c.Request.Context(), req, middleware.GetLocale(c))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
 // =====================================================
 // LIST ORDERS
 // =====================================================
@@ -205,6 +269,8 @@ func (h *OrderHandler) GetOrderByNumber(c *gin.Context) {
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Param status query string false "Filter by status (pending, confirmed, processing, shipping, delivered, cancelled, returned)"
+// @Param pagination query string false "cursor: keyset pagination (first page), page is ignored"
+// @Param cursor query string false "next_cursor of the previous page (implies pagination=cursor)"
 // @Success 200 {object} response.SuccessResponse{data=model.ListOrdersResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -321,6 +387,65 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Order cancelled successfully", nil)
 }
 
+// =====================================================
+// MODIFY ORDER
+// =====================================================
+
+// ModifyOrder godoc
+// @Summary Modify order shipping address or note
+// @Description Change shipping address / delivery note within the modification window after placing an order (before processing)
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Param request body model.ModifyOrderRequest true "Modify order request"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderDetailResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse "Version mismatch"
+// @Failure 422 {object} response.ErrorResponse "Modification window closed"
+// @Router /v1/orders/{id} [patch]
+func (h *OrderHandler) ModifyOrder(c *gin.Context) {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid order ID", map[string]string{
+			"error": "Order ID must be a valid UUID",
+		})
+		return
+	}
+
+	var req model.ModifyOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Validation failed", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.ModifyOrder(c.Request.Context(), orderID, userID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Order updated successfully", result)
+}
+
 // =====================================================
 // REORDER FROM EXISTING ORDER
 // =====================================================
@@ -379,6 +504,8 @@ func (h *OrderHandler) ReorderFromExisting(c *gin.Context) {
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Param status query string false "Filter by status"
+// @Param metadata_key query string false "Filter by metadata key (e.g. utm_source, affiliate_id)"
+// @Param metadata_value query string false "Filter by metadata value (requires metadata_key)"
 // @Success 200 {object} response.SuccessResponse{data=model.ListOrdersResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
@@ -411,6 +538,14 @@ func (h *OrderHandler) ListAllOrders(c *gin.Context) {
 		req.Limit = 20
 	}
 
+	// Admin lọc theo metadata / kênh → chưa có index keyset tương ứng, chỉ page/limit
+	if req.UseCursor() {
+		response.Error(c, http.StatusBadRequest, "Validation failed", map[string]string{
+			"error": "cursor pagination is not supported for admin order listing",
+		})
+		return
+	}
+
 	// Validate request
 	if err := req.Validate(); err != nil {
 		response.Error(c, http.StatusBadRequest, "Validation failed", map[string]string{
@@ -496,6 +631,120 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Order status updated successfully", nil)
 }
 
+// =====================================================
+// ADMIN: ORDER STATUS WORKFLOW
+// =====================================================
+
+// GetStatusWorkflow godoc
+// @Summary Admin: Get order status workflow
+// @Description Allowed status transitions with their side-effect hooks
+// @Tags Admin Orders
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=model.StatusWorkflowResponse}
+// @Router /v1/admin/orders/workflow [get]
+func (h *OrderHandler) GetStatusWorkflow(c *gin.Context) {
+	result, err := h.orderService.GetStatusWorkflow(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// UpdateStatusWorkflow godoc
+// @Summary Admin: Replace order status workflow
+// @Description Replace all transitions; rejected if a status is unreachable from pending or cannot reach a final status
+// @Tags Admin Orders
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateStatusWorkflowRequest true "Transitions"
+// @Success 200 {object} response.SuccessResponse{data=model.StatusWorkflowResponse}
+// @Failure 422 {object} response.ErrorResponse "Invalid workflow"
+// @Router /v1/admin/orders/workflow [put]
+func (h *OrderHandler) UpdateStatusWorkflow(c *gin.Context) {
+	adminID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	var req model.UpdateStatusWorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.UpdateStatusWorkflow(c.Request.Context(), adminID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Order status workflow updated", result)
+}
+
+// =====================================================
+// ADMIN: ORDER CANCELLATION RULES
+// =====================================================
+
+// GetCancellationRules godoc
+// @Summary Admin: Get order cancellation rules
+// @Description Customer self-cancellation rules (max status + time window) for regular and flash-sale orders
+// @Tags Admin Orders
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=model.CancellationRulesResponse}
+// @Router /v1/admin/orders/cancellation-rules [get]
+func (h *OrderHandler) GetCancellationRules(c *gin.Context) {
+	result, err := h.orderService.GetCancellationRules(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// UpdateCancellationRules godoc
+// @Summary Admin: Update order cancellation rules
+// @Description Update rules per scope (default, flash_sale); scopes not sent are kept unchanged
+// @Tags Admin Orders
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateCancellationRulesRequest true "Rules"
+// @Success 200 {object} response.SuccessResponse{data=model.CancellationRulesResponse}
+// @Failure 422 {object} response.ErrorResponse "Invalid rules"
+// @Router /v1/admin/orders/cancellation-rules [put]
+func (h *OrderHandler) UpdateCancellationRules(c *gin.Context) {
+	adminID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	var req model.UpdateCancellationRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.UpdateCancellationRules(c.Request.Context(), adminID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Order cancellation rules updated", result)
+}
+
 // =====================================================
 // HELPER METHODS
 // =====================================================
@@ -527,6 +776,18 @@ func (h *OrderHandler) handleServiceError(c *gin.Context, err error) {
 	if errors.As(err, &orderErr) {
 		// Map error code to HTTP status
 		statusCode := h.getHTTPStatusFromErrorCode(orderErr.Code)
+		var discontinued *model.ItemDiscontinuedError
+		if errors.As(orderErr.Err, &discontinued) {
+			detail := map[string]interface{}{
+				"code":    orderErr.Code,
+				"book_id": discontinued.BookID,
+			}
+			if discontinued.Replacement != nil {
+				detail["replacement"] = discontinued.Replacement
+			}
+			response.Error(c, statusCode, orderErr.Message, detail)
+			return
+		}
 		response.Error(c, statusCode, orderErr.Message, map[string]string{
 			"code": orderErr.Code,
 		})
@@ -587,6 +848,17 @@ func (h *OrderHandler) getHTTPStatusFromErrorCode(code string) int {
 		model.ErrCodeUnauthorized:           http.StatusForbidden,
 		model.ErrCodeInvalidStatus:          http.StatusUnprocessableEntity,
 		model.ErrCodePromoMinAmount:         http.StatusUnprocessableEntity,
+		model.ErrCodeInvalidGift:            http.StatusBadRequest,
+		model.ErrCodeOrderTimeout:           http.StatusGatewayTimeout,
+		model.ErrCodeInvalidWorkflow:        http.StatusUnprocessableEntity,
+		model.ErrCodeModificationClosed:     http.StatusUnprocessableEntity,
+		model.ErrCodeInvalidCancelRules:     http.StatusUnprocessableEntity,
+		model.ErrCodeInvalidChannel:         http.StatusUnprocessableEntity,
+		model.ErrCodeChannelNotFound:        http.StatusNotFound,
+		model.ErrCodeUnknownSKU:             http.StatusUnprocessableEntity,
+		model.ErrCodeInvalidImport:          http.StatusBadRequest,
+		model.ErrCodeImportNotFound:         http.StatusNotFound,
+		model.ErrCodeItemDiscontinued:       http.StatusUnprocessableEntity,
 	}
 
 	if status, exists := statusMap[code]; exists {
@@ -595,3 +867,545 @@ func (h *OrderHandler) getHTTPStatusFromErrorCode(code string) int {
 
 	return http.StatusInternalServerError
 }
+
+// =====================================================
+// INVOICE & PACKING SLIP
+// =====================================================
+
+// GetInvoice godoc
+// @Summary Get order invoice
+// @Description Invoice for buyer: bill-to buyer, ship-to gift recipient (if gift), always with prices
+// @Tags Orders
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} response.SuccessResponse{data=model.InvoiceResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /v1/orders/{id}/invoice [get]
+func (h *OrderHandler) GetInvoice(c *gin.Context) {
+	userID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	orderID, ok := parseOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.orderService.GetInvoice(c.Request.Context(), orderID, &userID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// AdminGetInvoice godoc
+// @Summary Get order invoice (Admin)
+// @Tags Admin Orders
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} response.SuccessResponse{data=model.InvoiceResponse}
+// @Router /v1/admin/orders/{id}/invoice [get]
+func (h *OrderHandler) AdminGetInvoice(c *gin.Context) {
+	orderID, ok := parseOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.orderService.GetInvoice(c.Request.Context(), orderID, nil)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// AdminGetOrderDetail godoc
+// @Summary Get order detail with contact history (Admin)
+// @Description Order detail + outbound communications (confirmation emails, SMS, verification calls, order notifications) newest first
+// @Tags Admin Orders
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} response.SuccessResponse{data=model.AdminOrderDetailResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /v1/admin/orders/{id} [get]
+func (h *OrderHandler) AdminGetOrderDetail(c *gin.Context) {
+	orderID, ok := parseOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.orderService.GetAdminOrderDetail(c.Request.Context(), orderID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// LogCommunication godoc
+// @Summary Log a customer communication for order (Admin)
+// @Description Record contact made outside the system (verification call, manual SMS/email)
+// @Tags Admin Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Param request body model.LogCommunicationRequest true "Communication"
+// @Success 201 {object} response.SuccessResponse{data=model.OrderCommunication}
+// @Failure 422 {object} response.ErrorResponse
+// @Router /v1/admin/orders/{id}/communications [post]
+func (h *OrderHandler) LogCommunication(c *gin.Context) {
+	staffID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	orderID, ok := parseOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.LogCommunicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Validation failed", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.LogCommunication(c.Request.Context(), orderID, staffID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Communication logged", result)
+}
+
+// GetPackingSlip godoc
+// @Summary Get packing slip for shipment
+// @Description Ship-to gift recipient if gift; gift receipt hides all prices. Warehouse staff only see their warehouses
+// @Tags Admin Orders
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} response.SuccessResponse{data=model.PackingSlipResponse}
+// @Failure 403 {object} response.ErrorResponse
+// @Router /v1/admin/orders/{id}/packing-slip [get]
+func (h *OrderHandler) GetPackingSlip(c *gin.Context) {
+	orderID, ok := parseOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.orderService.GetPackingSlip(c.Request.Context(), orderID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	// Staff kho chỉ in phiếu của kho được gán
+	if _, restricted := middleware.GetWarehouseScope(c); restricted {
+		if result.WarehouseID == nil || !middleware.CanAccessWarehouse(c, *result.WarehouseID) {
+			response.Error(c, http.StatusForbidden, "Access denied", map[string]string{
+				"code": model.ErrCodeUnauthorized,
+			})
+			return
+		}
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// GetPackagingReport godoc
+// @Summary Admin: Packaging preference adoption report
+// @Description Share of orders that chose minimal packaging / no printed invoice (default: last 30 days)
+// @Tags Admin Orders
+// @Produce json
+// @Param from_date query string false "RFC3339"
+// @Param to_date query string false "RFC3339"
+// @Success 200 {object} response.SuccessResponse{data=model.PackagingAdoptionReport}
+// @Router /v1/admin/orders/reports/packaging [get]
+func (h *OrderHandler) GetPackagingReport(c *gin.Context) {
+	var req model.PackagingReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.GetPackagingAdoptionReport(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// CreateReplacement godoc
+// @Summary Admin: Create replacement order
+// @Description Resend damaged/lost items at zero charge to the original address (no items = resend everything not yet replaced)
+// @Tags Admin Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Original order ID (UUID)"
+// @Param request body model.CreateReplacementRequest true "Reason + items"
+// @Success 201 {object} response.SuccessResponse{data=model.CreateOrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Router /v1/admin/orders/{id}/replacements [post]
+func (h *OrderHandler) CreateReplacement(c *gin.Context) {
+	staffID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	orderID, ok := parseOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateReplacementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	note := ""
+	if req.Note != nil {
+		note = strings.TrimSpace(*req.Note)
+	}
+
+	result, err := h.orderService.CreateReplacementOrder(c.Request.Context(), model.CreateReplacementOrderRequest{
+		SourceOrderID: orderID,
+		Items:         req.Items,
+		StaffID:       staffID,
+		Reason:        req.Reason,
+		Note:          note,
+	})
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Replacement order created", result)
+}
+
+// GetReplacementReport godoc
+// @Summary Admin: Replacement orders by reason
+// @Description Replacement orders, units and list value per reason (default: last 30 days)
+// @Tags Admin Orders
+// @Produce json
+// @Param from_date query string false "RFC3339"
+// @Param to_date query string false "RFC3339"
+// @Success 200 {object} response.SuccessResponse{data=model.ReplacementReport}
+// @Router /v1/admin/orders/reports/replacements [get]
+func (h *OrderHandler) GetReplacementReport(c *gin.Context) {
+	var req model.ReplacementReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.GetReplacementReport(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// =====================================================
+// SALES CHANNELS
+// =====================================================
+
+// ListSalesChannels godoc
+// @Summary Admin: List sales channels
+// @Description Channel configuration (payment methods, shipping carriers, marketplace account)
+// @Tags Admin Orders
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]model.SalesChannel}
+// @Router /v1/admin/channels [get]
+func (h *OrderHandler) ListSalesChannels(c *gin.Context) {
+	result, err := h.orderService.ListSalesChannels(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// UpdateSalesChannel godoc
+// @Summary Admin: Update sales channel
+// @Description Enable/disable channel, allowed payment methods / carriers (empty = all), marketplace account
+// @Tags Admin Orders
+// @Accept json
+// @Produce json
+// @Param code path string true "Channel code (web, app, shopee, lazada)"
+// @Param request body model.UpdateSalesChannelRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse{data=model.SalesChannel}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /v1/admin/channels/{code} [patch]
+func (h *OrderHandler) UpdateSalesChannel(c *gin.Context) {
+	adminID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	var req model.UpdateSalesChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.UpdateSalesChannel(c.Request.Context(), adminID, c.Param("code"), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Sales channel updated", result)
+}
+
+// IngestMarketplaceOrder godoc
+// @Summary Admin: Ingest marketplace order
+// @Description Push a Shopee/Lazada order into inventory reservation + fulfillment. Re-sending the same external_order_id returns the existing order (duplicate=true)
+// @Tags Admin Orders
+// @Accept json
+// @Produce json
+// @Param code path string true "Marketplace channel code (shopee, lazada)"
+// @Param request body model.MarketplaceOrderRequest true "Marketplace order"
+// @Success 200 {object} response.SuccessResponse{data=model.MarketplaceOrderResponse}
+// @Success 201 {object} response.SuccessResponse{data=model.MarketplaceOrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Router /v1/admin/channels/{code}/orders [post]
+func (h *OrderHandler) IngestMarketplaceOrder(c *gin.Context) {
+	var req model.MarketplaceOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.orderService.IngestMarketplaceOrder(c.Request.Context(), c.Param("code"), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	if result.Duplicate {
+		response.Success(c, http.StatusOK, "Marketplace order already imported", result)
+		return
+	}
+	response.Success(c, http.StatusCreated, "Marketplace order imported", result)
+}
+
+// CreateOrderImport godoc
+// @Summary Admin: Import orders from CSV
+// @Description Offline / phone / historical orders. Columns: order_ref, customer_email, isbn, quantity, payment_method (required), unit_price, paid, address_id, note.
+// @Description Lines sharing order_ref form one order. Orders are created by a background job; re-importing the same order_ref does not duplicate.
+// @Tags Admin Orders
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Success 202 {object} response.SuccessResponse{data=model.OrderImportResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /v1/admin/orders/imports [post]
+func (h *OrderHandler) CreateOrderImport(c *gin.Context) {
+	adminID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Missing CSV file", map[string]string{
+			"error": err.Error(),
+			"code":  model.ErrCodeInvalidImport,
+		})
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv") {
+		response.Error(c, http.StatusBadRequest, "Only CSV files are accepted", map[string]string{
+			"code": model.ErrCodeInvalidImport,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Cannot read file", map[string]string{
+			"error": err.Error(),
+			"code":  model.ErrCodeInvalidImport,
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.orderService.CreateOrderImport(c.Request.Context(), adminID, fileHeader.Filename, file)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Order import queued", result)
+}
+
+// GetOrderImport godoc
+// @Summary Admin: Get order import
+// @Description Progress and per-line result (created order / error) of a CSV order import
+// @Tags Admin Orders
+// @Produce json
+// @Param id path string true "Import ID"
+// @Param status query string false "Filter lines: pending, created, failed"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderImportResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /v1/admin/orders/imports/{id} [get]
+func (h *OrderHandler) GetOrderImport(c *gin.Context) {
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid import ID", map[string]string{
+			"error": "Import ID must be a valid UUID",
+		})
+		return
+	}
+
+	result, err := h.orderService.GetOrderImport(c.Request.Context(), importID, c.Query("status"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+// CreateBulkStatusUpdate godoc
+// @Summary Admin: Bulk update order status from CSV
+// @Description Columns: order_number, status (required), tracking_number. Each order goes through the configured status workflow.
+// @Description Processed by a background job; orders already in the target status are left untouched.
+// @Tags Admin Orders
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Success 202 {object} response.SuccessResponse{data=model.OrderStatusImportResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Router /v1/admin/orders/bulk-status [post]
+func (h *OrderHandler) CreateBulkStatusUpdate(c *gin.Context) {
+	adminID, err := h.getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", map[string]string{
+			"code": model.ErrCodeUnauthorized,
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Missing CSV file", map[string]string{
+			"error": err.Error(),
+			"code":  model.ErrCodeInvalidImport,
+		})
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv") {
+		response.Error(c, http.StatusBadRequest, "Only CSV files are accepted", map[string]string{
+			"code": model.ErrCodeInvalidImport,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Cannot read file", map[string]string{
+			"error": err.Error(),
+			"code":  model.ErrCodeInvalidImport,
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.orderService.CreateOrderStatusImport(c.Request.Context(), adminID, fileHeader.Filename, file)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Bulk status update queued", result)
+}
+
+// GetBulkStatusUpdate godoc
+// @Summary Admin: Get bulk order status update
+// @Description Progress and per-line result (from status / error) of a CSV bulk status update
+// @Tags Admin Orders
+// @Produce json
+// @Param id path string true "Import ID"
+// @Param status query string false "Filter lines: pending, updated, failed"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderStatusImportResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Router /v1/admin/orders/bulk-status/{id} [get]
+func (h *OrderHandler) GetBulkStatusUpdate(c *gin.Context) {
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid import ID", map[string]string{
+			"error": "Import ID must be a valid UUID",
+		})
+		return
+	}
+
+	result, err := h.orderService.GetOrderStatusImport(c.Request.Context(), importID, c.Query("status"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "OK", result)
+}
+
+func parseOrderIDParam(c *gin.Context) (uuid.UUID, bool) {
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid order ID", map[string]string{
+			"error": "Order ID must be a valid UUID",
+		})
+		return uuid.Nil, false
+	}
+	return orderID, true
+}
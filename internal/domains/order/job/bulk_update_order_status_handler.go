@@ -0,0 +1,36 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/domains/order/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// BulkUpdateOrderStatusHandler chuyển trạng thái đơn từ file CSV đã upload (order_status_imports)
+// Lỗi từng đơn ghi vào dòng import; chỉ lỗi hạ tầng mới trả error để asynq retry
+type BulkUpdateOrderStatusHandler struct {
+	service service.OrderService
+}
+
+func NewBulkUpdateOrderStatusHandler(service service.OrderService) *BulkUpdateOrderStatusHandler {
+	return &BulkUpdateOrderStatusHandler{service: service}
+}
+
+func (h *BulkUpdateOrderStatusHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.ProcessOrderStatusImportPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal BulkUpdateOrderStatus payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if err := h.service.ProcessOrderStatusImport(ctx, payload.ImportID); err != nil {
+		logger.Error("BulkUpdateOrderStatus: processing failed", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/order/repository"
+	"bookstore-backend/pkg/logger"
+)
+
+// CleanupIdempotencyKeysHandler xoá Idempotency-Key đã hết hạn (quá DefaultIdempotencyTTL)
+// Reserve chỉ ghi đè key hết hạn khi client dùng lại đúng key → phần còn lại nằm mãi trong bảng nếu không dọn
+type CleanupIdempotencyKeysHandler struct {
+	repo repository.IdempotencyRepository
+}
+
+func NewCleanupIdempotencyKeysHandler(repo repository.IdempotencyRepository) *CleanupIdempotencyKeysHandler {
+	return &CleanupIdempotencyKeysHandler{repo: repo}
+}
+
+func (h *CleanupIdempotencyKeysHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	deleted, err := h.repo.DeleteExpired(ctx)
+	if err != nil {
+		logger.Error("Failed to cleanup idempotency keys", err)
+		return fmt.Errorf("cleanup idempotency keys: %w", err)
+	}
+
+	logger.Info("Cleaned up expired idempotency keys", map[string]interface{}{
+		"deleted_count": deleted,
+	})
+	return nil
+}
@@ -0,0 +1,36 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/domains/order/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// ImportOrdersHandler tạo đơn từ file CSV admin đã upload (order_imports)
+// Lỗi từng đơn ghi vào dòng import; chỉ lỗi hạ tầng mới trả error để asynq retry
+type ImportOrdersHandler struct {
+	service service.OrderService
+}
+
+func NewImportOrdersHandler(service service.OrderService) *ImportOrdersHandler {
+	return &ImportOrdersHandler{service: service}
+}
+
+func (h *ImportOrdersHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload model.ProcessOrderImportPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal ImportOrders payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if err := h.service.ProcessOrderImport(ctx, payload.ImportID); err != nil {
+		logger.Error("ImportOrders: processing failed", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/order/service"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+)
+
+// SyncMarketplaceStockHandler đẩy tồn + giá của 1 sách lên các sàn (enqueue từ job inventory sync)
+// Hết quota phút hiện tại → trả lỗi để asynq retry với backoff
+type SyncMarketplaceStockHandler struct {
+	service service.MarketplaceSyncService
+}
+
+func NewSyncMarketplaceStockHandler(service service.MarketplaceSyncService) *SyncMarketplaceStockHandler {
+	return &SyncMarketplaceStockHandler{service: service}
+}
+
+func (h *SyncMarketplaceStockHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload shared.MarketplaceStockSyncPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("unmarshal MarketplaceStockSync payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	bookID, err := uuid.Parse(payload.BookID)
+	if err != nil {
+		return fmt.Errorf("MarketplaceStockSync: invalid book_id %q: %w", payload.BookID, asynq.SkipRetry)
+	}
+
+	results, err := h.service.SyncBooks(ctx, []uuid.UUID{bookID})
+	if err != nil {
+		logger.Error("MarketplaceStockSync: sync failed", err)
+		return err
+	}
+
+	for _, result := range results {
+		logger.Info("MarketplaceStockSync: channel synced", map[string]interface{}{
+			"book_id":    payload.BookID,
+			"source":     payload.Source,
+			"channel":    result.Channel,
+			"pushed":     result.Pushed,
+			"skipped":    result.Skipped,
+			"not_listed": result.NotListed,
+			"failed":     result.Failed,
+		})
+	}
+	return nil
+}
+
+// ReconcileMarketplaceStockHandler đẩy lại toàn catalog lên các sàn (chạy đêm)
+type ReconcileMarketplaceStockHandler struct {
+	service service.MarketplaceSyncService
+}
+
+func NewReconcileMarketplaceStockHandler(service service.MarketplaceSyncService) *ReconcileMarketplaceStockHandler {
+	return &ReconcileMarketplaceStockHandler{service: service}
+}
+
+func (h *ReconcileMarketplaceStockHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	results, err := h.service.Reconcile(ctx)
+	if err != nil {
+		logger.Error("Failed to reconcile marketplace stock", err)
+		return err
+	}
+
+	for _, result := range results {
+		logger.Info("Marketplace stock reconcile completed", map[string]interface{}{
+			"channel":    result.Channel,
+			"pushed":     result.Pushed,
+			"not_listed": result.NotListed,
+			"failed":     result.Failed,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package marketplace
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// CONNECTOR INTERFACE
+// =====================================================
+
+// Connector đẩy tồn + giá của các SKU lên sàn (Shopee, Lazada, ...)
+// Cùng service connector đẩy đơn sàn về (POST /admin/channels/:code/orders)
+type Connector interface {
+	// PushListings cập nhật tồn + giá của 1 lô SKU trên kênh, kết quả theo từng SKU
+	// Lỗi trả về = cả lô thất bại (mạng, xác thực, sàn từ chối) → caller đánh dấu failed
+	PushListings(ctx context.Context, channel string, updates []ListingUpdate) ([]ListingResult, error)
+}
+
+// MaxBatchSize số SKU tối đa trong 1 lần gọi (giới hạn chung của API cập nhật tồn các sàn)
+const MaxBatchSize = 50
+
+// Kết quả từng SKU
+const (
+	ResultUpdated   = "updated"
+	ResultNotListed = "not_listed" // SKU chưa đăng bán trên sàn
+	ResultRejected  = "rejected"   // sàn từ chối (giá ngoài khung, listing bị khoá, ...)
+)
+
+// ListingUpdate - tồn + giá mới của 1 SKU
+type ListingUpdate struct {
+	SKU      string          `json:"sku"`
+	Quantity int             `json:"quantity"`
+	Price    decimal.Decimal `json:"price"`
+}
+
+// ListingResult - kết quả cập nhật 1 SKU
+type ListingResult struct {
+	SKU    string `json:"sku"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+const defaultTimeout = 30 * time.Second
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultTimeout}
+}
@@ -0,0 +1,89 @@
+package marketplace
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPConnector gọi service connector sàn (ký, map SKU → item/model id, gọi Open API của từng sàn)
+// Body ký HMAC-SHA256 bằng secret chung, kèm timestamp chống replay
+type HTTPConnector struct {
+	baseURL    string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewHTTPConnector creates connector client
+func NewHTTPConnector(baseURL, secret string) *HTTPConnector {
+	return &HTTPConnector{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		secret:     secret,
+		httpClient: newHTTPClient(),
+	}
+}
+
+type pushListingsRequest struct {
+	Listings []ListingUpdate `json:"listings"`
+}
+
+type pushListingsResponse struct {
+	Results []ListingResult `json:"results"`
+	Message string          `json:"message,omitempty"`
+}
+
+func (c *HTTPConnector) PushListings(ctx context.Context, channel string, updates []ListingUpdate) ([]ListingResult, error) {
+	payload, err := json.Marshal(pushListingsRequest{Listings: updates})
+	if err != nil {
+		return nil, fmt.Errorf("marketplace: marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut,
+		c.baseURL+"/channels/"+url.PathEscape(channel)+"/listings", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("marketplace: build request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Timestamp", timestamp)
+	httpReq.Header.Set("X-Signature", c.sign(timestamp, payload))
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("marketplace: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("marketplace: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("marketplace: push listings failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result pushListingsResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("marketplace: decode response: %w", err)
+	}
+	return result.Results, nil
+}
+
+// sign = hex(HMAC-SHA256(secret, timestamp + "." + body))
+func (c *HTTPConnector) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(c.secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,30 @@
+package marketplace
+
+import (
+	"context"
+	"sync"
+)
+
+// MockConnector - sàn giả lập cho dev/staging (USE_MOCK_MARKETPLACE=true), không gọi connector thật
+// Mọi SKU coi như đã đăng bán; giữ tồn + giá đã đẩy trong bộ nhớ để kiểm tra luồng
+type MockConnector struct {
+	mu       sync.Mutex
+	listings map[string]ListingUpdate // channel:sku → lần đẩy gần nhất
+}
+
+// NewMockConnector creates mock connector
+func NewMockConnector() *MockConnector {
+	return &MockConnector{listings: make(map[string]ListingUpdate)}
+}
+
+func (c *MockConnector) PushListings(ctx context.Context, channel string, updates []ListingUpdate) ([]ListingResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make([]ListingResult, 0, len(updates))
+	for _, update := range updates {
+		c.listings[channel+":"+update.SKU] = update
+		results = append(results, ListingResult{SKU: update.SKU, Status: ResultUpdated})
+	}
+	return results, nil
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =====================================================
+// CANCELLATION POLICY
+// =====================================================
+// Khách tự huỷ đơn khi:
+// - Trạng thái chưa vượt MaxStatus (vd: confirmed → đơn processing trở đi không huỷ được)
+// - Và còn trong WindowHours giờ kể từ lúc đặt (0 = không giới hạn thời gian)
+// Đơn có hàng flash sale dùng rule riêng (thường chặt hơn: huỷ xong hàng khó bán lại đúng giá)
+
+// Phạm vi rule (khoá chính bảng order_cancellation_rules)
+const (
+	CancellationScopeDefault   = "default"
+	CancellationScopeFlashSale = "flash_sale"
+)
+
+var CancellationScopes = []string{
+	CancellationScopeDefault,
+	CancellationScopeFlashSale,
+}
+
+// CancellableStatuses trạng thái được chọn làm MaxStatus, theo thứ tự tiến trình đơn
+// (shipping trở đi hàng đã rời kho, không huỷ mà phải hoàn hàng)
+var CancellableStatuses = []string{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusProcessing,
+}
+
+// MaxCancellationWindowHours giới hạn cửa sổ huỷ (30 ngày)
+const MaxCancellationWindowHours = 720
+
+// CancellationRule - rule huỷ đơn của 1 phạm vi
+type CancellationRule struct {
+	Scope       string     `json:"scope"`
+	MaxStatus   string     `json:"max_status"`
+	WindowHours int        `json:"window_hours"` // 0 = không giới hạn thời gian
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Window cửa sổ huỷ (0 = không giới hạn)
+func (r CancellationRule) Window() time.Duration {
+	return time.Duration(r.WindowHours) * time.Hour
+}
+
+// allowsStatus trạng thái hiện tại chưa vượt MaxStatus
+func (r CancellationRule) allowsStatus(status string) bool {
+	for _, s := range CancellableStatuses {
+		if s == status {
+			return true
+		}
+		if s == r.MaxStatus {
+			return false
+		}
+	}
+	return false
+}
+
+// CancellableUntil hạn cuối khách được huỷ (nil = không giới hạn thời gian, chỉ theo trạng thái)
+func (r CancellationRule) CancellableUntil(order *Order) *time.Time {
+	if r.WindowHours <= 0 {
+		return nil
+	}
+	until := order.CreatedAt.Add(r.Window())
+	return &until
+}
+
+// CheckCancellable trả lý do không huỷ được (nil = được huỷ)
+func (r CancellationRule) CheckCancellable(order *Order, now time.Time) error {
+	if !r.allowsStatus(order.Status) {
+		return fmt.Errorf("order with status '%s' cannot be cancelled", order.Status)
+	}
+	if until := r.CancellableUntil(order); until != nil && !now.Before(*until) {
+		return fmt.Errorf("cancellation window closed at %s", until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// CancellationPolicy - tập rule huỷ đơn đang áp dụng
+type CancellationPolicy struct {
+	Default   CancellationRule
+	FlashSale CancellationRule
+}
+
+// RuleFor rule áp cho đơn (đơn có hàng flash sale → rule flash_sale)
+func (p CancellationPolicy) RuleFor(isFlashSale bool) CancellationRule {
+	if isFlashSale {
+		return p.FlashSale
+	}
+	return p.Default
+}
+
+// Rules danh sách rule theo thứ tự CancellationScopes
+func (p CancellationPolicy) Rules() []CancellationRule {
+	return []CancellationRule{p.Default, p.FlashSale}
+}
+
+// DefaultCancellationPolicy - policy khi bảng order_cancellation_rules chưa có dữ liệu
+// Default đúng bằng CanBeCancelled hard-code trước đây (pending/confirmed, không giới hạn thời gian)
+func DefaultCancellationPolicy() CancellationPolicy {
+	return CancellationPolicy{
+		Default: CancellationRule{
+			Scope:     CancellationScopeDefault,
+			MaxStatus: OrderStatusConfirmed,
+		},
+		FlashSale: CancellationRule{
+			Scope:       CancellationScopeFlashSale,
+			MaxStatus:   OrderStatusPending,
+			WindowHours: 1,
+		},
+	}
+}
+
+// NewCancellationPolicy dựng policy từ rule đã lưu, phạm vi thiếu lấy theo mặc định
+func NewCancellationPolicy(rules []CancellationRule) CancellationPolicy {
+	policy := DefaultCancellationPolicy()
+	for _, r := range rules {
+		switch r.Scope {
+		case CancellationScopeDefault:
+			policy.Default = r
+		case CancellationScopeFlashSale:
+			policy.FlashSale = r
+		}
+	}
+	return policy
+}
+
+// ValidateCancellationRules kiểm tra rule trước khi lưu / lúc khởi động
+func ValidateCancellationRules(rules []CancellationRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		validScope := false
+		for _, s := range CancellationScopes {
+			if s == r.Scope {
+				validScope = true
+				break
+			}
+		}
+		if !validScope {
+			return fmt.Errorf("invalid cancellation scope %q", r.Scope)
+		}
+		if seen[r.Scope] {
+			return fmt.Errorf("duplicate cancellation scope %q", r.Scope)
+		}
+		seen[r.Scope] = true
+
+		validStatus := false
+		for _, s := range CancellableStatuses {
+			if s == r.MaxStatus {
+				validStatus = true
+				break
+			}
+		}
+		if !validStatus {
+			return fmt.Errorf("invalid max_status %q for scope %s (allowed: %v)", r.MaxStatus, r.Scope, CancellableStatuses)
+		}
+		if r.WindowHours < 0 || r.WindowHours > MaxCancellationWindowHours {
+			return fmt.Errorf("window_hours for scope %s must be between 0 and %d", r.Scope, MaxCancellationWindowHours)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// SALES CHANNELS (web / app / marketplace)
+// =====================================================
+// Mỗi đơn thuộc 1 kênh (orders.channel). Kênh direct: khách tự checkout (web, app),
+// kênh marketplace: đơn đặt trên sàn (Shopee, Lazada) được đẩy về qua API ingestion,
+// đi chung luồng reserve tồn + tách kiện theo kho như đơn web.
+
+const (
+	ChannelWeb    = "web"
+	ChannelApp    = "app"
+	ChannelShopee = "shopee"
+	ChannelLazada = "lazada"
+	// Đơn bán tại quầy / qua điện thoại, nhập từ CSV (order_imports)
+	ChannelOffline = "offline"
+)
+
+const (
+	ChannelTypeDirect      = "direct"
+	ChannelTypeMarketplace = "marketplace"
+)
+
+// HeaderSalesChannel - app gửi kèm header để đơn được ghi nhận đúng kênh (body.channel ưu tiên hơn)
+const HeaderSalesChannel = "X-Sales-Channel"
+
+// MaxMarketplaceOrderItems giới hạn số dòng hàng 1 đơn sàn
+const MaxMarketplaceOrderItems = 100
+
+// MaxStockBuffer số lượng giữ lại tối đa khi đẩy tồn lên sàn
+const MaxStockBuffer = 1000
+
+// DirectPaymentMethods phương thức khách tự chọn lúc checkout (cấu hình kênh direct chọn trong danh sách này)
+var DirectPaymentMethods = []string{
+	PaymentMethodCOD,
+	PaymentMethodVNPay,
+	PaymentMethodMomo,
+	PaymentMethodBankTransfer,
+}
+
+var (
+	channelCodeRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+	carrierCodeRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+)
+
+// NormalizeChannel chuẩn hoá mã kênh client gửi lên, rỗng = web
+func NormalizeChannel(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return ChannelWeb
+	}
+	return code
+}
+
+// SalesChannel - cấu hình 1 kênh bán (sales_channels)
+type SalesChannel struct {
+	Code             string     `json:"code"`
+	Name             string     `json:"name"`
+	Type             string     `json:"channel_type"`
+	IsActive         bool       `json:"is_active"`
+	PaymentMethods   []string   `json:"payment_methods"`   // rỗng = tất cả
+	ShippingCarriers []string   `json:"shipping_carriers"` // rỗng = tất cả hãng đang cấu hình
+	AccountUserID    *uuid.UUID `json:"account_user_id,omitempty"`
+	// Đồng bộ tồn + giá lên sàn; tồn đẩy lên = available - StockBuffer (chống oversell)
+	StockSyncEnabled bool       `json:"stock_sync_enabled"`
+	StockBuffer      int        `json:"stock_buffer"`
+	UpdatedBy        *uuid.UUID `json:"updated_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// IsMarketplace đơn của kênh chỉ vào qua API ingestion, không checkout trực tiếp
+func (c *SalesChannel) IsMarketplace() bool {
+	return c.Type == ChannelTypeMarketplace
+}
+
+// AllowsPaymentMethod phương thức thanh toán được bật cho kênh
+func (c *SalesChannel) AllowsPaymentMethod(method string) bool {
+	return len(c.PaymentMethods) == 0 || containsString(c.PaymentMethods, method)
+}
+
+// AllowsCarrier hãng vận chuyển được dùng cho đơn của kênh
+func (c *SalesChannel) AllowsCarrier(carrier string) bool {
+	return len(c.ShippingCarriers) == 0 || containsString(c.ShippingCarriers, carrier)
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// =====================================================
+// ADMIN: CẤU HÌNH KÊNH
+// =====================================================
+
+// UpdateSalesChannelRequest - field nil = giữ nguyên
+type UpdateSalesChannelRequest struct {
+	Name             *string    `json:"name,omitempty"`
+	IsActive         *bool      `json:"is_active,omitempty"`
+	PaymentMethods   *[]string  `json:"payment_methods,omitempty"`
+	ShippingCarriers *[]string  `json:"shipping_carriers,omitempty"`
+	AccountUserID    *uuid.UUID `json:"account_user_id,omitempty"`
+	StockSyncEnabled *bool      `json:"stock_sync_enabled,omitempty"`
+	StockBuffer      *int       `json:"stock_buffer,omitempty"`
+}
+
+// Validate format; phương thức thanh toán theo loại kênh kiểm tra ở Apply
+func (r *UpdateSalesChannelRequest) Validate() error {
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+	}
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Name, validation.NilOrNotEmpty, validation.Length(1, 100)),
+		validation.Field(&r.ShippingCarriers, validation.By(func(value interface{}) error {
+			carriers, _ := value.(*[]string)
+			if carriers == nil {
+				return nil
+			}
+			for _, code := range *carriers {
+				if !carrierCodeRegex.MatchString(code) {
+					return fmt.Errorf("invalid carrier code %q", code)
+				}
+			}
+			return nil
+		})),
+		validation.Field(&r.StockBuffer, validation.Min(0), validation.Max(MaxStockBuffer)),
+	)
+}
+
+// Apply áp thay đổi lên cấu hình hiện tại và kiểm tra theo loại kênh
+func (r *UpdateSalesChannelRequest) Apply(channel *SalesChannel) error {
+	if r.Name != nil {
+		channel.Name = *r.Name
+	}
+	if r.IsActive != nil {
+		channel.IsActive = *r.IsActive
+	}
+	if r.PaymentMethods != nil {
+		channel.PaymentMethods = dedupeStrings(*r.PaymentMethods)
+	}
+	if r.ShippingCarriers != nil {
+		channel.ShippingCarriers = dedupeStrings(*r.ShippingCarriers)
+	}
+	if r.AccountUserID != nil {
+		channel.AccountUserID = r.AccountUserID
+	}
+	if r.StockSyncEnabled != nil {
+		channel.StockSyncEnabled = *r.StockSyncEnabled
+	}
+	if r.StockBuffer != nil {
+		channel.StockBuffer = *r.StockBuffer
+	}
+
+	// Kênh marketplace: sàn thu tiền → chỉ 'marketplace'; kênh direct: chọn trong các phương thức checkout
+	allowed := DirectPaymentMethods
+	if channel.IsMarketplace() {
+		allowed = []string{PaymentMethodMarketplace}
+	}
+	for _, method := range channel.PaymentMethods {
+		if !containsString(allowed, method) {
+			return fmt.Errorf("payment method %q is not supported for %s channel", method, channel.Type)
+		}
+	}
+	if !channel.IsMarketplace() && channel.AccountUserID != nil {
+		return errors.New("account_user_id is only used by marketplace channels")
+	}
+	if !channel.IsMarketplace() && channel.StockSyncEnabled {
+		return errors.New("stock sync is only available for marketplace channels")
+	}
+	return nil
+}
+
+func dedupeStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v != "" && !containsString(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// =====================================================
+// MARKETPLACE ORDER INGESTION
+// =====================================================
+
+// MarketplaceOrderRequest - đơn đặt trên sàn, do connector của sàn đẩy về
+// Giá / phí ship theo sàn (khách đã trả cho sàn), không áp giá niêm yết / promo bên mình
+type MarketplaceOrderRequest struct {
+	ExternalOrderID string                 `json:"external_order_id" binding:"required"`
+	Items           []MarketplaceOrderItem `json:"items" binding:"required,min=1,dive"`
+	ShippingFee     decimal.Decimal        `json:"shipping_fee"`
+	// SellerDiscount - voucher shop (người bán chịu), voucher sàn không trừ vào doanh thu bên mình
+	SellerDiscount decimal.Decimal      `json:"seller_discount"`
+	Recipient      MarketplaceRecipient `json:"recipient" binding:"required"`
+	BuyerNote      *string              `json:"buyer_note,omitempty"`
+	Metadata       Metadata             `json:"metadata,omitempty"`
+}
+
+// MarketplaceOrderItem - dòng hàng theo SKU trên sàn (SKU = ISBN của sách)
+type MarketplaceOrderItem struct {
+	SKU       string          `json:"sku" binding:"required"`
+	Quantity  int             `json:"quantity" binding:"required,min=1"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+}
+
+// MarketplaceRecipient - người nhận sàn cung cấp
+type MarketplaceRecipient struct {
+	Name     string `json:"name" binding:"required"`
+	Phone    string `json:"phone" binding:"required" pii:"phone"`
+	Province string `json:"province" binding:"required"`
+	District string `json:"district" binding:"required" pii:"address"`
+	Ward     string `json:"ward" binding:"required" pii:"address"`
+	Street   string `json:"street" binding:"required" pii:"address"`
+}
+
+// Validate validates MarketplaceOrderRequest
+func (r *MarketplaceOrderRequest) Validate() error {
+	r.ExternalOrderID = strings.TrimSpace(r.ExternalOrderID)
+	for i := range r.Items {
+		r.Items[i].SKU = strings.TrimSpace(r.Items[i].SKU)
+	}
+
+	return validation.ValidateStruct(r,
+		validation.Field(&r.ExternalOrderID, validation.Required, validation.Length(1, 100)),
+		validation.Field(&r.Items, validation.Required, validation.Length(1, MaxMarketplaceOrderItems), validation.By(uniqueSKUs)),
+		validation.Field(&r.ShippingFee, validation.By(nonNegativeDecimal)),
+		validation.Field(&r.SellerDiscount, validation.By(nonNegativeDecimal)),
+		validation.Field(&r.Recipient),
+		validation.Field(&r.BuyerNote, validation.NilOrNotEmpty, validation.Length(1, 1000)),
+		validation.Field(&r.Metadata),
+	)
+}
+
+// Validate validates MarketplaceOrderItem
+func (i MarketplaceOrderItem) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.SKU, validation.Required, validation.Length(1, 64)),
+		validation.Field(&i.Quantity, validation.Required, validation.Min(1)),
+		validation.Field(&i.UnitPrice, validation.By(nonNegativeDecimal)),
+	)
+}
+
+// Validate validates MarketplaceRecipient
+func (r MarketplaceRecipient) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&r.Phone, validation.Required, validation.Length(6, 20)),
+		validation.Field(&r.Province, validation.Required, validation.Length(1, 100)),
+		validation.Field(&r.District, validation.Required, validation.Length(1, 100)),
+		validation.Field(&r.Ward, validation.Required, validation.Length(1, 100)),
+		validation.Field(&r.Street, validation.Required, validation.Length(1, 500)),
+	)
+}
+
+// uniqueSKUs - connector gộp các dòng cùng SKU trước khi đẩy về (1 dòng = 1 lần reserve)
+func uniqueSKUs(value interface{}) error {
+	items, _ := value.([]MarketplaceOrderItem)
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[item.SKU] {
+			return fmt.Errorf("duplicate sku %q", item.SKU)
+		}
+		seen[item.SKU] = true
+	}
+	return nil
+}
+
+func nonNegativeDecimal(value interface{}) error {
+	d, _ := value.(decimal.Decimal)
+	if d.IsNegative() {
+		return errors.New("must not be negative")
+	}
+	return nil
+}
+
+// SKUs danh sách SKU của đơn (đã Validate: không trùng)
+func (r *MarketplaceOrderRequest) SKUs() []string {
+	skus := make([]string, len(r.Items))
+	for i, item := range r.Items {
+		skus[i] = item.SKU
+	}
+	return skus
+}
+
+// MarketplaceOrderResponse - Duplicate = sàn gửi lại đơn đã nhận, trả đơn đã tạo trước đó
+type MarketplaceOrderResponse struct {
+	OrderID         uuid.UUID       `json:"order_id"`
+	OrderNumber     string          `json:"order_number"`
+	Channel         string          `json:"channel"`
+	ExternalOrderID string          `json:"external_order_id"`
+	Total           decimal.Decimal `json:"total"`
+	Status          string          `json:"status"`
+	Duplicate       bool            `json:"duplicate"`
+}
+
+// ValidChannelCode mã kênh đúng định dạng (trước khi tra DB)
+func ValidChannelCode(code string) bool {
+	return channelCodeRegex.MatchString(code)
+}
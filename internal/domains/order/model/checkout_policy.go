@@ -0,0 +1,162 @@
+package model
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// CHECKOUT POLICY
+// =====================================================
+// Ngưỡng theo tiền hàng (subtotal sau giá theo số lượng, trước promo) - cấu hình qua ORDER_*:
+// - MinOrderAmount: dưới ngưỡng không cho checkout (ORD008)
+// - FreeShippingThreshold: đạt ngưỡng thì phí ship = 0
+// - ShippingDiscountTiers: chưa tới ngưỡng freeship nhưng đạt bậc thì tự giảm % phí ship (không cần mã)
+type CheckoutPolicy struct {
+	MinOrderAmount        decimal.Decimal // <= 0: không giới hạn
+	ShippingFee           decimal.Decimal // Phí ship cố định khi chưa đạt ngưỡng freeship
+	FreeShippingThreshold decimal.Decimal // <= 0: không freeship theo ngưỡng
+	ShippingDiscountTiers []ShippingDiscountTier
+}
+
+// ShippingDiscountTier tiền hàng >= MinSubtotal → giảm Percent% phí ship (100 = miễn phí)
+type ShippingDiscountTier struct {
+	MinSubtotal decimal.Decimal
+	Percent     decimal.Decimal
+}
+
+// NewCheckoutPolicy tạo policy từ giá trị config (VND)
+func NewCheckoutPolicy(minOrderAmount, shippingFee, freeShippingThreshold int64, tiers []ShippingDiscountTier) CheckoutPolicy {
+	sorted := append([]ShippingDiscountTier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].MinSubtotal.LessThan(sorted[j].MinSubtotal)
+	})
+
+	return CheckoutPolicy{
+		MinOrderAmount:        decimal.NewFromInt(minOrderAmount),
+		ShippingFee:           decimal.NewFromInt(shippingFee),
+		FreeShippingThreshold: decimal.NewFromInt(freeShippingThreshold),
+		ShippingDiscountTiers: sorted,
+	}
+}
+
+// ParseShippingDiscountTiers đọc ORDER_SHIPPING_DISCOUNT_TIERS dạng "min_subtotal:percent,..."
+// VD: "200000:30,300000:50" → đơn từ 200k giảm 30% ship, từ 300k giảm 50%. Bậc sai định dạng bị bỏ qua
+func ParseShippingDiscountTiers(spec string) []ShippingDiscountTier {
+	var tiers []ShippingDiscountTier
+	for _, part := range strings.Split(spec, ",") {
+		minStr, percentStr, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			continue
+		}
+		minSubtotal, err := decimal.NewFromString(strings.TrimSpace(minStr))
+		if err != nil || minSubtotal.IsNegative() {
+			continue
+		}
+		percent, err := decimal.NewFromString(strings.TrimSpace(percentStr))
+		if err != nil || !percent.IsPositive() || percent.GreaterThan(decimal.NewFromInt(100)) {
+			continue
+		}
+		tiers = append(tiers, ShippingDiscountTier{MinSubtotal: minSubtotal, Percent: percent})
+	}
+	return tiers
+}
+
+// MeetsMinimum tiền hàng đã đạt giá trị đơn tối thiểu chưa
+func (p CheckoutPolicy) MeetsMinimum(subtotal decimal.Decimal) bool {
+	return !p.MinOrderAmount.IsPositive() || subtotal.GreaterThanOrEqual(p.MinOrderAmount)
+}
+
+// AmountToMinimum số tiền còn thiếu để đạt đơn tối thiểu (0 nếu đã đạt)
+func (p CheckoutPolicy) AmountToMinimum(subtotal decimal.Decimal) decimal.Decimal {
+	if p.MeetsMinimum(subtotal) {
+		return decimal.Zero
+	}
+	return p.MinOrderAmount.Sub(subtotal)
+}
+
+// QualifiesFreeShipping đơn được miễn phí ship (phí ship = 0 cũng tính là miễn phí)
+func (p CheckoutPolicy) QualifiesFreeShipping(subtotal decimal.Decimal) bool {
+	if !p.ShippingFee.IsPositive() {
+		return true
+	}
+	return p.FreeShippingThreshold.IsPositive() && subtotal.GreaterThanOrEqual(p.FreeShippingThreshold)
+}
+
+// AmountToFreeShipping số tiền cần mua thêm để được freeship (nil = không có chương trình freeship theo ngưỡng)
+func (p CheckoutPolicy) AmountToFreeShipping(subtotal decimal.Decimal) *decimal.Decimal {
+	if p.QualifiesFreeShipping(subtotal) {
+		zero := decimal.Zero
+		return &zero
+	}
+	if !p.FreeShippingThreshold.IsPositive() {
+		return nil
+	}
+	remaining := p.FreeShippingThreshold.Sub(subtotal)
+	return &remaining
+}
+
+// ShippingFeeFor phí ship theo tiền hàng
+func (p CheckoutPolicy) ShippingFeeFor(subtotal decimal.Decimal) decimal.Decimal {
+	if p.QualifiesFreeShipping(subtotal) {
+		return decimal.Zero
+	}
+	return p.ShippingFee
+}
+
+// WithShippingFee policy với phí ship gốc khác (phí hãng báo theo kiện), giữ ngưỡng freeship / bậc giảm
+func (p CheckoutPolicy) WithShippingFee(fee decimal.Decimal) CheckoutPolicy {
+	p.ShippingFee = fee
+	return p
+}
+
+// ShippingTierFor bậc giảm ship cao nhất tiền hàng đạt được (nil = chưa đạt bậc nào)
+func (p CheckoutPolicy) ShippingTierFor(subtotal decimal.Decimal) *ShippingDiscountTier {
+	var matched *ShippingDiscountTier
+	for i := range p.ShippingDiscountTiers {
+		if subtotal.GreaterThanOrEqual(p.ShippingDiscountTiers[i].MinSubtotal) {
+			matched = &p.ShippingDiscountTiers[i]
+		}
+	}
+	return matched
+}
+
+// ShippingDiscountFor giảm trên phí ship: lấy mức lớn hơn giữa bậc tự động và mã giảm ship (không cộng dồn),
+// không vượt phí ship
+func (p CheckoutPolicy) ShippingDiscountFor(subtotal, shippingFee, promoShippingDiscount decimal.Decimal) decimal.Decimal {
+	if !shippingFee.IsPositive() {
+		return decimal.Zero
+	}
+
+	discount := decimal.Max(promoShippingDiscount, decimal.Zero)
+	if tier := p.ShippingTierFor(subtotal); tier != nil {
+		tierDiscount := shippingFee.Mul(tier.Percent).Div(decimal.NewFromInt(100)).Round(0)
+		discount = decimal.Max(discount, tierDiscount)
+	}
+	return decimal.Min(discount, shippingFee)
+}
+
+// ShippingPromoDiscount số tiền mã giảm ship (shipping_percentage / shipping_fixed) giảm được trên phí ship.
+// Mã giảm tiền hàng → 0
+func ShippingPromoDiscount(discountType string, value decimal.Decimal, maxDiscount *decimal.Decimal, shippingFee decimal.Decimal) decimal.Decimal {
+	if !shippingFee.IsPositive() {
+		return decimal.Zero
+	}
+
+	var discount decimal.Decimal
+	switch discountType {
+	case PromoTypeShippingPercentage:
+		discount = shippingFee.Mul(value).Div(decimal.NewFromInt(100))
+		if maxDiscount != nil && discount.GreaterThan(*maxDiscount) {
+			discount = *maxDiscount
+		}
+	case PromoTypeShippingFixed:
+		discount = value
+	default:
+		return decimal.Zero
+	}
+
+	return decimal.Min(discount, shippingFee).Round(0)
+}
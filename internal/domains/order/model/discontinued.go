@@ -0,0 +1,23 @@
+package model
+
+import (
+	"fmt"
+
+	bookModel "bookstore-backend/internal/domains/book/model"
+
+	"github.com/google/uuid"
+)
+
+// ErrCodeItemDiscontinued sách trong đơn đã ngừng bán (cùng code với cart validation)
+const ErrCodeItemDiscontinued = "ITEM_DISCONTINUED"
+
+// ItemDiscontinuedError - Err của OrderError(ErrCodeItemDiscontinued), mang gợi ý thay thế cho client
+type ItemDiscontinuedError struct {
+	BookID      uuid.UUID
+	Title       string
+	Replacement *bookModel.ReplacementSuggestion
+}
+
+func (e *ItemDiscontinuedError) Error() string {
+	return fmt.Sprintf("book %s (%s) has been discontinued", e.BookID, e.Title)
+}
@@ -1,12 +1,20 @@
 package model
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/internal/shared/pagination"
 )
 
 // =====================================================
@@ -18,25 +26,154 @@ type CreateOrderRequest struct {
 	PromoCode     *string           `json:"promo_code,omitempty"`
 	CustomerNote  *string           `json:"customer_note,omitempty"`
 	Items         []CreateOrderItem `json:"items" binding:"omitempty,min=1"`
+	// Gift - nil = đơn thường, giao tới address_id
+	Gift *GiftOptionsRequest `json:"gift,omitempty"`
+	// Packaging - nil = đóng gói tiêu chuẩn, có in hoá đơn
+	Packaging *PackagingPreferences `json:"packaging,omitempty"`
+	// PlacedBy - nhân viên đặt thay khách (set bởi hệ thống, không nhận từ client)
+	PlacedBy *uuid.UUID `json:"-"`
+	// Metadata - nguồn kênh (utm_*, affiliate_id, app_version...), lưu orders.metadata
+	Metadata Metadata `json:"metadata,omitempty"`
+	// ItemMetadata - metadata dòng hàng theo book_id khi items lấy từ cart (dòng quà bỏ qua)
+	ItemMetadata map[uuid.UUID]Metadata `json:"item_metadata,omitempty"`
+	// AffiliateCode - mã đối tác giới thiệu; rỗng → handler lấy từ ?aff= / cookie aff_code.
+	// Sai định dạng / không khớp đối tác thì bỏ qua, không chặn đặt hàng
+	AffiliateCode string `json:"affiliate_code,omitempty"`
+	// Channel - kênh đặt hàng (web / app), rỗng → handler lấy header X-Sales-Channel, không có thì web
+	Channel string `json:"channel,omitempty"`
+	// PostCommitTasks - task hậu checkout của caller (email, auto-release, tracking) ghi outbox
+	// cùng transaction tạo đơn (cần order id / number nên build sau khi tạo đơn)
+	PostCommitTasks PostCommitTaskBuilder `json:"-"`
+	// OrderID - id đặt trước bởi checkout saga (uuid.Nil → tự sinh), để bù trừ được khi
+	// CreateOrder trả lỗi nhưng transaction thực ra đã commit (timeout lúc commit, ctx huỷ)
+	OrderID uuid.UUID `json:"-"`
+}
+
+// PostCommitTaskBuilder build outbox message từ đơn vừa tạo (chưa commit)
+type PostCommitTaskBuilder func(order *Order) ([]outbox.Message, error)
+
+// PackagingPreferences - lựa chọn giảm bao bì / giấy của khách lúc checkout
+type PackagingPreferences struct {
+	MinimalPackaging bool `json:"minimal_packaging"`  // Hộp nhỏ nhất, không chèn xốp / túi khí nhựa
+	NoPrintedInvoice bool `json:"no_printed_invoice"` // Không bỏ hoá đơn giấy vào kiện (xem hoá đơn online)
+}
+
+// =====================================================
+// GIFT OPTIONS
+// =====================================================
+// address_id vẫn là địa chỉ người mua (billing), hàng giao tới người nhận bên dưới
+type GiftOptionsRequest struct {
+	RecipientName  string  `json:"recipient_name" binding:"required" pii:"name"`
+	RecipientPhone string  `json:"recipient_phone" binding:"required" pii:"phone"`
+	Province       string  `json:"province" binding:"required"`
+	District       string  `json:"district" binding:"required" pii:"address"`
+	Ward           string  `json:"ward" binding:"required" pii:"address"`
+	Street         string  `json:"street" binding:"required" pii:"address"`
+	GiftMessage    *string `json:"gift_message,omitempty"`
+	// HidePrices - gift receipt, default true
+	HidePrices *bool `json:"hide_prices,omitempty"`
+	// ScheduledDeliveryDate - YYYY-MM-DD, optional
+	ScheduledDeliveryDate *string `json:"scheduled_delivery_date,omitempty"`
+}
+
+var giftPhoneRegex = regexp.MustCompile(`^(\+84|0)[0-9]{9,10}$`)
+
+// Validate validates GiftOptionsRequest (format + ngày hẹn giao)
+func (req GiftOptionsRequest) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.RecipientName, validation.Required, validation.Length(2, 255)),
+		validation.Field(&req.RecipientPhone, validation.Required, validation.Match(giftPhoneRegex)),
+		validation.Field(&req.Province, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.District, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Ward, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Street, validation.Required, validation.Length(1, 500)),
+		validation.Field(&req.GiftMessage, validation.NilOrNotEmpty, validation.Length(1, GiftMessageMaxLength)),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = req.ParseScheduledDeliveryDate(time.Now())
+	return err
+}
+
+// ParseScheduledDeliveryDate parse + validate ngày hẹn giao (theo ngày, không theo giờ)
+func (req GiftOptionsRequest) ParseScheduledDeliveryDate(now time.Time) (*time.Time, error) {
+	if req.ScheduledDeliveryDate == nil || *req.ScheduledDeliveryDate == "" {
+		return nil, nil
+	}
+
+	date, err := time.ParseInLocation(GiftDateLayout, *req.ScheduledDeliveryDate, now.Location())
+	if err != nil {
+		return nil, fmt.Errorf("scheduled_delivery_date must be YYYY-MM-DD")
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if date.Before(today.AddDate(0, 0, GiftMinLeadDays)) {
+		return nil, fmt.Errorf("scheduled_delivery_date must be at least %d day(s) from today", GiftMinLeadDays)
+	}
+	if date.After(today.AddDate(0, 0, GiftMaxScheduleDays)) {
+		return nil, fmt.Errorf("scheduled_delivery_date must be within %d days", GiftMaxScheduleDays)
+	}
+
+	return &date, nil
+}
+
+// ToEntity builds OrderGift (sau khi đã Validate)
+func (req GiftOptionsRequest) ToEntity(orderID uuid.UUID, scheduledDate *time.Time) *OrderGift {
+	hidePrices := true
+	if req.HidePrices != nil {
+		hidePrices = *req.HidePrices
+	}
+
+	return &OrderGift{
+		OrderID:               orderID,
+		RecipientName:         strings.TrimSpace(req.RecipientName),
+		RecipientPhone:        strings.TrimSpace(req.RecipientPhone),
+		Province:              strings.TrimSpace(req.Province),
+		District:              strings.TrimSpace(req.District),
+		Ward:                  strings.TrimSpace(req.Ward),
+		Street:                strings.TrimSpace(req.Street),
+		GiftMessage:           req.GiftMessage,
+		HidePrices:            hidePrices,
+		ScheduledDeliveryDate: scheduledDate,
+	}
 }
 
 type CreateOrderItem struct {
 	BookID   uuid.UUID `json:"book_id" binding:"required"`
 	Quantity int       `json:"quantity" binding:"required,min=1"`
+	// Dòng quà 0đ từ cart (server set, không nhận từ client)
+	GiftPromotionID *uuid.UUID `json:"-"`
 	// Price    decimal.Decimal `json:"price" binding:"required"`
+	Metadata Metadata `json:"metadata,omitempty"`
+}
+
+// Validate validates CreateOrderItem metadata
+func (i CreateOrderItem) Validate() error {
+	return i.Metadata.Validate()
 }
 
 // Validate validates CreateOrderRequest
 func (req CreateOrderRequest) Validate() error {
+	paymentMethods := []interface{}{
+		PaymentMethodCOD,
+		PaymentMethodVNPay,
+		PaymentMethodMomo,
+		PaymentMethodBankTransfer,
+	}
+	// pay_link chỉ dành cho đơn nhân viên đặt thay khách
+	if req.PlacedBy != nil {
+		paymentMethods = append(paymentMethods, PaymentMethodPayLink)
+	}
+
 	return validation.ValidateStruct(&req,
 		validation.Field(&req.AddressID, validation.Required, is.UUIDv4),
-		validation.Field(&req.PaymentMethod, validation.Required, validation.In(
-			PaymentMethodCOD,
-			PaymentMethodVNPay,
-			PaymentMethodMomo,
-			PaymentMethodBankTransfer,
-		)),
+		validation.Field(&req.PaymentMethod, validation.Required, validation.In(paymentMethods...)),
 		// validation.Field(&req.Items, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Items),
+		validation.Field(&req.Metadata),
+		validation.Field(&req.ItemMetadata, validation.By(validateItemMetadata)),
 	)
 }
 
@@ -62,9 +199,11 @@ type OrderDetailResponse struct {
 	PaymentStatus       string                `json:"payment_status"`
 	Subtotal            decimal.Decimal       `json:"subtotal"`
 	ShippingFee         decimal.Decimal       `json:"shipping_fee"`
+	ShippingDiscount    decimal.Decimal       `json:"shipping_discount"`
 	CODFee              decimal.Decimal       `json:"cod_fee"`
 	DiscountAmount      decimal.Decimal       `json:"discount_amount"`
 	TaxAmount           decimal.Decimal       `json:"tax_amount"`
+	TaxLines            []OrderTaxLine        `json:"tax_lines,omitempty"`
 	Total               decimal.Decimal       `json:"total"`
 	Items               []OrderItemResponse   `json:"items"`
 	Address             *OrderAddressResponse `json:"address,omitempty"`
@@ -79,6 +218,30 @@ type OrderDetailResponse struct {
 	UpdatedAt           time.Time             `json:"updated_at"`
 	CancelledAt         *time.Time            `json:"cancelled_at,omitempty"`
 	Version             int                   `json:"version"`
+	Gift                *OrderGiftResponse    `json:"gift,omitempty"`
+	// Hạn cuối khách tự sửa địa chỉ / ghi chú (nil = không còn sửa được)
+	ModifiableUntil *time.Time           `json:"modifiable_until,omitempty"`
+	Packaging       PackagingPreferences `json:"packaging"`
+	// Khách còn tự huỷ được không; CancellableUntil nil = không giới hạn thời gian (chỉ theo trạng thái)
+	Cancellable      bool       `json:"cancellable"`
+	CancellableUntil *time.Time `json:"cancellable_until,omitempty"`
+	// Kiện theo kho (nhiều kiện khi đơn được tách); rỗng với đơn tạo trước khi có order_shipments
+	Shipments []OrderShipmentResponse `json:"shipments,omitempty"`
+	// Nguồn kênh lúc checkout (utm_*, affiliate_id, app_version...)
+	Metadata Metadata `json:"metadata,omitempty"`
+	// Kênh bán + mã đơn bên sàn (đơn Shopee / Lazada)
+	Channel         string  `json:"channel"`
+	ExternalOrderID *string `json:"external_order_id,omitempty"`
+}
+
+// OrderGiftResponse - thông tin quà tặng trong order detail
+type OrderGiftResponse struct {
+	RecipientName         string  `json:"recipient_name" pii:"name"`
+	RecipientPhone        string  `json:"recipient_phone" pii:"phone"`
+	FullAddress           string  `json:"full_address" pii:"address"`
+	GiftMessage           *string `json:"gift_message,omitempty"`
+	HidePrices            bool    `json:"hide_prices"`
+	ScheduledDeliveryDate *string `json:"scheduled_delivery_date,omitempty"`
 }
 
 type OrderItemResponse struct {
@@ -91,16 +254,26 @@ type OrderItemResponse struct {
 	Quantity     int             `json:"quantity"`
 	Price        decimal.Decimal `json:"price"`
 	Subtotal     decimal.Decimal `json:"subtotal"`
+
+	ListPrice           decimal.Decimal  `json:"list_price"`
+	TierDiscountPercent *decimal.Decimal `json:"tier_discount_percent,omitempty"`
+	Savings             decimal.Decimal  `json:"savings"`
+
+	// Quà tặng kèm đơn (price = 0)
+	IsFreeGift      bool       `json:"is_free_gift"`
+	GiftPromotionID *uuid.UUID `json:"gift_promotion_id,omitempty"`
+
+	Metadata Metadata `json:"metadata,omitempty"`
 }
 
 type OrderAddressResponse struct {
 	ID           uuid.UUID `json:"id"`
-	ReceiverName string    `json:"receiver_name"`
-	Phone        string    `json:"phone"`
+	ReceiverName string    `json:"receiver_name" pii:"name"`
+	Phone        string    `json:"phone" pii:"phone"`
 	Province     string    `json:"province"`
-	District     string    `json:"district"`
-	Ward         string    `json:"ward"`
-	FullAddress  string    `json:"full_address"`
+	District     string    `json:"district" pii:"address"`
+	Ward         string    `json:"ward" pii:"address"`
+	FullAddress  string    `json:"full_address" pii:"address"`
 }
 
 // =====================================================
@@ -108,8 +281,18 @@ type OrderAddressResponse struct {
 // =====================================================
 type ListOrdersRequest struct {
 	Status string `form:"status"` // Filter by status (optional)
-	Page   int    `form:"page" binding:"min=1"`
-	Limit  int    `form:"limit" binding:"min=1,max=100"`
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	// Cursor mode: ?pagination=cursor (trang đầu) hoặc ?cursor=<next_cursor>; page bị bỏ qua
+	Pagination string `form:"pagination"`
+	Cursor     string `form:"cursor"`
+	// Cursor đã decode (Validate)
+	After *pagination.Cursor `form:"-" json:"-"`
+	// Admin: lọc theo orders.metadata (chỉ key → đơn có key; key + value → đúng giá trị)
+	MetadataKey   string `form:"metadata_key"`
+	MetadataValue string `form:"metadata_value"`
+	// Admin: lọc theo kênh bán (web, app, shopee, lazada...)
+	Channel string `form:"channel"`
 }
 
 // Validate validates ListOrdersRequest
@@ -121,6 +304,23 @@ func (req *ListOrdersRequest) Validate() error {
 		req.Limit = 20 // Default
 	}
 
+	if req.MetadataValue != "" && req.MetadataKey == "" {
+		return errors.New("metadata_value requires metadata_key")
+	}
+	if req.MetadataKey != "" && !metadataKeyRegex.MatchString(req.MetadataKey) {
+		return fmt.Errorf("invalid metadata_key %q", req.MetadataKey)
+	}
+	if req.Channel != "" && !ValidChannelCode(req.Channel) {
+		return fmt.Errorf("invalid channel %q", req.Channel)
+	}
+	if req.Cursor != "" {
+		after, err := pagination.Decode(req.Cursor, 1)
+		if err != nil {
+			return err
+		}
+		req.After = after
+	}
+
 	// Validate status if provided
 	if req.Status != "" {
 		validStatuses := []interface{}{
@@ -138,6 +338,11 @@ func (req *ListOrdersRequest) Validate() error {
 	return nil
 }
 
+// UseCursor request dùng keyset pagination thay vì page/limit
+func (req *ListOrdersRequest) UseCursor() bool {
+	return pagination.IsCursorMode(req.Pagination, req.Cursor)
+}
+
 // =====================================================
 // LIST ORDERS RESPONSE
 // =====================================================
@@ -155,6 +360,13 @@ type OrderSummaryResponse struct {
 	Total         decimal.Decimal `json:"total"`
 	ItemsCount    int             `json:"items_count"`
 	CreatedAt     time.Time       `json:"created_at"`
+	// Admin list: lựa chọn đóng gói để kho lọc / gom đơn
+	Packaging *PackagingPreferences `json:"packaging,omitempty"`
+	// Admin list: nguồn kênh của đơn
+	Metadata Metadata `json:"metadata,omitempty"`
+	// Admin list: kênh bán + mã đơn bên sàn
+	Channel         string  `json:"channel,omitempty"`
+	ExternalOrderID *string `json:"external_order_id,omitempty"`
 }
 
 type PaginationMeta struct {
@@ -162,6 +374,9 @@ type PaginationMeta struct {
 	Limit      int `json:"limit"`
 	Total      int `json:"total"`
 	TotalPages int `json:"total_pages"`
+	// Cursor mode (page / total / total_pages = 0): rỗng = hết danh sách
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more,omitempty"`
 }
 
 // =====================================================
@@ -180,6 +395,30 @@ func (req CancelOrderRequest) Validate() error {
 	)
 }
 
+// =====================================================
+// MODIFY ORDER REQUEST (Customer self-service)
+// =====================================================
+// Field nil = giữ nguyên; CustomerNote rỗng = xoá ghi chú
+type ModifyOrderRequest struct {
+	AddressID    *uuid.UUID `json:"address_id,omitempty"`
+	CustomerNote *string    `json:"customer_note,omitempty"`
+	Version      int        `json:"version"`
+}
+
+// Validate validates ModifyOrderRequest
+func (req ModifyOrderRequest) Validate() error {
+	if req.AddressID == nil && req.CustomerNote == nil {
+		return fmt.Errorf("address_id or customer_note is required")
+	}
+	if req.AddressID != nil && *req.AddressID == uuid.Nil {
+		return fmt.Errorf("address_id: invalid")
+	}
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.CustomerNote, validation.Length(0, 500)),
+		validation.Field(&req.Version, validation.Min(0)),
+	)
+}
+
 // =====================================================
 // UPDATE ORDER STATUS REQUEST (Admin)
 // =====================================================
@@ -188,6 +427,9 @@ type UpdateOrderStatusRequest struct {
 	Version        int     `json:"version" binding:"required"`
 	AdminNote      *string `json:"admin_note,omitempty"`
 	TrackingNumber *string `json:"tracking_number,omitempty"` // For shipping status
+
+	// Ghi chú lịch sử do hệ thống đổi trạng thái (webhook hãng vận chuyển), không ghi đè admin_note
+	HistoryNote *string `json:"-"`
 }
 
 // Validate validates UpdateOrderStatusRequest
@@ -212,3 +454,299 @@ type ReorderRequest struct {
 	OrderID   uuid.UUID `json:"order_id" binding:"required"`
 	AddressID uuid.UUID `json:"address_id" binding:"required"`
 }
+
+// =====================================================
+// PACKING SLIP (shipment)
+// =====================================================
+// Gift receipt (hide_prices) → mọi field giá bị bỏ trống
+type PackingSlipResponse struct {
+	OrderNumber           string                    `json:"order_number"`
+	WarehouseID           *uuid.UUID                `json:"warehouse_id,omitempty"`
+	ShipTo                ShipToResponse            `json:"ship_to"`
+	Items                 []PackingSlipItemResponse `json:"items"`
+	IsGift                bool                      `json:"is_gift"`
+	IsGiftReceipt         bool                      `json:"is_gift_receipt"`
+	GiftMessage           *string                   `json:"gift_message,omitempty"`
+	ScheduledDeliveryDate *string                   `json:"scheduled_delivery_date,omitempty"`
+	Subtotal              *decimal.Decimal          `json:"subtotal,omitempty"`
+	Total                 *decimal.Decimal          `json:"total,omitempty"`
+	CODAmount             *decimal.Decimal          `json:"cod_amount,omitempty"`
+	CustomerNote          *string                   `json:"customer_note,omitempty"`
+	CreatedAt             time.Time                 `json:"created_at"`
+	// Kho đọc khi đóng hàng: đóng gói tối giản / không bỏ hoá đơn giấy vào kiện
+	Packaging PackagingPreferences `json:"packaging"`
+}
+
+type PackingSlipItemResponse struct {
+	BookTitle  string           `json:"book_title"`
+	AuthorName *string          `json:"author_name,omitempty"`
+	Quantity   int              `json:"quantity"`
+	Price      *decimal.Decimal `json:"price,omitempty"`
+	Subtotal   *decimal.Decimal `json:"subtotal,omitempty"`
+	// Serial bản giới hạn / có chữ ký đã gán lúc soạn hàng
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+}
+
+type ShipToResponse struct {
+	Name        string `json:"name"`
+	Phone       string `json:"phone" pii:"phone"`
+	FullAddress string `json:"full_address" pii:"address"`
+}
+
+// =====================================================
+// INVOICE
+// =====================================================
+// Invoice luôn có giá, bill-to = người mua, ship-to = người nhận (nếu là quà)
+type InvoiceResponse struct {
+	InvoiceNumber         string                `json:"invoice_number"`
+	OrderNumber           string                `json:"order_number"`
+	IssuedAt              time.Time             `json:"issued_at"`
+	BillTo                ShipToResponse        `json:"bill_to"`
+	ShipTo                ShipToResponse        `json:"ship_to"`
+	IsGift                bool                  `json:"is_gift"`
+	ScheduledDeliveryDate *string               `json:"scheduled_delivery_date,omitempty"`
+	Items                 []OrderItemResponse   `json:"items"`
+	Subtotal              decimal.Decimal       `json:"subtotal"`
+	ShippingFee           decimal.Decimal       `json:"shipping_fee"`
+	ShippingDiscount      decimal.Decimal       `json:"shipping_discount"`
+	CODFee                decimal.Decimal       `json:"cod_fee"`
+	DiscountAmount        decimal.Decimal       `json:"discount_amount"`
+	TaxAmount             decimal.Decimal       `json:"tax_amount"`
+	TaxLines              []OrderTaxLine        `json:"tax_lines"` // theo thuế suất, giá đã gồm / chưa gồm thuế
+	Total                 decimal.Decimal       `json:"total"`
+	PaymentMethod         string                `json:"payment_method"`
+	PaymentStatus         string                `json:"payment_status"`
+	PaidAt                *time.Time            `json:"paid_at,omitempty"`
+	PaymentTerms          *PaymentTermsResponse `json:"payment_terms,omitempty"`
+	EInvoice              *EInvoiceRef          `json:"e_invoice,omitempty"`
+}
+
+// EInvoiceRef ký hiệu / số hoá đơn điện tử đang hiệu lực của đơn (cột orders.einvoice_*)
+type EInvoiceRef struct {
+	TemplateCode string    `json:"template_code"`
+	Series       string    `json:"series"`
+	Number       string    `json:"number"`
+	IssuedAt     time.Time `json:"issued_at"`
+}
+
+// PaymentTermsResponse công nợ của invoice B2B
+type PaymentTermsResponse struct {
+	QuoteID     uuid.UUID `json:"quote_id"`
+	CompanyName string    `json:"company_name"`
+	TaxCode     *string   `json:"tax_code,omitempty"`
+	TermsDays   int       `json:"terms_days"`
+	DueAt       time.Time `json:"due_at"`
+	IsOverdue   bool      `json:"is_overdue"`
+}
+
+// =====================================================
+// ORDER COMMUNICATIONS (Admin)
+// =====================================================
+
+// AdminOrderDetailResponse - order detail cho admin/support kèm lịch sử liên lạc với khách
+type AdminOrderDetailResponse struct {
+	*OrderDetailResponse
+	Communications []OrderCommunication `json:"communications"`
+	// Số liên lạc gửi lỗi / bounce / bị đánh dấu spam → khách có thể chưa nhận được thông tin đơn
+	DeliveryProblems int `json:"delivery_problems"`
+	// Khách tự sửa địa chỉ / ghi chú sau khi đặt (mới nhất trước)
+	Modifications []OrderModification `json:"modifications"`
+	// Đơn này là đơn gửi bù của đơn nào / các đơn gửi bù của đơn này
+	ReplacementOf *OrderReplacement  `json:"replacement_of,omitempty"`
+	Replacements  []OrderReplacement `json:"replacements"`
+}
+
+// CreateReplacementRequest - admin gửi bù hàng hỏng / thất lạc (items rỗng = gửi lại toàn bộ)
+type CreateReplacementRequest struct {
+	Reason string            `json:"reason" binding:"required"`
+	Items  []CreateOrderItem `json:"items,omitempty" binding:"omitempty,dive"`
+	Note   *string           `json:"note,omitempty"`
+}
+
+// LogCommunicationRequest - staff ghi lại liên lạc ngoài hệ thống (cuộc gọi xác minh, SMS gửi tay)
+type LogCommunicationRequest struct {
+	Channel   string  `json:"channel" binding:"required"`
+	Purpose   string  `json:"purpose" binding:"required"`
+	Status    string  `json:"status" binding:"required"`
+	Recipient *string `json:"recipient,omitempty"`
+	Subject   *string `json:"subject,omitempty"`
+	Note      *string `json:"note,omitempty"`
+}
+
+// Validate validates LogCommunicationRequest
+func (req LogCommunicationRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Channel, validation.Required, validation.In(
+			CommunicationChannelEmail,
+			CommunicationChannelSMS,
+			CommunicationChannelPush,
+			CommunicationChannelCall,
+		)),
+		validation.Field(&req.Purpose, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Status, validation.Required, validation.In(
+			CommunicationStatusSent,
+			CommunicationStatusFailed,
+			CommunicationStatusAnswered,
+			CommunicationStatusNoAnswer,
+		)),
+		validation.Field(&req.Recipient, validation.NilOrNotEmpty, validation.Length(1, 255)),
+		validation.Field(&req.Subject, validation.NilOrNotEmpty, validation.Length(1, 255)),
+		validation.Field(&req.Note, validation.NilOrNotEmpty, validation.Length(1, 2000)),
+	)
+}
+
+// =====================================================
+// ORDER STATUS WORKFLOW (ADMIN)
+// =====================================================
+
+// UpdateStatusWorkflowRequest - thay toàn bộ danh sách transition (PUT)
+type UpdateStatusWorkflowRequest struct {
+	Transitions []StatusTransitionInput `json:"transitions" binding:"required,dive"`
+}
+
+type StatusTransitionInput struct {
+	FromStatus string   `json:"from_status" binding:"required"`
+	ToStatus   string   `json:"to_status" binding:"required"`
+	Hooks      []string `json:"hooks"`
+}
+
+// StatusWorkflowResponse - workflow hiện tại + danh sách trạng thái/hook để admin UI dựng form
+type StatusWorkflowResponse struct {
+	Statuses    []string           `json:"statuses"`
+	Hooks       []string           `json:"hooks"`
+	Transitions []StatusTransition `json:"transitions"`
+}
+
+// =====================================================
+// ORDER CANCELLATION RULES (ADMIN)
+// =====================================================
+
+// UpdateCancellationRulesRequest - thay rule huỷ đơn (PUT), phạm vi không gửi giữ nguyên
+type UpdateCancellationRulesRequest struct {
+	Rules []CancellationRuleInput `json:"rules" binding:"required,min=1,dive"`
+}
+
+type CancellationRuleInput struct {
+	Scope       string `json:"scope" binding:"required"`
+	MaxStatus   string `json:"max_status" binding:"required"`
+	WindowHours int    `json:"window_hours"`
+}
+
+// CancellationRulesResponse - rule hiện tại + danh sách phạm vi / trạng thái để admin UI dựng form
+type CancellationRulesResponse struct {
+	Scopes   []string           `json:"scopes"`
+	Statuses []string           `json:"statuses"`
+	Rules    []CancellationRule `json:"rules"`
+}
+
+// =====================================================
+// PACKAGING ADOPTION REPORT (admin)
+// =====================================================
+const (
+	PackagingReportDefaultRangeDays = 30
+	PackagingReportMaxRangeDays     = 366
+)
+
+// PackagingReportRequest - khoảng thời gian đặt hàng (mặc định 30 ngày gần nhất)
+type PackagingReportRequest struct {
+	FromDate *time.Time `form:"from_date"`
+	ToDate   *time.Time `form:"to_date"`
+}
+
+// Validate điền mặc định và giới hạn khoảng báo cáo
+func (r *PackagingReportRequest) Validate() error {
+	now := time.Now()
+	if r.ToDate == nil {
+		r.ToDate = &now
+	}
+	if r.FromDate == nil {
+		from := r.ToDate.AddDate(0, 0, -PackagingReportDefaultRangeDays)
+		r.FromDate = &from
+	}
+	if r.FromDate.After(*r.ToDate) {
+		return fmt.Errorf("from_date must be before to_date")
+	}
+	if r.ToDate.Sub(*r.FromDate) > time.Duration(PackagingReportMaxRangeDays)*24*time.Hour {
+		return fmt.Errorf("date range must not exceed %d days", PackagingReportMaxRangeDays)
+	}
+	return nil
+}
+
+// PackagingAdoptionReport - tỷ lệ đơn chọn đóng gói tối giản / không in hoá đơn (rate: % trên tổng đơn)
+type PackagingAdoptionReport struct {
+	FromDate               time.Time `json:"from_date"`
+	ToDate                 time.Time `json:"to_date"`
+	TotalOrders            int       `json:"total_orders"`
+	MinimalPackagingOrders int       `json:"minimal_packaging_orders"`
+	NoPrintedInvoiceOrders int       `json:"no_printed_invoice_orders"`
+	BothOrders             int       `json:"both_orders"`
+	MinimalPackagingRate   float64   `json:"minimal_packaging_rate"`
+	NoPrintedInvoiceRate   float64   `json:"no_printed_invoice_rate"`
+	AnyOptionRate          float64   `json:"any_option_rate"`
+}
+
+// CalculateRates tính tỷ lệ % (làm tròn 2 chữ số)
+func (r *PackagingAdoptionReport) CalculateRates() {
+	if r.TotalOrders == 0 {
+		return
+	}
+	rate := func(count int) float64 {
+		return math.Round(float64(count)*10000/float64(r.TotalOrders)) / 100
+	}
+	r.MinimalPackagingRate = rate(r.MinimalPackagingOrders)
+	r.NoPrintedInvoiceRate = rate(r.NoPrintedInvoiceOrders)
+	r.AnyOptionRate = rate(r.MinimalPackagingOrders + r.NoPrintedInvoiceOrders - r.BothOrders)
+}
+
+// =====================================================
+// REPLACEMENT REPORT (admin)
+// =====================================================
+
+// ReplacementReportRequest - khoảng thời gian tạo đơn gửi bù (mặc định 30 ngày gần nhất)
+type ReplacementReportRequest struct {
+	FromDate *time.Time `form:"from_date"`
+	ToDate   *time.Time `form:"to_date"`
+}
+
+// Validate điền mặc định và giới hạn khoảng báo cáo (cùng giới hạn báo cáo đóng gói)
+func (r *ReplacementReportRequest) Validate() error {
+	rangeReq := PackagingReportRequest{FromDate: r.FromDate, ToDate: r.ToDate}
+	if err := rangeReq.Validate(); err != nil {
+		return err
+	}
+	r.FromDate, r.ToDate = rangeReq.FromDate, rangeReq.ToDate
+	return nil
+}
+
+// ReplacementReport - đơn gửi bù theo lý do; rate: % trên số đơn thường tạo trong kỳ
+type ReplacementReport struct {
+	FromDate          time.Time               `json:"from_date"`
+	ToDate            time.Time               `json:"to_date"`
+	TotalOrders       int                     `json:"total_orders"`
+	ReplacementOrders int                     `json:"replacement_orders"`
+	ReplacementRate   float64                 `json:"replacement_rate"`
+	Units             int                     `json:"units"`
+	ListValue         decimal.Decimal         `json:"list_value"` // giá bìa sách đã gửi bù
+	ByReason          []ReplacementReasonStat `json:"by_reason"`
+}
+
+// ReplacementReasonStat - số đơn / số cuốn / giá trị gửi bù của 1 lý do
+type ReplacementReasonStat struct {
+	Reason    string          `json:"reason"`
+	Orders    int             `json:"orders"`
+	Units     int             `json:"units"`
+	ListValue decimal.Decimal `json:"list_value"`
+}
+
+// Summarize cộng tổng từ ByReason + tính tỷ lệ % (làm tròn 2 chữ số)
+func (r *ReplacementReport) Summarize() {
+	r.ReplacementOrders, r.Units, r.ListValue = 0, 0, decimal.Zero
+	for _, stat := range r.ByReason {
+		r.ReplacementOrders += stat.Orders
+		r.Units += stat.Units
+		r.ListValue = r.ListValue.Add(stat.ListValue)
+	}
+	if r.TotalOrders > 0 {
+		r.ReplacementRate = math.Round(float64(r.ReplacementOrders)*10000/float64(r.TotalOrders)) / 100
+	}
+}
@@ -30,6 +30,10 @@ const (
 	PaymentMethodVNPay        = "vnpay"
 	PaymentMethodMomo         = "momo"
 	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodInvoice      = "invoice"     // B2B: thanh toán theo công nợ, chỉ tạo từ quote
+	PaymentMethodPayLink      = "pay_link"    // Đơn CSKH đặt thay khách, khách thanh toán qua link gửi email
+	PaymentMethodReplacement  = "replacement" // Đơn gửi bù (thiếu hàng / sách hỏng), 0đ, tạo từ ticket khiếu nại
+	PaymentMethodMarketplace  = "marketplace" // Đơn sàn (Shopee / Lazada): sàn thu tiền khách, đối soát với sàn
 )
 
 // =====================================================
@@ -45,11 +49,16 @@ const (
 // =====================================================
 // BUSINESS CONSTANTS
 // =====================================================
+// Phí ship / đơn tối thiểu cấu hình qua CheckoutPolicy (ORDER_SHIPPING_FEE, ORDER_MIN_AMOUNT, ...)
+// Thuế suất cấu hình theo tỉnh / danh mục (tax_rules), xem OrderTaxLine
 const (
-	ShippingFee        = 0   // 15,000 VND
-	CODFee             = 0   // 15,000 VND
-	MinimumOrderAmount = 0   // No minimum (set to 0, can be updated later)
-	TaxRate            = 0.0 // 0% tax
+	CODFee = 0 // 15,000 VND
+)
+
+// Loại mã giảm trên phí ship (promotions.discount_type), không trừ vào tiền hàng
+const (
+	PromoTypeShippingPercentage = "shipping_percentage"
+	PromoTypeShippingFixed      = "shipping_fixed"
 )
 
 // =====================================================
@@ -64,6 +73,7 @@ type Order struct {
 	WarehouseID         *uuid.UUID      `json:"warehouse_id,omitempty"`
 	Subtotal            decimal.Decimal `json:"subtotal"`
 	ShippingFee         decimal.Decimal `json:"shipping_fee"`
+	ShippingDiscount    decimal.Decimal `json:"shipping_discount"` // Giảm trên phí ship (mã / bậc tự động)
 	CODFee              decimal.Decimal `json:"cod_fee"`
 	DiscountAmount      decimal.Decimal `json:"discount_amount"`
 	TaxAmount           decimal.Decimal `json:"tax_amount"`
@@ -83,19 +93,64 @@ type Order struct {
 	UpdatedAt           time.Time       `json:"updated_at"`
 	CancelledAt         *time.Time      `json:"cancelled_at,omitempty"`
 	Version             int             `json:"version"`
+
+	// Lựa chọn đóng gói lúc checkout (hiển thị trên packing slip)
+	MinimalPackaging bool `json:"minimal_packaging"`
+	NoPrintedInvoice bool `json:"no_printed_invoice"`
+
+	// Nhân viên đặt thay khách (đơn qua điện thoại), nil = khách tự đặt
+	PlacedBy *uuid.UUID `json:"placed_by,omitempty"`
+
+	// Nguồn kênh lúc checkout (orders.metadata)
+	Metadata Metadata `json:"metadata,omitempty"`
+
+	// Đối tác giới thiệu: mã đã chuẩn hoá + đối tác đang hoạt động khớp mã lúc đặt (nil = không tính hoa hồng)
+	AffiliateCode      *string    `json:"affiliate_code,omitempty"`
+	AffiliatePartnerID *uuid.UUID `json:"affiliate_partner_id,omitempty"`
+
+	// Kênh bán (sales_channels.code) + mã đơn bên sàn (nil với đơn web / app)
+	Channel         string  `json:"channel"`
+	ExternalOrderID *string `json:"external_order_id,omitempty"`
 }
 
-// CanBeCancelled checks if order can be cancelled by user
+// Packaging lựa chọn đóng gói của đơn
+func (o *Order) Packaging() PackagingPreferences {
+	return PackagingPreferences{
+		MinimalPackaging: o.MinimalPackaging,
+		NoPrintedInvoice: o.NoPrintedInvoice,
+	}
+}
+
+// CanBeCancelled checks if order can be cancelled by system (payment timeout, fraud)
 // Business rule: Only pending/confirmed orders can be cancelled
+// Khách tự huỷ theo CancellationPolicy (cấu hình được), không dùng hàm này
 func (o *Order) CanBeCancelled() bool {
 	return o.Status == OrderStatusPending || o.Status == OrderStatusConfirmed
 }
 
+// CanBeModified checks if customer can still change shipping address / note
+// Business rule: trước processing, chưa mua nhãn vận chuyển và còn trong cửa sổ sau khi đặt
+func (o *Order) CanBeModified(window time.Duration, now time.Time) bool {
+	if window <= 0 || o.TrackingNumber != nil {
+		return false
+	}
+	if o.Status != OrderStatusPending && o.Status != OrderStatusConfirmed {
+		return false
+	}
+	return now.Before(o.ModifiableUntil(window))
+}
+
+// ModifiableUntil hạn cuối khách được tự sửa đơn
+func (o *Order) ModifiableUntil(window time.Duration) time.Time {
+	return o.CreatedAt.Add(window)
+}
+
 // RequiresOnlinePayment checks if order requires online payment
 func (o *Order) RequiresOnlinePayment() bool {
 	return o.PaymentMethod == PaymentMethodVNPay ||
 		o.PaymentMethod == PaymentMethodMomo ||
-		o.PaymentMethod == PaymentMethodBankTransfer
+		o.PaymentMethod == PaymentMethodBankTransfer ||
+		o.PaymentMethod == PaymentMethodPayLink
 }
 
 // IsCOD checks if order is cash on delivery
@@ -129,7 +184,17 @@ type OrderItem struct {
 	Price        decimal.Decimal `json:"price"`
 	Subtotal     decimal.Decimal `json:"subtotal"`
 	CreatedAt    time.Time       `json:"created_at"`
-	WarehouseID  *uuid.UUID      `json:"warehouse_id"`
+	WarehouseID  *uuid.UUID      `json:"warehouse_id"` // kho của kiện (join order_shipments), nil với đơn cũ
+	ShipmentID   *uuid.UUID      `json:"shipment_id,omitempty"`
+
+	// Giá theo số lượng: Price = ListPrice sau khi giảm TierDiscountPercent (nil = không áp bậc)
+	ListPrice           decimal.Decimal  `json:"list_price"`
+	TierDiscountPercent *decimal.Decimal `json:"tier_discount_percent,omitempty"`
+
+	// Quà tặng kèm đơn: Price = 0, ListPrice = giá bìa lúc đặt
+	GiftPromotionID *uuid.UUID `json:"gift_promotion_id,omitempty"`
+
+	Metadata Metadata `json:"metadata,omitempty"` // order_items.metadata
 }
 
 // CalculateSubtotal calculates item subtotal
@@ -137,6 +202,81 @@ func (oi *OrderItem) CalculateSubtotal() decimal.Decimal {
 	return oi.Price.Mul(decimal.NewFromInt(int64(oi.Quantity)))
 }
 
+// TierSavings số tiền tiết kiệm nhờ bậc giá theo số lượng
+func (oi *OrderItem) TierSavings() decimal.Decimal {
+	if oi.TierDiscountPercent == nil {
+		return decimal.Zero
+	}
+	return oi.ListPrice.Sub(oi.Price).Mul(decimal.NewFromInt(int64(oi.Quantity)))
+}
+
+// =====================================================
+// ENTITY: OrderGift
+// =====================================================
+// Đơn quà tặng: giao tới người nhận khác người mua.
+// Địa chỉ người nhận là snapshot, không nằm trong sổ địa chỉ của user.
+type OrderGift struct {
+	OrderID               uuid.UUID  `json:"order_id"`
+	RecipientName         string     `json:"recipient_name" pii:"name"`
+	RecipientPhone        string     `json:"recipient_phone" pii:"phone"`
+	Province              string     `json:"province"`
+	District              string     `json:"district" pii:"address"`
+	Ward                  string     `json:"ward" pii:"address"`
+	Street                string     `json:"street" pii:"address"`
+	GiftMessage           *string    `json:"gift_message,omitempty"`
+	HidePrices            bool       `json:"hide_prices"`
+	ScheduledDeliveryDate *time.Time `json:"scheduled_delivery_date,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// FullAddress địa chỉ người nhận dạng 1 dòng
+func (g *OrderGift) FullAddress() string {
+	return g.Street + ", " + g.Ward + ", " + g.District + ", " + g.Province
+}
+
+// Gift constants
+const (
+	GiftMessageMaxLength = 500
+	GiftMinLeadDays      = 1  // ngày hẹn giao sớm nhất: ngày mai
+	GiftMaxScheduleDays  = 60 // hẹn tối đa 60 ngày
+	GiftDateLayout       = "2006-01-02"
+)
+
+// =====================================================
+// ENTITY: OrderPaymentTerms
+// =====================================================
+// Công nợ của đơn B2B (payment_method = invoice), tạo khi convert quote.
+type OrderPaymentTerms struct {
+	OrderID     uuid.UUID `json:"order_id"`
+	QuoteID     uuid.UUID `json:"quote_id"`
+	CompanyName string    `json:"company_name"`
+	TaxCode     *string   `json:"tax_code,omitempty"`
+	TermsDays   int       `json:"terms_days"`
+	DueAt       time.Time `json:"due_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IsOverdue công nợ quá hạn (chưa thanh toán sau due_at)
+func (t *OrderPaymentTerms) IsOverdue(paymentStatus string, now time.Time) bool {
+	return paymentStatus != PaymentStatusPaid && now.After(t.DueAt)
+}
+
+// =====================================================
+// ENTITY: OrderTaxLine
+// =====================================================
+// Dòng thuế của đơn (order_tax_lines), snapshot rule lúc đặt → sửa rule sau không đổi hoá đơn cũ.
+// PriceIncludesTax = true: thuế đã nằm trong giá (chỉ hiển thị), false: cộng thêm vào tổng đơn
+type OrderTaxLine struct {
+	ID               uuid.UUID       `json:"-"`
+	OrderID          uuid.UUID       `json:"-"`
+	TaxRuleID        *uuid.UUID      `json:"tax_rule_id,omitempty"`
+	Name             string          `json:"name"`
+	Rate             decimal.Decimal `json:"rate"`
+	PriceIncludesTax bool            `json:"price_includes_tax"`
+	TaxableAmount    decimal.Decimal `json:"taxable_amount"`
+	TaxAmount        decimal.Decimal `json:"tax_amount"`
+}
+
 // =====================================================
 // ENTITY: OrderStatusHistory
 // =====================================================
@@ -150,6 +290,117 @@ type OrderStatusHistory struct {
 	ChangedAt  time.Time  `json:"changed_at"`
 }
 
+// =====================================================
+// ENTITY: OrderCommunication
+// =====================================================
+// Liên lạc outbound với khách về 1 đơn (email, SMS, cuộc gọi xác minh).
+// Source = "notification": đọc từ notification_delivery_logs (reference_type = 'order'),
+// khi đó Status theo delivery log (queued, sent, delivered, failed, ...).
+// Email có ProviderMessageID được cập nhật delivered/bounced/complained qua webhook của provider.
+type OrderCommunication struct {
+	ID           uuid.UUID  `json:"id"`
+	OrderID      uuid.UUID  `json:"order_id"`
+	Channel      string     `json:"channel"`
+	Purpose      string     `json:"purpose"`
+	Status       string     `json:"status"`
+	Recipient    *string    `json:"recipient,omitempty"`
+	Subject      *string    `json:"subject,omitempty"`
+	Note         *string    `json:"note,omitempty"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"` // nil = hệ thống gửi tự động
+	Source       string     `json:"source"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	ProviderMessageID *string `json:"provider_message_id,omitempty"` // Message-ID header của email
+}
+
+// HasDeliveryProblem - email bị bounce / đánh dấu spam hoặc gửi lỗi → support cần liên hệ kênh khác
+func (c OrderCommunication) HasDeliveryProblem() bool {
+	switch c.Status {
+	case CommunicationStatusFailed, CommunicationStatusBounced, CommunicationStatusComplained:
+		return true
+	}
+	return false
+}
+
+// =====================================================
+// ENTITY: OrderModification
+// =====================================================
+// Audit 1 lần khách tự sửa đơn trong cửa sổ cho phép (địa chỉ giao / ghi chú).
+// Old/NewWarehouseID khác nhau khi đổi tỉnh làm đổi kho fulfill (reserve đã chuyển kho).
+type OrderModification struct {
+	ID              uuid.UUID  `json:"id"`
+	OrderID         uuid.UUID  `json:"order_id"`
+	OldAddressID    *uuid.UUID `json:"old_address_id,omitempty"`
+	NewAddressID    *uuid.UUID `json:"new_address_id,omitempty"`
+	OldCustomerNote *string    `json:"old_customer_note,omitempty"`
+	NewCustomerNote *string    `json:"new_customer_note,omitempty"`
+	OldWarehouseID  *uuid.UUID `json:"old_warehouse_id,omitempty"`
+	NewWarehouseID  *uuid.UUID `json:"new_warehouse_id,omitempty"`
+	ChangedBy       *uuid.UUID `json:"changed_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// =====================================================
+// ENTITY: OrderReplacement
+// =====================================================
+// Liên kết đơn gửi bù (0đ) với đơn gốc + lý do, dùng cho báo cáo hàng hỏng / thất lạc.
+type OrderReplacement struct {
+	OrderID             uuid.UUID `json:"order_id"` // đơn gửi bù
+	OrderNumber         string    `json:"order_number"`
+	OrderStatus         string    `json:"order_status"`
+	OriginalOrderID     uuid.UUID `json:"original_order_id"`
+	OriginalOrderNumber string    `json:"original_order_number"`
+	Reason              string    `json:"reason"`
+	Note                *string   `json:"note,omitempty"`
+	CreatedBy           uuid.UUID `json:"created_by"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// Replacement reasons
+const (
+	ReplacementReasonDamagedInTransit = "damaged_in_transit" // hỏng khi vận chuyển (móp, ướt, rách)
+	ReplacementReasonLostInTransit    = "lost_in_transit"    // thất lạc, hãng vận chuyển xác nhận mất
+	ReplacementReasonMissingItem      = "missing_item"       // kiện thiếu sách
+	ReplacementReasonWrongItem        = "wrong_item"         // giao nhầm sách
+	ReplacementReasonDefective        = "defective"          // lỗi in ấn / đóng sách
+	ReplacementReasonOther            = "other"
+)
+
+var ReplacementReasons = []string{
+	ReplacementReasonDamagedInTransit,
+	ReplacementReasonLostInTransit,
+	ReplacementReasonMissingItem,
+	ReplacementReasonWrongItem,
+	ReplacementReasonDefective,
+	ReplacementReasonOther,
+}
+
+// Communication constants
+const (
+	CommunicationChannelEmail = "email"
+	CommunicationChannelSMS   = "sms"
+	CommunicationChannelPush  = "push"
+	CommunicationChannelCall  = "call"
+
+	CommunicationStatusSent     = "sent"
+	CommunicationStatusFailed   = "failed"
+	CommunicationStatusAnswered = "answered"
+	CommunicationStatusNoAnswer = "no_answer"
+
+	// Cập nhật từ webhook của email provider
+	CommunicationStatusDelivered  = "delivered"
+	CommunicationStatusBounced    = "bounced"
+	CommunicationStatusComplained = "complained"
+
+	CommunicationPurposeOrderConfirmation = "order_confirmation"
+	CommunicationPurposeVerificationCall  = "verification_call"
+	CommunicationPurposePaymentLink       = "payment_link"
+
+	CommunicationSourceOrder        = "order"
+	CommunicationSourceNotification = "notification"
+)
+
 // =====================================================
 // WAREHOUSE PROVINCE MAPPING
 // =====================================================
@@ -172,6 +423,17 @@ type CreateOrderFromItemsRequest struct {
 	PromoCode     *string           `json:"promo_code,omitempty"`    // optional, thường nil cho Reorder
 	CustomerNote  *string           `json:"customer_note,omitempty"` // optional
 	Items         []CreateOrderItem `json:"items"`                   // BẮT BUỘC có sẵn
+
+	// PriceOverrides giá đặc biệt theo book (flash sale), không nhận từ client
+	PriceOverrides map[uuid.UUID]decimal.Decimal `json:"-"`
+
+	// Kênh + mã đơn ngoài (import CSV đơn offline), rỗng = web
+	Channel         string  `json:"-"`
+	ExternalOrderID *string `json:"-"`
+	// Paid đơn đã thu tiền ngoài hệ thống → confirmed, không chạy auto-release
+	Paid bool `json:"-"`
+
+	Metadata Metadata `json:"metadata,omitempty"`
 }
 
 // Validate đảm bảo address, payment method, items hợp lệ
@@ -185,5 +447,82 @@ func (req CreateOrderFromItemsRequest) Validate() error {
 			PaymentMethodBankTransfer,
 		)),
 		validation.Field(&req.Items, validation.Required, validation.Length(1, 100)),
+		validation.Field(&req.Metadata),
 	)
 }
+
+// CreateQuoteOrderRequest - use case: convert quote B2B đã duyệt thành order.
+// Không giới hạn 100 item/quantity như cart, giá lấy theo quote.
+type CreateQuoteOrderRequest struct {
+	QuoteID      uuid.UUID
+	AddressID    uuid.UUID
+	CompanyName  string
+	TaxCode      *string
+	TermsDays    int
+	CustomerNote *string
+	Items        []QuoteOrderItem
+}
+
+// QuoteOrderItem item với đơn giá đã duyệt
+type QuoteOrderItem struct {
+	BookID    uuid.UUID
+	Quantity  int
+	UnitPrice decimal.Decimal
+}
+
+// Validate đảm bảo quote, address, items hợp lệ
+func (req CreateQuoteOrderRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.QuoteID, validation.Required),
+		validation.Field(&req.AddressID, validation.Required),
+		validation.Field(&req.CompanyName, validation.Required),
+		validation.Field(&req.TermsDays, validation.Min(0), validation.Max(90)),
+		validation.Field(&req.Items, validation.Required),
+	)
+}
+
+// CreateReplacementOrderRequest - use case: gửi bù sách thiếu / hỏng / thất lạc cho đơn gốc (0đ, giao tới địa chỉ đơn gốc)
+// Items rỗng = gửi lại toàn bộ sách chưa được gửi bù của đơn gốc
+type CreateReplacementOrderRequest struct {
+	SourceOrderID uuid.UUID
+	Items         []CreateOrderItem
+	StaffID       uuid.UUID
+	Reason        string
+	Note          string
+}
+
+// Validate đảm bảo có đơn gốc, lý do và nhân viên thực hiện
+func (req CreateReplacementOrderRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.SourceOrderID, validation.Required),
+		validation.Field(&req.StaffID, validation.Required),
+		validation.Field(&req.Reason, validation.Required, validation.In(
+			ReplacementReasonDamagedInTransit,
+			ReplacementReasonLostInTransit,
+			ReplacementReasonMissingItem,
+			ReplacementReasonWrongItem,
+			ReplacementReasonDefective,
+			ReplacementReasonOther,
+		)),
+		validation.Field(&req.Items, validation.Length(0, 100)),
+	)
+}
+
+// CreateFlashSaleOrderRequest - use case: mua flash sale bằng purchase token
+type CreateFlashSaleOrderRequest struct {
+	FlashSaleName string
+	BookID        uuid.UUID
+	Quantity      int
+	UnitPrice     decimal.Decimal
+	AddressID     uuid.UUID
+	PaymentMethod string
+}
+
+// UnpaidOrderRef - đơn chưa thanh toán còn giữ hàng (đối soát auto-release khi worker khởi động)
+type UnpaidOrderRef struct {
+	ID            uuid.UUID
+	OrderNumber   string
+	UserID        uuid.UUID
+	PaymentMethod string
+	CreatedAt     time.Time
+}
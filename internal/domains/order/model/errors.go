@@ -23,6 +23,16 @@ const (
 	ErrCodeInvalidStatus          = "ORD015"
 	ErrCodePromoMinAmount         = "ORD016"
 	ErrCodeInvalidOrder           = "ORD017"
+	ErrCodeInvalidGift            = "ORD018"
+	ErrCodeOrderTimeout           = "ORD019"
+	ErrCodeInvalidWorkflow        = "ORD020"
+	ErrCodeModificationClosed     = "ORD021"
+	ErrCodeInvalidCancelRules     = "ORD022"
+	ErrCodeInvalidChannel         = "ORD023"
+	ErrCodeChannelNotFound        = "ORD024"
+	ErrCodeUnknownSKU             = "ORD025"
+	ErrCodeInvalidImport          = "ORD026"
+	ErrCodeImportNotFound         = "ORD027"
 )
 
 // =====================================================
@@ -45,6 +55,19 @@ var (
 	ErrUnauthorized           = errors.New("unauthorized access")
 	ErrInvalidStatus          = errors.New("invalid order status")
 	ErrPromoMinAmount         = errors.New("order amount below promotion minimum")
+	ErrInvalidGift            = errors.New("invalid gift options")
+	ErrOrderTimeout           = errors.New("order creation deadline exceeded")
+	ErrInvalidWorkflow        = errors.New("invalid order status workflow")
+	ErrModificationClosed     = errors.New("order modification window closed")
+	ErrInvalidCancelRules     = errors.New("invalid order cancellation rules")
+	ErrInvalidChannel         = errors.New("sales channel not allowed")
+	ErrChannelNotFound        = errors.New("sales channel not found")
+	ErrUnknownSKU             = errors.New("unknown marketplace sku")
+	ErrExternalOrderExists    = errors.New("marketplace order already ingested")
+	ErrMarketplaceRateLimited = errors.New("marketplace sync rate limit reached")
+	ErrInvalidImportFile      = errors.New("invalid order import file")
+	ErrImportNotFound         = errors.New("order import not found")
+	ErrStatusImportNotFound   = errors.New("order status import not found")
 )
 
 // =====================================================
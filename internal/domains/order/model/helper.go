@@ -3,6 +3,7 @@ package model
 import (
 	addressModel "bookstore-backend/internal/domains/address/model"
 	"fmt"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -12,18 +13,25 @@ import (
 // =====================================================
 
 // CalculateOrderAmounts calculates all order amounts
-// Returns: subtotal, discount, shipping, cod_fee, tax, total
+// Returns: subtotal, discount, shipping, shipping_discount, cod_fee, tax, total
+//
+// shipping là phí ship gốc, shipping_discount là dòng giảm riêng trên phí ship
+// (mức lớn hơn giữa mã giảm ship và bậc tự động của policy)
 func CalculateOrderAmounts(
 	itemsSubtotal decimal.Decimal,
 	discountAmount decimal.Decimal, // ✅ Đơn giản: chỉ nhận discount đã tính sẵn
+	promoShippingDiscount decimal.Decimal, // Giảm ship từ mã (0 nếu mã giảm tiền hàng / không có mã)
 	isCOD bool,
-) (subtotal, discount, shipping, codFee, tax, total decimal.Decimal) {
+	policy CheckoutPolicy,
+	taxLines []OrderTaxLine, // nil = không chịu thuế
+) (subtotal, discount, shipping, shippingDiscount, codFee, tax, total decimal.Decimal) {
 
 	subtotal = itemsSubtotal
 	discount = discountAmount
 
-	// Shipping fee (15,000 VND)
-	shipping = decimal.NewFromInt(ShippingFee)
+	// Shipping fee (miễn phí khi tiền hàng đạt ngưỡng freeship)
+	shipping = policy.ShippingFeeFor(itemsSubtotal)
+	shippingDiscount = policy.ShippingDiscountFor(itemsSubtotal, shipping, promoShippingDiscount)
 
 	// COD fee (15,000 VND if COD)
 	if isCOD {
@@ -32,18 +40,26 @@ func CalculateOrderAmounts(
 		codFee = decimal.Zero
 	}
 
-	// Tax (0%)
+	// Tax: tổng mọi dòng thuế; chỉ thuế giá chưa gồm (exclusive) cộng vào tổng,
+	// thuế giá đã gồm (inclusive) nằm sẵn trong tiền hàng
 	tax = decimal.Zero
+	addedTax := decimal.Zero
+	for _, line := range taxLines {
+		tax = tax.Add(line.TaxAmount)
+		if !line.PriceIncludesTax {
+			addedTax = addedTax.Add(line.TaxAmount)
+		}
+	}
 
-	// Total = subtotal - discount + shipping + cod_fee + tax
-	total = subtotal.Sub(discount).Add(shipping).Add(codFee).Add(tax)
+	// Total = subtotal - discount + shipping - shipping_discount + cod_fee + exclusive tax
+	total = subtotal.Sub(discount).Add(shipping).Sub(shippingDiscount).Add(codFee).Add(addedTax)
 
 	// Ensure non-negative
 	if total.LessThan(decimal.Zero) {
 		total = decimal.Zero
 	}
 
-	return subtotal, discount, shipping, codFee, tax, total
+	return subtotal, discount, shipping, shippingDiscount, codFee, tax, total
 }
 
 // GetWarehouseCodeByProvince returns warehouse code based on province
@@ -70,6 +86,15 @@ func BuildOrderDetailResponse(
 			Quantity:     item.Quantity,
 			Price:        item.Price,
 			Subtotal:     item.Subtotal,
+
+			ListPrice:           item.ListPrice,
+			TierDiscountPercent: item.TierDiscountPercent,
+			Savings:             item.TierSavings(),
+
+			IsFreeGift:      item.GiftPromotionID != nil,
+			GiftPromotionID: item.GiftPromotionID,
+
+			Metadata: item.Metadata,
 		}
 	}
 	var addressResponse *OrderAddressResponse
@@ -91,6 +116,7 @@ func BuildOrderDetailResponse(
 		PaymentStatus:       order.PaymentStatus,
 		Subtotal:            order.Subtotal,
 		ShippingFee:         order.ShippingFee,
+		ShippingDiscount:    order.ShippingDiscount,
 		CODFee:              order.CODFee,
 		DiscountAmount:      order.DiscountAmount,
 		TaxAmount:           order.TaxAmount,
@@ -108,5 +134,178 @@ func BuildOrderDetailResponse(
 		UpdatedAt:           order.UpdatedAt,
 		CancelledAt:         order.CancelledAt,
 		Version:             order.Version,
+		Packaging:           order.Packaging(),
+		Metadata:            order.Metadata,
+	}
+}
+
+// =====================================================
+// GIFT / SHIPMENT / INVOICE BUILDERS
+// =====================================================
+
+// BuildOrderGiftResponse - nil nếu không phải đơn quà
+func BuildOrderGiftResponse(gift *OrderGift) *OrderGiftResponse {
+	if gift == nil {
+		return nil
+	}
+
+	return &OrderGiftResponse{
+		RecipientName:         gift.RecipientName,
+		RecipientPhone:        gift.RecipientPhone,
+		FullAddress:           gift.FullAddress(),
+		GiftMessage:           gift.GiftMessage,
+		HidePrices:            gift.HidePrices,
+		ScheduledDeliveryDate: formatGiftDate(gift.ScheduledDeliveryDate),
+	}
+}
+
+// BuildShipTo - người nhận quà nếu có, ngược lại là địa chỉ của người mua
+func BuildShipTo(gift *OrderGift, address *addressModel.Address) ShipToResponse {
+	if gift != nil {
+		return ShipToResponse{
+			Name:        gift.RecipientName,
+			Phone:       gift.RecipientPhone,
+			FullAddress: gift.FullAddress(),
+		}
+	}
+	return buildAddressParty(address)
+}
+
+// BuildPackingSlip - phiếu đóng gói đi kèm hàng, gift receipt thì không in giá
+func BuildPackingSlip(order *Order, items []OrderItem, gift *OrderGift, address *addressModel.Address) *PackingSlipResponse {
+	hidePrices := gift != nil && gift.HidePrices
+
+	slip := &PackingSlipResponse{
+		OrderNumber:   order.OrderNumber,
+		WarehouseID:   order.WarehouseID,
+		ShipTo:        BuildShipTo(gift, address),
+		Items:         make([]PackingSlipItemResponse, len(items)),
+		IsGift:        gift != nil,
+		IsGiftReceipt: hidePrices,
+		CustomerNote:  order.CustomerNote,
+		CreatedAt:     order.CreatedAt,
+		Packaging:     order.Packaging(),
+	}
+
+	if gift != nil {
+		slip.GiftMessage = gift.GiftMessage
+		slip.ScheduledDeliveryDate = formatGiftDate(gift.ScheduledDeliveryDate)
+	}
+
+	for i, item := range items {
+		slip.Items[i] = PackingSlipItemResponse{
+			BookTitle:  item.BookTitle,
+			AuthorName: item.AuthorName,
+			Quantity:   item.Quantity,
+		}
+		if !hidePrices {
+			price, subtotal := item.Price, item.Subtotal
+			slip.Items[i].Price = &price
+			slip.Items[i].Subtotal = &subtotal
+		}
+	}
+
+	if !hidePrices {
+		subtotal, total := order.Subtotal, order.Total
+		slip.Subtotal = &subtotal
+		slip.Total = &total
+	}
+
+	// Shipper vẫn cần biết số tiền thu hộ, kể cả gift receipt (ghi trên vận đơn, không in lên phiếu trong hộp)
+	if order.IsCOD() && !order.IsPaymentCompleted() {
+		codAmount := order.Total
+		slip.CODAmount = &codAmount
+	}
+
+	return slip
+}
+
+// BuildInvoice - hoá đơn cho người mua (luôn có giá)
+func BuildInvoice(order *Order, items []OrderItem, gift *OrderGift, address *addressModel.Address) *InvoiceResponse {
+	issuedAt := order.CreatedAt
+	if order.PaidAt != nil {
+		issuedAt = *order.PaidAt
+	}
+
+	itemsResponse := make([]OrderItemResponse, len(items))
+	for i, item := range items {
+		itemsResponse[i] = OrderItemResponse{
+			ID:           item.ID,
+			BookID:       item.BookID,
+			BookTitle:    item.BookTitle,
+			BookSlug:     item.BookSlug,
+			BookCoverURL: item.BookCoverURL,
+			AuthorName:   item.AuthorName,
+			Quantity:     item.Quantity,
+			Price:        item.Price,
+			Subtotal:     item.Subtotal,
+
+			ListPrice:           item.ListPrice,
+			TierDiscountPercent: item.TierDiscountPercent,
+			Savings:             item.TierSavings(),
+
+			IsFreeGift:      item.GiftPromotionID != nil,
+			GiftPromotionID: item.GiftPromotionID,
+		}
+	}
+
+	invoice := &InvoiceResponse{
+		InvoiceNumber:    "INV-" + order.OrderNumber,
+		OrderNumber:      order.OrderNumber,
+		IssuedAt:         issuedAt,
+		BillTo:           buildAddressParty(address),
+		ShipTo:           BuildShipTo(gift, address),
+		IsGift:           gift != nil,
+		Items:            itemsResponse,
+		Subtotal:         order.Subtotal,
+		ShippingFee:      order.ShippingFee,
+		ShippingDiscount: order.ShippingDiscount,
+		CODFee:           order.CODFee,
+		DiscountAmount:   order.DiscountAmount,
+		TaxAmount:        order.TaxAmount,
+		Total:            order.Total,
+		PaymentMethod:    order.PaymentMethod,
+		PaymentStatus:    order.PaymentStatus,
+		PaidAt:           order.PaidAt,
+	}
+	if gift != nil {
+		invoice.ScheduledDeliveryDate = formatGiftDate(gift.ScheduledDeliveryDate)
+	}
+
+	return invoice
+}
+
+func buildAddressParty(address *addressModel.Address) ShipToResponse {
+	if address == nil {
+		return ShipToResponse{}
+	}
+	return ShipToResponse{
+		Name:        address.RecipientName,
+		Phone:       address.Phone,
+		FullAddress: fmt.Sprintf("%s, %s, %s, %s", address.Street, address.Ward, address.District, address.Province),
+	}
+}
+
+func formatGiftDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format(GiftDateLayout)
+	return &formatted
+}
+
+// ApplyPaymentTerms gắn công nợ B2B vào invoice: bill-to là doanh nghiệp
+func ApplyPaymentTerms(invoice *InvoiceResponse, terms *OrderPaymentTerms, paymentStatus string, now time.Time) {
+	if terms == nil {
+		return
+	}
+	invoice.BillTo.Name = terms.CompanyName
+	invoice.PaymentTerms = &PaymentTermsResponse{
+		QuoteID:     terms.QuoteID,
+		CompanyName: terms.CompanyName,
+		TaxCode:     terms.TaxCode,
+		TermsDays:   terms.TermsDays,
+		DueAt:       terms.DueAt,
+		IsOverdue:   terms.IsOverdue(paymentStatus, now),
 	}
 }
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// MARKETPLACE STOCK SYNC (đẩy tồn + giá lên sàn)
+// =====================================================
+
+// Trạng thái lần đẩy gần nhất (marketplace_listing_syncs.status)
+const (
+	ListingSyncStatusSynced    = "synced"
+	ListingSyncStatusFailed    = "failed"
+	ListingSyncStatusNotListed = "not_listed" // SKU chưa đăng bán trên sàn
+)
+
+// MarketplaceListing - trạng thái hiện tại của 1 sách (SKU = ISBN) so với lần đẩy trước lên 1 kênh
+type MarketplaceListing struct {
+	BookID    uuid.UUID
+	SKU       string
+	Available int
+	Price     decimal.Decimal
+
+	// nil = chưa từng đẩy lên kênh
+	SyncedQuantity *int
+	SyncedPrice    *decimal.Decimal
+	Status         *string
+}
+
+// PushQuantity tồn đẩy lên sàn: giữ lại buffer để chống oversell, không âm
+func (l *MarketplaceListing) PushQuantity(buffer int) int {
+	quantity := l.Available - buffer
+	if quantity < 0 {
+		return 0
+	}
+	return quantity
+}
+
+// NeedsPush tồn / giá khác lần đẩy thành công trước (job theo sự kiện)
+// SKU chưa đăng bán bỏ qua, reconcile đêm mới thử lại
+func (l *MarketplaceListing) NeedsPush(buffer int) bool {
+	if l.Status == nil {
+		return true
+	}
+	switch *l.Status {
+	case ListingSyncStatusNotListed:
+		return false
+	case ListingSyncStatusFailed:
+		return true
+	}
+	return l.SyncedQuantity == nil || *l.SyncedQuantity != l.PushQuantity(buffer) ||
+		l.SyncedPrice == nil || !l.SyncedPrice.Equal(l.Price)
+}
+
+// MarketplaceListingSync - kết quả 1 lần đẩy, ghi vào marketplace_listing_syncs
+type MarketplaceListingSync struct {
+	Channel   string
+	BookID    uuid.UUID
+	SKU       string
+	Quantity  int
+	Price     decimal.Decimal
+	Status    string
+	LastError *string
+	At        time.Time
+}
+
+// MarketplaceSyncResult - thống kê 1 lượt đồng bộ của 1 kênh
+type MarketplaceSyncResult struct {
+	Channel   string `json:"channel"`
+	Pushed    int    `json:"pushed"`
+	Skipped   int    `json:"skipped"` // không đổi so với lần đẩy trước
+	NotListed int    `json:"not_listed"`
+	Failed    int    `json:"failed"`
+}
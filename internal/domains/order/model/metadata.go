@@ -0,0 +1,81 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// =====================================================
+// ORDER METADATA (JSONB attributes)
+// =====================================================
+// Thuộc tính mở rộng của đơn / dòng hàng (orders.metadata, order_items.metadata):
+// nguồn kênh (utm_*), affiliate, phiên bản app... Client gửi lúc checkout, admin lọc được,
+// chuyển nguyên sang analytics event checkout_completed.
+// Chỉ nhận string → string để index GIN (@>) và analytics đọc thống nhất.
+
+const (
+	MetadataUTMSource   = "utm_source"
+	MetadataUTMMedium   = "utm_medium"
+	MetadataUTMCampaign = "utm_campaign"
+	MetadataUTMContent  = "utm_content"
+	MetadataUTMTerm     = "utm_term"
+	MetadataAffiliateID = "affiliate_id"
+	MetadataAppVersion  = "app_version"
+	MetadataPlatform    = "platform"
+	// Dòng hàng tính giá đặc biệt do server cấp (không nhận từ client)
+	MetadataPriceSource = "price_source"
+
+	PriceSourceWishlistGuarantee = "wishlist_guarantee"
+
+	MaxMetadataKeys        = 20
+	MaxMetadataValueLength = 255
+)
+
+var metadataKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// Metadata thuộc tính key → value của đơn / dòng hàng
+type Metadata map[string]string
+
+// Validate key snake_case (<= 40 ký tự), value không rỗng (<= 255 ký tự), tối đa 20 key
+func (m Metadata) Validate() error {
+	if len(m) > MaxMetadataKeys {
+		return fmt.Errorf("metadata must have at most %d keys", MaxMetadataKeys)
+	}
+	for key, value := range m {
+		if !metadataKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid metadata key %q: must be snake_case, max 40 characters", key)
+		}
+		if value == "" {
+			return fmt.Errorf("metadata %q must not be empty", key)
+		}
+		if len([]rune(value)) > MaxMetadataValueLength {
+			return fmt.Errorf("metadata %q must be at most %d characters", key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// OrEmpty map rỗng thay cho nil (cột NOT NULL DEFAULT '{}')
+func (m Metadata) OrEmpty() Metadata {
+	if m == nil {
+		return Metadata{}
+	}
+	return m
+}
+
+// validateItemMetadata metadata theo book_id cho dòng hàng lấy từ cart
+func validateItemMetadata(value interface{}) error {
+	items, _ := value.(map[uuid.UUID]Metadata)
+	for bookID, metadata := range items {
+		if bookID == uuid.Nil {
+			return errors.New("item_metadata key must be a book_id")
+		}
+		if err := metadata.Validate(); err != nil {
+			return fmt.Errorf("item_metadata[%s]: %w", bookID, err)
+		}
+	}
+	return nil
+}
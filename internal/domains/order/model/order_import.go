@@ -0,0 +1,93 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// ORDER IMPORT (CSV đơn offline / điện thoại / lịch sử)
+// =====================================================
+// Mỗi dòng CSV = 1 sách của 1 đơn; các dòng cùng order_ref gộp thành 1 đơn.
+// Cột: order_ref, customer_email, isbn, quantity, payment_method (bắt buộc)
+//      unit_price, paid, address_id, note (tuỳ chọn)
+// - unit_price trống → giá bán hiện tại (theo bậc số lượng)
+// - address_id trống → địa chỉ mặc định của khách
+// - paid=true → đơn đã thu tiền (không chạy timeout thanh toán)
+// Đơn tạo ở kênh 'offline', external_order_id = order_ref → nhập lại cùng mã không tạo đơn trùng.
+
+const (
+	OrderImportStatusPending    = "pending"
+	OrderImportStatusProcessing = "processing"
+	OrderImportStatusCompleted  = "completed"
+	OrderImportStatusFailed     = "failed"
+
+	OrderImportRowPending = "pending"
+	OrderImportRowCreated = "created"
+	OrderImportRowFailed  = "failed"
+
+	// Giới hạn 1 file (job xử lý tuần tự từng đơn)
+	OrderImportMaxRows = 2000
+	// order_ref lưu ở external_order_id
+	OrderImportMaxRefLength = 64
+)
+
+// OrderImportColumns header bắt buộc của file
+var OrderImportColumns = []string{"order_ref", "customer_email", "isbn", "quantity", "payment_method"}
+
+// OrderImport 1 lần upload file
+type OrderImport struct {
+	ID            uuid.UUID  `json:"id"`
+	FileName      string     `json:"file_name"`
+	Status        string     `json:"status"`
+	TotalRows     int        `json:"total_rows"`
+	TotalOrders   int        `json:"total_orders"`
+	CreatedOrders int        `json:"created_orders"`
+	FailedRows    int        `json:"failed_rows"`
+	ErrorMessage  *string    `json:"error_message,omitempty"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// OrderImportRow 1 dòng CSV đã parse + kết quả xử lý
+type OrderImportRow struct {
+	ID            uuid.UUID        `json:"id"`
+	ImportID      uuid.UUID        `json:"import_id"`
+	LineNo        int              `json:"line_no"`
+	OrderRef      string           `json:"order_ref"`
+	CustomerEmail string           `json:"customer_email"`
+	ISBN          string           `json:"isbn"`
+	Quantity      int              `json:"quantity"`
+	UnitPrice     *decimal.Decimal `json:"unit_price,omitempty"`
+	PaymentMethod string           `json:"payment_method"`
+	Paid          bool             `json:"paid"`
+	AddressID     *uuid.UUID       `json:"address_id,omitempty"`
+	Note          *string          `json:"note,omitempty"`
+	Status        string           `json:"status"`
+	OrderID       *uuid.UUID       `json:"order_id,omitempty"`
+	Error         *string          `json:"error,omitempty"`
+	ProcessedAt   *time.Time       `json:"processed_at,omitempty"`
+}
+
+// OrderImportRowResult kết quả ghi lại cho các dòng của 1 đơn
+type OrderImportRowResult struct {
+	RowID   uuid.UUID
+	Status  string
+	OrderID *uuid.UUID
+	Error   *string
+}
+
+// OrderImportResponse - GET /admin/orders/imports/:id
+type OrderImportResponse struct {
+	OrderImport
+	Rows []OrderImportRow `json:"rows"`
+}
+
+// ProcessOrderImportPayload payload task order:import_orders
+type ProcessOrderImportPayload struct {
+	ImportID uuid.UUID `json:"import_id"`
+}
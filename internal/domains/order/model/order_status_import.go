@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =====================================================
+// ORDER STATUS IMPORT (cập nhật trạng thái hàng loạt từ CSV)
+// =====================================================
+// Mỗi dòng CSV = 1 đơn. Cột: order_number, status (bắt buộc), tracking_number (tuỳ chọn)
+// - Chuyển trạng thái qua workflow đang cấu hình (validateStatusTransition), giống PATCH /admin/orders/:id/status
+// - Đơn đã ở trạng thái đích → dòng updated, không ghi lịch sử lần nữa (upload lại cùng file an toàn)
+
+const (
+	OrderStatusImportPending    = "pending"
+	OrderStatusImportProcessing = "processing"
+	OrderStatusImportCompleted  = "completed"
+	OrderStatusImportFailed     = "failed"
+
+	OrderStatusImportRowPending = "pending"
+	OrderStatusImportRowUpdated = "updated"
+	OrderStatusImportRowFailed  = "failed"
+
+	// Giới hạn 1 file (job chuyển trạng thái tuần tự từng đơn)
+	OrderStatusImportMaxRows = 2000
+	// Độ dài tối đa mã vận đơn trong file
+	OrderStatusImportMaxTrackingLength = 100
+)
+
+// OrderStatusImportColumns header bắt buộc của file
+var OrderStatusImportColumns = []string{"order_number", "status"}
+
+// OrderStatusImport 1 lần upload file
+type OrderStatusImport struct {
+	ID           uuid.UUID  `json:"id"`
+	FileName     string     `json:"file_name"`
+	Status       string     `json:"status"`
+	TotalRows    int        `json:"total_rows"`
+	UpdatedRows  int        `json:"updated_rows"`
+	FailedRows   int        `json:"failed_rows"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// OrderStatusImportRow 1 dòng CSV đã parse + kết quả xử lý
+type OrderStatusImportRow struct {
+	ID             uuid.UUID  `json:"id"`
+	ImportID       uuid.UUID  `json:"import_id"`
+	LineNo         int        `json:"line_no"`
+	OrderNumber    string     `json:"order_number"`
+	TargetStatus   string     `json:"target_status"`
+	TrackingNumber *string    `json:"tracking_number,omitempty"`
+	Status         string     `json:"status"`
+	OrderID        *uuid.UUID `json:"order_id,omitempty"`
+	FromStatus     *string    `json:"from_status,omitempty"`
+	Error          *string    `json:"error,omitempty"`
+	ProcessedAt    *time.Time `json:"processed_at,omitempty"`
+}
+
+// OrderStatusImportRowResult kết quả ghi lại cho 1 dòng
+type OrderStatusImportRowResult struct {
+	RowID      uuid.UUID
+	Status     string
+	OrderID    *uuid.UUID
+	FromStatus *string
+	Error      *string
+}
+
+// OrderStatusImportResponse - GET /admin/orders/bulk-status/:id
+type OrderStatusImportResponse struct {
+	OrderStatusImport
+	Rows []OrderStatusImportRow `json:"rows"`
+}
+
+// ProcessOrderStatusImportPayload payload task order:bulk_update_status
+type ProcessOrderStatusImportPayload struct {
+	ImportID uuid.UUID `json:"import_id"`
+}
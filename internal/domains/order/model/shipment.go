@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =====================================================
+// ORDER SHIPMENTS (tách đơn nhiều kho)
+// =====================================================
+// Mỗi kho fulfill 1 phần đơn là 1 kiện; đơn đủ hàng ở 1 kho chỉ có 1 kiện.
+// Đơn cũ (trước khi có order_shipments) không có kiện nào → mọi dòng đi orders.warehouse_id
+
+const ShipmentStatusPending = "pending"
+
+type OrderShipment struct {
+	ID             uuid.UUID `json:"id"`
+	OrderID        uuid.UUID `json:"order_id"`
+	WarehouseID    uuid.UUID `json:"warehouse_id"`
+	ShipmentNumber int       `json:"shipment_number"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// Join từ warehouses (chỉ đọc)
+	WarehouseName string `json:"warehouse_name,omitempty"`
+	WarehouseCode string `json:"warehouse_code,omitempty"`
+}
+
+// OrderShipmentResponse - 1 kiện trong order detail kèm các dòng hàng của kiện
+type OrderShipmentResponse struct {
+	ID             uuid.UUID   `json:"id"`
+	ShipmentNumber int         `json:"shipment_number"`
+	WarehouseID    uuid.UUID   `json:"warehouse_id"`
+	WarehouseName  string      `json:"warehouse_name"`
+	WarehouseCode  string      `json:"warehouse_code"`
+	Status         string      `json:"status"`
+	ItemIDs        []uuid.UUID `json:"item_ids"`
+	TotalQuantity  int         `json:"total_quantity"`
+}
+
+// BuildShipmentResponses gom dòng hàng theo kiện (dòng chưa gán kiện bị bỏ qua)
+func BuildShipmentResponses(shipments []OrderShipment, items []OrderItem) []OrderShipmentResponse {
+	responses := make([]OrderShipmentResponse, len(shipments))
+	indexByID := make(map[uuid.UUID]int, len(shipments))
+	for i, shipment := range shipments {
+		responses[i] = OrderShipmentResponse{
+			ID:             shipment.ID,
+			ShipmentNumber: shipment.ShipmentNumber,
+			WarehouseID:    shipment.WarehouseID,
+			WarehouseName:  shipment.WarehouseName,
+			WarehouseCode:  shipment.WarehouseCode,
+			Status:         shipment.Status,
+			ItemIDs:        []uuid.UUID{},
+		}
+		indexByID[shipment.ID] = i
+	}
+
+	for _, item := range items {
+		if item.ShipmentID == nil {
+			continue
+		}
+		if idx, ok := indexByID[*item.ShipmentID]; ok {
+			responses[idx].ItemIDs = append(responses[idx].ItemIDs, item.ID)
+			responses[idx].TotalQuantity += item.Quantity
+		}
+	}
+	return responses
+}
+
+// ReservedWarehouseID kho đang giữ tồn của dòng hàng (kho của kiện, đơn cũ thì kho của đơn)
+func (oi *OrderItem) ReservedWarehouseID(orderWarehouseID *uuid.UUID) *uuid.UUID {
+	if oi.WarehouseID != nil {
+		return oi.WarehouseID
+	}
+	return orderWarehouseID
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TrackOrderRequest - POST /orders/track (public, không cần đăng nhập)
+// Khách vãng lai / COD tra cứu bằng mã đơn + 4 số cuối SĐT người nhận
+type TrackOrderRequest struct {
+	OrderNumber string `json:"order_number" binding:"required,max=50"`
+	PhoneLast4  string `json:"phone_last4" binding:"required,len=4,numeric"`
+}
+
+// PublicTrackingResponse thông tin đơn đã lược bỏ dữ liệu cá nhân (không địa chỉ, không item, không ghi chú)
+type PublicTrackingResponse struct {
+	OrderNumber   string    `json:"order_number"`
+	Status        string    `json:"status"`
+	StatusLabel   string    `json:"status_label"`
+	PaymentMethod string    `json:"payment_method"`
+	PaymentStatus string    `json:"payment_status"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Số tiền shipper sẽ thu (chỉ có với đơn COD chưa thanh toán)
+	CODAmount *decimal.Decimal `json:"cod_amount,omitempty"`
+	// Tên người nhận đã che (xem MaskName)
+	RecipientName       string                `json:"recipient_name,omitempty" pii:"name"`
+	Province            string                `json:"province,omitempty"`
+	Shipment            *PublicShipmentInfo   `json:"shipment,omitempty"`
+	EstimatedDeliveryAt *time.Time            `json:"estimated_delivery_at,omitempty"`
+	DeliveredAt         *time.Time            `json:"delivered_at,omitempty"`
+	CancelledAt         *time.Time            `json:"cancelled_at,omitempty"`
+	Timeline            []PublicTrackingEvent `json:"timeline"`
+}
+
+// PublicShipmentInfo vận đơn của hãng vận chuyển
+type PublicShipmentInfo struct {
+	Carrier        string `json:"carrier,omitempty"`
+	TrackingNumber string `json:"tracking_number"`
+}
+
+// PublicTrackingEvent 1 mốc trạng thái (không kèm người đổi / ghi chú nội bộ)
+type PublicTrackingEvent struct {
+	Status      string    `json:"status"`
+	StatusLabel string    `json:"status_label"`
+	At          time.Time `json:"at"`
+}
+
+// PhoneMatchesLast4 so khớp 4 số cuối, bỏ ký tự không phải số ("+84 912-345-678")
+func PhoneMatchesLast4(phone, last4 string) bool {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	return len(digits) >= 4 && len(last4) == 4 && strings.HasSuffix(digits, last4)
+}
+
+// MaskName che tên người nhận, giữ ký tự đầu mỗi từ ("Nguyễn Văn An" → "N***** V** A*")
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		runes := []rune(w)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
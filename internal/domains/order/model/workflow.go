@@ -0,0 +1,173 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =====================================================
+// ORDER STATUS WORKFLOW
+// =====================================================
+
+// AllOrderStatuses - danh sách cố định theo CHECK của orders.status, workflow chỉ cấu hình cạnh nối
+var AllOrderStatuses = []string{
+	OrderStatusPending,
+	OrderStatusConfirmed,
+	OrderStatusProcessing,
+	OrderStatusShipping,
+	OrderStatusDelivered,
+	OrderStatusCancelled,
+	OrderStatusReturned,
+}
+
+// Side-effect chạy sau khi commit transition (best effort, lỗi không rollback trạng thái)
+const (
+	TransitionHookNotifyCustomer  = "notify_customer"  // in-app + email báo khách
+	TransitionHookTriggerShipment = "trigger_shipment" // tạo vận đơn với hãng vận chuyển
+)
+
+var ValidTransitionHooks = []string{
+	TransitionHookNotifyCustomer,
+	TransitionHookTriggerShipment,
+}
+
+// StatusTransition - 1 bước chuyển trạng thái admin được phép thực hiện
+type StatusTransition struct {
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	Hooks      []string   `json:"hooks"`
+	UpdatedBy  *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// HasHook checks if transition runs the given side-effect
+func (t *StatusTransition) HasHook(hook string) bool {
+	for _, h := range t.Hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultStatusTransitions - workflow mặc định khi bảng order_status_transitions chưa có dữ liệu
+func DefaultStatusTransitions() []StatusTransition {
+	notify := []string{TransitionHookNotifyCustomer}
+	return []StatusTransition{
+		{FromStatus: OrderStatusPending, ToStatus: OrderStatusConfirmed, Hooks: notify},
+		{FromStatus: OrderStatusPending, ToStatus: OrderStatusCancelled, Hooks: notify},
+		{FromStatus: OrderStatusConfirmed, ToStatus: OrderStatusProcessing, Hooks: []string{}},
+		{FromStatus: OrderStatusConfirmed, ToStatus: OrderStatusCancelled, Hooks: notify},
+		{FromStatus: OrderStatusProcessing, ToStatus: OrderStatusShipping, Hooks: []string{TransitionHookNotifyCustomer, TransitionHookTriggerShipment}},
+		{FromStatus: OrderStatusProcessing, ToStatus: OrderStatusCancelled, Hooks: notify},
+		{FromStatus: OrderStatusShipping, ToStatus: OrderStatusDelivered, Hooks: notify},
+		{FromStatus: OrderStatusShipping, ToStatus: OrderStatusReturned, Hooks: notify},
+		{FromStatus: OrderStatusDelivered, ToStatus: OrderStatusReturned, Hooks: notify},
+	}
+}
+
+// ValidateStatusWorkflow kiểm tra workflow trước khi lưu / lúc khởi động
+//
+// Rules:
+// 1. Trạng thái + hook hợp lệ, không tự nối, không trùng
+// 2. Mọi trạng thái có trong workflow phải đi được từ pending
+// 3. delivered phải đi được từ pending (nếu không đơn không bao giờ hoàn tất)
+// 4. Mọi trạng thái phải đi được tới 1 trạng thái kết thúc (không có outgoing) → không kẹt trong vòng lặp
+func ValidateStatusWorkflow(transitions []StatusTransition) error {
+	if len(transitions) == 0 {
+		return fmt.Errorf("workflow must have at least one transition")
+	}
+
+	validStatus := make(map[string]bool, len(AllOrderStatuses))
+	for _, s := range AllOrderStatuses {
+		validStatus[s] = true
+	}
+	validHook := make(map[string]bool, len(ValidTransitionHooks))
+	for _, h := range ValidTransitionHooks {
+		validHook[h] = true
+	}
+
+	next := make(map[string][]string)
+	seen := make(map[string]bool)
+	used := make(map[string]bool)
+	for _, t := range transitions {
+		if !validStatus[t.FromStatus] || !validStatus[t.ToStatus] {
+			return fmt.Errorf("invalid status in transition %s → %s", t.FromStatus, t.ToStatus)
+		}
+		if t.FromStatus == t.ToStatus {
+			return fmt.Errorf("transition %s → %s must change status", t.FromStatus, t.ToStatus)
+		}
+		key := t.FromStatus + "→" + t.ToStatus
+		if seen[key] {
+			return fmt.Errorf("duplicate transition %s → %s", t.FromStatus, t.ToStatus)
+		}
+		seen[key] = true
+		for _, h := range t.Hooks {
+			if !validHook[h] {
+				return fmt.Errorf("invalid hook %q in transition %s → %s", h, t.FromStatus, t.ToStatus)
+			}
+		}
+
+		next[t.FromStatus] = append(next[t.FromStatus], t.ToStatus)
+		used[t.FromStatus] = true
+		used[t.ToStatus] = true
+	}
+
+	// Reachability từ pending (đơn mới luôn bắt đầu ở pending)
+	reachable := map[string]bool{OrderStatusPending: true}
+	queue := []string{OrderStatusPending}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, to := range next[current] {
+			if !reachable[to] {
+				reachable[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+
+	if !reachable[OrderStatusDelivered] {
+		return fmt.Errorf("status %s is not reachable from %s", OrderStatusDelivered, OrderStatusPending)
+	}
+	for _, s := range AllOrderStatuses {
+		if used[s] && !reachable[s] {
+			return fmt.Errorf("status %s is not reachable from %s", s, OrderStatusPending)
+		}
+	}
+
+	// Đi ngược từ trạng thái kết thúc: trạng thái nào không tới được kết thúc → kẹt vòng lặp
+	prev := make(map[string][]string)
+	for from, tos := range next {
+		for _, to := range tos {
+			prev[to] = append(prev[to], from)
+		}
+	}
+	canFinish := make(map[string]bool)
+	queue = queue[:0]
+	for s := range used {
+		if len(next[s]) == 0 {
+			canFinish[s] = true
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, from := range prev[current] {
+			if !canFinish[from] {
+				canFinish[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+	for _, s := range AllOrderStatuses {
+		if used[s] && !canFinish[s] {
+			return fmt.Errorf("status %s cannot reach a final status", s)
+		}
+	}
+
+	return nil
+}
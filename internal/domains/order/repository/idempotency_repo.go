@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/database"
+)
+
+type idempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) IdempotencyRepository {
+	return &idempotencyRepository{pool: pool}
+}
+
+// Reserve INSERT key mới; key cũ đã hết hạn bị ghi đè, key còn hạn giữ nguyên và được trả về
+func (r *idempotencyRepository) Reserve(
+	ctx context.Context,
+	record *shared.IdempotencyRecord,
+) (*shared.IdempotencyRecord, bool, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO idempotency_keys (
+			user_id, scope, idempotency_key, request_hash, status, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, scope, idempotency_key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status = EXCLUDED.status,
+			response_status = NULL,
+			response_body = NULL,
+			created_at = NOW(),
+			completed_at = NULL,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+	`,
+		record.UserID,
+		record.Scope,
+		record.Key,
+		record.RequestHash,
+		shared.IdempotencyStatusProcessing,
+		record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, true, nil
+	}
+
+	existing := &shared.IdempotencyRecord{
+		UserID: record.UserID,
+		Scope:  record.Scope,
+		Key:    record.Key,
+	}
+	var responseStatus *int
+	err = r.pool.QueryRow(ctx, `
+		SELECT request_hash, status, response_status, response_body, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND scope = $2 AND idempotency_key = $3
+	`, record.UserID, record.Scope, record.Key).Scan(
+		&existing.RequestHash,
+		&existing.Status,
+		&responseStatus,
+		&existing.ResponseBody,
+		&existing.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Bị Release giữa 2 câu lệnh → coi như đang xử lý, client retry sau
+			existing.Status = shared.IdempotencyStatusProcessing
+			return existing, false, nil
+		}
+		return nil, false, fmt.Errorf("get idempotency key: %w", err)
+	}
+	if responseStatus != nil {
+		existing.ResponseStatus = *responseStatus
+	}
+	return existing, false, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, userID, scope, key string, status int, body []byte) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status = $4, response_status = $5, response_body = $6, completed_at = NOW()
+		WHERE user_id = $1 AND scope = $2 AND idempotency_key = $3
+	`, userID, scope, key, shared.IdempotencyStatusCompleted, status, body)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, userID, scope, key string) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND scope = $2 AND idempotency_key = $3 AND status = $4
+	`, userID, scope, key, shared.IdempotencyStatusProcessing)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := database.WithBatchTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
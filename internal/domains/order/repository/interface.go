@@ -8,6 +8,8 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/pagination"
 )
 
 // =====================================================
@@ -39,6 +41,16 @@ type OrderRepository interface {
 		adminNote *string,
 		deliveredAt *time.Time,
 	) error
+	// Khách tự sửa đơn: địa chỉ giao, ghi chú, kho fulfill (optimistic lock theo version)
+	UpdateOrderShippingWithTx(
+		ctx context.Context,
+		tx pgx.Tx,
+		orderID uuid.UUID,
+		addressID uuid.UUID,
+		customerNote *string,
+		warehouseID *uuid.UUID,
+		version int,
+	) error
 
 	// Order items operations
 	CreateOrderItems(ctx context.Context, items []model.OrderItem) error
@@ -47,13 +59,119 @@ type OrderRepository interface {
 
 	// List operations
 	ListOrdersByUserID(ctx context.Context, userID uuid.UUID, status string, page, limit int) ([]model.Order, int, error)
-	ListAllOrders(ctx context.Context, status string, page, limit int) ([]model.Order, int, error)
+	// Keyset: after nil = trang đầu, không COUNT (xem pagination.Cursor)
+	ListOrdersByUserIDAfterCursor(ctx context.Context, userID uuid.UUID, status string, after *pagination.Cursor, limit int) ([]model.Order, error)
+	// metadataKey != "": chỉ đơn có key trong orders.metadata; kèm metadataValue: đúng giá trị
+	// channel != "": chỉ đơn của kênh bán đó
+	ListAllOrders(ctx context.Context, status, metadataKey, metadataValue, channel string, page, limit int) ([]model.Order, int, error)
 	CountOrderItemsByOrderID(ctx context.Context, orderID uuid.UUID) (int, error)
 
 	// Order status history
 	CreateOrderStatusHistory(ctx context.Context, history *model.OrderStatusHistory) error
 	CreateOrderStatusHistoryWithTx(ctx context.Context, tx pgx.Tx, history *model.OrderStatusHistory) error
 	GetOrderStatusHistory(ctx context.Context, orderID uuid.UUID) ([]model.OrderStatusHistory, error)
+
+	// Gift (order_gifts)
+	CreateOrderGiftWithTx(ctx context.Context, tx pgx.Tx, gift *model.OrderGift) error
+	GetOrderGift(ctx context.Context, orderID uuid.UUID) (*model.OrderGift, error) // nil nếu không phải đơn quà
+
+	// GetShipmentCarrier hãng vận chuyển của vận đơn ("" nếu đơn chưa mua nhãn)
+	GetShipmentCarrier(ctx context.Context, orderID uuid.UUID) (string, error)
+
+	// GetEInvoiceRef hoá đơn điện tử đang hiệu lực (nil nếu đơn chưa xuất hoá đơn)
+	GetEInvoiceRef(ctx context.Context, orderID uuid.UUID) (*model.EInvoiceRef, error)
+
+	// GetOrderItemSerials serial bản giới hạn đã gán lúc soạn hàng, theo order item id
+	GetOrderItemSerials(ctx context.Context, orderID uuid.UUID) (map[uuid.UUID][]string, error)
+
+	// Payment terms (order_payment_terms) - đơn B2B thanh toán theo công nợ
+	CreateOrderPaymentTermsWithTx(ctx context.Context, tx pgx.Tx, terms *model.OrderPaymentTerms) error
+	GetOrderPaymentTerms(ctx context.Context, orderID uuid.UUID) (*model.OrderPaymentTerms, error) // nil nếu không phải đơn B2B
+	MarkInvoicePaid(ctx context.Context, orderID uuid.UUID) error
+
+	// Tax lines (order_tax_lines) - snapshot dòng thuế lúc đặt cho breakdown / hoá đơn
+	CreateOrderTaxLinesWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, lines []model.OrderTaxLine) error
+	ListOrderTaxLines(ctx context.Context, orderID uuid.UUID) ([]model.OrderTaxLine, error)
+
+	// Contact history (order_communications + notification delivery logs của đơn)
+	CreateOrderCommunication(ctx context.Context, comm *model.OrderCommunication) error
+	ListOrderCommunications(ctx context.Context, orderID uuid.UUID) ([]model.OrderCommunication, error)
+
+	// Self-service modification audit (order_modifications)
+	CreateOrderModificationWithTx(ctx context.Context, tx pgx.Tx, mod *model.OrderModification) error
+	ListOrderModifications(ctx context.Context, orderID uuid.UUID) ([]model.OrderModification, error)
+
+	// Status workflow (order_status_transitions)
+	ListStatusTransitions(ctx context.Context) ([]model.StatusTransition, error)
+	ReplaceStatusTransitions(ctx context.Context, transitions []model.StatusTransition, updatedBy uuid.UUID) error
+
+	// Cancellation rules (order_cancellation_rules)
+	ListCancellationRules(ctx context.Context) ([]model.CancellationRule, error)
+	UpsertCancellationRules(ctx context.Context, rules []model.CancellationRule, updatedBy uuid.UUID) error
+	// IsFlashSaleOrder đơn được tạo từ purchase token flash sale
+	IsFlashSaleOrder(ctx context.Context, orderID uuid.UUID) (bool, error)
+
+	// Báo cáo tỷ lệ chọn đóng gói tối giản / không in hoá đơn
+	GetPackagingAdoption(ctx context.Context, from, to time.Time) (*model.PackagingAdoptionReport, error)
+
+	// Đơn gửi bù (order_replacements)
+	CreateOrderReplacementWithTx(ctx context.Context, tx pgx.Tx, replacement *model.OrderReplacement) error
+	// GetReplacedQuantities số cuốn đã gửi bù theo sách của đơn gốc (bỏ qua đơn gửi bù đã huỷ)
+	GetReplacedQuantities(ctx context.Context, originalOrderID uuid.UUID) (map[uuid.UUID]int, error)
+	// GetReplacementOf đơn gốc của đơn gửi bù (nil nếu không phải đơn gửi bù)
+	GetReplacementOf(ctx context.Context, orderID uuid.UUID) (*model.OrderReplacement, error)
+	ListReplacements(ctx context.Context, originalOrderID uuid.UUID) ([]model.OrderReplacement, error)
+	GetReplacementReport(ctx context.Context, from, to time.Time) (*model.ReplacementReport, error)
+
+	// Kiện theo kho (order_shipments) - đơn tách nhiều kho khi không kho nào đủ hàng
+	CreateOrderShipmentsWithTx(ctx context.Context, tx pgx.Tx, shipments []model.OrderShipment) error
+	ListOrderShipments(ctx context.Context, orderID uuid.UUID) ([]model.OrderShipment, error)
+	MoveOrderShipmentsWithTx(ctx context.Context, tx pgx.Tx, orderID, warehouseID uuid.UUID) error
+
+	// Kênh bán (sales_channels) + đơn sàn đẩy về (orders.channel / external_order_id)
+	GetSalesChannel(ctx context.Context, code string) (*model.SalesChannel, error)
+	ListSalesChannels(ctx context.Context) ([]model.SalesChannel, error)
+	UpdateSalesChannel(ctx context.Context, channel *model.SalesChannel, updatedBy uuid.UUID) error
+	GetOrderByExternalID(ctx context.Context, channel, externalOrderID string) (*model.Order, error)
+	FindBookIDsBySKU(ctx context.Context, skus []string) (map[string]uuid.UUID, error)
+
+	// Đồng bộ tồn + giá lên sàn (marketplace_listing_syncs)
+	ListMarketplaceListings(ctx context.Context, channel string, bookIDs []uuid.UUID, afterBookID uuid.UUID, limit int) ([]model.MarketplaceListing, error)
+	SaveMarketplaceListingSyncs(ctx context.Context, syncs []model.MarketplaceListingSync) error
+
+	// Đơn chưa thanh toán quá hạn (đối soát giữ hàng khi worker khởi động lại)
+	ListUnpaidOrdersCreatedBefore(ctx context.Context, paymentMethods []string, before time.Time, afterID uuid.UUID, limit int) ([]model.UnpaidOrderRef, error)
+
+	// Import đơn từ CSV (order_imports / order_import_rows)
+	CreateOrderImport(ctx context.Context, imp *model.OrderImport, rows []model.OrderImportRow) error
+	GetOrderImport(ctx context.Context, importID uuid.UUID) (*model.OrderImport, error)
+	ListOrderImportRows(ctx context.Context, importID uuid.UUID, status string) ([]model.OrderImportRow, error)
+	StartOrderImport(ctx context.Context, importID uuid.UUID) (bool, error)
+	SaveOrderImportRowResults(ctx context.Context, results []model.OrderImportRowResult) error
+	FinishOrderImport(ctx context.Context, importID uuid.UUID, status string, errMsg *string) error
+	FindUserIDsByEmail(ctx context.Context, emails []string) (map[string]uuid.UUID, error)
+
+	// Cập nhật trạng thái hàng loạt từ CSV (order_status_imports / order_status_import_rows)
+	CreateOrderStatusImport(ctx context.Context, imp *model.OrderStatusImport, rows []model.OrderStatusImportRow) error
+	GetOrderStatusImport(ctx context.Context, importID uuid.UUID) (*model.OrderStatusImport, error)
+	ListOrderStatusImportRows(ctx context.Context, importID uuid.UUID, status string) ([]model.OrderStatusImportRow, error)
+	StartOrderStatusImport(ctx context.Context, importID uuid.UUID) (bool, error)
+	SaveOrderStatusImportRowResult(ctx context.Context, result model.OrderStatusImportRowResult) error
+	FinishOrderStatusImport(ctx context.Context, importID uuid.UUID, status string, errMsg *string) error
+}
+
+// =====================================================
+// IDEMPOTENCY REPOSITORY INTERFACE
+// =====================================================
+// IdempotencyRepository lưu response theo Idempotency-Key (implement middleware.IdempotencyStore)
+type IdempotencyRepository interface {
+	// Reserve giữ chỗ key (status processing). Key đã tồn tại và chưa hết hạn → trả record cũ, reserved = false
+	Reserve(ctx context.Context, record *shared.IdempotencyRecord) (existing *shared.IdempotencyRecord, reserved bool, err error)
+	Complete(ctx context.Context, userID, scope, key string, status int, body []byte) error
+	// Release xoá key đang processing (handler lỗi 5xx / panic) để client retry được
+	Release(ctx context.Context, userID, scope, key string) error
+	// DeleteExpired xoá key đã hết hạn (Reserve chỉ ghi đè khi cùng key được dùng lại), trả số dòng đã xoá
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
 // =====================================================
@@ -102,12 +220,12 @@ type AddressRepository interface {
 type Address struct {
 	ID           uuid.UUID
 	UserID       uuid.UUID
-	ReceiverName string
-	Phone        string
+	ReceiverName string `pii:"name"`
+	Phone        string `pii:"phone"`
 	Province     string
-	District     string
-	Ward         string
-	FullAddress  string
+	District     string `pii:"address"`
+	Ward         string `pii:"address"`
+	FullAddress  string `pii:"address"`
 	IsDefault    bool
 }
 
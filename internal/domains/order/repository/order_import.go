@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// ORDER IMPORTS (CSV đơn offline / lịch sử)
+// =====================================================
+
+const orderImportColumns = `
+	id, file_name, status, total_rows, total_orders, created_orders, failed_rows,
+	error_message, created_by, created_at, started_at, completed_at`
+
+const orderImportRowColumns = `
+	id, import_id, line_no, order_ref, customer_email, isbn, quantity, unit_price,
+	payment_method, paid, address_id, note, status, order_id, error, processed_at`
+
+// CreateOrderImport ghi lần import + toàn bộ dòng đã parse trong 1 transaction
+func (r *postgresOrderRepository) CreateOrderImport(ctx context.Context, imp *model.OrderImport, rows []model.OrderImportRow) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO order_imports (id, file_name, status, total_rows, total_orders, failed_rows, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`, imp.ID, imp.FileName, imp.Status, imp.TotalRows, imp.TotalOrders, imp.FailedRows, imp.CreatedBy).Scan(&imp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order import: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"order_import_rows"},
+		[]string{
+			"id", "import_id", "line_no", "order_ref", "customer_email", "isbn", "quantity", "unit_price",
+			"payment_method", "paid", "address_id", "note", "status", "error",
+		},
+		pgx.CopyFromSlice(len(rows), func(i int) ([]interface{}, error) {
+			row := rows[i]
+			return []interface{}{
+				row.ID, imp.ID, row.LineNo, row.OrderRef, row.CustomerEmail, row.ISBN, row.Quantity, row.UnitPrice,
+				row.PaymentMethod, row.Paid, row.AddressID, row.Note, row.Status, row.Error,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert order import rows: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit order import: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresOrderRepository) GetOrderImport(ctx context.Context, importID uuid.UUID) (*model.OrderImport, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var imp model.OrderImport
+	err := r.pool.QueryRow(ctx, `SELECT `+orderImportColumns+` FROM order_imports WHERE id = $1`, importID).Scan(
+		&imp.ID, &imp.FileName, &imp.Status, &imp.TotalRows, &imp.TotalOrders, &imp.CreatedOrders, &imp.FailedRows,
+		&imp.ErrorMessage, &imp.CreatedBy, &imp.CreatedAt, &imp.StartedAt, &imp.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrImportNotFound
+		}
+		return nil, fmt.Errorf("failed to get order import: %w", err)
+	}
+	return &imp, nil
+}
+
+// ListOrderImportRows dòng của lần import theo thứ tự trong file (status rỗng = tất cả)
+func (r *postgresOrderRepository) ListOrderImportRows(ctx context.Context, importID uuid.UUID, status string) ([]model.OrderImportRow, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + orderImportRowColumns + ` FROM order_import_rows WHERE import_id = $1`
+	args := []interface{}{importID}
+	if status != "" {
+		query += ` AND status = $2`
+		args = append(args, status)
+	}
+	query += ` ORDER BY line_no`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order import rows: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.OrderImportRow, 0)
+	for rows.Next() {
+		var row model.OrderImportRow
+		if err := rows.Scan(
+			&row.ID, &row.ImportID, &row.LineNo, &row.OrderRef, &row.CustomerEmail, &row.ISBN, &row.Quantity, &row.UnitPrice,
+			&row.PaymentMethod, &row.Paid, &row.AddressID, &row.Note, &row.Status, &row.OrderID, &row.Error, &row.ProcessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order import row: %w", err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// StartOrderImport chuyển sang processing; false khi import đã kết thúc (task chạy lại sau khi xong)
+func (r *postgresOrderRepository) StartOrderImport(ctx context.Context, importID uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE order_imports
+		SET status = 'processing', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1 AND status IN ('pending', 'processing')
+	`, importID)
+	if err != nil {
+		return false, fmt.Errorf("failed to start order import: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SaveOrderImportRowResults ghi kết quả các dòng của 1 đơn (gọi ngay sau mỗi đơn để retry không làm lại)
+func (r *postgresOrderRepository) SaveOrderImportRowResults(ctx context.Context, results []model.OrderImportRowResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, result := range results {
+		batch.Queue(`
+			UPDATE order_import_rows
+			SET status = $2, order_id = $3, error = $4, processed_at = NOW()
+			WHERE id = $1
+		`, result.RowID, result.Status, result.OrderID, result.Error)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := range results {
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("failed to save order import row %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// FinishOrderImport chốt trạng thái + đếm lại kết quả từ order_import_rows
+func (r *postgresOrderRepository) FinishOrderImport(ctx context.Context, importID uuid.UUID, status string, errMsg *string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE order_imports i
+		SET status = $2,
+			error_message = $3,
+			completed_at = NOW(),
+			created_orders = s.created_orders,
+			failed_rows = s.failed_rows
+		FROM (
+			SELECT
+				COUNT(DISTINCT order_id) FILTER (WHERE status = 'created') AS created_orders,
+				COUNT(*) FILTER (WHERE status = 'failed') AS failed_rows
+			FROM order_import_rows
+			WHERE import_id = $1
+		) s
+		WHERE i.id = $1
+	`, importID, status, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to finish order import: %w", err)
+	}
+	return nil
+}
+
+// FindUserIDsByEmail map email (lower case) → user id; email không có tài khoản thì không có trong map
+func (r *postgresOrderRepository) FindUserIDsByEmail(ctx context.Context, emails []string) (map[string]uuid.UUID, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT LOWER(email), id FROM users WHERE LOWER(email) = ANY($1) AND deleted_at IS NULL`,
+		emails,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users by email: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]uuid.UUID, len(emails))
+	for rows.Next() {
+		var email string
+		var userID uuid.UUID
+		if err := rows.Scan(&email, &userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user email: %w", err)
+		}
+		result[strings.ToLower(email)] = userID
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// ORDER STATUS IMPORTS (cập nhật trạng thái hàng loạt từ CSV)
+// =====================================================
+
+const orderStatusImportColumns = `
+	id, file_name, status, total_rows, updated_rows, failed_rows,
+	error_message, created_by, created_at, started_at, completed_at`
+
+const orderStatusImportRowColumns = `
+	id, import_id, line_no, order_number, target_status, tracking_number,
+	status, order_id, from_status, error, processed_at`
+
+// CreateOrderStatusImport ghi lần import + toàn bộ dòng đã parse trong 1 transaction
+func (r *postgresOrderRepository) CreateOrderStatusImport(ctx context.Context, imp *model.OrderStatusImport, rows []model.OrderStatusImportRow) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO order_status_imports (id, file_name, status, total_rows, failed_rows, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`, imp.ID, imp.FileName, imp.Status, imp.TotalRows, imp.FailedRows, imp.CreatedBy).Scan(&imp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order status import: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"order_status_import_rows"},
+		[]string{"id", "import_id", "line_no", "order_number", "target_status", "tracking_number", "status", "error"},
+		pgx.CopyFromSlice(len(rows), func(i int) ([]interface{}, error) {
+			row := rows[i]
+			return []interface{}{
+				row.ID, imp.ID, row.LineNo, row.OrderNumber, row.TargetStatus, row.TrackingNumber, row.Status, row.Error,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert order status import rows: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit order status import: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresOrderRepository) GetOrderStatusImport(ctx context.Context, importID uuid.UUID) (*model.OrderStatusImport, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var imp model.OrderStatusImport
+	err := r.pool.QueryRow(ctx, `SELECT `+orderStatusImportColumns+` FROM order_status_imports WHERE id = $1`, importID).Scan(
+		&imp.ID, &imp.FileName, &imp.Status, &imp.TotalRows, &imp.UpdatedRows, &imp.FailedRows,
+		&imp.ErrorMessage, &imp.CreatedBy, &imp.CreatedAt, &imp.StartedAt, &imp.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrStatusImportNotFound
+		}
+		return nil, fmt.Errorf("failed to get order status import: %w", err)
+	}
+	return &imp, nil
+}
+
+// ListOrderStatusImportRows dòng của lần import theo thứ tự trong file (status rỗng = tất cả)
+func (r *postgresOrderRepository) ListOrderStatusImportRows(ctx context.Context, importID uuid.UUID, status string) ([]model.OrderStatusImportRow, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + orderStatusImportRowColumns + ` FROM order_status_import_rows WHERE import_id = $1`
+	args := []interface{}{importID}
+	if status != "" {
+		query += ` AND status = $2`
+		args = append(args, status)
+	}
+	query += ` ORDER BY line_no`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order status import rows: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.OrderStatusImportRow, 0)
+	for rows.Next() {
+		var row model.OrderStatusImportRow
+		if err := rows.Scan(
+			&row.ID, &row.ImportID, &row.LineNo, &row.OrderNumber, &row.TargetStatus, &row.TrackingNumber,
+			&row.Status, &row.OrderID, &row.FromStatus, &row.Error, &row.ProcessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order status import row: %w", err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// StartOrderStatusImport chuyển sang processing; false khi import đã kết thúc (task chạy lại sau khi xong)
+func (r *postgresOrderRepository) StartOrderStatusImport(ctx context.Context, importID uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE order_status_imports
+		SET status = 'processing', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1 AND status IN ('pending', 'processing')
+	`, importID)
+	if err != nil {
+		return false, fmt.Errorf("failed to start order status import: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SaveOrderStatusImportRowResult ghi kết quả 1 dòng (gọi ngay sau mỗi đơn để retry không làm lại)
+func (r *postgresOrderRepository) SaveOrderStatusImportRowResult(ctx context.Context, result model.OrderStatusImportRowResult) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE order_status_import_rows
+		SET status = $2, order_id = $3, from_status = $4, error = $5, processed_at = NOW()
+		WHERE id = $1
+	`, result.RowID, result.Status, result.OrderID, result.FromStatus, result.Error)
+	if err != nil {
+		return fmt.Errorf("failed to save order status import row: %w", err)
+	}
+	return nil
+}
+
+// FinishOrderStatusImport chốt trạng thái + đếm lại kết quả từ order_status_import_rows
+func (r *postgresOrderRepository) FinishOrderStatusImport(ctx context.Context, importID uuid.UUID, status string, errMsg *string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE order_status_imports i
+		SET status = $2,
+			error_message = $3,
+			completed_at = NOW(),
+			updated_rows = s.updated_rows,
+			failed_rows = s.failed_rows
+		FROM (
+			SELECT
+				COUNT(*) FILTER (WHERE status = 'updated') AS updated_rows,
+				COUNT(*) FILTER (WHERE status = 'failed') AS failed_rows
+			FROM order_status_import_rows
+			WHERE import_id = $1
+		) s
+		WHERE i.id = $1
+	`, importID, status, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to finish order status import: %w", err)
+	}
+	return nil
+}
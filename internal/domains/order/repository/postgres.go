@@ -9,9 +9,12 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared/pagination"
+	"bookstore-backend/pkg/database"
 	"bookstore-backend/pkg/logger"
 )
 
@@ -40,8 +43,9 @@ func (r *postgresOrderRepository) CommitTx(ctx context.Context, tx pgx.Tx) error
 	return tx.Commit(ctx)
 }
 
+// RollbackTx - an toàn khi ctx đã cancel/hết deadline (xem database.Rollback)
 func (r *postgresOrderRepository) RollbackTx(ctx context.Context, tx pgx.Tx) error {
-	return tx.Rollback(ctx)
+	return database.Rollback(ctx, tx)
 }
 
 // =====================================================
@@ -49,15 +53,20 @@ func (r *postgresOrderRepository) RollbackTx(ctx context.Context, tx pgx.Tx) err
 // =====================================================
 
 func (r *postgresOrderRepository) CreateOrder(ctx context.Context, order *model.Order) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO orders (
 			id, user_id, address_id, promotion_id,
-			subtotal, shipping_fee, discount_amount, total,
-			payment_method, payment_status, status, customer_note, version
+			subtotal, shipping_fee, shipping_discount, discount_amount, total,
+			payment_method, payment_status, status, customer_note, version,
+			tax_amount
 		) VALUES (
 			$1, $2, $3, $4,
-			$5, $6, $7, $8,
-			$9, $10, $11, $12, $13
+			$5, $6, $7, $8, $9,
+			$10, $11, $12, $13, $14,
+			$15
 		)
 		RETURNING order_number, created_at, updated_at
 	`
@@ -69,6 +78,7 @@ func (r *postgresOrderRepository) CreateOrder(ctx context.Context, order *model.
 		order.PromotionID,
 		order.Subtotal,
 		order.ShippingFee,
+		order.ShippingDiscount,
 		order.DiscountAmount,
 		order.Total,
 		order.PaymentMethod,
@@ -76,6 +86,7 @@ func (r *postgresOrderRepository) CreateOrder(ctx context.Context, order *model.
 		order.Status,
 		order.CustomerNote,
 		order.Version,
+		order.TaxAmount,
 	).Scan(&order.OrderNumber, &order.CreatedAt, &order.UpdatedAt)
 
 	if err != nil {
@@ -89,16 +100,26 @@ func (r *postgresOrderRepository) CreateOrderWithTx(ctx context.Context, tx pgx.
 	query := `
 		INSERT INTO orders (
 			id, user_id, address_id, promotion_id,
-			subtotal, shipping_fee, discount_amount, total,
-			payment_method, payment_status, status, customer_note, version
+			subtotal, shipping_fee, shipping_discount, discount_amount, total,
+			payment_method, payment_status, status, customer_note, version,
+			warehouse_id, minimal_packaging, no_printed_invoice, placed_by,
+			metadata, affiliate_code, affiliate_partner_id, channel, external_order_id,
+			tax_amount
 		) VALUES (
 			$1, $2, $3, $4,
-			$5, $6, $7, $8,
-			$9, $10, $11, $12, $13
+			$5, $6, $7, $8, $9,
+			$10, $11, $12, $13, $14,
+			$15, $16, $17, $18,
+			$19, $20, (SELECT id FROM affiliate_partners WHERE code = $20 AND is_active), $21, $22,
+			$23
 		)
-		RETURNING order_number, created_at, updated_at
+		RETURNING order_number, created_at, updated_at, affiliate_partner_id
 	`
 
+	if order.Channel == "" {
+		order.Channel = model.ChannelWeb
+	}
+
 	err := tx.QueryRow(ctx, query,
 		order.ID,
 		order.UserID,
@@ -106,6 +127,7 @@ func (r *postgresOrderRepository) CreateOrderWithTx(ctx context.Context, tx pgx.
 		order.PromotionID,
 		order.Subtotal,
 		order.ShippingFee,
+		order.ShippingDiscount,
 		order.DiscountAmount,
 		order.Total,
 		order.PaymentMethod,
@@ -113,9 +135,23 @@ func (r *postgresOrderRepository) CreateOrderWithTx(ctx context.Context, tx pgx.
 		order.Status,
 		order.CustomerNote,
 		order.Version,
-	).Scan(&order.OrderNumber, &order.CreatedAt, &order.UpdatedAt)
+		order.WarehouseID,
+		order.MinimalPackaging,
+		order.NoPrintedInvoice,
+		order.PlacedBy,
+		order.Metadata.OrEmpty(),
+		order.AffiliateCode,
+		order.Channel,
+		order.ExternalOrderID,
+		order.TaxAmount,
+	).Scan(&order.OrderNumber, &order.CreatedAt, &order.UpdatedAt, &order.AffiliatePartnerID)
 
 	if err != nil {
+		// Sàn gửi cùng 1 đơn song song → idx_orders_channel_external_id chặn bản thứ 2
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_orders_channel_external_id" {
+			return model.ErrExternalOrderExists
+		}
 		return fmt.Errorf("failed to create order with tx: %w", err)
 	}
 
@@ -127,14 +163,19 @@ func (r *postgresOrderRepository) CreateOrderWithTx(ctx context.Context, tx pgx.
 // =====================================================
 
 func (r *postgresOrderRepository) GetOrderByID(ctx context.Context, orderID uuid.UUID) (*model.Order, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
-			id, order_number, user_id, address_id, promotion_id,
-			subtotal, shipping_fee, discount_amount, total,
+			id, order_number, user_id, address_id, promotion_id, warehouse_id,
+			subtotal, shipping_fee, shipping_discount, discount_amount, tax_amount, total,
 			payment_method, payment_status, payment_details, paid_at,
 			status, tracking_number, estimated_delivery_at, delivered_at,
 			customer_note, admin_note, cancellation_reason,
-			created_at, updated_at, cancelled_at, version
+			created_at, updated_at, cancelled_at, version,
+			minimal_packaging, no_printed_invoice, placed_by, metadata,
+			channel, external_order_id
 		FROM orders
 		WHERE id = $1
 	`
@@ -146,9 +187,12 @@ func (r *postgresOrderRepository) GetOrderByID(ctx context.Context, orderID uuid
 		&order.UserID,
 		&order.AddressID,
 		&order.PromotionID,
+		&order.WarehouseID,
 		&order.Subtotal,
 		&order.ShippingFee,
+		&order.ShippingDiscount,
 		&order.DiscountAmount,
+		&order.TaxAmount,
 		&order.Total,
 		&order.PaymentMethod,
 		&order.PaymentStatus,
@@ -165,6 +209,12 @@ func (r *postgresOrderRepository) GetOrderByID(ctx context.Context, orderID uuid
 		&order.UpdatedAt,
 		&order.CancelledAt,
 		&order.Version,
+		&order.MinimalPackaging,
+		&order.NoPrintedInvoice,
+		&order.PlacedBy,
+		&order.Metadata,
+		&order.Channel,
+		&order.ExternalOrderID,
 	)
 
 	if err != nil {
@@ -178,14 +228,19 @@ func (r *postgresOrderRepository) GetOrderByID(ctx context.Context, orderID uuid
 }
 
 func (r *postgresOrderRepository) GetOrderByIDAndUserID(ctx context.Context, orderID, userID uuid.UUID) (*model.Order, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
-			id, order_number, user_id, address_id, promotion_id,
-			subtotal, shipping_fee, discount_amount, total,
+			id, order_number, user_id, address_id, promotion_id, warehouse_id,
+			subtotal, shipping_fee, shipping_discount, discount_amount, tax_amount, total,
 			payment_method, payment_status, payment_details, paid_at,
 			status, tracking_number, estimated_delivery_at, delivered_at,
 			customer_note, admin_note, cancellation_reason,
-			created_at, updated_at, cancelled_at, version
+			created_at, updated_at, cancelled_at, version,
+			minimal_packaging, no_printed_invoice, metadata,
+			channel, external_order_id
 		FROM orders
 		WHERE id = $1 AND user_id = $2
 	`
@@ -197,9 +252,12 @@ func (r *postgresOrderRepository) GetOrderByIDAndUserID(ctx context.Context, ord
 		&order.UserID,
 		&order.AddressID,
 		&order.PromotionID,
+		&order.WarehouseID,
 		&order.Subtotal,
 		&order.ShippingFee,
+		&order.ShippingDiscount,
 		&order.DiscountAmount,
+		&order.TaxAmount,
 		&order.Total,
 		&order.PaymentMethod,
 		&order.PaymentStatus,
@@ -216,6 +274,11 @@ func (r *postgresOrderRepository) GetOrderByIDAndUserID(ctx context.Context, ord
 		&order.UpdatedAt,
 		&order.CancelledAt,
 		&order.Version,
+		&order.MinimalPackaging,
+		&order.NoPrintedInvoice,
+		&order.Metadata,
+		&order.Channel,
+		&order.ExternalOrderID,
 	)
 
 	if err != nil {
@@ -229,14 +292,18 @@ func (r *postgresOrderRepository) GetOrderByIDAndUserID(ctx context.Context, ord
 }
 
 func (r *postgresOrderRepository) GetOrderByNumber(ctx context.Context, orderNumber string) (*model.Order, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
-			id, order_number, user_id, address_id, promotion_id,
-			subtotal, shipping_fee, discount_amount, total,
+			id, order_number, user_id, address_id, promotion_id, warehouse_id,
+			subtotal, shipping_fee, shipping_discount, discount_amount, tax_amount, total,
 			payment_method, payment_status, payment_details, paid_at,
 			status, tracking_number, estimated_delivery_at, delivered_at,
 			customer_note, admin_note, cancellation_reason,
-			created_at, updated_at, cancelled_at, version
+			created_at, updated_at, cancelled_at, version,
+			minimal_packaging, no_printed_invoice, metadata
 		FROM orders
 		WHERE order_number = $1
 	`
@@ -248,9 +315,12 @@ func (r *postgresOrderRepository) GetOrderByNumber(ctx context.Context, orderNum
 		&order.UserID,
 		&order.AddressID,
 		&order.PromotionID,
+		&order.WarehouseID,
 		&order.Subtotal,
 		&order.ShippingFee,
+		&order.ShippingDiscount,
 		&order.DiscountAmount,
+		&order.TaxAmount,
 		&order.Total,
 		&order.PaymentMethod,
 		&order.PaymentStatus,
@@ -267,6 +337,9 @@ func (r *postgresOrderRepository) GetOrderByNumber(ctx context.Context, orderNum
 		&order.UpdatedAt,
 		&order.CancelledAt,
 		&order.Version,
+		&order.MinimalPackaging,
+		&order.NoPrintedInvoice,
+		&order.Metadata,
 	)
 
 	if err != nil {
@@ -284,6 +357,9 @@ func (r *postgresOrderRepository) GetOrderByNumber(ctx context.Context, orderNum
 // =====================================================
 
 func (r *postgresOrderRepository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, status string, version int) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE orders
 		SET status = $1, version = version + 1, updated_at = NOW()
@@ -359,6 +435,9 @@ func (r *postgresOrderRepository) UpdateOrderStatusWithTx(
 }
 
 func (r *postgresOrderRepository) CancelOrder(ctx context.Context, orderID uuid.UUID, reason string, version int) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE orders
 		SET status = $1, 
@@ -382,6 +461,9 @@ func (r *postgresOrderRepository) CancelOrder(ctx context.Context, orderID uuid.
 }
 
 func (r *postgresOrderRepository) UpdateOrderTracking(ctx context.Context, orderID uuid.UUID, trackingNumber string, version int) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE orders
 		SET tracking_number = $1,
@@ -403,6 +485,9 @@ func (r *postgresOrderRepository) UpdateOrderTracking(ctx context.Context, order
 }
 
 func (r *postgresOrderRepository) UpdateOrderAdminNote(ctx context.Context, orderID uuid.UUID, adminNote string, version int) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE orders
 		SET admin_note = $1,
@@ -423,15 +508,49 @@ func (r *postgresOrderRepository) UpdateOrderAdminNote(ctx context.Context, orde
 	return nil
 }
 
+func (r *postgresOrderRepository) UpdateOrderShippingWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	orderID uuid.UUID,
+	addressID uuid.UUID,
+	customerNote *string,
+	warehouseID *uuid.UUID,
+	version int,
+) error {
+	query := `
+		UPDATE orders
+		SET address_id = $1,
+			customer_note = $2,
+			warehouse_id = $3,
+			version = version + 1,
+			updated_at = NOW()
+		WHERE id = $4 AND version = $5
+	`
+
+	result, err := tx.Exec(ctx, query, addressID, customerNote, warehouseID, orderID, version)
+	if err != nil {
+		return fmt.Errorf("failed to update order shipping: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrVersionMismatch
+	}
+
+	return nil
+}
+
 // =====================================================
 // ORDER ITEMS
 // =====================================================
 
 func (r *postgresOrderRepository) CreateOrderItems(ctx context.Context, items []model.OrderItem) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	copyCount, err := r.pool.CopyFrom(
 		ctx,
 		pgx.Identifier{"order_items"},
-		[]string{"id", "order_id", "book_id", "book_title", "book_slug", "book_cover_url", "author_name", "quantity", "price", "subtotal"},
+		[]string{"id", "order_id", "book_id", "book_title", "book_slug", "book_cover_url", "author_name", "quantity", "price", "subtotal", "list_price", "tier_discount_percent", "gift_promotion_id"},
 		pgx.CopyFromSlice(len(items), func(i int) ([]interface{}, error) {
 			return []interface{}{
 				items[i].ID,
@@ -444,6 +563,9 @@ func (r *postgresOrderRepository) CreateOrderItems(ctx context.Context, items []
 				items[i].Quantity,
 				items[i].Price,
 				items[i].Subtotal,
+				items[i].ListPrice,
+				items[i].TierDiscountPercent,
+				items[i].GiftPromotionID,
 			}, nil
 		}),
 	)
@@ -464,8 +586,10 @@ func (r *postgresOrderRepository) CreateOrderItemsWithTx(ctx context.Context, tx
 	query := `
 		INSERT INTO order_items (
 			id, order_id, book_id, book_title, book_slug, 
-			book_cover_url, author_name, quantity, price, subtotal
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			book_cover_url, author_name, quantity, price, subtotal,
+			list_price, tier_discount_percent, gift_promotion_id, shipment_id,
+			metadata
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	for _, item := range items {
@@ -480,6 +604,11 @@ func (r *postgresOrderRepository) CreateOrderItemsWithTx(ctx context.Context, tx
 			item.Quantity,
 			item.Price,
 			item.Subtotal,
+			item.ListPrice,
+			item.TierDiscountPercent,
+			item.GiftPromotionID,
+			item.ShipmentID,
+			item.Metadata.OrEmpty(),
 		)
 	}
 
@@ -497,13 +626,19 @@ func (r *postgresOrderRepository) CreateOrderItemsWithTx(ctx context.Context, tx
 }
 
 func (r *postgresOrderRepository) GetOrderItemsByOrderID(ctx context.Context, orderID uuid.UUID) ([]model.OrderItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
-			id, order_id, book_id, book_title, book_slug,
-			book_cover_url, author_name, quantity, price, subtotal, created_at
-		FROM order_items
-		WHERE order_id = $1
-		ORDER BY created_at ASC
+			oi.id, oi.order_id, oi.book_id, oi.book_title, oi.book_slug,
+			oi.book_cover_url, oi.author_name, oi.quantity, oi.price, oi.subtotal, oi.created_at,
+			oi.list_price, oi.tier_discount_percent, oi.gift_promotion_id,
+			oi.shipment_id, os.warehouse_id, oi.metadata
+		FROM order_items oi
+		LEFT JOIN order_shipments os ON os.id = oi.shipment_id
+		WHERE oi.order_id = $1
+		ORDER BY oi.created_at ASC
 	`
 
 	rows, err := r.pool.Query(ctx, query, orderID)
@@ -527,6 +662,12 @@ func (r *postgresOrderRepository) GetOrderItemsByOrderID(ctx context.Context, or
 			&item.Price,
 			&item.Subtotal,
 			&item.CreatedAt,
+			&item.ListPrice,
+			&item.TierDiscountPercent,
+			&item.GiftPromotionID,
+			&item.ShipmentID,
+			&item.WarehouseID,
+			&item.Metadata,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan order item: %w", err)
@@ -542,6 +683,9 @@ func (r *postgresOrderRepository) GetOrderItemsByOrderID(ctx context.Context, or
 }
 
 func (r *postgresOrderRepository) CountOrderItemsByOrderID(ctx context.Context, orderID uuid.UUID) (int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `SELECT COUNT(*) FROM order_items WHERE order_id = $1`
 
 	var count int
@@ -554,6 +698,9 @@ func (r *postgresOrderRepository) CountOrderItemsByOrderID(ctx context.Context,
 }
 
 func (r *postgresOrderRepository) CountOrderItemsByOrderIDs(ctx context.Context, orderIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	result := make(map[uuid.UUID]int)
 	if len(orderIDs) == 0 {
 		return result, nil
@@ -593,12 +740,15 @@ func (r *postgresOrderRepository) CountOrderItemsByOrderIDs(ctx context.Context,
 // =====================================================
 
 func (r *postgresOrderRepository) ListOrdersByUserID(ctx context.Context, userID uuid.UUID, status string, page, limit int) ([]model.Order, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	offset := (page - 1) * limit
 
 	queryBuilder := `
 		SELECT 
 			id, order_number, user_id, address_id, promotion_id,
-			subtotal, shipping_fee, discount_amount, total,
+			subtotal, shipping_fee, shipping_discount, discount_amount, total,
 			payment_method, payment_status, paid_at,
 			status, tracking_number, estimated_delivery_at, delivered_at,
 			customer_note, cancellation_reason,
@@ -639,6 +789,57 @@ func (r *postgresOrderRepository) ListOrdersByUserID(ctx context.Context, userID
 	}
 	defer rows.Close()
 
+	orders, err := scanOrderList(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// ListOrdersByUserIDAfterCursor keyset: đơn cũ hơn cursor (created_at, id), mới nhất trước.
+// after nil = trang đầu. Không COUNT; service gọi với limit+1 để biết còn trang sau
+func (r *postgresOrderRepository) ListOrdersByUserIDAfterCursor(ctx context.Context, userID uuid.UUID, status string, after *pagination.Cursor, limit int) ([]model.Order, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			id, order_number, user_id, address_id, promotion_id,
+			subtotal, shipping_fee, shipping_discount, discount_amount, total,
+			payment_method, payment_status, paid_at,
+			status, tracking_number, estimated_delivery_at, delivered_at,
+			customer_note, cancellation_reason,
+			created_at, updated_at, cancelled_at, version
+		FROM orders
+		WHERE user_id = $1
+	`
+	args := []interface{}{userID}
+
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(` AND status = $%d`, len(args))
+	}
+	if after != nil {
+		condition, cursorArgs := after.Condition([]string{"created_at", "id"}, len(args)+1)
+		query += ` AND ` + condition
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOrderList(rows)
+}
+
+// scanOrderList scan các cột tóm tắt đơn (danh sách đơn của khách)
+func scanOrderList(rows pgx.Rows) ([]model.Order, error) {
 	var orders []model.Order
 	for rows.Next() {
 		var order model.Order
@@ -650,6 +851,7 @@ func (r *postgresOrderRepository) ListOrdersByUserID(ctx context.Context, userID
 			&order.PromotionID,
 			&order.Subtotal,
 			&order.ShippingFee,
+			&order.ShippingDiscount,
 			&order.DiscountAmount,
 			&order.Total,
 			&order.PaymentMethod,
@@ -667,29 +869,34 @@ func (r *postgresOrderRepository) ListOrdersByUserID(ctx context.Context, userID
 			&order.Version,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
+			return nil, fmt.Errorf("failed to scan order: %w", err)
 		}
 		orders = append(orders, order)
 	}
 
 	if rows.Err() != nil {
-		return nil, 0, fmt.Errorf("error iterating orders: %w", rows.Err())
+		return nil, fmt.Errorf("error iterating orders: %w", rows.Err())
 	}
 
-	return orders, total, nil
+	return orders, nil
 }
 
-func (r *postgresOrderRepository) ListAllOrders(ctx context.Context, status string, page, limit int) ([]model.Order, int, error) {
+func (r *postgresOrderRepository) ListAllOrders(ctx context.Context, status, metadataKey, metadataValue, channel string, page, limit int) ([]model.Order, int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	offset := (page - 1) * limit
 
 	queryBuilder := `
 		SELECT 
 			id, order_number, user_id, address_id, promotion_id,
-			subtotal, shipping_fee, discount_amount, total,
+			subtotal, shipping_fee, shipping_discount, discount_amount, total,
 			payment_method, payment_status, paid_at,
 			status, tracking_number, estimated_delivery_at, delivered_at,
 			customer_note, admin_note, cancellation_reason,
-			created_at, updated_at, cancelled_at, version
+			created_at, updated_at, cancelled_at, version,
+			minimal_packaging, no_printed_invoice, metadata,
+			channel, external_order_id
 		FROM orders
 		WHERE 1=1
 	`
@@ -699,10 +906,33 @@ func (r *postgresOrderRepository) ListAllOrders(ctx context.Context, status stri
 	countArgs := []interface{}{}
 
 	if status != "" {
-		queryBuilder += ` AND status = $1`
-		countQuery += ` AND status = $1`
 		args = append(args, status)
 		countArgs = append(countArgs, status)
+		queryBuilder += fmt.Sprintf(` AND status = $%d`, len(args))
+		countQuery += fmt.Sprintf(` AND status = $%d`, len(countArgs))
+	}
+
+	// Lọc metadata: key + value dùng @> (GIN idx_orders_metadata), chỉ key dùng toán tử ?
+	if metadataKey != "" {
+		var condition string
+		if metadataValue != "" {
+			args = append(args, model.Metadata{metadataKey: metadataValue})
+			countArgs = append(countArgs, model.Metadata{metadataKey: metadataValue})
+			condition = ` AND metadata @> $%d::jsonb`
+		} else {
+			args = append(args, metadataKey)
+			countArgs = append(countArgs, metadataKey)
+			condition = ` AND metadata ? $%d`
+		}
+		queryBuilder += fmt.Sprintf(condition, len(args))
+		countQuery += fmt.Sprintf(condition, len(countArgs))
+	}
+
+	if channel != "" {
+		args = append(args, channel)
+		countArgs = append(countArgs, channel)
+		queryBuilder += fmt.Sprintf(` AND channel = $%d`, len(args))
+		countQuery += fmt.Sprintf(` AND channel = $%d`, len(countArgs))
 	}
 
 	queryBuilder += ` ORDER BY created_at DESC LIMIT $` + fmt.Sprintf("%d", len(args)+1) + ` OFFSET $` + fmt.Sprintf("%d", len(args)+2)
@@ -731,6 +961,7 @@ func (r *postgresOrderRepository) ListAllOrders(ctx context.Context, status stri
 			&order.PromotionID,
 			&order.Subtotal,
 			&order.ShippingFee,
+			&order.ShippingDiscount,
 			&order.DiscountAmount,
 			&order.Total,
 			&order.PaymentMethod,
@@ -747,6 +978,11 @@ func (r *postgresOrderRepository) ListAllOrders(ctx context.Context, status stri
 			&order.UpdatedAt,
 			&order.CancelledAt,
 			&order.Version,
+			&order.MinimalPackaging,
+			&order.NoPrintedInvoice,
+			&order.Metadata,
+			&order.Channel,
+			&order.ExternalOrderID,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
@@ -766,6 +1002,9 @@ func (r *postgresOrderRepository) ListAllOrders(ctx context.Context, status stri
 // =====================================================
 
 func (r *postgresOrderRepository) CreateOrderStatusHistory(ctx context.Context, history *model.OrderStatusHistory) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
 	query := `
 		INSERT INTO order_status_history (
 			id, order_id, from_status, to_status, changed_by, notes
@@ -812,7 +1051,148 @@ func (r *postgresOrderRepository) CreateOrderStatusHistoryWithTx(ctx context.Con
 	return nil
 }
 
+// =====================================================
+// ORDER GIFT
+// =====================================================
+
+func (r *postgresOrderRepository) CreateOrderGiftWithTx(ctx context.Context, tx pgx.Tx, gift *model.OrderGift) error {
+	query := `
+		INSERT INTO order_gifts (
+			order_id, recipient_name, recipient_phone,
+			province, district, ward, street,
+			gift_message, hide_prices, scheduled_delivery_date
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`
+
+	err := tx.QueryRow(ctx, query,
+		gift.OrderID,
+		gift.RecipientName,
+		gift.RecipientPhone,
+		gift.Province,
+		gift.District,
+		gift.Ward,
+		gift.Street,
+		gift.GiftMessage,
+		gift.HidePrices,
+		gift.ScheduledDeliveryDate,
+	).Scan(&gift.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create order gift with tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresOrderRepository) GetOrderGift(ctx context.Context, orderID uuid.UUID) (*model.OrderGift, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			order_id, recipient_name, recipient_phone,
+			province, district, ward, street,
+			gift_message, hide_prices, scheduled_delivery_date, created_at
+		FROM order_gifts
+		WHERE order_id = $1
+	`
+
+	var gift model.OrderGift
+	err := r.pool.QueryRow(ctx, query, orderID).Scan(
+		&gift.OrderID,
+		&gift.RecipientName,
+		&gift.RecipientPhone,
+		&gift.Province,
+		&gift.District,
+		&gift.Ward,
+		&gift.Street,
+		&gift.GiftMessage,
+		&gift.HidePrices,
+		&gift.ScheduledDeliveryDate,
+		&gift.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order gift: %w", err)
+	}
+
+	return &gift, nil
+}
+
+func (r *postgresOrderRepository) GetShipmentCarrier(ctx context.Context, orderID uuid.UUID) (string, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var carrier string
+	err := r.pool.QueryRow(ctx, `SELECT carrier FROM shipments WHERE order_id = $1`, orderID).Scan(&carrier)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get shipment carrier: %w", err)
+	}
+
+	return carrier, nil
+}
+
+func (r *postgresOrderRepository) GetEInvoiceRef(ctx context.Context, orderID uuid.UUID) (*model.EInvoiceRef, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT einvoice_template_code, einvoice_series, einvoice_number, einvoice_issued_at
+		FROM orders
+		WHERE id = $1 AND einvoice_number IS NOT NULL`
+
+	var ref model.EInvoiceRef
+	err := r.pool.QueryRow(ctx, query, orderID).Scan(&ref.TemplateCode, &ref.Series, &ref.Number, &ref.IssuedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order e-invoice: %w", err)
+	}
+
+	return &ref, nil
+}
+
+func (r *postgresOrderRepository) GetOrderItemSerials(ctx context.Context, orderID uuid.UUID) (map[uuid.UUID][]string, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT order_item_id, serial_number
+		FROM inventory_serial_units
+		WHERE order_id = $1 AND order_item_id IS NOT NULL
+		ORDER BY serial_number`
+
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order item serials: %w", err)
+	}
+	defer rows.Close()
+
+	serials := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var itemID uuid.UUID
+		var serial string
+		if err := rows.Scan(&itemID, &serial); err != nil {
+			return nil, fmt.Errorf("failed to scan order item serial: %w", err)
+		}
+		serials[itemID] = append(serials[itemID], serial)
+	}
+
+	return serials, rows.Err()
+}
+
 func (r *postgresOrderRepository) GetOrderStatusHistory(ctx context.Context, orderID uuid.UUID) ([]model.OrderStatusHistory, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			id, order_id, from_status, to_status, changed_by, notes, changed_at
@@ -851,3 +1231,1066 @@ func (r *postgresOrderRepository) GetOrderStatusHistory(ctx context.Context, ord
 
 	return histories, nil
 }
+
+// =====================================================
+// PAYMENT TERMS (B2B INVOICE)
+// =====================================================
+
+func (r *postgresOrderRepository) CreateOrderPaymentTermsWithTx(ctx context.Context, tx pgx.Tx, terms *model.OrderPaymentTerms) error {
+	query := `
+		INSERT INTO order_payment_terms (
+			order_id, quote_id, company_name, tax_code, terms_days, due_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+
+	err := tx.QueryRow(ctx, query,
+		terms.OrderID,
+		terms.QuoteID,
+		terms.CompanyName,
+		terms.TaxCode,
+		terms.TermsDays,
+		terms.DueAt,
+	).Scan(&terms.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create order payment terms with tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresOrderRepository) GetOrderPaymentTerms(ctx context.Context, orderID uuid.UUID) (*model.OrderPaymentTerms, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT order_id, quote_id, company_name, tax_code, terms_days, due_at, created_at
+		FROM order_payment_terms
+		WHERE order_id = $1
+	`
+
+	var terms model.OrderPaymentTerms
+	err := r.pool.QueryRow(ctx, query, orderID).Scan(
+		&terms.OrderID,
+		&terms.QuoteID,
+		&terms.CompanyName,
+		&terms.TaxCode,
+		&terms.TermsDays,
+		&terms.DueAt,
+		&terms.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order payment terms: %w", err)
+	}
+
+	return &terms, nil
+}
+
+// =====================================================
+// TAX LINES
+// =====================================================
+
+func (r *postgresOrderRepository) CreateOrderTaxLinesWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, lines []model.OrderTaxLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, line := range lines {
+		batch.Queue(`
+			INSERT INTO order_tax_lines (
+				order_id, tax_rule_id, name, rate, price_includes_tax, taxable_amount, tax_amount
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, orderID, line.TaxRuleID, line.Name, line.Rate, line.PriceIncludesTax, line.TaxableAmount, line.TaxAmount)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range lines {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to create order tax line: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *postgresOrderRepository) ListOrderTaxLines(ctx context.Context, orderID uuid.UUID) ([]model.OrderTaxLine, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, order_id, tax_rule_id, name, rate, price_includes_tax, taxable_amount, tax_amount
+		FROM order_tax_lines
+		WHERE order_id = $1
+		ORDER BY rate DESC, name
+	`
+
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order tax lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]model.OrderTaxLine, 0)
+	for rows.Next() {
+		var line model.OrderTaxLine
+		if err := rows.Scan(
+			&line.ID,
+			&line.OrderID,
+			&line.TaxRuleID,
+			&line.Name,
+			&line.Rate,
+			&line.PriceIncludesTax,
+			&line.TaxableAmount,
+			&line.TaxAmount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order tax line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// MarkInvoicePaid ghi nhận khách B2B đã thanh toán công nợ.
+// Đơn invoice không có payment record (không qua gateway) → update trực tiếp orders.
+func (r *postgresOrderRepository) MarkInvoicePaid(ctx context.Context, orderID uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE orders
+		SET payment_status = 'paid',
+			paid_at = NOW(),
+			version = version + 1
+		WHERE id = $1
+			AND payment_method = 'invoice'
+			AND payment_status = 'pending'
+			AND status <> 'cancelled'
+	`
+
+	result, err := r.pool.Exec(ctx, query, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to mark invoice paid: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.NewOrderError(
+			model.ErrCodeInvalidStatus,
+			"Order is not an unpaid invoice order",
+			model.ErrInvalidStatus,
+		)
+	}
+
+	return nil
+}
+
+// =====================================================
+// ORDER COMMUNICATIONS
+// =====================================================
+
+func (r *postgresOrderRepository) CreateOrderCommunication(ctx context.Context, comm *model.OrderCommunication) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO order_communications (
+			order_id, channel, purpose, status, recipient, subject, note, error_message, created_by,
+			provider_message_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		comm.OrderID,
+		comm.Channel,
+		comm.Purpose,
+		comm.Status,
+		comm.Recipient,
+		comm.Subject,
+		comm.Note,
+		comm.ErrorMessage,
+		comm.CreatedBy,
+		comm.ProviderMessageID,
+	).Scan(&comm.ID, &comm.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order communication: %w", err)
+	}
+
+	comm.Source = model.CommunicationSourceOrder
+	return nil
+}
+
+// ListOrderCommunications gộp log liên lạc của đơn với delivery log của notification tham chiếu tới đơn
+func (r *postgresOrderRepository) ListOrderCommunications(ctx context.Context, orderID uuid.UUID) ([]model.OrderCommunication, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			oc.id, oc.channel, oc.purpose, oc.status, oc.recipient, oc.subject,
+			oc.note, oc.error_message, oc.created_by, 'order' AS source, oc.created_at,
+			oc.provider_message_id
+		FROM order_communications oc
+		WHERE oc.order_id = $1
+
+		UNION ALL
+
+		SELECT
+			dl.id, dl.channel, n.type, dl.status, dl.recipient, n.title,
+			NULL, dl.error_message, NULL, 'notification' AS source,
+			COALESCE(dl.sent_at, dl.failed_at, dl.created_at),
+			dl.provider_message_id
+		FROM notification_delivery_logs dl
+		JOIN notifications n ON n.id = dl.notification_id
+		WHERE (n.reference_type = 'order' AND n.reference_id = $1)
+			OR (n.reference_type = 'order_status' AND n.reference_id IN (
+				SELECT osh.id FROM order_status_history osh WHERE osh.order_id = $1
+			))
+
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order communications: %w", err)
+	}
+	defer rows.Close()
+
+	communications := []model.OrderCommunication{}
+	for rows.Next() {
+		comm := model.OrderCommunication{OrderID: orderID}
+		err := rows.Scan(
+			&comm.ID,
+			&comm.Channel,
+			&comm.Purpose,
+			&comm.Status,
+			&comm.Recipient,
+			&comm.Subject,
+			&comm.Note,
+			&comm.ErrorMessage,
+			&comm.CreatedBy,
+			&comm.Source,
+			&comm.CreatedAt,
+			&comm.ProviderMessageID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order communication: %w", err)
+		}
+		communications = append(communications, comm)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating order communications: %w", rows.Err())
+	}
+
+	return communications, nil
+}
+
+// =====================================================
+// ORDER MODIFICATIONS
+// =====================================================
+
+func (r *postgresOrderRepository) CreateOrderModificationWithTx(ctx context.Context, tx pgx.Tx, mod *model.OrderModification) error {
+	query := `
+		INSERT INTO order_modifications (
+			order_id, old_address_id, new_address_id, old_customer_note, new_customer_note,
+			old_warehouse_id, new_warehouse_id, changed_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := tx.QueryRow(ctx, query,
+		mod.OrderID,
+		mod.OldAddressID,
+		mod.NewAddressID,
+		mod.OldCustomerNote,
+		mod.NewCustomerNote,
+		mod.OldWarehouseID,
+		mod.NewWarehouseID,
+		mod.ChangedBy,
+	).Scan(&mod.ID, &mod.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order modification: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresOrderRepository) ListOrderModifications(ctx context.Context, orderID uuid.UUID) ([]model.OrderModification, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			id, order_id, old_address_id, new_address_id, old_customer_note, new_customer_note,
+			old_warehouse_id, new_warehouse_id, changed_by, created_at
+		FROM order_modifications
+		WHERE order_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order modifications: %w", err)
+	}
+	defer rows.Close()
+
+	modifications := []model.OrderModification{}
+	for rows.Next() {
+		var mod model.OrderModification
+		err := rows.Scan(
+			&mod.ID,
+			&mod.OrderID,
+			&mod.OldAddressID,
+			&mod.NewAddressID,
+			&mod.OldCustomerNote,
+			&mod.NewCustomerNote,
+			&mod.OldWarehouseID,
+			&mod.NewWarehouseID,
+			&mod.ChangedBy,
+			&mod.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order modification: %w", err)
+		}
+		modifications = append(modifications, mod)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating order modifications: %w", rows.Err())
+	}
+
+	return modifications, nil
+}
+
+// =====================================================
+// ORDER STATUS WORKFLOW
+// =====================================================
+
+// ListStatusTransitions lists configured transitions (rỗng → service dùng workflow mặc định)
+func (r *postgresOrderRepository) ListStatusTransitions(ctx context.Context) ([]model.StatusTransition, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT from_status, to_status, hooks, updated_by, updated_at
+		FROM order_status_transitions
+		ORDER BY from_status, to_status
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order status transitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions := []model.StatusTransition{}
+	for rows.Next() {
+		var t model.StatusTransition
+		if err := rows.Scan(&t.FromStatus, &t.ToStatus, &t.Hooks, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order status transition: %w", err)
+		}
+		transitions = append(transitions, t)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating order status transitions: %w", rows.Err())
+	}
+
+	return transitions, nil
+}
+
+// ReplaceStatusTransitions thay toàn bộ workflow trong 1 transaction (không có trạng thái nửa vời)
+func (r *postgresOrderRepository) ReplaceStatusTransitions(
+	ctx context.Context,
+	transitions []model.StatusTransition,
+	updatedBy uuid.UUID,
+) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	return database.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM order_status_transitions`); err != nil {
+			return fmt.Errorf("failed to clear order status transitions: %w", err)
+		}
+
+		query := `
+			INSERT INTO order_status_transitions (from_status, to_status, hooks, updated_by, updated_at)
+			VALUES ($1, $2, $3, $4, NOW())
+		`
+		for _, t := range transitions {
+			if _, err := tx.Exec(ctx, query, t.FromStatus, t.ToStatus, t.Hooks, updatedBy); err != nil {
+				return fmt.Errorf("failed to insert order status transition: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// =====================================================
+// ORDER CANCELLATION RULES
+// =====================================================
+
+// ListCancellationRules lists configured rules (phạm vi thiếu → service dùng rule mặc định)
+func (r *postgresOrderRepository) ListCancellationRules(ctx context.Context) ([]model.CancellationRule, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT scope, max_status, window_hours, updated_by, updated_at
+		FROM order_cancellation_rules
+		ORDER BY scope
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order cancellation rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.CancellationRule{}
+	for rows.Next() {
+		var rule model.CancellationRule
+		if err := rows.Scan(&rule.Scope, &rule.MaxStatus, &rule.WindowHours, &rule.UpdatedBy, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order cancellation rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating order cancellation rules: %w", rows.Err())
+	}
+
+	return rules, nil
+}
+
+// UpsertCancellationRules lưu rule theo phạm vi trong 1 transaction
+func (r *postgresOrderRepository) UpsertCancellationRules(
+	ctx context.Context,
+	rules []model.CancellationRule,
+	updatedBy uuid.UUID,
+) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	return database.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO order_cancellation_rules (scope, max_status, window_hours, updated_by, updated_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (scope) DO UPDATE
+			SET max_status = EXCLUDED.max_status,
+			    window_hours = EXCLUDED.window_hours,
+			    updated_by = EXCLUDED.updated_by,
+			    updated_at = NOW()
+		`
+		for _, rule := range rules {
+			if _, err := tx.Exec(ctx, query, rule.Scope, rule.MaxStatus, rule.WindowHours, updatedBy); err != nil {
+				return fmt.Errorf("failed to upsert order cancellation rule: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *postgresOrderRepository) IsFlashSaleOrder(ctx context.Context, orderID uuid.UUID) (bool, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM flash_sale_queue WHERE order_id = $1)`, orderID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check flash sale order: %w", err)
+	}
+	return exists, nil
+}
+
+// =====================================================
+// PACKAGING ADOPTION REPORT
+// =====================================================
+
+// GetPackagingAdoption đếm đơn chọn đóng gói tối giản / không in hoá đơn trong khoảng created_at [from, to)
+func (r *postgresOrderRepository) GetPackagingAdoption(ctx context.Context, from, to time.Time) (*model.PackagingAdoptionReport, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE minimal_packaging),
+			COUNT(*) FILTER (WHERE no_printed_invoice),
+			COUNT(*) FILTER (WHERE minimal_packaging AND no_printed_invoice)
+		FROM orders
+		WHERE created_at >= $1 AND created_at < $2
+	`
+
+	report := &model.PackagingAdoptionReport{FromDate: from, ToDate: to}
+	err := r.pool.QueryRow(ctx, query, from, to).Scan(
+		&report.TotalOrders,
+		&report.MinimalPackagingOrders,
+		&report.NoPrintedInvoiceOrders,
+		&report.BothOrders,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get packaging adoption: %w", err)
+	}
+
+	return report, nil
+}
+
+// =====================================================
+// REPLACEMENT ORDERS
+// =====================================================
+
+func (r *postgresOrderRepository) CreateOrderReplacementWithTx(ctx context.Context, tx pgx.Tx, replacement *model.OrderReplacement) error {
+	query := `
+		INSERT INTO order_replacements (order_id, original_order_id, reason, note, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+
+	err := tx.QueryRow(ctx, query,
+		replacement.OrderID,
+		replacement.OriginalOrderID,
+		replacement.Reason,
+		replacement.Note,
+		replacement.CreatedBy,
+	).Scan(&replacement.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create order replacement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresOrderRepository) GetReplacedQuantities(ctx context.Context, originalOrderID uuid.UUID) (map[uuid.UUID]int, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT oi.book_id, SUM(oi.quantity)
+		FROM order_replacements rp
+		JOIN orders o ON o.id = rp.order_id
+		JOIN order_items oi ON oi.order_id = rp.order_id
+		WHERE rp.original_order_id = $1 AND o.status <> 'cancelled'
+		GROUP BY oi.book_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, originalOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replaced quantities: %w", err)
+	}
+	defer rows.Close()
+
+	replaced := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var bookID uuid.UUID
+		var quantity int
+		if err := rows.Scan(&bookID, &quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan replaced quantity: %w", err)
+		}
+		replaced[bookID] = quantity
+	}
+
+	return replaced, rows.Err()
+}
+
+const orderReplacementSelect = `
+	SELECT
+		rp.order_id, o.order_number, o.status,
+		rp.original_order_id, oo.order_number,
+		rp.reason, rp.note, rp.created_by, rp.created_at
+	FROM order_replacements rp
+	JOIN orders o ON o.id = rp.order_id
+	JOIN orders oo ON oo.id = rp.original_order_id
+`
+
+func scanOrderReplacement(row pgx.Row, rp *model.OrderReplacement) error {
+	return row.Scan(
+		&rp.OrderID,
+		&rp.OrderNumber,
+		&rp.OrderStatus,
+		&rp.OriginalOrderID,
+		&rp.OriginalOrderNumber,
+		&rp.Reason,
+		&rp.Note,
+		&rp.CreatedBy,
+		&rp.CreatedAt,
+	)
+}
+
+func (r *postgresOrderRepository) GetReplacementOf(ctx context.Context, orderID uuid.UUID) (*model.OrderReplacement, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var rp model.OrderReplacement
+	err := scanOrderReplacement(r.pool.QueryRow(ctx, orderReplacementSelect+` WHERE rp.order_id = $1`, orderID), &rp)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order replacement: %w", err)
+	}
+
+	return &rp, nil
+}
+
+func (r *postgresOrderRepository) ListReplacements(ctx context.Context, originalOrderID uuid.UUID) ([]model.OrderReplacement, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := orderReplacementSelect + ` WHERE rp.original_order_id = $1 ORDER BY rp.created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, originalOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order replacements: %w", err)
+	}
+	defer rows.Close()
+
+	replacements := []model.OrderReplacement{}
+	for rows.Next() {
+		var rp model.OrderReplacement
+		if err := scanOrderReplacement(rows, &rp); err != nil {
+			return nil, fmt.Errorf("failed to scan order replacement: %w", err)
+		}
+		replacements = append(replacements, rp)
+	}
+
+	return replacements, rows.Err()
+}
+
+// GetReplacementReport đơn gửi bù theo lý do trong khoảng created_at [from, to) (bỏ qua đơn gửi bù đã huỷ)
+func (r *postgresOrderRepository) GetReplacementReport(ctx context.Context, from, to time.Time) (*model.ReplacementReport, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	report := &model.ReplacementReport{FromDate: from, ToDate: to, ByReason: []model.ReplacementReasonStat{}}
+
+	// Mẫu số: đơn bán thường (không tính đơn gửi bù)
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM orders
+		WHERE created_at >= $1 AND created_at < $2 AND payment_method <> 'replacement'
+	`
+	if err := r.pool.QueryRow(ctx, totalQuery, from, to).Scan(&report.TotalOrders); err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	query := `
+		SELECT
+			rp.reason,
+			COUNT(DISTINCT rp.order_id),
+			COALESCE(SUM(oi.quantity), 0),
+			COALESCE(SUM(oi.quantity * oi.list_price), 0)
+		FROM order_replacements rp
+		JOIN orders o ON o.id = rp.order_id
+		LEFT JOIN order_items oi ON oi.order_id = rp.order_id
+		WHERE rp.created_at >= $1 AND rp.created_at < $2 AND o.status <> 'cancelled'
+		GROUP BY rp.reason
+		ORDER BY COUNT(DISTINCT rp.order_id) DESC, rp.reason
+	`
+	rows, err := r.pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replacement report: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat model.ReplacementReasonStat
+		if err := rows.Scan(&stat.Reason, &stat.Orders, &stat.Units, &stat.ListValue); err != nil {
+			return nil, fmt.Errorf("failed to scan replacement stat: %w", err)
+		}
+		report.ByReason = append(report.ByReason, stat)
+	}
+
+	return report, rows.Err()
+}
+
+// =====================================================
+// ORDER SHIPMENTS (tách đơn nhiều kho)
+// =====================================================
+
+func (r *postgresOrderRepository) CreateOrderShipmentsWithTx(ctx context.Context, tx pgx.Tx, shipments []model.OrderShipment) error {
+	batch := &pgx.Batch{}
+	query := `
+		INSERT INTO order_shipments (id, order_id, warehouse_id, shipment_number, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, shipment := range shipments {
+		batch.Queue(query,
+			shipment.ID,
+			shipment.OrderID,
+			shipment.WarehouseID,
+			shipment.ShipmentNumber,
+			shipment.Status,
+		)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for i := 0; i < len(shipments); i++ {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to create order shipment %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (r *postgresOrderRepository) ListOrderShipments(ctx context.Context, orderID uuid.UUID) ([]model.OrderShipment, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT os.id, os.order_id, os.warehouse_id, os.shipment_number, os.status, os.created_at,
+			w.name, w.code
+		FROM order_shipments os
+		JOIN warehouses w ON w.id = os.warehouse_id
+		WHERE os.order_id = $1
+		ORDER BY os.shipment_number
+	`
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order shipments: %w", err)
+	}
+	defer rows.Close()
+
+	shipments := []model.OrderShipment{}
+	for rows.Next() {
+		var shipment model.OrderShipment
+		if err := rows.Scan(
+			&shipment.ID,
+			&shipment.OrderID,
+			&shipment.WarehouseID,
+			&shipment.ShipmentNumber,
+			&shipment.Status,
+			&shipment.CreatedAt,
+			&shipment.WarehouseName,
+			&shipment.WarehouseCode,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order shipment: %w", err)
+		}
+		shipments = append(shipments, shipment)
+	}
+
+	return shipments, rows.Err()
+}
+
+// MoveOrderShipmentsWithTx chuyển mọi kiện của đơn sang kho mới (đơn 1 kiện đổi kho khi sửa địa chỉ)
+func (r *postgresOrderRepository) MoveOrderShipmentsWithTx(ctx context.Context, tx pgx.Tx, orderID, warehouseID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `UPDATE order_shipments SET warehouse_id = $1 WHERE order_id = $2`, warehouseID, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to move order shipments: %w", err)
+	}
+	return nil
+}
+
+// =====================================================
+// SALES CHANNELS
+// =====================================================
+
+const salesChannelColumns = `
+	code, name, channel_type, is_active, payment_methods, shipping_carriers,
+	account_user_id, stock_sync_enabled, stock_buffer, updated_by, created_at, updated_at
+`
+
+func scanSalesChannel(row pgx.Row, channel *model.SalesChannel) error {
+	return row.Scan(
+		&channel.Code,
+		&channel.Name,
+		&channel.Type,
+		&channel.IsActive,
+		&channel.PaymentMethods,
+		&channel.ShippingCarriers,
+		&channel.AccountUserID,
+		&channel.StockSyncEnabled,
+		&channel.StockBuffer,
+		&channel.UpdatedBy,
+		&channel.CreatedAt,
+		&channel.UpdatedAt,
+	)
+}
+
+func (r *postgresOrderRepository) GetSalesChannel(ctx context.Context, code string) (*model.SalesChannel, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var channel model.SalesChannel
+	query := `SELECT ` + salesChannelColumns + ` FROM sales_channels WHERE code = $1`
+	if err := scanSalesChannel(r.pool.QueryRow(ctx, query, code), &channel); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrChannelNotFound
+		}
+		return nil, fmt.Errorf("failed to get sales channel: %w", err)
+	}
+	return &channel, nil
+}
+
+func (r *postgresOrderRepository) ListSalesChannels(ctx context.Context) ([]model.SalesChannel, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + salesChannelColumns + ` FROM sales_channels ORDER BY channel_type, code`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sales channels: %w", err)
+	}
+	defer rows.Close()
+
+	channels := []model.SalesChannel{}
+	for rows.Next() {
+		var channel model.SalesChannel
+		if err := scanSalesChannel(rows, &channel); err != nil {
+			return nil, fmt.Errorf("failed to scan sales channel: %w", err)
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, rows.Err()
+}
+
+// UpdateSalesChannel lưu cấu hình kênh (name, is_active, payment_methods, shipping_carriers, account_user_id, stock sync)
+func (r *postgresOrderRepository) UpdateSalesChannel(ctx context.Context, channel *model.SalesChannel, updatedBy uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE sales_channels
+		SET name = $2,
+		    is_active = $3,
+		    payment_methods = $4,
+		    shipping_carriers = $5,
+		    account_user_id = $6,
+		    stock_sync_enabled = $7,
+		    stock_buffer = $8,
+		    updated_by = $9
+		WHERE code = $1
+		RETURNING updated_by, updated_at
+	`
+	err := r.pool.QueryRow(ctx, query,
+		channel.Code,
+		channel.Name,
+		channel.IsActive,
+		channel.PaymentMethods,
+		channel.ShippingCarriers,
+		channel.AccountUserID,
+		channel.StockSyncEnabled,
+		channel.StockBuffer,
+		updatedBy,
+	).Scan(&channel.UpdatedBy, &channel.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrChannelNotFound
+		}
+		return fmt.Errorf("failed to update sales channel: %w", err)
+	}
+	return nil
+}
+
+// GetOrderByExternalID đơn sàn đã nhận theo mã đơn bên sàn
+func (r *postgresOrderRepository) GetOrderByExternalID(ctx context.Context, channel, externalOrderID string) (*model.Order, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	var orderID uuid.UUID
+	err := r.pool.QueryRow(ctx,
+		`SELECT id FROM orders WHERE channel = $1 AND external_order_id = $2`,
+		channel, externalOrderID,
+	).Scan(&orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order by external id: %w", err)
+	}
+
+	return r.GetOrderByID(ctx, orderID)
+}
+
+// FindBookIDsBySKU map SKU sàn (= ISBN) → book id; SKU không khớp sách đang bán thì không có trong map
+func (r *postgresOrderRepository) FindBookIDsBySKU(ctx context.Context, skus []string) (map[string]uuid.UUID, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT isbn, id FROM books WHERE isbn = ANY($1) AND is_active = true AND deleted_at IS NULL`,
+		skus,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find books by sku: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]uuid.UUID, len(skus))
+	for rows.Next() {
+		var sku string
+		var bookID uuid.UUID
+		if err := rows.Scan(&sku, &bookID); err != nil {
+			return nil, fmt.Errorf("failed to scan book sku: %w", err)
+		}
+		result[sku] = bookID
+	}
+
+	return result, rows.Err()
+}
+
+// =====================================================
+// MARKETPLACE STOCK SYNC
+// =====================================================
+
+// ListMarketplaceListings tồn + giá hiện tại của sách (SKU = ISBN) kèm lần đẩy trước lên kênh
+// bookIDs rỗng = toàn catalog (reconcile), phân trang keyset theo book id
+// Sách ngừng bán / đã xoá nhưng từng đẩy lên kênh → available = 0 để sàn ẩn listing
+func (r *postgresOrderRepository) ListMarketplaceListings(ctx context.Context, channel string, bookIDs []uuid.UUID, afterBookID uuid.UUID, limit int) ([]model.MarketplaceListing, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	conditions := []string{
+		"b.isbn IS NOT NULL",
+		"b.isbn <> ''",
+		"((b.is_active = true AND b.deleted_at IS NULL) OR s.book_id IS NOT NULL)",
+		"b.id > $2",
+	}
+	args := []interface{}{channel, afterBookID, limit}
+	if len(bookIDs) > 0 {
+		conditions = append(conditions, "b.id = ANY($4)")
+		args = append(args, bookIDs)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			b.id,
+			b.isbn,
+			CASE WHEN b.is_active = true AND b.deleted_at IS NULL THEN COALESCE(bts.available, 0) ELSE 0 END,
+			b.price,
+			s.synced_quantity,
+			s.synced_price,
+			s.status
+		FROM books b
+		LEFT JOIN books_total_stock bts ON bts.book_id = b.id
+		LEFT JOIN marketplace_listing_syncs s ON s.book_id = b.id AND s.channel = $1
+		WHERE %s
+		ORDER BY b.id
+		LIMIT $3
+	`, strings.Join(conditions, " AND "))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list marketplace listings: %w", err)
+	}
+	defer rows.Close()
+
+	listings := []model.MarketplaceListing{}
+	for rows.Next() {
+		var l model.MarketplaceListing
+		if err := rows.Scan(
+			&l.BookID,
+			&l.SKU,
+			&l.Available,
+			&l.Price,
+			&l.SyncedQuantity,
+			&l.SyncedPrice,
+			&l.Status,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan marketplace listing: %w", err)
+		}
+		listings = append(listings, l)
+	}
+
+	return listings, rows.Err()
+}
+
+// SaveMarketplaceListingSyncs ghi kết quả 1 lô đẩy lên sàn
+// Đẩy lỗi / SKU chưa đăng bán: giữ nguyên giá trị đã đẩy thành công trước đó
+func (r *postgresOrderRepository) SaveMarketplaceListingSyncs(ctx context.Context, syncs []model.MarketplaceListingSync) error {
+	if len(syncs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO marketplace_listing_syncs (
+			channel, book_id, sku, synced_quantity, synced_price, status, last_error, attempted_at, synced_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (channel, book_id) DO UPDATE SET
+			sku = EXCLUDED.sku,
+			synced_quantity = COALESCE(EXCLUDED.synced_quantity, marketplace_listing_syncs.synced_quantity),
+			synced_price = COALESCE(EXCLUDED.synced_price, marketplace_listing_syncs.synced_price),
+			status = EXCLUDED.status,
+			last_error = EXCLUDED.last_error,
+			attempted_at = EXCLUDED.attempted_at,
+			synced_at = COALESCE(EXCLUDED.synced_at, marketplace_listing_syncs.synced_at)
+	`
+
+	batch := &pgx.Batch{}
+	for _, sync := range syncs {
+		var quantity *int
+		var price interface{}
+		var syncedAt *time.Time
+		if sync.Status == model.ListingSyncStatusSynced {
+			quantity, price, syncedAt = &sync.Quantity, sync.Price, &sync.At
+		}
+		batch.Queue(query,
+			sync.Channel,
+			sync.BookID,
+			sync.SKU,
+			quantity,
+			price,
+			sync.Status,
+			sync.LastError,
+			sync.At,
+			syncedAt,
+		)
+	}
+
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for i := 0; i < len(syncs); i++ {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to save marketplace listing sync %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ListUnpaidOrdersCreatedBefore đơn pending/confirmed chưa thanh toán, đặt trước mốc before
+// Phân trang keyset theo order id; chỉ các phương thức giữ hàng chờ thanh toán online
+func (r *postgresOrderRepository) ListUnpaidOrdersCreatedBefore(ctx context.Context, paymentMethods []string, before time.Time, afterID uuid.UUID, limit int) ([]model.UnpaidOrderRef, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, order_number, user_id, payment_method, created_at
+		FROM orders
+		WHERE status IN ($1, $2)
+		  AND payment_status <> $3
+		  AND payment_method = ANY($4)
+		  AND created_at < $5
+		  AND id > $6
+		ORDER BY id
+		LIMIT $7
+	`, model.OrderStatusPending, model.OrderStatusConfirmed, model.PaymentStatusPaid,
+		paymentMethods, before, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unpaid orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []model.UnpaidOrderRef{}
+	for rows.Next() {
+		var o model.UnpaidOrderRef
+		if err := rows.Scan(&o.ID, &o.OrderNumber, &o.UserID, &o.PaymentMethod, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan unpaid order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, rows.Err()
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+	"time"
+)
+
+// =====================================================
+// CUSTOMER ACTIVITY (lịch sử đơn trong trang tài khoản)
+// =====================================================
+// Ghi outbox cùng transaction đổi trạng thái: đơn đã commit thì feed chắc chắn có event
+
+// orderActivityMessages task analytics:record_activity cho 1 event của đơn
+// Trả slice để append thẳng vào outbox.AddWithTx; lỗi build chỉ log (không chặn đổi trạng thái)
+func orderActivityMessages(order *model.Order, eventType string, properties map[string]interface{}) []outbox.Message {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	properties["order_number"] = order.OrderNumber
+
+	orderID := order.ID
+	message, err := outbox.NewMessage(shared.TypeRecordActivity, analyticsModel.RecordActivityPayload{
+		Activity: analyticsModel.ActivityEvent{
+			UserID:     order.UserID,
+			Domain:     analyticsModel.ActivityDomainOrder,
+			EventType:  eventType,
+			OrderID:    &orderID,
+			Properties: properties,
+			OccurredAt: time.Now(),
+		},
+	}, shared.QueueAnalytics)
+	if err != nil {
+		logger.Error("Failed to build order activity outbox message", err)
+		return nil
+	}
+	return []outbox.Message{message.WithMaxRetry(2)}
+}
+
+// orderStatusActivityMessages đổi trạng thái: huỷ là event riêng để feed hiển thị lý do
+func orderStatusActivityMessages(order *model.Order, fromStatus, toStatus string, reason *string) []outbox.Message {
+	properties := map[string]interface{}{
+		"from_status": fromStatus,
+		"to_status":   toStatus,
+	}
+	eventType := analyticsModel.ActivityOrderStatusChanged
+	if toStatus == model.OrderStatusCancelled {
+		eventType = analyticsModel.ActivityOrderCancelled
+		if reason != nil {
+			properties["reason"] = *reason
+		}
+	}
+	return orderActivityMessages(order, eventType, properties)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// ORDER CANCELLATION RULES
+// =====================================================
+
+// Rule nạp lúc khởi động, reload định kỳ để admin sửa trên 1 instance thì instance khác cũng thấy
+const cancellationPolicyTTL = time.Minute
+
+// LoadCancellationPolicy nạp + kiểm tra rule huỷ đơn (gọi lúc khởi động, lỗi thì không start)
+func (s *orderService) LoadCancellationPolicy(ctx context.Context) error {
+	rules, err := s.orderRepo.ListCancellationRules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load order cancellation rules: %w", err)
+	}
+	if err := model.ValidateCancellationRules(rules); err != nil {
+		return fmt.Errorf("invalid order cancellation rules: %w", err)
+	}
+
+	s.setCancellationPolicy(model.NewCancellationPolicy(rules))
+	return nil
+}
+
+func (s *orderService) setCancellationPolicy(policy model.CancellationPolicy) {
+	s.cancelMu.Lock()
+	s.cancelPolicy = &policy
+	s.cancelLoadedAt = time.Now()
+	s.cancelMu.Unlock()
+}
+
+// cancellationPolicy policy đang cache, hết TTL thì reload (reload lỗi → dùng bản cũ)
+func (s *orderService) cancellationPolicy(ctx context.Context) model.CancellationPolicy {
+	s.cancelMu.RLock()
+	policy, loadedAt := s.cancelPolicy, s.cancelLoadedAt
+	s.cancelMu.RUnlock()
+
+	if policy != nil && time.Since(loadedAt) < cancellationPolicyTTL {
+		return *policy
+	}
+
+	if err := s.LoadCancellationPolicy(ctx); err != nil {
+		logger.Info("Failed to reload order cancellation rules, using cached rules", map[string]interface{}{
+			"error": err.Error(),
+		})
+		if policy != nil {
+			return *policy
+		}
+		return model.DefaultCancellationPolicy()
+	}
+
+	s.cancelMu.RLock()
+	defer s.cancelMu.RUnlock()
+	return *s.cancelPolicy
+}
+
+// cancellationRuleFor rule áp cho đơn (đơn flash sale → rule flash_sale)
+func (s *orderService) cancellationRuleFor(ctx context.Context, order *model.Order) (model.CancellationRule, error) {
+	isFlashSale, err := s.orderRepo.IsFlashSaleOrder(ctx, order.ID)
+	if err != nil {
+		return model.CancellationRule{}, err
+	}
+	return s.cancellationPolicy(ctx).RuleFor(isFlashSale), nil
+}
+
+// attachCancellation điền cancellable / cancellable_until cho order detail
+func (s *orderService) attachCancellation(ctx context.Context, order *model.Order, resp *model.OrderDetailResponse) error {
+	rule, err := s.cancellationRuleFor(ctx, order)
+	if err != nil {
+		return err
+	}
+	if rule.CheckCancellable(order, time.Now()) != nil {
+		return nil
+	}
+	resp.Cancellable = true
+	resp.CancellableUntil = rule.CancellableUntil(order)
+	return nil
+}
+
+func (s *orderService) GetCancellationRules(ctx context.Context) (*model.CancellationRulesResponse, error) {
+	return &model.CancellationRulesResponse{
+		Scopes:   model.CancellationScopes,
+		Statuses: model.CancellableStatuses,
+		Rules:    s.cancellationPolicy(ctx).Rules(),
+	}, nil
+}
+
+// UpdateCancellationRules lưu rule theo phạm vi rồi áp dụng ngay trên instance hiện tại
+func (s *orderService) UpdateCancellationRules(
+	ctx context.Context,
+	adminID uuid.UUID,
+	req model.UpdateCancellationRulesRequest,
+) (*model.CancellationRulesResponse, error) {
+	rules := make([]model.CancellationRule, 0, len(req.Rules))
+	for _, r := range req.Rules {
+		rules = append(rules, model.CancellationRule{
+			Scope:       r.Scope,
+			MaxStatus:   r.MaxStatus,
+			WindowHours: r.WindowHours,
+		})
+	}
+
+	if err := model.ValidateCancellationRules(rules); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidCancelRules, err.Error(), model.ErrInvalidCancelRules)
+	}
+
+	if err := s.orderRepo.UpsertCancellationRules(ctx, rules, adminID); err != nil {
+		return nil, err
+	}
+	if err := s.LoadCancellationPolicy(ctx); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Order cancellation rules updated", map[string]interface{}{
+		"admin_id": adminID.String(),
+		"rules":    len(rules),
+	})
+
+	return s.GetCancellationRules(ctx)
+}
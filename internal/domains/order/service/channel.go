@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
+
+	addressModel "bookstore-backend/internal/domains/address/model"
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/database"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+)
+
+// =====================================================
+// SALES CHANNELS (web / app / marketplace)
+// =====================================================
+
+// getSalesChannel cấu hình kênh, không có → ORD024
+func (s *orderService) getSalesChannel(ctx context.Context, code string) (*model.SalesChannel, error) {
+	channel, err := s.orderRepo.GetSalesChannel(ctx, code)
+	if err != nil {
+		if errors.Is(err, model.ErrChannelNotFound) {
+			return nil, model.NewOrderError(model.ErrCodeChannelNotFound, fmt.Sprintf("Sales channel '%s' not found", code), err)
+		}
+		return nil, err
+	}
+	return channel, nil
+}
+
+// resolveCheckoutChannel kênh của đơn checkout trực tiếp (web / app):
+// kênh phải đang bật, không phải kênh sàn (đơn sàn chỉ vào qua ingestion) hay offline và cho phép phương thức thanh toán đã chọn.
+// pay_link (CSKH đặt thay khách) không theo cấu hình kênh.
+func (s *orderService) resolveCheckoutChannel(ctx context.Context, code, paymentMethod string) (*model.SalesChannel, error) {
+	code = model.NormalizeChannel(code)
+	if !model.ValidChannelCode(code) {
+		return nil, model.NewOrderError(model.ErrCodeInvalidChannel, fmt.Sprintf("Invalid sales channel '%s'", code), model.ErrInvalidChannel)
+	}
+
+	channel, err := s.getSalesChannel(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	// offline: đơn chỉ vào qua import CSV của admin
+	if !channel.IsActive || channel.IsMarketplace() || channel.Code == model.ChannelOffline {
+		return nil, model.NewOrderError(
+			model.ErrCodeInvalidChannel,
+			fmt.Sprintf("Checkout is not available on channel '%s'", code),
+			model.ErrInvalidChannel,
+		)
+	}
+	if paymentMethod != model.PaymentMethodPayLink && !channel.AllowsPaymentMethod(paymentMethod) {
+		return nil, model.NewOrderError(
+			model.ErrCodeInvalidPaymentMethod,
+			fmt.Sprintf("Payment method '%s' is not available on channel '%s'", paymentMethod, code),
+			model.ErrInvalidPaymentMethod,
+		)
+	}
+	return channel, nil
+}
+
+// ListSalesChannels - admin: cấu hình các kênh
+func (s *orderService) ListSalesChannels(ctx context.Context) ([]model.SalesChannel, error) {
+	return s.orderRepo.ListSalesChannels(ctx)
+}
+
+// UpdateSalesChannel - admin: bật / tắt kênh, phương thức thanh toán, hãng vận chuyển, tài khoản đứng tên đơn sàn
+func (s *orderService) UpdateSalesChannel(
+	ctx context.Context,
+	adminID uuid.UUID,
+	code string,
+	req model.UpdateSalesChannelRequest,
+) (*model.SalesChannel, error) {
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidChannel, err.Error(), model.ErrInvalidChannel)
+	}
+
+	channel, err := s.getSalesChannel(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Apply(channel); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidChannel, err.Error(), model.ErrInvalidChannel)
+	}
+
+	if err := s.orderRepo.UpdateSalesChannel(ctx, channel, adminID); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Sales channel updated", map[string]interface{}{
+		"admin_id":          adminID.String(),
+		"channel":           channel.Code,
+		"is_active":         channel.IsActive,
+		"payment_methods":   channel.PaymentMethods,
+		"shipping_carriers": channel.ShippingCarriers,
+	})
+	return channel, nil
+}
+
+// =====================================================
+// MARKETPLACE ORDER INGESTION
+// =====================================================
+
+// IngestMarketplaceOrder - nhận đơn Shopee / Lazada do connector đẩy về.
+// Khác createOrderFromItems:
+//   - SKU sàn (ISBN) → sách; đơn giá, phí ship, voucher shop theo sàn (không áp giá niêm yết / promo)
+//   - Đứng tên tài khoản hệ thống của kênh, người nhận lưu vào sổ địa chỉ của tài khoản đó
+//   - Sàn đã thu tiền: payment_method = marketplace, paid + confirmed ngay, không auto-release
+//   - Idempotent theo (channel, external_order_id): sàn gửi lại → trả đơn đã tạo, Duplicate = true
+//
+// Reserve tồn + tách kiện theo kho đi chung planFulfillment / reserveFulfillmentWithTx với đơn web.
+func (s *orderService) IngestMarketplaceOrder(
+	ctx context.Context,
+	channelCode string,
+	req model.MarketplaceOrderRequest,
+) (*model.MarketplaceOrderResponse, error) {
+	// 1. Validate request
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid marketplace order", err)
+	}
+
+	// 2. Kênh sàn đang bật + đã gán tài khoản đứng tên đơn
+	channel, err := s.getSalesChannel(ctx, strings.ToLower(channelCode))
+	if err != nil {
+		return nil, err
+	}
+	if !channel.IsMarketplace() || !channel.IsActive {
+		return nil, model.NewOrderError(
+			model.ErrCodeInvalidChannel,
+			fmt.Sprintf("Channel '%s' does not accept marketplace orders", channel.Code),
+			model.ErrInvalidChannel,
+		)
+	}
+	if channel.AccountUserID == nil {
+		return nil, model.NewOrderError(
+			model.ErrCodeInvalidChannel,
+			fmt.Sprintf("Channel '%s' has no account_user_id configured", channel.Code),
+			model.ErrInvalidChannel,
+		)
+	}
+	accountID := *channel.AccountUserID
+
+	// 3. Sàn gửi lại đơn đã nhận → trả đơn cũ, không reserve lần 2
+	if existing, err := s.existingMarketplaceOrder(ctx, channel.Code, req.ExternalOrderID); err != nil || existing != nil {
+		return existing, err
+	}
+
+	// 4. SKU → sách
+	bookIDsBySKU, err := s.orderRepo.FindBookIDsBySKU(ctx, req.SKUs())
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	items := make([]model.CreateOrderItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		bookID, ok := bookIDsBySKU[item.SKU]
+		if !ok {
+			unknown = append(unknown, item.SKU)
+			continue
+		}
+		items = append(items, model.CreateOrderItem{BookID: bookID, Quantity: item.Quantity})
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, model.NewOrderError(
+			model.ErrCodeUnknownSKU,
+			fmt.Sprintf("Unknown SKU(s): %s", strings.Join(unknown, ", ")),
+			model.ErrUnknownSKU,
+		)
+	}
+
+	// 5. Book snapshot, đơn giá theo sàn (items build 1-1 từ req.Items)
+	bookItems, err := s.validateAndFetchBookItems(ctx, items)
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid marketplace order items", err)
+	}
+	for i := range bookItems {
+		bookItems[i].Price = req.Items[i].UnitPrice
+		bookItems[i].TierDiscountPercent = nil
+	}
+	subtotal := s.calculateItemsSubtotal(bookItems)
+	discount := decimal.Min(req.SellerDiscount, subtotal)
+	total := subtotal.Sub(discount).Add(req.ShippingFee)
+
+	// 6. Người nhận → sổ địa chỉ của tài khoản kênh (orders.address_id NOT NULL)
+	address, err := s.addressRepo.Create(ctx, &addressModel.Address{
+		UserID:        accountID,
+		RecipientName: strings.TrimSpace(req.Recipient.Name),
+		Phone:         strings.TrimSpace(req.Recipient.Phone),
+		Province:      strings.TrimSpace(req.Recipient.Province),
+		District:      strings.TrimSpace(req.Recipient.District),
+		Ward:          strings.TrimSpace(req.Recipient.Ward),
+		Street:        strings.TrimSpace(req.Recipient.Street),
+		AddressType:   addressModel.AddressTypeOther,
+		Notes:         fmt.Sprintf("%s order %s", channel.Name, req.ExternalOrderID),
+	})
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Invalid recipient address", err)
+	}
+
+	// 7. Chọn warehouse (1 kho, không đủ thì tách kiện)
+	plan, err := s.planFulfillment(ctx, address, bookItems)
+	if err != nil {
+		return nil, err
+	}
+	selectedWarehouseID := plan.PrimaryWarehouseID()
+
+	// 8. Bắt đầu transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	txCtx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.orderRepo.BeginTx(txCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.orderRepo.RollbackTx(txCtx, tx)
+
+	// 9. Reserve inventory
+	if err := s.reserveFulfillmentWithTx(txCtx, tx, plan, bookItems, accountID); err != nil {
+		return nil, err
+	}
+
+	// 10. Insert order (sàn đã thu tiền)
+	orderID := uuid.New()
+	externalID := req.ExternalOrderID
+	order := &model.Order{
+		ID:              orderID,
+		UserID:          accountID,
+		AddressID:       address.ID,
+		WarehouseID:     &selectedWarehouseID,
+		Subtotal:        subtotal,
+		ShippingFee:     req.ShippingFee,
+		DiscountAmount:  discount,
+		Total:           total,
+		PaymentMethod:   model.PaymentMethodMarketplace,
+		PaymentStatus:   model.PaymentStatusPaid,
+		Status:          model.OrderStatusConfirmed,
+		CustomerNote:    req.BuyerNote,
+		Version:         0,
+		Metadata:        req.Metadata,
+		Channel:         channel.Code,
+		ExternalOrderID: &externalID,
+	}
+	if err := s.orderRepo.CreateOrderWithTx(txCtx, tx, order); err != nil {
+		if errors.Is(err, model.ErrExternalOrderExists) {
+			// Request song song của cùng đơn đã commit trước → rollback, trả đơn đó
+			s.orderRepo.RollbackTx(txCtx, tx)
+			return s.existingMarketplaceOrder(ctx, channel.Code, req.ExternalOrderID)
+		}
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	// 11. Insert kiện theo kho + order items
+	orderItems := s.buildOrderItems(orderID, bookItems)
+	if err := s.createShipmentsWithTx(txCtx, tx, plan, orderID, orderItems); err != nil {
+		return nil, err
+	}
+	if err := s.orderRepo.CreateOrderItemsWithTx(txCtx, tx, orderItems); err != nil {
+		return nil, fmt.Errorf("failed to create order items: %w", err)
+	}
+
+	// 12. Status history
+	note := fmt.Sprintf("Imported from %s order %s", channel.Name, req.ExternalOrderID)
+	statusHistory := &model.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: nil,
+		ToStatus:   order.Status,
+		ChangedBy:  nil,
+		Notes:      &note,
+	}
+	if err := s.orderRepo.CreateOrderStatusHistoryWithTx(txCtx, tx, statusHistory); err != nil {
+		return nil, fmt.Errorf("failed to create order status history: %w", err)
+	}
+
+	// 13. Commit
+	if err := s.orderRepo.CommitTx(txCtx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// 14. Jobs sau commit (không auto-release: sàn đã thu tiền)
+	for _, item := range orderItems {
+		payload := shared.InventorySyncPayload{
+			BookID: item.BookID.String(),
+			Source: "SALE",
+		}
+		if b, err := json.Marshal(payload); err == nil {
+			task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
+			if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
+				logger.Error("Failed to enqueue InventorySyncJob after marketplace order", err)
+			}
+		}
+	}
+
+	logger.Info("Marketplace order ingested", map[string]interface{}{
+		"order_id":          order.ID,
+		"channel":           channel.Code,
+		"external_order_id": req.ExternalOrderID,
+		"shipments":         len(plan.Shipments),
+	})
+
+	return &model.MarketplaceOrderResponse{
+		OrderID:         order.ID,
+		OrderNumber:     order.OrderNumber,
+		Channel:         order.Channel,
+		ExternalOrderID: externalID,
+		Total:           order.Total,
+		Status:          order.Status,
+	}, nil
+}
+
+// existingMarketplaceOrder đơn sàn đã nhận trước đó (nil nếu chưa có)
+func (s *orderService) existingMarketplaceOrder(ctx context.Context, channel, externalOrderID string) (*model.MarketplaceOrderResponse, error) {
+	order, err := s.orderRepo.GetOrderByExternalID(ctx, channel, externalOrderID)
+	if err != nil {
+		if errors.Is(err, model.ErrOrderNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &model.MarketplaceOrderResponse{
+		OrderID:         order.ID,
+		OrderNumber:     order.OrderNumber,
+		Channel:         order.Channel,
+		ExternalOrderID: externalOrderID,
+		Total:           order.Total,
+		Status:          order.Status,
+		Duplicate:       true,
+	}, nil
+}
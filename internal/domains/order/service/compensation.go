@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/pkg/database"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// CHECKOUT COMPENSATION
+// =====================================================
+// Bước bù trừ của checkout saga (cart domain điều phối):
+// - CompensateCheckout: CreateOrder trả lỗi nhưng transaction có thể đã commit (timeout lúc commit,
+//   client huỷ request) → huỷ đơn đặt trước id, trả tồn đã giữ, hoàn lượt dùng promotion
+// - ReleaseExpiredReservationHolds: đối soát định kỳ đơn chưa thanh toán quá hạn mà task
+//   auto-release không chạy (worker ngừng lâu, Redis mất dữ liệu)
+
+const reservationHoldBatch = 200
+
+// CompensateCheckout implements OrderService.CompensateCheckout
+// Idempotent: đơn không tồn tại (tx đã rollback) → false; đơn đã huỷ → true, không làm gì thêm
+func (s *orderService) CompensateCheckout(ctx context.Context, orderID uuid.UUID, reason string) (bool, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, orderID)
+	if errors.Is(err, model.ErrOrderNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Status == model.OrderStatusCancelled {
+		return true, nil
+	}
+	// Khách đã trả tiền → không tự huỷ, để CSKH / refund xử lý
+	if order.IsPaymentCompleted() || order.Status != model.OrderStatusPending {
+		return false, fmt.Errorf("order %s cannot be compensated: status=%s payment_status=%s",
+			order.OrderNumber, order.Status, order.PaymentStatus)
+	}
+
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.orderRepo.RollbackTx(ctx, tx)
+
+	// 1. Huỷ đơn có điều kiện: thanh toán về cùng lúc (callback) → 0 dòng, không release
+	result, err := tx.Exec(ctx, `
+		UPDATE orders
+		SET status = $2,
+			cancellation_reason = $3,
+			cancelled_at = NOW(),
+			version = version + 1,
+			updated_at = NOW()
+		WHERE id = $1 AND status = $4 AND payment_status <> $5
+	`, orderID, model.OrderStatusCancelled, reason, model.OrderStatusPending, model.PaymentStatusPaid)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel order: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, fmt.Errorf("order %s changed during compensation", order.OrderNumber)
+	}
+
+	// 2. Trả tồn đã giữ (đơn tách kiện: release tại kho của kiện chứa dòng hàng)
+	items, err := s.orderRepo.GetOrderItemsByOrderID(ctx, orderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get order items: %w", err)
+	}
+	for _, item := range items {
+		warehouseID := item.ReservedWarehouseID(order.WarehouseID)
+		if warehouseID == nil {
+			continue
+		}
+		if err := s.inventoryRepo.ReleaseStockWithTx(ctx, tx, *warehouseID, item.BookID, item.Quantity, nil); err != nil {
+			return false, fmt.Errorf("failed to release stock for book %s: %w", item.BookID.String(), err)
+		}
+	}
+
+	// 3. Hoàn lượt dùng promotion (xoá promotion_usage của đơn, giảm current_uses)
+	reverted, err := s.promoRepo.RevertUsageWithTx(ctx, tx, orderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revert promotion usage: %w", err)
+	}
+
+	// 4. Status history (system action)
+	statusHistory := &model.OrderStatusHistory{
+		ID:         uuid.New(),
+		OrderID:    orderID,
+		FromStatus: &order.Status,
+		ToStatus:   model.OrderStatusCancelled,
+		ChangedBy:  nil,
+		Notes:      &reason,
+	}
+	if err := s.orderRepo.CreateOrderStatusHistoryWithTx(ctx, tx, statusHistory); err != nil {
+		return false, fmt.Errorf("failed to create status history: %w", err)
+	}
+
+	messages := inventorySyncMessages(orderItemBookIDs(items), "RELEASE")
+	messages = append(messages, orderStatusActivityMessages(order, order.Status, model.OrderStatusCancelled, &reason)...)
+	messages = append(messages, orderStatusPushMessages(order, model.OrderStatusCancelled, statusHistory.ID)...)
+	if err := outbox.AddWithTx(ctx, tx, messages...); err != nil {
+		return false, err
+	}
+
+	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("Checkout compensated", map[string]interface{}{
+		"order_id":            orderID,
+		"order_number":        order.OrderNumber,
+		"items_released":      len(items),
+		"promotions_reverted": reverted,
+		"reason":              reason,
+	})
+	return true, nil
+}
+
+// ReleaseExpiredReservationHolds implements OrderService.ReleaseExpiredReservationHolds
+// Huỷ (trả tồn) đơn pending chưa thanh toán của paymentMethods đặt trước mốc before, tối đa limit đơn.
+// Đơn huỷ lỗi (vừa được thanh toán / đổi trạng thái) chỉ log, lần chạy sau quét lại
+func (s *orderService) ReleaseExpiredReservationHolds(ctx context.Context, paymentMethods []string, before time.Time, limit int) (int, error) {
+	released := 0
+	afterID := uuid.Nil
+	for released < limit {
+		orders, err := s.orderRepo.ListUnpaidOrdersCreatedBefore(ctx, paymentMethods, before, afterID, reservationHoldBatch)
+		if err != nil {
+			return released, fmt.Errorf("failed to list expired unpaid orders: %w", err)
+		}
+
+		for _, o := range orders {
+			if released >= limit {
+				break
+			}
+			if err := s.CancelOrderBySystem(ctx, o.ID, "Payment window expired (reservation reconcile)", "reservation_reconcile"); err != nil {
+				logger.Info("Failed to release expired reservation hold", map[string]interface{}{
+					"order_id":     o.ID,
+					"order_number": o.OrderNumber,
+					"error":        err.Error(),
+				})
+				continue
+			}
+			released++
+		}
+
+		if len(orders) < reservationHoldBatch {
+			break
+		}
+		afterID = orders[len(orders)-1].ID
+	}
+	return released, nil
+}
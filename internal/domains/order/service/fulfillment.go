@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	addressModel "bookstore-backend/internal/domains/address/model"
+	"bookstore-backend/internal/domains/order/model"
+	whModel "bookstore-backend/internal/domains/warehouse/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// FULFILLMENT PLANNER (tách đơn nhiều kho)
+// =====================================================
+
+// fulfillmentShipment 1 kiện: kho + các dòng hàng (index trong bookItems) lấy tại kho đó
+type fulfillmentShipment struct {
+	Warehouse   whModel.Warehouse
+	ItemIndexes []int
+}
+
+type fulfillmentPlan struct {
+	Shipments []fulfillmentShipment
+}
+
+// PrimaryWarehouseID kho của kiện đầu tiên, lưu vào orders.warehouse_id
+func (p *fulfillmentPlan) PrimaryWarehouseID() uuid.UUID {
+	return p.Shipments[0].Warehouse.ID
+}
+
+// planFulfillment chọn kho cho đơn
+//
+// 1. Có 1 kho đủ hàng cho toàn bộ đơn → 1 kiện (như trước)
+// 2. Không có → tách theo kho: mỗi dòng hàng lấy trọn tại 1 kho, gom vào ít kho nhất
+// 3. Có dòng không kho nào đủ → ORD004 như cũ
+func (s *orderService) planFulfillment(
+	ctx context.Context,
+	address *addressModel.Address,
+	bookItems []bookItemData,
+) (*fulfillmentPlan, error) {
+	selectedWH, err := s.selectSingleWarehouseForOrder(ctx, address, bookItems)
+	if err == nil {
+		indexes := make([]int, len(bookItems))
+		for i := range bookItems {
+			indexes[i] = i
+		}
+		return &fulfillmentPlan{Shipments: []fulfillmentShipment{{
+			Warehouse:   selectedWH.Warehouse,
+			ItemIndexes: indexes,
+		}}}, nil
+	}
+
+	var orderErr *model.OrderError
+	if !errors.As(err, &orderErr) || orderErr.Code != model.ErrCodeInsufficientStock || len(bookItems) < 2 {
+		return nil, err
+	}
+
+	plan, splitErr := s.planSplitFulfillment(ctx, address, bookItems)
+	if splitErr != nil {
+		return nil, splitErr
+	}
+
+	logger.Info("Order split across warehouses", map[string]interface{}{
+		"items":     len(bookItems),
+		"shipments": len(plan.Shipments),
+	})
+	return plan, nil
+}
+
+// planSplitFulfillment greedy: lặp chọn kho phục vụ được nhiều dòng còn lại nhất,
+// hoà thì lấy kho ưu tiên hơn (gần hơn theo allocation_priority / kho mặc định)
+func (s *orderService) planSplitFulfillment(
+	ctx context.Context,
+	address *addressModel.Address,
+	bookItems []bookItemData,
+) (*fulfillmentPlan, error) {
+	ranked, canServe, err := s.fulfillmentCandidates(ctx, address, bookItems)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range bookItems {
+		if len(canServe[i]) == 0 {
+			return nil, model.NewOrderError(
+				model.ErrCodeInsufficientStock,
+				fmt.Sprintf("No warehouse with stock found for book: %s", item.BookID),
+				model.ErrInsufficientStock,
+			)
+		}
+	}
+
+	remaining := make(map[int]bool, len(bookItems))
+	for i := range bookItems {
+		remaining[i] = true
+	}
+
+	plan := &fulfillmentPlan{}
+	for len(remaining) > 0 {
+		var best *whModel.Warehouse
+		var bestItems []int
+		for i := range ranked {
+			var covered []int
+			for idx := range bookItems {
+				if remaining[idx] && canServe[idx][ranked[i].ID] {
+					covered = append(covered, idx)
+				}
+			}
+			if len(covered) > len(bestItems) {
+				best = &ranked[i]
+				bestItems = covered
+			}
+		}
+		if best == nil {
+			// Không xảy ra: mọi dòng đã có ít nhất 1 kho ở bước kiểm tra trên
+			return nil, model.NewOrderError(model.ErrCodeInsufficientStock, "Unable to plan fulfillment", model.ErrInsufficientStock)
+		}
+
+		plan.Shipments = append(plan.Shipments, fulfillmentShipment{Warehouse: *best, ItemIndexes: bestItems})
+		for _, idx := range bestItems {
+			delete(remaining, idx)
+		}
+	}
+
+	return plan, nil
+}
+
+// fulfillmentCandidates kho xếp theo ưu tiên + map dòng hàng → các kho đủ tồn online cho dòng đó
+func (s *orderService) fulfillmentCandidates(
+	ctx context.Context,
+	address *addressModel.Address,
+	bookItems []bookItemData,
+) ([]whModel.Warehouse, []map[uuid.UUID]bool, error) {
+	canServe := make([]map[uuid.UUID]bool, len(bookItems))
+	for i := range canServe {
+		canServe[i] = map[uuid.UUID]bool{}
+	}
+
+	// Có toạ độ: xếp theo khoảng cách đã áp allocation_priority (như chọn 1 kho)
+	if address.Latitude != 0 && address.Longitude != 0 {
+		byID := map[uuid.UUID]whModel.WarehouseWithInventory{}
+		for i, item := range bookItems {
+			list, err := s.warehouseService.ListWarehousesWithStock(ctx, item.BookID, address.Latitude, address.Longitude, item.Quantity)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to find warehouses with stock: %w", err)
+			}
+			for _, wh := range list {
+				canServe[i][wh.ID] = true
+				byID[wh.ID] = wh
+			}
+		}
+
+		candidates := make([]whModel.WarehouseWithInventory, 0, len(byID))
+		for _, wh := range byID {
+			candidates = append(candidates, wh)
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].EffectiveDistanceKm != candidates[j].EffectiveDistanceKm {
+				return candidates[i].EffectiveDistanceKm < candidates[j].EffectiveDistanceKm
+			}
+			return candidates[i].Code < candidates[j].Code
+		})
+
+		ranked := make([]whModel.Warehouse, len(candidates))
+		for i, wh := range candidates {
+			ranked[i] = wh.Warehouse
+		}
+		return ranked, canServe, nil
+	}
+
+	// Không có toạ độ: kho mặc định trước, sau đó theo allocation_priority
+	warehouses, err := s.warehouseService.ListActiveWarehouses(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+	sort.SliceStable(warehouses, func(i, j int) bool {
+		iDefault := warehouses[i].Code == model.DefaultWarehouseCode
+		jDefault := warehouses[j].Code == model.DefaultWarehouseCode
+		if iDefault != jDefault {
+			return iDefault
+		}
+		if warehouses[i].AllocationPriority != warehouses[j].AllocationPriority {
+			return warehouses[i].AllocationPriority > warehouses[j].AllocationPriority
+		}
+		return warehouses[i].Code < warehouses[j].Code
+	})
+
+	for i, item := range bookItems {
+		for _, wh := range warehouses {
+			ok, err := s.warehouseService.ValidateWarehouseHasStock(ctx, wh.ID, item.BookID, item.Quantity)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to check warehouse stock: %w", err)
+			}
+			if ok {
+				canServe[i][wh.ID] = true
+			}
+		}
+	}
+	return warehouses, canServe, nil
+}
+
+// reserveFulfillmentWithTx reserve tồn của từng dòng tại kho của kiện chứa dòng đó
+func (s *orderService) reserveFulfillmentWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	plan *fulfillmentPlan,
+	bookItems []bookItemData,
+	userID uuid.UUID,
+) error {
+	for _, shipment := range plan.Shipments {
+		for _, idx := range shipment.ItemIndexes {
+			item := bookItems[idx]
+			if err := s.inventoryRepo.ReserveStockWithTx(ctx, tx, shipment.Warehouse.ID, item.BookID, item.Quantity, &userID); err != nil {
+				return model.NewOrderError(
+					model.ErrCodeInsufficientStock,
+					fmt.Sprintf("Failed to reserve stock for book: %s", item.BookID),
+					err,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// createShipmentsWithTx ghi order_shipments và gán shipment_id cho order items (items build 1-1 từ bookItems).
+// Phải gọi trước CreateOrderItemsWithTx (FK shipment_id)
+func (s *orderService) createShipmentsWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	plan *fulfillmentPlan,
+	orderID uuid.UUID,
+	orderItems []model.OrderItem,
+) error {
+	shipments := make([]model.OrderShipment, len(plan.Shipments))
+	for i, planned := range plan.Shipments {
+		shipments[i] = model.OrderShipment{
+			ID:             uuid.New(),
+			OrderID:        orderID,
+			WarehouseID:    planned.Warehouse.ID,
+			ShipmentNumber: i + 1,
+			Status:         model.ShipmentStatusPending,
+		}
+		for _, idx := range planned.ItemIndexes {
+			shipmentID, warehouseID := shipments[i].ID, planned.Warehouse.ID
+			orderItems[idx].ShipmentID = &shipmentID
+			orderItems[idx].WarehouseID = &warehouseID
+		}
+	}
+
+	if err := s.orderRepo.CreateOrderShipmentsWithTx(ctx, tx, shipments); err != nil {
+		return fmt.Errorf("failed to create order shipments: %w", err)
+	}
+	return nil
+}
+
+// attachShipments gắn danh sách kiện theo kho vào order detail
+func (s *orderService) attachShipments(ctx context.Context, items []model.OrderItem, response *model.OrderDetailResponse) error {
+	shipments, err := s.orderRepo.ListOrderShipments(ctx, response.ID)
+	if err != nil {
+		return err
+	}
+	if len(shipments) > 0 {
+		response.Shipments = model.BuildShipmentResponses(shipments, items)
+	}
+	return nil
+}
+
+// isSplitFulfillment đơn có dòng hàng giữ tồn ở kho khác kho chính
+func isSplitFulfillment(order *model.Order, items []model.OrderItem) bool {
+	for _, item := range items {
+		warehouseID := item.ReservedWarehouseID(order.WarehouseID)
+		if warehouseID != nil && order.WarehouseID != nil && *warehouseID != *order.WarehouseID {
+			return true
+		}
+	}
+	return false
+}
@@ -2,12 +2,49 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
 
 	"bookstore-backend/internal/domains/order/model"
+	shippingModel "bookstore-backend/internal/domains/shipping/model"
+	taxModel "bookstore-backend/internal/domains/tax/model"
 )
 
+// CODCollectionRecorder ghi kiện COD đã giao + tiền đã thu (sổ cái, chờ hãng vận chuyển nộp tiền)
+// Payment domain implement (payment service import order service → không import ngược được)
+type CODCollectionRecorder interface {
+	RecordCODCollectionWithTx(
+		ctx context.Context,
+		tx pgx.Tx,
+		orderID uuid.UUID,
+		trackingNumber *string,
+		amount decimal.Decimal,
+		collectedBy *uuid.UUID,
+	) error
+}
+
+// ShipmentTrigger tạo vận đơn với hãng vận chuyển khi transition có hook trigger_shipment
+// Chưa wire → hook chỉ log bỏ qua
+type ShipmentTrigger interface {
+	TriggerShipment(ctx context.Context, orderID uuid.UUID) error
+}
+
+// ShippingQuoter báo giá phí ship với hãng theo kho gửi / địa chỉ nhận / cân nặng sách
+// Chưa wire hoặc lỗi → phí cố định của checkout policy
+type ShippingQuoter interface {
+	QuoteShippingFee(ctx context.Context, parcels []shippingModel.QuoteParcel) (decimal.Decimal, error)
+}
+
+// TaxCalculator tính thuế theo rule tỉnh / danh mục (tax_rules)
+// Chưa wire → thuế 0% như trước khi có cấu hình thuế
+type TaxCalculator interface {
+	Calculate(ctx context.Context, req taxModel.CalculateRequest) (*taxModel.Result, error)
+}
+
 // =====================================================
 // ORDER SERVICE INTERFACE
 // =====================================================
@@ -24,6 +61,9 @@ type OrderService interface {
 	// Cancel order (by user)
 	CancelOrder(ctx context.Context, orderID uuid.UUID, userID uuid.UUID, req model.CancelOrderRequest) error
 
+	// Modify shipping address / note (by user) trong cửa sổ sau khi đặt; đổi tỉnh → chọn lại kho
+	ModifyOrder(ctx context.Context, orderID uuid.UUID, userID uuid.UUID, req model.ModifyOrderRequest) (*model.OrderDetailResponse, error)
+
 	// Update order status (admin only)
 	UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, userID uuid.UUID, req model.UpdateOrderStatusRequest) error
 
@@ -32,11 +72,74 @@ type OrderService interface {
 
 	// Admin: List all orders
 	ListAllOrders(ctx context.Context, req model.ListOrdersRequest) (*model.ListOrdersResponse, error)
+	// Admin: order detail kèm lịch sử liên lạc với khách (email, SMS, cuộc gọi)
+	GetAdminOrderDetail(ctx context.Context, orderID uuid.UUID) (*model.AdminOrderDetailResponse, error)
+	// Admin: staff ghi lại liên lạc ngoài hệ thống (vd: cuộc gọi xác minh)
+	LogCommunication(ctx context.Context, orderID uuid.UUID, staffID uuid.UUID, req model.LogCommunicationRequest) (*model.OrderCommunication, error)
 	// GetOrderByIDWithoutUser gets order without user verification (for system operations)
 	GetOrderByIDWithoutUser(ctx context.Context, orderID uuid.UUID) (*model.OrderDetailResponse, error)
 
 	// CancelOrderBySystem cancels order via system action (payment timeout, fraud, etc.)
 	CancelOrderBySystem(ctx context.Context, orderID uuid.UUID, reason string, source string) error
+	// CompensateCheckout bù trừ checkout lỗi: huỷ đơn (id đặt trước) + trả tồn + hoàn lượt promotion
+	// false, nil nếu đơn không tồn tại (transaction tạo đơn đã rollback)
+	CompensateCheckout(ctx context.Context, orderID uuid.UUID, reason string) (bool, error)
+	// ReleaseExpiredReservationHolds huỷ đơn chưa thanh toán quá hạn còn giữ hàng (đối soát định kỳ)
+	ReleaseExpiredReservationHolds(ctx context.Context, paymentMethods []string, before time.Time, limit int) (int, error)
 	// Get order by number
 	GetOrderByNumber(ctx context.Context, orderNumber string, userID uuid.UUID) (*model.OrderDetailResponse, error)
+
+	// TrackOrderPublic tra cứu đơn không cần đăng nhập (mã đơn + 4 số cuối SĐT người nhận)
+	// Sai mã đơn hoặc sai SĐT đều trả ErrOrderNotFound để không lộ mã đơn tồn tại
+	TrackOrderPublic(ctx context.Context, req model.TrackOrderRequest, locale string) (*model.PublicTrackingResponse, error)
+
+	// GetPackingSlip builds packing slip for shipment (gift receipt hides prices)
+	GetPackingSlip(ctx context.Context, orderID uuid.UUID) (*model.PackingSlipResponse, error)
+	// GetInvoice builds invoice for buyer (userID) or admin (userID = nil)
+	GetInvoice(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID) (*model.InvoiceResponse, error)
+	// Admin: tỷ lệ chọn đóng gói tối giản / không in hoá đơn
+	GetPackagingAdoptionReport(ctx context.Context, req model.PackagingReportRequest) (*model.PackagingAdoptionReport, error)
+
+	// CreateOrderFromQuote creates invoice-terms order from approved B2B quote (no cart caps)
+	CreateOrderFromQuote(ctx context.Context, userID uuid.UUID, req model.CreateQuoteOrderRequest) (*model.CreateOrderResponse, error)
+	// MarkInvoicePaid records payment of B2B invoice order (admin)
+	MarkInvoicePaid(ctx context.Context, orderID uuid.UUID) error
+
+	// CreateFlashSaleOrder creates single-book order at flash sale price (token already verified)
+	CreateFlashSaleOrder(ctx context.Context, userID uuid.UUID, req model.CreateFlashSaleOrderRequest) (*model.CreateOrderResponse, error)
+
+	// CreateReplacementOrder creates zero-priced order resending items of source order (admin action / ticket compensation)
+	CreateReplacementOrder(ctx context.Context, req model.CreateReplacementOrderRequest) (*model.CreateOrderResponse, error)
+	// Admin: đơn gửi bù theo lý do
+	GetReplacementReport(ctx context.Context, req model.ReplacementReportRequest) (*model.ReplacementReport, error)
+
+	// Admin: workflow trạng thái đơn (order_status_transitions)
+	GetStatusWorkflow(ctx context.Context) (*model.StatusWorkflowResponse, error)
+	UpdateStatusWorkflow(ctx context.Context, adminID uuid.UUID, req model.UpdateStatusWorkflowRequest) (*model.StatusWorkflowResponse, error)
+	// ValidateStatusWorkflow kiểm tra workflow đang lưu lúc khởi động (reachability) - lỗi thì không start
+	ValidateStatusWorkflow(ctx context.Context) error
+
+	// Admin: rule khách tự huỷ đơn (order_cancellation_rules)
+	GetCancellationRules(ctx context.Context) (*model.CancellationRulesResponse, error)
+	UpdateCancellationRules(ctx context.Context, adminID uuid.UUID, req model.UpdateCancellationRulesRequest) (*model.CancellationRulesResponse, error)
+	// LoadCancellationPolicy nạp + kiểm tra rule huỷ đơn lúc khởi động - lỗi thì không start
+	LoadCancellationPolicy(ctx context.Context) error
+
+	// Admin: cấu hình kênh bán (phương thức thanh toán, hãng vận chuyển theo kênh)
+	ListSalesChannels(ctx context.Context) ([]model.SalesChannel, error)
+	UpdateSalesChannel(ctx context.Context, adminID uuid.UUID, code string, req model.UpdateSalesChannelRequest) (*model.SalesChannel, error)
+	// IngestMarketplaceOrder nhận đơn Shopee / Lazada vào luồng reserve tồn + fulfillment (idempotent theo mã đơn sàn)
+	IngestMarketplaceOrder(ctx context.Context, channelCode string, req model.MarketplaceOrderRequest) (*model.MarketplaceOrderResponse, error)
+
+	// Admin: import đơn offline / lịch sử từ CSV (parse + validate ngay, tạo đơn trong job order:import_orders)
+	CreateOrderImport(ctx context.Context, adminID uuid.UUID, fileName string, file io.Reader) (*model.OrderImportResponse, error)
+	GetOrderImport(ctx context.Context, importID uuid.UUID, rowStatus string) (*model.OrderImportResponse, error)
+	// ProcessOrderImport tạo đơn cho các dòng còn pending (worker); chạy lại an toàn
+	ProcessOrderImport(ctx context.Context, importID uuid.UUID) error
+
+	// Admin / staff kho: cập nhật trạng thái hàng loạt từ CSV (chuyển trạng thái trong job order:bulk_update_status)
+	CreateOrderStatusImport(ctx context.Context, adminID uuid.UUID, fileName string, file io.Reader) (*model.OrderStatusImportResponse, error)
+	GetOrderStatusImport(ctx context.Context, importID uuid.UUID, rowStatus string) (*model.OrderStatusImportResponse, error)
+	// ProcessOrderStatusImport chuyển trạng thái các dòng còn pending (worker); chạy lại an toàn
+	ProcessOrderStatusImport(ctx context.Context, importID uuid.UUID) error
 }
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"time"
+
+	"bookstore-backend/pkg/logger"
+)
+
+// stepTimer đo latency từng bước của 1 flow (vd: CreateOrder) để biết bước nào ăn budget
+// Không thread-safe: chỉ gọi mark() từ goroutine chính của flow
+type stepTimer struct {
+	op    string
+	start time.Time
+	last  time.Time
+	steps map[string]interface{}
+}
+
+func newStepTimer(op string) *stepTimer {
+	now := time.Now()
+	return &stepTimer{
+		op:    op,
+		start: now,
+		last:  now,
+		steps: make(map[string]interface{}),
+	}
+}
+
+// mark ghi thời gian (ms) từ lần mark trước tới hiện tại cho step
+func (t *stepTimer) mark(step string) {
+	now := time.Now()
+	t.steps[step+"_ms"] = now.Sub(t.last).Milliseconds()
+	t.last = now
+}
+
+// log in toàn bộ step + tổng thời gian, err != nil để phân biệt request lỗi/timeout
+func (t *stepTimer) log(err error) {
+	t.steps["total_ms"] = time.Since(t.start).Milliseconds()
+	if err != nil {
+		t.steps["error"] = err.Error()
+	}
+	logger.Info(t.op+" latency", t.steps)
+}
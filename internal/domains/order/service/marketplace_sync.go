@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/order/marketplace"
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/domains/order/repository"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// MARKETPLACE STOCK SYNC SERVICE
+// =====================================================
+// Đẩy tồn (available - stock_buffer) + giá bán lên các kênh sàn bật stock_sync_enabled
+// - Theo sự kiện: job inventory sync (reserve / release / nhập kho / đổi giá) → chỉ đẩy khi khác lần trước
+// - Reconcile đêm: đẩy lại toàn catalog, sửa lệch do sàn tự trừ tồn / lần đẩy trước lỗi
+
+// MarketplaceSyncService đồng bộ tồn + giá lên sàn
+type MarketplaceSyncService interface {
+	// SyncBooks đẩy các sách có thay đổi lên mọi kênh đang bật đồng bộ
+	// Trả ErrMarketplaceRateLimited khi hết quota phút hiện tại (job retry sau)
+	SyncBooks(ctx context.Context, bookIDs []uuid.UUID) ([]model.MarketplaceSyncResult, error)
+
+	// Reconcile đẩy lại toàn bộ catalog, chờ khi hết quota thay vì bỏ dở
+	Reconcile(ctx context.Context) ([]model.MarketplaceSyncResult, error)
+}
+
+// reconcilePageSize số sách đọc mỗi trang khi reconcile
+const reconcilePageSize = 500
+
+type marketplaceSyncService struct {
+	orderRepo repository.OrderRepository
+	connector marketplace.Connector // nil = chưa cấu hình connector, bỏ qua đồng bộ
+	cache     cache.Cache
+
+	// Số lần gọi connector tối đa / phút / kênh (<= 0: không giới hạn)
+	requestsPerMinute int
+}
+
+// NewMarketplaceSyncService creates marketplace stock sync service
+func NewMarketplaceSyncService(
+	orderRepo repository.OrderRepository,
+	connector marketplace.Connector,
+	cache cache.Cache,
+	requestsPerMinute int,
+) MarketplaceSyncService {
+	return &marketplaceSyncService{
+		orderRepo:         orderRepo,
+		connector:         connector,
+		cache:             cache,
+		requestsPerMinute: requestsPerMinute,
+	}
+}
+
+func (s *marketplaceSyncService) SyncBooks(ctx context.Context, bookIDs []uuid.UUID) ([]model.MarketplaceSyncResult, error) {
+	if s.connector == nil || len(bookIDs) == 0 {
+		return nil, nil
+	}
+
+	channels, err := s.syncChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.MarketplaceSyncResult, 0, len(channels))
+	for _, channel := range channels {
+		result := model.MarketplaceSyncResult{Channel: channel.Code}
+
+		listings, err := s.orderRepo.ListMarketplaceListings(ctx, channel.Code, bookIDs, uuid.Nil, len(bookIDs))
+		if err != nil {
+			return results, err
+		}
+
+		pending := make([]model.MarketplaceListing, 0, len(listings))
+		for _, listing := range listings {
+			if listing.NeedsPush(channel.StockBuffer) {
+				pending = append(pending, listing)
+			} else {
+				result.Skipped++
+			}
+		}
+
+		err = s.push(ctx, channel, pending, false, &result)
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+func (s *marketplaceSyncService) Reconcile(ctx context.Context) ([]model.MarketplaceSyncResult, error) {
+	if s.connector == nil {
+		return nil, nil
+	}
+
+	channels, err := s.syncChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]model.MarketplaceSyncResult, 0, len(channels))
+	for _, channel := range channels {
+		result := model.MarketplaceSyncResult{Channel: channel.Code}
+
+		after := uuid.Nil
+		for {
+			listings, err := s.orderRepo.ListMarketplaceListings(ctx, channel.Code, nil, after, reconcilePageSize)
+			if err != nil {
+				return results, err
+			}
+			if len(listings) == 0 {
+				break
+			}
+
+			// Lỗi connector của 1 kênh không chặn kênh khác, đã ghi failed theo từng SKU
+			if err := s.push(ctx, channel, listings, true, &result); err != nil {
+				logger.Error("Marketplace reconcile aborted for channel "+channel.Code, err)
+				break
+			}
+
+			after = listings[len(listings)-1].BookID
+			if len(listings) < reconcilePageSize {
+				break
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// syncChannels kênh sàn đang hoạt động + bật đồng bộ tồn
+func (s *marketplaceSyncService) syncChannels(ctx context.Context) ([]model.SalesChannel, error) {
+	channels, err := s.orderRepo.ListSalesChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make([]model.SalesChannel, 0, len(channels))
+	for _, channel := range channels {
+		if channel.IsActive && channel.IsMarketplace() && channel.StockSyncEnabled {
+			enabled = append(enabled, channel)
+		}
+	}
+	return enabled, nil
+}
+
+// push đẩy listings theo lô MaxBatchSize, ghi kết quả từng SKU vào marketplace_listing_syncs
+// wait = true: hết quota thì chờ sang phút sau; false: trả ErrMarketplaceRateLimited
+func (s *marketplaceSyncService) push(
+	ctx context.Context,
+	channel model.SalesChannel,
+	listings []model.MarketplaceListing,
+	wait bool,
+	result *model.MarketplaceSyncResult,
+) error {
+	for start := 0; start < len(listings); start += marketplace.MaxBatchSize {
+		end := start + marketplace.MaxBatchSize
+		if end > len(listings) {
+			end = len(listings)
+		}
+		batch := listings[start:end]
+
+		if err := s.acquireRateSlot(ctx, channel.Code, wait); err != nil {
+			return err
+		}
+
+		updates := make([]marketplace.ListingUpdate, len(batch))
+		for i, listing := range batch {
+			updates[i] = marketplace.ListingUpdate{
+				SKU:      listing.SKU,
+				Quantity: listing.PushQuantity(channel.StockBuffer),
+				Price:    listing.Price,
+			}
+		}
+
+		pushResults, pushErr := s.connector.PushListings(ctx, channel.Code, updates)
+		bySKU := make(map[string]marketplace.ListingResult, len(pushResults))
+		for _, r := range pushResults {
+			bySKU[r.SKU] = r
+		}
+
+		now := time.Now()
+		syncs := make([]model.MarketplaceListingSync, len(batch))
+		for i, listing := range batch {
+			sync := model.MarketplaceListingSync{
+				Channel:  channel.Code,
+				BookID:   listing.BookID,
+				SKU:      listing.SKU,
+				Quantity: updates[i].Quantity,
+				Price:    updates[i].Price,
+				At:       now,
+			}
+
+			r, ok := bySKU[listing.SKU]
+			switch {
+			case pushErr != nil:
+				sync.Status, sync.LastError = model.ListingSyncStatusFailed, stringPtr(pushErr.Error())
+			case !ok:
+				sync.Status, sync.LastError = model.ListingSyncStatusFailed, stringPtr("no result returned by connector")
+			case r.Status == marketplace.ResultUpdated:
+				sync.Status = model.ListingSyncStatusSynced
+			case r.Status == marketplace.ResultNotListed:
+				sync.Status = model.ListingSyncStatusNotListed
+			default:
+				sync.Status, sync.LastError = model.ListingSyncStatusFailed, stringPtr(fmt.Sprintf("%s: %s", r.Status, r.Error))
+			}
+
+			switch sync.Status {
+			case model.ListingSyncStatusSynced:
+				result.Pushed++
+			case model.ListingSyncStatusNotListed:
+				result.NotListed++
+			default:
+				result.Failed++
+			}
+			syncs[i] = sync
+		}
+
+		if err := s.orderRepo.SaveMarketplaceListingSyncs(ctx, syncs); err != nil {
+			return err
+		}
+		if pushErr != nil {
+			return fmt.Errorf("push listings to %s: %w", channel.Code, pushErr)
+		}
+	}
+	return nil
+}
+
+// acquireRateSlot fixed window theo phút / kênh trên Redis (worker chạy nhiều process dùng chung quota)
+// Redis lỗi → không chặn, sàn tự trả lỗi rate limit nếu vượt
+func (s *marketplaceSyncService) acquireRateSlot(ctx context.Context, channel string, wait bool) error {
+	if s.requestsPerMinute <= 0 {
+		return nil
+	}
+
+	for {
+		window := time.Now().Unix() / 60
+		key := fmt.Sprintf("marketplace:sync:rate:%s:%d", channel, window)
+
+		count, err := s.cache.Increment(ctx, key)
+		if err != nil {
+			logger.Error("Marketplace sync: rate counter unavailable", err)
+			return nil
+		}
+		if count == 1 {
+			if err := s.cache.Expire(ctx, key, 2*time.Minute); err != nil {
+				logger.Error("Marketplace sync: set rate counter ttl failed", err)
+			}
+		}
+		if count <= int64(s.requestsPerMinute) {
+			return nil
+		}
+		if !wait {
+			return model.ErrMarketplaceRateLimited
+		}
+
+		timer := time.NewTimer(time.Until(time.Unix((window+1)*60, 0)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(model.ErrMarketplaceRateLimited, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/database"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+)
+
+// =====================================================
+// MODIFY ORDER (CUSTOMER SELF-SERVICE)
+// =====================================================
+
+// ModifyOrder cho khách đổi địa chỉ giao / ghi chú trong modifyWindow sau khi đặt (trước processing).
+// Đổi sang tỉnh khác → chọn lại kho theo địa chỉ mới; kho khác kho cũ thì chuyển reserve trong cùng transaction.
+// Mỗi lần sửa ghi 1 dòng order_modifications (giá trị cũ/mới) để support tra cứu.
+func (s *orderService) ModifyOrder(
+	ctx context.Context,
+	orderID uuid.UUID,
+	userID uuid.UUID,
+	req model.ModifyOrderRequest,
+) (*model.OrderDetailResponse, error) {
+	// 1. Validate request
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid modify request", err)
+	}
+
+	// 2. Get order and verify ownership
+	order, err := s.orderRepo.GetOrderByIDAndUserID(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Business rule: còn trong cửa sổ, chưa processing, chưa mua nhãn vận chuyển
+	if !order.CanBeModified(s.modifyWindow, time.Now()) {
+		return nil, model.NewOrderError(
+			model.ErrCodeModificationClosed,
+			fmt.Sprintf("Order with status '%s' can no longer be modified", order.Status),
+			model.ErrModificationClosed,
+		)
+	}
+
+	newAddressID := order.AddressID
+	newWarehouseID := order.WarehouseID
+	newNote := order.CustomerNote
+	var items []model.OrderItem
+
+	// 4. Địa chỉ mới
+	if req.AddressID != nil && *req.AddressID != order.AddressID {
+		gift, err := s.orderRepo.GetOrderGift(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if gift != nil {
+			// Đơn quà giao tới người nhận (snapshot trong order_gifts), không theo sổ địa chỉ của khách
+			return nil, model.NewOrderError(
+				model.ErrCodeInvalidGift,
+				"Shipping address of a gift order cannot be changed",
+				model.ErrInvalidGift,
+			)
+		}
+
+		newAddr, err := s.addressRepo.GetByID(ctx, *req.AddressID)
+		if err != nil {
+			return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Invalid shipping address", err)
+		}
+		if newAddr.UserID != userID {
+			return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Address does not belong to user", nil)
+		}
+		newAddressID = newAddr.ID
+
+		// Địa chỉ cũ bị xoá → không biết tỉnh cũ, coi như đổi tỉnh
+		provinceChanged := true
+		if oldAddr, err := s.addressRepo.GetByID(ctx, order.AddressID); err == nil {
+			provinceChanged = !strings.EqualFold(strings.TrimSpace(oldAddr.Province), strings.TrimSpace(newAddr.Province))
+		}
+
+		// Đơn cũ chưa lưu warehouse_id → không biết reserve ở kho nào, giữ nguyên
+		if provinceChanged && order.WarehouseID != nil {
+			items, err = s.orderRepo.GetOrderItemsByOrderID(ctx, orderID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get order items: %w", err)
+			}
+
+			bookItems := make([]bookItemData, 0, len(items))
+			for _, item := range items {
+				bookItems = append(bookItems, bookItemData{BookID: item.BookID, Quantity: item.Quantity})
+			}
+
+			// Đơn đã tách nhiều kho: giữ nguyên phân bổ, chỉ đơn 1 kiện mới chuyển kho
+			if isSplitFulfillment(order, items) {
+				logger.Info("Keep split fulfillment after address change", map[string]interface{}{
+					"order_id": order.ID,
+				})
+			} else if selectedWH, err := s.selectSingleWarehouseForOrder(ctx, newAddr, bookItems); err != nil {
+				// Hàng đã giữ ở kho cũ → vẫn giao được, chỉ xa hơn; không chặn khách đổi địa chỉ
+				logger.Info("Keep current warehouse after address change", map[string]interface{}{
+					"order_id":     order.ID,
+					"warehouse_id": *order.WarehouseID,
+					"error":        err.Error(),
+				})
+			} else {
+				newWarehouseID = &selectedWH.ID
+			}
+		}
+	}
+
+	// 5. Ghi chú mới (chuỗi rỗng = xoá)
+	if req.CustomerNote != nil {
+		note := strings.TrimSpace(*req.CustomerNote)
+		if note == "" {
+			newNote = nil
+		} else {
+			newNote = &note
+		}
+	}
+
+	warehouseChanged := newWarehouseID != nil && *newWarehouseID != *order.WarehouseID
+	addressChanged := newAddressID != order.AddressID
+	noteChanged := !equalStringPtr(newNote, order.CustomerNote)
+	if !addressChanged && !noteChanged {
+		return s.GetOrderDetail(ctx, orderID, userID)
+	}
+
+	// 6. Begin transaction
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.orderRepo.RollbackTx(ctx, tx)
+
+	// 7. Chuyển reserve sang kho mới
+	if warehouseChanged {
+		for _, item := range items {
+			if err := s.inventoryRepo.ReleaseStockWithTx(ctx, tx, *order.WarehouseID, item.BookID, item.Quantity, &userID); err != nil {
+				return nil, fmt.Errorf("failed to release stock for book %s: %w", item.BookID.String(), err)
+			}
+			if err := s.inventoryRepo.ReserveStockWithTx(ctx, tx, *newWarehouseID, item.BookID, item.Quantity, &userID); err != nil {
+				return nil, model.NewOrderError(
+					model.ErrCodeInsufficientStock,
+					fmt.Sprintf("Failed to reserve stock for book: %s", item.BookID),
+					err,
+				)
+			}
+		}
+	}
+
+	// 8. Update order với optimistic locking
+	if err := s.orderRepo.UpdateOrderShippingWithTx(ctx, tx, orderID, newAddressID, newNote, newWarehouseID, req.Version); err != nil {
+		return nil, err
+	}
+	if warehouseChanged {
+		if err := s.orderRepo.MoveOrderShipmentsWithTx(ctx, tx, orderID, *newWarehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 9. Audit
+	modification := &model.OrderModification{
+		OrderID:         orderID,
+		OldCustomerNote: order.CustomerNote,
+		NewCustomerNote: newNote,
+		OldWarehouseID:  order.WarehouseID,
+		NewWarehouseID:  newWarehouseID,
+		ChangedBy:       &userID,
+	}
+	if addressChanged {
+		modification.OldAddressID = &order.AddressID
+		modification.NewAddressID = &newAddressID
+	}
+	if err := s.orderRepo.CreateOrderModificationWithTx(ctx, tx, modification); err != nil {
+		return nil, err
+	}
+
+	// 10. Commit
+	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("Order modified by customer", map[string]interface{}{
+		"order_id":          order.ID,
+		"address_changed":   addressChanged,
+		"note_changed":      noteChanged,
+		"warehouse_changed": warehouseChanged,
+	})
+
+	// 11. Enqueue InventorySyncJob sau commit (tồn 2 kho đã đổi)
+	if warehouseChanged {
+		for _, item := range items {
+			payload := shared.InventorySyncPayload{
+				BookID: item.BookID.String(),
+				Source: "ORDER_MODIFIED",
+			}
+			if b, err := json.Marshal(payload); err == nil {
+				task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
+				if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
+					logger.Error("Failed to enqueue InventorySyncJob after modify order", err)
+				}
+			}
+		}
+	}
+
+	return s.GetOrderDetail(ctx, orderID, userID)
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
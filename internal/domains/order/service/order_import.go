@@ -0,0 +1,452 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// ORDER IMPORT (CSV đơn offline / điện thoại / lịch sử)
+// =====================================================
+// Upload: parse + validate từng dòng ngay, lưu toàn bộ dòng (kể cả dòng lỗi) để admin xem lại.
+// Job order:import_orders: tạo từng đơn qua createOrderFromItems (reserve tồn, tách kho, tính phí
+// như đơn thường), ghi kết quả sau mỗi đơn → retry chỉ xử lý dòng còn pending.
+
+// CreateOrderImport - admin upload file, trả import + các dòng đã parse (status pending / failed)
+func (s *orderService) CreateOrderImport(
+	ctx context.Context,
+	adminID uuid.UUID,
+	fileName string,
+	file io.Reader,
+) (*model.OrderImportResponse, error) {
+	rows, err := parseOrderImportCSV(file)
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidImport, err.Error(), model.ErrInvalidImportFile)
+	}
+	validateOrderImportGroups(rows)
+
+	importID := uuid.New()
+	imp := &model.OrderImport{
+		ID:        importID,
+		FileName:  fileName,
+		Status:    model.OrderImportStatusPending,
+		TotalRows: len(rows),
+		CreatedBy: &adminID,
+	}
+	refs := make(map[string]struct{})
+	for i := range rows {
+		rows[i].ID = uuid.New()
+		rows[i].ImportID = importID
+		refs[rows[i].OrderRef] = struct{}{}
+		if rows[i].Status == model.OrderImportRowFailed {
+			imp.FailedRows++
+		}
+	}
+	imp.TotalOrders = len(refs)
+
+	if err := s.orderRepo.CreateOrderImport(ctx, imp, rows); err != nil {
+		return nil, err
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeImportOrders, model.ProcessOrderImportPayload{ImportID: importID})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueOrder), asynq.MaxRetry(3)); err != nil {
+		return nil, fmt.Errorf("failed to enqueue order import: %w", err)
+	}
+
+	logger.Info("Order import queued", map[string]interface{}{
+		"import_id":   importID,
+		"admin_id":    adminID,
+		"file_name":   fileName,
+		"rows":        imp.TotalRows,
+		"orders":      imp.TotalOrders,
+		"failed_rows": imp.FailedRows,
+	})
+
+	return &model.OrderImportResponse{OrderImport: *imp, Rows: rows}, nil
+}
+
+// GetOrderImport - admin xem tiến độ + lỗi từng dòng (rowStatus: "" | pending | created | failed)
+func (s *orderService) GetOrderImport(ctx context.Context, importID uuid.UUID, rowStatus string) (*model.OrderImportResponse, error) {
+	switch rowStatus {
+	case "", model.OrderImportRowPending, model.OrderImportRowCreated, model.OrderImportRowFailed:
+	default:
+		return nil, model.NewOrderError(model.ErrCodeInvalidImport, fmt.Sprintf("Invalid row status '%s'", rowStatus), model.ErrInvalidImportFile)
+	}
+
+	imp, err := s.orderRepo.GetOrderImport(ctx, importID)
+	if err != nil {
+		if errors.Is(err, model.ErrImportNotFound) {
+			return nil, model.NewOrderError(model.ErrCodeImportNotFound, "Order import not found", err)
+		}
+		return nil, err
+	}
+
+	rows, err := s.orderRepo.ListOrderImportRows(ctx, importID, rowStatus)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OrderImportResponse{OrderImport: *imp, Rows: rows}, nil
+}
+
+// ProcessOrderImport tạo đơn cho các dòng pending, nhóm theo order_ref.
+// Lỗi của 1 đơn chỉ ghi vào dòng; trả error khi lỗi hạ tầng (asynq retry, đơn đã tạo không bị tạo lại).
+func (s *orderService) ProcessOrderImport(ctx context.Context, importID uuid.UUID) error {
+	started, err := s.orderRepo.StartOrderImport(ctx, importID)
+	if err != nil {
+		return err
+	}
+	if !started {
+		// Import đã kết thúc (task bị giao lại sau khi xong)
+		return nil
+	}
+
+	rows, err := s.orderRepo.ListOrderImportRows(ctx, importID, model.OrderImportRowPending)
+	if err != nil {
+		return err
+	}
+
+	refs := make([]string, 0)
+	groups := make(map[string][]model.OrderImportRow)
+	emailSet := make(map[string]struct{})
+	isbnSet := make(map[string]struct{})
+	for _, row := range rows {
+		if _, ok := groups[row.OrderRef]; !ok {
+			refs = append(refs, row.OrderRef)
+		}
+		groups[row.OrderRef] = append(groups[row.OrderRef], row)
+		emailSet[row.CustomerEmail] = struct{}{}
+		isbnSet[row.ISBN] = struct{}{}
+	}
+
+	users := map[string]uuid.UUID{}
+	books := map[string]uuid.UUID{}
+	if len(rows) > 0 {
+		if users, err = s.orderRepo.FindUserIDsByEmail(ctx, setKeys(emailSet)); err != nil {
+			return err
+		}
+		if books, err = s.orderRepo.FindBookIDsBySKU(ctx, setKeys(isbnSet)); err != nil {
+			return err
+		}
+	}
+
+	created, failed := 0, 0
+	for _, ref := range refs {
+		group := groups[ref]
+		orderID, rowErr := s.importOrderGroup(ctx, importID, group, users, books)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		results := make([]model.OrderImportRowResult, 0, len(group))
+		for _, row := range group {
+			result := model.OrderImportRowResult{RowID: row.ID, Status: model.OrderImportRowCreated, OrderID: orderID}
+			if rowErr != "" {
+				msg := rowErr
+				result = model.OrderImportRowResult{RowID: row.ID, Status: model.OrderImportRowFailed, Error: &msg}
+			}
+			results = append(results, result)
+		}
+		if err := s.orderRepo.SaveOrderImportRowResults(ctx, results); err != nil {
+			return err
+		}
+
+		if rowErr != "" {
+			failed++
+		} else {
+			created++
+		}
+	}
+
+	if err := s.orderRepo.FinishOrderImport(ctx, importID, model.OrderImportStatusCompleted, nil); err != nil {
+		return err
+	}
+
+	logger.Info("Order import completed", map[string]interface{}{
+		"import_id":      importID,
+		"orders_created": created,
+		"orders_failed":  failed,
+	})
+	return nil
+}
+
+// importOrderGroup tạo 1 đơn từ các dòng cùng order_ref; trả order id hoặc lý do lỗi
+func (s *orderService) importOrderGroup(
+	ctx context.Context,
+	importID uuid.UUID,
+	group []model.OrderImportRow,
+	users map[string]uuid.UUID,
+	books map[string]uuid.UUID,
+) (*uuid.UUID, string) {
+	first := group[0]
+
+	// Đã import ở lần trước (cùng mã đơn) → không tạo trùng
+	if existing, err := s.orderRepo.GetOrderByExternalID(ctx, model.ChannelOffline, first.OrderRef); err == nil {
+		return &existing.ID, ""
+	} else if !errors.Is(err, model.ErrOrderNotFound) {
+		return nil, err.Error()
+	}
+
+	userID, ok := users[first.CustomerEmail]
+	if !ok {
+		return nil, fmt.Sprintf("customer %s not found", first.CustomerEmail)
+	}
+
+	// Gộp dòng trùng sách (cộng số lượng, giá lấy dòng đầu)
+	items := make([]model.CreateOrderItem, 0, len(group))
+	itemIndex := make(map[uuid.UUID]int)
+	overrides := make(map[uuid.UUID]decimal.Decimal)
+	var note *string
+	for _, row := range group {
+		bookID, ok := books[row.ISBN]
+		if !ok {
+			return nil, fmt.Sprintf("line %d: book with ISBN %s not found or inactive", row.LineNo, row.ISBN)
+		}
+		if i, ok := itemIndex[bookID]; ok {
+			items[i].Quantity += row.Quantity
+		} else {
+			itemIndex[bookID] = len(items)
+			items = append(items, model.CreateOrderItem{BookID: bookID, Quantity: row.Quantity})
+		}
+		if _, ok := overrides[bookID]; !ok && row.UnitPrice != nil {
+			overrides[bookID] = *row.UnitPrice
+		}
+		if note == nil && row.Note != nil {
+			note = row.Note
+		}
+	}
+
+	// Địa chỉ: address_id của khách, trống → mặc định / lần giao gần nhất
+	var addressID uuid.UUID
+	if first.AddressID != nil {
+		address, err := s.addressRepo.GetByID(ctx, *first.AddressID)
+		if err != nil || address == nil || address.UserID != userID {
+			return nil, fmt.Sprintf("address %s does not belong to %s", first.AddressID, first.CustomerEmail)
+		}
+		addressID = address.ID
+	} else {
+		address, err := s.resolveFallbackAddress(ctx, userID)
+		if err != nil {
+			return nil, fmt.Sprintf("customer %s has no shipping address", first.CustomerEmail)
+		}
+		addressID = address.ID
+	}
+
+	ref := first.OrderRef
+	resp, err := s.createOrderFromItems(ctx, userID, model.CreateOrderFromItemsRequest{
+		AddressID:       addressID,
+		PaymentMethod:   first.PaymentMethod,
+		CustomerNote:    note,
+		Items:           items,
+		PriceOverrides:  overrides,
+		Channel:         model.ChannelOffline,
+		ExternalOrderID: &ref,
+		Paid:            first.Paid,
+		Metadata: model.Metadata{
+			"import_id":  importID.String(),
+			"import_ref": ref,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrExternalOrderExists) {
+			// Task song song đã tạo đơn cùng mã
+			if existing, lookupErr := s.orderRepo.GetOrderByExternalID(ctx, model.ChannelOffline, ref); lookupErr == nil {
+				return &existing.ID, ""
+			}
+		}
+		var orderErr *model.OrderError
+		if errors.As(err, &orderErr) {
+			return nil, orderErr.Message
+		}
+		logger.Error("Order import: failed to create order", err)
+		return nil, err.Error()
+	}
+
+	return &resp.OrderID, ""
+}
+
+// parseOrderImportCSV đọc file; lỗi header / file rỗng / quá nhiều dòng → từ chối cả file,
+// lỗi từng dòng → dòng status failed kèm lý do
+func parseOrderImportCSV(file io.Reader) ([]model.OrderImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file is empty")
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range model.OrderImportColumns {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header must contain %s", strings.Join(model.OrderImportColumns, ", "))
+		}
+	}
+
+	field := func(record []string, name string) string {
+		col, ok := columns[name]
+		if !ok || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+
+	rows := make([]model.OrderImportRow, 0)
+	for lineNo := 1; ; lineNo++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if len(rows) >= model.OrderImportMaxRows {
+			return nil, fmt.Errorf("file exceeds %d lines", model.OrderImportMaxRows)
+		}
+
+		row := model.OrderImportRow{
+			LineNo:        lineNo,
+			OrderRef:      field(record, "order_ref"),
+			CustomerEmail: strings.ToLower(field(record, "customer_email")),
+			ISBN:          field(record, "isbn"),
+			PaymentMethod: strings.ToLower(field(record, "payment_method")),
+			Status:        model.OrderImportRowPending,
+		}
+		if note := field(record, "note"); note != "" {
+			row.Note = &note
+		}
+		if msg := parseOrderImportRow(&row, record, field); msg != "" {
+			row.Status = model.OrderImportRowFailed
+			row.Error = &msg
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no order lines")
+	}
+	return rows, nil
+}
+
+// parseOrderImportRow validate 1 dòng, trả lý do lỗi ("" = hợp lệ)
+func parseOrderImportRow(row *model.OrderImportRow, record []string, field func([]string, string) string) string {
+	if row.OrderRef == "" || row.CustomerEmail == "" || row.ISBN == "" || row.PaymentMethod == "" {
+		return "order_ref, customer_email, isbn and payment_method are required"
+	}
+	if len(row.OrderRef) > model.OrderImportMaxRefLength {
+		return fmt.Sprintf("order_ref exceeds %d characters", model.OrderImportMaxRefLength)
+	}
+
+	quantity, err := strconv.Atoi(field(record, "quantity"))
+	if err != nil || quantity < 1 || quantity > 100 {
+		return fmt.Sprintf("invalid quantity %q (1-100)", field(record, "quantity"))
+	}
+	row.Quantity = quantity
+
+	switch row.PaymentMethod {
+	case model.PaymentMethodCOD, model.PaymentMethodBankTransfer, model.PaymentMethodVNPay, model.PaymentMethodMomo:
+	default:
+		return fmt.Sprintf("unsupported payment_method %q", row.PaymentMethod)
+	}
+
+	if raw := field(record, "unit_price"); raw != "" {
+		price, err := decimal.NewFromString(strings.ReplaceAll(raw, ",", ""))
+		if err != nil || price.IsNegative() {
+			return fmt.Sprintf("invalid unit_price %q", raw)
+		}
+		row.UnitPrice = &price
+	}
+
+	if raw := field(record, "paid"); raw != "" {
+		paid, err := strconv.ParseBool(strings.ToLower(raw))
+		if err != nil {
+			return fmt.Sprintf("invalid paid %q", raw)
+		}
+		row.Paid = paid
+	}
+
+	if raw := field(record, "address_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return fmt.Sprintf("invalid address_id %q", raw)
+		}
+		row.AddressID = &id
+	}
+	return ""
+}
+
+// validateOrderImportGroups các dòng cùng order_ref phải cùng khách / thanh toán / địa chỉ;
+// 1 dòng lỗi → cả đơn lỗi (không tạo đơn thiếu sách)
+func validateOrderImportGroups(rows []model.OrderImportRow) {
+	groups := make(map[string][]int)
+	for i, row := range rows {
+		groups[row.OrderRef] = append(groups[row.OrderRef], i)
+	}
+
+	sameAddress := func(a, b *uuid.UUID) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return *a == *b
+	}
+
+	for ref, indexes := range groups {
+		first := rows[indexes[0]]
+		reason := ""
+		for _, i := range indexes {
+			row := rows[i]
+			if row.Status == model.OrderImportRowFailed {
+				reason = fmt.Sprintf("order %s has invalid line %d", ref, row.LineNo)
+				break
+			}
+			if row.CustomerEmail != first.CustomerEmail || row.PaymentMethod != first.PaymentMethod ||
+				row.Paid != first.Paid || !sameAddress(row.AddressID, first.AddressID) {
+				reason = fmt.Sprintf("lines of order %s disagree on customer, payment or address", ref)
+				break
+			}
+		}
+		if reason == "" {
+			continue
+		}
+		for _, i := range indexes {
+			if rows[i].Status == model.OrderImportRowFailed {
+				continue
+			}
+			msg := reason
+			rows[i].Status = model.OrderImportRowFailed
+			rows[i].Error = &msg
+		}
+	}
+}
+
+func setKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -3,30 +3,39 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	addressModel "bookstore-backend/internal/domains/address/model"
 	address "bookstore-backend/internal/domains/address/repository"
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
+	bookModel "bookstore-backend/internal/domains/book/model"
 	book "bookstore-backend/internal/domains/book/service"
 	cartModel "bookstore-backend/internal/domains/cart/model"
 	cart "bookstore-backend/internal/domains/cart/repository"
 	invenRepo "bookstore-backend/internal/domains/inventory/repository"
 	invenSer "bookstore-backend/internal/domains/inventory/service"
+	notificationService "bookstore-backend/internal/domains/notification/service"
 	"bookstore-backend/internal/domains/order/model"
 	"bookstore-backend/internal/domains/order/repository"
 	modelPromo "bookstore-backend/internal/domains/promotion/model"
 	promo "bookstore-backend/internal/domains/promotion/repository"
 	whModel "bookstore-backend/internal/domains/warehouse/model"
 	warehouse "bookstore-backend/internal/domains/warehouse/service"
+	"bookstore-backend/internal/infrastructure/outbox"
 	"bookstore-backend/internal/shared"
-	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/internal/shared/pagination"
+	"bookstore-backend/pkg/database"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/shopspring/decimal"
+	"golang.org/x/sync/errgroup"
 )
 
 // =====================================================
@@ -42,6 +51,26 @@ type orderService struct {
 	inventorySerivce invenSer.ServiceInterface
 	asynq            *asynq.Client // DI từ container, queue riêng inventory
 	bookService      book.ServiceInterface
+	priceTiers       book.PriceTierService // Giá theo số lượng khi build order items
+	codRecorder      CODCollectionRecorder
+
+	// Side-effect của transition trạng thái (set sau khi notification/shipment service được tạo)
+	notificationService notificationService.NotificationService
+	shipmentTrigger     ShipmentTrigger
+	shippingQuoter      ShippingQuoter
+	taxCalculator       TaxCalculator
+
+	// Deadline tổng cho CreateOrder (<= 0: không giới hạn, chỉ theo ctx của request)
+	createTimeout time.Duration
+	// Thời gian khách được tự sửa địa chỉ / ghi chú sau khi đặt (<= 0: tắt)
+	modifyWindow time.Duration
+	// Đơn tối thiểu + phí ship / ngưỡng freeship
+	checkoutPolicy model.CheckoutPolicy
+
+	// Rule khách tự huỷ đơn (order_cancellation_rules), nạp lúc khởi động + reload theo TTL
+	cancelMu       sync.RWMutex
+	cancelPolicy   *model.CancellationPolicy
+	cancelLoadedAt time.Time
 }
 
 // NewOrderService creates a new order service
@@ -53,9 +82,13 @@ func NewOrderService(
 	cartRepo cart.RepositoryInterface,
 	promoRepo promo.PromotionRepository,
 	bookService book.ServiceInterface,
+	priceTiers book.PriceTierService,
 	inventorySerivce invenSer.ServiceInterface,
 	asynq *asynq.Client,
-
+	codRecorder CODCollectionRecorder,
+	createTimeout time.Duration,
+	modifyWindow time.Duration,
+	checkoutPolicy model.CheckoutPolicy,
 ) OrderService {
 	return &orderService{
 		orderRepo:        orderRepo,
@@ -67,6 +100,11 @@ func NewOrderService(
 		inventorySerivce: inventorySerivce,
 		asynq:            asynq,
 		bookService:      bookService,
+		priceTiers:       priceTiers,
+		codRecorder:      codRecorder,
+		createTimeout:    createTimeout,
+		modifyWindow:     modifyWindow,
+		checkoutPolicy:   checkoutPolicy,
 	}
 }
 
@@ -77,11 +115,46 @@ func NewOrderService(
 // CREATE ORDER - V2 (DÙNG CHO CHECKOUT TỪ CART)
 // =====================================================
 
+// CreateOrder áp deadline tổng (createTimeout) cho toàn bộ flow.
+// Quá hạn → ORD019 thay vì lỗi DB/context khó hiểu; transaction chưa commit sẽ rollback.
 func (s *orderService) CreateOrder(ctx context.Context, userID uuid.UUID, req model.CreateOrderRequest) (*model.CreateOrderResponse, error) {
+	if s.createTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.createTimeout)
+		defer cancel()
+	}
+
+	timer := newStepTimer("CreateOrder")
+	resp, err := s.createOrder(ctx, userID, req, timer)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = model.NewOrderError(
+			model.ErrCodeOrderTimeout,
+			fmt.Sprintf("Order creation timed out after %s, please try again", s.createTimeout),
+			model.ErrOrderTimeout,
+		)
+	}
+	timer.log(err)
+
+	return resp, err
+}
+
+func (s *orderService) createOrder(ctx context.Context, userID uuid.UUID, req model.CreateOrderRequest, timer *stepTimer) (*model.CreateOrderResponse, error) {
 	// Step 1: Validate request cơ bản (format)
 	if err := req.Validate(); err != nil {
 		return nil, model.NewOrderError(model.ErrCodeOrderNotFound, "Invalid request", err)
 	}
+	var scheduledDeliveryDate *time.Time
+	if req.Gift != nil {
+		if err := req.Gift.Validate(); err != nil {
+			return nil, model.NewOrderError(model.ErrCodeInvalidGift, "Invalid gift options", err)
+		}
+		scheduledDeliveryDate, _ = req.Gift.ParseScheduledDeliveryDate(time.Now())
+	}
+	// Step 1b: Kênh đặt hàng + phương thức thanh toán kênh cho phép
+	channel, err := s.resolveCheckoutChannel(ctx, req.Channel, req.PaymentMethod)
+	if err != nil {
+		return nil, err
+	}
 	// ==================== STEP 2: LẤY CART + ITEMS TỪ DB ====================
 	cart, err := s.cartRepo.GetByUserID(ctx, userID)
 	if err != nil || cart == nil {
@@ -93,120 +166,226 @@ func (s *orderService) CreateOrder(ctx context.Context, userID uuid.UUID, req mo
 	if err != nil || len(cartItems) == 0 {
 		return nil, model.NewOrderError(model.ErrCodeOrderNotFound, "Cart is empty", err)
 	}
+	timer.mark("cart")
 
-	// ==================== STEP 3: ADDRESS HANDLING ====================
-	var address *addressModel.Address
-	if req.AddressID != uuid.Nil {
-		// Nếu client gửi address_id thì dùng
-		address, err = s.addressRepo.GetByID(ctx, req.AddressID)
-		if err != nil {
-			return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Invalid shipping address", err)
-		}
-		if address.UserID != userID {
-			return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Address does not belong to user", nil)
-		}
-	} else {
-		// Nếu không gửi thì fallback default address
-		address, err = s.addressRepo.GetDefaultByUserID(ctx, userID)
-		if err != nil {
-			return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Missing default address", err)
-		}
-		req.AddressID = address.ID
-	}
 	var oi []model.CreateOrderItem
 	for _, item := range cartItems {
-		oi = append(oi, model.CreateOrderItem{
-			BookID:   item.BookID,
-			Quantity: item.Quantity,
-		})
+		orderItem := model.CreateOrderItem{
+			BookID:          item.BookID,
+			Quantity:        item.Quantity,
+			GiftPromotionID: item.GiftPromotionID, // dòng quà 0đ: vẫn reserve tồn kho như hàng mua
+		}
+		if item.GiftPromotionID == nil {
+			orderItem.Metadata = req.ItemMetadata[item.BookID]
+		}
+		oi = append(oi, orderItem)
 	}
-	// ==================== STEP 4: LẤY BOOK DATA & TÍNH SUBTOTAL ====================
-	bookItems, err := s.validateAndFetchBookItems(ctx, oi)
-	if err != nil {
-		return nil, model.NewOrderError(model.ErrCodeOrderNotFound, "Invalid cart items", err)
+	subtotal := cart.Subtotal
+	if !s.checkoutPolicy.MeetsMinimum(subtotal) {
+		return nil, model.NewOrderError(
+			model.ErrCodeMinOrderAmount,
+			fmt.Sprintf("Order subtotal must be at least %s VND (add %s VND more)",
+				s.checkoutPolicy.MinOrderAmount.String(), s.checkoutPolicy.AmountToMinimum(subtotal).String()),
+			model.ErrMinOrderAmount,
+		)
 	}
 
-	subtotal := cart.Subtotal
+	// ==================== STEP 3-5: VALIDATE SONG SONG ====================
+	// Address, book data, promo độc lập nhau (chỉ cần cart) → chạy song song,
+	// lỗi đầu tiên cancel các goroutine còn lại. Transaction phía sau vẫn tuần tự.
+	var (
+		address               *addressModel.Address
+		bookItems             []bookItemData
+		codePromotion         *modelPromo.Promotion
+		automaticPromotions   []*modelPromo.Promotion
+		discountAmount        = decimal.Zero
+		promoShippingDiscount = decimal.Zero
+	)
+	g, gctx := errgroup.WithContext(ctx)
+
+	// STEP 3: ADDRESS HANDLING
+	g.Go(func() error {
+		if req.AddressID != uuid.Nil {
+			// Nếu client gửi address_id thì dùng
+			addr, err := s.addressRepo.GetByID(gctx, req.AddressID)
+			if err != nil {
+				return model.NewOrderError(model.ErrCodeInvalidAddress, "Invalid shipping address", err)
+			}
+			if addr.UserID != userID {
+				return model.NewOrderError(model.ErrCodeInvalidAddress, "Address does not belong to user", nil)
+			}
+			address = addr
+			return nil
+		}
+		// Nếu không gửi thì fallback default address, chưa có default thì lấy địa chỉ giao thành công gần nhất
+		addr, err := s.resolveFallbackAddress(gctx, userID)
+		if err != nil {
+			return err
+		}
+		address = addr
+		return nil
+	})
 
-	// ==================== STEP 5: PROMO TỪ CART (KHÔNG TIN CLIENT) ====================
-	var promotion *modelPromo.Promotion
-	var discountAmount decimal.Decimal
+	// STEP 4: LẤY BOOK DATA
+	g.Go(func() error {
+		items, err := s.validateAndFetchBookItems(gctx, oi)
+		if err != nil {
+			var orderErr *model.OrderError
+			if errors.As(err, &orderErr) {
+				return err // ITEM_DISCONTINUED: giữ code riêng cho client
+			}
+			return model.NewOrderError(model.ErrCodeOrderNotFound, "Invalid cart items", err)
+		}
+		bookItems = items
+		return nil
+	})
 
+	// STEP 5: PROMO TỪ CART (KHÔNG TIN CLIENT)
 	if cart.PromoCode != nil && *cart.PromoCode != "" && cart.PromoMetadata != nil {
 		// Lấy promo_id từ promo_metadata trong cart
 		if promoIDStr, ok := cart.PromoMetadata["promotion_id"].(string); ok {
 			if promoID, err := uuid.Parse(promoIDStr); err == nil {
-				promotion, err = s.promoRepo.FindByID(ctx, promoID)
-				if err != nil {
-					return nil, model.NewOrderError(model.ErrCodePromoInvalid, "Invalid promotion attached to cart", err)
-				}
-				// Validate lại với subtotal hiện tại
-				if err := s.validatePromotion(promotion, subtotal, userID); err != nil {
-					return nil, err
-				}
-				discountAmount = s.calculateDiscount(promotion, subtotal)
+				g.Go(func() error {
+					p, err := s.promoRepo.FindByID(gctx, promoID)
+					if err != nil {
+						return model.NewOrderError(model.ErrCodePromoInvalid, "Invalid promotion attached to cart", err)
+					}
+					// Validate lại với subtotal hiện tại
+					// Promo do admin override lúc đặt thay khách (metadata do server ghi) → chỉ cần promo còn bật
+					if overridden, _ := cart.PromoMetadata[cartModel.PromoMetadataOverride].(bool); overridden {
+						if !p.IsActive {
+							return model.NewOrderError(model.ErrCodePromoInvalid, "Promotion is not active", model.ErrPromoInvalid)
+						}
+					} else if err := s.validatePromotion(p, subtotal, userID); err != nil {
+						return err
+					}
+					codePromotion = p
+					return nil
+				})
 			}
 		}
-	} else {
-		discountAmount = decimal.Zero
 	}
 
-	// ==================== STEP 6: TÍNH TỔNG TIỀN ====================
+	// STEP 5b: PROMOTION TỰ ĐỘNG (không cần mã, tính lại theo giỏ lúc đặt)
+	g.Go(func() error {
+		promos, err := s.eligibleAutomaticPromotions(gctx, subtotal, userID)
+		if err != nil {
+			return err
+		}
+		automaticPromotions = promos
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	// Step 5c: Giá giữ từ wishlist (cart_items.price đã dùng giá này → subtotal khớp dòng hàng)
+	priceOverrideIDs := s.applyCartPriceOverrides(ctx, cart.ID, bookItems)
+
+	// Mã + tự động theo luật stacking (xem modelPromo.ResolveStack)
+	appliedPromotions := s.resolvePromotionStack(codePromotion, automaticPromotions, subtotal)
+	for _, a := range appliedPromotions {
+		discountAmount = discountAmount.Add(a.discount)
+		promoShippingDiscount = promoShippingDiscount.Add(a.shippingDiscount)
+	}
+	// Cộng dồn nhiều mã không được giảm quá tiền hàng
+	discountAmount = decimal.Min(discountAmount, subtotal)
+	if req.AddressID == uuid.Nil {
+		req.AddressID = address.ID
+	}
+	timer.mark("validate")
+
+	// ==================== STEP 6: CHỌN WAREHOUSE (1 KHO, KHÔNG ĐỦ THÌ TÁCH KIỆN) ====================
+	// Đơn quà giao tới người nhận → chọn kho theo địa chỉ người nhận
+	shipTo := address
+	if req.Gift != nil {
+		shipTo = &addressModel.Address{
+			RecipientName: req.Gift.RecipientName,
+			Phone:         req.Gift.RecipientPhone,
+			Province:      req.Gift.Province,
+			District:      req.Gift.District,
+			Ward:          req.Gift.Ward,
+			Street:        req.Gift.Street,
+		}
+	}
+	plan, err := s.planFulfillment(ctx, shipTo, bookItems)
+	if err != nil {
+		return nil, err
+	}
+	selectedWarehouseID := plan.PrimaryWarehouseID()
+	timer.mark("warehouse")
+
+	// ==================== STEP 7: TÍNH TỔNG TIỀN ====================
+	// Phí ship hãng báo theo từng kiện (kho gửi → địa chỉ nhận, cân nặng sách)
 	isCOD := req.PaymentMethod == model.PaymentMethodCOD
-	_, finalDiscount, shippingFee, codFee, taxAmount, total := model.CalculateOrderAmounts(
+	taxLines, err := s.calculateTaxLines(ctx, shipTo.Province, bookItems, discountAmount)
+	if err != nil {
+		return nil, err
+	}
+	_, finalDiscount, shippingFee, shippingDiscount, codFee, taxAmount, total := model.CalculateOrderAmounts(
 		subtotal,
 		discountAmount,
+		promoShippingDiscount,
 		isCOD,
+		s.shippingPolicyFor(ctx, plan, shipTo, bookItems),
+		taxLines,
 	)
-
-	// ==================== STEP 7: CHỌN WAREHOUSE (V1: 1 KHO) ====================
-	selectedWH, err := s.selectSingleWarehouseForOrder(ctx, address, bookItems)
-	if err != nil {
-		return nil, err
-	}
-	selectedWarehouseID := selectedWH.ID
+	timer.mark("shipping_quote")
 
 	// ==================== STEP 8: TRANSACTION BẮT ĐẦU ====================
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.orderRepo.BeginTx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer s.orderRepo.RollbackTx(ctx, tx)
 
-	// Step 9: Reserve inventory cho TẤT CẢ items tại 1 kho
-	for _, item := range bookItems {
-		if err := s.inventoryRepo.ReserveStockWithTx(ctx, tx, selectedWarehouseID, item.BookID, item.Quantity, &userID); err != nil {
-			return nil, model.NewOrderError(
-				model.ErrCodeInsufficientStock,
-				fmt.Sprintf("Failed to reserve stock for book: %s", item.BookID),
-				err,
-			)
-		}
+	// Step 9: Reserve inventory từng item tại kho của kiện chứa item
+	if err := s.reserveFulfillmentWithTx(ctx, tx, plan, bookItems, userID); err != nil {
+		return nil, err
 	}
 
 	// Step 10: Build order entity
-	orderID := uuid.New()
+	orderID := req.OrderID
+	if orderID == uuid.Nil {
+		orderID = uuid.New()
+	}
+	// orders.promotion_id: mã nhập tay, không có thì promotion tự động đầu tiên (đủ chi tiết ở promotion_usage)
 	var promotionID *uuid.UUID
-	if promotion != nil {
-		promotionID = &promotion.ID
+	if len(appliedPromotions) > 0 {
+		promotionID = &appliedPromotions[0].promo.ID
 	}
 
 	order := &model.Order{
-		ID:             orderID,
-		UserID:         userID,
-		AddressID:      req.AddressID,
-		PromotionID:    promotionID,
-		WarehouseID:    &selectedWarehouseID,
-		Subtotal:       subtotal,
-		ShippingFee:    shippingFee,
-		CODFee:         codFee,
-		DiscountAmount: finalDiscount,
-		TaxAmount:      taxAmount,
-		Total:          total,
-		PaymentMethod:  req.PaymentMethod,
-		PaymentStatus:  model.PaymentStatusPending,
-		CustomerNote:   req.CustomerNote,
-		Version:        0,
+		ID:               orderID,
+		UserID:           userID,
+		AddressID:        req.AddressID,
+		PromotionID:      promotionID,
+		WarehouseID:      &selectedWarehouseID,
+		Subtotal:         subtotal,
+		ShippingFee:      shippingFee,
+		ShippingDiscount: shippingDiscount,
+		CODFee:           codFee,
+		DiscountAmount:   finalDiscount,
+		TaxAmount:        taxAmount,
+		Total:            total,
+		PaymentMethod:    req.PaymentMethod,
+		PaymentStatus:    model.PaymentStatusPending,
+		CustomerNote:     req.CustomerNote,
+		Version:          0,
+		PlacedBy:         req.PlacedBy,
+		Metadata:         req.Metadata,
+		Channel:          channel.Code,
+	}
+	if code := shared.NormalizeAffiliateCode(req.AffiliateCode); code != "" {
+		order.AffiliateCode = &code
+	}
+	if req.Packaging != nil {
+		order.MinimalPackaging = req.Packaging.MinimalPackaging
+		order.NoPrintedInvoice = req.Packaging.NoPrintedInvoice
 	}
 
 	if isCOD {
@@ -221,9 +400,23 @@ func (s *orderService) CreateOrder(ctx context.Context, userID uuid.UUID, req mo
 	if err := s.orderRepo.CreateOrderWithTx(ctx, tx, order); err != nil {
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	if err := s.orderRepo.CreateOrderTaxLinesWithTx(ctx, tx, orderID, taxLines); err != nil {
+		return nil, err
+	}
 
-	// Step 12: Tạo order items
+	// Step 11b: Thông tin quà tặng (người nhận, gift receipt, ngày hẹn giao)
+	if req.Gift != nil {
+		gift := req.Gift.ToEntity(orderID, scheduledDeliveryDate)
+		if err := s.orderRepo.CreateOrderGiftWithTx(ctx, tx, gift); err != nil {
+			return nil, fmt.Errorf("failed to create order gift: %w", err)
+		}
+	}
+
+	// Step 12: Tạo kiện theo kho + order items
 	orderItems := s.buildOrderItems(orderID, bookItems)
+	if err := s.createShipmentsWithTx(ctx, tx, plan, orderID, orderItems); err != nil {
+		return nil, err
+	}
 	logger.Info("Go to save order items :", map[string]interface{}{
 		"order items": orderItems,
 	})
@@ -231,55 +424,75 @@ func (s *orderService) CreateOrder(ctx context.Context, userID uuid.UUID, req mo
 		return nil, fmt.Errorf("failed to create order items: %w", err)
 	}
 
-	// Step 13: Status history
+	// Step 13: Status history (đơn đặt thay → ghi nhân viên đã đặt)
+	changedBy := &userID
+	if req.PlacedBy != nil {
+		changedBy = req.PlacedBy
+	}
 	statusHistory := &model.OrderStatusHistory{
 		OrderID:    orderID,
 		FromStatus: nil,
 		ToStatus:   order.Status,
-		ChangedBy:  &userID,
+		ChangedBy:  changedBy,
 		Notes:      nil,
 	}
 	if err := s.orderRepo.CreateOrderStatusHistoryWithTx(ctx, tx, statusHistory); err != nil {
 		return nil, fmt.Errorf("failed to create order status history: %w", err)
 	}
 
-	// Step 14: Promotion usage (nếu có)
-	if promotion != nil {
+	// Step 14: Promotion usage (mỗi promotion được áp 1 dòng)
+	// Mã giảm ship: ghi phần giảm ship thực tế của mã (bậc tự động lớn hơn thì mã không giảm thêm),
+	// nhiều mã thì chia phần giảm thực tế (đã clamp) theo thứ tự áp
+	remainingDiscount, remainingShippingDiscount := discountAmount, shippingDiscount
+	for _, a := range appliedPromotions {
+		promoDiscount := decimal.Min(a.discount, remainingDiscount)
+		remainingDiscount = remainingDiscount.Sub(promoDiscount)
+		promoShipping := decimal.Min(a.shippingDiscount, remainingShippingDiscount)
+		remainingShippingDiscount = remainingShippingDiscount.Sub(promoShipping)
+
 		usage := &modelPromo.PromotionUsage{
-			PromotionID:    promotion.ID,
+			PromotionID:    a.promo.ID,
 			UserID:         userID,
 			OrderID:        orderID,
-			DiscountAmount: discountAmount,
+			DiscountAmount: promoDiscount.Add(promoShipping),
 		}
 		if err := s.promoRepo.CreateUsage(ctx, tx, usage); err != nil {
 			return nil, fmt.Errorf("failed to create promotion usage: %w", err)
 		}
 	}
 
-	// Step 15: Clear cart TRONG TX
+	// Step 15: Clear cart TRONG TX (giá giữ đã áp gắn vào đơn, không dùng lại được)
+	if err := s.cartRepo.ConsumePriceOverridesWithTx(ctx, tx, priceOverrideIDs, orderID); err != nil {
+		return nil, err
+	}
 	if err := s.cartRepo.DeleteCartWithTx(ctx, tx, cart.ID); err != nil {
 		return nil, fmt.Errorf("failed to clear cart in transaction: %w", err)
 	}
 
-	// Step 16: Commit
-	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	// Step 16: Jobs hậu commit (inventory sync + task của caller) ghi outbox TRONG TX
+	// Redis lỗi lúc commit không làm mất task, relay trong worker phát lại
+	messages := inventorySyncMessages(orderItemBookIDs(orderItems), "SALE")
+	messages = append(messages, orderActivityMessages(order, analyticsModel.ActivityOrderPlaced, map[string]interface{}{
+		"total":       order.Total.String(),
+		"items_count": len(orderItems),
+	})...)
+	messages = append(messages, shipmentOnConfirmMessages(order)...)
+	if req.PostCommitTasks != nil {
+		extra, err := req.PostCommitTasks(order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build post-commit tasks: %w", err)
+		}
+		messages = append(messages, extra...)
+	}
+	if err := outbox.AddWithTx(ctx, tx, messages...); err != nil {
+		return nil, err
 	}
 
-	// ==================== STEP 17: JOBS SAU COMMIT ====================
-	for _, item := range orderItems {
-		payload := shared.InventorySyncPayload{
-			BookID: item.BookID.String(),
-			Source: "SALE",
-		}
-		if b, err := json.Marshal(payload); err == nil {
-			task := asynq.NewTask(shared.TypeInventorySyncBookStock, b)
-			if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
-				logger.Error("Failed to enqueue InventorySyncJob after order", err)
-			}
-		}
+	// Step 17: Commit
+	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
-	// (Optional) enqueue payment-timeout job ở Phase 1.3
+	timer.mark("transaction")
 
 	// Step 18: Response
 	resp := &model.CreateOrderResponse{
@@ -294,9 +507,9 @@ func (s *orderService) CreateOrder(ctx context.Context, userID uuid.UUID, req mo
 
 // selectSingleWarehouseForOrder chọn 1 kho duy nhất có thể fulfill toàn bộ items.
 // Hiện tại strategy đơn giản:
-// 1. Dùng item đầu tiên để tìm kho gần nhất có đủ stock.
-// 2. Validate kho đó có đủ stock cho tất cả items còn lại.
-// Sau này Phase 2 có thể nâng cấp để hỗ trợ multi-warehouse splitting.
+// 1. Dùng item đầu tiên để tìm kho ưu tiên nhất có đủ stock (khoảng cách đã áp allocation_priority).
+// 2. Validate kho đó có đủ stock cho tất cả items còn lại (tồn online đã trừ safety stock).
+// Không kho nào đủ → planFulfillment tách đơn thành nhiều kiện (xem fulfillment.go).
 func (s *orderService) selectSingleWarehouseForOrder(
 	ctx context.Context,
 	address *addressModel.Address,
@@ -324,10 +537,9 @@ func (s *orderService) selectSingleWarehouseForOrder(
 			)
 		}
 
-		// Ở mức tối thiểu, chỉ trả về warehouse có code default, không check stock chi tiết
-		// Nếu muốn chặt hơn: lặp qua bookItems và gọi CheckAvailableStock
+		// Đơn online không được lấn vào safety stock giữ cho khách nhận tại cửa hàng
 		for _, item := range bookItems {
-			ok, err := s.inventorySerivce.CheckAvailableStock(ctx, wh.ID, item.BookID, item.Quantity)
+			ok, err := s.warehouseService.ValidateWarehouseHasStock(ctx, wh.ID, item.BookID, item.Quantity)
 			if err != nil || !ok {
 				return nil, model.NewOrderError(
 					model.ErrCodeInsufficientStock,
@@ -424,6 +636,20 @@ func (s *orderService) GetOrderDetail(
 
 	// 4. Build response (buildOrderDetailResponse chấp nhận address = nil)
 	response := model.BuildOrderDetailResponse(order, items, *addr)
+	response.TaxLines = s.loadTaxLines(ctx, order.ID)
+	if err := s.attachGift(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := s.attachShipments(ctx, items, response); err != nil {
+		return nil, err
+	}
+	if order.CanBeModified(s.modifyWindow, time.Now()) {
+		until := order.ModifiableUntil(s.modifyWindow)
+		response.ModifiableUntil = &until
+	}
+	if err := s.attachCancellation(ctx, order, response); err != nil {
+		return nil, err
+	}
 	return response, nil
 }
 
@@ -441,6 +667,10 @@ func (s *orderService) ListOrders(
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
+	if req.UseCursor() {
+		return s.listOrdersAfterCursor(ctx, userID, req)
+	}
+
 	// 2. Query orders page
 	orders, total, err := s.orderRepo.ListOrdersByUserID(ctx, userID, req.Status, req.Page, req.Limit)
 	if err != nil {
@@ -460,7 +690,68 @@ func (s *orderService) ListOrders(
 		}, nil
 	}
 
-	// 3. Batch count items cho tất cả orders trong page
+	// 3-4. Batch count items + build response list
+	orderSummaries, err := s.summarizeOrders(ctx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Pagination meta
+	totalPages := 0
+	if req.Limit > 0 {
+		totalPages = int(math.Ceil(float64(total) / float64(req.Limit)))
+	}
+
+	response := &model.ListOrdersResponse{
+		Orders: orderSummaries,
+		Pagination: model.PaginationMeta{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}
+
+	return response, nil
+}
+
+// listOrdersAfterCursor keyset pagination: lấy limit+1 đơn, đơn dư chỉ để biết còn trang sau
+func (s *orderService) listOrdersAfterCursor(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.ListOrdersRequest,
+) (*model.ListOrdersResponse, error) {
+	orders, err := s.orderRepo.ListOrdersByUserIDAfterCursor(ctx, userID, req.Status, req.After, req.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	meta := model.PaginationMeta{Limit: req.Limit}
+	if len(orders) > req.Limit {
+		orders = orders[:req.Limit]
+		last := orders[len(orders)-1]
+		meta.HasMore = true
+		meta.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, IDs: []uuid.UUID{last.ID}}.Encode()
+	}
+
+	orderSummaries, err := s.summarizeOrders(ctx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListOrdersResponse{
+		Orders:     orderSummaries,
+		Pagination: meta,
+	}, nil
+}
+
+// summarizeOrders đếm item theo batch (1 query cho cả trang) rồi dựng danh sách tóm tắt
+func (s *orderService) summarizeOrders(ctx context.Context, orders []model.Order) ([]model.OrderSummaryResponse, error) {
+	orderSummaries := make([]model.OrderSummaryResponse, 0, len(orders))
+	if len(orders) == 0 {
+		return orderSummaries, nil
+	}
+
 	orderIDs := make([]uuid.UUID, len(orders))
 	for i, o := range orders {
 		orderIDs[i] = o.ID
@@ -472,8 +763,6 @@ func (s *orderService) ListOrders(
 		return nil, fmt.Errorf("failed to count order items for orders: %w", err)
 	}
 
-	// 4. Build response list
-	orderSummaries := make([]model.OrderSummaryResponse, 0, len(orders))
 	for _, order := range orders {
 		itemsCount := itemsCountMap[order.ID] // default 0 nếu không có key
 
@@ -488,24 +777,7 @@ func (s *orderService) ListOrders(
 			CreatedAt:     order.CreatedAt,
 		})
 	}
-
-	// 5. Pagination meta
-	totalPages := 0
-	if req.Limit > 0 {
-		totalPages = int(math.Ceil(float64(total) / float64(req.Limit)))
-	}
-
-	response := &model.ListOrdersResponse{
-		Orders: orderSummaries,
-		Pagination: model.PaginationMeta{
-			Page:       req.Page,
-			Limit:      req.Limit,
-			Total:      total,
-			TotalPages: totalPages,
-		},
-	}
-
-	return response, nil
+	return orderSummaries, nil
 }
 
 // =====================================================
@@ -530,11 +802,15 @@ func (s *orderService) CancelOrder(
 		return err // đã map ErrNoRows -> ErrOrderNotFound trong repo
 	}
 
-	// 3. Business rule: chỉ cho cancel trong 1 số trạng thái
-	if !order.CanBeCancelled() {
+	// 3. Business rule: trạng thái + cửa sổ thời gian theo rule huỷ đơn (flash sale chặt hơn)
+	rule, err := s.cancellationRuleFor(ctx, order)
+	if err != nil {
+		return fmt.Errorf("failed to get cancellation rule: %w", err)
+	}
+	if err := rule.CheckCancellable(order, time.Now()); err != nil {
 		return model.NewOrderError(
 			model.ErrCodeOrderCannotCancel,
-			fmt.Sprintf("Order with status '%s' cannot be cancelled", order.Status),
+			fmt.Sprintf("Order cannot be cancelled: %s", err.Error()),
 			model.ErrOrderCannotCancel,
 		)
 	}
@@ -549,6 +825,10 @@ func (s *orderService) CancelOrder(
 	}
 
 	// 4. Begin transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.orderRepo.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -562,20 +842,23 @@ func (s *orderService) CancelOrder(
 	}
 
 	// 6. Release reserved inventory (trong TX)
-	if order.WarehouseID != nil {
-		for _, item := range items {
-			if err := s.inventoryRepo.ReleaseStockWithTx(ctx, tx, *order.WarehouseID, item.BookID, item.Quantity, &userID); err != nil {
-				// Nếu lỗi là business (ví dụ BIZ02 – không đủ reserved) có thể log và tiếp tục
-				// Nếu là lỗi hệ thống (DB, connection) nên rollback toàn bộ
-				logger.Info("Failed to release stock when cancelling order", map[string]interface{}{
-					"order_id":     order.ID,
-					"warehouse_id": *order.WarehouseID,
-					"book_id":      item.BookID,
-					"quantity":     item.Quantity,
-					"error":        err.Error(),
-				})
-				return fmt.Errorf("failed to release stock for book %s: %w", item.BookID.String(), err)
-			}
+	// Đơn tách kiện: mỗi dòng release tại kho của kiện chứa dòng đó
+	for _, item := range items {
+		warehouseID := item.ReservedWarehouseID(order.WarehouseID)
+		if warehouseID == nil {
+			continue
+		}
+		if err := s.inventoryRepo.ReleaseStockWithTx(ctx, tx, *warehouseID, item.BookID, item.Quantity, &userID); err != nil {
+			// Nếu lỗi là business (ví dụ BIZ02 – không đủ reserved) có thể log và tiếp tục
+			// Nếu là lỗi hệ thống (DB, connection) nên rollback toàn bộ
+			logger.Info("Failed to release stock when cancelling order", map[string]interface{}{
+				"order_id":     order.ID,
+				"warehouse_id": *warehouseID,
+				"book_id":      item.BookID,
+				"quantity":     item.Quantity,
+				"error":        err.Error(),
+			})
+			return fmt.Errorf("failed to release stock for book %s: %w", item.BookID.String(), err)
 		}
 	}
 
@@ -610,23 +893,16 @@ func (s *orderService) CancelOrder(
 		return fmt.Errorf("failed to create order status history: %w", err)
 	}
 
-	// 9. Commit transaction
-	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	// 9. InventorySyncJob ghi outbox trong TX (relay phát sau commit)
+	messages := inventorySyncMessages(orderItemBookIDs(items), "ORDER_CANCELLED")
+	messages = append(messages, orderStatusActivityMessages(order, order.Status, model.OrderStatusCancelled, &req.CancellationReason)...)
+	if err := outbox.AddWithTx(ctx, tx, messages...); err != nil {
+		return err
 	}
 
-	// 10. Enqueue InventorySyncJob sau commit
-	for _, item := range items {
-		payload := shared.InventorySyncPayload{
-			BookID: item.BookID.String(),
-			Source: "ORDER_CANCELLED",
-		}
-		if b, err := json.Marshal(payload); err == nil {
-			task := asynq.NewTask(shared.TypeInventorySyncBookStock, b)
-			if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
-				logger.Error("Failed to enqueue InventorySyncJob after cancel order", err)
-			}
-		}
+	// 10. Commit transaction
+	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	// Refund xử lý riêng (admin / payment service)
@@ -655,12 +931,29 @@ func (s *orderService) UpdateOrderStatus(
 		return err
 	}
 
-	// 3. Validate status transition
-	if err := s.validateStatusTransition(order.Status, req.Status); err != nil {
+	return s.transitionOrderStatus(ctx, order, req, &userID)
+}
+
+// transitionOrderStatus bước 3-9 của UpdateOrderStatus; changedBy nil = hệ thống (webhook hãng vận chuyển)
+func (s *orderService) transitionOrderStatus(
+	ctx context.Context,
+	order *model.Order,
+	req model.UpdateOrderStatusRequest,
+	changedBy *uuid.UUID,
+) error {
+	orderID := order.ID
+
+	// 3. Validate status transition theo workflow đang cấu hình
+	transition, err := s.validateStatusTransition(ctx, order.Status, req.Status)
+	if err != nil {
 		return err
 	}
 
 	// 4. Begin transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.orderRepo.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -694,19 +987,51 @@ func (s *orderService) UpdateOrderStatus(
 		OrderID:    orderID,
 		FromStatus: &order.Status,
 		ToStatus:   req.Status,
-		ChangedBy:  &userID,
+		ChangedBy:  changedBy,
 		Notes:      req.AdminNote,
 	}
+	if req.HistoryNote != nil {
+		statusHistory.Notes = req.HistoryNote
+	}
 	if err := s.orderRepo.CreateOrderStatusHistoryWithTx(ctx, tx, statusHistory); err != nil {
 		return fmt.Errorf("failed to create order status history: %w", err)
 	}
 
+	// 7b. Giao COD thành công = shipper đã thu tiền → ghi kiện chờ đối soát + sổ cái cùng transaction
+	if req.Status == model.OrderStatusDelivered && order.IsCOD() && s.codRecorder != nil {
+		trackingNumber := order.TrackingNumber
+		if req.TrackingNumber != nil {
+			trackingNumber = req.TrackingNumber
+		}
+		if err := s.codRecorder.RecordCODCollectionWithTx(ctx, tx, orderID, trackingNumber, order.Total, changedBy); err != nil {
+			return err
+		}
+	}
+
+	// 7c. Lịch sử đơn của khách (+ push nếu transition báo khách) ghi outbox cùng TX
+	messages := orderStatusActivityMessages(order, order.Status, req.Status, req.AdminNote)
+	if transition.HasHook(model.TransitionHookNotifyCustomer) {
+		messages = append(messages, orderStatusPushMessages(order, req.Status, statusHistory.ID)...)
+	}
+	if req.Status == model.OrderStatusConfirmed {
+		messages = append(messages, shipmentOnConfirmMessages(order)...)
+	}
+	if err := outbox.AddWithTx(ctx, tx, messages...); err != nil {
+		return err
+	}
+
 	// 8. Commit
 	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// (Optional) Enqueue event để gửi notification/ email cho user, v.v.
+	// 9. Side-effect của transition (best effort, trạng thái đã commit)
+	order.Status = req.Status
+	order.Version = req.Version + 1
+	if req.TrackingNumber != nil {
+		order.TrackingNumber = req.TrackingNumber
+	}
+	s.runTransitionHooks(context.WithoutCancel(ctx), order, transition, statusHistory.ID)
 
 	return nil
 }
@@ -786,26 +1111,44 @@ func (s *orderService) createOrderFromItems(
 	if err != nil {
 		return nil, err
 	}
+	// 3b. Giá đặc biệt (flash sale) thay giá niêm yết
+	for i := range bookItems {
+		if price, ok := req.PriceOverrides[bookItems[i].BookID]; ok {
+			bookItems[i].Price = price
+			bookItems[i].TierDiscountPercent = nil // Giá đặc biệt không cộng dồn bậc giá
+		}
+	}
 	subtotal := s.calculateItemsSubtotal(bookItems)
 
 	var discountAmount decimal.Decimal = decimal.Zero
 
-	// 5. Tính tổng tiền
+	// 5. Chọn warehouse (1 kho, không đủ thì tách kiện)
+	plan, err := s.planFulfillment(ctx, address, bookItems)
+	if err != nil {
+		return nil, err
+	}
+	selectedWarehouseID := plan.PrimaryWarehouseID()
+
+	// 6. Tính tổng tiền (phí ship hãng báo theo kiện)
 	isCOD := req.PaymentMethod == model.PaymentMethodCOD
-	_, finalDiscount, shippingFee, codFee, taxAmount, total := model.CalculateOrderAmounts(
+	taxLines, err := s.calculateTaxLines(ctx, address.Province, bookItems, discountAmount)
+	if err != nil {
+		return nil, err
+	}
+	_, finalDiscount, shippingFee, shippingDiscount, codFee, taxAmount, total := model.CalculateOrderAmounts(
 		subtotal,
 		discountAmount,
+		decimal.Zero,
 		isCOD,
+		s.shippingPolicyFor(ctx, plan, address, bookItems),
+		taxLines,
 	)
 
-	// 6. Chọn warehouse (V1: single warehouse)
-	selectedWH, err := s.selectSingleWarehouseForOrder(ctx, address, bookItems)
-	if err != nil {
-		return nil, err
-	}
-	selectedWarehouseID := selectedWH.ID
-
 	// 7. Bắt đầu transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.orderRepo.BeginTx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -813,49 +1156,59 @@ func (s *orderService) createOrderFromItems(
 	defer s.orderRepo.RollbackTx(ctx, tx)
 
 	// 8. Reserve inventory
-	for _, item := range bookItems {
-		if err := s.inventoryRepo.ReserveStockWithTx(ctx, tx, selectedWarehouseID, item.BookID, item.Quantity, &userID); err != nil {
-			return nil, model.NewOrderError(
-				model.ErrCodeInsufficientStock,
-				fmt.Sprintf("Failed to reserve stock for book: %s", item.BookID),
-				err,
-			)
-		}
+	if err := s.reserveFulfillmentWithTx(ctx, tx, plan, bookItems, userID); err != nil {
+		return nil, err
 	}
 
 	// 9. Build order entity
 	orderID := uuid.New()
 
 	order := &model.Order{
-		ID:             orderID,
-		UserID:         userID,
-		AddressID:      req.AddressID,
-		PromotionID:    nil,
-		WarehouseID:    &selectedWarehouseID,
-		Subtotal:       subtotal,
-		ShippingFee:    shippingFee,
-		CODFee:         codFee,
-		DiscountAmount: finalDiscount,
-		TaxAmount:      taxAmount,
-		Total:          total,
-		PaymentMethod:  req.PaymentMethod,
-		PaymentStatus:  model.PaymentStatusPending,
-		CustomerNote:   req.CustomerNote,
-		Version:        0,
-	}
-
-	if isCOD {
+		ID:               orderID,
+		UserID:           userID,
+		AddressID:        req.AddressID,
+		PromotionID:      nil,
+		WarehouseID:      &selectedWarehouseID,
+		Subtotal:         subtotal,
+		ShippingFee:      shippingFee,
+		ShippingDiscount: shippingDiscount,
+		CODFee:           codFee,
+		DiscountAmount:   finalDiscount,
+		TaxAmount:        taxAmount,
+		Total:            total,
+		PaymentMethod:    req.PaymentMethod,
+		PaymentStatus:    model.PaymentStatusPending,
+		CustomerNote:     req.CustomerNote,
+		Version:          0,
+		Metadata:         req.Metadata,
+		Channel:          req.Channel,
+		ExternalOrderID:  req.ExternalOrderID,
+	}
+
+	if req.Paid {
+		order.PaymentStatus = model.PaymentStatusPaid
+	}
+	if isCOD || req.Paid {
 		order.Status = model.OrderStatusConfirmed
 	} else {
 		order.Status = model.OrderStatusPending
 	}
 	// 10. Insert order
 	if err := s.orderRepo.CreateOrderWithTx(ctx, tx, order); err != nil {
+		if errors.Is(err, model.ErrExternalOrderExists) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	if err := s.orderRepo.CreateOrderTaxLinesWithTx(ctx, tx, orderID, taxLines); err != nil {
+		return nil, err
+	}
 
-	// 11. Insert order items
+	// 11. Insert kiện theo kho + order items
 	orderItems := s.buildOrderItems(orderID, bookItems)
+	if err := s.createShipmentsWithTx(ctx, tx, plan, orderID, orderItems); err != nil {
+		return nil, err
+	}
 
 	if err := s.orderRepo.CreateOrderItemsWithTx(ctx, tx, orderItems); err != nil {
 		return nil, fmt.Errorf("failed to create order items: %w", err)
@@ -873,69 +1226,358 @@ func (s *orderService) createOrderFromItems(
 		return nil, fmt.Errorf("failed to create order status history: %w", err)
 	}
 
+	// 13. Jobs hậu commit ghi outbox TRONG TX: đơn COD / đã thanh toán → tạo vận đơn,
+	// chưa thanh toán → auto-release giữ hàng (enqueue sau commit lỗi = tồn bị giữ vĩnh viễn)
+	messages := inventorySyncMessages(orderItemBookIDs(orderItems), "SALE")
+	messages = append(messages, shipmentOnConfirmMessages(order)...)
+	if !req.Paid {
+		messages = append(messages, autoReleaseReservationMessages(order)...)
+	}
+	if err := outbox.AddWithTx(ctx, tx, messages...); err != nil {
+		return nil, err
+	}
+
 	// 14. Commit
 	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// 15. Jobs sau commit
-	for _, item := range orderItems {
-		payload := shared.InventorySyncPayload{
-			BookID: item.BookID.String(),
+	// 15. Response
+	resp := &model.CreateOrderResponse{
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Total:       order.Total,
+		Status:      order.Status,
+		PaymentURL:  nil,
+	}
+
+	return resp, nil
+}
+
+// autoReleaseReservationMessages huỷ đơn + trả tồn nếu quá hạn thanh toán mà chưa paid
+func autoReleaseReservationMessages(order *model.Order) []outbox.Message {
+	message, err := outbox.NewMessage(shared.TypeAutoReleaseReservation, cartModel.AutoReleaseReservationPayload{
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		UserID:      order.UserID,
+	}, shared.QueueInventory)
+	if err != nil {
+		logger.Error("Failed to build auto-release outbox message", err)
+		return nil
+	}
+	return []outbox.Message{message.
+		WithMaxRetry(3).
+		WithDelay(cartModel.OnlinePaymentWindow).
+		WithDedupKey("auto_release:" + order.ID.String())}
+}
+
+// =====================================================
+// FLASH SALE ORDER
+// =====================================================
+
+// CreateFlashSaleOrder - tạo order 1 sách với giá flash sale.
+// Caller (flash sale service) đã kiểm tra purchase token trước khi gọi,
+// nên reserve_stock chỉ bị gọi bởi user đã được admit khỏi hàng đợi.
+func (s *orderService) CreateFlashSaleOrder(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.CreateFlashSaleOrderRequest,
+) (*model.CreateOrderResponse, error) {
+	note := fmt.Sprintf("Flash sale: %s", req.FlashSaleName)
+	return s.createOrderFromItems(ctx, userID, model.CreateOrderFromItemsRequest{
+		AddressID:     req.AddressID,
+		PaymentMethod: req.PaymentMethod,
+		CustomerNote:  &note,
+		Items: []model.CreateOrderItem{
+			{BookID: req.BookID, Quantity: req.Quantity},
+		},
+		PriceOverrides: map[uuid.UUID]decimal.Decimal{req.BookID: req.UnitPrice},
+	})
+}
+
+// =====================================================
+// B2B: CREATE ORDER FROM QUOTE
+// =====================================================
+
+// CreateOrderFromQuote - convert quote B2B đã duyệt thành order.
+// Khác createOrderFromItems:
+//   - Đơn giá lấy theo quote (admin duyệt), không phải giá niêm yết
+//   - Không giới hạn 100 quantity/item như cart
+//   - payment_method = invoice: confirmed ngay, không auto-release reservation,
+//     thanh toán theo hạn công nợ (order_payment_terms)
+func (s *orderService) CreateOrderFromQuote(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.CreateQuoteOrderRequest,
+) (*model.CreateOrderResponse, error) {
+	// 1. Validate request
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid quote order request", err)
+	}
+
+	// 2. Address
+	address, err := s.addressRepo.GetByID(ctx, req.AddressID)
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Invalid shipping address", err)
+	}
+
+	// 3. Book snapshot (title, author, cover) + đơn giá quote
+	bookIDs := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		bookIDs[i] = item.BookID.String()
+	}
+	books, err := s.bookService.GetBooksCheckout(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	bookByID := make(map[uuid.UUID]int, len(books))
+	for i, book := range books {
+		bookByID[book.ID] = i
+	}
+
+	bookItems := make([]bookItemData, 0, len(req.Items))
+	for _, item := range req.Items {
+		idx, ok := bookByID[item.BookID]
+		if !ok {
+			return nil, model.NewOrderError(
+				model.ErrCodeInvalidOrder,
+				fmt.Sprintf("Book is no longer available: %s", item.BookID),
+				nil,
+			)
+		}
+		book := books[idx]
+		if book.IsDiscontinued() {
+			return nil, s.discontinuedError(ctx, book)
+		}
+		coverURL := ""
+		if book.CoverURL != nil {
+			coverURL = *book.CoverURL
+		}
+		bookItems = append(bookItems, bookItemData{
+			BookID:     book.ID,
+			Quantity:   item.Quantity,
+			Price:      item.UnitPrice,
+			Title:      book.Title,
+			AuthorName: book.AuthorName,
+			CoverURL:   coverURL,
+			CategoryID: book.CategoryID,
+		})
+	}
+	subtotal := s.calculateItemsSubtotal(bookItems)
+
+	// 4. Chọn warehouse (1 kho, không đủ thì tách kiện)
+	plan, err := s.planFulfillment(ctx, address, bookItems)
+	if err != nil {
+		return nil, err
+	}
+	selectedWarehouseID := plan.PrimaryWarehouseID()
+
+	// 5. Tính tổng tiền (giá quote đã là giá cuối, không áp promo; phí ship hãng báo theo kiện)
+	taxLines, err := s.calculateTaxLines(ctx, address.Province, bookItems, decimal.Zero)
+	if err != nil {
+		return nil, err
+	}
+	_, finalDiscount, shippingFee, shippingDiscount, codFee, taxAmount, total := model.CalculateOrderAmounts(
+		subtotal,
+		decimal.Zero,
+		decimal.Zero,
+		false,
+		s.shippingPolicyFor(ctx, plan, address, bookItems),
+		taxLines,
+	)
+
+	// 6. Bắt đầu transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.orderRepo.RollbackTx(ctx, tx)
+
+	// 7. Reserve inventory
+	if err := s.reserveFulfillmentWithTx(ctx, tx, plan, bookItems, userID); err != nil {
+		return nil, err
+	}
+
+	// 8. Insert order
+	orderID := uuid.New()
+	order := &model.Order{
+		ID:               orderID,
+		UserID:           userID,
+		AddressID:        req.AddressID,
+		WarehouseID:      &selectedWarehouseID,
+		Subtotal:         subtotal,
+		ShippingFee:      shippingFee,
+		ShippingDiscount: shippingDiscount,
+		CODFee:           codFee,
+		DiscountAmount:   finalDiscount,
+		TaxAmount:        taxAmount,
+		Total:            total,
+		PaymentMethod:    model.PaymentMethodInvoice,
+		PaymentStatus:    model.PaymentStatusPending,
+		Status:           model.OrderStatusConfirmed,
+		CustomerNote:     req.CustomerNote,
+		Version:          0,
+	}
+	if err := s.orderRepo.CreateOrderWithTx(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+	if err := s.orderRepo.CreateOrderTaxLinesWithTx(ctx, tx, orderID, taxLines); err != nil {
+		return nil, err
+	}
+
+	// 9. Insert kiện theo kho + order items
+	orderItems := s.buildOrderItems(orderID, bookItems)
+	if err := s.createShipmentsWithTx(ctx, tx, plan, orderID, orderItems); err != nil {
+		return nil, err
+	}
+	if err := s.orderRepo.CreateOrderItemsWithTx(ctx, tx, orderItems); err != nil {
+		return nil, fmt.Errorf("failed to create order items: %w", err)
+	}
+
+	// 10. Payment terms (hạn công nợ tính từ lúc tạo đơn)
+	terms := &model.OrderPaymentTerms{
+		OrderID:     orderID,
+		QuoteID:     req.QuoteID,
+		CompanyName: req.CompanyName,
+		TaxCode:     req.TaxCode,
+		TermsDays:   req.TermsDays,
+		DueAt:       time.Now().AddDate(0, 0, req.TermsDays),
+	}
+	if err := s.orderRepo.CreateOrderPaymentTermsWithTx(ctx, tx, terms); err != nil {
+		return nil, err
+	}
+
+	// 11. Status history
+	note := fmt.Sprintf("Created from quote %s", req.QuoteID)
+	statusHistory := &model.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: nil,
+		ToStatus:   order.Status,
+		ChangedBy:  &userID,
+		Notes:      &note,
+	}
+	if err := s.orderRepo.CreateOrderStatusHistoryWithTx(ctx, tx, statusHistory); err != nil {
+		return nil, fmt.Errorf("failed to create order status history: %w", err)
+	}
+	if err := outbox.AddWithTx(ctx, tx, shipmentOnConfirmMessages(order)...); err != nil {
+		return nil, err
+	}
+
+	// 12. Commit
+	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// 13. Jobs sau commit (không auto-release: đơn invoice không chờ thanh toán online)
+	for _, item := range orderItems {
+		payload := shared.InventorySyncPayload{
+			BookID: item.BookID.String(),
 			Source: "SALE",
 		}
 		if b, err := json.Marshal(payload); err == nil {
-			task := asynq.NewTask(shared.TypeInventorySyncBookStock, b)
+			task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
 			if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
-				logger.Error("Failed to enqueue InventorySyncJob after reorder", err)
+				logger.Error("Failed to enqueue InventorySyncJob after quote conversion", err)
 			}
 		}
 	}
-	go s.enqueueAutoReleaseReservation(order.ID, order.OrderNumber, userID)
-	// 16. Response
-	resp := &model.CreateOrderResponse{
+
+	return &model.CreateOrderResponse{
 		OrderID:     order.ID,
 		OrderNumber: order.OrderNumber,
 		Total:       order.Total,
 		Status:      order.Status,
 		PaymentURL:  nil,
+	}, nil
+}
+
+// MarkInvoicePaid - admin ghi nhận khách B2B đã chuyển khoản công nợ
+func (s *orderService) MarkInvoicePaid(ctx context.Context, orderID uuid.UUID) error {
+	if err := s.orderRepo.MarkInvoicePaid(ctx, orderID); err != nil {
+		return err
 	}
 
-	return resp, nil
+	logger.Info("Invoice order marked as paid", map[string]interface{}{
+		"order_id": orderID,
+	})
+	return nil
 }
-func (s *orderService) enqueueAutoReleaseReservation(orderID uuid.UUID, orderNumber string, userID uuid.UUID) {
-	payload := cartModel.AutoReleaseReservationPayload{
-		OrderID:     orderID,
-		OrderNumber: orderNumber,
-		UserID:      userID,
+
+// =====================================================
+// ADMIN: ORDER DETAIL + CONTACT HISTORY
+// =====================================================
+
+func (s *orderService) GetAdminOrderDetail(ctx context.Context, orderID uuid.UUID) (*model.AdminOrderDetailResponse, error) {
+	detail, err := s.GetOrderByIDWithoutUser(ctx, orderID)
+	if err != nil {
+		return nil, err
 	}
 
-	task, err := utils.MarshalTask(shared.TypeAutoReleaseReservation, payload)
+	communications, err := s.orderRepo.ListOrderCommunications(ctx, orderID)
 	if err != nil {
-		logger.Info("Failed to marshal auto-release task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
-		})
-		return
+		return nil, err
 	}
 
-	_, err = s.asynq.Enqueue(task,
-		asynq.Queue(shared.QueueInventory), // High priority
-		asynq.MaxRetry(3),                  // Critical task
-		asynq.ProcessIn(15*time.Minute),    // Execute after 15 minutes
-	)
+	deliveryProblems := 0
+	for _, comm := range communications {
+		if comm.HasDeliveryProblem() {
+			deliveryProblems++
+		}
+	}
 
+	modifications, err := s.orderRepo.ListOrderModifications(ctx, orderID)
 	if err != nil {
-		logger.Info("Failed to enqueue auto-release task", map[string]interface{}{
-			"order_id": orderID,
-			"error":    err.Error(),
-		})
-	} else {
-		logger.Info("Enqueued auto-release reservation task", map[string]interface{}{
-			"order_id":   orderID,
-			"execute_at": time.Now().Add(15 * time.Minute).Format(time.RFC3339),
-		})
+		return nil, err
+	}
+
+	replacementOf, err := s.orderRepo.GetReplacementOf(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	replacements, err := s.orderRepo.ListReplacements(ctx, orderID)
+	if err != nil {
+		return nil, err
 	}
+
+	return &model.AdminOrderDetailResponse{
+		OrderDetailResponse: detail,
+		Communications:      communications,
+		DeliveryProblems:    deliveryProblems,
+		Modifications:       modifications,
+		ReplacementOf:       replacementOf,
+		Replacements:        replacements,
+	}, nil
+}
+
+func (s *orderService) LogCommunication(ctx context.Context, orderID uuid.UUID, staffID uuid.UUID, req model.LogCommunicationRequest) (*model.OrderCommunication, error) {
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid communication", err)
+	}
+
+	// Đơn phải tồn tại (repo map ErrNoRows → ErrOrderNotFound)
+	if _, err := s.orderRepo.GetOrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	comm := &model.OrderCommunication{
+		OrderID:   orderID,
+		Channel:   req.Channel,
+		Purpose:   req.Purpose,
+		Status:    req.Status,
+		Recipient: req.Recipient,
+		Subject:   req.Subject,
+		Note:      req.Note,
+		CreatedBy: &staffID,
+	}
+	if err := s.orderRepo.CreateOrderCommunication(ctx, comm); err != nil {
+		return nil, err
+	}
+
+	return comm, nil
 }
 
 // =====================================================
@@ -950,7 +1592,7 @@ func (s *orderService) ListAllOrders(
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	orders, total, err := s.orderRepo.ListAllOrders(ctx, req.Status, req.Page, req.Limit)
+	orders, total, err := s.orderRepo.ListAllOrders(ctx, req.Status, req.MetadataKey, req.MetadataValue, req.Channel, req.Page, req.Limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list all orders: %w", err)
 	}
@@ -981,16 +1623,21 @@ func (s *orderService) ListAllOrders(
 	orderSummaries := make([]model.OrderSummaryResponse, 0, len(orders))
 	for _, order := range orders {
 		itemsCount := itemsCountMap[order.ID] // default 0 nếu không có key
+		packaging := order.Packaging()
 
 		orderSummaries = append(orderSummaries, model.OrderSummaryResponse{
-			ID:            order.ID,
-			OrderNumber:   order.OrderNumber,
-			Status:        order.Status,
-			PaymentMethod: order.PaymentMethod,
-			PaymentStatus: order.PaymentStatus,
-			Total:         order.Total,
-			ItemsCount:    itemsCount,
-			CreatedAt:     order.CreatedAt,
+			ID:              order.ID,
+			OrderNumber:     order.OrderNumber,
+			Status:          order.Status,
+			PaymentMethod:   order.PaymentMethod,
+			PaymentStatus:   order.PaymentStatus,
+			Total:           order.Total,
+			ItemsCount:      itemsCount,
+			CreatedAt:       order.CreatedAt,
+			Packaging:       &packaging,
+			Metadata:        order.Metadata,
+			Channel:         order.Channel,
+			ExternalOrderID: order.ExternalOrderID,
 		})
 	}
 
@@ -1050,6 +1697,9 @@ func (s *orderService) GetOrderByNumber(
 
 	// 5. Build response
 	response := s.buildOrderDetailResponse(order, items, *addr)
+	if err := s.attachGift(ctx, response); err != nil {
+		return nil, err
+	}
 	return response, nil
 }
 
@@ -1069,22 +1719,70 @@ func (s *orderService) validateAndFetchBookItems(ctx context.Context, items []mo
 		return nil, err
 	}
 
-	result := make([]bookItemData, len(items))
+	tierBookIDs := make([]uuid.UUID, len(books))
+	for i, book := range books {
+		tierBookIDs[i] = book.ID
+	}
+	tiers, err := s.priceTiers.GetTiersForBooks(ctx, tierBookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price tiers: %w", err)
+	}
+
+	// Map theo book_id: cùng 1 sách có thể vừa là dòng mua vừa là dòng quà
+	booksByID := make(map[uuid.UUID]int, len(books))
 	for i, book := range books {
-		// Mock data - replace with actual book service call
+		booksByID[book.ID] = i
+	}
+
+	result := make([]bookItemData, len(items))
+	for i, item := range items {
+		idx, ok := booksByID[item.BookID]
+		if !ok {
+			return nil, fmt.Errorf("book not found: %s", item.BookID)
+		}
+		book := books[idx]
+		if book.IsDiscontinued() {
+			return nil, s.discontinuedError(ctx, book)
+		}
+
+		// Đơn giá theo bậc số lượng (khớp cart_items.price)
+		unitPrice, tier := tiers[book.ID].Apply(book.Price, item.Quantity)
+		if item.GiftPromotionID != nil {
+			unitPrice, tier = decimal.Zero, nil // Quà tặng 0đ, ListPrice giữ giá bìa
+		}
 		result[i] = bookItemData{
-			BookID:     book.ID,
-			Quantity:   items[i].Quantity,
-			Price:      book.Price,
-			Title:      book.Title,
-			AuthorName: book.AuthorName,
-			CoverURL:   *book.CoverURL,
+			BookID:          book.ID,
+			Quantity:        item.Quantity,
+			Price:           unitPrice,
+			ListPrice:       book.Price,
+			Title:           book.Title,
+			AuthorName:      book.AuthorName,
+			CoverURL:        *book.CoverURL,
+			CategoryID:      book.CategoryID,
+			GiftPromotionID: item.GiftPromotionID,
+			Metadata:        item.Metadata,
+		}
+		if tier != nil {
+			result[i].TierDiscountPercent = &tier.DiscountPercent
 		}
 	}
 
 	return result, nil
 }
 
+// discontinuedError OrderError ITEM_DISCONTINUED kèm gợi ý thay thế (lỗi gợi ý không chặn, chỉ bỏ gợi ý)
+func (s *orderService) discontinuedError(ctx context.Context, book bookModel.BookCheckoutResponse) error {
+	replacement, err := s.bookService.SuggestReplacement(ctx, book.ID)
+	if err != nil {
+		logger.Error("Failed to suggest replacement for discontinued book", err)
+	}
+	return model.NewOrderError(
+		model.ErrCodeItemDiscontinued,
+		fmt.Sprintf("Book has been discontinued: %s", book.Title),
+		&model.ItemDiscontinuedError{BookID: book.ID, Title: book.Title, Replacement: replacement},
+	)
+}
+
 // bookItemData holds book details for order creation
 type bookItemData struct {
 	BookID     uuid.UUID
@@ -1093,6 +1791,15 @@ type bookItemData struct {
 	Title      string
 	AuthorName string
 	CoverURL   string
+	CategoryID *uuid.UUID // chọn rule thuế theo danh mục
+
+	// Giá bìa + bậc giá đã áp (zero/nil khi Price là giá quote/giá đặc biệt)
+	ListPrice           decimal.Decimal
+	TierDiscountPercent *decimal.Decimal
+
+	GiftPromotionID *uuid.UUID // dòng quà tặng kèm đơn (Price = 0)
+
+	Metadata model.Metadata // order_items.metadata
 }
 
 // calculateItemsSubtotal calculates total subtotal from all items
@@ -1105,6 +1812,32 @@ func (s *orderService) calculateItemsSubtotal(items []bookItemData) decimal.Deci
 	return subtotal
 }
 
+// resolveFallbackAddress địa chỉ giao khi request không gửi address_id:
+// default address → địa chỉ của đơn giao thành công gần nhất → ORD lỗi địa chỉ
+func (s *orderService) resolveFallbackAddress(ctx context.Context, userID uuid.UUID) (*addressModel.Address, error) {
+	addr, err := s.addressRepo.GetDefaultByUserID(ctx, userID)
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Missing default address", err)
+	}
+	if addr != nil {
+		return addr, nil
+	}
+
+	addr, err = s.addressRepo.GetLastDeliveredByUserID(ctx, userID)
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Missing default address", err)
+	}
+	if addr == nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Missing default address", nil)
+	}
+
+	logger.Info("Using last delivered address as shipping address", map[string]interface{}{
+		"user_id":    userID.String(),
+		"address_id": addr.ID.String(),
+	})
+	return addr, nil
+}
+
 // validatePromotion validates promotion code
 func (s *orderService) validatePromotion(promo *modelPromo.Promotion, subtotal decimal.Decimal, userID uuid.UUID) error {
 	// Check if promotion is active
@@ -1167,19 +1900,37 @@ func (s *orderService) calculateDiscount(promo *modelPromo.Promotion, subtotal d
 	return decimal.Zero
 }
 
+// calculateShippingDiscount số tiền mã giảm ship giảm được trên phí ship của đơn (0 với mã giảm tiền hàng)
+func (s *orderService) calculateShippingDiscount(promo *modelPromo.Promotion, subtotal decimal.Decimal) decimal.Decimal {
+	return model.ShippingPromoDiscount(
+		string(promo.DiscountType),
+		promo.DiscountValue,
+		promo.MaxDiscountAmount,
+		s.checkoutPolicy.ShippingFeeFor(subtotal),
+	)
+}
+
 // buildOrderItems builds order items from book items
 func (s *orderService) buildOrderItems(orderID uuid.UUID, bookItems []bookItemData) []model.OrderItem {
 	items := make([]model.OrderItem, len(bookItems))
 	for i, book := range bookItems {
+		listPrice := book.ListPrice
+		if listPrice.IsZero() {
+			listPrice = book.Price // Giá quote: không có giá bìa riêng
+		}
 		items[i] = model.OrderItem{
-			ID:         uuid.New(),
-			OrderID:    orderID,
-			BookID:     book.BookID,
-			Quantity:   book.Quantity,
-			Price:      book.Price,
-			AuthorName: &book.AuthorName,
-			BookTitle:  book.Title,
-			Subtotal:   book.Price.Mul(decimal.NewFromInt(int64(book.Quantity))),
+			ID:                  uuid.New(),
+			OrderID:             orderID,
+			BookID:              book.BookID,
+			Quantity:            book.Quantity,
+			Price:               book.Price,
+			AuthorName:          &book.AuthorName,
+			BookTitle:           book.Title,
+			Subtotal:            book.Price.Mul(decimal.NewFromInt(int64(book.Quantity))),
+			ListPrice:           listPrice,
+			TierDiscountPercent: book.TierDiscountPercent,
+			GiftPromotionID:     book.GiftPromotionID,
+			Metadata:            book.Metadata,
 		}
 	}
 	return items
@@ -1204,6 +1955,15 @@ func (s *orderService) buildOrderDetailResponse(
 			Quantity:     item.Quantity,
 			Price:        item.Price,
 			Subtotal:     item.Subtotal,
+
+			ListPrice:           item.ListPrice,
+			TierDiscountPercent: item.TierDiscountPercent,
+			Savings:             item.TierSavings(),
+
+			IsFreeGift:      item.GiftPromotionID != nil,
+			GiftPromotionID: item.GiftPromotionID,
+
+			Metadata: item.Metadata,
 		}
 	}
 
@@ -1227,6 +1987,7 @@ func (s *orderService) buildOrderDetailResponse(
 		PaymentStatus:       order.PaymentStatus,
 		Subtotal:            order.Subtotal,
 		ShippingFee:         order.ShippingFee,
+		ShippingDiscount:    order.ShippingDiscount,
 		CODFee:              order.CODFee,
 		DiscountAmount:      order.DiscountAmount,
 		TaxAmount:           order.TaxAmount,
@@ -1244,40 +2005,21 @@ func (s *orderService) buildOrderDetailResponse(
 		UpdatedAt:           order.UpdatedAt,
 		CancelledAt:         order.CancelledAt,
 		Version:             order.Version,
+		Packaging:           order.Packaging(),
+		Metadata:            order.Metadata,
+		Channel:             order.Channel,
+		ExternalOrderID:     order.ExternalOrderID,
 	}
 }
 
-// validateStatusTransition validates if status transition is allowed
-func (s *orderService) validateStatusTransition(currentStatus, newStatus string) error {
-	// Define allowed transitions
-	allowedTransitions := map[string][]string{
-		model.OrderStatusPending:    {model.OrderStatusConfirmed, model.OrderStatusCancelled},
-		model.OrderStatusConfirmed:  {model.OrderStatusProcessing, model.OrderStatusCancelled},
-		model.OrderStatusProcessing: {model.OrderStatusShipping, model.OrderStatusCancelled},
-		model.OrderStatusShipping:   {model.OrderStatusDelivered, model.OrderStatusReturned},
-		model.OrderStatusDelivered:  {model.OrderStatusReturned},
-	}
-
-	allowed, exists := allowedTransitions[currentStatus]
-	if !exists {
-		return model.NewOrderError(
-			model.ErrCodeInvalidStatus,
-			fmt.Sprintf("Cannot transition from status '%s'", currentStatus),
-			model.ErrInvalidStatus,
-		)
-	}
-
-	for _, allowedStatus := range allowed {
-		if allowedStatus == newStatus {
-			return nil
-		}
+// attachGift gắn thông tin quà tặng (nếu có) vào order detail
+func (s *orderService) attachGift(ctx context.Context, response *model.OrderDetailResponse) error {
+	gift, err := s.orderRepo.GetOrderGift(ctx, response.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get order gift: %w", err)
 	}
-
-	return model.NewOrderError(
-		model.ErrCodeInvalidStatus,
-		fmt.Sprintf("Cannot transition from '%s' to '%s'", currentStatus, newStatus),
-		model.ErrInvalidStatus,
-	)
+	response.Gift = model.BuildOrderGiftResponse(gift)
+	return nil
 }
 
 // internal/domains/order/service/implement.go
@@ -1307,6 +2049,12 @@ func (s *orderService) GetOrderByIDWithoutUser(
 
 	// Build response
 	response := s.buildOrderDetailResponse(order, items, *address)
+	if err := s.attachGift(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := s.attachShipments(ctx, items, response); err != nil {
+		return nil, err
+	}
 
 	return response, nil
 }
@@ -1331,6 +2079,10 @@ func (s *orderService) CancelOrderBySystem(
 	}
 
 	// Step 3: Start transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.orderRepo.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -1343,20 +2095,23 @@ func (s *orderService) CancelOrderBySystem(
 		return fmt.Errorf("failed to get order items: %w", err)
 	}
 
-	if order.WarehouseID != nil {
-		for _, item := range items {
-			// Release stock with system user (nil)
-			err = s.inventoryRepo.ReleaseStockWithTx(
-				ctx,
-				tx,
-				*order.WarehouseID,
-				item.BookID,
-				item.Quantity,
-				nil,
-			)
-			if err != nil {
-				fmt.Printf("Warning: failed to release stock for book %s: %v\n", item.BookID, err)
-			}
+	for _, item := range items {
+		// Đơn tách kiện: release tại kho của kiện chứa dòng hàng
+		warehouseID := item.ReservedWarehouseID(order.WarehouseID)
+		if warehouseID == nil {
+			continue
+		}
+		// Release stock with system user (nil)
+		err = s.inventoryRepo.ReleaseStockWithTx(
+			ctx,
+			tx,
+			*warehouseID,
+			item.BookID,
+			item.Quantity,
+			nil,
+		)
+		if err != nil {
+			fmt.Printf("Warning: failed to release stock for book %s: %v\n", item.BookID, err)
 		}
 	}
 
@@ -1386,6 +2141,11 @@ func (s *orderService) CancelOrderBySystem(
 	if err := s.orderRepo.CreateOrderStatusHistoryWithTx(ctx, tx, statusHistory); err != nil {
 		return fmt.Errorf("failed to create status history: %w", err)
 	}
+	messages := orderStatusActivityMessages(order, order.Status, model.OrderStatusCancelled, &reason)
+	messages = append(messages, orderStatusPushMessages(order, model.OrderStatusCancelled, statusHistory.ID)...)
+	if err := outbox.AddWithTx(ctx, tx, messages...); err != nil {
+		return err
+	}
 
 	// Step 7: Commit transaction
 	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
@@ -1398,7 +2158,7 @@ func (s *orderService) CancelOrderBySystem(
 			Source: "RELEASE", // hoặc "ORDER_CANCELLED"
 		}
 		b, _ := json.Marshal(payload)
-		task := asynq.NewTask(shared.TypeInventorySyncBookStock, b)
+		task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
 		if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
 			logger.Error("Failed to enqueue InventorySyncJob after cancel order", err)
 		}
@@ -1409,3 +2169,122 @@ func (s *orderService) CancelOrderBySystem(
 
 	return nil
 }
+
+// =====================================================
+// SHIPMENT & INVOICE
+// =====================================================
+
+// GetPackingSlip builds packing slip cho kho (gift receipt → không in giá)
+func (s *orderService) GetPackingSlip(ctx context.Context, orderID uuid.UUID) (*model.PackingSlipResponse, error) {
+	order, items, gift, address, err := s.loadOrderDocuments(ctx, orderID, nil)
+	if err != nil {
+		return nil, err
+	}
+	slip := model.BuildPackingSlip(order, items, gift, address)
+
+	// Bản giới hạn: in serial từng cuốn để kho đối chiếu khi đóng hàng
+	serials, err := s.orderRepo.GetOrderItemSerials(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		slip.Items[i].SerialNumbers = serials[item.ID]
+	}
+	return slip, nil
+}
+
+// GetInvoice builds invoice; userID = nil khi admin xem
+func (s *orderService) GetInvoice(ctx context.Context, orderID uuid.UUID, userID *uuid.UUID) (*model.InvoiceResponse, error) {
+	order, items, gift, address, err := s.loadOrderDocuments(ctx, orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	invoice := model.BuildInvoice(order, items, gift, address)
+
+	// Dòng thuế snapshot lúc đặt (đơn trước khi có cấu hình thuế: rỗng)
+	invoice.TaxLines, err = s.orderRepo.ListOrderTaxLines(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Đơn B2B: bill-to doanh nghiệp + hạn thanh toán
+	terms, err := s.orderRepo.GetOrderPaymentTerms(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order payment terms: %w", err)
+	}
+	model.ApplyPaymentTerms(invoice, terms, order.PaymentStatus, time.Now())
+
+	// Hoá đơn điện tử (VAT) đã phát hành qua nhà cung cấp
+	invoice.EInvoice, err = s.orderRepo.GetEInvoiceRef(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order e-invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// loadOrderDocuments load order + items + gift + địa chỉ người mua cho packing slip / invoice
+func (s *orderService) loadOrderDocuments(
+	ctx context.Context,
+	orderID uuid.UUID,
+	userID *uuid.UUID,
+) (*model.Order, []model.OrderItem, *model.OrderGift, *addressModel.Address, error) {
+	var order *model.Order
+	var err error
+	if userID != nil {
+		order, err = s.orderRepo.GetOrderByIDAndUserID(ctx, orderID, *userID)
+	} else {
+		order, err = s.orderRepo.GetOrderByID(ctx, orderID)
+	}
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	items, err := s.orderRepo.GetOrderItemsByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	gift, err := s.orderRepo.GetOrderGift(ctx, order.ID)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to get order gift: %w", err)
+	}
+
+	address, err := s.addressRepo.GetByID(ctx, order.AddressID)
+	if err != nil {
+		logger.Info("Order address not found, building document without buyer address", map[string]interface{}{
+			"order_id":   order.ID,
+			"address_id": order.AddressID,
+			"error":      err.Error(),
+		})
+		address = nil
+	}
+
+	return order, items, gift, address, nil
+}
+
+// inventorySyncMessages task sync tồn ra Redis của các sách, ghi outbox cùng transaction nghiệp vụ
+func inventorySyncMessages(bookIDs []uuid.UUID, source string) []outbox.Message {
+	messages := make([]outbox.Message, 0, len(bookIDs))
+	for _, bookID := range bookIDs {
+		message, err := outbox.NewMessage(shared.TypeInventorySyncBookStock, shared.InventorySyncPayload{
+			BookID: bookID.String(),
+			Source: source,
+		}, shared.QueueInventory)
+		if err != nil {
+			// Payload struct cố định, không thể lỗi marshal
+			logger.Error("Failed to build InventorySync outbox message", err)
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func orderItemBookIDs(items []model.OrderItem) []uuid.UUID {
+	bookIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+	return bookIDs
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// ORDER STATUS IMPORT (cập nhật trạng thái hàng loạt từ CSV)
+// =====================================================
+// Upload: parse + validate từng dòng ngay, lưu toàn bộ dòng (kể cả dòng lỗi) để staff xem lại.
+// Job order:bulk_update_status: chuyển từng đơn qua transitionOrderStatus (validateStatusTransition,
+// lịch sử, outbox, hook như PATCH /admin/orders/:id/status), ghi kết quả sau mỗi đơn → retry chỉ xử lý dòng pending.
+
+// CreateOrderStatusImport - upload file, trả import + các dòng đã parse (status pending / failed)
+func (s *orderService) CreateOrderStatusImport(
+	ctx context.Context,
+	adminID uuid.UUID,
+	fileName string,
+	file io.Reader,
+) (*model.OrderStatusImportResponse, error) {
+	rows, err := parseOrderStatusImportCSV(file)
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidImport, err.Error(), model.ErrInvalidImportFile)
+	}
+
+	importID := uuid.New()
+	imp := &model.OrderStatusImport{
+		ID:        importID,
+		FileName:  fileName,
+		Status:    model.OrderStatusImportPending,
+		TotalRows: len(rows),
+		CreatedBy: &adminID,
+	}
+	for i := range rows {
+		rows[i].ID = uuid.New()
+		rows[i].ImportID = importID
+		if rows[i].Status == model.OrderStatusImportRowFailed {
+			imp.FailedRows++
+		}
+	}
+
+	if err := s.orderRepo.CreateOrderStatusImport(ctx, imp, rows); err != nil {
+		return nil, err
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeBulkUpdateOrderStatus, model.ProcessOrderStatusImportPayload{ImportID: importID})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueOrder), asynq.MaxRetry(3)); err != nil {
+		return nil, fmt.Errorf("failed to enqueue order status import: %w", err)
+	}
+
+	logger.Info("Order status import queued", map[string]interface{}{
+		"import_id":   importID,
+		"admin_id":    adminID,
+		"file_name":   fileName,
+		"rows":        imp.TotalRows,
+		"failed_rows": imp.FailedRows,
+	})
+
+	return &model.OrderStatusImportResponse{OrderStatusImport: *imp, Rows: rows}, nil
+}
+
+// GetOrderStatusImport - xem tiến độ + lỗi từng dòng (rowStatus: "" | pending | updated | failed)
+func (s *orderService) GetOrderStatusImport(ctx context.Context, importID uuid.UUID, rowStatus string) (*model.OrderStatusImportResponse, error) {
+	switch rowStatus {
+	case "", model.OrderStatusImportRowPending, model.OrderStatusImportRowUpdated, model.OrderStatusImportRowFailed:
+	default:
+		return nil, model.NewOrderError(model.ErrCodeInvalidImport, fmt.Sprintf("Invalid row status '%s'", rowStatus), model.ErrInvalidImportFile)
+	}
+
+	imp, err := s.orderRepo.GetOrderStatusImport(ctx, importID)
+	if err != nil {
+		if errors.Is(err, model.ErrStatusImportNotFound) {
+			return nil, model.NewOrderError(model.ErrCodeImportNotFound, "Order status import not found", err)
+		}
+		return nil, err
+	}
+
+	rows, err := s.orderRepo.ListOrderStatusImportRows(ctx, importID, rowStatus)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OrderStatusImportResponse{OrderStatusImport: *imp, Rows: rows}, nil
+}
+
+// ProcessOrderStatusImport chuyển trạng thái các dòng pending theo thứ tự trong file.
+// Lỗi của 1 đơn chỉ ghi vào dòng; trả error khi lỗi hạ tầng (asynq retry, dòng đã xử lý không chạy lại).
+func (s *orderService) ProcessOrderStatusImport(ctx context.Context, importID uuid.UUID) error {
+	started, err := s.orderRepo.StartOrderStatusImport(ctx, importID)
+	if err != nil {
+		return err
+	}
+	if !started {
+		// Import đã kết thúc (task bị giao lại sau khi xong)
+		return nil
+	}
+
+	imp, err := s.orderRepo.GetOrderStatusImport(ctx, importID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.orderRepo.ListOrderStatusImportRows(ctx, importID, model.OrderStatusImportRowPending)
+	if err != nil {
+		return err
+	}
+
+	updated, failed := 0, 0
+	for _, row := range rows {
+		result := s.applyOrderStatusImportRow(ctx, row, imp.CreatedBy)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.orderRepo.SaveOrderStatusImportRowResult(ctx, result); err != nil {
+			return err
+		}
+
+		if result.Status == model.OrderStatusImportRowFailed {
+			failed++
+		} else {
+			updated++
+		}
+	}
+
+	if err := s.orderRepo.FinishOrderStatusImport(ctx, importID, model.OrderStatusImportCompleted, nil); err != nil {
+		return err
+	}
+
+	logger.Info("Order status import completed", map[string]interface{}{
+		"import_id":    importID,
+		"rows_updated": updated,
+		"rows_failed":  failed,
+	})
+	return nil
+}
+
+// applyOrderStatusImportRow chuyển trạng thái 1 đơn; lỗi (không tìm thấy, transition không hợp lệ,
+// đơn bị sửa đồng thời) ghi vào dòng
+func (s *orderService) applyOrderStatusImportRow(
+	ctx context.Context,
+	row model.OrderStatusImportRow,
+	changedBy *uuid.UUID,
+) model.OrderStatusImportRowResult {
+	result := model.OrderStatusImportRowResult{RowID: row.ID, Status: model.OrderStatusImportRowFailed}
+	fail := func(msg string) model.OrderStatusImportRowResult {
+		result.Error = &msg
+		return result
+	}
+
+	order, err := s.orderRepo.GetOrderByNumber(ctx, row.OrderNumber)
+	if err != nil {
+		if errors.Is(err, model.ErrOrderNotFound) {
+			return fail(fmt.Sprintf("order %s not found", row.OrderNumber))
+		}
+		return fail(err.Error())
+	}
+	fromStatus := order.Status
+	result.OrderID = &order.ID
+	result.FromStatus = &fromStatus
+
+	// Đã ở trạng thái đích (upload lại file / retry sau khi đơn đã đổi) → không ghi lịch sử lần nữa
+	if order.Status == row.TargetStatus {
+		result.Status = model.OrderStatusImportRowUpdated
+		return result
+	}
+
+	note := fmt.Sprintf("Bulk status import line %d", row.LineNo)
+	err = s.transitionOrderStatus(ctx, order, model.UpdateOrderStatusRequest{
+		Status:         row.TargetStatus,
+		Version:        order.Version,
+		TrackingNumber: row.TrackingNumber,
+		HistoryNote:    &note,
+	}, changedBy)
+	if err != nil {
+		var orderErr *model.OrderError
+		if errors.As(err, &orderErr) {
+			return fail(orderErr.Message)
+		}
+		if !errors.Is(err, model.ErrVersionMismatch) {
+			logger.Error("Order status import: failed to update order", err)
+		}
+		return fail(err.Error())
+	}
+
+	result.Status = model.OrderStatusImportRowUpdated
+	return result
+}
+
+// parseOrderStatusImportCSV đọc file; lỗi header / file rỗng / quá nhiều dòng → từ chối cả file,
+// lỗi từng dòng → dòng status failed kèm lý do
+func parseOrderStatusImportCSV(file io.Reader) ([]model.OrderStatusImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file is empty")
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range model.OrderStatusImportColumns {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header must contain %s", strings.Join(model.OrderStatusImportColumns, ", "))
+		}
+	}
+
+	field := func(record []string, name string) string {
+		col, ok := columns[name]
+		if !ok || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+
+	rows := make([]model.OrderStatusImportRow, 0)
+	seen := make(map[string]int)
+	for lineNo := 1; ; lineNo++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if len(rows) >= model.OrderStatusImportMaxRows {
+			return nil, fmt.Errorf("file exceeds %d lines", model.OrderStatusImportMaxRows)
+		}
+
+		row := model.OrderStatusImportRow{
+			LineNo:       lineNo,
+			OrderNumber:  strings.ToUpper(field(record, "order_number")),
+			TargetStatus: strings.ToLower(field(record, "status")),
+			Status:       model.OrderStatusImportRowPending,
+		}
+		if tracking := field(record, "tracking_number"); tracking != "" {
+			row.TrackingNumber = &tracking
+		}
+
+		msg := validateOrderStatusImportRow(row)
+		if msg == "" && row.OrderNumber != "" {
+			// 1 đơn 2 dòng → chỉ chạy dòng đầu (thứ tự chuyển trạng thái trong file không đảm bảo)
+			if first, ok := seen[row.OrderNumber]; ok {
+				msg = fmt.Sprintf("order %s already listed on line %d", row.OrderNumber, first)
+			} else {
+				seen[row.OrderNumber] = lineNo
+			}
+		}
+		if msg != "" {
+			row.Status = model.OrderStatusImportRowFailed
+			row.Error = &msg
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no order lines")
+	}
+	return rows, nil
+}
+
+// validateOrderStatusImportRow validate 1 dòng, trả lý do lỗi ("" = hợp lệ).
+// Transition có hợp lệ không kiểm tra trong job (trạng thái đơn có thể đổi sau khi upload)
+func validateOrderStatusImportRow(row model.OrderStatusImportRow) string {
+	if row.OrderNumber == "" || row.TargetStatus == "" {
+		return "order_number and status are required"
+	}
+
+	req := model.UpdateOrderStatusRequest{Status: row.TargetStatus, Version: 1}
+	if err := req.Validate(); err != nil {
+		return fmt.Sprintf("unsupported status %q", row.TargetStatus)
+	}
+
+	if row.TrackingNumber != nil && len(*row.TrackingNumber) > model.OrderStatusImportMaxTrackingLength {
+		return fmt.Sprintf("tracking_number exceeds %d characters", model.OrderStatusImportMaxTrackingLength)
+	}
+	return ""
+}
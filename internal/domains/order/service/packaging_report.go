@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"bookstore-backend/internal/domains/order/model"
+)
+
+// GetPackagingAdoptionReport - tỷ lệ đơn chọn đóng gói tối giản / không in hoá đơn theo khoảng ngày đặt
+func (s *orderService) GetPackagingAdoptionReport(ctx context.Context, req model.PackagingReportRequest) (*model.PackagingAdoptionReport, error) {
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid report range", err)
+	}
+
+	report, err := s.orderRepo.GetPackagingAdoption(ctx, *req.FromDate, *req.ToDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report.CalculateRates()
+	return report, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// ========================================
+// GIÁ GIỮ TỪ WISHLIST (cart_price_overrides)
+// ========================================
+// cart.Subtotal đã tính giá giữ (cart_items.price) → dòng hàng của đơn phải dùng cùng giá,
+// override hết hạn / vượt số lượng thì dòng hàng về giá thường như giỏ đã tính lại lúc checkout.
+
+// applyCartPriceOverrides thay giá dòng hàng bằng giá giữ còn hiệu lực, trả id override đã áp (consume trong tx)
+func (s *orderService) applyCartPriceOverrides(ctx context.Context, cartID uuid.UUID, items []bookItemData) []uuid.UUID {
+	overrides, err := s.cartRepo.ListActivePriceOverrides(ctx, cartID)
+	if err != nil {
+		// Không đọc được → giá thường (đơn không được rẻ hơn giá niêm yết khi chưa xác minh)
+		logger.Error("Failed to load cart price overrides", err)
+		return nil
+	}
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var applied []uuid.UUID
+	for _, override := range overrides {
+		for i := range items {
+			if items[i].BookID != override.BookID || items[i].GiftPromotionID != nil {
+				continue
+			}
+			price, ok := override.UnitPriceFor(items[i].Price, items[i].Quantity, now)
+			if !ok {
+				continue
+			}
+			items[i].Price = price
+			items[i].TierDiscountPercent = nil // Giá giữ không cộng dồn bậc giá
+
+			metadata := make(model.Metadata, len(items[i].Metadata)+1)
+			for k, v := range items[i].Metadata {
+				metadata[k] = v
+			}
+			metadata[model.MetadataPriceSource] = model.PriceSourceWishlistGuarantee
+			items[i].Metadata = metadata
+
+			applied = append(applied, override.ID)
+		}
+	}
+	return applied
+}
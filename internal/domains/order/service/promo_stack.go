@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	modelPromo "bookstore-backend/internal/domains/promotion/model"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ========================================
+// PROMOTION STACKING LÚC TẠO ĐƠN
+// ========================================
+// Không dùng tập promotion cart đã tính: promotion tự động có thể bật/tắt/hết lượt từ lúc áp mã,
+// nên chọn lại bằng modelPromo.ResolveStack trên dữ liệu hiện tại.
+
+// appliedPromotion promotion được áp vào đơn + phần giảm của riêng nó (ghi promotion_usage)
+type appliedPromotion struct {
+	promo            *modelPromo.Promotion
+	discount         decimal.Decimal
+	shippingDiscount decimal.Decimal
+}
+
+// eligibleAutomaticPromotions promotion tự động đang chạy mà đơn hiện tại đạt điều kiện
+// (min order, lượt dùng toàn cục, lượt dùng của user)
+func (s *orderService) eligibleAutomaticPromotions(ctx context.Context, subtotal decimal.Decimal, userID uuid.UUID) ([]*modelPromo.Promotion, error) {
+	promos, err := s.promoRepo.ListAutomaticActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automatic promotions: %w", err)
+	}
+
+	eligible := make([]*modelPromo.Promotion, 0, len(promos))
+	for _, p := range promos {
+		if err := s.validatePromotion(p, subtotal, userID); err != nil {
+			continue
+		}
+		used, err := s.promoRepo.GetUserUsageCount(ctx, p.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check automatic promotion usage: %w", err)
+		}
+		if used >= p.MaxUsesPerUser {
+			continue
+		}
+		eligible = append(eligible, p)
+	}
+	return eligible, nil
+}
+
+// resolvePromotionStack chọn tập promotion áp vào đơn: mã từ cart (có thể nil) + tự động theo luật stacking
+func (s *orderService) resolvePromotionStack(code *modelPromo.Promotion, automatic []*modelPromo.Promotion, subtotal decimal.Decimal) []appliedPromotion {
+	byID := make(map[uuid.UUID]appliedPromotion, len(automatic)+1)
+	candidate := func(p *modelPromo.Promotion) modelPromo.StackCandidate {
+		a := appliedPromotion{
+			promo:            p,
+			discount:         s.calculateDiscount(p, subtotal),
+			shippingDiscount: s.calculateShippingDiscount(p, subtotal),
+		}
+		byID[p.ID] = a
+		return modelPromo.NewStackCandidate(p, a.discount.Add(a.shippingDiscount))
+	}
+
+	var codeCandidate *modelPromo.StackCandidate
+	if code != nil {
+		c := candidate(code)
+		codeCandidate = &c
+	}
+	candidates := make([]modelPromo.StackCandidate, 0, len(automatic))
+	for _, p := range automatic {
+		if code != nil && p.ID == code.ID {
+			continue
+		}
+		candidates = append(candidates, candidate(p))
+	}
+
+	stack := modelPromo.ResolveStack(codeCandidate, candidates)
+	applied := make([]appliedPromotion, 0, len(stack))
+	for _, c := range stack {
+		applied = append(applied, byID[c.PromotionID])
+	}
+	return applied
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/database"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+)
+
+// =====================================================
+// REPLACEMENT ORDER (gửi bù)
+// =====================================================
+
+// CreateReplacementOrder - tạo đơn 0đ gửi bù sách thiếu / hỏng / thất lạc của đơn gốc.
+// Khác createOrderFromItems:
+//   - Chỉ gửi bù sách có trong đơn gốc, tổng các lần gửi bù không vượt số đã mua
+//   - Không chọn items → gửi lại toàn bộ phần chưa gửi bù (thất lạc cả kiện)
+//   - Giao tới địa chỉ đơn gốc, không phí ship / COD, không chờ thanh toán (confirmed ngay)
+//   - placed_by = nhân viên xử lý, lý do lưu ở order_replacements cho báo cáo
+func (s *orderService) CreateReplacementOrder(
+	ctx context.Context,
+	req model.CreateReplacementOrderRequest,
+) (*model.CreateOrderResponse, error) {
+	// 1. Validate request
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid replacement order request", err)
+	}
+
+	// 2. Đơn gốc + items
+	source, err := s.orderRepo.GetOrderByID(ctx, req.SourceOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Status == model.OrderStatusPending || source.Status == model.OrderStatusCancelled {
+		return nil, model.NewOrderError(
+			model.ErrCodeInvalidStatus,
+			fmt.Sprintf("Cannot resend items of order with status '%s'", source.Status),
+			model.ErrInvalidStatus,
+		)
+	}
+
+	sourceItems, err := s.orderRepo.GetOrderItemsByOrderID(ctx, source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	replaced, err := s.orderRepo.GetReplacedQuantities(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Số còn gửi bù được = đã mua - đã gửi bù (giữ thứ tự sách như đơn gốc)
+	remaining := make(map[uuid.UUID]int, len(sourceItems))
+	var remainingItems []model.CreateOrderItem
+	for _, item := range sourceItems {
+		if _, seen := remaining[item.BookID]; !seen {
+			remainingItems = append(remainingItems, model.CreateOrderItem{BookID: item.BookID})
+		}
+		remaining[item.BookID] += item.Quantity
+	}
+	for i := range remainingItems {
+		bookID := remainingItems[i].BookID
+		remaining[bookID] -= replaced[bookID]
+		remainingItems[i].Quantity = remaining[bookID]
+	}
+
+	items := req.Items
+	if len(items) == 0 {
+		for _, item := range remainingItems {
+			if item.Quantity > 0 {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 {
+			return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "All items of this order have already been replaced", nil)
+		}
+	}
+
+	requested := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		requested[item.BookID] += item.Quantity
+	}
+	for bookID, quantity := range requested {
+		if quantity > remaining[bookID] {
+			return nil, model.NewOrderError(
+				model.ErrCodeInvalidOrder,
+				fmt.Sprintf("Cannot resend %d of book %s (%d left to replace)", quantity, bookID, max(remaining[bookID], 0)),
+				nil,
+			)
+		}
+	}
+
+	// 3. Địa chỉ đơn gốc
+	address, err := s.addressRepo.GetByID(ctx, source.AddressID)
+	if err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidAddress, "Invalid shipping address", err)
+	}
+
+	// 4. Book snapshot, đơn giá 0 (hàng gửi bù không tính tiền)
+	bookItems, err := s.validateAndFetchBookItems(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	for i := range bookItems {
+		bookItems[i].Price = decimal.Zero
+		bookItems[i].TierDiscountPercent = nil
+	}
+
+	// 5. Chọn warehouse (V1: single warehouse)
+	selectedWH, err := s.selectSingleWarehouseForOrder(ctx, address, bookItems)
+	if err != nil {
+		return nil, err
+	}
+	selectedWarehouseID := selectedWH.ID
+
+	// 6. Bắt đầu transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.orderRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.orderRepo.RollbackTx(ctx, tx)
+
+	// 7. Reserve inventory
+	for _, item := range bookItems {
+		if err := s.inventoryRepo.ReserveStockWithTx(ctx, tx, selectedWarehouseID, item.BookID, item.Quantity, &req.StaffID); err != nil {
+			return nil, model.NewOrderError(
+				model.ErrCodeInsufficientStock,
+				fmt.Sprintf("Failed to reserve stock for book: %s", item.BookID),
+				err,
+			)
+		}
+	}
+
+	// 8. Insert order (0đ, không cần thanh toán)
+	orderID := uuid.New()
+	note := req.Note
+	order := &model.Order{
+		ID:               orderID,
+		UserID:           source.UserID,
+		AddressID:        source.AddressID,
+		WarehouseID:      &selectedWarehouseID,
+		Subtotal:         decimal.Zero,
+		ShippingFee:      decimal.Zero,
+		DiscountAmount:   decimal.Zero,
+		Total:            decimal.Zero,
+		PaymentMethod:    model.PaymentMethodReplacement,
+		PaymentStatus:    model.PaymentStatusPaid,
+		Status:           model.OrderStatusConfirmed,
+		CustomerNote:     &note,
+		MinimalPackaging: source.MinimalPackaging,
+		NoPrintedInvoice: source.NoPrintedInvoice,
+		PlacedBy:         &req.StaffID,
+		Version:          0,
+	}
+	if err := s.orderRepo.CreateOrderWithTx(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	// 9. Insert order items
+	orderItems := s.buildOrderItems(orderID, bookItems)
+	if err := s.orderRepo.CreateOrderItemsWithTx(ctx, tx, orderItems); err != nil {
+		return nil, fmt.Errorf("failed to create order items: %w", err)
+	}
+
+	// 10. Liên kết đơn gốc + lý do
+	replacement := &model.OrderReplacement{
+		OrderID:         orderID,
+		OriginalOrderID: source.ID,
+		Reason:          req.Reason,
+		CreatedBy:       req.StaffID,
+	}
+	if req.Note != "" {
+		replacement.Note = &note
+	}
+	if err := s.orderRepo.CreateOrderReplacementWithTx(ctx, tx, replacement); err != nil {
+		return nil, err
+	}
+
+	// 11. Status history
+	historyNote := fmt.Sprintf("Replacement for order %s (%s)", source.OrderNumber, req.Reason)
+	statusHistory := &model.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: nil,
+		ToStatus:   order.Status,
+		ChangedBy:  &req.StaffID,
+		Notes:      &historyNote,
+	}
+	if err := s.orderRepo.CreateOrderStatusHistoryWithTx(ctx, tx, statusHistory); err != nil {
+		return nil, fmt.Errorf("failed to create order status history: %w", err)
+	}
+	if err := outbox.AddWithTx(ctx, tx, shipmentOnConfirmMessages(order)...); err != nil {
+		return nil, err
+	}
+
+	// 12. Commit
+	if err := s.orderRepo.CommitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// 13. Jobs sau commit (không auto-release: đơn gửi bù không chờ thanh toán)
+	for _, item := range orderItems {
+		payload := shared.InventorySyncPayload{
+			BookID: item.BookID.String(),
+			Source: "SALE",
+		}
+		if b, err := json.Marshal(payload); err == nil {
+			task := tracing.NewTask(ctx, shared.TypeInventorySyncBookStock, b)
+			if _, err := s.asynq.Enqueue(task, asynq.Queue(shared.QueueInventory)); err != nil {
+				logger.Error("Failed to enqueue InventorySyncJob after replacement order", err)
+			}
+		}
+	}
+
+	logger.Info("Replacement order created", map[string]interface{}{
+		"order_id":        order.ID,
+		"source_order_id": source.ID,
+		"reason":          req.Reason,
+		"staff_id":        req.StaffID,
+	})
+
+	return &model.CreateOrderResponse{
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Total:       order.Total,
+		Status:      order.Status,
+		PaymentURL:  nil,
+	}, nil
+}
+
+// GetReplacementReport - đơn gửi bù theo lý do (hỏng / thất lạc / thiếu / nhầm) trong khoảng ngày tạo
+func (s *orderService) GetReplacementReport(ctx context.Context, req model.ReplacementReportRequest) (*model.ReplacementReport, error) {
+	if err := req.Validate(); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidOrder, "Invalid report range", err)
+	}
+
+	report, err := s.orderRepo.GetReplacementReport(ctx, *req.FromDate, *req.ToDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Summarize()
+	return report, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	addressModel "bookstore-backend/internal/domains/address/model"
+	"bookstore-backend/internal/domains/order/model"
+	shippingModel "bookstore-backend/internal/domains/shipping/model"
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// SHIPPING: PHÍ SHIP THEO HÃNG + ĐỒNG BỘ TRẠNG THÁI VẬN ĐƠN
+// =====================================================
+
+// SetShippingQuoter sets carrier rate quoting used for checkout shipping fee
+func (s *orderService) SetShippingQuoter(quoter ShippingQuoter) {
+	s.shippingQuoter = quoter
+}
+
+// shippingPolicyFor checkout policy với phí ship hãng báo cho kế hoạch kiện (mỗi kho 1 kiện)
+//
+// Ngưỡng freeship / bậc giảm ship vẫn áp như cũ, chỉ thay phí gốc.
+// Chưa wire quoter / hãng lỗi → phí cố định của policy (không chặn khách đặt hàng vì hãng chậm)
+func (s *orderService) shippingPolicyFor(
+	ctx context.Context,
+	plan *fulfillmentPlan,
+	shipTo *addressModel.Address,
+	bookItems []bookItemData,
+) model.CheckoutPolicy {
+	if s.shippingQuoter == nil {
+		return s.checkoutPolicy
+	}
+
+	parcels := make([]shippingModel.QuoteParcel, 0, len(plan.Shipments))
+	for _, shipment := range plan.Shipments {
+		parcel := shippingModel.QuoteParcel{
+			WarehouseID:   shipment.Warehouse.ID,
+			Street:        shipTo.Street,
+			Ward:          shipTo.Ward,
+			District:      shipTo.District,
+			Province:      shipTo.Province,
+			Items:         make(map[uuid.UUID]int, len(shipment.ItemIndexes)),
+			DeclaredValue: decimal.Zero,
+		}
+		for _, idx := range shipment.ItemIndexes {
+			item := bookItems[idx]
+			parcel.Items[item.BookID] += item.Quantity
+			parcel.DeclaredValue = parcel.DeclaredValue.Add(item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))))
+		}
+		parcels = append(parcels, parcel)
+	}
+
+	fee, err := s.shippingQuoter.QuoteShippingFee(ctx, parcels)
+	if err != nil {
+		logger.Info("Carrier rate quote unavailable, using flat shipping fee", map[string]interface{}{
+			"province": shipTo.Province,
+			"parcels":  len(parcels),
+			"error":    err.Error(),
+		})
+		return s.checkoutPolicy
+	}
+	return s.checkoutPolicy.WithShippingFee(fee)
+}
+
+// shipmentOnConfirmMessages job tạo vận đơn khi đơn ở trạng thái confirmed
+// Dedup theo đơn: đặt COD rồi admin xác nhận lại / webhook thanh toán lặp không tạo 2 job
+func shipmentOnConfirmMessages(order *model.Order) []outbox.Message {
+	if order.Status != model.OrderStatusConfirmed {
+		return nil
+	}
+
+	message, err := outbox.NewMessage(shared.TypeCreateOrderShipment, shippingModel.CreateOrderShipmentPayload{
+		OrderID: order.ID,
+	}, shared.QueueOrder)
+	if err != nil {
+		logger.Error("Failed to build create shipment outbox message", err)
+		return nil
+	}
+	return []outbox.Message{message.WithMaxRetry(5).WithDedupKey("create_order_shipment:" + order.ID.String())}
+}
+
+// SyncCarrierStatus chuyển đơn tới toStatus theo đường ngắn nhất của workflow (confirmed → processing → shipping...),
+// mỗi bước chạy như admin đổi trạng thái (lịch sử, push khách, ghi nhận COD) với người đổi = hệ thống
+//
+// Đơn đã ở toStatus / không có đường đi (đã huỷ, event tới trễ sau delivered) → giữ nguyên, không lỗi
+func (s *orderService) SyncCarrierStatus(ctx context.Context, orderID uuid.UUID, toStatus, note string) (string, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+	if order.Status == toStatus {
+		return order.Status, nil
+	}
+
+	transitions, err := s.loadStatusTransitions(ctx)
+	if err != nil {
+		return "", err
+	}
+	path := statusPath(transitions, order.Status, toStatus)
+	if path == nil {
+		logger.Info("Skip carrier status sync: no workflow path", map[string]interface{}{
+			"order_id": orderID.String(),
+			"from":     order.Status,
+			"to":       toStatus,
+		})
+		return order.Status, nil
+	}
+
+	for _, status := range path {
+		req := model.UpdateOrderStatusRequest{
+			Status:  status,
+			Version: order.Version,
+		}
+		if status == toStatus {
+			req.HistoryNote = &note
+		}
+		if err := s.transitionOrderStatus(ctx, order, req, nil); err != nil {
+			return order.Status, err
+		}
+	}
+
+	return order.Status, nil
+}
+
+// statusPath BFS trên workflow: các trạng thái cần đi qua (không gồm from), nil nếu không tới được
+func statusPath(transitions []model.StatusTransition, from, to string) []string {
+	next := make(map[string][]string)
+	for _, t := range transitions {
+		next[t.FromStatus] = append(next[t.FromStatus], t.ToStatus)
+	}
+
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			break
+		}
+		for _, n := range next[current] {
+			if _, seen := prev[n]; seen {
+				continue
+			}
+			prev[n] = current
+			queue = append(queue, n)
+		}
+	}
+
+	if _, ok := prev[to]; !ok {
+		return nil
+	}
+	var path []string
+	for status := to; status != from; status = prev[status] {
+		path = append([]string{status}, path...)
+	}
+	return path
+}
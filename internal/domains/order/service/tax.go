@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/order/model"
+	taxModel "bookstore-backend/internal/domains/tax/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// TAX: THUẾ THEO TỈNH GIAO HÀNG / DANH MỤC SÁCH
+// =====================================================
+
+// SetTaxCalculator sets the tax engine used when pricing orders
+func (s *orderService) SetTaxCalculator(calculator TaxCalculator) {
+	s.taxCalculator = calculator
+}
+
+// calculateTaxLines dòng thuế của đơn theo tỉnh nhận hàng + danh mục từng dòng hàng.
+// discount (giảm tiền hàng) phân bổ theo tỷ lệ → thuế tính trên giá khách thực trả.
+//
+// Khác phí ship: engine lỗi thì chặn đặt đơn (thu thiếu thuế exclusive không sửa lại được sau khi thanh toán)
+func (s *orderService) calculateTaxLines(
+	ctx context.Context,
+	province string,
+	bookItems []bookItemData,
+	discount decimal.Decimal,
+) ([]model.OrderTaxLine, error) {
+	if s.taxCalculator == nil {
+		return nil, nil
+	}
+
+	items := make([]taxModel.TaxableItem, 0, len(bookItems))
+	for _, item := range bookItems {
+		items = append(items, taxModel.TaxableItem{
+			BookID:     item.BookID,
+			CategoryID: item.CategoryID,
+			Amount:     item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))),
+		})
+	}
+
+	result, err := s.taxCalculator.Calculate(ctx, taxModel.CalculateRequest{
+		Province: province,
+		Items:    items,
+		Discount: discount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate tax: %w", err)
+	}
+
+	lines := make([]model.OrderTaxLine, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		lines = append(lines, model.OrderTaxLine{
+			TaxRuleID:        line.TaxRuleID,
+			Name:             line.Name,
+			Rate:             line.Rate,
+			PriceIncludesTax: line.PriceIncludesTax,
+			TaxableAmount:    line.TaxableAmount,
+			TaxAmount:        line.TaxAmount,
+		})
+	}
+	return lines, nil
+}
+
+// loadTaxLines dòng thuế đã lưu của đơn (lỗi → nil, không chặn xem đơn / hoá đơn)
+func (s *orderService) loadTaxLines(ctx context.Context, orderID uuid.UUID) []model.OrderTaxLine {
+	lines, err := s.orderRepo.ListOrderTaxLines(ctx, orderID)
+	if err != nil {
+		logger.Error("Failed to load order tax lines", err)
+		return nil
+	}
+	return lines
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/shared/locale"
+)
+
+// =====================================================
+// PUBLIC ORDER TRACKING
+// =====================================================
+
+// TrackOrderPublic cho khách vãng lai / COD không có tài khoản tra cứu đơn
+// Xác thực bằng 4 số cuối SĐT người nhận (địa chỉ giao hoặc người nhận quà)
+func (s *orderService) TrackOrderPublic(ctx context.Context, req model.TrackOrderRequest, lang string) (*model.PublicTrackingResponse, error) {
+	order, err := s.orderRepo.GetOrderByNumber(ctx, strings.ToUpper(strings.TrimSpace(req.OrderNumber)))
+	if err != nil {
+		if errors.Is(err, model.ErrOrderNotFound) {
+			return nil, trackingNotFound()
+		}
+		return nil, err
+	}
+
+	gift, err := s.orderRepo.GetOrderGift(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order gift: %w", err)
+	}
+
+	// Đơn quà giao tới người nhận quà → chấp nhận cả SĐT người nhận quà lẫn SĐT người mua
+	recipientName, province := "", ""
+	matched := false
+	if gift != nil && model.PhoneMatchesLast4(gift.RecipientPhone, req.PhoneLast4) {
+		matched = true
+		recipientName, province = gift.RecipientName, gift.Province
+	}
+	if addr, err := s.addressRepo.GetByID(ctx, order.AddressID); err == nil && addr != nil {
+		if !matched && model.PhoneMatchesLast4(addr.Phone, req.PhoneLast4) {
+			matched = true
+		}
+		if gift == nil {
+			recipientName, province = addr.RecipientName, addr.Province
+		}
+	}
+	if !matched {
+		return nil, trackingNotFound()
+	}
+
+	history, err := s.orderRepo.GetOrderStatusHistory(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.PublicTrackingResponse{
+		OrderNumber:         order.OrderNumber,
+		Status:              order.Status,
+		StatusLabel:         locale.OrderStatus(lang, order.Status),
+		PaymentMethod:       order.PaymentMethod,
+		PaymentStatus:       order.PaymentStatus,
+		CreatedAt:           order.CreatedAt,
+		RecipientName:       model.MaskName(recipientName),
+		Province:            province,
+		EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+		DeliveredAt:         order.DeliveredAt,
+		CancelledAt:         order.CancelledAt,
+		Timeline:            make([]model.PublicTrackingEvent, 0, len(history)),
+	}
+
+	if order.IsCOD() && order.PaymentStatus != model.PaymentStatusPaid &&
+		order.Status != model.OrderStatusCancelled {
+		total := order.Total
+		result.CODAmount = &total
+	}
+
+	if order.TrackingNumber != nil && *order.TrackingNumber != "" {
+		carrier, err := s.orderRepo.GetShipmentCarrier(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.Shipment = &model.PublicShipmentInfo{
+			Carrier:        carrier,
+			TrackingNumber: *order.TrackingNumber,
+		}
+	}
+
+	for _, h := range history {
+		result.Timeline = append(result.Timeline, model.PublicTrackingEvent{
+			Status:      h.ToStatus,
+			StatusLabel: locale.OrderStatus(lang, h.ToStatus),
+			At:          h.ChangedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func trackingNotFound() error {
+	return model.NewOrderError(
+		model.ErrCodeOrderNotFound,
+		"Order not found or phone number does not match",
+		model.ErrOrderNotFound,
+	)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	notificationModel "bookstore-backend/internal/domains/notification/model"
+	notificationService "bookstore-backend/internal/domains/notification/service"
+	"bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// ORDER STATUS WORKFLOW
+// =====================================================
+
+// SetNotificationService sets notification dependency (called after notification services created)
+func (s *orderService) SetNotificationService(ns notificationService.NotificationService) {
+	s.notificationService = ns
+}
+
+// SetShipmentTrigger sets carrier integration used by trigger_shipment hook
+func (s *orderService) SetShipmentTrigger(trigger ShipmentTrigger) {
+	s.shipmentTrigger = trigger
+}
+
+// loadStatusTransitions đọc workflow từ DB mỗi lần dùng (admin sửa trên 1 instance, instance khác thấy ngay)
+// Bảng rỗng → workflow mặc định
+func (s *orderService) loadStatusTransitions(ctx context.Context) ([]model.StatusTransition, error) {
+	transitions, err := s.orderRepo.ListStatusTransitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(transitions) == 0 {
+		return model.DefaultStatusTransitions(), nil
+	}
+	return transitions, nil
+}
+
+// validateStatusTransition trả về transition đã cấu hình (kèm hooks) nếu được phép
+func (s *orderService) validateStatusTransition(ctx context.Context, currentStatus, newStatus string) (*model.StatusTransition, error) {
+	transitions, err := s.loadStatusTransitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hasOutgoing := false
+	for i := range transitions {
+		if transitions[i].FromStatus != currentStatus {
+			continue
+		}
+		hasOutgoing = true
+		if transitions[i].ToStatus == newStatus {
+			return &transitions[i], nil
+		}
+	}
+
+	if !hasOutgoing {
+		return nil, model.NewOrderError(
+			model.ErrCodeInvalidStatus,
+			fmt.Sprintf("Cannot transition from status '%s'", currentStatus),
+			model.ErrInvalidStatus,
+		)
+	}
+
+	return nil, model.NewOrderError(
+		model.ErrCodeInvalidStatus,
+		fmt.Sprintf("Cannot transition from '%s' to '%s'", currentStatus, newStatus),
+		model.ErrInvalidStatus,
+	)
+}
+
+func (s *orderService) GetStatusWorkflow(ctx context.Context) (*model.StatusWorkflowResponse, error) {
+	transitions, err := s.loadStatusTransitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StatusWorkflowResponse{
+		Statuses:    model.AllOrderStatuses,
+		Hooks:       model.ValidTransitionHooks,
+		Transitions: transitions,
+	}, nil
+}
+
+// UpdateStatusWorkflow thay toàn bộ workflow; workflow không hợp lệ (kẹt, không tới được delivered) bị từ chối
+func (s *orderService) UpdateStatusWorkflow(
+	ctx context.Context,
+	adminID uuid.UUID,
+	req model.UpdateStatusWorkflowRequest,
+) (*model.StatusWorkflowResponse, error) {
+	transitions := make([]model.StatusTransition, 0, len(req.Transitions))
+	for _, t := range req.Transitions {
+		hooks := t.Hooks
+		if hooks == nil {
+			hooks = []string{}
+		}
+		transitions = append(transitions, model.StatusTransition{
+			FromStatus: t.FromStatus,
+			ToStatus:   t.ToStatus,
+			Hooks:      hooks,
+		})
+	}
+
+	if err := model.ValidateStatusWorkflow(transitions); err != nil {
+		return nil, model.NewOrderError(model.ErrCodeInvalidWorkflow, err.Error(), model.ErrInvalidWorkflow)
+	}
+
+	if err := s.orderRepo.ReplaceStatusTransitions(ctx, transitions, adminID); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Order status workflow updated", map[string]interface{}{
+		"admin_id":    adminID.String(),
+		"transitions": len(transitions),
+	})
+
+	return s.GetStatusWorkflow(ctx)
+}
+
+func (s *orderService) ValidateStatusWorkflow(ctx context.Context) error {
+	transitions, err := s.loadStatusTransitions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load order status workflow: %w", err)
+	}
+
+	if err := model.ValidateStatusWorkflow(transitions); err != nil {
+		return fmt.Errorf("invalid order status workflow: %w", err)
+	}
+	return nil
+}
+
+// runTransitionHooks chạy side-effect sau commit - lỗi chỉ log, không ảnh hưởng trạng thái đã lưu
+func (s *orderService) runTransitionHooks(
+	ctx context.Context,
+	order *model.Order,
+	transition *model.StatusTransition,
+	historyID uuid.UUID,
+) {
+	for _, hook := range transition.Hooks {
+		switch hook {
+		case model.TransitionHookNotifyCustomer:
+			s.notifyCustomerStatusChanged(ctx, order, historyID)
+		case model.TransitionHookTriggerShipment:
+			s.triggerShipment(ctx, order)
+		}
+	}
+}
+
+// orderStatusMessages - nội dung báo khách theo trạng thái mới
+var orderStatusMessages = map[string]string{
+	model.OrderStatusConfirmed:  "Đơn hàng %s đã được xác nhận.",
+	model.OrderStatusProcessing: "Đơn hàng %s đang được chuẩn bị.",
+	model.OrderStatusShipping:   "Đơn hàng %s đã được giao cho đơn vị vận chuyển.",
+	model.OrderStatusDelivered:  "Đơn hàng %s đã được giao thành công.",
+	model.OrderStatusCancelled:  "Đơn hàng %s đã bị huỷ.",
+	model.OrderStatusReturned:   "Đơn hàng %s đã được hoàn trả.",
+}
+
+func (s *orderService) notifyCustomerStatusChanged(ctx context.Context, order *model.Order, historyID uuid.UUID) {
+	if s.notificationService == nil {
+		return
+	}
+
+	template, ok := orderStatusMessages[order.Status]
+	if !ok {
+		return
+	}
+
+	data := map[string]interface{}{
+		"order_id":     order.ID,
+		"order_number": order.OrderNumber,
+		"status":       order.Status,
+	}
+	if order.TrackingNumber != nil {
+		data["tracking_number"] = *order.TrackingNumber
+	}
+
+	// Reference theo status history: mỗi lần chuyển trạng thái là 1 notification riêng (idempotency key theo reference)
+	referenceType := "order_status"
+	_, err := s.notificationService.CreateNotification(ctx, notificationModel.CreateNotificationRequest{
+		UserID:        order.UserID,
+		Type:          notificationModel.NotificationTypeOrderStatus,
+		Title:         "Cập nhật đơn hàng " + order.OrderNumber,
+		Message:       fmt.Sprintf(template, order.OrderNumber),
+		Data:          data,
+		Channels:      []string{notificationModel.ChannelInApp, notificationModel.ChannelEmail},
+		ReferenceType: &referenceType,
+		ReferenceID:   &historyID,
+	})
+	if err != nil {
+		logger.Error("Failed to send order status notification", err)
+	}
+}
+
+// orderStatusPushStatuses trạng thái báo push lên điện thoại (processing, returned chỉ báo in-app + email)
+var orderStatusPushStatuses = map[string]bool{
+	model.OrderStatusConfirmed: true,
+	model.OrderStatusShipping:  true,
+	model.OrderStatusDelivered: true,
+	model.OrderStatusCancelled: true,
+}
+
+// orderStatusPushMessages task push ghi outbox cùng TX đổi trạng thái (dedup theo status history)
+// Lỗi build chỉ log, không chặn đổi trạng thái
+func orderStatusPushMessages(order *model.Order, toStatus string, historyID uuid.UUID) []outbox.Message {
+	template, ok := orderStatusMessages[toStatus]
+	if !ok || !orderStatusPushStatuses[toStatus] {
+		return nil
+	}
+
+	message, err := outbox.NewMessage(shared.TypeSendOrderStatusPush, notificationModel.OrderStatusPushPayload{
+		UserID:      order.UserID,
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		Status:      toStatus,
+		Title:       "Cập nhật đơn hàng " + order.OrderNumber,
+		Body:        fmt.Sprintf(template, order.OrderNumber),
+	}, shared.QueueNotification)
+	if err != nil {
+		logger.Error("Failed to build order status push outbox message", err)
+		return nil
+	}
+	return []outbox.Message{message.WithMaxRetry(3).WithDedupKey("order_status_push:" + historyID.String())}
+}
+
+func (s *orderService) triggerShipment(ctx context.Context, order *model.Order) {
+	if s.shipmentTrigger == nil {
+		logger.Info("Skip trigger_shipment hook: no carrier integration configured", map[string]interface{}{
+			"order_id": order.ID.String(),
+		})
+		return
+	}
+
+	if err := s.shipmentTrigger.TriggerShipment(ctx, order.ID); err != nil {
+		logger.Error("Failed to trigger shipment", err)
+	}
+}
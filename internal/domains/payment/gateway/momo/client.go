@@ -134,6 +134,7 @@ func (c *Client) CreatePaymentURL(
 // VerifySignature verifies Momo webhook signature
 func (c *Client) VerifySignature(webhookData model.MomoWebhookRequest) bool {
 	return VerifyWebhookSignature(
+		c.config.AccessKey,
 		webhookData.PartnerCode,
 		webhookData.OrderID,
 		webhookData.RequestID,
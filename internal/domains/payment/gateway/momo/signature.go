@@ -46,16 +46,17 @@ func BuildPaymentSignatureString(
 	return strings.Join(parts, "&")
 }
 
-// VerifyWebhookSignature verifies Momo webhook signature
+// VerifyWebhookSignature verifies Momo IPN signature
+// Raw string theo tài liệu Momo: các field sắp theo alphabet, accessKey đứng đầu (bắt buộc, không để trống)
 func VerifyWebhookSignature(
-	partnerCode, orderId, requestId, amount, orderInfo, orderType,
+	accessKey, partnerCode, orderId, requestId, amount, orderInfo, orderType,
 	transId string, resultCode int, message, payType, responseTime,
 	extraData, receivedSignature, secretKey string,
 ) bool {
 	// Build raw signature string for webhook
 	rawSignature := fmt.Sprintf(
 		"accessKey=%s&amount=%s&extraData=%s&message=%s&orderId=%s&orderInfo=%s&orderType=%s&partnerCode=%s&payType=%s&requestId=%s&responseTime=%s&resultCode=%d&transId=%s",
-		"", // accessKey not included in webhook signature
+		accessKey,
 		amount,
 		extraData,
 		message,
@@ -71,5 +72,5 @@ func VerifyWebhookSignature(
 	)
 
 	expectedSignature := GenerateSignature(rawSignature, secretKey)
-	return strings.EqualFold(receivedSignature, expectedSignature)
+	return hmac.Equal([]byte(strings.ToLower(receivedSignature)), []byte(expectedSignature))
 }
@@ -0,0 +1,192 @@
+package vnpay
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/payment/model"
+	"bookstore-backend/internal/infrastructure/sandbox"
+)
+
+// =====================================================
+// FAKE VNPAY GATEWAY (PAYMENT_PROVIDER_MODE=fake)
+// =====================================================
+// Phía server của cổng VNPay, chạy ngay trong API (/sandbox/vnpay/...). Client thật (Client) vẫn
+// tạo payment URL / gọi refund như production, chỉ APIUrl trỏ về đây → E2E đi qua đúng code ký,
+// kiểm chữ ký, xử lý IPN. Kịch bản theo số tiền (sandbox.ForAmount) hoặc ?sandbox_scenario=.
+
+// FakeGateway giả lập trang thanh toán + API refund của VNPay
+type FakeGateway struct {
+	config *Config
+	hang   time.Duration
+	seq    atomic.Int64
+}
+
+func NewFakeGateway(config *Config, hang time.Duration) *FakeGateway {
+	return &FakeGateway{config: config, hang: hang}
+}
+
+// FakePayment kết quả khách "thanh toán" trên cổng giả lập
+type FakePayment struct {
+	Scenario sandbox.Scenario
+	// Callback đã ký, dùng làm IPN + query của ReturnURL (rỗng khi timeout)
+	Callback model.VNPayWebhookRequest
+	// ReturnURL của merchant kèm query callback (rỗng khi timeout)
+	RedirectURL string
+}
+
+// Pay kiểm chữ ký payment URL rồi sinh callback theo kịch bản
+// override: kịch bản ép từ query (rỗng → theo số tiền)
+func (f *FakeGateway) Pay(query url.Values, override string) (*FakePayment, error) {
+	if !f.verifyPaymentQuery(query) {
+		return nil, fmt.Errorf("invalid payment URL signature")
+	}
+	if query.Get("vnp_TmnCode") != f.config.TmnCode {
+		return nil, fmt.Errorf("unknown merchant %q", query.Get("vnp_TmnCode"))
+	}
+
+	amount, err := parseVNPayAmount(query.Get("vnp_Amount"))
+	if err != nil {
+		return nil, err
+	}
+
+	scenario, ok := sandbox.Parse(override)
+	if !ok {
+		scenario = sandbox.ForAmount(amount)
+	}
+	payment := &FakePayment{Scenario: scenario}
+	if scenario == sandbox.ScenarioTimeout {
+		// Khách bỏ dở / cổng không phản hồi: không IPN, không redirect → payment hết hạn theo job
+		return payment, nil
+	}
+
+	responseCode, transactionStatus := ResponseCodeSuccess, "00"
+	if scenario == sandbox.ScenarioFailure {
+		responseCode, transactionStatus = ResponseCodeInsufficientBalance, "02"
+	}
+
+	now := time.Now()
+	transactionNo := f.nextTransactionNo(now)
+	params := map[string]string{
+		"vnp_Amount":            query.Get("vnp_Amount"),
+		"vnp_BankCode":          "NCB",
+		"vnp_BankTranNo":        "VNP" + transactionNo,
+		"vnp_CardType":          "ATM",
+		"vnp_OrderInfo":         query.Get("vnp_OrderInfo"),
+		"vnp_PayDate":           now.Format("20060102150405"),
+		"vnp_ResponseCode":      responseCode,
+		"vnp_TmnCode":           f.config.TmnCode,
+		"vnp_TransactionNo":     transactionNo,
+		"vnp_TransactionStatus": transactionStatus,
+		"vnp_TxnRef":            query.Get("vnp_TxnRef"),
+	}
+	params["vnp_SecureHash"] = GenerateSignature(params, f.config.HashSecret)
+
+	payment.Callback = model.VNPayWebhookRequest{
+		VnpAmount:            params["vnp_Amount"],
+		VnpBankCode:          params["vnp_BankCode"],
+		VnpBankTranNo:        params["vnp_BankTranNo"],
+		VnpCardType:          params["vnp_CardType"],
+		VnpOrderInfo:         params["vnp_OrderInfo"],
+		VnpPayDate:           params["vnp_PayDate"],
+		VnpResponseCode:      params["vnp_ResponseCode"],
+		VnpTmnCode:           params["vnp_TmnCode"],
+		VnpTransactionNo:     params["vnp_TransactionNo"],
+		VnpTxnRef:            params["vnp_TxnRef"],
+		VnpSecureHash:        params["vnp_SecureHash"],
+		VnpTransactionStatus: params["vnp_TransactionStatus"],
+	}
+
+	if returnURL := query.Get("vnp_ReturnUrl"); returnURL != "" {
+		redirect, err := url.Parse(returnURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vnp_ReturnUrl: %w", err)
+		}
+		values := redirect.Query()
+		for k, v := range params {
+			values.Set(k, v)
+		}
+		redirect.RawQuery = values.Encode()
+		payment.RedirectURL = redirect.String()
+	}
+	return payment, nil
+}
+
+// Refund giả lập merchant_webapi refund (body JSON đã ký bởi Client.InitiateRefund)
+// Kịch bản timeout treo tới khi hết hang / ctx rồi trả sandbox.ErrTimeout
+func (f *FakeGateway) Refund(ctx context.Context, params map[string]string) (map[string]string, error) {
+	if !VerifySignature(params, f.config.HashSecret) {
+		return map[string]string{"vnp_ResponseCode": "97", "vnp_Message": "Invalid signature"}, nil
+	}
+
+	amount, err := parseVNPayAmount(params["vnp_Amount"])
+	if err != nil {
+		return map[string]string{"vnp_ResponseCode": "03", "vnp_Message": err.Error()}, nil
+	}
+
+	switch sandbox.ForAmount(amount) {
+	case sandbox.ScenarioTimeout:
+		return nil, sandbox.Hang(ctx, f.hang)
+	case sandbox.ScenarioFailure:
+		return map[string]string{
+			"vnp_ResponseCode": "99",
+			"vnp_Message":      "Refund rejected by fake gateway",
+			"vnp_TxnRef":       params["vnp_TxnRef"],
+		}, nil
+	}
+
+	return map[string]string{
+		"vnp_ResponseCode":  ResponseCodeSuccess,
+		"vnp_Message":       "Refund success (fake gateway)",
+		"vnp_TxnRef":        params["vnp_TxnRef"],
+		"vnp_TransactionNo": f.nextTransactionNo(time.Now()),
+	}, nil
+}
+
+// verifyPaymentQuery kiểm chữ ký payment URL (ký trên giá trị đã urlencode kiểu PHP, xem BuildPaymentURL)
+func (f *FakeGateway) verifyPaymentQuery(query url.Values) bool {
+	received := query.Get("vnp_SecureHash")
+	if received == "" {
+		return false
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		if strings.HasPrefix(k, "vnp_") && k != "vnp_SecureHash" && k != "vnp_SecureHashType" && query.Get(k) != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, phpURLEncode(k)+"="+phpURLEncode(query.Get(k)))
+	}
+
+	expected := createSecureHash(strings.Join(parts, "&"), f.config.HashSecret)
+	return hmac.Equal([]byte(strings.ToUpper(received)), []byte(expected))
+}
+
+// nextTransactionNo mã giao dịch 8 số như VNPay (duy nhất trong process → IPN không bị coi là trùng)
+func (f *FakeGateway) nextTransactionNo(now time.Time) string {
+	n := (now.UnixMilli() + f.seq.Add(1)) % 100000000
+	return fmt.Sprintf("%08d", n)
+}
+
+// parseVNPayAmount vnp_Amount (VND x 100) → VND
+func parseVNPayAmount(raw string) (decimal.Decimal, error) {
+	cents, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid vnp_Amount %q", raw)
+	}
+	return decimal.NewFromInt(cents).Div(decimal.NewFromInt(100)), nil
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/payment/model"
+	"bookstore-backend/internal/domains/payment/service"
+	res "bookstore-backend/internal/shared/response"
+)
+
+// CODRemittanceHandler - admin endpoints đối soát tiền COD với hãng vận chuyển (finance)
+type CODRemittanceHandler struct {
+	codService service.CODRemittanceInterface
+}
+
+// NewCODRemittanceHandler creates new COD remittance handler
+func NewCODRemittanceHandler(codService service.CODRemittanceInterface) *CODRemittanceHandler {
+	return &CODRemittanceHandler{codService: codService}
+}
+
+// AdminImportRemittance imports a carrier remittance CSV and reconciles it against COD shipments
+// POST /api/v1/admin/payments/cod/remittances (multipart: file, carrier, reference, remitted_at)
+func (h *CODRemittanceHandler) AdminImportRemittance(c *gin.Context) {
+	adminID, err := GetUserIDFromContext(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.ImportCODRemittanceRequest
+	if err := c.ShouldBind(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, "FILE_REQUIRED", "Remittance file is required")
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv") {
+		res.Error(c, http.StatusBadRequest, "INVALID_FILE", "Only CSV files are allowed")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_FILE", err.Error())
+		return
+	}
+	defer file.Close()
+
+	response, err := h.codService.ImportRemittance(c.Request.Context(), adminID, req, fileHeader.Filename, file)
+	if err != nil {
+		statusCode, errCode := mapPaymentError(err)
+		res.Error(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	res.Success(c, http.StatusCreated, "Remittance reconciled", response)
+}
+
+// AdminGetRemittance gets reconciliation result of an imported remittance
+// GET /api/v1/admin/payments/cod/remittances/:remittance_id
+func (h *CODRemittanceHandler) AdminGetRemittance(c *gin.Context) {
+	remittanceID, err := uuid.Parse(c.Param("remittance_id"))
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_REMITTANCE_ID", "Invalid remittance ID")
+		return
+	}
+
+	response, err := h.codService.GetRemittance(c.Request.Context(), remittanceID)
+	if err != nil {
+		statusCode, errCode := mapPaymentError(err)
+		res.Error(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	res.Success(c, http.StatusOK, "OK", response)
+}
+
+// AdminListShipments lists COD shipments by reconciliation status (status=shortfall → hãng nộp thiếu)
+// GET /api/v1/admin/payments/cod/shipments?status=&carrier=&page=&limit=
+func (h *CODRemittanceHandler) AdminListShipments(c *gin.Context) {
+	var req model.ListCODShipmentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	response, err := h.codService.ListShipments(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapPaymentError(err)
+		res.Error(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	res.Success(c, http.StatusOK, "OK", response)
+}
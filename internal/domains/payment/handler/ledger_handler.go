@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/payment/model"
+	"bookstore-backend/internal/domains/payment/service"
+	res "bookstore-backend/internal/shared/response"
+)
+
+// LedgerHandler - admin endpoints của sổ cái thanh toán (finance)
+type LedgerHandler struct {
+	ledgerService service.LedgerInterface
+}
+
+// NewLedgerHandler creates new ledger handler
+func NewLedgerHandler(ledgerService service.LedgerInterface) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+// AdminGetOrderLedger lists money movements of an order
+// GET /api/v1/admin/payments/ledger/orders/:order_id
+func (h *LedgerHandler) AdminGetOrderLedger(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_ORDER_ID", "Invalid order ID")
+		return
+	}
+
+	response, err := h.ledgerService.GetOrderLedger(c.Request.Context(), orderID)
+	if err != nil {
+		statusCode, errCode := mapPaymentError(err)
+		res.Error(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	res.Success(c, http.StatusOK, "OK", response)
+}
+
+// AdminGetLedgerSummary gets debit/credit totals per account
+// GET /api/v1/admin/payments/ledger/summary?from_date=&to_date=&entry_type=
+func (h *LedgerHandler) AdminGetLedgerSummary(c *gin.Context) {
+	var req model.LedgerQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	response, err := h.ledgerService.GetLedgerSummary(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapPaymentError(err)
+		res.Error(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	res.Success(c, http.StatusOK, "OK", response)
+}
+
+// AdminExportLedger downloads ledger entries as CSV
+// GET /api/v1/admin/payments/ledger/export?from_date=&to_date=&entry_type=
+func (h *LedgerHandler) AdminExportLedger(c *gin.Context) {
+	var req model.LedgerQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	// Ghi vào buffer trước: lỗi giữa chừng vẫn trả được JSON error thay vì file CSV dở dang
+	var buf bytes.Buffer
+	if err := h.ledgerService.ExportLedgerCSV(c.Request.Context(), req, &buf); err != nil {
+		statusCode, errCode := mapPaymentError(err)
+		res.Error(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("payment_ledger_%s.csv", time.Now().Format("20060102_150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -256,8 +257,12 @@ func mapPaymentError(err error) (statusCode int, errorCode string) {
 			statusCode = http.StatusTooManyRequests
 		case model.ErrCodeOrderNotPending:
 			statusCode = http.StatusBadRequest
-		case model.ErrCodeInvalidGateway:
+		case model.ErrCodeInvalidGateway, model.ErrCodeInvalidLedgerQuery, model.ErrCodeInvalidRemittance:
 			statusCode = http.StatusBadRequest
+		case model.ErrCodeRemittanceAlreadyImported:
+			statusCode = http.StatusConflict
+		case model.ErrCodeRemittanceNotFound:
+			statusCode = http.StatusNotFound
 		case model.ErrCodeUnauthorized:
 			statusCode = http.StatusUnauthorized
 		case model.ErrCodeGatewayTimeout, model.ErrCodeGatewayUnavailable:
@@ -313,6 +318,13 @@ func (h *PaymentHandler) VNPayWebhook(c *gin.Context) {
 	err := h.paymentService.ProcessVNPayWebhook(c.Request.Context(), webhookData)
 
 	// Step 3: Return response to VNPay (MUST be fast, < 3 seconds)
+	if errors.Is(err, model.ErrAmountMismatch) {
+		c.JSON(http.StatusOK, gin.H{
+			"RspCode": "04",
+			"Message": "Invalid amount",
+		})
+		return
+	}
 	if err != nil {
 		// Even if processing fails, acknowledge webhook
 		// Failed webhooks will be retried by background job
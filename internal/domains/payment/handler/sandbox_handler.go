@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/domains/payment/gateway/vnpay"
+	"bookstore-backend/internal/domains/payment/service"
+	"bookstore-backend/internal/infrastructure/sandbox"
+	res "bookstore-backend/internal/shared/response"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// FAKE PAYMENT GATEWAY (PAYMENT_PROVIDER_MODE=fake)
+// =====================================================
+// Chỉ đăng ký route khi mode fake (không bao giờ ở production, xem config.validateProviderModes).
+// Kịch bản theo số tiền: ...001 VND → thất bại, ...002 VND → timeout, còn lại → thành công
+
+type SandboxHandler struct {
+	gateway        *vnpay.FakeGateway
+	paymentService service.PaymentService
+}
+
+func NewSandboxHandler(gateway *vnpay.FakeGateway, paymentService service.PaymentService) *SandboxHandler {
+	return &SandboxHandler{
+		gateway:        gateway,
+		paymentService: paymentService,
+	}
+}
+
+// VNPayPay trang thanh toán giả lập (payment URL từ /payments/create trỏ về đây)
+// GET /api/v1/sandbox/vnpay/vpcpay.html?...&sandbox_scenario=success|failure|timeout
+// Gửi IPN ngay trong process (như VNPay gọi /webhooks/vnpay) rồi redirect về vnp_ReturnUrl
+func (h *SandboxHandler) VNPayPay(c *gin.Context) {
+	query := c.Request.URL.Query()
+	override := query.Get("sandbox_scenario")
+	query.Del("sandbox_scenario")
+
+	payment, err := h.gateway.Pay(query, override)
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, "INVALID_PAYMENT_URL", err.Error())
+		return
+	}
+
+	if payment.Scenario == sandbox.ScenarioTimeout {
+		// Không IPN: payment giữ pending tới khi job hủy payment hết hạn
+		res.Error(c, http.StatusGatewayTimeout, "GATEWAY_TIMEOUT", "fake gateway did not respond (timeout scenario)")
+		return
+	}
+
+	if err := h.paymentService.ProcessVNPayWebhook(c.Request.Context(), payment.Callback); err != nil {
+		// Giống VNPay thật: IPN lỗi không chặn redirect, trang return tự verify lại
+		logger.Error("Fake gateway IPN processing failed", err)
+	}
+
+	if payment.RedirectURL == "" {
+		res.Success(c, http.StatusOK, "Fake payment processed", payment.Callback)
+		return
+	}
+	c.Redirect(http.StatusFound, payment.RedirectURL)
+}
+
+// VNPayRefund API refund giả lập
+// POST /api/v1/sandbox/vnpay/merchant_webapi/api/transaction
+func (h *SandboxHandler) VNPayRefund(c *gin.Context) {
+	var params map[string]string
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusOK, gin.H{"vnp_ResponseCode": "03", "vnp_Message": "Invalid request format"})
+		return
+	}
+
+	result, err := h.gateway.Refund(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, sandbox.ErrTimeout) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"vnp_Message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"vnp_Message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// COD CARRIERS
+// =====================================================
+const (
+	CarrierGHN         = "ghn"
+	CarrierGHTK        = "ghtk"
+	CarrierViettelPost = "viettel_post"
+	CarrierVNPost      = "vnpost"
+	CarrierJTExpress   = "jt_express"
+)
+
+var ValidCODCarriers = []string{
+	CarrierGHN,
+	CarrierGHTK,
+	CarrierViettelPost,
+	CarrierVNPost,
+	CarrierJTExpress,
+}
+
+// =====================================================
+// COD SHIPMENT STATUS
+// =====================================================
+const (
+	CODShipmentStatusCollected = "collected" // shipper đã thu, hãng chưa nộp
+	CODShipmentStatusRemitted  = "remitted"  // hãng đã nộp đủ
+	CODShipmentStatusShortfall = "shortfall" // hãng nộp thiếu, chờ nộp bù
+)
+
+var ValidCODShipmentStatuses = []string{
+	CODShipmentStatusCollected,
+	CODShipmentStatusRemitted,
+	CODShipmentStatusShortfall,
+}
+
+// =====================================================
+// REMITTANCE LINE MATCH STATUS
+// =====================================================
+const (
+	RemittanceMatchMatched   = "matched"   // nộp đúng số còn phải nộp
+	RemittanceMatchShortfall = "shortfall" // nộp thiếu
+	RemittanceMatchOverpaid  = "overpaid"  // nộp thừa
+	RemittanceMatchUnmatched = "unmatched" // không tìm thấy kiện COD tương ứng
+	RemittanceMatchDuplicate = "duplicate" // kiện đã nộp đủ trước đó (file trùng dòng / hãng nộp 2 lần)
+)
+
+// =====================================================
+// COD REMITTANCE ENTITIES
+// =====================================================
+
+// CODShipment - 1 kiện COD đã giao, tiền đang nằm ở hãng vận chuyển
+type CODShipment struct {
+	ID               uuid.UUID       `json:"id"`
+	OrderID          uuid.UUID       `json:"order_id"`
+	OrderNumber      string          `json:"order_number,omitempty"`
+	Carrier          *string         `json:"carrier,omitempty"`
+	TrackingNumber   *string         `json:"tracking_number,omitempty"`
+	CODAmount        decimal.Decimal `json:"cod_amount"`
+	RemittedAmount   decimal.Decimal `json:"remitted_amount"`
+	Status           string          `json:"status"`
+	CollectedAt      time.Time       `json:"collected_at"`
+	LastRemittanceID *uuid.UUID      `json:"last_remittance_id,omitempty"`
+	ReconciledAt     *time.Time      `json:"reconciled_at,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// OutstandingAmount - số tiền hãng còn phải nộp
+func (s *CODShipment) OutstandingAmount() decimal.Decimal {
+	return s.CODAmount.Sub(s.RemittedAmount)
+}
+
+// CODRemittance - 1 file đối soát hãng vận chuyển gửi
+type CODRemittance struct {
+	ID             uuid.UUID       `json:"id"`
+	Carrier        string          `json:"carrier"`
+	Reference      string          `json:"reference"`
+	RemittedAt     time.Time       `json:"remitted_at"`
+	FileName       string          `json:"file_name"`
+	TotalAmount    decimal.Decimal `json:"total_amount"`
+	LineCount      int             `json:"line_count"`
+	MatchedCount   int             `json:"matched_count"`
+	ShortfallCount int             `json:"shortfall_count"`
+	OverpaidCount  int             `json:"overpaid_count"`
+	UnmatchedCount int             `json:"unmatched_count"`
+	DuplicateCount int             `json:"duplicate_count"`
+	ImportedBy     *uuid.UUID      `json:"imported_by,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// CODRemittanceLine - 1 dòng trong file đối soát + kết quả khớp
+type CODRemittanceLine struct {
+	ID             uuid.UUID        `json:"id"`
+	RemittanceID   uuid.UUID        `json:"remittance_id"`
+	LineNo         int              `json:"line_no"`
+	TrackingNumber *string          `json:"tracking_number,omitempty"`
+	OrderNumber    *string          `json:"order_number,omitempty"`
+	Amount         decimal.Decimal  `json:"amount"`
+	ShipmentID     *uuid.UUID       `json:"shipment_id,omitempty"`
+	OrderID        *uuid.UUID       `json:"order_id,omitempty"`
+	ExpectedAmount *decimal.Decimal `json:"expected_amount,omitempty"`
+	Difference     *decimal.Decimal `json:"difference,omitempty"`
+	MatchStatus    string           `json:"match_status"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
@@ -96,6 +96,18 @@ const (
 	ErrCodeOrderCancelled = "PAY022"
 	ErrCodeRefundFailed   = "PAY023"
 	ErrCodeInternalError  = "PAY024" // Internal system error
+
+	// Ledger errors
+	ErrCodeLedgerUnbalanced   = "PAY025"
+	ErrCodeInvalidLedgerQuery = "PAY026"
+
+	// COD remittance errors
+	ErrCodeInvalidRemittance         = "PAY027"
+	ErrCodeRemittanceAlreadyImported = "PAY028"
+	ErrCodeRemittanceNotFound        = "PAY029"
+
+	// Webhook amount errors
+	ErrCodeAmountMismatch = "PAY030"
 )
 
 // =====================================================
@@ -130,6 +142,9 @@ func MapVNPayErrorCode(vnpCode string) (string, string) {
 // =====================================================
 // MOMO ERROR CODE MAPPING
 // =====================================================
+// MomoResultCodeSuccess resultCode của IPN thanh toán thành công
+const MomoResultCodeSuccess = 0
+
 var MomoErrorCodeMap = map[int]struct {
 	InternalCode string
 	Message      string
@@ -168,6 +183,13 @@ const (
 
 	// Default currency
 	DefaultCurrency = "VND"
+
+	// Ledger export/summary mặc định 30 ngày gần nhất, tối đa 1 năm
+	LedgerDefaultRangeDays = 30
+	LedgerMaxRangeDays     = 366
+
+	// File đối soát COD xử lý đồng bộ trong 1 transaction → giới hạn số dòng
+	CODRemittanceMaxLines = 5000
 )
 
 // =====================================================
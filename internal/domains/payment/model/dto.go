@@ -2,6 +2,8 @@ package model
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -197,7 +199,7 @@ func (r *AdminListPaymentsRequest) Validate() error {
 type AdminPaymentResponse struct {
 	TransactionID    uuid.UUID       `json:"transaction_id"`
 	OrderNumber      string          `json:"order_number"`
-	UserEmail        string          `json:"user_email"`
+	UserEmail        string          `json:"user_email" pii:"email"`
 	Gateway          string          `json:"gateway"`
 	Status           string          `json:"status"`
 	Amount           decimal.Decimal `json:"amount"`
@@ -246,7 +248,7 @@ type OrderInfo struct {
 
 type UserInfo struct {
 	ID    uuid.UUID `json:"id"`
-	Email string    `json:"email"`
+	Email string    `json:"email" pii:"email"`
 	Name  string    `json:"name"`
 }
 
@@ -318,6 +320,15 @@ type VNPayWebhookRequest struct {
 	VnpTransactionStatus string `form:"vnp_TransactionStatus"`
 }
 
+// PaidAmount vnp_Amount (VND x 100) → VND
+func (r VNPayWebhookRequest) PaidAmount() (decimal.Decimal, error) {
+	cents, err := strconv.ParseInt(r.VnpAmount, 10, 64)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid vnp_Amount %q", r.VnpAmount)
+	}
+	return decimal.NewFromInt(cents).Div(decimal.NewFromInt(100)), nil
+}
+
 // =====================================================
 // VERIFY PAYMENT RESPONSE (for ReturnURL verification)
 // =====================================================
@@ -335,3 +346,161 @@ type VerifyPaymentResponse struct {
 	ResponseCode     string          `json:"response_code"`
 	AlreadyProcessed bool            `json:"already_processed,omitempty"`
 }
+
+// =====================================================
+// ADMIN: PAYMENT LEDGER DTOs
+// =====================================================
+
+type LedgerQueryRequest struct {
+	FromDate  *time.Time `form:"from_date"`
+	ToDate    *time.Time `form:"to_date"`
+	EntryType *string    `form:"entry_type"`
+}
+
+func (r *LedgerQueryRequest) Validate() error {
+	now := time.Now()
+	if r.ToDate == nil {
+		r.ToDate = &now
+	}
+	if r.FromDate == nil {
+		from := r.ToDate.AddDate(0, 0, -LedgerDefaultRangeDays)
+		r.FromDate = &from
+	}
+	if r.FromDate.After(*r.ToDate) {
+		return fmt.Errorf("from_date must be before to_date")
+	}
+	if r.ToDate.Sub(*r.FromDate) > time.Duration(LedgerMaxRangeDays)*24*time.Hour {
+		return fmt.Errorf("date range must not exceed %d days", LedgerMaxRangeDays)
+	}
+	if r.EntryType != nil {
+		valid := false
+		for _, t := range ValidLedgerTypes {
+			if *r.EntryType == t {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid entry_type: %s", *r.EntryType)
+		}
+	}
+	return nil
+}
+
+// OrderLedgerResponse - toàn bộ dòng tiền của 1 đơn + tổng hợp
+type OrderLedgerResponse struct {
+	OrderID       uuid.UUID           `json:"order_id"`
+	Transactions  []LedgerTransaction `json:"transactions"`
+	TotalReceived decimal.Decimal     `json:"total_received"` // capture + cod_collection + gift_card_redemption
+	TotalRefunded decimal.Decimal     `json:"total_refunded"`
+	NetAmount     decimal.Decimal     `json:"net_amount"`
+}
+
+// LedgerAccountBalance - tổng Nợ/Có của 1 tài khoản trong khoảng thời gian
+type LedgerAccountBalance struct {
+	Account string          `json:"account"`
+	Debit   decimal.Decimal `json:"debit"`
+	Credit  decimal.Decimal `json:"credit"`
+	Balance decimal.Decimal `json:"balance"` // debit - credit
+}
+
+type LedgerSummaryResponse struct {
+	FromDate time.Time              `json:"from_date"`
+	ToDate   time.Time              `json:"to_date"`
+	Accounts []LedgerAccountBalance `json:"accounts"`
+	// Tổng Nợ = tổng Có nếu sổ cân (luôn đúng khi constraint trigger hoạt động)
+	TotalDebit  decimal.Decimal `json:"total_debit"`
+	TotalCredit decimal.Decimal `json:"total_credit"`
+}
+
+// LedgerExportRow - 1 bút toán trong file export cho kế toán
+type LedgerExportRow struct {
+	CreatedAt           time.Time
+	LedgerTransactionID uuid.UUID
+	OrderID             uuid.UUID
+	OrderNumber         string
+	EntryType           string
+	Gateway             string
+	Account             string
+	Direction           string
+	Amount              decimal.Decimal
+	Currency            string
+	Description         string
+}
+
+// =====================================================
+// ADMIN: COD REMITTANCE DTOs
+// =====================================================
+
+// ImportCODRemittanceRequest - thông tin file đối soát (multipart form, file ở field "file")
+// File CSV có header, cột: tracking_number, order_number, amount (cần ít nhất 1 trong 2 cột mã)
+type ImportCODRemittanceRequest struct {
+	Carrier    string    `form:"carrier" binding:"required"`
+	Reference  string    `form:"reference" binding:"required,max=100"`
+	RemittedAt time.Time `form:"remitted_at" binding:"required"`
+}
+
+func (r *ImportCODRemittanceRequest) Validate() error {
+	r.Carrier = strings.TrimSpace(r.Carrier)
+	r.Reference = strings.TrimSpace(r.Reference)
+
+	valid := false
+	for _, c := range ValidCODCarriers {
+		if r.Carrier == c {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid carrier: %s", r.Carrier)
+	}
+	if r.Reference == "" {
+		return fmt.Errorf("reference is required")
+	}
+	if r.RemittedAt.After(time.Now()) {
+		return fmt.Errorf("remitted_at cannot be in the future")
+	}
+	return nil
+}
+
+// CODRemittanceResponse - kết quả import/đối soát của 1 file
+type CODRemittanceResponse struct {
+	Remittance CODRemittance       `json:"remittance"`
+	Lines      []CODRemittanceLine `json:"lines"`
+}
+
+type ListCODShipmentsRequest struct {
+	Status  *string `form:"status"` // collected, remitted, shortfall
+	Carrier *string `form:"carrier"`
+	Page    int     `form:"page"`
+	Limit   int     `form:"limit"`
+}
+
+func (r *ListCODShipmentsRequest) Validate() error {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+	if r.Limit < 1 || r.Limit > 100 {
+		r.Limit = 20
+	}
+	if r.Status != nil {
+		valid := false
+		for _, s := range ValidCODShipmentStatuses {
+			if *r.Status == s {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid status: %s", *r.Status)
+		}
+	}
+	return nil
+}
+
+type ListCODShipmentsResponse struct {
+	Shipments []CODShipment `json:"shipments"`
+	// Tổng tiền hãng còn phải nộp của các kiện thoả filter (không phân trang)
+	OutstandingAmount decimal.Decimal `json:"outstanding_amount"`
+	Pagination        PaginationMeta  `json:"pagination"`
+}
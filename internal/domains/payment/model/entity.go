@@ -198,3 +198,28 @@ func (w *PaymentWebhookLog) MarkProcessingError(err error) {
 	errMsg := err.Error()
 	w.ProcessingError = &errMsg
 }
+
+// =====================================================
+// PAYMENT CAPTURE (webhook thành công → chốt đơn)
+// =====================================================
+
+// PaidOrder đơn vừa chuyển sang paid trong transaction capture (kèm liên hệ để gửi email xác nhận)
+type PaidOrder struct {
+	OrderID       uuid.UUID       `json:"order_id"`
+	OrderNumber   string          `json:"order_number"`
+	UserID        uuid.UUID       `json:"user_id"`
+	AddressID     uuid.UUID       `json:"address_id"`
+	Status        string          `json:"status"`
+	Total         decimal.Decimal `json:"total"`
+	PaymentMethod string          `json:"payment_method"`
+	UserEmail     string          `json:"user_email" pii:"email"`
+	UserLocale    string          `json:"user_locale"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// SaleLine 1 dòng hàng cần chốt bán tại kho đang giữ tồn (kho của kiện, đơn cũ thì kho của đơn)
+type SaleLine struct {
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	BookID      uuid.UUID `json:"book_id"`
+	Quantity    int       `json:"quantity"`
+}
@@ -28,6 +28,11 @@ var (
 	ErrRefundRequestNotFound   = errors.New("refund request not found")
 	ErrCannotApproveRefund     = errors.New("cannot approve refund request")
 	ErrCannotRejectRefund      = errors.New("cannot reject refund request")
+	ErrLedgerUnbalanced        = errors.New("ledger transaction is unbalanced")
+	ErrInvalidRemittance       = errors.New("invalid COD remittance file")
+	ErrRemittanceExists        = errors.New("COD remittance already imported")
+	ErrRemittanceNotFound      = errors.New("COD remittance not found")
+	ErrAmountMismatch          = errors.New("webhook amount does not match payment amount")
 )
 
 // =====================================================
@@ -120,6 +125,15 @@ func NewInvalidSignatureError() *PaymentError {
 	)
 }
 
+// NewAmountMismatchError số tiền gateway báo khác payment_transactions.amount
+func NewAmountMismatchError(expected, actual string) *PaymentError {
+	return NewPaymentError(
+		ErrCodeAmountMismatch,
+		fmt.Sprintf("Webhook amount %s does not match payment amount %s", actual, expected),
+		ErrAmountMismatch,
+	)
+}
+
 func NewWebhookAlreadyProcessedError() *PaymentError {
 	return NewPaymentError(
 		ErrCodeWebhookAlreadyProcessed,
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// LEDGER ENTRY TYPES
+// =====================================================
+const (
+	// Authorization / gift card: chưa phát sinh (VNPay/Momo authorize + capture cùng lúc,
+	// chưa có thẻ quà tặng) - giữ sẵn để DB CHECK và export thống nhất khi bổ sung
+	LedgerTypeAuthorization      = "authorization"
+	LedgerTypeCapture            = "capture"
+	LedgerTypeRefund             = "refund"
+	LedgerTypeCODCollection      = "cod_collection"
+	LedgerTypeCODRemittance      = "cod_remittance"
+	LedgerTypeGiftCardRedemption = "gift_card_redemption"
+)
+
+var ValidLedgerTypes = []string{
+	LedgerTypeAuthorization,
+	LedgerTypeCapture,
+	LedgerTypeRefund,
+	LedgerTypeCODCollection,
+	LedgerTypeCODRemittance,
+	LedgerTypeGiftCardRedemption,
+}
+
+// =====================================================
+// LEDGER ACCOUNTS
+// =====================================================
+const (
+	AccountGatewayClearing = "gateway_clearing" // tiền đang nằm ở VNPay/Momo, chờ đối soát về ngân hàng
+	AccountCODReceivable   = "cod_receivable"   // tiền COD đơn vị vận chuyển đã thu, chưa nộp về
+	AccountOrderRevenue    = "order_revenue"    // doanh thu đơn hàng
+	AccountSalesRefunds    = "sales_refunds"    // hoàn tiền (giảm trừ doanh thu)
+	AccountCashInBank      = "cash_in_bank"     // tiền đã về tài khoản ngân hàng của shop
+)
+
+const (
+	LedgerDirectionDebit  = "debit"
+	LedgerDirectionCredit = "credit"
+)
+
+// =====================================================
+// LEDGER ENTITIES
+// =====================================================
+
+// LedgerTransaction - 1 dòng tiền gắn với đơn, gồm các bút toán Nợ/Có cân nhau
+type LedgerTransaction struct {
+	ID                   uuid.UUID       `json:"id"`
+	OrderID              uuid.UUID       `json:"order_id"`
+	PaymentTransactionID *uuid.UUID      `json:"payment_transaction_id,omitempty"`
+	RefundRequestID      *uuid.UUID      `json:"refund_request_id,omitempty"`
+	EntryType            string          `json:"entry_type"`
+	Gateway              *string         `json:"gateway,omitempty"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	Description          string          `json:"description"`
+	IdempotencyKey       string          `json:"idempotency_key"`
+	CreatedBy            *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt            time.Time       `json:"created_at"`
+
+	Entries []LedgerEntry `json:"entries"`
+}
+
+// LedgerEntry - 1 bút toán Nợ hoặc Có
+type LedgerEntry struct {
+	ID        uuid.UUID       `json:"id"`
+	Account   string          `json:"account"`
+	Direction string          `json:"direction"`
+	Amount    decimal.Decimal `json:"amount"`
+}
+
+// IsBalanced kiểm tra tổng Nợ = tổng Có (DB cũng kiểm tra lúc commit)
+func (t *LedgerTransaction) IsBalanced() bool {
+	debit, credit := decimal.Zero, decimal.Zero
+	for _, e := range t.Entries {
+		if e.Direction == LedgerDirectionDebit {
+			debit = debit.Add(e.Amount)
+		} else {
+			credit = credit.Add(e.Amount)
+		}
+	}
+	return len(t.Entries) >= 2 && debit.Equal(credit)
+}
+
+// newLedgerTransaction - dòng tiền 2 bút toán: Nợ debitAccount / Có creditAccount cùng số tiền
+func newLedgerTransaction(
+	orderID uuid.UUID,
+	entryType string,
+	amount decimal.Decimal,
+	debitAccount, creditAccount string,
+	idempotencyKey string,
+) *LedgerTransaction {
+	return &LedgerTransaction{
+		ID:             uuid.New(),
+		OrderID:        orderID,
+		EntryType:      entryType,
+		Amount:         amount,
+		Currency:       DefaultCurrency,
+		IdempotencyKey: idempotencyKey,
+		CreatedAt:      time.Now(),
+		Entries: []LedgerEntry{
+			{ID: uuid.New(), Account: debitAccount, Direction: LedgerDirectionDebit, Amount: amount},
+			{ID: uuid.New(), Account: creditAccount, Direction: LedgerDirectionCredit, Amount: amount},
+		},
+	}
+}
+
+// NewCaptureLedger - payment gateway thành công: Nợ gateway_clearing / Có order_revenue
+func NewCaptureLedger(payment *PaymentTransaction, capturedBy *uuid.UUID) *LedgerTransaction {
+	t := newLedgerTransaction(
+		payment.OrderID, LedgerTypeCapture, payment.Amount,
+		AccountGatewayClearing, AccountOrderRevenue,
+		fmt.Sprintf("%s:%s", LedgerTypeCapture, payment.ID),
+	)
+	t.PaymentTransactionID = &payment.ID
+	t.Gateway = &payment.Gateway
+	t.Currency = payment.Currency
+	t.CreatedBy = capturedBy
+	t.Description = fmt.Sprintf("Captured %s payment", payment.Gateway)
+	return t
+}
+
+// NewRefundLedger - hoàn tiền (toàn phần hoặc một phần): Nợ sales_refunds / Có gateway_clearing
+// Mỗi refund request là 1 dòng tiền riêng → audit được từng lần hoàn một phần
+func NewRefundLedger(refund *RefundRequest, payment *PaymentTransaction, approvedBy uuid.UUID) *LedgerTransaction {
+	t := newLedgerTransaction(
+		refund.OrderID, LedgerTypeRefund, refund.RequestedAmount,
+		AccountSalesRefunds, AccountGatewayClearing,
+		fmt.Sprintf("%s:%s", LedgerTypeRefund, refund.ID),
+	)
+	t.PaymentTransactionID = &payment.ID
+	t.RefundRequestID = &refund.ID
+	t.Gateway = &payment.Gateway
+	t.Currency = payment.Currency
+	t.CreatedBy = &approvedBy
+	t.Description = refund.Reason
+	return t
+}
+
+// NewCODCollectionLedger - giao COD thành công, shipper đã thu tiền: Nợ cod_receivable / Có order_revenue
+func NewCODCollectionLedger(orderID uuid.UUID, amount decimal.Decimal, collectedBy *uuid.UUID) *LedgerTransaction {
+	t := newLedgerTransaction(
+		orderID, LedgerTypeCODCollection, amount,
+		AccountCODReceivable, AccountOrderRevenue,
+		fmt.Sprintf("%s:%s", LedgerTypeCODCollection, orderID),
+	)
+	gateway := GatewayCOD
+	t.Gateway = &gateway
+	t.CreatedBy = collectedBy
+	t.Description = "Cash collected on delivery"
+	return t
+}
+
+// NewCODRemittanceLedger - hãng vận chuyển nộp tiền COD về tài khoản: Nợ cash_in_bank / Có cod_receivable
+// Mỗi dòng trong file đối soát là 1 dòng tiền (kiện nộp thiếu có thể được nộp bù ở file sau)
+func NewCODRemittanceLedger(line *CODRemittanceLine, remittance *CODRemittance) *LedgerTransaction {
+	t := newLedgerTransaction(
+		*line.OrderID, LedgerTypeCODRemittance, line.Amount,
+		AccountCashInBank, AccountCODReceivable,
+		fmt.Sprintf("%s:%s", LedgerTypeCODRemittance, line.ID),
+	)
+	gateway := GatewayCOD
+	t.Gateway = &gateway
+	t.CreatedBy = remittance.ImportedBy
+	t.Description = fmt.Sprintf("COD remittance %s/%s", remittance.Carrier, remittance.Reference)
+	return t
+}
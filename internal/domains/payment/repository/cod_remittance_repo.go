@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/payment/model"
+)
+
+// =====================================================
+// COD REMITTANCE REPOSITORY IMPLEMENTATION
+// =====================================================
+type codRemittanceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCODRemittanceRepository(pool *pgxpool.Pool) CODRemittanceRepoInterface {
+	return &codRemittanceRepository{pool: pool}
+}
+
+// CreateShipmentWithTx ghi kiện COD đã giao; đơn đã có kiện (giao lại sau khi hoàn) → bỏ qua
+// Carrier lấy từ vận đơn đã mua nhãn (shipments), đơn giao tay thì để trống tới khi import đối soát
+func (r *codRemittanceRepository) CreateShipmentWithTx(ctx context.Context, tx pgx.Tx, shipment *model.CODShipment) error {
+	query := `
+		INSERT INTO cod_shipments (
+			id, order_id, carrier, tracking_number, cod_amount, remitted_amount,
+			status, collected_at, created_at, updated_at
+		) VALUES (
+			$1, $2, (SELECT carrier FROM shipments WHERE order_id = $2),
+			$3, $4, $5, $6, $7, $8, $9
+		)
+		ON CONFLICT (order_id) DO NOTHING
+	`
+
+	_, err := tx.Exec(ctx, query,
+		shipment.ID,
+		shipment.OrderID,
+		shipment.TrackingNumber,
+		shipment.CODAmount,
+		shipment.RemittedAmount,
+		shipment.Status,
+		shipment.CollectedAt,
+		shipment.CreatedAt,
+		shipment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create COD shipment: %w", err)
+	}
+	return nil
+}
+
+// MarkOrderPaidWithTx ghi nhận đơn COD đã thanh toán
+// COD không có payment record (không qua gateway) → update trực tiếp orders
+func (r *codRemittanceRepository) MarkOrderPaidWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) error {
+	query := `
+		UPDATE orders
+		SET payment_status = 'paid',
+			paid_at = NOW(),
+			version = version + 1
+		WHERE id = $1
+			AND payment_method = 'cod'
+			AND payment_status = 'pending'
+	`
+
+	if _, err := tx.Exec(ctx, query, orderID); err != nil {
+		return fmt.Errorf("failed to mark COD order paid: %w", err)
+	}
+	return nil
+}
+
+// FindShipmentsForUpdateWithTx lấy + khoá các kiện khớp mã vận đơn hoặc mã đơn trong file đối soát
+// Khoá FOR UPDATE → 2 file import song song không cộng trùng remitted_amount
+func (r *codRemittanceRepository) FindShipmentsForUpdateWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	trackingNumbers []string,
+	orderNumbers []string,
+) ([]model.CODShipment, error) {
+	query := `
+		SELECT
+			s.id, s.order_id, o.order_number, s.carrier, s.tracking_number,
+			s.cod_amount, s.remitted_amount, s.status, s.collected_at,
+			s.last_remittance_id, s.reconciled_at, s.created_at, s.updated_at
+		FROM cod_shipments s
+		JOIN orders o ON o.id = s.order_id
+		WHERE s.tracking_number = ANY($1) OR o.order_number = ANY($2)
+		ORDER BY s.collected_at
+		FOR UPDATE OF s
+	`
+
+	rows, err := tx.Query(ctx, query, trackingNumbers, orderNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find COD shipments: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCODShipments(rows)
+}
+
+// UpdateShipmentRemittanceWithTx cập nhật số đã nộp + trạng thái sau khi khớp 1 dòng đối soát
+func (r *codRemittanceRepository) UpdateShipmentRemittanceWithTx(ctx context.Context, tx pgx.Tx, shipment *model.CODShipment) error {
+	query := `
+		UPDATE cod_shipments
+		SET carrier = $2,
+			remitted_amount = $3,
+			status = $4,
+			last_remittance_id = $5,
+			reconciled_at = $6
+		WHERE id = $1
+	`
+
+	_, err := tx.Exec(ctx, query,
+		shipment.ID,
+		shipment.Carrier,
+		shipment.RemittedAmount,
+		shipment.Status,
+		shipment.LastRemittanceID,
+		shipment.ReconciledAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update COD shipment: %w", err)
+	}
+	return nil
+}
+
+// CreateRemittanceWithTx ghi file đối soát; (carrier, reference) đã import → ErrRemittanceExists
+func (r *codRemittanceRepository) CreateRemittanceWithTx(ctx context.Context, tx pgx.Tx, remittance *model.CODRemittance) error {
+	query := `
+		INSERT INTO cod_remittances (
+			id, carrier, reference, remitted_at, file_name, total_amount,
+			line_count, matched_count, shortfall_count, overpaid_count,
+			unmatched_count, duplicate_count, imported_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		remittance.ID,
+		remittance.Carrier,
+		remittance.Reference,
+		remittance.RemittedAt,
+		remittance.FileName,
+		remittance.TotalAmount,
+		remittance.LineCount,
+		remittance.MatchedCount,
+		remittance.ShortfallCount,
+		remittance.OverpaidCount,
+		remittance.UnmatchedCount,
+		remittance.DuplicateCount,
+		remittance.ImportedBy,
+		remittance.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // uq_cod_remittances_carrier_reference
+			return model.ErrRemittanceExists
+		}
+		return fmt.Errorf("failed to create COD remittance: %w", err)
+	}
+	return nil
+}
+
+// CreateRemittanceLinesWithTx ghi các dòng đối soát (batch)
+func (r *codRemittanceRepository) CreateRemittanceLinesWithTx(ctx context.Context, tx pgx.Tx, lines []model.CODRemittanceLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	query := `
+		INSERT INTO cod_remittance_lines (
+			id, remittance_id, line_no, tracking_number, order_number, amount,
+			shipment_id, order_id, expected_amount, difference, match_status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	for _, line := range lines {
+		batch.Queue(query,
+			line.ID,
+			line.RemittanceID,
+			line.LineNo,
+			line.TrackingNumber,
+			line.OrderNumber,
+			line.Amount,
+			line.ShipmentID,
+			line.OrderID,
+			line.ExpectedAmount,
+			line.Difference,
+			line.MatchStatus,
+			line.CreatedAt,
+		)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for i := 0; i < len(lines); i++ {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to insert COD remittance line %d: %w", lines[i].LineNo, err)
+		}
+	}
+	return nil
+}
+
+// GetRemittanceByID gets an imported remittance file
+func (r *codRemittanceRepository) GetRemittanceByID(ctx context.Context, id uuid.UUID) (*model.CODRemittance, error) {
+	query := `
+		SELECT
+			id, carrier, reference, remitted_at, file_name, total_amount,
+			line_count, matched_count, shortfall_count, overpaid_count,
+			unmatched_count, duplicate_count, imported_by, created_at
+		FROM cod_remittances
+		WHERE id = $1
+	`
+
+	var rm model.CODRemittance
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&rm.ID,
+		&rm.Carrier,
+		&rm.Reference,
+		&rm.RemittedAt,
+		&rm.FileName,
+		&rm.TotalAmount,
+		&rm.LineCount,
+		&rm.MatchedCount,
+		&rm.ShortfallCount,
+		&rm.OverpaidCount,
+		&rm.UnmatchedCount,
+		&rm.DuplicateCount,
+		&rm.ImportedBy,
+		&rm.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrRemittanceNotFound
+		}
+		return nil, fmt.Errorf("failed to get COD remittance: %w", err)
+	}
+
+	return &rm, nil
+}
+
+// ListRemittanceLines lists lines of a remittance file in file order
+func (r *codRemittanceRepository) ListRemittanceLines(ctx context.Context, remittanceID uuid.UUID) ([]model.CODRemittanceLine, error) {
+	query := `
+		SELECT
+			id, remittance_id, line_no, tracking_number, order_number, amount,
+			shipment_id, order_id, expected_amount, difference, match_status, created_at
+		FROM cod_remittance_lines
+		WHERE remittance_id = $1
+		ORDER BY line_no
+	`
+
+	rows, err := r.pool.Query(ctx, query, remittanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list COD remittance lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]model.CODRemittanceLine, 0)
+	for rows.Next() {
+		var line model.CODRemittanceLine
+		if err := rows.Scan(
+			&line.ID,
+			&line.RemittanceID,
+			&line.LineNo,
+			&line.TrackingNumber,
+			&line.OrderNumber,
+			&line.Amount,
+			&line.ShipmentID,
+			&line.OrderID,
+			&line.ExpectedAmount,
+			&line.Difference,
+			&line.MatchStatus,
+			&line.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan COD remittance line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// ListShipments lists COD shipments (oldest first) + tổng tiền còn phải nộp của toàn bộ kết quả lọc
+func (r *codRemittanceRepository) ListShipments(
+	ctx context.Context,
+	status, carrier *string,
+	page, limit int,
+) ([]model.CODShipment, int, decimal.Decimal, error) {
+	where := `
+		WHERE ($1::TEXT IS NULL OR s.status = $1)
+			AND ($2::TEXT IS NULL OR s.carrier = $2)
+	`
+
+	var total int
+	outstanding := decimal.Zero
+	countQuery := `
+		SELECT COUNT(*), COALESCE(SUM(s.cod_amount - s.remitted_amount), 0)
+		FROM cod_shipments s
+	` + where
+	if err := r.pool.QueryRow(ctx, countQuery, status, carrier).Scan(&total, &outstanding); err != nil {
+		return nil, 0, decimal.Zero, fmt.Errorf("failed to count COD shipments: %w", err)
+	}
+
+	query := `
+		SELECT
+			s.id, s.order_id, o.order_number, s.carrier, s.tracking_number,
+			s.cod_amount, s.remitted_amount, s.status, s.collected_at,
+			s.last_remittance_id, s.reconciled_at, s.created_at, s.updated_at
+		FROM cod_shipments s
+		JOIN orders o ON o.id = s.order_id
+	` + where + `
+		ORDER BY s.collected_at ASC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, status, carrier, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, decimal.Zero, fmt.Errorf("failed to list COD shipments: %w", err)
+	}
+	defer rows.Close()
+
+	shipments, err := scanCODShipments(rows)
+	if err != nil {
+		return nil, 0, decimal.Zero, err
+	}
+
+	return shipments, total, outstanding, nil
+}
+
+func scanCODShipments(rows pgx.Rows) ([]model.CODShipment, error) {
+	shipments := make([]model.CODShipment, 0)
+	for rows.Next() {
+		var s model.CODShipment
+		if err := rows.Scan(
+			&s.ID,
+			&s.OrderID,
+			&s.OrderNumber,
+			&s.Carrier,
+			&s.TrackingNumber,
+			&s.CODAmount,
+			&s.RemittedAmount,
+			&s.Status,
+			&s.CollectedAt,
+			&s.LastRemittanceID,
+			&s.ReconciledAt,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan COD shipment: %w", err)
+		}
+		shipments = append(shipments, s)
+	}
+	return shipments, rows.Err()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -24,6 +25,16 @@ type PaymentRepoInteface interface {
 	// UpdateStatusWithTx updates payment status within transaction
 	UpdateStatusWithTx(ctx context.Context, tx pgx.Tx, paymentID uuid.UUID, status string, details map[string]interface{}) error
 
+	// MarkAsSuccessWithTx marks payment as successful within transaction (webhook capture)
+	MarkAsSuccessWithTx(ctx context.Context, tx pgx.Tx, id uuid.UUID, transactionID string, gatewayResponse map[string]interface{}, paymentDetails map[string]interface{}) error
+
+	// MarkOrderPaidWithTx chuyển đơn sang paid (pending → confirmed), khoá dòng orders
+	// Trả nil nếu đơn đã paid trước đó (webhook lặp / ReturnURL + IPN cùng về)
+	MarkOrderPaidWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (*model.PaidOrder, error)
+
+	// ListSaleLinesWithTx các dòng hàng của đơn kèm kho đang giữ tồn
+	ListSaleLinesWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) ([]model.SaleLine, error)
+
 	// ============================================
 	// STANDALONE METHODS
 	// ============================================
@@ -159,6 +170,55 @@ type WebhookRepoInterface interface {
 	MarkProcessingError(ctx context.Context, id uuid.UUID, errorMsg string) error
 }
 
+// =====================================================
+// PAYMENT LEDGER REPOSITORY INTERFACE
+// =====================================================
+type LedgerRepoInterface interface {
+	// PostWithTx ghi dòng tiền + bút toán trong transaction của caller
+	// idempotency_key đã tồn tại (webhook retry, reconcile lại) → bỏ qua, trả về false
+	PostWithTx(ctx context.Context, tx pgx.Tx, txn *model.LedgerTransaction) (bool, error)
+
+	// Post ghi dòng tiền trong transaction riêng
+	Post(ctx context.Context, txn *model.LedgerTransaction) (bool, error)
+
+	// ListByOrderID lists ledger transactions (with entries) of an order, oldest first
+	ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]model.LedgerTransaction, error)
+
+	// GetAccountBalances tổng Nợ/Có theo tài khoản trong [from, to]
+	GetAccountBalances(ctx context.Context, from, to time.Time, entryType *string) ([]model.LedgerAccountBalance, error)
+
+	// ListExportRows lists ledger entries in [from, to] for finance export
+	ListExportRows(ctx context.Context, from, to time.Time, entryType *string) ([]model.LedgerExportRow, error)
+}
+
+// =====================================================
+// COD REMITTANCE REPOSITORY INTERFACE
+// =====================================================
+type CODRemittanceRepoInterface interface {
+	// CreateShipmentWithTx ghi kiện COD khi đơn giao thành công (transaction cập nhật trạng thái đơn)
+	CreateShipmentWithTx(ctx context.Context, tx pgx.Tx, shipment *model.CODShipment) error
+
+	// MarkOrderPaidWithTx chuyển đơn COD sang payment_status = paid
+	MarkOrderPaidWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) error
+
+	// FindShipmentsForUpdateWithTx lấy + khoá kiện theo mã vận đơn hoặc mã đơn
+	FindShipmentsForUpdateWithTx(ctx context.Context, tx pgx.Tx, trackingNumbers, orderNumbers []string) ([]model.CODShipment, error)
+
+	// UpdateShipmentRemittanceWithTx cập nhật số đã nộp + trạng thái kiện
+	UpdateShipmentRemittanceWithTx(ctx context.Context, tx pgx.Tx, shipment *model.CODShipment) error
+
+	// CreateRemittanceWithTx ghi file đối soát, trả ErrRemittanceExists nếu đã import
+	CreateRemittanceWithTx(ctx context.Context, tx pgx.Tx, remittance *model.CODRemittance) error
+
+	// CreateRemittanceLinesWithTx ghi các dòng đối soát
+	CreateRemittanceLinesWithTx(ctx context.Context, tx pgx.Tx, lines []model.CODRemittanceLine) error
+
+	// Admin queries
+	GetRemittanceByID(ctx context.Context, id uuid.UUID) (*model.CODRemittance, error)
+	ListRemittanceLines(ctx context.Context, remittanceID uuid.UUID) ([]model.CODRemittanceLine, error)
+	ListShipments(ctx context.Context, status, carrier *string, page, limit int) ([]model.CODShipment, int, decimal.Decimal, error)
+}
+
 // =====================================================
 // TRANSACTION MANAGER
 // =====================================================
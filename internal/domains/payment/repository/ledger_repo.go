@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/payment/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// PAYMENT LEDGER REPOSITORY IMPLEMENTATION
+// =====================================================
+type ledgerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLedgerRepository(pool *pgxpool.Pool) LedgerRepoInterface {
+	return &ledgerRepository{pool: pool}
+}
+
+// PostWithTx ghi dòng tiền trong transaction của caller
+// Bút toán phải cân (tổng Nợ = tổng Có) - kiểm tra ở đây để báo lỗi rõ ràng,
+// DB kiểm tra lại bằng constraint trigger lúc commit
+func (r *ledgerRepository) PostWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	txn *model.LedgerTransaction,
+) (bool, error) {
+	if !txn.IsBalanced() {
+		return false, model.NewPaymentError(
+			model.ErrCodeLedgerUnbalanced,
+			fmt.Sprintf("Ledger transaction %s is unbalanced", txn.IdempotencyKey),
+			model.ErrLedgerUnbalanced,
+		)
+	}
+
+	query := `
+		INSERT INTO ledger_transactions (
+			id, order_id, payment_transaction_id, refund_request_id,
+			entry_type, gateway, amount, currency, description,
+			idempotency_key, created_by, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`
+
+	result, err := tx.Exec(ctx, query,
+		txn.ID,
+		txn.OrderID,
+		txn.PaymentTransactionID,
+		txn.RefundRequestID,
+		txn.EntryType,
+		txn.Gateway,
+		txn.Amount,
+		txn.Currency,
+		txn.Description,
+		txn.IdempotencyKey,
+		txn.CreatedBy,
+		txn.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert ledger transaction: %w", err)
+	}
+
+	// Đã ghi trước đó → không ghi lại bút toán
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	entryQuery := `
+		INSERT INTO ledger_entries (id, ledger_transaction_id, account, direction, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	for _, entry := range txn.Entries {
+		if _, err := tx.Exec(ctx, entryQuery,
+			entry.ID, txn.ID, entry.Account, entry.Direction, entry.Amount, txn.CreatedAt,
+		); err != nil {
+			return false, fmt.Errorf("failed to insert ledger entry: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// Post ghi dòng tiền trong transaction riêng
+func (r *ledgerRepository) Post(ctx context.Context, txn *model.LedgerTransaction) (bool, error) {
+	return database.WithTransactionResult(ctx, r.pool, func(tx pgx.Tx) (bool, error) {
+		return r.PostWithTx(ctx, tx, txn)
+	})
+}
+
+// ListByOrderID lists ledger transactions (with entries) of an order, oldest first
+func (r *ledgerRepository) ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]model.LedgerTransaction, error) {
+	query := `
+		SELECT
+			lt.id, lt.order_id, lt.payment_transaction_id, lt.refund_request_id,
+			lt.entry_type, lt.gateway, lt.amount, lt.currency, lt.description,
+			lt.idempotency_key, lt.created_by, lt.created_at,
+			le.id, le.account, le.direction, le.amount
+		FROM ledger_transactions lt
+		JOIN ledger_entries le ON le.ledger_transaction_id = lt.id
+		WHERE lt.order_id = $1
+		ORDER BY lt.created_at ASC, lt.id, le.direction DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := make([]model.LedgerTransaction, 0)
+	for rows.Next() {
+		var t model.LedgerTransaction
+		var e model.LedgerEntry
+		if err := rows.Scan(
+			&t.ID, &t.OrderID, &t.PaymentTransactionID, &t.RefundRequestID,
+			&t.EntryType, &t.Gateway, &t.Amount, &t.Currency, &t.Description,
+			&t.IdempotencyKey, &t.CreatedBy, &t.CreatedAt,
+			&e.ID, &e.Account, &e.Direction, &e.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger transaction: %w", err)
+		}
+
+		// Rows đã sort theo transaction → gom entries vào transaction cuối cùng
+		if n := len(transactions); n > 0 && transactions[n-1].ID == t.ID {
+			transactions[n-1].Entries = append(transactions[n-1].Entries, e)
+			continue
+		}
+		t.Entries = []model.LedgerEntry{e}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
+
+// GetAccountBalances tổng Nợ/Có theo tài khoản trong [from, to]
+func (r *ledgerRepository) GetAccountBalances(
+	ctx context.Context,
+	from, to time.Time,
+	entryType *string,
+) ([]model.LedgerAccountBalance, error) {
+	query := `
+		SELECT
+			le.account,
+			COALESCE(SUM(le.amount) FILTER (WHERE le.direction = 'debit'), 0) AS debit,
+			COALESCE(SUM(le.amount) FILTER (WHERE le.direction = 'credit'), 0) AS credit
+		FROM ledger_entries le
+		JOIN ledger_transactions lt ON lt.id = le.ledger_transaction_id
+		WHERE lt.created_at >= $1 AND lt.created_at <= $2
+			AND ($3::text IS NULL OR lt.entry_type = $3)
+		GROUP BY le.account
+		ORDER BY le.account
+	`
+
+	rows, err := r.pool.Query(ctx, query, from, to, entryType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := make([]model.LedgerAccountBalance, 0)
+	for rows.Next() {
+		var b model.LedgerAccountBalance
+		if err := rows.Scan(&b.Account, &b.Debit, &b.Credit); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger balance: %w", err)
+		}
+		b.Balance = b.Debit.Sub(b.Credit)
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+// ListExportRows lists ledger entries in [from, to] for finance export
+func (r *ledgerRepository) ListExportRows(
+	ctx context.Context,
+	from, to time.Time,
+	entryType *string,
+) ([]model.LedgerExportRow, error) {
+	query := `
+		SELECT
+			lt.created_at, lt.id, lt.order_id, o.order_number,
+			lt.entry_type, COALESCE(lt.gateway, ''),
+			le.account, le.direction, le.amount, lt.currency, lt.description
+		FROM ledger_entries le
+		JOIN ledger_transactions lt ON lt.id = le.ledger_transaction_id
+		JOIN orders o ON o.id = lt.order_id
+		WHERE lt.created_at >= $1 AND lt.created_at <= $2
+			AND ($3::text IS NULL OR lt.entry_type = $3)
+		ORDER BY lt.created_at ASC, lt.id, le.direction DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, from, to, entryType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger export rows: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.LedgerExportRow, 0)
+	for rows.Next() {
+		var row model.LedgerExportRow
+		if err := rows.Scan(
+			&row.CreatedAt, &row.LedgerTransactionID, &row.OrderID, &row.OrderNumber,
+			&row.EntryType, &row.Gateway,
+			&row.Account, &row.Direction, &row.Amount, &row.Currency, &row.Description,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger export row: %w", err)
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
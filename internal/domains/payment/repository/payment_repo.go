@@ -13,6 +13,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"bookstore-backend/internal/domains/payment/model"
+	"bookstore-backend/pkg/database"
 )
 
 // =====================================================
@@ -97,6 +98,112 @@ func (r *ppRepository) UpdateStatusWithTx(
 	return nil
 }
 
+// MarkAsSuccessWithTx marks payment as successful within the capture transaction
+// Trigger sync_order_payment_status() vẫn chạy nhưng đơn đã được MarkOrderPaidWithTx cập nhật trước → không đổi gì thêm
+func (r *ppRepository) MarkAsSuccessWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	id uuid.UUID,
+	transactionID string,
+	gatewayResponse map[string]interface{},
+	paymentDetails map[string]interface{},
+) error {
+	query := `
+		UPDATE payment_transactions
+		SET status = 'success',
+			transaction_id = $2,
+			gateway_response = $3,
+			payment_details = $4,
+			completed_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	gatewayResponseJSON, _ := json.Marshal(gatewayResponse)
+	paymentDetailsJSON, _ := json.Marshal(paymentDetails)
+
+	result, err := tx.Exec(ctx, query, id, transactionID, gatewayResponseJSON, paymentDetailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to mark payment as success: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrPaymentNotFound
+	}
+
+	return nil
+}
+
+// MarkOrderPaidWithTx chuyển đơn sang paid + pending → confirmed
+// Điều kiện payment_status <> 'paid' + UPDATE khoá dòng orders: 2 webhook song song chỉ 1 cái chốt đơn
+func (r *ppRepository) MarkOrderPaidWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (*model.PaidOrder, error) {
+	query := `
+		UPDATE orders o
+		SET payment_status = 'paid',
+			paid_at = NOW(),
+			status = CASE WHEN o.status = 'pending' THEN 'confirmed' ELSE o.status END,
+			version = o.version + 1
+		FROM users u
+		WHERE o.id = $1
+			AND u.id = o.user_id
+			AND o.payment_status <> 'paid'
+		RETURNING o.id, o.order_number, o.user_id, o.address_id, o.status,
+			o.total, o.payment_method, u.email, u.locale, o.created_at
+	`
+
+	var order model.PaidOrder
+	err := tx.QueryRow(ctx, query, orderID).Scan(
+		&order.OrderID,
+		&order.OrderNumber,
+		&order.UserID,
+		&order.AddressID,
+		&order.Status,
+		&order.Total,
+		&order.PaymentMethod,
+		&order.UserEmail,
+		&order.UserLocale,
+		&order.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to mark order paid: %w", err)
+	}
+
+	return &order, nil
+}
+
+// ListSaleLinesWithTx dòng hàng của đơn + kho giữ tồn (order_shipments, đơn cũ lấy orders.warehouse_id)
+func (r *ppRepository) ListSaleLinesWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) ([]model.SaleLine, error) {
+	query := `
+		SELECT COALESCE(os.warehouse_id, o.warehouse_id), oi.book_id, oi.quantity
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		LEFT JOIN order_shipments os ON os.id = oi.shipment_id
+		WHERE oi.order_id = $1
+			AND COALESCE(os.warehouse_id, o.warehouse_id) IS NOT NULL
+		ORDER BY oi.created_at ASC
+	`
+
+	rows, err := tx.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sale lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []model.SaleLine
+	for rows.Next() {
+		var line model.SaleLine
+		if err := rows.Scan(&line.WarehouseID, &line.BookID, &line.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan sale line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
 // =====================================================
 // STANDALONE METHODS
 // =====================================================
@@ -881,10 +988,7 @@ func (m *ppRepository) CommitTx(ctx context.Context, tx pgx.Tx) error {
 }
 
 func (m *ppRepository) RollbackTx(ctx context.Context, tx pgx.Tx) error {
-	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-		return fmt.Errorf("failed to rollback transaction: %w", err)
-	}
-	return nil
+	return database.Rollback(ctx, tx)
 }
 
 // MarkExpiredPaymentsAsCancelled bulk update expired payments
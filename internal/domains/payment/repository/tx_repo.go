@@ -1,8 +1,8 @@
 package repository
 
 import (
+	"bookstore-backend/pkg/database"
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
@@ -34,8 +34,5 @@ func (m *postgresTransactionManager) CommitTx(ctx context.Context, tx pgx.Tx) er
 }
 
 func (m *postgresTransactionManager) RollbackTx(ctx context.Context, tx pgx.Tx) error {
-	if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-		return fmt.Errorf("failed to rollback transaction: %w", err)
-	}
-	return nil
+	return database.Rollback(ctx, tx)
 }
@@ -39,9 +39,9 @@ func (r *webhookRepository) Create(
 	query := `
 		INSERT INTO payment_webhook_logs (
 			id, payment_transaction_id, order_id, gateway, webhook_event,
-			headers, body, signature, is_valid, is_processed, received_at
+			headers, body, signature, is_valid, is_processed, processing_error, received_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		)
 	`
 
@@ -67,6 +67,7 @@ func (r *webhookRepository) Create(
 		log.Signature,
 		log.IsValid,
 		log.IsProcessed,
+		log.ProcessingError,
 		log.ReceivedAt,
 	)
 
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/payment/model"
+	repo "bookstore-backend/internal/domains/payment/repository"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// COD REMITTANCE SERVICE INTERFACE
+// =====================================================
+type CODRemittanceInterface interface {
+	// RecordCODCollectionWithTx ghi kiện COD + tiền shipper đã thu khi đơn chuyển sang delivered
+	// Chạy trong transaction cập nhật trạng thái đơn (order domain)
+	RecordCODCollectionWithTx(
+		ctx context.Context,
+		tx pgx.Tx,
+		orderID uuid.UUID,
+		trackingNumber *string,
+		amount decimal.Decimal,
+		collectedBy *uuid.UUID,
+	) error
+
+	// Admin endpoints
+	ImportRemittance(
+		ctx context.Context,
+		adminID uuid.UUID,
+		req model.ImportCODRemittanceRequest,
+		fileName string,
+		file io.Reader,
+	) (*model.CODRemittanceResponse, error)
+	GetRemittance(ctx context.Context, remittanceID uuid.UUID) (*model.CODRemittanceResponse, error)
+	ListShipments(ctx context.Context, req model.ListCODShipmentsRequest) (*model.ListCODShipmentsResponse, error)
+}
+
+// =====================================================
+// COD REMITTANCE SERVICE IMPLEMENTATION
+// =====================================================
+type codRemittanceService struct {
+	codRepo    repo.CODRemittanceRepoInterface
+	ledgerRepo repo.LedgerRepoInterface
+	txManager  repo.TransactionManager
+
+	// true: đơn COD chỉ chuyển paid khi hãng nộp đủ tiền; false: paid ngay khi giao thành công
+	markPaidOnRemittance bool
+}
+
+func NewCODRemittanceService(
+	codRepo repo.CODRemittanceRepoInterface,
+	ledgerRepo repo.LedgerRepoInterface,
+	txManager repo.TransactionManager,
+	markPaidOnRemittance bool,
+) CODRemittanceInterface {
+	return &codRemittanceService{
+		codRepo:              codRepo,
+		ledgerRepo:           ledgerRepo,
+		txManager:            txManager,
+		markPaidOnRemittance: markPaidOnRemittance,
+	}
+}
+
+func (s *codRemittanceService) RecordCODCollectionWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	orderID uuid.UUID,
+	trackingNumber *string,
+	amount decimal.Decimal,
+	collectedBy *uuid.UUID,
+) error {
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	if _, err := s.ledgerRepo.PostWithTx(ctx, tx, model.NewCODCollectionLedger(orderID, amount, collectedBy)); err != nil {
+		return fmt.Errorf("failed to post COD collection ledger: %w", err)
+	}
+
+	now := time.Now()
+	shipment := &model.CODShipment{
+		ID:             uuid.New(),
+		OrderID:        orderID,
+		TrackingNumber: trackingNumber,
+		CODAmount:      amount,
+		RemittedAmount: decimal.Zero,
+		Status:         model.CODShipmentStatusCollected,
+		CollectedAt:    now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.codRepo.CreateShipmentWithTx(ctx, tx, shipment); err != nil {
+		return err
+	}
+
+	if !s.markPaidOnRemittance {
+		return s.codRepo.MarkOrderPaidWithTx(ctx, tx, orderID)
+	}
+	return nil
+}
+
+// ImportRemittance đối soát file nộp tiền COD của hãng vận chuyển
+//
+// Flow (1 transaction):
+// 1. Parse CSV, khoá các kiện khớp mã vận đơn / mã đơn
+// 2. Mỗi dòng: so số nộp với số kiện còn phải nộp → matched / shortfall / overpaid;
+// không tìm thấy kiện → unmatched; kiện đã nộp đủ → duplicate (không cộng tiền)
+// 3. Ghi sổ cái tiền về tài khoản (Nợ cash_in_bank / Có cod_receivable) cho mỗi dòng khớp
+// 4. Kiện nộp đủ + cấu hình paid-on-remittance → đơn chuyển payment_status = paid
+func (s *codRemittanceService) ImportRemittance(
+	ctx context.Context,
+	adminID uuid.UUID,
+	req model.ImportCODRemittanceRequest,
+	fileName string,
+	file io.Reader,
+) (*model.CODRemittanceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, model.NewPaymentError(model.ErrCodeInvalidRemittance, err.Error(), model.ErrInvalidRemittance)
+	}
+
+	lines, err := parseRemittanceCSV(file)
+	if err != nil {
+		return nil, model.NewPaymentError(model.ErrCodeInvalidRemittance, err.Error(), model.ErrInvalidRemittance)
+	}
+
+	now := time.Now()
+	remittance := &model.CODRemittance{
+		ID:          uuid.New(),
+		Carrier:     req.Carrier,
+		Reference:   req.Reference,
+		RemittedAt:  req.RemittedAt,
+		FileName:    fileName,
+		TotalAmount: decimal.Zero,
+		LineCount:   len(lines),
+		ImportedBy:  &adminID,
+		CreatedAt:   now,
+	}
+
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.txManager.RollbackTx(ctx, tx)
+
+	trackingNumbers, orderNumbers := make([]string, 0, len(lines)), make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line.TrackingNumber != nil {
+			trackingNumbers = append(trackingNumbers, *line.TrackingNumber)
+		}
+		if line.OrderNumber != nil {
+			orderNumbers = append(orderNumbers, *line.OrderNumber)
+		}
+	}
+
+	shipments, err := s.codRepo.FindShipmentsForUpdateWithTx(ctx, tx, trackingNumbers, orderNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	byTracking := make(map[string]*model.CODShipment, len(shipments))
+	byOrderNumber := make(map[string]*model.CODShipment, len(shipments))
+	for i := range shipments {
+		if shipments[i].TrackingNumber != nil {
+			byTracking[*shipments[i].TrackingNumber] = &shipments[i]
+		}
+		byOrderNumber[shipments[i].OrderNumber] = &shipments[i]
+	}
+
+	touched := make(map[uuid.UUID]*model.CODShipment)
+	ledgers := make([]*model.LedgerTransaction, 0, len(lines))
+
+	for i := range lines {
+		line := &lines[i]
+		line.RemittanceID = remittance.ID
+		remittance.TotalAmount = remittance.TotalAmount.Add(line.Amount)
+
+		var shipment *model.CODShipment
+		if line.TrackingNumber != nil {
+			shipment = byTracking[*line.TrackingNumber]
+		}
+		if shipment == nil && line.OrderNumber != nil {
+			shipment = byOrderNumber[*line.OrderNumber]
+		}
+
+		if shipment == nil {
+			line.MatchStatus = model.RemittanceMatchUnmatched
+			remittance.UnmatchedCount++
+			continue
+		}
+
+		line.ShipmentID = &shipment.ID
+		line.OrderID = &shipment.OrderID
+
+		expected := shipment.OutstandingAmount()
+		if !expected.IsPositive() {
+			line.MatchStatus = model.RemittanceMatchDuplicate
+			remittance.DuplicateCount++
+			continue
+		}
+
+		difference := line.Amount.Sub(expected)
+		line.ExpectedAmount = &expected
+		line.Difference = &difference
+
+		switch {
+		case difference.IsZero():
+			line.MatchStatus = model.RemittanceMatchMatched
+			remittance.MatchedCount++
+		case difference.IsNegative():
+			line.MatchStatus = model.RemittanceMatchShortfall
+			remittance.ShortfallCount++
+		default:
+			line.MatchStatus = model.RemittanceMatchOverpaid
+			remittance.OverpaidCount++
+		}
+
+		shipment.RemittedAmount = shipment.RemittedAmount.Add(line.Amount)
+		shipment.Status = model.CODShipmentStatusRemitted
+		if shipment.OutstandingAmount().IsPositive() {
+			shipment.Status = model.CODShipmentStatusShortfall
+		}
+		carrier := req.Carrier
+		shipment.Carrier = &carrier
+		shipment.LastRemittanceID = &remittance.ID
+		shipment.ReconciledAt = &now
+		touched[shipment.ID] = shipment
+
+		ledgers = append(ledgers, model.NewCODRemittanceLedger(line, remittance))
+	}
+
+	if err := s.codRepo.CreateRemittanceWithTx(ctx, tx, remittance); err != nil {
+		if errors.Is(err, model.ErrRemittanceExists) {
+			return nil, model.NewPaymentError(
+				model.ErrCodeRemittanceAlreadyImported,
+				fmt.Sprintf("Remittance %s of carrier %s was already imported", req.Reference, req.Carrier),
+				err,
+			)
+		}
+		return nil, err
+	}
+
+	if err := s.codRepo.CreateRemittanceLinesWithTx(ctx, tx, lines); err != nil {
+		return nil, err
+	}
+
+	for _, shipment := range touched {
+		if err := s.codRepo.UpdateShipmentRemittanceWithTx(ctx, tx, shipment); err != nil {
+			return nil, err
+		}
+		if s.markPaidOnRemittance && shipment.Status == model.CODShipmentStatusRemitted {
+			if err := s.codRepo.MarkOrderPaidWithTx(ctx, tx, shipment.OrderID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, ledger := range ledgers {
+		if _, err := s.ledgerRepo.PostWithTx(ctx, tx, ledger); err != nil {
+			return nil, fmt.Errorf("failed to post COD remittance ledger: %w", err)
+		}
+	}
+
+	if err := s.txManager.CommitTx(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &model.CODRemittanceResponse{
+		Remittance: *remittance,
+		Lines:      lines,
+	}, nil
+}
+
+// GetRemittance - kết quả đối soát của 1 file đã import
+func (s *codRemittanceService) GetRemittance(ctx context.Context, remittanceID uuid.UUID) (*model.CODRemittanceResponse, error) {
+	remittance, err := s.codRepo.GetRemittanceByID(ctx, remittanceID)
+	if err != nil {
+		if errors.Is(err, model.ErrRemittanceNotFound) {
+			return nil, model.NewPaymentError(model.ErrCodeRemittanceNotFound, "COD remittance not found", err)
+		}
+		return nil, err
+	}
+
+	lines, err := s.codRepo.ListRemittanceLines(ctx, remittanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CODRemittanceResponse{
+		Remittance: *remittance,
+		Lines:      lines,
+	}, nil
+}
+
+// ListShipments - kiện COD theo trạng thái (vd: status=shortfall để xem kiện hãng nộp thiếu)
+func (s *codRemittanceService) ListShipments(ctx context.Context, req model.ListCODShipmentsRequest) (*model.ListCODShipmentsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, model.NewPaymentError(model.ErrCodeInvalidRemittance, err.Error(), err)
+	}
+
+	shipments, total, outstanding, err := s.codRepo.ListShipments(ctx, req.Status, req.Carrier, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListCODShipmentsResponse{
+		Shipments:         shipments,
+		OutstandingAmount: outstanding,
+		Pagination: model.PaginationMeta{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			Total:      total,
+			TotalPages: (total + req.Limit - 1) / req.Limit,
+		},
+	}, nil
+}
+
+// parseRemittanceCSV đọc file đối soát: header bắt buộc, cột amount + tracking_number và/hoặc order_number
+// Số tiền chấp nhận dấu phẩy phân cách hàng nghìn (150,000)
+func parseRemittanceCSV(file io.Reader) ([]model.CODRemittanceLine, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file is empty")
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+
+	amountCol, hasAmount := columns["amount"]
+	trackingCol, hasTracking := columns["tracking_number"]
+	orderCol, hasOrder := columns["order_number"]
+	if !hasAmount || (!hasTracking && !hasOrder) {
+		return nil, fmt.Errorf("header must contain amount and tracking_number or order_number")
+	}
+
+	field := func(record []string, col int, ok bool) *string {
+		if !ok || col >= len(record) {
+			return nil
+		}
+		value := strings.TrimSpace(record[col])
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+
+	now := time.Now()
+	lines := make([]model.CODRemittanceLine, 0)
+	for lineNo := 1; ; lineNo++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if len(lines) >= model.CODRemittanceMaxLines {
+			return nil, fmt.Errorf("file exceeds %d lines", model.CODRemittanceMaxLines)
+		}
+
+		trackingNumber := field(record, trackingCol, hasTracking)
+		orderNumber := field(record, orderCol, hasOrder)
+		if trackingNumber == nil && orderNumber == nil {
+			return nil, fmt.Errorf("line %d: tracking_number or order_number is required", lineNo)
+		}
+
+		rawAmount := field(record, amountCol, true)
+		if rawAmount == nil {
+			return nil, fmt.Errorf("line %d: amount is required", lineNo)
+		}
+		amount, err := decimal.NewFromString(strings.ReplaceAll(*rawAmount, ",", ""))
+		if err != nil || !amount.IsPositive() {
+			return nil, fmt.Errorf("line %d: invalid amount %q", lineNo, *rawAmount)
+		}
+
+		lines = append(lines, model.CODRemittanceLine{
+			ID:             uuid.New(),
+			LineNo:         lineNo,
+			TrackingNumber: trackingNumber,
+			OrderNumber:    orderNumber,
+			Amount:         amount,
+			CreatedAt:      now,
+		})
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file has no remittance lines")
+	}
+
+	return lines, nil
+}
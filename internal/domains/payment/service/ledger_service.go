@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/payment/model"
+	repo "bookstore-backend/internal/domains/payment/repository"
+)
+
+// =====================================================
+// LEDGER SERVICE INTERFACE
+// =====================================================
+type LedgerInterface interface {
+	// Admin endpoints
+	GetOrderLedger(ctx context.Context, orderID uuid.UUID) (*model.OrderLedgerResponse, error)
+	GetLedgerSummary(ctx context.Context, req model.LedgerQueryRequest) (*model.LedgerSummaryResponse, error)
+	ExportLedgerCSV(ctx context.Context, req model.LedgerQueryRequest, w io.Writer) error
+}
+
+// =====================================================
+// LEDGER SERVICE IMPLEMENTATION
+// =====================================================
+type ledgerService struct {
+	ledgerRepo repo.LedgerRepoInterface
+}
+
+func NewLedgerService(ledgerRepo repo.LedgerRepoInterface) LedgerInterface {
+	return &ledgerService{ledgerRepo: ledgerRepo}
+}
+
+// GetOrderLedger - dòng tiền của 1 đơn: đã thu, đã hoàn, còn lại
+func (s *ledgerService) GetOrderLedger(ctx context.Context, orderID uuid.UUID) (*model.OrderLedgerResponse, error) {
+	transactions, err := s.ledgerRepo.ListByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.OrderLedgerResponse{
+		OrderID:       orderID,
+		Transactions:  transactions,
+		TotalReceived: decimal.Zero,
+		TotalRefunded: decimal.Zero,
+	}
+
+	for _, t := range transactions {
+		switch t.EntryType {
+		case model.LedgerTypeCapture, model.LedgerTypeCODCollection, model.LedgerTypeGiftCardRedemption:
+			response.TotalReceived = response.TotalReceived.Add(t.Amount)
+		case model.LedgerTypeRefund:
+			response.TotalRefunded = response.TotalRefunded.Add(t.Amount)
+		}
+	}
+	response.NetAmount = response.TotalReceived.Sub(response.TotalRefunded)
+
+	return response, nil
+}
+
+// GetLedgerSummary - tổng Nợ/Có theo tài khoản (trial balance) trong khoảng thời gian
+func (s *ledgerService) GetLedgerSummary(ctx context.Context, req model.LedgerQueryRequest) (*model.LedgerSummaryResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, model.NewPaymentError(model.ErrCodeInvalidLedgerQuery, err.Error(), err)
+	}
+
+	balances, err := s.ledgerRepo.GetAccountBalances(ctx, *req.FromDate, *req.ToDate, req.EntryType)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.LedgerSummaryResponse{
+		FromDate:    *req.FromDate,
+		ToDate:      *req.ToDate,
+		Accounts:    balances,
+		TotalDebit:  decimal.Zero,
+		TotalCredit: decimal.Zero,
+	}
+	for _, b := range balances {
+		response.TotalDebit = response.TotalDebit.Add(b.Debit)
+		response.TotalCredit = response.TotalCredit.Add(b.Credit)
+	}
+
+	return response, nil
+}
+
+// ExportLedgerCSV ghi toàn bộ bút toán trong khoảng thời gian ra CSV (1 dòng / bút toán)
+func (s *ledgerService) ExportLedgerCSV(ctx context.Context, req model.LedgerQueryRequest, w io.Writer) error {
+	if err := req.Validate(); err != nil {
+		return model.NewPaymentError(model.ErrCodeInvalidLedgerQuery, err.Error(), err)
+	}
+
+	rows, err := s.ledgerRepo.ListExportRows(ctx, *req.FromDate, *req.ToDate, req.EntryType)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{
+		"created_at", "ledger_transaction_id", "order_id", "order_number",
+		"entry_type", "gateway", "account", "debit", "credit", "currency", "description",
+	}); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, row := range rows {
+		debit, credit := "", ""
+		if row.Direction == model.LedgerDirectionDebit {
+			debit = row.Amount.StringFixed(2)
+		} else {
+			credit = row.Amount.StringFixed(2)
+		}
+
+		if err := writer.Write([]string{
+			row.CreatedAt.Format(time.RFC3339),
+			row.LedgerTransactionID.String(),
+			row.OrderID.String(),
+			row.OrderNumber,
+			row.EntryType,
+			row.Gateway,
+			row.Account,
+			debit,
+			credit,
+			row.Currency,
+			row.Description,
+		}); err != nil {
+			return fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/payment/model"
+)
+
+func TestVerifyVNPayAmount(t *testing.T) {
+	payment := &model.PaymentTransaction{Amount: decimal.NewFromInt(150000)}
+
+	cases := []struct {
+		name      string
+		vnpAmount string
+		wantErr   bool
+	}{
+		{"matching amount", "15000000", false},
+		{"lower amount", "100", true},
+		{"higher amount", "15000100", true},
+		{"malformed amount", "abc", true},
+		{"missing amount", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyVNPayAmount(payment, model.VNPayWebhookRequest{VnpAmount: tc.vnpAmount})
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("verifyVNPayAmount(%q) error = %v, wantErr %v", tc.vnpAmount, err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, model.ErrAmountMismatch) {
+				t.Fatalf("error %v is not ErrAmountMismatch", err)
+			}
+		})
+	}
+}
+
+func TestVerifyPaidAmountMomo(t *testing.T) {
+	payment := &model.PaymentTransaction{Amount: decimal.RequireFromString("150000.00")}
+
+	if err := verifyPaidAmount(payment, decimal.NewFromInt(150000)); err != nil {
+		t.Fatalf("matching amount rejected: %v", err)
+	}
+	if err := verifyPaidAmount(payment, decimal.NewFromInt(1000)); !errors.Is(err, model.ErrAmountMismatch) {
+		t.Fatalf("mismatched amount: error = %v, want ErrAmountMismatch", err)
+	}
+}
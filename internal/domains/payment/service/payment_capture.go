@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+
+	cartModel "bookstore-backend/internal/domains/cart/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/domains/payment/model"
+	shippingModel "bookstore-backend/internal/domains/shipping/model"
+	"bookstore-backend/internal/infrastructure/outbox"
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/database"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// PAYMENT CAPTURE (VNPay / Momo / admin reconcile)
+// =====================================================
+
+// InventorySaleCompleter chốt bán tồn đã reserve khi đơn được thanh toán (inventory repository)
+type InventorySaleCompleter interface {
+	CompleteSaleWithTx(ctx context.Context, tx pgx.Tx, warehouseID, bookID uuid.UUID, quantity int, userID *uuid.UUID) error
+}
+
+// paymentCapture dữ liệu gateway báo thanh toán thành công
+type paymentCapture struct {
+	TransactionID   string
+	GatewayResponse map[string]interface{}
+	PaymentDetails  map[string]interface{}
+}
+
+// capturePayment ghi nhận thanh toán thành công trong 1 transaction rồi gửi email xác nhận
+func (s *paymentService) capturePayment(
+	ctx context.Context,
+	payment *model.PaymentTransaction,
+	capture paymentCapture,
+) error {
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	txCtx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
+	tx, err := s.txManager.BeginTx(txCtx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.txManager.RollbackTx(txCtx, tx)
+
+	paidOrder, err := s.capturePaymentWithTx(txCtx, tx, payment, capture, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := s.txManager.CommitTx(txCtx, tx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.afterCapture(ctx, payment, paidOrder)
+	return nil
+}
+
+// capturePaymentWithTx - cùng 1 transaction:
+//  1. orders: payment_status = paid, pending → confirmed (khoá dòng đơn)
+//  2. payment_transactions: success
+//  3. complete_sale cho từng dòng tại kho đang giữ tồn (bỏ qua nếu đơn đã paid trước đó / đã huỷ)
+//  4. Ghi sổ cái capture (idempotency key chặn ghi trùng)
+//  5. Đơn vừa được xác nhận → job tạo vận đơn (outbox)
+//
+// Lỗi bất kỳ bước nào → rollback toàn bộ, webhook lỗi → gateway / job RetryFailedWebhooks gửi lại
+// Trả đơn vừa chuyển paid (nil nếu đơn đã paid trước đó)
+func (s *paymentService) capturePaymentWithTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	payment *model.PaymentTransaction,
+	capture paymentCapture,
+	actorID *uuid.UUID,
+) (*model.PaidOrder, error) {
+	paidOrder, err := s.paymentRepo.MarkOrderPaidWithTx(ctx, tx, payment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.paymentRepo.MarkAsSuccessWithTx(
+		ctx,
+		tx,
+		payment.ID,
+		capture.TransactionID,
+		capture.GatewayResponse,
+		capture.PaymentDetails,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark payment as success: %w", err)
+	}
+
+	// Đơn đã huỷ thì tồn reserve đã được nhả khi huỷ → không chốt bán
+	if paidOrder != nil && paidOrder.Status != orderModel.OrderStatusCancelled {
+		if err := s.completeSaleWithTx(ctx, tx, paidOrder); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ghi sổ cái: webhook retry / ReturnURL + IPN cùng về → idempotency key chặn ghi trùng
+	if _, err := s.ledgerRepo.PostWithTx(ctx, tx, model.NewCaptureLedger(payment, actorID)); err != nil {
+		return nil, fmt.Errorf("failed to post capture ledger: %w", err)
+	}
+
+	if paidOrder != nil && paidOrder.Status == orderModel.OrderStatusConfirmed {
+		message, err := outbox.NewMessage(shared.TypeCreateOrderShipment, shippingModel.CreateOrderShipmentPayload{
+			OrderID: paidOrder.OrderID,
+		}, shared.QueueOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build create shipment message: %w", err)
+		}
+		message = message.WithMaxRetry(5).WithDedupKey("create_order_shipment:" + paidOrder.OrderID.String())
+		if err := outbox.AddWithTx(ctx, tx, message); err != nil {
+			return nil, err
+		}
+	}
+
+	return paidOrder, nil
+}
+
+// completeSaleWithTx giảm quantity + reserved của từng dòng hàng tại kho đã reserve
+func (s *paymentService) completeSaleWithTx(ctx context.Context, tx pgx.Tx, order *model.PaidOrder) error {
+	lines, err := s.paymentRepo.ListSaleLinesWithTx(ctx, tx, order.OrderID)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if err := s.inventory.CompleteSaleWithTx(ctx, tx, line.WarehouseID, line.BookID, line.Quantity, &order.UserID); err != nil {
+			return fmt.Errorf("failed to complete sale for book %s: %w", line.BookID, err)
+		}
+	}
+	return nil
+}
+
+// afterCapture việc sau commit: cảnh báo đơn đã huỷ nhưng tiền đã về, gửi email xác nhận đơn
+// Chỉ chạy 1 lần / đơn: MarkOrderPaidWithTx trả nil cho webhook lặp
+func (s *paymentService) afterCapture(ctx context.Context, payment *model.PaymentTransaction, paidOrder *model.PaidOrder) {
+	if paidOrder == nil {
+		// Đơn đã paid từ webhook trước → email đã gửi
+		return
+	}
+
+	if paidOrder.Status == orderModel.OrderStatusCancelled {
+		// Order was cancelled but payment succeeded → cần hoàn tiền thủ công
+		logger.Info("WARNING: payment succeeded but order is cancelled, manual refund needed", map[string]interface{}{
+			"payment_id": payment.ID,
+			"order_id":   payment.OrderID,
+		})
+		return
+	}
+
+	if paidOrder.UserEmail == "" {
+		return
+	}
+	s.enqueueSendOrderConfirmation(ctx, paidOrder)
+}
+
+// enqueueSendOrderConfirmation email xác nhận đơn thanh toán online
+// (đơn COD đã gửi lúc checkout, đơn online chỉ gửi khi tiền về)
+func (s *paymentService) enqueueSendOrderConfirmation(ctx context.Context, order *model.PaidOrder) {
+	if s.asynqClient == nil {
+		return
+	}
+
+	payload := cartModel.SendOrderConfirmationPayload{
+		OrderID:                  order.OrderID,
+		OrderNumber:              order.OrderNumber,
+		UserID:                   order.UserID,
+		UserEmail:                order.UserEmail,
+		Total:                    order.Total,
+		PaymentMethod:            order.PaymentMethod,
+		ShippingAddressID:        order.AddressID,
+		OrderCreatedAt:           order.CreatedAt.Format(time.RFC3339),
+		EstimatedDeliveryMinDays: 3,
+		EstimatedDeliveryMaxDays: 5,
+		Locale:                   order.UserLocale,
+	}
+
+	task, err := utils.MarshalTaskContext(ctx, shared.TypeSendOrderConfirmation, payload)
+	if err != nil {
+		logger.Info("Failed to marshal send email task", map[string]interface{}{
+			"order_id": order.OrderID,
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	_, err = s.asynqClient.Enqueue(task,
+		asynq.Queue(shared.QueueOrder),
+		asynq.MaxRetry(2),
+		asynq.Timeout(30*time.Second),
+	)
+	if err != nil {
+		logger.Info("Failed to enqueue send email task", map[string]interface{}{
+			"order_id": order.OrderID,
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	logger.Info("Enqueued send order confirmation email", map[string]interface{}{
+		"order_id": order.OrderID,
+		"email":    order.UserEmail,
+	})
+}
+
+// markPaymentFailed ghi nhận thanh toán thất bại
+// Trigger sync_order_payment_status() cập nhật orders.payment_status = 'failed'
+func (s *paymentService) markPaymentFailed(
+	ctx context.Context,
+	payment *model.PaymentTransaction,
+	internalCode, errorMessage string,
+) error {
+	if err := s.paymentRepo.MarkAsFailed(ctx, payment.ID, internalCode, errorMessage); err != nil {
+		return fmt.Errorf("failed to mark payment as failed: %w", err)
+	}
+	return nil
+}
@@ -7,12 +7,15 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/shopspring/decimal"
 
 	orderModel "bookstore-backend/internal/domains/order/model"
 	os "bookstore-backend/internal/domains/order/service"
 	"bookstore-backend/internal/domains/payment/gateway"
 	"bookstore-backend/internal/domains/payment/model"
 	repo "bookstore-backend/internal/domains/payment/repository"
+	"bookstore-backend/pkg/database"
 	"bookstore-backend/pkg/logger"
 )
 
@@ -23,6 +26,7 @@ type paymentService struct {
 	paymentRepo repo.PaymentRepoInteface
 	webhookRepo repo.WebhookRepoInterface
 	refundRepo  repo.RefundRepoInterface
+	ledgerRepo  repo.LedgerRepoInterface
 	txManager   repo.TransactionManager
 
 	// Gateway integrations
@@ -31,25 +35,35 @@ type paymentService struct {
 
 	// Order service (for cross-domain operations)
 	orderService os.OrderService
+
+	// Chốt bán tồn kho + gửi email xác nhận khi tiền về
+	inventory   InventorySaleCompleter
+	asynqClient *asynq.Client
 }
 
 func NewPaymentService(
 	paymentRepo repo.PaymentRepoInteface,
 	webhookRepo repo.WebhookRepoInterface,
 	refundRepo repo.RefundRepoInterface,
+	ledgerRepo repo.LedgerRepoInterface,
 	txManager repo.TransactionManager,
 	vnpayGateway gateway.VNPayGateway,
 	momoGateway gateway.MomoGateway,
 	orderService os.OrderService,
+	inventory InventorySaleCompleter,
+	asynqClient *asynq.Client,
 ) PaymentService {
 	return &paymentService{
 		paymentRepo:  paymentRepo,
 		webhookRepo:  webhookRepo,
 		refundRepo:   refundRepo,
+		ledgerRepo:   ledgerRepo,
 		txManager:    txManager,
 		vnpayGateway: vnpayGateway,
 		momoGateway:  momoGateway,
 		orderService: orderService,
+		inventory:    inventory,
+		asynqClient:  asynqClient,
 	}
 }
 
@@ -425,6 +439,11 @@ func (s *paymentService) ProcessVNPayWebhook(
 	webhookLog.PaymentTransactionID = &payment.ID
 	webhookLog.OrderID = &payment.OrderID
 
+	// Step 4.1: vnp_Amount phải khớp payment.Amount (spec IPN: sai → RspCode 04)
+	if err := verifyVNPayAmount(payment, webhookData); err != nil {
+		return s.rejectWebhookAmount(ctx, webhookLog, err)
+	}
+
 	// Create webhook log
 	if err := s.webhookRepo.Create(ctx, webhookLog); err != nil {
 		return fmt.Errorf("failed to create webhook log: %w", err)
@@ -453,74 +472,65 @@ func (s *paymentService) ProcessVNPayWebhook(
 	return nil
 }
 
-// handleSuccessfulPayment handles successful payment webhook
-func (s *paymentService) handleSuccessfulPayment(
-	ctx context.Context,
-	payment *model.PaymentTransaction,
-	webhookData model.VNPayWebhookRequest,
-) error {
-	// Start transaction for atomic update
-	tx, err := s.txManager.BeginTx(ctx)
+// verifyVNPayAmount so vnp_Amount (VND x 100) với payment.Amount
+func verifyVNPayAmount(payment *model.PaymentTransaction, webhookData model.VNPayWebhookRequest) error {
+	paid, err := webhookData.PaidAmount()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer s.txManager.RollbackTx(ctx, tx)
-
-	// Build payment details from webhook
-	paymentDetails := map[string]interface{}{
-		"bank_code": webhookData.VnpBankCode,
-		"card_type": webhookData.VnpCardType,
-		"pay_date":  webhookData.VnpPayDate,
+		return model.NewAmountMismatchError(payment.Amount.String(), webhookData.VnpAmount)
 	}
+	return verifyPaidAmount(payment, paid)
+}
 
-	gatewayResponse := map[string]interface{}{
-		"vnp_Amount":            webhookData.VnpAmount,
-		"vnp_BankCode":          webhookData.VnpBankCode,
-		"vnp_CardType":          webhookData.VnpCardType,
-		"vnp_OrderInfo":         webhookData.VnpOrderInfo,
-		"vnp_PayDate":           webhookData.VnpPayDate,
-		"vnp_ResponseCode":      webhookData.VnpResponseCode,
-		"vnp_TransactionNo":     webhookData.VnpTransactionNo,
-		"vnp_TransactionStatus": webhookData.VnpTransactionStatus,
-	}
-
-	// Update payment to success
-	err = s.paymentRepo.MarkAsSuccess(
-		ctx,
-		payment.ID,
-		webhookData.VnpTransactionNo,
-		gatewayResponse,
-		paymentDetails,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to mark payment as success: %w", err)
+// verifyPaidAmount số tiền gateway thực thu phải bằng payment.Amount,
+// nếu không ledger sẽ ghi payment.Amount khác với số tiền thực tế
+func verifyPaidAmount(payment *model.PaymentTransaction, paid decimal.Decimal) error {
+	if !paid.Equal(payment.Amount) {
+		return model.NewAmountMismatchError(payment.Amount.String(), paid.String())
 	}
+	return nil
+}
 
-	// Commit transaction
-	if err := s.txManager.CommitTx(ctx, tx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
+// rejectWebhookAmount ghi webhook log is_valid=false (không retry) rồi trả lỗi sai số tiền
+func (s *paymentService) rejectWebhookAmount(
+	ctx context.Context,
+	webhookLog *model.PaymentWebhookLog,
+	err error,
+) error {
+	logger.Error("Payment webhook amount mismatch", err)
 
-	// Note: Trigger sync_order_payment_status() will automatically:
-	// - Update orders.payment_status = 'paid'
-	// - Update orders.paid_at = NOW()
-	// - Update orders.status = 'confirmed' (if currently 'pending')
+	webhookLog.MarkAsInvalid(err.Error())
+	s.webhookRepo.Create(ctx, webhookLog)
 
-	// Edge case: Check if order was cancelled after payment initiated
-	order, err := s.orderService.GetOrderByIDWithoutUser(ctx, payment.OrderID)
-	if err == nil && order.Status == "cancelled" {
-		// Order was cancelled but payment succeeded
-		// Need to initiate auto-refund
-		// TODO: Alert admin for manual reconciliation
-		// For now, just log the issue
-		fmt.Printf("WARNING: Payment %s succeeded but order %s is cancelled. Manual refund needed.\n",
-			payment.ID, payment.OrderID)
-	}
+	return err
+}
 
-	return nil
+// handleSuccessfulPayment handles successful VNPay payment (IPN + ReturnURL)
+func (s *paymentService) handleSuccessfulPayment(
+	ctx context.Context,
+	payment *model.PaymentTransaction,
+	webhookData model.VNPayWebhookRequest,
+) error {
+	return s.capturePayment(ctx, payment, paymentCapture{
+		TransactionID: webhookData.VnpTransactionNo,
+		GatewayResponse: map[string]interface{}{
+			"vnp_Amount":            webhookData.VnpAmount,
+			"vnp_BankCode":          webhookData.VnpBankCode,
+			"vnp_CardType":          webhookData.VnpCardType,
+			"vnp_OrderInfo":         webhookData.VnpOrderInfo,
+			"vnp_PayDate":           webhookData.VnpPayDate,
+			"vnp_ResponseCode":      webhookData.VnpResponseCode,
+			"vnp_TransactionNo":     webhookData.VnpTransactionNo,
+			"vnp_TransactionStatus": webhookData.VnpTransactionStatus,
+		},
+		PaymentDetails: map[string]interface{}{
+			"bank_code": webhookData.VnpBankCode,
+			"card_type": webhookData.VnpCardType,
+			"pay_date":  webhookData.VnpPayDate,
+		},
+	})
 }
 
-// handleFailedPayment handles failed payment webhook
+// handleFailedPayment handles failed VNPay payment
 func (s *paymentService) handleFailedPayment(
 	ctx context.Context,
 	payment *model.PaymentTransaction,
@@ -528,17 +538,7 @@ func (s *paymentService) handleFailedPayment(
 ) error {
 	// Map VNPay error code to internal error code
 	internalCode, errorMessage := model.MapVNPayErrorCode(webhookData.VnpResponseCode)
-
-	// Update payment to failed
-	err := s.paymentRepo.MarkAsFailed(ctx, payment.ID, internalCode, errorMessage)
-	if err != nil {
-		return fmt.Errorf("failed to mark payment as failed: %w", err)
-	}
-
-	// Note: Trigger sync_order_payment_status() will automatically:
-	// - Update orders.payment_status = 'failed'
-
-	return nil
+	return s.markPaymentFailed(ctx, payment, internalCode, errorMessage)
 }
 
 // =====================================================
@@ -598,7 +598,19 @@ func (s *paymentService) VerifyVNPayReturn(
 		}, nil
 	}
 
-	// Step 4: Process based on response code
+	// Step 4: vnp_Amount phải khớp payment.Amount
+	if err := verifyVNPayAmount(payment, webhookData); err != nil {
+		logger.Error("VNPay return amount mismatch", err)
+		return &model.VerifyPaymentResponse{
+			Success:      false,
+			PaymentID:    payment.ID,
+			OrderID:      payment.OrderID,
+			Message:      "Số tiền không hợp lệ",
+			ResponseCode: "04",
+		}, nil
+	}
+
+	// Step 5: Process based on response code
 	if webhookData.VnpResponseCode == "00" {
 		// Payment success - update database
 		err = s.handleSuccessfulPayment(ctx, payment, webhookData)
@@ -649,19 +661,130 @@ func (s *paymentService) VerifyVNPayReturn(
 // =====================================================
 
 // ProcessMomoWebhook processes Momo IPN callback
-// Similar logic to VNPay but with Momo-specific data structure
+// Cùng luồng với VNPay, khác:
+// - Signature: HMAC-SHA256 trên raw string các field theo alphabet
+// - resultCode: 0 = success, còn lại = failed
+// - orderId = payment_transaction.id, transId = mã giao dịch Momo
 func (s *paymentService) ProcessMomoWebhook(
 	ctx context.Context,
 	webhookData model.MomoWebhookRequest,
 ) error {
-	// Similar implementation to VNPay
-	// TODO: Implement Momo webhook processing
-	// Key differences:
-	// - Signature algorithm: HMAC-SHA256 (not SHA512)
-	// - Response codes: 0=success, others=fail
-	// - Data structure different from VNPay
-
-	return fmt.Errorf("Momo webhook not implemented yet")
+	// Step 1: Create webhook log (audit trail)
+	webhookID := uuid.New()
+	transID := webhookData.TransID
+	event := model.WebhookEventPaymentSuccess
+	if webhookData.ResultCode != model.MomoResultCodeSuccess {
+		event = model.WebhookEventPaymentFailed
+	}
+	webhookLog := &model.PaymentWebhookLog{
+		ID:           webhookID,
+		Gateway:      model.GatewayMomo,
+		WebhookEvent: &event,
+		Body: map[string]interface{}{
+			"partnerCode":    webhookData.PartnerCode,
+			"orderId":        webhookData.OrderID,
+			"requestId":      webhookData.RequestID,
+			"amount":         webhookData.Amount,
+			"orderInfo":      webhookData.OrderInfo,
+			"orderType":      webhookData.OrderType,
+			"transId":        transID,
+			"resultCode":     webhookData.ResultCode,
+			"message":        webhookData.Message,
+			"payType":        webhookData.PayType,
+			"responseTime":   webhookData.ResponseTime,
+			"extraData":      webhookData.ExtraData,
+			"transaction_id": transID, // For idempotency check
+		},
+		Signature:  &webhookData.Signature,
+		ReceivedAt: time.Now(),
+	}
+
+	// Step 2: Verify signature
+	if !s.momoGateway.VerifySignature(webhookData) {
+		// Invalid signature - potential fraud
+		isValidFlag := false
+		webhookLog.IsValid = &isValidFlag
+		s.webhookRepo.Create(ctx, webhookLog)
+
+		return model.NewInvalidSignatureError()
+	}
+
+	isValidFlag := true
+	webhookLog.IsValid = &isValidFlag
+
+	// Step 3: Check idempotency
+	alreadyProcessed, err := s.webhookRepo.CheckIdempotency(ctx, model.GatewayMomo, event, transID)
+	if err != nil {
+		s.webhookRepo.Create(ctx, webhookLog)
+		return fmt.Errorf("failed to check idempotency: %w", err)
+	}
+
+	if alreadyProcessed {
+		webhookLog.IsProcessed = true
+		s.webhookRepo.Create(ctx, webhookLog)
+		return nil
+	}
+
+	// Step 4: Get payment transaction by orderId (payment_transaction.id)
+	paymentID, err := uuid.Parse(webhookData.OrderID)
+	if err != nil {
+		s.webhookRepo.Create(ctx, webhookLog)
+		return fmt.Errorf("invalid transaction ref: %w", err)
+	}
+
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		s.webhookRepo.Create(ctx, webhookLog)
+		return fmt.Errorf("payment not found: %w", err)
+	}
+
+	webhookLog.PaymentTransactionID = &payment.ID
+	webhookLog.OrderID = &payment.OrderID
+
+	// Step 4.1: amount phải khớp payment.Amount
+	if err := verifyPaidAmount(payment, decimal.NewFromInt(webhookData.Amount)); err != nil {
+		return s.rejectWebhookAmount(ctx, webhookLog, err)
+	}
+
+	if err := s.webhookRepo.Create(ctx, webhookLog); err != nil {
+		return fmt.Errorf("failed to create webhook log: %w", err)
+	}
+
+	// Step 5: Process based on result code
+	if webhookData.ResultCode == model.MomoResultCodeSuccess {
+		err = s.capturePayment(ctx, payment, paymentCapture{
+			TransactionID: transID,
+			GatewayResponse: map[string]interface{}{
+				"partnerCode":  webhookData.PartnerCode,
+				"requestId":    webhookData.RequestID,
+				"amount":       webhookData.Amount,
+				"transId":      transID,
+				"resultCode":   webhookData.ResultCode,
+				"message":      webhookData.Message,
+				"payType":      webhookData.PayType,
+				"responseTime": webhookData.ResponseTime,
+			},
+			PaymentDetails: map[string]interface{}{
+				"pay_type":      webhookData.PayType,
+				"response_time": webhookData.ResponseTime,
+			},
+		})
+	} else {
+		internalCode, errorMessage := model.MapMomoErrorCode(webhookData.ResultCode)
+		err = s.markPaymentFailed(ctx, payment, internalCode, errorMessage)
+	}
+
+	if err != nil {
+		s.webhookRepo.MarkProcessingError(ctx, webhookID, err.Error())
+		return err
+	}
+
+	// Step 6: Mark webhook as processed
+	if err := s.webhookRepo.MarkAsProcessed(ctx, webhookID); err != nil {
+		return fmt.Errorf("failed to mark webhook as processed: %w", err)
+	}
+
+	return nil
 }
 
 // =====================================================
@@ -747,8 +870,26 @@ func (s *paymentService) RetryFailedWebhooks(ctx context.Context) (int, error) {
 			retryErr = s.ProcessVNPayWebhook(ctx, webhookData)
 
 		case model.GatewayMomo:
-			// TODO: Retry Momo webhook
-			continue
+			// Body lưu dạng JSONB → số về lại float64
+			webhookData := model.MomoWebhookRequest{
+				PartnerCode:  webhookBodyString(webhook.Body, "partnerCode"),
+				OrderID:      webhookBodyString(webhook.Body, "orderId"),
+				RequestID:    webhookBodyString(webhook.Body, "requestId"),
+				Amount:       webhookBodyInt64(webhook.Body, "amount"),
+				OrderInfo:    webhookBodyString(webhook.Body, "orderInfo"),
+				OrderType:    webhookBodyString(webhook.Body, "orderType"),
+				TransID:      webhookBodyString(webhook.Body, "transId"),
+				ResultCode:   int(webhookBodyInt64(webhook.Body, "resultCode")),
+				Message:      webhookBodyString(webhook.Body, "message"),
+				PayType:      webhookBodyString(webhook.Body, "payType"),
+				ResponseTime: webhookBodyInt64(webhook.Body, "responseTime"),
+				ExtraData:    webhookBodyString(webhook.Body, "extraData"),
+			}
+			if webhook.Signature != nil {
+				webhookData.Signature = *webhook.Signature
+			}
+
+			retryErr = s.ProcessMomoWebhook(ctx, webhookData)
 		}
 
 		if retryErr != nil {
@@ -763,6 +904,27 @@ func (s *paymentService) RetryFailedWebhooks(ctx context.Context) (int, error) {
 	return retriedCount, nil
 }
 
+// webhookBodyString đọc field chuỗi từ body webhook đã lưu
+func webhookBodyString(body map[string]interface{}, key string) string {
+	if v, ok := body[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// webhookBodyInt64 đọc field số từ body webhook đã lưu (JSONB decode thành float64)
+func webhookBodyInt64(body map[string]interface{}, key string) int64 {
+	switch v := body[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	}
+	return 0
+}
+
 // =====================================================
 // ADMIN METHODS (TODO: Implement in next part)
 // =====================================================
@@ -943,12 +1105,16 @@ func (s *paymentService) AdminReconcilePayment(
 	}
 
 	// Step 2: Get payment
-	_, err := s.paymentRepo.GetByID(ctx, paymentID)
+	payment, err := s.paymentRepo.GetByID(ctx, paymentID)
 	if err != nil {
 		return err
 	}
 
 	// Step 3: Start transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.txManager.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -956,6 +1122,7 @@ func (s *paymentService) AdminReconcilePayment(
 	defer s.txManager.RollbackTx(ctx, tx)
 
 	// Step 4: Update payment based on admin's verification
+	var paidOrder *model.PaidOrder
 	if req.Status == model.PaymentStatusSuccess {
 		// Admin verified payment succeeded
 		gatewayResponse := map[string]interface{}{
@@ -968,20 +1135,15 @@ func (s *paymentService) AdminReconcilePayment(
 			"gateway_transaction_id": req.GatewayTransactionID,
 		}
 
-		err = s.paymentRepo.MarkAsSuccess(
-			ctx,
-			paymentID,
-			req.GatewayTransactionID,
-			gatewayResponse,
-			paymentDetails,
-		)
-
+		paidOrder, err = s.capturePaymentWithTx(ctx, tx, payment, paymentCapture{
+			TransactionID:   req.GatewayTransactionID,
+			GatewayResponse: gatewayResponse,
+			PaymentDetails:  paymentDetails,
+		}, &adminID)
 		if err != nil {
-			return fmt.Errorf("failed to mark payment as success: %w", err)
+			return err
 		}
 
-		// Trigger will sync order status automatically
-
 	} else if req.Status == model.PaymentStatusFailed {
 		// Admin verified payment failed
 		err = s.paymentRepo.MarkAsFailed(
@@ -1001,6 +1163,8 @@ func (s *paymentService) AdminReconcilePayment(
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.afterCapture(ctx, payment, paidOrder)
+
 	// TODO: Create admin audit log entry
 	fmt.Printf("Admin %s reconciled payment %s: status=%s, notes=%s\n",
 		adminID, paymentID, req.Status, req.Notes)
@@ -5,6 +5,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type RefundInterface interface {
@@ -17,4 +18,7 @@ type RefundInterface interface {
 	GetRefundDetail(ctx context.Context, refundID uuid.UUID) (*model.RefundRequest, map[string]interface{}, error)
 	ApproveRefund(ctx context.Context, adminID uuid.UUID, refundID uuid.UUID, req model.ApproveRefundRequestDTO) (*model.RefundRequestResponse, error)
 	RejectRefund(ctx context.Context, adminID uuid.UUID, refundID uuid.UUID, req model.RejectRefundRequestDTO) error
+
+	// CreateCompensationRefund staff tạo yêu cầu hoàn (một phần) cho đơn thay khách - bồi thường khiếu nại
+	CreateCompensationRefund(ctx context.Context, staffID uuid.UUID, orderID uuid.UUID, amount decimal.Decimal, reason string) (*model.RefundRequestResponse, error)
 }
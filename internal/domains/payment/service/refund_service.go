@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 
 	os "bookstore-backend/internal/domains/order/service"
 	"bookstore-backend/internal/domains/payment/gateway"
 	"bookstore-backend/internal/domains/payment/model"
 	repo "bookstore-backend/internal/domains/payment/repository"
+	"bookstore-backend/pkg/database"
 )
 
 // =====================================================
@@ -23,6 +25,7 @@ import (
 type refundService struct {
 	paymentRepo repo.PaymentRepoInteface
 	refundRepo  repo.RefundRepoInterface
+	ledgerRepo  repo.LedgerRepoInterface
 	txManager   repo.TransactionManager
 
 	vnpayGateway gateway.VNPayGateway
@@ -34,6 +37,7 @@ type refundService struct {
 func NewRefundService(
 	paymentRepo repo.PaymentRepoInteface,
 	refundRepo repo.RefundRepoInterface,
+	ledgerRepo repo.LedgerRepoInterface,
 	txManager repo.TransactionManager,
 	vnpayGateway gateway.VNPayGateway,
 	momoGateway gateway.MomoGateway,
@@ -42,6 +46,7 @@ func NewRefundService(
 	return &refundService{
 		paymentRepo:  paymentRepo,
 		refundRepo:   refundRepo,
+		ledgerRepo:   ledgerRepo,
 		txManager:    txManager,
 		vnpayGateway: vnpayGateway,
 		momoGateway:  momoGateway,
@@ -176,6 +181,83 @@ func (s *refundService) RequestRefund(
 	return response, nil
 }
 
+// =====================================================
+// STAFF: COMPENSATION REFUND
+// =====================================================
+
+// CreateCompensationRefund creates pending refund request on behalf of customer
+//
+// Khác RequestRefund:
+// - Nhân viên tạo (bồi thường khiếu nại: thiếu sách, sách hỏng), không cần payment ID từ khách
+// - Hoàn một phần: amount <= số tiền đã thanh toán - đã hoàn
+// - Không giới hạn cửa sổ 7 ngày (khiếu nại đã được CSKH xác minh)
+// Vẫn đi qua ApproveRefund như refund thường → ledger + gateway refund
+func (s *refundService) CreateCompensationRefund(
+	ctx context.Context,
+	staffID uuid.UUID,
+	orderID uuid.UUID,
+	amount decimal.Decimal,
+	reason string,
+) (*model.RefundRequestResponse, error) {
+	if !amount.IsPositive() {
+		return nil, model.NewRefundNotAllowedError("refund amount must be positive")
+	}
+
+	// Payment thành công mới nhất của đơn
+	latest, err := s.paymentRepo.GetByOrderIDAndStatus(ctx, orderID, model.PaymentStatusSuccess)
+	if err != nil {
+		return nil, model.NewPaymentError(model.ErrCodePaymentNotFound, "No successful payment found for order", err)
+	}
+	payment, err := s.paymentRepo.GetByID(ctx, latest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if !payment.CanBeRefunded() {
+		return nil, model.NewRefundNotAllowedError("payment cannot be refunded")
+	}
+	refundable := payment.Amount.Sub(payment.RefundAmount)
+	if amount.GreaterThan(refundable) {
+		return nil, model.NewRefundNotAllowedError(fmt.Sprintf("amount exceeds refundable balance %s", refundable.StringFixed(0)))
+	}
+
+	hasPending, err := s.refundRepo.HasPendingRefund(ctx, payment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pending refund: %w", err)
+	}
+	if hasPending {
+		return nil, model.NewPaymentError(
+			model.ErrCodeRefundAlreadyExists,
+			"A refund request already exists for this payment",
+			model.ErrRefundAlreadyExists,
+		)
+	}
+
+	refund := &model.RefundRequest{
+		ID:                   uuid.New(),
+		PaymentTransactionID: payment.ID,
+		OrderID:              orderID,
+		RequestedBy:          staffID,
+		RequestedAmount:      amount,
+		Reason:               reason,
+		Status:               model.RefundStatusPending,
+		RequestedAt:          time.Now(),
+	}
+	if err := s.refundRepo.Create(ctx, refund); err != nil {
+		return nil, fmt.Errorf("failed to create refund request: %w", err)
+	}
+
+	return &model.RefundRequestResponse{
+		RefundRequestID:         refund.ID,
+		PaymentTransactionID:    payment.ID,
+		Status:                  model.RefundStatusPending,
+		RequestedAmount:         amount,
+		Reason:                  reason,
+		RequestedAt:             refund.RequestedAt,
+		EstimatedProcessingTime: "3-5 business days",
+	}, nil
+}
+
 // =====================================================
 // USER: GET REFUND STATUS
 // =====================================================
@@ -287,6 +369,10 @@ func (s *refundService) ApproveRefund(
 	}
 
 	// Step 3: Start transaction
+	// Deadline cho transaction: ctx cancel/hết hạn → query lỗi, defer rollback giải phóng lock
+	ctx, cancelTx := database.WithWriteTimeout(ctx)
+	defer cancelTx()
+
 	tx, err := s.txManager.BeginTx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -341,6 +427,11 @@ func (s *refundService) ApproveRefund(
 		return nil, fmt.Errorf("failed to update gateway refund: %w", err)
 	}
 
+	// Step 6b: Ghi sổ cái - mỗi refund request 1 dòng tiền, hoàn một phần vẫn audit được
+	if _, err := s.ledgerRepo.PostWithTx(ctx, tx, model.NewRefundLedger(refund, payment, adminID)); err != nil {
+		return nil, fmt.Errorf("failed to post refund ledger: %w", err)
+	}
+
 	// Step 7: Commit transaction
 	if err := s.txManager.CommitTx(ctx, tx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -285,6 +288,192 @@ func (h *AdminHandler) DeletePromotion(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Promotion đã được xóa thành công", id)
 }
 
+// -------------------------------------------------------------------
+// TARGETED PROMOTIONS (mã cá nhân)
+// -------------------------------------------------------------------
+
+// AssignPromotion gán mã cho danh sách user, mã hiện trong ví của họ
+// @Description  Gán promotion cho user (mã cá nhân / đền bù), user không tồn tại hoặc đã gán thì bỏ qua (Admin only)
+// @Router       /v1/promotion/:id/assignments [post]
+func (h *AdminHandler) AssignPromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Promotion ID không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	var req model.AssignPromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Dữ liệu request không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	result, err := h.service.AssignPromotion(c.Request.Context(), id, &req, *adminID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Assign promotion successfully", result)
+}
+
+// UnassignPromotion thu hồi mã đã gán cho user
+// @Router       /v1/promotion/:id/assignments/:user_id [delete]
+func (h *AdminHandler) UnassignPromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Promotion ID không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "User ID không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	if err := h.service.UnassignPromotion(c.Request.Context(), id, userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Unassign promotion successfully", gin.H{
+		"promotion_id": id,
+		"user_id":      userID,
+	})
+}
+
+// -------------------------------------------------------------------
+// GIFT-WITH-PURCHASE (tặng quà kèm đơn)
+// -------------------------------------------------------------------
+
+// CreateGiftPromotion tạo chương trình tặng quà, giỏ đạt điều kiện được tự thêm dòng quà 0đ
+// @Router       /v1/promotion/gifts [post]
+func (h *AdminHandler) CreateGiftPromotion(c *gin.Context) {
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	var req model.CreateGiftPromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Dữ liệu request không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	gift, err := h.service.CreateGiftPromotion(c.Request.Context(), &req, *adminID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Create gift promotion successfully", gift)
+}
+
+// ListGiftPromotions danh sách chương trình tặng quà
+// @Router       /v1/promotion/gifts [get]
+func (h *AdminHandler) ListGiftPromotions(c *gin.Context) {
+	var filter model.ListGiftPromotionsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.Error(c, http.StatusBadRequest, "Query parameters không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	gifts, total, err := h.service.ListGiftPromotions(c.Request.Context(), &filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "List gift promotions successfully", gin.H{
+		"gift_promotions": gifts,
+		"pagination": gin.H{
+			"page":        filter.Page,
+			"limit":       filter.Limit,
+			"total":       total,
+			"total_pages": (total + filter.Limit - 1) / filter.Limit,
+		},
+	})
+}
+
+// UpdateGiftPromotionStatus bật/tắt chương trình tặng quà
+// @Router       /v1/promotion/gifts/:id/status [patch]
+func (h *AdminHandler) UpdateGiftPromotionStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Gift promotion ID không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	var req struct {
+		IsActive bool `json:"is_active"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Dữ liệu request không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	if err := h.service.UpdateGiftPromotionStatus(c.Request.Context(), id, req.IsActive); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Update gift promotion status successfully", gin.H{
+		"id":        id,
+		"is_active": req.IsActive,
+	})
+}
+
+// DeleteGiftPromotion xoá chương trình tặng quà
+// @Router       /v1/promotion/gifts/:id [delete]
+func (h *AdminHandler) DeleteGiftPromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Gift promotion ID không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	if err := h.service.DeleteGiftPromotion(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Gift promotion đã được xóa", id)
+}
+
 // -------------------------------------------------------------------
 // USAGE HISTORY & REPORTING
 // -------------------------------------------------------------------
@@ -435,12 +624,94 @@ func (h *AdminHandler) ExportUsageReport(c *gin.Context) {
 	response.Success(c, http.StatusAccepted, "Export đang được xử lý, bạn sẽ nhận email khi hoàn thành", jobID)
 }
 
+// ImportPromotionUsage backfill promotion_usage từ CSV hệ thống cũ (chuyển dữ liệu cửa hàng)
+// @Description  Import lịch sử dùng mã (promotion_code, order_number, user_email | user_id, discount_amount, used_at)
+// @Description  dry_run=true chỉ validate; skip_invalid=true bỏ qua dòng lỗi thay vì huỷ cả file
+// @Router       /v1/promotion/usage-imports [post]
+func (h *AdminHandler) ImportPromotionUsage(c *gin.Context) {
+	v, _ := c.Get("user_id")
+	adminID, ok := v.(uuid.UUID)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	var req model.ImportPromotionUsageRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Dữ liệu request không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Thiếu file CSV", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv") {
+		response.Error(c, http.StatusBadRequest, "Chỉ chấp nhận file CSV", gin.H{
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Không đọc được file", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportPromotionUsage(c.Request.Context(), adminID, req, fileHeader.Filename, file)
+	switch {
+	case errors.Is(err, model.ErrUsageImportHasErrors):
+		// Trả kèm danh sách dòng lỗi để admin sửa file hoặc chạy lại với skip_invalid
+		response.Error(c, http.StatusUnprocessableEntity, "File có dòng không hợp lệ, chưa import", gin.H{
+			"info":   err.Error(),
+			"code":   model.ErrCodeValidationFailed,
+			"result": result,
+		})
+		return
+	case errors.Is(err, model.ErrUsageImportInvalidFile):
+		response.Error(c, http.StatusBadRequest, "File CSV không hợp lệ", gin.H{
+			"info": err.Error(),
+			"code": model.ErrCodeValidationFailed,
+		})
+		return
+	case err != nil:
+		h.handleError(c, err)
+		return
+	}
+
+	if req.DryRun {
+		response.Success(c, http.StatusOK, "Validate promotion usage import successfully", result)
+		return
+	}
+	response.Success(c, http.StatusCreated, "Import promotion usage successfully", result)
+}
+
 // -------------------------------------------------------------------
 // HELPER FUNCTIONS
 // -------------------------------------------------------------------
 
 // handleError giống PublicHandler
 func (h *AdminHandler) handleError(c *gin.Context, err error) {
+	var appErr *model.AppError
+	if errors.As(err, &appErr) {
+		response.Error(c, appErr.HTTPStatus, appErr.Message, gin.H{
+			"info": appErr.Details,
+			"code": appErr.Code,
+		})
+		return
+	}
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "Internal server error", gin.H{
 			"info": err.Error(),
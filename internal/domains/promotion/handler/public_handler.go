@@ -277,6 +277,30 @@ func (h *PublicHandler) RemovePromotionFromCart(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Remove promotion to cart", cart)
 }
 
+// GetPromoWallet ví mã của user ("my coupons")
+// @Summary      Get my coupons
+// @Description  Mã được gán riêng + mã công khai còn lượt, đã kiểm tra với giỏ hàng hiện tại để checkout UI hiển thị mã bấm chọn
+// @Router       /v1/promotion/wallet [get]
+func (h *PublicHandler) GetPromoWallet(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		response.Error(c, http.StatusUnauthorized, "Vui lòng đăng nhập", gin.H{
+			"RequestID": c.GetString("request_id"),
+			"Timestamp": time.Now(),
+			"Code":      "UNAUTHORIZED",
+		})
+		return
+	}
+
+	wallet, err := h.service.GetPromoWallet(c.Request.Context(), *userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get promo wallet successfully", wallet)
+}
+
 // -------------------------------------------------------------------
 // HELPER FUNCTIONS
 // -------------------------------------------------------------------
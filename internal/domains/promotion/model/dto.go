@@ -86,6 +86,10 @@ type CreatePromotionRequest struct {
 	StartsAt              string      `json:"starts_at"` // RFC3339 format
 	ExpiresAt             string      `json:"expires_at"`
 	IsActive              bool        `json:"is_active"`
+	IsTargeted            bool        `json:"is_targeted"`  // mã cá nhân, gán user qua /promotion/:id/assignments
+	IsAutomatic           bool        `json:"is_automatic"` // tự áp khi giỏ đạt điều kiện, không cần nhập mã
+	Stackable             bool        `json:"stackable"`    // được cộng dồn với promotion stackable khác
+	Priority              int         `json:"priority"`
 }
 
 // Validate validates CreatePromotionRequest
@@ -107,7 +111,7 @@ func (r CreatePromotionRequest) Validate() error {
 		),
 		validation.Field(&r.DiscountType,
 			validation.Required.Error("Loại giảm giá bắt buộc"),
-			validation.In("percentage", "fixed").Error("Loại giảm giá phải là 'percentage' hoặc 'fixed'"),
+			validation.In("percentage", "fixed", "shipping_percentage", "shipping_fixed").Error("Loại giảm giá phải là 'percentage', 'fixed', 'shipping_percentage' hoặc 'shipping_fixed'"),
 		),
 		validation.Field(&r.DiscountValue,
 			validation.Required.Error("Giá trị giảm giá bắt buộc"),
@@ -144,7 +148,7 @@ func (r CreatePromotionRequest) Validate() error {
 
 // validateDiscountValue kiểm tra percentage không vượt 100
 func (r CreatePromotionRequest) validateDiscountValue(value interface{}) error {
-	if r.DiscountType == "percentage" {
+	if r.DiscountType == "percentage" || r.DiscountType == "shipping_percentage" {
 		if r.DiscountValue > 100 {
 			return errors.New("giảm giá phần trăm không được vượt quá 100")
 		}
@@ -187,6 +191,9 @@ type UpdatePromotionRequest struct {
 	StartsAt          *string          `json:"starts_at"`
 	ExpiresAt         *string          `json:"expires_at"`
 	IsActive          *bool            `json:"is_active"`
+	IsAutomatic       *bool            `json:"is_automatic"`
+	Stackable         *bool            `json:"stackable"`
+	Priority          *int             `json:"priority"`
 }
 
 // ListPromotionsFilter - Filter cho list promotions (Admin)
@@ -303,6 +310,10 @@ type PromotionDetailResponse struct {
 	StartsAt              time.Time        `json:"starts_at"`
 	ExpiresAt             time.Time        `json:"expires_at"`
 	IsActive              bool             `json:"is_active"`
+	IsTargeted            bool             `json:"is_targeted"`
+	IsAutomatic           bool             `json:"is_automatic"`
+	Stackable             bool             `json:"stackable"`
+	Priority              int              `json:"priority"`
 	Version               int              `json:"version"`
 	CreatedAt             time.Time        `json:"created_at"`
 	UpdatedAt             time.Time        `json:"updated_at"`
@@ -337,8 +348,8 @@ type PromotionUsageDetailItem struct {
 // UserInfo - Thông tin user trong usage history
 type UserInfo struct {
 	ID       uuid.UUID `json:"id"`
-	Email    string    `json:"email"`
-	FullName string    `json:"full_name"`
+	Email    string    `json:"email" pii:"email"`
+	FullName string    `json:"full_name" pii:"name"`
 }
 
 // OrderInfo - Thông tin order trong usage history
@@ -108,6 +108,10 @@ func (p *PromotionEntity) CalculateDiscount(orderTotal decimal.Decimal) (decimal
 			discount = orderTotal
 		}
 		
+	case string(DiscountTypeShippingPercentage), string(DiscountTypeShippingFixed):
+		// Giảm trên phí ship, không trừ tiền hàng
+		discount = decimal.Zero
+
 	default:
 		return decimal.Zero, ErrInvalidDiscountType
 	}
@@ -63,5 +63,23 @@ var (
 		HTTPStatus: 400,
 	}
 
+	ErrPromotionAssignmentNotFound = &AppError{
+		Code:       ErrCodePromoNotFound,
+		Message:    "User chưa được gán mã giảm giá này",
+		HTTPStatus: 404,
+	}
+
+	ErrGiftPromotionNotFound = &AppError{
+		Code:       ErrCodePromoNotFound,
+		Message:    "Chương trình tặng quà không tồn tại",
+		HTTPStatus: 404,
+	}
+
+	ErrGiftBookNotFound = &AppError{
+		Code:       ErrCodeValidationFailed,
+		Message:    "Sách quà tặng không tồn tại hoặc đã ngừng bán",
+		HTTPStatus: 400,
+	}
+
 	// ... định nghĩa các errors khác
 )
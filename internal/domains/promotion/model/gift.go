@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// GIFT-WITH-PURCHASE (tặng quà kèm đơn)
+// =====================================================
+// Khác promotion giảm tiền: không cần nhập mã, giỏ đạt điều kiện thì hệ thống tự thêm
+// dòng quà 0đ vào cart_items (gift_promotion_id), hết điều kiện thì tự gỡ.
+// Điều kiện chỉ tính trên dòng hàng trả tiền; cộng dồn được với mã giảm giá.
+
+const GiftPromotionMaxQuantity = 10 // khớp CHECK gift_quantity trong migration
+
+// GiftPromotion entity bảng gift_promotions
+type GiftPromotion struct {
+	ID                    uuid.UUID       `json:"id"`
+	Name                  string          `json:"name"`
+	Description           *string         `json:"description,omitempty"`
+	GiftBookID            uuid.UUID       `json:"gift_book_id"`
+	GiftQuantity          int             `json:"gift_quantity"`
+	MinOrderAmount        decimal.Decimal `json:"min_order_amount"`
+	ApplicableCategoryIDs []uuid.UUID     `json:"applicable_category_ids"`
+	StartsAt              time.Time       `json:"starts_at"`
+	ExpiresAt             time.Time       `json:"expires_at"`
+	IsActive              bool            `json:"is_active"`
+	CreatedBy             *uuid.UUID      `json:"created_by,omitempty"`
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at"`
+
+	// Join books (list admin)
+	GiftBookTitle string `json:"gift_book_title,omitempty"`
+}
+
+// CreateGiftPromotionRequest - POST /promotion/gifts
+type CreateGiftPromotionRequest struct {
+	Name                  string          `json:"name" binding:"required,min=3,max=200"`
+	Description           *string         `json:"description" binding:"omitempty,max=1000"`
+	GiftBookID            uuid.UUID       `json:"gift_book_id" binding:"required"`
+	GiftQuantity          int             `json:"gift_quantity" binding:"omitempty,min=1,max=10"`
+	MinOrderAmount        decimal.Decimal `json:"min_order_amount"`
+	ApplicableCategoryIDs []uuid.UUID     `json:"applicable_category_ids"`
+	StartsAt              time.Time       `json:"starts_at" binding:"required"`
+	ExpiresAt             time.Time       `json:"expires_at" binding:"required"`
+	IsActive              bool            `json:"is_active"`
+}
+
+// ListGiftPromotionsFilter - GET /promotion/gifts
+type ListGiftPromotionsFilter struct {
+	IsActive *bool `form:"is_active"`
+	Page     int   `form:"page"`
+	Limit    int   `form:"limit"`
+}
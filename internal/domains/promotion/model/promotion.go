@@ -13,8 +13,17 @@ type DiscountType string
 const (
 	DiscountTypePercentage DiscountType = "percentage" // Giảm theo %
 	DiscountTypeFixed      DiscountType = "fixed"      // Giảm số tiền cố định
+
+	// Giảm trên phí ship (không trừ vào tiền hàng)
+	DiscountTypeShippingPercentage DiscountType = "shipping_percentage" // Giảm % phí ship (100 = freeship)
+	DiscountTypeShippingFixed      DiscountType = "shipping_fixed"      // Giảm số tiền cố định trên phí ship
 )
 
+// IsShippingDiscount mã giảm phí ship thay vì giảm tiền hàng
+func (t DiscountType) IsShippingDiscount() bool {
+	return t == DiscountTypeShippingPercentage || t == DiscountTypeShippingFixed
+}
+
 // Promotion đại diện cho một chương trình khuyến mãi
 type Promotion struct {
 	ID          uuid.UUID `db:"id" json:"id"`
@@ -44,6 +53,14 @@ type Promotion struct {
 	// Trạng thái
 	IsActive bool `db:"is_active" json:"is_active"`
 	Version  int  `db:"version" json:"version"` // Optimistic locking
+
+	// Mã cá nhân: chỉ user được gán (promotion_assignments) mới thấy / dùng được
+	IsTargeted bool `db:"is_targeted" json:"is_targeted"`
+
+	// Tự áp khi giỏ đạt điều kiện (không cần nhập mã) + luật cộng dồn, xem ResolveStack
+	IsAutomatic bool `db:"is_automatic" json:"is_automatic"`
+	Stackable   bool `db:"stackable" json:"stackable"` // được cộng dồn với promotion stackable khác
+	Priority    int  `db:"priority" json:"priority"`   // cao xét trước
 	
 	// Audit
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// STACKING RULES (mã nhập tay + promotion tự động)
+// =====================================================
+// Dùng chung cho cart (hiển thị khi áp mã) và order (tính tiền thật lúc tạo đơn)
+// nên chỉ làm việc trên StackCandidate đã qua kiểm tra điều kiện của từng bên.
+
+// StackCandidate promotion đã đủ điều kiện + số tiền giảm (tiền hàng + phí ship) trên giỏ hiện tại
+type StackCandidate struct {
+	PromotionID uuid.UUID
+	Code        string
+	Automatic   bool
+	Stackable   bool
+	Priority    int
+	Discount    decimal.Decimal
+}
+
+// NewStackCandidate build candidate từ promotion + số tiền giảm đã tính
+func NewStackCandidate(p *Promotion, discount decimal.Decimal) StackCandidate {
+	return StackCandidate{
+		PromotionID: p.ID,
+		Code:        p.Code,
+		Automatic:   p.IsAutomatic,
+		Stackable:   p.Stackable,
+		Priority:    p.Priority,
+		Discount:    discount,
+	}
+}
+
+// ResolveStack chọn các promotion được áp:
+// - Có mã nhập tay: luôn áp mã; mã stackable thì cộng thêm mọi promotion tự động stackable
+// - Không có mã: phương án giảm nhiều nhất giữa (tất cả tự động stackable) và (từng tự động không stackable)
+// Kết quả: mã nhập tay trước, sau đó tự động theo priority giảm dần
+func ResolveStack(code *StackCandidate, automatic []StackCandidate) []StackCandidate {
+	sorted := make([]StackCandidate, 0, len(automatic))
+	for _, c := range automatic {
+		if code != nil && c.PromotionID == code.PromotionID {
+			continue
+		}
+		sorted = append(sorted, c)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].Code < sorted[j].Code
+	})
+
+	var stackable []StackCandidate
+	for _, c := range sorted {
+		if c.Stackable {
+			stackable = append(stackable, c)
+		}
+	}
+
+	if code != nil {
+		applied := []StackCandidate{*code}
+		if code.Stackable {
+			applied = append(applied, stackable...)
+		}
+		return applied
+	}
+
+	best := stackable
+	bestTotal := StackDiscount(stackable)
+	for _, c := range sorted {
+		if c.Stackable {
+			continue
+		}
+		// sorted theo priority → bằng tiền thì giữ phương án xét trước
+		if c.Discount.GreaterThan(bestTotal) {
+			best = []StackCandidate{c}
+			bestTotal = c.Discount
+		}
+	}
+	return best
+}
+
+// StackDiscount tổng tiền giảm của các candidate (chưa clamp theo subtotal / phí ship)
+func StackDiscount(applied []StackCandidate) decimal.Decimal {
+	total := decimal.Zero
+	for _, c := range applied {
+		total = total.Add(c.Discount)
+	}
+	return total
+}
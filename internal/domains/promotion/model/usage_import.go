@@ -0,0 +1,118 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// PROMOTION USAGE IMPORT (backfill từ hệ thống cũ)
+// =====================================================
+
+const (
+	UsageImportMaxRows   = 20000
+	UsageImportMaxErrors = 200 // số lỗi trả về trong response, tổng số lỗi vẫn đếm đủ
+)
+
+var (
+	ErrUsageImportInvalidFile = errors.New("invalid promotion usage import file")
+	ErrUsageImportHasErrors   = errors.New("promotion usage import has invalid rows, nothing was imported")
+)
+
+// ImportPromotionUsageRequest - form fields đi kèm file CSV
+// dry_run: chỉ validate, không ghi; skip_invalid: bỏ qua dòng lỗi thay vì huỷ cả file
+type ImportPromotionUsageRequest struct {
+	DryRun      bool `form:"dry_run"`
+	SkipInvalid bool `form:"skip_invalid"`
+}
+
+// UsageImportLine 1 dòng CSV: promotion_code, order_number, user_email | user_id,
+// discount_amount (tuỳ chọn, mặc định = orders.discount_amount), used_at (tuỳ chọn, mặc định = ngày đặt đơn)
+type UsageImportLine struct {
+	LineNo         int
+	PromotionCode  string
+	OrderNumber    string
+	UserEmail      *string
+	UserID         *uuid.UUID
+	DiscountAmount *decimal.Decimal
+	UsedAt         *time.Time
+}
+
+// UsageImportPromotion promotion tra theo code
+type UsageImportPromotion struct {
+	ID          uuid.UUID
+	Code        string
+	MaxUses     *int
+	CurrentUses int
+}
+
+// UsageImportOrder đơn tra theo order_number
+type UsageImportOrder struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	PromotionID    *uuid.UUID
+	DiscountAmount decimal.Decimal
+	CreatedAt      time.Time
+}
+
+// UsageKey cặp (promotion, order) - UNIQUE trong promotion_usage
+type UsageKey struct {
+	PromotionID uuid.UUID
+	OrderID     uuid.UUID
+}
+
+// UsageImportLookup dữ liệu đối chiếu cho cả file (tra 1 lần, không query từng dòng)
+type UsageImportLookup struct {
+	Promotions     map[string]UsageImportPromotion // key: lower(code)
+	Orders         map[string]UsageImportOrder     // key: order_number
+	UsersByEmail   map[string]uuid.UUID            // key: lower(email)
+	ExistingUsages map[UsageKey]struct{}
+}
+
+// PromotionUsageImport represents promotion_usage_imports table
+type PromotionUsageImport struct {
+	ID            uuid.UUID
+	FileName      string
+	TotalRows     int
+	ImportedRows  int
+	DuplicateRows int
+	InvalidRows   int
+	SkipInvalid   bool
+	ImportedBy    uuid.UUID
+}
+
+// UsageImportRowError dòng bị loại và lý do
+type UsageImportRowError struct {
+	LineNo        int    `json:"line_no"`
+	PromotionCode string `json:"promotion_code,omitempty"`
+	OrderNumber   string `json:"order_number,omitempty"`
+	Reason        string `json:"reason"`
+}
+
+// UsageImportPromotionStat kết quả theo promotion sau import
+type UsageImportPromotionStat struct {
+	PromotionID uuid.UUID `json:"promotion_id"`
+	Code        string    `json:"code"`
+	Imported    int       `json:"imported"`
+	CurrentUses int       `json:"current_uses"`
+	MaxUses     *int      `json:"max_uses,omitempty"`
+	// Dữ liệu cũ vượt giới hạn hiện tại: mã sẽ bị coi là hết lượt, admin cân nhắc nâng max_uses
+	ExceedsMaxUses bool `json:"exceeds_max_uses"`
+}
+
+// PromotionUsageImportResult response import (dry run thì ImportID = nil, CurrentUses là số dự kiến)
+type PromotionUsageImportResult struct {
+	ImportID      *uuid.UUID                 `json:"import_id,omitempty"`
+	FileName      string                     `json:"file_name"`
+	DryRun        bool                       `json:"dry_run"`
+	SkipInvalid   bool                       `json:"skip_invalid"`
+	TotalRows     int                        `json:"total_rows"`
+	ImportedRows  int                        `json:"imported_rows"`
+	DuplicateRows int                        `json:"duplicate_rows"`
+	InvalidRows   int                        `json:"invalid_rows"`
+	Errors        []UsageImportRowError      `json:"errors"`
+	Promotions    []UsageImportPromotionStat `json:"promotions"`
+}
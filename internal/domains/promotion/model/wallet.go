@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// PROMO WALLET ("my coupons") & TARGETED PROMOTIONS
+// =====================================================
+
+const (
+	WalletMaxPromotions       = 50   // số mã tối đa trong ví (ưu tiên mã cá nhân, sắp hết hạn)
+	AssignmentMaxUsersPerCall = 1000 // số user tối đa mỗi lần gán
+)
+
+// Nguồn của mã trong ví
+const (
+	WalletSourceAssigned = "assigned" // admin gán riêng cho user (mã cá nhân / dùng 1 lần)
+	WalletSourcePublic   = "public"   // promotion công khai đang chạy
+)
+
+// AssignPromotionRequest - POST /promotion/:id/assignments
+type AssignPromotionRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required,min=1"`
+	Note    *string     `json:"note"`
+}
+
+// AssignPromotionResult - user_id không tồn tại hoặc đã được gán thì bỏ qua
+type AssignPromotionResult struct {
+	PromotionID uuid.UUID `json:"promotion_id"`
+	Requested   int       `json:"requested"`
+	Assigned    int       `json:"assigned"`
+}
+
+// WalletPromotion promotion user còn dùng được (đọc từ repo)
+type WalletPromotion struct {
+	Promotion      *Promotion
+	Assigned       bool // có trong promotion_assignments của user
+	UserUsageCount int
+}
+
+// WalletCoupon 1 mã trong ví, eligibility đã kiểm tra sẵn với giỏ hàng hiện tại
+type WalletCoupon struct {
+	Code              string           `json:"code"`
+	Name              string           `json:"name"`
+	Description       *string          `json:"description,omitempty"`
+	DiscountType      string           `json:"discount_type"`
+	DiscountValue     decimal.Decimal  `json:"discount_value"`
+	MaxDiscountAmount *decimal.Decimal `json:"max_discount_amount,omitempty"`
+	MinOrderAmount    decimal.Decimal  `json:"min_order_amount"`
+	ExpiresAt         time.Time        `json:"expires_at"`
+	Source            string           `json:"source"` // assigned | public
+	UserRemainingUses int              `json:"user_remaining_uses"`
+
+	// Kiểm tra với giỏ hàng: Eligible = bấm là áp dụng được
+	Eligible          bool             `json:"eligible"`
+	Applied           bool             `json:"applied"` // đang áp dụng trên giỏ
+	EstimatedDiscount decimal.Decimal  `json:"estimated_discount"`
+	IneligibleCode    ErrorCode        `json:"ineligible_code,omitempty"`
+	IneligibleReason  string           `json:"ineligible_reason,omitempty"`
+	AmountNeeded      *decimal.Decimal `json:"amount_needed,omitempty"` // còn thiếu bao nhiêu để đạt min_order_amount
+}
+
+// PromoWalletResponse - GET /promotion/wallet
+type PromoWalletResponse struct {
+	CartSubtotal decimal.Decimal `json:"cart_subtotal"`
+	AppliedCode  *string         `json:"applied_code,omitempty"`
+	Coupons      []WalletCoupon  `json:"coupons"`
+}
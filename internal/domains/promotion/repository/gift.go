@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/promotion/model"
+)
+
+// =================================================================================================
+// GIFT-WITH-PURCHASE PROMOTIONS
+// =================================================================================================
+
+// CreateGiftPromotion tạo chương trình tặng quà, sách quà phải tồn tại và đang bán
+func (r *PostgresRepository) CreateGiftPromotion(ctx context.Context, gift *model.GiftPromotion) error {
+	query := `
+		INSERT INTO gift_promotions (
+			name, description, gift_book_id, gift_quantity,
+			min_order_amount, applicable_category_ids,
+			starts_at, expires_at, is_active, created_by
+		)
+		SELECT $1, $2, b.id, $4, $5, $6, $7, $8, $9, $10
+		FROM books b
+		WHERE b.id = $3 AND b.is_active = true AND b.deleted_at IS NULL
+		RETURNING id, created_at, updated_at
+	`
+
+	rows, err := r.db.Query(ctx, query,
+		gift.Name, gift.Description, gift.GiftBookID, gift.GiftQuantity,
+		gift.MinOrderAmount, gift.ApplicableCategoryIDs,
+		gift.StartsAt, gift.ExpiresAt, gift.IsActive, gift.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("create gift promotion: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("create gift promotion: %w", err)
+		}
+		return model.ErrGiftBookNotFound
+	}
+	if err := rows.Scan(&gift.ID, &gift.CreatedAt, &gift.UpdatedAt); err != nil {
+		return fmt.Errorf("scan gift promotion: %w", err)
+	}
+	return nil
+}
+
+// ListGiftPromotions danh sách chương trình tặng quà (admin), mới nhất trước
+func (r *PostgresRepository) ListGiftPromotions(ctx context.Context, filter *model.ListGiftPromotionsFilter) ([]*model.GiftPromotion, int, error) {
+	query := `
+		SELECT
+			g.id, g.name, g.description, g.gift_book_id, g.gift_quantity,
+			g.min_order_amount, g.applicable_category_ids,
+			g.starts_at, g.expires_at, g.is_active, g.created_by,
+			g.created_at, g.updated_at,
+			COALESCE(b.title, ''),
+			COUNT(*) OVER() AS total_count
+		FROM gift_promotions g
+		LEFT JOIN books b ON b.id = g.gift_book_id
+		WHERE ($1::BOOLEAN IS NULL OR g.is_active = $1)
+		ORDER BY g.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	offset := (filter.Page - 1) * filter.Limit
+	rows, err := r.db.Query(ctx, query, filter.IsActive, filter.Limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list gift promotions: %w", err)
+	}
+	defer rows.Close()
+
+	gifts := make([]*model.GiftPromotion, 0)
+	total := 0
+	for rows.Next() {
+		var g model.GiftPromotion
+		if err := rows.Scan(
+			&g.ID, &g.Name, &g.Description, &g.GiftBookID, &g.GiftQuantity,
+			&g.MinOrderAmount, &g.ApplicableCategoryIDs,
+			&g.StartsAt, &g.ExpiresAt, &g.IsActive, &g.CreatedBy,
+			&g.CreatedAt, &g.UpdatedAt,
+			&g.GiftBookTitle,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan gift promotion: %w", err)
+		}
+		gifts = append(gifts, &g)
+	}
+	return gifts, total, rows.Err()
+}
+
+// UpdateGiftPromotionStatus bật/tắt chương trình tặng quà
+// Tắt → dòng quà trong giỏ bị gỡ ở lần đồng bộ kế tiếp (mọi thay đổi giỏ + checkout)
+func (r *PostgresRepository) UpdateGiftPromotionStatus(ctx context.Context, id uuid.UUID, isActive bool) error {
+	query := `UPDATE gift_promotions SET is_active = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, query, id, isActive)
+	if err != nil {
+		return fmt.Errorf("update gift promotion status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrGiftPromotionNotFound
+	}
+	return nil
+}
+
+// DeleteGiftPromotion xoá chương trình, dòng quà trong các giỏ bị xoá theo (ON DELETE CASCADE)
+// Đơn đã đặt giữ nguyên snapshot order_items.gift_promotion_id
+func (r *PostgresRepository) DeleteGiftPromotion(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM gift_promotions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete gift promotion: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrGiftPromotionNotFound
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ type PromotionRepository interface {
 	FindByCode(ctx context.Context, code string) (*model.Promotion, error)
 	FindByCodeActive(ctx context.Context, code string) (*model.Promotion, error)
 	GetUserUsageCount(ctx context.Context, promoID, userID uuid.UUID) (int, error)
+	ListAutomaticActive(ctx context.Context) ([]*model.Promotion, error)
 	ListActive(ctx context.Context, categoryID *uuid.UUID, page, limit int) ([]*model.Promotion, int, error)
 	ListAdmin(ctx context.Context, filter *model.ListPromotionsFilter) ([]*model.PromotionListItem, int, error)
 
@@ -28,9 +29,27 @@ type PromotionRepository interface {
 
 	// Usage tracking
 	CreateUsage(ctx context.Context, tx pgx.Tx, usage *model.PromotionUsage) error
+	// RevertUsageWithTx xoá usage của đơn (bù trừ checkout) + giảm current_uses tương ứng
+	RevertUsageWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (int, error)
 	GetUsageHistory(ctx context.Context, promoID uuid.UUID, startDate, endDate *time.Time, userID *uuid.UUID, page, limit int) ([]*model.PromotionUsageWithDetails, int, error)
 	GetUsageStats(ctx context.Context, promoID uuid.UUID, startDate, endDate *time.Time) (*model.UsageStats, error)
 
+	// Usage import (backfill từ hệ thống cũ)
+	GetUsageImportLookup(ctx context.Context, codes, orderNumbers, emails []string) (*model.UsageImportLookup, error)
+	ImportUsages(ctx context.Context, imp *model.PromotionUsageImport, usages []model.PromotionUsage) (imported map[uuid.UUID]int, currentUses map[uuid.UUID]int, err error)
+
+	// Targeted promotions & promo wallet
+	IsPromotionAssigned(ctx context.Context, promoID, userID uuid.UUID) (bool, error)
+	AssignPromotion(ctx context.Context, promoID uuid.UUID, userIDs []uuid.UUID, note *string, assignedBy uuid.UUID) (int, error)
+	UnassignPromotion(ctx context.Context, promoID, userID uuid.UUID) error
+	ListWalletPromotions(ctx context.Context, userID uuid.UUID, limit int) ([]*model.WalletPromotion, error)
+
+	// Gift-with-purchase (dòng quà 0đ tự thêm vào giỏ)
+	CreateGiftPromotion(ctx context.Context, gift *model.GiftPromotion) error
+	ListGiftPromotions(ctx context.Context, filter *model.ListGiftPromotionsFilter) ([]*model.GiftPromotion, int, error)
+	UpdateGiftPromotionStatus(ctx context.Context, id uuid.UUID, isActive bool) error
+	DeleteGiftPromotion(ctx context.Context, id uuid.UUID) error
+
 	// Utility
 	CheckCodeExists(ctx context.Context, code string, excludeID *uuid.UUID) (bool, error)
 }
@@ -44,7 +44,8 @@ func scanPromotionRow(row scannable) (*model.Promotion, error) {
 		&p.MinOrderAmount, &p.ApplicableCategoryIDs, &p.FirstOrderOnly,
 		&p.MaxUses, &p.MaxUsesPerUser, &p.CurrentUses,
 		&p.StartsAt, &p.ExpiresAt, &p.IsActive, &p.Version,
-		&p.CreatedAt, &p.UpdatedAt,
+		&p.CreatedAt, &p.UpdatedAt, &p.IsTargeted,
+		&p.IsAutomatic, &p.Stackable, &p.Priority,
 	)
 	return &p, err
 }
@@ -57,7 +58,8 @@ const standardSelectClause = `
 		min_order_amount, applicable_category_ids, first_order_only,
 		max_uses, max_uses_per_user, current_uses,
 		starts_at, expires_at, is_active, version,
-		created_at, updated_at
+		created_at, updated_at, is_targeted,
+		is_automatic, stackable, priority
 	FROM promotions
 `
 
@@ -105,7 +107,8 @@ func (r *PostgresRepository) FindByCodeActive(ctx context.Context, code string)
 			min_order_amount, applicable_category_ids, first_order_only,
 			max_uses, COALESCE(max_uses_per_user, 0) AS max_uses_per_user, current_uses,
 			starts_at, expires_at, is_active, version,
-			created_at, updated_at
+			created_at, updated_at, is_targeted,
+			is_automatic, stackable, priority
 		FROM promotions
 		WHERE LOWER(code) = LOWER($1)
 			AND is_active = true
@@ -135,11 +138,40 @@ func (r *PostgresRepository) GetUserUsageCount(ctx context.Context, promoID, use
 	return count, nil
 }
 
+// ListAutomaticActive promotion tự động đang chạy, còn lượt toàn cục (priority cao trước)
+func (r *PostgresRepository) ListAutomaticActive(ctx context.Context) ([]*model.Promotion, error) {
+	query := standardSelectClause + `
+		WHERE is_automatic = true
+			AND is_active = true
+			AND starts_at <= NOW()
+			AND expires_at >= NOW()
+			AND (max_uses IS NULL OR current_uses < max_uses)
+		ORDER BY priority DESC, code ASC
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list automatic promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var promotions []*model.Promotion
+	for rows.Next() {
+		p, err := scanPromotionRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan automatic promotion: %w", err)
+		}
+		promotions = append(promotions, p)
+	}
+	return promotions, rows.Err()
+}
+
 // ListActive lấy danh sách promotion active
 func (r *PostgresRepository) ListActive(ctx context.Context, categoryID *uuid.UUID, page, limit int) ([]*model.Promotion, int, error) {
 	offset := (page - 1) * limit
 
-	baseWhere := ` WHERE is_active = true AND starts_at <= NOW() AND expires_at >= NOW()`
+	// Mã cá nhân (is_targeted) không hiện ở danh sách công khai
+	baseWhere := ` WHERE is_active = true AND is_targeted = false AND starts_at <= NOW() AND expires_at >= NOW()`
 	args := []interface{}{}
 	argIndex := 1
 
@@ -159,7 +191,8 @@ func (r *PostgresRepository) ListActive(ctx context.Context, categoryID *uuid.UU
 			min_order_amount, applicable_category_ids, first_order_only,
 			max_uses, COALESCE(max_uses_per_user, 0), current_uses,
 			starts_at, expires_at, is_active, version,
-			created_at, updated_at
+			created_at, updated_at, is_targeted,
+			is_automatic, stackable, priority
 		FROM promotions` + baseWhere + ` ORDER BY starts_at DESC` + fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
 
 	queryArgs := append(args, limit, offset)
@@ -301,11 +334,13 @@ func (r *PostgresRepository) Create(ctx context.Context, promo *model.Promotion)
 			discount_type, discount_value, max_discount_amount,
 			min_order_amount, applicable_category_ids, first_order_only,
 			max_uses, max_uses_per_user, current_uses,
-			starts_at, expires_at, is_active,
+			starts_at, expires_at, is_active, is_targeted,
+			is_automatic, stackable, priority,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-			$11, 0, $12, $13, $14, NOW(), NOW()
+			$11, 0, $12, $13, $14, $15,
+			$16, $17, $18, NOW(), NOW()
 		)
 		RETURNING id, code, name
 	`
@@ -317,6 +352,8 @@ func (r *PostgresRepository) Create(ctx context.Context, promo *model.Promotion)
 		promo.MinOrderAmount, pq.Array(promo.ApplicableCategoryIDs), promo.FirstOrderOnly,
 		promo.MaxUses, promo.MaxUsesPerUser, // $10, $11
 		promo.StartsAt, promo.ExpiresAt, promo.IsActive, // $12, $13, $14
+		promo.IsTargeted, // $15
+		promo.IsAutomatic, promo.Stackable, promo.Priority,
 	).Scan(&promo.ID, &promo.Code, &promo.Name)
 
 	if err != nil {
@@ -344,6 +381,7 @@ func (r *PostgresRepository) Update(ctx context.Context, promo *model.Promotion)
 			min_order_amount = $8, applicable_category_ids = $9, first_order_only = $10,
 			max_uses = $11, max_uses_per_user = $12,
 			starts_at = $13, expires_at = $14, is_active = $15,
+			is_automatic = $17, stackable = $18, priority = $19,
 			version = version + 1, updated_at = NOW()
 		WHERE id = $1 AND version = $16
 		RETURNING id, name, code
@@ -356,6 +394,7 @@ func (r *PostgresRepository) Update(ctx context.Context, promo *model.Promotion)
 		promo.MaxUses, promo.MaxUsesPerUser,
 		promo.StartsAt, promo.ExpiresAt, promo.IsActive,
 		promo.Version,
+		promo.IsAutomatic, promo.Stackable, promo.Priority,
 	).Scan(&promo.ID, &promo.Name, &promo.Code)
 
 	if err != nil {
@@ -431,6 +470,33 @@ func (r *PostgresRepository) CreateUsage(ctx context.Context, tx pgx.Tx, usage *
 	return nil
 }
 
+// RevertUsageWithTx - không có trigger AFTER DELETE nên giảm current_uses trong cùng câu lệnh
+func (r *PostgresRepository) RevertUsageWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (int, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM promotion_usage
+			WHERE order_id = $1
+			RETURNING promotion_id
+		), counts AS (
+			SELECT promotion_id, COUNT(*) AS uses
+			FROM deleted
+			GROUP BY promotion_id
+		), updated AS (
+			UPDATE promotions p
+			SET current_uses = GREATEST(p.current_uses - c.uses, 0)
+			FROM counts c
+			WHERE p.id = c.promotion_id
+			RETURNING c.uses
+		)
+		SELECT COALESCE(SUM(uses), 0) FROM updated
+	`
+	var reverted int
+	if err := tx.QueryRow(ctx, query, orderID).Scan(&reverted); err != nil {
+		return 0, fmt.Errorf("revert promotion usage: %w", err)
+	}
+	return reverted, nil
+}
+
 // GetUsageHistory lấy lịch sử sử dụng
 func (r *PostgresRepository) GetUsageHistory(ctx context.Context, promoID uuid.UUID, startDate, endDate *time.Time, userID *uuid.UUID, page, limit int) ([]*model.PromotionUsageWithDetails, int, error) {
 	offset := (page - 1) * limit
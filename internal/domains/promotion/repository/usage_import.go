@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/promotion/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =================================================================================================
+// USAGE IMPORT (backfill promotion_usage từ hệ thống cũ)
+// =================================================================================================
+
+// GetUsageImportLookup tra promotion / order / user / usage đã có cho cả file import
+func (r *PostgresRepository) GetUsageImportLookup(ctx context.Context, codes, orderNumbers, emails []string) (*model.UsageImportLookup, error) {
+	lookup := &model.UsageImportLookup{
+		Promotions:     make(map[string]model.UsageImportPromotion),
+		Orders:         make(map[string]model.UsageImportOrder),
+		UsersByEmail:   make(map[string]uuid.UUID),
+		ExistingUsages: make(map[model.UsageKey]struct{}),
+	}
+
+	promoRows, err := r.db.Query(ctx, `
+		SELECT id, code, max_uses, current_uses
+		FROM promotions
+		WHERE LOWER(code) = ANY($1)`, codes)
+	if err != nil {
+		return nil, fmt.Errorf("lookup import promotions: %w", err)
+	}
+	defer promoRows.Close()
+	for promoRows.Next() {
+		var p model.UsageImportPromotion
+		if err := promoRows.Scan(&p.ID, &p.Code, &p.MaxUses, &p.CurrentUses); err != nil {
+			return nil, fmt.Errorf("scan import promotion: %w", err)
+		}
+		lookup.Promotions[strings.ToLower(p.Code)] = p
+	}
+	if err := promoRows.Err(); err != nil {
+		return nil, fmt.Errorf("lookup import promotions: %w", err)
+	}
+
+	orderRows, err := r.db.Query(ctx, `
+		SELECT id, order_number, user_id, promotion_id, COALESCE(discount_amount, 0), created_at
+		FROM orders
+		WHERE order_number = ANY($1)`, orderNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("lookup import orders: %w", err)
+	}
+	defer orderRows.Close()
+	orderIDs := make([]uuid.UUID, 0, len(orderNumbers))
+	for orderRows.Next() {
+		var o model.UsageImportOrder
+		var number string
+		if err := orderRows.Scan(&o.ID, &number, &o.UserID, &o.PromotionID, &o.DiscountAmount, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan import order: %w", err)
+		}
+		lookup.Orders[number] = o
+		orderIDs = append(orderIDs, o.ID)
+	}
+	if err := orderRows.Err(); err != nil {
+		return nil, fmt.Errorf("lookup import orders: %w", err)
+	}
+
+	if len(emails) > 0 {
+		userRows, err := r.db.Query(ctx, `
+			SELECT id, LOWER(email)
+			FROM users
+			WHERE LOWER(email) = ANY($1)`, emails)
+		if err != nil {
+			return nil, fmt.Errorf("lookup import users: %w", err)
+		}
+		defer userRows.Close()
+		for userRows.Next() {
+			var id uuid.UUID
+			var email string
+			if err := userRows.Scan(&id, &email); err != nil {
+				return nil, fmt.Errorf("scan import user: %w", err)
+			}
+			lookup.UsersByEmail[email] = id
+		}
+		if err := userRows.Err(); err != nil {
+			return nil, fmt.Errorf("lookup import users: %w", err)
+		}
+	}
+
+	usageRows, err := r.db.Query(ctx, `
+		SELECT promotion_id, order_id
+		FROM promotion_usage
+		WHERE order_id = ANY($1)`, orderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("lookup existing usages: %w", err)
+	}
+	defer usageRows.Close()
+	for usageRows.Next() {
+		var key model.UsageKey
+		if err := usageRows.Scan(&key.PromotionID, &key.OrderID); err != nil {
+			return nil, fmt.Errorf("scan existing usage: %w", err)
+		}
+		lookup.ExistingUsages[key] = struct{}{}
+	}
+
+	return lookup, usageRows.Err()
+}
+
+// ImportUsages ghi usage đã validate trong 1 transaction:
+// khoá promotion liên quan (theo thứ tự id, tránh deadlock với checkout), insert bỏ qua cặp đã tồn tại,
+// trigger increment_promotion_usage() tăng current_uses cho đúng số row insert,
+// gắn promotion_id cho đơn cũ chưa có. Trả về số usage insert + current_uses mới theo promotion
+func (r *PostgresRepository) ImportUsages(
+	ctx context.Context,
+	imp *model.PromotionUsageImport,
+	usages []model.PromotionUsage,
+) (map[uuid.UUID]int, map[uuid.UUID]int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin usage import: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	promoIDs := make([]uuid.UUID, 0)
+	seen := make(map[uuid.UUID]struct{})
+	ids := make([]uuid.UUID, len(usages))
+	promotionIDs := make([]uuid.UUID, len(usages))
+	userIDs := make([]uuid.UUID, len(usages))
+	orderIDs := make([]uuid.UUID, len(usages))
+	amounts := make([]string, len(usages))
+	usedAts := make([]time.Time, len(usages))
+	for i, u := range usages {
+		ids[i] = u.ID
+		promotionIDs[i] = u.PromotionID
+		userIDs[i] = u.UserID
+		orderIDs[i] = u.OrderID
+		amounts[i] = u.DiscountAmount.String()
+		usedAts[i] = u.UsedAt
+		if _, ok := seen[u.PromotionID]; !ok {
+			seen[u.PromotionID] = struct{}{}
+			promoIDs = append(promoIDs, u.PromotionID)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		SELECT id FROM promotions WHERE id = ANY($1) ORDER BY id FOR UPDATE`, promoIDs); err != nil {
+		return nil, nil, fmt.Errorf("lock import promotions: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO promotion_usage_imports (id, file_name, total_rows, invalid_rows, skip_invalid, imported_by)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		imp.ID, imp.FileName, imp.TotalRows, imp.InvalidRows, imp.SkipInvalid, imp.ImportedBy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create usage import: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH input AS (
+			SELECT * FROM UNNEST($1::uuid[], $2::uuid[], $3::uuid[], $4::uuid[], $5::numeric[], $6::timestamptz[])
+				AS t(id, promotion_id, user_id, order_id, discount_amount, used_at)
+		),
+		inserted AS (
+			INSERT INTO promotion_usage (id, promotion_id, user_id, order_id, discount_amount, used_at, version, import_id)
+			SELECT id, promotion_id, user_id, order_id, discount_amount, used_at, 0, $7
+			FROM input
+			ON CONFLICT (promotion_id, order_id) DO NOTHING
+			RETURNING promotion_id, order_id
+		),
+		linked AS (
+			UPDATE orders o
+			SET promotion_id = i.promotion_id
+			FROM inserted i
+			WHERE o.id = i.order_id AND o.promotion_id IS NULL
+		)
+		SELECT promotion_id, COUNT(*) FROM inserted GROUP BY promotion_id`,
+		ids, promotionIDs, userIDs, orderIDs, amounts, usedAts, imp.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("insert imported usages: %w", err)
+	}
+	imported := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var promoID uuid.UUID
+		var n int
+		if err := rows.Scan(&promoID, &n); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("scan imported usages: %w", err)
+		}
+		imported[promoID] = n
+		imp.ImportedRows += n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("insert imported usages: %w", err)
+	}
+
+	// Dòng hợp lệ nhưng không insert = usage đã có (import song song / checkout vừa ghi)
+	imp.DuplicateRows += len(usages) - imp.ImportedRows
+	if _, err := tx.Exec(ctx, `
+		UPDATE promotion_usage_imports
+		SET imported_rows = $2, duplicate_rows = $3
+		WHERE id = $1`, imp.ID, imp.ImportedRows, imp.DuplicateRows); err != nil {
+		return nil, nil, fmt.Errorf("update usage import: %w", err)
+	}
+
+	currentUses := make(map[uuid.UUID]int, len(promoIDs))
+	useRows, err := tx.Query(ctx, `SELECT id, current_uses FROM promotions WHERE id = ANY($1)`, promoIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get promotion uses: %w", err)
+	}
+	for useRows.Next() {
+		var id uuid.UUID
+		var n int
+		if err := useRows.Scan(&id, &n); err != nil {
+			useRows.Close()
+			return nil, nil, fmt.Errorf("scan promotion uses: %w", err)
+		}
+		currentUses[id] = n
+	}
+	useRows.Close()
+	if err := useRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("get promotion uses: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit usage import: %w", err)
+	}
+	return imported, currentUses, nil
+}
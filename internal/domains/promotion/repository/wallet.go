@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/promotion/model"
+)
+
+// =================================================================================================
+// TARGETED PROMOTIONS & PROMO WALLET
+// =================================================================================================
+
+// IsPromotionAssigned kiểm tra mã cá nhân đã được gán cho user chưa
+func (r *PostgresRepository) IsPromotionAssigned(ctx context.Context, promoID, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM promotion_assignments WHERE promotion_id = $1 AND user_id = $2)`
+
+	var assigned bool
+	if err := r.db.QueryRow(ctx, query, promoID, userID).Scan(&assigned); err != nil {
+		return false, fmt.Errorf("check promotion assignment: %w", err)
+	}
+	return assigned, nil
+}
+
+// AssignPromotion gán promotion cho danh sách user, bỏ qua user không tồn tại / đã gán
+func (r *PostgresRepository) AssignPromotion(ctx context.Context, promoID uuid.UUID, userIDs []uuid.UUID, note *string, assignedBy uuid.UUID) (int, error) {
+	query := `
+		INSERT INTO promotion_assignments (promotion_id, user_id, note, assigned_by)
+		SELECT $1, u.id, $3, $4
+		FROM users u
+		WHERE u.id = ANY($2)
+		ON CONFLICT (promotion_id, user_id) DO NOTHING
+	`
+
+	result, err := r.db.Exec(ctx, query, promoID, userIDs, note, assignedBy)
+	if err != nil {
+		return 0, fmt.Errorf("assign promotion: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// UnassignPromotion thu hồi mã cá nhân của user (usage đã ghi vẫn giữ nguyên)
+func (r *PostgresRepository) UnassignPromotion(ctx context.Context, promoID, userID uuid.UUID) error {
+	query := `DELETE FROM promotion_assignments WHERE promotion_id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(ctx, query, promoID, userID)
+	if err != nil {
+		return fmt.Errorf("unassign promotion: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrPromotionAssignmentNotFound
+	}
+	return nil
+}
+
+// ListWalletPromotions lấy promotion user còn dùng được:
+// đang chạy, còn lượt toàn cục, user chưa dùng hết lượt, công khai hoặc được gán cho user.
+// Mã được gán lên trước, sau đó mã sắp hết hạn
+func (r *PostgresRepository) ListWalletPromotions(ctx context.Context, userID uuid.UUID, limit int) ([]*model.WalletPromotion, error) {
+	query := `
+		SELECT
+			p.id, p.code, p.name, p.description,
+			p.discount_type, p.discount_value, p.max_discount_amount,
+			p.min_order_amount, p.applicable_category_ids, p.first_order_only,
+			p.max_uses, COALESCE(p.max_uses_per_user, 0), p.current_uses,
+			p.starts_at, p.expires_at, p.is_active, p.version,
+			p.created_at, p.updated_at, p.is_targeted,
+			p.is_automatic, p.stackable, p.priority,
+			a.id IS NOT NULL AS assigned,
+			COALESCE(u.used, 0) AS used
+		FROM promotions p
+		LEFT JOIN promotion_assignments a ON a.promotion_id = p.id AND a.user_id = $1
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS used
+			FROM promotion_usage pu
+			WHERE pu.promotion_id = p.id AND pu.user_id = $1
+		) u ON true
+		WHERE p.is_active = true
+			AND p.starts_at <= NOW()
+			AND p.expires_at >= NOW()
+			AND (p.max_uses IS NULL OR p.current_uses < p.max_uses)
+			AND (p.is_targeted = false OR a.id IS NOT NULL)
+			AND COALESCE(u.used, 0) < COALESCE(p.max_uses_per_user, 0)
+		ORDER BY assigned DESC, p.expires_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list wallet promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := make([]*model.WalletPromotion, 0)
+	for rows.Next() {
+		var p model.Promotion
+		var w model.WalletPromotion
+		if err := rows.Scan(
+			&p.ID, &p.Code, &p.Name, &p.Description,
+			&p.DiscountType, &p.DiscountValue, &p.MaxDiscountAmount,
+			&p.MinOrderAmount, &p.ApplicableCategoryIDs, &p.FirstOrderOnly,
+			&p.MaxUses, &p.MaxUsesPerUser, &p.CurrentUses,
+			&p.StartsAt, &p.ExpiresAt, &p.IsActive, &p.Version,
+			&p.CreatedAt, &p.UpdatedAt, &p.IsTargeted,
+			&p.IsAutomatic, &p.Stackable, &p.Priority,
+			&w.Assigned, &w.UserUsageCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan wallet promotion: %w", err)
+		}
+		w.Promotion = &p
+		promotions = append(promotions, &w)
+	}
+	return promotions, rows.Err()
+}
@@ -26,6 +26,9 @@ func NewDiscountCalculator() *DiscountCalculator {
 //   - discount = discount_value
 //   - Không được vượt quá subtotal: discount = min(discount, subtotal)
 //
+// 3. Shipping Discount (shipping_percentage / shipping_fixed):
+//   - Không giảm tiền hàng → 0, phần giảm ship tính bằng CalculateShipping
+//
 // Returns: Số tiền giảm giá (đã làm tròn)
 func (c *DiscountCalculator) Calculate(promo *model.Promotion, subtotal decimal.Decimal) decimal.Decimal {
 	var discount decimal.Decimal
@@ -53,6 +56,10 @@ func (c *DiscountCalculator) Calculate(promo *model.Promotion, subtotal decimal.
 			discount = subtotal
 		}
 
+	case model.DiscountTypeShippingPercentage, model.DiscountTypeShippingFixed:
+		// Giảm trên phí ship, tiền hàng giữ nguyên
+		return decimal.Zero
+
 	default:
 		// Không hỗ trợ discount type này
 		return decimal.Zero
@@ -63,6 +70,37 @@ func (c *DiscountCalculator) Calculate(promo *model.Promotion, subtotal decimal.
 	return discount.Round(0)
 }
 
+// CalculateShipping tính số tiền giảm trên phí ship (0 nếu promo giảm tiền hàng)
+//
+// - shipping_percentage: shippingFee × discount_value / 100, cap max_discount_amount (100% = freeship)
+// - shipping_fixed: discount_value
+// - Không bao giờ vượt phí ship
+func (c *DiscountCalculator) CalculateShipping(promo *model.Promotion, shippingFee decimal.Decimal) decimal.Decimal {
+	if !shippingFee.IsPositive() {
+		return decimal.Zero
+	}
+
+	var discount decimal.Decimal
+	switch promo.DiscountType {
+	case model.DiscountTypeShippingPercentage:
+		discount = shippingFee.Mul(promo.DiscountValue).Div(decimal.NewFromInt(100))
+		if promo.MaxDiscountAmount != nil && discount.GreaterThan(*promo.MaxDiscountAmount) {
+			discount = *promo.MaxDiscountAmount
+		}
+
+	case model.DiscountTypeShippingFixed:
+		discount = promo.DiscountValue
+
+	default:
+		return decimal.Zero
+	}
+
+	if discount.GreaterThan(shippingFee) {
+		discount = shippingFee
+	}
+	return discount.Round(0)
+}
+
 // CalculateWithBreakdown tính toán chi tiết từng bước (dùng cho debugging/logging)
 func (c *DiscountCalculator) CalculateWithBreakdown(promo *model.Promotion, subtotal decimal.Decimal) DiscountBreakdown {
 	breakdown := DiscountBreakdown{
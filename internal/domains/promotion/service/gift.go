@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/promotion/model"
+)
+
+// CreateGiftPromotion tạo chương trình tặng quà kèm đơn
+// Dòng quà được giỏ hàng tự đồng bộ (cart service), ở đây chỉ lưu cấu hình
+func (s *promotionService) CreateGiftPromotion(ctx context.Context, req *model.CreateGiftPromotionRequest, adminID uuid.UUID) (*model.GiftPromotion, error) {
+	if !req.ExpiresAt.After(req.StartsAt) {
+		return nil, &model.AppError{
+			Code:       model.ErrCodeValidationFailed,
+			Message:    "Thời gian kết thúc phải sau thời gian bắt đầu",
+			HTTPStatus: 400,
+		}
+	}
+	if req.MinOrderAmount.IsNegative() {
+		return nil, &model.AppError{
+			Code:       model.ErrCodeValidationFailed,
+			Message:    "Giá trị đơn hàng tối thiểu phải >= 0",
+			HTTPStatus: 400,
+		}
+	}
+
+	quantity := req.GiftQuantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	categoryIDs := req.ApplicableCategoryIDs
+	if categoryIDs == nil {
+		categoryIDs = []uuid.UUID{}
+	}
+
+	gift := &model.GiftPromotion{
+		Name:                  req.Name,
+		Description:           req.Description,
+		GiftBookID:            req.GiftBookID,
+		GiftQuantity:          quantity,
+		MinOrderAmount:        req.MinOrderAmount,
+		ApplicableCategoryIDs: categoryIDs,
+		StartsAt:              req.StartsAt,
+		ExpiresAt:             req.ExpiresAt,
+		IsActive:              req.IsActive,
+		CreatedBy:             &adminID,
+	}
+	if err := s.repo.CreateGiftPromotion(ctx, gift); err != nil {
+		return nil, err
+	}
+	return gift, nil
+}
+
+// ListGiftPromotions danh sách chương trình tặng quà (admin)
+func (s *promotionService) ListGiftPromotions(ctx context.Context, filter *model.ListGiftPromotionsFilter) ([]*model.GiftPromotion, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	return s.repo.ListGiftPromotions(ctx, filter)
+}
+
+// UpdateGiftPromotionStatus bật/tắt chương trình tặng quà
+func (s *promotionService) UpdateGiftPromotionStatus(ctx context.Context, id uuid.UUID, isActive bool) error {
+	return s.repo.UpdateGiftPromotionStatus(ctx, id, isActive)
+}
+
+// DeleteGiftPromotion xoá chương trình tặng quà (dòng quà trong giỏ bị xoá theo)
+func (s *promotionService) DeleteGiftPromotion(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteGiftPromotion(ctx, id)
+}
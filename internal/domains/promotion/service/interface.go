@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	cart "bookstore-backend/internal/domains/cart/model"
@@ -18,6 +19,8 @@ type ServiceInterface interface {
 	RemovePromotionFromCart(ctx context.Context, userID uuid.UUID) (*cart.CartResponse, error)
 	ListActivePromotions(ctx context.Context, categoryID *uuid.UUID, page, limit int) ([]*model.Promotion, int, error)
 	GetAvailablePromotionsForCart(ctx context.Context, cartID uuid.UUID, userID uuid.UUID) ([]*model.AvailablePromotionResponse, error)
+	// GetPromoWallet ví mã của user: mã được gán + mã công khai, eligibility kiểm tra sẵn với giỏ hàng
+	GetPromoWallet(ctx context.Context, userID uuid.UUID) (*model.PromoWalletResponse, error)
 
 	// Admin methods
 	CreatePromotion(ctx context.Context, req *model.CreatePromotionRequest) (*model.Promotion, error)
@@ -27,6 +30,15 @@ type ServiceInterface interface {
 	UpdatePromotionStatus(ctx context.Context, id uuid.UUID, isActive bool) error
 	DeletePromotion(ctx context.Context, id uuid.UUID) error
 	GetUsageHistory(ctx context.Context, promoID uuid.UUID, startDate, endDate *time.Time, userID *uuid.UUID, page, limit int) (*model.UsageHistoryResponse, error)
+	AssignPromotion(ctx context.Context, promoID uuid.UUID, req *model.AssignPromotionRequest, adminID uuid.UUID) (*model.AssignPromotionResult, error)
+	UnassignPromotion(ctx context.Context, promoID, userID uuid.UUID) error
+	// Gift-with-purchase: giỏ đạt điều kiện được tự thêm dòng quà 0đ
+	CreateGiftPromotion(ctx context.Context, req *model.CreateGiftPromotionRequest, adminID uuid.UUID) (*model.GiftPromotion, error)
+	ListGiftPromotions(ctx context.Context, filter *model.ListGiftPromotionsFilter) ([]*model.GiftPromotion, int, error)
+	UpdateGiftPromotionStatus(ctx context.Context, id uuid.UUID, isActive bool) error
+	DeleteGiftPromotion(ctx context.Context, id uuid.UUID) error
+	// ImportPromotionUsage backfill promotion_usage từ CSV hệ thống cũ (user / order / code)
+	ImportPromotionUsage(ctx context.Context, adminID uuid.UUID, req model.ImportPromotionUsageRequest, fileName string, file io.Reader) (*model.PromotionUsageImportResult, error)
 	// Internal methods (called by Order service)
 	RecordUsage(ctx context.Context, orderID, promoID, userID uuid.UUID, discountAmount interface{}) error
 	CalculateDiscount(promo *model.Promotion, subtotal decimal.Decimal) decimal.Decimal
@@ -14,6 +14,7 @@ import (
 	cartService "bookstore-backend/internal/domains/cart/service"
 	"bookstore-backend/internal/domains/promotion/model"
 	"bookstore-backend/internal/domains/promotion/repository"
+	"bookstore-backend/pkg/database"
 )
 
 // PromotionService xử lý business logic cho promotion
@@ -187,16 +188,19 @@ func (s *promotionService) ApplyPromotionToCart(
 		return nil, errors.New("Giỏ hàng trống, không thể áp dụng mã giảm giá")
 	}
 
-	// Step 2: Build cart items for validation
-	cartItems := make([]model.CartItem, len(cart.Items))
+	// Step 2: Build cart items for validation (bỏ dòng quà 0đ: không tính điều kiện / giảm giá)
+	cartItems := make([]model.CartItem, 0, len(cart.Items))
 	subtotal := decimal.Zero
 
-	for i, item := range cart.Items {
-		cartItems[i] = model.CartItem{
+	for _, item := range cart.Items {
+		if item.IsGift {
+			continue
+		}
+		cartItems = append(cartItems, model.CartItem{
 			BookID:   item.BookID,
 			Price:    item.Price,
 			Quantity: item.Quantity,
-		}
+		})
 
 		itemSubtotal := item.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
 		subtotal = subtotal.Add(itemSubtotal)
@@ -210,22 +214,19 @@ func (s *promotionService) ApplyPromotionToCart(
 		UserID:    &userID,
 	}
 
-	validationResult, err := s.ValidatePromotion(ctx, validateReq)
-	if err != nil {
+	if _, err := s.ValidatePromotion(ctx, validateReq); err != nil {
 		return nil, err
 	}
-	fmt.Printf("validationResult %w", validationResult)
 
 	// Step 4: Store promo in cart
-	c, err := s.cart.ApplyPromoCode(ctx, cart.ID, code, userID)
-	if err != nil {
+	if _, err := s.cart.ApplyPromoCode(ctx, cart.ID, code, userID); err != nil {
 		return nil, fmt.Errorf("apply promotion to cart: %w", err)
 	}
 
 	// Step 5: Get updated cart
 	updatedCart, err := s.cart.GetOrCreateCart(ctx, &userID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("get updated cart: %w", c)
+		return nil, fmt.Errorf("get updated cart: %w", err)
 	}
 	return updatedCart, nil
 }
@@ -258,7 +259,7 @@ func (s *promotionService) UpdatePromotion(
 	if err != nil {
 		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
-	defer tx.Rollback(ctx)
+	defer database.Rollback(ctx, tx)
 
 	// Get current promotion
 	existing, err := s.repo.FindByID(ctx, id)
@@ -350,6 +351,26 @@ func (s *promotionService) UpdatePromotion(
 		hasChanges = true
 	}
 
+	if req.IsAutomatic != nil {
+		updated.IsAutomatic = *req.IsAutomatic
+		hasChanges = true
+	}
+
+	if req.Stackable != nil {
+		updated.Stackable = *req.Stackable
+		hasChanges = true
+	}
+
+	if req.Priority != nil {
+		updated.Priority = *req.Priority
+		hasChanges = true
+	}
+
+	// Khớp CHECK chk_promotions_automatic_scope
+	if updated.IsAutomatic && (updated.IsTargeted || updated.FirstOrderOnly) {
+		return nil, errors.New("khuyến mãi tự động không hỗ trợ mã cá nhân hoặc chỉ áp dụng đơn đầu tiên")
+	}
+
 	// Nếu không có gì thay đổi
 	if !hasChanges {
 		return existing, nil
@@ -406,6 +427,20 @@ func (s *promotionService) ValidatePromotion(ctx context.Context, req *model.Val
 		return nil, err
 	}
 
+	// Mã cá nhân: user không được gán coi như mã không tồn tại (không lộ mã của người khác)
+	if promo.IsTargeted {
+		if req.UserID == nil || *req.UserID == uuid.Nil {
+			return nil, model.ErrPromotionNotFound
+		}
+		assigned, err := s.repo.IsPromotionAssigned(ctx, promo.ID, *req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("check promotion assignment: %w", err)
+		}
+		if !assigned {
+			return nil, model.ErrPromotionNotFound
+		}
+	}
+
 	// Step 2: Validate time window (double-check vì query đã filter)
 	now := time.Now()
 	if now.Before(promo.StartsAt) {
@@ -583,16 +618,16 @@ func (s *promotionService) GetAvailablePromotionsForCart(
 	}
 
 	var cartItems []model.CartItem
-	if len(cartInfo.Items) > 0 {
-		cartItems = make([]model.CartItem, len(cartInfo.Items))
-		for i, item := range cartInfo.Items {
-			cartItems[i] = model.CartItem{
-				Quantity:   item.Quantity,
-				BookID:     item.BookID,
-				Price:      item.Price,
-				CategoryID: *item.CategoryID,
-			}
+	for _, item := range cartInfo.Items {
+		if item.IsGift {
+			continue // Dòng quà 0đ không tính điều kiện danh mục
 		}
+		cartItems = append(cartItems, model.CartItem{
+			Quantity:   item.Quantity,
+			BookID:     item.BookID,
+			Price:      item.Price,
+			CategoryID: *item.CategoryID,
+		})
 	}
 
 	// Step 3: Filter promotions
@@ -681,6 +716,13 @@ func (s *promotionService) CreatePromotion(ctx context.Context, req *model.Creat
 			HTTPStatus: 400,
 		}
 	}
+	if req.IsAutomatic && (req.IsTargeted || req.FirstOrderOnly) {
+		return nil, &model.AppError{
+			Code:       model.ErrCodeValidationFailed,
+			Message:    "Khuyến mãi tự động không hỗ trợ mã cá nhân hoặc chỉ áp dụng đơn đầu tiên",
+			HTTPStatus: 400,
+		}
+	}
 	max_discount_amount := decimal.NewFromFloat(*req.MaxDiscountAmount)
 	// Build promotion model
 	promo := &model.Promotion{
@@ -698,6 +740,10 @@ func (s *promotionService) CreatePromotion(ctx context.Context, req *model.Creat
 		StartsAt:              startsAt,
 		ExpiresAt:             expiresAt,
 		IsActive:              req.IsActive,
+		IsTargeted:            req.IsTargeted,
+		IsAutomatic:           req.IsAutomatic,
+		Stackable:             req.Stackable,
+		Priority:              req.Priority,
 	}
 
 	// Create in DB
@@ -747,6 +793,10 @@ func (s *promotionService) GetPromotionByID(ctx context.Context, id uuid.UUID) (
 		StartsAt:              promo.StartsAt,
 		ExpiresAt:             promo.ExpiresAt,
 		IsActive:              promo.IsActive,
+		IsTargeted:            promo.IsTargeted,
+		IsAutomatic:           promo.IsAutomatic,
+		Stackable:             promo.Stackable,
+		Priority:              promo.Priority,
 		Version:               promo.Version,
 		CreatedAt:             promo.CreatedAt,
 		UpdatedAt:             promo.UpdatedAt,
@@ -867,7 +917,7 @@ func (s *promotionService) RecordUsage(
 	}
 
 	if err := s.repo.CreateUsage(ctx, tx, usage); err != nil {
-		tx.Rollback(ctx) // rollback ngay khi lỗi
+		database.Rollback(ctx, tx) // rollback ngay khi lỗi
 		return fmt.Errorf("create usage failed: %w", err)
 	}
 
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/promotion/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// ImportPromotionUsage backfill promotion_usage từ CSV hệ thống cũ (chuyển dữ liệu cửa hàng)
+//
+// Flow:
+// 1. Parse CSV (promotion_code, order_number, user_email | user_id, discount_amount?, used_at?)
+// 2. Đối chiếu cả file với promotions / orders / users (đơn đúng user, chưa gắn mã khác)
+// 3. Cặp (mã, đơn) đã có usage hoặc lặp trong file → duplicate, bỏ qua
+// 4. Có dòng lỗi mà không bật skip_invalid → không ghi gì; dry_run → chỉ trả kết quả validate
+// 5. Ghi usage + tăng current_uses trong 1 transaction
+func (s *promotionService) ImportPromotionUsage(
+	ctx context.Context,
+	adminID uuid.UUID,
+	req model.ImportPromotionUsageRequest,
+	fileName string,
+	file io.Reader,
+) (*model.PromotionUsageImportResult, error) {
+	lines, err := parseUsageImportCSV(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrUsageImportInvalidFile, err)
+	}
+
+	codes, orderNumbers, emails := usageImportKeys(lines)
+	lookup, err := s.repo.GetUsageImportLookup(ctx, codes, orderNumbers, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.PromotionUsageImportResult{
+		FileName:    fileName,
+		DryRun:      req.DryRun,
+		SkipInvalid: req.SkipInvalid,
+		TotalRows:   len(lines),
+		Errors:      make([]model.UsageImportRowError, 0),
+		Promotions:  make([]model.UsageImportPromotionStat, 0),
+	}
+
+	usages := make([]model.PromotionUsage, 0, len(lines))
+	inFile := make(map[model.UsageKey]struct{}, len(lines))
+	for _, line := range lines {
+		usage, reason := validateUsageImportLine(line, lookup)
+		if reason != "" {
+			result.InvalidRows++
+			if len(result.Errors) < model.UsageImportMaxErrors {
+				result.Errors = append(result.Errors, model.UsageImportRowError{
+					LineNo:        line.LineNo,
+					PromotionCode: line.PromotionCode,
+					OrderNumber:   line.OrderNumber,
+					Reason:        reason,
+				})
+			}
+			continue
+		}
+
+		key := model.UsageKey{PromotionID: usage.PromotionID, OrderID: usage.OrderID}
+		if _, ok := lookup.ExistingUsages[key]; ok {
+			result.DuplicateRows++
+			continue
+		}
+		if _, ok := inFile[key]; ok {
+			result.DuplicateRows++
+			continue
+		}
+		inFile[key] = struct{}{}
+		usages = append(usages, *usage)
+	}
+
+	if result.InvalidRows > 0 && !req.SkipInvalid && !req.DryRun {
+		return result, model.ErrUsageImportHasErrors
+	}
+
+	if req.DryRun || len(usages) == 0 {
+		// Dry run (hoặc không còn dòng nào để ghi): current_uses dự kiến sau import
+		imported := make(map[uuid.UUID]int)
+		for _, u := range usages {
+			imported[u.PromotionID]++
+		}
+		result.ImportedRows = len(usages)
+		result.Promotions = buildUsageImportStats(lookup, imported, nil)
+		return result, nil
+	}
+
+	imp := &model.PromotionUsageImport{
+		ID:            uuid.New(),
+		FileName:      fileName,
+		TotalRows:     result.TotalRows,
+		DuplicateRows: result.DuplicateRows,
+		InvalidRows:   result.InvalidRows,
+		SkipInvalid:   req.SkipInvalid,
+		ImportedBy:    adminID,
+	}
+	imported, currentUses, err := s.repo.ImportUsages(ctx, imp, usages)
+	if err != nil {
+		return nil, err
+	}
+
+	result.ImportID = &imp.ID
+	result.ImportedRows = imp.ImportedRows
+	result.DuplicateRows = imp.DuplicateRows
+	result.Promotions = buildUsageImportStats(lookup, imported, currentUses)
+
+	logger.Info("Promotion usage imported", map[string]interface{}{
+		"import_id":  imp.ID,
+		"file_name":  fileName,
+		"total":      imp.TotalRows,
+		"imported":   imp.ImportedRows,
+		"duplicates": imp.DuplicateRows,
+		"invalid":    imp.InvalidRows,
+		"admin_id":   adminID,
+	})
+
+	return result, nil
+}
+
+// validateUsageImportLine đối chiếu 1 dòng, trả về usage hoặc lý do loại
+func validateUsageImportLine(line model.UsageImportLine, lookup *model.UsageImportLookup) (*model.PromotionUsage, string) {
+	promo, ok := lookup.Promotions[strings.ToLower(line.PromotionCode)]
+	if !ok {
+		return nil, "promotion code not found"
+	}
+	order, ok := lookup.Orders[line.OrderNumber]
+	if !ok {
+		return nil, "order not found"
+	}
+
+	userID := order.UserID
+	switch {
+	case line.UserID != nil:
+		userID = *line.UserID
+	case line.UserEmail != nil:
+		id, ok := lookup.UsersByEmail[strings.ToLower(*line.UserEmail)]
+		if !ok {
+			return nil, "user email not found"
+		}
+		userID = id
+	}
+	if userID != order.UserID {
+		return nil, "order does not belong to this user"
+	}
+
+	if order.PromotionID != nil && *order.PromotionID != promo.ID {
+		return nil, "order already uses a different promotion"
+	}
+
+	amount := order.DiscountAmount
+	if line.DiscountAmount != nil {
+		amount = *line.DiscountAmount
+	}
+
+	usedAt := order.CreatedAt
+	if line.UsedAt != nil {
+		usedAt = *line.UsedAt
+	}
+
+	return &model.PromotionUsage{
+		ID:             uuid.New(),
+		PromotionID:    promo.ID,
+		UserID:         userID,
+		OrderID:        order.ID,
+		DiscountAmount: amount,
+		UsedAt:         usedAt,
+	}, ""
+}
+
+// buildUsageImportStats currentUses nil (dry run) → current_uses dự kiến = hiện tại + số sẽ import
+func buildUsageImportStats(
+	lookup *model.UsageImportLookup,
+	imported map[uuid.UUID]int,
+	currentUses map[uuid.UUID]int,
+) []model.UsageImportPromotionStat {
+	stats := make([]model.UsageImportPromotionStat, 0, len(imported))
+	for _, promo := range lookup.Promotions {
+		n, ok := imported[promo.ID]
+		if !ok {
+			continue
+		}
+		uses := promo.CurrentUses + n
+		if currentUses != nil {
+			uses = currentUses[promo.ID]
+		}
+		stats = append(stats, model.UsageImportPromotionStat{
+			PromotionID:    promo.ID,
+			Code:           promo.Code,
+			Imported:       n,
+			CurrentUses:    uses,
+			MaxUses:        promo.MaxUses,
+			ExceedsMaxUses: promo.MaxUses != nil && uses > *promo.MaxUses,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Code < stats[j].Code })
+	return stats
+}
+
+func usageImportKeys(lines []model.UsageImportLine) (codes, orderNumbers, emails []string) {
+	seenCode := make(map[string]struct{})
+	seenOrder := make(map[string]struct{})
+	seenEmail := make(map[string]struct{})
+	for _, line := range lines {
+		code := strings.ToLower(line.PromotionCode)
+		if _, ok := seenCode[code]; !ok {
+			seenCode[code] = struct{}{}
+			codes = append(codes, code)
+		}
+		if _, ok := seenOrder[line.OrderNumber]; !ok {
+			seenOrder[line.OrderNumber] = struct{}{}
+			orderNumbers = append(orderNumbers, line.OrderNumber)
+		}
+		if line.UserEmail != nil {
+			email := strings.ToLower(*line.UserEmail)
+			if _, ok := seenEmail[email]; !ok {
+				seenEmail[email] = struct{}{}
+				emails = append(emails, email)
+			}
+		}
+	}
+	return codes, orderNumbers, emails
+}
+
+// parseUsageImportCSV header bắt buộc: promotion_code (hoặc code), order_number, user_email và/hoặc user_id
+// Tuỳ chọn: discount_amount (chấp nhận 150,000), used_at (RFC3339 hoặc YYYY-MM-DD)
+func parseUsageImportCSV(file io.Reader) ([]model.UsageImportLine, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file is empty")
+		}
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["promotion_code"]; !ok {
+		if i, ok := columns["code"]; ok {
+			columns["promotion_code"] = i
+		}
+	}
+
+	codeCol, hasCode := columns["promotion_code"]
+	orderCol, hasOrder := columns["order_number"]
+	emailCol, hasEmail := columns["user_email"]
+	userCol, hasUser := columns["user_id"]
+	amountCol, hasAmount := columns["discount_amount"]
+	usedAtCol, hasUsedAt := columns["used_at"]
+	if !hasCode || !hasOrder || (!hasEmail && !hasUser) {
+		return nil, fmt.Errorf("header must contain promotion_code, order_number and user_email or user_id")
+	}
+
+	field := func(record []string, col int, ok bool) *string {
+		if !ok || col >= len(record) {
+			return nil
+		}
+		value := strings.TrimSpace(record[col])
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+
+	lines := make([]model.UsageImportLine, 0)
+	for lineNo := 1; ; lineNo++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if len(lines) >= model.UsageImportMaxRows {
+			return nil, fmt.Errorf("file exceeds %d lines", model.UsageImportMaxRows)
+		}
+
+		code := field(record, codeCol, true)
+		orderNumber := field(record, orderCol, true)
+		if code == nil || orderNumber == nil {
+			return nil, fmt.Errorf("line %d: promotion_code and order_number are required", lineNo)
+		}
+
+		line := model.UsageImportLine{
+			LineNo:        lineNo,
+			PromotionCode: *code,
+			OrderNumber:   *orderNumber,
+			UserEmail:     field(record, emailCol, hasEmail),
+		}
+
+		if raw := field(record, userCol, hasUser); raw != nil {
+			id, err := uuid.Parse(*raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid user_id %q", lineNo, *raw)
+			}
+			line.UserID = &id
+		}
+		if line.UserID == nil && line.UserEmail == nil {
+			return nil, fmt.Errorf("line %d: user_email or user_id is required", lineNo)
+		}
+
+		if raw := field(record, amountCol, hasAmount); raw != nil {
+			amount, err := decimal.NewFromString(strings.ReplaceAll(*raw, ",", ""))
+			if err != nil || amount.IsNegative() {
+				return nil, fmt.Errorf("line %d: invalid discount_amount %q", lineNo, *raw)
+			}
+			line.DiscountAmount = &amount
+		}
+
+		if raw := field(record, usedAtCol, hasUsedAt); raw != nil {
+			usedAt, err := parseUsageImportTime(*raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid used_at %q", lineNo, *raw)
+			}
+			line.UsedAt = &usedAt
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file has no usage lines")
+	}
+
+	return lines, nil
+}
+
+func parseUsageImportTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/promotion/model"
+)
+
+// GetPromoWallet ví mã ("my coupons") cho checkout UI
+//
+// Flow:
+// 1. Lấy giỏ hàng hiện tại của user
+// 2. Lấy mã user còn dùng được (mã được gán + mã công khai, đã lọc hết lượt / hết hạn) - 1 query
+// 3. Kiểm tra từng mã với giỏ (min order, category) và ước tính số tiền giảm, không query thêm
+// 4. Sắp xếp: dùng được trước, giảm nhiều trước, sắp hết hạn trước
+func (s *promotionService) GetPromoWallet(ctx context.Context, userID uuid.UUID) (*model.PromoWalletResponse, error) {
+	cartInfo, err := s.cart.GetOrCreateCart(ctx, &userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get cart: %w", err)
+	}
+
+	subtotal := decimal.Zero
+	categoryIDs := make([]uuid.UUID, 0, len(cartInfo.Items))
+	for _, item := range cartInfo.Items {
+		if item.IsGift {
+			continue // Dòng quà 0đ không tính điều kiện danh mục
+		}
+		subtotal = subtotal.Add(item.Price.Mul(decimal.NewFromInt(int64(item.Quantity))))
+		if item.CategoryID != nil {
+			categoryIDs = append(categoryIDs, *item.CategoryID)
+		}
+	}
+
+	promotions, err := s.repo.ListWalletPromotions(ctx, userID, model.WalletMaxPromotions)
+	if err != nil {
+		return nil, err
+	}
+
+	coupons := make([]model.WalletCoupon, 0, len(promotions))
+	for _, w := range promotions {
+		promo := w.Promotion
+		coupon := model.WalletCoupon{
+			Code:              promo.Code,
+			Name:              promo.Name,
+			Description:       promo.Description,
+			DiscountType:      string(promo.DiscountType),
+			DiscountValue:     promo.DiscountValue,
+			MaxDiscountAmount: promo.MaxDiscountAmount,
+			MinOrderAmount:    promo.MinOrderAmount,
+			ExpiresAt:         promo.ExpiresAt,
+			Source:            model.WalletSourcePublic,
+			UserRemainingUses: promo.MaxUsesPerUser - w.UserUsageCount,
+			Applied:           cartInfo.PromoCode != nil && strings.EqualFold(*cartInfo.PromoCode, promo.Code),
+			EstimatedDiscount: decimal.Zero,
+		}
+		if w.Assigned {
+			coupon.Source = model.WalletSourceAssigned
+		}
+
+		switch {
+		case len(cartInfo.Items) == 0 || subtotal.LessThan(promo.MinOrderAmount):
+			needed := promo.MinOrderAmount.Sub(subtotal)
+			coupon.IneligibleCode = model.ErrCodePromoMinOrderNotMet
+			coupon.IneligibleReason = fmt.Sprintf("Đơn hàng chưa đạt giá trị tối thiểu %s VND", promo.MinOrderAmount.String())
+			if needed.IsPositive() {
+				coupon.AmountNeeded = &needed
+			}
+		case len(promo.ApplicableCategoryIDs) > 0 && !containsAnyUUID(promo.ApplicableCategoryIDs, categoryIDs):
+			coupon.IneligibleCode = model.ErrCodePromoCategoryNotApplicable
+			coupon.IneligibleReason = "Mã giảm giá không áp dụng cho sản phẩm trong giỏ hàng"
+		default:
+			coupon.Eligible = true
+			coupon.EstimatedDiscount = s.calculator.Calculate(promo, subtotal)
+		}
+
+		coupons = append(coupons, coupon)
+	}
+
+	sort.SliceStable(coupons, func(i, j int) bool {
+		if coupons[i].Eligible != coupons[j].Eligible {
+			return coupons[i].Eligible
+		}
+		if !coupons[i].EstimatedDiscount.Equal(coupons[j].EstimatedDiscount) {
+			return coupons[i].EstimatedDiscount.GreaterThan(coupons[j].EstimatedDiscount)
+		}
+		return coupons[i].ExpiresAt.Before(coupons[j].ExpiresAt)
+	})
+
+	return &model.PromoWalletResponse{
+		CartSubtotal: subtotal,
+		AppliedCode:  cartInfo.PromoCode,
+		Coupons:      coupons,
+	}, nil
+}
+
+// AssignPromotion gán mã cho danh sách user (mã cá nhân hiện trong ví của họ)
+func (s *promotionService) AssignPromotion(
+	ctx context.Context,
+	promoID uuid.UUID,
+	req *model.AssignPromotionRequest,
+	adminID uuid.UUID,
+) (*model.AssignPromotionResult, error) {
+	if len(req.UserIDs) > model.AssignmentMaxUsersPerCall {
+		return nil, &model.AppError{
+			Code:       model.ErrCodeValidationFailed,
+			Message:    fmt.Sprintf("Tối đa %d user mỗi lần gán", model.AssignmentMaxUsersPerCall),
+			HTTPStatus: 400,
+		}
+	}
+
+	// Kiểm tra promotion tồn tại
+	if _, err := s.repo.FindByID(ctx, promoID); err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.repo.AssignPromotion(ctx, promoID, req.UserIDs, req.Note, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AssignPromotionResult{
+		PromotionID: promoID,
+		Requested:   len(req.UserIDs),
+		Assigned:    assigned,
+	}, nil
+}
+
+// UnassignPromotion thu hồi mã đã gán cho user
+func (s *promotionService) UnassignPromotion(ctx context.Context, promoID, userID uuid.UUID) error {
+	return s.repo.UnassignPromotion(ctx, promoID, userID)
+}
+
+// containsAnyUUID kiểm tra 2 slice có phần tử chung không
+func containsAnyUUID(slice []uuid.UUID, items []uuid.UUID) bool {
+	for _, item := range items {
+		if containsUUID(slice, item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/question/model"
+	"bookstore-backend/internal/domains/question/service"
+)
+
+// =====================================================
+// QUESTION HANDLER
+// =====================================================
+
+type QuestionHandler struct {
+	questionService service.ServiceInterface
+}
+
+func NewQuestionHandler(questionService service.ServiceInterface) *QuestionHandler {
+	return &QuestionHandler{
+		questionService: questionService,
+	}
+}
+
+// =====================================================
+// PUBLIC ENDPOINTS
+// =====================================================
+
+// ListQuestions lists published questions of a book
+// GET /api/v1/questions?book_id=
+func (h *QuestionHandler) ListQuestions(c *gin.Context) {
+	var req model.ListQuestionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.questionService.ListQuestions(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetQuestion gets question with answers
+// GET /api/v1/questions/:id
+func (h *QuestionHandler) GetQuestion(c *gin.Context) {
+	questionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid question ID")
+		return
+	}
+
+	var req model.ListAnswersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.questionService.GetQuestion(c.Request.Context(), questionID, req)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// =====================================================
+// USER ENDPOINTS
+// =====================================================
+
+// AskQuestion creates question
+// POST /api/v1/questions
+func (h *QuestionHandler) AskQuestion(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.CreateQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.questionService.AskQuestion(c.Request.Context(), userID, req)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// AnswerQuestion answers question (staff or verified buyer)
+// POST /api/v1/questions/:id/answers
+func (h *QuestionHandler) AnswerQuestion(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	questionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid question ID")
+		return
+	}
+
+	var req model.CreateAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	role := c.GetString("role")
+	response, err := h.questionService.AnswerQuestion(c.Request.Context(), userID, role, questionID, req)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// UpvoteAnswer upvotes answer
+// POST /api/v1/questions/answers/:answer_id/upvote
+func (h *QuestionHandler) UpvoteAnswer(c *gin.Context) {
+	userID, answerID, ok := parseUpvoteParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.questionService.UpvoteAnswer(c.Request.Context(), userID, answerID)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// RemoveUpvote removes upvote
+// DELETE /api/v1/questions/answers/:answer_id/upvote
+func (h *QuestionHandler) RemoveUpvote(c *gin.Context) {
+	userID, answerID, ok := parseUpvoteParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.questionService.RemoveUpvote(c.Request.Context(), userID, answerID)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// =====================================================
+// ADMIN ENDPOINTS
+// =====================================================
+
+// AdminListQuestions lists questions (including hidden)
+// GET /api/v1/admin/questions
+func (h *QuestionHandler) AdminListQuestions(c *gin.Context) {
+	var req model.AdminListQuestionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.questionService.AdminListQuestions(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AdminGetQuestion gets question with all answers
+// GET /api/v1/admin/questions/:id
+func (h *QuestionHandler) AdminGetQuestion(c *gin.Context) {
+	questionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid question ID")
+		return
+	}
+
+	var req model.ListAnswersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.questionService.AdminGetQuestion(c.Request.Context(), questionID, req)
+	if err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AdminUpdateQuestionStatus hides/publishes question
+// PATCH /api/v1/admin/questions/:id/status
+func (h *QuestionHandler) AdminUpdateQuestionStatus(c *gin.Context) {
+	questionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid question ID")
+		return
+	}
+
+	var req model.UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.questionService.AdminUpdateQuestionStatus(c.Request.Context(), questionID, req); err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Question status updated successfully",
+	})
+}
+
+// AdminUpdateAnswerStatus hides/publishes answer
+// PATCH /api/v1/admin/questions/answers/:answer_id/status
+func (h *QuestionHandler) AdminUpdateAnswerStatus(c *gin.Context) {
+	answerID, err := uuid.Parse(c.Param("answer_id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid answer ID")
+		return
+	}
+
+	var req model.UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.questionService.AdminUpdateAnswerStatus(c.Request.Context(), answerID, req); err != nil {
+		statusCode, errCode := mapQuestionError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Answer status updated successfully",
+	})
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// getUserID extracts user ID from JWT claims
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, model.ErrNotEligible
+	}
+
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrNotEligible
+	}
+}
+
+func parseUpvoteParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	answerID, err := uuid.Parse(c.Param("answer_id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid answer ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, answerID, true
+}
+
+// respondSuccess sends success response
+func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// respondError sends error response
+func respondError(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// mapQuestionError maps question error to HTTP status code
+func mapQuestionError(err error) (int, string) {
+	if qErr, ok := err.(*model.QuestionError); ok {
+		switch qErr.Code {
+		case model.ErrCodeQuestionNotFound, model.ErrCodeAnswerNotFound, model.ErrCodeBookNotFound:
+			return http.StatusNotFound, qErr.Code
+		case model.ErrCodeNotEligible, model.ErrCodeCannotUpvote:
+			return http.StatusForbidden, qErr.Code
+		case model.ErrCodeInvalidContent, model.ErrCodeInvalidStatus:
+			return http.StatusBadRequest, qErr.Code
+		default:
+			return http.StatusInternalServerError, "INTERNAL_ERROR"
+		}
+	}
+	return http.StatusInternalServerError, "INTERNAL_ERROR"
+}
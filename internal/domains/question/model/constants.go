@@ -0,0 +1,25 @@
+package model
+
+// Question/answer statuses
+const (
+	StatusPublished = "published"
+	StatusHidden    = "hidden"
+)
+
+// Pagination defaults
+const (
+	DefaultPage  = 1
+	DefaultLimit = 10
+	MaxLimit     = 50
+)
+
+// Content limits
+const (
+	MinQuestionLength = 10
+	MaxQuestionLength = 1000
+	MinAnswerLength   = 2
+	MaxAnswerLength   = 2000
+)
+
+// StaffRoles - role được trả lời với badge nhân viên (không cần mua hàng)
+var StaffRoles = []string{"admin", "cskh"}
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// =====================================================
+// USER REQUEST DTOs
+// =====================================================
+
+// CreateQuestionRequest request to ask question on a book
+type CreateQuestionRequest struct {
+	BookID  uuid.UUID `json:"book_id" binding:"required"`
+	Content string    `json:"content" binding:"required"`
+}
+
+func (r *CreateQuestionRequest) Validate() error {
+	r.Content = strings.TrimSpace(r.Content)
+	n := utf8.RuneCountInString(r.Content)
+	if n < MinQuestionLength || n > MaxQuestionLength {
+		return NewInvalidContentError(fmt.Sprintf("question must be between %d and %d characters", MinQuestionLength, MaxQuestionLength))
+	}
+	return nil
+}
+
+// CreateAnswerRequest request to answer question
+type CreateAnswerRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+func (r *CreateAnswerRequest) Validate() error {
+	r.Content = strings.TrimSpace(r.Content)
+	n := utf8.RuneCountInString(r.Content)
+	if n < MinAnswerLength || n > MaxAnswerLength {
+		return NewInvalidContentError(fmt.Sprintf("answer must be between %d and %d characters", MinAnswerLength, MaxAnswerLength))
+	}
+	return nil
+}
+
+// ListQuestionsRequest public request to list questions of a book
+type ListQuestionsRequest struct {
+	BookID uuid.UUID `form:"book_id" binding:"required"`
+	Page   int       `form:"page"`
+	Limit  int       `form:"limit"`
+}
+
+func (r *ListQuestionsRequest) Validate() error {
+	r.Page, r.Limit = normalizePagination(r.Page, r.Limit)
+	return nil
+}
+
+// ListAnswersRequest pagination for answers of a question
+type ListAnswersRequest struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
+
+func (r *ListAnswersRequest) Validate() error {
+	r.Page, r.Limit = normalizePagination(r.Page, r.Limit)
+	return nil
+}
+
+// =====================================================
+// ADMIN REQUEST DTOs
+// =====================================================
+
+// AdminListQuestionsRequest admin request to list questions
+type AdminListQuestionsRequest struct {
+	BookID *uuid.UUID `form:"book_id"`
+	Status *string    `form:"status"`
+	Page   int        `form:"page"`
+	Limit  int        `form:"limit"`
+}
+
+func (r *AdminListQuestionsRequest) Validate() error {
+	if r.Status != nil && !IsValidStatus(*r.Status) {
+		return NewInvalidStatusError()
+	}
+	r.Page, r.Limit = normalizePagination(r.Page, r.Limit)
+	return nil
+}
+
+// UpdateStatusRequest admin request to hide/publish question or answer
+type UpdateStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+func (r *UpdateStatusRequest) Validate() error {
+	if !IsValidStatus(r.Status) {
+		return NewInvalidStatusError()
+	}
+	return nil
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// QuestionResponse question
+type QuestionResponse struct {
+	ID          uuid.UUID `json:"id"`
+	BookID      uuid.UUID `json:"book_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Content     string    `json:"content"`
+	Status      string    `json:"status,omitempty"`
+	AnswerCount int       `json:"answer_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AnswerResponse answer
+type AnswerResponse struct {
+	ID              uuid.UUID `json:"id"`
+	QuestionID      uuid.UUID `json:"question_id"`
+	UserID          uuid.UUID `json:"user_id"`
+	Content         string    `json:"content"`
+	IsStaff         bool      `json:"is_staff"`
+	IsVerifiedBuyer bool      `json:"is_verified_buyer"`
+	Status          string    `json:"status,omitempty"`
+	UpvoteCount     int       `json:"upvote_count"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ListQuestionsResponse paginated questions
+type ListQuestionsResponse struct {
+	Questions  []QuestionResponse `json:"questions"`
+	Pagination PaginationMeta     `json:"pagination"`
+}
+
+// QuestionDetailResponse question with paginated answers
+type QuestionDetailResponse struct {
+	Question   QuestionResponse `json:"question"`
+	Answers    []AnswerResponse `json:"answers"`
+	Pagination PaginationMeta   `json:"pagination"`
+}
+
+// UpvoteResponse upvote result
+type UpvoteResponse struct {
+	AnswerID    uuid.UUID `json:"answer_id"`
+	UpvoteCount int       `json:"upvote_count"`
+	Upvoted     bool      `json:"upvoted"`
+}
+
+// PaginationMeta pagination metadata
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
+
+// NewPaginationMeta builds pagination metadata
+func NewPaginationMeta(page, limit, total int) PaginationMeta {
+	totalPages := (total + limit - 1) / limit
+	return PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+// IsValidStatus checks question/answer status
+func IsValidStatus(status string) bool {
+	return status == StatusPublished || status == StatusHidden
+}
+
+func normalizePagination(page, limit int) (int, int) {
+	if page < 1 {
+		page = DefaultPage
+	}
+	if limit < 1 || limit > MaxLimit {
+		limit = DefaultLimit
+	}
+	return page, limit
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Question represents a customer question on a book page
+type Question struct {
+	ID          uuid.UUID `json:"id"`
+	BookID      uuid.UUID `json:"book_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Content     string    `json:"content"`
+	Status      string    `json:"status"` // published, hidden
+	AnswerCount int       `json:"answer_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Answer represents an answer from staff or verified buyer
+type Answer struct {
+	ID              uuid.UUID `json:"id"`
+	QuestionID      uuid.UUID `json:"question_id"`
+	UserID          uuid.UUID `json:"user_id"`
+	Content         string    `json:"content"`
+	IsStaff         bool      `json:"is_staff"`
+	IsVerifiedBuyer bool      `json:"is_verified_buyer"`
+	Status          string    `json:"status"` // published, hidden
+	UpvoteCount     int       `json:"upvote_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// IsPublished checks if question is visible to public
+func (q *Question) IsPublished() bool {
+	return q.Status == StatusPublished
+}
+
+// IsPublished checks if answer is visible to public
+func (a *Answer) IsPublished() bool {
+	return a.Status == StatusPublished
+}
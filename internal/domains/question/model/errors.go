@@ -0,0 +1,99 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodeQuestionNotFound = "QNA001"
+	ErrCodeAnswerNotFound   = "QNA002"
+	ErrCodeNotEligible      = "QNA003"
+	ErrCodeInvalidContent   = "QNA004"
+	ErrCodeInvalidStatus    = "QNA005"
+	ErrCodeCannotUpvote     = "QNA006"
+	ErrCodeBookNotFound     = "QNA007"
+)
+
+// Errors
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrNotEligible      = errors.New("not eligible to answer")
+	ErrInvalidContent   = errors.New("invalid content")
+	ErrInvalidStatus    = errors.New("invalid status")
+	ErrCannotUpvote     = errors.New("cannot upvote this answer")
+	ErrBookNotFound     = errors.New("book not found")
+)
+
+// QuestionError custom error type
+type QuestionError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *QuestionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Error constructors
+func NewQuestionNotFoundError() *QuestionError {
+	return &QuestionError{
+		Code:    ErrCodeQuestionNotFound,
+		Message: "Question not found",
+		Err:     ErrQuestionNotFound,
+	}
+}
+
+func NewAnswerNotFoundError() *QuestionError {
+	return &QuestionError{
+		Code:    ErrCodeAnswerNotFound,
+		Message: "Answer not found",
+		Err:     ErrAnswerNotFound,
+	}
+}
+
+func NewNotEligibleError() *QuestionError {
+	return &QuestionError{
+		Code:    ErrCodeNotEligible,
+		Message: "Only staff or customers who purchased this book can answer",
+		Err:     ErrNotEligible,
+	}
+}
+
+func NewInvalidContentError(message string) *QuestionError {
+	return &QuestionError{
+		Code:    ErrCodeInvalidContent,
+		Message: message,
+		Err:     ErrInvalidContent,
+	}
+}
+
+func NewInvalidStatusError() *QuestionError {
+	return &QuestionError{
+		Code:    ErrCodeInvalidStatus,
+		Message: "Status must be one of: published, hidden",
+		Err:     ErrInvalidStatus,
+	}
+}
+
+func NewCannotUpvoteError(message string) *QuestionError {
+	return &QuestionError{
+		Code:    ErrCodeCannotUpvote,
+		Message: message,
+		Err:     ErrCannotUpvote,
+	}
+}
+
+func NewBookNotFoundError() *QuestionError {
+	return &QuestionError{
+		Code:    ErrCodeBookNotFound,
+		Message: "Book not found",
+		Err:     ErrBookNotFound,
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/question/model"
+)
+
+// =====================================================
+// QUESTION REPOSITORY INTERFACE
+// =====================================================
+
+type QuestionRepository interface {
+	// ========================================
+	// QUESTIONS
+	// ========================================
+
+	// CreateQuestion creates new question
+	CreateQuestion(ctx context.Context, question *model.Question) error
+
+	// GetQuestionByID gets question by ID
+	GetQuestionByID(ctx context.Context, id uuid.UUID) (*model.Question, error)
+
+	// ListQuestions lists questions with filters (book_id, status)
+	ListQuestions(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*model.Question, int, error)
+
+	// UpdateQuestionStatus hides/publishes question
+	UpdateQuestionStatus(ctx context.Context, id uuid.UUID, status string) error
+
+	// ========================================
+	// ANSWERS
+	// ========================================
+
+	// CreateAnswer creates answer and refreshes question answer_count
+	CreateAnswer(ctx context.Context, answer *model.Answer) error
+
+	// GetAnswerByID gets answer by ID
+	GetAnswerByID(ctx context.Context, id uuid.UUID) (*model.Answer, error)
+
+	// ListAnswers lists answers of question (staff first, most upvoted first)
+	ListAnswers(ctx context.Context, questionID uuid.UUID, publishedOnly bool, page, limit int) ([]*model.Answer, int, error)
+
+	// UpdateAnswerStatus hides/publishes answer and refreshes answer_count
+	UpdateAnswerStatus(ctx context.Context, id uuid.UUID, status string) error
+
+	// ========================================
+	// UPVOTES
+	// ========================================
+
+	// AddUpvote upvotes answer (idempotent), returns current upvote count
+	AddUpvote(ctx context.Context, answerID, userID uuid.UUID) (int, error)
+
+	// RemoveUpvote removes upvote (idempotent), returns current upvote count
+	RemoveUpvote(ctx context.Context, answerID, userID uuid.UUID) (int, error)
+
+	// ========================================
+	// ELIGIBILITY
+	// ========================================
+
+	// BookExists checks book is available (not soft-deleted)
+	BookExists(ctx context.Context, bookID uuid.UUID) (bool, error)
+
+	// HasPurchased checks if user has purchased the book
+	HasPurchased(ctx context.Context, userID, bookID uuid.UUID) (bool, error)
+}
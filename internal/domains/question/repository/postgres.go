@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/question/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresQuestionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresQuestionRepository(pool *pgxpool.Pool) QuestionRepository {
+	return &postgresQuestionRepository{pool: pool}
+}
+
+// =====================================================
+// QUESTIONS
+// =====================================================
+
+func (r *postgresQuestionRepository) CreateQuestion(ctx context.Context, question *model.Question) error {
+	query := `
+		INSERT INTO product_questions (
+			id, book_id, user_id, content, status, answer_count, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		question.ID,
+		question.BookID,
+		question.UserID,
+		question.Content,
+		question.Status,
+		question.AnswerCount,
+		question.CreatedAt,
+		question.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create question: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresQuestionRepository) GetQuestionByID(ctx context.Context, id uuid.UUID) (*model.Question, error) {
+	query := `
+		SELECT id, book_id, user_id, content, status, answer_count, created_at, updated_at
+		FROM product_questions
+		WHERE id = $1
+	`
+
+	question := &model.Question{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&question.ID,
+		&question.BookID,
+		&question.UserID,
+		&question.Content,
+		&question.Status,
+		&question.AnswerCount,
+		&question.CreatedAt,
+		&question.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrQuestionNotFound
+		}
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+
+	return question, nil
+}
+
+func (r *postgresQuestionRepository) ListQuestions(
+	ctx context.Context,
+	filters map[string]interface{},
+	page, limit int,
+) ([]*model.Question, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if bookID, ok := filters["book_id"].(uuid.UUID); ok {
+		where += fmt.Sprintf(" AND book_id = $%d", argCount)
+		args = append(args, bookID)
+		argCount++
+	}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, status)
+		argCount++
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM product_questions" + where
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count questions: %w", err)
+	}
+
+	// Câu hỏi đã có trả lời lên trước, sau đó mới nhất trước
+	query := `
+		SELECT id, book_id, user_id, content, status, answer_count, created_at, updated_at
+		FROM product_questions` + where +
+		" ORDER BY (answer_count > 0) DESC, created_at DESC" +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*model.Question
+	for rows.Next() {
+		question := &model.Question{}
+		if err := rows.Scan(
+			&question.ID,
+			&question.BookID,
+			&question.UserID,
+			&question.Content,
+			&question.Status,
+			&question.AnswerCount,
+			&question.CreatedAt,
+			&question.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan question: %w", err)
+		}
+		questions = append(questions, question)
+	}
+
+	return questions, total, nil
+}
+
+func (r *postgresQuestionRepository) UpdateQuestionStatus(ctx context.Context, id uuid.UUID, status string) error {
+	query := `UPDATE product_questions SET status = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update question status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrQuestionNotFound
+	}
+
+	return nil
+}
+
+// =====================================================
+// ANSWERS
+// =====================================================
+
+func (r *postgresQuestionRepository) CreateAnswer(ctx context.Context, answer *model.Answer) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	query := `
+		INSERT INTO product_answers (
+			id, question_id, user_id, content,
+			is_staff, is_verified_buyer, status, upvote_count,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = tx.Exec(ctx, query,
+		answer.ID,
+		answer.QuestionID,
+		answer.UserID,
+		answer.Content,
+		answer.IsStaff,
+		answer.IsVerifiedBuyer,
+		answer.Status,
+		answer.UpvoteCount,
+		answer.CreatedAt,
+		answer.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create answer: %w", err)
+	}
+
+	if err := refreshAnswerCount(ctx, tx, answer.QuestionID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresQuestionRepository) GetAnswerByID(ctx context.Context, id uuid.UUID) (*model.Answer, error) {
+	query := `
+		SELECT id, question_id, user_id, content,
+			is_staff, is_verified_buyer, status, upvote_count,
+			created_at, updated_at
+		FROM product_answers
+		WHERE id = $1
+	`
+
+	answer := &model.Answer{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&answer.ID,
+		&answer.QuestionID,
+		&answer.UserID,
+		&answer.Content,
+		&answer.IsStaff,
+		&answer.IsVerifiedBuyer,
+		&answer.Status,
+		&answer.UpvoteCount,
+		&answer.CreatedAt,
+		&answer.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrAnswerNotFound
+		}
+		return nil, fmt.Errorf("failed to get answer: %w", err)
+	}
+
+	return answer, nil
+}
+
+func (r *postgresQuestionRepository) ListAnswers(
+	ctx context.Context,
+	questionID uuid.UUID,
+	publishedOnly bool,
+	page, limit int,
+) ([]*model.Answer, int, error) {
+	where := " WHERE question_id = $1"
+	if publishedOnly {
+		where += " AND status = 'published'"
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM product_answers"+where, questionID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count answers: %w", err)
+	}
+
+	query := `
+		SELECT id, question_id, user_id, content,
+			is_staff, is_verified_buyer, status, upvote_count,
+			created_at, updated_at
+		FROM product_answers` + where + `
+		ORDER BY is_staff DESC, upvote_count DESC, created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, questionID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list answers: %w", err)
+	}
+	defer rows.Close()
+
+	var answers []*model.Answer
+	for rows.Next() {
+		answer := &model.Answer{}
+		if err := rows.Scan(
+			&answer.ID,
+			&answer.QuestionID,
+			&answer.UserID,
+			&answer.Content,
+			&answer.IsStaff,
+			&answer.IsVerifiedBuyer,
+			&answer.Status,
+			&answer.UpvoteCount,
+			&answer.CreatedAt,
+			&answer.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan answer: %w", err)
+		}
+		answers = append(answers, answer)
+	}
+
+	return answers, total, nil
+}
+
+func (r *postgresQuestionRepository) UpdateAnswerStatus(ctx context.Context, id uuid.UUID, status string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	var questionID uuid.UUID
+	query := `
+		UPDATE product_answers SET status = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING question_id
+	`
+	if err := tx.QueryRow(ctx, query, id, status).Scan(&questionID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrAnswerNotFound
+		}
+		return fmt.Errorf("failed to update answer status: %w", err)
+	}
+
+	if err := refreshAnswerCount(ctx, tx, questionID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// refreshAnswerCount recalculates denormalized answer_count (published answers only)
+func refreshAnswerCount(ctx context.Context, tx pgx.Tx, questionID uuid.UUID) error {
+	query := `
+		UPDATE product_questions
+		SET answer_count = (
+			SELECT COUNT(*) FROM product_answers
+			WHERE question_id = $1 AND status = 'published'
+		), updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, questionID); err != nil {
+		return fmt.Errorf("failed to refresh answer count: %w", err)
+	}
+	return nil
+}
+
+// =====================================================
+// UPVOTES
+// =====================================================
+
+func (r *postgresQuestionRepository) AddUpvote(ctx context.Context, answerID, userID uuid.UUID) (int, error) {
+	return r.changeUpvote(ctx, answerID, userID,
+		`INSERT INTO product_answer_upvotes (answer_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		1,
+	)
+}
+
+func (r *postgresQuestionRepository) RemoveUpvote(ctx context.Context, answerID, userID uuid.UUID) (int, error) {
+	return r.changeUpvote(ctx, answerID, userID,
+		`DELETE FROM product_answer_upvotes WHERE answer_id = $1 AND user_id = $2`,
+		-1,
+	)
+}
+
+// changeUpvote chỉ cập nhật upvote_count khi vote thực sự thay đổi (idempotent)
+func (r *postgresQuestionRepository) changeUpvote(
+	ctx context.Context,
+	answerID, userID uuid.UUID,
+	voteQuery string,
+	delta int,
+) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	result, err := tx.Exec(ctx, voteQuery, answerID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to change upvote: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		delta = 0
+	}
+
+	var count int
+	query := `
+		UPDATE product_answers
+		SET upvote_count = GREATEST(upvote_count + $2, 0)
+		WHERE id = $1
+		RETURNING upvote_count
+	`
+	if err := tx.QueryRow(ctx, query, answerID, delta).Scan(&count); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, model.ErrAnswerNotFound
+		}
+		return 0, fmt.Errorf("failed to update upvote count: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return count, nil
+}
+
+// =====================================================
+// ELIGIBILITY
+// =====================================================
+
+func (r *postgresQuestionRepository) BookExists(ctx context.Context, bookID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM books WHERE id = $1 AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, bookID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check book: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *postgresQuestionRepository) HasPurchased(ctx context.Context, userID, bookID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM orders o
+			INNER JOIN order_items oi ON o.id = oi.order_id
+			WHERE o.user_id = $1
+			AND oi.book_id = $2
+			AND o.status IN ('confirmed', 'processing', 'shipped', 'delivered')
+		)
+	`
+
+	var purchased bool
+	if err := r.pool.QueryRow(ctx, query, userID, bookID).Scan(&purchased); err != nil {
+		return false, fmt.Errorf("failed to check purchase: %w", err)
+	}
+
+	return purchased, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/question/model"
+)
+
+// =====================================================
+// QUESTION SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ========================================
+	// PUBLIC / USER OPERATIONS
+	// ========================================
+
+	// AskQuestion creates question on a book
+	AskQuestion(ctx context.Context, userID uuid.UUID, req model.CreateQuestionRequest) (*model.QuestionResponse, error)
+
+	// ListQuestions lists published questions of a book
+	ListQuestions(ctx context.Context, req model.ListQuestionsRequest) (*model.ListQuestionsResponse, error)
+
+	// GetQuestion gets published question with paginated answers
+	GetQuestion(ctx context.Context, id uuid.UUID, req model.ListAnswersRequest) (*model.QuestionDetailResponse, error)
+
+	// AnswerQuestion answers question (staff or verified buyer only)
+	AnswerQuestion(ctx context.Context, userID uuid.UUID, role string, questionID uuid.UUID, req model.CreateAnswerRequest) (*model.AnswerResponse, error)
+
+	// UpvoteAnswer upvotes answer (idempotent)
+	UpvoteAnswer(ctx context.Context, userID, answerID uuid.UUID) (*model.UpvoteResponse, error)
+
+	// RemoveUpvote removes upvote (idempotent)
+	RemoveUpvote(ctx context.Context, userID, answerID uuid.UUID) (*model.UpvoteResponse, error)
+
+	// ========================================
+	// ADMIN OPERATIONS
+	// ========================================
+
+	// AdminListQuestions lists all questions with filters
+	AdminListQuestions(ctx context.Context, req model.AdminListQuestionsRequest) (*model.ListQuestionsResponse, error)
+
+	// AdminGetQuestion gets question with all answers (including hidden)
+	AdminGetQuestion(ctx context.Context, id uuid.UUID, req model.ListAnswersRequest) (*model.QuestionDetailResponse, error)
+
+	// AdminUpdateQuestionStatus hides/publishes question
+	AdminUpdateQuestionStatus(ctx context.Context, id uuid.UUID, req model.UpdateStatusRequest) error
+
+	// AdminUpdateAnswerStatus hides/publishes answer
+	AdminUpdateAnswerStatus(ctx context.Context, id uuid.UUID, req model.UpdateStatusRequest) error
+}
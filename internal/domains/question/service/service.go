@@ -0,0 +1,453 @@
+package service
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	notificationModel "bookstore-backend/internal/domains/notification/model"
+	notificationService "bookstore-backend/internal/domains/notification/service"
+	"bookstore-backend/internal/domains/question/model"
+	"bookstore-backend/internal/domains/question/repository"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type questionService struct {
+	questionRepo        repository.QuestionRepository
+	notificationService notificationService.NotificationService
+}
+
+func NewQuestionService(questionRepo repository.QuestionRepository) ServiceInterface {
+	return &questionService{
+		questionRepo: questionRepo,
+	}
+}
+
+// SetNotificationService sets notification dependency (called after notification services created)
+func (s *questionService) SetNotificationService(ns notificationService.NotificationService) {
+	s.notificationService = ns
+}
+
+// =====================================================
+// ASK QUESTION
+// =====================================================
+
+func (s *questionService) AskQuestion(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.CreateQuestionRequest,
+) (*model.QuestionResponse, error) {
+	// Step 1: Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Book must exist
+	exists, err := s.questionRepo.BookExists(ctx, req.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, model.NewBookNotFoundError()
+	}
+
+	// Step 3: Create question (published ngay, admin ẩn sau nếu vi phạm)
+	now := time.Now()
+	question := &model.Question{
+		ID:        uuid.New(),
+		BookID:    req.BookID,
+		UserID:    userID,
+		Content:   req.Content,
+		Status:    model.StatusPublished,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.questionRepo.CreateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	response := toQuestionResponse(question, false)
+	return &response, nil
+}
+
+// =====================================================
+// LIST / GET QUESTIONS (PUBLIC)
+// =====================================================
+
+func (s *questionService) ListQuestions(
+	ctx context.Context,
+	req model.ListQuestionsRequest,
+) (*model.ListQuestionsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{
+		"book_id": req.BookID,
+		"status":  model.StatusPublished,
+	}
+
+	questions, total, err := s.questionRepo.ListQuestions(ctx, filters, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.QuestionResponse, 0, len(questions))
+	for _, q := range questions {
+		items = append(items, toQuestionResponse(q, false))
+	}
+
+	return &model.ListQuestionsResponse{
+		Questions:  items,
+		Pagination: model.NewPaginationMeta(req.Page, req.Limit, total),
+	}, nil
+}
+
+func (s *questionService) GetQuestion(
+	ctx context.Context,
+	id uuid.UUID,
+	req model.ListAnswersRequest,
+) (*model.QuestionDetailResponse, error) {
+	return s.getQuestionDetail(ctx, id, req, false)
+}
+
+// =====================================================
+// ANSWER QUESTION
+// =====================================================
+
+func (s *questionService) AnswerQuestion(
+	ctx context.Context,
+	userID uuid.UUID,
+	role string,
+	questionID uuid.UUID,
+	req model.CreateAnswerRequest,
+) (*model.AnswerResponse, error) {
+	// Step 1: Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Get question (chỉ trả lời câu hỏi đang hiển thị)
+	question, err := s.getQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if !question.IsPublished() {
+		return nil, model.NewQuestionNotFoundError()
+	}
+
+	// Step 3: Check eligibility - staff hoặc đã mua sách
+	isStaff := isStaffRole(role)
+	isVerifiedBuyer := false
+	if !isStaff {
+		isVerifiedBuyer, err = s.questionRepo.HasPurchased(ctx, userID, question.BookID)
+		if err != nil {
+			return nil, err
+		}
+		if !isVerifiedBuyer {
+			return nil, model.NewNotEligibleError()
+		}
+	}
+
+	// Step 4: Create answer
+	now := time.Now()
+	answer := &model.Answer{
+		ID:              uuid.New(),
+		QuestionID:      questionID,
+		UserID:          userID,
+		Content:         req.Content,
+		IsStaff:         isStaff,
+		IsVerifiedBuyer: isVerifiedBuyer,
+		Status:          model.StatusPublished,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.questionRepo.CreateAnswer(ctx, answer); err != nil {
+		return nil, err
+	}
+
+	// Step 5: Notify asker (không tự notify khi tự trả lời)
+	if question.UserID != userID {
+		s.notifyQuestionAnswered(ctx, question, answer)
+	}
+
+	response := toAnswerResponse(answer, false)
+	return &response, nil
+}
+
+// =====================================================
+// UPVOTES
+// =====================================================
+
+func (s *questionService) UpvoteAnswer(
+	ctx context.Context,
+	userID, answerID uuid.UUID,
+) (*model.UpvoteResponse, error) {
+	answer, err := s.getPublishedAnswer(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if answer.UserID == userID {
+		return nil, model.NewCannotUpvoteError("You cannot upvote your own answer")
+	}
+
+	count, err := s.questionRepo.AddUpvote(ctx, answerID, userID)
+	if err != nil {
+		if err == model.ErrAnswerNotFound {
+			return nil, model.NewAnswerNotFoundError()
+		}
+		return nil, err
+	}
+
+	return &model.UpvoteResponse{AnswerID: answerID, UpvoteCount: count, Upvoted: true}, nil
+}
+
+func (s *questionService) RemoveUpvote(
+	ctx context.Context,
+	userID, answerID uuid.UUID,
+) (*model.UpvoteResponse, error) {
+	if _, err := s.getPublishedAnswer(ctx, answerID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.questionRepo.RemoveUpvote(ctx, answerID, userID)
+	if err != nil {
+		if err == model.ErrAnswerNotFound {
+			return nil, model.NewAnswerNotFoundError()
+		}
+		return nil, err
+	}
+
+	return &model.UpvoteResponse{AnswerID: answerID, UpvoteCount: count, Upvoted: false}, nil
+}
+
+// =====================================================
+// ADMIN OPERATIONS
+// =====================================================
+
+func (s *questionService) AdminListQuestions(
+	ctx context.Context,
+	req model.AdminListQuestionsRequest,
+) (*model.ListQuestionsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters := make(map[string]interface{})
+	if req.BookID != nil {
+		filters["book_id"] = *req.BookID
+	}
+	if req.Status != nil {
+		filters["status"] = *req.Status
+	}
+
+	questions, total, err := s.questionRepo.ListQuestions(ctx, filters, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.QuestionResponse, 0, len(questions))
+	for _, q := range questions {
+		items = append(items, toQuestionResponse(q, true))
+	}
+
+	return &model.ListQuestionsResponse{
+		Questions:  items,
+		Pagination: model.NewPaginationMeta(req.Page, req.Limit, total),
+	}, nil
+}
+
+func (s *questionService) AdminGetQuestion(
+	ctx context.Context,
+	id uuid.UUID,
+	req model.ListAnswersRequest,
+) (*model.QuestionDetailResponse, error) {
+	return s.getQuestionDetail(ctx, id, req, true)
+}
+
+func (s *questionService) AdminUpdateQuestionStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	req model.UpdateStatusRequest,
+) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.questionRepo.UpdateQuestionStatus(ctx, id, req.Status); err != nil {
+		if err == model.ErrQuestionNotFound {
+			return model.NewQuestionNotFoundError()
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *questionService) AdminUpdateAnswerStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	req model.UpdateStatusRequest,
+) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.questionRepo.UpdateAnswerStatus(ctx, id, req.Status); err != nil {
+		if err == model.ErrAnswerNotFound {
+			return model.NewAnswerNotFoundError()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+func (s *questionService) getQuestion(ctx context.Context, id uuid.UUID) (*model.Question, error) {
+	question, err := s.questionRepo.GetQuestionByID(ctx, id)
+	if err != nil {
+		if err == model.ErrQuestionNotFound {
+			return nil, model.NewQuestionNotFoundError()
+		}
+		return nil, err
+	}
+	return question, nil
+}
+
+func (s *questionService) getPublishedAnswer(ctx context.Context, id uuid.UUID) (*model.Answer, error) {
+	answer, err := s.questionRepo.GetAnswerByID(ctx, id)
+	if err != nil {
+		if err == model.ErrAnswerNotFound {
+			return nil, model.NewAnswerNotFoundError()
+		}
+		return nil, err
+	}
+	if !answer.IsPublished() {
+		return nil, model.NewAnswerNotFoundError()
+	}
+	return answer, nil
+}
+
+// getQuestionDetail - admin xem được cả câu hỏi/câu trả lời đã ẩn
+func (s *questionService) getQuestionDetail(
+	ctx context.Context,
+	id uuid.UUID,
+	req model.ListAnswersRequest,
+	isAdmin bool,
+) (*model.QuestionDetailResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	question, err := s.getQuestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && !question.IsPublished() {
+		return nil, model.NewQuestionNotFoundError()
+	}
+
+	answers, total, err := s.questionRepo.ListAnswers(ctx, id, !isAdmin, req.Page, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.AnswerResponse, 0, len(answers))
+	for _, a := range answers {
+		items = append(items, toAnswerResponse(a, isAdmin))
+	}
+
+	return &model.QuestionDetailResponse{
+		Question:   toQuestionResponse(question, isAdmin),
+		Answers:    items,
+		Pagination: model.NewPaginationMeta(req.Page, req.Limit, total),
+	}, nil
+}
+
+func (s *questionService) notifyQuestionAnswered(ctx context.Context, question *model.Question, answer *model.Answer) {
+	if s.notificationService == nil {
+		return
+	}
+
+	referenceType := "product_question"
+	_, err := s.notificationService.CreateNotification(ctx, notificationModel.CreateNotificationRequest{
+		UserID:  question.UserID,
+		Type:    notificationModel.NotificationTypeQuestionAnswered,
+		Title:   "Câu hỏi của bạn đã có câu trả lời",
+		Message: truncate(answer.Content, 200),
+		Data: map[string]interface{}{
+			"question_id": question.ID,
+			"answer_id":   answer.ID,
+			"book_id":     question.BookID,
+			"is_staff":    answer.IsStaff,
+		},
+		Channels:      []string{notificationModel.ChannelInApp},
+		ReferenceType: &referenceType,
+		ReferenceID:   &question.ID,
+	})
+	if err != nil {
+		// Notification lỗi không làm fail câu trả lời
+		logger.Error("Failed to send question answered notification", err)
+	}
+}
+
+func isStaffRole(role string) bool {
+	for _, r := range model.StaffRoles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max]) + "..."
+}
+
+func toQuestionResponse(q *model.Question, withStatus bool) model.QuestionResponse {
+	resp := model.QuestionResponse{
+		ID:          q.ID,
+		BookID:      q.BookID,
+		UserID:      q.UserID,
+		Content:     q.Content,
+		AnswerCount: q.AnswerCount,
+		CreatedAt:   q.CreatedAt,
+	}
+	if withStatus {
+		resp.Status = q.Status
+	}
+	return resp
+}
+
+func toAnswerResponse(a *model.Answer, withStatus bool) model.AnswerResponse {
+	resp := model.AnswerResponse{
+		ID:              a.ID,
+		QuestionID:      a.QuestionID,
+		UserID:          a.UserID,
+		Content:         a.Content,
+		IsStaff:         a.IsStaff,
+		IsVerifiedBuyer: a.IsVerifiedBuyer,
+		UpvoteCount:     a.UpvoteCount,
+		CreatedAt:       a.CreatedAt,
+	}
+	if withStatus {
+		resp.Status = a.Status
+	}
+	return resp
+}
@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	orderModel "bookstore-backend/internal/domains/order/model"
+	"bookstore-backend/internal/domains/quote/model"
+	"bookstore-backend/internal/domains/quote/service"
+)
+
+// =====================================================
+// QUOTE HANDLER
+// =====================================================
+
+type QuoteHandler struct {
+	quoteService service.ServiceInterface
+}
+
+func NewQuoteHandler(quoteService service.ServiceInterface) *QuoteHandler {
+	return &QuoteHandler{
+		quoteService: quoteService,
+	}
+}
+
+// =====================================================
+// USER ENDPOINTS
+// =====================================================
+
+// RequestQuote submits bulk quote request
+// POST /api/v1/quotes
+func (h *QuoteHandler) RequestQuote(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.CreateQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.quoteService.RequestQuote(c.Request.Context(), userID, req)
+	if err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// ListMyQuotes lists quotes of current user
+// GET /api/v1/quotes
+func (h *QuoteHandler) ListMyQuotes(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.ListQuotesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.quoteService.ListMyQuotes(c.Request.Context(), userID, req)
+	if err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetMyQuote gets quote detail
+// GET /api/v1/quotes/:id
+func (h *QuoteHandler) GetMyQuote(c *gin.Context) {
+	userID, quoteID, ok := parseUserQuoteParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.quoteService.GetMyQuote(c.Request.Context(), userID, quoteID)
+	if err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// CancelQuote cancels pending/approved quote
+// POST /api/v1/quotes/:id/cancel
+func (h *QuoteHandler) CancelQuote(c *gin.Context) {
+	userID, quoteID, ok := parseUserQuoteParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.quoteService.CancelQuote(c.Request.Context(), userID, quoteID); err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Quote cancelled successfully",
+	})
+}
+
+// ConvertQuote accepts approved quote and creates order with payment terms
+// POST /api/v1/quotes/:id/convert
+func (h *QuoteHandler) ConvertQuote(c *gin.Context) {
+	userID, quoteID, ok := parseUserQuoteParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.quoteService.ConvertQuote(c.Request.Context(), userID, quoteID)
+	if err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// =====================================================
+// ADMIN ENDPOINTS
+// =====================================================
+
+// AdminListQuotes lists all quotes
+// GET /api/v1/admin/quotes
+func (h *QuoteHandler) AdminListQuotes(c *gin.Context) {
+	var req model.ListQuotesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.quoteService.AdminListQuotes(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AdminGetQuote gets quote detail
+// GET /api/v1/admin/quotes/:id
+func (h *QuoteHandler) AdminGetQuote(c *gin.Context) {
+	quoteID, ok := parseQuoteID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.quoteService.AdminGetQuote(c.Request.Context(), quoteID)
+	if err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// ApproveQuote prices quote and sets payment terms
+// POST /api/v1/admin/quotes/:id/approve
+func (h *QuoteHandler) ApproveQuote(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	quoteID, ok := parseQuoteID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ApproveQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.quoteService.ApproveQuote(c.Request.Context(), adminID, quoteID, req)
+	if err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// RejectQuote rejects pending quote
+// POST /api/v1/admin/quotes/:id/reject
+func (h *QuoteHandler) RejectQuote(c *gin.Context) {
+	quoteID, ok := parseQuoteID(c)
+	if !ok {
+		return
+	}
+
+	var req model.RejectQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.quoteService.RejectQuote(c.Request.Context(), quoteID, req); err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Quote rejected successfully",
+	})
+}
+
+// MarkInvoicePaid records payment of invoice order created from quote
+// POST /api/v1/admin/quotes/:id/mark-paid
+func (h *QuoteHandler) MarkInvoicePaid(c *gin.Context) {
+	quoteID, ok := parseQuoteID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.quoteService.MarkInvoicePaid(c.Request.Context(), quoteID); err != nil {
+		statusCode, errCode := mapQuoteError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Invoice marked as paid",
+	})
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// getUserID extracts user ID from JWT claims
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+}
+
+func parseQuoteID(c *gin.Context) (uuid.UUID, bool) {
+	quoteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid quote ID")
+		return uuid.Nil, false
+	}
+	return quoteID, true
+}
+
+func parseUserQuoteParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	quoteID, ok := parseQuoteID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, quoteID, true
+}
+
+// respondSuccess sends success response
+func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// respondError sends error response
+func respondError(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// mapQuoteError maps quote/order error to HTTP status code
+func mapQuoteError(err error) (int, string) {
+	var qErr *model.QuoteError
+	if errors.As(err, &qErr) {
+		switch qErr.Code {
+		case model.ErrCodeQuoteNotFound:
+			return http.StatusNotFound, qErr.Code
+		case model.ErrCodeInvalidTransition, model.ErrCodeQuoteExpired:
+			return http.StatusConflict, qErr.Code
+		case model.ErrCodeInvalidRequest, model.ErrCodeBookUnavailable,
+			model.ErrCodeInvalidAddress, model.ErrCodeInvalidPricing:
+			return http.StatusBadRequest, qErr.Code
+		default:
+			return http.StatusInternalServerError, "INTERNAL_ERROR"
+		}
+	}
+
+	// Lỗi khi convert sang order (hết hàng, địa chỉ không hợp lệ, ...)
+	var oErr *orderModel.OrderError
+	if errors.As(err, &oErr) {
+		switch oErr.Code {
+		case orderModel.ErrCodeInsufficientStock, orderModel.ErrCodeInvalidStatus:
+			return http.StatusConflict, oErr.Code
+		case orderModel.ErrCodeOrderNotFound:
+			return http.StatusNotFound, oErr.Code
+		default:
+			return http.StatusBadRequest, oErr.Code
+		}
+	}
+
+	return http.StatusInternalServerError, "INTERNAL_ERROR"
+}
@@ -0,0 +1,57 @@
+package model
+
+// Quote statuses
+const (
+	StatusPending   = "pending"   // khách gửi, chờ admin báo giá
+	StatusApproved  = "approved"  // admin đã báo giá, chờ khách chấp nhận
+	StatusRejected  = "rejected"  // admin từ chối
+	StatusConverted = "converted" // đã tạo order
+	StatusCancelled = "cancelled" // khách huỷ
+	StatusExpired   = "expired"   // quá valid_until chưa convert
+)
+
+// Bulk limits
+// WHY? Quote dành cho đơn số lượng lớn, đơn nhỏ đi qua cart bình thường.
+// Cart giới hạn 100/item; quote cho phép tới MaxItemQuantity.
+const (
+	MinTotalQuantity = 50
+	MaxItemQuantity  = 10000
+	MaxQuoteItems    = 200
+	MaxNoteLength    = 1000
+)
+
+// Pricing defaults
+const (
+	DefaultValidDays = 14
+	MaxValidDays     = 60
+	MaxTermsDays     = 90
+)
+
+// AllowedTermsDays - điều khoản công nợ chuẩn (0 = thanh toán khi nhận hoá đơn)
+var AllowedTermsDays = []int{0, 15, 30, 45, 60, 90}
+
+// Pagination defaults
+const (
+	DefaultPage  = 1
+	DefaultLimit = 10
+	MaxLimit     = 50
+)
+
+// IsValidStatus checks quote status
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusPending, StatusApproved, StatusRejected, StatusConverted, StatusCancelled, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// IsAllowedTermsDays checks payment terms
+func IsAllowedTermsDays(days int) bool {
+	for _, d := range AllowedTermsDays {
+		if d == days {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// USER REQUEST DTOs
+// =====================================================
+
+// CreateQuoteRequest khách doanh nghiệp gửi yêu cầu báo giá
+type CreateQuoteRequest struct {
+	CompanyName  string                   `json:"company_name" binding:"required"`
+	TaxCode      *string                  `json:"tax_code,omitempty"`
+	ContactName  string                   `json:"contact_name" binding:"required"`
+	ContactEmail string                   `json:"contact_email" binding:"required"`
+	ContactPhone string                   `json:"contact_phone" binding:"required"`
+	AddressID    uuid.UUID                `json:"address_id" binding:"required"`
+	Items        []CreateQuoteItemRequest `json:"items" binding:"required"`
+	Note         *string                  `json:"note,omitempty"`
+}
+
+// CreateQuoteItemRequest sách + số lượng
+type CreateQuoteItemRequest struct {
+	BookID   uuid.UUID `json:"book_id" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required"`
+}
+
+func (r *CreateQuoteRequest) Validate() error {
+	r.CompanyName = strings.TrimSpace(r.CompanyName)
+	r.ContactName = strings.TrimSpace(r.ContactName)
+	r.ContactEmail = strings.TrimSpace(r.ContactEmail)
+	r.ContactPhone = strings.TrimSpace(r.ContactPhone)
+
+	if r.CompanyName == "" || r.ContactName == "" {
+		return NewInvalidRequestError("company_name and contact_name are required")
+	}
+	if _, err := mail.ParseAddress(r.ContactEmail); err != nil {
+		return NewInvalidRequestError("contact_email is invalid")
+	}
+	if r.ContactPhone == "" {
+		return NewInvalidRequestError("contact_phone is required")
+	}
+	if r.Note != nil && utf8.RuneCountInString(*r.Note) > MaxNoteLength {
+		return NewInvalidRequestError(fmt.Sprintf("note must be at most %d characters", MaxNoteLength))
+	}
+
+	if len(r.Items) == 0 || len(r.Items) > MaxQuoteItems {
+		return NewInvalidRequestError(fmt.Sprintf("quote must have between 1 and %d items", MaxQuoteItems))
+	}
+
+	seen := make(map[uuid.UUID]bool, len(r.Items))
+	totalQuantity := 0
+	for _, item := range r.Items {
+		if seen[item.BookID] {
+			return NewInvalidRequestError(fmt.Sprintf("duplicate book in quote: %s", item.BookID))
+		}
+		seen[item.BookID] = true
+
+		if item.Quantity < 1 || item.Quantity > MaxItemQuantity {
+			return NewInvalidRequestError(fmt.Sprintf("quantity must be between 1 and %d", MaxItemQuantity))
+		}
+		totalQuantity += item.Quantity
+	}
+
+	if totalQuantity < MinTotalQuantity {
+		return NewInvalidRequestError(fmt.Sprintf("bulk quotes require at least %d copies in total", MinTotalQuantity))
+	}
+
+	return nil
+}
+
+// TotalQuantity tổng số cuốn
+func (r *CreateQuoteRequest) TotalQuantity() int {
+	total := 0
+	for _, item := range r.Items {
+		total += item.Quantity
+	}
+	return total
+}
+
+// ListQuotesRequest pagination + filter status
+type ListQuotesRequest struct {
+	Status *string `form:"status"`
+	Page   int     `form:"page"`
+	Limit  int     `form:"limit"`
+}
+
+func (r *ListQuotesRequest) Validate() error {
+	if r.Status != nil && !IsValidStatus(*r.Status) {
+		return NewInvalidRequestError("invalid status filter")
+	}
+	r.Page, r.Limit = normalizePagination(r.Page, r.Limit)
+	return nil
+}
+
+// =====================================================
+// ADMIN REQUEST DTOs
+// =====================================================
+
+// ApproveQuoteRequest admin duyệt giá
+type ApproveQuoteRequest struct {
+	Items            []ApproveQuoteItemRequest `json:"items" binding:"required"`
+	PaymentTermsDays int                       `json:"payment_terms_days"`
+	ValidDays        int                       `json:"valid_days"`
+	AdminNote        *string                   `json:"admin_note,omitempty"`
+}
+
+// ApproveQuoteItemRequest đơn giá cho từng dòng
+type ApproveQuoteItemRequest struct {
+	ItemID    uuid.UUID       `json:"item_id" binding:"required"`
+	UnitPrice decimal.Decimal `json:"unit_price" binding:"required"`
+}
+
+func (r *ApproveQuoteRequest) Validate() error {
+	if len(r.Items) == 0 {
+		return NewInvalidPricingError("items pricing is required")
+	}
+	for _, item := range r.Items {
+		if item.UnitPrice.IsNegative() {
+			return NewInvalidPricingError("unit_price must not be negative")
+		}
+	}
+	if !IsAllowedTermsDays(r.PaymentTermsDays) {
+		return NewInvalidPricingError(fmt.Sprintf("payment_terms_days must be one of %v", AllowedTermsDays))
+	}
+	if r.ValidDays == 0 {
+		r.ValidDays = DefaultValidDays
+	}
+	if r.ValidDays < 1 || r.ValidDays > MaxValidDays {
+		return NewInvalidPricingError(fmt.Sprintf("valid_days must be between 1 and %d", MaxValidDays))
+	}
+	return nil
+}
+
+// RejectQuoteRequest admin từ chối
+type RejectQuoteRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (r *RejectQuoteRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" || utf8.RuneCountInString(r.Reason) > MaxNoteLength {
+		return NewInvalidRequestError(fmt.Sprintf("reason must be between 1 and %d characters", MaxNoteLength))
+	}
+	return nil
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// QuoteResponse báo giá + items
+type QuoteResponse struct {
+	ID               uuid.UUID           `json:"id"`
+	QuoteNumber      string              `json:"quote_number"`
+	UserID           uuid.UUID           `json:"user_id"`
+	AddressID        uuid.UUID           `json:"address_id"`
+	CompanyName      string              `json:"company_name"`
+	TaxCode          *string             `json:"tax_code,omitempty"`
+	ContactName      string              `json:"contact_name"`
+	ContactEmail     string              `json:"contact_email"`
+	ContactPhone     string              `json:"contact_phone"`
+	Status           string              `json:"status"`
+	CustomerNote     *string             `json:"customer_note,omitempty"`
+	AdminNote        *string             `json:"admin_note,omitempty"`
+	PaymentTermsDays *int                `json:"payment_terms_days,omitempty"`
+	TotalQuantity    int                 `json:"total_quantity"`
+	ListTotal        decimal.Decimal     `json:"list_total"`
+	QuotedTotal      *decimal.Decimal    `json:"quoted_total,omitempty"`
+	ValidUntil       *time.Time          `json:"valid_until,omitempty"`
+	QuotedAt         *time.Time          `json:"quoted_at,omitempty"`
+	ConvertedOrderID *uuid.UUID          `json:"converted_order_id,omitempty"`
+	Items            []QuoteItemResponse `json:"items,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+}
+
+// QuoteItemResponse dòng báo giá
+type QuoteItemResponse struct {
+	ID              uuid.UUID        `json:"id"`
+	BookID          uuid.UUID        `json:"book_id"`
+	BookTitle       string           `json:"book_title"`
+	Quantity        int              `json:"quantity"`
+	ListPrice       decimal.Decimal  `json:"list_price"`
+	QuotedUnitPrice *decimal.Decimal `json:"quoted_unit_price,omitempty"`
+}
+
+// ListQuotesResponse paginated quotes
+type ListQuotesResponse struct {
+	Quotes     []QuoteResponse `json:"quotes"`
+	Pagination PaginationMeta  `json:"pagination"`
+}
+
+// ConvertQuoteResponse order tạo từ quote
+type ConvertQuoteResponse struct {
+	QuoteID      uuid.UUID       `json:"quote_id"`
+	OrderID      uuid.UUID       `json:"order_id"`
+	OrderNumber  string          `json:"order_number"`
+	Total        decimal.Decimal `json:"total"`
+	PaymentTerms int             `json:"payment_terms_days"`
+}
+
+// PaginationMeta pagination metadata
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
+
+// NewPaginationMeta builds pagination metadata
+func NewPaginationMeta(page, limit, total int) PaginationMeta {
+	totalPages := (total + limit - 1) / limit
+	return PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+func normalizePagination(page, limit int) (int, int) {
+	if page < 1 {
+		page = DefaultPage
+	}
+	if limit < 1 || limit > MaxLimit {
+		limit = DefaultLimit
+	}
+	return page, limit
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Quote - yêu cầu báo giá B2B (quote_requests)
+type Quote struct {
+	ID               uuid.UUID
+	QuoteNumber      string
+	UserID           uuid.UUID
+	AddressID        uuid.UUID
+	CompanyName      string
+	TaxCode          *string
+	ContactName      string
+	ContactEmail     string
+	ContactPhone     string
+	Status           string
+	CustomerNote     *string
+	AdminNote        *string
+	PaymentTermsDays *int
+	TotalQuantity    int
+	ListTotal        decimal.Decimal
+	QuotedTotal      *decimal.Decimal
+	ValidUntil       *time.Time
+	QuotedBy         *uuid.UUID
+	QuotedAt         *time.Time
+	ConvertedOrderID *uuid.UUID
+	ConvertedAt      *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	Items []QuoteItem
+}
+
+// IsExpired báo giá đã duyệt nhưng quá hạn hiệu lực
+func (q *Quote) IsExpired(now time.Time) bool {
+	return q.Status == StatusApproved && q.ValidUntil != nil && now.After(*q.ValidUntil)
+}
+
+// CanBeCancelled khách huỷ khi chưa convert
+func (q *Quote) CanBeCancelled() bool {
+	return q.Status == StatusPending || q.Status == StatusApproved
+}
+
+// QuoteItem - dòng sách trong báo giá (quote_request_items)
+type QuoteItem struct {
+	ID              uuid.UUID
+	QuoteID         uuid.UUID
+	BookID          uuid.UUID
+	BookTitle       string
+	Quantity        int
+	ListPrice       decimal.Decimal
+	QuotedUnitPrice *decimal.Decimal
+	CreatedAt       time.Time
+}
+
+// BookPrice - giá niêm yết hiện tại của sách (snapshot khi tạo quote)
+type BookPrice struct {
+	BookID   uuid.UUID
+	Title    string
+	Price    decimal.Decimal
+	IsActive bool
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodeQuoteNotFound     = "QTE001"
+	ErrCodeInvalidRequest    = "QTE002"
+	ErrCodeInvalidTransition = "QTE003"
+	ErrCodeQuoteExpired      = "QTE004"
+	ErrCodeBookUnavailable   = "QTE005"
+	ErrCodeInvalidAddress    = "QTE006"
+	ErrCodeInvalidPricing    = "QTE007"
+)
+
+// Errors
+var (
+	ErrQuoteNotFound     = errors.New("quote not found")
+	ErrInvalidRequest    = errors.New("invalid quote request")
+	ErrInvalidTransition = errors.New("invalid quote status transition")
+	ErrQuoteExpired      = errors.New("quote expired")
+	ErrBookUnavailable   = errors.New("book unavailable")
+	ErrInvalidAddress    = errors.New("invalid address")
+	ErrInvalidPricing    = errors.New("invalid pricing")
+)
+
+// QuoteError custom error type
+type QuoteError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *QuoteError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *QuoteError) Unwrap() error {
+	return e.Err
+}
+
+// Error constructors
+func NewQuoteNotFoundError() *QuoteError {
+	return &QuoteError{
+		Code:    ErrCodeQuoteNotFound,
+		Message: "Quote not found",
+		Err:     ErrQuoteNotFound,
+	}
+}
+
+func NewInvalidRequestError(message string) *QuoteError {
+	return &QuoteError{
+		Code:    ErrCodeInvalidRequest,
+		Message: message,
+		Err:     ErrInvalidRequest,
+	}
+}
+
+func NewInvalidTransitionError(from, action string) *QuoteError {
+	return &QuoteError{
+		Code:    ErrCodeInvalidTransition,
+		Message: fmt.Sprintf("Cannot %s a quote in status %s", action, from),
+		Err:     ErrInvalidTransition,
+	}
+}
+
+func NewQuoteExpiredError() *QuoteError {
+	return &QuoteError{
+		Code:    ErrCodeQuoteExpired,
+		Message: "Quote has expired, please request a new quote",
+		Err:     ErrQuoteExpired,
+	}
+}
+
+func NewBookUnavailableError(bookID string) *QuoteError {
+	return &QuoteError{
+		Code:    ErrCodeBookUnavailable,
+		Message: fmt.Sprintf("Book is not available: %s", bookID),
+		Err:     ErrBookUnavailable,
+	}
+}
+
+func NewInvalidAddressError() *QuoteError {
+	return &QuoteError{
+		Code:    ErrCodeInvalidAddress,
+		Message: "Delivery address not found",
+		Err:     ErrInvalidAddress,
+	}
+}
+
+func NewInvalidPricingError(message string) *QuoteError {
+	return &QuoteError{
+		Code:    ErrCodeInvalidPricing,
+		Message: message,
+		Err:     ErrInvalidPricing,
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/quote/model"
+)
+
+// =====================================================
+// QUOTE REPOSITORY INTERFACE
+// =====================================================
+
+type QuoteRepository interface {
+	// ========================================
+	// QUOTES
+	// ========================================
+
+	// CreateQuote creates quote with items in one transaction
+	CreateQuote(ctx context.Context, quote *model.Quote) error
+
+	// GetQuoteByID gets quote with items
+	GetQuoteByID(ctx context.Context, id uuid.UUID) (*model.Quote, error)
+
+	// ListQuotes lists quotes with filters (user_id, status), without items
+	ListQuotes(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*model.Quote, int, error)
+
+	// ApproveQuote sets quoted prices + terms, pending → approved
+	ApproveQuote(ctx context.Context, quote *model.Quote) error
+
+	// UpdateStatus guarded transition (only from given statuses)
+	UpdateStatus(ctx context.Context, id uuid.UUID, from []string, to string, adminNote *string) error
+
+	// ========================================
+	// CONVERSION
+	// ========================================
+
+	// ClaimForConversion approved (còn hiệu lực) → converted, chặn convert 2 lần
+	ClaimForConversion(ctx context.Context, id, userID uuid.UUID) (bool, error)
+
+	// SetConvertedOrder links created order
+	SetConvertedOrder(ctx context.Context, id, orderID uuid.UUID) error
+
+	// ReleaseConversion converted → approved khi tạo order thất bại
+	ReleaseConversion(ctx context.Context, id uuid.UUID) error
+
+	// ========================================
+	// LOOKUPS
+	// ========================================
+
+	// GetBookPrices gets current list prices of books
+	GetBookPrices(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]model.BookPrice, error)
+
+	// AddressBelongsToUser checks delivery address ownership
+	AddressBelongsToUser(ctx context.Context, addressID, userID uuid.UUID) (bool, error)
+}
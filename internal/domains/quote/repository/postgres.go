@@ -0,0 +1,384 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/quote/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresQuoteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresQuoteRepository(pool *pgxpool.Pool) QuoteRepository {
+	return &postgresQuoteRepository{pool: pool}
+}
+
+const quoteColumns = `
+	id, quote_number, user_id, address_id,
+	company_name, tax_code, contact_name, contact_email, contact_phone,
+	status, customer_note, admin_note, payment_terms_days,
+	total_quantity, list_total, quoted_total, valid_until, quoted_by, quoted_at,
+	converted_order_id, converted_at, created_at, updated_at`
+
+func scanQuote(row pgx.Row, quote *model.Quote) error {
+	return row.Scan(
+		&quote.ID,
+		&quote.QuoteNumber,
+		&quote.UserID,
+		&quote.AddressID,
+		&quote.CompanyName,
+		&quote.TaxCode,
+		&quote.ContactName,
+		&quote.ContactEmail,
+		&quote.ContactPhone,
+		&quote.Status,
+		&quote.CustomerNote,
+		&quote.AdminNote,
+		&quote.PaymentTermsDays,
+		&quote.TotalQuantity,
+		&quote.ListTotal,
+		&quote.QuotedTotal,
+		&quote.ValidUntil,
+		&quote.QuotedBy,
+		&quote.QuotedAt,
+		&quote.ConvertedOrderID,
+		&quote.ConvertedAt,
+		&quote.CreatedAt,
+		&quote.UpdatedAt,
+	)
+}
+
+// =====================================================
+// QUOTES
+// =====================================================
+
+func (r *postgresQuoteRepository) CreateQuote(ctx context.Context, quote *model.Quote) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	query := `
+		INSERT INTO quote_requests (
+			id, quote_number, user_id, address_id,
+			company_name, tax_code, contact_name, contact_email, contact_phone,
+			status, customer_note, total_quantity, list_total
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at, updated_at
+	`
+
+	err = tx.QueryRow(ctx, query,
+		quote.ID,
+		quote.QuoteNumber,
+		quote.UserID,
+		quote.AddressID,
+		quote.CompanyName,
+		quote.TaxCode,
+		quote.ContactName,
+		quote.ContactEmail,
+		quote.ContactPhone,
+		quote.Status,
+		quote.CustomerNote,
+		quote.TotalQuantity,
+		quote.ListTotal,
+	).Scan(&quote.CreatedAt, &quote.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create quote: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO quote_request_items (id, quote_id, book_id, quantity, list_price)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	for i := range quote.Items {
+		item := &quote.Items[i]
+		if err := tx.QueryRow(ctx, itemQuery,
+			item.ID,
+			quote.ID,
+			item.BookID,
+			item.Quantity,
+			item.ListPrice,
+		).Scan(&item.CreatedAt); err != nil {
+			return fmt.Errorf("failed to create quote item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit quote: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresQuoteRepository) GetQuoteByID(ctx context.Context, id uuid.UUID) (*model.Quote, error) {
+	query := `SELECT ` + quoteColumns + ` FROM quote_requests WHERE id = $1`
+
+	quote := &model.Quote{}
+	if err := scanQuote(r.pool.QueryRow(ctx, query, id), quote); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrQuoteNotFound
+		}
+		return nil, fmt.Errorf("failed to get quote: %w", err)
+	}
+
+	itemQuery := `
+		SELECT qi.id, qi.quote_id, qi.book_id, b.title, qi.quantity,
+			qi.list_price, qi.quoted_unit_price, qi.created_at
+		FROM quote_request_items qi
+		JOIN books b ON b.id = qi.book_id
+		WHERE qi.quote_id = $1
+		ORDER BY qi.created_at, qi.id
+	`
+
+	rows, err := r.pool.Query(ctx, itemQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quote items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.QuoteItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.QuoteID,
+			&item.BookID,
+			&item.BookTitle,
+			&item.Quantity,
+			&item.ListPrice,
+			&item.QuotedUnitPrice,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan quote item: %w", err)
+		}
+		quote.Items = append(quote.Items, item)
+	}
+
+	return quote, rows.Err()
+}
+
+func (r *postgresQuoteRepository) ListQuotes(
+	ctx context.Context,
+	filters map[string]interface{},
+	page, limit int,
+) ([]*model.Quote, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if userID, ok := filters["user_id"].(uuid.UUID); ok {
+		where += fmt.Sprintf(" AND user_id = $%d", argCount)
+		args = append(args, userID)
+		argCount++
+	}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += fmt.Sprintf(" AND status = $%d", argCount)
+		args = append(args, status)
+		argCount++
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM quote_requests" + where
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count quotes: %w", err)
+	}
+
+	query := `SELECT ` + quoteColumns + ` FROM quote_requests` + where +
+		" ORDER BY created_at DESC" +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list quotes: %w", err)
+	}
+	defer rows.Close()
+
+	var quotes []*model.Quote
+	for rows.Next() {
+		quote := &model.Quote{}
+		if err := scanQuote(rows, quote); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan quote: %w", err)
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, total, rows.Err()
+}
+
+func (r *postgresQuoteRepository) ApproveQuote(ctx context.Context, quote *model.Quote) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	// Status guard trước: chặn 2 admin duyệt cùng lúc
+	query := `
+		UPDATE quote_requests
+		SET status = 'approved',
+			payment_terms_days = $2,
+			quoted_total = $3,
+			valid_until = $4,
+			quoted_by = $5,
+			quoted_at = $6,
+			admin_note = COALESCE($7, admin_note)
+		WHERE id = $1 AND status = 'pending'
+	`
+	result, err := tx.Exec(ctx, query,
+		quote.ID,
+		quote.PaymentTermsDays,
+		quote.QuotedTotal,
+		quote.ValidUntil,
+		quote.QuotedBy,
+		quote.QuotedAt,
+		quote.AdminNote,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to approve quote: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrInvalidTransition
+	}
+
+	itemQuery := `
+		UPDATE quote_request_items
+		SET quoted_unit_price = $3
+		WHERE id = $1 AND quote_id = $2
+	`
+	for _, item := range quote.Items {
+		if _, err := tx.Exec(ctx, itemQuery, item.ID, quote.ID, item.QuotedUnitPrice); err != nil {
+			return fmt.Errorf("failed to price quote item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit quote approval: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresQuoteRepository) UpdateStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	from []string,
+	to string,
+	adminNote *string,
+) error {
+	query := `
+		UPDATE quote_requests
+		SET status = $2, admin_note = COALESCE($4, admin_note)
+		WHERE id = $1 AND status = ANY($3)
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, to, from, adminNote)
+	if err != nil {
+		return fmt.Errorf("failed to update quote status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+// =====================================================
+// CONVERSION
+// =====================================================
+
+func (r *postgresQuoteRepository) ClaimForConversion(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE quote_requests
+		SET status = 'converted', converted_at = NOW()
+		WHERE id = $1
+			AND user_id = $2
+			AND status = 'approved'
+			AND valid_until >= NOW()
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim quote for conversion: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *postgresQuoteRepository) SetConvertedOrder(ctx context.Context, id, orderID uuid.UUID) error {
+	query := `UPDATE quote_requests SET converted_order_id = $2 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, orderID); err != nil {
+		return fmt.Errorf("failed to link converted order: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresQuoteRepository) ReleaseConversion(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE quote_requests
+		SET status = 'approved', converted_at = NULL
+		WHERE id = $1 AND status = 'converted' AND converted_order_id IS NULL
+	`
+
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to release quote conversion: %w", err)
+	}
+
+	return nil
+}
+
+// =====================================================
+// LOOKUPS
+// =====================================================
+
+func (r *postgresQuoteRepository) GetBookPrices(ctx context.Context, bookIDs []uuid.UUID) (map[uuid.UUID]model.BookPrice, error) {
+	query := `
+		SELECT id, title, price, COALESCE(is_active, false)
+		FROM books
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+
+	rows, err := r.pool.Query(ctx, query, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book prices: %w", err)
+	}
+	defer rows.Close()
+
+	prices := make(map[uuid.UUID]model.BookPrice, len(bookIDs))
+	for rows.Next() {
+		var price model.BookPrice
+		if err := rows.Scan(&price.BookID, &price.Title, &price.Price, &price.IsActive); err != nil {
+			return nil, fmt.Errorf("failed to scan book price: %w", err)
+		}
+		prices[price.BookID] = price
+	}
+
+	return prices, rows.Err()
+}
+
+func (r *postgresQuoteRepository) AddressBelongsToUser(ctx context.Context, addressID, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM addresses WHERE id = $1 AND user_id = $2)`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, addressID, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check address: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/quote/model"
+)
+
+// =====================================================
+// QUOTE SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ========================================
+	// USER (B2B CUSTOMER)
+	// ========================================
+
+	// RequestQuote submits bulk quote request with list-price snapshot
+	RequestQuote(ctx context.Context, userID uuid.UUID, req model.CreateQuoteRequest) (*model.QuoteResponse, error)
+
+	// ListMyQuotes lists quotes of user
+	ListMyQuotes(ctx context.Context, userID uuid.UUID, req model.ListQuotesRequest) (*model.ListQuotesResponse, error)
+
+	// GetMyQuote gets quote owned by user
+	GetMyQuote(ctx context.Context, userID, quoteID uuid.UUID) (*model.QuoteResponse, error)
+
+	// CancelQuote cancels pending/approved quote
+	CancelQuote(ctx context.Context, userID, quoteID uuid.UUID) error
+
+	// ConvertQuote accepts approved quote and creates invoice-terms order
+	ConvertQuote(ctx context.Context, userID, quoteID uuid.UUID) (*model.ConvertQuoteResponse, error)
+
+	// ========================================
+	// ADMIN
+	// ========================================
+
+	// AdminListQuotes lists all quotes
+	AdminListQuotes(ctx context.Context, req model.ListQuotesRequest) (*model.ListQuotesResponse, error)
+
+	// AdminGetQuote gets quote detail
+	AdminGetQuote(ctx context.Context, quoteID uuid.UUID) (*model.QuoteResponse, error)
+
+	// ApproveQuote prices quote items and sets payment terms
+	ApproveQuote(ctx context.Context, adminID, quoteID uuid.UUID, req model.ApproveQuoteRequest) (*model.QuoteResponse, error)
+
+	// RejectQuote rejects pending quote
+	RejectQuote(ctx context.Context, quoteID uuid.UUID, req model.RejectQuoteRequest) error
+
+	// MarkInvoicePaid records payment of order converted from quote
+	MarkInvoicePaid(ctx context.Context, quoteID uuid.UUID) error
+}
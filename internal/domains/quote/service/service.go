@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	orderModel "bookstore-backend/internal/domains/order/model"
+	orderService "bookstore-backend/internal/domains/order/service"
+	"bookstore-backend/internal/domains/quote/model"
+	"bookstore-backend/internal/domains/quote/repository"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type quoteService struct {
+	quoteRepo    repository.QuoteRepository
+	orderService orderService.OrderService
+}
+
+func NewQuoteService(quoteRepo repository.QuoteRepository, orderService orderService.OrderService) ServiceInterface {
+	return &quoteService{
+		quoteRepo:    quoteRepo,
+		orderService: orderService,
+	}
+}
+
+// =====================================================
+// REQUEST QUOTE
+// =====================================================
+
+func (s *quoteService) RequestQuote(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.CreateQuoteRequest,
+) (*model.QuoteResponse, error) {
+	// Step 1: Validate request (min tổng số lượng, max/item)
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Address phải thuộc user
+	owned, err := s.quoteRepo.AddressBelongsToUser(ctx, req.AddressID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, model.NewInvalidAddressError()
+	}
+
+	// Step 3: Snapshot giá niêm yết
+	bookIDs := make([]uuid.UUID, len(req.Items))
+	for i, item := range req.Items {
+		bookIDs[i] = item.BookID
+	}
+	prices, err := s.quoteRepo.GetBookPrices(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	quoteID := uuid.New()
+	listTotal := decimal.Zero
+	items := make([]model.QuoteItem, len(req.Items))
+	for i, item := range req.Items {
+		price, ok := prices[item.BookID]
+		if !ok || !price.IsActive {
+			return nil, model.NewBookUnavailableError(item.BookID.String())
+		}
+		items[i] = model.QuoteItem{
+			ID:        uuid.New(),
+			QuoteID:   quoteID,
+			BookID:    item.BookID,
+			BookTitle: price.Title,
+			Quantity:  item.Quantity,
+			ListPrice: price.Price,
+		}
+		listTotal = listTotal.Add(price.Price.Mul(decimal.NewFromInt(int64(item.Quantity))))
+	}
+
+	// Step 4: Create quote (pending, chờ admin báo giá)
+	quote := &model.Quote{
+		ID:            quoteID,
+		QuoteNumber:   generateQuoteNumber(quoteID, time.Now()),
+		UserID:        userID,
+		AddressID:     req.AddressID,
+		CompanyName:   req.CompanyName,
+		TaxCode:       req.TaxCode,
+		ContactName:   req.ContactName,
+		ContactEmail:  req.ContactEmail,
+		ContactPhone:  req.ContactPhone,
+		Status:        model.StatusPending,
+		CustomerNote:  req.Note,
+		TotalQuantity: req.TotalQuantity(),
+		ListTotal:     listTotal,
+		Items:         items,
+	}
+
+	if err := s.quoteRepo.CreateQuote(ctx, quote); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Quote requested", map[string]interface{}{
+		"quote_id":       quote.ID,
+		"user_id":        userID,
+		"total_quantity": quote.TotalQuantity,
+	})
+
+	return toQuoteResponse(quote, time.Now()), nil
+}
+
+// =====================================================
+// USER QUERIES
+// =====================================================
+
+func (s *quoteService) ListMyQuotes(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.ListQuotesRequest,
+) (*model.ListQuotesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{"user_id": userID}
+	if req.Status != nil {
+		filters["status"] = *req.Status
+	}
+	return s.listQuotes(ctx, filters, req.Page, req.Limit)
+}
+
+func (s *quoteService) GetMyQuote(ctx context.Context, userID, quoteID uuid.UUID) (*model.QuoteResponse, error) {
+	quote, err := s.getOwnedQuote(ctx, userID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	return toQuoteResponse(quote, time.Now()), nil
+}
+
+// =====================================================
+// CANCEL / CONVERT
+// =====================================================
+
+func (s *quoteService) CancelQuote(ctx context.Context, userID, quoteID uuid.UUID) error {
+	quote, err := s.getOwnedQuote(ctx, userID, quoteID)
+	if err != nil {
+		return err
+	}
+	if !quote.CanBeCancelled() {
+		return model.NewInvalidTransitionError(quote.Status, "cancel")
+	}
+
+	err = s.quoteRepo.UpdateStatus(ctx, quoteID,
+		[]string{model.StatusPending, model.StatusApproved},
+		model.StatusCancelled,
+		nil,
+	)
+	if errors.Is(err, model.ErrInvalidTransition) {
+		return model.NewInvalidTransitionError(quote.Status, "cancel")
+	}
+	return err
+}
+
+// ConvertQuote - khách chấp nhận báo giá → tạo order thanh toán theo công nợ
+func (s *quoteService) ConvertQuote(ctx context.Context, userID, quoteID uuid.UUID) (*model.ConvertQuoteResponse, error) {
+	quote, err := s.getOwnedQuote(ctx, userID, quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 1: Hết hạn → đánh dấu expired (lazy, không cần cron)
+	if quote.IsExpired(time.Now()) {
+		if err := s.quoteRepo.UpdateStatus(ctx, quoteID, []string{model.StatusApproved}, model.StatusExpired, nil); err != nil &&
+			!errors.Is(err, model.ErrInvalidTransition) {
+			logger.Error("Failed to mark quote expired", err)
+		}
+		return nil, model.NewQuoteExpiredError()
+	}
+	if quote.Status != model.StatusApproved {
+		return nil, model.NewInvalidTransitionError(quote.Status, "convert")
+	}
+
+	// Step 2: Claim (approved → converted) trước khi tạo order, chặn double-submit
+	claimed, err := s.quoteRepo.ClaimForConversion(ctx, quoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, model.NewInvalidTransitionError(quote.Status, "convert")
+	}
+
+	// Step 3: Tạo order với đơn giá quote (không qua cart → không bị cap 100/item)
+	orderItems := make([]orderModel.QuoteOrderItem, len(quote.Items))
+	for i, item := range quote.Items {
+		orderItems[i] = orderModel.QuoteOrderItem{
+			BookID:    item.BookID,
+			Quantity:  item.Quantity,
+			UnitPrice: *item.QuotedUnitPrice,
+		}
+	}
+
+	order, err := s.orderService.CreateOrderFromQuote(ctx, userID, orderModel.CreateQuoteOrderRequest{
+		QuoteID:      quote.ID,
+		AddressID:    quote.AddressID,
+		CompanyName:  quote.CompanyName,
+		TaxCode:      quote.TaxCode,
+		TermsDays:    *quote.PaymentTermsDays,
+		CustomerNote: quote.CustomerNote,
+		Items:        orderItems,
+	})
+	if err != nil {
+		// Trả quote về approved để khách thử lại (vd: kho chưa đủ hàng)
+		if releaseErr := s.quoteRepo.ReleaseConversion(ctx, quoteID); releaseErr != nil {
+			logger.Error("Failed to release quote conversion", releaseErr)
+		}
+		return nil, err
+	}
+
+	if err := s.quoteRepo.SetConvertedOrder(ctx, quoteID, order.OrderID); err != nil {
+		// Order đã tạo thành công, chỉ log (order_payment_terms vẫn link quote_id)
+		logger.Error("Failed to link converted order to quote", err)
+	}
+
+	logger.Info("Quote converted to order", map[string]interface{}{
+		"quote_id": quoteID,
+		"order_id": order.OrderID,
+	})
+
+	return &model.ConvertQuoteResponse{
+		QuoteID:      quote.ID,
+		OrderID:      order.OrderID,
+		OrderNumber:  order.OrderNumber,
+		Total:        order.Total,
+		PaymentTerms: *quote.PaymentTermsDays,
+	}, nil
+}
+
+// =====================================================
+// ADMIN
+// =====================================================
+
+func (s *quoteService) AdminListQuotes(ctx context.Context, req model.ListQuotesRequest) (*model.ListQuotesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{}
+	if req.Status != nil {
+		filters["status"] = *req.Status
+	}
+	return s.listQuotes(ctx, filters, req.Page, req.Limit)
+}
+
+func (s *quoteService) AdminGetQuote(ctx context.Context, quoteID uuid.UUID) (*model.QuoteResponse, error) {
+	quote, err := s.getQuote(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	return toQuoteResponse(quote, time.Now()), nil
+}
+
+// ApproveQuote - admin báo giá: đơn giá từng dòng + điều khoản công nợ + hạn hiệu lực
+func (s *quoteService) ApproveQuote(
+	ctx context.Context,
+	adminID, quoteID uuid.UUID,
+	req model.ApproveQuoteRequest,
+) (*model.QuoteResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	quote, err := s.getQuote(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Status != model.StatusPending {
+		return nil, model.NewInvalidTransitionError(quote.Status, "approve")
+	}
+
+	// Mọi dòng phải có giá, không thừa dòng lạ
+	unitPrices := make(map[uuid.UUID]decimal.Decimal, len(req.Items))
+	for _, item := range req.Items {
+		unitPrices[item.ItemID] = item.UnitPrice
+	}
+	if len(unitPrices) != len(quote.Items) {
+		return nil, model.NewInvalidPricingError("every quote item must be priced exactly once")
+	}
+
+	quotedTotal := decimal.Zero
+	for i := range quote.Items {
+		item := &quote.Items[i]
+		price, ok := unitPrices[item.ID]
+		if !ok {
+			return nil, model.NewInvalidPricingError(fmt.Sprintf("missing unit_price for item %s", item.ID))
+		}
+		item.QuotedUnitPrice = &price
+		quotedTotal = quotedTotal.Add(price.Mul(decimal.NewFromInt(int64(item.Quantity))))
+	}
+
+	now := time.Now()
+	validUntil := now.AddDate(0, 0, req.ValidDays)
+	quote.Status = model.StatusApproved
+	quote.PaymentTermsDays = &req.PaymentTermsDays
+	quote.QuotedTotal = &quotedTotal
+	quote.ValidUntil = &validUntil
+	quote.QuotedBy = &adminID
+	quote.QuotedAt = &now
+	if req.AdminNote != nil {
+		quote.AdminNote = req.AdminNote
+	}
+
+	if err := s.quoteRepo.ApproveQuote(ctx, quote); err != nil {
+		if errors.Is(err, model.ErrInvalidTransition) {
+			return nil, model.NewInvalidTransitionError(model.StatusPending, "approve")
+		}
+		return nil, err
+	}
+
+	return toQuoteResponse(quote, now), nil
+}
+
+func (s *quoteService) RejectQuote(ctx context.Context, quoteID uuid.UUID, req model.RejectQuoteRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	quote, err := s.getQuote(ctx, quoteID)
+	if err != nil {
+		return err
+	}
+
+	err = s.quoteRepo.UpdateStatus(ctx, quoteID, []string{model.StatusPending}, model.StatusRejected, &req.Reason)
+	if errors.Is(err, model.ErrInvalidTransition) {
+		return model.NewInvalidTransitionError(quote.Status, "reject")
+	}
+	return err
+}
+
+// MarkInvoicePaid - kế toán xác nhận khách đã thanh toán công nợ
+func (s *quoteService) MarkInvoicePaid(ctx context.Context, quoteID uuid.UUID) error {
+	quote, err := s.getQuote(ctx, quoteID)
+	if err != nil {
+		return err
+	}
+	if quote.Status != model.StatusConverted || quote.ConvertedOrderID == nil {
+		return model.NewInvalidTransitionError(quote.Status, "mark paid")
+	}
+
+	return s.orderService.MarkInvoicePaid(ctx, *quote.ConvertedOrderID)
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+func (s *quoteService) getQuote(ctx context.Context, quoteID uuid.UUID) (*model.Quote, error) {
+	quote, err := s.quoteRepo.GetQuoteByID(ctx, quoteID)
+	if err != nil {
+		if errors.Is(err, model.ErrQuoteNotFound) {
+			return nil, model.NewQuoteNotFoundError()
+		}
+		return nil, err
+	}
+	return quote, nil
+}
+
+// getOwnedQuote - quote của user khác trả 404 (không lộ tồn tại)
+func (s *quoteService) getOwnedQuote(ctx context.Context, userID, quoteID uuid.UUID) (*model.Quote, error) {
+	quote, err := s.getQuote(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.UserID != userID {
+		return nil, model.NewQuoteNotFoundError()
+	}
+	return quote, nil
+}
+
+func (s *quoteService) listQuotes(
+	ctx context.Context,
+	filters map[string]interface{},
+	page, limit int,
+) (*model.ListQuotesResponse, error) {
+	quotes, total, err := s.quoteRepo.ListQuotes(ctx, filters, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]model.QuoteResponse, len(quotes))
+	for i, quote := range quotes {
+		responses[i] = *toQuoteResponse(quote, now)
+	}
+
+	return &model.ListQuotesResponse{
+		Quotes:     responses,
+		Pagination: model.NewPaginationMeta(page, limit, total),
+	}, nil
+}
+
+// generateQuoteNumber QT-YYYYMMDD-XXXXXXXX
+func generateQuoteNumber(id uuid.UUID, now time.Time) string {
+	suffix := strings.ToUpper(strings.ReplaceAll(id.String(), "-", "")[:8])
+	return fmt.Sprintf("QT-%s-%s", now.Format("20060102"), suffix)
+}
+
+func toQuoteResponse(quote *model.Quote, now time.Time) *model.QuoteResponse {
+	// Quote approved quá hạn hiển thị expired dù chưa update DB
+	status := quote.Status
+	if quote.IsExpired(now) {
+		status = model.StatusExpired
+	}
+
+	items := make([]model.QuoteItemResponse, len(quote.Items))
+	for i, item := range quote.Items {
+		items[i] = model.QuoteItemResponse{
+			ID:              item.ID,
+			BookID:          item.BookID,
+			BookTitle:       item.BookTitle,
+			Quantity:        item.Quantity,
+			ListPrice:       item.ListPrice,
+			QuotedUnitPrice: item.QuotedUnitPrice,
+		}
+	}
+
+	return &model.QuoteResponse{
+		ID:               quote.ID,
+		QuoteNumber:      quote.QuoteNumber,
+		UserID:           quote.UserID,
+		AddressID:        quote.AddressID,
+		CompanyName:      quote.CompanyName,
+		TaxCode:          quote.TaxCode,
+		ContactName:      quote.ContactName,
+		ContactEmail:     quote.ContactEmail,
+		ContactPhone:     quote.ContactPhone,
+		Status:           status,
+		CustomerNote:     quote.CustomerNote,
+		AdminNote:        quote.AdminNote,
+		PaymentTermsDays: quote.PaymentTermsDays,
+		TotalQuantity:    quote.TotalQuantity,
+		ListTotal:        quote.ListTotal,
+		QuotedTotal:      quote.QuotedTotal,
+		ValidUntil:       quote.ValidUntil,
+		QuotedAt:         quote.QuotedAt,
+		ConvertedOrderID: quote.ConvertedOrderID,
+		Items:            items,
+		CreatedAt:        quote.CreatedAt,
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/recommendation/model"
+	"bookstore-backend/internal/domains/recommendation/service"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/response"
+	"bookstore-backend/pkg/logger"
+)
+
+type Handler struct {
+	service service.ServiceInterface
+}
+
+func NewHandler(service service.ServiceInterface) *Handler {
+	return &Handler{service: service}
+}
+
+// TrackBookView - middleware gắn trước GetBookDetail (cần OptionalAuthMiddleware).
+// Chỉ ghi nhận khi user đã login và book detail trả về thành công.
+func (h *Handler) TrackBookView(c *gin.Context) {
+	c.Next()
+
+	if c.Writer.Status() < http.StatusOK || c.Writer.Status() >= http.StatusMultipleChoices {
+		return
+	}
+
+	userID, ok := middleware.GetAuthenticatedUserID(c)
+	if !ok {
+		return
+	}
+
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	// Async: không làm chậm response
+	go func(userID, bookID uuid.UUID) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := h.service.RecordView(ctx, userID, bookID); err != nil {
+			logger.Error("Failed to record book view", err)
+		}
+	}(*userID, bookID)
+}
+
+// GetFeed - GET /users/me/feed
+func (h *Handler) GetFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.service.GetFeed(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to build feed", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get feed successfully", feed)
+}
+
+// ListRecentlyViewed - GET /users/me/recently-viewed
+func (h *Handler) ListRecentlyViewed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.ListRecentlyViewed(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get recently viewed books", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get recently viewed books successfully", items)
+}
+
+// GetRelatedBooks - GET /books/:id/related?limit=10
+func (h *Handler) GetRelatedBooks(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	related, err := h.service.GetRelatedBooks(c.Request.Context(), bookID, limit)
+	if err != nil {
+		if errors.Is(err, model.ErrBookNotFound) {
+			response.Error(c, http.StatusNotFound, "Book not found", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get related books", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get related books successfully", related)
+}
+
+// GetRecommendations - GET /users/me/recommendations
+func (h *Handler) GetRecommendations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	recs, err := h.service.GetRecommendations(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to build recommendations", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get recommendations successfully", recs)
+}
+
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, ok := c.Get("user_id")
+	if ok {
+		if id, ok := userID.(uuid.UUID); ok {
+			return id, true
+		}
+	}
+	response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+	return uuid.Nil, false
+}
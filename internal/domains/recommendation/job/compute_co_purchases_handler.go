@@ -0,0 +1,35 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/recommendation/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// ComputeCoPurchasesHandler tính lại cặp sách hay được mua cùng (book_recommendations)
+// cho GET /books/:id/related và GET /users/me/recommendations
+type ComputeCoPurchasesHandler struct {
+	service service.ServiceInterface
+}
+
+func NewComputeCoPurchasesHandler(service service.ServiceInterface) *ComputeCoPurchasesHandler {
+	return &ComputeCoPurchasesHandler{service: service}
+}
+
+func (h *ComputeCoPurchasesHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	stats, err := h.service.ComputeCoPurchases(ctx)
+	if err != nil {
+		return fmt.Errorf("compute co-purchases: %w", err)
+	}
+
+	logger.Info("Completed ComputeCoPurchases job", map[string]interface{}{
+		"pairs":       stats.Pairs,
+		"computed_at": stats.ComputedAt,
+	})
+
+	return nil
+}
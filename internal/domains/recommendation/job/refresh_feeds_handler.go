@@ -0,0 +1,53 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/recommendation/service"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// RefreshFeedsPayload - số user tối đa mỗi lần chạy
+type RefreshFeedsPayload struct {
+	Limit int `json:"limit"`
+}
+
+const defaultRefreshFeedsLimit = 1000
+
+// RefreshFeedsHandler tính lại "For you" feed cho user hoạt động gần đây,
+// để request GET /users/me/feed luôn hit cache.
+type RefreshFeedsHandler struct {
+	service service.ServiceInterface
+}
+
+func NewRefreshFeedsHandler(service service.ServiceInterface) *RefreshFeedsHandler {
+	return &RefreshFeedsHandler{service: service}
+}
+
+func (h *RefreshFeedsHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload RefreshFeedsPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		logger.Error("Failed to unmarshal refresh_feeds payload, using default limit", err)
+	}
+
+	limit := payload.Limit
+	if limit <= 0 {
+		limit = defaultRefreshFeedsLimit
+	}
+
+	refreshed, err := h.service.RefreshActiveFeeds(ctx, limit)
+	if err != nil {
+		return fmt.Errorf("refresh active feeds: %w", err)
+	}
+
+	logger.Info("Completed RefreshFeeds job", map[string]interface{}{
+		"limit":     limit,
+		"refreshed": refreshed,
+	})
+
+	return nil
+}
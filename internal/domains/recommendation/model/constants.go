@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// Feed sizing & diversity
+const (
+	FeedSize          = 20
+	CandidatePoolSize = 150
+	MaxPerCategory    = 4 // tối đa N sách cùng category trong feed
+	MaxPerAuthor      = 2 // tối đa N sách cùng tác giả trong feed
+	MaxAffinities     = 10
+	RecentlyViewedMax = 20
+)
+
+// Related books ("customers also bought" / "similar books")
+const (
+	RelatedDefaultLimit = 10
+	RelatedMaxLimit     = 30
+	RelatedCacheTTL     = time.Hour
+
+	// Co-purchase: chỉ tính đơn trong CoPurchaseWindow, cặp xuất hiện ở >= CoPurchaseMinSupport đơn,
+	// mỗi sách giữ top CoPurchasePerBook cặp
+	CoPurchaseWindow     = 365 * 24 * time.Hour
+	CoPurchaseMinSupport = 2
+	CoPurchasePerBook    = 30
+
+	// Gợi ý cá nhân: lấy cặp của tối đa N sách mua gần nhất
+	RecommendationSeedBooks = 20
+	RecommendationCacheTTL  = 6 * time.Hour
+)
+
+// Signal weights: mua > wishlist > xem
+const (
+	WeightView     = 1.0
+	WeightWishlist = 3.0
+	WeightPurchase = 5.0
+)
+
+// Windows
+const (
+	SignalWindow     = 90 * 24 * time.Hour // chỉ dùng lượt xem trong 90 ngày
+	ActiveUserWindow = 7 * 24 * time.Hour  // worker recompute cho user hoạt động 7 ngày gần nhất
+	FeedCacheTTL     = 6 * time.Hour
+)
+
+// Feed reasons
+const (
+	ReasonCategory   = "similar_category"
+	ReasonAuthor     = "same_author"
+	ReasonPopular    = "popular"
+	ReasonAlsoBought = "also_bought"
+)
+
+// FeedCacheKey - cache key của feed theo user
+func FeedCacheKey(userID string) string {
+	return "feed:user:" + userID
+}
+
+// RelatedCacheKey - cache key sách liên quan theo book
+func RelatedCacheKey(bookID string) string {
+	return "related:book:" + bookID
+}
+
+// RecommendationsCacheKey - cache key gợi ý cá nhân theo user
+func RecommendationsCacheKey(userID string) string {
+	return "recs:user:" + userID
+}
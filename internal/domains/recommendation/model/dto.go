@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedItem - 1 sách trong feed "For you"
+type FeedItem struct {
+	Book   BookSummary `json:"book"`
+	Reason string      `json:"reason"` // similar_category, same_author, popular
+	Score  float64     `json:"score"`
+}
+
+// FeedResponse personalized feed
+type FeedResponse struct {
+	Items       []FeedItem `json:"items"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// RelatedBook - 1 sách liên quan; Support chỉ có với also_bought (số đơn mua cùng)
+type RelatedBook struct {
+	Book    BookSummary `json:"book"`
+	Score   float64     `json:"score"`
+	Support int         `json:"support,omitempty"`
+}
+
+// RelatedBooksResponse - GET /books/:id/related
+type RelatedBooksResponse struct {
+	BookID     uuid.UUID     `json:"book_id"`
+	AlsoBought []RelatedBook `json:"also_bought"` // khách mua sách này cũng mua
+	Similar    []RelatedBook `json:"similar"`     // cùng tác giả / thể loại
+}
+
+// CoPurchaseStats kết quả job tính cặp mua cùng
+type CoPurchaseStats struct {
+	Pairs      int64     `json:"pairs"`
+	ComputedAt time.Time `json:"computed_at"`
+}
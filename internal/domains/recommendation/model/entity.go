@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookSummary - thông tin sách rút gọn dùng cho feed/wishlist/recently viewed
+type BookSummary struct {
+	ID           uuid.UUID  `json:"id"`
+	Title        string     `json:"title"`
+	Slug         string     `json:"slug"`
+	Price        float64    `json:"price"`
+	CoverURL     *string    `json:"cover_url"`
+	AuthorID     uuid.UUID  `json:"author_id"`
+	AuthorName   string     `json:"author_name"`
+	CategoryID   *uuid.UUID `json:"category_id"`
+	CategoryName *string    `json:"category_name"`
+	SoldCount    int        `json:"-"`
+}
+
+// Affinity - trọng số sở thích của user theo (category, author)
+type Affinity struct {
+	CategoryID *uuid.UUID
+	AuthorID   uuid.UUID
+	Weight     float64
+}
+
+// ViewedBook recently viewed entry
+type ViewedBook struct {
+	Book         BookSummary `json:"book"`
+	LastViewedAt time.Time   `json:"last_viewed_at"`
+}
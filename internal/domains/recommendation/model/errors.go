@@ -0,0 +1,7 @@
+package model
+
+import "errors"
+
+var (
+	ErrBookNotFound = errors.New("book not found")
+)
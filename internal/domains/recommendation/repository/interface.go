@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/recommendation/model"
+)
+
+// Repository - personalization signals (views, wishlist, orders) + candidate books
+// Wishlist ghi/đọc ở domain wishlist, feed chỉ đọc bảng wishlists làm tín hiệu
+type Repository interface {
+	// Signals
+	RecordView(ctx context.Context, userID, bookID uuid.UUID) error
+	ListRecentlyViewed(ctx context.Context, userID uuid.UUID, limit int) ([]model.ViewedBook, error)
+
+	// Feed
+	GetAffinities(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]model.Affinity, error)
+	ListCandidates(ctx context.Context, userID uuid.UUID, categoryIDs, authorIDs []uuid.UUID, limit int) ([]model.BookSummary, error)
+	ListPopular(ctx context.Context, userID uuid.UUID, limit int) ([]model.BookSummary, error)
+	ListActiveUserIDs(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error)
+
+	// Related books: cặp mua cùng tính sẵn (job đêm) + sách cùng tác giả / thể loại
+	RebuildCoPurchases(ctx context.Context, since time.Time, minSupport, perBook int) (int64, error)
+	ListAlsoBought(ctx context.Context, bookID uuid.UUID, limit int) ([]model.RelatedBook, error)
+	ListSimilar(ctx context.Context, bookID uuid.UUID, limit int) ([]model.RelatedBook, error)
+	ListCoPurchasedForUser(ctx context.Context, userID uuid.UUID, seedLimit, limit int) ([]model.RelatedBook, error)
+
+	BookExists(ctx context.Context, bookID uuid.UUID) (bool, error)
+}
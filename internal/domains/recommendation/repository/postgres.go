@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/recommendation/model"
+)
+
+type postgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) Repository {
+	return &postgresRepository{pool: pool}
+}
+
+// bookSummaryColumns - dùng chung cho mọi query trả về BookSummary (alias b, a, c)
+const bookSummaryColumns = `
+	b.id, b.title, b.slug, b.price, b.cover_url,
+	b.author_id, COALESCE(a.name, ''), b.category_id, c.name, COALESCE(b.sold_count, 0)
+`
+
+const bookSummaryJoins = `
+	LEFT JOIN authors a ON a.id = b.author_id
+	LEFT JOIN categories c ON c.id = b.category_id
+`
+
+// purchasedStatuses - đơn đã xác nhận trở đi được tính là "đã mua"
+const purchasedStatuses = `('confirmed', 'processing', 'shipped', 'delivered')`
+
+func scanBookSummary(row pgx.Row, extra ...interface{}) (model.BookSummary, error) {
+	var b model.BookSummary
+	dest := []interface{}{
+		&b.ID, &b.Title, &b.Slug, &b.Price, &b.CoverURL,
+		&b.AuthorID, &b.AuthorName, &b.CategoryID, &b.CategoryName, &b.SoldCount,
+	}
+	dest = append(dest, extra...)
+	err := row.Scan(dest...)
+	return b, err
+}
+
+// =====================================================
+// VIEWS
+// =====================================================
+
+func (r *postgresRepository) RecordView(ctx context.Context, userID, bookID uuid.UUID) error {
+	query := `
+		INSERT INTO user_book_views (user_id, book_id, view_count, last_viewed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (user_id, book_id) DO UPDATE
+		SET view_count = user_book_views.view_count + 1,
+			last_viewed_at = NOW()
+	`
+	if _, err := r.pool.Exec(ctx, query, userID, bookID); err != nil {
+		return fmt.Errorf("record view: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListRecentlyViewed(ctx context.Context, userID uuid.UUID, limit int) ([]model.ViewedBook, error) {
+	query := `
+		SELECT ` + bookSummaryColumns + `, v.last_viewed_at
+		FROM user_book_views v
+		JOIN books b ON b.id = v.book_id
+		` + bookSummaryJoins + `
+		WHERE v.user_id = $1 AND b.deleted_at IS NULL AND b.is_active = true
+		ORDER BY v.last_viewed_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list recently viewed: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.ViewedBook{}
+	for rows.Next() {
+		var viewedAt time.Time
+		book, err := scanBookSummary(rows, &viewedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan recently viewed: %w", err)
+		}
+		items = append(items, model.ViewedBook{Book: book, LastViewedAt: viewedAt})
+	}
+	return items, rows.Err()
+}
+
+// =====================================================
+// FEED
+// =====================================================
+
+// GetAffinities gộp 3 nguồn tín hiệu thành trọng số theo (category, author)
+func (r *postgresRepository) GetAffinities(
+	ctx context.Context,
+	userID uuid.UUID,
+	since time.Time,
+	limit int,
+) ([]model.Affinity, error) {
+	query := `
+		WITH signals AS (
+			SELECT book_id, $3::float8 * LEAST(view_count, 5) AS weight
+			FROM user_book_views
+			WHERE user_id = $1 AND last_viewed_at >= $2
+
+			UNION ALL
+			SELECT book_id, $4::float8
+			FROM wishlists
+			WHERE user_id = $1
+
+			UNION ALL
+			SELECT oi.book_id, $5::float8
+			FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			WHERE o.user_id = $1 AND o.status IN ` + purchasedStatuses + `
+		)
+		SELECT b.category_id, b.author_id, SUM(s.weight) AS weight
+		FROM signals s
+		JOIN books b ON b.id = s.book_id
+		GROUP BY b.category_id, b.author_id
+		ORDER BY weight DESC
+		LIMIT $6
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, since,
+		model.WeightView, model.WeightWishlist, model.WeightPurchase, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get affinities: %w", err)
+	}
+	defer rows.Close()
+
+	var affinities []model.Affinity
+	for rows.Next() {
+		var a model.Affinity
+		if err := rows.Scan(&a.CategoryID, &a.AuthorID, &a.Weight); err != nil {
+			return nil, fmt.Errorf("scan affinity: %w", err)
+		}
+		affinities = append(affinities, a)
+	}
+	return affinities, rows.Err()
+}
+
+// ListCandidates lấy sách cùng category/tác giả, bỏ qua sách user đã mua
+func (r *postgresRepository) ListCandidates(
+	ctx context.Context,
+	userID uuid.UUID,
+	categoryIDs, authorIDs []uuid.UUID,
+	limit int,
+) ([]model.BookSummary, error) {
+	query := `
+		SELECT ` + bookSummaryColumns + `
+		FROM books b
+		` + bookSummaryJoins + `
+		WHERE b.deleted_at IS NULL AND b.is_active = true
+		AND (b.category_id = ANY($2::uuid[]) OR b.author_id = ANY($3::uuid[]))
+		AND NOT EXISTS (
+			SELECT 1 FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			WHERE o.user_id = $1 AND oi.book_id = b.id AND o.status IN ` + purchasedStatuses + `
+		)
+		ORDER BY b.sold_count DESC, b.created_at DESC
+		LIMIT $4
+	`
+
+	return r.queryBooks(ctx, query, userID, uuidStrings(categoryIDs), uuidStrings(authorIDs), limit)
+}
+
+// ListPopular - fallback khi user chưa có tín hiệu hoặc không đủ candidate
+func (r *postgresRepository) ListPopular(ctx context.Context, userID uuid.UUID, limit int) ([]model.BookSummary, error) {
+	query := `
+		SELECT ` + bookSummaryColumns + `
+		FROM books b
+		` + bookSummaryJoins + `
+		WHERE b.deleted_at IS NULL AND b.is_active = true
+		AND NOT EXISTS (
+			SELECT 1 FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			WHERE o.user_id = $1 AND oi.book_id = b.id AND o.status IN ` + purchasedStatuses + `
+		)
+		ORDER BY b.sold_count DESC, b.view_count DESC
+		LIMIT $2
+	`
+
+	return r.queryBooks(ctx, query, userID, limit)
+}
+
+// ListActiveUserIDs - user có xem sách hoặc đặt hàng trong khoảng thời gian
+func (r *postgresRepository) ListActiveUserIDs(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT user_id FROM (
+			SELECT user_id, MAX(last_viewed_at) AS last_active
+			FROM user_book_views
+			WHERE last_viewed_at >= $1
+			GROUP BY user_id
+
+			UNION ALL
+			SELECT user_id, MAX(created_at)
+			FROM orders
+			WHERE created_at >= $1
+			GROUP BY user_id
+		) active
+		GROUP BY user_id
+		ORDER BY MAX(last_active) DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list active users: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan active user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *postgresRepository) BookExists(ctx context.Context, bookID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM books WHERE id = $1 AND deleted_at IS NULL)`
+	if err := r.pool.QueryRow(ctx, query, bookID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check book: %w", err)
+	}
+	return exists, nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+func (r *postgresRepository) queryBooks(ctx context.Context, query string, args ...interface{}) ([]model.BookSummary, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query books: %w", err)
+	}
+	defer rows.Close()
+
+	var books []model.BookSummary
+	for rows.Next() {
+		book, err := scanBookSummary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan book: %w", err)
+		}
+		books = append(books, book)
+	}
+	return books, rows.Err()
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
+}
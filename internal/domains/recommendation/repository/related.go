@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"bookstore-backend/internal/domains/recommendation/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// RELATED BOOKS
+// =====================================================
+
+// RebuildCoPurchases tính lại toàn bộ book_recommendations trong 1 transaction
+// (request đọc thấy bộ cũ tới khi commit, không thấy bảng rỗng)
+func (r *postgresRepository) RebuildCoPurchases(ctx context.Context, since time.Time, minSupport, perBook int) (int64, error) {
+	return database.WithTransactionResult(ctx, r.pool, func(tx pgx.Tx) (int64, error) {
+		if _, err := tx.Exec(ctx, `DELETE FROM book_recommendations`); err != nil {
+			return 0, fmt.Errorf("clear co-purchases: %w", err)
+		}
+
+		// Mỗi (đơn, sách) đếm 1 lần; cặp (A, B) và (B, A) đều ghi để đọc theo book_id
+		query := `
+			WITH order_books AS (
+				SELECT DISTINCT oi.order_id, oi.book_id
+				FROM orders o
+				JOIN order_items oi ON oi.order_id = o.id
+				WHERE o.status IN ` + purchasedStatuses + ` AND o.created_at >= $1
+			),
+			book_orders AS (
+				SELECT book_id, COUNT(*) AS orders
+				FROM order_books
+				GROUP BY book_id
+			),
+			pairs AS (
+				SELECT a.book_id, b.book_id AS related_book_id, COUNT(*) AS support
+				FROM order_books a
+				JOIN order_books b ON b.order_id = a.order_id AND b.book_id <> a.book_id
+				GROUP BY a.book_id, b.book_id
+				HAVING COUNT(*) >= $2
+			),
+			ranked AS (
+				SELECT p.book_id, p.related_book_id, p.support,
+					p.support / SQRT(ba.orders::float8 * bb.orders) AS score
+				FROM pairs p
+				JOIN book_orders ba ON ba.book_id = p.book_id
+				JOIN book_orders bb ON bb.book_id = p.related_book_id
+			),
+			top AS (
+				SELECT *, ROW_NUMBER() OVER (PARTITION BY book_id ORDER BY score DESC, support DESC) AS rn
+				FROM ranked
+			)
+			INSERT INTO book_recommendations (book_id, related_book_id, support, score, computed_at)
+			SELECT book_id, related_book_id, support, score, NOW()
+			FROM top
+			WHERE rn <= $3
+		`
+		tag, err := tx.Exec(ctx, query, since, minSupport, perBook)
+		if err != nil {
+			return 0, fmt.Errorf("compute co-purchases: %w", err)
+		}
+		return tag.RowsAffected(), nil
+	})
+}
+
+// ListAlsoBought sách hay được mua cùng bookID (đã tính sẵn)
+func (r *postgresRepository) ListAlsoBought(ctx context.Context, bookID uuid.UUID, limit int) ([]model.RelatedBook, error) {
+	query := `
+		SELECT ` + bookSummaryColumns + `, br.score::float8, br.support
+		FROM book_recommendations br
+		JOIN books b ON b.id = br.related_book_id
+		` + bookSummaryJoins + `
+		WHERE br.book_id = $1 AND b.deleted_at IS NULL AND b.is_active = true
+		ORDER BY br.score DESC, br.support DESC
+		LIMIT $2
+	`
+	return r.queryRelated(ctx, query, bookID, limit)
+}
+
+// ListSimilar sách cùng tác giả / thể loại: cùng tác giả xếp trước, rồi tới bán chạy
+func (r *postgresRepository) ListSimilar(ctx context.Context, bookID uuid.UUID, limit int) ([]model.RelatedBook, error) {
+	query := `
+		WITH src AS (
+			SELECT author_id, category_id FROM books WHERE id = $1
+		)
+		SELECT ` + bookSummaryColumns + `,
+			(CASE WHEN b.author_id = src.author_id THEN 2 ELSE 0 END
+			 + CASE WHEN b.category_id = src.category_id THEN 1 ELSE 0 END)::float8 AS score,
+			0 AS support
+		FROM books b
+		CROSS JOIN src
+		` + bookSummaryJoins + `
+		WHERE b.id <> $1 AND b.deleted_at IS NULL AND b.is_active = true
+		AND (b.author_id = src.author_id OR b.category_id = src.category_id)
+		ORDER BY score DESC, b.sold_count DESC, b.created_at DESC
+		LIMIT $2
+	`
+	return r.queryRelated(ctx, query, bookID, limit)
+}
+
+// ListCoPurchasedForUser cộng dồn cặp mua cùng của seedLimit sách user mua gần nhất,
+// bỏ qua sách đã mua
+func (r *postgresRepository) ListCoPurchasedForUser(ctx context.Context, userID uuid.UUID, seedLimit, limit int) ([]model.RelatedBook, error) {
+	query := `
+		WITH purchased AS (
+			SELECT oi.book_id, MAX(o.created_at) AS last_bought
+			FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			WHERE o.user_id = $1 AND o.status IN ` + purchasedStatuses + `
+			GROUP BY oi.book_id
+		),
+		seeds AS (
+			SELECT book_id FROM purchased ORDER BY last_bought DESC LIMIT $2
+		),
+		scored AS (
+			SELECT br.related_book_id, SUM(br.score)::float8 AS score, SUM(br.support)::int AS support
+			FROM book_recommendations br
+			JOIN seeds s ON s.book_id = br.book_id
+			WHERE br.related_book_id NOT IN (SELECT book_id FROM purchased)
+			GROUP BY br.related_book_id
+		)
+		SELECT ` + bookSummaryColumns + `, sc.score, sc.support
+		FROM scored sc
+		JOIN books b ON b.id = sc.related_book_id
+		` + bookSummaryJoins + `
+		WHERE b.deleted_at IS NULL AND b.is_active = true
+		ORDER BY sc.score DESC, sc.support DESC
+		LIMIT $3
+	`
+	return r.queryRelated(ctx, query, userID, seedLimit, limit)
+}
+
+func (r *postgresRepository) queryRelated(ctx context.Context, query string, args ...interface{}) ([]model.RelatedBook, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query related books: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.RelatedBook{}
+	for rows.Next() {
+		var item model.RelatedBook
+		book, err := scanBookSummary(rows, &item.Score, &item.Support)
+		if err != nil {
+			return nil, fmt.Errorf("scan related book: %w", err)
+		}
+		item.Book = book
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
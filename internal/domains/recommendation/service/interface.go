@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/recommendation/model"
+)
+
+type ServiceInterface interface {
+	// RecordView lưu lượt xem sách của user (recently viewed)
+	RecordView(ctx context.Context, userID, bookID uuid.UUID) error
+	ListRecentlyViewed(ctx context.Context, userID uuid.UUID) ([]model.ViewedBook, error)
+
+	// InvalidateFeed bỏ feed đã cache khi tín hiệu mạnh thay đổi (wishlist)
+	InvalidateFeed(ctx context.Context, userID uuid.UUID)
+
+	// GetFeed trả feed "For you" (cache-first)
+	GetFeed(ctx context.Context, userID uuid.UUID) (*model.FeedResponse, error)
+
+	// RefreshFeed tính lại feed và ghi đè cache
+	RefreshFeed(ctx context.Context, userID uuid.UUID) (*model.FeedResponse, error)
+
+	// RefreshActiveFeeds tính lại feed cho user hoạt động gần đây (worker)
+	RefreshActiveFeeds(ctx context.Context, limit int) (int, error)
+
+	// GetRelatedBooks "customers also bought" + "similar books" của 1 sách
+	GetRelatedBooks(ctx context.Context, bookID uuid.UUID, limit int) (*model.RelatedBooksResponse, error)
+
+	// GetRecommendations gợi ý cá nhân từ lịch sử mua (co-purchase) + thể loại / tác giả
+	GetRecommendations(ctx context.Context, userID uuid.UUID) (*model.FeedResponse, error)
+
+	// ComputeCoPurchases tính lại cặp mua cùng từ order_items (worker, mỗi đêm)
+	ComputeCoPurchases(ctx context.Context) (*model.CoPurchaseStats, error)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/recommendation/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// RELATED BOOKS
+// =====================================================
+
+// GetRelatedBooks "customers also bought" + "similar books" của 1 sách (cache-first)
+// Cache luôn giữ RelatedMaxLimit phần tử, cắt theo limit khi trả
+func (s *service) GetRelatedBooks(ctx context.Context, bookID uuid.UUID, limit int) (*model.RelatedBooksResponse, error) {
+	if limit <= 0 {
+		limit = model.RelatedDefaultLimit
+	}
+	if limit > model.RelatedMaxLimit {
+		limit = model.RelatedMaxLimit
+	}
+
+	var related model.RelatedBooksResponse
+	cacheKey := model.RelatedCacheKey(bookID.String())
+	found, err := s.cache.Get(ctx, cacheKey, &related)
+	if err != nil {
+		logger.Error("Related books cache GET error", err)
+	}
+	if !found {
+		exists, err := s.repo.BookExists(ctx, bookID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, model.ErrBookNotFound
+		}
+
+		alsoBought, err := s.repo.ListAlsoBought(ctx, bookID, model.RelatedMaxLimit)
+		if err != nil {
+			return nil, err
+		}
+		similar, err := s.repo.ListSimilar(ctx, bookID, model.RelatedMaxLimit)
+		if err != nil {
+			return nil, err
+		}
+		related = model.RelatedBooksResponse{
+			BookID:     bookID,
+			AlsoBought: alsoBought,
+			Similar:    excludeRelated(similar, alsoBought),
+		}
+
+		if err := s.cache.Set(ctx, cacheKey, related, model.RelatedCacheTTL); err != nil {
+			logger.Error("Related books cache SET error", err)
+		}
+	}
+
+	related.AlsoBought = truncateRelated(related.AlsoBought, limit)
+	related.Similar = truncateRelated(related.Similar, limit)
+	return &related, nil
+}
+
+// GetRecommendations gợi ý cá nhân (cache-first):
+// 1. Sách hay được mua cùng các sách user đã mua (co-purchase)
+// 2. Bù bằng feed theo lịch sử thể loại / tác giả (GetFeed, đã gồm fallback bán chạy)
+// Cùng giới hạn diversity với feed
+func (s *service) GetRecommendations(ctx context.Context, userID uuid.UUID) (*model.FeedResponse, error) {
+	var cached model.FeedResponse
+	cacheKey := model.RecommendationsCacheKey(userID.String())
+	found, err := s.cache.Get(ctx, cacheKey, &cached)
+	if err != nil {
+		logger.Error("Recommendations cache GET error", err)
+	}
+	if found {
+		return &cached, nil
+	}
+
+	coPurchased, err := s.repo.ListCoPurchasedForUser(ctx, userID, model.RecommendationSeedBooks, model.CandidatePoolSize)
+	if err != nil {
+		return nil, err
+	}
+	alsoBought := make([]model.FeedItem, 0, len(coPurchased))
+	for _, r := range coPurchased {
+		alsoBought = append(alsoBought, model.FeedItem{
+			Book:   r.Book,
+			Reason: model.ReasonAlsoBought,
+			Score:  r.Score,
+		})
+	}
+
+	feed, err := s.GetFeed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := pickDiverse(alsoBought, nil, model.FeedSize, true)
+	items = pickDiverse(feed.Items, items, model.FeedSize, true)
+	if len(items) < model.FeedSize {
+		items = pickDiverse(append(alsoBought, feed.Items...), items, model.FeedSize, false)
+	}
+
+	recs := &model.FeedResponse{
+		Items:       items,
+		GeneratedAt: time.Now(),
+	}
+	if err := s.cache.Set(ctx, cacheKey, recs, model.RecommendationCacheTTL); err != nil {
+		logger.Error("Recommendations cache SET error", err)
+	}
+	return recs, nil
+}
+
+// ComputeCoPurchases tính lại cặp mua cùng từ order_items (job đêm)
+// Cache related / recommendations hết hạn tự nhiên (TTL), không xoá hàng loạt
+func (s *service) ComputeCoPurchases(ctx context.Context) (*model.CoPurchaseStats, error) {
+	pairs, err := s.repo.RebuildCoPurchases(ctx,
+		time.Now().Add(-model.CoPurchaseWindow),
+		model.CoPurchaseMinSupport,
+		model.CoPurchasePerBook,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CoPurchaseStats{Pairs: pairs, ComputedAt: time.Now()}, nil
+}
+
+// excludeRelated bỏ khỏi similar các sách đã có trong also bought (không lặp 2 khối trên trang sách)
+func excludeRelated(items, exclude []model.RelatedBook) []model.RelatedBook {
+	seen := make(map[uuid.UUID]bool, len(exclude))
+	for _, e := range exclude {
+		seen[e.Book.ID] = true
+	}
+	out := make([]model.RelatedBook, 0, len(items))
+	for _, item := range items {
+		if !seen[item.Book.ID] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func truncateRelated(items []model.RelatedBook, limit int) []model.RelatedBook {
+	if len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/recommendation/model"
+	"bookstore-backend/internal/domains/recommendation/repository"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+)
+
+type service struct {
+	repo  repository.Repository
+	cache cache.Cache
+}
+
+func NewService(repo repository.Repository, cache cache.Cache) ServiceInterface {
+	return &service{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+// =====================================================
+// VIEWS
+// =====================================================
+
+func (s *service) RecordView(ctx context.Context, userID, bookID uuid.UUID) error {
+	return s.repo.RecordView(ctx, userID, bookID)
+}
+
+func (s *service) ListRecentlyViewed(ctx context.Context, userID uuid.UUID) ([]model.ViewedBook, error) {
+	return s.repo.ListRecentlyViewed(ctx, userID, model.RecentlyViewedMax)
+}
+
+// =====================================================
+// FEED
+// =====================================================
+
+func (s *service) GetFeed(ctx context.Context, userID uuid.UUID) (*model.FeedResponse, error) {
+	var cached model.FeedResponse
+	found, err := s.cache.Get(ctx, model.FeedCacheKey(userID.String()), &cached)
+	if err != nil {
+		logger.Error("Feed cache GET error", err)
+	}
+	if found {
+		return &cached, nil
+	}
+
+	return s.RefreshFeed(ctx, userID)
+}
+
+func (s *service) RefreshFeed(ctx context.Context, userID uuid.UUID) (*model.FeedResponse, error) {
+	feed, err := s.computeFeed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, model.FeedCacheKey(userID.String()), feed, model.FeedCacheTTL); err != nil {
+		logger.Error("Feed cache SET error", err)
+	}
+
+	return feed, nil
+}
+
+func (s *service) RefreshActiveFeeds(ctx context.Context, limit int) (int, error) {
+	userIDs, err := s.repo.ListActiveUserIDs(ctx, time.Now().Add(-model.ActiveUserWindow), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return refreshed, ctx.Err()
+		}
+		// 1 user lỗi không dừng cả batch
+		if _, err := s.RefreshFeed(ctx, userID); err != nil {
+			logger.Error(fmt.Sprintf("Refresh feed failed for user %s", userID), err)
+			continue
+		}
+		refreshed++
+	}
+
+	return refreshed, nil
+}
+
+// computeFeed:
+// 1. Gộp views/wishlist/orders thành trọng số theo category & tác giả
+// 2. Lấy candidate cùng category/tác giả (bỏ sách đã mua), chấm điểm
+// 3. Chọn greedy theo điểm với giới hạn mỗi category/tác giả (diversity)
+// 4. Thiếu thì bù bằng sách bán chạy
+func (s *service) computeFeed(ctx context.Context, userID uuid.UUID) (*model.FeedResponse, error) {
+	affinities, err := s.repo.GetAffinities(ctx, userID, time.Now().Add(-model.SignalWindow), model.MaxAffinities)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryWeights := make(map[uuid.UUID]float64)
+	authorWeights := make(map[uuid.UUID]float64)
+	for _, a := range affinities {
+		if a.CategoryID != nil {
+			categoryWeights[*a.CategoryID] += a.Weight
+		}
+		authorWeights[a.AuthorID] += a.Weight
+	}
+
+	var scored []model.FeedItem
+	if len(affinities) > 0 {
+		candidates, err := s.repo.ListCandidates(ctx, userID, mapKeys(categoryWeights), mapKeys(authorWeights), model.CandidatePoolSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, book := range candidates {
+			var categoryScore float64
+			if book.CategoryID != nil {
+				categoryScore = categoryWeights[*book.CategoryID]
+			}
+			authorScore := authorWeights[book.AuthorID] * 1.5
+
+			reason := model.ReasonCategory
+			if authorScore > categoryScore {
+				reason = model.ReasonAuthor
+			}
+
+			scored = append(scored, model.FeedItem{
+				Book:   book,
+				Reason: reason,
+				// Popularity chỉ là tie-breaker nhỏ
+				Score: categoryScore + authorScore + math.Log1p(float64(book.SoldCount))*0.1,
+			})
+		}
+
+		sort.SliceStable(scored, func(i, j int) bool {
+			return scored[i].Score > scored[j].Score
+		})
+	}
+
+	items := pickDiverse(scored, nil, model.FeedSize, true)
+
+	if len(items) < model.FeedSize {
+		popular, err := s.repo.ListPopular(ctx, userID, model.CandidatePoolSize)
+		if err != nil {
+			return nil, err
+		}
+
+		fallback := make([]model.FeedItem, 0, len(popular))
+		for _, book := range popular {
+			fallback = append(fallback, model.FeedItem{
+				Book:   book,
+				Reason: model.ReasonPopular,
+				Score:  math.Log1p(float64(book.SoldCount)) * 0.1,
+			})
+		}
+		items = pickDiverse(fallback, items, model.FeedSize, true)
+
+		// Catalog nhỏ: vẫn thiếu thì nới giới hạn diversity
+		if len(items) < model.FeedSize {
+			items = pickDiverse(append(scored, fallback...), items, model.FeedSize, false)
+		}
+	}
+
+	return &model.FeedResponse{
+		Items:       items,
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// pickDiverse append candidates theo thứ tự vào selected, bỏ qua sách trùng.
+// enforceCaps = true: bỏ qua sách vượt giới hạn mỗi category/tác giả.
+func pickDiverse(candidates, selected []model.FeedItem, size int, enforceCaps bool) []model.FeedItem {
+	seen := make(map[uuid.UUID]bool, len(selected))
+	perCategory := make(map[uuid.UUID]int)
+	perAuthor := make(map[uuid.UUID]int)
+
+	count := func(item model.FeedItem) {
+		seen[item.Book.ID] = true
+		if item.Book.CategoryID != nil {
+			perCategory[*item.Book.CategoryID]++
+		}
+		perAuthor[item.Book.AuthorID]++
+	}
+
+	for _, item := range selected {
+		count(item)
+	}
+
+	for _, item := range candidates {
+		if len(selected) >= size {
+			break
+		}
+		if seen[item.Book.ID] {
+			continue
+		}
+		if enforceCaps {
+			if item.Book.CategoryID != nil && perCategory[*item.Book.CategoryID] >= model.MaxPerCategory {
+				continue
+			}
+			if perAuthor[item.Book.AuthorID] >= model.MaxPerAuthor {
+				continue
+			}
+		}
+		count(item)
+		selected = append(selected, item)
+	}
+
+	return selected
+}
+
+// InvalidateFeed - wishlist là tín hiệu mạnh => bỏ feed cũ để lần sau tính lại
+func (s *service) InvalidateFeed(ctx context.Context, userID uuid.UUID) {
+	if err := s.cache.Delete(ctx, model.FeedCacheKey(userID.String())); err != nil {
+		logger.Error("Feed cache DELETE error", err)
+	}
+}
+
+func mapKeys(m map[uuid.UUID]float64) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -36,12 +36,15 @@ func getUserID(c *gin.Context) (uuid.UUID, error) {
 		return uuid.Nil, model.ErrUnauthorized
 	}
 
-	userID, err := uuid.Parse(userIDStr.(string))
-	if err != nil {
-		return uuid.Nil, err
+	// AuthMiddleware set uuid.UUID, giữ fallback string cho token cũ
+	switch v := userIDStr.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrUnauthorized
 	}
-
-	return userID, nil
 }
 
 // =====================================================
@@ -465,6 +468,176 @@ func (h *ReviewHandler) AdminDeleteReview(c *gin.Context) {
 	})
 }
 
+// ReportReview reports abusive review
+// POST /api/v1/reviews/:id/report
+func (h *ReviewHandler) ReportReview(c *gin.Context) {
+	// Step 1: Get user ID from JWT
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	// Step 2: Parse review ID
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid review ID")
+		return
+	}
+
+	// Step 3: Bind request body
+	var req model.ReportReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	// Step 4: Call service
+	response, err := h.reviewService.ReportReview(c.Request.Context(), userID, reviewID, req)
+	if err != nil {
+		statusCode, errCode := mapReviewError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// AdminModerationQueue lists reviews waiting for moderation
+// GET /api/v1/admin/reviews/queue
+func (h *ReviewHandler) AdminModerationQueue(c *gin.Context) {
+	var req model.ModerationQueueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.reviewService.AdminListModerationQueue(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapReviewError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AdminApproveReview approves review
+// POST /api/v1/admin/reviews/:id/approve
+func (h *ReviewHandler) AdminApproveReview(c *gin.Context) {
+	adminID, reviewID, req, ok := h.bindModerationAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.AdminApproveReview(c.Request.Context(), adminID, reviewID, req); err != nil {
+		statusCode, errCode := mapReviewError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Review approved successfully",
+	})
+}
+
+// AdminRejectReview rejects (hides) review
+// POST /api/v1/admin/reviews/:id/reject
+func (h *ReviewHandler) AdminRejectReview(c *gin.Context) {
+	adminID, reviewID, req, ok := h.bindModerationAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.AdminRejectReview(c.Request.Context(), adminID, reviewID, req); err != nil {
+		statusCode, errCode := mapReviewError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Review rejected successfully",
+	})
+}
+
+// AdminFlagReview flags review for follow-up
+// POST /api/v1/admin/reviews/:id/flag
+func (h *ReviewHandler) AdminFlagReview(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid review ID")
+		return
+	}
+
+	var req model.FlagReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.reviewService.AdminFlagReview(c.Request.Context(), adminID, reviewID, req); err != nil {
+		statusCode, errCode := mapReviewError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"message": "Review flagged successfully",
+	})
+}
+
+// AdminListReports lists user reports of a review
+// GET /api/v1/admin/reviews/:id/reports
+func (h *ReviewHandler) AdminListReports(c *gin.Context) {
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid review ID")
+		return
+	}
+
+	reports, err := h.reviewService.AdminListReports(c.Request.Context(), reviewID)
+	if err != nil {
+		statusCode, errCode := mapReviewError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, reports)
+}
+
+// bindModerationAction parses admin ID, review ID and optional admin note
+func (h *ReviewHandler) bindModerationAction(c *gin.Context) (uuid.UUID, uuid.UUID, model.ModerationActionRequest, bool) {
+	var req model.ModerationActionRequest
+
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return uuid.Nil, uuid.Nil, req, false
+	}
+
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid review ID")
+		return uuid.Nil, uuid.Nil, req, false
+	}
+
+	// Body optional (chỉ có admin_note)
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return uuid.Nil, uuid.Nil, req, false
+		}
+	}
+
+	return adminID, reviewID, req, true
+}
+
 // respondSuccess sends success response
 func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
 	c.JSON(statusCode, gin.H{
@@ -498,7 +671,11 @@ func mapReviewError(err error) (int, string) {
 			return http.StatusForbidden, reviewErr.Code
 		case model.ErrCodeUnauthorized:
 			return http.StatusUnauthorized, reviewErr.Code
-		case model.ErrCodeInvalidRating, model.ErrCodeContentTooShort, model.ErrCodeContentTooLong:
+		case model.ErrCodeAlreadyReported:
+			return http.StatusConflict, reviewErr.Code
+		case model.ErrCodeCannotReport:
+			return http.StatusForbidden, reviewErr.Code
+		case model.ErrCodeInvalidRating, model.ErrCodeContentTooShort, model.ErrCodeContentTooLong, model.ErrCodeInvalidReport:
 			return http.StatusBadRequest, reviewErr.Code
 		default:
 			return http.StatusInternalServerError, "INTERNAL_ERROR"
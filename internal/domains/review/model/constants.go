@@ -17,3 +17,39 @@ const (
 	MinRating = 1
 	MaxRating = 5
 )
+
+const (
+	// Report threshold: review bị report >= N lần sẽ tự động flag
+	DefaultAutoFlagReportThreshold = 3
+
+	// Flag reasons
+	FlagReasonBannedWords = "auto_filter: banned words"
+	FlagReasonLinkSpam    = "auto_filter: link spam"
+	FlagReasonUserReports = "user_reports"
+
+	// Moderation status trả về cho user sau create/update
+	ModerationStatusPublished = "published"
+	ModerationStatusPending   = "pending_review"
+)
+
+// Report reasons
+const (
+	ReportReasonSpam      = "spam"
+	ReportReasonOffensive = "offensive"
+	ReportReasonOffTopic  = "off_topic"
+	ReportReasonFake      = "fake"
+	ReportReasonOther     = "other"
+)
+
+// Report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// DefaultBannedWords - danh sách từ cấm cho content filter (so khớp theo từ)
+var DefaultBannedWords = []string{
+	"dm", "đm", "dcm", "đcm", "vcl", "vkl", "clm", "địt", "lồn",
+	"fuck", "shit", "bitch", "asshole",
+}
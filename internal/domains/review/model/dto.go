@@ -96,6 +96,7 @@ type AdminListReviewsRequest struct {
 	Rating     *int       `form:"rating"`
 	IsApproved *bool      `form:"is_approved"`
 	IsFeatured *bool      `form:"is_featured"`
+	IsFlagged  *bool      `form:"is_flagged"`
 	Search     *string    `form:"search"`
 	Page       int        `form:"page"`
 	Limit      int        `form:"limit"`
@@ -117,6 +118,46 @@ type ModerateReviewRequest struct {
 	AdminNote  *string `json:"admin_note"`
 }
 
+// ModerationActionRequest admin request to approve/reject/flag review
+type ModerationActionRequest struct {
+	AdminNote *string `json:"admin_note"`
+}
+
+// FlagReviewRequest admin request to flag review for follow-up
+type FlagReviewRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ReportReviewRequest user request to report abusive review
+type ReportReviewRequest struct {
+	Reason string  `json:"reason" binding:"required"` // spam, offensive, off_topic, fake, other
+	Note   *string `json:"note" binding:"omitempty,max=500"`
+}
+
+func (r *ReportReviewRequest) Validate() error {
+	switch r.Reason {
+	case ReportReasonSpam, ReportReasonOffensive, ReportReasonOffTopic, ReportReasonFake, ReportReasonOther:
+		return nil
+	}
+	return NewInvalidReportError()
+}
+
+// ModerationQueueRequest admin request to list moderation queue
+type ModerationQueueRequest struct {
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
+
+func (r *ModerationQueueRequest) Validate() error {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+	if r.Limit < 1 || r.Limit > 100 {
+		r.Limit = 50
+	}
+	return nil
+}
+
 // FeatureReviewRequest admin request to feature review
 type FeatureReviewRequest struct {
 	IsFeatured bool `json:"is_featured"`
@@ -141,6 +182,9 @@ type ReviewResponse struct {
 	IsVerifiedPurchase bool `json:"is_verified_purchase"`
 	IsFeatured         bool `json:"is_featured"`
 
+	// Chỉ set khi create/update: published | pending_review
+	ModerationStatus string `json:"moderation_status,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -190,7 +234,22 @@ type PaginationMeta struct {
 // AdminReviewResponse admin response with full details
 type AdminReviewResponse struct {
 	ReviewResponse
-	OrderID    uuid.UUID `json:"order_id"`
-	AdminNote  *string   `json:"admin_note"`
-	IsApproved bool      `json:"is_approved"`
+	OrderID     uuid.UUID `json:"order_id"`
+	AdminNote   *string   `json:"admin_note"`
+	IsApproved  bool      `json:"is_approved"`
+	IsFlagged   bool      `json:"is_flagged"`
+	FlagReason  *string   `json:"flag_reason"`
+	ReportCount int       `json:"report_count"`
+}
+
+// ReportReviewResponse response after reporting review
+type ReportReviewResponse struct {
+	ReportID uuid.UUID `json:"report_id"`
+	Message  string    `json:"message"`
+}
+
+// ModerationQueueResponse admin moderation queue
+type ModerationQueueResponse struct {
+	Reviews    []AdminReviewResponse `json:"reviews"`
+	Pagination PaginationMeta        `json:"pagination"`
 }
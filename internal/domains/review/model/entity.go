@@ -24,6 +24,9 @@ type Review struct {
 	IsApproved         bool    `json:"is_approved"`
 	IsFeatured         bool    `json:"is_featured"`
 	AdminNote          *string `json:"admin_note"`
+	IsFlagged          bool    `json:"is_flagged"`
+	FlagReason         *string `json:"flag_reason"`
+	ReportCount        int     `json:"report_count"`
 
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
@@ -41,3 +44,16 @@ func (r *Review) CanBeDeleted() bool {
 	// Can delete within 30 days of creation
 	return time.Since(r.CreatedAt) < 30*24*time.Hour
 }
+
+// ReviewReport represents a user abuse report on a review
+type ReviewReport struct {
+	ID         uuid.UUID  `json:"id"`
+	ReviewID   uuid.UUID  `json:"review_id"`
+	ReporterID uuid.UUID  `json:"reporter_id"`
+	Reason     string     `json:"reason"` // spam, offensive, off_topic, fake, other
+	Note       *string    `json:"note"`
+	Status     string     `json:"status"` // open, resolved, dismissed
+	ResolvedBy *uuid.UUID `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -17,6 +17,9 @@ const (
 	ErrCodeContentTooLong  = "REV008"
 	ErrCodeTooManyImages   = "REV009"
 	ErrCodeUnauthorized    = "REV010"
+	ErrCodeAlreadyReported = "REV011"
+	ErrCodeCannotReport    = "REV012"
+	ErrCodeInvalidReport   = "REV013"
 )
 
 // Errors
@@ -27,6 +30,9 @@ var (
 	ErrCannotEdit      = errors.New("cannot edit review after 7 days")
 	ErrCannotDelete    = errors.New("cannot delete review after 30 days")
 	ErrUnauthorized    = errors.New("unauthorized to perform this action")
+	ErrAlreadyReported = errors.New("already reported this review")
+	ErrCannotReport    = errors.New("cannot report this review")
+	ErrInvalidReport   = errors.New("invalid report reason")
 )
 
 // ReviewError custom error type
@@ -90,3 +96,27 @@ func NewUnauthorizedError(message string) *ReviewError {
 		Err:     ErrCannotEdit,
 	}
 }
+
+func NewAlreadyReportedError() *ReviewError {
+	return &ReviewError{
+		Code:    ErrCodeAlreadyReported,
+		Message: "You have already reported this review",
+		Err:     ErrAlreadyReported,
+	}
+}
+
+func NewCannotReportError(message string) *ReviewError {
+	return &ReviewError{
+		Code:    ErrCodeCannotReport,
+		Message: message,
+		Err:     ErrCannotReport,
+	}
+}
+
+func NewInvalidReportError() *ReviewError {
+	return &ReviewError{
+		Code:    ErrCodeInvalidReport,
+		Message: "Reason must be one of: spam, offensive, off_topic, fake, other",
+		Err:     ErrInvalidReport,
+	}
+}
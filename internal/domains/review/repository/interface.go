@@ -73,12 +73,28 @@ type ReviewRepository interface {
 	// AdminListReviews lists all reviews with admin filters
 	AdminListReviews(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*model.Review, int, error)
 
-	// UpdateModeration updates moderation status
-	UpdateModeration(ctx context.Context, id uuid.UUID, isApproved bool, adminNote *string) error
+	// UpdateModeration updates moderation status (approve/reject/flag)
+	UpdateModeration(ctx context.Context, id, moderatorID uuid.UUID, isApproved, isFlagged bool, flagReason, adminNote *string) error
 
 	// UpdateFeatured updates featured status
 	UpdateFeatured(ctx context.Context, id uuid.UUID, isFeatured bool) error
 
 	// GetPendingCount gets count of pending reviews (for admin dashboard)
 	GetPendingCount(ctx context.Context) (int, error)
+
+	// ========================================
+	// MODERATION QUEUE & ABUSE REPORTS
+	// ========================================
+
+	// ListModerationQueue lists unapproved or flagged reviews (most reported first)
+	ListModerationQueue(ctx context.Context, page, limit int) ([]*model.Review, int, error)
+
+	// CreateReport saves user report, returns whether review is now flagged
+	CreateReport(ctx context.Context, report *model.ReviewReport, autoFlagThreshold int) (bool, error)
+
+	// ListReports lists reports of a review
+	ListReports(ctx context.Context, reviewID uuid.UUID) ([]*model.ReviewReport, error)
+
+	// CloseOpenReports marks all open reports as resolved/dismissed
+	CloseOpenReports(ctx context.Context, reviewID, resolvedBy uuid.UUID, status string) error
 }
@@ -7,10 +7,12 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/lib/pq"
 
 	"bookstore-backend/internal/domains/review/model"
+	"bookstore-backend/pkg/database"
 )
 
 // =====================================================
@@ -35,8 +37,9 @@ func (r *postgresReviewRepository) Create(ctx context.Context, review *model.Rev
 			id, user_id, book_id, order_id,
 			rating, title, content, images,
 			is_verified_purchase, is_approved, is_featured,
+			is_flagged, flag_reason,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
@@ -49,8 +52,10 @@ func (r *postgresReviewRepository) Create(ctx context.Context, review *model.Rev
 		review.Content,
 		pq.Array(review.Images),
 		review.IsVerifiedPurchase,
-		review.IsApproved, // Auto-approve = true by default
+		review.IsApproved, // Auto-approve unless content filter flagged it
 		review.IsFeatured,
+		review.IsFlagged,
+		review.FlagReason,
 		review.CreatedAt,
 		review.UpdatedAt,
 	)
@@ -76,6 +81,7 @@ func (r *postgresReviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 			id, user_id, book_id, order_id,
 			rating, title, content, images,
 			is_verified_purchase, is_approved, is_featured, admin_note,
+			COALESCE(is_flagged, false), flag_reason, report_count,
 			created_at, updated_at
 		FROM reviews
 		WHERE id = $1
@@ -97,6 +103,9 @@ func (r *postgresReviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 		&review.IsApproved,
 		&review.IsFeatured,
 		&review.AdminNote,
+		&review.IsFlagged,
+		&review.FlagReason,
+		&review.ReportCount,
 		&review.CreatedAt,
 		&review.UpdatedAt,
 	)
@@ -173,6 +182,9 @@ func (r *postgresReviewRepository) Update(ctx context.Context, review *model.Rev
 			title = $3,
 			content = $4,
 			images = $5,
+			is_approved = $6,
+			is_flagged = $7,
+			flag_reason = $8,
 			updated_at = NOW()
 		WHERE id = $1
 	`
@@ -183,6 +195,9 @@ func (r *postgresReviewRepository) Update(ctx context.Context, review *model.Rev
 		review.Title,
 		review.Content,
 		pq.Array(review.Images),
+		review.IsApproved,
+		review.IsFlagged,
+		review.FlagReason,
 	)
 
 	if err != nil {
@@ -613,6 +628,7 @@ func (r *postgresReviewRepository) AdminListReviews(
 			id, user_id, book_id, order_id,
 			rating, title, content, images,
 			is_verified_purchase, is_approved, is_featured, admin_note,
+			COALESCE(is_flagged, false), flag_reason, report_count,
 			created_at, updated_at
 		FROM reviews
 		WHERE 1=1
@@ -646,6 +662,12 @@ func (r *postgresReviewRepository) AdminListReviews(
 		argCount++
 	}
 
+	if isFlagged, ok := filters["is_flagged"].(bool); ok {
+		query += fmt.Sprintf(" AND COALESCE(is_flagged, false) = $%d", argCount)
+		args = append(args, isFlagged)
+		argCount++
+	}
+
 	if search, ok := filters["search"].(string); ok && search != "" {
 		query += fmt.Sprintf(" AND (content ILIKE $%d OR title ILIKE $%d)", argCount, argCount)
 		args = append(args, "%"+search+"%")
@@ -680,6 +702,9 @@ func (r *postgresReviewRepository) AdminListReviews(
 			&review.IsApproved,
 			&review.IsFeatured,
 			&review.AdminNote,
+			&review.IsFlagged,
+			&review.FlagReason,
+			&review.ReportCount,
 			&review.CreatedAt,
 			&review.UpdatedAt,
 		)
@@ -720,6 +745,12 @@ func (r *postgresReviewRepository) AdminListReviews(
 		countArgNum++
 	}
 
+	if isFlagged, ok := filters["is_flagged"].(bool); ok {
+		countQuery += fmt.Sprintf(" AND COALESCE(is_flagged, false) = $%d", countArgNum)
+		countArgs = append(countArgs, isFlagged)
+		countArgNum++
+	}
+
 	if search, ok := filters["search"].(string); ok && search != "" {
 		countQuery += fmt.Sprintf(" AND (content ILIKE $%d OR title ILIKE $%d)", countArgNum, countArgNum)
 		countArgs = append(countArgs, "%"+search+"%")
@@ -737,17 +768,23 @@ func (r *postgresReviewRepository) AdminListReviews(
 
 func (r *postgresReviewRepository) UpdateModeration(
 	ctx context.Context,
-	id uuid.UUID,
-	isApproved bool,
-	adminNote *string,
+	id, moderatorID uuid.UUID,
+	isApproved, isFlagged bool,
+	flagReason, adminNote *string,
 ) error {
 	query := `
 		UPDATE reviews
-		SET is_approved = $2, admin_note = $3, updated_at = NOW()
+		SET is_approved = $2,
+			is_flagged = $3,
+			flag_reason = $4,
+			admin_note = COALESCE($5, admin_note),
+			moderated_by = $6,
+			moderated_at = NOW(),
+			updated_at = NOW()
 		WHERE id = $1
 	`
 
-	result, err := r.pool.Exec(ctx, query, id, isApproved, adminNote)
+	result, err := r.pool.Exec(ctx, query, id, isApproved, isFlagged, flagReason, adminNote, moderatorID)
 	if err != nil {
 		return fmt.Errorf("failed to update moderation: %w", err)
 	}
@@ -794,3 +831,194 @@ func (r *postgresReviewRepository) GetPendingCount(ctx context.Context) (int, er
 
 	return count, nil
 }
+
+// =====================================================
+// MODERATION QUEUE & ABUSE REPORTS
+// =====================================================
+
+func (r *postgresReviewRepository) ListModerationQueue(
+	ctx context.Context,
+	page, limit int,
+) ([]*model.Review, int, error) {
+	// Queue = chưa duyệt hoặc đang bị flag; review bị report nhiều lên đầu
+	query := `
+		SELECT 
+			id, user_id, book_id, order_id,
+			rating, title, content, images,
+			is_verified_purchase, is_approved, is_featured, admin_note,
+			COALESCE(is_flagged, false), flag_reason, report_count,
+			created_at, updated_at
+		FROM reviews
+		WHERE is_approved = false OR is_flagged = true
+		ORDER BY report_count DESC, created_at ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*model.Review
+	for rows.Next() {
+		review := &model.Review{}
+		var images []string
+
+		err := rows.Scan(
+			&review.ID,
+			&review.UserID,
+			&review.BookID,
+			&review.OrderID,
+			&review.Rating,
+			&review.Title,
+			&review.Content,
+			pq.Array(&images),
+			&review.IsVerifiedPurchase,
+			&review.IsApproved,
+			&review.IsFeatured,
+			&review.AdminNote,
+			&review.IsFlagged,
+			&review.FlagReason,
+			&review.ReportCount,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan review: %w", err)
+		}
+
+		review.Images = images
+		reviews = append(reviews, review)
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM reviews WHERE is_approved = false OR is_flagged = true`
+	if err := r.pool.QueryRow(ctx, countQuery).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count moderation queue: %w", err)
+	}
+
+	return reviews, total, nil
+}
+
+// CreateReport inserts report and bumps report_count in one transaction.
+// Khi report_count đạt autoFlagThreshold, review tự động bị flag để vào queue.
+func (r *postgresReviewRepository) CreateReport(
+	ctx context.Context,
+	report *model.ReviewReport,
+	autoFlagThreshold int,
+) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	insertQuery := `
+		INSERT INTO review_reports (id, review_id, reporter_id, reason, note, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.Exec(ctx, insertQuery,
+		report.ID,
+		report.ReviewID,
+		report.ReporterID,
+		report.Reason,
+		report.Note,
+		report.Status,
+		report.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false, model.ErrAlreadyReported
+		}
+		return false, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE reviews
+		SET report_count = report_count + 1,
+			is_flagged = COALESCE(is_flagged, false) OR report_count + 1 >= $2,
+			flag_reason = CASE
+				WHEN COALESCE(is_flagged, false) = false AND report_count + 1 >= $2 THEN $3
+				ELSE flag_reason
+			END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING is_flagged
+	`
+	var flagged bool
+	err = tx.QueryRow(ctx, updateQuery, report.ReviewID, autoFlagThreshold, model.FlagReasonUserReports).Scan(&flagged)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, model.ErrReviewNotFound
+		}
+		return false, fmt.Errorf("failed to update report count: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return flagged, nil
+}
+
+func (r *postgresReviewRepository) ListReports(
+	ctx context.Context,
+	reviewID uuid.UUID,
+) ([]*model.ReviewReport, error) {
+	query := `
+		SELECT id, review_id, reporter_id, reason, note, status,
+			resolved_by, resolved_at, created_at
+		FROM review_reports
+		WHERE review_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*model.ReviewReport
+	for rows.Next() {
+		report := &model.ReviewReport{}
+		if err := rows.Scan(
+			&report.ID,
+			&report.ReviewID,
+			&report.ReporterID,
+			&report.Reason,
+			&report.Note,
+			&report.Status,
+			&report.ResolvedBy,
+			&report.ResolvedAt,
+			&report.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// CloseOpenReports đóng tất cả report đang open của review
+// (resolved khi admin reject, dismissed khi admin approve)
+func (r *postgresReviewRepository) CloseOpenReports(
+	ctx context.Context,
+	reviewID, resolvedBy uuid.UUID,
+	status string,
+) error {
+	query := `
+		UPDATE review_reports
+		SET status = $3, resolved_by = $2, resolved_at = NOW()
+		WHERE review_id = $1 AND status = 'open'
+	`
+
+	if _, err := r.pool.Exec(ctx, query, reviewID, resolvedBy, status); err != nil {
+		return fmt.Errorf("failed to close reports: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"bookstore-backend/internal/domains/review/model"
+)
+
+// =====================================================
+// CONTENT FILTER (auto-moderation)
+// =====================================================
+
+// linkPattern - URL đầy đủ, www.* hoặc domain trần (shopee.vn, abc.xyz...)
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+|\b[a-z0-9-]+\.(com|net|org|vn|xyz|info|top|ru|io|me|link|click)\b`)
+
+// contentFilter checks review text for banned words and link spam.
+// Review dính filter không bị chặn mà chuyển sang chờ admin duyệt.
+type contentFilter struct {
+	bannedWords map[string]struct{}
+}
+
+func newContentFilter(bannedWords []string) *contentFilter {
+	words := make(map[string]struct{}, len(bannedWords))
+	for _, w := range bannedWords {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			words[w] = struct{}{}
+		}
+	}
+	return &contentFilter{bannedWords: words}
+}
+
+// Check returns flag reason if any text violates the rules ("" = clean)
+func (f *contentFilter) Check(texts ...string) string {
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+
+		if linkPattern.MatchString(text) {
+			return model.FlagReasonLinkSpam
+		}
+
+		// So khớp theo từ (không phải substring) để tránh false positive
+		tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, token := range tokens {
+			if _, banned := f.bannedWords[token]; banned {
+				return model.FlagReasonBannedWords
+			}
+		}
+	}
+
+	return ""
+}
@@ -53,4 +53,26 @@ type ServiceInterface interface {
 
 	// AdminGetStatistics gets admin dashboard statistics
 	AdminGetStatistics(ctx context.Context) (map[string]interface{}, error)
+
+	// ========================================
+	// MODERATION & ABUSE REPORTS
+	// ========================================
+
+	// ReportReview reports abusive review (user)
+	ReportReview(ctx context.Context, userID, reviewID uuid.UUID, req model.ReportReviewRequest) (*model.ReportReviewResponse, error)
+
+	// AdminListModerationQueue lists unapproved/flagged reviews
+	AdminListModerationQueue(ctx context.Context, req model.ModerationQueueRequest) (*model.ModerationQueueResponse, error)
+
+	// AdminApproveReview publishes review and dismisses open reports
+	AdminApproveReview(ctx context.Context, adminID, reviewID uuid.UUID, req model.ModerationActionRequest) error
+
+	// AdminRejectReview hides review and resolves open reports
+	AdminRejectReview(ctx context.Context, adminID, reviewID uuid.UUID, req model.ModerationActionRequest) error
+
+	// AdminFlagReview flags review for follow-up
+	AdminFlagReview(ctx context.Context, adminID, reviewID uuid.UUID, req model.FlagReviewRequest) error
+
+	// AdminListReports lists user reports of a review
+	AdminListReports(ctx context.Context, reviewID uuid.UUID) ([]*model.ReviewReport, error)
 }
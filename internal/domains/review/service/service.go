@@ -7,8 +7,11 @@ import (
 
 	"github.com/google/uuid"
 
+	notificationModel "bookstore-backend/internal/domains/notification/model"
+	notificationService "bookstore-backend/internal/domains/notification/service"
 	"bookstore-backend/internal/domains/review/model"
 	"bookstore-backend/internal/domains/review/repository"
+	"bookstore-backend/pkg/logger"
 )
 
 // =====================================================
@@ -16,18 +19,27 @@ import (
 // =====================================================
 
 type reviewService struct {
-	reviewRepo repository.ReviewRepository
-	// Add other dependencies if needed (e.g., book service, user service)
+	reviewRepo          repository.ReviewRepository
+	notificationService notificationService.NotificationService
+	filter              *contentFilter
+	autoFlagThreshold   int
 }
 
 func NewReviewService(
 	reviewRepo repository.ReviewRepository,
 ) ServiceInterface {
 	return &reviewService{
-		reviewRepo: reviewRepo,
+		reviewRepo:        reviewRepo,
+		filter:            newContentFilter(model.DefaultBannedWords),
+		autoFlagThreshold: model.DefaultAutoFlagReportThreshold,
 	}
 }
 
+// SetNotificationService sets notification dependency (called after notification services created)
+func (s *reviewService) SetNotificationService(ns notificationService.NotificationService) {
+	s.notificationService = ns
+}
+
 // =====================================================
 // CREATE REVIEW
 // =====================================================
@@ -78,6 +90,9 @@ func (s *reviewService) CreateReview(
 		UpdatedAt:          time.Now(),
 	}
 
+	// Step 4.1: Auto-filter - dính banned words/link spam thì chờ admin duyệt
+	s.applyContentFilter(review)
+
 	// Step 5: Save to database
 	if err := s.reviewRepo.Create(ctx, review); err != nil {
 		if err == model.ErrAlreadyReviewed {
@@ -100,6 +115,7 @@ func (s *reviewService) CreateReview(
 		Images:             review.Images,
 		IsVerifiedPurchase: review.IsVerifiedPurchase,
 		IsFeatured:         review.IsFeatured,
+		ModerationStatus:   moderationStatus(review),
 		CreatedAt:          review.CreatedAt,
 		UpdatedAt:          review.UpdatedAt,
 	}
@@ -194,6 +210,9 @@ func (s *reviewService) UpdateReview(
 
 	review.UpdatedAt = time.Now()
 
+	// Step 4.1: Re-check content sau khi sửa
+	s.applyContentFilter(review)
+
 	// Step 5: Save changes
 	if err := s.reviewRepo.Update(ctx, review); err != nil {
 		return nil, fmt.Errorf("failed to update review: %w", err)
@@ -213,6 +232,7 @@ func (s *reviewService) UpdateReview(
 		Images:             review.Images,
 		IsVerifiedPurchase: review.IsVerifiedPurchase,
 		IsFeatured:         review.IsFeatured,
+		ModerationStatus:   moderationStatus(review),
 		CreatedAt:          review.CreatedAt,
 		UpdatedAt:          review.UpdatedAt,
 	}
@@ -418,6 +438,9 @@ func (s *reviewService) AdminListReviews(
 	if req.IsFeatured != nil {
 		filters["is_featured"] = *req.IsFeatured
 	}
+	if req.IsFlagged != nil {
+		filters["is_flagged"] = *req.IsFlagged
+	}
 	if req.Search != nil {
 		filters["search"] = *req.Search
 	}
@@ -501,9 +524,12 @@ func (s *reviewService) AdminGetReview(
 			CreatedAt:          review.CreatedAt,
 			UpdatedAt:          review.UpdatedAt,
 		},
-		OrderID:    review.OrderID,
-		AdminNote:  review.AdminNote,
-		IsApproved: review.IsApproved,
+		OrderID:     review.OrderID,
+		AdminNote:   review.AdminNote,
+		IsApproved:  review.IsApproved,
+		IsFlagged:   review.IsFlagged,
+		FlagReason:  review.FlagReason,
+		ReportCount: review.ReportCount,
 	}
 
 	return response, nil
@@ -527,18 +553,17 @@ func (s *reviewService) AdminModerateReview(
 		return fmt.Errorf("failed to get review: %w", err)
 	}
 
-	// Step 2: Update moderation status
-	if err := s.reviewRepo.UpdateModeration(ctx, reviewID, req.IsApproved, req.AdminNote); err != nil {
+	// Step 2: Update moderation status (giữ nguyên flag hiện tại)
+	if err := s.reviewRepo.UpdateModeration(ctx, reviewID, adminID, req.IsApproved, review.IsFlagged, review.FlagReason, req.AdminNote); err != nil {
 		return fmt.Errorf("failed to update moderation: %w", err)
 	}
 
 	// Step 3: Create audit log (TODO)
 	// Log admin action for compliance
 
-	// Step 4: If review was hidden, notify user (optional)
-	if !req.IsApproved && review.IsApproved {
-		// TODO: Send notification to user
-		// "Your review has been hidden by admin: {reason}"
+	// Step 4: Notify reviewer when review gets published
+	if req.IsApproved && !review.IsApproved {
+		s.notifyReviewPublished(ctx, review)
 	}
 
 	return nil
@@ -596,3 +621,278 @@ func (s *reviewService) AdminGetStatistics(ctx context.Context) (map[string]inte
 
 	return statistics, nil
 }
+
+// =====================================================
+// ADMIN: MODERATION QUEUE
+// =====================================================
+
+func (s *reviewService) AdminListModerationQueue(
+	ctx context.Context,
+	req model.ModerationQueueRequest,
+) (*model.ModerationQueueResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	reviews, total, err := s.reviewRepo.ListModerationQueue(ctx, req.Page, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation queue: %w", err)
+	}
+
+	items := make([]model.AdminReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		items = append(items, model.AdminReviewResponse{
+			ReviewResponse: model.ReviewResponse{
+				ID:     review.ID,
+				BookID: review.BookID,
+				UserInfo: model.UserInfo{
+					ID:   review.UserID,
+					Name: "User",
+				},
+				Rating:             review.Rating,
+				Title:              review.Title,
+				Content:            review.Content,
+				Images:             review.Images,
+				IsVerifiedPurchase: review.IsVerifiedPurchase,
+				IsFeatured:         review.IsFeatured,
+				CreatedAt:          review.CreatedAt,
+				UpdatedAt:          review.UpdatedAt,
+			},
+			OrderID:     review.OrderID,
+			AdminNote:   review.AdminNote,
+			IsApproved:  review.IsApproved,
+			IsFlagged:   review.IsFlagged,
+			FlagReason:  review.FlagReason,
+			ReportCount: review.ReportCount,
+		})
+	}
+
+	totalPages := (total + req.Limit - 1) / req.Limit
+	return &model.ModerationQueueResponse{
+		Reviews: items,
+		Pagination: model.PaginationMeta{
+			Page:       req.Page,
+			Limit:      req.Limit,
+			Total:      total,
+			TotalPages: totalPages,
+			HasNext:    req.Page < totalPages,
+			HasPrev:    req.Page > 1,
+		},
+	}, nil
+}
+
+// =====================================================
+// ADMIN: APPROVE / REJECT / FLAG
+// =====================================================
+
+// AdminApproveReview publishes review, clears flag and dismisses open reports
+func (s *reviewService) AdminApproveReview(
+	ctx context.Context,
+	adminID, reviewID uuid.UUID,
+	req model.ModerationActionRequest,
+) error {
+	review, err := s.getReviewForModeration(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.reviewRepo.UpdateModeration(ctx, reviewID, adminID, true, false, nil, req.AdminNote); err != nil {
+		return fmt.Errorf("failed to approve review: %w", err)
+	}
+
+	// Admin giữ review => các report đang mở coi như không hợp lệ
+	if err := s.reviewRepo.CloseOpenReports(ctx, reviewID, adminID, model.ReportStatusDismissed); err != nil {
+		return err
+	}
+
+	if !review.IsApproved {
+		s.notifyReviewPublished(ctx, review)
+	}
+
+	return nil
+}
+
+// AdminRejectReview hides review and resolves open reports
+func (s *reviewService) AdminRejectReview(
+	ctx context.Context,
+	adminID, reviewID uuid.UUID,
+	req model.ModerationActionRequest,
+) error {
+	if _, err := s.getReviewForModeration(ctx, reviewID); err != nil {
+		return err
+	}
+
+	if err := s.reviewRepo.UpdateModeration(ctx, reviewID, adminID, false, false, nil, req.AdminNote); err != nil {
+		return fmt.Errorf("failed to reject review: %w", err)
+	}
+
+	if err := s.reviewRepo.CloseOpenReports(ctx, reviewID, adminID, model.ReportStatusResolved); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AdminFlagReview keeps current visibility but puts review into moderation queue
+func (s *reviewService) AdminFlagReview(
+	ctx context.Context,
+	adminID, reviewID uuid.UUID,
+	req model.FlagReviewRequest,
+) error {
+	review, err := s.getReviewForModeration(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+
+	reason := "admin: " + req.Reason
+	if err := s.reviewRepo.UpdateModeration(ctx, reviewID, adminID, review.IsApproved, true, &reason, nil); err != nil {
+		return fmt.Errorf("failed to flag review: %w", err)
+	}
+
+	return nil
+}
+
+// AdminListReports lists user reports of a review
+func (s *reviewService) AdminListReports(
+	ctx context.Context,
+	reviewID uuid.UUID,
+) ([]*model.ReviewReport, error) {
+	if _, err := s.getReviewForModeration(ctx, reviewID); err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reviewRepo.ListReports(ctx, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// =====================================================
+// USER: REPORT REVIEW
+// =====================================================
+
+func (s *reviewService) ReportReview(
+	ctx context.Context,
+	userID, reviewID uuid.UUID,
+	req model.ReportReviewRequest,
+) (*model.ReportReviewResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		if err == model.ErrReviewNotFound {
+			return nil, model.NewReviewNotFoundError()
+		}
+		return nil, fmt.Errorf("failed to get review: %w", err)
+	}
+
+	// Chỉ report được review đang hiển thị, và không tự report review của mình
+	if !review.IsApproved {
+		return nil, model.NewReviewNotFoundError()
+	}
+	if review.UserID == userID {
+		return nil, model.NewCannotReportError("You cannot report your own review")
+	}
+
+	report := &model.ReviewReport{
+		ID:         uuid.New(),
+		ReviewID:   reviewID,
+		ReporterID: userID,
+		Reason:     req.Reason,
+		Note:       req.Note,
+		Status:     model.ReportStatusOpen,
+		CreatedAt:  time.Now(),
+	}
+
+	flagged, err := s.reviewRepo.CreateReport(ctx, report, s.autoFlagThreshold)
+	if err != nil {
+		if err == model.ErrAlreadyReported {
+			return nil, model.NewAlreadyReportedError()
+		}
+		if err == model.ErrReviewNotFound {
+			return nil, model.NewReviewNotFoundError()
+		}
+		return nil, fmt.Errorf("failed to report review: %w", err)
+	}
+
+	if flagged && !review.IsFlagged {
+		logger.Info("Review auto-flagged by user reports", map[string]interface{}{
+			"review_id": reviewID,
+		})
+	}
+
+	return &model.ReportReviewResponse{
+		ReportID: report.ID,
+		Message:  "Thank you, our team will review this report",
+	}, nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+func (s *reviewService) getReviewForModeration(ctx context.Context, reviewID uuid.UUID) (*model.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		if err == model.ErrReviewNotFound {
+			return nil, model.NewReviewNotFoundError()
+		}
+		return nil, fmt.Errorf("failed to get review: %w", err)
+	}
+	return review, nil
+}
+
+// applyContentFilter hold review for moderation if content violates filter rules
+func (s *reviewService) applyContentFilter(review *model.Review) {
+	title := ""
+	if review.Title != nil {
+		title = *review.Title
+	}
+
+	reason := s.filter.Check(title, review.Content)
+	if reason == "" {
+		return
+	}
+
+	review.IsApproved = false
+	review.IsFlagged = true
+	review.FlagReason = &reason
+}
+
+func moderationStatus(review *model.Review) string {
+	if review.IsApproved {
+		return model.ModerationStatusPublished
+	}
+	return model.ModerationStatusPending
+}
+
+// notifyReviewPublished - best effort, lỗi notification không fail moderation
+func (s *reviewService) notifyReviewPublished(ctx context.Context, review *model.Review) {
+	if s.notificationService == nil {
+		return
+	}
+
+	referenceType := "review"
+	priority := notificationModel.PriorityLow
+	_, err := s.notificationService.CreateNotification(ctx, notificationModel.CreateNotificationRequest{
+		UserID:  review.UserID,
+		Type:    notificationModel.NotificationTypeReviewResponse,
+		Title:   "Đánh giá của bạn đã được đăng",
+		Message: "Đánh giá của bạn đã được duyệt và hiển thị công khai.",
+		Data: map[string]interface{}{
+			"review_id": review.ID,
+			"book_id":   review.BookID,
+		},
+		Channels:      []string{notificationModel.ChannelInApp},
+		ReferenceType: &referenceType,
+		ReferenceID:   &review.ID,
+		Priority:      &priority,
+	})
+	if err != nil {
+		logger.Error("Failed to send review published notification", err)
+	}
+}
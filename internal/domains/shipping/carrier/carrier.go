@@ -0,0 +1,124 @@
+package carrier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// CARRIER INTERFACE
+// =====================================================
+
+// Carrier tạo vận đơn + lấy nhãn in từ API hãng vận chuyển
+type Carrier interface {
+	// Code returns carrier identifier stored on shipments (ghn, ghtk, ...)
+	Code() string
+
+	// SupportsFormat checks if carrier can render label in given format (pdf, zpl)
+	SupportsFormat(format string) bool
+
+	// CreateShipment books a pickup and returns tracking number + fee charged by carrier
+	CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error)
+
+	// GetLabel downloads printable label of a created shipment
+	GetLabel(ctx context.Context, trackingNumber, format string) (*Label, error)
+}
+
+// RateQuoter - hãng có API báo giá trước khi tạo vận đơn (checkout tính phí ship thật thay phí cố định)
+type RateQuoter interface {
+	// QuoteRate returns fee carrier would charge for a parcel, without booking anything
+	QuoteRate(ctx context.Context, req RateRequest) (*RateQuote, error)
+}
+
+// Carrier codes (khớp CHECK của shipments.carrier)
+const (
+	CodeGHN         = "ghn"
+	CodeGHTK        = "ghtk"
+	CodeViettelPost = "viettel_post"
+	CodeVNPost      = "vnpost"
+	CodeJTExpress   = "jt_express"
+)
+
+var ValidCodes = []string{
+	CodeGHN,
+	CodeGHTK,
+	CodeViettelPost,
+	CodeVNPost,
+	CodeJTExpress,
+}
+
+// Label formats
+const (
+	FormatPDF = "pdf" // A6, in máy in văn phòng
+	FormatZPL = "zpl" // máy in nhiệt Zebra
+)
+
+// ErrFormatNotSupported is returned when carrier cannot render label in requested format
+var ErrFormatNotSupported = errors.New("label format not supported by carrier")
+
+// Address - địa chỉ VN tách cấp (hãng yêu cầu tỉnh/huyện/xã riêng để định tuyến)
+type Address struct {
+	Name     string
+	Phone    string
+	Street   string
+	Ward     string
+	District string
+	Province string
+}
+
+// ShipmentRequest - thông tin kiện hàng gửi cho hãng
+type ShipmentRequest struct {
+	ClientOrderCode string // order_number, hãng lưu để tra cứu ngược
+	From            Address
+	To              Address
+	WeightGrams     int
+	ItemCount       int
+	CODAmount       decimal.Decimal // 0 = không thu hộ
+	DeclaredValue   decimal.Decimal // giá trị khai báo (bảo hiểm)
+	Note            string
+}
+
+// ShipmentResult - vận đơn hãng trả về
+type ShipmentResult struct {
+	TrackingNumber     string
+	Fee                decimal.Decimal
+	ExpectedDeliveryAt *time.Time
+}
+
+// RateRequest - kiện cần báo giá (lúc checkout, chưa có đơn)
+type RateRequest struct {
+	From          Address
+	To            Address
+	WeightGrams   int
+	DeclaredValue decimal.Decimal
+}
+
+// RateQuote - phí hãng báo
+type RateQuote struct {
+	Fee                decimal.Decimal
+	ExpectedDeliveryAt *time.Time
+}
+
+// Label - file nhãn in
+type Label struct {
+	Data        []byte
+	ContentType string
+}
+
+// ContentTypeFor returns MIME type of a label format
+func ContentTypeFor(format string) string {
+	if format == FormatZPL {
+		return "application/x-zpl"
+	}
+	return "application/pdf"
+}
+
+const defaultTimeout = 30 * time.Second
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultTimeout}
+}
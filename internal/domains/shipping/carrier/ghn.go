@@ -0,0 +1,248 @@
+package carrier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/shared/utils"
+)
+
+const defaultGHNBaseURL = "https://online-gateway.ghn.vn/shiip/public-api"
+
+// GHN service_type_id: 2 = hàng nhẹ (E-commerce Delivery), đủ cho kiện sách
+const ghnServiceTypeLight = 2
+
+// GHNCarrier - Giao Hàng Nhanh
+//
+// Địa chỉ gửi theo tên tỉnh/huyện/xã (GHN tự map sang mã), không cần đồng bộ master data mã địa giới.
+// GHN chỉ trả trang in HTML → nhãn PDF/ZPL dựng tại chỗ từ chi tiết vận đơn.
+type GHNCarrier struct {
+	token      string
+	shopID     int64
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGHNCarrier creates GHN client; baseURL rỗng → production
+func NewGHNCarrier(token string, shopID int64, baseURL string) *GHNCarrier {
+	if baseURL == "" {
+		baseURL = defaultGHNBaseURL
+	}
+	return &GHNCarrier{
+		token:      token,
+		shopID:     shopID,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: newHTTPClient(),
+	}
+}
+
+func (c *GHNCarrier) Code() string {
+	return CodeGHN
+}
+
+func (c *GHNCarrier) SupportsFormat(format string) bool {
+	return format == FormatPDF || format == FormatZPL
+}
+
+// ghnResponse - envelope chung: code 200 = thành công, còn lại message là lý do
+type ghnResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// post gọi API GHN, decode data vào out
+func (c *GHNCarrier) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("ghn: marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("ghn: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Token", c.token)
+	httpReq.Header.Set("ShopId", strconv.FormatInt(c.shopID, 10))
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("ghn: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ghnResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("ghn: decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if result.Code != http.StatusOK {
+		return fmt.Errorf("ghn: %s rejected: %s", path, result.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("ghn: decode data: %w", err)
+	}
+	return nil
+}
+
+type ghnItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Weight   int    `json:"weight"` // gram
+}
+
+type ghnCreateOrder struct {
+	PaymentTypeID    int       `json:"payment_type_id"` // 1 = shop trả phí ship
+	RequiredNote     string    `json:"required_note"`
+	ClientOrderCode  string    `json:"client_order_code"`
+	FromName         string    `json:"from_name"`
+	FromPhone        string    `json:"from_phone"`
+	FromAddress      string    `json:"from_address"`
+	FromWardName     string    `json:"from_ward_name,omitempty"`
+	FromDistrictName string    `json:"from_district_name,omitempty"`
+	FromProvinceName string    `json:"from_province_name"`
+	ToName           string    `json:"to_name"`
+	ToPhone          string    `json:"to_phone"`
+	ToAddress        string    `json:"to_address"`
+	ToWardName       string    `json:"to_ward_name"`
+	ToDistrictName   string    `json:"to_district_name"`
+	ToProvinceName   string    `json:"to_province_name"`
+	CODAmount        int64     `json:"cod_amount"`
+	InsuranceValue   int64     `json:"insurance_value"`
+	Weight           int       `json:"weight"`
+	ServiceTypeID    int       `json:"service_type_id"`
+	Note             string    `json:"note,omitempty"`
+	Items            []ghnItem `json:"items"`
+}
+
+type ghnCreateData struct {
+	OrderCode            string          `json:"order_code"`
+	TotalFee             decimal.Decimal `json:"total_fee"`
+	ExpectedDeliveryTime *time.Time      `json:"expected_delivery_time"`
+}
+
+func (c *GHNCarrier) CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error) {
+	body := ghnCreateOrder{
+		PaymentTypeID:    1,
+		RequiredNote:     "CHOXEMHANGKHONGTHU",
+		ClientOrderCode:  req.ClientOrderCode,
+		FromName:         req.From.Name,
+		FromPhone:        req.From.Phone,
+		FromAddress:      req.From.Street,
+		FromWardName:     req.From.Ward,
+		FromDistrictName: req.From.District,
+		FromProvinceName: req.From.Province,
+		ToName:           req.To.Name,
+		ToPhone:          req.To.Phone,
+		ToAddress:        req.To.Street,
+		ToWardName:       req.To.Ward,
+		ToDistrictName:   req.To.District,
+		ToProvinceName:   req.To.Province,
+		CODAmount:        req.CODAmount.Round(0).IntPart(),
+		InsuranceValue:   req.DeclaredValue.Round(0).IntPart(),
+		Weight:           req.WeightGrams,
+		ServiceTypeID:    ghnServiceTypeLight,
+		Note:             req.Note,
+		Items: []ghnItem{{
+			Name:     fmt.Sprintf("Sách (%d cuốn)", req.ItemCount),
+			Quantity: 1,
+			Weight:   req.WeightGrams,
+		}},
+	}
+
+	var data ghnCreateData
+	if err := c.post(ctx, "/v2/shipping-order/create", body, &data); err != nil {
+		return nil, err
+	}
+	if data.OrderCode == "" {
+		return nil, fmt.Errorf("ghn: create shipment returned no order code")
+	}
+
+	return &ShipmentResult{
+		TrackingNumber:     data.OrderCode,
+		Fee:                data.TotalFee,
+		ExpectedDeliveryAt: data.ExpectedDeliveryTime,
+	}, nil
+}
+
+type ghnFeeRequest struct {
+	ServiceTypeID    int    `json:"service_type_id"`
+	FromDistrictName string `json:"from_district_name,omitempty"`
+	FromProvinceName string `json:"from_province_name,omitempty"`
+	ToWardName       string `json:"to_ward_name"`
+	ToDistrictName   string `json:"to_district_name"`
+	ToProvinceName   string `json:"to_province_name"`
+	Weight           int    `json:"weight"`
+	InsuranceValue   int64  `json:"insurance_value"`
+}
+
+// QuoteRate - kho gửi không có quận/huyện → GHN tính từ địa chỉ lấy hàng mặc định của shop
+func (c *GHNCarrier) QuoteRate(ctx context.Context, req RateRequest) (*RateQuote, error) {
+	body := ghnFeeRequest{
+		ServiceTypeID:    ghnServiceTypeLight,
+		FromDistrictName: req.From.District,
+		FromProvinceName: req.From.Province,
+		ToWardName:       req.To.Ward,
+		ToDistrictName:   req.To.District,
+		ToProvinceName:   req.To.Province,
+		Weight:           req.WeightGrams,
+		InsuranceValue:   req.DeclaredValue.Round(0).IntPart(),
+	}
+
+	var data struct {
+		Total decimal.Decimal `json:"total"`
+	}
+	if err := c.post(ctx, "/v2/shipping-order/fee", body, &data); err != nil {
+		return nil, err
+	}
+	return &RateQuote{Fee: data.Total}, nil
+}
+
+type ghnOrderDetail struct {
+	OrderCode       string          `json:"order_code"`
+	ClientOrderCode string          `json:"client_order_code"`
+	ToName          string          `json:"to_name"`
+	ToPhone         string          `json:"to_phone"`
+	ToAddress       string          `json:"to_address"`
+	Weight          int             `json:"weight"`
+	CODAmount       decimal.Decimal `json:"cod_amount"`
+	SortCode        string          `json:"sort_code"`
+}
+
+// GetLabel dựng nhãn từ chi tiết vận đơn (tên/địa chỉ bỏ dấu vì nhãn chỉ in được ASCII)
+func (c *GHNCarrier) GetLabel(ctx context.Context, trackingNumber, format string) (*Label, error) {
+	if !c.SupportsFormat(format) {
+		return nil, ErrFormatNotSupported
+	}
+
+	var detail ghnOrderDetail
+	if err := c.post(ctx, "/v2/shipping-order/detail", map[string]string{"order_code": trackingNumber}, &detail); err != nil {
+		return nil, err
+	}
+
+	lines := []string{
+		"GHN " + detail.SortCode,
+		"Tracking: " + trackingNumber,
+		"Order: " + detail.ClientOrderCode,
+		"To: " + utils.RemoveVietnameseAccents(detail.ToName) + " - " + detail.ToPhone,
+		utils.RemoveVietnameseAccents(detail.ToAddress),
+		fmt.Sprintf("Weight: %dg", detail.Weight),
+		"COD: " + detail.CODAmount.StringFixed(0) + " VND",
+	}
+
+	if format == FormatZPL {
+		return &Label{Data: buildTextZPL(trackingNumber, lines), ContentType: ContentTypeFor(FormatZPL)}, nil
+	}
+	return &Label{Data: buildTextPDF(lines), ContentType: ContentTypeFor(FormatPDF)}, nil
+}
@@ -0,0 +1,232 @@
+package carrier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const defaultGHTKBaseURL = "https://services.giaohangtietkiem.vn"
+
+// GHTKCarrier - Giao Hàng Tiết Kiệm (nhãn chỉ có PDF, không hỗ trợ ZPL)
+type GHTKCarrier struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGHTKCarrier creates GHTK client; baseURL rỗng → production
+func NewGHTKCarrier(token, baseURL string) *GHTKCarrier {
+	if baseURL == "" {
+		baseURL = defaultGHTKBaseURL
+	}
+	return &GHTKCarrier{
+		token:      token,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: newHTTPClient(),
+	}
+}
+
+func (c *GHTKCarrier) Code() string {
+	return CodeGHTK
+}
+
+func (c *GHTKCarrier) SupportsFormat(format string) bool {
+	return format == FormatPDF
+}
+
+type ghtkProduct struct {
+	Name     string  `json:"name"`
+	Weight   float64 `json:"weight"` // kg
+	Quantity int     `json:"quantity"`
+}
+
+type ghtkOrder struct {
+	ID           string `json:"id"`
+	PickName     string `json:"pick_name"`
+	PickTel      string `json:"pick_tel"`
+	PickAddress  string `json:"pick_address"`
+	PickWard     string `json:"pick_ward,omitempty"`
+	PickDistrict string `json:"pick_district"`
+	PickProvince string `json:"pick_province"`
+	Name         string `json:"name"`
+	Tel          string `json:"tel"`
+	Address      string `json:"address"`
+	Ward         string `json:"ward"`
+	District     string `json:"district"`
+	Province     string `json:"province"`
+	Hamlet       string `json:"hamlet"`
+	IsFreeship   string `json:"is_freeship"`
+	PickMoney    int64  `json:"pick_money"`
+	Value        int64  `json:"value"`
+	Note         string `json:"note,omitempty"`
+}
+
+type ghtkCreateResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Order   struct {
+		Label string          `json:"label"`
+		Fee   decimal.Decimal `json:"fee"`
+	} `json:"order"`
+}
+
+func (c *GHTKCarrier) CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error) {
+	body := map[string]interface{}{
+		"products": []ghtkProduct{{
+			Name:     fmt.Sprintf("Sách (%d cuốn)", req.ItemCount),
+			Weight:   float64(req.WeightGrams) / 1000,
+			Quantity: 1,
+		}},
+		"order": ghtkOrder{
+			ID:           req.ClientOrderCode,
+			PickName:     req.From.Name,
+			PickTel:      req.From.Phone,
+			PickAddress:  req.From.Street,
+			PickWard:     req.From.Ward,
+			PickDistrict: req.From.District,
+			PickProvince: req.From.Province,
+			Name:         req.To.Name,
+			Tel:          req.To.Phone,
+			Address:      req.To.Street,
+			Ward:         req.To.Ward,
+			District:     req.To.District,
+			Province:     req.To.Province,
+			Hamlet:       "Khác",
+			IsFreeship:   "1", // phí ship shop trả, khách chỉ trả pick_money
+			PickMoney:    req.CODAmount.Round(0).IntPart(),
+			Value:        req.DeclaredValue.Round(0).IntPart(),
+			Note:         req.Note,
+		},
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.baseURL+"/services/shipment/order/?ver=1.5", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Token", c.token)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ghtkCreateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("ghtk: decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if !result.Success || result.Order.Label == "" {
+		return nil, fmt.Errorf("ghtk: create shipment rejected: %s", result.Message)
+	}
+
+	return &ShipmentResult{
+		TrackingNumber: result.Order.Label,
+		Fee:            result.Order.Fee,
+	}, nil
+}
+
+type ghtkFeeResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Fee     struct {
+		Fee          decimal.Decimal `json:"fee"`
+		InsuranceFee decimal.Decimal `json:"insurance_fee"`
+		Delivery     bool            `json:"delivery"`
+	} `json:"fee"`
+}
+
+// QuoteRate - GHTK tính phí theo tỉnh/huyện gửi + nhận, weight tính bằng gram
+func (c *GHTKCarrier) QuoteRate(ctx context.Context, req RateRequest) (*RateQuote, error) {
+	params := url.Values{}
+	params.Set("pick_address", req.From.Street)
+	params.Set("pick_district", req.From.District)
+	params.Set("pick_province", req.From.Province)
+	params.Set("address", req.To.Street)
+	params.Set("ward", req.To.Ward)
+	params.Set("district", req.To.District)
+	params.Set("province", req.To.Province)
+	params.Set("weight", strconv.Itoa(req.WeightGrams))
+	params.Set("value", strconv.FormatInt(req.DeclaredValue.Round(0).IntPart(), 10))
+	params.Set("deliver_option", "none")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/services/shipment/fee?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: build request: %w", err)
+	}
+	httpReq.Header.Set("Token", c.token)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ghtkFeeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("ghtk: decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("ghtk: quote rejected: %s", result.Message)
+	}
+	// delivery = false: GHTK không giao tới địa chỉ này
+	if !result.Fee.Delivery {
+		return nil, fmt.Errorf("ghtk: destination not served: %s, %s", req.To.District, req.To.Province)
+	}
+
+	return &RateQuote{Fee: result.Fee.Fee.Add(result.Fee.InsuranceFee)}, nil
+}
+
+func (c *GHTKCarrier) GetLabel(ctx context.Context, trackingNumber, format string) (*Label, error) {
+	if !c.SupportsFormat(format) {
+		return nil, ErrFormatNotSupported
+	}
+
+	params := url.Values{}
+	params.Set("original", "portrait")
+	params.Set("page_size", "A6")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/services/label/"+url.PathEscape(trackingNumber)+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: build request: %w", err)
+	}
+	httpReq.Header.Set("Token", c.token)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ghtk: read label: %w", err)
+	}
+
+	// Lỗi (vận đơn không tồn tại, token sai) trả JSON thay vì PDF
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "pdf") {
+		return nil, fmt.Errorf("ghtk: get label failed (status %d): %s", resp.StatusCode, string(data))
+	}
+
+	return &Label{
+		Data:        data,
+		ContentType: ContentTypeFor(FormatPDF),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package carrier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// =====================================================
+// TEXT LABELS
+// =====================================================
+// Nhãn dựng tại chỗ khi không có file từ hãng (mock, GHN chỉ trả trang in HTML):
+// các dòng text ASCII + barcode mã vận đơn
+
+// buildTextZPL nhãn 4x6 inch: các dòng text + barcode Code128 mã vận đơn
+func buildTextZPL(trackingNumber string, lines []string) []byte {
+	var b strings.Builder
+	b.WriteString("^XA\n^CI28\n")
+	for i, line := range lines {
+		fmt.Fprintf(&b, "^FO50,%d^A0N,32,32^FD%s^FS\n", 50+i*50, line)
+	}
+	fmt.Fprintf(&b, "^FO50,%d^BY3^BCN,120,Y,N,N^FD%s^FS\n", 80+len(lines)*50, trackingNumber)
+	b.WriteString("^XZ\n")
+	return []byte(b.String())
+}
+
+// buildTextPDF PDF 1 trang khổ A6, font Helvetica chuẩn (không cần nhúng font)
+func buildTextPDF(lines []string) []byte {
+	var content strings.Builder
+	content.WriteString("BT\n/F1 12 Tf\n20 390 Td\n16 TL\n")
+	for _, line := range lines {
+		escaped := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(line)
+		fmt.Fprintf(&content, "(%s) Tj T*\n", escaped)
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 298 420] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	return buf.Bytes()
+}
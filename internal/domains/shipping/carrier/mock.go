@@ -0,0 +1,120 @@
+package carrier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/infrastructure/sandbox"
+)
+
+// MockCarrier - hãng giả lập cho dev/staging (SHIPPING_PROVIDER_MODE=fake), không gọi API thật
+// Nhãn sinh tại chỗ (PDF 1 trang / ZPL) chỉ chứa thông tin ASCII để kiểm tra luồng in
+// Báo giá / tạo vận đơn theo kịch bản sandbox của giá trị khai báo (...001 từ chối, ...002 treo)
+type MockCarrier struct {
+	code string
+	// Kịch bản timeout treo bao lâu trước khi trả lỗi
+	hang time.Duration
+
+	mu        sync.Mutex
+	shipments map[string]ShipmentRequest
+}
+
+// NewMockCarrier creates mock impersonating a carrier code (để qua CHECK của shipments.carrier)
+func NewMockCarrier(code string, hang time.Duration) *MockCarrier {
+	return &MockCarrier{
+		code:      code,
+		hang:      hang,
+		shipments: make(map[string]ShipmentRequest),
+	}
+}
+
+func (c *MockCarrier) Code() string {
+	return c.code
+}
+
+func (c *MockCarrier) SupportsFormat(format string) bool {
+	return format == FormatPDF || format == FormatZPL
+}
+
+func (c *MockCarrier) CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error) {
+	if err := c.simulate(ctx, req.DeclaredValue); err != nil {
+		return nil, err
+	}
+
+	trackingNumber := fmt.Sprintf("MOCK%s", strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:12]))
+
+	c.mu.Lock()
+	c.shipments[trackingNumber] = req
+	c.mu.Unlock()
+
+	fee, expected := mockRate(req.From, req.To, req.WeightGrams)
+	return &ShipmentResult{
+		TrackingNumber:     trackingNumber,
+		Fee:                fee,
+		ExpectedDeliveryAt: &expected,
+	}, nil
+}
+
+func (c *MockCarrier) QuoteRate(ctx context.Context, req RateRequest) (*RateQuote, error) {
+	if err := c.simulate(ctx, req.DeclaredValue); err != nil {
+		return nil, err
+	}
+
+	fee, expected := mockRate(req.From, req.To, req.WeightGrams)
+	return &RateQuote{Fee: fee, ExpectedDeliveryAt: &expected}, nil
+}
+
+// simulate kịch bản lỗi của hãng theo giá trị khai báo (success → nil)
+func (c *MockCarrier) simulate(ctx context.Context, declaredValue decimal.Decimal) error {
+	switch sandbox.ForAmount(declaredValue) {
+	case sandbox.ScenarioFailure:
+		return fmt.Errorf("%w: %s does not serve this route", sandbox.ErrRejected, c.code)
+	case sandbox.ScenarioTimeout:
+		return sandbox.Hang(ctx, c.hang)
+	}
+	return nil
+}
+
+// mockRate phí giả lập: 20.000đ + 5.000đ mỗi 500g, khác tỉnh +10.000đ và giao chậm hơn 2 ngày
+func mockRate(from, to Address, weightGrams int) (decimal.Decimal, time.Time) {
+	fee := decimal.NewFromInt(20000).Add(decimal.NewFromInt(int64(5000 * (weightGrams / 500))))
+	days := 1
+	if from.Province != "" && !strings.EqualFold(from.Province, to.Province) {
+		fee = fee.Add(decimal.NewFromInt(10000))
+		days = 3
+	}
+	return fee, time.Now().AddDate(0, 0, days)
+}
+
+func (c *MockCarrier) GetLabel(ctx context.Context, trackingNumber, format string) (*Label, error) {
+	c.mu.Lock()
+	req, ok := c.shipments[trackingNumber]
+	c.mu.Unlock()
+	if !ok {
+		// Worker/API khác process → không có dữ liệu kiện, vẫn in được mã vận đơn
+		req = ShipmentRequest{ClientOrderCode: "-"}
+	}
+
+	lines := []string{
+		"MOCK CARRIER " + strings.ToUpper(c.code),
+		"Tracking: " + trackingNumber,
+		"Order: " + req.ClientOrderCode,
+		fmt.Sprintf("Weight: %dg", req.WeightGrams),
+		"COD: " + req.CODAmount.StringFixed(0) + " VND",
+	}
+
+	switch format {
+	case FormatZPL:
+		return &Label{Data: buildTextZPL(trackingNumber, lines), ContentType: ContentTypeFor(FormatZPL)}, nil
+	case FormatPDF:
+		return &Label{Data: buildTextPDF(lines), ContentType: ContentTypeFor(FormatPDF)}, nil
+	default:
+		return nil, ErrFormatNotSupported
+	}
+}
@@ -0,0 +1,179 @@
+package carrier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================================================
+// TRACKING WEBHOOKS
+// =====================================================
+// Hãng gọi webhook mỗi lần vận đơn đổi trạng thái. Parse độc lập với hãng đã cấu hình
+// (mock mạo danh ghn/ghtk trên staging vẫn test được bằng payload thật của hãng).
+
+// Trạng thái vận đơn đã chuẩn hoá (trạng thái gốc của hãng giữ ở TrackingUpdate.CarrierStatus)
+const (
+	TrackingStatusInTransit = "in_transit" // đã lấy hàng → đang giao
+	TrackingStatusDelivered = "delivered"
+	TrackingStatusReturned  = "returned" // đã hoàn về kho
+	TrackingStatusCancelled = "cancelled"
+	TrackingStatusOther     = "other" // chờ lấy, giao lỗi, hoãn... chỉ ghi nhận
+)
+
+// ErrInvalidTrackingWebhook - payload không đọc được / thiếu mã vận đơn
+var ErrInvalidTrackingWebhook = errors.New("invalid tracking webhook payload")
+
+// TrackingUpdate - 1 lần đổi trạng thái vận đơn
+type TrackingUpdate struct {
+	Carrier         string
+	TrackingNumber  string
+	ClientOrderCode string // order_number gửi lúc tạo vận đơn
+	CarrierStatus   string
+	Status          string // TrackingStatus*
+	Reason          string
+	OccurredAt      time.Time
+}
+
+// ParseTrackingWebhook đọc payload webhook theo hãng
+func ParseTrackingWebhook(carrierCode string, body []byte) (*TrackingUpdate, error) {
+	var (
+		update *TrackingUpdate
+		err    error
+	)
+	switch carrierCode {
+	case CodeGHN:
+		update, err = parseGHNWebhook(body)
+	case CodeGHTK:
+		update, err = parseGHTKWebhook(body)
+	default:
+		return nil, fmt.Errorf("%w: carrier %s has no tracking webhook", ErrInvalidTrackingWebhook, carrierCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if update.TrackingNumber == "" {
+		return nil, fmt.Errorf("%w: missing tracking number", ErrInvalidTrackingWebhook)
+	}
+	update.Carrier = carrierCode
+	if update.OccurredAt.IsZero() {
+		update.OccurredAt = time.Now()
+	}
+	return update, nil
+}
+
+// =====================================================
+// GHN
+// =====================================================
+
+var ghnStatuses = map[string]string{
+	"picked":                   TrackingStatusInTransit,
+	"storing":                  TrackingStatusInTransit,
+	"transporting":             TrackingStatusInTransit,
+	"sorting":                  TrackingStatusInTransit,
+	"delivering":               TrackingStatusInTransit,
+	"money_collect_delivering": TrackingStatusInTransit,
+	"delivered":                TrackingStatusDelivered,
+	"returned":                 TrackingStatusReturned,
+	"cancel":                   TrackingStatusCancelled,
+}
+
+// parseGHNWebhook - GHN gửi JSON: {"OrderCode", "ClientOrderCode", "Status", "Reason", "Time", "Type"}
+func parseGHNWebhook(body []byte) (*TrackingUpdate, error) {
+	var payload struct {
+		OrderCode       string `json:"OrderCode"`
+		ClientOrderCode string `json:"ClientOrderCode"`
+		Status          string `json:"Status"`
+		Reason          string `json:"Reason"`
+		Time            string `json:"Time"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrackingWebhook, err)
+	}
+
+	carrierStatus := strings.ToLower(strings.TrimSpace(payload.Status))
+	status, ok := ghnStatuses[carrierStatus]
+	if !ok {
+		status = TrackingStatusOther
+	}
+	occurredAt, _ := time.Parse(time.RFC3339, payload.Time)
+
+	return &TrackingUpdate{
+		TrackingNumber:  strings.TrimSpace(payload.OrderCode),
+		ClientOrderCode: payload.ClientOrderCode,
+		CarrierStatus:   carrierStatus,
+		Status:          status,
+		Reason:          payload.Reason,
+		OccurredAt:      occurredAt,
+	}, nil
+}
+
+// =====================================================
+// GHTK
+// =====================================================
+
+// GHTK status_id → trạng thái chuẩn hoá (mã không có trong map = chỉ ghi nhận)
+var ghtkStatuses = map[int]string{
+	-1:  TrackingStatusCancelled,
+	3:   TrackingStatusInTransit, // đã lấy hàng / nhập kho
+	4:   TrackingStatusInTransit, // đang giao
+	10:  TrackingStatusInTransit, // delay giao
+	123: TrackingStatusInTransit, // shipper báo đã lấy
+	5:   TrackingStatusDelivered, // đã giao, chưa đối soát
+	6:   TrackingStatusDelivered, // đã đối soát
+	45:  TrackingStatusDelivered, // shipper báo đã giao
+	11:  TrackingStatusReturned,  // đã đối soát trả hàng
+	21:  TrackingStatusReturned,  // đã trả hàng
+}
+
+// parseGHTKWebhook - GHTK gửi form-urlencoded (hoặc JSON tuỳ cấu hình):
+// label_id, partner_id (order_number), status_id, action_time, reason
+func parseGHTKWebhook(body []byte) (*TrackingUpdate, error) {
+	var payload struct {
+		LabelID    string      `json:"label_id"`
+		PartnerID  string      `json:"partner_id"`
+		StatusID   json.Number `json:"status_id"`
+		ActionTime string      `json:"action_time"`
+		Reason     string      `json:"reason"`
+	}
+
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTrackingWebhook, err)
+		}
+	} else {
+		form, err := url.ParseQuery(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTrackingWebhook, err)
+		}
+		payload.LabelID = form.Get("label_id")
+		payload.PartnerID = form.Get("partner_id")
+		payload.StatusID = json.Number(form.Get("status_id"))
+		payload.ActionTime = form.Get("action_time")
+		payload.Reason = form.Get("reason")
+	}
+
+	statusID, err := strconv.Atoi(payload.StatusID.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid status_id %q", ErrInvalidTrackingWebhook, payload.StatusID)
+	}
+	status, ok := ghtkStatuses[statusID]
+	if !ok {
+		status = TrackingStatusOther
+	}
+	occurredAt, _ := time.Parse(time.RFC3339, payload.ActionTime)
+
+	return &TrackingUpdate{
+		TrackingNumber:  strings.TrimSpace(payload.LabelID),
+		ClientOrderCode: payload.PartnerID,
+		CarrierStatus:   strconv.Itoa(statusID),
+		Status:          status,
+		Reason:          payload.Reason,
+		OccurredAt:      occurredAt,
+	}, nil
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/shipping/model"
+	"bookstore-backend/internal/domains/shipping/service"
+)
+
+// =====================================================
+// SHIPPING HANDLER
+// =====================================================
+
+// maxTrackingWebhookBody - webhook hãng chỉ chứa 1 vận đơn
+const maxTrackingWebhookBody = 64 << 10
+
+type ShippingHandler struct {
+	shippingService service.ServiceInterface
+	webhookSecret   string
+}
+
+// NewShippingHandler - webhookSecret là SHIPPING_WEBHOOK_SECRET, URL webhook khai báo với hãng kèm ?token=<secret>
+func NewShippingHandler(shippingService service.ServiceInterface, webhookSecret string) *ShippingHandler {
+	return &ShippingHandler{
+		shippingService: shippingService,
+		webhookSecret:   webhookSecret,
+	}
+}
+
+// =====================================================
+// LABELS
+// =====================================================
+
+// ListCarriers lists configured carriers with supported label formats
+// GET /api/v1/admin/shipments/carriers
+func (h *ShippingHandler) ListCarriers(c *gin.Context) {
+	respondSuccess(c, http.StatusOK, h.shippingService.ListCarriers(c.Request.Context()))
+}
+
+// PurchaseLabel buys shipping label for order (body rỗng → carrier/format mặc định)
+// POST /api/v1/admin/orders/:id/shipment
+func (h *ShippingHandler) PurchaseLabel(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	orderID, ok := parseUUIDParam(c, "id", "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	var req model.PurchaseLabelRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+
+	shipment, err := h.shippingService.PurchaseLabel(c.Request.Context(), adminID, orderID, req)
+	if err != nil {
+		statusCode, errCode := mapShippingError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, shipment)
+}
+
+// GetOrderShipment gets shipment (tracking number, cost, label URL) of order
+// GET /api/v1/admin/orders/:id/shipment
+func (h *ShippingHandler) GetOrderShipment(c *gin.Context) {
+	orderID, ok := parseUUIDParam(c, "id", "Invalid order ID")
+	if !ok {
+		return
+	}
+
+	shipment, err := h.shippingService.GetOrderShipment(c.Request.Context(), orderID)
+	if err != nil {
+		statusCode, errCode := mapShippingError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, shipment)
+}
+
+// PrintLabel downloads label file (PDF/ZPL) of shipment
+// GET /api/v1/admin/shipments/:id/label
+func (h *ShippingHandler) PrintLabel(c *gin.Context) {
+	shipmentID, ok := parseUUIDParam(c, "id", "Invalid shipment ID")
+	if !ok {
+		return
+	}
+
+	file, err := h.shippingService.GetLabel(c.Request.Context(), shipmentID)
+	if err != nil {
+		statusCode, errCode := mapShippingError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	sendFile(c, file)
+}
+
+// =====================================================
+// DISPATCH BATCHES
+// =====================================================
+
+// CreateDispatchBatch queues bulk label purchase for orders waiting to ship
+// POST /api/v1/admin/shipments/batches
+func (h *ShippingHandler) CreateDispatchBatch(c *gin.Context) {
+	adminID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.CreateDispatchBatchRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+
+	batch, err := h.shippingService.CreateDispatchBatch(c.Request.Context(), adminID, req)
+	if err != nil {
+		statusCode, errCode := mapShippingError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusAccepted, batch)
+}
+
+// GetDispatchBatch gets batch progress and per-order result
+// GET /api/v1/admin/shipments/batches/:id
+func (h *ShippingHandler) GetDispatchBatch(c *gin.Context) {
+	batchID, ok := parseUUIDParam(c, "id", "Invalid batch ID")
+	if !ok {
+		return
+	}
+
+	batch, err := h.shippingService.GetDispatchBatch(c.Request.Context(), batchID)
+	if err != nil {
+		statusCode, errCode := mapShippingError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, batch)
+}
+
+// PrintBatchLabels downloads all labels of batch (ZPL nối liền hoặc zip PDF)
+// GET /api/v1/admin/shipments/batches/:id/labels
+func (h *ShippingHandler) PrintBatchLabels(c *gin.Context) {
+	batchID, ok := parseUUIDParam(c, "id", "Invalid batch ID")
+	if !ok {
+		return
+	}
+
+	file, err := h.shippingService.GetBatchLabels(c.Request.Context(), batchID)
+	if err != nil {
+		statusCode, errCode := mapShippingError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	sendFile(c, file)
+}
+
+// =====================================================
+// TRACKING WEBHOOKS (PUBLIC)
+// =====================================================
+
+// TrackingWebhook receives shipment status updates from carrier
+// POST /api/v1/webhooks/shipping/:carrier?token=...
+func (h *ShippingHandler) TrackingWebhook(c *gin.Context) {
+	// Chưa cấu hình secret → đóng endpoint
+	token := c.Query("token")
+	if h.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.webhookSecret)) != 1 {
+		respondError(c, http.StatusUnauthorized, model.ErrCodeWebhookForbidden, model.ErrWebhookForbidden.Error())
+		return
+	}
+
+	// Body thô: GHTK gửi form-urlencoded, GHN gửi JSON
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxTrackingWebhookBody))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	result, err := h.shippingService.ProcessTrackingWebhook(c.Request.Context(), c.Param("carrier"), body)
+	if err != nil {
+		// 5xx → hãng gửi lại
+		statusCode, errCode := mapShippingError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, result)
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// getUserID extracts user ID from JWT claims
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func sendFile(c *gin.Context, file *model.LabelFile) {
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.FileName))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// respondSuccess sends success response
+func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// respondError sends error response
+func respondError(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// mapShippingError maps shipping error to HTTP status code
+func mapShippingError(err error) (int, string) {
+	var sErr *model.ShippingError
+	if errors.As(err, &sErr) {
+		switch sErr.Code {
+		case model.ErrCodeShipmentNotFound, model.ErrCodeOrderNotFound, model.ErrCodeBatchNotFound:
+			return http.StatusNotFound, sErr.Code
+		case model.ErrCodeOrderNotEligible, model.ErrCodeBatchNotCompleted:
+			return http.StatusConflict, sErr.Code
+		case model.ErrCodeNoEligibleOrders:
+			return http.StatusUnprocessableEntity, sErr.Code
+		case model.ErrCodeInvalidRequest, model.ErrCodeCarrierUnavailable, model.ErrCodeFormatNotSupported,
+			model.ErrCodeCarrierNotAllowed, model.ErrCodeInvalidWebhook:
+			return http.StatusBadRequest, sErr.Code
+		case model.ErrCodeCarrierError:
+			return http.StatusBadGateway, sErr.Code
+		default:
+			return http.StatusInternalServerError, "INTERNAL_ERROR"
+		}
+	}
+
+	return http.StatusInternalServerError, "INTERNAL_ERROR"
+}
@@ -0,0 +1,43 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+
+	"bookstore-backend/internal/domains/shipping/model"
+	shippingService "bookstore-backend/internal/domains/shipping/service"
+)
+
+// CreateOrderShipmentHandler tạo vận đơn với hãng mặc định khi đơn được xác nhận
+type CreateOrderShipmentHandler struct {
+	shippingService shippingService.ServiceInterface
+}
+
+func NewCreateOrderShipmentHandler(shippingService shippingService.ServiceInterface) *CreateOrderShipmentHandler {
+	return &CreateOrderShipmentHandler{
+		shippingService: shippingService,
+	}
+}
+
+// ProcessTask - lỗi hãng (timeout, 5xx) → asynq retry; đơn đã có vận đơn → không mua lại
+func (h *CreateOrderShipmentHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload model.CreateOrderShipmentPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		log.Error().Err(err).Msg("Failed to unmarshal CreateOrderShipment payload")
+		return fmt.Errorf("unmarshal payload: %v: %w", err, asynq.SkipRetry)
+	}
+
+	if err := h.shippingService.CreateOrderShipment(ctx, payload.OrderID); err != nil {
+		log.Error().
+			Err(err).
+			Str("order_id", payload.OrderID.String()).
+			Msg("Failed to create shipment for confirmed order")
+		return fmt.Errorf("create order shipment: %w", err)
+	}
+
+	return nil
+}
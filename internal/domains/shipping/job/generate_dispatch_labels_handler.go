@@ -0,0 +1,59 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+
+	"bookstore-backend/internal/domains/shipping/model"
+	shippingService "bookstore-backend/internal/domains/shipping/service"
+)
+
+// GenerateDispatchLabelsHandler mua nhãn hàng loạt cho các đơn trong 1 dispatch batch
+type GenerateDispatchLabelsHandler struct {
+	shippingService shippingService.ServiceInterface
+}
+
+func NewGenerateDispatchLabelsHandler(shippingService shippingService.ServiceInterface) *GenerateDispatchLabelsHandler {
+	return &GenerateDispatchLabelsHandler{
+		shippingService: shippingService,
+	}
+}
+
+// ProcessTask xử lý background job in nhãn theo đợt
+func (h *GenerateDispatchLabelsHandler) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	var payload struct {
+		BatchID string `json:"batch_id"`
+	}
+
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		log.Error().Err(err).Msg("Failed to unmarshal GenerateDispatchLabels payload")
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	batchID, err := uuid.Parse(payload.BatchID)
+	if err != nil {
+		return fmt.Errorf("invalid batch id %q: %v: %w", payload.BatchID, err, asynq.SkipRetry)
+	}
+
+	if err := h.shippingService.ProcessDispatchBatch(ctx, batchID); err != nil {
+		// Batch bị xoá: retry cũng vô ích
+		if errors.Is(err, model.ErrBatchNotFound) {
+			log.Warn().Err(err).Str("batch_id", payload.BatchID).Msg("Skip dispatch batch")
+			return fmt.Errorf("generate dispatch labels: %v: %w", err, asynq.SkipRetry)
+		}
+
+		log.Error().
+			Err(err).
+			Str("batch_id", payload.BatchID).
+			Msg("Failed to generate dispatch labels")
+		return fmt.Errorf("generate dispatch labels: %w", err)
+	}
+
+	return nil
+}
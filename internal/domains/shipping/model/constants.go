@@ -0,0 +1,36 @@
+package model
+
+// Trạng thái đơn được phép mua nhãn
+// shipping: admin đã chuyển trạng thái trước khi có nhãn (hook trigger_shipment / mua bổ sung)
+var LabelEligibleOrderStatuses = []string{"confirmed", "processing", "shipping"}
+
+// Trạng thái đơn được gom vào đợt in nhãn hàng loạt (đơn chờ xuất kho)
+var BatchEligibleOrderStatuses = []string{"confirmed", "processing"}
+
+// Dispatch batch statuses
+const (
+	BatchStatusProcessing = "processing" // worker đang mua nhãn
+	BatchStatusCompleted  = "completed"
+)
+
+// Dispatch batch order statuses
+const (
+	BatchOrderStatusPending   = "pending"
+	BatchOrderStatusSucceeded = "succeeded"
+	BatchOrderStatusFailed    = "failed"
+)
+
+// Batch limits
+const (
+	DefaultBatchSize = 100
+	MaxBatchSize     = 300
+)
+
+// DefaultBookWeightGrams - sách chưa nhập weight_grams tính theo trọng lượng trung bình
+const DefaultBookWeightGrams = 300
+
+// PackagingWeightGrams - hộp + vật liệu chèn cộng thêm vào mỗi kiện
+const PackagingWeightGrams = 100
+
+// LabelStoragePrefix - thư mục nhãn trên storage
+const LabelStoragePrefix = "shipping-labels"
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// REQUEST DTOs
+// =====================================================
+
+// PurchaseLabelRequest mua nhãn cho 1 đơn; bỏ trống → carrier / format mặc định trong config
+type PurchaseLabelRequest struct {
+	Carrier     string `json:"carrier"`
+	LabelFormat string `json:"label_format"`
+}
+
+func (r *PurchaseLabelRequest) Normalize() {
+	r.Carrier = strings.ToLower(strings.TrimSpace(r.Carrier))
+	r.LabelFormat = strings.ToLower(strings.TrimSpace(r.LabelFormat))
+}
+
+// CreateDispatchBatchRequest in nhãn hàng loạt
+// OrderIDs rỗng → toàn bộ đơn confirmed/processing chưa có nhãn (cũ nhất trước, tối đa Limit)
+type CreateDispatchBatchRequest struct {
+	Carrier     string      `json:"carrier"`
+	LabelFormat string      `json:"label_format"`
+	WarehouseID *uuid.UUID  `json:"warehouse_id,omitempty"`
+	OrderIDs    []uuid.UUID `json:"order_ids,omitempty"`
+	Limit       int         `json:"limit"`
+}
+
+func (r *CreateDispatchBatchRequest) Validate() error {
+	r.Carrier = strings.ToLower(strings.TrimSpace(r.Carrier))
+	r.LabelFormat = strings.ToLower(strings.TrimSpace(r.LabelFormat))
+
+	if r.Limit <= 0 {
+		r.Limit = DefaultBatchSize
+	}
+	if r.Limit > MaxBatchSize {
+		return NewInvalidRequestError(fmt.Sprintf("limit must be at most %d", MaxBatchSize))
+	}
+	if len(r.OrderIDs) > MaxBatchSize {
+		return NewInvalidRequestError(fmt.Sprintf("batch can contain at most %d orders", MaxBatchSize))
+	}
+	return nil
+}
+
+// QuoteParcel - 1 kiện cần báo giá lúc checkout: kho gửi (theo kế hoạch tách kiện) → địa chỉ nhận
+type QuoteParcel struct {
+	WarehouseID   uuid.UUID
+	Street        string
+	Ward          string
+	District      string
+	Province      string
+	Items         map[uuid.UUID]int // book_id → số lượng (cân nặng lấy từ books.weight_grams)
+	DeclaredValue decimal.Decimal
+}
+
+// CreateOrderShipmentPayload - job tạo vận đơn khi đơn được xác nhận (COD lúc đặt, online khi tiền về)
+type CreateOrderShipmentPayload struct {
+	OrderID uuid.UUID `json:"order_id"`
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// CarrierResponse - hãng đã cấu hình + định dạng nhãn hỗ trợ
+type CarrierResponse struct {
+	Code      string   `json:"code"`
+	Formats   []string `json:"formats"`
+	IsDefault bool     `json:"is_default"`
+}
+
+// DispatchBatchResponse - đợt in nhãn + kết quả từng đơn
+type DispatchBatchResponse struct {
+	DispatchBatch
+	Orders []DispatchBatchOrder `json:"orders"`
+}
+
+// LabelFile - file trả về để in (1 nhãn, hoặc cả đợt: ZPL nối liền / PDF nén zip)
+type LabelFile struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// TrackingWebhookResult - kết quả xử lý 1 webhook của hãng
+// Ignored: vận đơn không phải của shop / event đã xử lý → vẫn trả 200 để hãng không gửi lại
+type TrackingWebhookResult struct {
+	TrackingNumber string `json:"tracking_number"`
+	CarrierStatus  string `json:"carrier_status"`
+	Status         string `json:"status"`
+	Ignored        bool   `json:"ignored"`
+	OrderStatus    string `json:"order_status,omitempty"`
+}
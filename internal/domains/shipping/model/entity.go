@@ -0,0 +1,143 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// ENTITY: Shipment
+// =====================================================
+// 1 đơn = 1 vận đơn; nhãn lưu trên storage để in lại không cần gọi hãng
+type Shipment struct {
+	ID             uuid.UUID       `json:"id"`
+	OrderID        uuid.UUID       `json:"order_id"`
+	OrderNumber    string          `json:"order_number,omitempty"`
+	BatchID        *uuid.UUID      `json:"batch_id,omitempty"`
+	Carrier        string          `json:"carrier"`
+	TrackingNumber string          `json:"tracking_number"`
+	ShippingCost   decimal.Decimal `json:"shipping_cost"`
+	CODAmount      decimal.Decimal `json:"cod_amount"`
+	WeightGrams    int             `json:"weight_grams"`
+	LabelFormat    string          `json:"label_format"`
+	LabelURL       string          `json:"label_url"`
+	LabelKey       string          `json:"-"`
+	PurchasedBy    *uuid.UUID      `json:"purchased_by,omitempty"`
+	// Trạng thái mới nhất hãng báo qua webhook (nil = chưa có event)
+	CarrierStatus   *string    `json:"carrier_status,omitempty"`
+	TrackingStatus  *string    `json:"tracking_status,omitempty"`
+	StatusUpdatedAt *time.Time `json:"status_updated_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// =====================================================
+// ENTITY: TrackingEvent
+// =====================================================
+// 1 lần hãng báo đổi trạng thái vận đơn (webhook), UNIQUE theo mã gốc + thời điểm → chặn xử lý lặp
+type TrackingEvent struct {
+	ID             uuid.UUID `json:"id"`
+	ShipmentID     uuid.UUID `json:"shipment_id"`
+	Carrier        string    `json:"carrier"`
+	TrackingNumber string    `json:"tracking_number"`
+	CarrierStatus  string    `json:"carrier_status"`
+	Status         string    `json:"status"`
+	Reason         *string   `json:"reason,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// =====================================================
+// ENTITY: DispatchBatch
+// =====================================================
+// 1 đợt in nhãn hàng loạt cho các đơn chờ xuất kho trong ngày
+type DispatchBatch struct {
+	ID             uuid.UUID  `json:"id"`
+	BatchDate      time.Time  `json:"batch_date"`
+	Carrier        string     `json:"carrier"`
+	LabelFormat    string     `json:"label_format"`
+	WarehouseID    *uuid.UUID `json:"warehouse_id,omitempty"`
+	Status         string     `json:"status"`
+	TotalOrders    int        `json:"total_orders"`
+	SucceededCount int        `json:"succeeded_count"`
+	FailedCount    int        `json:"failed_count"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// DispatchBatchOrder - kết quả mua nhãn của 1 đơn trong đợt
+type DispatchBatchOrder struct {
+	BatchID        uuid.UUID  `json:"batch_id"`
+	OrderID        uuid.UUID  `json:"order_id"`
+	OrderNumber    string     `json:"order_number"`
+	Status         string     `json:"status"`
+	ShipmentID     *uuid.UUID `json:"shipment_id,omitempty"`
+	TrackingNumber *string    `json:"tracking_number,omitempty"`
+	LabelURL       *string    `json:"label_url,omitempty"`
+	ErrorMessage   *string    `json:"error_message,omitempty"`
+	ProcessedAt    *time.Time `json:"processed_at,omitempty"`
+}
+
+// =====================================================
+// READ MODEL: Parcel
+// =====================================================
+// Dữ liệu đơn cần để mua nhãn: người nhận (quà tặng → người nhận quà), kho gửi, cân nặng, tiền thu hộ
+type Parcel struct {
+	OrderID           uuid.UUID
+	OrderNumber       string
+	OrderStatus       string
+	PaymentMethod     string
+	PaymentStatus     string
+	Total             decimal.Decimal
+	CustomerNote      *string
+	RecipientName     string
+	RecipientPhone    string
+	Street            string
+	Ward              string
+	District          string
+	Province          string
+	WarehouseID       *uuid.UUID
+	WarehouseName     *string
+	WarehouseAddress  *string
+	WarehouseProvince *string
+	ItemCount         int
+	WeightGrams       int
+	HasShipment       bool
+	// Kênh bán của đơn + hãng kênh cho phép (rỗng = tất cả)
+	Channel         string
+	ChannelCarriers []string
+}
+
+// AllowsCarrier hãng được dùng cho đơn theo cấu hình kênh bán
+func (p *Parcel) AllowsCarrier(code string) bool {
+	if len(p.ChannelCarriers) == 0 {
+		return true
+	}
+	for _, c := range p.ChannelCarriers {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// CODAmount - số tiền shipper thu hộ (đơn COD chưa thanh toán)
+func (p *Parcel) CODAmount() decimal.Decimal {
+	if p.PaymentMethod == "cod" && p.PaymentStatus != "paid" {
+		return p.Total
+	}
+	return decimal.Zero
+}
+
+// =====================================================
+// READ MODEL: QuoteOrigin
+// =====================================================
+// Kho gửi dùng để báo giá lúc checkout (đơn chưa tồn tại, chưa có Parcel)
+type QuoteOrigin struct {
+	WarehouseID uuid.UUID
+	Name        string
+	Address     string
+	Province    string
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodeShipmentNotFound   = "SHP001"
+	ErrCodeOrderNotFound      = "SHP002"
+	ErrCodeOrderNotEligible   = "SHP003"
+	ErrCodeCarrierUnavailable = "SHP004"
+	ErrCodeFormatNotSupported = "SHP005"
+	ErrCodeCarrierError       = "SHP006"
+	ErrCodeBatchNotFound      = "SHP007"
+	ErrCodeInvalidRequest     = "SHP008"
+	ErrCodeNoEligibleOrders   = "SHP009"
+	ErrCodeBatchNotCompleted  = "SHP010"
+	ErrCodeCarrierNotAllowed  = "SHP011"
+	ErrCodeInvalidWebhook     = "SHP012"
+	ErrCodeWebhookForbidden   = "SHP013"
+)
+
+// Errors
+var (
+	ErrShipmentNotFound   = errors.New("shipment not found")
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrOrderNotEligible   = errors.New("order not eligible for shipping label")
+	ErrCarrierUnavailable = errors.New("carrier not configured")
+	ErrFormatNotSupported = errors.New("label format not supported")
+	ErrCarrierError       = errors.New("carrier request failed")
+	ErrBatchNotFound      = errors.New("dispatch batch not found")
+	ErrInvalidRequest     = errors.New("invalid shipping request")
+	ErrNoEligibleOrders   = errors.New("no eligible orders")
+	ErrBatchNotCompleted  = errors.New("dispatch batch not completed")
+	ErrCarrierNotAllowed  = errors.New("carrier not allowed for sales channel")
+	ErrInvalidWebhook     = errors.New("invalid tracking webhook")
+	ErrWebhookForbidden   = errors.New("invalid tracking webhook token")
+)
+
+// ShippingError custom error type
+type ShippingError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *ShippingError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *ShippingError) Unwrap() error {
+	return e.Err
+}
+
+// Error constructors
+func NewShipmentNotFoundError() *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeShipmentNotFound,
+		Message: "Shipment not found",
+		Err:     ErrShipmentNotFound,
+	}
+}
+
+func NewOrderNotFoundError() *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeOrderNotFound,
+		Message: "Order not found",
+		Err:     ErrOrderNotFound,
+	}
+}
+
+func NewOrderNotEligibleError(status string) *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeOrderNotEligible,
+		Message: fmt.Sprintf("Cannot buy shipping label for order in status %s", status),
+		Err:     ErrOrderNotEligible,
+	}
+}
+
+func NewCarrierUnavailableError(carrier string) *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeCarrierUnavailable,
+		Message: fmt.Sprintf("Carrier %s is not configured", carrier),
+		Err:     ErrCarrierUnavailable,
+	}
+}
+
+// NewCarrierNotAllowedError - kênh bán của đơn chỉ cho phép một số hãng (sales_channels.shipping_carriers)
+func NewCarrierNotAllowedError(carrier, channel string) *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeCarrierNotAllowed,
+		Message: fmt.Sprintf("Carrier %s is not allowed for %s orders", carrier, channel),
+		Err:     ErrCarrierNotAllowed,
+	}
+}
+
+func NewFormatNotSupportedError(carrier, format string) *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeFormatNotSupported,
+		Message: fmt.Sprintf("Carrier %s does not support %s labels", carrier, format),
+		Err:     ErrFormatNotSupported,
+	}
+}
+
+// NewCarrierError wraps lỗi từ API hãng (message giữ nguyên để kho biết lý do: sai địa chỉ, quá cân...)
+func NewCarrierError(err error) *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeCarrierError,
+		Message: err.Error(),
+		Err:     ErrCarrierError,
+	}
+}
+
+func NewBatchNotFoundError() *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeBatchNotFound,
+		Message: "Dispatch batch not found",
+		Err:     ErrBatchNotFound,
+	}
+}
+
+func NewInvalidRequestError(message string) *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeInvalidRequest,
+		Message: message,
+		Err:     ErrInvalidRequest,
+	}
+}
+
+func NewNoEligibleOrdersError() *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeNoEligibleOrders,
+		Message: "No confirmed orders waiting for a shipping label",
+		Err:     ErrNoEligibleOrders,
+	}
+}
+
+func NewBatchNotCompletedError() *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeBatchNotCompleted,
+		Message: "Dispatch batch is still processing",
+		Err:     ErrBatchNotCompleted,
+	}
+}
+
+func NewInvalidWebhookError(err error) *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeInvalidWebhook,
+		Message: err.Error(),
+		Err:     ErrInvalidWebhook,
+	}
+}
+
+func NewWebhookForbiddenError() *ShippingError {
+	return &ShippingError{
+		Code:    ErrCodeWebhookForbidden,
+		Message: "Invalid webhook token",
+		Err:     ErrWebhookForbidden,
+	}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"bookstore-backend/internal/domains/shipping/model"
+)
+
+// =====================================================
+// SHIPPING REPOSITORY INTERFACE
+// =====================================================
+
+type ShippingRepository interface {
+	// ========================================
+	// PARCEL
+	// ========================================
+
+	// GetParcel gets order data needed for a label (recipient, warehouse, weight, COD)
+	GetParcel(ctx context.Context, orderID uuid.UUID) (*model.Parcel, error)
+
+	// ========================================
+	// RATE QUOTES
+	// ========================================
+
+	// GetQuoteOrigin gets warehouse address used as pickup point when quoting
+	GetQuoteOrigin(ctx context.Context, warehouseID uuid.UUID) (*model.QuoteOrigin, error)
+
+	// GetParcelWeightGrams sums book weights (book_id → quantity) + packaging
+	GetParcelWeightGrams(ctx context.Context, items map[uuid.UUID]int) (int, error)
+
+	// ========================================
+	// SHIPMENTS
+	// ========================================
+
+	// LockOrderWithTx khoá đơn (FOR UPDATE) để 2 request không mua 2 nhãn cho 1 đơn
+	LockOrderWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) error
+
+	// GetShipmentByOrderIDWithTx gets shipment of order inside transaction
+	GetShipmentByOrderIDWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (*model.Shipment, error)
+
+	// CreateShipmentWithTx stores purchased label and copies tracking number to order
+	CreateShipmentWithTx(ctx context.Context, tx pgx.Tx, shipment *model.Shipment) error
+
+	// GetShipmentByID gets shipment
+	GetShipmentByID(ctx context.Context, id uuid.UUID) (*model.Shipment, error)
+
+	// GetShipmentByOrderID gets shipment of order
+	GetShipmentByOrderID(ctx context.Context, orderID uuid.UUID) (*model.Shipment, error)
+
+	// GetShipmentByTracking gets shipment by carrier + tracking number (webhook của hãng)
+	GetShipmentByTracking(ctx context.Context, carrierCode, trackingNumber string) (*model.Shipment, error)
+
+	// ========================================
+	// TRACKING EVENTS
+	// ========================================
+
+	// RecordTrackingEvent stores carrier event and latest shipment status; false = duplicate event
+	RecordTrackingEvent(ctx context.Context, event *model.TrackingEvent) (bool, error)
+
+	// ========================================
+	// DISPATCH BATCHES
+	// ========================================
+
+	// ListBatchEligibleOrderIDs đơn confirmed/processing chưa có nhãn (cũ nhất trước)
+	// orderIDs rỗng → không lọc theo danh sách
+	ListBatchEligibleOrderIDs(ctx context.Context, orderIDs []uuid.UUID, warehouseID *uuid.UUID, limit int) ([]uuid.UUID, error)
+
+	// CreateBatch creates batch with its orders (pending) in one transaction
+	CreateBatch(ctx context.Context, batch *model.DispatchBatch, orderIDs []uuid.UUID) error
+
+	// GetBatch gets dispatch batch
+	GetBatch(ctx context.Context, id uuid.UUID) (*model.DispatchBatch, error)
+
+	// ListBatchOrders lists orders of batch with purchase result
+	ListBatchOrders(ctx context.Context, batchID uuid.UUID) ([]model.DispatchBatchOrder, error)
+
+	// ListPendingBatchOrderIDs lists orders not yet processed (job retry tiếp tục từ đây)
+	ListPendingBatchOrderIDs(ctx context.Context, batchID uuid.UUID) ([]uuid.UUID, error)
+
+	// MarkBatchOrder records purchase result of an order in batch
+	MarkBatchOrder(ctx context.Context, batchID, orderID uuid.UUID, status string, shipmentID *uuid.UUID, errorMessage *string) error
+
+	// CompleteBatch recalculates counters and marks batch completed
+	CompleteBatch(ctx context.Context, batchID uuid.UUID) (*model.DispatchBatch, error)
+
+	// ListBatchShipments lists shipments purchased successfully in batch (thứ tự đơn trong đợt)
+	ListBatchShipments(ctx context.Context, batchID uuid.UUID) ([]model.Shipment, error)
+}
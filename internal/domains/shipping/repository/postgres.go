@@ -0,0 +1,607 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/shipping/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresShippingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresShippingRepository(pool *pgxpool.Pool) ShippingRepository {
+	return &postgresShippingRepository{pool: pool}
+}
+
+const shipmentColumns = `
+	s.id, s.order_id, o.order_number, s.batch_id, s.carrier, s.tracking_number,
+	s.shipping_cost, s.cod_amount, s.weight_grams, s.label_format, s.label_url, s.label_key,
+	s.purchased_by, s.carrier_status, s.tracking_status, s.status_updated_at, s.created_at, s.updated_at`
+
+func scanShipment(row pgx.Row, s *model.Shipment) error {
+	return row.Scan(
+		&s.ID,
+		&s.OrderID,
+		&s.OrderNumber,
+		&s.BatchID,
+		&s.Carrier,
+		&s.TrackingNumber,
+		&s.ShippingCost,
+		&s.CODAmount,
+		&s.WeightGrams,
+		&s.LabelFormat,
+		&s.LabelURL,
+		&s.LabelKey,
+		&s.PurchasedBy,
+		&s.CarrierStatus,
+		&s.TrackingStatus,
+		&s.StatusUpdatedAt,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+}
+
+const batchColumns = `
+	id, batch_date, carrier, label_format, warehouse_id, status,
+	total_orders, succeeded_count, failed_count, created_by, created_at, completed_at`
+
+func scanBatch(row pgx.Row, b *model.DispatchBatch) error {
+	return row.Scan(
+		&b.ID,
+		&b.BatchDate,
+		&b.Carrier,
+		&b.LabelFormat,
+		&b.WarehouseID,
+		&b.Status,
+		&b.TotalOrders,
+		&b.SucceededCount,
+		&b.FailedCount,
+		&b.CreatedBy,
+		&b.CreatedAt,
+		&b.CompletedAt,
+	)
+}
+
+// =====================================================
+// PARCEL
+// =====================================================
+
+// GetParcel - đơn quà tặng giao tới người nhận quà (order_gifts), còn lại theo sổ địa chỉ
+// Kèm hãng vận chuyển kênh bán của đơn cho phép (sales_channels.shipping_carriers)
+// Cân nặng = tổng weight_grams * quantity (sách chưa có cân nặng → DefaultBookWeightGrams) + bao bì
+func (r *postgresShippingRepository) GetParcel(ctx context.Context, orderID uuid.UUID) (*model.Parcel, error) {
+	query := `
+		SELECT
+			o.id, o.order_number, o.status, o.payment_method, o.payment_status, o.total, o.customer_note,
+			COALESCE(g.recipient_name, a.recipient_name, ''),
+			COALESCE(g.recipient_phone, a.phone, ''),
+			COALESCE(g.street, a.street, ''),
+			COALESCE(g.ward, a.ward, ''),
+			COALESCE(g.district, a.district, ''),
+			COALESCE(g.province, a.province, ''),
+			o.warehouse_id, w.name, w.address, w.province,
+			COALESCE(items.item_count, 0),
+			COALESCE(items.weight_grams, 0),
+			o.channel, COALESCE(sc.shipping_carriers, '{}')
+		FROM orders o
+		LEFT JOIN sales_channels sc ON sc.code = o.channel
+		LEFT JOIN order_gifts g ON g.order_id = o.id
+		LEFT JOIN addresses a ON a.id = o.address_id
+		LEFT JOIN warehouses w ON w.id = o.warehouse_id
+		LEFT JOIN LATERAL (
+			SELECT
+				SUM(oi.quantity)::INT AS item_count,
+				SUM(COALESCE(b.weight_grams, $2) * oi.quantity)::INT AS weight_grams
+			FROM order_items oi
+			LEFT JOIN books b ON b.id = oi.book_id
+			WHERE oi.order_id = o.id
+		) items ON TRUE
+		WHERE o.id = $1
+	`
+
+	var p model.Parcel
+	err := r.pool.QueryRow(ctx, query, orderID, model.DefaultBookWeightGrams).Scan(
+		&p.OrderID,
+		&p.OrderNumber,
+		&p.OrderStatus,
+		&p.PaymentMethod,
+		&p.PaymentStatus,
+		&p.Total,
+		&p.CustomerNote,
+		&p.RecipientName,
+		&p.RecipientPhone,
+		&p.Street,
+		&p.Ward,
+		&p.District,
+		&p.Province,
+		&p.WarehouseID,
+		&p.WarehouseName,
+		&p.WarehouseAddress,
+		&p.WarehouseProvince,
+		&p.ItemCount,
+		&p.WeightGrams,
+		&p.Channel,
+		&p.ChannelCarriers,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get parcel: %w", err)
+	}
+
+	p.WeightGrams += model.PackagingWeightGrams
+	return &p, nil
+}
+
+// =====================================================
+// RATE QUOTES
+// =====================================================
+
+func (r *postgresShippingRepository) GetQuoteOrigin(ctx context.Context, warehouseID uuid.UUID) (*model.QuoteOrigin, error) {
+	var o model.QuoteOrigin
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, name, address, province FROM warehouses WHERE id = $1
+	`, warehouseID).Scan(&o.WarehouseID, &o.Name, &o.Address, &o.Province)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrInvalidRequest
+		}
+		return nil, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+	return &o, nil
+}
+
+// GetParcelWeightGrams - cùng công thức GetParcel: weight_grams * quantity (thiếu → mặc định) + bao bì
+func (r *postgresShippingRepository) GetParcelWeightGrams(ctx context.Context, items map[uuid.UUID]int) (int, error) {
+	bookIDs := make([]uuid.UUID, 0, len(items))
+	quantities := make([]int32, 0, len(items))
+	for bookID, qty := range items {
+		bookIDs = append(bookIDs, bookID)
+		quantities = append(quantities, int32(qty))
+	}
+
+	var weight int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(COALESCE(b.weight_grams, $3) * i.quantity), 0)::INT
+		FROM UNNEST($1::UUID[], $2::INT[]) AS i(book_id, quantity)
+		LEFT JOIN books b ON b.id = i.book_id
+	`, bookIDs, quantities, model.DefaultBookWeightGrams).Scan(&weight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get parcel weight: %w", err)
+	}
+	return weight + model.PackagingWeightGrams, nil
+}
+
+// =====================================================
+// SHIPMENTS
+// =====================================================
+
+func (r *postgresShippingRepository) LockOrderWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, `SELECT id FROM orders WHERE id = $1 FOR UPDATE`, orderID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrOrderNotFound
+		}
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresShippingRepository) GetShipmentByOrderIDWithTx(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (*model.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + `
+		FROM shipments s
+		JOIN orders o ON o.id = s.order_id
+		WHERE s.order_id = $1
+	`
+
+	var s model.Shipment
+	if err := scanShipment(tx.QueryRow(ctx, query, orderID), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrShipmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *postgresShippingRepository) CreateShipmentWithTx(ctx context.Context, tx pgx.Tx, shipment *model.Shipment) error {
+	query := `
+		INSERT INTO shipments (
+			id, order_id, batch_id, carrier, tracking_number, shipping_cost, cod_amount,
+			weight_grams, label_format, label_url, label_key, purchased_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		shipment.ID,
+		shipment.OrderID,
+		shipment.BatchID,
+		shipment.Carrier,
+		shipment.TrackingNumber,
+		shipment.ShippingCost,
+		shipment.CODAmount,
+		shipment.WeightGrams,
+		shipment.LabelFormat,
+		shipment.LabelURL,
+		shipment.LabelKey,
+		shipment.PurchasedBy,
+		shipment.CreatedAt,
+		shipment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create shipment: %w", err)
+	}
+
+	// Mã vận đơn trên nhãn là mã khách tra cứu → ghi đè mã nhập tay (nếu có)
+	_, err = tx.Exec(ctx, `
+		UPDATE orders
+		SET tracking_number = $2,
+			updated_at = NOW(),
+			version = version + 1
+		WHERE id = $1
+	`, shipment.OrderID, shipment.TrackingNumber)
+	if err != nil {
+		return fmt.Errorf("failed to set order tracking number: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresShippingRepository) GetShipmentByID(ctx context.Context, id uuid.UUID) (*model.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + `
+		FROM shipments s
+		JOIN orders o ON o.id = s.order_id
+		WHERE s.id = $1
+	`
+
+	var s model.Shipment
+	if err := scanShipment(r.pool.QueryRow(ctx, query, id), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrShipmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *postgresShippingRepository) GetShipmentByOrderID(ctx context.Context, orderID uuid.UUID) (*model.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + `
+		FROM shipments s
+		JOIN orders o ON o.id = s.order_id
+		WHERE s.order_id = $1
+	`
+
+	var s model.Shipment
+	if err := scanShipment(r.pool.QueryRow(ctx, query, orderID), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrShipmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *postgresShippingRepository) GetShipmentByTracking(ctx context.Context, carrierCode, trackingNumber string) (*model.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + `
+		FROM shipments s
+		JOIN orders o ON o.id = s.order_id
+		WHERE s.carrier = $1 AND s.tracking_number = $2
+	`
+
+	var s model.Shipment
+	if err := scanShipment(r.pool.QueryRow(ctx, query, carrierCode, trackingNumber), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrShipmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return &s, nil
+}
+
+// =====================================================
+// TRACKING EVENTS
+// =====================================================
+
+// RecordTrackingEvent ghi event + trạng thái mới nhất của vận đơn trong 1 transaction
+// Event trùng (hãng retry) → false, không đổi gì. Event cũ hơn trạng thái hiện tại chỉ lưu lịch sử
+func (r *postgresShippingRepository) RecordTrackingEvent(ctx context.Context, event *model.TrackingEvent) (bool, error) {
+	return database.WithTransactionResult(ctx, r.pool, func(tx pgx.Tx) (bool, error) {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO shipment_tracking_events (
+				id, shipment_id, carrier, tracking_number, carrier_status, status, reason, occurred_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT ON CONSTRAINT uq_shipment_tracking_events DO NOTHING
+		`,
+			event.ID,
+			event.ShipmentID,
+			event.Carrier,
+			event.TrackingNumber,
+			event.CarrierStatus,
+			event.Status,
+			event.Reason,
+			event.OccurredAt,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to record tracking event: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return false, nil
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE shipments
+			SET carrier_status = $2,
+				tracking_status = $3,
+				status_updated_at = $4,
+				updated_at = NOW()
+			WHERE id = $1
+				AND (status_updated_at IS NULL OR status_updated_at <= $4)
+		`, event.ShipmentID, event.CarrierStatus, event.Status, event.OccurredAt)
+		if err != nil {
+			return false, fmt.Errorf("failed to update shipment status: %w", err)
+		}
+
+		// Mã tra cứu của khách = mã vận đơn (admin sửa tay / import đơn cũ có thể lệch)
+		_, err = tx.Exec(ctx, `
+			UPDATE orders o
+			SET tracking_number = s.tracking_number,
+				updated_at = NOW(),
+				version = o.version + 1
+			FROM shipments s
+			WHERE s.id = $1
+				AND o.id = s.order_id
+				AND o.tracking_number IS DISTINCT FROM s.tracking_number
+		`, event.ShipmentID)
+		if err != nil {
+			return false, fmt.Errorf("failed to sync order tracking number: %w", err)
+		}
+
+		return true, nil
+	})
+}
+
+// =====================================================
+// DISPATCH BATCHES
+// =====================================================
+
+func (r *postgresShippingRepository) ListBatchEligibleOrderIDs(
+	ctx context.Context,
+	orderIDs []uuid.UUID,
+	warehouseID *uuid.UUID,
+	limit int,
+) ([]uuid.UUID, error) {
+	query := `
+		SELECT o.id
+		FROM orders o
+		WHERE o.status = ANY($1)
+			AND NOT EXISTS (SELECT 1 FROM shipments s WHERE s.order_id = o.id)
+			AND (cardinality($2::UUID[]) = 0 OR o.id = ANY($2))
+			AND ($3::UUID IS NULL OR o.warehouse_id = $3)
+		ORDER BY o.created_at ASC
+		LIMIT $4
+	`
+
+	if orderIDs == nil {
+		orderIDs = []uuid.UUID{}
+	}
+
+	rows, err := r.pool.Query(ctx, query, model.BatchEligibleOrderStatuses, orderIDs, warehouseID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list eligible orders: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan order id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *postgresShippingRepository) CreateBatch(ctx context.Context, batch *model.DispatchBatch, orderIDs []uuid.UUID) error {
+	return database.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO dispatch_batches (
+				id, batch_date, carrier, label_format, warehouse_id, status,
+				total_orders, succeeded_count, failed_count, created_by, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, 0, 0, $8, $9)
+		`,
+			batch.ID,
+			batch.BatchDate,
+			batch.Carrier,
+			batch.LabelFormat,
+			batch.WarehouseID,
+			batch.Status,
+			batch.TotalOrders,
+			batch.CreatedBy,
+			batch.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create dispatch batch: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO dispatch_batch_orders (batch_id, order_id, status)
+			SELECT $1, UNNEST($2::UUID[]), $3
+		`, batch.ID, orderIDs, model.BatchOrderStatusPending)
+		if err != nil {
+			return fmt.Errorf("failed to add orders to dispatch batch: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *postgresShippingRepository) GetBatch(ctx context.Context, id uuid.UUID) (*model.DispatchBatch, error) {
+	query := `SELECT ` + batchColumns + ` FROM dispatch_batches WHERE id = $1`
+
+	var b model.DispatchBatch
+	if err := scanBatch(r.pool.QueryRow(ctx, query, id), &b); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get dispatch batch: %w", err)
+	}
+	return &b, nil
+}
+
+func (r *postgresShippingRepository) ListBatchOrders(ctx context.Context, batchID uuid.UUID) ([]model.DispatchBatchOrder, error) {
+	query := `
+		SELECT
+			bo.batch_id, bo.order_id, o.order_number, bo.status, bo.shipment_id,
+			s.tracking_number, s.label_url, bo.error_message, bo.processed_at
+		FROM dispatch_batch_orders bo
+		JOIN orders o ON o.id = bo.order_id
+		LEFT JOIN shipments s ON s.id = bo.shipment_id
+		WHERE bo.batch_id = $1
+		ORDER BY o.created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dispatch batch orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]model.DispatchBatchOrder, 0)
+	for rows.Next() {
+		var bo model.DispatchBatchOrder
+		if err := rows.Scan(
+			&bo.BatchID,
+			&bo.OrderID,
+			&bo.OrderNumber,
+			&bo.Status,
+			&bo.ShipmentID,
+			&bo.TrackingNumber,
+			&bo.LabelURL,
+			&bo.ErrorMessage,
+			&bo.ProcessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan dispatch batch order: %w", err)
+		}
+		orders = append(orders, bo)
+	}
+	return orders, rows.Err()
+}
+
+func (r *postgresShippingRepository) ListPendingBatchOrderIDs(ctx context.Context, batchID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT bo.order_id
+		FROM dispatch_batch_orders bo
+		JOIN orders o ON o.id = bo.order_id
+		WHERE bo.batch_id = $1 AND bo.status = $2
+		ORDER BY o.created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, batchID, model.BatchOrderStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending batch orders: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan order id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *postgresShippingRepository) MarkBatchOrder(
+	ctx context.Context,
+	batchID, orderID uuid.UUID,
+	status string,
+	shipmentID *uuid.UUID,
+	errorMessage *string,
+) error {
+	query := `
+		UPDATE dispatch_batch_orders
+		SET status = $3,
+			shipment_id = $4,
+			error_message = $5,
+			processed_at = NOW()
+		WHERE batch_id = $1 AND order_id = $2
+	`
+
+	if _, err := r.pool.Exec(ctx, query, batchID, orderID, status, shipmentID, errorMessage); err != nil {
+		return fmt.Errorf("failed to mark dispatch batch order: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresShippingRepository) CompleteBatch(ctx context.Context, batchID uuid.UUID) (*model.DispatchBatch, error) {
+	query := `
+		UPDATE dispatch_batches b
+		SET succeeded_count = c.succeeded,
+			failed_count = c.failed,
+			status = $2,
+			completed_at = NOW()
+		FROM (
+			SELECT
+				COUNT(*) FILTER (WHERE status = 'succeeded') AS succeeded,
+				COUNT(*) FILTER (WHERE status = 'failed') AS failed
+			FROM dispatch_batch_orders
+			WHERE batch_id = $1
+		) c
+		WHERE b.id = $1
+		RETURNING
+			b.id, b.batch_date, b.carrier, b.label_format, b.warehouse_id, b.status,
+			b.total_orders, b.succeeded_count, b.failed_count, b.created_by, b.created_at, b.completed_at
+	`
+
+	var b model.DispatchBatch
+	if err := scanBatch(r.pool.QueryRow(ctx, query, batchID, model.BatchStatusCompleted), &b); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to complete dispatch batch: %w", err)
+	}
+	return &b, nil
+}
+
+func (r *postgresShippingRepository) ListBatchShipments(ctx context.Context, batchID uuid.UUID) ([]model.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + `
+		FROM dispatch_batch_orders bo
+		JOIN shipments s ON s.id = bo.shipment_id
+		JOIN orders o ON o.id = s.order_id
+		WHERE bo.batch_id = $1 AND bo.status = $2
+		ORDER BY o.created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, batchID, model.BatchOrderStatusSucceeded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batch shipments: %w", err)
+	}
+	defer rows.Close()
+
+	shipments := make([]model.Shipment, 0)
+	for rows.Next() {
+		var s model.Shipment
+		if err := scanShipment(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan shipment: %w", err)
+		}
+		shipments = append(shipments, s)
+	}
+	return shipments, rows.Err()
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/shipping/model"
+)
+
+// OrderStatusSync chuyển đơn theo trạng thái vận đơn hãng báo (shipping → delivered / returned)
+// Order service implement (order service đã dùng shipping làm ShipmentTrigger → không import ngược được)
+type OrderStatusSync interface {
+	// SyncCarrierStatus đi theo workflow từ trạng thái hiện tại tới toStatus, trả trạng thái sau cùng của đơn
+	SyncCarrierStatus(ctx context.Context, orderID uuid.UUID, toStatus, note string) (string, error)
+}
+
+// =====================================================
+// SHIPPING SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ========================================
+	// LABELS
+	// ========================================
+
+	// ListCarriers lists configured carriers with supported label formats
+	ListCarriers(ctx context.Context) []model.CarrierResponse
+
+	// PurchaseLabel buys label for order; đơn đã có nhãn → trả nhãn cũ (không mua lại)
+	PurchaseLabel(ctx context.Context, adminID, orderID uuid.UUID, req model.PurchaseLabelRequest) (*model.Shipment, error)
+
+	// GetOrderShipment gets shipment of order
+	GetOrderShipment(ctx context.Context, orderID uuid.UUID) (*model.Shipment, error)
+
+	// GetLabel loads printable label file of shipment
+	GetLabel(ctx context.Context, shipmentID uuid.UUID) (*model.LabelFile, error)
+
+	// TriggerShipment buys label with default carrier (hook trigger_shipment của workflow đơn)
+	TriggerShipment(ctx context.Context, orderID uuid.UUID) error
+
+	// CreateOrderShipment buys label when order is confirmed (worker, bật bằng SHIPPING_CREATE_ON_CONFIRM)
+	CreateOrderShipment(ctx context.Context, orderID uuid.UUID) error
+
+	// ========================================
+	// RATES + TRACKING
+	// ========================================
+
+	// QuoteShippingFee sums default carrier quotes of parcels (checkout, trước khi tạo đơn)
+	QuoteShippingFee(ctx context.Context, parcels []model.QuoteParcel) (decimal.Decimal, error)
+
+	// ProcessTrackingWebhook records carrier status event and moves order along (delivered, returned)
+	ProcessTrackingWebhook(ctx context.Context, carrierCode string, body []byte) (*model.TrackingWebhookResult, error)
+
+	// ========================================
+	// DISPATCH BATCHES
+	// ========================================
+
+	// CreateDispatchBatch selects orders waiting for label and queues bulk purchase
+	CreateDispatchBatch(ctx context.Context, adminID uuid.UUID, req model.CreateDispatchBatchRequest) (*model.DispatchBatchResponse, error)
+
+	// GetDispatchBatch gets batch progress with per-order result
+	GetDispatchBatch(ctx context.Context, batchID uuid.UUID) (*model.DispatchBatchResponse, error)
+
+	// GetBatchLabels builds one printable file for all labels of completed batch
+	GetBatchLabels(ctx context.Context, batchID uuid.UUID) (*model.LabelFile, error)
+
+	// ProcessDispatchBatch buys labels for pending orders of batch (worker)
+	ProcessDispatchBatch(ctx context.Context, batchID uuid.UUID) error
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"bookstore-backend/internal/domains/shipping/carrier"
+	"bookstore-backend/internal/domains/shipping/model"
+)
+
+// quoteTimeout - báo giá nằm trên đường checkout: hãng chậm → order dùng phí cố định thay vì treo request
+const quoteTimeout = 5 * time.Second
+
+// =====================================================
+// RATE QUOTES
+// =====================================================
+
+// QuoteShippingFee báo giá từng kiện (mỗi kho 1 kiện) với hãng mặc định rồi cộng lại
+// Lỗi bất kỳ kiện nào → trả lỗi, order service tự fallback phí cố định (không tính phí thiếu kiện)
+func (s *shippingService) QuoteShippingFee(ctx context.Context, parcels []model.QuoteParcel) (decimal.Decimal, error) {
+	if !s.cfg.QuoteRates {
+		return decimal.Zero, fmt.Errorf("rate quoting disabled")
+	}
+
+	c, ok := s.carriers[s.cfg.DefaultCarrier]
+	if !ok {
+		return decimal.Zero, model.NewCarrierUnavailableError(s.cfg.DefaultCarrier)
+	}
+	quoter, ok := c.(carrier.RateQuoter)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("carrier %s does not support rate quotes", c.Code())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, quoteTimeout)
+	defer cancel()
+
+	total := decimal.Zero
+	for _, parcel := range parcels {
+		origin, err := s.repo.GetQuoteOrigin(ctx, parcel.WarehouseID)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		weight, err := s.repo.GetParcelWeightGrams(ctx, parcel.Items)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		quote, err := quoter.QuoteRate(ctx, carrier.RateRequest{
+			From: carrier.Address{
+				Name:     s.cfg.Sender.Name + " - " + origin.Name,
+				Phone:    s.cfg.Sender.Phone,
+				Street:   origin.Address,
+				Province: origin.Province,
+			},
+			To: carrier.Address{
+				Street:   parcel.Street,
+				Ward:     parcel.Ward,
+				District: parcel.District,
+				Province: parcel.Province,
+			},
+			WeightGrams:   weight,
+			DeclaredValue: parcel.DeclaredValue,
+		})
+		if err != nil {
+			return decimal.Zero, model.NewCarrierError(err)
+		}
+		total = total.Add(quote.Fee)
+	}
+
+	return total.Round(0), nil
+}
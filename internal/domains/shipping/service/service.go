@@ -0,0 +1,575 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/shipping/carrier"
+	"bookstore-backend/internal/domains/shipping/model"
+	"bookstore-backend/internal/domains/shipping/repository"
+	"bookstore-backend/internal/infrastructure/storage"
+	types "bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/database"
+	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+)
+
+// Config - carrier / định dạng nhãn mặc định + người gửi khi đơn chưa gán kho
+type Config struct {
+	DefaultCarrier     string
+	DefaultLabelFormat string
+	Sender             carrier.Address
+	// Báo giá phí ship thật lúc checkout (false → order dùng phí cố định ORDER_SHIPPING_FEE)
+	QuoteRates bool
+	// Tạo vận đơn ngay khi đơn được xác nhận (false → kho mua nhãn tay / theo đợt)
+	CreateOnConfirm bool
+}
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type shippingService struct {
+	repo        repository.ShippingRepository
+	pool        *pgxpool.Pool
+	carriers    map[string]carrier.Carrier
+	storage     *storage.MinIOStorage
+	asynqClient *asynq.Client
+	cfg         Config
+
+	// Chuyển trạng thái đơn theo webhook của hãng (set sau khi order service được tạo)
+	orderSync OrderStatusSync
+}
+
+func NewShippingService(
+	repo repository.ShippingRepository,
+	pool *pgxpool.Pool,
+	carriers []carrier.Carrier,
+	storage *storage.MinIOStorage,
+	asynqClient *asynq.Client,
+	cfg Config,
+) ServiceInterface {
+	byCode := make(map[string]carrier.Carrier, len(carriers))
+	for _, c := range carriers {
+		byCode[c.Code()] = c
+	}
+	return &shippingService{
+		repo:        repo,
+		pool:        pool,
+		carriers:    byCode,
+		storage:     storage,
+		asynqClient: asynqClient,
+		cfg:         cfg,
+	}
+}
+
+// =====================================================
+// LABELS
+// =====================================================
+
+func (s *shippingService) ListCarriers(ctx context.Context) []model.CarrierResponse {
+	result := make([]model.CarrierResponse, 0, len(s.carriers))
+	for _, code := range carrier.ValidCodes {
+		c, ok := s.carriers[code]
+		if !ok {
+			continue
+		}
+		formats := make([]string, 0, 2)
+		for _, f := range []string{carrier.FormatPDF, carrier.FormatZPL} {
+			if c.SupportsFormat(f) {
+				formats = append(formats, f)
+			}
+		}
+		result = append(result, model.CarrierResponse{
+			Code:      code,
+			Formats:   formats,
+			IsDefault: code == s.cfg.DefaultCarrier,
+		})
+	}
+	return result
+}
+
+func (s *shippingService) PurchaseLabel(
+	ctx context.Context,
+	adminID, orderID uuid.UUID,
+	req model.PurchaseLabelRequest,
+) (*model.Shipment, error) {
+	req.Normalize()
+	return s.purchaseLabel(ctx, orderID, req.Carrier, req.LabelFormat, &adminID, nil)
+}
+
+func (s *shippingService) GetOrderShipment(ctx context.Context, orderID uuid.UUID) (*model.Shipment, error) {
+	shipment, err := s.repo.GetShipmentByOrderID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, model.ErrShipmentNotFound) {
+			return nil, model.NewShipmentNotFoundError()
+		}
+		return nil, err
+	}
+	return shipment, nil
+}
+
+func (s *shippingService) GetLabel(ctx context.Context, shipmentID uuid.UUID) (*model.LabelFile, error) {
+	shipment, err := s.repo.GetShipmentByID(ctx, shipmentID)
+	if err != nil {
+		if errors.Is(err, model.ErrShipmentNotFound) {
+			return nil, model.NewShipmentNotFoundError()
+		}
+		return nil, err
+	}
+
+	data, err := s.storage.Download(ctx, shipment.LabelKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load label: %w", err)
+	}
+
+	return &model.LabelFile{
+		FileName:    labelFileName(shipment),
+		ContentType: carrier.ContentTypeFor(shipment.LabelFormat),
+		Data:        data,
+	}, nil
+}
+
+func (s *shippingService) TriggerShipment(ctx context.Context, orderID uuid.UUID) error {
+	_, err := s.purchaseLabel(ctx, orderID, "", "", nil, nil)
+	return err
+}
+
+// CreateOrderShipment - đơn đã huỷ / đã giao trước khi job chạy, hoặc kênh bán tự lo vận chuyển → bỏ qua, không coi là lỗi
+func (s *shippingService) CreateOrderShipment(ctx context.Context, orderID uuid.UUID) error {
+	if !s.cfg.CreateOnConfirm {
+		return nil
+	}
+
+	shipment, err := s.purchaseLabel(ctx, orderID, "", "", nil, nil)
+	if err != nil {
+		if errors.Is(err, model.ErrOrderNotEligible) ||
+			errors.Is(err, model.ErrOrderNotFound) ||
+			errors.Is(err, model.ErrCarrierNotAllowed) {
+			logger.Info("Skip shipment on confirmation", map[string]interface{}{
+				"order_id": orderID.String(),
+				"reason":   err.Error(),
+			})
+			return nil
+		}
+		return err
+	}
+
+	logger.Info("Shipment created on order confirmation", map[string]interface{}{
+		"order_id":        orderID.String(),
+		"tracking_number": shipment.TrackingNumber,
+	})
+	return nil
+}
+
+// purchaseLabel tạo vận đơn với hãng → tải nhãn → lưu storage → ghi shipments
+//
+// Khoá đơn (FOR UPDATE) suốt lúc gọi hãng: 2 request song song (admin bấm 2 lần, batch + hook)
+// không mua 2 vận đơn cho 1 đơn. Đơn đã có nhãn → trả nhãn cũ.
+func (s *shippingService) purchaseLabel(
+	ctx context.Context,
+	orderID uuid.UUID,
+	carrierCode, format string,
+	purchasedBy, batchID *uuid.UUID,
+) (*model.Shipment, error) {
+	parcel, err := s.repo.GetParcel(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, model.ErrOrderNotFound) {
+			return nil, model.NewOrderNotFoundError()
+		}
+		return nil, err
+	}
+	if !isLabelEligible(parcel.OrderStatus) {
+		return nil, model.NewOrderNotEligibleError(parcel.OrderStatus)
+	}
+
+	// Không chỉ định hãng + kênh giới hạn hãng → hãng đầu tiên của kênh thay cho hãng mặc định
+	if carrierCode == "" && len(parcel.ChannelCarriers) > 0 {
+		carrierCode = parcel.ChannelCarriers[0]
+	}
+	c, format, err := s.resolveCarrier(carrierCode, format)
+	if err != nil {
+		return nil, err
+	}
+	if !parcel.AllowsCarrier(c.Code()) {
+		return nil, model.NewCarrierNotAllowedError(c.Code(), parcel.Channel)
+	}
+
+	return database.WithTransactionResult(ctx, s.pool, func(tx pgx.Tx) (*model.Shipment, error) {
+		if err := s.repo.LockOrderWithTx(ctx, tx, orderID); err != nil {
+			if errors.Is(err, model.ErrOrderNotFound) {
+				return nil, model.NewOrderNotFoundError()
+			}
+			return nil, err
+		}
+
+		existing, err := s.repo.GetShipmentByOrderIDWithTx(ctx, tx, orderID)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, model.ErrShipmentNotFound) {
+			return nil, err
+		}
+
+		result, err := c.CreateShipment(ctx, s.buildShipmentRequest(parcel))
+		if err != nil {
+			return nil, model.NewCarrierError(err)
+		}
+
+		// Từ đây vận đơn đã tồn tại bên hãng: lỗi → log mã vận đơn để kho huỷ tay trên portal của hãng
+		shipment, err := s.storeLabel(ctx, c, parcel, result, format, purchasedBy, batchID)
+		if err == nil {
+			err = s.repo.CreateShipmentWithTx(ctx, tx, shipment)
+		}
+		if err != nil {
+			logger.Error("Carrier shipment created but label not saved, cancel it on carrier portal", err)
+			logger.Info("Orphan carrier shipment", map[string]interface{}{
+				"order_id":        orderID.String(),
+				"carrier":         c.Code(),
+				"tracking_number": result.TrackingNumber,
+			})
+			return nil, err
+		}
+
+		logger.Info("Shipping label purchased", map[string]interface{}{
+			"order_id":        orderID.String(),
+			"carrier":         shipment.Carrier,
+			"tracking_number": shipment.TrackingNumber,
+			"shipping_cost":   shipment.ShippingCost.String(),
+		})
+
+		return shipment, nil
+	})
+}
+
+// storeLabel tải nhãn từ hãng và lưu lên storage
+func (s *shippingService) storeLabel(
+	ctx context.Context,
+	c carrier.Carrier,
+	parcel *model.Parcel,
+	result *carrier.ShipmentResult,
+	format string,
+	purchasedBy, batchID *uuid.UUID,
+) (*model.Shipment, error) {
+	label, err := c.GetLabel(ctx, result.TrackingNumber, format)
+	if err != nil {
+		return nil, model.NewCarrierError(err)
+	}
+
+	now := time.Now()
+	key := fmt.Sprintf("%s/%s/%s-%s.%s",
+		model.LabelStoragePrefix, now.Format("2006-01-02"), parcel.OrderNumber, result.TrackingNumber, format)
+	url, err := s.storage.Upload(ctx, key, label.Data, label.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store label: %w", err)
+	}
+
+	return &model.Shipment{
+		ID:             uuid.New(),
+		OrderID:        parcel.OrderID,
+		OrderNumber:    parcel.OrderNumber,
+		BatchID:        batchID,
+		Carrier:        c.Code(),
+		TrackingNumber: result.TrackingNumber,
+		ShippingCost:   result.Fee,
+		CODAmount:      parcel.CODAmount(),
+		WeightGrams:    parcel.WeightGrams,
+		LabelFormat:    format,
+		LabelURL:       url,
+		LabelKey:       key,
+		PurchasedBy:    purchasedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// resolveCarrier áp dụng mặc định + kiểm tra hãng đã cấu hình và hỗ trợ định dạng
+func (s *shippingService) resolveCarrier(code, format string) (carrier.Carrier, string, error) {
+	if code == "" {
+		code = s.cfg.DefaultCarrier
+	}
+	if format == "" {
+		format = s.cfg.DefaultLabelFormat
+	}
+	if format != carrier.FormatPDF && format != carrier.FormatZPL {
+		return nil, "", model.NewInvalidRequestError("label_format must be one of: pdf, zpl")
+	}
+
+	c, ok := s.carriers[code]
+	if !ok {
+		return nil, "", model.NewCarrierUnavailableError(code)
+	}
+	if !c.SupportsFormat(format) {
+		return nil, "", model.NewFormatNotSupportedError(code, format)
+	}
+	return c, format, nil
+}
+
+// buildShipmentRequest - đơn gán kho gửi từ kho đó, còn lại dùng địa chỉ người gửi mặc định
+func (s *shippingService) buildShipmentRequest(parcel *model.Parcel) carrier.ShipmentRequest {
+	from := s.cfg.Sender
+	if parcel.WarehouseID != nil && parcel.WarehouseAddress != nil {
+		from = carrier.Address{
+			Name:     s.cfg.Sender.Name + " - " + derefString(parcel.WarehouseName),
+			Phone:    s.cfg.Sender.Phone,
+			Street:   *parcel.WarehouseAddress,
+			Province: derefString(parcel.WarehouseProvince),
+		}
+	}
+
+	return carrier.ShipmentRequest{
+		ClientOrderCode: parcel.OrderNumber,
+		From:            from,
+		To: carrier.Address{
+			Name:     parcel.RecipientName,
+			Phone:    parcel.RecipientPhone,
+			Street:   parcel.Street,
+			Ward:     parcel.Ward,
+			District: parcel.District,
+			Province: parcel.Province,
+		},
+		WeightGrams:   parcel.WeightGrams,
+		ItemCount:     parcel.ItemCount,
+		CODAmount:     parcel.CODAmount(),
+		DeclaredValue: parcel.Total,
+		Note:          derefString(parcel.CustomerNote),
+	}
+}
+
+// =====================================================
+// DISPATCH BATCHES
+// =====================================================
+
+// CreateDispatchBatch - OrderIDs chỉ định nhưng không đủ điều kiện (đã có nhãn, sai trạng thái) bị bỏ qua
+func (s *shippingService) CreateDispatchBatch(
+	ctx context.Context,
+	adminID uuid.UUID,
+	req model.CreateDispatchBatchRequest,
+) (*model.DispatchBatchResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, format, err := s.resolveCarrier(req.Carrier, req.LabelFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	orderIDs, err := s.repo.ListBatchEligibleOrderIDs(ctx, req.OrderIDs, req.WarehouseID, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(orderIDs) == 0 {
+		return nil, model.NewNoEligibleOrdersError()
+	}
+
+	now := time.Now()
+	batch := &model.DispatchBatch{
+		ID:          uuid.New(),
+		BatchDate:   now,
+		Carrier:     c.Code(),
+		LabelFormat: format,
+		WarehouseID: req.WarehouseID,
+		Status:      model.BatchStatusProcessing,
+		TotalOrders: len(orderIDs),
+		CreatedBy:   &adminID,
+		CreatedAt:   now,
+	}
+	if err := s.repo.CreateBatch(ctx, batch, orderIDs); err != nil {
+		return nil, err
+	}
+
+	payload, _ := json.Marshal(map[string]string{"batch_id": batch.ID.String()})
+	task := tracing.NewTask(ctx, types.TypeGenerateDispatchLabels, payload)
+	if _, err := s.asynqClient.Enqueue(task, asynq.Queue(types.QueueOrder), asynq.MaxRetry(3)); err != nil {
+		return nil, fmt.Errorf("enqueue dispatch batch job: %w", err)
+	}
+
+	logger.Info("Dispatch batch created", map[string]interface{}{
+		"batch_id": batch.ID.String(),
+		"carrier":  batch.Carrier,
+		"orders":   batch.TotalOrders,
+		"admin_id": adminID.String(),
+	})
+
+	return s.GetDispatchBatch(ctx, batch.ID)
+}
+
+func (s *shippingService) GetDispatchBatch(ctx context.Context, batchID uuid.UUID) (*model.DispatchBatchResponse, error) {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		if errors.Is(err, model.ErrBatchNotFound) {
+			return nil, model.NewBatchNotFoundError()
+		}
+		return nil, err
+	}
+
+	orders, err := s.repo.ListBatchOrders(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DispatchBatchResponse{
+		DispatchBatch: *batch,
+		Orders:        orders,
+	}, nil
+}
+
+// GetBatchLabels - toàn bộ nhãn ZPL → nối thành 1 file gửi thẳng máy in nhiệt; có PDF → nén zip
+func (s *shippingService) GetBatchLabels(ctx context.Context, batchID uuid.UUID) (*model.LabelFile, error) {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		if errors.Is(err, model.ErrBatchNotFound) {
+			return nil, model.NewBatchNotFoundError()
+		}
+		return nil, err
+	}
+	if batch.Status != model.BatchStatusCompleted {
+		return nil, model.NewBatchNotCompletedError()
+	}
+
+	shipments, err := s.repo.ListBatchShipments(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if len(shipments) == 0 {
+		return nil, model.NewShipmentNotFoundError()
+	}
+
+	allZPL := true
+	for i := range shipments {
+		if shipments[i].LabelFormat != carrier.FormatZPL {
+			allZPL = false
+			break
+		}
+	}
+
+	baseName := fmt.Sprintf("dispatch-%s-%s", batch.BatchDate.Format("2006-01-02"), batch.ID.String()[:8])
+
+	if allZPL {
+		var buf bytes.Buffer
+		for i := range shipments {
+			data, err := s.storage.Download(ctx, shipments[i].LabelKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load label %s: %w", shipments[i].TrackingNumber, err)
+			}
+			buf.Write(data)
+		}
+		return &model.LabelFile{
+			FileName:    baseName + ".zpl",
+			ContentType: carrier.ContentTypeFor(carrier.FormatZPL),
+			Data:        buf.Bytes(),
+		}, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := range shipments {
+		data, err := s.storage.Download(ctx, shipments[i].LabelKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load label %s: %w", shipments[i].TrackingNumber, err)
+		}
+		w, err := zw.Create(labelFileName(&shipments[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build label archive: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to build label archive: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build label archive: %w", err)
+	}
+
+	return &model.LabelFile{
+		FileName:    baseName + ".zip",
+		ContentType: "application/zip",
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// ProcessDispatchBatch - lỗi mua nhãn từng đơn ghi vào batch rồi làm tiếp đơn sau (kho mua lại bằng đợt mới)
+// Lỗi ghi kết quả → return để asynq retry, chỉ xử lý tiếp các đơn còn pending
+func (s *shippingService) ProcessDispatchBatch(ctx context.Context, batchID uuid.UUID) error {
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	if batch.Status == model.BatchStatusCompleted {
+		return nil
+	}
+
+	orderIDs, err := s.repo.ListPendingBatchOrderIDs(ctx, batchID)
+	if err != nil {
+		return err
+	}
+
+	for _, orderID := range orderIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		shipment, err := s.purchaseLabel(ctx, orderID, batch.Carrier, batch.LabelFormat, batch.CreatedBy, &batch.ID)
+		if err != nil {
+			message := err.Error()
+			var shipErr *model.ShippingError
+			if errors.As(err, &shipErr) {
+				message = shipErr.Message
+			}
+			if err := s.repo.MarkBatchOrder(ctx, batchID, orderID, model.BatchOrderStatusFailed, nil, &message); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.repo.MarkBatchOrder(ctx, batchID, orderID, model.BatchOrderStatusSucceeded, &shipment.ID, nil); err != nil {
+			return err
+		}
+	}
+
+	completed, err := s.repo.CompleteBatch(ctx, batchID)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Dispatch batch completed", map[string]interface{}{
+		"batch_id":  batchID.String(),
+		"succeeded": completed.SucceededCount,
+		"failed":    completed.FailedCount,
+	})
+	return nil
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+func isLabelEligible(status string) bool {
+	for _, st := range model.LabelEligibleOrderStatuses {
+		if st == status {
+			return true
+		}
+	}
+	return false
+}
+
+func labelFileName(shipment *model.Shipment) string {
+	return fmt.Sprintf("%s-%s.%s", shipment.OrderNumber, shipment.TrackingNumber, shipment.LabelFormat)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/shipping/carrier"
+	"bookstore-backend/internal/domains/shipping/model"
+	"bookstore-backend/pkg/logger"
+)
+
+// trackingOrderStatuses - trạng thái vận đơn (đã chuẩn hoá) kéo theo trạng thái đơn
+// Huỷ vận đơn không huỷ đơn: kho tạo lại vận đơn hoặc admin tự huỷ
+var trackingOrderStatuses = map[string]string{
+	carrier.TrackingStatusInTransit: "shipping",
+	carrier.TrackingStatusDelivered: "delivered",
+	carrier.TrackingStatusReturned:  "returned",
+}
+
+// SetOrderStatusSync sets order service used to move orders along carrier status
+func (s *shippingService) SetOrderStatusSync(sync OrderStatusSync) {
+	s.orderSync = sync
+}
+
+// =====================================================
+// TRACKING WEBHOOKS
+// =====================================================
+
+// ProcessTrackingWebhook ghi event → cập nhật trạng thái vận đơn + mã tra cứu của đơn → chuyển đơn theo workflow
+//
+// Lỗi chuyển trạng thái đơn (version conflict, DB) trả lỗi để hãng gửi lại; event đã ghi nên lần sau
+// bị coi là trùng → chuyển trạng thái lần nữa ngay cả khi event trùng (SyncCarrierStatus idempotent).
+func (s *shippingService) ProcessTrackingWebhook(
+	ctx context.Context,
+	carrierCode string,
+	body []byte,
+) (*model.TrackingWebhookResult, error) {
+	update, err := carrier.ParseTrackingWebhook(carrierCode, body)
+	if err != nil {
+		return nil, model.NewInvalidWebhookError(err)
+	}
+
+	result := &model.TrackingWebhookResult{
+		TrackingNumber: update.TrackingNumber,
+		CarrierStatus:  update.CarrierStatus,
+		Status:         update.Status,
+	}
+
+	shipment, err := s.repo.GetShipmentByTracking(ctx, carrierCode, update.TrackingNumber)
+	if err != nil {
+		if errors.Is(err, model.ErrShipmentNotFound) {
+			// Vận đơn tạo tay trên portal hãng / của shop khác dùng chung tài khoản
+			logger.Info("Tracking webhook for unknown shipment", map[string]interface{}{
+				"carrier":           carrierCode,
+				"tracking_number":   update.TrackingNumber,
+				"client_order_code": update.ClientOrderCode,
+			})
+			result.Ignored = true
+			return result, nil
+		}
+		return nil, err
+	}
+
+	event := &model.TrackingEvent{
+		ID:             uuid.New(),
+		ShipmentID:     shipment.ID,
+		Carrier:        carrierCode,
+		TrackingNumber: update.TrackingNumber,
+		CarrierStatus:  update.CarrierStatus,
+		Status:         update.Status,
+		OccurredAt:     update.OccurredAt,
+	}
+	if update.Reason != "" {
+		event.Reason = &update.Reason
+	}
+	recorded, err := s.repo.RecordTrackingEvent(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	result.Ignored = !recorded
+
+	toStatus, ok := trackingOrderStatuses[update.Status]
+	if !ok || s.orderSync == nil {
+		return result, nil
+	}
+
+	note := fmt.Sprintf("%s: %s", carrierCode, update.CarrierStatus)
+	if update.Reason != "" {
+		note += " - " + update.Reason
+	}
+	orderStatus, err := s.orderSync.SyncCarrierStatus(ctx, shipment.OrderID, toStatus, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync order status: %w", err)
+	}
+	result.OrderStatus = orderStatus
+
+	logger.Info("Tracking webhook processed", map[string]interface{}{
+		"carrier":         carrierCode,
+		"tracking_number": update.TrackingNumber,
+		"carrier_status":  update.CarrierStatus,
+		"order_id":        shipment.OrderID.String(),
+		"order_status":    orderStatus,
+		"duplicate":       !recorded,
+	})
+
+	return result, nil
+}
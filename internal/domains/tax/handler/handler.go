@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bookstore-backend/internal/domains/tax/model"
+	"bookstore-backend/internal/domains/tax/service"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	svc service.Service
+}
+
+func NewHandler(svc service.Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// ListRules danh sách rule thuế (cả rule đã tắt)
+// GET /admin/tax-rules
+func (h *Handler) ListRules(c *gin.Context) {
+	rules, err := h.svc.ListRules(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list tax rules", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Tax rules retrieved successfully", rules)
+}
+
+// CreateRule thêm rule thuế theo tỉnh và/hoặc danh mục
+// POST /admin/tax-rules
+func (h *Handler) CreateRule(c *gin.Context) {
+	var req model.CreateTaxRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	rule, err := h.svc.CreateRule(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create tax rule")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Tax rule created successfully", rule)
+}
+
+// UpdateRule đổi tên / thuế suất / inclusive / bật tắt (phạm vi tỉnh + danh mục không đổi được)
+// PUT /admin/tax-rules/:id
+func (h *Handler) UpdateRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid tax rule ID", err.Error())
+		return
+	}
+
+	var req model.UpdateTaxRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	rule, err := h.svc.UpdateRule(c.Request.Context(), id, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update tax rule")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Tax rule updated successfully", rule)
+}
+
+// DeleteRule xoá rule (dòng thuế của đơn cũ giữ nguyên snapshot)
+// DELETE /admin/tax-rules/:id
+func (h *Handler) DeleteRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid tax rule ID", err.Error())
+		return
+	}
+
+	if err := h.svc.DeleteRule(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Failed to delete tax rule")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Tax rule deleted successfully", nil)
+}
+
+func (h *Handler) handleError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrTaxRuleNotFound):
+		response.Error(c, http.StatusNotFound, "Tax rule not found", err.Error())
+	case errors.Is(err, model.ErrTaxRuleExists):
+		response.Error(c, http.StatusConflict, msg, err.Error())
+	case errors.Is(err, model.ErrInvalidTaxRule):
+		response.Error(c, http.StatusBadRequest, msg, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
@@ -0,0 +1,242 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrTaxRuleNotFound = errors.New("tax rule not found")
+	ErrTaxRuleExists   = errors.New("an active tax rule already exists for this province and category")
+	ErrInvalidTaxRule  = errors.New("invalid tax rule")
+)
+
+// Entity rule thuế (map bảng tax_rules)
+// Province / CategoryID nil = áp cho mọi tỉnh / mọi danh mục
+type TaxRule struct {
+	ID               uuid.UUID       `json:"id"`
+	Name             string          `json:"name"`
+	Province         *string         `json:"province,omitempty"`
+	CategoryID       *uuid.UUID      `json:"category_id,omitempty"`
+	Rate             decimal.Decimal `json:"rate"` // 0.1 = 10%
+	PriceIncludesTax bool            `json:"price_includes_tax"`
+	IsActive         bool            `json:"is_active"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// specificity độ cụ thể của rule khi khớp (tỉnh + danh mục > danh mục > tỉnh > mặc định), -1 = không khớp
+func (r *TaxRule) specificity(province string, categoryID *uuid.UUID) int {
+	score := 0
+	if r.CategoryID != nil {
+		if categoryID == nil || *r.CategoryID != *categoryID {
+			return -1
+		}
+		score += 2
+	}
+	if r.Province != nil {
+		if !strings.EqualFold(strings.TrimSpace(*r.Province), strings.TrimSpace(province)) {
+			return -1
+		}
+		score++
+	}
+	return score
+}
+
+// DTO tạo rule (admin)
+type CreateTaxRuleRequest struct {
+	Name             string          `json:"name" binding:"required"`
+	Province         *string         `json:"province,omitempty"`
+	CategoryID       *uuid.UUID      `json:"category_id,omitempty"`
+	Rate             decimal.Decimal `json:"rate"`
+	PriceIncludesTax *bool           `json:"price_includes_tax,omitempty"` // mặc định true (giá niêm yết đã gồm VAT)
+}
+
+func (r *CreateTaxRuleRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTaxRule)
+	}
+	if r.Province != nil {
+		province := strings.TrimSpace(*r.Province)
+		if province == "" {
+			r.Province = nil
+		} else {
+			r.Province = &province
+		}
+	}
+	return validateRate(r.Rate)
+}
+
+// DTO sửa rule (admin), field nil = giữ nguyên
+type UpdateTaxRuleRequest struct {
+	Name             *string          `json:"name,omitempty"`
+	Rate             *decimal.Decimal `json:"rate,omitempty"`
+	PriceIncludesTax *bool            `json:"price_includes_tax,omitempty"`
+	IsActive         *bool            `json:"is_active,omitempty"`
+}
+
+func (r *UpdateTaxRuleRequest) Validate() error {
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		if name == "" {
+			return fmt.Errorf("%w: name is required", ErrInvalidTaxRule)
+		}
+		r.Name = &name
+	}
+	if r.Rate != nil {
+		return validateRate(*r.Rate)
+	}
+	return nil
+}
+
+func validateRate(rate decimal.Decimal) error {
+	if rate.IsNegative() || rate.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return fmt.Errorf("%w: rate must be in [0, 1)", ErrInvalidTaxRule)
+	}
+	return nil
+}
+
+// ========================================
+// TÍNH THUẾ
+// ========================================
+
+// TaxableItem 1 dòng hàng chịu thuế (Amount = đơn giá đã áp bậc * số lượng)
+type TaxableItem struct {
+	BookID     uuid.UUID
+	CategoryID *uuid.UUID
+	Amount     decimal.Decimal
+}
+
+// CalculateRequest đầu vào tính thuế: tỉnh giao hàng + dòng hàng + giảm giá cấp đơn
+// Discount (mã giảm tiền hàng) phân bổ theo tỷ lệ tiền hàng → thuế tính trên giá sau giảm
+type CalculateRequest struct {
+	Province string
+	Items    []TaxableItem
+	Discount decimal.Decimal
+}
+
+// TaxLine dòng thuế trên breakdown / hoá đơn (gộp theo rule)
+type TaxLine struct {
+	TaxRuleID        *uuid.UUID      `json:"tax_rule_id,omitempty"`
+	Name             string          `json:"name"`
+	Rate             decimal.Decimal `json:"rate"`
+	PriceIncludesTax bool            `json:"price_includes_tax"`
+	TaxableAmount    decimal.Decimal `json:"taxable_amount"`
+	TaxAmount        decimal.Decimal `json:"tax_amount"`
+}
+
+// Result kết quả tính thuế
+// TotalTax = tổng mọi dòng (hiển thị), AddedTax = phần cộng thêm vào tổng đơn (chỉ rule exclusive)
+type Result struct {
+	Lines    []TaxLine       `json:"lines"`
+	TotalTax decimal.Decimal `json:"total_tax"`
+	AddedTax decimal.Decimal `json:"added_tax"`
+}
+
+// ZeroResult không có rule nào khớp (hành vi cũ: thuế 0%)
+func ZeroResult() *Result {
+	return &Result{Lines: []TaxLine{}, TotalTax: decimal.Zero, AddedTax: decimal.Zero}
+}
+
+// EffectiveRate thuế suất hiển thị: 1 dòng thuế → rate của dòng đó, nhiều thuế suất → 0 (xem Lines)
+func (r *Result) EffectiveRate() decimal.Decimal {
+	if r == nil || len(r.Lines) != 1 {
+		return decimal.Zero
+	}
+	return r.Lines[0].Rate
+}
+
+// Calculate áp rule cụ thể nhất cho từng dòng hàng, gộp dòng thuế theo rule.
+// Inclusive: thuế = base * rate / (1 + rate) (tách từ giá), exclusive: thuế = base * rate (cộng thêm).
+// Làm tròn tới đồng theo từng dòng thuế
+func Calculate(rules []TaxRule, req CalculateRequest) *Result {
+	result := ZeroResult()
+	if len(rules) == 0 || len(req.Items) == 0 {
+		return result
+	}
+
+	gross := decimal.Zero
+	for _, item := range req.Items {
+		gross = gross.Add(item.Amount)
+	}
+	if !gross.IsPositive() {
+		return result
+	}
+	discount := decimal.Min(decimal.Max(req.Discount, decimal.Zero), gross)
+
+	type bucket struct {
+		rule *TaxRule
+		base decimal.Decimal
+	}
+	buckets := make(map[uuid.UUID]*bucket)
+	for _, item := range req.Items {
+		if !item.Amount.IsPositive() {
+			continue // dòng quà 0đ
+		}
+		rule := matchRule(rules, req.Province, item.CategoryID)
+		if rule == nil {
+			continue
+		}
+		// Phân bổ giảm giá theo tỷ lệ tiền hàng
+		base := item.Amount.Sub(discount.Mul(item.Amount).Div(gross))
+		b, ok := buckets[rule.ID]
+		if !ok {
+			b = &bucket{rule: rule, base: decimal.Zero}
+			buckets[rule.ID] = b
+		}
+		b.base = b.base.Add(base)
+	}
+
+	one := decimal.NewFromInt(1)
+	for _, b := range buckets {
+		base := b.base.Round(0)
+		var tax decimal.Decimal
+		if b.rule.PriceIncludesTax {
+			tax = base.Mul(b.rule.Rate).Div(one.Add(b.rule.Rate)).Round(0)
+		} else {
+			tax = base.Mul(b.rule.Rate).Round(0)
+			result.AddedTax = result.AddedTax.Add(tax)
+		}
+		ruleID := b.rule.ID
+		result.Lines = append(result.Lines, TaxLine{
+			TaxRuleID:        &ruleID,
+			Name:             b.rule.Name,
+			Rate:             b.rule.Rate,
+			PriceIncludesTax: b.rule.PriceIncludesTax,
+			TaxableAmount:    base,
+			TaxAmount:        tax,
+		})
+		result.TotalTax = result.TotalTax.Add(tax)
+	}
+
+	// Thứ tự ổn định cho response / hoá đơn
+	sort.Slice(result.Lines, func(i, j int) bool {
+		if !result.Lines[i].Rate.Equal(result.Lines[j].Rate) {
+			return result.Lines[i].Rate.GreaterThan(result.Lines[j].Rate)
+		}
+		return result.Lines[i].Name < result.Lines[j].Name
+	})
+	return result
+}
+
+// matchRule rule đang hoạt động cụ thể nhất cho (tỉnh, danh mục), nil = không chịu thuế
+func matchRule(rules []TaxRule, province string, categoryID *uuid.UUID) *TaxRule {
+	var best *TaxRule
+	bestScore := -1
+	for i := range rules {
+		if !rules[i].IsActive {
+			continue
+		}
+		if score := rules[i].specificity(province, categoryID); score > bestScore {
+			best, bestScore = &rules[i], score
+		}
+	}
+	return best
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/tax/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	// CRUD admin
+	Create(ctx context.Context, req model.CreateTaxRuleRequest) (*model.TaxRule, error)
+	Update(ctx context.Context, id uuid.UUID, req model.UpdateTaxRuleRequest) (*model.TaxRule, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]model.TaxRule, error)
+
+	// ListActive rule đang hoạt động (đầu vào của model.Calculate)
+	ListActive(ctx context.Context) ([]model.TaxRule, error)
+}
+
+type postgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) Repository {
+	return &postgresRepository{pool: pool}
+}
+
+const taxRuleColumns = `id, name, province, category_id, rate, price_includes_tax, is_active, created_at, updated_at`
+
+func scanTaxRule(row pgx.Row) (*model.TaxRule, error) {
+	var r model.TaxRule
+	err := row.Scan(
+		&r.ID,
+		&r.Name,
+		&r.Province,
+		&r.CategoryID,
+		&r.Rate,
+		&r.PriceIncludesTax,
+		&r.IsActive,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// mapTaxRuleWriteError unique → ErrTaxRuleExists, FK category → ErrInvalidTaxRule
+func mapTaxRuleWriteError(err error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // idx_tax_rules_scope
+			return model.ErrTaxRuleExists
+		case "23503": // category_id không tồn tại
+			return fmt.Errorf("%w: category not found", model.ErrInvalidTaxRule)
+		}
+	}
+	return fmt.Errorf("failed to %s tax rule: %w", action, err)
+}
+
+func (r *postgresRepository) Create(ctx context.Context, req model.CreateTaxRuleRequest) (*model.TaxRule, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	includesTax := true
+	if req.PriceIncludesTax != nil {
+		includesTax = *req.PriceIncludesTax
+	}
+
+	query := `
+		INSERT INTO tax_rules (name, province, category_id, rate, price_includes_tax)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + taxRuleColumns
+
+	rule, err := scanTaxRule(r.pool.QueryRow(ctx, query,
+		req.Name,
+		req.Province,
+		req.CategoryID,
+		req.Rate,
+		includesTax,
+	))
+	if err != nil {
+		return nil, mapTaxRuleWriteError(err, "create")
+	}
+	return rule, nil
+}
+
+func (r *postgresRepository) Update(ctx context.Context, id uuid.UUID, req model.UpdateTaxRuleRequest) (*model.TaxRule, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE tax_rules
+		SET name = COALESCE($2, name),
+		    rate = COALESCE($3, rate),
+		    price_includes_tax = COALESCE($4, price_includes_tax),
+		    is_active = COALESCE($5, is_active)
+		WHERE id = $1
+		RETURNING ` + taxRuleColumns
+
+	rule, err := scanTaxRule(r.pool.QueryRow(ctx, query, id, req.Name, req.Rate, req.PriceIncludesTax, req.IsActive))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrTaxRuleNotFound
+		}
+		return nil, mapTaxRuleWriteError(err, "update")
+	}
+	return rule, nil
+}
+
+func (r *postgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM tax_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tax rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrTaxRuleNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) List(ctx context.Context) ([]model.TaxRule, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + taxRuleColumns + ` FROM tax_rules
+		ORDER BY is_active DESC, province NULLS FIRST, category_id NULLS FIRST, created_at`
+	return r.queryTaxRules(ctx, query)
+}
+
+func (r *postgresRepository) ListActive(ctx context.Context) ([]model.TaxRule, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + taxRuleColumns + ` FROM tax_rules WHERE is_active ORDER BY created_at`
+	return r.queryTaxRules(ctx, query)
+}
+
+func (r *postgresRepository) queryTaxRules(ctx context.Context, query string, args ...interface{}) ([]model.TaxRule, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tax rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]model.TaxRule, 0)
+	for rows.Next() {
+		rule, err := scanTaxRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tax rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
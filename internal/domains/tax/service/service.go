@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/tax/model"
+	"bookstore-backend/internal/domains/tax/repository"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	// CRUD admin
+	CreateRule(ctx context.Context, req model.CreateTaxRuleRequest) (*model.TaxRule, error)
+	UpdateRule(ctx context.Context, id uuid.UUID, req model.UpdateTaxRuleRequest) (*model.TaxRule, error)
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+	ListRules(ctx context.Context) ([]model.TaxRule, error)
+
+	// Calculate tính thuế cho giỏ / đơn (dùng chung bởi cart Checkout và order CalculateOrderAmounts)
+	Calculate(ctx context.Context, req model.CalculateRequest) (*model.Result, error)
+}
+
+// Rule thuế gần như không đổi, mỗi checkout / quote đều cần → cache trong process
+const activeRulesCacheTTL = 5 * time.Minute
+
+type service struct {
+	repo repository.Repository
+
+	mu       sync.RWMutex
+	active   []model.TaxRule
+	activeAt time.Time
+}
+
+func NewService(repo repository.Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) CreateRule(ctx context.Context, req model.CreateTaxRuleRequest) (*model.TaxRule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	rule, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return rule, nil
+}
+
+func (s *service) UpdateRule(ctx context.Context, id uuid.UUID, req model.UpdateTaxRuleRequest) (*model.TaxRule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	rule, err := s.repo.Update(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return rule, nil
+}
+
+func (s *service) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *service) ListRules(ctx context.Context) ([]model.TaxRule, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) Calculate(ctx context.Context, req model.CalculateRequest) (*model.Result, error) {
+	rules, err := s.activeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.Calculate(rules, req), nil
+}
+
+// activeRules rule đang hoạt động, reload khi hết TTL
+// Slice trả về là snapshot read-only, caller không được sửa
+func (s *service) activeRules(ctx context.Context) ([]model.TaxRule, error) {
+	s.mu.RLock()
+	if s.active != nil && time.Since(s.activeAt) < activeRulesCacheTTL {
+		rules := s.active
+		s.mu.RUnlock()
+		return rules, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != nil && time.Since(s.activeAt) < activeRulesCacheTTL {
+		return s.active, nil
+	}
+
+	rules, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.active = rules
+	s.activeAt = time.Now()
+	return rules, nil
+}
+
+// invalidate buộc lần tính thuế tiếp theo reload rule từ DB (gọi sau mutation của admin)
+func (s *service) invalidate() {
+	s.mu.Lock()
+	s.active = nil
+	s.mu.Unlock()
+}
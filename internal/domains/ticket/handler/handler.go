@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/ticket/model"
+	"bookstore-backend/internal/domains/ticket/service"
+)
+
+// =====================================================
+// TICKET HANDLER
+// =====================================================
+
+type TicketHandler struct {
+	ticketService service.ServiceInterface
+}
+
+func NewTicketHandler(ticketService service.ServiceInterface) *TicketHandler {
+	return &TicketHandler{
+		ticketService: ticketService,
+	}
+}
+
+// =====================================================
+// USER ENDPOINTS
+// =====================================================
+
+// CreateTicket opens issue ticket for own order
+// POST /api/v1/tickets
+func (h *TicketHandler) CreateTicket(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.CreateTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.CreateTicket(c.Request.Context(), userID, req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// ListMyTickets lists tickets of current user
+// GET /api/v1/tickets
+func (h *TicketHandler) ListMyTickets(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return
+	}
+
+	var req model.ListTicketsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.ListMyTickets(c.Request.Context(), userID, req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetMyTicket gets ticket detail with messages
+// GET /api/v1/tickets/:id
+func (h *TicketHandler) GetMyTicket(c *gin.Context) {
+	userID, ticketID, ok := parseUserTicketParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.ticketService.GetMyTicket(c.Request.Context(), userID, ticketID)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AddCustomerMessage customer replies on ticket
+// POST /api/v1/tickets/:id/messages
+func (h *TicketHandler) AddCustomerMessage(c *gin.Context) {
+	userID, ticketID, ok := parseUserTicketParams(c)
+	if !ok {
+		return
+	}
+
+	var req model.AddMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.AddCustomerMessage(c.Request.Context(), userID, ticketID, req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// =====================================================
+// ADMIN ENDPOINTS
+// =====================================================
+
+// AdminListTickets lists tickets (filter status/category/priority/assigned_to/overdue)
+// GET /api/v1/admin/tickets
+func (h *TicketHandler) AdminListTickets(c *gin.Context) {
+	var req model.ListTicketsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.AdminListTickets(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// GetSLADashboard gets ticket SLA metrics
+// GET /api/v1/admin/tickets/dashboard?days=30
+func (h *TicketHandler) GetSLADashboard(c *gin.Context) {
+	var req model.DashboardRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.GetSLADashboard(c.Request.Context(), req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AdminGetTicket gets ticket detail
+// GET /api/v1/admin/tickets/:id
+func (h *TicketHandler) AdminGetTicket(c *gin.Context) {
+	ticketID, ok := parseTicketID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.ticketService.AdminGetTicket(c.Request.Context(), ticketID)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AssignTicket assigns ticket to staff (optional priority change)
+// PATCH /api/v1/admin/tickets/:id/assign
+func (h *TicketHandler) AssignTicket(c *gin.Context) {
+	ticketID, ok := parseTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req model.AssignTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.AssignTicket(c.Request.Context(), ticketID, req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// UpdateStatus changes ticket status
+// PATCH /api/v1/admin/tickets/:id/status
+func (h *TicketHandler) UpdateStatus(c *gin.Context) {
+	staffID, ticketID, ok := parseUserTicketParams(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.UpdateStatus(c.Request.Context(), staffID, ticketID, req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusOK, response)
+}
+
+// AddStaffMessage staff replies on ticket
+// POST /api/v1/admin/tickets/:id/messages
+func (h *TicketHandler) AddStaffMessage(c *gin.Context) {
+	staffID, ticketID, ok := parseUserTicketParams(c)
+	if !ok {
+		return
+	}
+
+	var req model.AddMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.AddStaffMessage(c.Request.Context(), staffID, ticketID, req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// CreateCompensation creates refund request / replacement order for ticket
+// POST /api/v1/admin/tickets/:id/compensations
+func (h *TicketHandler) CreateCompensation(c *gin.Context) {
+	staffID, ticketID, ok := parseUserTicketParams(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateCompensationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	response, err := h.ticketService.CreateCompensation(c.Request.Context(), staffID, ticketID, req)
+	if err != nil {
+		statusCode, errCode := mapTicketError(err)
+		respondError(c, statusCode, errCode, err.Error())
+		return
+	}
+
+	respondSuccess(c, http.StatusCreated, response)
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// getUserID extracts user ID from JWT claims
+func getUserID(c *gin.Context) (uuid.UUID, error) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+
+	switch v := value.(type) {
+	case uuid.UUID:
+		return v, nil
+	case string:
+		return uuid.Parse(v)
+	default:
+		return uuid.Nil, model.ErrInvalidRequest
+	}
+}
+
+func parseTicketID(c *gin.Context) (uuid.UUID, bool) {
+	ticketID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "INVALID_ID", "Invalid ticket ID")
+		return uuid.Nil, false
+	}
+	return ticketID, true
+}
+
+func parseUserTicketParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "AUTH_ERROR", "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	ticketID, ok := parseTicketID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, ticketID, true
+}
+
+// respondSuccess sends success response
+func respondSuccess(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// respondError sends error response
+func respondError(c *gin.Context, statusCode int, code, message string) {
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// mapTicketError maps ticket error to HTTP status code
+func mapTicketError(err error) (int, string) {
+	var tErr *model.TicketError
+	if errors.As(err, &tErr) {
+		switch tErr.Code {
+		case model.ErrCodeTicketNotFound, model.ErrCodeOrderNotFound:
+			return http.StatusNotFound, tErr.Code
+		case model.ErrCodeTicketExists, model.ErrCodeInvalidTransition, model.ErrCodeTicketClosed:
+			return http.StatusConflict, tErr.Code
+		case model.ErrCodeOrderNotEligible, model.ErrCodeCompensationFailed:
+			return http.StatusUnprocessableEntity, tErr.Code
+		case model.ErrCodeInvalidRequest, model.ErrCodeInvalidAssignee:
+			return http.StatusBadRequest, tErr.Code
+		default:
+			return http.StatusInternalServerError, "INTERNAL_ERROR"
+		}
+	}
+
+	return http.StatusInternalServerError, "INTERNAL_ERROR"
+}
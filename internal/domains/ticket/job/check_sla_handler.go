@@ -0,0 +1,36 @@
+package job
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/ticket/service"
+	"bookstore-backend/pkg/logger"
+)
+
+// CheckSLAHandler đánh dấu sla_breached_at cho ticket vừa trễ hạn phản hồi / xử lý.
+// Dashboard + queue CSKH tính overdue realtime, job chỉ ghi lại mốc trễ lần đầu
+// để báo cáo không phụ thuộc trạng thái hiện tại của ticket.
+type CheckSLAHandler struct {
+	service service.ServiceInterface
+}
+
+func NewCheckSLAHandler(service service.ServiceInterface) *CheckSLAHandler {
+	return &CheckSLAHandler{service: service}
+}
+
+func (h *CheckSLAHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	breached, err := h.service.CheckSLA(ctx)
+	if err != nil {
+		logger.Error("Failed to check ticket SLA", err)
+		return err
+	}
+
+	if breached > 0 {
+		logger.Info("Tickets breached SLA", map[string]interface{}{
+			"count": breached,
+		})
+	}
+	return nil
+}
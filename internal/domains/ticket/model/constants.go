@@ -0,0 +1,147 @@
+package model
+
+import "time"
+
+// Ticket categories
+const (
+	CategoryMissingItem  = "missing_item"  // thiếu sách trong kiện
+	CategoryDamagedItem  = "damaged_item"  // sách rách / ướt / móp
+	CategoryWrongItem    = "wrong_item"    // giao nhầm sách
+	CategoryLateDelivery = "late_delivery" // giao trễ
+	CategoryOther        = "other"
+)
+
+var ValidCategories = []string{
+	CategoryMissingItem,
+	CategoryDamagedItem,
+	CategoryWrongItem,
+	CategoryLateDelivery,
+	CategoryOther,
+}
+
+// Ticket statuses
+const (
+	StatusOpen            = "open"             // khách vừa gửi, chưa ai nhận
+	StatusInProgress      = "in_progress"      // CSKH đang xử lý
+	StatusWaitingCustomer = "waiting_customer" // chờ khách bổ sung (ảnh, thông tin)
+	StatusResolved        = "resolved"         // đã xử lý xong, khách còn mở lại được
+	StatusClosed          = "closed"           // đóng hẳn
+)
+
+var ValidStatuses = []string{
+	StatusOpen,
+	StatusInProgress,
+	StatusWaitingCustomer,
+	StatusResolved,
+	StatusClosed,
+}
+
+// allowedTransitions - luồng trạng thái admin được chuyển
+var allowedTransitions = map[string][]string{
+	StatusOpen:            {StatusInProgress, StatusWaitingCustomer, StatusResolved, StatusClosed},
+	StatusInProgress:      {StatusWaitingCustomer, StatusResolved, StatusClosed},
+	StatusWaitingCustomer: {StatusInProgress, StatusResolved, StatusClosed},
+	StatusResolved:        {StatusInProgress, StatusClosed},
+}
+
+// CanTransition checks admin status change
+func CanTransition(from, to string) bool {
+	for _, s := range allowedTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Ticket priorities
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var ValidPriorities = []string{
+	PriorityLow,
+	PriorityNormal,
+	PriorityHigh,
+	PriorityUrgent,
+}
+
+// SLATarget - hạn phản hồi đầu tiên + hạn xử lý xong, tính từ lúc khách tạo ticket
+type SLATarget struct {
+	FirstResponse time.Duration
+	Resolution    time.Duration
+}
+
+// SLATargets theo priority
+var SLATargets = map[string]SLATarget{
+	PriorityUrgent: {FirstResponse: 1 * time.Hour, Resolution: 8 * time.Hour},
+	PriorityHigh:   {FirstResponse: 4 * time.Hour, Resolution: 24 * time.Hour},
+	PriorityNormal: {FirstResponse: 8 * time.Hour, Resolution: 48 * time.Hour},
+	PriorityLow:    {FirstResponse: 24 * time.Hour, Resolution: 72 * time.Hour},
+}
+
+// DefaultPriority theo loại khiếu nại (thiếu / hỏng / nhầm sách ảnh hưởng trực tiếp tiền khách trả)
+func DefaultPriority(category string) string {
+	switch category {
+	case CategoryMissingItem, CategoryDamagedItem, CategoryWrongItem:
+		return PriorityHigh
+	default:
+		return PriorityNormal
+	}
+}
+
+// Compensation types
+const (
+	CompensationRefund = "refund" // hoàn tiền (một phần) qua refund request, admin duyệt như refund thường
+	CompensationResend = "resend" // gửi bù sách bằng đơn 0đ
+)
+
+// Sender types (ticket_messages)
+const (
+	SenderCustomer = "customer"
+	SenderStaff    = "staff"
+)
+
+// Limits
+const (
+	MaxSubjectLength     = 200
+	MaxMessageLength     = 4000
+	MaxPhotoURLs         = 10
+	DueSoonWindow        = time.Hour // dashboard: sắp trễ SLA
+	DashboardDefaultDays = 30
+	DashboardMaxDays     = 180
+)
+
+// Pagination defaults
+const (
+	DefaultPage  = 1
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// IsValidCategory checks ticket category
+func IsValidCategory(category string) bool {
+	return contains(ValidCategories, category)
+}
+
+// IsValidStatus checks ticket status
+func IsValidStatus(status string) bool {
+	return contains(ValidStatuses, status)
+}
+
+// IsValidPriority checks ticket priority
+func IsValidPriority(priority string) bool {
+	return contains(ValidPriorities, priority)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// USER REQUEST DTOs
+// =====================================================
+
+// CreateTicketRequest khách khiếu nại về 1 đơn (thiếu sách, sách hỏng, ...)
+type CreateTicketRequest struct {
+	OrderID     uuid.UUID `json:"order_id" binding:"required"`
+	Category    string    `json:"category" binding:"required"`
+	Subject     string    `json:"subject" binding:"required"`
+	Description string    `json:"description" binding:"required"`
+	PhotoURLs   []string  `json:"photo_urls,omitempty"`
+}
+
+func (r *CreateTicketRequest) Validate() error {
+	r.Subject = strings.TrimSpace(r.Subject)
+	r.Description = strings.TrimSpace(r.Description)
+
+	if !IsValidCategory(r.Category) {
+		return NewInvalidRequestError(fmt.Sprintf("category must be one of %v", ValidCategories))
+	}
+	if r.Subject == "" || utf8.RuneCountInString(r.Subject) > MaxSubjectLength {
+		return NewInvalidRequestError(fmt.Sprintf("subject must be between 1 and %d characters", MaxSubjectLength))
+	}
+	if r.Description == "" || utf8.RuneCountInString(r.Description) > MaxMessageLength {
+		return NewInvalidRequestError(fmt.Sprintf("description must be between 1 and %d characters", MaxMessageLength))
+	}
+	if len(r.PhotoURLs) > MaxPhotoURLs {
+		return NewInvalidRequestError(fmt.Sprintf("at most %d photos are allowed", MaxPhotoURLs))
+	}
+	for _, u := range r.PhotoURLs {
+		if !strings.HasPrefix(u, "https://") {
+			return NewInvalidRequestError("photo_urls must be https URLs")
+		}
+	}
+	if r.PhotoURLs == nil {
+		r.PhotoURLs = []string{}
+	}
+	return nil
+}
+
+// AddMessageRequest khách / CSKH trả lời trong ticket
+type AddMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (r *AddMessageRequest) Validate() error {
+	r.Body = strings.TrimSpace(r.Body)
+	if r.Body == "" || utf8.RuneCountInString(r.Body) > MaxMessageLength {
+		return NewInvalidRequestError(fmt.Sprintf("body must be between 1 and %d characters", MaxMessageLength))
+	}
+	return nil
+}
+
+// ListTicketsRequest pagination + filter
+// Admin lọc thêm theo priority / assignee / overdue
+type ListTicketsRequest struct {
+	Status     *string `form:"status"`
+	Category   *string `form:"category"`
+	Priority   *string `form:"priority"`
+	AssignedTo *string `form:"assigned_to"` // uuid hoặc "none" (chưa giao)
+	Overdue    bool    `form:"overdue"`     // quá hạn SLA (phản hồi hoặc xử lý)
+	Page       int     `form:"page"`
+	Limit      int     `form:"limit"`
+}
+
+func (r *ListTicketsRequest) Validate() error {
+	if r.Status != nil && !IsValidStatus(*r.Status) {
+		return NewInvalidRequestError("invalid status filter")
+	}
+	if r.Category != nil && !IsValidCategory(*r.Category) {
+		return NewInvalidRequestError("invalid category filter")
+	}
+	if r.Priority != nil && !IsValidPriority(*r.Priority) {
+		return NewInvalidRequestError("invalid priority filter")
+	}
+	if r.AssignedTo != nil && *r.AssignedTo != "none" {
+		if _, err := uuid.Parse(*r.AssignedTo); err != nil {
+			return NewInvalidRequestError("assigned_to must be a user ID or 'none'")
+		}
+	}
+	r.Page, r.Limit = normalizePagination(r.Page, r.Limit)
+	return nil
+}
+
+// =====================================================
+// ADMIN REQUEST DTOs
+// =====================================================
+
+// AssignTicketRequest giao ticket cho nhân viên (nil = bỏ giao), đổi priority tuỳ chọn
+type AssignTicketRequest struct {
+	AssigneeID *uuid.UUID `json:"assignee_id"`
+	Priority   *string    `json:"priority,omitempty"`
+}
+
+func (r *AssignTicketRequest) Validate() error {
+	if r.Priority != nil && !IsValidPriority(*r.Priority) {
+		return NewInvalidRequestError(fmt.Sprintf("priority must be one of %v", ValidPriorities))
+	}
+	return nil
+}
+
+// UpdateStatusRequest admin chuyển trạng thái
+type UpdateStatusRequest struct {
+	Status string  `json:"status" binding:"required"`
+	Note   *string `json:"note,omitempty"` // gửi kèm như tin nhắn cho khách
+}
+
+func (r *UpdateStatusRequest) Validate() error {
+	if !IsValidStatus(r.Status) {
+		return NewInvalidRequestError(fmt.Sprintf("status must be one of %v", ValidStatuses))
+	}
+	if r.Note != nil {
+		note := strings.TrimSpace(*r.Note)
+		if note == "" {
+			r.Note = nil
+		} else if utf8.RuneCountInString(note) > MaxMessageLength {
+			return NewInvalidRequestError(fmt.Sprintf("note must be at most %d characters", MaxMessageLength))
+		} else {
+			r.Note = &note
+		}
+	}
+	return nil
+}
+
+// CreateCompensationRequest bồi thường cho ticket
+// refund: Amount bắt buộc (hoàn một phần / toàn bộ tiền đã thanh toán online)
+// resend: Items bắt buộc (sách trong đơn gốc, không vượt số lượng đã mua)
+type CreateCompensationRequest struct {
+	Type   string                    `json:"type" binding:"required"`
+	Amount *decimal.Decimal          `json:"amount,omitempty"`
+	Items  []CompensationItemRequest `json:"items,omitempty"`
+	Note   *string                   `json:"note,omitempty"`
+}
+
+// CompensationItemRequest sách gửi bù
+type CompensationItemRequest struct {
+	BookID   uuid.UUID `json:"book_id" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required"`
+}
+
+func (r *CreateCompensationRequest) Validate() error {
+	if r.Note != nil && utf8.RuneCountInString(*r.Note) > MaxMessageLength {
+		return NewInvalidRequestError(fmt.Sprintf("note must be at most %d characters", MaxMessageLength))
+	}
+
+	switch r.Type {
+	case CompensationRefund:
+		if r.Amount == nil || !r.Amount.IsPositive() {
+			return NewInvalidRequestError("amount must be positive for refund")
+		}
+		if len(r.Items) > 0 {
+			return NewInvalidRequestError("items are not allowed for refund")
+		}
+	case CompensationResend:
+		if len(r.Items) == 0 {
+			return NewInvalidRequestError("items are required for resend")
+		}
+		if r.Amount != nil {
+			return NewInvalidRequestError("amount is not allowed for resend")
+		}
+		for _, item := range r.Items {
+			if item.Quantity < 1 {
+				return NewInvalidRequestError("quantity must be at least 1")
+			}
+		}
+	default:
+		return NewInvalidRequestError(fmt.Sprintf("type must be %s or %s", CompensationRefund, CompensationResend))
+	}
+	return nil
+}
+
+// DashboardRequest khoảng thời gian tính số liệu SLA (mặc định 30 ngày)
+type DashboardRequest struct {
+	Days int `form:"days"`
+}
+
+func (r *DashboardRequest) Validate() error {
+	if r.Days == 0 {
+		r.Days = DashboardDefaultDays
+	}
+	if r.Days < 1 || r.Days > DashboardMaxDays {
+		return NewInvalidRequestError(fmt.Sprintf("days must be between 1 and %d", DashboardMaxDays))
+	}
+	return nil
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// TicketResponse ticket + trao đổi + bồi thường
+type TicketResponse struct {
+	ID                   uuid.UUID              `json:"id"`
+	TicketNumber         string                 `json:"ticket_number"`
+	OrderID              uuid.UUID              `json:"order_id"`
+	OrderNumber          string                 `json:"order_number"`
+	UserID               uuid.UUID              `json:"user_id"`
+	Category             string                 `json:"category"`
+	Priority             string                 `json:"priority"`
+	Status               string                 `json:"status"`
+	Subject              string                 `json:"subject"`
+	Description          string                 `json:"description"`
+	PhotoURLs            []string               `json:"photo_urls"`
+	AssignedTo           *uuid.UUID             `json:"assigned_to,omitempty"`
+	AssignedAt           *time.Time             `json:"assigned_at,omitempty"`
+	FirstResponseDueAt   time.Time              `json:"first_response_due_at"`
+	ResolutionDueAt      time.Time              `json:"resolution_due_at"`
+	FirstRespondedAt     *time.Time             `json:"first_responded_at,omitempty"`
+	ResolvedAt           *time.Time             `json:"resolved_at,omitempty"`
+	ClosedAt             *time.Time             `json:"closed_at,omitempty"`
+	FirstResponseOverdue bool                   `json:"first_response_overdue"`
+	ResolutionOverdue    bool                   `json:"resolution_overdue"`
+	Messages             []MessageResponse      `json:"messages,omitempty"`
+	Compensations        []CompensationResponse `json:"compensations,omitempty"`
+	CreatedAt            time.Time              `json:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at"`
+}
+
+// MessageResponse 1 tin nhắn trong ticket
+type MessageResponse struct {
+	ID         uuid.UUID `json:"id"`
+	SenderType string    `json:"sender_type"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CompensationResponse bồi thường đã tạo
+type CompensationResponse struct {
+	ID                 uuid.UUID        `json:"id"`
+	Type               string           `json:"type"`
+	Amount             *decimal.Decimal `json:"amount,omitempty"`
+	RefundRequestID    *uuid.UUID       `json:"refund_request_id,omitempty"`
+	ReplacementOrderID *uuid.UUID       `json:"replacement_order_id,omitempty"`
+	Note               *string          `json:"note,omitempty"`
+	CreatedBy          uuid.UUID        `json:"created_by"`
+	CreatedAt          time.Time        `json:"created_at"`
+}
+
+// ListTicketsResponse paginated tickets (không kèm messages)
+type ListTicketsResponse struct {
+	Tickets    []TicketResponse `json:"tickets"`
+	Pagination PaginationMeta   `json:"pagination"`
+}
+
+// SLADashboard - số liệu SLA cho dashboard vận hành
+type SLADashboard struct {
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Ticket đang mở (chưa resolved / closed)
+	OpenByStatus         map[string]int `json:"open_by_status"`
+	OpenByPriority       map[string]int `json:"open_by_priority"`
+	OpenByCategory       map[string]int `json:"open_by_category"`
+	Unassigned           int            `json:"unassigned"`
+	FirstResponseOverdue int            `json:"first_response_overdue"`
+	ResolutionOverdue    int            `json:"resolution_overdue"`
+	FirstResponseDueSoon int            `json:"first_response_due_soon"` // trong DueSoonWindow tới
+	ResolutionDueSoon    int            `json:"resolution_due_soon"`
+
+	// Ticket tạo trong [From, GeneratedAt)
+	From                    time.Time       `json:"from"`
+	Created                 int             `json:"created"`
+	Resolved                int             `json:"resolved"`
+	AvgFirstResponseMinutes *float64        `json:"avg_first_response_minutes,omitempty"`
+	AvgResolutionHours      *float64        `json:"avg_resolution_hours,omitempty"`
+	FirstResponseMetPercent *float64        `json:"first_response_met_percent,omitempty"`
+	ResolutionMetPercent    *float64        `json:"resolution_met_percent,omitempty"`
+	RefundCount             int             `json:"refund_count"`
+	RefundAmount            decimal.Decimal `json:"refund_amount"`
+	ResendCount             int             `json:"resend_count"`
+}
+
+// PaginationMeta pagination metadata
+type PaginationMeta struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
+
+// NewPaginationMeta builds pagination metadata
+func NewPaginationMeta(page, limit, total int) PaginationMeta {
+	totalPages := (total + limit - 1) / limit
+	return PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
+func normalizePagination(page, limit int) (int, int) {
+	if page < 1 {
+		page = DefaultPage
+	}
+	if limit < 1 || limit > MaxLimit {
+		limit = DefaultLimit
+	}
+	return page, limit
+}
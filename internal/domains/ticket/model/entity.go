@@ -0,0 +1,100 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Ticket - khiếu nại gắn với 1 đơn hàng (order_tickets)
+type Ticket struct {
+	ID           uuid.UUID
+	TicketNumber string
+	OrderID      uuid.UUID
+	OrderNumber  string // join orders, chỉ đọc
+	UserID       uuid.UUID
+	Category     string
+	Priority     string
+	Status       string
+	Subject      string
+	Description  string
+	PhotoURLs    []string
+	AssignedTo   *uuid.UUID
+	AssignedAt   *time.Time
+
+	// SLA: hạn tính lúc tạo theo priority (đổi priority → tính lại từ created_at)
+	FirstResponseDueAt time.Time
+	ResolutionDueAt    time.Time
+	FirstRespondedAt   *time.Time
+	ResolvedAt         *time.Time
+	ClosedAt           *time.Time
+	SLABreachedAt      *time.Time // job check SLA đánh dấu lần đầu trễ hạn
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Messages      []TicketMessage
+	Compensations []Compensation
+}
+
+// IsOpen ticket còn đang xử lý (tính SLA)
+func (t *Ticket) IsOpen() bool {
+	return t.Status != StatusResolved && t.Status != StatusClosed
+}
+
+// ApplySLA tính hạn SLA theo priority, mốc là lúc tạo ticket
+func (t *Ticket) ApplySLA() {
+	target, ok := SLATargets[t.Priority]
+	if !ok {
+		target = SLATargets[PriorityNormal]
+	}
+	t.FirstResponseDueAt = t.CreatedAt.Add(target.FirstResponse)
+	t.ResolutionDueAt = t.CreatedAt.Add(target.Resolution)
+}
+
+// FirstResponseOverdue chưa phản hồi khách mà đã quá hạn
+func (t *Ticket) FirstResponseOverdue(now time.Time) bool {
+	return t.FirstRespondedAt == nil && t.IsOpen() && now.After(t.FirstResponseDueAt)
+}
+
+// ResolutionOverdue chưa xử lý xong mà đã quá hạn
+func (t *Ticket) ResolutionOverdue(now time.Time) bool {
+	return t.IsOpen() && now.After(t.ResolutionDueAt)
+}
+
+// TicketMessage - trao đổi giữa khách và CSKH (ticket_messages)
+type TicketMessage struct {
+	ID         uuid.UUID
+	TicketID   uuid.UUID
+	SenderID   uuid.UUID
+	SenderType string
+	Body       string
+	CreatedAt  time.Time
+}
+
+// Compensation - bồi thường đã thực hiện cho ticket (ticket_compensations)
+type Compensation struct {
+	ID                 uuid.UUID
+	TicketID           uuid.UUID
+	Type               string
+	Amount             *decimal.Decimal // refund
+	RefundRequestID    *uuid.UUID       // refund
+	ReplacementOrderID *uuid.UUID       // resend
+	Note               *string
+	CreatedBy          uuid.UUID
+	CreatedAt          time.Time
+}
+
+// OrderInfo - đơn hàng khách khiếu nại (kiểm tra sở hữu + trạng thái)
+type OrderInfo struct {
+	ID          uuid.UUID
+	OrderNumber string
+	UserID      uuid.UUID
+	Status      string
+}
+
+// CanOpenTicket đơn đã được xác nhận, chưa huỷ
+func (o *OrderInfo) CanOpenTicket() bool {
+	return o.Status != "pending" && o.Status != "cancelled"
+}
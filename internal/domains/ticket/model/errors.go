@@ -0,0 +1,123 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodeTicketNotFound     = "TKT001"
+	ErrCodeInvalidRequest     = "TKT002"
+	ErrCodeOrderNotFound      = "TKT003"
+	ErrCodeOrderNotEligible   = "TKT004"
+	ErrCodeTicketExists       = "TKT005"
+	ErrCodeInvalidTransition  = "TKT006"
+	ErrCodeTicketClosed       = "TKT007"
+	ErrCodeInvalidAssignee    = "TKT008"
+	ErrCodeCompensationFailed = "TKT009"
+)
+
+// Errors
+var (
+	ErrTicketNotFound     = errors.New("ticket not found")
+	ErrInvalidRequest     = errors.New("invalid ticket request")
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrOrderNotEligible   = errors.New("order not eligible for ticket")
+	ErrTicketExists       = errors.New("open ticket already exists")
+	ErrInvalidTransition  = errors.New("invalid ticket status transition")
+	ErrTicketClosed       = errors.New("ticket closed")
+	ErrInvalidAssignee    = errors.New("invalid assignee")
+	ErrCompensationFailed = errors.New("compensation failed")
+)
+
+// TicketError custom error type
+type TicketError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *TicketError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *TicketError) Unwrap() error {
+	return e.Err
+}
+
+// Error constructors
+func NewTicketNotFoundError() *TicketError {
+	return &TicketError{
+		Code:    ErrCodeTicketNotFound,
+		Message: "Ticket not found",
+		Err:     ErrTicketNotFound,
+	}
+}
+
+func NewInvalidRequestError(message string) *TicketError {
+	return &TicketError{
+		Code:    ErrCodeInvalidRequest,
+		Message: message,
+		Err:     ErrInvalidRequest,
+	}
+}
+
+func NewOrderNotFoundError() *TicketError {
+	return &TicketError{
+		Code:    ErrCodeOrderNotFound,
+		Message: "Order not found",
+		Err:     ErrOrderNotFound,
+	}
+}
+
+func NewOrderNotEligibleError(status string) *TicketError {
+	return &TicketError{
+		Code:    ErrCodeOrderNotEligible,
+		Message: fmt.Sprintf("Cannot open a ticket for an order in status %s", status),
+		Err:     ErrOrderNotEligible,
+	}
+}
+
+func NewTicketExistsError(category string) *TicketError {
+	return &TicketError{
+		Code:    ErrCodeTicketExists,
+		Message: fmt.Sprintf("An open %s ticket already exists for this order", category),
+		Err:     ErrTicketExists,
+	}
+}
+
+func NewInvalidTransitionError(from, to string) *TicketError {
+	return &TicketError{
+		Code:    ErrCodeInvalidTransition,
+		Message: fmt.Sprintf("Cannot change ticket status from %s to %s", from, to),
+		Err:     ErrInvalidTransition,
+	}
+}
+
+func NewTicketClosedError() *TicketError {
+	return &TicketError{
+		Code:    ErrCodeTicketClosed,
+		Message: "Ticket is closed",
+		Err:     ErrTicketClosed,
+	}
+}
+
+func NewInvalidAssigneeError() *TicketError {
+	return &TicketError{
+		Code:    ErrCodeInvalidAssignee,
+		Message: "Assignee must be an active admin or customer service staff",
+		Err:     ErrInvalidAssignee,
+	}
+}
+
+func NewCompensationFailedError(reason string) *TicketError {
+	return &TicketError{
+		Code:    ErrCodeCompensationFailed,
+		Message: fmt.Sprintf("Compensation could not be created: %s", reason),
+		Err:     ErrCompensationFailed,
+	}
+}
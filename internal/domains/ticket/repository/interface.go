@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/ticket/model"
+)
+
+// =====================================================
+// TICKET REPOSITORY INTERFACE
+// =====================================================
+
+type TicketRepository interface {
+	// ========================================
+	// TICKETS
+	// ========================================
+
+	// CreateTicket creates ticket with the customer's description as first message
+	CreateTicket(ctx context.Context, ticket *model.Ticket) error
+
+	// GetTicketByID gets ticket with messages + compensations
+	GetTicketByID(ctx context.Context, id uuid.UUID) (*model.Ticket, error)
+
+	// ListTickets lists tickets with filters (user_id, status, category, priority, assigned_to, unassigned, overdue), without messages
+	ListTickets(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*model.Ticket, int, error)
+
+	// UpdateStatus guarded transition, set resolved_at / closed_at theo status mới
+	UpdateStatus(ctx context.Context, id uuid.UUID, from, to string) error
+
+	// Assign sets assignee + priority (priority đổi → SLA due tính lại)
+	Assign(ctx context.Context, ticket *model.Ticket) error
+
+	// ========================================
+	// MESSAGES + COMPENSATIONS
+	// ========================================
+
+	// AddMessage inserts message; staff reply đầu tiên set first_responded_at
+	AddMessage(ctx context.Context, msg *model.TicketMessage) error
+
+	// AddCompensation records compensation đã tạo (refund request / đơn gửi bù)
+	AddCompensation(ctx context.Context, comp *model.Compensation) error
+
+	// ========================================
+	// SLA
+	// ========================================
+
+	// MarkSLABreaches đánh dấu ticket mở vừa trễ SLA, trả về số ticket
+	MarkSLABreaches(ctx context.Context, now time.Time) (int, error)
+
+	// GetSLADashboard số liệu SLA (ticket đang mở + ticket tạo từ from)
+	GetSLADashboard(ctx context.Context, from, now time.Time) (*model.SLADashboard, error)
+
+	// ========================================
+	// LOOKUPS
+	// ========================================
+
+	// GetOrderInfo gets order owner + status
+	GetOrderInfo(ctx context.Context, orderID uuid.UUID) (*model.OrderInfo, error)
+
+	// IsActiveStaff admin / cskh đang hoạt động (nhận được ticket)
+	IsActiveStaff(ctx context.Context, userID uuid.UUID) (bool, error)
+}
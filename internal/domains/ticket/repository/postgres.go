@@ -0,0 +1,557 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/ticket/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresTicketRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTicketRepository(pool *pgxpool.Pool) TicketRepository {
+	return &postgresTicketRepository{pool: pool}
+}
+
+const ticketColumns = `
+	t.id, t.ticket_number, t.order_id, o.order_number, t.user_id,
+	t.category, t.priority, t.status, t.subject, t.description, t.photo_urls,
+	t.assigned_to, t.assigned_at, t.first_response_due_at, t.resolution_due_at,
+	t.first_responded_at, t.resolved_at, t.closed_at, t.sla_breached_at,
+	t.created_at, t.updated_at`
+
+// Ticket chưa resolved / closed + trễ hạn phản hồi hoặc hạn xử lý tại $N
+const overdueCondition = `
+	t.status NOT IN ('resolved', 'closed')
+	AND ((t.first_responded_at IS NULL AND t.first_response_due_at < %[1]s) OR t.resolution_due_at < %[1]s)`
+
+func scanTicket(row pgx.Row, ticket *model.Ticket) error {
+	return row.Scan(
+		&ticket.ID,
+		&ticket.TicketNumber,
+		&ticket.OrderID,
+		&ticket.OrderNumber,
+		&ticket.UserID,
+		&ticket.Category,
+		&ticket.Priority,
+		&ticket.Status,
+		&ticket.Subject,
+		&ticket.Description,
+		&ticket.PhotoURLs,
+		&ticket.AssignedTo,
+		&ticket.AssignedAt,
+		&ticket.FirstResponseDueAt,
+		&ticket.ResolutionDueAt,
+		&ticket.FirstRespondedAt,
+		&ticket.ResolvedAt,
+		&ticket.ClosedAt,
+		&ticket.SLABreachedAt,
+		&ticket.CreatedAt,
+		&ticket.UpdatedAt,
+	)
+}
+
+// isOpenTicketConflict - vi phạm uq_order_tickets_open_per_category (đã có ticket mở cùng loại cho đơn)
+func isOpenTicketConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" &&
+		pgErr.ConstraintName == "uq_order_tickets_open_per_category"
+}
+
+// =====================================================
+// TICKETS
+// =====================================================
+
+func (r *postgresTicketRepository) CreateTicket(ctx context.Context, ticket *model.Ticket) error {
+	query := `
+		INSERT INTO order_tickets (
+			id, ticket_number, order_id, user_id, category, priority, status,
+			subject, description, photo_urls, first_response_due_at, resolution_due_at,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		ticket.ID,
+		ticket.TicketNumber,
+		ticket.OrderID,
+		ticket.UserID,
+		ticket.Category,
+		ticket.Priority,
+		ticket.Status,
+		ticket.Subject,
+		ticket.Description,
+		ticket.PhotoURLs,
+		ticket.FirstResponseDueAt,
+		ticket.ResolutionDueAt,
+		ticket.CreatedAt,
+	)
+	if err != nil {
+		if isOpenTicketConflict(err) {
+			return model.ErrTicketExists
+		}
+		return fmt.Errorf("failed to create ticket: %w", err)
+	}
+
+	ticket.UpdatedAt = ticket.CreatedAt
+	return nil
+}
+
+func (r *postgresTicketRepository) GetTicketByID(ctx context.Context, id uuid.UUID) (*model.Ticket, error) {
+	query := `SELECT ` + ticketColumns + `
+		FROM order_tickets t
+		JOIN orders o ON o.id = t.order_id
+		WHERE t.id = $1`
+
+	ticket := &model.Ticket{}
+	if err := scanTicket(r.pool.QueryRow(ctx, query, id), ticket); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrTicketNotFound
+		}
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	msgQuery := `
+		SELECT id, ticket_id, sender_id, sender_type, body, created_at
+		FROM ticket_messages
+		WHERE ticket_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := r.pool.Query(ctx, msgQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket messages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var msg model.TicketMessage
+		if err := rows.Scan(
+			&msg.ID,
+			&msg.TicketID,
+			&msg.SenderID,
+			&msg.SenderType,
+			&msg.Body,
+			&msg.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket message: %w", err)
+		}
+		ticket.Messages = append(ticket.Messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	compQuery := `
+		SELECT id, ticket_id, type, amount, refund_request_id, replacement_order_id,
+			note, created_by, created_at
+		FROM ticket_compensations
+		WHERE ticket_id = $1
+		ORDER BY created_at, id
+	`
+	compRows, err := r.pool.Query(ctx, compQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket compensations: %w", err)
+	}
+	defer compRows.Close()
+
+	for compRows.Next() {
+		var comp model.Compensation
+		if err := compRows.Scan(
+			&comp.ID,
+			&comp.TicketID,
+			&comp.Type,
+			&comp.Amount,
+			&comp.RefundRequestID,
+			&comp.ReplacementOrderID,
+			&comp.Note,
+			&comp.CreatedBy,
+			&comp.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket compensation: %w", err)
+		}
+		ticket.Compensations = append(ticket.Compensations, comp)
+	}
+
+	return ticket, compRows.Err()
+}
+
+func (r *postgresTicketRepository) ListTickets(
+	ctx context.Context,
+	filters map[string]interface{},
+	page, limit int,
+) ([]*model.Ticket, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if userID, ok := filters["user_id"].(uuid.UUID); ok {
+		where += fmt.Sprintf(" AND t.user_id = $%d", argCount)
+		args = append(args, userID)
+		argCount++
+	}
+
+	for _, col := range []string{"status", "category", "priority"} {
+		if v, ok := filters[col].(string); ok && v != "" {
+			where += fmt.Sprintf(" AND t.%s = $%d", col, argCount)
+			args = append(args, v)
+			argCount++
+		}
+	}
+
+	if assignee, ok := filters["assigned_to"].(uuid.UUID); ok {
+		where += fmt.Sprintf(" AND t.assigned_to = $%d", argCount)
+		args = append(args, assignee)
+		argCount++
+	}
+
+	if unassigned, ok := filters["unassigned"].(bool); ok && unassigned {
+		where += " AND t.assigned_to IS NULL"
+	}
+
+	if overdue, ok := filters["overdue"].(bool); ok && overdue {
+		where += " AND " + fmt.Sprintf(overdueCondition, "NOW()")
+	}
+
+	from := " FROM order_tickets t JOIN orders o ON o.id = t.order_id"
+
+	var total int
+	countQuery := "SELECT COUNT(*)" + from + where
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+
+	// Ticket sắp trễ hạn xử lý lên trước cho hàng đợi CSKH
+	query := `SELECT ` + ticketColumns + from + where +
+		" ORDER BY t.resolution_due_at ASC, t.created_at DESC" +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []*model.Ticket
+	for rows.Next() {
+		ticket := &model.Ticket{}
+		if err := scanTicket(rows, ticket); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, total, rows.Err()
+}
+
+func (r *postgresTicketRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from, to string) error {
+	// closed không qua resolved vẫn tính là xử lý xong (thống kê SLA)
+	query := `
+		UPDATE order_tickets
+		SET status = $3,
+			resolved_at = CASE
+				WHEN $3 IN ('resolved', 'closed') THEN COALESCE(resolved_at, NOW())
+				ELSE NULL
+			END,
+			closed_at = CASE WHEN $3 = 'closed' THEN NOW() ELSE NULL END
+		WHERE id = $1 AND status = $2
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, from, to)
+	if err != nil {
+		if isOpenTicketConflict(err) {
+			return model.ErrTicketExists
+		}
+		return fmt.Errorf("failed to update ticket status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrInvalidTransition
+	}
+
+	return nil
+}
+
+func (r *postgresTicketRepository) Assign(ctx context.Context, ticket *model.Ticket) error {
+	// Giao cho người mới → assigned_at = NOW(); ticket open được nhận → in_progress
+	query := `
+		UPDATE order_tickets
+		SET assigned_to = $2,
+			assigned_at = CASE
+				WHEN $2::uuid IS NULL THEN NULL
+				WHEN assigned_to IS DISTINCT FROM $2::uuid THEN NOW()
+				ELSE assigned_at
+			END,
+			status = CASE WHEN status = 'open' AND $2::uuid IS NOT NULL THEN 'in_progress' ELSE status END,
+			priority = $3,
+			first_response_due_at = $4,
+			resolution_due_at = $5
+		WHERE id = $1 AND status <> 'closed'
+		RETURNING assigned_at, status, updated_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		ticket.ID,
+		ticket.AssignedTo,
+		ticket.Priority,
+		ticket.FirstResponseDueAt,
+		ticket.ResolutionDueAt,
+	).Scan(&ticket.AssignedAt, &ticket.Status, &ticket.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.ErrTicketClosed
+		}
+		return fmt.Errorf("failed to assign ticket: %w", err)
+	}
+
+	return nil
+}
+
+// =====================================================
+// MESSAGES + COMPENSATIONS
+// =====================================================
+
+func (r *postgresTicketRepository) AddMessage(ctx context.Context, msg *model.TicketMessage) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	query := `
+		INSERT INTO ticket_messages (id, ticket_id, sender_id, sender_type, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	if err := tx.QueryRow(ctx, query,
+		msg.ID,
+		msg.TicketID,
+		msg.SenderID,
+		msg.SenderType,
+		msg.Body,
+	).Scan(&msg.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create ticket message: %w", err)
+	}
+
+	// Staff trả lời lần đầu → dừng đồng hồ SLA phản hồi.
+	// Khách trả lời khi đang chờ khách / đã resolved → mở lại cho CSKH xử lý.
+	ticketQuery := `
+		UPDATE order_tickets
+		SET status = CASE WHEN status IN ('waiting_customer', 'resolved') THEN 'in_progress' ELSE status END,
+			resolved_at = CASE WHEN status = 'resolved' THEN NULL ELSE resolved_at END
+		WHERE id = $1 AND status <> 'closed'
+	`
+	args := []interface{}{msg.TicketID}
+	if msg.SenderType == model.SenderStaff {
+		ticketQuery = `
+			UPDATE order_tickets
+			SET first_responded_at = COALESCE(first_responded_at, $2)
+			WHERE id = $1 AND status <> 'closed'
+		`
+		args = append(args, msg.CreatedAt)
+	}
+	result, err := tx.Exec(ctx, ticketQuery, args...)
+	if err != nil {
+		if isOpenTicketConflict(err) {
+			return model.ErrTicketExists
+		}
+		return fmt.Errorf("failed to update ticket: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrTicketClosed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit ticket message: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postgresTicketRepository) AddCompensation(ctx context.Context, comp *model.Compensation) error {
+	query := `
+		INSERT INTO ticket_compensations (
+			id, ticket_id, type, amount, refund_request_id, replacement_order_id, note, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+
+	if err := r.pool.QueryRow(ctx, query,
+		comp.ID,
+		comp.TicketID,
+		comp.Type,
+		comp.Amount,
+		comp.RefundRequestID,
+		comp.ReplacementOrderID,
+		comp.Note,
+		comp.CreatedBy,
+	).Scan(&comp.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create ticket compensation: %w", err)
+	}
+
+	return nil
+}
+
+// =====================================================
+// SLA
+// =====================================================
+
+func (r *postgresTicketRepository) MarkSLABreaches(ctx context.Context, now time.Time) (int, error) {
+	query := `
+		UPDATE order_tickets t
+		SET sla_breached_at = $1
+		WHERE t.sla_breached_at IS NULL AND ` + fmt.Sprintf(overdueCondition, "$1")
+
+	result, err := r.pool.Exec(ctx, query, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark SLA breaches: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *postgresTicketRepository) GetSLADashboard(ctx context.Context, from, now time.Time) (*model.SLADashboard, error) {
+	dashboard := &model.SLADashboard{
+		GeneratedAt:    now,
+		From:           from,
+		OpenByStatus:   map[string]int{},
+		OpenByPriority: map[string]int{},
+		OpenByCategory: map[string]int{},
+	}
+
+	// 1. Ticket đang mở
+	openQuery := `
+		SELECT status, priority, category, COUNT(*),
+			COUNT(*) FILTER (WHERE assigned_to IS NULL),
+			COUNT(*) FILTER (WHERE first_responded_at IS NULL AND first_response_due_at < $1),
+			COUNT(*) FILTER (WHERE resolution_due_at < $1),
+			COUNT(*) FILTER (WHERE first_responded_at IS NULL AND first_response_due_at >= $1 AND first_response_due_at < $2),
+			COUNT(*) FILTER (WHERE resolution_due_at >= $1 AND resolution_due_at < $2)
+		FROM order_tickets
+		WHERE status NOT IN ('resolved', 'closed')
+		GROUP BY status, priority, category
+	`
+	rows, err := r.pool.Query(ctx, openQuery, now, now.Add(model.DueSoonWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open ticket stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status, priority, category string
+		var count, unassigned, frOverdue, resOverdue, frDueSoon, resDueSoon int
+		if err := rows.Scan(&status, &priority, &category, &count,
+			&unassigned, &frOverdue, &resOverdue, &frDueSoon, &resDueSoon); err != nil {
+			return nil, fmt.Errorf("failed to scan open ticket stats: %w", err)
+		}
+		dashboard.OpenByStatus[status] += count
+		dashboard.OpenByPriority[priority] += count
+		dashboard.OpenByCategory[category] += count
+		dashboard.Unassigned += unassigned
+		dashboard.FirstResponseOverdue += frOverdue
+		dashboard.ResolutionOverdue += resOverdue
+		dashboard.FirstResponseDueSoon += frDueSoon
+		dashboard.ResolutionDueSoon += resDueSoon
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 2. Ticket tạo trong kỳ: thời gian phản hồi / xử lý, tỉ lệ đạt SLA
+	// (chỉ tính ticket đã có kết quả: đã phản hồi / xử lý, hoặc đã quá hạn)
+	periodQuery := `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE resolved_at IS NOT NULL),
+			(AVG(EXTRACT(EPOCH FROM first_responded_at - created_at) / 60)
+				FILTER (WHERE first_responded_at IS NOT NULL))::float8,
+			(AVG(EXTRACT(EPOCH FROM resolved_at - created_at) / 3600)
+				FILTER (WHERE resolved_at IS NOT NULL))::float8,
+			(100.0 * COUNT(*) FILTER (WHERE first_responded_at <= first_response_due_at)
+				/ NULLIF(COUNT(*) FILTER (WHERE first_responded_at IS NOT NULL OR first_response_due_at < $2), 0))::float8,
+			(100.0 * COUNT(*) FILTER (WHERE resolved_at <= resolution_due_at)
+				/ NULLIF(COUNT(*) FILTER (WHERE resolved_at IS NOT NULL OR resolution_due_at < $2), 0))::float8
+		FROM order_tickets
+		WHERE created_at >= $1
+	`
+	if err := r.pool.QueryRow(ctx, periodQuery, from, now).Scan(
+		&dashboard.Created,
+		&dashboard.Resolved,
+		&dashboard.AvgFirstResponseMinutes,
+		&dashboard.AvgResolutionHours,
+		&dashboard.FirstResponseMetPercent,
+		&dashboard.ResolutionMetPercent,
+	); err != nil {
+		return nil, fmt.Errorf("failed to get ticket period stats: %w", err)
+	}
+
+	// 3. Bồi thường trong kỳ
+	compQuery := `
+		SELECT COUNT(*) FILTER (WHERE type = 'refund'),
+			COALESCE(SUM(amount) FILTER (WHERE type = 'refund'), 0),
+			COUNT(*) FILTER (WHERE type = 'resend')
+		FROM ticket_compensations
+		WHERE created_at >= $1
+	`
+	if err := r.pool.QueryRow(ctx, compQuery, from).Scan(
+		&dashboard.RefundCount,
+		&dashboard.RefundAmount,
+		&dashboard.ResendCount,
+	); err != nil {
+		return nil, fmt.Errorf("failed to get ticket compensation stats: %w", err)
+	}
+
+	return dashboard, nil
+}
+
+// =====================================================
+// LOOKUPS
+// =====================================================
+
+func (r *postgresTicketRepository) GetOrderInfo(ctx context.Context, orderID uuid.UUID) (*model.OrderInfo, error) {
+	query := `SELECT id, order_number, user_id, status FROM orders WHERE id = $1`
+
+	info := &model.OrderInfo{}
+	if err := r.pool.QueryRow(ctx, query, orderID).Scan(
+		&info.ID,
+		&info.OrderNumber,
+		&info.UserID,
+		&info.Status,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	return info, nil
+}
+
+func (r *postgresTicketRepository) IsActiveStaff(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM users
+			WHERE id = $1 AND role IN ('admin', 'cskh')
+				AND is_active = true AND deleted_at IS NULL
+		)
+	`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check staff: %w", err)
+	}
+
+	return exists, nil
+}
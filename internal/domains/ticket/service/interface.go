@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/ticket/model"
+)
+
+// =====================================================
+// TICKET SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ========================================
+	// USER (CUSTOMER)
+	// ========================================
+
+	// CreateTicket opens ticket for own order (SLA tính theo priority mặc định của category)
+	CreateTicket(ctx context.Context, userID uuid.UUID, req model.CreateTicketRequest) (*model.TicketResponse, error)
+
+	// ListMyTickets lists tickets of user
+	ListMyTickets(ctx context.Context, userID uuid.UUID, req model.ListTicketsRequest) (*model.ListTicketsResponse, error)
+
+	// GetMyTicket gets ticket owned by user
+	GetMyTicket(ctx context.Context, userID, ticketID uuid.UUID) (*model.TicketResponse, error)
+
+	// AddCustomerMessage customer reply (waiting_customer / resolved → in_progress)
+	AddCustomerMessage(ctx context.Context, userID, ticketID uuid.UUID, req model.AddMessageRequest) (*model.TicketResponse, error)
+
+	// ========================================
+	// ADMIN (CSKH)
+	// ========================================
+
+	// AdminListTickets lists all tickets (queue theo hạn SLA)
+	AdminListTickets(ctx context.Context, req model.ListTicketsRequest) (*model.ListTicketsResponse, error)
+
+	// AdminGetTicket gets ticket detail
+	AdminGetTicket(ctx context.Context, ticketID uuid.UUID) (*model.TicketResponse, error)
+
+	// AssignTicket assigns staff + optional priority change
+	AssignTicket(ctx context.Context, ticketID uuid.UUID, req model.AssignTicketRequest) (*model.TicketResponse, error)
+
+	// UpdateStatus admin status transition
+	UpdateStatus(ctx context.Context, staffID, ticketID uuid.UUID, req model.UpdateStatusRequest) (*model.TicketResponse, error)
+
+	// AddStaffMessage staff reply (dừng SLA phản hồi)
+	AddStaffMessage(ctx context.Context, staffID, ticketID uuid.UUID, req model.AddMessageRequest) (*model.TicketResponse, error)
+
+	// CreateCompensation refund (refund request chờ duyệt) hoặc resend (đơn 0đ gửi bù)
+	CreateCompensation(ctx context.Context, staffID, ticketID uuid.UUID, req model.CreateCompensationRequest) (*model.TicketResponse, error)
+
+	// GetSLADashboard số liệu SLA cho dashboard vận hành
+	GetSLADashboard(ctx context.Context, req model.DashboardRequest) (*model.SLADashboard, error)
+
+	// ========================================
+	// JOB
+	// ========================================
+
+	// CheckSLA đánh dấu ticket vừa trễ SLA, trả về số ticket
+	CheckSLA(ctx context.Context) (int, error)
+}
@@ -0,0 +1,555 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	orderModel "bookstore-backend/internal/domains/order/model"
+	orderService "bookstore-backend/internal/domains/order/service"
+	paymentModel "bookstore-backend/internal/domains/payment/model"
+	paymentService "bookstore-backend/internal/domains/payment/service"
+	"bookstore-backend/internal/domains/ticket/model"
+	"bookstore-backend/internal/domains/ticket/repository"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type ticketService struct {
+	ticketRepo    repository.TicketRepository
+	orderService  orderService.OrderService
+	refundService paymentService.RefundInterface
+}
+
+func NewTicketService(
+	ticketRepo repository.TicketRepository,
+	orderService orderService.OrderService,
+	refundService paymentService.RefundInterface,
+) ServiceInterface {
+	return &ticketService{
+		ticketRepo:    ticketRepo,
+		orderService:  orderService,
+		refundService: refundService,
+	}
+}
+
+// =====================================================
+// CUSTOMER
+// =====================================================
+
+func (s *ticketService) CreateTicket(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.CreateTicketRequest,
+) (*model.TicketResponse, error) {
+	// Step 1: Validate request
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Đơn phải của khách + đã xác nhận (đơn của người khác trả 404)
+	order, err := s.ticketRepo.GetOrderInfo(ctx, req.OrderID)
+	if err != nil {
+		if errors.Is(err, model.ErrOrderNotFound) {
+			return nil, model.NewOrderNotFoundError()
+		}
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, model.NewOrderNotFoundError()
+	}
+	if !order.CanOpenTicket() {
+		return nil, model.NewOrderNotEligibleError(order.Status)
+	}
+
+	// Step 3: Create ticket, SLA theo priority mặc định
+	now := time.Now()
+	ticketID := uuid.New()
+	ticket := &model.Ticket{
+		ID:           ticketID,
+		TicketNumber: generateTicketNumber(ticketID, now),
+		OrderID:      order.ID,
+		OrderNumber:  order.OrderNumber,
+		UserID:       userID,
+		Category:     req.Category,
+		Priority:     model.DefaultPriority(req.Category),
+		Status:       model.StatusOpen,
+		Subject:      req.Subject,
+		Description:  req.Description,
+		PhotoURLs:    req.PhotoURLs,
+		CreatedAt:    now,
+	}
+	ticket.ApplySLA()
+
+	if err := s.ticketRepo.CreateTicket(ctx, ticket); err != nil {
+		if errors.Is(err, model.ErrTicketExists) {
+			return nil, model.NewTicketExistsError(req.Category)
+		}
+		return nil, err
+	}
+
+	logger.Info("Ticket created", map[string]interface{}{
+		"ticket_id": ticket.ID,
+		"order_id":  ticket.OrderID,
+		"category":  ticket.Category,
+		"priority":  ticket.Priority,
+	})
+
+	return toTicketResponse(ticket, now), nil
+}
+
+func (s *ticketService) ListMyTickets(
+	ctx context.Context,
+	userID uuid.UUID,
+	req model.ListTicketsRequest,
+) (*model.ListTicketsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{"user_id": userID}
+	if req.Status != nil {
+		filters["status"] = *req.Status
+	}
+	if req.Category != nil {
+		filters["category"] = *req.Category
+	}
+	return s.listTickets(ctx, filters, req.Page, req.Limit)
+}
+
+func (s *ticketService) GetMyTicket(ctx context.Context, userID, ticketID uuid.UUID) (*model.TicketResponse, error) {
+	ticket, err := s.getOwnedTicket(ctx, userID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return toTicketResponse(ticket, time.Now()), nil
+}
+
+func (s *ticketService) AddCustomerMessage(
+	ctx context.Context,
+	userID, ticketID uuid.UUID,
+	req model.AddMessageRequest,
+) (*model.TicketResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getOwnedTicket(ctx, userID, ticketID); err != nil {
+		return nil, err
+	}
+	if err := s.addMessage(ctx, ticketID, userID, model.SenderCustomer, req.Body); err != nil {
+		return nil, err
+	}
+
+	return s.GetMyTicket(ctx, userID, ticketID)
+}
+
+// =====================================================
+// ADMIN
+// =====================================================
+
+func (s *ticketService) AdminListTickets(ctx context.Context, req model.ListTicketsRequest) (*model.ListTicketsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{"overdue": req.Overdue}
+	if req.Status != nil {
+		filters["status"] = *req.Status
+	}
+	if req.Category != nil {
+		filters["category"] = *req.Category
+	}
+	if req.Priority != nil {
+		filters["priority"] = *req.Priority
+	}
+	if req.AssignedTo != nil {
+		if *req.AssignedTo == "none" {
+			filters["unassigned"] = true
+		} else {
+			filters["assigned_to"] = uuid.MustParse(*req.AssignedTo) // đã validate
+		}
+	}
+	return s.listTickets(ctx, filters, req.Page, req.Limit)
+}
+
+func (s *ticketService) AdminGetTicket(ctx context.Context, ticketID uuid.UUID) (*model.TicketResponse, error) {
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return toTicketResponse(ticket, time.Now()), nil
+}
+
+// AssignTicket - giao ticket cho nhân viên; đổi priority → tính lại hạn SLA từ lúc tạo
+func (s *ticketService) AssignTicket(
+	ctx context.Context,
+	ticketID uuid.UUID,
+	req model.AssignTicketRequest,
+) (*model.TicketResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == model.StatusClosed {
+		return nil, model.NewTicketClosedError()
+	}
+
+	if req.AssigneeID != nil {
+		ok, err := s.ticketRepo.IsActiveStaff(ctx, *req.AssigneeID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, model.NewInvalidAssigneeError()
+		}
+	}
+
+	ticket.AssignedTo = req.AssigneeID
+	if req.Priority != nil && *req.Priority != ticket.Priority {
+		ticket.Priority = *req.Priority
+		ticket.ApplySLA()
+	}
+
+	if err := s.ticketRepo.Assign(ctx, ticket); err != nil {
+		if errors.Is(err, model.ErrTicketClosed) {
+			return nil, model.NewTicketClosedError()
+		}
+		return nil, err
+	}
+
+	logger.Info("Ticket assigned", map[string]interface{}{
+		"ticket_id":   ticket.ID,
+		"assigned_to": ticket.AssignedTo,
+		"priority":    ticket.Priority,
+	})
+
+	return s.AdminGetTicket(ctx, ticketID)
+}
+
+func (s *ticketService) UpdateStatus(
+	ctx context.Context,
+	staffID, ticketID uuid.UUID,
+	req model.UpdateStatusRequest,
+) (*model.TicketResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.CanTransition(ticket.Status, req.Status) {
+		return nil, model.NewInvalidTransitionError(ticket.Status, req.Status)
+	}
+
+	// Ghi chú gửi khách trước khi đóng (ticket closed không nhận tin nhắn)
+	if req.Note != nil {
+		if err := s.addMessage(ctx, ticketID, staffID, model.SenderStaff, *req.Note); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.ticketRepo.UpdateStatus(ctx, ticketID, ticket.Status, req.Status); err != nil {
+		switch {
+		case errors.Is(err, model.ErrInvalidTransition):
+			return nil, model.NewInvalidTransitionError(ticket.Status, req.Status)
+		case errors.Is(err, model.ErrTicketExists):
+			return nil, model.NewTicketExistsError(ticket.Category)
+		}
+		return nil, err
+	}
+
+	logger.Info("Ticket status updated", map[string]interface{}{
+		"ticket_id": ticketID,
+		"from":      ticket.Status,
+		"to":        req.Status,
+		"staff_id":  staffID,
+	})
+
+	return s.AdminGetTicket(ctx, ticketID)
+}
+
+func (s *ticketService) AddStaffMessage(
+	ctx context.Context,
+	staffID, ticketID uuid.UUID,
+	req model.AddMessageRequest,
+) (*model.TicketResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getTicket(ctx, ticketID); err != nil {
+		return nil, err
+	}
+	if err := s.addMessage(ctx, ticketID, staffID, model.SenderStaff, req.Body); err != nil {
+		return nil, err
+	}
+
+	return s.AdminGetTicket(ctx, ticketID)
+}
+
+// CreateCompensation - bồi thường gắn với ticket
+//   - refund: tạo refund request (pending) cho payment online của đơn, duyệt + hoàn tiền qua luồng refund thường
+//   - resend: tạo đơn 0đ gửi bù sách của đơn gốc (confirmed ngay, đi luồng giao hàng thường)
+func (s *ticketService) CreateCompensation(
+	ctx context.Context,
+	staffID, ticketID uuid.UUID,
+	req model.CreateCompensationRequest,
+) (*model.TicketResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == model.StatusClosed {
+		return nil, model.NewTicketClosedError()
+	}
+
+	note := fmt.Sprintf("Ticket %s", ticket.TicketNumber)
+	if req.Note != nil && strings.TrimSpace(*req.Note) != "" {
+		note += ": " + strings.TrimSpace(*req.Note)
+	}
+
+	comp := &model.Compensation{
+		ID:        uuid.New(),
+		TicketID:  ticket.ID,
+		Type:      req.Type,
+		Note:      req.Note,
+		CreatedBy: staffID,
+	}
+
+	switch req.Type {
+	case model.CompensationRefund:
+		refund, err := s.refundService.CreateCompensationRefund(ctx, staffID, ticket.OrderID, *req.Amount, note)
+		if err != nil {
+			return nil, compensationError(err)
+		}
+		comp.Amount = req.Amount
+		comp.RefundRequestID = &refund.RefundRequestID
+
+	case model.CompensationResend:
+		items := make([]orderModel.CreateOrderItem, len(req.Items))
+		for i, item := range req.Items {
+			items[i] = orderModel.CreateOrderItem{BookID: item.BookID, Quantity: item.Quantity}
+		}
+		order, err := s.orderService.CreateReplacementOrder(ctx, orderModel.CreateReplacementOrderRequest{
+			SourceOrderID: ticket.OrderID,
+			Items:         items,
+			StaffID:       staffID,
+			Reason:        replacementReason(ticket.Category),
+			Note:          note,
+		})
+		if err != nil {
+			return nil, compensationError(err)
+		}
+		comp.ReplacementOrderID = &order.OrderID
+	}
+
+	// Refund request / đơn gửi bù đã tạo: lỗi ghi link chỉ làm mất liên kết với ticket
+	if err := s.ticketRepo.AddCompensation(ctx, comp); err != nil {
+		logger.Error("Failed to link compensation to ticket", err)
+		return nil, err
+	}
+
+	logger.Info("Ticket compensation created", map[string]interface{}{
+		"ticket_id":            ticket.ID,
+		"type":                 comp.Type,
+		"refund_request_id":    comp.RefundRequestID,
+		"replacement_order_id": comp.ReplacementOrderID,
+		"staff_id":             staffID,
+	})
+
+	return s.AdminGetTicket(ctx, ticketID)
+}
+
+func (s *ticketService) GetSLADashboard(ctx context.Context, req model.DashboardRequest) (*model.SLADashboard, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.ticketRepo.GetSLADashboard(ctx, now.AddDate(0, 0, -req.Days), now)
+}
+
+// =====================================================
+// JOB
+// =====================================================
+
+func (s *ticketService) CheckSLA(ctx context.Context) (int, error) {
+	return s.ticketRepo.MarkSLABreaches(ctx, time.Now())
+}
+
+// =====================================================
+// HELPERS
+// =====================================================
+
+func (s *ticketService) getTicket(ctx context.Context, ticketID uuid.UUID) (*model.Ticket, error) {
+	ticket, err := s.ticketRepo.GetTicketByID(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, model.ErrTicketNotFound) {
+			return nil, model.NewTicketNotFoundError()
+		}
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// getOwnedTicket - ticket của user khác trả 404 (không lộ tồn tại)
+func (s *ticketService) getOwnedTicket(ctx context.Context, userID, ticketID uuid.UUID) (*model.Ticket, error) {
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.UserID != userID {
+		return nil, model.NewTicketNotFoundError()
+	}
+	return ticket, nil
+}
+
+func (s *ticketService) addMessage(ctx context.Context, ticketID, senderID uuid.UUID, senderType, body string) error {
+	msg := &model.TicketMessage{
+		ID:         uuid.New(),
+		TicketID:   ticketID,
+		SenderID:   senderID,
+		SenderType: senderType,
+		Body:       body,
+	}
+
+	err := s.ticketRepo.AddMessage(ctx, msg)
+	switch {
+	case errors.Is(err, model.ErrTicketClosed):
+		return model.NewTicketClosedError()
+	case errors.Is(err, model.ErrTicketExists):
+		// Khách mở lại ticket resolved trong khi đã có ticket khác cùng loại đang mở
+		return model.NewInvalidRequestError("Another open ticket of the same category exists for this order, please reply there")
+	}
+	return err
+}
+
+func (s *ticketService) listTickets(
+	ctx context.Context,
+	filters map[string]interface{},
+	page, limit int,
+) (*model.ListTicketsResponse, error) {
+	tickets, total, err := s.ticketRepo.ListTickets(ctx, filters, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]model.TicketResponse, len(tickets))
+	for i, ticket := range tickets {
+		responses[i] = *toTicketResponse(ticket, now)
+	}
+
+	return &model.ListTicketsResponse{
+		Tickets:    responses,
+		Pagination: model.NewPaginationMeta(page, limit, total),
+	}, nil
+}
+
+// compensationError - lỗi nghiệp vụ từ order / payment (vd: vượt số tiền còn hoàn được) trả 422 kèm lý do
+func compensationError(err error) error {
+	var orderErr *orderModel.OrderError
+	if errors.As(err, &orderErr) {
+		return model.NewCompensationFailedError(orderErr.Message)
+	}
+	var paymentErr *paymentModel.PaymentError
+	if errors.As(err, &paymentErr) {
+		return model.NewCompensationFailedError(paymentErr.Message)
+	}
+	return err
+}
+
+// replacementReason lý do gửi bù (báo cáo đơn gửi bù) theo loại khiếu nại
+func replacementReason(category string) string {
+	switch category {
+	case model.CategoryMissingItem:
+		return orderModel.ReplacementReasonMissingItem
+	case model.CategoryDamagedItem:
+		return orderModel.ReplacementReasonDamagedInTransit
+	case model.CategoryWrongItem:
+		return orderModel.ReplacementReasonWrongItem
+	case model.CategoryLateDelivery:
+		return orderModel.ReplacementReasonLostInTransit
+	default:
+		return orderModel.ReplacementReasonOther
+	}
+}
+
+// generateTicketNumber TKT-YYYYMMDD-XXXXXXXX
+func generateTicketNumber(id uuid.UUID, now time.Time) string {
+	suffix := strings.ToUpper(strings.ReplaceAll(id.String(), "-", "")[:8])
+	return fmt.Sprintf("TKT-%s-%s", now.Format("20060102"), suffix)
+}
+
+func toTicketResponse(ticket *model.Ticket, now time.Time) *model.TicketResponse {
+	photoURLs := ticket.PhotoURLs
+	if photoURLs == nil {
+		photoURLs = []string{}
+	}
+
+	resp := &model.TicketResponse{
+		ID:                   ticket.ID,
+		TicketNumber:         ticket.TicketNumber,
+		OrderID:              ticket.OrderID,
+		OrderNumber:          ticket.OrderNumber,
+		UserID:               ticket.UserID,
+		Category:             ticket.Category,
+		Priority:             ticket.Priority,
+		Status:               ticket.Status,
+		Subject:              ticket.Subject,
+		Description:          ticket.Description,
+		PhotoURLs:            photoURLs,
+		AssignedTo:           ticket.AssignedTo,
+		AssignedAt:           ticket.AssignedAt,
+		FirstResponseDueAt:   ticket.FirstResponseDueAt,
+		ResolutionDueAt:      ticket.ResolutionDueAt,
+		FirstRespondedAt:     ticket.FirstRespondedAt,
+		ResolvedAt:           ticket.ResolvedAt,
+		ClosedAt:             ticket.ClosedAt,
+		FirstResponseOverdue: ticket.FirstResponseOverdue(now),
+		ResolutionOverdue:    ticket.ResolutionOverdue(now),
+		CreatedAt:            ticket.CreatedAt,
+		UpdatedAt:            ticket.UpdatedAt,
+	}
+
+	for _, msg := range ticket.Messages {
+		resp.Messages = append(resp.Messages, model.MessageResponse{
+			ID:         msg.ID,
+			SenderType: msg.SenderType,
+			Body:       msg.Body,
+			CreatedAt:  msg.CreatedAt,
+		})
+	}
+	for _, comp := range ticket.Compensations {
+		resp.Compensations = append(resp.Compensations, model.CompensationResponse{
+			ID:                 comp.ID,
+			Type:               comp.Type,
+			Amount:             comp.Amount,
+			RefundRequestID:    comp.RefundRequestID,
+			ReplacementOrderID: comp.ReplacementOrderID,
+			Note:               comp.Note,
+			CreatedBy:          comp.CreatedBy,
+			CreatedAt:          comp.CreatedAt,
+		})
+	}
+
+	return resp
+}
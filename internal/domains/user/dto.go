@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"time"
 
+	"bookstore-backend/internal/shared/locale"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/google/uuid"
@@ -15,10 +17,11 @@ import (
 
 // RegisterRequest - FR-AUTH-001: User Registration
 type RegisterRequest struct {
-	Email    string `json:"email" binding:"required"`
+	Email    string `json:"email" binding:"required" pii:"email"`
 	Password string `json:"password" binding:"required"`
-	FullName string `json:"full_name" binding:"required"`
-	Phone    string `json:"phone,omitempty"`
+	FullName string `json:"full_name" binding:"required" pii:"name"`
+	Phone    string `json:"phone,omitempty" pii:"phone"`
+	Locale   string `json:"locale,omitempty"` // Mặc định "vi"
 }
 
 func (r RegisterRequest) Validate() error {
@@ -44,12 +47,15 @@ func (r RegisterRequest) Validate() error {
 				is.E164.Error("phone must be in E.164 format (e.g., +84912345678)"),
 			),
 		),
+		validation.Field(&r.Locale,
+			validation.When(r.Locale != "", validation.In(locale.Supported()...).Error("unsupported locale")),
+		),
 	)
 }
 
 // LoginRequest - FR-AUTH-002: User Login
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required"`
+	Email    string `json:"email" binding:"required" pii:"email"`
 	Password string `json:"password" binding:"required"`
 }
 
@@ -80,7 +86,7 @@ type VerifyEmailRequest struct {
 
 // ForgotPasswordRequest - FR-AUTH-003: Password Reset Request
 type ForgotPasswordRequest struct {
-	Email string `json:"email" binding:"required"`
+	Email string `json:"email" binding:"required" pii:"email"`
 }
 
 func (r ForgotPasswordRequest) Validate() error {
@@ -134,9 +140,10 @@ func (r ChangePasswordRequest) Validate() error {
 // UserDTO - Public user representation (safe to expose)
 type UserDTO struct {
 	ID          uuid.UUID  `json:"id"`
-	Email       string     `json:"email"`
-	FullName    string     `json:"full_name"`
-	Phone       *string    `json:"phone,omitempty"`
+	Email       string     `json:"email" pii:"email"`
+	FullName    string     `json:"full_name" pii:"name"`
+	Phone       *string    `json:"phone,omitempty" pii:"phone"`
+	Locale      string     `json:"locale"`
 	Role        Role       `json:"role"`
 	IsActive    bool       `json:"is_active"`
 	Points      int        `json:"points"`
@@ -152,6 +159,7 @@ func (u *User) ToDTO() UserDTO {
 		Email:       u.Email,
 		FullName:    u.FullName,
 		Phone:       u.Phone,
+		Locale:      u.Locale,
 		Role:        u.Role,
 		IsActive:    u.IsActive,
 		Points:      u.Points,
@@ -163,8 +171,9 @@ func (u *User) ToDTO() UserDTO {
 
 // UpdateProfileRequest - User updates own profile
 type UpdateProfileRequest struct {
-	FullName string  `json:"full_name,omitempty"`
-	Phone    *string `json:"phone,omitempty"`
+	FullName string  `json:"full_name,omitempty" pii:"name"`
+	Phone    *string `json:"phone,omitempty" pii:"phone"`
+	Locale   *string `json:"locale,omitempty"` // Ngôn ngữ email/notification: vi, en
 }
 
 func (r UpdateProfileRequest) Validate() error {
@@ -177,9 +186,109 @@ func (r UpdateProfileRequest) Validate() error {
 				is.E164.Error("phone must be in E.164 format"),
 			),
 		),
+		validation.Field(&r.Locale,
+			validation.When(r.Locale != nil, validation.In(locale.Supported()...).Error("unsupported locale")),
+		),
 	)
 }
 
+// ========================================
+// PREFERENCES DTOs
+// ========================================
+
+// PreferencesDTO - GET/PUT /users/me/preferences
+type PreferencesDTO struct {
+	Language             string                  `json:"language"`
+	CurrencyDisplay      string                  `json:"currency_display"`
+	MarketingOptIn       bool                    `json:"marketing_opt_in"`
+	MarketingOptInAt     *time.Time              `json:"marketing_opt_in_at,omitempty"`
+	NotificationChannels NotificationChannelsDTO `json:"notification_channels"`
+	UpdatedAt            *time.Time              `json:"updated_at,omitempty"`
+}
+
+// NotificationChannelsDTO - công tắc tổng theo kênh
+type NotificationChannelsDTO struct {
+	Email bool `json:"email"`
+	Push  bool `json:"push"`
+	InApp bool `json:"in_app"`
+	SMS   bool `json:"sms"`
+}
+
+// ToDTO converts Preferences entity to PreferencesDTO
+func (p *Preferences) ToDTO() PreferencesDTO {
+	return PreferencesDTO{
+		Language:         p.Language,
+		CurrencyDisplay:  p.CurrencyDisplay,
+		MarketingOptIn:   p.MarketingOptIn,
+		MarketingOptInAt: p.MarketingOptInAt,
+		NotificationChannels: NotificationChannelsDTO{
+			Email: p.EmailEnabled,
+			Push:  p.PushEnabled,
+			InApp: p.InAppEnabled,
+			SMS:   p.SMSEnabled,
+		},
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// UpdatePreferencesRequest - partial update, field nil = giữ nguyên
+type UpdatePreferencesRequest struct {
+	Language             *string                        `json:"language,omitempty"`
+	CurrencyDisplay      *string                        `json:"currency_display,omitempty"`
+	MarketingOptIn       *bool                          `json:"marketing_opt_in,omitempty"`
+	NotificationChannels *UpdateNotificationChannelsReq `json:"notification_channels,omitempty"`
+}
+
+// UpdateNotificationChannelsReq - partial update công tắc kênh
+type UpdateNotificationChannelsReq struct {
+	Email *bool `json:"email,omitempty"`
+	Push  *bool `json:"push,omitempty"`
+	InApp *bool `json:"in_app,omitempty"`
+	SMS   *bool `json:"sms,omitempty"`
+}
+
+func (r UpdatePreferencesRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Language,
+			validation.When(r.Language != nil, validation.In(locale.Supported()...).Error("unsupported language")),
+		),
+		validation.Field(&r.CurrencyDisplay,
+			validation.When(r.CurrencyDisplay != nil, validation.In(CurrencyVND, CurrencyUSD).Error("currency_display must be VND or USD")),
+		),
+	)
+}
+
+// Apply ghi các field được gửi lên vào preferences hiện tại
+// Opt-in marketing (false → true) ghi lại thời điểm consent
+func (r UpdatePreferencesRequest) Apply(p *Preferences, now time.Time) {
+	if r.Language != nil {
+		p.Language = *r.Language
+	}
+	if r.CurrencyDisplay != nil {
+		p.CurrencyDisplay = *r.CurrencyDisplay
+	}
+	if r.MarketingOptIn != nil {
+		if *r.MarketingOptIn && !p.MarketingOptIn {
+			p.MarketingOptInAt = &now
+		}
+		p.MarketingOptIn = *r.MarketingOptIn
+	}
+	if ch := r.NotificationChannels; ch != nil {
+		if ch.Email != nil {
+			p.EmailEnabled = *ch.Email
+		}
+		if ch.Push != nil {
+			p.PushEnabled = *ch.Push
+		}
+		if ch.InApp != nil {
+			p.InAppEnabled = *ch.InApp
+		}
+		if ch.SMS != nil {
+			p.SMSEnabled = *ch.SMS
+		}
+	}
+}
+
 // ========================================
 // ADMIN DTOs
 // ========================================
@@ -277,7 +386,7 @@ type DeductPointsRequest struct {
 
 // ResendVerificationRequest đại diện resend verification request
 type ResendVerificationRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	Email string `json:"email" binding:"required,email" pii:"email"`
 }
 
 // Validate validates resend verification request
@@ -302,12 +411,12 @@ func (r *ResendVerificationRequest) isValidEmail(email string) error {
 // internal/domains/user/job_payload.go
 type VerifyEmailPayload struct {
 	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	Email  string `json:"email" pii:"email"`
 	Token  string `json:"token"`
 }
 
 type ResetPasswordPayload struct {
 	UserID     string `json:"user_id"`
-	Email      string `json:"email"`
+	Email      string `json:"email" pii:"email"`
 	ResetToken string `json:"reset_token"`
 }
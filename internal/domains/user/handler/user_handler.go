@@ -9,6 +9,7 @@ import (
 
 	"bookstore-backend/internal/domains/cart/service"
 	"bookstore-backend/internal/domains/user"
+	wishlistService "bookstore-backend/internal/domains/wishlist/service"
 	"bookstore-backend/internal/shared/middleware"
 	"bookstore-backend/internal/shared/response"
 	"bookstore-backend/pkg/jwt"
@@ -18,9 +19,10 @@ import (
 // UserHandler xử lý HTTP requests cho user domain
 // Struct này là stateless - chỉ chứa dependencies
 type UserHandler struct {
-	service     user.Service // Business logic layer
-	cartService service.ServiceInterface
-	jwtManager  *jwt.Manager
+	service         user.Service // Business logic layer
+	cartService     service.ServiceInterface
+	wishlistService wishlistService.ServiceInterface
+	jwtManager      *jwt.Manager
 }
 
 // NewUserHandler tạo handler instance
@@ -28,13 +30,15 @@ type UserHandler struct {
 func NewUserHandler(
 	service user.Service,
 	cartService service.ServiceInterface,
+	wishlistService wishlistService.ServiceInterface,
 	jwtManager *jwt.Manager,
 
 ) *UserHandler {
 	return &UserHandler{
-		service:     service,
-		cartService: cartService,
-		jwtManager:  jwtManager,
+		service:         service,
+		cartService:     cartService,
+		wishlistService: wishlistService,
+		jwtManager:      jwtManager,
 	}
 }
 
@@ -56,6 +60,10 @@ func (h *UserHandler) Register(c *gin.Context) {
 	if err := h.bindAndValidate(c, &req); err != nil {
 		return
 	}
+	// Không chọn ngôn ngữ → theo Accept-Language (i18n middleware)
+	if req.Locale == "" {
+		req.Locale = middleware.GetLocale(c)
+	}
 	logger.Info("bind and validate", map[string]interface{}{
 		"req": req,
 	})
@@ -170,7 +178,11 @@ func (h *UserHandler) Login(c *gin.Context) {
 	res.RefreshToken = ""
 
 	// Merge cart if user had anonymous session
+	// Route login không chạy CartMiddleware → fallback đọc thẳng cookie
 	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		sessionID = middleware.GetSessionIDFromCookie(c)
+	}
 	if sessionID != "" {
 		if err := h.cartService.MergeCart(c.Request.Context(), sessionID, res.User.ID); err != nil {
 			// Log error but DON'T fail login
@@ -182,6 +194,15 @@ func (h *UserHandler) Login(c *gin.Context) {
 
 		}
 
+		// Wishlist guest cùng session → wishlist của user (lỗi không chặn login)
+		if _, err := h.wishlistService.MergeWishlist(c.Request.Context(), sessionID, res.User.ID); err != nil {
+			logger.Info("Failed to merge wishlist after login", map[string]interface{}{
+				"user_id":    res.User.ID,
+				"session_id": sessionID,
+				"error":      err.Error(),
+			})
+		}
+
 		// Clear session cookie
 		c.SetCookie(middleware.SessionCookieName, "", -1, "/", "", true, true)
 	}
@@ -389,6 +410,59 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	response.Success(c, http.StatusOK, "Profile updated successfully", updatedProfile)
 }
 
+// GetPreferences xử lý GET /users/me/preferences
+// @Summary      Get user preferences
+// @Description  Language, currency display, marketing opt-in and notification channels
+// @Security     BearerAuth
+// @Router       /users/me/preferences
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Preferences retrieved successfully", prefs)
+}
+
+// UpdatePreferences xử lý PUT /users/me/preferences
+// @Summary      Update user preferences
+// @Description  Partial update: only fields present in the body are changed
+// @Security     BearerAuth
+// @Router       /users/me/preferences
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", err)
+		return
+	}
+
+	var req user.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.Error(c, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	prefs, err := h.service.UpdatePreferences(c.Request.Context(), userID, req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Preferences updated successfully", prefs)
+}
+
 // ChangePassword xử lý PUT /users/me/password
 // @Summary      Change password
 // @Description  Change authenticated user's password
@@ -15,6 +15,7 @@ import (
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/cache"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 )
 
 const (
@@ -157,7 +158,7 @@ func (h *FailedLoginHandler) sendWarningAlert(ctx context.Context, payload types
 
 func (h *FailedLoginHandler) triggerSecurityAlert(ctx context.Context, payload types.SecurityAlertPayload) {
 	data, _ := json.Marshal(payload)
-	task := asynq.NewTask(shared.TypeSendSecurityAlert, data)
+	task := tracing.NewTask(ctx, shared.TypeSendSecurityAlert, data)
 
 	_, err := h.asynqClient.EnqueueContext(
 		ctx,
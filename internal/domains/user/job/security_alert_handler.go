@@ -3,7 +3,9 @@ package job
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -11,6 +13,7 @@ import (
 
 	"bookstore-backend/internal/domains/user"
 	"bookstore-backend/internal/infrastructure/email"
+	"bookstore-backend/internal/infrastructure/joboutcome"
 	"bookstore-backend/internal/shared"
 	"bookstore-backend/internal/shared/utils"
 )
@@ -18,15 +21,18 @@ import (
 type SecurityAlertHandler struct {
 	emailService email.EmailService
 	userRepo     user.Repository // ✅ Use shared interface
+	outcomes     *joboutcome.Recorder
 }
 
 func NewSecurityAlertHandler(
 	emailService email.EmailService,
 	userRepo user.Repository,
+	outcomes *joboutcome.Recorder,
 ) *SecurityAlertHandler {
 	return &SecurityAlertHandler{
 		emailService: emailService,
 		userRepo:     userRepo,
+		outcomes:     outcomes,
 	}
 }
 
@@ -43,6 +49,13 @@ func (h *SecurityAlertHandler) ProcessTask(ctx context.Context, task *asynq.Task
 		Str("ip_address", payload.IPAddress).
 		Msg("Processing security alert")
 
+	// Không có địa chỉ thì retry cũng không gửi được → bỏ qua, đếm cho digest vận hành
+	if strings.TrimSpace(payload.Email) == "" {
+		log.Warn().Str("user_id", payload.UserID).Msg("No recipient email, skip security alert")
+		h.outcomes.Record(ctx, joboutcome.JobSecurityAlertEmail, joboutcome.OutcomeSkippedMissingAddress, 1)
+		return nil
+	}
+
 	uid := utils.ParseStringToUUID(payload.UserID)
 	// Get user basic info
 	user, err := h.userRepo.FindByID(ctx, uid)
@@ -61,8 +74,14 @@ func (h *SecurityAlertHandler) ProcessTask(ctx context.Context, task *asynq.Task
 		Body:    body,
 	}); err != nil {
 		log.Error().Err(err).Msg("Failed to send security alert email")
+		if errors.Is(err, email.ErrRecipientSuppressed) {
+			h.outcomes.Record(ctx, joboutcome.JobSecurityAlertEmail, joboutcome.OutcomeSuppressed, 1)
+		} else {
+			h.outcomes.Record(ctx, joboutcome.JobSecurityAlertEmail, joboutcome.OutcomeFailed, 1)
+		}
 		return fmt.Errorf("send email: %w", err)
 	}
+	h.outcomes.Record(ctx, joboutcome.JobSecurityAlertEmail, joboutcome.OutcomeSent, 1)
 
 	log.Info().
 		Str("user_id", payload.UserID).
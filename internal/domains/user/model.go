@@ -11,14 +11,15 @@ import (
 type User struct {
 	// Identity
 	ID    uuid.UUID `db:"id" json:"id"`
-	Email string    `db:"email" json:"email"`
+	Email string    `db:"email" json:"email" pii:"email"`
 
 	// Authentication
 	PasswordHash string `db:"password_hash" json:"-"` // Never expose in JSON
 
 	// Profile
-	FullName string  `db:"full_name" json:"full_name"` // Lưu ý: DB dùng full_name không phải fullname
-	Phone    *string `db:"phone" json:"phone,omitempty"`
+	FullName string  `db:"full_name" json:"full_name" pii:"name"` // Lưu ý: DB dùng full_name không phải fullname
+	Phone    *string `db:"phone" json:"phone,omitempty" pii:"phone"`
+	Locale   string  `db:"locale" json:"locale"` // Ngôn ngữ nhận notification/email (vi, en)
 
 	// Authorization - ĐÚNG 4 ROLES từ migration
 	Role     Role `db:"role" json:"role"`
@@ -125,3 +126,68 @@ func (u *User) Sanitize() {
 	u.VerificationToken = nil
 	u.ResetToken = nil
 }
+
+// ========================================
+// PREFERENCES
+// ========================================
+
+// Preferences - bảng user_preferences + users.locale
+// User chưa lưu preferences → DefaultPreferences
+type Preferences struct {
+	UserID           uuid.UUID  `db:"user_id" json:"user_id"`
+	Language         string     `db:"locale" json:"language"` // users.locale
+	CurrencyDisplay  string     `db:"currency_display" json:"currency_display"`
+	MarketingOptIn   bool       `db:"marketing_opt_in" json:"marketing_opt_in"`
+	MarketingOptInAt *time.Time `db:"marketing_opt_in_at" json:"marketing_opt_in_at,omitempty"`
+
+	// Công tắc tổng theo kênh notification
+	EmailEnabled bool `db:"email_enabled" json:"email_enabled"`
+	PushEnabled  bool `db:"push_enabled" json:"push_enabled"`
+	InAppEnabled bool `db:"in_app_enabled" json:"in_app_enabled"`
+	SMSEnabled   bool `db:"sms_enabled" json:"sms_enabled"`
+
+	UpdatedAt *time.Time `db:"updated_at" json:"updated_at,omitempty"` // nil = chưa từng lưu
+}
+
+// Currency hiển thị giá (chỉ ảnh hưởng hiển thị, đơn hàng luôn tính VND)
+const (
+	CurrencyVND = "VND"
+	CurrencyUSD = "USD"
+)
+
+// Notification channels (khớp notification model.Channel*)
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelInApp = "in_app"
+	ChannelSMS   = "sms"
+)
+
+// DefaultPreferences - khớp DEFAULT của migration 000059
+func DefaultPreferences(userID uuid.UUID, locale string) *Preferences {
+	return &Preferences{
+		UserID:          userID,
+		Language:        locale,
+		CurrencyDisplay: CurrencyVND,
+		MarketingOptIn:  false,
+		EmailEnabled:    true,
+		PushEnabled:     true,
+		InAppEnabled:    true,
+		SMSEnabled:      false,
+	}
+}
+
+// IsChannelEnabled kiểm tra công tắc tổng của kênh, kênh lạ coi như bật
+func (p *Preferences) IsChannelEnabled(channel string) bool {
+	switch channel {
+	case ChannelEmail:
+		return p.EmailEnabled
+	case ChannelPush:
+		return p.PushEnabled
+	case ChannelInApp:
+		return p.InAppEnabled
+	case ChannelSMS:
+		return p.SMSEnabled
+	}
+	return true
+}
@@ -63,6 +63,17 @@ type Repository interface {
 	// UpdateLastLogin cập nhật last_login_at
 	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
 
+	// ========================================
+	// PREFERENCES
+	// ========================================
+
+	// GetPreferences lấy preferences (users.locale + user_preferences), chưa lưu thì trả default
+	// Returns: ErrUserNotFound nếu user không tồn tại
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error)
+
+	// SavePreferences upsert user_preferences và cập nhật users.locale trong 1 transaction
+	SavePreferences(ctx context.Context, prefs *Preferences) error
+
 	// ========================================
 	// ADMIN FUNCTIONS
 	// ========================================
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	_ "github.com/jackc/pgx/v5"        // ← Add
 	_ "github.com/jackc/pgx/v5/pgconn" // ← Add
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -18,6 +19,7 @@ import (
 
 	user "bookstore-backend/internal/domains/user"
 	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/database"
 	"bookstore-backend/pkg/logger"
 )
 
@@ -58,12 +60,14 @@ func (r *postgresRepository) Create(ctx context.Context, u *user.User) (uuid.UUI
 			 email, password_hash, full_name, phone, role,
 			is_active, points, is_verified, 
 			verification_token, verification_sent_at,
-			verification_token_expires_at, created_at, updated_at
+			verification_token_expires_at, created_at, updated_at,
+			locale
 		) VALUES (
 			$1, $2, $3, $4, $5, $6,
 			$7, $8, $9,
 			$10, $11,
-			$12, $13
+			$12, $13,
+			$14
 		)
 		RETURNING id
 	`
@@ -86,6 +90,7 @@ func (r *postgresRepository) Create(ctx context.Context, u *user.User) (uuid.UUI
 		u.VerificationTokenExpiresAt,
 		u.CreatedAt,
 		u.UpdatedAt,
+		u.Locale,
 	).Scan(&userID)
 
 	if err != nil {
@@ -130,7 +135,7 @@ func (r *postgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*user.
 	// STEP 2: CACHE MISS - QUERY DATABASE
 	query := `
 		SELECT 
-			id, email, password_hash, full_name, phone, role,
+			id, email, password_hash, full_name, phone, locale, role,
 			is_active, points, is_verified,
 			verification_token, verification_sent_at,
 			reset_token, reset_token_expires_at,
@@ -147,6 +152,7 @@ func (r *postgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*user.
 		&u.PasswordHash,
 		&u.FullName,
 		&u.Phone,
+		&u.Locale,
 		&u.Role,
 		&u.IsActive,
 		&u.Points,
@@ -183,7 +189,7 @@ func (r *postgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*user.
 func (r *postgresRepository) FindByEmail(ctx context.Context, email string) (*user.User, error) {
 	query := `
 		SELECT 
-			id, email, password_hash, full_name, phone, role,
+			id, email, password_hash, full_name, phone, locale, role,
 			is_active, points, is_verified, last_login_at,
 			created_at, updated_at
 		FROM users
@@ -197,6 +203,7 @@ func (r *postgresRepository) FindByEmail(ctx context.Context, email string) (*us
 		&u.PasswordHash,
 		&u.FullName,
 		&u.Phone,
+		&u.Locale,
 		&u.Role,
 		&u.IsActive,
 		&u.Points,
@@ -238,7 +245,8 @@ func (r *postgresRepository) Update(ctx context.Context, u *user.User) error {
 		SET 
 			full_name = $2,
 			phone = $3,
-			updated_at = $4
+			locale = $4,
+			updated_at = $5
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -250,6 +258,7 @@ func (r *postgresRepository) Update(ctx context.Context, u *user.User) error {
 		u.ID,
 		u.FullName,
 		u.Phone,
+		u.Locale,
 		u.UpdatedAt,
 	)
 
@@ -497,6 +506,112 @@ func (r *postgresRepository) UpdateLastLogin(ctx context.Context, userID uuid.UU
 	return nil
 }
 
+// ========================================
+// PREFERENCES
+// ========================================
+
+// GetPreferences đọc users.locale + user_preferences (LEFT JOIN)
+// User chưa có dòng user_preferences → COALESCE về DEFAULT của migration 000059
+func (r *postgresRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*user.Preferences, error) {
+	query := `
+		SELECT
+			u.id, u.locale,
+			COALESCE(p.currency_display, 'VND'),
+			COALESCE(p.marketing_opt_in, FALSE),
+			p.marketing_opt_in_at,
+			COALESCE(p.email_enabled, TRUE),
+			COALESCE(p.push_enabled, TRUE),
+			COALESCE(p.in_app_enabled, TRUE),
+			COALESCE(p.sms_enabled, FALSE),
+			p.updated_at
+		FROM users u
+		LEFT JOIN user_preferences p ON p.user_id = u.id
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+	`
+
+	var p user.Preferences
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&p.UserID,
+		&p.Language,
+		&p.CurrencyDisplay,
+		&p.MarketingOptIn,
+		&p.MarketingOptInAt,
+		&p.EmailEnabled,
+		&p.PushEnabled,
+		&p.InAppEnabled,
+		&p.SMSEnabled,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get preferences: %w", err)
+	}
+
+	return &p, nil
+}
+
+// SavePreferences upsert user_preferences + users.locale trong 1 transaction
+// Invalidate cache user vì FindByID trả về locale
+func (r *postgresRepository) SavePreferences(ctx context.Context, p *user.Preferences) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET locale = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, p.UserID, p.Language)
+	if err != nil {
+		return fmt.Errorf("update locale: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return user.ErrUserNotFound
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO user_preferences (
+			user_id, currency_display, marketing_opt_in, marketing_opt_in_at,
+			email_enabled, push_enabled, in_app_enabled, sms_enabled
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			currency_display = EXCLUDED.currency_display,
+			marketing_opt_in = EXCLUDED.marketing_opt_in,
+			marketing_opt_in_at = EXCLUDED.marketing_opt_in_at,
+			email_enabled = EXCLUDED.email_enabled,
+			push_enabled = EXCLUDED.push_enabled,
+			in_app_enabled = EXCLUDED.in_app_enabled,
+			sms_enabled = EXCLUDED.sms_enabled,
+			updated_at = NOW()
+		RETURNING updated_at
+	`,
+		p.UserID,
+		p.CurrencyDisplay,
+		p.MarketingOptIn,
+		p.MarketingOptInAt,
+		p.EmailEnabled,
+		p.PushEnabled,
+		p.InAppEnabled,
+		p.SMSEnabled,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert preferences: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit preferences: %w", err)
+	}
+
+	cacheKey := fmt.Sprintf("user:%s", p.UserID.String())
+	_ = r.cache.Delete(ctx, cacheKey)
+
+	return nil
+}
+
 // ========================================
 // ADMIN FUNCTIONS
 // ========================================
@@ -509,7 +624,7 @@ func (r *postgresRepository) List(ctx context.Context, req user.ListUsersRequest
 	var queryBuilder strings.Builder
 	queryBuilder.WriteString(`
 		SELECT 
-			id, email, full_name, phone, role, 
+			id, email, full_name, phone, locale, role, 
 			is_active, points, is_verified, 
 			last_login_at, created_at
 		FROM users
@@ -612,6 +727,7 @@ func (r *postgresRepository) List(ctx context.Context, req user.ListUsersRequest
 			&u.Email,
 			&u.FullName,
 			&u.Phone,
+			&u.Locale,
 			&u.Role,
 			&u.IsActive,
 			&u.Points,
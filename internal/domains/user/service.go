@@ -23,6 +23,12 @@ type Service interface {
 	GetProfile(ctx context.Context, userID uuid.UUID) (*UserDTO, error)
 	UpdateProfile(ctx context.Context, userID uuid.UUID, req UpdateProfileRequest) (*UserDTO, error)
 
+	// Preferences
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*PreferencesDTO, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, req UpdatePreferencesRequest) (*PreferencesDTO, error)
+	// GetLocale - ngôn ngữ đã lưu của user (dùng cho i18n middleware)
+	GetLocale(ctx context.Context, userID uuid.UUID) (string, error)
+
 	// Admin Functions
 	ListUsers(ctx context.Context, req ListUsersRequest) (*ListUsersResponse, error)
 	UpdateUserRole(ctx context.Context, userID uuid.UUID, req UpdateRoleRequest) error
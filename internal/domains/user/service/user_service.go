@@ -18,9 +18,11 @@ import (
 	"bookstore-backend/internal/domains/user"
 	"bookstore-backend/internal/infrastructure/email"
 	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/locale"
 	"bookstore-backend/pkg/cache"
 	"bookstore-backend/pkg/jwt"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 	// TODO: Cần tạo JWT helper
 )
 
@@ -91,10 +93,11 @@ func (s *userService) Register(ctx context.Context, req user.RegisterRequest) (*
 		PasswordHash:               string(passwordHash),
 		FullName:                   req.FullName,
 		Phone:                      stringPtr(req.Phone), // Convert string to *string
-		Role:                       user.RoleUser,        // Default role
-		IsActive:                   true,                 // Active by default
-		Points:                     0,                    // Start with 0 points
-		IsVerified:                 false,                // Require email verification
+		Locale:                     locale.Normalize(req.Locale),
+		Role:                       user.RoleUser, // Default role
+		IsActive:                   true,          // Active by default
+		Points:                     0,             // Start with 0 points
+		IsVerified:                 false,         // Require email verification
 		VerificationToken:          &verificationTokenHash,
 		VerificationSentAt:         &now,
 		VerificationTokenExpiresAt: &expiresAt,
@@ -110,14 +113,14 @@ func (s *userService) Register(ctx context.Context, req user.RegisterRequest) (*
 	newUser.ID = id
 	// 7. SEND VERIFICATION EMAIL (Async)
 	// Gửi job qua Asynq
-	link := fmt.Sprintf("http://localhost:8080/api/v1/auth/verify-email?token=%s", verificationTokenHash)
 	payload := email.VerificationEmailData{
-		VerifyLink: link,
-		Email:      req.Email,
-		ExpiresIn:  "24 giờ",
+		VerifyLink:     verifyEmailLink(verificationTokenHash),
+		Email:          req.Email,
+		ExpiresInHours: 24,
+		Locale:         newUser.Locale,
 	}
 	b, _ := json.Marshal(payload)
-	task := asynq.NewTask(shared.TypeSendVerificationEmail, b)
+	task := tracing.NewTask(ctx, shared.TypeSendVerificationEmail, b)
 	s.asynqClient.Enqueue(task, asynq.Queue(shared.QueueAuth), asynq.Timeout(30*time.Second), asynq.MaxRetry(3))
 
 	// 8. RETURN DTO (không expose sensitive data)
@@ -238,7 +241,7 @@ func (s *userService) trackFailedLogin(ctx context.Context, userID, ipAddress st
 		return
 	}
 
-	task := asynq.NewTask(shared.TypeProcessFailedLogin, data)
+	task := tracing.NewTask(ctx, shared.TypeProcessFailedLogin, data)
 
 	// ✅ Enqueue with proper options
 	_, err = s.asynqClient.EnqueueContext(
@@ -377,22 +380,24 @@ func (s *userService) ForgotPassword(ctx context.Context, req user.ForgotPasswor
 	}
 
 	// 6. SEND RESET EMAIL (Async)
-	payload := user.ResetPasswordPayload{
-		UserID:     u.ID.String(),
-		Email:      req.Email,
-		ResetToken: resetToken,
+	// Payload phải khớp email.ResetPasswordData (handler unmarshal thẳng vào struct này)
+	payload := email.ResetPasswordData{
+		Email:          req.Email,
+		Token:          resetToken,
+		ExpiresInHours: 24,
+		Locale:         u.Locale,
 	}
 	b, _ := json.Marshal(payload)
-	task := asynq.NewTask(shared.TypeSendResetEmail, b)
+	task := tracing.NewTask(ctx, shared.TypeSendResetEmail, b)
 	s.asynqClient.Enqueue(task, asynq.Queue(shared.QueueAuth), asynq.Timeout(30*time.Second), asynq.MaxRetry(3))
 	log.Printf("🔐 Reset token for %s: %s (expires: %v)", u.Email, resetToken, expiresAt)
 	return nil
 }
 
 // service.go
-func (s *userService) ResendVerification(ctx context.Context, email string) error {
+func (s *userService) ResendVerification(ctx context.Context, emailAddr string) error {
 	// 1. Find user by email
-	u, err := s.repo.FindByEmail(ctx, email)
+	u, err := s.repo.FindByEmail(ctx, emailAddr)
 	if err != nil {
 		// Security: generic message
 		return nil
@@ -417,13 +422,14 @@ func (s *userService) ResendVerification(ctx context.Context, email string) erro
 	}
 
 	// 5. Send email
-	payload := user.VerifyEmailPayload{
-		Token:  newTokenHash,
-		UserID: u.ID.String(),
-		Email:  u.Email,
+	payload := email.VerificationEmailData{
+		VerifyLink:     verifyEmailLink(newTokenHash),
+		Email:          u.Email,
+		ExpiresInHours: 24,
+		Locale:         u.Locale,
 	}
 	b, _ := json.Marshal(payload)
-	task := asynq.NewTask(shared.TypeSendVerificationEmail, b)
+	task := tracing.NewTask(ctx, shared.TypeSendVerificationEmail, b)
 	s.asynqClient.Enqueue(task, asynq.Queue(shared.QueueAuth), asynq.Timeout(30*time.Second), asynq.MaxRetry(3))
 
 	return nil
@@ -564,6 +570,9 @@ func (s *userService) UpdateProfile(ctx context.Context, userID uuid.UUID, req u
 	if req.Phone != nil {
 		u.Phone = req.Phone
 	}
+	if req.Locale != nil {
+		u.Locale = *req.Locale
+	}
 
 	// 4. PERSIST CHANGES
 	if err := s.repo.Update(ctx, u); err != nil {
@@ -575,6 +584,53 @@ func (s *userService) UpdateProfile(ctx context.Context, userID uuid.UUID, req u
 	return &dto, nil
 }
 
+// ========================================
+// PREFERENCES
+// ========================================
+
+// GetPreferences lấy preferences của user (default nếu chưa lưu)
+func (s *userService) GetPreferences(ctx context.Context, userID uuid.UUID) (*user.PreferencesDTO, error) {
+	prefs, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := prefs.ToDTO()
+	return &dto, nil
+}
+
+// UpdatePreferences partial update preferences (ngôn ngữ, tiền tệ, marketing, kênh notification)
+func (s *userService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req user.UpdatePreferencesRequest) (*user.PreferencesDTO, error) {
+	// 1. VALIDATE INPUT
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 2. GET CURRENT PREFERENCES
+	prefs, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. APPLY + PERSIST
+	req.Apply(prefs, time.Now())
+	if err := s.repo.SavePreferences(ctx, prefs); err != nil {
+		return nil, fmt.Errorf("save preferences: %w", err)
+	}
+
+	dto := prefs.ToDTO()
+	return &dto, nil
+}
+
+// GetLocale trả về ngôn ngữ đã lưu (FindByID có cache Redis → rẻ cho middleware)
+func (s *userService) GetLocale(ctx context.Context, userID uuid.UUID) (string, error) {
+	u, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return locale.Normalize(u.Locale), nil
+}
+
 // ========================================
 // ADMIN FUNCTIONS
 // ========================================
@@ -669,6 +725,11 @@ func (s *userService) hashToken(token string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// verifyEmailLink build link xác thực gửi trong email
+func verifyEmailLink(tokenHash string) string {
+	return fmt.Sprintf("http://localhost:8080/api/v1/auth/verify-email?token=%s", tokenHash)
+}
+
 // stringPtr convert string thành *string (helper cho nullable fields)
 func stringPtr(s string) *string {
 	if s == "" {
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -271,3 +272,167 @@ func (h *Handler) ValidateWarehouseHasStock(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, "Stock validation completed", result)
 }
+
+// ==================== STAFF ASSIGNMENT (ADMIN) ====================
+
+// AssignStaff gán nhân viên kho vào kho
+// POST /admin/warehouses/:id/staff
+func (h *Handler) AssignStaff(c *gin.Context) {
+	warehouseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid warehouse ID", err.Error())
+		return
+	}
+
+	var req model.AssignStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	var assignedBy *uuid.UUID
+	if v, exists := c.Get("user_id"); exists {
+		if id, ok := v.(uuid.UUID); ok {
+			assignedBy = &id
+		}
+	}
+
+	if err := h.svc.AssignStaff(c.Request.Context(), warehouseID, req.UserID, assignedBy); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to assign staff", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Staff assigned successfully", nil)
+}
+
+// UnassignStaff gỡ nhân viên khỏi kho
+// DELETE /admin/warehouses/:id/staff/:user_id
+func (h *Handler) UnassignStaff(c *gin.Context) {
+	warehouseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid warehouse ID", err.Error())
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	if err := h.svc.UnassignStaff(c.Request.Context(), warehouseID, userID); err != nil {
+		response.Error(c, http.StatusNotFound, "Staff assignment not found", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Staff unassigned successfully", nil)
+}
+
+// ListStaff danh sách nhân viên được gán vào kho
+// GET /admin/warehouses/:id/staff
+func (h *Handler) ListStaff(c *gin.Context) {
+	warehouseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid warehouse ID", err.Error())
+		return
+	}
+
+	staff, err := h.svc.ListStaff(c.Request.Context(), warehouseID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list warehouse staff", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Warehouse staff retrieved successfully", staff)
+}
+
+// ==================== ALLOCATION SETTINGS (ADMIN) ====================
+
+// UpdateAllocationSettings chỉnh safety stock mặc định / trọng số ưu tiên của kho
+// PUT /admin/warehouses/:id/allocation
+func (h *Handler) UpdateAllocationSettings(c *gin.Context) {
+	warehouseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid warehouse ID", err.Error())
+		return
+	}
+
+	var req model.UpdateAllocationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	warehouse, err := h.svc.UpdateAllocationSettings(c.Request.Context(), warehouseID, req)
+	if err != nil {
+		handleAllocationError(c, err, "Failed to update allocation settings")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Allocation settings updated successfully", warehouse)
+}
+
+// GetBookSafetyStock safety stock hiệu lực + tồn online của 1 sách tại kho
+// GET /admin/warehouses/:id/safety-stock/:book_id
+func (h *Handler) GetBookSafetyStock(c *gin.Context) {
+	warehouseID, bookID, ok := parseWarehouseBookIDs(c)
+	if !ok {
+		return
+	}
+
+	stock, err := h.svc.GetBookSafetyStock(c.Request.Context(), warehouseID, bookID)
+	if err != nil {
+		handleAllocationError(c, err, "Failed to get safety stock")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Safety stock retrieved successfully", stock)
+}
+
+// SetBookSafetyStock đặt override safety stock cho 1 sách (safety_stock null = dùng mặc định kho)
+// PUT /admin/warehouses/:id/safety-stock/:book_id
+func (h *Handler) SetBookSafetyStock(c *gin.Context) {
+	warehouseID, bookID, ok := parseWarehouseBookIDs(c)
+	if !ok {
+		return
+	}
+
+	var req model.SetBookSafetyStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	stock, err := h.svc.SetBookSafetyStock(c.Request.Context(), warehouseID, bookID, req)
+	if err != nil {
+		handleAllocationError(c, err, "Failed to set safety stock")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Safety stock updated successfully", stock)
+}
+
+func parseWarehouseBookIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	warehouseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid warehouse ID", err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+	bookID, err := uuid.Parse(c.Param("book_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book ID", err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+	return warehouseID, bookID, true
+}
+
+func handleAllocationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrInvalidAllocationSettings):
+		response.Error(c, http.StatusBadRequest, "Validation failed", err.Error())
+	case errors.Is(err, model.ErrWarehouseNotFound), errors.Is(err, model.ErrInventoryNotFound):
+		response.Error(c, http.StatusNotFound, "Not found", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, fallback, err.Error())
+	}
+}
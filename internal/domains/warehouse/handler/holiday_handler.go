@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"bookstore-backend/internal/domains/warehouse/model"
+	"bookstore-backend/internal/domains/warehouse/service"
+	"bookstore-backend/internal/shared/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HolidayHandler struct {
+	svc service.HolidayService
+}
+
+func NewHolidayHandler(svc service.HolidayService) *HolidayHandler {
+	return &HolidayHandler{svc: svc}
+}
+
+// ListHolidays danh sách ngày nghỉ
+// GET /admin/warehouses/holidays?year=2026&scope=province&province=Hà Nội
+func (h *HolidayHandler) ListHolidays(c *gin.Context) {
+	var filter model.ListHolidaysFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return
+	}
+	if raw := c.Query("warehouse_id"); raw != "" {
+		warehouseID, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid warehouse ID", err.Error())
+			return
+		}
+		filter.WarehouseID = &warehouseID
+	}
+
+	holidays, err := h.svc.ListHolidays(c.Request.Context(), filter)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to list holidays", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Holidays retrieved successfully", holidays)
+}
+
+// CreateHoliday khai báo ngày nghỉ toàn quốc / tỉnh / kho
+// POST /admin/warehouses/holidays
+func (h *HolidayHandler) CreateHoliday(c *gin.Context) {
+	var req model.CreateHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if v, exists := c.Get("user_id"); exists {
+		if id, ok := v.(uuid.UUID); ok {
+			createdBy = &id
+		}
+	}
+
+	holiday, err := h.svc.CreateHoliday(c.Request.Context(), req, createdBy)
+	if err != nil {
+		h.handleError(c, err, "Failed to create holiday")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Holiday created successfully", holiday)
+}
+
+// UpdateHoliday đổi ngày / tên ngày nghỉ
+// PUT /admin/warehouses/holidays/:holiday_id
+func (h *HolidayHandler) UpdateHoliday(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("holiday_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid holiday ID", err.Error())
+		return
+	}
+
+	var req model.UpdateHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	holiday, err := h.svc.UpdateHoliday(c.Request.Context(), id, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update holiday")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Holiday updated successfully", holiday)
+}
+
+// DeleteHoliday xoá ngày nghỉ (kể cả ngày do seed sinh, năm đó sẽ không bị seed lại)
+// DELETE /admin/warehouses/holidays/:holiday_id
+func (h *HolidayHandler) DeleteHoliday(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("holiday_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid holiday ID", err.Error())
+		return
+	}
+
+	if err := h.svc.DeleteHoliday(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Failed to delete holiday")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Holiday deleted successfully", nil)
+}
+
+// SeedNationalHolidays sinh ngày lễ quốc gia VN cho năm (mặc định năm hiện tại)
+// POST /admin/warehouses/holidays/seed
+func (h *HolidayHandler) SeedNationalHolidays(c *gin.Context) {
+	var req model.SeedHolidaysRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+	if req.Year == 0 {
+		req.Year = time.Now().In(model.VietnamTZ).Year()
+	}
+
+	result, err := h.svc.SeedNationalHolidays(c.Request.Context(), req.Year)
+	if err != nil {
+		h.handleError(c, err, "Failed to seed national holidays")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "National holidays seeded", result)
+}
+
+// GetProcessingSLABreaches đơn quá hạn xử lý (đã bỏ qua ngày nghỉ của kho)
+// GET /admin/warehouses/processing-sla/breaches
+func (h *HolidayHandler) GetProcessingSLABreaches(c *gin.Context) {
+	report, err := h.svc.GetProcessingSLAReport(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to build processing SLA report", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Processing SLA report generated", report)
+}
+
+func (h *HolidayHandler) handleError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, model.ErrHolidayNotFound):
+		response.Error(c, http.StatusNotFound, "Holiday not found", err.Error())
+	case errors.Is(err, model.ErrHolidayExists):
+		response.Error(c, http.StatusConflict, msg, err.Error())
+	case errors.Is(err, model.ErrInvalidHoliday):
+		response.Error(c, http.StatusBadRequest, msg, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, msg, err.Error())
+	}
+}
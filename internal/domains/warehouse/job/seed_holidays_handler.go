@@ -0,0 +1,53 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/domains/warehouse/model"
+	"bookstore-backend/internal/domains/warehouse/service"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/logger"
+)
+
+// SeedHolidaysPayload - seed năm hiện tại + YearsAhead năm tới
+type SeedHolidaysPayload struct {
+	YearsAhead int `json:"years_ahead"`
+}
+
+const defaultSeedYearsAhead = 1
+
+// SeedHolidaysHandler sinh ngày lễ quốc gia VN cho năm hiện tại và năm sau,
+// để lịch Tết năm sau có sẵn trước khi khách đặt hàng cuối năm.
+// Năm đã seed thì bỏ qua → chạy lại nhiều lần không ghi đè chỉnh sửa của admin.
+type SeedHolidaysHandler struct {
+	service service.HolidayService
+}
+
+func NewSeedHolidaysHandler(service service.HolidayService) *SeedHolidaysHandler {
+	return &SeedHolidaysHandler{service: service}
+}
+
+func (h *SeedHolidaysHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload SeedHolidaysPayload
+	if err := utils.UnmarshalTask(t, &payload); err != nil {
+		logger.Error("Failed to unmarshal seed_national_holidays payload, using default", err)
+	}
+
+	yearsAhead := payload.YearsAhead
+	if yearsAhead <= 0 {
+		yearsAhead = defaultSeedYearsAhead
+	}
+
+	year := time.Now().In(model.VietnamTZ).Year()
+	for y := year; y <= year+yearsAhead; y++ {
+		if _, err := h.service.SeedNationalHolidays(ctx, y); err != nil {
+			return fmt.Errorf("seed national holidays %d: %w", y, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Phạm vi ngày nghỉ (map cột warehouse_holidays.scope)
+const (
+	HolidayScopeNational  = "national"
+	HolidayScopeProvince  = "province"
+	HolidayScopeWarehouse = "warehouse"
+)
+
+// Nguồn tạo ngày nghỉ
+const (
+	HolidaySourceManual       = "manual"
+	HolidaySourceNationalSeed = "national_seed"
+)
+
+// HolidayDateLayout định dạng ngày trong request / query (YYYY-MM-DD)
+const HolidayDateLayout = "2006-01-02"
+
+// VietnamTZ - ngày nghỉ tính theo giờ Việt Nam (UTC+7, không có DST)
+var VietnamTZ = time.FixedZone("ICT", 7*60*60)
+
+var (
+	ErrHolidayNotFound = errors.New("holiday not found")
+	ErrHolidayExists   = errors.New("holiday already declared for this scope and date")
+	ErrInvalidHoliday  = errors.New("invalid holiday")
+)
+
+// Entity ngày nghỉ (map bảng warehouse_holidays)
+type Holiday struct {
+	ID          uuid.UUID  `json:"id"`
+	Scope       string     `json:"scope"`
+	WarehouseID *uuid.UUID `json:"warehouse_id,omitempty"`
+	Province    *string    `json:"province,omitempty"`
+	Date        time.Time  `json:"date"`
+	Name        string     `json:"name"`
+	Source      string     `json:"source"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// AppliesTo ngày nghỉ có áp cho kho (thuộc province) không
+// warehouseID nil = đơn chưa gán kho → chỉ ngày nghỉ toàn quốc
+func (h *Holiday) AppliesTo(warehouseID *uuid.UUID, province string) bool {
+	switch h.Scope {
+	case HolidayScopeNational:
+		return true
+	case HolidayScopeProvince:
+		return warehouseID != nil && h.Province != nil && strings.EqualFold(*h.Province, province)
+	case HolidayScopeWarehouse:
+		return warehouseID != nil && h.WarehouseID != nil && *h.WarehouseID == *warehouseID
+	}
+	return false
+}
+
+// DTO tạo ngày nghỉ (admin)
+type CreateHolidayRequest struct {
+	Scope       string     `json:"scope" binding:"required"`
+	WarehouseID *uuid.UUID `json:"warehouse_id,omitempty"`
+	Province    *string    `json:"province,omitempty"`
+	Date        string     `json:"date" binding:"required"` // YYYY-MM-DD
+	Name        string     `json:"name" binding:"required"`
+
+	// ParsedDate do Validate điền
+	ParsedDate time.Time `json:"-"`
+}
+
+// Validate kiểm tra phạm vi khớp với warehouse_id / province (cùng rule CHECK của DB)
+func (r *CreateHolidayRequest) Validate() error {
+	date, err := parseHolidayDate(r.Date)
+	if err != nil {
+		return err
+	}
+	r.ParsedDate = date
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidHoliday)
+	}
+
+	if r.Province != nil {
+		p := strings.TrimSpace(*r.Province)
+		r.Province = &p
+	}
+
+	switch r.Scope {
+	case HolidayScopeNational:
+		if r.WarehouseID != nil || r.Province != nil {
+			return fmt.Errorf("%w: national holiday must not have warehouse_id or province", ErrInvalidHoliday)
+		}
+	case HolidayScopeProvince:
+		if r.Province == nil || *r.Province == "" || r.WarehouseID != nil {
+			return fmt.Errorf("%w: province holiday requires province only", ErrInvalidHoliday)
+		}
+	case HolidayScopeWarehouse:
+		if r.WarehouseID == nil || r.Province != nil {
+			return fmt.Errorf("%w: warehouse holiday requires warehouse_id only", ErrInvalidHoliday)
+		}
+	default:
+		return fmt.Errorf("%w: scope must be national, province or warehouse", ErrInvalidHoliday)
+	}
+	return nil
+}
+
+// DTO sửa ngày nghỉ: chỉ đổi ngày / tên, đổi phạm vi thì xoá và tạo lại
+type UpdateHolidayRequest struct {
+	Date *string `json:"date,omitempty"`
+	Name *string `json:"name,omitempty"`
+
+	ParsedDate *time.Time `json:"-"`
+}
+
+func (r *UpdateHolidayRequest) Validate() error {
+	if r.Date != nil {
+		date, err := parseHolidayDate(*r.Date)
+		if err != nil {
+			return err
+		}
+		r.ParsedDate = &date
+	}
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		if name == "" {
+			return fmt.Errorf("%w: name must not be empty", ErrInvalidHoliday)
+		}
+		r.Name = &name
+	}
+	return nil
+}
+
+// ListHolidaysFilter - GET /admin/warehouses/holidays?year=2026&scope=province&province=...
+type ListHolidaysFilter struct {
+	Year        int        `form:"year"`
+	Scope       string     `form:"scope"`
+	WarehouseID *uuid.UUID `form:"-"` // parse từ query warehouse_id ở handler
+	Province    string     `form:"province"`
+}
+
+// SeedHolidaysRequest - sinh ngày lễ quốc gia cho năm (mặc định năm hiện tại)
+type SeedHolidaysRequest struct {
+	Year int `json:"year"`
+}
+
+// SeedHolidaysResult kết quả seed 1 năm
+type SeedHolidaysResult struct {
+	Year     int  `json:"year"`
+	Inserted int  `json:"inserted"`
+	Skipped  bool `json:"skipped"` // năm đã seed trước đó → giữ nguyên chỉnh sửa của admin
+}
+
+// Giới hạn năm seed (thuật toán âm lịch chính xác trong khoảng này)
+const (
+	MinHolidaySeedYear = 2000
+	MaxHolidaySeedYear = 2100
+)
+
+func parseHolidayDate(s string) (time.Time, error) {
+	date, err := time.ParseInLocation(HolidayDateLayout, strings.TrimSpace(s), time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidHoliday)
+	}
+	return date, nil
+}
+
+// =====================================================
+// CALENDAR
+// =====================================================
+
+// HolidayCalendar - tập ngày nghỉ áp cho 1 kho
+// Ngày làm việc = ngày không nằm trong lịch (kho làm cả T7/CN, ngày nghỉ cuối tuần khai báo riêng nếu có)
+type HolidayCalendar struct {
+	days map[string]string // YYYY-MM-DD (giờ VN) → tên ngày nghỉ
+}
+
+// NewHolidayCalendar lọc các ngày nghỉ áp cho kho
+func NewHolidayCalendar(holidays []Holiday, warehouseID *uuid.UUID, province string) HolidayCalendar {
+	days := make(map[string]string)
+	for i := range holidays {
+		if holidays[i].AppliesTo(warehouseID, province) {
+			days[holidays[i].Date.Format(HolidayDateLayout)] = holidays[i].Name
+		}
+	}
+	return HolidayCalendar{days: days}
+}
+
+// IsHoliday ngày (theo giờ VN) có phải ngày nghỉ không
+func (c HolidayCalendar) IsHoliday(t time.Time) bool {
+	_, ok := c.days[t.In(VietnamTZ).Format(HolidayDateLayout)]
+	return ok
+}
+
+// maxSkippedHolidays chặn vòng lặp khi lịch cấu hình sai (nghỉ liên tục cả năm)
+const maxSkippedHolidays = 60
+
+// AddWorkingDays cộng n ngày làm việc kể từ start, bỏ qua ngày nghỉ
+// Trả về thời điểm kết thúc và số ngày nghỉ đã bỏ qua
+func (c HolidayCalendar) AddWorkingDays(start time.Time, n int) (time.Time, int) {
+	end := start
+	skipped := 0
+	for added := 0; added < n; {
+		end = end.AddDate(0, 0, 1)
+		if c.IsHoliday(end) && skipped < maxSkippedHolidays {
+			skipped++
+			continue
+		}
+		added++
+	}
+	return end, skipped
+}
@@ -1,33 +1,52 @@
 package model
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Giới hạn trọng số ưu tiên kho (khớp CHECK của DB)
+const (
+	MinAllocationPriority = 1
+	MaxAllocationPriority = 1000
+)
+
+var (
+	ErrInvalidAllocationSettings = errors.New("invalid allocation settings")
+	ErrWarehouseNotFound         = errors.New("warehouse not found")
+	ErrInventoryNotFound         = errors.New("book has no inventory at this warehouse")
+)
+
 // Entity kho (map bảng warehouses)
 type Warehouse struct {
-	ID        uuid.UUID  `json:"id"`
-	Name      string     `json:"name"`
-	Code      string     `json:"code"`
-	Address   string     `json:"address"`
-	Province  string     `json:"province"`
-	Latitude  *float64   `json:"latitude,omitempty"`
-	Longitude *float64   `json:"longitude,omitempty"`
-	IsActive  bool       `json:"is_active"`
-	Version   int        `json:"version"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	Code               string     `json:"code"`
+	Address            string     `json:"address"`
+	Province           string     `json:"province"`
+	Latitude           *float64   `json:"latitude,omitempty"`
+	Longitude          *float64   `json:"longitude,omitempty"`
+	IsActive           bool       `json:"is_active"`
+	SafetyStock        int        `json:"safety_stock"`        // tồn đệm mặc định / sách, không dùng cho đơn online
+	AllocationPriority int        `json:"allocation_priority"` // trọng số % khi chọn kho (100 = trung tính)
+	Version            int        `json:"version"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Khi lookup inventory cho book tại các kho
 // (map chức năng FindWarehousesWithStockByDistance)
 type WarehouseWithInventory struct {
 	Warehouse
+	// Tồn khả dụng cho đơn online (đã trừ reserved và safety stock)
 	AvailableQuantity int     `json:"available_quantity"`
 	DistanceKm        float64 `json:"distance_km"`
+	// Khoảng cách sau khi áp allocation_priority (dùng để xếp hạng kho)
+	EffectiveDistanceKm float64 `json:"effective_distance_km"`
 }
 
 // DTO cho tạo kho
@@ -48,6 +67,53 @@ type UpdateWarehouseRequest struct {
 	Longitude *float64 `json:"longitude,omitempty"`
 	IsActive  *bool    `json:"is_active,omitempty"`
 }
+
+// DTO admin chỉnh tham số chọn kho (PUT /admin/warehouses/:id/allocation)
+type UpdateAllocationSettingsRequest struct {
+	SafetyStock        *int `json:"safety_stock,omitempty"`
+	AllocationPriority *int `json:"allocation_priority,omitempty"`
+}
+
+func (r *UpdateAllocationSettingsRequest) Validate() error {
+	if r.SafetyStock == nil && r.AllocationPriority == nil {
+		return fmt.Errorf("%w: safety_stock or allocation_priority is required", ErrInvalidAllocationSettings)
+	}
+	if r.SafetyStock != nil && *r.SafetyStock < 0 {
+		return fmt.Errorf("%w: safety_stock must be >= 0", ErrInvalidAllocationSettings)
+	}
+	if r.AllocationPriority != nil && (*r.AllocationPriority < MinAllocationPriority || *r.AllocationPriority > MaxAllocationPriority) {
+		return fmt.Errorf("%w: allocation_priority must be between %d and %d",
+			ErrInvalidAllocationSettings, MinAllocationPriority, MaxAllocationPriority)
+	}
+	return nil
+}
+
+// DTO override safety stock cho 1 sách tại kho (PUT /admin/warehouses/:id/safety-stock/:book_id)
+// SafetyStock nil = bỏ override, quay về mức mặc định của kho
+type SetBookSafetyStockRequest struct {
+	SafetyStock *int `json:"safety_stock"`
+}
+
+func (r *SetBookSafetyStockRequest) Validate() error {
+	if r.SafetyStock != nil && *r.SafetyStock < 0 {
+		return fmt.Errorf("%w: safety_stock must be >= 0", ErrInvalidAllocationSettings)
+	}
+	return nil
+}
+
+// Safety stock hiệu lực của 1 sách tại kho
+type BookSafetyStock struct {
+	WarehouseID uuid.UUID `json:"warehouse_id"`
+	BookID      uuid.UUID `json:"book_id"`
+	// Override riêng cho sách (nil = dùng mặc định kho)
+	Override             *int `json:"override,omitempty"`
+	WarehouseDefault     int  `json:"warehouse_default"`
+	EffectiveSafetyStock int  `json:"effective_safety_stock"`
+	Quantity             int  `json:"quantity"`
+	Reserved             int  `json:"reserved"`
+	OnlineAvailable      int  `json:"online_available"`
+}
+
 type ListWarehouseFilter struct {
 	Keyword  string
 	Province string
@@ -55,3 +121,16 @@ type ListWarehouseFilter struct {
 	Offset   int
 	Limit    int
 }
+
+// Phân quyền nhân viên kho (map bảng warehouse_staff_assignments)
+type StaffAssignment struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	WarehouseID uuid.UUID  `json:"warehouse_id"`
+	AssignedBy  *uuid.UUID `json:"assigned_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// DTO gán nhân viên vào kho
+type AssignStaffRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PendingShipmentOrder đơn đã xác nhận nhưng chưa bàn giao vận chuyển (đối tượng theo dõi SLA xử lý)
+type PendingShipmentOrder struct {
+	OrderID       uuid.UUID
+	OrderNumber   string
+	Status        string
+	WarehouseID   *uuid.UUID
+	WarehouseName *string
+	Province      string
+	// Mốc bắt đầu SLA: lúc thanh toán (online) hoặc lúc đặt (COD)
+	SLAStartedAt time.Time
+}
+
+// ProcessingSLABreach 1 đơn quá hạn xử lý
+type ProcessingSLABreach struct {
+	OrderID         uuid.UUID  `json:"order_id"`
+	OrderNumber     string     `json:"order_number"`
+	Status          string     `json:"status"`
+	WarehouseID     *uuid.UUID `json:"warehouse_id,omitempty"`
+	WarehouseName   *string    `json:"warehouse_name,omitempty"`
+	SLAStartedAt    time.Time  `json:"sla_started_at"`
+	DueAt           time.Time  `json:"due_at"`
+	HolidaysSkipped int        `json:"holidays_skipped"`
+	OverdueHours    int        `json:"overdue_hours"`
+}
+
+// ProcessingSLAReport - GET /admin/warehouses/processing-sla/breaches
+type ProcessingSLAReport struct {
+	SLADays        int                   `json:"sla_days"`
+	GeneratedAt    time.Time             `json:"generated_at"`
+	AwaitingOrders int                   `json:"awaiting_orders"`
+	Breaches       []ProcessingSLABreach `json:"breaches"`
+}
@@ -0,0 +1,199 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// =====================================================
+// NGÀY LỄ QUỐC GIA VIỆT NAM (Bộ luật Lao động 2019, Điều 112)
+// =====================================================
+// - Tết Dương lịch: 01/01
+// - Tết Âm lịch: 5 ngày (ngày cuối năm cũ + mùng 1 → mùng 4)
+// - Giỗ Tổ Hùng Vương: 10/3 âm lịch
+// - Giải phóng miền Nam 30/4, Quốc tế Lao động 01/5
+// - Quốc khánh: 02/9 + 01 ngày liền kề (seed 01/9)
+// Ngày nghỉ bù / hoán đổi do Chính phủ công bố từng năm → admin khai báo thêm
+
+// NationalHoliday 1 ngày lễ sinh tự động
+type NationalHoliday struct {
+	Date time.Time
+	Name string
+}
+
+// VietnamNationalHolidays sinh ngày lễ quốc gia của năm (ngày dương lịch, UTC midnight)
+func VietnamNationalHolidays(year int) []NationalHoliday {
+	date := func(y, m, d int) time.Time {
+		return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	}
+
+	holidays := []NationalHoliday{
+		{Date: date(year, 1, 1), Name: "Tết Dương lịch"},
+	}
+
+	// Mùng 1 Tết của năm âm lịch bắt đầu trong năm dương lịch này
+	tet := lunarToSolar(1, 1, year, false)
+	holidays = append(holidays, NationalHoliday{Date: tet.AddDate(0, 0, -1), Name: "Tết Nguyên đán (giao thừa)"})
+	for i := 0; i < 4; i++ {
+		holidays = append(holidays, NationalHoliday{
+			Date: tet.AddDate(0, 0, i),
+			Name: "Tết Nguyên đán (mùng " + string(rune('1'+i)) + ")",
+		})
+	}
+
+	holidays = append(holidays,
+		NationalHoliday{Date: lunarToSolar(10, 3, year, false), Name: "Giỗ Tổ Hùng Vương (10/3 âm lịch)"},
+		NationalHoliday{Date: date(year, 4, 30), Name: "Ngày Giải phóng miền Nam"},
+		NationalHoliday{Date: date(year, 5, 1), Name: "Ngày Quốc tế Lao động"},
+		NationalHoliday{Date: date(year, 9, 1), Name: "Quốc khánh (nghỉ liền kề)"},
+		NationalHoliday{Date: date(year, 9, 2), Name: "Quốc khánh"},
+	)
+	return holidays
+}
+
+// =====================================================
+// ÂM LỊCH VIỆT NAM (thuật toán Hồ Ngọc Đức, múi giờ UTC+7)
+// =====================================================
+
+const lunarTimeZone = 7.0
+
+// lunarToSolar đổi ngày âm lịch sang dương lịch (UTC midnight)
+func lunarToSolar(lunarDay, lunarMonth, lunarYear int, leap bool) time.Time {
+	var a11, b11 int
+	if lunarMonth < 11 {
+		a11 = lunarMonth11(lunarYear - 1)
+		b11 = lunarMonth11(lunarYear)
+	} else {
+		a11 = lunarMonth11(lunarYear)
+		b11 = lunarMonth11(lunarYear + 1)
+	}
+
+	k := int(math.Floor(0.5 + (float64(a11)-2415021.076998695)/29.530588853))
+	off := lunarMonth - 11
+	if off < 0 {
+		off += 12
+	}
+
+	// Năm nhuận âm lịch: các tháng sau tháng nhuận lệch thêm 1
+	if b11-a11 > 365 {
+		leapOff := leapMonthOffset(a11)
+		if leap || off >= leapOff {
+			off++
+		}
+	}
+
+	monthStart := newMoonDay(k + off)
+	y, m, d := jdToDate(monthStart + lunarDay - 1)
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+}
+
+// jdFromDate số ngày Julius của ngày dương lịch
+func jdFromDate(dd, mm, yy int) int {
+	a := (14 - mm) / 12
+	y := yy + 4800 - a
+	m := mm + 12*a - 3
+	jd := dd + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+	if jd < 2299161 {
+		jd = dd + (153*m+2)/5 + 365*y + y/4 - 32083
+	}
+	return jd
+}
+
+// jdToDate đổi số ngày Julius sang ngày dương lịch (year, month, day)
+func jdToDate(jd int) (int, int, int) {
+	var b, c int
+	if jd > 2299160 {
+		a := jd + 32044
+		b = (4*a + 3) / 146097
+		c = a - (b*146097)/4
+	} else {
+		c = jd + 32082
+	}
+	d := (4*c + 3) / 1461
+	e := c - (1461*d)/4
+	m := (5*e + 2) / 153
+	day := e - (153*m+2)/5 + 1
+	month := m + 3 - 12*(m/10)
+	year := b*100 + d - 4800 + m/10
+	return year, month, day
+}
+
+// newMoon thời điểm sóc thứ k (Julius, UTC) kể từ 1/1/1900
+func newMoon(k int) float64 {
+	t := float64(k) / 1236.85
+	t2 := t * t
+	t3 := t2 * t
+	dr := math.Pi / 180
+	kf := float64(k)
+
+	jd1 := 2415020.75933 + 29.53058868*kf + 0.0001178*t2 - 0.000000155*t3
+	jd1 += 0.00033 * math.Sin((166.56+132.87*t-0.009173*t2)*dr)
+	m := 359.2242 + 29.10535608*kf - 0.0000333*t2 - 0.00000347*t3
+	mpr := 306.0253 + 385.81691806*kf + 0.0107306*t2 + 0.00001236*t3
+	f := 21.2964 + 390.67050646*kf - 0.0016528*t2 - 0.00000239*t3
+
+	c1 := (0.1734-0.000393*t)*math.Sin(m*dr) + 0.0021*math.Sin(2*dr*m)
+	c1 -= 0.4068*math.Sin(mpr*dr) + 0.0161*math.Sin(dr*2*mpr)
+	c1 -= 0.0004 * math.Sin(dr*3*mpr)
+	c1 += 0.0104*math.Sin(dr*2*f) - 0.0051*math.Sin(dr*(m+mpr))
+	c1 -= 0.0074*math.Sin(dr*(m-mpr)) + 0.0004*math.Sin(dr*(2*f+m))
+	c1 -= 0.0004*math.Sin(dr*(2*f-m)) - 0.0006*math.Sin(dr*(2*f+mpr))
+	c1 += 0.0010*math.Sin(dr*(2*f-mpr)) + 0.0005*math.Sin(dr*(2*mpr+m))
+
+	var deltaT float64
+	if t < -11 {
+		deltaT = 0.001 + 0.000839*t + 0.0002261*t2 - 0.00000845*t3 - 0.000000081*t*t3
+	} else {
+		deltaT = -0.000278 + 0.000265*t + 0.000262*t2
+	}
+	return jd1 + c1 - deltaT
+}
+
+// newMoonDay ngày (Julius, giờ VN) chứa sóc thứ k
+func newMoonDay(k int) int {
+	return int(math.Floor(newMoon(k) + 0.5 + lunarTimeZone/24))
+}
+
+// sunLongitudeSector cung hoàng đạo (0..11) của mặt trời lúc đầu ngày Julius dayNumber (giờ VN)
+func sunLongitudeSector(dayNumber int) int {
+	jdn := float64(dayNumber) - 0.5 - lunarTimeZone/24
+	t := (jdn - 2451545.0) / 36525
+	t2 := t * t
+	dr := math.Pi / 180
+
+	m := 357.52910 + 35999.05030*t - 0.0001559*t2 - 0.00000048*t*t2
+	l0 := 280.46645 + 36000.76983*t + 0.0003032*t2
+	dl := (1.914600 - 0.004817*t - 0.000014*t2) * math.Sin(dr*m)
+	dl += (0.019993-0.000101*t)*math.Sin(dr*2*m) + 0.000290*math.Sin(dr*3*m)
+
+	l := (l0 + dl) * dr
+	l -= math.Pi * 2 * math.Floor(l/(math.Pi*2))
+	return int(math.Floor(l / math.Pi * 6))
+}
+
+// lunarMonth11 ngày bắt đầu tháng 11 âm lịch (tháng chứa Đông chí) của năm dương lịch yy
+func lunarMonth11(yy int) int {
+	off := jdFromDate(31, 12, yy) - 2415021
+	k := int(math.Floor(float64(off) / 29.530588853))
+	nm := newMoonDay(k)
+	if sunLongitudeSector(nm) >= 9 {
+		nm = newMoonDay(k - 1)
+	}
+	return nm
+}
+
+// leapMonthOffset vị trí tháng nhuận tính từ tháng 11 âm lịch a11
+func leapMonthOffset(a11 int) int {
+	k := int(math.Floor((float64(a11)-2415021.076998695)/29.530588853 + 0.5))
+	i := 1
+	arc := sunLongitudeSector(newMoonDay(k + i))
+	for {
+		last := arc
+		i++
+		arc = sunLongitudeSector(newMoonDay(k + i))
+		if arc == last || i >= 14 {
+			break
+		}
+	}
+	return i - 1
+}
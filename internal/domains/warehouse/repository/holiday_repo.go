@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"bookstore-backend/internal/domains/warehouse/model"
+	"bookstore-backend/pkg/database"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HolidayRepository interface {
+	// CRUD admin
+	Create(ctx context.Context, req model.CreateHolidayRequest, createdBy *uuid.UUID) (*model.Holiday, error)
+	Update(ctx context.Context, id uuid.UUID, req model.UpdateHolidayRequest) (*model.Holiday, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter model.ListHolidaysFilter) ([]model.Holiday, error)
+
+	// ListInRange mọi ngày nghỉ (mọi phạm vi) trong [from, to], caller lọc theo kho bằng HolidayCalendar
+	ListInRange(ctx context.Context, from, to time.Time) ([]model.Holiday, error)
+
+	// SeedNational ghi ngày lễ quốc gia của năm (1 transaction)
+	// Năm đã có dòng national_seed → bỏ qua (skipped = true) để không ghi đè chỉnh sửa của admin
+	SeedNational(ctx context.Context, year int, holidays []model.NationalHoliday) (inserted int, skipped bool, err error)
+
+	// ListPendingShipmentOrders đơn confirmed / processing chưa bàn giao vận chuyển (theo dõi SLA xử lý)
+	ListPendingShipmentOrders(ctx context.Context, limit int) ([]model.PendingShipmentOrder, error)
+}
+
+type holidayRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewHolidayRepository(pool *pgxpool.Pool) HolidayRepository {
+	return &holidayRepository{pool: pool}
+}
+
+const holidayColumns = `id, scope, warehouse_id, province, holiday_date, name, source, created_by, created_at, updated_at`
+
+func scanHoliday(row pgx.Row) (*model.Holiday, error) {
+	var h model.Holiday
+	err := row.Scan(
+		&h.ID,
+		&h.Scope,
+		&h.WarehouseID,
+		&h.Province,
+		&h.Date,
+		&h.Name,
+		&h.Source,
+		&h.CreatedBy,
+		&h.CreatedAt,
+		&h.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// mapHolidayWriteError unique → ErrHolidayExists, FK warehouse → ErrInvalidHoliday
+func mapHolidayWriteError(err error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // uq_warehouse_holidays_scope_date
+			return model.ErrHolidayExists
+		case "23503": // warehouse_id không tồn tại
+			return fmt.Errorf("%w: warehouse not found", model.ErrInvalidHoliday)
+		}
+	}
+	return fmt.Errorf("failed to %s holiday: %w", action, err)
+}
+
+func (r *holidayRepository) Create(ctx context.Context, req model.CreateHolidayRequest, createdBy *uuid.UUID) (*model.Holiday, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO warehouse_holidays (scope, warehouse_id, province, holiday_date, name, source, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + holidayColumns
+
+	h, err := scanHoliday(r.pool.QueryRow(ctx, query,
+		req.Scope,
+		req.WarehouseID,
+		req.Province,
+		req.ParsedDate,
+		req.Name,
+		model.HolidaySourceManual,
+		createdBy,
+	))
+	if err != nil {
+		return nil, mapHolidayWriteError(err, "create")
+	}
+	return h, nil
+}
+
+func (r *holidayRepository) Update(ctx context.Context, id uuid.UUID, req model.UpdateHolidayRequest) (*model.Holiday, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE warehouse_holidays
+		SET holiday_date = COALESCE($2, holiday_date),
+		    name = COALESCE($3, name),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + holidayColumns
+
+	h, err := scanHoliday(r.pool.QueryRow(ctx, query, id, req.ParsedDate, req.Name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrHolidayNotFound
+		}
+		return nil, mapHolidayWriteError(err, "update")
+	}
+	return h, nil
+}
+
+func (r *holidayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM warehouse_holidays WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete holiday: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrHolidayNotFound
+	}
+	return nil
+}
+
+func (r *holidayRepository) List(ctx context.Context, filter model.ListHolidaysFilter) ([]model.Holiday, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	where := []string{"1=1"}
+	args := []interface{}{}
+	idx := 1
+	if filter.Year > 0 {
+		where = append(where, fmt.Sprintf("EXTRACT(YEAR FROM holiday_date) = $%d", idx))
+		args = append(args, filter.Year)
+		idx++
+	}
+	if filter.Scope != "" {
+		where = append(where, fmt.Sprintf("scope = $%d", idx))
+		args = append(args, filter.Scope)
+		idx++
+	}
+	if filter.WarehouseID != nil {
+		where = append(where, fmt.Sprintf("warehouse_id = $%d", idx))
+		args = append(args, *filter.WarehouseID)
+		idx++
+	}
+	if filter.Province != "" {
+		where = append(where, fmt.Sprintf("LOWER(province) = LOWER($%d)", idx))
+		args = append(args, filter.Province)
+	}
+
+	query := `SELECT ` + holidayColumns + ` FROM warehouse_holidays WHERE ` +
+		strings.Join(where, " AND ") + ` ORDER BY holiday_date, scope`
+
+	return r.queryHolidays(ctx, query, args...)
+}
+
+func (r *holidayRepository) ListInRange(ctx context.Context, from, to time.Time) ([]model.Holiday, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ` + holidayColumns + `
+		FROM warehouse_holidays
+		WHERE holiday_date BETWEEN $1::date AND $2::date
+		ORDER BY holiday_date
+	`
+	return r.queryHolidays(ctx, query,
+		from.In(model.VietnamTZ).Format(model.HolidayDateLayout),
+		to.In(model.VietnamTZ).Format(model.HolidayDateLayout),
+	)
+}
+
+func (r *holidayRepository) queryHolidays(ctx context.Context, query string, args ...interface{}) ([]model.Holiday, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holidays: %w", err)
+	}
+	defer rows.Close()
+
+	holidays := make([]model.Holiday, 0)
+	for rows.Next() {
+		h, err := scanHoliday(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan holiday: %w", err)
+		}
+		holidays = append(holidays, *h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate holidays: %w", err)
+	}
+	return holidays, nil
+}
+
+func (r *holidayRepository) SeedNational(ctx context.Context, year int, holidays []model.NationalHoliday) (int, bool, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	inserted := 0
+	skipped := false
+	err := database.WithTransaction(ctx, r.pool, func(tx pgx.Tx) error {
+		// Khoá theo năm: 2 lần seed song song (job + admin) không cùng chèn
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('warehouse_holidays_seed'), $1)`, year); err != nil {
+			return fmt.Errorf("lock holiday seed: %w", err)
+		}
+
+		var seeded bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM warehouse_holidays
+				WHERE source = $1 AND EXTRACT(YEAR FROM holiday_date) = $2
+			)
+		`, model.HolidaySourceNationalSeed, year).Scan(&seeded)
+		if err != nil {
+			return fmt.Errorf("check seeded year: %w", err)
+		}
+		if seeded {
+			skipped = true
+			return nil
+		}
+
+		for _, h := range holidays {
+			// ON CONFLICT: admin đã khai báo tay ngày này ở phạm vi toàn quốc → giữ bản của admin
+			tag, err := tx.Exec(ctx, `
+				INSERT INTO warehouse_holidays (scope, holiday_date, name, source)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT DO NOTHING
+			`, model.HolidayScopeNational, h.Date, h.Name, model.HolidaySourceNationalSeed)
+			if err != nil {
+				return fmt.Errorf("insert national holiday: %w", err)
+			}
+			inserted += int(tag.RowsAffected())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return inserted, skipped, nil
+}
+
+func (r *holidayRepository) ListPendingShipmentOrders(ctx context.Context, limit int) ([]model.PendingShipmentOrder, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT o.id, o.order_number, o.status, o.warehouse_id, w.name, COALESCE(w.province, ''),
+		       COALESCE(o.paid_at, o.created_at) AS sla_started_at
+		FROM orders o
+		LEFT JOIN warehouses w ON w.id = o.warehouse_id
+		WHERE o.status IN ('confirmed', 'processing')
+		ORDER BY sla_started_at
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending shipment orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]model.PendingShipmentOrder, 0)
+	for rows.Next() {
+		var o model.PendingShipmentOrder
+		if err := rows.Scan(
+			&o.OrderID,
+			&o.OrderNumber,
+			&o.Status,
+			&o.WarehouseID,
+			&o.WarehouseName,
+			&o.Province,
+			&o.SLAStartedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pending shipment order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate pending shipment orders: %w", err)
+	}
+	return orders, nil
+}
@@ -23,4 +23,15 @@ type Repository interface {
 	// Public lookup
 	FindWarehousesWithStockByDistance(ctx context.Context, bookID uuid.UUID, lat float64, long float64, requiredQty int) ([]model.WarehouseWithInventory, error)
 	ListActiveWarehouses(ctx context.Context) ([]model.Warehouse, error)
+
+	// Safety stock + ưu tiên kho khi chọn kho cho đơn online
+	GetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID) (*model.BookSafetyStock, error)
+	UpdateAllocationSettings(ctx context.Context, id uuid.UUID, req model.UpdateAllocationSettingsRequest) error
+	SetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID, safetyStock *int) error
+
+	// Phân quyền nhân viên kho
+	AssignStaff(ctx context.Context, warehouseID, userID uuid.UUID, assignedBy *uuid.UUID) error
+	UnassignStaff(ctx context.Context, warehouseID, userID uuid.UUID) error
+	ListStaff(ctx context.Context, warehouseID uuid.UUID) ([]model.StaffAssignment, error)
+	ListAssignedWarehouseIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
 }
@@ -3,11 +3,13 @@ package repository
 import (
 	"bookstore-backend/internal/domains/warehouse/model"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -24,10 +26,10 @@ func (r *postgresRepository) CreateWarehouse(ctx context.Context, req model.Crea
 	code := fmt.Sprintf("WARE_%d", time.Now().UnixNano())
 	query := `INSERT INTO warehouses (name, code, address, province, latitude, longitude, is_active)
     VALUES ($1, $2, $3, $4, $5, $6, TRUE)
-    RETURNING id, version, created_at, updated_at`
+    RETURNING id, safety_stock, allocation_priority, version, created_at, updated_at`
 	var warehouse model.Warehouse
 	err := r.pool.QueryRow(ctx, query, req.Name, code, req.Address, req.Province, req.Latitude, req.Longitude).
-		Scan(&warehouse.ID, &warehouse.Version, &warehouse.CreatedAt, &warehouse.UpdatedAt)
+		Scan(&warehouse.ID, &warehouse.SafetyStock, &warehouse.AllocationPriority, &warehouse.Version, &warehouse.CreatedAt, &warehouse.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create warehouse: %w", err)
 	}
@@ -79,10 +81,10 @@ func (r *postgresRepository) UpdateWarehouse(ctx context.Context, id uuid.UUID,
 		return nil, fmt.Errorf("no field to update")
 	}
 	setClause := strings.Join(setClauses, ", ")
-	query := fmt.Sprintf(`UPDATE warehouses SET %s, updated_at=NOW(), version=version+1 WHERE id=$1 AND deleted_at IS NULL RETURNING name, code, address, province, latitude, longitude, is_active, version, created_at, updated_at`, setClause)
+	query := fmt.Sprintf(`UPDATE warehouses SET %s, updated_at=NOW(), version=version+1 WHERE id=$1 AND deleted_at IS NULL RETURNING name, code, address, province, latitude, longitude, is_active, safety_stock, allocation_priority, version, created_at, updated_at`, setClause)
 	var wh model.Warehouse
 	wh.ID = id
-	err := r.pool.QueryRow(ctx, query, args...).Scan(&wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt)
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.SafetyStock, &wh.AllocationPriority, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update warehouse: %w", err)
 	}
@@ -102,10 +104,10 @@ func (r *postgresRepository) SoftDeleteWarehouse(ctx context.Context, id uuid.UU
 }
 
 func (r *postgresRepository) GetWarehouseByID(ctx context.Context, id uuid.UUID) (*model.Warehouse, error) {
-	query := `SELECT id, name, code, address, province, latitude, longitude, is_active, version, created_at, updated_at, deleted_at
+	query := `SELECT id, name, code, address, province, latitude, longitude, is_active, safety_stock, allocation_priority, version, created_at, updated_at, deleted_at
             FROM warehouses WHERE id = $1 AND deleted_at IS NULL`
 	var wh model.Warehouse
-	err := r.pool.QueryRow(ctx, query, id).Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
+	err := r.pool.QueryRow(ctx, query, id).Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.SafetyStock, &wh.AllocationPriority, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
 	if err != nil {
 		return nil, fmt.Errorf("warehouse not found: %w", err)
 	}
@@ -113,10 +115,10 @@ func (r *postgresRepository) GetWarehouseByID(ctx context.Context, id uuid.UUID)
 }
 
 func (r *postgresRepository) GetWarehouseByCode(ctx context.Context, code string) (*model.Warehouse, error) {
-	query := `SELECT id, name, code, address, province, latitude, longitude, is_active, version, created_at, updated_at, deleted_at
+	query := `SELECT id, name, code, address, province, latitude, longitude, is_active, safety_stock, allocation_priority, version, created_at, updated_at, deleted_at
             FROM warehouses WHERE code = $1 AND deleted_at IS NULL`
 	var wh model.Warehouse
-	err := r.pool.QueryRow(ctx, query, code).Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
+	err := r.pool.QueryRow(ctx, query, code).Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.SafetyStock, &wh.AllocationPriority, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
 	if err != nil {
 		return nil, fmt.Errorf("warehouse code not found: %w", err)
 	}
@@ -144,7 +146,7 @@ func (r *postgresRepository) ListWarehouses(ctx context.Context, filter model.Li
 		idx++
 	}
 	whereStr := strings.Join(where, " AND ")
-	query := fmt.Sprintf(`SELECT id, name, code, address, province, latitude, longitude, is_active, version, created_at, updated_at, deleted_at
+	query := fmt.Sprintf(`SELECT id, name, code, address, province, latitude, longitude, is_active, safety_stock, allocation_priority, version, created_at, updated_at, deleted_at
  FROM warehouses WHERE %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, whereStr, idx, idx+1)
 	args = append(args, filter.Limit)
 	args = append(args, filter.Offset)
@@ -156,7 +158,7 @@ func (r *postgresRepository) ListWarehouses(ctx context.Context, filter model.Li
 	var result []model.Warehouse
 	for rows.Next() {
 		var wh model.Warehouse
-		err := rows.Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
+		err := rows.Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.SafetyStock, &wh.AllocationPriority, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
 		if err != nil {
 			continue
 		}
@@ -175,22 +177,30 @@ func (r *postgresRepository) HasInventory(ctx context.Context, warehouseID uuid.
 	return true, nil
 }
 
+// FindWarehousesWithStockByDistance kho còn đủ tồn cho đơn online, xếp theo khoảng cách đã áp ưu tiên
+// Tồn online = quantity - reserved - safety stock (override của sách, không có thì mặc định kho)
 func (r *postgresRepository) FindWarehousesWithStockByDistance(ctx context.Context, bookID uuid.UUID, lat float64, lon float64, requiredQty int) ([]model.WarehouseWithInventory, error) {
 	query := `
-    SELECT w.id, w.name, w.code, w.address, w.province, w.latitude, w.longitude,
-        w.is_active, w.version, w.created_at, w.updated_at, w.deleted_at,
-        (wi.quantity - wi.reserved) AS available_quantity,
-        (6371 * acos(
-          cos(radians($2)) * cos(radians(w.latitude)) * cos(radians(w.longitude) - radians($3)) +
-          sin(radians($2)) * sin(radians(w.latitude))
-        )) AS distance_km
-    FROM warehouses w
-    INNER JOIN warehouse_inventory wi ON w.id = wi.warehouse_id
-    WHERE w.is_active AND w.deleted_at IS NULL
-      AND w.latitude IS NOT NULL AND w.longitude IS NOT NULL
-      AND wi.book_id = $1
-      AND (wi.quantity - wi.reserved) >= $4
-    ORDER BY distance_km ASC`
+    SELECT id, name, code, address, province, latitude, longitude,
+        is_active, safety_stock, allocation_priority, version, created_at, updated_at, deleted_at,
+        available_quantity, distance_km,
+        distance_km * 100.0 / allocation_priority AS effective_distance_km
+    FROM (
+        SELECT w.id, w.name, w.code, w.address, w.province, w.latitude, w.longitude,
+            w.is_active, w.safety_stock, w.allocation_priority, w.version, w.created_at, w.updated_at, w.deleted_at,
+            (wi.quantity - wi.reserved - COALESCE(wi.safety_stock, w.safety_stock)) AS available_quantity,
+            (6371 * acos(LEAST(1.0,
+              cos(radians($2)) * cos(radians(w.latitude)) * cos(radians(w.longitude) - radians($3)) +
+              sin(radians($2)) * sin(radians(w.latitude))
+            ))) AS distance_km
+        FROM warehouses w
+        INNER JOIN warehouse_inventory wi ON w.id = wi.warehouse_id
+        WHERE w.is_active AND w.deleted_at IS NULL
+          AND w.latitude IS NOT NULL AND w.longitude IS NOT NULL
+          AND wi.book_id = $1
+    ) candidates
+    WHERE available_quantity >= $4
+    ORDER BY effective_distance_km ASC, distance_km ASC`
 	rows, err := r.pool.Query(ctx, query, bookID, lat, lon, requiredQty)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find warehouse: %w", err)
@@ -202,8 +212,9 @@ func (r *postgresRepository) FindWarehousesWithStockByDistance(ctx context.Conte
 		var deletedAt *time.Time
 		err := rows.Scan(
 			&w.ID, &w.Name, &w.Code, &w.Address, &w.Province,
-			&w.Latitude, &w.Longitude, &w.IsActive, &w.Version, &w.CreatedAt, &w.UpdatedAt, &deletedAt,
-			&w.AvailableQuantity, &w.DistanceKm,
+			&w.Latitude, &w.Longitude, &w.IsActive, &w.SafetyStock, &w.AllocationPriority,
+			&w.Version, &w.CreatedAt, &w.UpdatedAt, &deletedAt,
+			&w.AvailableQuantity, &w.DistanceKm, &w.EffectiveDistanceKm,
 		)
 		if err != nil {
 			continue
@@ -215,7 +226,7 @@ func (r *postgresRepository) FindWarehousesWithStockByDistance(ctx context.Conte
 }
 
 func (r *postgresRepository) ListActiveWarehouses(ctx context.Context) ([]model.Warehouse, error) {
-	query := `SELECT id, name, code, address, province, latitude, longitude, is_active, version, created_at, updated_at, deleted_at
+	query := `SELECT id, name, code, address, province, latitude, longitude, is_active, safety_stock, allocation_priority, version, created_at, updated_at, deleted_at
             FROM warehouses WHERE is_active = TRUE AND deleted_at IS NULL`
 	rows, err := r.pool.Query(ctx, query)
 	if err != nil {
@@ -225,7 +236,7 @@ func (r *postgresRepository) ListActiveWarehouses(ctx context.Context) ([]model.
 	var result []model.Warehouse
 	for rows.Next() {
 		var wh model.Warehouse
-		err := rows.Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
+		err := rows.Scan(&wh.ID, &wh.Name, &wh.Code, &wh.Address, &wh.Province, &wh.Latitude, &wh.Longitude, &wh.IsActive, &wh.SafetyStock, &wh.AllocationPriority, &wh.Version, &wh.CreatedAt, &wh.UpdatedAt, &wh.DeletedAt)
 		if err != nil {
 			continue
 		}
@@ -233,3 +244,122 @@ func (r *postgresRepository) ListActiveWarehouses(ctx context.Context) ([]model.
 	}
 	return result, nil
 }
+
+func (r *postgresRepository) AssignStaff(ctx context.Context, warehouseID, userID uuid.UUID, assignedBy *uuid.UUID) error {
+	query := `INSERT INTO warehouse_staff_assignments (user_id, warehouse_id, assigned_by)
+    VALUES ($1, $2, $3)
+    ON CONFLICT (user_id, warehouse_id) DO NOTHING`
+	if _, err := r.pool.Exec(ctx, query, userID, warehouseID, assignedBy); err != nil {
+		return fmt.Errorf("failed to assign staff: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) UnassignStaff(ctx context.Context, warehouseID, userID uuid.UUID) error {
+	query := `DELETE FROM warehouse_staff_assignments WHERE user_id=$1 AND warehouse_id=$2`
+	result, err := r.pool.Exec(ctx, query, userID, warehouseID)
+	if err != nil {
+		return fmt.Errorf("failed to unassign staff: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("staff assignment not found")
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListStaff(ctx context.Context, warehouseID uuid.UUID) ([]model.StaffAssignment, error) {
+	query := `SELECT user_id, warehouse_id, assigned_by, created_at
+            FROM warehouse_staff_assignments WHERE warehouse_id = $1 ORDER BY created_at`
+	rows, err := r.pool.Query(ctx, query, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouse staff: %w", err)
+	}
+	defer rows.Close()
+	result := make([]model.StaffAssignment, 0)
+	for rows.Next() {
+		var a model.StaffAssignment
+		if err := rows.Scan(&a.UserID, &a.WarehouseID, &a.AssignedBy, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan staff assignment: %w", err)
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+func (r *postgresRepository) ListAssignedWarehouseIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT a.warehouse_id
+            FROM warehouse_staff_assignments a
+            INNER JOIN warehouses w ON w.id = a.warehouse_id
+            WHERE a.user_id = $1 AND w.deleted_at IS NULL`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assigned warehouses: %w", err)
+	}
+	defer rows.Close()
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan warehouse id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetBookSafetyStock safety stock hiệu lực + tồn online của 1 sách tại kho
+func (r *postgresRepository) GetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID) (*model.BookSafetyStock, error) {
+	query := `SELECT wi.safety_stock, w.safety_stock, wi.quantity, wi.reserved
+            FROM warehouse_inventory wi
+            INNER JOIN warehouses w ON w.id = wi.warehouse_id
+            WHERE wi.warehouse_id = $1 AND wi.book_id = $2 AND w.deleted_at IS NULL`
+	res := model.BookSafetyStock{WarehouseID: warehouseID, BookID: bookID}
+	err := r.pool.QueryRow(ctx, query, warehouseID, bookID).
+		Scan(&res.Override, &res.WarehouseDefault, &res.Quantity, &res.Reserved)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrInventoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get safety stock: %w", err)
+	}
+	res.EffectiveSafetyStock = res.WarehouseDefault
+	if res.Override != nil {
+		res.EffectiveSafetyStock = *res.Override
+	}
+	res.OnlineAvailable = res.Quantity - res.Reserved - res.EffectiveSafetyStock
+	if res.OnlineAvailable < 0 {
+		res.OnlineAvailable = 0
+	}
+	return &res, nil
+}
+
+// UpdateAllocationSettings cập nhật safety stock mặc định / trọng số ưu tiên của kho
+func (r *postgresRepository) UpdateAllocationSettings(ctx context.Context, id uuid.UUID, req model.UpdateAllocationSettingsRequest) error {
+	query := `UPDATE warehouses
+            SET safety_stock = COALESCE($2, safety_stock),
+                allocation_priority = COALESCE($3, allocation_priority),
+                updated_at = NOW(), version = version + 1
+            WHERE id = $1 AND deleted_at IS NULL`
+	result, err := r.pool.Exec(ctx, query, id, req.SafetyStock, req.AllocationPriority)
+	if err != nil {
+		return fmt.Errorf("failed to update allocation settings: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrWarehouseNotFound
+	}
+	return nil
+}
+
+// SetBookSafetyStock đặt / bỏ (nil) override safety stock cho 1 sách tại kho
+func (r *postgresRepository) SetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID, safetyStock *int) error {
+	query := `UPDATE warehouse_inventory SET safety_stock = $3, updated_at = NOW()
+            WHERE warehouse_id = $1 AND book_id = $2`
+	result, err := r.pool.Exec(ctx, query, warehouseID, bookID, safetyStock)
+	if err != nil {
+		return fmt.Errorf("failed to set safety stock: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrInventoryNotFound
+	}
+	return nil
+}
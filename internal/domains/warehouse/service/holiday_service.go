@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bookstore-backend/internal/domains/warehouse/model"
+	"bookstore-backend/internal/domains/warehouse/repository"
+	"bookstore-backend/pkg/logger"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HolidayService interface {
+	// CRUD admin
+	CreateHoliday(ctx context.Context, req model.CreateHolidayRequest, createdBy *uuid.UUID) (*model.Holiday, error)
+	UpdateHoliday(ctx context.Context, id uuid.UUID, req model.UpdateHolidayRequest) (*model.Holiday, error)
+	DeleteHoliday(ctx context.Context, id uuid.UUID) error
+	ListHolidays(ctx context.Context, filter model.ListHolidaysFilter) ([]model.Holiday, error)
+
+	// SeedNationalHolidays sinh ngày lễ quốc gia VN của năm (idempotent, năm đã seed thì bỏ qua)
+	SeedNationalHolidays(ctx context.Context, year int) (*model.SeedHolidaysResult, error)
+
+	// CalendarFor lịch nghỉ sắp tới của kho (cache), dùng cho ước tính giao hàng
+	// warehouseID nil = chưa chọn kho → chỉ ngày nghỉ toàn quốc
+	CalendarFor(ctx context.Context, warehouseID *uuid.UUID, province string) (model.HolidayCalendar, error)
+
+	// GetProcessingSLAReport đơn quá hạn xử lý; hạn = mốc bắt đầu + N ngày làm việc theo lịch của kho
+	GetProcessingSLAReport(ctx context.Context) (*model.ProcessingSLAReport, error)
+}
+
+const (
+	// Lịch cache cho ước tính giao hàng: từ hôm qua → 90 ngày tới (đủ cho "5-7 days" + Tết)
+	upcomingHolidayWindowDays = 90
+	upcomingHolidayCacheTTL   = 10 * time.Minute
+
+	// Số đơn chờ giao tối đa quét mỗi lần lập báo cáo SLA (cũ nhất trước)
+	maxSLAScanOrders = 1000
+)
+
+type holidayService struct {
+	repo repository.HolidayRepository
+
+	// Số ngày làm việc từ lúc thanh toán / đặt COD tới lúc bàn giao vận chuyển (<= 0: tắt theo dõi SLA)
+	processingSLADays int
+
+	mu         sync.RWMutex
+	upcoming   []model.Holiday
+	upcomingAt time.Time
+}
+
+func NewHolidayService(repo repository.HolidayRepository, processingSLADays int) HolidayService {
+	return &holidayService{
+		repo:              repo,
+		processingSLADays: processingSLADays,
+	}
+}
+
+func (s *holidayService) CreateHoliday(ctx context.Context, req model.CreateHolidayRequest, createdBy *uuid.UUID) (*model.Holiday, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	holiday, err := s.repo.Create(ctx, req, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return holiday, nil
+}
+
+func (s *holidayService) UpdateHoliday(ctx context.Context, id uuid.UUID, req model.UpdateHolidayRequest) (*model.Holiday, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	holiday, err := s.repo.Update(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return holiday, nil
+}
+
+func (s *holidayService) DeleteHoliday(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *holidayService) ListHolidays(ctx context.Context, filter model.ListHolidaysFilter) ([]model.Holiday, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *holidayService) SeedNationalHolidays(ctx context.Context, year int) (*model.SeedHolidaysResult, error) {
+	if year < model.MinHolidaySeedYear || year > model.MaxHolidaySeedYear {
+		return nil, fmt.Errorf("%w: year must be in [%d, %d]", model.ErrInvalidHoliday, model.MinHolidaySeedYear, model.MaxHolidaySeedYear)
+	}
+
+	inserted, skipped, err := s.repo.SeedNational(ctx, year, model.VietnamNationalHolidays(year))
+	if err != nil {
+		return nil, err
+	}
+	if inserted > 0 {
+		s.invalidate()
+	}
+
+	logger.Info("National holidays seeded", map[string]interface{}{
+		"year":     year,
+		"inserted": inserted,
+		"skipped":  skipped,
+	})
+
+	return &model.SeedHolidaysResult{Year: year, Inserted: inserted, Skipped: skipped}, nil
+}
+
+func (s *holidayService) CalendarFor(ctx context.Context, warehouseID *uuid.UUID, province string) (model.HolidayCalendar, error) {
+	holidays, err := s.upcomingHolidays(ctx)
+	if err != nil {
+		return model.HolidayCalendar{}, err
+	}
+	return model.NewHolidayCalendar(holidays, warehouseID, province), nil
+}
+
+// upcomingHolidays ngày nghỉ mọi phạm vi trong cửa sổ sắp tới, reload khi hết TTL
+// Slice trả về là snapshot read-only, caller không được sửa
+func (s *holidayService) upcomingHolidays(ctx context.Context) ([]model.Holiday, error) {
+	s.mu.RLock()
+	if s.upcoming != nil && time.Since(s.upcomingAt) < upcomingHolidayCacheTTL {
+		holidays := s.upcoming
+		s.mu.RUnlock()
+		return holidays, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.upcoming != nil && time.Since(s.upcomingAt) < upcomingHolidayCacheTTL {
+		return s.upcoming, nil
+	}
+
+	now := time.Now()
+	holidays, err := s.repo.ListInRange(ctx, now.AddDate(0, 0, -1), now.AddDate(0, 0, upcomingHolidayWindowDays))
+	if err != nil {
+		return nil, err
+	}
+
+	s.upcoming = holidays
+	s.upcomingAt = now
+	return holidays, nil
+}
+
+// invalidate buộc lần đọc lịch tiếp theo reload từ DB (gọi sau mutation của admin / seed)
+func (s *holidayService) invalidate() {
+	s.mu.Lock()
+	s.upcoming = nil
+	s.mu.Unlock()
+}
+
+func (s *holidayService) GetProcessingSLAReport(ctx context.Context) (*model.ProcessingSLAReport, error) {
+	now := time.Now()
+	report := &model.ProcessingSLAReport{
+		SLADays:     s.processingSLADays,
+		GeneratedAt: now,
+		Breaches:    make([]model.ProcessingSLABreach, 0),
+	}
+	if s.processingSLADays <= 0 {
+		return report, nil
+	}
+
+	orders, err := s.repo.ListPendingShipmentOrders(ctx, maxSLAScanOrders)
+	if err != nil {
+		return nil, err
+	}
+	report.AwaitingOrders = len(orders)
+	if len(orders) == 0 {
+		return report, nil
+	}
+
+	// Orders sort theo sla_started_at tăng dần → đơn đầu tiên là mốc sớm nhất.
+	// Chỉ cần ngày nghỉ tới hiện tại: hạn vượt qua "now" thì chưa trễ dù sau đó còn ngày nghỉ
+	holidays, err := s.repo.ListInRange(ctx, orders[0].SLAStartedAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range orders {
+		calendar := model.NewHolidayCalendar(holidays, o.WarehouseID, o.Province)
+		dueAt, skipped := calendar.AddWorkingDays(o.SLAStartedAt, s.processingSLADays)
+		if !now.After(dueAt) {
+			continue
+		}
+		report.Breaches = append(report.Breaches, model.ProcessingSLABreach{
+			OrderID:         o.OrderID,
+			OrderNumber:     o.OrderNumber,
+			Status:          o.Status,
+			WarehouseID:     o.WarehouseID,
+			WarehouseName:   o.WarehouseName,
+			SLAStartedAt:    o.SLAStartedAt,
+			DueAt:           dueAt,
+			HolidaysSkipped: skipped,
+			OverdueHours:    int(now.Sub(dueAt).Hours()),
+		})
+	}
+
+	return report, nil
+}
@@ -18,6 +18,17 @@ type Service interface {
 	ListActiveWarehouses(ctx context.Context) ([]model.Warehouse, error)
 	// Lookup kho gần nhất có hàng
 	FindNearestWarehouseWithStock(ctx context.Context, bookID uuid.UUID, lat float64, lon float64, requiredQty int) (*model.WarehouseWithInventory, error)
+	// Tất cả kho đủ tồn online cho sách, xếp theo khoảng cách đã áp ưu tiên (dùng khi tách đơn nhiều kho)
+	ListWarehousesWithStock(ctx context.Context, bookID uuid.UUID, lat float64, lon float64, requiredQty int) ([]model.WarehouseWithInventory, error)
 	// Validate kho cho order
 	ValidateWarehouseHasStock(ctx context.Context, warehouseID, bookID uuid.UUID, requiredQty int) (bool, error)
+	// Safety stock (giữ tồn cho nhận tại cửa hàng) + trọng số ưu tiên kho (admin)
+	UpdateAllocationSettings(ctx context.Context, id uuid.UUID, req model.UpdateAllocationSettingsRequest) (*model.Warehouse, error)
+	GetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID) (*model.BookSafetyStock, error)
+	SetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID, req model.SetBookSafetyStockRequest) (*model.BookSafetyStock, error)
+	// Phân quyền nhân viên kho (staff chỉ thao tác trên kho được gán)
+	AssignStaff(ctx context.Context, warehouseID, userID uuid.UUID, assignedBy *uuid.UUID) error
+	UnassignStaff(ctx context.Context, warehouseID, userID uuid.UUID) error
+	ListStaff(ctx context.Context, warehouseID uuid.UUID) ([]model.StaffAssignment, error)
+	ListAssignedWarehouseIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
 }
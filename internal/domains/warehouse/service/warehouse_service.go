@@ -52,7 +52,7 @@ func (s *warehouseService) ListActiveWarehouses(ctx context.Context) ([]model.Wa
 	return s.repo.ListActiveWarehouses(ctx)
 }
 
-// Trả về kho gần nhất còn hàng
+// Trả về kho ưu tiên nhất còn đủ tồn online (khoảng cách đã áp allocation_priority)
 func (s *warehouseService) FindNearestWarehouseWithStock(ctx context.Context, bookID uuid.UUID, lat float64, lon float64, requiredQty int) (*model.WarehouseWithInventory, error) {
 	list, err := s.repo.FindWarehousesWithStockByDistance(ctx, bookID, lat, lon, requiredQty)
 	if err != nil {
@@ -64,17 +64,65 @@ func (s *warehouseService) FindNearestWarehouseWithStock(ctx context.Context, bo
 	return &list[0], nil
 }
 
+// ListWarehousesWithStock mọi kho còn đủ tồn online, kho ưu tiên nhất đứng đầu
+func (s *warehouseService) ListWarehousesWithStock(ctx context.Context, bookID uuid.UUID, lat float64, lon float64, requiredQty int) ([]model.WarehouseWithInventory, error) {
+	return s.repo.FindWarehousesWithStockByDistance(ctx, bookID, lat, lon, requiredQty)
+}
+
+// ValidateWarehouseHasStock kho đủ tồn online (đã trừ reserved + safety stock) cho số lượng yêu cầu
 func (s *warehouseService) ValidateWarehouseHasStock(ctx context.Context, warehouseID, bookID uuid.UUID, requiredQty int) (bool, error) {
-	// Đơn giản: query toàn bộ kho còn hàng, check xem warehouseID có nằm trong này không
-	// (có thể optimize riêng nhưng cách này đủ chắc)
-	list, err := s.repo.FindWarehousesWithStockByDistance(ctx, bookID, 0, 0, requiredQty)
+	stock, err := s.repo.GetBookSafetyStock(ctx, warehouseID, bookID)
 	if err != nil {
+		if errors.Is(err, model.ErrInventoryNotFound) {
+			return false, nil
+		}
 		return false, err
 	}
-	for _, w := range list {
-		if w.ID == warehouseID && w.AvailableQuantity >= requiredQty {
-			return true, nil
-		}
+	return stock.OnlineAvailable >= requiredQty, nil
+}
+
+// UpdateAllocationSettings admin chỉnh safety stock mặc định / trọng số ưu tiên của kho
+func (s *warehouseService) UpdateAllocationSettings(ctx context.Context, id uuid.UUID, req model.UpdateAllocationSettingsRequest) (*model.Warehouse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateAllocationSettings(ctx, id, req); err != nil {
+		return nil, err
+	}
+	return s.repo.GetWarehouseByID(ctx, id)
+}
+
+func (s *warehouseService) GetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID) (*model.BookSafetyStock, error) {
+	return s.repo.GetBookSafetyStock(ctx, warehouseID, bookID)
+}
+
+// SetBookSafetyStock đặt / bỏ override safety stock cho sách bán chạy tại kho
+func (s *warehouseService) SetBookSafetyStock(ctx context.Context, warehouseID, bookID uuid.UUID, req model.SetBookSafetyStockRequest) (*model.BookSafetyStock, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetBookSafetyStock(ctx, warehouseID, bookID, req.SafetyStock); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBookSafetyStock(ctx, warehouseID, bookID)
+}
+
+func (s *warehouseService) AssignStaff(ctx context.Context, warehouseID, userID uuid.UUID, assignedBy *uuid.UUID) error {
+	// Kho phải tồn tại (chưa bị xóa mềm) mới cho gán
+	if _, err := s.repo.GetWarehouseByID(ctx, warehouseID); err != nil {
+		return err
 	}
-	return false, nil
+	return s.repo.AssignStaff(ctx, warehouseID, userID, assignedBy)
+}
+
+func (s *warehouseService) UnassignStaff(ctx context.Context, warehouseID, userID uuid.UUID) error {
+	return s.repo.UnassignStaff(ctx, warehouseID, userID)
+}
+
+func (s *warehouseService) ListStaff(ctx context.Context, warehouseID uuid.UUID) ([]model.StaffAssignment, error) {
+	return s.repo.ListStaff(ctx, warehouseID)
+}
+
+func (s *warehouseService) ListAssignedWarehouseIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.repo.ListAssignedWarehouseIDs(ctx, userID)
 }
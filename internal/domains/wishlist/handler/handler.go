@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/wishlist/model"
+	"bookstore-backend/internal/domains/wishlist/service"
+	"bookstore-backend/internal/shared/middleware"
+	"bookstore-backend/internal/shared/response"
+)
+
+// =====================================================
+// WISHLIST HANDLER
+// =====================================================
+// Route chạy OptionalAuthMiddleware + CartMiddleware:
+// user đã login → wishlist của user, guest → wishlist theo cookie session_id
+
+type WishlistHandler struct {
+	wishlistService service.ServiceInterface
+}
+
+func NewWishlistHandler(wishlistService service.ServiceInterface) *WishlistHandler {
+	return &WishlistHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+// ListWishlist - GET /wishlist
+func (h *WishlistHandler) ListWishlist(c *gin.Context) {
+	owner, ok := currentOwner(c)
+	if !ok {
+		return
+	}
+
+	wishlist, err := h.wishlistService.ListWishlist(c.Request.Context(), owner)
+	if err != nil {
+		respondWishlistError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Get wishlist successfully", wishlist)
+}
+
+// AddItem - POST /wishlist/items
+func (h *WishlistHandler) AddItem(c *gin.Context) {
+	owner, ok := currentOwner(c)
+	if !ok {
+		return
+	}
+
+	var req model.AddItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+		return
+	}
+
+	if err := h.wishlistService.AddItem(c.Request.Context(), owner, req); err != nil {
+		respondWishlistError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Added to wishlist", nil)
+}
+
+// RemoveItem - DELETE /wishlist/items/:book_id
+func (h *WishlistHandler) RemoveItem(c *gin.Context) {
+	owner, ok := currentOwner(c)
+	if !ok {
+		return
+	}
+
+	bookID, ok := parseBookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.wishlistService.RemoveItem(c.Request.Context(), owner, bookID); err != nil {
+		respondWishlistError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Removed from wishlist", nil)
+}
+
+// MoveToCart - POST /wishlist/items/:book_id/move-to-cart
+func (h *WishlistHandler) MoveToCart(c *gin.Context) {
+	owner, ok := currentOwner(c)
+	if !ok {
+		return
+	}
+
+	bookID, ok := parseBookID(c)
+	if !ok {
+		return
+	}
+
+	cartID, err := middleware.GetCartID(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid cart", err.Error())
+		return
+	}
+
+	var req model.MoveToCartRequest
+	// Body rỗng = 1 cuốn
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+	}
+
+	item, err := h.wishlistService.MoveToCart(c.Request.Context(), owner, cartID, bookID, req)
+	if err != nil {
+		respondWishlistError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Item moved to cart", item)
+}
+
+// MergeWishlist - POST /wishlist/merge (cần đăng nhập)
+// Client gọi ngay sau login nếu flow login không tự merge (vd. login qua OAuth)
+func (h *WishlistHandler) MergeWishlist(c *gin.Context) {
+	userID, ok := middleware.GetAuthenticatedUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized", "user not authenticated")
+		return
+	}
+
+	var req model.MergeWishlistRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+	}
+	if req.SessionID == "" {
+		req.SessionID = middleware.GetSessionIDFromCookie(c)
+	}
+
+	result, err := h.wishlistService.MergeWishlist(c.Request.Context(), req.SessionID, *userID)
+	if err != nil {
+		respondWishlistError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Wishlist merged successfully", result)
+}
+
+// =====================================================
+// HELPER FUNCTIONS
+// =====================================================
+
+// currentOwner user đã login, nếu không thì session ẩn danh do CartMiddleware gắn
+func currentOwner(c *gin.Context) (model.Owner, bool) {
+	if userID, ok := middleware.GetAuthenticatedUserID(c); ok {
+		return model.Owner{UserID: userID}, true
+	}
+
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid session", "missing session")
+		return model.Owner{}, false
+	}
+	return model.Owner{SessionID: sessionID}, true
+}
+
+func parseBookID(c *gin.Context) (uuid.UUID, bool) {
+	bookID, err := uuid.Parse(c.Param("book_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid book id", err.Error())
+		return uuid.Nil, false
+	}
+	return bookID, true
+}
+
+// respondWishlistError maps wishlist error to HTTP status code
+func respondWishlistError(c *gin.Context, err error) {
+	var wErr *model.WishlistError
+	if !errors.As(err, &wErr) {
+		response.Error(c, http.StatusInternalServerError, "Internal server error", err.Error())
+		return
+	}
+
+	switch wErr.Code {
+	case model.ErrCodeItemNotFound, model.ErrCodeBookUnavailable:
+		response.Error(c, http.StatusNotFound, wErr.Message, wErr.Code)
+	case model.ErrCodeWishlistFull, model.ErrCodeMoveToCart:
+		response.Error(c, http.StatusConflict, wErr.Message, wErr.Error())
+	default:
+		response.Error(c, http.StatusBadRequest, wErr.Message, wErr.Code)
+	}
+}
@@ -0,0 +1,8 @@
+package model
+
+// Limits
+const (
+	MaxWishlistItems    = 200 // chặn wishlist phình vô hạn (guest bot, script)
+	DefaultMoveQuantity = 1
+	MaxMoveQuantity     = 100 // = cart MaxItemsPerProduct, CartService.AddItem kiểm tra lại
+)
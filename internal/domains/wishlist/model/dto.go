@@ -0,0 +1,96 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// =====================================================
+// REQUEST DTOs
+// =====================================================
+
+// AddItemRequest thêm sách vào wishlist
+type AddItemRequest struct {
+	BookID uuid.UUID `json:"book_id" binding:"required"`
+}
+
+// MoveToCartRequest chuyển sách từ wishlist sang giỏ (mặc định 1 cuốn)
+type MoveToCartRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+func (r *MoveToCartRequest) Validate() error {
+	if r.Quantity == 0 {
+		r.Quantity = DefaultMoveQuantity
+	}
+	if r.Quantity < 1 || r.Quantity > MaxMoveQuantity {
+		return NewInvalidRequestError(fmt.Sprintf("quantity must be between 1 and %d", MaxMoveQuantity))
+	}
+	return nil
+}
+
+// MergeWishlistRequest merge wishlist guest vào user sau login
+// session_id rỗng → đọc cookie session_id
+type MergeWishlistRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// =====================================================
+// RESPONSE DTOs
+// =====================================================
+
+// WishlistResponse danh sách wishlist
+type WishlistResponse struct {
+	Items      []WishlistItemResponse `json:"items"`
+	TotalItems int                    `json:"total_items"`
+	IsGuest    bool                   `json:"is_guest"`
+}
+
+// WishlistItemResponse 1 sách + giá / tồn hiện tại
+type WishlistItemResponse struct {
+	BookID         uuid.UUID        `json:"book_id"`
+	Title          string           `json:"title"`
+	Slug           string           `json:"slug"`
+	CoverURL       *string          `json:"cover_url,omitempty"`
+	AuthorName     string           `json:"author_name"`
+	CurrentPrice   decimal.Decimal  `json:"current_price"`
+	PriceWhenAdded *decimal.Decimal `json:"price_when_added,omitempty"`
+	PriceDrop      *decimal.Decimal `json:"price_drop,omitempty"` // > 0 khi giá giảm từ lúc thêm
+	IsActive       bool             `json:"is_active"`
+	AvailableStock int              `json:"available_stock"`
+	InStock        bool             `json:"in_stock"`
+	AddedAt        time.Time        `json:"added_at"`
+}
+
+// MergeWishlistResponse số sách chuyển từ wishlist guest
+type MergeWishlistResponse struct {
+	Merged int `json:"merged"`
+}
+
+// =====================================================
+// MAPPERS
+// =====================================================
+
+func (i *WishlistItem) ToResponse() WishlistItemResponse {
+	response := WishlistItemResponse{
+		BookID:         i.BookID,
+		Title:          i.Title,
+		Slug:           i.Slug,
+		CoverURL:       i.CoverURL,
+		AuthorName:     i.AuthorName,
+		CurrentPrice:   i.CurrentPrice,
+		PriceWhenAdded: i.PriceWhenAdded,
+		IsActive:       i.IsActive,
+		AvailableStock: i.AvailableStock,
+		InStock:        i.IsActive && i.AvailableStock > 0,
+		AddedAt:        i.AddedAt,
+	}
+	if i.PriceWhenAdded != nil && i.PriceWhenAdded.GreaterThan(i.CurrentPrice) {
+		drop := i.PriceWhenAdded.Sub(i.CurrentPrice)
+		response.PriceDrop = &drop
+	}
+	return response
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Owner - chủ wishlist: user đã đăng nhập hoặc session ẩn danh (cookie session_id)
+type Owner struct {
+	UserID    *uuid.UUID
+	SessionID string
+}
+
+// IsGuest wishlist lưu theo session (guest_wishlist_items)
+func (o Owner) IsGuest() bool {
+	return o.UserID == nil
+}
+
+// WishlistItem - 1 sách trong wishlist kèm snapshot giá + tồn lúc đọc
+type WishlistItem struct {
+	BookID         uuid.UUID
+	Title          string
+	Slug           string
+	CoverURL       *string
+	AuthorName     string
+	CurrentPrice   decimal.Decimal
+	PriceWhenAdded *decimal.Decimal // NULL cho dòng thêm trước khi lưu giá
+	IsActive       bool
+	AvailableStock int
+	AddedAt        time.Time
+}
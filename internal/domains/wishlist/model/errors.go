@@ -0,0 +1,84 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error codes
+const (
+	ErrCodeItemNotFound    = "WSH001"
+	ErrCodeInvalidRequest  = "WSH002"
+	ErrCodeBookUnavailable = "WSH003"
+	ErrCodeWishlistFull    = "WSH004"
+	ErrCodeMoveToCart      = "WSH005"
+)
+
+// Errors
+var (
+	ErrItemNotFound    = errors.New("wishlist item not found")
+	ErrInvalidRequest  = errors.New("invalid wishlist request")
+	ErrBookUnavailable = errors.New("book unavailable")
+	ErrWishlistFull    = errors.New("wishlist is full")
+	ErrMoveToCart      = errors.New("failed to move item to cart")
+)
+
+// WishlistError custom error type
+type WishlistError struct {
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *WishlistError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *WishlistError) Unwrap() error {
+	return e.Err
+}
+
+// Error constructors
+func NewItemNotFoundError() *WishlistError {
+	return &WishlistError{
+		Code:    ErrCodeItemNotFound,
+		Message: "Book is not in wishlist",
+		Err:     ErrItemNotFound,
+	}
+}
+
+func NewInvalidRequestError(message string) *WishlistError {
+	return &WishlistError{
+		Code:    ErrCodeInvalidRequest,
+		Message: message,
+		Err:     ErrInvalidRequest,
+	}
+}
+
+func NewBookUnavailableError(bookID string) *WishlistError {
+	return &WishlistError{
+		Code:    ErrCodeBookUnavailable,
+		Message: fmt.Sprintf("Book is not available: %s", bookID),
+		Err:     ErrBookUnavailable,
+	}
+}
+
+func NewWishlistFullError() *WishlistError {
+	return &WishlistError{
+		Code:    ErrCodeWishlistFull,
+		Message: fmt.Sprintf("Wishlist can hold at most %d books", MaxWishlistItems),
+		Err:     ErrWishlistFull,
+	}
+}
+
+// NewMoveToCartError - CartService.AddItem từ chối (hết hàng, vượt số lượng, giỏ hết hạn)
+func NewMoveToCartError(err error) *WishlistError {
+	return &WishlistError{
+		Code:    ErrCodeMoveToCart,
+		Message: "Failed to move item to cart",
+		Err:     err,
+	}
+}
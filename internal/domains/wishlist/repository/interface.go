@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/domains/wishlist/model"
+)
+
+// =====================================================
+// WISHLIST REPOSITORY INTERFACE
+// =====================================================
+// User → bảng wishlists, guest → guest_wishlist_items (theo session_id)
+
+type WishlistRepository interface {
+	// ListItems lists wishlist with current price + available stock, newest first
+	ListItems(ctx context.Context, owner model.Owner) ([]model.WishlistItem, error)
+
+	// CountItems counts books in wishlist
+	CountItems(ctx context.Context, owner model.Owner) (int, error)
+
+	// HasItem checks book is in wishlist
+	HasItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (bool, error)
+
+	// GetItem sách trong wishlist kèm giá + thời điểm thêm (nil nếu không có)
+	GetItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (*model.WishlistItem, error)
+
+	// AddItem thêm sách kèm giá hiện tại, đã có thì giữ nguyên (trả false)
+	AddItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (bool, error)
+
+	// RemoveItem trả false nếu sách không có trong wishlist
+	RemoveItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (bool, error)
+
+	// MergeGuestItems chuyển wishlist của session sang user rồi xoá bản guest (1 transaction)
+	// Sách user đã có giữ nguyên dòng của user (giá + thời điểm thêm cũ hơn)
+	MergeGuestItems(ctx context.Context, sessionID string, userID uuid.UUID) (int, error)
+
+	// IsBookAvailable sách còn bán (active, chưa xoá mềm)
+	IsBookAvailable(ctx context.Context, bookID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/internal/domains/wishlist/model"
+	"bookstore-backend/pkg/database"
+)
+
+// =====================================================
+// POSTGRES REPOSITORY IMPLEMENTATION
+// =====================================================
+
+type postgresWishlistRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresWishlistRepository(pool *pgxpool.Pool) WishlistRepository {
+	return &postgresWishlistRepository{pool: pool}
+}
+
+// ownerTable bảng + cột khoá chủ wishlist
+func ownerTable(owner model.Owner) (string, string, interface{}) {
+	if owner.IsGuest() {
+		return "guest_wishlist_items", "session_id", owner.SessionID
+	}
+	return "wishlists", "user_id", *owner.UserID
+}
+
+func (r *postgresWishlistRepository) ListItems(ctx context.Context, owner model.Owner) ([]model.WishlistItem, error) {
+	ctx, cancel := database.WithReadTimeout(ctx)
+	defer cancel()
+
+	table, column, arg := ownerTable(owner)
+	query := fmt.Sprintf(`
+		SELECT b.id, b.title, b.slug, b.cover_url, COALESCE(a.name, ''),
+			b.price, w.price_when_added, b.is_active,
+			GREATEST(COALESCE(s.available, 0), 0), w.created_at
+		FROM %s w
+		JOIN books b ON b.id = w.book_id
+		LEFT JOIN authors a ON a.id = b.author_id
+		LEFT JOIN books_total_stock s ON s.book_id = b.id
+		WHERE w.%s = $1 AND b.deleted_at IS NULL
+		ORDER BY w.created_at DESC
+	`, table, column)
+
+	rows, err := r.pool.Query(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wishlist: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.WishlistItem{}
+	for rows.Next() {
+		var item model.WishlistItem
+		if err := rows.Scan(
+			&item.BookID,
+			&item.Title,
+			&item.Slug,
+			&item.CoverURL,
+			&item.AuthorName,
+			&item.CurrentPrice,
+			&item.PriceWhenAdded,
+			&item.IsActive,
+			&item.AvailableStock,
+			&item.AddedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan wishlist item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *postgresWishlistRepository) CountItems(ctx context.Context, owner model.Owner) (int, error) {
+	table, column, arg := ownerTable(owner)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = $1`, table, column)
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, arg).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count wishlist: %w", err)
+	}
+	return count, nil
+}
+
+func (r *postgresWishlistRepository) HasItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (bool, error) {
+	table, column, arg := ownerTable(owner)
+	query := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE %s = $1 AND book_id = $2)`, table, column)
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, arg, bookID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check wishlist item: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *postgresWishlistRepository) GetItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (*model.WishlistItem, error) {
+	table, column, arg := ownerTable(owner)
+	query := fmt.Sprintf(`
+		SELECT w.book_id, b.price, w.price_when_added, b.is_active, w.created_at
+		FROM %s w
+		JOIN books b ON b.id = w.book_id
+		WHERE w.%s = $1 AND w.book_id = $2
+	`, table, column)
+
+	var item model.WishlistItem
+	err := r.pool.QueryRow(ctx, query, arg, bookID).Scan(
+		&item.BookID,
+		&item.CurrentPrice,
+		&item.PriceWhenAdded,
+		&item.IsActive,
+		&item.AddedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist item: %w", err)
+	}
+	return &item, nil
+}
+
+func (r *postgresWishlistRepository) AddItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (bool, error) {
+	table, column, arg := ownerTable(owner)
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s, book_id, price_when_added)
+		SELECT $1, b.id, b.price FROM books b WHERE b.id = $2
+		ON CONFLICT (%s, book_id) DO NOTHING
+	`, table, column, column)
+
+	result, err := r.pool.Exec(ctx, query, arg, bookID)
+	if err != nil {
+		return false, fmt.Errorf("failed to add to wishlist: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *postgresWishlistRepository) RemoveItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) (bool, error) {
+	table, column, arg := ownerTable(owner)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND book_id = $2`, table, column)
+
+	result, err := r.pool.Exec(ctx, query, arg, bookID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove from wishlist: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *postgresWishlistRepository) MergeGuestItems(ctx context.Context, sessionID string, userID uuid.UUID) (int, error) {
+	ctx, cancel := database.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	result, err := tx.Exec(ctx, `
+		INSERT INTO wishlists (user_id, book_id, price_when_added, created_at)
+		SELECT $2, book_id, price_when_added, created_at
+		FROM guest_wishlist_items
+		WHERE session_id = $1
+		ON CONFLICT (user_id, book_id) DO NOTHING
+	`, sessionID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge guest wishlist: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM guest_wishlist_items WHERE session_id = $1`, sessionID); err != nil {
+		return 0, fmt.Errorf("failed to clear guest wishlist: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit wishlist merge: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+func (r *postgresWishlistRepository) IsBookAvailable(ctx context.Context, bookID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM books WHERE id = $1 AND deleted_at IS NULL AND is_active = true)`
+
+	var exists bool
+	if err := r.pool.QueryRow(ctx, query, bookID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check book: %w", err)
+	}
+	return exists, nil
+}
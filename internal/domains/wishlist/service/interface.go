@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	cartModel "bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/domains/wishlist/model"
+)
+
+// =====================================================
+// WISHLIST SERVICE INTERFACE
+// =====================================================
+
+type ServiceInterface interface {
+	// ListWishlist lists books with current price / stock
+	ListWishlist(ctx context.Context, owner model.Owner) (*model.WishlistResponse, error)
+
+	// AddItem adds active book (idempotent)
+	AddItem(ctx context.Context, owner model.Owner, req model.AddItemRequest) error
+
+	// RemoveItem removes book from wishlist
+	RemoveItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) error
+
+	// MoveToCart thêm sách vào giỏ qua CartService.AddItem rồi bỏ khỏi wishlist
+	// User đăng nhập: sách thêm wishlist gần đây + giá đã tăng → giỏ giữ giá lúc thêm wishlist (nếu bật)
+	MoveToCart(ctx context.Context, owner model.Owner, cartID, bookID uuid.UUID, req model.MoveToCartRequest) (*cartModel.CartItemResponse, error)
+
+	// MergeWishlist merges guest wishlist (session) into user's wishlist after login
+	MergeWishlist(ctx context.Context, sessionID string, userID uuid.UUID) (*model.MergeWishlistResponse, error)
+}
+
+// CartItemAdder - CartService.AddItem (kiểm tra tồn, giới hạn số lượng, giá theo bậc)
+// + GrantWishlistPrice (giữ giá lúc thêm wishlist, cart tự kiểm điều kiện + quota)
+type CartItemAdder interface {
+	AddItem(ctx context.Context, cartID uuid.UUID, req cartModel.AddToCartRequest) (*cartModel.CartItemResponse, error)
+	GrantWishlistPrice(ctx context.Context, cartID, userID, bookID uuid.UUID, wishlistPrice decimal.Decimal, wishlistedAt time.Time) (*cartModel.PriceOverride, error)
+}
+
+// FeedInvalidator - wishlist là tín hiệu của feed "For you" (recommendation service)
+type FeedInvalidator interface {
+	InvalidateFeed(ctx context.Context, userID uuid.UUID)
+}
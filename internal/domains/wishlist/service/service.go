@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	cartModel "bookstore-backend/internal/domains/cart/model"
+	"bookstore-backend/internal/domains/wishlist/model"
+	"bookstore-backend/internal/domains/wishlist/repository"
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// SERVICE IMPLEMENTATION
+// =====================================================
+
+type wishlistService struct {
+	repo        repository.WishlistRepository
+	cartService CartItemAdder
+	feed        FeedInvalidator
+}
+
+func NewWishlistService(
+	repo repository.WishlistRepository,
+	cartService CartItemAdder,
+	feed FeedInvalidator,
+) ServiceInterface {
+	return &wishlistService{
+		repo:        repo,
+		cartService: cartService,
+		feed:        feed,
+	}
+}
+
+func (s *wishlistService) ListWishlist(ctx context.Context, owner model.Owner) (*model.WishlistResponse, error) {
+	items, err := s.repo.ListItems(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.WishlistItemResponse, len(items))
+	for i := range items {
+		responses[i] = items[i].ToResponse()
+	}
+
+	return &model.WishlistResponse{
+		Items:      responses,
+		TotalItems: len(responses),
+		IsGuest:    owner.IsGuest(),
+	}, nil
+}
+
+func (s *wishlistService) AddItem(ctx context.Context, owner model.Owner, req model.AddItemRequest) error {
+	available, err := s.repo.IsBookAvailable(ctx, req.BookID)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return model.NewBookUnavailableError(req.BookID.String())
+	}
+
+	count, err := s.repo.CountItems(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if count >= model.MaxWishlistItems {
+		// Sách đã có trong wishlist vẫn trả thành công (idempotent)
+		exists, err := s.repo.HasItem(ctx, owner, req.BookID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+		return model.NewWishlistFullError()
+	}
+
+	added, err := s.repo.AddItem(ctx, owner, req.BookID)
+	if err != nil {
+		return err
+	}
+	if added {
+		s.invalidateFeed(ctx, owner)
+	}
+	return nil
+}
+
+func (s *wishlistService) RemoveItem(ctx context.Context, owner model.Owner, bookID uuid.UUID) error {
+	removed, err := s.repo.RemoveItem(ctx, owner, bookID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return model.NewItemNotFoundError()
+	}
+
+	s.invalidateFeed(ctx, owner)
+	return nil
+}
+
+// MoveToCart - giỏ nhận trước, sau đó mới bỏ khỏi wishlist:
+// AddItem lỗi (hết hàng, vượt giới hạn) → sách vẫn nằm trong wishlist
+func (s *wishlistService) MoveToCart(
+	ctx context.Context,
+	owner model.Owner,
+	cartID, bookID uuid.UUID,
+	req model.MoveToCartRequest,
+) (*cartModel.CartItemResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	wished, err := s.repo.GetItem(ctx, owner, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if wished == nil {
+		return nil, model.NewItemNotFoundError()
+	}
+
+	// Giữ giá trước AddItem để dòng giỏ được tính giá giữ ngay. Lỗi cấp giá không chặn chuyển sách
+	if owner.UserID != nil && wished.PriceWhenAdded != nil {
+		if _, err := s.cartService.GrantWishlistPrice(ctx, cartID, *owner.UserID, bookID, *wished.PriceWhenAdded, wished.AddedAt); err != nil {
+			logger.Info("Failed to grant wishlist price guarantee", map[string]interface{}{
+				"book_id": bookID,
+				"cart_id": cartID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	item, err := s.cartService.AddItem(ctx, cartID, cartModel.AddToCartRequest{
+		BookID:   bookID,
+		Quantity: req.Quantity,
+	})
+	if err != nil {
+		return nil, model.NewMoveToCartError(err)
+	}
+
+	if _, err := s.repo.RemoveItem(ctx, owner, bookID); err != nil {
+		// Sách đã vào giỏ → không trả lỗi, lần sau khách tự xoá khỏi wishlist
+		logger.Info("Failed to remove moved item from wishlist", map[string]interface{}{
+			"book_id": bookID,
+			"cart_id": cartID,
+			"error":   err.Error(),
+		})
+	}
+
+	s.invalidateFeed(ctx, owner)
+	return item, nil
+}
+
+// MergeWishlist - như MergeCart: chạy sau login, session rỗng / không có wishlist → 0
+func (s *wishlistService) MergeWishlist(
+	ctx context.Context,
+	sessionID string,
+	userID uuid.UUID,
+) (*model.MergeWishlistResponse, error) {
+	if sessionID == "" {
+		return &model.MergeWishlistResponse{}, nil
+	}
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return nil, model.NewInvalidRequestError("session_id is invalid")
+	}
+
+	merged, err := s.repo.MergeGuestItems(ctx, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if merged > 0 {
+		s.feed.InvalidateFeed(ctx, userID)
+		logger.Info("Merged guest wishlist", map[string]interface{}{
+			"user_id": userID,
+			"merged":  merged,
+		})
+	}
+	return &model.MergeWishlistResponse{Merged: merged}, nil
+}
+
+// invalidateFeed chỉ áp dụng cho user (guest không có feed)
+func (s *wishlistService) invalidateFeed(ctx context.Context, owner model.Owner) {
+	if owner.UserID != nil {
+		s.feed.InvalidateFeed(ctx, *owner.UserID)
+	}
+}
@@ -0,0 +1,194 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"bookstore-backend/pkg/logger"
+)
+
+// =====================================================
+// CIRCUIT BREAKER
+// =====================================================
+// closed    → gọi DB / inventory bình thường, đếm lỗi hạ tầng liên tiếp
+// open      → chặn ngay (không chờ timeout), hết OpenTimeout thì cho 1 request thử
+// half-open → request thử thành công thì closed, lỗi thì open lại
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// ErrOpen - breaker đang mở, caller trả 503 + Retry-After thay vì gọi tiếp
+var ErrOpen = errors.New("service temporarily unavailable")
+
+// OpenError mang thời gian chờ trước khi thử lại; errors.Is(err, ErrOpen) == true
+type OpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s: %v (retry after %s)", e.Name, ErrOpen, e.RetryAfter)
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// Config - ngưỡng mở + thời gian mở
+type Config struct {
+	// Số lỗi hạ tầng liên tiếp thì mở (<= 0: mặc định 5)
+	FailureThreshold int
+	// Thời gian mở trước khi cho request thử (<= 0: mặc định 30s)
+	OpenTimeout time.Duration
+}
+
+type Breaker struct {
+	name   string
+	config Config
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool // half-open: đã có request thử đang chạy
+}
+
+func New(name string, config Config) *Breaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	return &Breaker{name: name, config: config, state: StateClosed}
+}
+
+// Allow trả *OpenError khi breaker mở; half-open chỉ cho 1 request thử tại 1 thời điểm
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.config.OpenTimeout {
+			return b.openError()
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return b.openError()
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record ghi kết quả của lần gọi đã được Allow; chỉ lỗi hạ tầng (IsUnavailable) mới tính
+// Lỗi nghiệp vụ (không tìm thấy, hết hàng...) chứng tỏ DB vẫn trả lời → coi như thành công
+func (b *Breaker) Record(err error) {
+	if err != nil && IsUnavailable(err) {
+		b.failure()
+		return
+	}
+	b.success()
+}
+
+// Do = Allow + fn + Record (luôn Record để half-open không kẹt ở trạng thái đang thử)
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.Record(err)
+	return err
+}
+
+// Degraded true khi breaker không ở closed (storefront đánh dấu tồn kho "unknown")
+func (b *Breaker) Degraded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state != StateClosed
+}
+
+// State trạng thái hiện tại (health check / log)
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+func (b *Breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.openedAt = time.Now()
+		if b.state != StateOpen {
+			b.setState(StateOpen)
+		}
+	}
+}
+
+func (b *Breaker) openError() *OpenError {
+	retryAfter := b.config.OpenTimeout - time.Since(b.openedAt)
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return &OpenError{Name: b.name, RetryAfter: retryAfter}
+}
+
+// setState gọi khi đang giữ mu
+func (b *Breaker) setState(state string) {
+	logger.Info("Circuit breaker state changed", map[string]interface{}{
+		"breaker":  b.name,
+		"from":     b.state,
+		"to":       state,
+		"failures": b.failures,
+	})
+	b.state = state
+}
+
+// IsUnavailable lỗi do DB / mạng không phản hồi (timeout, mất kết nối)
+// context.Canceled (client tự ngắt) không tính
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return true
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
-	"github.com/redis/go-redis/v9/maintnotifications"
 
 	// Import cache interface từ pkg
 	pkgCache "bookstore-backend/pkg/cache"
@@ -18,38 +18,28 @@ import (
 // RedisCache implements pkg/cache.Cache interface
 // Đổi tên từ RedisClient -> RedisCache để rõ ràng hơn
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
+	mode   pkgCache.RedisMode
 }
 
-// NewRedisCache tạo Redis cache instance
+// NewRedisCache tạo Redis cache instance theo topology (standalone / sentinel / cluster)
 // QUAN TRỌNG: Return pkg/cache.Cache interface, không phải concrete type
-func NewRedisCache(host, password string, db int) pkgCache.Cache {
-	client := redis.NewClient(&redis.Options{
-		Addr:         host,
-		Password:     password,
-		DB:           db,
-		PoolSize:     10, // Connection pool size
-		MinIdleConns: 5,  // Minimum idle connections
-		MaxRetries:   3,  // Retry failed commands
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		MaintNotificationsConfig: &maintnotifications.Config{
-			Mode: maintnotifications.ModeDisabled, // ✅ No warnings
-		},
-	})
+func NewRedisCache(opts pkgCache.RedisOptions) pkgCache.Cache {
+	client := pkgCache.NewRedisClient(opts)
+	client.AddHook(tracingHook{})
 
 	return &RedisCache{
 		client: client,
+		mode:   opts.Mode,
 	}
 }
 
 // Connect khởi tạo kết nối Redis (gọi khi startup)
 func (r *RedisCache) Connect(ctx context.Context) error {
-	log.Println("[REDIS] Connecting to Redis...")
+	log.Printf("[REDIS] Connecting to Redis (%s)...", r.mode)
 
-	// Ping để verify connection
-	if err := r.client.Ping(ctx).Err(); err != nil {
+	// Ping để verify connection (cluster: mọi shard)
+	if err := pkgCache.PingRedis(ctx, r.client); err != nil {
 		return fmt.Errorf("redis ping failed: %w", err)
 	}
 
@@ -67,7 +57,7 @@ func (r *RedisCache) HealthCheck(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	if err := r.client.Ping(ctx).Err(); err != nil {
+	if err := pkgCache.PingRedis(ctx, r.client); err != nil {
 		return fmt.Errorf("redis ping failed: %w", err)
 	}
 
@@ -148,7 +138,17 @@ func (r *RedisCache) Delete(ctx context.Context, keys ...string) error {
 		return nil
 	}
 
-	err := r.client.Del(ctx, keys...).Err()
+	// Cluster: DEL nhiều key khác slot → CROSSSLOT, pipeline tách từng key (ClusterClient tự gom theo node)
+	var err error
+	if r.mode == pkgCache.RedisModeCluster && len(keys) > 1 {
+		pipe := r.client.Pipeline()
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		_, err = pipe.Exec(ctx)
+	} else {
+		err = r.client.Del(ctx, keys...).Err()
+	}
 	if err != nil {
 		// Log error nhưng không return
 		log.Printf("[REDIS] Delete error for keys %v: %v", keys, err)
@@ -158,9 +158,9 @@ func (r *RedisCache) Delete(ctx context.Context, keys ...string) error {
 	return nil
 }
 
-// Ping implements cache.Cache interface
+// Ping implements cache.Cache interface (cluster: mọi shard phải trả lời)
 func (r *RedisCache) Ping(ctx context.Context) error {
-	return r.client.Ping(ctx).Err()
+	return pkgCache.PingRedis(ctx, r.client)
 }
 
 // ========================================
@@ -179,7 +179,9 @@ func (r *RedisCache) GetTTL(ctx context.Context, key string) (time.Duration, err
 
 // FlushDB xóa toàn bộ database (CHỈ dùng trong testing)
 func (r *RedisCache) FlushDB(ctx context.Context) error {
-	return r.client.FlushDB(ctx).Err()
+	return pkgCache.ForEachMaster(ctx, r.client, func(ctx context.Context, node redis.Cmdable) error {
+		return node.FlushDB(ctx).Err()
+	})
 }
 
 // IncrBy atomic increment (cho counters, rate limiting)
@@ -187,6 +189,11 @@ func (r *RedisCache) IncrBy(ctx context.Context, key string, value int64) (int64
 	return r.client.IncrBy(ctx, key, value).Result()
 }
 
+// TakeToken lấy 1 token từ bucket (rate limit phân tán, xem pkg/cache/token_bucket.go)
+func (r *RedisCache) TakeToken(ctx context.Context, key string, capacity int, refillPerSecond float64) (*pkgCache.TokenBucketResult, error) {
+	return pkgCache.TakeToken(ctx, r.client, key, capacity, refillPerSecond)
+}
+
 // SetNX set key nếu chưa tồn tại (distributed lock)
 func (r *RedisCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
 	jsonData, err := json.Marshal(value)
@@ -195,24 +202,40 @@ func (r *RedisCache) SetNX(ctx context.Context, key string, value interface{}, t
 	}
 	return r.client.SetNX(ctx, key, jsonData, ttl).Result()
 }
+
+// DeletePattern xoá mọi key khớp pattern
+// Cluster: SCAN chỉ duyệt keyspace của 1 node → quét từng master
 func (r *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
+	// ClusterClient.ForEachMaster chạy song song các master → đếm atomic
+	var deletedCount atomic.Int64
+	_ = pkgCache.ForEachMaster(ctx, r.client, func(ctx context.Context, node redis.Cmdable) error {
+		deletedCount.Add(int64(deletePatternOnNode(ctx, node, pattern)))
+		return nil
+	})
+
+	log.Printf("[REDIS] DeletePattern: deleted %d keys matching %s", deletedCount.Load(), pattern)
+	return nil
+}
+
+// deletePatternOnNode SCAN + DEL trên 1 node, trả số key đã xoá
+func deletePatternOnNode(ctx context.Context, node redis.Cmdable, pattern string) int {
 	var cursor uint64 = 0 // Bắt đầu từ cursor 0
 	var deletedCount int
 
 	// Loop cho đến khi cursor quay về 0 (đã scan hết)
 	for {
 		// SCAN command: cursor hiện tại, pattern match, số keys mỗi batch
-		keys, nextCursor, err := r.client.Scan(ctx, cursor, pattern, 100).Result()
+		keys, nextCursor, err := node.Scan(ctx, cursor, pattern, 100).Result()
 
 		if err != nil {
 			log.Printf("[REDIS] SCAN error: %v", err)
-			return nil // Fail silently - cache failure không nên crash app
+			return deletedCount // Fail silently - cache failure không nên crash app
 		}
 
 		// Nếu batch này có keys, delete chúng
 		if len(keys) > 0 {
 			// Sử dụng PIPELINE để batch delete - giảm network round-trips
-			pipe := r.client.Pipeline()
+			pipe := node.Pipeline()
 
 			for _, key := range keys {
 				pipe.Del(ctx, key) // Queue delete command
@@ -237,8 +260,7 @@ func (r *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
 		}
 	}
 
-	log.Printf("[REDIS] DeletePattern: deleted %d keys matching %s", deletedCount, pattern)
-	return nil
+	return deletedCount
 }
 
 // ✅ New methods for counter operations
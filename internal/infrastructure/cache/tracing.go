@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"bookstore-backend/pkg/tracing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingHook mỗi lệnh Redis (và mỗi pipeline) là 1 span client con của request/task đang trace.
+// Không ghi key: key có thể chứa email/ID khách (failed login tracking).
+type tracingHook struct{}
+
+var _ redis.Hook = tracingHook{}
+
+func (tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		operation := strings.ToUpper(cmd.Name())
+		ctx, span, ok := tracing.StartChild(ctx, "redis "+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "redis"),
+				attribute.String("db.operation", operation),
+			),
+		)
+		if !ok {
+			return next(ctx, cmd)
+		}
+
+		err := next(ctx, cmd)
+		if !errors.Is(err, redis.Nil) {
+			tracing.RecordError(span, err)
+		}
+		span.End()
+		return err
+	}
+}
+
+func (tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span, ok := tracing.StartChild(ctx, "redis pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "redis"),
+				attribute.Int("db.redis.pipeline_length", len(cmds)),
+			),
+		)
+		if !ok {
+			return next(ctx, cmds)
+		}
+
+		err := next(ctx, cmds)
+		if !errors.Is(err, redis.Nil) {
+			tracing.RecordError(span, err)
+		}
+		span.End()
+		return err
+	}
+}
@@ -0,0 +1,68 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TurnstileVerifier - verify token CAPTCHA của Cloudflare Turnstile (siteverify API)
+type TurnstileVerifier struct {
+	secretKey  string
+	verifyURL  string
+	httpClient *http.Client
+}
+
+func NewTurnstileVerifier(secretKey, verifyURL string) *TurnstileVerifier {
+	return &TurnstileVerifier{
+		secretKey:  secretKey,
+		verifyURL:  verifyURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type turnstileResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify trả về false (không lỗi) khi token sai/hết hạn; error chỉ khi không gọi được Turnstile
+func (v *TurnstileVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	form := url.Values{}
+	form.Set("secret", v.secretKey)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("turnstile: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("turnstile: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("turnstile: unexpected status %d", resp.StatusCode)
+	}
+
+	var body turnstileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("turnstile: decode response: %w", err)
+	}
+
+	return body.Success, nil
+}
@@ -306,8 +306,11 @@ func (db *PostgresDB) ExecuteInTransaction(
 	// defer ensure rollback được gọi nếu có error
 	// Rollback on committed transaction là no-op (safe)
 	// Pattern này ensure transaction luôn được cleaned up
+	// WithoutCancel: ctx bị cancel giữa chừng vẫn gửi được ROLLBACK (không để tx treo giữ lock)
 	defer func() {
-		if err := tx.Rollback(ctx); err != nil {
+		rbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+		defer cancel()
+		if err := tx.Rollback(rbCtx); err != nil {
 			// Rollback error chỉ log, không return
 			// Có thể rollback fail nếu transaction đã committed
 			if err != pgx.ErrTxClosed {
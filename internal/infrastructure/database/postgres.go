@@ -6,6 +6,10 @@ import (
 	"log"
 	"time"
 
+	"bookstore-backend/pkg/chaos"
+	"bookstore-backend/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -78,6 +82,17 @@ func (db *PostgresDB) configurePool(ctx context.Context) (*pgxpool.Config, error
 	// === TIMEOUTS ===// ConnectTimeout áp dụng cho mỗi lần establish connection// Tránh hang indefinitely nếu DB không response
 	config.ConnConfig.ConnectTimeout = db.Config.ConnectTimeout
 
+	// === PREPARED STATEMENTS ===// CacheStatement: mỗi connection prepare SQL text 1 lần, các lần sau chỉ bind + execute// Set explicit để hot path (cart items, checkout) không bị đổi mode ngầm// Lưu ý: query phải là hằng số, SQL ghép động sẽ chiếm slot cache
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.ConnConfig.StatementCacheCapacity = 512
+
+	// === TRACING ===// Mỗi query là span con của request/task đang trace (no-op khi tracing tắt)
+	config.ConnConfig.Tracer = tracing.PgxTracer{}
+	if chaos.Enabled() {
+		// Dev / staging: chèn DB latency trước mỗi query (CHAOS_DB_LATENCY_MS)
+		config.ConnConfig.Tracer = chaos.PgxTracer{Next: config.ConnConfig.Tracer}
+	}
+
 	return config, nil
 }
 
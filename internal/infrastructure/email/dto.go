@@ -1,14 +1,16 @@
 package email
 
 type VerificationEmailData struct {
-	Email      string
-	VerifyLink string
-	ExpiresIn  string
+	Email          string
+	VerifyLink     string
+	ExpiresInHours int
+	Locale         string // users.locale, render template theo ngôn ngữ này
 }
 type ResetPasswordData struct {
-	Email     string
-	Token     string
-	ExpiresIn string
+	Email          string
+	Token          string
+	ExpiresInHours int
+	Locale         string
 }
 type EmailRequest struct {
 	To          []string     // Recipients
@@ -18,6 +20,7 @@ type EmailRequest struct {
 	Body        string       // Email body (HTML or plain text)
 	IsHTML      bool         // true for HTML, false for plain text
 	Attachments []Attachment // File attachments (optional)
+	MessageID   string       // Message-ID header (optional), key để khớp webhook delivered/bounce của provider
 }
 type Attachment struct {
 	Filename string
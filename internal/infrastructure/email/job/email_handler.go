@@ -4,12 +4,16 @@ package job
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 
 	"bookstore-backend/internal/infrastructure/email"
+	"bookstore-backend/internal/infrastructure/joboutcome"
+	"bookstore-backend/internal/shared/locale"
 )
 
 // ============================================
@@ -18,11 +22,15 @@ import (
 
 type EmailVerificationHandler struct {
 	emailService email.EmailService
+	templates    email.TemplateRenderer
+	outcomes     *joboutcome.Recorder
 }
 
-func NewEmailVerificationHandler(emailService email.EmailService) *EmailVerificationHandler {
+func NewEmailVerificationHandler(emailService email.EmailService, templates email.TemplateRenderer, outcomes *joboutcome.Recorder) *EmailVerificationHandler {
 	return &EmailVerificationHandler{
 		emailService: emailService,
+		templates:    templates,
+		outcomes:     outcomes,
 	}
 }
 
@@ -35,11 +43,24 @@ func (h *EmailVerificationHandler) ProcessTask(ctx context.Context, task *asynq.
 
 	log.Info().
 		Str("email", payload.Email).
+		Str("locale", payload.Locale).
 		Msg("Processing email verification")
 
-	if err := h.emailService.SendVerificationEmail(ctx, payload); err != nil {
+	if skipMissingAddress(ctx, h.outcomes, joboutcome.JobVerificationEmail, payload.Email) {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"verify_link": payload.VerifyLink,
+		"expires_in":  locale.FormatHours(payload.Locale, payload.ExpiresInHours),
+	}
+	err := sendTemplatedEmail(ctx, h.emailService, h.templates, email.TemplateEmailVerification, payload.Locale, payload.Email, data,
+		func() error { return h.emailService.SendVerificationEmail(ctx, payload) },
+	)
+	recordSendOutcome(ctx, h.outcomes, joboutcome.JobVerificationEmail, err)
+	if err != nil {
 		log.Error().Err(err).Msg("Failed to send verification email")
-		return fmt.Errorf("send verification email: %w", err)
+		return skipRetryIfSuppressed(fmt.Errorf("send verification email: %w", err))
 	}
 
 	log.Info().
@@ -55,11 +76,15 @@ func (h *EmailVerificationHandler) ProcessTask(ctx context.Context, task *asynq.
 
 type ResetPasswordEmailHandler struct {
 	emailService email.EmailService
+	templates    email.TemplateRenderer
+	outcomes     *joboutcome.Recorder
 }
 
-func NewResetPasswordEmailHandler(emailService email.EmailService) *ResetPasswordEmailHandler {
+func NewResetPasswordEmailHandler(emailService email.EmailService, templates email.TemplateRenderer, outcomes *joboutcome.Recorder) *ResetPasswordEmailHandler {
 	return &ResetPasswordEmailHandler{
 		emailService: emailService,
+		templates:    templates,
+		outcomes:     outcomes,
 	}
 }
 
@@ -72,11 +97,24 @@ func (h *ResetPasswordEmailHandler) ProcessTask(ctx context.Context, task *asynq
 
 	log.Info().
 		Str("email", payload.Email).
+		Str("locale", payload.Locale).
 		Msg("Processing reset password email")
 
-	if err := h.emailService.SendResetPasswordEmail(ctx, payload); err != nil {
+	if skipMissingAddress(ctx, h.outcomes, joboutcome.JobResetPasswordEmail, payload.Email) {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"reset_token": payload.Token,
+		"expires_in":  locale.FormatHours(payload.Locale, payload.ExpiresInHours),
+	}
+	err := sendTemplatedEmail(ctx, h.emailService, h.templates, email.TemplatePasswordReset, payload.Locale, payload.Email, data,
+		func() error { return h.emailService.SendResetPasswordEmail(ctx, payload) },
+	)
+	recordSendOutcome(ctx, h.outcomes, joboutcome.JobResetPasswordEmail, err)
+	if err != nil {
 		log.Error().Err(err).Msg("Failed to send reset password email")
-		return fmt.Errorf("send reset password email: %w", err)
+		return skipRetryIfSuppressed(fmt.Errorf("send reset password email: %w", err))
 	}
 
 	log.Info().
@@ -85,3 +123,62 @@ func (h *ResetPasswordEmailHandler) ProcessTask(ctx context.Context, task *asynq
 
 	return nil
 }
+
+// sendTemplatedEmail render template theo locale của user rồi gửi HTML
+// Template lỗi (chưa seed, inactive, thiếu biến) → fallback nội dung tiếng Việt hardcode, không chặn luồng đăng ký / reset
+func sendTemplatedEmail(
+	ctx context.Context,
+	emailService email.EmailService,
+	templates email.TemplateRenderer,
+	templateCode, userLocale, to string,
+	data map[string]interface{},
+	fallback func() error,
+) error {
+	subject, body, err := templates.RenderTemplate(ctx, templateCode, locale.Normalize(userLocale), email.TemplateChannel, data)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("template_code", templateCode).
+			Str("locale", userLocale).
+			Msg("Render email template failed, falling back to default content")
+		return fallback()
+	}
+
+	return emailService.SendEmail(ctx, email.EmailRequest{
+		To:        []string{to},
+		Subject:   subject,
+		Body:      body,
+		IsHTML:    true,
+		MessageID: email.NewMessageID(),
+	})
+}
+
+// skipRetryIfSuppressed - địa chỉ đã hard bounce / complaint thì retry cũng không gửi được
+func skipRetryIfSuppressed(err error) error {
+	if errors.Is(err, email.ErrRecipientSuppressed) {
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+	return err
+}
+
+// skipMissingAddress - payload không có địa chỉ thì retry cũng không gửi được: bỏ qua + đếm cho digest vận hành
+func skipMissingAddress(ctx context.Context, outcomes *joboutcome.Recorder, job, to string) bool {
+	if strings.TrimSpace(to) != "" {
+		return false
+	}
+	log.Warn().Str("job", job).Msg("No recipient email, skip sending")
+	outcomes.Record(ctx, job, joboutcome.OutcomeSkippedMissingAddress, 1)
+	return true
+}
+
+// recordSendOutcome phân loại kết quả gửi: sent / suppressed / failed
+func recordSendOutcome(ctx context.Context, outcomes *joboutcome.Recorder, job string, err error) {
+	switch {
+	case err == nil:
+		outcomes.Record(ctx, job, joboutcome.OutcomeSent, 1)
+	case errors.Is(err, email.ErrRecipientSuppressed):
+		outcomes.Record(ctx, job, joboutcome.OutcomeSuppressed, 1)
+	default:
+		outcomes.Record(ctx, job, joboutcome.OutcomeFailed, 1)
+	}
+}
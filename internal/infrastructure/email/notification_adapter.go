@@ -3,7 +3,6 @@ package email
 import (
 	"context"
 	"fmt"
-	"time"
 )
 
 // ================================================
@@ -23,18 +22,19 @@ func NewNotificationEmailProvider(emailService EmailService) *NotificationEmailP
 
 // SendEmail implements notification DeliveryService.EmailProvider interface
 func (p *NotificationEmailProvider) SendEmail(ctx context.Context, to, subject, body string) (messageID string, err error) {
+	messageID = NewMessageID()
 	req := EmailRequest{
-		To:      []string{to},
-		Subject: subject,
-		Body:    body,
-		IsHTML:  true, // Notification emails are HTML
+		To:        []string{to},
+		Subject:   subject,
+		Body:      body,
+		IsHTML:    true, // Notification emails are HTML
+		MessageID: messageID,
 	}
 
 	if err := p.emailService.SendEmail(ctx, req); err != nil {
 		return "", fmt.Errorf("send notification email: %w", err)
 	}
 
-	// Generate a pseudo message ID (SMTP doesn't return one)
-	messageID = fmt.Sprintf("smtp-%s-%d", to, time.Now().Unix())
+	// SMTP không trả message ID → dùng Message-ID header tự sinh, provider webhook trả lại đúng giá trị này
 	return messageID, nil
 }
@@ -2,6 +2,7 @@ package email
 
 // internal/infrastructure/email/smtp_service.go
 import (
+	"bookstore-backend/internal/shared/locale"
 	"bookstore-backend/pkg/logger"
 	"context"
 	"fmt"
@@ -38,7 +39,7 @@ func (s *smtpEmailService) SendResetPasswordEmail(ctx context.Context, data Rese
 
 	Link có hiệu lực %s.
 
-	Nếu bạn không đăng ký tài khoản này, vui lòng bỏ qua email này.`, data.Token, data.ExpiresIn)
+	Nếu bạn không đăng ký tài khoản này, vui lòng bỏ qua email này.`, data.Token, locale.FormatHours(locale.Vietnamese, data.ExpiresInHours))
 	msg := []byte(fmt.Sprintf(
 		"From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
 		s.smtpFrom, data.Email, subject, body))
@@ -55,7 +56,7 @@ func (s *smtpEmailService) SendVerificationEmail(ctx context.Context, data Verif
 
 	Link có hiệu lực %s.
 
-	Nếu bạn không đăng ký tài khoản này, vui lòng bỏ qua email này.`, data.VerifyLink, data.ExpiresIn)
+	Nếu bạn không đăng ký tài khoản này, vui lòng bỏ qua email này.`, data.VerifyLink, locale.FormatHours(locale.Vietnamese, data.ExpiresInHours))
 
 	msg := []byte(fmt.Sprintf(
 		"From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
@@ -126,6 +127,10 @@ func (s *smtpEmailService) buildMessage(req EmailRequest) string {
 
 	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", req.Subject))
 
+	if req.MessageID != "" {
+		builder.WriteString(fmt.Sprintf("Message-ID: <%s>\r\n", req.MessageID))
+	}
+
 	// Content type
 	if req.IsHTML {
 		builder.WriteString("MIME-Version: 1.0\r\n")
@@ -0,0 +1,102 @@
+package email
+
+import (
+	"bookstore-backend/pkg/logger"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// messageIDDomain - phần sau @ của Message-ID tự sinh
+const messageIDDomain = "bookstore.dev"
+
+// ErrRecipientSuppressed - mọi người nhận đều nằm trong email_suppressions (hard bounce / complaint)
+// Job gửi email nên coi đây là lỗi vĩnh viễn (không retry)
+var ErrRecipientSuppressed = errors.New("recipient is suppressed")
+
+// NewMessageID sinh Message-ID (không kèm <>) để gắn vào email trước khi gửi
+func NewMessageID() string {
+	return uuid.NewString() + "@" + messageIDDomain
+}
+
+// NormalizeMessageID bỏ <> và khoảng trắng: provider trả Message-ID ở nhiều dạng ("<id>", "id")
+func NormalizeMessageID(id string) string {
+	return strings.Trim(strings.TrimSpace(id), "<>")
+}
+
+// SuppressionChecker kiểm tra địa chỉ có bị suppress không (implement bởi notification EmailEventRepository)
+type SuppressionChecker interface {
+	IsSuppressed(ctx context.Context, email string) (bool, error)
+}
+
+// suppressingEmailService bọc EmailService, bỏ các địa chỉ đã hard bounce / complaint trước khi gửi
+//
+// WHY decorator: mọi đường gửi (verification, reset password, order confirmation, notification)
+// đều đi qua EmailService → suppress 1 chỗ thay vì sửa từng job
+type suppressingEmailService struct {
+	inner   EmailService
+	checker SuppressionChecker
+}
+
+func NewSuppressingEmailService(inner EmailService, checker SuppressionChecker) EmailService {
+	return &suppressingEmailService{
+		inner:   inner,
+		checker: checker,
+	}
+}
+
+func (s *suppressingEmailService) SendEmail(ctx context.Context, req EmailRequest) error {
+	req.To = s.filter(ctx, req.To)
+	if len(req.To) == 0 {
+		return ErrRecipientSuppressed
+	}
+	req.Cc = s.filter(ctx, req.Cc)
+	req.Bcc = s.filter(ctx, req.Bcc)
+
+	return s.inner.SendEmail(ctx, req)
+}
+
+func (s *suppressingEmailService) SendResetPasswordEmail(ctx context.Context, data ResetPasswordData) error {
+	if s.isSuppressed(ctx, data.Email) {
+		return ErrRecipientSuppressed
+	}
+	return s.inner.SendResetPasswordEmail(ctx, data)
+}
+
+func (s *suppressingEmailService) SendVerificationEmail(ctx context.Context, data VerificationEmailData) error {
+	if s.isSuppressed(ctx, data.Email) {
+		return ErrRecipientSuppressed
+	}
+	return s.inner.SendVerificationEmail(ctx, data)
+}
+
+func (s *suppressingEmailService) filter(ctx context.Context, recipients []string) []string {
+	if len(recipients) == 0 {
+		return recipients
+	}
+
+	kept := make([]string, 0, len(recipients))
+	for _, to := range recipients {
+		if !s.isSuppressed(ctx, to) {
+			kept = append(kept, to)
+		}
+	}
+	return kept
+}
+
+// isSuppressed fail-open: lỗi DB không được chặn email quan trọng (reset password, xác nhận đơn)
+func (s *suppressingEmailService) isSuppressed(ctx context.Context, address string) bool {
+	suppressed, err := s.checker.IsSuppressed(ctx, address)
+	if err != nil {
+		logger.Error("Failed to check email suppression", err)
+		return false
+	}
+	if suppressed {
+		logger.Info("Skip sending to suppressed address", map[string]interface{}{
+			"email": address,
+		})
+	}
+	return suppressed
+}
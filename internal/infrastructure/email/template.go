@@ -0,0 +1,19 @@
+package email
+
+import "context"
+
+// Template code của email transactional gửi từ job (seed vi + en ở migration 000058)
+const (
+	TemplateEmailVerification = "email_verification"
+	TemplatePasswordReset     = "password_reset"
+	TemplateOrderConfirmation = "order_confirmation"
+
+	// TemplateChannel - kênh render của notification_templates (email_subject, email_body_html)
+	TemplateChannel = "email"
+)
+
+// TemplateRenderer render template theo (code, ngôn ngữ), fallback locale mặc định khi chưa có bản dịch
+// Implement bởi notification TemplateService
+type TemplateRenderer interface {
+	RenderTemplate(ctx context.Context, templateCode, language, channel string, data map[string]interface{}) (string, string, error)
+}
@@ -0,0 +1,126 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"bookstore-backend/internal/config"
+	"bookstore-backend/internal/infrastructure/email"
+	"bookstore-backend/internal/infrastructure/joboutcome"
+	"bookstore-backend/pkg/logger"
+)
+
+// DigestEmailSender gửi email (implement bởi email.EmailService)
+type DigestEmailSender interface {
+	SendEmail(ctx context.Context, req email.EmailRequest) error
+}
+
+// DigestSlackSender gửi tin qua Slack incoming webhook (implement bởi slack.WebhookClient)
+type DigestSlackSender interface {
+	Send(ctx context.Context, webhookURL, text string) error
+}
+
+// ========================================
+// DAILY: ops:send_daily_digest
+// ========================================
+
+// SendOpsDigestHandler tổng hợp kết quả nghiệp vụ của background job ngày hôm qua (UTC),
+// so với hôm trước nữa → gửi email / Slack cho đội vận hành
+type SendOpsDigestHandler struct {
+	outcomes *joboutcome.Recorder
+	emails   DigestEmailSender
+	slack    DigestSlackSender
+	cfg      config.JobConfig
+}
+
+func NewSendOpsDigestHandler(
+	outcomes *joboutcome.Recorder,
+	emails DigestEmailSender,
+	slack DigestSlackSender,
+	cfg config.JobConfig,
+) *SendOpsDigestHandler {
+	return &SendOpsDigestHandler{
+		outcomes: outcomes,
+		emails:   emails,
+		slack:    slack,
+		cfg:      cfg,
+	}
+}
+
+// ProcessTask - chưa cấu hình người nhận vẫn log digest (xem được trong log worker).
+// Gửi lỗi chỉ log: retry sẽ gửi trùng cho kênh đã nhận
+func (h *SendOpsDigestHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	day := time.Now().UTC().AddDate(0, 0, -1)
+
+	current, err := h.outcomes.DailyCounts(ctx, day)
+	if err != nil {
+		logger.Error("Failed to load job outcomes for ops digest", err)
+		return fmt.Errorf("load job outcomes: %w", err)
+	}
+	previous, err := h.outcomes.DailyCounts(ctx, day.AddDate(0, 0, -1))
+	if err != nil {
+		logger.Error("Failed to load previous job outcomes for ops digest", err)
+		return fmt.Errorf("load previous job outcomes: %w", err)
+	}
+
+	subject := fmt.Sprintf("[Ops] Tổng hợp background job %s", day.Format("02/01/2006"))
+	body := formatDigest(current, previous)
+
+	logger.Info("Ops digest generated", map[string]interface{}{
+		"day":     day.Format("2006-01-02"),
+		"summary": body,
+	})
+
+	for _, to := range h.cfg.OpsDigestEmails {
+		if err := h.emails.SendEmail(ctx, email.EmailRequest{
+			To:      []string{to},
+			Subject: subject,
+			Body:    body,
+		}); err != nil {
+			logger.Error(fmt.Sprintf("Failed to send ops digest email to %s", to), err)
+		}
+	}
+	if h.cfg.OpsDigestSlackWebhook != "" {
+		if err := h.slack.Send(ctx, h.cfg.OpsDigestSlackWebhook, "*"+subject+"*\n```\n"+body+"```"); err != nil {
+			logger.Error("Failed to send ops digest to Slack", err)
+		}
+	}
+	return nil
+}
+
+// formatDigest 1 khối / job theo Catalog, job cả 2 ngày đều 0 thì gộp 1 dòng
+func formatDigest(current, previous []joboutcome.Count) string {
+	prev := make(map[string]int64, len(previous))
+	for _, c := range previous {
+		prev[c.Job+"/"+c.Outcome] = c.Value
+	}
+
+	var b strings.Builder
+	var idle []string
+	for _, entry := range joboutcome.Catalog {
+		var lines []string
+		for _, c := range current {
+			if c.Job != entry.Job {
+				continue
+			}
+			before := prev[c.Job+"/"+c.Outcome]
+			if c.Value == 0 && before == 0 {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  - %s: %d (hôm trước %d)", c.Outcome, c.Value, before))
+		}
+		if len(lines) == 0 {
+			idle = append(idle, entry.Job)
+			continue
+		}
+		fmt.Fprintf(&b, "%s\n%s\n", entry.Job, strings.Join(lines, "\n"))
+	}
+	if len(idle) > 0 {
+		fmt.Fprintf(&b, "Không có hoạt động: %s\n", strings.Join(idle, ", "))
+	}
+	return b.String()
+}
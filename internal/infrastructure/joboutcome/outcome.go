@@ -0,0 +1,159 @@
+package joboutcome
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bookstore-backend/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ========================================
+// KẾT QUẢ NGHIỆP VỤ CỦA BACKGROUND JOB
+// ========================================
+// asynq chỉ biết task thành công / lỗi: job auto-release "thành công" vẫn có thể không trả được hàng nào,
+// job email "thành công" vẫn có thể bỏ qua vì khách không có địa chỉ.
+//
+// WHY 2 nơi ghi:
+// - Prometheus (job_outcomes_total): dashboard / alert gần real-time, nhưng reset khi worker restart
+// - Counter theo ngày trong Redis: digest vận hành hằng ngày đọc lại số liệu cả ngày (mọi worker cộng dồn)
+
+var jobOutcomesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "job_outcomes_total",
+	Help: "Business outcomes recorded by background jobs (beyond task success / failure).",
+}, []string{"job", "outcome"})
+
+// Tên job (label "job")
+const (
+	JobInventorySync          = "inventory_sync"
+	JobAutoReleaseReservation = "auto_release_reservation"
+	JobOrderConfirmationEmail = "order_confirmation_email"
+	JobPaymentLinkEmail       = "payment_link_email"
+	JobVerificationEmail      = "verification_email"
+	JobResetPasswordEmail     = "reset_password_email"
+	JobSecurityAlertEmail     = "security_alert_email"
+)
+
+// Kết quả (label "outcome")
+const (
+	// Inventory sync
+	OutcomeCacheUpdated        = "cache_updated"
+	OutcomeOutOfStock          = "out_of_stock" // tồn khả dụng về 0 sau khi đồng bộ
+	OutcomeCacheWriteFailed    = "cache_write_failed"
+	OutcomeMarketplaceEnqueued = "marketplace_sync_enqueued"
+
+	// Auto-release
+	OutcomeReservationsReleased = "reservations_released" // số dòng giữ hàng trả lại thành công
+	OutcomeUnitsReleased        = "units_released"        // số cuốn trả lại tồn khả dụng
+	OutcomeReleaseFailed        = "release_failed"
+	OutcomeOrderCancelled       = "order_cancelled"
+	OutcomeCancelFailed         = "cancel_failed"
+	OutcomeSkippedPaid          = "skipped_paid"
+	OutcomeSkippedClosed        = "skipped_closed" // đơn đã huỷ / trả hàng bởi flow khác
+	OutcomeSkippedIneligible    = "skipped_ineligible"
+
+	// Email
+	OutcomeSent                  = "sent"
+	OutcomeSkippedMissingAddress = "skipped_missing_address"
+	OutcomeSkippedExpired        = "skipped_expired" // link thanh toán đã hết hạn
+	OutcomeSuppressed            = "suppressed"      // địa chỉ đã hard bounce / complaint
+	OutcomeFailed                = "failed"
+)
+
+// Catalog thứ tự job / kết quả trong digest (counter Redis đọc theo danh sách này, không SCAN)
+var Catalog = []CatalogEntry{
+	{Job: JobInventorySync, Outcomes: []string{OutcomeCacheUpdated, OutcomeOutOfStock, OutcomeCacheWriteFailed, OutcomeMarketplaceEnqueued}},
+	{Job: JobAutoReleaseReservation, Outcomes: []string{
+		OutcomeReservationsReleased, OutcomeUnitsReleased, OutcomeReleaseFailed,
+		OutcomeOrderCancelled, OutcomeCancelFailed,
+		OutcomeSkippedPaid, OutcomeSkippedClosed, OutcomeSkippedIneligible,
+	}},
+	{Job: JobOrderConfirmationEmail, Outcomes: emailOutcomes},
+	{Job: JobPaymentLinkEmail, Outcomes: append([]string{OutcomeSkippedExpired}, emailOutcomes...)},
+	{Job: JobVerificationEmail, Outcomes: emailOutcomes},
+	{Job: JobResetPasswordEmail, Outcomes: emailOutcomes},
+	{Job: JobSecurityAlertEmail, Outcomes: emailOutcomes},
+}
+
+var emailOutcomes = []string{OutcomeSent, OutcomeSkippedMissingAddress, OutcomeSuppressed, OutcomeFailed}
+
+type CatalogEntry struct {
+	Job      string
+	Outcomes []string
+}
+
+// dailyRetention giữ counter đủ để digest so sánh với hôm trước (+ dư vài ngày tra cứu tay)
+const dailyRetention = 8 * 24 * time.Hour
+
+// CounterStore implement bởi infrastructure/cache.RedisCache
+type CounterStore interface {
+	IncrBy(ctx context.Context, key string, value int64) (int64, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	Get(ctx context.Context, key string, dest interface{}) (bool, error)
+}
+
+// Recorder ghi kết quả job. Nil-safe: recorder nil (hoặc store nil) vẫn đếm Prometheus
+type Recorder struct {
+	store CounterStore
+}
+
+func NewRecorder(store CounterStore) *Recorder {
+	return &Recorder{store: store}
+}
+
+// Record cộng n vào (job, outcome). Lỗi Redis chỉ log: số liệu vận hành không được làm fail job
+func (r *Recorder) Record(ctx context.Context, job, outcome string, n int) {
+	if n <= 0 {
+		return
+	}
+	jobOutcomesTotal.WithLabelValues(job, outcome).Add(float64(n))
+
+	if r == nil || r.store == nil {
+		return
+	}
+	key := dailyKey(time.Now().UTC(), job, outcome)
+	count, err := r.store.IncrBy(ctx, key, int64(n))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to record job outcome %s/%s", job, outcome), err)
+		return
+	}
+	// Lần tăng đầu tiên trong ngày mới đặt TTL (tránh 1 round-trip mỗi lần ghi)
+	if count == int64(n) {
+		if err := r.store.Expire(ctx, key, dailyRetention); err != nil {
+			logger.Error("Failed to set job outcome counter TTL", err)
+		}
+	}
+}
+
+// Count số liệu 1 (job, outcome) trong 1 ngày
+type Count struct {
+	Job     string
+	Outcome string
+	Value   int64
+}
+
+// DailyCounts đọc counter của ngày (UTC) theo Catalog, giữ cả dòng 0 để digest so sánh được
+func (r *Recorder) DailyCounts(ctx context.Context, day time.Time) ([]Count, error) {
+	if r == nil || r.store == nil {
+		return nil, fmt.Errorf("job outcome store is not configured")
+	}
+	var counts []Count
+	for _, entry := range Catalog {
+		for _, outcome := range entry.Outcomes {
+			var value int64
+			if _, err := r.store.Get(ctx, dailyKey(day, entry.Job, outcome), &value); err != nil {
+				return nil, fmt.Errorf("get job outcome %s/%s: %w", entry.Job, outcome, err)
+			}
+			counts = append(counts, Count{Job: entry.Job, Outcome: outcome, Value: value})
+		}
+	}
+	return counts, nil
+}
+
+// dailyKey ops:job_outcomes:{yyyy-mm-dd}:{job}:{outcome}, ngày theo UTC (khớp Location của scheduler)
+func dailyKey(day time.Time, job, outcome string) string {
+	return fmt.Sprintf("ops:job_outcomes:%s:%s:%s", day.UTC().Format("2006-01-02"), job, outcome)
+}
@@ -0,0 +1,124 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bookstore-backend/pkg/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// =====================================================
+// TRANSACTIONAL OUTBOX
+// =====================================================
+// Task hậu commit ghi vào outbox_messages trong cùng pgx transaction nghiệp vụ,
+// Relay (worker) đẩy sang asynq. Redis down lúc commit không còn làm mất task.
+
+// Message - 1 asynq task chờ phát
+type Message struct {
+	ID       uuid.UUID
+	TaskType string
+	Payload  []byte
+	Queue    string
+
+	// nil = mặc định của asynq
+	MaxRetry  *int
+	Timeout   *time.Duration
+	ProcessAt *time.Time
+
+	// DedupKey != nil: ghi trùng key bị bỏ qua (ON CONFLICT DO NOTHING)
+	DedupKey *string
+}
+
+// NewMessage marshal payload thành message của task type trên queue
+func NewMessage(taskType string, payload interface{}, queue string) (Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("marshal outbox payload %s: %w", taskType, err)
+	}
+	return Message{
+		ID:       uuid.New(),
+		TaskType: taskType,
+		Payload:  data,
+		Queue:    queue,
+	}, nil
+}
+
+// WithMaxRetry số lần asynq retry task
+func (m Message) WithMaxRetry(n int) Message {
+	m.MaxRetry = &n
+	return m
+}
+
+// WithTimeout thời gian xử lý tối đa của task
+func (m Message) WithTimeout(d time.Duration) Message {
+	m.Timeout = &d
+	return m
+}
+
+// WithDelay task chạy sau d tính từ lúc ghi outbox (không phải lúc relay phát)
+func (m Message) WithDelay(d time.Duration) Message {
+	at := time.Now().Add(d)
+	m.ProcessAt = &at
+	return m
+}
+
+// WithDedupKey chặn ghi trùng cùng key
+func (m Message) WithDedupKey(key string) Message {
+	m.DedupKey = &key
+	return m
+}
+
+// AddWithTx ghi messages trong transaction của caller; commit thì relay mới thấy
+func AddWithTx(ctx context.Context, tx pgx.Tx, messages ...Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO outbox_messages (
+			id, task_type, payload, headers, queue, max_retry, timeout_seconds, process_at, dedup_key
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (dedup_key) WHERE dedup_key IS NOT NULL DO NOTHING
+	`
+
+	// Trace context đi theo header task (payload giữ nguyên), relay chuyển tiếp khi enqueue
+	headers := tracing.Headers(ctx)
+
+	batch := &pgx.Batch{}
+	for _, m := range messages {
+		if m.ID == uuid.Nil {
+			m.ID = uuid.New()
+		}
+		var timeoutSeconds *int
+		if m.Timeout != nil {
+			seconds := int(m.Timeout.Seconds())
+			timeoutSeconds = &seconds
+		}
+		batch.Queue(query,
+			m.ID,
+			m.TaskType,
+			m.Payload,
+			headers,
+			m.Queue,
+			m.MaxRetry,
+			timeoutSeconds,
+			m.ProcessAt,
+			m.DedupKey,
+		)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for _, m := range messages {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to write outbox message %s: %w", m.TaskType, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"bookstore-backend/pkg/database"
+	"bookstore-backend/pkg/logger"
+)
+
+const (
+	// MaxDispatchAttempts số lần relay thử enqueue trước khi đánh dấu failed (cần xử lý tay)
+	MaxDispatchAttempts = 20
+	// maxBackoff khoảng chờ tối đa giữa 2 lần thử khi Redis lỗi
+	maxBackoff = 5 * time.Minute
+	// dispatchedRetention message đã phát giữ lại để tra cứu rồi xoá
+	dispatchedRetention = 7 * 24 * time.Hour
+	// taskRetention giữ task đã xong trong Redis để TaskID còn chặn enqueue trùng
+	taskRetention = time.Hour
+)
+
+// Relay đẩy outbox_messages sang asynq (chạy trong worker, nhiều instance dùng SKIP LOCKED)
+type Relay struct {
+	pool      *pgxpool.Pool
+	client    *asynq.Client
+	interval  time.Duration
+	batchSize int
+
+	lastPurge time.Time
+}
+
+// NewRelay creates outbox relay polling mỗi interval, tối đa batchSize message / lần
+func NewRelay(pool *pgxpool.Pool, client *asynq.Client, interval time.Duration, batchSize int) *Relay {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &Relay{
+		pool:      pool,
+		client:    client,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run poll tới khi ctx bị cancel; mỗi tick drain hết các lô đang chờ
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			n, err := r.Drain(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error("Outbox relay: drain failed", err)
+				}
+				break
+			}
+			if n < r.batchSize {
+				break
+			}
+		}
+
+		if time.Since(r.lastPurge) > time.Hour {
+			r.purge(ctx)
+		}
+	}
+}
+
+type pendingMessage struct {
+	id             uuid.UUID
+	taskType       string
+	payload        []byte
+	headers        map[string]string
+	queue          string
+	maxRetry       *int
+	timeoutSeconds *int
+	processAt      *time.Time
+	attempts       int
+}
+
+// Drain phát 1 lô message, trả số message đã xử lý (phát được hoặc lỗi)
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin outbox tx: %w", err)
+	}
+	defer database.Rollback(ctx, tx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, task_type, payload, headers, queue, max_retry, timeout_seconds, process_at, attempts
+		FROM outbox_messages
+		WHERE status = 'pending' AND available_at <= NOW()
+		ORDER BY available_at, created_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("select outbox messages: %w", err)
+	}
+
+	messages := make([]pendingMessage, 0, r.batchSize)
+	for rows.Next() {
+		var m pendingMessage
+		if err := rows.Scan(&m.id, &m.taskType, &m.payload, &m.headers, &m.queue, &m.maxRetry, &m.timeoutSeconds, &m.processAt, &m.attempts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan outbox message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, m := range messages {
+		if enqueueErr := r.enqueue(ctx, m); enqueueErr != nil {
+			attempts := m.attempts + 1
+			status := "pending"
+			if attempts >= MaxDispatchAttempts {
+				status = "failed"
+				logger.Error(fmt.Sprintf("Outbox relay: giving up message %s (%s)", m.id, m.taskType), enqueueErr)
+			}
+			if _, err := tx.Exec(ctx, `
+				UPDATE outbox_messages
+				SET attempts = $2, status = $3, last_error = $4, available_at = NOW() + make_interval(secs => $5)
+				WHERE id = $1
+			`, m.id, attempts, status, enqueueErr.Error(), backoff(attempts).Seconds()); err != nil {
+				return 0, fmt.Errorf("mark outbox message retry: %w", err)
+			}
+			continue
+		}
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE outbox_messages
+			SET status = 'dispatched', attempts = attempts + 1, last_error = NULL, dispatched_at = NOW()
+			WHERE id = $1
+		`, m.id); err != nil {
+			return 0, fmt.Errorf("mark outbox message dispatched: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit outbox tx: %w", err)
+	}
+	return len(messages), nil
+}
+
+// enqueue TaskID = outbox id: đã enqueue ở lần trước (relay chết trước khi commit) → coi như thành công
+func (r *Relay) enqueue(ctx context.Context, m pendingMessage) error {
+	opts := []asynq.Option{
+		asynq.Queue(m.queue),
+		asynq.TaskID(m.id.String()),
+		asynq.Retention(taskRetention),
+	}
+	if m.maxRetry != nil {
+		opts = append(opts, asynq.MaxRetry(*m.maxRetry))
+	}
+	if m.timeoutSeconds != nil {
+		opts = append(opts, asynq.Timeout(time.Duration(*m.timeoutSeconds)*time.Second))
+	}
+	if m.processAt != nil {
+		opts = append(opts, asynq.ProcessAt(*m.processAt))
+	}
+
+	_, err := r.client.EnqueueContext(ctx, asynq.NewTaskWithHeaders(m.taskType, m.payload, m.headers), opts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
+
+// purge xoá message đã phát quá hạn lưu
+func (r *Relay) purge(ctx context.Context) {
+	r.lastPurge = time.Now()
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM outbox_messages
+		WHERE status = 'dispatched' AND dispatched_at < $1
+	`, time.Now().Add(-dispatchedRetention))
+	if err != nil {
+		logger.Error("Outbox relay: purge failed", err)
+		return
+	}
+	if tag.RowsAffected() > 0 {
+		logger.Info("Outbox relay: purged dispatched messages", map[string]interface{}{
+			"count": tag.RowsAffected(),
+		})
+	}
+}
+
+// backoff 2^attempts giây, tối đa maxBackoff
+func backoff(attempts int) time.Duration {
+	if attempts > 8 {
+		return maxBackoff
+	}
+	d := time.Duration(1<<attempts) * time.Second
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
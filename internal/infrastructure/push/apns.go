@@ -0,0 +1,165 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ================================================
+// APNs (token-based auth, HTTP/2)
+// ================================================
+// Auth bằng key .p8 (Key ID + Team ID): JWT ES256, Apple cho dùng tối đa 1 giờ,
+// không được đổi quá 1 lần / 20 phút → cache 50 phút.
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+	apnsTokenTTL       = 50 * time.Minute
+)
+
+// APNsConfig credentials token-based của Apple Developer
+type APNsConfig struct {
+	KeyFile    string // file .p8
+	KeyID      string
+	TeamID     string
+	Topic      string // bundle id của app
+	Production bool
+}
+
+type APNsSender struct {
+	config APNsConfig
+	key    *ecdsa.PrivateKey
+	host   string
+	client *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+func NewAPNsSender(cfg APNsConfig) (*APNsSender, error) {
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" {
+		return nil, fmt.Errorf("apns requires key id, team id and topic")
+	}
+	data, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read apns key: %w", err)
+	}
+	parsed, err := parsePKCS8(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse apns key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("apns key is not ECDSA")
+	}
+
+	host := apnsSandboxHost
+	if cfg.Production {
+		host = apnsProductionHost
+	}
+
+	// Transport mặc định tự đàm phán HTTP/2 qua TLS (APNs chỉ nhận HTTP/2)
+	return &APNsSender{
+		config: cfg,
+		key:    key,
+		host:   host,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *APNsSender) Send(ctx context.Context, msg Message) (string, error) {
+	token, err := s.providerToken()
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal apns payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.host+"/3/device/"+msg.Token, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("build apns request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", s.config.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send apns notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.Header.Get("apns-id"), nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 16<<10))
+	_ = json.Unmarshal(respBody, &apnsErr)
+
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		apnsErr.Reason == "BadDeviceToken",
+		apnsErr.Reason == "DeviceTokenNotForTopic",
+		apnsErr.Reason == "Unregistered":
+		return "", fmt.Errorf("%w: apns %s", ErrInvalidToken, apnsErr.Reason)
+	case apnsErr.Reason == "ExpiredProviderToken" || apnsErr.Reason == "InvalidProviderToken":
+		s.resetToken()
+	}
+	return "", fmt.Errorf("apns returned status %d: %s", resp.StatusCode, apnsErr.Reason)
+}
+
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.jwt != "" && time.Since(s.issuedAt) < apnsTokenTTL {
+		return s.jwt, nil
+	}
+
+	now := time.Now()
+	token, err := signJWT(
+		map[string]interface{}{"alg": "ES256", "kid": s.config.KeyID},
+		map[string]interface{}{"iss": s.config.TeamID, "iat": now.Unix()},
+		signES256(s.key),
+	)
+	if err != nil {
+		return "", err
+	}
+	s.jwt = token
+	s.issuedAt = now
+	return token, nil
+}
+
+func (s *APNsSender) resetToken() {
+	s.mu.Lock()
+	s.jwt = ""
+	s.mu.Unlock()
+}
@@ -0,0 +1,229 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ================================================
+// FCM HTTP v1
+// ================================================
+// Auth bằng service account (file JSON tải từ Firebase console): ký JWT → đổi access token OAuth2,
+// cache tới gần hết hạn. API legacy (server key) Google đã tắt nên không hỗ trợ.
+
+const (
+	fcmScope        = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmSendURL      = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	defaultTokenURI = "https://oauth2.googleapis.com/token"
+)
+
+type serviceAccount struct {
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+type FCMSender struct {
+	account serviceAccount
+	key     *rsa.PrivateKey
+	client  *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMSender đọc file service account JSON
+func NewFCMSender(credentialsFile string) (*FCMSender, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read fcm credentials: %w", err)
+	}
+
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("parse fcm credentials: %w", err)
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("fcm credentials missing project_id, client_email or private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = defaultTokenURI
+	}
+
+	parsed, err := parsePKCS8([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse fcm private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("fcm private key is not RSA")
+	}
+
+	return &FCMSender{
+		account: account,
+		key:     key,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      map[string]string `json:"android"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (s *FCMSender) Send(ctx context.Context, msg Message) (string, error) {
+	token, err := s.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(fcmRequest{Message: fcmMessage{
+		Token:        msg.Token,
+		Notification: fcmNotification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+		Android:      map[string]string{"priority": "HIGH"},
+	}})
+	if err != nil {
+		return "", fmt.Errorf("marshal fcm message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmSendURL, s.account.ProjectID), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("build fcm request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("send fcm message: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusOK {
+		var ok struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(respBody, &ok)
+		return ok.Name, nil
+	}
+
+	var fcmErr fcmErrorResponse
+	_ = json.Unmarshal(respBody, &fcmErr)
+	errorCode := fcmErr.Error.Status
+	for _, d := range fcmErr.Error.Details {
+		if d.ErrorCode != "" {
+			errorCode = d.ErrorCode
+		}
+	}
+
+	switch {
+	case errorCode == "UNREGISTERED" || errorCode == "SENDER_ID_MISMATCH":
+		return "", fmt.Errorf("%w: fcm %s", ErrInvalidToken, errorCode)
+	case errorCode == "INVALID_ARGUMENT" && strings.Contains(strings.ToLower(fcmErr.Error.Message), "registration token"):
+		return "", fmt.Errorf("%w: fcm %s", ErrInvalidToken, fcmErr.Error.Message)
+	case resp.StatusCode == http.StatusUnauthorized:
+		s.resetToken()
+	}
+	return "", fmt.Errorf("fcm returned status %d: %s %s", resp.StatusCode, errorCode, fcmErr.Error.Message)
+}
+
+// token access token OAuth2 còn hạn (đổi mới trước hạn 1 phút)
+func (s *FCMSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := signJWT(
+		map[string]interface{}{"alg": "RS256", "typ": "JWT", "kid": s.account.PrivateKeyID},
+		map[string]interface{}{
+			"iss":   s.account.ClientEmail,
+			"scope": fcmScope,
+			"aud":   s.account.TokenURI,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		},
+		signRS256(s.key),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("build oauth request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch fcm access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oauth token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.AccessToken == "" {
+		return "", fmt.Errorf("invalid oauth token response")
+	}
+
+	s.accessToken = result.AccessToken
+	s.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
+
+func (s *FCMSender) resetToken() {
+	s.mu.Lock()
+	s.accessToken = ""
+	s.mu.Unlock()
+}
@@ -18,35 +18,15 @@ func NewMockPushService() *MockPushService {
 	return &MockPushService{}
 }
 
-// SendPush implements notification DeliveryService.PushProvider interface
-func (s *MockPushService) SendPush(ctx context.Context, deviceToken, title, body string, data map[string]interface{}) (messageID string, err error) {
+// Send implements Sender (đăng ký cho cả fcm và apns khi USE_MOCK_PUSH=true)
+func (s *MockPushService) Send(ctx context.Context, msg Message) (string, error) {
 	log.Info().
-		Str("device_token", deviceToken).
-		Str("title", title).
-		Str("body", body).
-		Interface("data", data).
+		Str("device_token", msg.Token).
+		Str("title", msg.Title).
+		Str("body", msg.Body).
+		Interface("data", msg.Data).
 		Msg("[MOCK] Push notification sent successfully")
 
 	// Simulate success
-	messageID = fmt.Sprintf("mock-push-%d", time.Now().Unix())
-	return messageID, nil
-}
-
-// ================================================
-// TODO: FCM PUSH SERVICE (for production)
-// ================================================
-
-type FCMPushService struct {
-	serverKey string
-}
-
-func NewFCMPushService(serverKey string) *FCMPushService {
-	return &FCMPushService{
-		serverKey: serverKey,
-	}
-}
-
-func (s *FCMPushService) SendPush(ctx context.Context, deviceToken, title, body string, data map[string]interface{}) (messageID string, err error) {
-	// TODO: Implement FCM API call
-	return NewMockPushService().SendPush(ctx, deviceToken, title, body, data)
+	return fmt.Sprintf("mock-push-%d", time.Now().UnixNano()), nil
 }
@@ -0,0 +1,80 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ================================================
+// PUSH PROVIDERS (FCM / APNs)
+// ================================================
+// Mỗi device token thuộc 1 provider (user_devices.provider):
+// - fcm:  Android + web, iOS khi app dùng Firebase SDK
+// - apns: iOS gửi thẳng qua Apple (token hex từ APNs)
+// Router chọn sender theo provider; provider chưa cấu hình → lỗi ErrProviderNotConfigured.
+
+const (
+	ProviderFCM  = "fcm"
+	ProviderAPNs = "apns"
+)
+
+var (
+	// ErrInvalidToken token không còn hợp lệ (app gỡ, token hết hạn, sai topic) → xoá token, không retry
+	ErrInvalidToken = errors.New("push: device token is invalid or unregistered")
+	// ErrProviderNotConfigured chưa cấu hình credentials cho provider của token
+	ErrProviderNotConfigured = errors.New("push: provider not configured")
+)
+
+// Message 1 push tới 1 device
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	// Data payload cho app (deep link...), FCM chỉ nhận string → string
+	Data map[string]string
+}
+
+// Sender gửi push qua 1 provider, trả message id của provider
+type Sender interface {
+	Send(ctx context.Context, msg Message) (string, error)
+}
+
+// Router gửi theo provider của device
+type Router struct {
+	senders map[string]Sender
+}
+
+func NewRouter() *Router {
+	return &Router{senders: make(map[string]Sender)}
+}
+
+// Register gắn sender cho provider (gọi lúc khởi động)
+func (r *Router) Register(provider string, sender Sender) *Router {
+	r.senders[provider] = sender
+	return r
+}
+
+// Configured provider đã có sender
+func (r *Router) Configured(provider string) bool {
+	_, ok := r.senders[provider]
+	return ok
+}
+
+// SendTo gửi push tới device thuộc provider
+func (r *Router) SendTo(ctx context.Context, provider string, msg Message) (string, error) {
+	sender, ok := r.senders[provider]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrProviderNotConfigured, provider)
+	}
+	return sender.Send(ctx, msg)
+}
+
+// SendPush implements notification DeliveryService.PushProvider interface (token FCM)
+func (r *Router) SendPush(ctx context.Context, deviceToken, title, body string, data map[string]interface{}) (string, error) {
+	msg := Message{Token: deviceToken, Title: title, Body: body, Data: make(map[string]string, len(data))}
+	for k, v := range data {
+		msg.Data[k] = fmt.Sprint(v)
+	}
+	return r.SendTo(ctx, ProviderFCM, msg)
+}
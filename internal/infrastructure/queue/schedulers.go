@@ -2,10 +2,15 @@ package queue
 
 import (
 	"bookstore-backend/internal/config"
+	analyticsModel "bookstore-backend/internal/domains/analytics/model"
 	cartModel "bookstore-backend/internal/domains/cart/model"
+	recommendationJob "bookstore-backend/internal/domains/recommendation/job"
 	"bookstore-backend/internal/domains/user/job"
+	warehouseJob "bookstore-backend/internal/domains/warehouse/job"
 	"bookstore-backend/internal/shared"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+	"context"
 	"encoding/json"
 	"time"
 
@@ -17,9 +22,9 @@ type Scheduler struct {
 	jobConfig config.JobConfig
 }
 
-func NewScheduler(redisAddress string, jobConfig config.JobConfig) *Scheduler {
+func NewScheduler(redisOpt asynq.RedisConnOpt, jobConfig config.JobConfig) *Scheduler {
 	scheduler := asynq.NewScheduler(
-		asynq.RedisClientOpt{Addr: redisAddress},
+		redisOpt,
 		&asynq.SchedulerOpts{
 			Location: time.UTC,
 			LogLevel: asynq.InfoLevel,
@@ -32,6 +37,12 @@ func NewScheduler(redisAddress string, jobConfig config.JobConfig) *Scheduler {
 	}
 }
 
+// newScheduledTask task định kỳ không có request cha → mỗi lần chạy là 1 root trace ở worker.
+// Vẫn đi qua tracing.NewTask như mọi chỗ tạo task (không gọi asynq.NewTask trực tiếp).
+func newScheduledTask(typename string, payload []byte) *asynq.Task {
+	return tracing.NewTask(context.Background(), typename, payload)
+}
+
 func (s *Scheduler) RegisterCleanupJobs() error {
 	// Register all scheduled jobs
 	if err := s.registerCleanupExpiredTokensJob(); err != nil {
@@ -54,6 +65,82 @@ func (s *Scheduler) RegisterCleanupJobs() error {
 		return err
 	}
 
+	if err := s.registerRefreshFeedsJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerBackfillIdentitiesJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerComputeFunnelJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerSeedNationalHolidaysJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerCheckTicketSLAJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerGenerateAffiliateCommissionsJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerReconcileMarketplaceStockJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerCleanupCheckoutFailuresJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerCleanupAnonymousCartsJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerWarnExpiringCartsJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerReconcileCheckoutsJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerComputeCoPurchasesJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerDispatchLowStockAlertsJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerLowStockDigestJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerComputeDemandForecastsJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerApplyPriceSchedulesJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerScanBookDataQualityJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerSendOpsDigestJob(); err != nil {
+		return err
+	}
+
+	if err := s.registerCleanupIdempotencyKeysJob(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -66,7 +153,7 @@ func (s *Scheduler) registerCleanupExpiredTokensJob() error {
 		return err
 	}
 
-	task := asynq.NewTask(shared.TypeCleanupExpiredToken, payload)
+	task := newScheduledTask(shared.TypeCleanupExpiredToken, payload)
 
 	_, err = s.scheduler.Register(
 		"0 2 * * *", // Daily at 2 AM
@@ -99,7 +186,7 @@ func (s *Scheduler) registerRemoveExpiredPromotionsJob() error {
 		return err
 	}
 
-	task := asynq.NewTask(shared.TypeRemoveExpiredPromotions, payload)
+	task := newScheduledTask(shared.TypeRemoveExpiredPromotions, payload)
 
 	_, err = s.scheduler.Register(
 		"0 */3 * * *", // Every 3 hours at minute 0 (00:00, 03:00, 06:00, etc.)
@@ -134,7 +221,7 @@ func (s *Scheduler) registerSendPendingNotificationsJob() error {
 		return err
 	}
 
-	task := asynq.NewTask(shared.TypeSendPendingNotifications, payload)
+	task := newScheduledTask(shared.TypeSendPendingNotifications, payload)
 
 	_, err = s.scheduler.Register(
 		"0 7 * * *", // Daily at 7 AM
@@ -169,7 +256,7 @@ func (s *Scheduler) registerCleanupOldNotificationsJob() error {
 		return err
 	}
 
-	task := asynq.NewTask(shared.TypeCleanupOldNotifications, payload)
+	task := newScheduledTask(shared.TypeCleanupOldNotifications, payload)
 
 	_, err = s.scheduler.Register(
 		"0 3 * * *", // Daily at 3 AM (staggered from other cleanup jobs)
@@ -203,7 +290,7 @@ func (s *Scheduler) registerRetryFailedDeliveriesJob() error {
 	if err != nil {
 		logger.Error("marshal payload: ", err)
 	}
-	task := asynq.NewTask(shared.TypeRetryFailedDeliveries, payloadBytes)
+	task := newScheduledTask(shared.TypeRetryFailedDeliveries, payloadBytes)
 
 	_, err = s.scheduler.Register(
 		"*/360 * * * *", // Every 6 hour
@@ -222,6 +309,504 @@ func (s *Scheduler) registerRetryFailedDeliveriesJob() error {
 	return nil
 }
 
+// ================================================
+// JOB 6: Refresh Personalized Feeds (Every hour)
+// ================================================
+// WHY EVERY HOUR?
+// - Feed cache TTL = 6h, refresh hourly giữ feed của active user luôn ấm
+// - Chỉ tính cho user hoạt động 7 ngày gần nhất (không tốn cho user bỏ đi)
+func (s *Scheduler) registerRefreshFeedsJob() error {
+	payload, err := json.Marshal(recommendationJob.RefreshFeedsPayload{Limit: 1000})
+	if err != nil {
+		return err
+	}
+
+	task := newScheduledTask(shared.TypeRefreshFeeds, payload)
+
+	_, err = s.scheduler.Register(
+		"15 * * * *", // Every hour at minute 15
+		task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(1),
+		asynq.Timeout(15*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register RefreshFeeds job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered RefreshFeeds: every hour", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 7: Backfill Analytics Identities (Every 30 minutes)
+// ================================================
+// WHY?
+// - Event ghi async → có thể tới SAU khi session đã được stitch lúc login
+// - Job điền user_id cho các event đó để funnel không bị đứt
+func (s *Scheduler) registerBackfillIdentitiesJob() error {
+	payload, err := json.Marshal(analyticsModel.BackfillIdentitiesPayload{
+		BatchSize: analyticsModel.DefaultBackfillBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	task := newScheduledTask(shared.TypeBackfillIdentities, payload)
+
+	_, err = s.scheduler.Register(
+		"*/30 * * * *", // Every 30 minutes
+		task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(1),
+		asynq.Timeout(5*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register BackfillIdentities job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered BackfillIdentities: every 30 minutes", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 8: Compute Cart Funnel Metrics (Every hour)
+// ================================================
+// WHY RECOMPUTE 2 DAYS?
+// - Đơn được thanh toán muộn / event tới muộn / identity stitch sau → số liệu hôm qua vẫn thay đổi
+// - Chạy sau BackfillIdentities (phút 0, 30) để funnel dùng user_id mới nhất
+func (s *Scheduler) registerComputeFunnelJob() error {
+	payload, err := json.Marshal(analyticsModel.ComputeFunnelPayload{
+		Days: analyticsModel.FunnelRecomputeDays,
+	})
+	if err != nil {
+		return err
+	}
+
+	task := newScheduledTask(shared.TypeComputeFunnel, payload)
+
+	_, err = s.scheduler.Register(
+		"45 * * * *", // Every hour at minute 45
+		task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(1),
+		asynq.Timeout(10*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register ComputeFunnel job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered ComputeFunnel: every hour", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 9: Seed Vietnam National Holidays (Weekly, Monday 1:30 AM)
+// ================================================
+// WHY WEEKLY?
+//   - Lịch Tết năm sau phải có trước mùa đặt hàng cuối năm (ước tính giao hàng / SLA kho)
+//   - Năm đã seed thì job bỏ qua → chạy thường xuyên gần như không tốn gì,
+//     deploy mới / DB trống có lịch trong vòng 1 tuần (admin seed tay được ngay)
+func (s *Scheduler) registerSeedNationalHolidaysJob() error {
+	payload, err := json.Marshal(warehouseJob.SeedHolidaysPayload{YearsAhead: 1})
+	if err != nil {
+		return err
+	}
+
+	task := newScheduledTask(shared.TypeSeedNationalHolidays, payload)
+
+	_, err = s.scheduler.Register(
+		"30 1 * * 1", // Every Monday at 1:30 AM
+		task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.MaxRetry(3),
+		asynq.Timeout(2*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register SeedNationalHolidays job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered SeedNationalHolidays: weekly on Monday 1:30 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 10: Check Ticket SLA (Every 10 minutes)
+// ================================================
+// SLA ngắn nhất là 1h (phản hồi ticket urgent) → 10 phút đủ để mốc trễ chính xác cho báo cáo
+func (s *Scheduler) registerCheckTicketSLAJob() error {
+	task := newScheduledTask(shared.TypeCheckTicketSLA, nil)
+
+	_, err := s.scheduler.Register(
+		"*/10 * * * *", // Every 10 minutes
+		task,
+		asynq.Queue(shared.QueueOrder),
+		asynq.MaxRetry(1),
+		asynq.Timeout(1*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register CheckTicketSLA job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered CheckTicketSLA: every 10 minutes", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 11: Generate Affiliate Commission Reports (Monthly, day 1 at 4 AM)
+// ================================================
+// Tính hoa hồng tháng trước cho từng đối tác → pending_approval chờ admin duyệt
+// Đơn giao trễ (shipper cập nhật delivered muộn) vẫn lọt vào nếu admin generate lại trước khi duyệt
+func (s *Scheduler) registerGenerateAffiliateCommissionsJob() error {
+	task := newScheduledTask(shared.TypeGenerateAffiliateCommissions, nil)
+
+	_, err := s.scheduler.Register(
+		"0 4 1 * *", // Day 1 of every month at 4 AM
+		task,
+		asynq.Queue(shared.QueueOrder),
+		asynq.MaxRetry(3),
+		asynq.Timeout(10*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register GenerateAffiliateCommissions job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered GenerateAffiliateCommissions: monthly on day 1 at 4 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 12: Reconcile Marketplace Stock (Daily at 1 AM)
+// ================================================
+// Đẩy lại tồn + giá toàn catalog lên các sàn: sửa lệch do sàn tự trừ tồn khi bán / lần đẩy theo sự kiện bị lỗi
+// Chạy lúc ít đơn nhất để quota API của sàn dành cho job theo sự kiện ban ngày
+func (s *Scheduler) registerReconcileMarketplaceStockJob() error {
+	task := newScheduledTask(shared.TypeMarketplaceReconcileStock, nil)
+
+	_, err := s.scheduler.Register(
+		"0 1 * * *", // Daily at 1 AM
+		task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.MaxRetry(2),
+		asynq.Timeout(2*time.Hour),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register ReconcileMarketplaceStock job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered ReconcileMarketplaceStock: daily at 1 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 13: Cleanup Checkout Failures (Daily at 3:30 AM)
+// ================================================
+// Chẩn đoán checkout thất bại chỉ giữ CheckoutFailureRetention (30 ngày) cho CSKH tra cứu
+// Chạy sau CleanupOldNotifications để không tranh tài nguyên
+func (s *Scheduler) registerCleanupCheckoutFailuresJob() error {
+	task := newScheduledTask(shared.TypeCleanupCheckoutFailures, nil)
+
+	_, err := s.scheduler.Register(
+		"30 3 * * *", // Daily at 3:30 AM
+		task,
+		asynq.Queue(shared.QueueOrder),
+		asynq.MaxRetry(2),
+		asynq.Timeout(10*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register CleanupCheckoutFailures job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered CleanupCheckoutFailures: daily at 3:30 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 14: Cleanup Anonymous Carts (Every hour at minute 20)
+// ================================================
+// Giỏ ẩn danh rỗng không hoạt động quá CART_ANON_EMPTY_MAX_AGE_HOURS: phần lớn do bot / crawler không giữ cookie
+// Chạy mỗi giờ để bảng carts không phình giữa 2 lần dọn khi bị bot dội
+func (s *Scheduler) registerCleanupAnonymousCartsJob() error {
+	task := newScheduledTask(shared.TypeCleanupAnonymousCarts, nil)
+
+	_, err := s.scheduler.Register(
+		"20 * * * *", // Every hour at minute 20
+		task,
+		asynq.Queue(shared.QueueOrder),
+		asynq.MaxRetry(1),
+		asynq.Timeout(10*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register CleanupAnonymousCarts job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered CleanupAnonymousCarts: every hour at minute 20", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 15: Warn Expiring Carts (Every hour at minute 40)
+// ================================================
+// Giỏ user còn hàng hết hạn trong CART_EXPIRY_WARNING_HOURS tới → nhắc 1 lần (in-app + email theo template)
+func (s *Scheduler) registerWarnExpiringCartsJob() error {
+	task := newScheduledTask(shared.TypeWarnExpiringCarts, nil)
+
+	_, err := s.scheduler.Register(
+		"40 * * * *", // Every hour at minute 40
+		task,
+		asynq.Queue(shared.QueueOrder),
+		asynq.MaxRetry(1),
+		asynq.Timeout(10*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register WarnExpiringCarts job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered WarnExpiringCarts: every hour at minute 40", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 16: Reconcile Checkouts (Every 10 minutes)
+// ================================================
+// Saga checkout kẹt / bù trừ lỗi → xử lý lại; đơn chưa thanh toán quá hạn + CHECKOUT_HOLD_GRACE_MINUTES
+// còn giữ hàng (task auto-release bị mất) → huỷ, trả tồn
+func (s *Scheduler) registerReconcileCheckoutsJob() error {
+	task := newScheduledTask(shared.TypeReconcileCheckouts, nil)
+
+	_, err := s.scheduler.Register(
+		"*/10 * * * *", // Every 10 minutes
+		task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.MaxRetry(1),
+		asynq.Timeout(5*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register ReconcileCheckouts job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered ReconcileCheckouts: every 10 minutes", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 17: Compute Co-Purchases (Daily at 5 AM)
+// ================================================
+// Tính lại cặp sách hay được mua cùng từ order_items (self-join nặng → chạy giờ thấp điểm,
+// sau các job dọn dẹp 1-4h sáng)
+func (s *Scheduler) registerComputeCoPurchasesJob() error {
+	task := newScheduledTask(shared.TypeComputeCoPurchases, nil)
+
+	_, err := s.scheduler.Register(
+		"0 5 * * *", // Daily at 5 AM
+		task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(2),
+		asynq.Timeout(30*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register ComputeCoPurchases job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered ComputeCoPurchases: daily at 5 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 18: Dispatch Low Stock Alerts (Every minute)
+// ================================================
+// Cảnh báo do trigger check_low_stock tạo → email / Slack cho người nhận immediate của kho
+// (claim notified_at trước khi gửi nên chạy chồng / retry không gửi trùng)
+func (s *Scheduler) registerDispatchLowStockAlertsJob() error {
+	task := newScheduledTask(shared.TypeDispatchLowStockAlerts, nil)
+
+	_, err := s.scheduler.Register(
+		"* * * * *", // Every minute
+		task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.MaxRetry(1),
+		asynq.Timeout(2*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register DispatchLowStockAlerts job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered DispatchLowStockAlerts: every minute", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 19: Low Stock Digest (Hourly at :05)
+// ================================================
+// Gộp cảnh báo tạo trong giờ qua thành 1 tin / người nhận digest (tránh :15, :40 của job khác)
+func (s *Scheduler) registerLowStockDigestJob() error {
+	task := newScheduledTask(shared.TypeSendLowStockDigest, nil)
+
+	_, err := s.scheduler.Register(
+		"5 * * * *", // Hourly at minute 5
+		task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.MaxRetry(1),
+		asynq.Timeout(5*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register LowStockDigest job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered LowStockDigest: hourly at :05", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 20: Compute Demand Forecasts (Weekly, Monday 4:30 AM)
+// ================================================
+// Fit lại dự báo nhu cầu từ order_items sau khi tuần cũ khép lại (trước job co-purchases 5h sáng)
+func (s *Scheduler) registerComputeDemandForecastsJob() error {
+	task := newScheduledTask(shared.TypeComputeDemandForecasts, nil)
+
+	_, err := s.scheduler.Register(
+		"30 4 * * 1", // Weekly, Monday 4:30 AM
+		task,
+		asynq.Queue(shared.QueueInventory),
+		asynq.MaxRetry(2),
+		asynq.Timeout(30*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register ComputeDemandForecasts job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered ComputeDemandForecasts: weekly on Monday at 4:30 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 21: Apply Price Schedules (Every minute)
+// ================================================
+// Áp lịch đổi giá admin hẹn giờ (giá lệch tối đa ~1 phút so với effective_at)
+// Mỗi lịch claim FOR UPDATE SKIP LOCKED nên chạy chồng / retry không áp trùng
+func (s *Scheduler) registerApplyPriceSchedulesJob() error {
+	task := newScheduledTask(shared.TypeApplyPriceSchedules, nil)
+
+	_, err := s.scheduler.Register(
+		"* * * * *", // Every minute
+		task,
+		asynq.Queue(shared.QueueBook),
+		asynq.MaxRetry(1),
+		asynq.Timeout(2*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register ApplyPriceSchedules job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered ApplyPriceSchedules: every minute", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 22: Scan Book Data Quality (Daily at 2:30 AM)
+// ================================================
+// Sách thiếu cover / mô tả / danh mục hoặc không có tồn ở kho nào → book_data_quality_issues
+// Chạy trước giờ làm để catalog manager mở báo cáo là thấy số liệu mới
+func (s *Scheduler) registerScanBookDataQualityJob() error {
+	task := newScheduledTask(shared.TypeScanBookDataQuality, nil)
+
+	_, err := s.scheduler.Register(
+		"30 2 * * *", // Daily at 2:30 AM
+		task,
+		asynq.Queue(shared.QueueBook),
+		asynq.MaxRetry(2),
+		asynq.Timeout(10*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register ScanBookDataQuality job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered ScanBookDataQuality: daily at 2:30 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 23: Send Ops Digest (Daily at 0:20 AM UTC)
+// ================================================
+// Tổng hợp kết quả nghiệp vụ của background job ngày UTC hôm qua (7:20 sáng giờ VN, đầu ca vận hành)
+func (s *Scheduler) registerSendOpsDigestJob() error {
+	task := newScheduledTask(shared.TypeSendOpsDigest, nil)
+
+	_, err := s.scheduler.Register(
+		"20 0 * * *", // Daily at 0:20 AM
+		task,
+		asynq.Queue(shared.QueueAnalytics),
+		asynq.MaxRetry(1),
+		asynq.Timeout(2*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register SendOpsDigest job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered SendOpsDigest: daily at 0:20 AM", map[string]interface{}{})
+	return nil
+}
+
+// ================================================
+// JOB 24: Cleanup Idempotency Keys (Daily at 3:45 AM)
+// ================================================
+// Key quá expires_at (24h) không còn được replay; Reserve chỉ ghi đè khi client dùng lại đúng key
+func (s *Scheduler) registerCleanupIdempotencyKeysJob() error {
+	task := newScheduledTask(shared.TypeCleanupIdempotencyKeys, nil)
+
+	_, err := s.scheduler.Register(
+		"45 3 * * *", // Daily at 3:45 AM
+		task,
+		asynq.Queue(shared.QueueOrder),
+		asynq.MaxRetry(2),
+		asynq.Timeout(10*time.Minute),
+	)
+
+	if err != nil {
+		logger.Error("Failed to register CleanupIdempotencyKeys job", err)
+		return err
+	}
+
+	logger.Info("✓ Registered CleanupIdempotencyKeys: daily at 3:45 AM", map[string]interface{}{})
+	return nil
+}
+
 func (s *Scheduler) Start() error {
 	return s.scheduler.Run()
 }
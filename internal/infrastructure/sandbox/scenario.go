@@ -0,0 +1,71 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ================================================
+// FAKE PROVIDER SCENARIOS
+// ================================================
+// Provider giả lập (PAYMENT_PROVIDER_MODE / SHIPPING_PROVIDER_MODE = fake) chọn kịch bản cố định
+// theo phần lẻ nghìn của số tiền (giống thẻ test của Stripe) → test E2E tự chọn kết quả bằng giá sách:
+//   ...000 và còn lại → success
+//   ...001            → failure (hãng từ chối: thẻ không đủ số dư / tuyến không phục vụ)
+//   ...002            → timeout (hãng không phản hồi)
+// Cổng thanh toán giả lập còn nhận ?sandbox_scenario= để ép kịch bản khi bấm tay trên staging.
+
+type Scenario string
+
+const (
+	ScenarioSuccess Scenario = "success"
+	ScenarioFailure Scenario = "failure"
+	ScenarioTimeout Scenario = "timeout"
+)
+
+var (
+	// ErrRejected hãng giả lập từ chối yêu cầu (kịch bản failure)
+	ErrRejected = errors.New("sandbox: request rejected by fake provider")
+	// ErrTimeout hãng giả lập không phản hồi (kịch bản timeout)
+	ErrTimeout = errors.New("sandbox: fake provider timed out")
+)
+
+var thousand = decimal.NewFromInt(1000)
+
+// ForAmount kịch bản theo phần lẻ nghìn của số tiền (VND)
+func ForAmount(amount decimal.Decimal) Scenario {
+	switch amount.Round(0).Mod(thousand).IntPart() {
+	case 1:
+		return ScenarioFailure
+	case 2:
+		return ScenarioTimeout
+	default:
+		return ScenarioSuccess
+	}
+}
+
+// Parse kịch bản từ query / header (rỗng hoặc không hợp lệ → false)
+func Parse(s string) (Scenario, bool) {
+	switch Scenario(s) {
+	case ScenarioSuccess, ScenarioFailure, ScenarioTimeout:
+		return Scenario(s), true
+	}
+	return "", false
+}
+
+// Hang giả lập hãng treo: chờ d (hoặc tới khi ctx huỷ) rồi trả ErrTimeout
+func Hang(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
@@ -0,0 +1,76 @@
+package sentry
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sentrygo "github.com/getsentry/sentry-go"
+
+	"bookstore-backend/pkg/logger"
+)
+
+// Config cấu hình client Sentry (DSN rỗng → không khởi tạo)
+type Config struct {
+	DSN           string
+	Environment   string
+	Release       string
+	SamplePercent int // % event lỗi được gửi (0-100)
+}
+
+// flushTimeout chờ gửi nốt event trong hàng đợi khi shutdown
+const flushTimeout = 2 * time.Second
+
+// NewClient khởi tạo sentry-go client và gắn vào hub gốc (sentrygin clone hub này cho mỗi request)
+// SDK lo parse DSN, sampling, transport (hàng đợi + retry); ở đây chỉ cấu hình + scrub dữ liệu nhạy cảm
+func NewClient(cfg Config) (*sentrygo.Client, error) {
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{
+		Dsn:              cfg.DSN,
+		Environment:      cfg.Environment,
+		Release:          cfg.Release,
+		SampleRate:       float64(cfg.SamplePercent) / 100,
+		AttachStacktrace: true,
+		// Không gửi cookie, Authorization, IP client (SDK tự lọc khi SendDefaultPII = false)
+		SendDefaultPII: false,
+		BeforeSend:     scrubEvent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sentry: %w", err)
+	}
+
+	sentrygo.CurrentHub().BindClient(client)
+	return client, nil
+}
+
+// Flush chờ event còn trong hàng đợi được gửi (gọi lúc shutdown)
+func Flush(client *sentrygo.Client) {
+	if client == nil {
+		return
+	}
+	if !client.Flush(flushTimeout) {
+		logger.Info("Sentry flush timed out, some events may be lost", nil)
+	}
+}
+
+// sensitiveHeaders header chứa credential ngoài danh sách mặc định của SDK
+var sensitiveHeaders = []string{"x-api-key", "x-captcha-token"}
+
+// scrubEvent bỏ header credential + mask email lẫn trong message / query string trước khi gửi ra ngoài
+func scrubEvent(event *sentrygo.Event, _ *sentrygo.EventHint) *sentrygo.Event {
+	if event.Request != nil {
+		for key := range event.Request.Headers {
+			for _, sensitive := range sensitiveHeaders {
+				if strings.EqualFold(key, sensitive) {
+					delete(event.Request.Headers, key)
+				}
+			}
+		}
+		event.Request.QueryString = logger.MaskString(event.Request.QueryString)
+	}
+
+	event.Message = logger.MaskString(event.Message)
+	for i := range event.Exception {
+		event.Exception[i].Value = logger.MaskString(event.Exception[i].Value)
+	}
+	return event
+}
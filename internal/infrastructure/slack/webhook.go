@@ -0,0 +1,58 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ================================================
+// SLACK INCOMING WEBHOOK
+// ================================================
+// Mỗi webhook URL gắn sẵn 1 channel (cấu hình bên Slack) → chỉ cần POST {"text": ...}.
+// Text dùng mrkdwn của Slack (*bold*, `code`, xuống dòng \n).
+
+// WebhookClient gửi tin qua Slack incoming webhook
+type WebhookClient struct {
+	httpClient *http.Client
+}
+
+func NewWebhookClient() *WebhookClient {
+	return &WebhookClient{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type webhookMessage struct {
+	Text string `json:"text"`
+}
+
+// Send POST text tới webhook URL; Slack trả 200 "ok", lỗi trả 4xx kèm mô tả ngắn (invalid_payload, no_service...)
+func (c *WebhookClient) Send(ctx context.Context, webhookURL, text string) error {
+	payload, err := json.Marshal(webhookMessage{Text: text})
+	if err != nil {
+		return fmt.Errorf("slack: marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("slack: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack: send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("slack: webhook returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
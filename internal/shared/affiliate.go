@@ -0,0 +1,19 @@
+package shared
+
+import (
+	"regexp"
+	"strings"
+)
+
+// affiliateCodeRegex - khớp CHECK của affiliate_partners.code (chữ hoa, số, '_', '-', 3-32 ký tự)
+var affiliateCodeRegex = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{2,31}$`)
+
+// NormalizeAffiliateCode trim + viết hoa mã affiliate, trả "" nếu sai định dạng
+// Dùng chung cho cookie / query param lúc checkout và admin tạo đối tác
+func NormalizeAffiliateCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !affiliateCodeRegex.MatchString(code) {
+		return ""
+	}
+	return code
+}
@@ -0,0 +1,152 @@
+package locale
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ngôn ngữ hỗ trợ cho notification/email (users.locale, notification_templates.language)
+const (
+	Vietnamese = "vi"
+	English    = "en"
+
+	// Default - locale khi user chưa chọn hoặc template chưa có bản dịch
+	Default = Vietnamese
+)
+
+// Supported trả về danh sách locale hỗ trợ (dùng cho validation)
+func Supported() []interface{} {
+	return []interface{}{Vietnamese, English}
+}
+
+// IsSupported kiểm tra locale có template/bản dịch không
+func IsSupported(code string) bool {
+	switch code {
+	case Vietnamese, English:
+		return true
+	}
+	return false
+}
+
+// Normalize chuẩn hoá "en-US", "EN", "" → locale hỗ trợ (fallback Default)
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	if IsSupported(code) {
+		return code
+	}
+	return Default
+}
+
+// ================================================
+// FORMATTERS
+// Giá trị chèn vào template ({{estimated_delivery}}, {{expires_in}}, ...) phải theo ngôn ngữ của template,
+// payload chỉ mang dữ liệu trung lập (số ngày, số giờ, mã phương thức thanh toán)
+// ================================================
+
+// FormatDayRange: 3, 5 → "3-5 ngày" / "3-5 days"
+func FormatDayRange(locale string, minDays, maxDays int) string {
+	days := fmt.Sprintf("%d-%d", minDays, maxDays)
+	if minDays == maxDays {
+		days = fmt.Sprintf("%d", minDays)
+	}
+
+	if Normalize(locale) == English {
+		if maxDays == 1 {
+			return days + " day"
+		}
+		return days + " days"
+	}
+	return days + " ngày"
+}
+
+// FormatHours: 24 → "24 giờ" / "24 hours"
+func FormatHours(locale string, hours int) string {
+	if Normalize(locale) == English {
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d giờ", hours)
+}
+
+// FormatVND: 50000 → "50.000 ₫" / "50,000 VND"
+func FormatVND(locale string, amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	sep, suffix := ".", " ₫"
+	if Normalize(locale) == English {
+		sep, suffix = ",", " VND"
+	}
+
+	digits := fmt.Sprintf("%d", amount)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String() + suffix
+}
+
+var paymentMethodLabels = map[string]map[string]string{
+	Vietnamese: {
+		"cash_on_delivery": "Thanh toán khi nhận hàng (COD)",
+		"bank_transfer":    "Chuyển khoản ngân hàng",
+		"e_wallet":         "Ví điện tử",
+		"credit_card":      "Thẻ tín dụng",
+		"pay_link":         "Thanh toán qua link",
+	},
+	English: {
+		"cash_on_delivery": "Cash on delivery (COD)",
+		"bank_transfer":    "Bank transfer",
+		"e_wallet":         "E-wallet",
+		"credit_card":      "Credit card",
+		"pay_link":         "Payment link",
+	},
+}
+
+// PaymentMethod trả về tên phương thức thanh toán theo locale, mã lạ thì trả nguyên mã
+func PaymentMethod(locale, method string) string {
+	if label, ok := paymentMethodLabels[Normalize(locale)][method]; ok {
+		return label
+	}
+	return method
+}
+
+var orderStatusLabels = map[string]map[string]string{
+	Vietnamese: {
+		"pending":    "Chờ xác nhận",
+		"confirmed":  "Đã xác nhận",
+		"processing": "Đang chuẩn bị hàng",
+		"shipping":   "Đang giao hàng",
+		"delivered":  "Đã giao hàng",
+		"cancelled":  "Đã huỷ",
+		"returned":   "Đã hoàn trả",
+	},
+	English: {
+		"pending":    "Pending confirmation",
+		"confirmed":  "Confirmed",
+		"processing": "Preparing your order",
+		"shipping":   "Out for delivery",
+		"delivered":  "Delivered",
+		"cancelled":  "Cancelled",
+		"returned":   "Returned",
+	},
+}
+
+// OrderStatus trả về tên trạng thái đơn theo locale, mã lạ thì trả nguyên mã
+func OrderStatus(locale, status string) string {
+	if label, ok := orderStatusLabels[Normalize(locale)][status]; ok {
+		return label
+	}
+	return status
+}
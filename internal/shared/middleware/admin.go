@@ -33,3 +33,21 @@ func AdminMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// StaffMiddleware checks if user is staff serving customers (admin or cskh)
+// Dùng cho các thao tác thay mặt khách (vd: dựng giỏ + đặt đơn qua điện thoại)
+func StaffMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		if role != "admin" && role != "cskh" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Access denied: staff role required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/shared"
+)
+
+// ===================================
+// CONSTANTS
+// ===================================
+
+const (
+	AffiliateQueryParam   = "aff"
+	AffiliateCookieName   = "aff_code"
+	AffiliateCookieMaxAge = 60 * 60 * 24 * 30 // 30 ngày attribution window
+
+	ContextKeyAffiliateCode = "affiliate_code"
+)
+
+// ===================================
+// AFFILIATE ATTRIBUTION MIDDLEWARE
+// ===================================
+
+// AffiliateAttribution ghi nhận mã đối tác giới thiệu
+//
+// Flow:
+// 1. Link đối tác có ?aff=CODE hợp lệ → set cookie aff_code 30 ngày (last click wins)
+// 2. Không có query → đọc cookie aff_code
+// 3. Mã (đã chuẩn hoá) đặt vào context, checkout / tạo đơn lấy qua GetAffiliateCode
+// 4. Mã sai định dạng bị bỏ qua, không bao giờ chặn request
+//
+// Usage:
+//
+//	router.Use(middleware.AffiliateAttribution(cookieSecure))
+func AffiliateAttribution(cookieSecure bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if code := shared.NormalizeAffiliateCode(c.Query(AffiliateQueryParam)); code != "" {
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(AffiliateCookieName, code, AffiliateCookieMaxAge, "/", "", cookieSecure, true)
+			c.Set(ContextKeyAffiliateCode, code)
+		} else if cookie, err := c.Cookie(AffiliateCookieName); err == nil {
+			if code := shared.NormalizeAffiliateCode(cookie); code != "" {
+				c.Set(ContextKeyAffiliateCode, code)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// GetAffiliateCode mã affiliate của request (query param hoặc cookie), "" nếu không có
+func GetAffiliateCode(c *gin.Context) string {
+	return c.GetString(ContextKeyAffiliateCode)
+}
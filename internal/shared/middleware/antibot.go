@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/internal/shared/utils"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+)
+
+// ===================================
+// INTERFACES
+// ===================================
+
+// CaptchaVerifier verifies a CAPTCHA token (Turnstile)
+// Returns (false, nil) for invalid/expired token, error only when provider is unreachable
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// DeviceFingerprintLogger records high-risk requests for fraud review (fraud domain)
+type DeviceFingerprintLogger interface {
+	LogDevice(ctx context.Context, event shared.DeviceFingerprintEvent) error
+}
+
+// ===================================
+// CONSTANTS
+// ===================================
+
+const (
+	HeaderCaptchaToken      = "X-Captcha-Token"
+	HeaderDeviceFingerprint = "X-Device-Fingerprint"
+
+	FingerprintSourceClient  = "client"  // client gửi qua header (FingerprintJS, ...)
+	FingerprintSourceDerived = "derived" // server tự tính từ User-Agent + Accept-Language
+
+	ErrCodeCaptchaRequired = "CAPTCHA_REQUIRED"
+
+	maxFingerprintLength = 128
+)
+
+// ===================================
+// MIDDLEWARE CONFIGURATION
+// ===================================
+
+// AntiBotConfig holds configuration for one protected action
+type AntiBotConfig struct {
+	Action    string        // add_to_cart, apply_promo, validate_promo, track_order
+	Enabled   bool          // false → chỉ log fingerprint, không bắt CAPTCHA
+	Threshold int           // số request trong Window trước khi bắt CAPTCHA (<= 0: tắt)
+	Window    time.Duration // cửa sổ đếm request
+	PassTTL   time.Duration // giải CAPTCHA xong được miễn kiểm tra trong khoảng này
+
+	Cache    cache.Cache
+	Verifier CaptchaVerifier
+	Logger   DeviceFingerprintLogger
+}
+
+// ===================================
+// ANTI-BOT MIDDLEWARE
+// ===================================
+
+// AntiBot protects high-risk actions of anonymous users (rapid add-to-cart, promo code guessing)
+//
+// Flow:
+// 1. Tính device fingerprint (header X-Device-Fingerprint, hoặc hash UA + Accept-Language)
+// 2. User đã đăng nhập → bỏ qua CAPTCHA (đã có tài khoản để truy vết)
+// 3. Khách vãng lai: đếm request theo session (hoặc IP nếu bot bỏ cookie) trong Window
+// 4. Vượt Threshold → bắt buộc header X-Captcha-Token hợp lệ, sai/thiếu → 403 CAPTCHA_REQUIRED
+// 5. Mọi request đều được log fingerprint (async) cho fraud review
+//
+// Redis/Turnstile lỗi → fail open (không chặn khách thật), chỉ log
+//
+// Usage:
+//
+//	cart.POST("/items", middleware.AntiBot(cfg), c.CartHandler.AddItem)
+func AntiBot(cfg AntiBotConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fingerprint, source := deviceFingerprint(c)
+
+		event := shared.DeviceFingerprintEvent{
+			Action:            cfg.Action,
+			Fingerprint:       fingerprint,
+			FingerprintSource: source,
+			SessionID:         anonymousSessionID(c),
+			IPAddress:         utils.ExtractClientIP(c),
+			UserAgent:         c.Request.UserAgent(),
+			AcceptLanguage:    c.GetHeader("Accept-Language"),
+			Path:              c.FullPath(),
+			OccurredAt:        time.Now(),
+		}
+
+		userID, isAuth := GetAuthenticatedUserID(c)
+		if isAuth {
+			event.UserID = userID.String()
+		}
+
+		if cfg.Enabled && !isAuth && cfg.Threshold > 0 && cfg.Cache != nil && cfg.Verifier != nil {
+			if !checkCaptcha(c, cfg, &event) {
+				logDeviceEvent(c, cfg.Logger, event)
+				c.JSON(http.StatusForbidden, gin.H{
+					"success": false,
+					"error":   "Captcha verification required",
+					"code":    ErrCodeCaptchaRequired,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		logDeviceEvent(c, cfg.Logger, event)
+		c.Next()
+	}
+}
+
+// checkCaptcha trả về false khi request phải bị chặn
+func checkCaptcha(c *gin.Context, cfg AntiBotConfig, event *shared.DeviceFingerprintEvent) bool {
+	ctx := c.Request.Context()
+
+	identity := "ip:" + event.IPAddress
+	if event.SessionID != "" {
+		identity = "session:" + event.SessionID
+	}
+	passKey := fmt.Sprintf("antibot:pass:%s", identity)
+	counterKey := fmt.Sprintf("antibot:%s:%s", cfg.Action, identity)
+
+	passed, err := cfg.Cache.Exists(ctx, passKey)
+	if err != nil {
+		logger.Error("antibot: check captcha pass failed", err)
+		return true
+	}
+	if passed {
+		return true
+	}
+
+	count, err := cfg.Cache.Increment(ctx, counterKey)
+	if err != nil {
+		logger.Error("antibot: increment request counter failed", err)
+		return true
+	}
+	if count == 1 {
+		if err := cfg.Cache.Expire(ctx, counterKey, cfg.Window); err != nil {
+			logger.Error("antibot: set counter ttl failed", err)
+		}
+	}
+	event.RequestCount = count
+
+	if count <= int64(cfg.Threshold) {
+		return true
+	}
+
+	event.CaptchaRequired = true
+	ok, err := cfg.Verifier.Verify(ctx, c.GetHeader(HeaderCaptchaToken), event.IPAddress)
+	if err != nil {
+		logger.Error("antibot: captcha verification unavailable", err)
+		return true
+	}
+	event.CaptchaPassed = &ok
+	if !ok {
+		return false
+	}
+
+	// Đã giải CAPTCHA → miễn kiểm tra cho mọi action trong PassTTL, reset counter
+	if err := cfg.Cache.Set(ctx, passKey, true, cfg.PassTTL); err != nil {
+		logger.Error("antibot: store captcha pass failed", err)
+	}
+	if err := cfg.Cache.Delete(ctx, counterKey); err != nil {
+		logger.Error("antibot: reset request counter failed", err)
+	}
+	return true
+}
+
+// deviceFingerprint ưu tiên fingerprint client gửi, không có thì tự tính (kém chính xác hơn)
+func deviceFingerprint(c *gin.Context) (string, string) {
+	if fp := strings.TrimSpace(c.GetHeader(HeaderDeviceFingerprint)); fp != "" {
+		if len(fp) > maxFingerprintLength {
+			fp = fp[:maxFingerprintLength]
+		}
+		return fp, FingerprintSourceClient
+	}
+
+	sum := sha256.Sum256([]byte(c.Request.UserAgent() + "|" + c.GetHeader("Accept-Language")))
+	return hex.EncodeToString(sum[:]), FingerprintSourceDerived
+}
+
+// anonymousSessionID - session từ CartMiddleware nếu đã chạy, nếu không đọc thẳng cookie
+func anonymousSessionID(c *gin.Context) string {
+	if sessionID := GetSessionID(c); sessionID != "" {
+		return sessionID
+	}
+	return GetSessionIDFromCookie(c)
+}
+
+func logDeviceEvent(c *gin.Context, deviceLogger DeviceFingerprintLogger, event shared.DeviceFingerprintEvent) {
+	if deviceLogger == nil {
+		return
+	}
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := deviceLogger.LogDevice(ctx, event); err != nil {
+			logger.Error("Failed to log device fingerprint", err)
+		}
+	}()
+}
@@ -68,6 +68,11 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		c.Set("is_authenticated", true)
 		c.Set("user_id", userID)
 
+		// 7. Role (dùng cho AdminMiddleware, WarehouseScopeMiddleware)
+		if role, ok := claims["role"].(string); ok {
+			c.Set("role", role)
+		}
+
 		// Tiếp tục xử lý request
 		c.Next()
 	}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"bookstore-backend/internal/shared/response"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -125,6 +128,12 @@ func CartMiddleware(config CartMiddlewareConfig) gin.HandlerFunc {
 		// ===================================
 		anonCartID, err := config.CartService.GetOrCreateCartBySession(c.Request.Context(), sessionID)
 		if err != nil {
+			// IP tạo quá nhiều giỏ ẩn danh (bot không giữ cookie) → 429, không tạo thêm row
+			var quotaErr interface{ RetryAfter() time.Duration }
+			if errors.As(err, &quotaErr) {
+				response.TooManyRequests(c, quotaErr.RetryAfter(), "Too many carts created, please retry later", err.Error())
+				return
+			}
 			c.Set("cart_error", err.Error())
 		} else {
 			cartID = anonCartID
@@ -212,6 +221,12 @@ func IsAnonymousCart(c *gin.Context) bool {
 	return anonymous
 }
 
+// GetSessionIDFromCookie reads the anonymous session ID directly from cookie.
+// Dùng cho route không chạy CartMiddleware (login, book detail)
+func GetSessionIDFromCookie(c *gin.Context) string {
+	return getSessionID(c)
+}
+
 // GetSessionID retrieves session ID from context
 func GetSessionID(c *gin.Context) string {
 	sessionID, exists := c.Get(ContextKeySessionID)
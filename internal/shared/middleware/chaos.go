@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"bookstore-backend/internal/shared/response"
+	"bookstore-backend/pkg/chaos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Chaos cho phép test E2E bật lỗi riêng cho 1 request qua header X-Chaos
+// (vd "reserve_stock_fail=100,db_latency=500ms"), không ảnh hưởng request khác.
+// No-op khi CHAOS_ENABLED=false (header bị bỏ qua, không lộ cơ chế ra production).
+func Chaos() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("X-Chaos")
+		if header == "" || !chaos.Enabled() {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		cfg, err := chaos.ParseOverride(header, chaos.Current(ctx))
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "INVALID_CHAOS_HEADER", err.Error())
+			return
+		}
+
+		c.Request = c.Request.WithContext(chaos.WithOverride(ctx, cfg))
+		c.Next()
+	}
+}
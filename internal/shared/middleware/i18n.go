@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/shared/locale"
+)
+
+// UserLocaleResolver trả về ngôn ngữ user đã lưu (implement bởi user.Service)
+type UserLocaleResolver interface {
+	GetLocale(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+const (
+	ContextKeyLocale         = "locale"
+	contextKeyLocaleResolver = "locale_resolver"
+)
+
+// I18n xác định ngôn ngữ của request
+//
+// Thứ tự ưu tiên:
+// 1. Preference đã lưu của user đăng nhập (users.locale)
+// 2. Accept-Language header
+// 3. locale.Default
+//
+// WHY resolve lazy trong GetLocale?
+// - I18n chạy global, trước AuthMiddleware (gắn theo route group) → lúc này chưa có user_id
+// - Chỉ handler cần locale mới tốn 1 lần lookup user (FindByID có cache Redis)
+func I18n(resolver UserLocaleResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		headerLocale := locale.Normalize(c.GetHeader("Accept-Language"))
+
+		c.Set(ContextKeyLocale, headerLocale)
+		if resolver != nil {
+			c.Set(contextKeyLocaleResolver, resolver)
+		}
+		c.Header("Content-Language", headerLocale)
+		c.Next()
+	}
+}
+
+// GetLocale trả về ngôn ngữ của request hiện tại (xem I18n)
+func GetLocale(c *gin.Context) string {
+	current := c.GetString(ContextKeyLocale)
+	if current == "" {
+		current = locale.Default
+	}
+
+	userID, ok := c.Get(ContextKeyUserID)
+	if !ok {
+		return current
+	}
+	uid, ok := userID.(uuid.UUID)
+	if !ok {
+		return current
+	}
+	resolver, ok := c.Value(contextKeyLocaleResolver).(UserLocaleResolver)
+	if !ok || resolver == nil {
+		return current
+	}
+
+	stored, err := resolver.GetLocale(c.Request.Context(), uid)
+	if err != nil || stored == "" {
+		return current
+	}
+
+	// Cache cho các lần gọi sau trong cùng request (bỏ resolver → không lookup lại)
+	c.Set(ContextKeyLocale, stored)
+	c.Set(contextKeyLocaleResolver, nil)
+	c.Header("Content-Language", stored)
+	return stored
+}
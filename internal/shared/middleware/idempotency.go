@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/shared"
+	"bookstore-backend/pkg/logger"
+)
+
+// ===================================
+// INTERFACES
+// ===================================
+
+// IdempotencyStore persists responses keyed by Idempotency-Key (order domain IdempotencyRepository)
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, record *shared.IdempotencyRecord) (*shared.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, userID, scope, key string, status int, body []byte) error
+	Release(ctx context.Context, userID, scope, key string) error
+}
+
+// ===================================
+// CONSTANTS
+// ===================================
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotency-Replayed"
+
+	IdempotencyScopeCartCheckout = "cart_checkout"
+	IdempotencyScopeOrderCreate  = "order_create"
+
+	ErrCodeIdempotencyKeyInvalid = "IDEMPOTENCY_KEY_INVALID"
+	ErrCodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	ErrCodeIdempotencyInProgress = "IDEMPOTENCY_REQUEST_IN_PROGRESS"
+
+	DefaultIdempotencyTTL   = 24 * time.Hour
+	maxIdempotencyKeyLength = 255
+	idempotencyStoreTimeout = 5 * time.Second
+)
+
+// ===================================
+// MIDDLEWARE CONFIGURATION
+// ===================================
+
+// IdempotencyConfig holds configuration for one protected endpoint
+type IdempotencyConfig struct {
+	Scope string        // cart_checkout, order_create
+	TTL   time.Duration // thời gian lưu response (<= 0: DefaultIdempotencyTTL)
+	Store IdempotencyStore
+}
+
+// ===================================
+// IDEMPOTENCY MIDDLEWARE
+// ===================================
+
+// Idempotency replays the original response for retried POSTs carrying the same Idempotency-Key
+//
+// Flow:
+// 1. Không có header Idempotency-Key → chạy bình thường (header là tuỳ chọn)
+// 2. Giữ chỗ (user, scope, key) với hash của method + path + body
+// 3. Key đã hoàn tất, cùng body → trả lại đúng status + body đã lưu (header Idempotency-Replayed: true)
+// 4. Key đã hoàn tất, khác body → 422 IDEMPOTENCY_KEY_REUSED
+// 5. Key đang xử lý (request song song) → 409 IDEMPOTENCY_REQUEST_IN_PROGRESS
+// 6. Handler trả < 500 → lưu response; 5xx hoặc panic → xoá key để client retry được
+// 7. Key hết hạn được job order:cleanup_idempotency_keys xoá định kỳ
+//
+// Cần AuthMiddleware chạy trước (key tách theo user). Store lỗi → fail open, chỉ log
+//
+// Usage:
+//
+//	cart.POST("/checkout", middleware.Idempotency(cfg), c.CartHandler.Checkout)
+func Idempotency(cfg IdempotencyConfig) gin.HandlerFunc {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(HeaderIdempotencyKey))
+		if key == "" || cfg.Store == nil {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Idempotency-Key is too long",
+				"code":    ErrCodeIdempotencyKeyInvalid,
+			})
+			return
+		}
+
+		userID, isAuth := GetAuthenticatedUserID(c)
+		if !isAuth {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record := &shared.IdempotencyRecord{
+			UserID:      userID.String(),
+			Scope:       cfg.Scope,
+			Key:         key,
+			RequestHash: idempotencyRequestHash(c, body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		existing, reserved, err := cfg.Store.Reserve(c.Request.Context(), record)
+		if err != nil {
+			logger.Error("idempotency: reserve key failed", err)
+			c.Next()
+			return
+		}
+		if !reserved {
+			replayIdempotentResponse(c, record, existing)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// defer: handler panic (Recovery ở ngoài trả 500) cũng phải nhả key, không thì mọi retry
+		// cùng key bị 409 IN_PROGRESS tới expires_at
+		defer func() {
+			if r := recover(); r != nil {
+				releaseIdempotencyKey(c, cfg.Store, record)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			releaseIdempotencyKey(c, cfg.Store, record)
+			return
+		}
+
+		ctx, cancel := idempotencyStoreContext(c)
+		defer cancel()
+		if err := cfg.Store.Complete(ctx, record.UserID, record.Scope, record.Key, status, writer.body.Bytes()); err != nil {
+			logger.Error("idempotency: store response failed", err)
+		}
+	}
+}
+
+// idempotencyStoreContext - request đã xong: lưu / nhả key kể cả khi client ngắt kết nối giữa chừng
+func idempotencyStoreContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(c.Request.Context()), idempotencyStoreTimeout)
+}
+
+// releaseIdempotencyKey xoá key đang processing (handler 5xx / panic) để client retry được
+func releaseIdempotencyKey(c *gin.Context, store IdempotencyStore, record *shared.IdempotencyRecord) {
+	ctx, cancel := idempotencyStoreContext(c)
+	defer cancel()
+	if err := store.Release(ctx, record.UserID, record.Scope, record.Key); err != nil {
+		logger.Error("idempotency: release key failed", err)
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, record, existing *shared.IdempotencyRecord) {
+	if existing.Status != shared.IdempotencyStatusCompleted {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "A request with this Idempotency-Key is still being processed",
+			"code":    ErrCodeIdempotencyInProgress,
+		})
+		return
+	}
+	if existing.RequestHash != record.RequestHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error":   "Idempotency-Key was already used for a different request",
+			"code":    ErrCodeIdempotencyKeyReused,
+		})
+		return
+	}
+
+	c.Header(HeaderIdempotencyReplayed, "true")
+	c.Data(existing.ResponseStatus, "application/json; charset=utf-8", existing.ResponseBody)
+	c.Abort()
+}
+
+// idempotencyRequestHash - cùng key nhưng khác endpoint/body là request khác
+func idempotencyRequestHash(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyResponseWriter ghi response ra client đồng thời giữ bản sao để lưu
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"bookstore-backend/internal/shared"
+)
+
+// fakeIdempotencyStore luôn giữ chỗ được, ghi lại Release / Complete
+type fakeIdempotencyStore struct {
+	released  int
+	completed int
+}
+
+func (s *fakeIdempotencyStore) Reserve(context.Context, *shared.IdempotencyRecord) (*shared.IdempotencyRecord, bool, error) {
+	return nil, true, nil
+}
+
+func (s *fakeIdempotencyStore) Complete(context.Context, string, string, string, int, []byte) error {
+	s.completed++
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Release(context.Context, string, string, string) error {
+	s.released++
+	return nil
+}
+
+func newIdempotencyTestRouter(store IdempotencyStore, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	router.POST("/checkout",
+		func(c *gin.Context) {
+			c.Set("is_authenticated", true)
+			c.Set(ContextKeyUserID, uuid.New())
+		},
+		Idempotency(IdempotencyConfig{Scope: IdempotencyScopeCartCheckout, Store: store}),
+		handler,
+	)
+	return router
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	store := &fakeIdempotencyStore{}
+	router := newIdempotencyTestRouter(store, func(*gin.Context) {
+		panic("checkout exploded")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/checkout", nil)
+	req.Header.Set(HeaderIdempotencyKey, "key-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 from Recovery (panic re-raised)", w.Code)
+	}
+	if store.released != 1 || store.completed != 0 {
+		t.Errorf("released = %d, completed = %d, want 1, 0", store.released, store.completed)
+	}
+}
+
+func TestIdempotencyStoresSuccessfulResponse(t *testing.T) {
+	store := &fakeIdempotencyStore{}
+	router := newIdempotencyTestRouter(store, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"success": true})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/checkout", nil)
+	req.Header.Set(HeaderIdempotencyKey, "key-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if store.released != 0 || store.completed != 1 {
+		t.Errorf("released = %d, completed = %d, want 0, 1", store.released, store.completed)
+	}
+}
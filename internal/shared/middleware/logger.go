@@ -3,6 +3,8 @@ package middleware
 import (
 	"time"
 
+	"bookstore-backend/pkg/tracing"
+
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -19,6 +21,7 @@ func Logger() gin.HandlerFunc {
 
 		log.Info().
 			Str("request_id", c.GetString("request_id")).
+			Str("trace_id", tracing.TraceIDFromContext(c.Request.Context())).
 			Str("method", c.Request.Method).
 			Str("path", path).
 			Int("status", status).
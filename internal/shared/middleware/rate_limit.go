@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bookstore-backend/internal/shared/response"
+	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/logger"
+)
+
+var rateLimitRejectionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "rate_limit_rejections_total",
+	Help: "Requests rejected with 429 by the rate limiter.",
+}, []string{"scope", "subject"})
+
+// RateLimitStore token bucket phân tán (implement bởi infrastructure/cache.RedisCache)
+type RateLimitStore interface {
+	TakeToken(ctx context.Context, key string, capacity int, refillPerSecond float64) (*cache.TokenBucketResult, error)
+}
+
+const ErrCodeRateLimited = "RATE_LIMITED"
+
+// RateLimitPolicy 1 loại bucket: Burst request liên tiếp, nạp lại PerMinute request / phút
+type RateLimitPolicy struct {
+	PerMinute int
+	Burst     int
+}
+
+// enabled - PerMinute <= 0: tắt bucket này
+func (p RateLimitPolicy) enabled() bool {
+	return p.PerMinute > 0
+}
+
+func (p RateLimitPolicy) capacity() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.PerMinute
+}
+
+// RateLimitConfig holds configuration for one rate limit scope
+type RateLimitConfig struct {
+	Scope         string          // global, promo, checkout → prefix key Redis + label metric
+	Anonymous     RateLimitPolicy // theo IP (không có token hợp lệ)
+	Authenticated RateLimitPolicy // theo user
+	JWTSecret     string          // nhận diện user khi middleware chạy trước AuthMiddleware
+	SkipPrefixes  []string        // health check, webhook cổng thanh toán / hãng vận chuyển
+
+	Store RateLimitStore
+}
+
+// RateLimit token bucket theo user (đã đăng nhập) hoặc theo IP (khách vãng lai)
+//
+// Flow:
+// 1. Xác định chủ thể: user_id do AuthMiddleware set, hoặc Bearer token hợp lệ, còn lại là IP
+// 2. Lấy 1 token từ bucket ratelimit:{scope}:{user|ip}:{id}
+// 3. Hết token → 429 + Retry-After (thời gian tới khi đủ 1 token)
+//
+// # Redis lỗi → fail open (không chặn khách thật), chỉ log
+//
+// Usage:
+//
+//	v1.Use(middleware.RateLimit(cfg))
+//	cart.POST("/checkout", middleware.RateLimit(checkoutCfg), c.CartHandler.Checkout)
+func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg.Store == nil || skipRateLimit(c.Request.URL.Path, cfg.SkipPrefixes) {
+			c.Next()
+			return
+		}
+
+		subject, id := rateLimitSubject(c, cfg.JWTSecret)
+		policy := cfg.Anonymous
+		if subject == "user" {
+			policy = cfg.Authenticated
+		}
+		if !policy.enabled() {
+			c.Next()
+			return
+		}
+
+		key := fmt.Sprintf("ratelimit:%s:%s:%s", cfg.Scope, subject, id)
+		result, err := cfg.Store.TakeToken(c.Request.Context(), key, policy.capacity(), float64(policy.PerMinute)/60)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Rate limit check failed (scope %s)", cfg.Scope), err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(policy.capacity()))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			rateLimitRejectionsTotal.WithLabelValues(cfg.Scope, subject).Inc()
+			response.TooManyRequests(c, result.RetryAfter, "Too many requests, please retry later", ErrCodeRateLimited)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitSubject ("user", user_id) hoặc ("ip", client IP)
+// Token sai / hết hạn tính theo IP: không để client đổi token rác để lách bucket.
+// IP lấy từ c.ClientIP(): chỉ đọc X-Forwarded-For khi request đi qua proxy tin cậy (APP_TRUSTED_PROXIES),
+// còn lại là RemoteAddr → client không đổi header giả để lấy bucket mới.
+func rateLimitSubject(c *gin.Context, jwtSecret string) (string, string) {
+	if userID, ok := GetAuthenticatedUserID(c); ok {
+		return "user", userID.String()
+	}
+
+	if jwtSecret != "" {
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			if claims, err := VerifyToken(token, jwtSecret); err == nil {
+				if userID, ok := claims["user_id"].(string); ok && userID != "" {
+					return "user", userID
+				}
+			}
+		}
+	}
+
+	return "ip", c.ClientIP()
+}
+
+func skipRateLimit(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitSubjectIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{"no trusted proxy: header ignored", nil, "203.0.113.7:5123", "198.51.100.1", "203.0.113.7"},
+		{"untrusted sender: header ignored", []string{"10.0.0.0/8"}, "203.0.113.7:5123", "198.51.100.1", "203.0.113.7"},
+		{"trusted load balancer: client from header", []string{"10.0.0.0/8"}, "10.0.0.5:5123", "198.51.100.1", "198.51.100.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+
+			var subject, id string
+			router.GET("/", func(c *gin.Context) {
+				subject, id = rateLimitSubject(c, "")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			req.Header.Set("X-Real-IP", tt.forwardedFor)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if subject != "ip" || id != tt.want {
+				t.Errorf("rateLimitSubject() = (%q, %q), want (\"ip\", %q)", subject, id, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"bookstore-backend/internal/shared/rbac"
+)
+
+// RequirePermission chặn request nếu role của caller thiếu 1 trong các permission
+//
+// Flow:
+// 1. Cần AuthMiddleware đứng trước (user_id, role trong context)
+// 2. Chưa đăng nhập → 401
+// 3. Role thiếu quyền → 403 (kèm quyền còn thiếu để frontend ẩn nút)
+//
+// Usage:
+//
+//	adminOrders.Use(middleware.AuthMiddleware(secret), middleware.RequirePermission(rbac.PermOrderRead))
+func RequirePermission(permissions ...rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ContextKeyUserID); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Unauthorized",
+			})
+			return
+		}
+
+		role, _ := c.Get("role")
+		roleName, _ := role.(string)
+
+		var missing []string
+		for _, p := range permissions {
+			if !rbac.Has(rbac.Role(roleName), p) {
+				missing = append(missing, string(p))
+			}
+		}
+		if len(missing) > 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Access denied: missing permission " + strings.Join(missing, ", "),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
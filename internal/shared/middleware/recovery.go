@@ -1,32 +1,79 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
+	"runtime"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
+// Recovery bắt panic của handler, trả 500 có cấu trúc (kèm request_id để tra log)
+// Gửi Sentry do sentrygin (đặt sau Recovery, Repanic: true) đảm nhận
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
-			if err := recover(); err != nil {
-				log.Error().
-					Str("request_id", c.GetString("request_id")).
-					Interface("error", err).
-					Msg("Panic recovered")
-
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"success": false,
-					"error": gin.H{
-						"code":    "SYS_001",
-						"message": "Internal server error",
-					},
-				})
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// Client đã ngắt kết nối giữa chừng: http.ErrAbortHandler, không phải lỗi của mình
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			requestID := c.GetString("request_id")
+			frames := panicFrames()
+
+			log.Error().
+				Str("request_id", requestID).
+				Str("method", c.Request.Method).
+				Str("path", c.Request.URL.Path).
+				Interface("error", recovered).
+				Str("stack", formatFrames(frames)).
+				Msg("Panic recovered")
+
+			// Handler đã ghi response một phần → không ghi đè được, chỉ dừng chain
+			if c.Writer.Written() {
 				c.Abort()
+				return
 			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":       "SYS_001",
+					"message":    "Internal server error",
+					"request_id": requestID,
+				},
+			})
 		}()
 
 		c.Next()
 	}
 }
+
+// panicFrames stack tại điểm panic (bỏ frame của runtime + chính middleware)
+func panicFrames() []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	iter := runtime.CallersFrames(pcs[:n])
+
+	var frames []runtime.Frame
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+func formatFrames(frames []runtime.Frame) string {
+	var out string
+	for _, f := range frames {
+		out += fmt.Sprintf("%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return out
+}
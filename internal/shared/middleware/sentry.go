@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+
+	sentrygo "github.com/getsentry/sentry-go"
+	sentrygin "github.com/getsentry/sentry-go/gin"
+	"github.com/gin-gonic/gin"
+)
+
+// SentryScope gắn request_id, route, user_id vào scope Sentry của request
+// Đặt ngay sau sentrygin.New (cần hub sentrygin gắn vào context)
+//
+// WHY set user trong defer:
+// - user_id do AuthMiddleware (middleware của route) set sau middleware global
+// - Khi panic, defer ở đây chạy trước recover của sentrygin → event vẫn có user_id
+func SentryScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hub := sentrygin.GetHubFromContext(c)
+		if hub == nil {
+			c.Next()
+			return
+		}
+
+		hub.Scope().SetTag("request_id", c.GetString("request_id"))
+		hub.Scope().SetTag("route", c.FullPath())
+
+		defer func() {
+			if userID, ok := c.Get(ContextKeyUserID); ok && userID != nil {
+				hub.Scope().SetUser(sentrygo.User{ID: fmt.Sprint(userID)})
+			}
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Timeout gắn deadline tổng cho request vào c.Request.Context()
+//
+// WHY không chạy handler trong goroutine riêng rồi trả 504 ngay khi hết giờ?
+// gin.Context không an toàn khi 2 goroutine cùng ghi response. Repository đều truyền ctx
+// xuống pgx/redis nên hết deadline thì query bị huỷ và handler tự trả về sớm.
+//
+// Route khớp skipSuffixes (SSE stream, export/import file lớn) không bị giới hạn.
+func Timeout(timeout time.Duration, skipSuffixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || skipTimeout(c.FullPath(), skipSuffixes) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return
+		}
+
+		requestID := c.GetString("request_id")
+		log.Warn().
+			Str("request_id", requestID).
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Dur("timeout", timeout).
+			Msg("Request deadline exceeded")
+
+		// Handler đã tự trả lỗi (thường là 500 từ query bị huỷ) → giữ nguyên
+		if c.Writer.Written() {
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":       "SYS_002",
+				"message":    "Request timed out",
+				"request_id": requestID,
+			},
+		})
+	}
+}
+
+func skipTimeout(route string, skipSuffixes []string) bool {
+	for _, suffix := range skipSuffixes {
+		if strings.HasSuffix(route, suffix) {
+			return true
+		}
+	}
+	return false
+}
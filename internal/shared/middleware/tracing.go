@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing bổ sung cho span server của otelgin (đặt ngay sau otelgin.Middleware):
+// gắn request_id vào span và trả header traceparent để client/CSKH tra trace theo request.
+// Span + extract traceparent từ client/gateway do otelgin lo; query pgx, lệnh Redis, task enqueue
+// trong handler là span con qua ctx của c.Request.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		span := trace.SpanFromContext(ctx)
+		if !span.IsRecording() {
+			c.Next()
+			return
+		}
+
+		span.SetAttributes(attribute.String("request_id", c.GetString("request_id")))
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WarehouseAssignmentProvider defines minimal interface to resolve staff assignments
+// Used for dependency injection to avoid circular dependencies
+type WarehouseAssignmentProvider interface {
+	ListAssignedWarehouseIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// ContextKeyWarehouseScope holds []uuid.UUID of warehouses the caller may access.
+// Key is absent for admins (unrestricted).
+const ContextKeyWarehouseScope = "warehouse_scope"
+
+// WarehouseScopeMiddleware restricts inventory endpoints by role
+//
+// Flow:
+// 1. Requires AuthMiddleware before it (user_id, role in context)
+// 2. admin → unrestricted, no scope set
+// 3. warehouse → load assigned warehouses and set scope in context
+// 4. other roles → 403
+//
+// Usage:
+//
+//	inventory.Use(middleware.AuthMiddleware(secret), middleware.WarehouseScopeMiddleware(c.WarehouseService))
+func WarehouseScopeMiddleware(provider WarehouseAssignmentProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+
+		switch role {
+		case "admin":
+			c.Next()
+			return
+		case "warehouse":
+			userID, ok := c.Get(ContextKeyUserID)
+			uid, isUUID := userID.(uuid.UUID)
+			if !ok || !isUUID {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"success": false,
+					"error":   "Unauthorized",
+				})
+				c.Abort()
+				return
+			}
+
+			warehouseIDs, err := provider.ListAssignedWarehouseIDs(c.Request.Context(), uid)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"error":   "Failed to resolve warehouse assignments",
+				})
+				c.Abort()
+				return
+			}
+
+			c.Set(ContextKeyWarehouseScope, warehouseIDs)
+			c.Next()
+		default:
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Access denied: warehouse staff or admin role required",
+			})
+			c.Abort()
+		}
+	}
+}
+
+// GetWarehouseScope returns the warehouses the caller is limited to.
+// restricted = false means the caller can access every warehouse.
+func GetWarehouseScope(c *gin.Context) (warehouseIDs []uuid.UUID, restricted bool) {
+	v, exists := c.Get(ContextKeyWarehouseScope)
+	if !exists {
+		return nil, false
+	}
+	ids, _ := v.([]uuid.UUID)
+	return ids, true
+}
+
+// CanAccessWarehouse checks a single warehouse against the caller's scope
+func CanAccessWarehouse(c *gin.Context, warehouseID uuid.UUID) bool {
+	ids, restricted := GetWarehouseScope(c)
+	if !restricted {
+		return true
+	}
+	for _, id := range ids {
+		if id == warehouseID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Keyset (cursor) pagination cho danh sách lớn, song song với page/limit (OFFSET)
+//
+// WHY: OFFSET N vẫn phải quét + bỏ N hàng → trang sâu chậm dần; hàng mới chèn vào đầu danh sách
+// làm trang sau lặp lại / bỏ sót hàng. Cursor ghi (created_at, id) của hàng cuối trang trước,
+// trang sau chỉ lấy hàng "nhỏ hơn" theo đúng thứ tự ORDER BY created_at DESC, id DESC → đi thẳng
+// vào index, không phụ thuộc hàng chèn thêm.
+//
+// Client: ?pagination=cursor (trang đầu) rồi ?cursor=<next_cursor> cho tới khi next_cursor rỗng.
+// Cursor mode không COUNT(*): total / total_pages không có.
+
+// ModeCursor giá trị query ?pagination= bật cursor mode cho trang đầu
+const ModeCursor = "cursor"
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Cursor vị trí hàng cuối của trang trước: created_at + khoá định danh (1 UUID, hoặc nhiều khi
+// bảng có khoá ghép như warehouse_inventory(warehouse_id, book_id))
+type Cursor struct {
+	CreatedAt time.Time
+	IDs       []uuid.UUID
+}
+
+// IsCursorMode request dùng cursor (có cursor, hoặc trang đầu với ?pagination=cursor)
+func IsCursorMode(mode, cursor string) bool {
+	return cursor != "" || mode == ModeCursor
+}
+
+// Encode chuỗi opaque cho client (base64url, client không được tự dựng / sửa)
+func (c Cursor) Encode() string {
+	parts := make([]string, 0, len(c.IDs)+1)
+	parts = append(parts, strconv.FormatInt(c.CreatedAt.UnixMicro(), 10))
+	for _, id := range c.IDs {
+		parts = append(parts, id.String())
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, "|")))
+}
+
+// Decode đọc cursor từ client; idCount = số khoá của danh sách (cursor của danh sách khác bị từ chối)
+// Độ chính xác micro giây = timestamptz của Postgres → so sánh (created_at, id) không lệch
+func Decode(token string, idCount int) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != idCount+1 {
+		return nil, ErrInvalidCursor
+	}
+
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{
+		CreatedAt: time.UnixMicro(micros).UTC(),
+		IDs:       make([]uuid.UUID, 0, idCount),
+	}
+	for _, part := range parts[1:] {
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.IDs = append(cursor.IDs, id)
+	}
+	return cursor, nil
+}
+
+// Condition dựng "(col1, col2, ...) < ($n, $n+1, ...)" cho trang sau cursor, trả args theo thứ tự
+// columns: created_at trước rồi tới các cột khoá, cùng thứ tự với IDs
+func (c Cursor) Condition(columns []string, firstArg int) (string, []interface{}) {
+	placeholders := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns))
+	args = append(args, c.CreatedAt)
+	for _, id := range c.IDs {
+		args = append(args, id)
+	}
+	for i := range columns {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", firstArg+i))
+	}
+	return fmt.Sprintf("(%s) < (%s)", strings.Join(columns, ", "), strings.Join(placeholders, ", ")), args
+}
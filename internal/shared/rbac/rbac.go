@@ -0,0 +1,80 @@
+package rbac
+
+// =====================================================
+// ROLES + PERMISSIONS
+// =====================================================
+// Role lưu ở users.role (CHECK constraint migration 000001), đi kèm JWT claim "role".
+// Permission khai báo trong code: thêm quyền = sửa bảng rolePermissions + gắn vào route,
+// không cần migration. Route kiểm tra permission, không kiểm tra tên role
+// → đổi phạm vi 1 role không phải sửa router.
+
+type Role string
+
+const (
+	RoleAdmin          Role = "admin"
+	RoleWarehouseStaff Role = "warehouse" // nhân viên kho (phạm vi kho theo warehouse_staff_assignments)
+	RoleSupport        Role = "cskh"      // chăm sóc khách hàng
+	RoleCustomer       Role = "user"
+)
+
+type Permission string
+
+const (
+	// Catalog: sách, danh mục, tác giả, NXB
+	PermCatalogWrite Permission = "catalog:write"
+
+	// Kho + tồn kho
+	PermWarehouseRead   Permission = "warehouse:read"
+	PermWarehouseManage Permission = "warehouse:manage"
+	PermInventoryRead   Permission = "inventory:read"
+	PermInventoryWrite  Permission = "inventory:write"
+
+	// Đơn hàng
+	PermOrderRead         Permission = "order:read"
+	PermOrderUpdateStatus Permission = "order:update_status"
+
+	// Khách hàng (tài khoản, sổ địa chỉ)
+	PermCustomerRead   Permission = "customer:read"
+	PermCustomerManage Permission = "customer:manage"
+
+	// Thanh toán, hoàn tiền, sổ cái
+	PermPaymentRead   Permission = "payment:read"
+	PermPaymentManage Permission = "payment:manage"
+
+	// Khuyến mãi + thông báo marketing
+	PermPromotionManage    Permission = "promotion:manage"
+	PermNotificationManage Permission = "notification:manage"
+)
+
+// rolePermissions - admin không cần liệt kê (có mọi quyền)
+var rolePermissions = map[Role][]Permission{
+	RoleWarehouseStaff: {
+		PermWarehouseRead,
+		PermInventoryRead,
+		PermInventoryWrite,
+		PermOrderRead,
+		PermOrderUpdateStatus, // soạn hàng → đóng gói → bàn giao vận chuyển
+	},
+	RoleSupport: {
+		PermWarehouseRead,
+		PermInventoryRead,
+		PermOrderRead,
+		PermOrderUpdateStatus, // huỷ / xác nhận đơn theo yêu cầu khách
+		PermCustomerRead,
+		PermPaymentRead,
+	},
+	RoleCustomer: {},
+}
+
+// Has role có permission không; role lạ → không có quyền nào
+func Has(role Role, permission Permission) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,10 @@
 package response
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -46,3 +49,24 @@ func Error(c *gin.Context, statusCode int, message string, err interface{}) {
 		Code:    statusCode,
 	})
 }
+
+// ServiceUnavailable gửi 503 + header Retry-After (giây, làm tròn lên)
+// Dùng khi circuit breaker mở: client biết chờ bao lâu thay vì retry dồn dập
+func ServiceUnavailable(c *gin.Context, retryAfter time.Duration, message string, err interface{}) {
+	setRetryAfter(c, retryAfter)
+	Error(c, http.StatusServiceUnavailable, message, err)
+}
+
+// TooManyRequests gửi 429 + header Retry-After (vượt quota theo IP / user)
+func TooManyRequests(c *gin.Context, retryAfter time.Duration, message string, err interface{}) {
+	setRetryAfter(c, retryAfter)
+	Error(c, http.StatusTooManyRequests, message, err)
+}
+
+func setRetryAfter(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+}
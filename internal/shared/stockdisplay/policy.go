@@ -0,0 +1,64 @@
+package stockdisplay
+
+import "fmt"
+
+// Stock status trả về cho khách hàng (customer-facing), client dựa vào đây để render badge
+const (
+	StatusInStock    = "in_stock"
+	StatusLowStock   = "low_stock"
+	StatusOutOfStock = "out_of_stock"
+	// Degraded mode: DB / inventory đang lỗi, số tồn trong cache không còn tin được
+	StatusUnknown = "unknown"
+)
+
+// Policy - quy tắc hiển thị tồn kho cho khách: bao nhiêu số lượng được phép lộ ra
+//
+// WHY: số tồn chính xác là thông tin kinh doanh (đối thủ crawl được), và với khách
+// "Only 3 left" có ý nghĩa hơn "available: 1873". Admin/inventory API vẫn thấy số thật.
+type Policy struct {
+	// available <= ngưỡng → "Only N left" (<= 0: không có trạng thái low stock)
+	LowStockThreshold int
+	// available > ngưỡng → số trả về bị cap ở ngưỡng, client hiển thị "N+" (<= 0: không cap)
+	// Phải > LowStockThreshold để Apply idempotent (áp lên số đã cap vẫn ra cùng kết quả)
+	MaxVisibleQuantity int
+}
+
+// Display - kết quả áp policy lên 1 số tồn kho
+type Display struct {
+	Status   string
+	Message  string
+	Quantity int // số lượng được phép trả về client
+}
+
+// Apply tính status/message/số hiển thị từ số available thật
+func (p Policy) Apply(available int) Display {
+	if available <= 0 {
+		return Display{Status: StatusOutOfStock, Message: "Out of stock", Quantity: 0}
+	}
+
+	if p.LowStockThreshold > 0 && available <= p.LowStockThreshold {
+		return Display{
+			Status:   StatusLowStock,
+			Message:  fmt.Sprintf("Only %d left", available),
+			Quantity: available,
+		}
+	}
+
+	return Display{Status: StatusInStock, Message: "In stock", Quantity: p.VisibleQuantity(available)}
+}
+
+// Unknown hiển thị khi storefront chạy degraded mode (breaker mở): không trả số tồn
+func Unknown() Display {
+	return Display{Status: StatusUnknown, Message: "Availability temporarily unknown", Quantity: 0}
+}
+
+// VisibleQuantity cap số tồn ở MaxVisibleQuantity (không cap nếu policy tắt)
+func (p Policy) VisibleQuantity(available int) int {
+	if available < 0 {
+		return 0
+	}
+	if p.MaxVisibleQuantity > 0 && available > p.MaxVisibleQuantity {
+		return p.MaxVisibleQuantity
+	}
+	return available
+}
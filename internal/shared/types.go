@@ -21,25 +21,78 @@ const (
 	TypeSendResetEmail         = "email:reset_password"
 	TypeProcessBookImage       = "book:process_image"
 	TypeDeleteBookImages       = "book:delete_images"
+	TypeEnrichBookMetadata     = "book:enrich_metadata"
+	TypeApplyPriceSchedules    = "book:apply_price_schedules"
+	TypeScanBookDataQuality    = "book:scan_data_quality"
 	TypeInventorySyncBookStock = "inventory:sync_book_stock"
 	TypeClearCart              = "cart:clear"
 	TypeSendOrderConfirmation  = "order:send_confirmation"
 	TypeAutoReleaseReservation = "inventory:auto_release_reservation"
+	TypeDispatchLowStockAlerts = "inventory:dispatch_low_stock_alerts"
+	TypeSendLowStockDigest     = "inventory:send_low_stock_digest"
+	TypeComputeDemandForecasts = "inventory:compute_demand_forecasts"
+	TypeSendPaymentLink        = "order:send_payment_link"
 	TypeTrackCheckout          = "analytics:track_checkout"
+	TypeTrackEvent             = "analytics:track_event"
+	TypeRecordActivity         = "analytics:record_activity"
+	TypeStitchIdentity         = "analytics:stitch_identity"
+	TypeBackfillIdentities     = "analytics:backfill_identities"
+	TypeComputeFunnel          = "analytics:compute_funnel"
+	TypeRefreshFeeds           = "recommendation:refresh_feeds"
+	TypeComputeCoPurchases     = "recommendation:compute_co_purchases"
+	TypeLogDeviceFingerprint   = "fraud:log_device_fingerprint"
+	TypeGenerateDispatchLabels = "shipping:generate_dispatch_labels"
+	TypeCreateOrderShipment    = "shipping:create_order_shipment"
+	TypeIssueEInvoice          = "einvoice:issue"
+	TypeSeedNationalHolidays   = "warehouse:seed_national_holidays"
+	TypeCheckTicketSLA         = "ticket:check_sla"
+
+	// Affiliate: báo cáo hoa hồng tháng
+	TypeGenerateAffiliateCommissions = "affiliate:generate_commission_reports"
+
+	// Marketplace: đẩy tồn + giá lên sàn (theo sự kiện tồn kho / đổi giá + reconcile đêm)
+	TypeMarketplaceSyncStock      = "marketplace:sync_stock"
+	TypeMarketplaceReconcileStock = "marketplace:reconcile_stock"
+
+	// Import đơn offline / lịch sử từ CSV (admin upload)
+	TypeImportOrders = "order:import_orders"
+	// Cập nhật trạng thái đơn hàng loạt từ CSV (order_number, status, tracking_number)
+	TypeBulkUpdateOrderStatus = "order:bulk_update_status"
+
+	// Dọn Idempotency-Key đã hết hạn (checkout, tạo đơn)
+	TypeCleanupIdempotencyKeys = "order:cleanup_idempotency_keys"
 
 	// Promotion removal job
 	TypeRemoveExpiredPromotions = "cart:remove_expired_promotions"
 
+	// Checkout failure diagnostics: xoá bản ghi quá hạn giữ
+	TypeCleanupCheckoutFailures = "cart:cleanup_checkout_failures"
+
+	// Dọn giỏ ẩn danh rỗng không hoạt động (chống bot tạo giỏ vô hạn)
+	TypeCleanupAnonymousCarts = "cart:cleanup_anonymous_carts"
+
+	// Nhắc khách có giỏ còn hàng sắp hết hạn
+	TypeWarnExpiringCarts = "cart:warn_expiring_carts"
+
+	// Đối soát checkout saga + đơn quá hạn thanh toán còn giữ hàng
+	TypeReconcileCheckouts = "cart:reconcile_checkouts"
+
 	// Notification jobs
 	TypeSendPendingNotifications = "notification:send_pending"
 	TypeCleanupOldNotifications  = "notification:cleanup_old"
 	TypeRetryFailedDeliveries    = "notification:retry_failed"
+
+	// Push trạng thái đơn tới device của khách (FCM / APNs)
+	TypeSendOrderStatusPush = "notification:order_status_push"
+
+	// Digest vận hành hằng ngày: kết quả nghiệp vụ của background job (giữ hàng đã trả, email bỏ qua...)
+	TypeSendOpsDigest = "ops:send_daily_digest"
 )
 
 // SecurityAlertPayload represents data for security alert
 type SecurityAlertPayload struct {
 	UserID     string            `json:"userId"`
-	Email      string            `json:"email"`
+	Email      string            `json:"email" pii:"email"`
 	AlertType  SecurityAlertType `json:"alertType"`
 	DeviceInfo map[string]string `json:"deviceInfo"`
 	IPAddress  string            `json:"ipAddress"`
@@ -59,11 +112,53 @@ type UserBasicInfo struct {
 	FullName string
 }
 
+// DeviceFingerprintEvent - 1 request của action rủi ro cao (thêm giỏ, áp mã) kèm fingerprint thiết bị.
+// Đặt ở shared để middleware và fraud domain cùng dùng mà không import lẫn nhau
+type DeviceFingerprintEvent struct {
+	Action            string    `json:"action"`
+	Fingerprint       string    `json:"fingerprint"`
+	FingerprintSource string    `json:"fingerprint_source"` // client | derived
+	SessionID         string    `json:"session_id,omitempty"`
+	UserID            string    `json:"user_id,omitempty"`
+	IPAddress         string    `json:"ip_address"`
+	UserAgent         string    `json:"user_agent"`
+	AcceptLanguage    string    `json:"accept_language,omitempty"`
+	Path              string    `json:"path"`
+	RequestCount      int64     `json:"request_count"` // số lần action trong cửa sổ rate (0 = không đếm)
+	CaptchaRequired   bool      `json:"captcha_required"`
+	CaptchaPassed     *bool     `json:"captcha_passed,omitempty"`
+	OccurredAt        time.Time `json:"occurred_at"`
+}
+
+// IdempotencyRecord - response đã lưu theo Idempotency-Key (checkout, tạo đơn).
+// Đặt ở shared để middleware và order repository cùng dùng mà không import lẫn nhau
+type IdempotencyRecord struct {
+	UserID         string
+	Scope          string // cart_checkout | order_create
+	Key            string
+	RequestHash    string
+	Status         string // processing | completed
+	ResponseStatus int
+	ResponseBody   []byte
+	ExpiresAt      time.Time
+}
+
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
 type InventorySyncPayload struct {
 	BookID        string `json:"book_id"`                  // UUID của book
 	Source        string `json:"source,omitempty"`         // RESERVE|RELEASE|SALE|ADMIN_ADJUST|BULK_INVENTORY (optional)
 	CorrelationID string `json:"correlation_id,omitempty"` // trace id (optional)
 }
+
+// MarketplaceStockSyncPayload - sách cần đẩy lại tồn / giá lên các sàn bật đồng bộ
+type MarketplaceStockSyncPayload struct {
+	BookID string `json:"book_id"`
+	Source string `json:"source,omitempty"` // source của InventorySyncPayload, PRICE_CHANGE
+}
 type RetryFailedPayload struct {
 	Limit int `json:"limit"`
 }
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -92,13 +94,14 @@ func IsValidUUID(u string) bool {
 	return true
 }
 
-// MarshalTask creates an Asynq task with JSON payload
-func MarshalTask(taskType string, payload interface{}) (*asynq.Task, error) {
+// MarshalTaskContext creates an Asynq task with JSON payload, kèm trace context của ctx trong header task
+// (payload giữ nguyên) để worker tiếp tục trace. Không có bản không ctx: task tạo không ctx mất trace cha.
+func MarshalTaskContext(ctx context.Context, taskType string, payload interface{}) (*asynq.Task, error) {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("marshal task payload: %w", err)
 	}
-	return asynq.NewTask(taskType, data), nil
+	return tracing.NewTask(ctx, taskType, data), nil
 }
 
 // UnmarshalTask unmarshals Asynq task payload into target struct
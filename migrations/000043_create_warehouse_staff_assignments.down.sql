@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_warehouse_staff_assignments_warehouse;
+DROP TABLE IF EXISTS warehouse_staff_assignments;
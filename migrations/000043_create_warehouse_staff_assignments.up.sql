@@ -0,0 +1,28 @@
+-- ================================================
+-- Migration: Create Warehouse Staff Assignments
+-- Purpose: Scope warehouse staff to the warehouses they operate
+-- Version: 000043
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Users with role 'warehouse' must only see/mutate inventory of their own warehouses
+-- 2. One staff member can cover several warehouses (e.g. HN + DN)
+-- 3. Admins are never scoped, so they never need rows here
+
+CREATE TABLE IF NOT EXISTS warehouse_staff_assignments (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+
+    -- Audit: which admin granted the access
+    assigned_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, warehouse_id)
+);
+
+-- USE CASE: "Which staff work in this warehouse?"
+CREATE INDEX idx_warehouse_staff_assignments_warehouse
+ON warehouse_staff_assignments(warehouse_id);
+
+COMMENT ON TABLE warehouse_staff_assignments IS
+'Warehouses a staff user (role = warehouse) is allowed to view and mutate inventory for.';
@@ -0,0 +1,8 @@
+DELETE FROM inventory_audit_log
+WHERE action IN ('ADJUSTMENT_REQUESTED', 'ADJUSTMENT_APPROVED', 'ADJUSTMENT_REJECTED');
+
+ALTER TABLE inventory_audit_log DROP CONSTRAINT IF EXISTS inventory_audit_log_action_check;
+ALTER TABLE inventory_audit_log ADD CONSTRAINT inventory_audit_log_action_check
+    CHECK (action IN ('RESTOCK', 'RESERVE', 'RELEASE', 'ADJUSTMENT', 'SALE'));
+
+DROP TABLE IF EXISTS inventory_adjustment_requests;
@@ -0,0 +1,58 @@
+-- ================================================
+-- Migration: Create Inventory Adjustment Requests
+-- Purpose: Two-step approval for large manual stock adjustments
+-- Version: 000044
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Adjustments above a configured threshold must not be applied by a single admin
+-- 2. The request keeps the target quantity + inventory version seen by the requester
+-- 3. A second admin approves/rejects; approval applies the adjustment
+
+CREATE TABLE IF NOT EXISTS inventory_adjustment_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    book_id UUID NOT NULL REFERENCES books(id),
+
+    -- Snapshot at request time
+    old_quantity INT NOT NULL,
+    new_quantity INT NOT NULL CHECK (new_quantity >= 0),
+    expected_version INT NOT NULL,
+    reason TEXT NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+
+    requested_by UUID NOT NULL REFERENCES users(id),
+    reviewed_by UUID REFERENCES users(id),
+    review_note TEXT,
+    reviewed_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    -- Requester cannot approve own request
+    CONSTRAINT chk_adjustment_reviewer_differs
+        CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+-- USE CASE: Admin queue "pending adjustments"
+CREATE INDEX idx_adjustment_requests_status
+ON inventory_adjustment_requests(status, created_at DESC);
+
+-- USE CASE: Only one pending request per inventory row
+CREATE UNIQUE INDEX idx_adjustment_requests_pending_unique
+ON inventory_adjustment_requests(warehouse_id, book_id)
+WHERE status = 'pending';
+
+COMMENT ON TABLE inventory_adjustment_requests IS
+'Stock adjustments above the approval threshold, waiting for a second admin.';
+
+-- ================================================
+-- Audit log: allow approval workflow actions
+-- ================================================
+ALTER TABLE inventory_audit_log DROP CONSTRAINT IF EXISTS inventory_audit_log_action_check;
+ALTER TABLE inventory_audit_log ADD CONSTRAINT inventory_audit_log_action_check
+    CHECK (action IN (
+        'RESTOCK', 'RESERVE', 'RELEASE', 'ADJUSTMENT', 'SALE',
+        'ADJUSTMENT_REQUESTED', 'ADJUSTMENT_APPROVED', 'ADJUSTMENT_REJECTED'
+    ));
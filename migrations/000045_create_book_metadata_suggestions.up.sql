@@ -0,0 +1,45 @@
+-- ================================================
+-- Migration: Create Book Metadata Suggestions
+-- Purpose: Store metadata fetched from external APIs (Google Books, OpenLibrary)
+--          for admin review before it overwrites book data
+-- Version: 000045
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. External data can be wrong/low quality -> never write directly to books
+-- 2. Provenance: which source, which URL, when fetched, who accepted
+-- 3. One row per (book, field, source) so admin accepts/rejects per field
+
+CREATE TABLE IF NOT EXISTS book_metadata_suggestions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+
+    field TEXT NOT NULL CHECK (field IN ('cover_url', 'pages', 'description')),
+    suggested_value TEXT NOT NULL,
+    current_value TEXT, -- Snapshot of books.<field> at fetch time
+
+    -- Provenance
+    source TEXT NOT NULL CHECK (source IN ('google_books', 'open_library')),
+    source_url TEXT,
+    source_isbn TEXT NOT NULL,
+    fetched_at TIMESTAMPTZ DEFAULT NOW(),
+
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'rejected')),
+    reviewed_by UUID REFERENCES users(id),
+    reviewed_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- USE CASE: Admin reviews suggestions of one book
+CREATE INDEX idx_book_metadata_suggestions_book
+ON book_metadata_suggestions(book_id, status);
+
+-- USE CASE: Re-running enrichment refreshes the pending suggestion instead of duplicating it
+CREATE UNIQUE INDEX idx_book_metadata_suggestions_pending_unique
+ON book_metadata_suggestions(book_id, field, source)
+WHERE status = 'pending';
+
+COMMENT ON TABLE book_metadata_suggestions IS
+'Book metadata suggested by external APIs, applied to books only after admin acceptance.';
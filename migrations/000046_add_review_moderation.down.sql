@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS review_reports;
+
+DROP INDEX IF EXISTS idx_reviews_moderation_queue;
+
+ALTER TABLE reviews
+DROP COLUMN IF EXISTS moderated_at,
+DROP COLUMN IF EXISTS moderated_by,
+DROP COLUMN IF EXISTS report_count,
+DROP COLUMN IF EXISTS flag_reason,
+DROP COLUMN IF EXISTS is_flagged;
@@ -0,0 +1,51 @@
+-- ================================================
+-- Migration: Review Moderation & Abuse Reporting
+-- Purpose: Moderation queue (approve/reject/flag), user reports,
+--          auto-filter results on reviews
+-- Version: 000046
+-- ================================================
+
+-- WHY THESE COLUMNS?
+-- 1. is_flagged: review cần admin xem lại (auto-filter hoặc bị report nhiều)
+-- 2. flag_reason: lý do flag (banned words, link spam, user reports, admin)
+-- 3. report_count: denormalized để sort moderation queue không cần JOIN
+-- 4. moderated_by/moderated_at: ai duyệt, khi nào (audit)
+
+ALTER TABLE reviews
+ADD COLUMN IF NOT EXISTS is_flagged BOOLEAN DEFAULT false,
+ADD COLUMN IF NOT EXISTS flag_reason TEXT,
+ADD COLUMN IF NOT EXISTS report_count INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS moderated_by UUID REFERENCES users(id),
+ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ;
+
+-- USE CASE: Admin moderation queue (pending or flagged)
+CREATE INDEX IF NOT EXISTS idx_reviews_moderation_queue
+ON reviews(report_count DESC, created_at ASC)
+WHERE is_approved = false OR is_flagged = true;
+
+-- ================================================
+-- TABLE: review_reports
+-- ================================================
+CREATE TABLE IF NOT EXISTS review_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'offensive', 'off_topic', 'fake', 'other')),
+    note TEXT,
+
+    -- open: chờ xử lý | resolved: admin đã reject review | dismissed: admin giữ review
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    -- Mỗi user chỉ report 1 review 1 lần (chống spam report)
+    UNIQUE(review_id, reporter_id)
+);
+
+CREATE INDEX idx_review_reports_review ON review_reports(review_id, status);
+
+COMMENT ON TABLE review_reports IS 'User abuse reports on reviews';
+COMMENT ON COLUMN reviews.is_flagged IS 'Needs moderator attention (auto-filter, user reports or admin flag)';
@@ -0,0 +1,73 @@
+-- ================================================
+-- Migration: Create Product Q&A Tables
+-- Purpose: Users hỏi về sách, staff/verified buyer trả lời
+-- Version: 000047
+-- ================================================
+
+-- WHY SEPARATE FROM REVIEWS?
+-- 1. Review = đánh giá sau khi mua (1 user / 1 sách)
+-- 2. Question = ai cũng hỏi được, nhiều câu hỏi / nhiều câu trả lời
+-- 3. Answer có upvote để câu trả lời hữu ích nổi lên trên
+
+-- ================================================
+-- TABLE 1: PRODUCT_QUESTIONS
+-- ================================================
+CREATE TABLE IF NOT EXISTS product_questions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    content TEXT NOT NULL CHECK (char_length(content) BETWEEN 10 AND 1000),
+
+    -- published: hiển thị | hidden: admin ẩn
+    status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'hidden')),
+
+    -- Denormalized: số câu trả lời đang hiển thị (tránh COUNT khi list)
+    answer_count INT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- USE CASE: List câu hỏi của 1 sách (public)
+CREATE INDEX idx_product_questions_book ON product_questions(book_id, status, created_at DESC);
+CREATE INDEX idx_product_questions_user ON product_questions(user_id);
+
+-- ================================================
+-- TABLE 2: PRODUCT_ANSWERS
+-- ================================================
+CREATE TABLE IF NOT EXISTS product_answers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    question_id UUID NOT NULL REFERENCES product_questions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    content TEXT NOT NULL CHECK (char_length(content) BETWEEN 2 AND 2000),
+
+    -- Snapshot tại thời điểm trả lời (badge "Nhân viên" / "Đã mua hàng")
+    is_staff BOOLEAN NOT NULL DEFAULT false,
+    is_verified_buyer BOOLEAN NOT NULL DEFAULT false,
+
+    status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'hidden')),
+    upvote_count INT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- USE CASE: List câu trả lời (staff trước, upvote nhiều trước)
+CREATE INDEX idx_product_answers_question ON product_answers(question_id, status, is_staff DESC, upvote_count DESC);
+
+-- ================================================
+-- TABLE 3: PRODUCT_ANSWER_UPVOTES
+-- ================================================
+-- WHY COMPOSITE PK? Mỗi user chỉ upvote 1 answer 1 lần
+CREATE TABLE IF NOT EXISTS product_answer_upvotes (
+    answer_id UUID NOT NULL REFERENCES product_answers(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (answer_id, user_id)
+);
+
+COMMENT ON TABLE product_questions IS 'Customer questions on product pages';
+COMMENT ON TABLE product_answers IS 'Answers from staff or verified buyers';
+COMMENT ON COLUMN product_questions.answer_count IS 'Number of published answers (denormalized)';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS wishlists;
+DROP TABLE IF EXISTS user_book_views;
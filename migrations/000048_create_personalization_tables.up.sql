@@ -0,0 +1,40 @@
+-- ================================================
+-- Migration: Personalization (Recently Viewed + Wishlist)
+-- Purpose: Signals cho "For you" feed trên homepage
+-- Version: 000048
+-- ================================================
+
+-- WHY THESE TABLES?
+-- 1. books.view_count chỉ là tổng, không biết AI đã xem
+-- 2. Feed cần lịch sử xem + wishlist + đơn hàng của từng user
+-- 3. Upsert 1 row / (user, book) thay vì log mọi lượt xem (bảng nhỏ, query nhanh)
+
+-- ================================================
+-- TABLE 1: USER_BOOK_VIEWS (recently viewed)
+-- ================================================
+CREATE TABLE IF NOT EXISTS user_book_views (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    view_count INT NOT NULL DEFAULT 1,
+    last_viewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, book_id)
+);
+
+-- USE CASE: Recently viewed list + active users cho worker
+CREATE INDEX idx_user_book_views_recent ON user_book_views(user_id, last_viewed_at DESC);
+CREATE INDEX idx_user_book_views_last_viewed ON user_book_views(last_viewed_at DESC);
+
+-- ================================================
+-- TABLE 2: WISHLISTS
+-- ================================================
+CREATE TABLE IF NOT EXISTS wishlists (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE INDEX idx_wishlists_user ON wishlists(user_id, created_at DESC);
+
+COMMENT ON TABLE user_book_views IS 'Per-user book view history (one row per user/book)';
+COMMENT ON TABLE wishlists IS 'User wishlist';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS analytics_identities;
+DROP TABLE IF EXISTS analytics_events;
@@ -0,0 +1,54 @@
+-- ================================================
+-- Migration: Analytics Events + Identity Stitching
+-- Purpose: Ghi event của khách vãng lai (session) và gắn về user sau khi login
+-- Version: 000049
+-- ================================================
+
+-- WHY THESE TABLES?
+-- 1. Khách chưa login chỉ có session_id (cookie) → funnel bị đứt ở bước login
+-- 2. analytics_identities map session_id → user_id (ghi lúc login / MergeCart)
+-- 3. Backfill job điền user_id cho event cũ của session đã được map
+--    (event ghi async nên có thể tới SAU khi login → cần chạy định kỳ)
+
+-- ================================================
+-- TABLE 1: ANALYTICS_EVENTS
+-- ================================================
+CREATE TABLE IF NOT EXISTS analytics_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type TEXT NOT NULL, -- book_view, cart_add, checkout_completed
+    session_id TEXT,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    book_id UUID REFERENCES books(id) ON DELETE SET NULL,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    properties JSONB NOT NULL DEFAULT '{}'::jsonb,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Event phải gắn được với ít nhất 1 danh tính
+    CONSTRAINT chk_analytics_events_identity CHECK (session_id IS NOT NULL OR user_id IS NOT NULL)
+);
+
+-- USE CASE: Backfill theo session chưa có user
+CREATE INDEX idx_analytics_events_unattributed ON analytics_events(session_id)
+    WHERE user_id IS NULL;
+
+-- USE CASE: Funnel theo user / theo loại event
+CREATE INDEX idx_analytics_events_user ON analytics_events(user_id, occurred_at DESC)
+    WHERE user_id IS NOT NULL;
+CREATE INDEX idx_analytics_events_type_time ON analytics_events(event_type, occurred_at DESC);
+
+-- ================================================
+-- TABLE 2: ANALYTICS_IDENTITIES (session → user)
+-- ================================================
+CREATE TABLE IF NOT EXISTS analytics_identities (
+    session_id TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    stitched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_backfilled_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_analytics_identities_user ON analytics_identities(user_id);
+
+COMMENT ON TABLE analytics_events IS 'Raw funnel events (views, cart adds, checkouts) for anonymous sessions and users';
+COMMENT ON TABLE analytics_identities IS 'Anonymous session to user mapping, written on login';
+COMMENT ON COLUMN analytics_identities.last_backfilled_at IS 'Last time events of this session were attributed to user_id';
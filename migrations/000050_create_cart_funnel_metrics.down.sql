@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS cart_funnel_daily_metrics;
+
+DROP INDEX IF EXISTS idx_analytics_events_source;
+ALTER TABLE analytics_events DROP COLUMN IF EXISTS traffic_source;
@@ -0,0 +1,48 @@
+-- ================================================
+-- Migration: Cart Conversion Funnel Metrics
+-- Purpose: Số liệu funnel theo ngày + traffic source cho admin dashboard
+-- Version: 000050
+-- ================================================
+
+-- WHY PRE-AGGREGATE?
+-- 1. Dashboard đọc nhiều, tính trực tiếp trên analytics_events + orders rất nặng
+-- 2. Job tính lại vài ngày gần nhất mỗi giờ (event tới muộn, đơn thanh toán muộn, stitch identity)
+-- 3. Upsert theo (metric_date, traffic_source) → chạy lại bao nhiêu lần cũng được
+
+-- ================================================
+-- ANALYTICS_EVENTS: traffic source (first-touch attribution)
+-- ================================================
+ALTER TABLE analytics_events
+    ADD COLUMN IF NOT EXISTS traffic_source TEXT; -- utm_source hoặc host của referrer ngoài
+
+-- USE CASE: Tìm nguồn đầu tiên của user / session
+CREATE INDEX IF NOT EXISTS idx_analytics_events_source ON analytics_events(occurred_at)
+    WHERE traffic_source IS NOT NULL;
+
+-- ================================================
+-- TABLE: CART_FUNNEL_DAILY_METRICS
+-- ================================================
+CREATE TABLE IF NOT EXISTS cart_funnel_daily_metrics (
+    metric_date DATE NOT NULL,
+    traffic_source TEXT NOT NULL DEFAULT 'direct',
+
+    -- Distinct user/session theo từng bước
+    carts_created INT NOT NULL DEFAULT 0,
+    items_added INT NOT NULL DEFAULT 0,
+    checkouts_started INT NOT NULL DEFAULT 0,
+
+    -- Số đơn (1 user có thể đặt nhiều đơn)
+    orders_created INT NOT NULL DEFAULT 0,
+    orders_paid INT NOT NULL DEFAULT 0,
+    paid_revenue NUMERIC(14,2) NOT NULL DEFAULT 0,
+
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (metric_date, traffic_source)
+);
+
+CREATE INDEX idx_cart_funnel_source_date ON cart_funnel_daily_metrics(traffic_source, metric_date DESC);
+
+COMMENT ON TABLE cart_funnel_daily_metrics IS 'Daily cart conversion funnel per traffic source (UTC days)';
+COMMENT ON COLUMN cart_funnel_daily_metrics.orders_paid IS 'Orders created that day which have been paid (cohort by order date)';
+COMMENT ON COLUMN analytics_events.traffic_source IS 'utm_source or external referrer host; first non-null per user/session is used for attribution';
@@ -0,0 +1,35 @@
+-- ================================================
+-- Migration: Order Gifting
+-- Purpose: Giao đơn tới người nhận khác người mua, gift receipt (không in giá), hẹn ngày giao
+-- Version: 000051
+-- ================================================
+
+-- WHY SEPARATE TABLE?
+-- 1. Phần lớn đơn không phải quà → không thêm 9 cột NULL vào orders
+-- 2. Địa chỉ người nhận là SNAPSHOT (không phải address của user) → người mua không cần lưu địa chỉ người lạ vào sổ địa chỉ
+-- 3. orders.address_id vẫn là địa chỉ người mua (billing trên invoice)
+
+CREATE TABLE IF NOT EXISTS order_gifts (
+    order_id UUID PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+
+    -- Người nhận (ship-to)
+    recipient_name TEXT NOT NULL,
+    recipient_phone TEXT NOT NULL,
+    province TEXT NOT NULL,
+    district TEXT NOT NULL,
+    ward TEXT NOT NULL,
+    street TEXT NOT NULL,
+
+    gift_message TEXT CHECK (gift_message IS NULL OR LENGTH(gift_message) <= 500),
+    hide_prices BOOLEAN NOT NULL DEFAULT TRUE, -- gift receipt: packing slip không in giá
+    scheduled_delivery_date DATE,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: Kho lên lịch giao theo ngày hẹn
+CREATE INDEX idx_order_gifts_scheduled ON order_gifts(scheduled_delivery_date)
+    WHERE scheduled_delivery_date IS NOT NULL;
+
+COMMENT ON TABLE order_gifts IS 'Gift details of an order: distinct recipient, gift receipt and scheduled delivery';
+COMMENT ON COLUMN order_gifts.hide_prices IS 'Gift receipt: packing slip omits all prices';
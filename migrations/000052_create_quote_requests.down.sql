@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS order_payment_terms;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer'));
+
+DROP TRIGGER IF EXISTS update_quote_requests_updated_at ON quote_requests;
+DROP TABLE IF EXISTS quote_request_items;
+DROP TABLE IF EXISTS quote_requests;
@@ -0,0 +1,103 @@
+-- ================================================
+-- Migration: Corporate / Bulk Ordering (Quotes)
+-- Purpose: Khách doanh nghiệp gửi yêu cầu báo giá số lượng lớn, admin duyệt giá,
+--          chuyển báo giá thành đơn hàng thanh toán theo công nợ (payment terms)
+-- Version: 000052
+-- ================================================
+
+-- FLOW:
+-- pending  → admin báo giá → approved → khách chấp nhận → converted (tạo order)
+-- pending  → admin từ chối → rejected
+-- pending/approved → khách huỷ → cancelled
+-- approved quá valid_until → expired (đánh dấu lazy khi convert)
+
+CREATE TABLE IF NOT EXISTS quote_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    quote_number TEXT NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    address_id UUID NOT NULL REFERENCES addresses(id),
+
+    -- Thông tin doanh nghiệp (in trên invoice)
+    company_name TEXT NOT NULL,
+    tax_code TEXT,
+    contact_name TEXT NOT NULL,
+    contact_email TEXT NOT NULL,
+    contact_phone TEXT NOT NULL,
+
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'converted', 'cancelled', 'expired')),
+    customer_note TEXT,
+    admin_note TEXT,
+
+    -- Báo giá (set khi approved)
+    payment_terms_days INT CHECK (payment_terms_days IS NULL OR payment_terms_days BETWEEN 0 AND 90),
+    total_quantity INT NOT NULL CHECK (total_quantity > 0),
+    list_total NUMERIC(12,2) NOT NULL DEFAULT 0,     -- tổng theo giá niêm yết lúc gửi
+    quoted_total NUMERIC(12,2),                       -- tổng theo giá admin duyệt
+    valid_until TIMESTAMPTZ,
+    quoted_by UUID REFERENCES users(id),
+    quoted_at TIMESTAMPTZ,
+
+    converted_order_id UUID REFERENCES orders(id),
+    converted_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_quote_approved_pricing CHECK (
+        status NOT IN ('approved', 'converted')
+        OR (quoted_total IS NOT NULL AND payment_terms_days IS NOT NULL AND valid_until IS NOT NULL)
+    )
+);
+
+CREATE TABLE IF NOT EXISTS quote_request_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    quote_id UUID NOT NULL REFERENCES quote_requests(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    list_price NUMERIC(10,2) NOT NULL CHECK (list_price >= 0),  -- snapshot giá niêm yết
+    quoted_unit_price NUMERIC(10,2) CHECK (quoted_unit_price IS NULL OR quoted_unit_price >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (quote_id, book_id)
+);
+
+-- USE CASE: Khách xem danh sách báo giá của mình
+CREATE INDEX idx_quote_requests_user ON quote_requests(user_id, created_at DESC);
+
+-- USE CASE: Admin xử lý hàng đợi báo giá theo trạng thái
+CREATE INDEX idx_quote_requests_status ON quote_requests(status, created_at DESC);
+
+CREATE INDEX idx_quote_request_items_quote ON quote_request_items(quote_id);
+
+CREATE TRIGGER update_quote_requests_updated_at
+    BEFORE UPDATE ON quote_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ================================================
+-- ORDERS: payment method 'invoice' (thanh toán theo công nợ)
+-- ================================================
+-- WHY? Đơn B2B không thanh toán trước: giao hàng trước, xuất hoá đơn với hạn thanh toán.
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer', 'invoice'));
+
+-- WHY SEPARATE TABLE? (giống order_gifts)
+-- Chỉ đơn B2B có payment terms → không thêm cột NULL vào orders
+CREATE TABLE IF NOT EXISTS order_payment_terms (
+    order_id UUID PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    quote_id UUID NOT NULL REFERENCES quote_requests(id),
+    company_name TEXT NOT NULL,
+    tax_code TEXT,
+    terms_days INT NOT NULL CHECK (terms_days BETWEEN 0 AND 90),
+    due_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: Kế toán theo dõi công nợ sắp/đã quá hạn
+CREATE INDEX idx_order_payment_terms_due ON order_payment_terms(due_at);
+
+COMMENT ON TABLE quote_requests IS 'B2B bulk quote requests: admin pricing approval, converted into invoice-terms orders';
+COMMENT ON COLUMN quote_request_items.quoted_unit_price IS 'Unit price approved by admin; used instead of list price when converting';
+COMMENT ON TABLE order_payment_terms IS 'Payment terms of invoice orders created from quotes';
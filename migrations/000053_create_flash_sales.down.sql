@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS flash_sale_queue;
+DROP SEQUENCE IF EXISTS flash_sale_ticket_seq;
+DROP TRIGGER IF EXISTS update_flash_sales_updated_at ON flash_sales;
+DROP TABLE IF EXISTS flash_sales;
@@ -0,0 +1,80 @@
+-- ================================================
+-- Migration: Flash Sale Purchase Queue
+-- Purpose: Hàng đợi FIFO + purchase token có hạn cho flash sale số lượng giới hạn
+-- Version: 000053
+-- ================================================
+
+-- WHY QUEUE/TOKEN?
+-- 1. Flash sale: hàng nghìn user bấm mua cùng lúc → reserve_stock() lock cùng 1 row
+--    warehouse_inventory → contention, timeout, ai nhanh tay/retry nhiều thì thắng
+-- 2. Với queue: user lấy số thứ tự (ticket) → chỉ người được admit (có token) mới
+--    được gọi reserve_stock → số lượng gọi reserve ≈ số lượng hàng
+-- 3. Admission: số token đang active * per_user_limit <= stock còn lại → không oversell,
+--    token hết hạn không dùng → trả slot cho người tiếp theo trong hàng
+
+CREATE TABLE IF NOT EXISTS flash_sales (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    book_id UUID NOT NULL REFERENCES books(id),
+    sale_price NUMERIC(10,2) NOT NULL CHECK (sale_price >= 0),
+
+    stock_limit INT NOT NULL CHECK (stock_limit > 0),       -- số cuốn mở bán
+    sold_units INT NOT NULL DEFAULT 0 CHECK (sold_units >= 0),
+    per_user_limit INT NOT NULL DEFAULT 1 CHECK (per_user_limit > 0),
+    token_ttl_seconds INT NOT NULL DEFAULT 300 CHECK (token_ttl_seconds BETWEEN 30 AND 3600),
+
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_flash_sale_window CHECK (ends_at > starts_at),
+    CONSTRAINT chk_flash_sale_sold CHECK (sold_units <= stock_limit)
+);
+
+-- Ticket tăng dần toàn cục → thứ tự FIFO (rejoin sau khi token hết hạn = xếp cuối hàng)
+CREATE SEQUENCE IF NOT EXISTS flash_sale_ticket_seq START 1;
+
+CREATE TABLE IF NOT EXISTS flash_sale_queue (
+    flash_sale_id UUID NOT NULL REFERENCES flash_sales(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ticket BIGINT NOT NULL DEFAULT nextval('flash_sale_ticket_seq'),
+
+    status TEXT NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'admitted', 'used', 'expired')),
+    token UUID UNIQUE,                 -- cấp khi admitted
+    token_expires_at TIMESTAMPTZ,
+    order_id UUID REFERENCES orders(id),
+
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    admitted_at TIMESTAMPTZ,
+    used_at TIMESTAMPTZ,
+
+    PRIMARY KEY (flash_sale_id, user_id),
+    CONSTRAINT chk_flash_queue_token CHECK (
+        status NOT IN ('admitted', 'used') OR (token IS NOT NULL AND token_expires_at IS NOT NULL)
+    )
+);
+
+-- USE CASE: Admit người tiếp theo theo ticket + tính vị trí trong hàng
+CREATE INDEX idx_flash_sale_queue_waiting ON flash_sale_queue(flash_sale_id, ticket)
+    WHERE status = 'waiting';
+
+-- USE CASE: Đếm/expire token đang active
+CREATE INDEX idx_flash_sale_queue_admitted ON flash_sale_queue(flash_sale_id, token_expires_at)
+    WHERE status = 'admitted';
+
+-- USE CASE: Danh sách flash sale đang/sắp diễn ra
+CREATE INDEX idx_flash_sales_window ON flash_sales(starts_at, ends_at) WHERE is_active = TRUE;
+
+CREATE TRIGGER update_flash_sales_updated_at
+    BEFORE UPDATE ON flash_sales
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE flash_sales IS 'Limited-stock flash sales gated by a FIFO purchase-token queue';
+COMMENT ON TABLE flash_sale_queue IS 'FIFO queue entries; admitted entries hold a time-limited purchase token';
+COMMENT ON COLUMN flash_sale_queue.ticket IS 'Global monotonic ticket number, defines FIFO order';
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_cart_items_cart ON cart_items(cart_id);
+
+DROP INDEX IF EXISTS idx_inventory_book_covering;
+DROP INDEX IF EXISTS idx_cart_items_cart_created_covering;
@@ -0,0 +1,27 @@
+-- ================================================
+-- Migration: Covering indexes cho cart items hot path
+-- Purpose: Index-only scan cho GetItemsWithBooks / GetCheckoutItems
+-- Version: 000054
+-- ================================================
+
+-- WHY COVERING INDEX?
+-- 1. Mọi request cart (get/validate/checkout/merge) đều query cart_items theo cart_id
+--    ORDER BY created_at DESC → index cũ (cart_id) phải sort + heap fetch từng row
+-- 2. INCLUDE các cột được SELECT → Postgres đọc thẳng từ index (Index Only Scan),
+--    không cần sort, không cần chạm heap (khi visibility map đã set)
+-- 3. Stock: LATERAL SUM(quantity - reserved) theo book_id. idx_inventory_book cũ là
+--    partial (quantity > 0) và không chứa reserved → phải heap fetch
+
+CREATE INDEX IF NOT EXISTS idx_cart_items_cart_created_covering
+    ON cart_items(cart_id, created_at DESC)
+    INCLUDE (id, book_id, quantity, price, updated_at);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_book_covering
+    ON warehouse_inventory(book_id)
+    INCLUDE (quantity, reserved);
+
+-- Index cũ bị cover hoàn toàn bởi index mới (cart_id là leading column)
+DROP INDEX IF EXISTS idx_cart_items_cart;
+
+COMMENT ON INDEX idx_cart_items_cart_created_covering IS 'Covering index cho cart item listing/checkout (index-only scan, không sort)';
+COMMENT ON INDEX idx_inventory_book_covering IS 'Covering index cho tính tồn kho khả dụng theo book (quantity - reserved)';
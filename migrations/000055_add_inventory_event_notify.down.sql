@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_low_stock_alerts_notify ON low_stock_alerts;
+DROP TRIGGER IF EXISTS trg_inventory_audit_log_notify ON inventory_audit_log;
+DROP FUNCTION IF EXISTS notify_inventory_event();
@@ -0,0 +1,57 @@
+-- ================================================
+-- Migration: Inventory event notifications
+-- Purpose: Push inventory changes to API instances (SSE stream cho admin dashboard)
+-- Version: 000055
+-- ================================================
+
+-- WHY NOTIFY FROM THE AUDIT LOG?
+-- 1. Mọi thay đổi tồn kho (service, order flow, bulk job, SQL tay) đều đi qua
+--    trigger log_inventory_change() → inventory_audit_log là nguồn đầy đủ nhất
+-- 2. pg_notify chỉ gửi khi transaction COMMIT → không stream thay đổi bị rollback
+-- 3. Mọi API instance LISTEN cùng channel → không cần message broker riêng
+-- Payload giữ nhỏ (giới hạn NOTIFY 8000 bytes), client reload chi tiết qua API nếu cần
+
+CREATE OR REPLACE FUNCTION notify_inventory_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'low_stock_alerts' THEN
+        PERFORM pg_notify('inventory_events', json_build_object(
+            'event', 'LOW_STOCK_ALERT',
+            'warehouse_id', NEW.warehouse_id,
+            'book_id', NEW.book_id,
+            'new_quantity', NEW.current_quantity,
+            'alert_threshold', NEW.alert_threshold,
+            'created_at', NEW.created_at
+        )::text);
+    ELSE
+        PERFORM pg_notify('inventory_events', json_build_object(
+            'event', NEW.action,
+            'warehouse_id', NEW.warehouse_id,
+            'book_id', NEW.book_id,
+            'old_quantity', NEW.old_quantity,
+            'new_quantity', NEW.new_quantity,
+            'old_reserved', NEW.old_reserved,
+            'new_reserved', NEW.new_reserved,
+            'quantity_change', NEW.quantity_change,
+            'changed_by', NEW.changed_by,
+            'created_at', NEW.created_at
+        )::text);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger trên bảng partitioned được áp cho mọi partition (kể cả partition tạo sau)
+CREATE TRIGGER trg_inventory_audit_log_notify
+    AFTER INSERT ON inventory_audit_log
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_inventory_event();
+
+CREATE TRIGGER trg_low_stock_alerts_notify
+    AFTER INSERT ON low_stock_alerts
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_inventory_event();
+
+COMMENT ON FUNCTION notify_inventory_event() IS
+'Publishes inventory audit entries and low stock alerts on channel inventory_events (LISTEN in API).';
@@ -0,0 +1,37 @@
+-- ================================================
+-- Migration: Create Order Communications
+-- Purpose: Contact history per order (emails, SMS, verification calls)
+-- Version: 000056
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Support cần biết khách đã nhận gì (email xác nhận, SMS, cuộc gọi xác minh) trước khi trả lời
+-- 2. Email xác nhận đơn gửi thẳng qua email job (không qua notifications) → cần log riêng
+-- 3. Cuộc gọi xác minh do staff thực hiện ngoài hệ thống → staff ghi lại qua admin API
+-- Notification có reference_type = 'order' vẫn nằm ở notification_delivery_logs,
+-- admin view gộp cả 2 nguồn khi đọc (không ghi trùng)
+
+CREATE TABLE IF NOT EXISTS order_communications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'sms', 'push', 'call')),
+    purpose TEXT NOT NULL,          -- order_confirmation, verification_call, ...
+    status TEXT NOT NULL CHECK (status IN ('sent', 'failed', 'answered', 'no_answer')),
+
+    recipient TEXT,                 -- email / số điện thoại
+    subject TEXT,
+    note TEXT,                      -- nội dung cuộc gọi / ghi chú staff
+    error_message TEXT,
+
+    -- NULL = gửi tự động bởi hệ thống
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: Admin order view "contact history" (mới nhất trước)
+CREATE INDEX idx_order_communications_order
+ON order_communications(order_id, created_at DESC);
+
+COMMENT ON TABLE order_communications IS
+'Outbound communications per order (confirmation emails, SMS, verification calls) shown to support in admin order view.';
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_delivery_logs_provider_message;
+DROP INDEX IF EXISTS idx_order_communications_provider_message;
+
+UPDATE order_communications SET status = 'sent' WHERE status = 'delivered';
+UPDATE order_communications SET status = 'failed' WHERE status IN ('bounced', 'complained');
+
+ALTER TABLE order_communications
+    DROP CONSTRAINT IF EXISTS order_communications_status_check;
+
+ALTER TABLE order_communications
+    ADD CONSTRAINT order_communications_status_check
+    CHECK (status IN ('sent', 'failed', 'answered', 'no_answer'));
+
+ALTER TABLE order_communications
+    DROP COLUMN IF EXISTS provider_message_id;
+
+DROP TABLE IF EXISTS email_suppressions;
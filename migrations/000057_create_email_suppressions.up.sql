@@ -0,0 +1,47 @@
+-- ================================================
+-- Migration: Create Email Suppressions
+-- Purpose: Track provider delivery events (delivered/bounced/complained) and suppress bad addresses
+-- Version: 000057
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Gửi tiếp tới địa chỉ hard bounce / complaint làm giảm sender reputation (SES/SendGrid có thể khoá tài khoản)
+-- 2. Webhook của provider là nguồn duy nhất biết email có tới inbox hay không (SMTP chỉ biết đã bàn giao)
+-- Soft bounce (mailbox full, tạm thời) KHÔNG suppress, provider tự retry
+
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    email TEXT PRIMARY KEY,         -- lowercase
+    reason TEXT NOT NULL CHECK (reason IN ('hard_bounce', 'complaint')),
+    provider TEXT NOT NULL,         -- ses, sendgrid
+    provider_message_id TEXT,       -- message gây ra suppression
+    diagnostic TEXT,                -- SMTP diagnostic / feedback type từ provider
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE email_suppressions IS
+'Addresses that hard-bounced or complained; outbound email to these addresses is skipped.';
+
+-- ================================================
+-- ORDER COMMUNICATIONS: link to provider message
+-- ================================================
+-- Email xác nhận đơn ghi Message-ID khi gửi → webhook cập nhật đúng dòng contact history
+
+ALTER TABLE order_communications
+    ADD COLUMN IF NOT EXISTS provider_message_id TEXT;
+
+ALTER TABLE order_communications
+    DROP CONSTRAINT IF EXISTS order_communications_status_check;
+
+ALTER TABLE order_communications
+    ADD CONSTRAINT order_communications_status_check
+    CHECK (status IN ('sent', 'failed', 'answered', 'no_answer', 'delivered', 'bounced', 'complained'));
+
+-- USE CASE: Webhook lookup theo Message-ID
+CREATE INDEX IF NOT EXISTS idx_order_communications_provider_message
+ON order_communications(provider_message_id)
+WHERE provider_message_id IS NOT NULL;
+
+-- USE CASE: Webhook lookup delivery log của notification theo Message-ID
+CREATE INDEX IF NOT EXISTS idx_delivery_logs_provider_message
+ON notification_delivery_logs(provider_message_id)
+WHERE provider_message_id IS NOT NULL;
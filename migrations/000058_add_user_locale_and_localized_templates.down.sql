@@ -0,0 +1,20 @@
+DELETE FROM notification_templates
+WHERE code IN ('order_confirmation', 'email_verification', 'password_reset');
+
+DELETE FROM notification_templates
+WHERE language <> 'vi';
+
+DROP INDEX IF EXISTS idx_templates_code;
+CREATE INDEX idx_templates_code ON notification_templates(code, is_active);
+
+ALTER TABLE notification_templates
+    DROP CONSTRAINT IF EXISTS notification_templates_code_language_key;
+
+ALTER TABLE notification_templates
+    ADD CONSTRAINT notification_templates_code_key UNIQUE (code);
+
+ALTER TABLE notification_templates
+    ALTER COLUMN language DROP NOT NULL;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locale;
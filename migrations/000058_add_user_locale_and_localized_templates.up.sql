@@ -0,0 +1,159 @@
+-- ================================================
+-- Migration: User Locale & Localized Notification Templates
+-- Purpose: Render transactional notifications in the user's language
+-- Version: 000058
+-- ================================================
+
+-- WHY?
+-- 1. Email xác nhận đơn / xác thực tài khoản đang hardcode tiếng Việt trong code và payload ("3-5 ngày", "24 giờ")
+-- 2. notification_templates đã có cột language nhưng code UNIQUE → mỗi template chỉ có 1 ngôn ngữ
+-- Sau migration: 1 code có nhiều bản (code, language), service chọn theo users.locale, fallback 'vi'
+
+-- ================================================
+-- USERS: locale preference
+-- ================================================
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(5) NOT NULL DEFAULT 'vi'
+    CHECK (locale IN ('vi', 'en'));
+
+COMMENT ON COLUMN users.locale IS
+'Preferred language for transactional notifications (vi, en).';
+
+-- ================================================
+-- NOTIFICATION TEMPLATES: unique per (code, language)
+-- ================================================
+
+UPDATE notification_templates SET language = 'vi' WHERE language IS NULL;
+
+ALTER TABLE notification_templates
+    ALTER COLUMN language SET NOT NULL;
+
+ALTER TABLE notification_templates
+    DROP CONSTRAINT IF EXISTS notification_templates_code_key;
+
+ALTER TABLE notification_templates
+    ADD CONSTRAINT notification_templates_code_language_key UNIQUE (code, language);
+
+-- USE CASE: Render template theo (code, locale của user)
+DROP INDEX IF EXISTS idx_templates_code;
+CREATE INDEX idx_templates_code ON notification_templates(code, language, is_active);
+
+-- ================================================
+-- SEED: transactional templates (vi + en)
+-- ================================================
+
+-- Email-only templates gửi từ job (xác nhận đơn, xác thực tài khoản, đặt lại mật khẩu)
+INSERT INTO notification_templates (code, name, category, language, email_subject, email_body_html, required_variables, default_channels, default_priority)
+VALUES
+(
+    'order_confirmation',
+    'Order Placed (email)',
+    'transactional',
+    'vi',
+    'Đơn hàng #{{order_number}} đã được đặt thành công',
+    '<p>Chào bạn,</p><p>Cảm ơn bạn đã đặt hàng tại Bookstore!</p><p>Chi tiết đơn hàng:</p><ul><li>Mã đơn hàng: <strong>{{order_number}}</strong></li><li>Ngày đặt: {{order_date}}</li><li>Tổng tiền: <strong>{{total_amount}} VNĐ</strong></li><li>Phương thức thanh toán: {{payment_method}}</li></ul><p>Dự kiến giao hàng: {{estimated_delivery}}</p><p>Theo dõi đơn hàng của bạn tại: <a href="{{order_url}}">{{order_url}}</a></p><p>Trân trọng,<br>Bookstore Team</p>',
+    ARRAY['order_number', 'order_date', 'total_amount', 'payment_method', 'estimated_delivery', 'order_url'],
+    ARRAY['email'],
+    3
+),
+(
+    'order_confirmation',
+    'Order Placed (email)',
+    'transactional',
+    'en',
+    'Your order #{{order_number}} has been placed',
+    '<p>Hello,</p><p>Thank you for shopping at Bookstore!</p><p>Order details:</p><ul><li>Order number: <strong>{{order_number}}</strong></li><li>Order date: {{order_date}}</li><li>Total: <strong>{{total_amount}} VND</strong></li><li>Payment method: {{payment_method}}</li></ul><p>Estimated delivery: {{estimated_delivery}}</p><p>Track your order at: <a href="{{order_url}}">{{order_url}}</a></p><p>Best regards,<br>Bookstore Team</p>',
+    ARRAY['order_number', 'order_date', 'total_amount', 'payment_method', 'estimated_delivery', 'order_url'],
+    ARRAY['email'],
+    3
+),
+(
+    'email_verification',
+    'Email Verification',
+    'transactional',
+    'vi',
+    'Xác thực tài khoản Bookstore',
+    '<p>Chào bạn,</p><p>Vui lòng click vào link sau để xác thực tài khoản:</p><p><a href="{{verify_link}}">{{verify_link}}</a></p><p>Link có hiệu lực {{expires_in}}.</p><p>Nếu bạn không đăng ký tài khoản này, vui lòng bỏ qua email này.</p>',
+    ARRAY['verify_link', 'expires_in'],
+    ARRAY['email'],
+    3
+),
+(
+    'email_verification',
+    'Email Verification',
+    'transactional',
+    'en',
+    'Verify your Bookstore account',
+    '<p>Hello,</p><p>Please click the link below to verify your account:</p><p><a href="{{verify_link}}">{{verify_link}}</a></p><p>This link is valid for {{expires_in}}.</p><p>If you did not create this account, please ignore this email.</p>',
+    ARRAY['verify_link', 'expires_in'],
+    ARRAY['email'],
+    3
+),
+(
+    'password_reset',
+    'Password Reset',
+    'transactional',
+    'vi',
+    'Đặt lại mật khẩu tài khoản Bookstore',
+    '<p>Chào bạn,</p><p>Vui lòng sử dụng token sau để đặt lại mật khẩu:</p><p><strong>{{reset_token}}</strong></p><p>Token có hiệu lực {{expires_in}}.</p><p>Nếu bạn không yêu cầu đặt lại mật khẩu, vui lòng bỏ qua email này.</p>',
+    ARRAY['reset_token', 'expires_in'],
+    ARRAY['email'],
+    3
+),
+(
+    'password_reset',
+    'Password Reset',
+    'transactional',
+    'en',
+    'Reset your Bookstore password',
+    '<p>Hello,</p><p>Use the following token to reset your password:</p><p><strong>{{reset_token}}</strong></p><p>This token is valid for {{expires_in}}.</p><p>If you did not request a password reset, please ignore this email.</p>',
+    ARRAY['reset_token', 'expires_in'],
+    ARRAY['email'],
+    3
+)
+ON CONFLICT (code, language) DO NOTHING;
+
+-- Bản tiếng Anh cho các template seed ở 000042
+INSERT INTO notification_templates (code, name, category, language, email_subject, email_body_html, in_app_title, in_app_body, required_variables, default_channels, default_priority)
+VALUES
+(
+    'order_confirmed',
+    'Order Confirmation',
+    'transactional',
+    'en',
+    'Order {{order_number}} has been confirmed',
+    '<h2>Thank you for your order!</h2><p>Your order <strong>{{order_number}}</strong> has been confirmed.</p><p>Total: <strong>{{total_amount}} VND</strong></p>',
+    'Order confirmed',
+    'Order {{order_number}} has been confirmed. Total: {{total_amount}} VND',
+    ARRAY['order_number', 'total_amount'],
+    ARRAY['in_app', 'email'],
+    3
+),
+(
+    'promotion_removed',
+    'Promotion Expired',
+    'transactional',
+    'en',
+    'Promo code {{promo_code}} has expired',
+    '<p>The promo code <strong>{{promo_code}}</strong> in your cart has expired and was removed.</p>',
+    'Promo code expired',
+    'Code {{promo_code}} has expired and was removed from your cart',
+    ARRAY['promo_code'],
+    ARRAY['in_app'],
+    2
+),
+(
+    'payment_success',
+    'Payment Successful',
+    'transactional',
+    'en',
+    'Payment received for order {{order_number}}',
+    '<h2>Payment successful!</h2><p>We have received your payment of <strong>{{amount}} VND</strong> for order {{order_number}}.</p>',
+    'Payment successful',
+    'Paid {{amount}} VND for order {{order_number}}',
+    ARRAY['order_number', 'amount'],
+    ARRAY['in_app', 'email'],
+    3
+)
+ON CONFLICT (code, language) DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_preferences_marketing;
+DROP TABLE IF EXISTS user_preferences;
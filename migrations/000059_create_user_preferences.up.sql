@@ -0,0 +1,38 @@
+-- ================================================
+-- Migration: Create User Preferences
+-- Purpose: Per-user preferences (currency display, marketing opt-in, notification channels)
+-- Version: 000059
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Marketing email/campaign phải có opt-in rõ ràng của user (consent), trước đây gửi cho tất cả
+-- 2. notification_preferences bật/tắt theo từng loại notification, chưa có công tắc tổng theo kênh
+-- Ngôn ngữ vẫn lưu ở users.locale (000058) - email job đọc trực tiếp, không JOIN thêm bảng
+-- User chưa có dòng ở đây → dùng giá trị mặc định (xem user.DefaultPreferences)
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+
+    currency_display VARCHAR(3) NOT NULL DEFAULT 'VND'
+        CHECK (currency_display IN ('VND', 'USD')),
+
+    marketing_opt_in BOOLEAN NOT NULL DEFAULT FALSE,
+    marketing_opt_in_at TIMESTAMPTZ,   -- thời điểm opt-in gần nhất (bằng chứng consent)
+
+    -- Công tắc tổng theo kênh, áp dụng trước notification_preferences
+    email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    in_app_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sms_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE user_preferences IS
+'Per-user display and communication preferences; missing row means defaults.';
+
+-- USE CASE: Đếm / lọc user đã opt-in marketing khi lên campaign
+CREATE INDEX IF NOT EXISTS idx_user_preferences_marketing
+ON user_preferences(user_id)
+WHERE marketing_opt_in = TRUE;
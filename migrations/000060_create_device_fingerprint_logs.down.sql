@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_device_fingerprint_logs_occurred;
+DROP INDEX IF EXISTS idx_device_fingerprint_logs_user;
+DROP INDEX IF EXISTS idx_device_fingerprint_logs_ip;
+DROP INDEX IF EXISTS idx_device_fingerprint_logs_fingerprint;
+DROP TABLE IF EXISTS device_fingerprint_logs;
@@ -0,0 +1,54 @@
+-- ================================================
+-- Migration: Create Device Fingerprint Logs
+-- Purpose: Log device fingerprint của action rủi ro cao (thêm giỏ, áp / validate mã giảm giá)
+-- Version: 000060
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Bot thêm giỏ liên tục (giữ hàng flash sale) và dò mã giảm giá không để lại dấu vết gì
+-- 2. Fraud review cần biết 1 thiết bị đứng sau bao nhiêu session / tài khoản / IP
+-- 3. Ghi cả kết quả CAPTCHA (nếu bị yêu cầu) để thấy thiết bị nào liên tục fail
+-- Ghi async qua worker (queue analytics) - không làm chậm request
+
+CREATE TABLE IF NOT EXISTS device_fingerprint_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    action VARCHAR(30) NOT NULL
+        CHECK (action IN ('add_to_cart', 'apply_promo', 'validate_promo')),
+
+    fingerprint VARCHAR(128) NOT NULL,
+    fingerprint_source VARCHAR(10) NOT NULL
+        CHECK (fingerprint_source IN ('client', 'derived')),
+
+    session_id VARCHAR(100),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    accept_language VARCHAR(255) NOT NULL DEFAULT '',
+    path VARCHAR(255) NOT NULL DEFAULT '',
+
+    request_count INT NOT NULL DEFAULT 0,   -- số lần action trong cửa sổ rate lúc ghi (0 = không đếm)
+    captcha_required BOOLEAN NOT NULL DEFAULT FALSE,
+    captcha_passed BOOLEAN,                 -- NULL = không yêu cầu / Turnstile lỗi
+
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE device_fingerprint_logs IS
+'High-risk storefront actions with device fingerprint, for fraud review.';
+
+-- USE CASE: Xem lịch sử 1 thiết bị
+CREATE INDEX IF NOT EXISTS idx_device_fingerprint_logs_fingerprint
+ON device_fingerprint_logs(fingerprint, occurred_at DESC);
+
+-- USE CASE: Lọc theo IP / user khi điều tra
+CREATE INDEX IF NOT EXISTS idx_device_fingerprint_logs_ip
+ON device_fingerprint_logs(ip_address, occurred_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_device_fingerprint_logs_user
+ON device_fingerprint_logs(user_id, occurred_at DESC)
+WHERE user_id IS NOT NULL;
+
+-- USE CASE: Dashboard theo khoảng thời gian
+CREATE INDEX IF NOT EXISTS idx_device_fingerprint_logs_occurred
+ON device_fingerprint_logs(occurred_at DESC);
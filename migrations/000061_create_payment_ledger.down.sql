@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS trigger_ledger_entries_immutable ON ledger_entries;
+DROP TRIGGER IF EXISTS trigger_ledger_transactions_immutable ON ledger_transactions;
+DROP TRIGGER IF EXISTS trigger_ledger_entries_balanced ON ledger_entries;
+DROP FUNCTION IF EXISTS prevent_ledger_mutation();
+DROP FUNCTION IF EXISTS check_ledger_transaction_balanced();
+
+DROP INDEX IF EXISTS idx_ledger_entries_account;
+DROP INDEX IF EXISTS idx_ledger_entries_transaction;
+DROP INDEX IF EXISTS idx_ledger_transactions_created;
+DROP INDEX IF EXISTS idx_ledger_transactions_order;
+
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
@@ -0,0 +1,154 @@
+-- ================================================
+-- Migration: Create Payment Ledger
+-- Purpose: Sổ cái kép (double-entry) cho mọi dòng tiền gắn với đơn hàng
+-- Version: 000061
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. payment_transactions chỉ giữ trạng thái mới nhất (refund_amount bị cộng dồn) → không audit được từng lần hoàn tiền một phần
+-- 2. Tiền COD thu hộ không có bản ghi nào, finance phải đối chiếu tay từ orders
+-- 3. Export cho kế toán cần số liệu cân (tổng Nợ = tổng Có) theo từng tài khoản
+--
+-- Mô hình:
+-- - ledger_transactions: 1 dòng tiền (capture, refund, cod_collection, ...) - idempotency_key chống ghi trùng khi webhook retry
+-- - ledger_entries: các bút toán Nợ/Có của dòng tiền đó, tổng debit = tổng credit (constraint trigger deferred)
+-- - Append-only: không UPDATE/DELETE, sai thì ghi bút toán đảo
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    payment_transaction_id UUID REFERENCES payment_transactions(id) ON DELETE RESTRICT,
+    refund_request_id UUID REFERENCES refund_requests(id) ON DELETE RESTRICT,
+
+    entry_type TEXT NOT NULL CHECK (
+        entry_type IN ('authorization', 'capture', 'refund', 'cod_collection', 'gift_card_redemption')
+    ),
+    gateway TEXT,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT 'VND',
+    description TEXT NOT NULL DEFAULT '',
+
+    idempotency_key TEXT NOT NULL UNIQUE,   -- vd: capture:<payment_id>, refund:<refund_id>
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ledger_transaction_id UUID NOT NULL REFERENCES ledger_transactions(id) ON DELETE RESTRICT,
+
+    account TEXT NOT NULL,
+    direction TEXT NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE ledger_transactions IS
+'Money movements tied to orders (capture, refund, COD collection, ...). Append-only.';
+COMMENT ON TABLE ledger_entries IS
+'Double-entry lines of a ledger transaction; debits equal credits per transaction.';
+
+-- USE CASE: Xem toàn bộ dòng tiền của 1 đơn (admin order detail, audit refund)
+CREATE INDEX IF NOT EXISTS idx_ledger_transactions_order
+ON ledger_transactions(order_id, created_at);
+
+-- USE CASE: Finance export theo khoảng thời gian
+CREATE INDEX IF NOT EXISTS idx_ledger_transactions_created
+ON ledger_transactions(created_at);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction
+ON ledger_entries(ledger_transaction_id);
+
+-- USE CASE: Số dư theo tài khoản
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account
+ON ledger_entries(account, created_at);
+
+-- ================================================
+-- BALANCE CHECK (deferred → kiểm tra lúc COMMIT, sau khi đã insert đủ các dòng)
+-- ================================================
+CREATE OR REPLACE FUNCTION check_ledger_transaction_balanced()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_debit NUMERIC(14,2);
+    v_credit NUMERIC(14,2);
+BEGIN
+    SELECT
+        COALESCE(SUM(amount) FILTER (WHERE direction = 'debit'), 0),
+        COALESCE(SUM(amount) FILTER (WHERE direction = 'credit'), 0)
+    INTO v_debit, v_credit
+    FROM ledger_entries
+    WHERE ledger_transaction_id = NEW.ledger_transaction_id;
+
+    IF v_debit <> v_credit THEN
+        RAISE EXCEPTION 'ledger transaction % is unbalanced: debit=% credit=%',
+            NEW.ledger_transaction_id, v_debit, v_credit;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trigger_ledger_entries_balanced
+AFTER INSERT ON ledger_entries
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION check_ledger_transaction_balanced();
+
+-- ================================================
+-- APPEND-ONLY
+-- ================================================
+CREATE OR REPLACE FUNCTION prevent_ledger_mutation()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only, post a reversing entry instead', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_ledger_transactions_immutable
+BEFORE UPDATE OR DELETE ON ledger_transactions
+FOR EACH ROW
+EXECUTE FUNCTION prevent_ledger_mutation();
+
+CREATE TRIGGER trigger_ledger_entries_immutable
+BEFORE UPDATE OR DELETE ON ledger_entries
+FOR EACH ROW
+EXECUTE FUNCTION prevent_ledger_mutation();
+
+-- ================================================
+-- BACKFILL: payment thành công + refund đã duyệt trước migration
+-- ================================================
+INSERT INTO ledger_transactions (
+    order_id, payment_transaction_id, entry_type, gateway, amount, currency,
+    description, idempotency_key, created_at
+)
+SELECT order_id, id, 'capture', gateway, amount, currency,
+    'Backfill: captured payment', 'capture:' || id, COALESCE(completed_at, updated_at)
+FROM payment_transactions
+WHERE status IN ('success', 'refunded')
+  AND gateway <> 'cod'
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+INSERT INTO ledger_transactions (
+    order_id, payment_transaction_id, refund_request_id, entry_type, gateway, amount, currency,
+    description, idempotency_key, created_by, created_at
+)
+SELECT rr.order_id, rr.payment_transaction_id, rr.id, 'refund', pt.gateway, rr.requested_amount, pt.currency,
+    'Backfill: ' || rr.reason, 'refund:' || rr.id, rr.approved_by, COALESCE(rr.approved_at, rr.updated_at)
+FROM refund_requests rr
+JOIN payment_transactions pt ON pt.id = rr.payment_transaction_id
+WHERE rr.status IN ('approved', 'processing', 'completed')
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+-- Capture: Nợ tiền treo ở cổng thanh toán / Có doanh thu đơn hàng
+INSERT INTO ledger_entries (ledger_transaction_id, account, direction, amount, created_at)
+SELECT id, 'gateway_clearing', 'debit', amount, created_at FROM ledger_transactions WHERE entry_type = 'capture'
+UNION ALL
+SELECT id, 'order_revenue', 'credit', amount, created_at FROM ledger_transactions WHERE entry_type = 'capture';
+
+-- Refund: Nợ hoàn tiền (giảm trừ doanh thu) / Có tiền ở cổng thanh toán
+INSERT INTO ledger_entries (ledger_transaction_id, account, direction, amount, created_at)
+SELECT id, 'sales_refunds', 'debit', amount, created_at FROM ledger_transactions WHERE entry_type = 'refund'
+UNION ALL
+SELECT id, 'gateway_clearing', 'credit', amount, created_at FROM ledger_transactions WHERE entry_type = 'refund';
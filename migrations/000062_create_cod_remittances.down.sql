@@ -0,0 +1,15 @@
+-- Lỗi nếu sổ cái đã có dòng tiền cod_remittance (append-only, không xoá được) → phải xử lý tay trước khi rollback
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS ledger_transactions_entry_type_check;
+ALTER TABLE ledger_transactions ADD CONSTRAINT ledger_transactions_entry_type_check CHECK (
+    entry_type IN ('authorization', 'capture', 'refund', 'cod_collection', 'gift_card_redemption')
+);
+
+DROP TRIGGER IF EXISTS trigger_cod_shipments_updated_at ON cod_shipments;
+
+DROP INDEX IF EXISTS idx_cod_remittance_lines_remittance;
+DROP INDEX IF EXISTS idx_cod_shipments_status;
+DROP INDEX IF EXISTS idx_cod_shipments_tracking;
+
+DROP TABLE IF EXISTS cod_remittance_lines;
+DROP TABLE IF EXISTS cod_shipments;
+DROP TABLE IF EXISTS cod_remittances;
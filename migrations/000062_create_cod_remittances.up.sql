@@ -0,0 +1,139 @@
+-- ================================================
+-- Migration: Create COD Remittance Reconciliation
+-- Purpose: Theo dõi tiền COD đơn vị vận chuyển thu hộ theo từng kiện, đối soát với file nộp tiền (remittance) của hãng
+-- Version: 000062
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Đơn COD giao xong chỉ có nghĩa là shipper đã thu tiền, tiền về tài khoản shop sau vài ngày theo đợt đối soát của hãng
+-- 2. Hãng gửi file đối soát (mã vận đơn + số tiền) → finance phải dò tay với đơn, dễ sót kiện bị nộp thiếu
+-- 3. Cần biết kiện nào đã nộp đủ, kiện nào thiếu (shortfall), dòng nào trong file không khớp đơn nào
+--
+-- Mô hình:
+-- - cod_shipments: 1 kiện COD đã giao (1 đơn = 1 kiện), số tiền phải thu + số tiền hãng đã nộp
+-- - cod_remittances: 1 file đối soát đã import, UNIQUE(carrier, reference) chống import trùng
+-- - cod_remittance_lines: từng dòng trong file + kết quả khớp (matched/shortfall/overpaid/unmatched/duplicate)
+
+CREATE TABLE IF NOT EXISTS cod_remittances (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+
+    carrier TEXT NOT NULL,
+    reference TEXT NOT NULL,               -- mã bảng kê của hãng
+    remitted_at TIMESTAMPTZ NOT NULL,      -- ngày hãng chuyển tiền
+    file_name TEXT NOT NULL DEFAULT '',
+
+    total_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    line_count INT NOT NULL DEFAULT 0,
+    matched_count INT NOT NULL DEFAULT 0,
+    shortfall_count INT NOT NULL DEFAULT 0,
+    overpaid_count INT NOT NULL DEFAULT 0,
+    unmatched_count INT NOT NULL DEFAULT 0,
+    duplicate_count INT NOT NULL DEFAULT 0,
+
+    imported_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_cod_remittances_carrier_reference UNIQUE (carrier, reference)
+);
+
+CREATE TABLE IF NOT EXISTS cod_shipments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE RESTRICT,
+    carrier TEXT,                          -- biết được khi hãng nộp tiền (file đối soát theo hãng)
+    tracking_number TEXT,
+
+    cod_amount NUMERIC(12,2) NOT NULL CHECK (cod_amount > 0),
+    remitted_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'collected' CHECK (status IN ('collected', 'remitted', 'shortfall')),
+
+    collected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_remittance_id UUID REFERENCES cod_remittances(id) ON DELETE SET NULL,
+    reconciled_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS cod_remittance_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    remittance_id UUID NOT NULL REFERENCES cod_remittances(id) ON DELETE CASCADE,
+    line_no INT NOT NULL,
+
+    tracking_number TEXT,
+    order_number TEXT,
+    amount NUMERIC(12,2) NOT NULL,
+
+    shipment_id UUID REFERENCES cod_shipments(id) ON DELETE SET NULL,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    expected_amount NUMERIC(12,2),         -- số còn phải nộp của kiện tại thời điểm import
+    difference NUMERIC(12,2),              -- amount - expected_amount (âm = nộp thiếu)
+    match_status TEXT NOT NULL CHECK (
+        match_status IN ('matched', 'shortfall', 'overpaid', 'unmatched', 'duplicate')
+    ),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE cod_shipments IS
+'Delivered COD parcels: amount the carrier collected and how much of it has been remitted.';
+COMMENT ON TABLE cod_remittances IS
+'Carrier COD remittance files imported by finance.';
+COMMENT ON TABLE cod_remittance_lines IS
+'Lines of a remittance file with the reconciliation result against cod_shipments.';
+
+-- USE CASE: Khớp dòng trong file theo mã vận đơn
+CREATE INDEX IF NOT EXISTS idx_cod_shipments_tracking
+ON cod_shipments(tracking_number)
+WHERE tracking_number IS NOT NULL;
+
+-- USE CASE: Finance xem kiện chưa nộp / nộp thiếu (cũ nhất trước)
+CREATE INDEX IF NOT EXISTS idx_cod_shipments_status
+ON cod_shipments(status, collected_at);
+
+CREATE INDEX IF NOT EXISTS idx_cod_remittance_lines_remittance
+ON cod_remittance_lines(remittance_id, line_no);
+
+CREATE TRIGGER trigger_cod_shipments_updated_at
+BEFORE UPDATE ON cod_shipments
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ================================================
+-- LEDGER: thêm loại dòng tiền hãng nộp tiền COD (Nợ cash_in_bank / Có cod_receivable)
+-- ================================================
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS ledger_transactions_entry_type_check;
+ALTER TABLE ledger_transactions ADD CONSTRAINT ledger_transactions_entry_type_check CHECK (
+    entry_type IN ('authorization', 'capture', 'refund', 'cod_collection', 'cod_remittance', 'gift_card_redemption')
+);
+
+-- ================================================
+-- BACKFILL: đơn COD đã giao, chưa thanh toán → kiện chờ đối soát + bút toán thu hộ
+-- ================================================
+INSERT INTO cod_shipments (order_id, tracking_number, cod_amount, collected_at)
+SELECT id, tracking_number, total, COALESCE(delivered_at, updated_at)
+FROM orders
+WHERE payment_method = 'cod'
+  AND status = 'delivered'
+  AND payment_status = 'pending'
+  AND total > 0
+ON CONFLICT (order_id) DO NOTHING;
+
+INSERT INTO ledger_transactions (
+    order_id, entry_type, gateway, amount, currency, description, idempotency_key, created_at
+)
+SELECT order_id, 'cod_collection', 'cod', cod_amount, 'VND',
+    'Backfill: cash collected on delivery', 'cod_collection:' || order_id, collected_at
+FROM cod_shipments
+ON CONFLICT (idempotency_key) DO NOTHING;
+
+INSERT INTO ledger_entries (ledger_transaction_id, account, direction, amount, created_at)
+SELECT lt.id, 'cod_receivable', 'debit', lt.amount, lt.created_at
+FROM ledger_transactions lt
+WHERE lt.entry_type = 'cod_collection'
+  AND NOT EXISTS (SELECT 1 FROM ledger_entries le WHERE le.ledger_transaction_id = lt.id)
+UNION ALL
+SELECT lt.id, 'order_revenue', 'credit', lt.amount, lt.created_at
+FROM ledger_transactions lt
+WHERE lt.entry_type = 'cod_collection'
+  AND NOT EXISTS (SELECT 1 FROM ledger_entries le WHERE le.ledger_transaction_id = lt.id);
@@ -0,0 +1,46 @@
+-- ================================================
+-- Migration: Create Order Status Transitions
+-- Purpose: State machine trạng thái đơn hàng cấu hình được (admin sửa), thay cho map hard-code trong code
+-- Version: 000063
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Thêm/bớt bước (vd: cho phép confirmed → shipping khi kho tự đóng gói) phải sửa code + deploy
+-- 2. Side-effect theo từng bước (báo khách, tạo vận đơn) cần bật/tắt riêng cho từng transition
+--
+-- Mô hình:
+-- - 1 dòng = 1 transition được phép (from_status → to_status)
+-- - hooks: side-effect chạy sau khi commit (notify_customer, trigger_shipment)
+-- - Danh sách trạng thái cố định theo CHECK của orders.status, bảng này chỉ cấu hình cạnh nối
+
+CREATE TABLE IF NOT EXISTS order_status_transitions (
+    from_status TEXT NOT NULL CHECK (
+        from_status IN ('pending', 'confirmed', 'processing', 'shipping', 'delivered', 'cancelled', 'returned')
+    ),
+    to_status TEXT NOT NULL CHECK (
+        to_status IN ('pending', 'confirmed', 'processing', 'shipping', 'delivered', 'cancelled', 'returned')
+    ),
+    hooks TEXT[] NOT NULL DEFAULT '{}',
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (from_status, to_status),
+    CONSTRAINT chk_order_status_transitions_not_self CHECK (from_status <> to_status)
+);
+
+COMMENT ON TABLE order_status_transitions IS
+'Allowed admin order status transitions with side-effect hooks run after the change is committed.';
+
+-- Seed: đúng bằng allowedTransitions hard-code trước đây
+INSERT INTO order_status_transitions (from_status, to_status, hooks) VALUES
+    ('pending',    'confirmed',  ARRAY['notify_customer']),
+    ('pending',    'cancelled',  ARRAY['notify_customer']),
+    ('confirmed',  'processing', ARRAY[]::TEXT[]),
+    ('confirmed',  'cancelled',  ARRAY['notify_customer']),
+    ('processing', 'shipping',   ARRAY['notify_customer', 'trigger_shipment']),
+    ('processing', 'cancelled',  ARRAY['notify_customer']),
+    ('shipping',   'delivered',  ARRAY['notify_customer']),
+    ('shipping',   'returned',   ARRAY['notify_customer']),
+    ('delivered',  'returned',   ARRAY['notify_customer'])
+ON CONFLICT (from_status, to_status) DO NOTHING;
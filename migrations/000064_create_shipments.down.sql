@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS trigger_shipments_updated_at ON shipments;
+
+DROP INDEX IF EXISTS idx_dispatch_batch_orders_pending;
+DROP INDEX IF EXISTS idx_shipments_batch;
+DROP INDEX IF EXISTS uq_shipments_carrier_tracking;
+DROP INDEX IF EXISTS idx_dispatch_batches_date;
+
+DROP TABLE IF EXISTS dispatch_batch_orders;
+DROP TABLE IF EXISTS shipments;
+DROP TABLE IF EXISTS dispatch_batches;
@@ -0,0 +1,97 @@
+-- ================================================
+-- Migration: Create Shipments & Dispatch Batches
+-- Purpose: Mua vận đơn + in nhãn (PDF/ZPL) từ API hãng vận chuyển cho đơn đã xác nhận, gom theo đợt xuất kho trong ngày
+-- Version: 000064
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Trước đây kho tạo vận đơn trên web của hãng rồi copy mã vận đơn về admin → chậm, dễ gõ sai
+-- 2. Cần lưu lại phí vận chuyển thực trả cho hãng (khác shipping_fee thu của khách) để đối chiếu chi phí
+-- 3. Nhãn in (PDF khổ A6 / ZPL cho máy in nhiệt) lưu trên storage → in lại không cần gọi hãng
+-- 4. Mỗi ngày kho in nhãn hàng loạt cho toàn bộ đơn chờ xuất → dispatch_batches theo dõi tiến độ + đơn lỗi
+--
+-- Mô hình:
+-- - shipments: 1 đơn = 1 vận đơn (UNIQUE order_id), mã vận đơn + phí + nhãn
+-- - dispatch_batches: 1 đợt in nhãn hàng loạt (xử lý nền bằng worker)
+-- - dispatch_batch_orders: từng đơn trong đợt + kết quả (succeeded / failed + lỗi từ hãng)
+
+CREATE TABLE IF NOT EXISTS dispatch_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+
+    batch_date DATE NOT NULL,
+    carrier TEXT NOT NULL,
+    label_format TEXT NOT NULL,
+    warehouse_id UUID REFERENCES warehouses(id) ON DELETE SET NULL,
+
+    status TEXT NOT NULL DEFAULT 'processing',
+    total_orders INT NOT NULL DEFAULT 0,
+    succeeded_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_dispatch_batches_carrier CHECK (carrier IN ('ghn', 'ghtk', 'viettel_post', 'vnpost', 'jt_express')),
+    CONSTRAINT chk_dispatch_batches_label_format CHECK (label_format IN ('pdf', 'zpl')),
+    CONSTRAINT chk_dispatch_batches_status CHECK (status IN ('processing', 'completed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispatch_batches_date
+ON dispatch_batches(batch_date DESC, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS shipments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    batch_id UUID REFERENCES dispatch_batches(id) ON DELETE SET NULL,
+
+    carrier TEXT NOT NULL,
+    tracking_number TEXT NOT NULL,
+    shipping_cost NUMERIC(12,2) NOT NULL DEFAULT 0,
+    cod_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    weight_grams INT NOT NULL,
+
+    label_format TEXT NOT NULL,
+    label_url TEXT NOT NULL,
+    label_key TEXT NOT NULL, -- object key trên storage, dùng để tải lại khi in
+
+    purchased_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_shipments_order UNIQUE (order_id),
+    CONSTRAINT chk_shipments_carrier CHECK (carrier IN ('ghn', 'ghtk', 'viettel_post', 'vnpost', 'jt_express')),
+    CONSTRAINT chk_shipments_label_format CHECK (label_format IN ('pdf', 'zpl')),
+    CONSTRAINT chk_shipments_shipping_cost CHECK (shipping_cost >= 0),
+    CONSTRAINT chk_shipments_cod_amount CHECK (cod_amount >= 0),
+    CONSTRAINT chk_shipments_weight CHECK (weight_grams > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_shipments_carrier_tracking
+ON shipments(carrier, tracking_number);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_batch
+ON shipments(batch_id)
+WHERE batch_id IS NOT NULL;
+
+CREATE TRIGGER trigger_shipments_updated_at
+BEFORE UPDATE ON shipments
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS dispatch_batch_orders (
+    batch_id UUID NOT NULL REFERENCES dispatch_batches(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+
+    status TEXT NOT NULL DEFAULT 'pending',
+    shipment_id UUID REFERENCES shipments(id) ON DELETE SET NULL,
+    error_message TEXT,
+    processed_at TIMESTAMPTZ,
+
+    PRIMARY KEY (batch_id, order_id),
+    CONSTRAINT chk_dispatch_batch_orders_status CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispatch_batch_orders_pending
+ON dispatch_batch_orders(batch_id)
+WHERE status = 'pending';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_modifications;
+
+DROP INDEX IF EXISTS idx_orders_warehouse;
+ALTER TABLE orders DROP COLUMN IF EXISTS warehouse_id;
@@ -0,0 +1,44 @@
+-- ================================================
+-- Migration: Create Order Modifications
+-- Purpose: Customer self-service changes (shipping address / note) after checkout
+-- Version: 000065
+-- ================================================
+
+-- Kho đã giữ hàng (reserve) cho đơn. Trước đây chỉ nằm trong bộ nhớ lúc tạo đơn →
+-- không biết release ở kho nào khi huỷ / đổi địa chỉ. Đơn cũ để NULL.
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS warehouse_id UUID REFERENCES warehouses(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_warehouse
+ON orders(warehouse_id)
+WHERE warehouse_id IS NOT NULL;
+
+-- WHY THIS TABLE?
+-- 1. Khách được sửa địa chỉ giao / ghi chú trong thời gian ngắn sau khi đặt (trước processing)
+-- 2. Đổi tỉnh có thể đổi kho fulfill → support cần biết hàng đã chuyển reserve từ kho nào sang kho nào
+-- 3. Tranh chấp giao hàng ("tôi đã đổi địa chỉ rồi") → cần bằng chứng ai đổi, lúc nào, giá trị cũ/mới
+-- order_status_history chỉ lưu chuyển trạng thái, không chứa được các giá trị trước/sau này
+
+CREATE TABLE IF NOT EXISTS order_modifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+
+    old_address_id UUID,
+    new_address_id UUID,
+    old_customer_note TEXT,
+    new_customer_note TEXT,
+
+    -- Khác nhau khi đổi tỉnh và kho gần địa chỉ mới khác kho cũ (reserve đã được chuyển)
+    old_warehouse_id UUID REFERENCES warehouses(id) ON DELETE SET NULL,
+    new_warehouse_id UUID REFERENCES warehouses(id) ON DELETE SET NULL,
+
+    changed_by UUID REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: Admin order view "modification history" (mới nhất trước)
+CREATE INDEX idx_order_modifications_order
+ON order_modifications(order_id, created_at DESC);
+
+COMMENT ON TABLE order_modifications IS
+'Audit trail of customer self-service order changes (shipping address, delivery note, warehouse re-selection).';
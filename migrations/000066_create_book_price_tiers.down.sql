@@ -0,0 +1,5 @@
+ALTER TABLE order_items
+DROP COLUMN IF EXISTS tier_discount_percent,
+DROP COLUMN IF EXISTS list_price;
+
+DROP TABLE IF EXISTS book_price_tiers;
@@ -0,0 +1,43 @@
+-- ================================================
+-- Migration: Create Book Price Tiers
+-- Purpose: Giá theo số lượng (mua 5+ giảm 5%, 10+ giảm 10%) do admin cấu hình từng sách
+-- Version: 000066
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Khách mua sỉ cho lớp học / văn phòng cần giá tốt hơn khi mua nhiều cùng 1 đầu sách
+-- 2. Promotion áp cho cả đơn, không diễn tả được "từ N cuốn trở lên" theo từng sách
+--
+-- Mô hình:
+-- - 1 dòng = 1 bậc: số lượng >= min_quantity → giảm discount_percent trên giá bìa
+-- - Nhiều bậc khớp → lấy bậc có min_quantity lớn nhất
+-- - cart_items.price lưu đơn giá ĐÃ áp bậc → trigger subtotal của carts không cần đổi
+
+CREATE TABLE IF NOT EXISTS book_price_tiers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+
+    min_quantity INT NOT NULL CHECK (min_quantity >= 2),
+    discount_percent NUMERIC(5,2) NOT NULL CHECK (discount_percent > 0 AND discount_percent < 100),
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_book_price_tiers_book_quantity UNIQUE (book_id, min_quantity)
+);
+
+-- USE CASE: Load bậc giá của các sách trong cart (WHERE book_id = ANY($1))
+-- (UNIQUE (book_id, min_quantity) đã tạo index phù hợp)
+
+COMMENT ON TABLE book_price_tiers IS
+'Quantity-based price tiers per book (buy N+ get X% off), applied to cart item and order item unit prices.';
+
+-- Snapshot giá lúc đặt hàng: price = đơn giá đã áp bậc, list_price = giá bìa
+-- → hiển thị tiết kiệm trên đơn/hoá đơn kể cả khi bậc giá đổi sau này
+ALTER TABLE order_items
+ADD COLUMN IF NOT EXISTS list_price NUMERIC(12,2),
+ADD COLUMN IF NOT EXISTS tier_discount_percent NUMERIC(5,2);
+
+UPDATE order_items SET list_price = price WHERE list_price IS NULL;
+
+ALTER TABLE order_items ALTER COLUMN list_price SET NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+DROP COLUMN IF EXISTS no_printed_invoice,
+DROP COLUMN IF EXISTS minimal_packaging;
@@ -0,0 +1,17 @@
+-- ================================================
+-- Migration: Add Order Packaging Preferences
+-- Purpose: Khách chọn đóng gói tối giản / không in hoá đơn giấy khi checkout
+-- Version: 000067
+-- ================================================
+
+-- WHY ON ORDERS?
+-- 1. Lựa chọn gắn với từng đơn (không phải preference cố định của user) → snapshot lúc checkout
+-- 2. Kho đọc trên packing slip khi đóng hàng, không cần join thêm bảng
+-- 3. Báo cáo tỷ lệ chọn theo khoảng thời gian đặt hàng (created_at)
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS minimal_packaging BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS no_printed_invoice BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN orders.minimal_packaging IS 'Customer opted for minimal packaging (no plastic fill, smallest box)';
+COMMENT ON COLUMN orders.no_printed_invoice IS 'Customer opted out of a printed invoice in the parcel (invoice available online)';
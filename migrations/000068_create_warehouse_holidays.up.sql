@@ -0,0 +1,52 @@
+-- ================================================
+-- Migration: Create Warehouse Holidays
+-- Purpose: Lịch nghỉ theo toàn quốc / tỉnh / kho → ước tính giao hàng và SLA xử lý đơn bỏ qua ngày nghỉ
+-- Version: 000068
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Tết, 30/4-1/5, 2/9 kho không xử lý đơn → ước tính "1-2 ngày" sai, đơn bị báo trễ SLA oan
+-- 2. Mỗi tỉnh / kho có ngày nghỉ riêng (giỗ tổ địa phương, kiểm kê, bão lũ) → không hardcode được
+--
+-- Phạm vi (scope):
+-- - national : áp cho mọi kho (warehouse_id, province NULL)
+-- - province : áp cho các kho thuộc tỉnh (province NOT NULL)
+-- - warehouse: chỉ 1 kho (warehouse_id NOT NULL)
+--
+-- source = 'national_seed': do job tự sinh ngày lễ quốc gia VN theo năm (kể cả Tết âm lịch),
+-- admin sửa/xoá được; job chỉ seed năm chưa có dòng seed nào → không ghi đè chỉnh sửa của admin
+
+CREATE TABLE IF NOT EXISTS warehouse_holidays (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    scope TEXT NOT NULL CHECK (scope IN ('national', 'province', 'warehouse')),
+    warehouse_id UUID REFERENCES warehouses(id) ON DELETE CASCADE,
+    province TEXT,
+
+    holiday_date DATE NOT NULL,
+    name TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'national_seed')),
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_warehouse_holidays_scope CHECK (
+        (scope = 'national' AND warehouse_id IS NULL AND province IS NULL) OR
+        (scope = 'province' AND warehouse_id IS NULL AND province IS NOT NULL) OR
+        (scope = 'warehouse' AND warehouse_id IS NOT NULL AND province IS NULL)
+    )
+);
+
+-- 1 ngày chỉ khai báo 1 lần cho mỗi phạm vi (seed chạy lại không tạo trùng)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_warehouse_holidays_scope_date
+ON warehouse_holidays (holiday_date, scope, COALESCE(warehouse_id::text, ''), COALESCE(province, ''));
+
+-- USE CASE: Lịch áp cho 1 kho trong khoảng ngày (WHERE holiday_date BETWEEN $1 AND $2)
+CREATE INDEX IF NOT EXISTS idx_warehouse_holidays_date ON warehouse_holidays (holiday_date);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_holidays_warehouse
+ON warehouse_holidays (warehouse_id, holiday_date) WHERE warehouse_id IS NOT NULL;
+
+COMMENT ON TABLE warehouse_holidays IS
+'Non-working days per scope (national / province / warehouse), consulted by delivery estimation and processing SLA monitoring.';
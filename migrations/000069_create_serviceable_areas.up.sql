@@ -0,0 +1,51 @@
+-- ================================================
+-- Migration: Create Serviceable Areas
+-- Purpose: Khu vực cửa hàng giao tới (tỉnh / quận-huyện, tuỳ chọn theo kho / hãng vận chuyển)
+-- Version: 000069
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Một số huyện đảo / vùng sâu hãng vận chuyển không nhận → khách đặt xong mới bị huỷ
+-- 2. Chặn ngay khi tạo địa chỉ + checkout, frontend kiểm tra trong lúc khách nhập địa chỉ
+--
+-- Quy tắc:
+-- - Chưa có dòng active nào → giao toàn quốc (không giới hạn, giữ hành vi cũ)
+-- - Có rule → địa chỉ hợp lệ khi khớp ít nhất 1 rule active
+-- - district NULL = cả tỉnh; warehouse_id / carrier NULL = mọi kho / mọi hãng
+-- - So khớp theo tên đã chuẩn hoá (bỏ dấu, bỏ tiền tố "Tỉnh", "Quận"...) → lưu thêm cột *_key
+
+CREATE TABLE IF NOT EXISTS serviceable_areas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    province TEXT NOT NULL,
+    province_key TEXT NOT NULL,
+    district TEXT,
+    district_key TEXT,
+
+    warehouse_id UUID REFERENCES warehouses(id) ON DELETE CASCADE,
+    carrier TEXT,
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    note TEXT,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_serviceable_areas_district_key CHECK ((district IS NULL) = (district_key IS NULL))
+);
+
+-- 1 khu vực chỉ khai báo 1 lần cho mỗi cặp kho / hãng
+CREATE UNIQUE INDEX IF NOT EXISTS uq_serviceable_areas_scope
+ON serviceable_areas (
+    province_key,
+    COALESCE(district_key, ''),
+    COALESCE(warehouse_id::text, ''),
+    COALESCE(carrier, '')
+);
+
+-- USE CASE: Load toàn bộ rule active vào cache (bảng nhỏ, vài trăm dòng)
+CREATE INDEX IF NOT EXISTS idx_serviceable_areas_active ON serviceable_areas (province_key) WHERE is_active = TRUE;
+
+COMMENT ON TABLE serviceable_areas IS
+'Provinces/districts the store ships to, optionally restricted per warehouse/carrier. No active rows = ship nationwide.';
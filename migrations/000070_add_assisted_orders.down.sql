@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_orders_placed_by;
+ALTER TABLE orders DROP COLUMN IF EXISTS placed_by;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer', 'invoice'));
@@ -0,0 +1,29 @@
+-- ================================================
+-- Migration: Assisted Orders (đơn đặt qua điện thoại)
+-- Purpose: CSKH dựng giỏ hàng + đặt đơn thay khách, khách thanh toán qua link gửi email
+-- Version: 000070
+-- ================================================
+
+-- WHY?
+-- 1. Khách gọi hotline đặt sách: nhân viên tạo giỏ + checkout thay khách
+-- 2. Nhân viên không được cầm thông tin thẻ → đơn chờ thanh toán, khách tự trả qua link
+-- 3. Cần biết đơn nào do nhân viên đặt (đối soát, KPI CSKH, điều tra khi có khiếu nại)
+
+-- ================================================
+-- ORDERS: payment method 'pay_link'
+-- ================================================
+-- Đơn pending, khách mở link → chọn cổng thanh toán như đơn online thường
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer', 'invoice', 'pay_link'));
+
+-- ================================================
+-- ORDERS: placed_by (nhân viên đặt thay, NULL = khách tự đặt)
+-- ================================================
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS placed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_placed_by
+    ON orders(placed_by, created_at DESC)
+    WHERE placed_by IS NOT NULL;
+
+COMMENT ON COLUMN orders.placed_by IS 'Staff (admin/cskh) who placed the order on behalf of the customer; NULL = customer self-checkout';
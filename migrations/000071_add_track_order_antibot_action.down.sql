@@ -0,0 +1,5 @@
+DELETE FROM device_fingerprint_logs WHERE action = 'track_order';
+
+ALTER TABLE device_fingerprint_logs DROP CONSTRAINT IF EXISTS device_fingerprint_logs_action_check;
+ALTER TABLE device_fingerprint_logs ADD CONSTRAINT device_fingerprint_logs_action_check
+    CHECK (action IN ('add_to_cart', 'apply_promo', 'validate_promo'));
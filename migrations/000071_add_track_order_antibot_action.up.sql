@@ -0,0 +1,13 @@
+-- ================================================
+-- Migration: Anti-bot action track_order
+-- Purpose: Tra cứu đơn public (mã đơn + 4 số cuối SĐT) được bảo vệ bởi AntiBot middleware
+-- Version: 000071
+-- ================================================
+
+-- WHY?
+-- 1. Endpoint tra cứu đơn không cần đăng nhập → bot có thể dò mã đơn + 4 số cuối SĐT
+-- 2. Log fingerprint các lần tra cứu để fraud review thấy thiết bị đang dò
+
+ALTER TABLE device_fingerprint_logs DROP CONSTRAINT IF EXISTS device_fingerprint_logs_action_check;
+ALTER TABLE device_fingerprint_logs ADD CONSTRAINT device_fingerprint_logs_action_check
+    CHECK (action IN ('add_to_cart', 'apply_promo', 'validate_promo', 'track_order'));
@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS einvoice_issued_at,
+    DROP COLUMN IF EXISTS einvoice_number,
+    DROP COLUMN IF EXISTS einvoice_series,
+    DROP COLUMN IF EXISTS einvoice_template_code;
+
+DROP TRIGGER IF EXISTS trigger_einvoices_updated_at ON einvoices;
+DROP TABLE IF EXISTS einvoices;
@@ -0,0 +1,96 @@
+-- ================================================
+-- Migration: Create E-Invoices
+-- Purpose: Hoá đơn điện tử (Nghị định 123/2020) phát hành qua nhà cung cấp (MISA meInvoice, VNPT)
+-- Version: 000072
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Khách (nhất là doanh nghiệp) cần hoá đơn GTGT có MST người mua, không chỉ invoice PDF tự in
+-- 2. Kế toán phải lưu mẫu số / ký hiệu / số hoá đơn của từng đơn để đối chiếu với cơ quan thuế
+-- 3. Hoá đơn đã phát hành không được sửa/xoá: sai thông tin → lập hoá đơn thay thế, sai tiền → hoá đơn điều chỉnh
+--
+-- Mô hình:
+-- - einvoices: 1 đơn = tối đa 1 hoá đơn gốc, mỗi hoá đơn tối đa 1 hoá đơn thay thế, không giới hạn điều chỉnh
+-- - orders.einvoice_*: mẫu số / ký hiệu / số của hoá đơn đang có hiệu lực (gốc hoặc thay thế mới nhất)
+-- - Số tiền của hoá đơn điều chỉnh là phần chênh lệch (âm = điều chỉnh giảm)
+
+CREATE TABLE IF NOT EXISTS einvoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    invoice_type TEXT NOT NULL DEFAULT 'original',
+    original_invoice_id UUID REFERENCES einvoices(id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'pending',
+    provider TEXT NOT NULL,
+
+    template_code TEXT NOT NULL,
+    invoice_series TEXT NOT NULL,
+    invoice_number TEXT,
+    transaction_id TEXT, -- mã giao dịch bên nhà cung cấp
+    lookup_code TEXT,    -- mã tra cứu cho người mua
+
+    buyer_name TEXT NOT NULL,
+    buyer_company TEXT,
+    buyer_tax_code VARCHAR(14),
+    buyer_address TEXT NOT NULL,
+    buyer_email TEXT,
+
+    amount_before_tax NUMERIC(12,2) NOT NULL,
+    vat_rate INT NOT NULL DEFAULT 0,
+    tax_amount NUMERIC(12,2) NOT NULL,
+    total_amount NUMERIC(12,2) NOT NULL,
+
+    reason TEXT,        -- lý do thay thế / điều chỉnh
+    error_message TEXT, -- lỗi gần nhất từ nhà cung cấp
+    attempts INT NOT NULL DEFAULT 0,
+
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    issued_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_einvoices_type CHECK (invoice_type IN ('original', 'replacement', 'adjustment')),
+    CONSTRAINT chk_einvoices_status CHECK (status IN ('pending', 'issued', 'failed', 'replaced')),
+    CONSTRAINT chk_einvoices_provider CHECK (provider IN ('misa', 'vnpt')),
+    CONSTRAINT chk_einvoices_original CHECK ((invoice_type = 'original') = (original_invoice_id IS NULL)),
+    CONSTRAINT chk_einvoices_issued CHECK (status NOT IN ('issued', 'replaced') OR invoice_number IS NOT NULL),
+    CONSTRAINT chk_einvoices_vat_rate CHECK (vat_rate >= 0 AND vat_rate <= 100)
+);
+
+-- 1 đơn chỉ có 1 hoá đơn gốc (khách bấm 2 lần / khách + admin cùng yêu cầu)
+CREATE UNIQUE INDEX IF NOT EXISTS uq_einvoices_original_per_order
+ON einvoices(order_id)
+WHERE invoice_type = 'original';
+
+-- 1 hoá đơn chỉ bị thay thế 1 lần
+CREATE UNIQUE INDEX IF NOT EXISTS uq_einvoices_replacement_per_original
+ON einvoices(original_invoice_id)
+WHERE invoice_type = 'replacement';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_einvoices_number
+ON einvoices(provider, template_code, invoice_series, invoice_number)
+WHERE invoice_number IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_einvoices_order
+ON einvoices(order_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_einvoices_status
+ON einvoices(status, created_at DESC);
+
+CREATE TRIGGER trigger_einvoices_updated_at
+BEFORE UPDATE ON einvoices
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- ================================================
+-- ORDERS: hoá đơn đang có hiệu lực
+-- ================================================
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS einvoice_template_code TEXT,
+    ADD COLUMN IF NOT EXISTS einvoice_series TEXT,
+    ADD COLUMN IF NOT EXISTS einvoice_number TEXT,
+    ADD COLUMN IF NOT EXISTS einvoice_issued_at TIMESTAMPTZ;
+
+COMMENT ON TABLE einvoices IS
+'Electronic VAT invoices issued through a certified provider; corrections are new replacement/adjustment invoices.';
+COMMENT ON COLUMN orders.einvoice_number IS
+'Number of the e-invoice currently in effect for the order (original or latest replacement).';
@@ -0,0 +1,33 @@
+-- ================================================
+-- Migration: Create Order Cancellation Rules
+-- Purpose: Cửa sổ khách tự huỷ đơn cấu hình được (admin sửa), thay cho CanBeCancelled hard-code
+-- Version: 000073
+-- ================================================
+
+-- WHY THIS TABLE?
+-- 1. Siết / nới điều kiện huỷ (vd: chỉ huỷ trong 2 giờ đầu) phải sửa code + deploy
+-- 2. Đơn flash sale cần rule chặt hơn: huỷ xong hàng khó bán lại đúng giá
+--
+-- Mô hình:
+-- - 1 dòng = rule của 1 phạm vi (default, flash_sale)
+-- - max_status: trạng thái cuối cùng còn huỷ được (pending < confirmed < processing)
+-- - window_hours: số giờ kể từ lúc đặt còn huỷ được, 0 = không giới hạn thời gian
+-- - Đơn huỷ được khi thoả CẢ 2 điều kiện
+
+CREATE TABLE IF NOT EXISTS order_cancellation_rules (
+    scope TEXT PRIMARY KEY CHECK (scope IN ('default', 'flash_sale')),
+    max_status TEXT NOT NULL CHECK (max_status IN ('pending', 'confirmed', 'processing')),
+    window_hours INT NOT NULL DEFAULT 0 CHECK (window_hours BETWEEN 0 AND 720),
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE order_cancellation_rules IS
+'Customer self-cancellation rules per scope: cancellable while status <= max_status and within window_hours of placing (0 = no time limit).';
+
+-- Seed: default đúng bằng CanBeCancelled hard-code trước đây, flash sale chặt hơn
+INSERT INTO order_cancellation_rules (scope, max_status, window_hours) VALUES
+    ('default',    'confirmed', 0),
+    ('flash_sale', 'pending',   1)
+ON CONFLICT (scope) DO NOTHING;
@@ -0,0 +1,8 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer', 'invoice', 'pay_link'));
+
+DROP TABLE IF EXISTS ticket_compensations;
+DROP TABLE IF EXISTS ticket_messages;
+DROP TRIGGER IF EXISTS update_order_tickets_updated_at ON order_tickets;
+DROP TABLE IF EXISTS order_tickets;
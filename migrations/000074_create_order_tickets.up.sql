@@ -0,0 +1,109 @@
+-- ================================================
+-- Migration: Order Issue Tickets
+-- Purpose: Khách khiếu nại đơn hàng (thiếu sách, sách hỏng, giao nhầm, giao trễ),
+--          CSKH nhận / xử lý, bồi thường (hoàn tiền, gửi bù) + theo dõi SLA
+-- Version: 000074
+-- ================================================
+
+-- FLOW:
+-- open → CSKH nhận (assign) → in_progress ⇄ waiting_customer → resolved → closed
+-- Khách trả lời khi waiting_customer / resolved → in_progress
+-- resolved → in_progress (mở lại), closed là trạng thái cuối
+
+CREATE TABLE IF NOT EXISTS order_tickets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ticket_number TEXT NOT NULL UNIQUE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    category TEXT NOT NULL
+        CHECK (category IN ('missing_item', 'damaged_item', 'wrong_item', 'late_delivery', 'other')),
+    priority TEXT NOT NULL DEFAULT 'normal'
+        CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    status TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'in_progress', 'waiting_customer', 'resolved', 'closed')),
+
+    subject TEXT NOT NULL,
+    description TEXT NOT NULL,
+    photo_urls TEXT[] NOT NULL DEFAULT '{}',
+
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ,
+
+    -- SLA: hạn tính từ created_at theo priority (đổi priority → tính lại)
+    first_response_due_at TIMESTAMPTZ NOT NULL,
+    resolution_due_at TIMESTAMPTZ NOT NULL,
+    first_responded_at TIMESTAMPTZ,     -- staff trả lời lần đầu
+    resolved_at TIMESTAMPTZ,            -- resolved / closed lần gần nhất
+    closed_at TIMESTAMPTZ,
+    sla_breached_at TIMESTAMPTZ,        -- job check SLA đánh dấu lần đầu trễ hạn
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- WHY PARTIAL UNIQUE? Mỗi đơn chỉ 1 ticket đang mở mỗi loại (khách bấm gửi 2 lần),
+-- ticket đã xử lý xong thì khiếu nại lại cùng loại vẫn được
+CREATE UNIQUE INDEX uq_order_tickets_open_per_category
+    ON order_tickets(order_id, category)
+    WHERE status NOT IN ('resolved', 'closed');
+
+-- USE CASE: Khách xem danh sách khiếu nại của mình
+CREATE INDEX idx_order_tickets_user ON order_tickets(user_id, created_at DESC);
+
+-- USE CASE: Hàng đợi CSKH (ticket đang mở theo hạn SLA) + job check SLA
+CREATE INDEX idx_order_tickets_open_due ON order_tickets(resolution_due_at)
+    WHERE status NOT IN ('resolved', 'closed');
+
+CREATE INDEX idx_order_tickets_assignee ON order_tickets(assigned_to, status)
+    WHERE assigned_to IS NOT NULL;
+
+-- USE CASE: Dashboard SLA theo khoảng thời gian
+CREATE INDEX idx_order_tickets_created ON order_tickets(created_at);
+
+CREATE TRIGGER update_order_tickets_updated_at
+    BEFORE UPDATE ON order_tickets
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS ticket_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ticket_id UUID NOT NULL REFERENCES order_tickets(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id),
+    sender_type TEXT NOT NULL CHECK (sender_type IN ('customer', 'staff')),
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ticket_messages_ticket ON ticket_messages(ticket_id, created_at);
+
+-- Bồi thường: refund → refund_requests (pending, duyệt như refund thường)
+--             resend → đơn 0đ gửi bù (payment_method 'replacement')
+CREATE TABLE IF NOT EXISTS ticket_compensations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ticket_id UUID NOT NULL REFERENCES order_tickets(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('refund', 'resend')),
+    amount NUMERIC(12,2) CHECK (amount IS NULL OR amount > 0),
+    refund_request_id UUID REFERENCES refund_requests(id),
+    replacement_order_id UUID REFERENCES orders(id),
+    note TEXT,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_ticket_compensation_link CHECK (
+        (type = 'refund' AND amount IS NOT NULL AND refund_request_id IS NOT NULL AND replacement_order_id IS NULL)
+        OR (type = 'resend' AND replacement_order_id IS NOT NULL AND refund_request_id IS NULL)
+    )
+);
+
+CREATE INDEX idx_ticket_compensations_ticket ON ticket_compensations(ticket_id);
+CREATE INDEX idx_ticket_compensations_created ON ticket_compensations(created_at);
+
+-- ================================================
+-- ORDERS: payment method 'replacement' (đơn gửi bù 0đ)
+-- ================================================
+-- WHY? Đơn gửi bù không thanh toán, không COD → tách khỏi các phương thức thật
+-- để báo cáo doanh thu / đối soát không lẫn
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer', 'invoice', 'pay_link', 'replacement'));
@@ -0,0 +1,46 @@
+-- ================================================
+-- Migration: Replacement Orders
+-- Purpose: Liên kết đơn gửi bù (0đ, payment_method 'replacement') với đơn gốc + lý do,
+--          để báo cáo hàng hỏng / thất lạc khi vận chuyển
+-- Version: 000075
+-- ================================================
+
+-- WHY SEPARATE TABLE? (giống order_payment_terms)
+-- Chỉ đơn gửi bù có đơn gốc → không thêm cột NULL vào orders
+CREATE TABLE IF NOT EXISTS order_replacements (
+    order_id UUID PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,   -- đơn gửi bù
+    original_order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL
+        CHECK (reason IN ('damaged_in_transit', 'lost_in_transit', 'missing_item', 'wrong_item', 'defective', 'other')),
+    note TEXT,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_order_replacements_not_self CHECK (order_id <> original_order_id)
+);
+
+-- USE CASE: Order detail (các đơn gửi bù của đơn gốc) + giới hạn số lượng đã gửi bù
+CREATE INDEX idx_order_replacements_original ON order_replacements(original_order_id);
+
+-- USE CASE: Báo cáo gửi bù theo lý do trong khoảng thời gian
+CREATE INDEX idx_order_replacements_created ON order_replacements(created_at, reason);
+
+-- Backfill: đơn gửi bù đã tạo từ ticket khiếu nại (lý do theo loại ticket)
+INSERT INTO order_replacements (order_id, original_order_id, reason, note, created_by, created_at)
+SELECT
+    tc.replacement_order_id,
+    t.order_id,
+    CASE t.category
+        WHEN 'missing_item' THEN 'missing_item'
+        WHEN 'damaged_item' THEN 'damaged_in_transit'
+        WHEN 'wrong_item' THEN 'wrong_item'
+        WHEN 'late_delivery' THEN 'lost_in_transit'
+        ELSE 'other'
+    END,
+    tc.note,
+    tc.created_by,
+    tc.created_at
+FROM ticket_compensations tc
+JOIN order_tickets t ON t.id = tc.ticket_id
+WHERE tc.type = 'resend' AND tc.replacement_order_id IS NOT NULL
+ON CONFLICT (order_id) DO NOTHING;
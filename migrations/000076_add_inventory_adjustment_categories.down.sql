@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_audit_log_adjustment_category;
+
+ALTER TABLE inventory_adjustment_requests DROP COLUMN IF EXISTS reason_category;
+ALTER TABLE inventory_audit_log DROP COLUMN IF EXISTS reason_category;
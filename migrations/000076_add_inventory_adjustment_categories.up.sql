@@ -0,0 +1,36 @@
+-- ================================================
+-- Migration: Inventory Adjustment Reason Categories
+-- Purpose: Structured write-off categories for stock adjustments + write-off report
+-- Version: 000076
+-- ================================================
+
+-- WHY A CATEGORY COLUMN?
+-- 1. reason là free-text ("rách bìa 3 cuốn", "kiểm kê thiếu") → không group được cho báo cáo hao hụt
+-- 2. reason_category phân loại cố định: damaged / lost / expired / sample (write-off)
+--    + recount (kiểm kê lệch sổ) / other
+-- 3. reason giữ nguyên làm ghi chú chi tiết cho audit
+-- 4. Trigger log_inventory_change() không biết lý do → service gắn category vào
+--    audit row ADJUSTMENT trong cùng transaction với UPDATE warehouse_inventory
+
+ALTER TABLE inventory_audit_log
+    ADD COLUMN IF NOT EXISTS reason_category TEXT
+        CHECK (reason_category IN ('damaged', 'lost', 'expired', 'sample', 'recount', 'other'));
+
+-- Request duyệt 2 bước giữ category để khi approve audit row ADJUSTMENT vẫn có category
+ALTER TABLE inventory_adjustment_requests
+    ADD COLUMN IF NOT EXISTS reason_category TEXT NOT NULL DEFAULT 'other'
+        CHECK (reason_category IN ('damaged', 'lost', 'expired', 'sample', 'recount', 'other'));
+
+-- Adjustment cũ chỉ có free-text → xếp vào 'other'
+UPDATE inventory_audit_log
+SET reason_category = 'other'
+WHERE action LIKE 'ADJUSTMENT%'
+  AND reason_category IS NULL;
+
+-- USE CASE: Báo cáo write-off theo kho + category trong khoảng ngày
+CREATE INDEX IF NOT EXISTS idx_audit_log_adjustment_category
+ON inventory_audit_log(warehouse_id, reason_category, created_at)
+WHERE action = 'ADJUSTMENT';
+
+COMMENT ON COLUMN inventory_audit_log.reason_category IS
+'Structured adjustment reason: damaged, lost, expired, sample (write-offs), recount, other.';
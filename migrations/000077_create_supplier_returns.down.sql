@@ -0,0 +1,13 @@
+-- Giữ lịch sử trừ kho, chỉ đổi về action cũ
+UPDATE inventory_audit_log SET action = 'ADJUSTMENT' WHERE action = 'RTV';
+
+ALTER TABLE inventory_audit_log DROP CONSTRAINT IF EXISTS inventory_audit_log_action_check;
+ALTER TABLE inventory_audit_log ADD CONSTRAINT inventory_audit_log_action_check
+    CHECK (action IN (
+        'RESTOCK', 'RESERVE', 'RELEASE', 'ADJUSTMENT', 'SALE',
+        'ADJUSTMENT_REQUESTED', 'ADJUSTMENT_APPROVED', 'ADJUSTMENT_REJECTED'
+    ));
+
+DROP TABLE IF EXISTS supplier_credit_notes;
+DROP TABLE IF EXISTS supplier_return_items;
+DROP TABLE IF EXISTS supplier_returns;
@@ -0,0 +1,88 @@
+-- ================================================
+-- Migration: Supplier Returns (RTV - return to vendor)
+-- Purpose: Trả hàng hỏng / dư về nhà cung cấp, theo dõi credit note NCC gửi lại
+-- Version: 000077
+-- ================================================
+
+-- WHY?
+-- 1. Hàng hỏng / tồn dư trả NCC không phải hao hụt: NCC trả lại tiền bằng credit note
+--    → tách khỏi write-off (ADJUSTMENT damaged/lost...) để báo cáo không bị đội số
+-- 2. Nhà cung cấp = nhà xuất bản (books.publisher_id), mỗi phiếu RTV chỉ 1 NCC + 1 kho
+-- 3. Tạo phiếu là trừ kho ngay (hàng đã tách khỏi kệ), audit action = 'RTV'
+-- 4. Credit note ghi nhận dần (NCC có thể trả nhiều đợt), đủ giá trị phiếu → credited
+
+CREATE TABLE IF NOT EXISTS supplier_returns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rtv_number TEXT NOT NULL UNIQUE,                          -- RTV-YYYYMMDD-XXXXXXXX
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    supplier_id UUID NOT NULL REFERENCES publishers(id),
+
+    status TEXT NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'partially_credited', 'credited', 'closed')),
+
+    -- Snapshot lúc tạo (giá vốn books.cost_price)
+    total_quantity INT NOT NULL CHECK (total_quantity > 0),
+    total_value NUMERIC(12,2) NOT NULL CHECK (total_value >= 0),
+    credited_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (credited_amount >= 0),
+
+    note TEXT,
+    close_note TEXT,                                           -- đóng phiếu khi NCC không trả đủ
+
+    created_by UUID NOT NULL REFERENCES users(id),
+    closed_by UUID REFERENCES users(id),
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: Danh sách phiếu theo trạng thái (phiếu chờ credit note)
+CREATE INDEX idx_supplier_returns_status ON supplier_returns(status, created_at DESC);
+
+-- USE CASE: Báo cáo RTV theo NCC trong khoảng thời gian
+CREATE INDEX idx_supplier_returns_supplier ON supplier_returns(supplier_id, created_at);
+
+CREATE TRIGGER trg_supplier_returns_updated_at
+    BEFORE UPDATE ON supplier_returns
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS supplier_return_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    return_id UUID NOT NULL REFERENCES supplier_returns(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_cost NUMERIC(10,2) NOT NULL CHECK (unit_cost >= 0),
+    condition TEXT NOT NULL CHECK (condition IN ('damaged', 'excess')),
+
+    CONSTRAINT uq_supplier_return_items_book UNIQUE (return_id, book_id)
+);
+
+CREATE TABLE IF NOT EXISTS supplier_credit_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    return_id UUID NOT NULL REFERENCES supplier_returns(id) ON DELETE CASCADE,
+    credit_note_number TEXT NOT NULL,                          -- số chứng từ của NCC
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    issued_date DATE NOT NULL,
+    note TEXT,
+    recorded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Nhập trùng chứng từ
+    CONSTRAINT uq_supplier_credit_notes_number UNIQUE (return_id, credit_note_number)
+);
+
+CREATE INDEX idx_supplier_credit_notes_return ON supplier_credit_notes(return_id);
+
+-- ================================================
+-- Audit log: allow RTV action
+-- ================================================
+ALTER TABLE inventory_audit_log DROP CONSTRAINT IF EXISTS inventory_audit_log_action_check;
+ALTER TABLE inventory_audit_log ADD CONSTRAINT inventory_audit_log_action_check
+    CHECK (action IN (
+        'RESTOCK', 'RESERVE', 'RELEASE', 'ADJUSTMENT', 'SALE',
+        'ADJUSTMENT_REQUESTED', 'ADJUSTMENT_APPROVED', 'ADJUSTMENT_REJECTED',
+        'RTV'
+    ));
+
+COMMENT ON TABLE supplier_returns IS
+'Return-to-vendor documents: stock returned to the supplier (publisher) and credit notes received.';
@@ -0,0 +1,6 @@
+ALTER TABLE warehouse_inventory
+    DROP COLUMN IF EXISTS safety_stock;
+
+ALTER TABLE warehouses
+    DROP COLUMN IF EXISTS allocation_priority,
+    DROP COLUMN IF EXISTS safety_stock;
@@ -0,0 +1,35 @@
+-- ================================================
+-- Migration: Warehouse Safety Stock & Allocation Priority
+-- Purpose: Giữ tồn đệm cho khách nhận tại cửa hàng + admin chỉnh ưu tiên kho khi chọn kho cho đơn online
+-- Version: 000078
+-- ================================================
+
+-- WHY SAFETY STOCK?
+-- 1. Kho cũng là điểm nhận hàng tại cửa hàng → cần giữ lại 1 lượng tồn đệm
+-- 2. Chọn kho cho đơn online chỉ dùng (quantity - reserved - safety_stock)
+-- 3. safety_stock trên warehouses = mức mặc định cho mọi sách của kho
+--    warehouse_inventory.safety_stock (NULL = dùng mặc định kho) = override cho sách bán chạy
+-- 4. Không chặn bán tại quầy / điều chuyển / RTV: chỉ áp cho việc chọn kho đơn online
+
+-- WHY ALLOCATION PRIORITY?
+-- 1. Kho gần nhất chưa chắc là kho nên xuất (kho nhỏ, sắp kiểm kê, phí vận hành cao...)
+-- 2. allocation_priority là trọng số % (mặc định 100):
+--    khoảng cách hiệu dụng = distance_km * 100 / allocation_priority
+--    200 = ưu tiên gấp đôi (kho ở 20km ngang kho 10km), 50 = chỉ chọn khi kho khác xa gấp đôi
+
+ALTER TABLE warehouses
+    ADD COLUMN IF NOT EXISTS safety_stock INT NOT NULL DEFAULT 0
+        CHECK (safety_stock >= 0),
+    ADD COLUMN IF NOT EXISTS allocation_priority INT NOT NULL DEFAULT 100
+        CHECK (allocation_priority BETWEEN 1 AND 1000);
+
+ALTER TABLE warehouse_inventory
+    ADD COLUMN IF NOT EXISTS safety_stock INT
+        CHECK (safety_stock IS NULL OR safety_stock >= 0);
+
+COMMENT ON COLUMN warehouses.safety_stock IS
+'Default per-book buffer kept out of online order allocation (reserved for store pickup).';
+COMMENT ON COLUMN warehouses.allocation_priority IS
+'Allocation weight in percent (default 100). Effective distance = distance_km * 100 / allocation_priority.';
+COMMENT ON COLUMN warehouse_inventory.safety_stock IS
+'Per-book safety stock override. NULL = use warehouses.safety_stock.';
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trg_inventory_serial_units_updated_at ON inventory_serial_units;
+DROP TABLE IF EXISTS inventory_serial_units;
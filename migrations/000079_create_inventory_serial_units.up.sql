@@ -0,0 +1,64 @@
+-- ================================================
+-- Migration: Serial / Lot Tracking for Special Editions
+-- Purpose: Ghi serial (+ lot) từng cuốn khi nhập kho, gán serial cho order item lúc soạn hàng,
+--          tra cứu serial để xác thực bản giới hạn / có chữ ký
+-- Version: 000079
+-- ================================================
+
+-- WHY A UNIT TABLE?
+-- 1. warehouse_inventory chỉ giữ số lượng → không biết cuốn nào (serial nào) đã giao cho đơn nào
+-- 2. Tracking là tuỳ chọn: chỉ sách nhập kèm serial (bản giới hạn / có chữ ký) mới có row ở đây
+-- 3. Số lượng tồn vẫn do warehouse_inventory quản lý, bảng này chỉ định danh từng cuốn
+-- 4. lot_number = lô nhập / lô in (VD "SIGNED-2026-01"), dùng chung cho mọi serial trong 1 lần nhập
+--
+-- STATUS:
+--   in_stock → assigned (soạn hàng: gán cho order item) → in_stock (gỡ khi soạn nhầm, đơn chưa giao)
+
+CREATE TABLE IF NOT EXISTS inventory_serial_units (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    serial_number TEXT NOT NULL,
+    lot_number TEXT,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE RESTRICT,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+
+    status TEXT NOT NULL DEFAULT 'in_stock'
+        CHECK (status IN ('in_stock', 'assigned')),
+
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    order_item_id UUID REFERENCES order_items(id) ON DELETE SET NULL,
+
+    received_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_serial_units_assignment CHECK (
+        (status = 'in_stock' AND order_item_id IS NULL)
+        OR (status = 'assigned' AND order_item_id IS NOT NULL)
+    )
+);
+
+-- Serial là duy nhất toàn hệ thống (không phân biệt hoa thường) → tra cứu xác thực không mơ hồ
+CREATE UNIQUE INDEX IF NOT EXISTS uq_serial_units_serial
+ON inventory_serial_units(UPPER(serial_number));
+
+-- USE CASE: Soạn hàng - liệt kê serial còn trong kho của 1 sách
+CREATE INDEX IF NOT EXISTS idx_serial_units_stock
+ON inventory_serial_units(warehouse_id, book_id)
+WHERE status = 'in_stock';
+
+-- USE CASE: Phiếu đóng gói / chi tiết đơn - serial đã gán cho từng order item
+CREATE INDEX IF NOT EXISTS idx_serial_units_order_item
+ON inventory_serial_units(order_item_id)
+WHERE order_item_id IS NOT NULL;
+
+CREATE TRIGGER trg_inventory_serial_units_updated_at
+    BEFORE UPDATE ON inventory_serial_units
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE inventory_serial_units IS
+'Optional per-unit serial / lot tracking for limited and signed editions. Quantities stay in warehouse_inventory.';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_promotion_usage_import;
+
+ALTER TABLE promotion_usage
+    DROP COLUMN IF EXISTS import_id;
+
+DROP TABLE IF EXISTS promotion_usage_imports;
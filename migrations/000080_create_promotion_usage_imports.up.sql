@@ -0,0 +1,40 @@
+-- ================================================
+-- Migration: Promotion Usage Imports
+-- Purpose: Backfill promotion_usage từ hệ thống cũ (CSV user / order / code) khi chuyển cửa hàng
+-- Version: 000080
+-- ================================================
+
+-- WHY AN IMPORT TABLE?
+-- 1. Chuyển dữ liệu từ hệ thống cũ: đơn đã import vào orders nhưng chưa có lịch sử dùng mã
+--    → max_uses / max_uses_per_user / first-order check bị sai nếu không backfill
+-- 2. Mỗi lần import ghi lại file, người chạy, số dòng import / trùng / lỗi để đối chiếu
+-- 3. promotion_usage.import_id phân biệt usage backfill với usage phát sinh từ checkout
+-- 4. current_uses vẫn do trigger increment_promotion_usage() tăng (chỉ cho row thực sự insert),
+--    import chạy trong 1 transaction nên counter và usage luôn khớp
+
+CREATE TABLE IF NOT EXISTS promotion_usage_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    file_name TEXT NOT NULL,
+
+    total_rows INT NOT NULL DEFAULT 0,
+    imported_rows INT NOT NULL DEFAULT 0,
+    duplicate_rows INT NOT NULL DEFAULT 0, -- (promotion, order) đã có usage → bỏ qua, import lại an toàn
+    invalid_rows INT NOT NULL DEFAULT 0,
+    skip_invalid BOOLEAN NOT NULL DEFAULT FALSE,
+
+    imported_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE promotion_usage
+    ADD COLUMN IF NOT EXISTS import_id UUID REFERENCES promotion_usage_imports(id) ON DELETE SET NULL;
+
+-- USE CASE: Liệt kê usage của 1 lần import
+CREATE INDEX IF NOT EXISTS idx_promotion_usage_import
+ON promotion_usage(import_id)
+WHERE import_id IS NOT NULL;
+
+COMMENT ON TABLE promotion_usage_imports IS
+'Legacy promotion usage backfill runs (CSV of user / order / code), one row per committed import.';
+COMMENT ON COLUMN promotion_usage.import_id IS
+'Set when the usage was backfilled by a promotion usage import. NULL = recorded at checkout.';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS promotion_assignments;
+
+DROP INDEX IF EXISTS idx_promotions_active;
+CREATE INDEX idx_promotions_active ON promotions(is_active, starts_at, expires_at)
+    WHERE is_active = true;
+
+ALTER TABLE promotions DROP COLUMN IF EXISTS is_targeted;
@@ -0,0 +1,41 @@
+-- ================================================
+-- Migration: Promotion Assignments (targeted promos / mã cá nhân)
+-- Purpose: Gán promotion cho từng user để hiển thị trong ví mã ("my coupons")
+-- Version: 000081
+-- ================================================
+
+-- WHY TARGETED PROMOTIONS?
+-- 1. Mã cá nhân (đền bù khiếu nại, tri ân khách thân thiết, mã dùng 1 lần) không được lộ ở
+--    danh sách công khai và không ai khác nhập được
+-- 2. is_targeted = true → chỉ user có trong promotion_assignments mới validate / áp dụng được
+-- 3. Ví mã của user = promotion công khai đang chạy + promotion được gán cho mình, còn lượt dùng
+-- 4. Giữ nguyên giới hạn max_uses / max_uses_per_user: mã dùng 1 lần = max_uses_per_user = 1
+
+ALTER TABLE promotions
+    ADD COLUMN IF NOT EXISTS is_targeted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS promotion_assignments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    note TEXT, -- lý do gán (ticket khiếu nại, chiến dịch...)
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_promotion_assignments UNIQUE (promotion_id, user_id)
+);
+
+-- USE CASE: Ví mã của user (GET /promotion/wallet)
+CREATE INDEX IF NOT EXISTS idx_promotion_assignments_user
+ON promotion_assignments(user_id);
+
+-- USE CASE: Danh sách công khai loại mã cá nhân
+DROP INDEX IF EXISTS idx_promotions_active;
+CREATE INDEX idx_promotions_active ON promotions(is_active, starts_at, expires_at)
+    WHERE is_active = true AND is_targeted = false;
+
+COMMENT ON COLUMN promotions.is_targeted IS
+'TRUE = only users listed in promotion_assignments can see and apply this promotion.';
+COMMENT ON TABLE promotion_assignments IS
+'Users a targeted promotion was issued to (personal / single-use coupons shown in the promo wallet).';
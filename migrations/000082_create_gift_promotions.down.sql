@@ -0,0 +1,16 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS gift_promotion_id;
+
+DELETE FROM cart_items WHERE gift_promotion_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_cart_items_cart_created_covering;
+CREATE INDEX idx_cart_items_cart_created_covering
+    ON cart_items(cart_id, created_at DESC)
+    INCLUDE (id, book_id, quantity, price, updated_at);
+
+DROP INDEX IF EXISTS uq_cart_items_cart_gift;
+DROP INDEX IF EXISTS uq_cart_items_cart_book;
+ALTER TABLE cart_items ADD CONSTRAINT cart_items_cart_id_book_id_key UNIQUE (cart_id, book_id);
+
+ALTER TABLE cart_items DROP COLUMN IF EXISTS gift_promotion_id;
+
+DROP TABLE IF EXISTS gift_promotions;
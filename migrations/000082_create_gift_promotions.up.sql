@@ -0,0 +1,80 @@
+-- ================================================
+-- Migration: Gift-with-purchase promotions (tặng quà kèm đơn)
+-- Purpose: Tự thêm 1 dòng quà 0đ vào giỏ khi giỏ đạt điều kiện (giá trị tối thiểu / danh mục)
+-- Version: 000082
+-- ================================================
+
+-- WHY BẢNG RIÊNG (không dùng promotions)?
+-- 1. promotions là mã giảm tiền (percentage / fixed) có CHECK trên discount_value,
+--    quà tặng không giảm tiền mà thêm sách → không nhét vừa discount_type
+-- 2. Quà tự áp dụng (không cần nhập mã) và cộng dồn được với mã giảm giá
+-- 3. Không cần max_uses: tồn kho của sách quà là giới hạn tự nhiên (hết hàng → không tặng)
+
+CREATE TABLE IF NOT EXISTS gift_promotions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    description TEXT,
+
+    gift_book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    gift_quantity INT NOT NULL DEFAULT 1 CHECK (gift_quantity BETWEEN 1 AND 10),
+
+    -- Điều kiện: tính trên dòng hàng trả tiền (không tính dòng quà)
+    min_order_amount NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (min_order_amount >= 0),
+    applicable_category_ids UUID[] NOT NULL DEFAULT '{}',
+
+    starts_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_gift_promotions_dates CHECK (expires_at > starts_at)
+);
+
+-- USE CASE: Đồng bộ quà sau mỗi thay đổi giỏ (chỉ quét chương trình đang chạy)
+CREATE INDEX IF NOT EXISTS idx_gift_promotions_active
+ON gift_promotions(starts_at, expires_at)
+WHERE is_active = true;
+
+-- ================================================
+-- CART ITEMS: dòng quà
+-- ================================================
+-- gift_promotion_id NOT NULL = dòng quà (price = 0, do hệ thống thêm/xoá, user không sửa được)
+-- Cùng 1 sách có thể vừa là hàng mua vừa là quà → unique (cart_id, book_id) chỉ áp cho dòng mua,
+-- dòng quà unique theo (cart_id, gift_promotion_id)
+
+ALTER TABLE cart_items
+    ADD COLUMN IF NOT EXISTS gift_promotion_id UUID REFERENCES gift_promotions(id) ON DELETE CASCADE;
+
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_cart_id_book_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cart_items_cart_book
+ON cart_items(cart_id, book_id)
+WHERE gift_promotion_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cart_items_cart_gift
+ON cart_items(cart_id, gift_promotion_id)
+WHERE gift_promotion_id IS NOT NULL;
+
+-- Covering index của cart item hot path cần thêm cột mới để vẫn index-only scan
+DROP INDEX IF EXISTS idx_cart_items_cart_created_covering;
+CREATE INDEX idx_cart_items_cart_created_covering
+    ON cart_items(cart_id, created_at DESC)
+    INCLUDE (id, book_id, quantity, price, updated_at, gift_promotion_id);
+
+-- ================================================
+-- ORDER ITEMS: snapshot dòng quà
+-- ================================================
+-- Không FK: chương trình quà bị xoá vẫn giữ lịch sử đơn
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS gift_promotion_id UUID;
+
+COMMENT ON TABLE gift_promotions IS
+'Gift-with-purchase: a zero-priced gift line is auto-added to carts meeting min_order_amount / categories.';
+COMMENT ON COLUMN cart_items.gift_promotion_id IS
+'NOT NULL = system-managed free gift line (price 0), synced whenever the cart changes.';
+COMMENT ON COLUMN order_items.gift_promotion_id IS
+'Gift promotion that added this free line (snapshot, no FK).';
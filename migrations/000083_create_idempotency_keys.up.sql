@@ -0,0 +1,34 @@
+-- ================================================
+-- Migration: Idempotency keys (checkout / tạo đơn)
+-- Purpose: Lưu response gốc theo header Idempotency-Key để request retry không tạo đơn trùng
+-- Version: 000083
+-- ================================================
+
+-- WHY?
+-- 1. App mobile mạng chập chờn: timeout phía client nhưng server đã tạo đơn → bấm lại tạo đơn thứ 2
+-- 2. Cùng (user, scope, key) trong 24h → trả lại đúng response đã lưu, không chạy lại checkout
+-- 3. status = processing giữ chỗ cho request đang chạy → request song song cùng key nhận 409
+-- 4. request_hash chặn dùng lại key cho body khác (client bug) → 422 thay vì trả nhầm đơn cũ
+-- 5. Key hết hạn được ghi đè khi client dùng lại (ON CONFLICT ... WHERE expires_at <= NOW())
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL,            -- cart_checkout | order_create
+    idempotency_key TEXT NOT NULL,
+
+    request_hash TEXT NOT NULL,     -- sha256(method + path + body)
+    status TEXT NOT NULL DEFAULT 'processing'
+        CHECK (status IN ('processing', 'completed')),
+
+    response_status INT,
+    response_body BYTEA,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    PRIMARY KEY (user_id, scope, idempotency_key)
+);
+
+COMMENT ON TABLE idempotency_keys IS
+'Stored responses for POST requests carrying an Idempotency-Key header; retries within expires_at replay the original response.';
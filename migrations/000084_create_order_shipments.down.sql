@@ -0,0 +1,3 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS shipment_id;
+
+DROP TABLE IF EXISTS order_shipments;
@@ -0,0 +1,38 @@
+-- ================================================
+-- Migration: Order shipments (tách đơn nhiều kho)
+-- Purpose: 1 đơn giao thành nhiều kiện khi không kho nào đủ hàng cho toàn bộ đơn
+-- Version: 000084
+-- ================================================
+
+-- WHY?
+-- 1. Trước đây chọn 1 kho duy nhất đủ hàng cho mọi dòng, không có → cả đơn bị từ chối
+-- 2. Planner gom dòng hàng vào ít kho nhất (ưu tiên kho gần / allocation_priority),
+--    mỗi kho là 1 kiện (order_shipments), reserve tồn theo từng kho
+-- 3. order_items.shipment_id cho biết dòng hàng đi kho nào → huỷ đơn release đúng kho
+-- 4. orders.warehouse_id giữ kho của kiện đầu tiên (kho chính) cho code / báo cáo cũ
+-- 5. Đơn cũ không có shipment: mọi dòng coi như đi orders.warehouse_id
+
+CREATE TABLE IF NOT EXISTS order_shipments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE RESTRICT,
+
+    shipment_number INT NOT NULL CHECK (shipment_number > 0), -- 1..n trong đơn
+    status TEXT NOT NULL DEFAULT 'pending',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_order_shipments_number UNIQUE (order_id, shipment_number)
+);
+
+-- USE CASE: Kho xem các kiện cần soạn
+CREATE INDEX IF NOT EXISTS idx_order_shipments_warehouse
+ON order_shipments(warehouse_id, created_at DESC);
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS shipment_id UUID REFERENCES order_shipments(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE order_shipments IS
+'Per-warehouse parcels of an order; an order is split when no single warehouse can fulfill every line.';
+COMMENT ON COLUMN order_items.shipment_id IS
+'Shipment (and therefore warehouse) the line is reserved at. NULL = legacy order, use orders.warehouse_id.';
@@ -0,0 +1,14 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_discount;
+
+DELETE FROM promotions WHERE discount_type IN ('shipping_percentage', 'shipping_fixed');
+
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS chk_promotions_percentage_max;
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_discount_type_check;
+
+ALTER TABLE promotions
+    ADD CONSTRAINT promotions_discount_type_check
+    CHECK (discount_type IN ('percentage', 'fixed'));
+
+ALTER TABLE promotions
+    ADD CONSTRAINT promotions_check1
+    CHECK (discount_type = 'fixed' OR (discount_type = 'percentage' AND discount_value <= 100));
@@ -0,0 +1,31 @@
+-- ================================================
+-- Migration: Shipping-level discounts (giảm / miễn phí ship)
+-- Purpose: Mã khuyến mãi giảm phí ship + giảm ship tự động theo bậc tiền hàng
+-- Version: 000085
+-- ================================================
+
+-- WHY?
+-- 1. promotions chỉ giảm tiền hàng (percentage / fixed) → không có mã "freeship" / "giảm 15k ship"
+-- 2. shipping_percentage: giảm % phí ship (100 = miễn phí), cap bằng max_discount_amount
+--    shipping_fixed: giảm số tiền cố định trên phí ship, không vượt phí ship
+-- 3. Giảm ship tự động theo bậc (ORDER_SHIPPING_DISCOUNT_TIERS) không cần mã
+-- 4. orders.shipping_discount là dòng riêng: shipping_fee giữ phí gốc, discount_amount chỉ là giảm tiền hàng
+--    total = subtotal - discount_amount + shipping_fee - shipping_discount + cod_fee + tax
+
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_discount_type_check;
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_check1;
+
+ALTER TABLE promotions
+    ADD CONSTRAINT promotions_discount_type_check
+    CHECK (discount_type IN ('percentage', 'fixed', 'shipping_percentage', 'shipping_fixed'));
+
+ALTER TABLE promotions
+    ADD CONSTRAINT chk_promotions_percentage_max
+    CHECK (discount_type NOT IN ('percentage', 'shipping_percentage') OR discount_value <= 100);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS shipping_discount NUMERIC(12,2) NOT NULL DEFAULT 0
+    CHECK (shipping_discount >= 0);
+
+COMMENT ON COLUMN orders.shipping_discount IS
+'Discount applied to shipping_fee (promo code or automatic threshold tier), never exceeds shipping_fee.';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_metadata;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS metadata;
+ALTER TABLE orders DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,27 @@
+-- ================================================
+-- Migration: Order metadata (JSONB attributes)
+-- Purpose: Thuộc tính mở rộng cho đơn / dòng hàng: nguồn kênh (utm_*), affiliate, phiên bản app...
+-- Version: 000086
+-- ================================================
+
+-- WHY JSONB (không thêm cột riêng)?
+-- 1. Marketing thêm / bớt tham số kênh liên tục (utm_content, affiliate_id, app_version...),
+--    mỗi lần thêm cột là 1 migration + sửa mọi query
+-- 2. Giá trị chỉ là string → string, validate ở tầng app (key snake_case, tối đa 20 key, value <= 255 ký tự)
+-- 3. Admin lọc theo key / key + value → GIN index jsonb_path_ops cho toán tử @>
+-- 4. Đơn cũ = '{}' (NOT NULL DEFAULT) → không phải xử lý NULL
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- USE CASE: Admin tìm đơn theo nguồn (metadata @> '{"utm_source":"facebook"}')
+CREATE INDEX IF NOT EXISTS idx_orders_metadata
+ON orders USING GIN (metadata jsonb_path_ops);
+
+COMMENT ON COLUMN orders.metadata IS
+'Channel attribution set at checkout (utm_*, affiliate_id, app_version...). String values only.';
+COMMENT ON COLUMN order_items.metadata IS
+'Per-line attribution set at checkout (e.g. recommendation list the book was added from).';
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS affiliate_commission_lines;
+DROP TABLE IF EXISTS affiliate_commission_reports;
+
+DROP INDEX IF EXISTS idx_orders_affiliate_partner;
+ALTER TABLE orders DROP COLUMN IF EXISTS affiliate_partner_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS affiliate_code;
+
+DROP TABLE IF EXISTS affiliate_partners;
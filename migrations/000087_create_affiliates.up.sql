@@ -0,0 +1,95 @@
+-- ================================================
+-- Migration: Affiliate / partner attribution
+-- Purpose: Gắn đơn với đối tác giới thiệu (mã affiliate lúc checkout),
+--          cấu hình % hoa hồng theo đối tác, báo cáo hoa hồng hàng tháng chờ admin duyệt trước khi chi
+-- Version: 000087
+-- ================================================
+
+-- WHY cột riêng trên orders (không dùng metadata.affiliate_id)?
+-- 1. Hoa hồng là tiền: cần FK tới đối tác + index để job tháng quét theo đối tác
+-- 2. metadata là free-form từ client, affiliate_code được chuẩn hoá + resolve đối tác lúc tạo đơn
+-- 3. Mã không khớp đối tác đang hoạt động vẫn lưu affiliate_code (audit), partner_id = NULL → không tính hoa hồng
+--
+-- WHY lưu dòng báo cáo (affiliate_commission_lines)?
+-- 1. Báo cáo đã duyệt / đã chi phải cố định: đơn trả hàng sau đó, đổi % hoa hồng không làm đổi số đã chi
+-- 2. UNIQUE(order_id): 1 đơn chỉ được tính hoa hồng 1 lần dù chạy lại job
+--
+-- Luồng báo cáo: pending_approval → approved (admin duyệt) → paid (đã chi cho đối tác)
+-- Báo cáo pending_approval được tính lại khi chạy lại job / admin generate lại cùng tháng
+
+CREATE TABLE IF NOT EXISTS affiliate_partners (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code TEXT NOT NULL UNIQUE CHECK (code ~ '^[A-Z0-9][A-Z0-9_-]{2,31}$'),
+    name TEXT NOT NULL,
+    contact_email TEXT,
+    commission_rate NUMERIC(5,2) NOT NULL CHECK (commission_rate >= 0 AND commission_rate <= 100),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_affiliate_partners_updated_at
+BEFORE UPDATE ON affiliate_partners
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS affiliate_code TEXT,
+    ADD COLUMN IF NOT EXISTS affiliate_partner_id UUID REFERENCES affiliate_partners(id) ON DELETE SET NULL;
+
+-- USE CASE: Job hoa hồng tháng quét đơn đã giao theo đối tác
+CREATE INDEX IF NOT EXISTS idx_orders_affiliate_partner
+ON orders(affiliate_partner_id, delivered_at)
+WHERE affiliate_partner_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS affiliate_commission_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    partner_id UUID NOT NULL REFERENCES affiliate_partners(id) ON DELETE RESTRICT,
+    period_start DATE NOT NULL,  -- ngày đầu tháng
+    period_end DATE NOT NULL,    -- ngày đầu tháng sau (exclusive)
+    order_count INT NOT NULL DEFAULT 0,
+    net_sales NUMERIC(14,2) NOT NULL DEFAULT 0,
+    commission_rate NUMERIC(5,2) NOT NULL,
+    commission_amount NUMERIC(14,2) NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending_approval'
+        CHECK (status IN ('pending_approval', 'approved', 'paid')),
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMPTZ,
+    paid_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    paid_at TIMESTAMPTZ,
+    payout_reference TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_affiliate_commission_reports_period UNIQUE (partner_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_affiliate_commission_reports_status
+ON affiliate_commission_reports(status, period_start DESC);
+
+CREATE TRIGGER trigger_affiliate_commission_reports_updated_at
+BEFORE UPDATE ON affiliate_commission_reports
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS affiliate_commission_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    report_id UUID NOT NULL REFERENCES affiliate_commission_reports(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    order_number TEXT NOT NULL,
+    delivered_at TIMESTAMPTZ NOT NULL,
+    net_sales NUMERIC(12,2) NOT NULL,          -- subtotal - discount (không tính ship, thuế, phí COD)
+    commission_amount NUMERIC(12,2) NOT NULL,
+    CONSTRAINT uq_affiliate_commission_lines_order UNIQUE (order_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_affiliate_commission_lines_report
+ON affiliate_commission_lines(report_id);
+
+COMMENT ON COLUMN orders.affiliate_code IS
+'Normalized affiliate code captured at checkout (body, ?aff= query param or aff_code cookie).';
+COMMENT ON COLUMN orders.affiliate_partner_id IS
+'Active partner matching affiliate_code when the order was placed. NULL = no commission.';
+COMMENT ON TABLE affiliate_commission_reports IS
+'Monthly commission per partner, based on orders delivered in the period. Must be approved before being marked paid.';
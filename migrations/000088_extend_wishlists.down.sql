@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS guest_wishlist_items;
+
+ALTER TABLE wishlists DROP COLUMN IF EXISTS price_when_added;
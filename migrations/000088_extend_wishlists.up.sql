@@ -0,0 +1,33 @@
+-- ================================================
+-- Migration: Wishlist domain (guest wishlist + price snapshot)
+-- Purpose: Khách chưa đăng nhập lưu wishlist theo session_id (như giỏ hàng ẩn danh),
+--          merge vào wishlist của user khi login; lưu giá lúc thêm để hiển thị giảm giá
+-- Version: 000088
+-- ================================================
+
+-- WHY bảng riêng cho guest (không cho wishlists.user_id NULL)?
+-- 1. wishlists PK (user_id, book_id) đang được feed "For you" đọc làm tín hiệu → giữ nguyên
+-- 2. Guest wishlist chỉ sống tới khi login (merge rồi xoá), không cần FK users
+-- 3. session_id là cookie session_id của CartMiddleware → 1 session = 1 giỏ + 1 wishlist
+--
+-- WHY price_when_added?
+-- 1. Màn wishlist hiện "giảm X đ từ khi bạn thêm"
+-- 2. NULL cho dòng cũ (trước migration) → không hiển thị mức giảm
+
+ALTER TABLE wishlists
+    ADD COLUMN IF NOT EXISTS price_when_added NUMERIC(10,2);
+
+CREATE TABLE IF NOT EXISTS guest_wishlist_items (
+    session_id TEXT NOT NULL,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    price_when_added NUMERIC(10,2),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (session_id, book_id)
+);
+
+-- USE CASE: Dọn wishlist của session bỏ dở (không bao giờ login)
+CREATE INDEX IF NOT EXISTS idx_guest_wishlist_items_created
+ON guest_wishlist_items(created_at);
+
+COMMENT ON TABLE guest_wishlist_items IS
+'Wishlist of anonymous sessions (session_id cookie). Merged into wishlists on login, then deleted.';
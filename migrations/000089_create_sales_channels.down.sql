@@ -0,0 +1,10 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer', 'invoice', 'pay_link', 'replacement'));
+
+DROP INDEX IF EXISTS idx_orders_channel_created;
+DROP INDEX IF EXISTS idx_orders_channel_external_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS external_order_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS channel;
+
+DROP TABLE IF EXISTS sales_channels;
@@ -0,0 +1,73 @@
+-- ================================================
+-- Migration: Sales channels (web / app / marketplace)
+-- Purpose: Mỗi đơn gắn 1 kênh bán; cấu hình phương thức thanh toán + hãng vận chuyển theo kênh;
+--          đơn Shopee / Lazada đẩy về qua API đi chung luồng reserve tồn + fulfillment
+-- Version: 000089
+-- ================================================
+
+-- WHY cột orders.channel (không dùng metadata.utm_source)?
+-- 1. Kênh quyết định nghiệp vụ (thanh toán nào được chọn, hãng nào giao), không chỉ để thống kê
+-- 2. FK tới sales_channels: không có đơn mang kênh lạ
+-- 3. Đơn cũ = 'web' (NOT NULL DEFAULT)
+--
+-- WHY external_order_id + UNIQUE(channel, external_order_id)?
+-- 1. Sàn gửi lại đơn (retry / đồng bộ lại) → không tạo đơn trùng, không reserve tồn 2 lần
+-- 2. CSKH tra đơn theo mã đơn bên sàn
+--
+-- WHY account_user_id?
+-- orders.user_id / addresses.user_id NOT NULL: đơn sàn không có tài khoản khách bên mình
+-- → gắn vào tài khoản hệ thống của kênh, địa chỉ người nhận lưu vào sổ địa chỉ của tài khoản đó
+
+CREATE TABLE IF NOT EXISTS sales_channels (
+    code TEXT PRIMARY KEY CHECK (code ~ '^[a-z][a-z0-9_]{1,31}$'),
+    name TEXT NOT NULL,
+    channel_type TEXT NOT NULL CHECK (channel_type IN ('direct', 'marketplace')),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+    -- Rỗng = cho phép tất cả phương thức / hãng đang cấu hình
+    payment_methods TEXT[] NOT NULL DEFAULT '{}',
+    shipping_carriers TEXT[] NOT NULL DEFAULT '{}',
+
+    -- Kênh marketplace: tài khoản đứng tên đơn đẩy về từ sàn
+    account_user_id UUID REFERENCES users(id) ON DELETE RESTRICT,
+
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_sales_channels_updated_at
+BEFORE UPDATE ON sales_channels
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO sales_channels (code, name, channel_type, payment_methods) VALUES
+    ('web', 'Website', 'direct', '{}'),
+    ('app', 'Mobile app', 'direct', '{}'),
+    ('shopee', 'Shopee', 'marketplace', '{marketplace}'),
+    ('lazada', 'Lazada', 'marketplace', '{marketplace}')
+ON CONFLICT (code) DO NOTHING;
+
+-- ================================================
+-- ORDERS: channel + external_order_id
+-- ================================================
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS channel TEXT NOT NULL DEFAULT 'web' REFERENCES sales_channels(code) ON UPDATE CASCADE,
+    ADD COLUMN IF NOT EXISTS external_order_id TEXT;
+
+-- USE CASE: Chặn đơn sàn trùng + tra đơn theo mã bên sàn
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_channel_external_id
+ON orders(channel, external_order_id)
+WHERE external_order_id IS NOT NULL;
+
+-- USE CASE: Admin lọc / thống kê đơn theo kênh
+CREATE INDEX IF NOT EXISTS idx_orders_channel_created
+ON orders(channel, created_at DESC);
+
+-- Đơn sàn: sàn thu tiền của khách, đối soát với sàn theo kỳ
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('cod', 'vnpay', 'momo', 'bank_transfer', 'invoice', 'pay_link', 'replacement', 'marketplace'));
+
+COMMENT ON COLUMN orders.channel IS 'Sales channel the order was placed through (sales_channels.code)';
+COMMENT ON COLUMN orders.external_order_id IS 'Order ID on the marketplace (Shopee/Lazada), NULL for direct orders';
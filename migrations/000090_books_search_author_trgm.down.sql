@@ -0,0 +1,31 @@
+DROP INDEX IF EXISTS idx_authors_name_trgm;
+DROP INDEX IF EXISTS idx_books_title_trgm;
+
+DROP TRIGGER IF EXISTS authors_search_vector_trigger ON authors;
+DROP FUNCTION IF EXISTS authors_refresh_books_search_vector();
+
+DROP TRIGGER IF EXISTS books_search_vector_trigger ON books;
+
+CREATE OR REPLACE FUNCTION books_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.description, '')), 'B');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER books_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description ON books
+    FOR EACH ROW
+    EXECUTE FUNCTION books_search_vector_update();
+
+UPDATE books SET search_vector =
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(description, '')), 'B');
+
+DROP FUNCTION IF EXISTS books_build_search_vector(TEXT, TEXT, TEXT);
+DROP FUNCTION IF EXISTS immutable_unaccent(TEXT);
+
+COMMENT ON COLUMN books.search_vector IS 'Full-text search vector (title:A + description:B) using simple config for Vietnamese support';
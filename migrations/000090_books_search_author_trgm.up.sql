@@ -0,0 +1,87 @@
+-- ================================================
+-- Migration: Storefront full-text search cho sách
+-- Purpose: search_vector gồm cả tên tác giả; bỏ dấu tiếng Việt khi index + tìm;
+--          trigram index cho tìm gần đúng (gõ sai chính tả / gõ dở)
+-- Version: 000090
+-- ================================================
+
+-- WHY thêm tên tác giả vào search_vector?
+-- 1. Khách gõ "nguyen nhat anh" vào ô tìm kiếm, trước đây chỉ match title/description
+-- 2. Weight A (title) > B (author) > C (description): ưu tiên sách có từ khoá trong tên
+--
+-- WHY unaccent?
+-- Khách thường gõ không dấu ("mat biec" → "Mắt biếc"). Index + query cùng bỏ dấu → match cả 2 kiểu gõ
+--
+-- WHY pg_trgm?
+-- tsvector chỉ match đúng token (hoặc prefix với :*). Gõ sai 1-2 ký tự ("harry poter") → dùng
+-- word_similarity trên title / tên tác giả làm fallback, có GIN index nên không seq scan
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() là STABLE (phụ thuộc dictionary) → không dùng được trong index expression
+-- Wrapper IMMUTABLE chỉ định rõ dictionary
+CREATE OR REPLACE FUNCTION immutable_unaccent(input TEXT)
+RETURNS TEXT AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, input)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Build vector dùng chung cho trigger books + trigger authors
+CREATE OR REPLACE FUNCTION books_build_search_vector(p_title TEXT, p_author TEXT, p_description TEXT)
+RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('simple', immutable_unaccent(COALESCE(p_title, ''))), 'A') ||
+        setweight(to_tsvector('simple', immutable_unaccent(COALESCE(p_author, ''))), 'B') ||
+        setweight(to_tsvector('simple', immutable_unaccent(COALESCE(p_description, ''))), 'C')
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+DROP TRIGGER IF EXISTS books_search_vector_trigger ON books;
+
+CREATE OR REPLACE FUNCTION books_search_vector_update()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_author TEXT;
+BEGIN
+    SELECT name INTO v_author FROM authors WHERE id = NEW.author_id;
+    NEW.search_vector := books_build_search_vector(NEW.title, v_author, NEW.description);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER books_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description, author_id ON books
+    FOR EACH ROW
+    EXECUTE FUNCTION books_search_vector_update();
+
+-- Đổi tên tác giả → cập nhật lại vector các sách của tác giả đó
+CREATE OR REPLACE FUNCTION authors_refresh_books_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE books
+    SET search_vector = books_build_search_vector(title, NEW.name, description)
+    WHERE author_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER authors_search_vector_trigger
+    AFTER UPDATE OF name ON authors
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION authors_refresh_books_search_vector();
+
+-- Backfill
+UPDATE books b
+SET search_vector = books_build_search_vector(b.title, a.name, b.description)
+FROM authors a
+WHERE a.id = b.author_id;
+
+-- USE CASE: Fallback tìm gần đúng theo tên sách / tên tác giả (word_similarity, operator <%)
+CREATE INDEX IF NOT EXISTS idx_books_title_trgm
+ON books USING GIN (immutable_unaccent(lower(title)) gin_trgm_ops)
+WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_authors_name_trgm
+ON authors USING GIN (immutable_unaccent(lower(name)) gin_trgm_ops);
+
+COMMENT ON COLUMN books.search_vector IS 'Full-text search vector (title:A + author:B + description:C), simple config, unaccented';
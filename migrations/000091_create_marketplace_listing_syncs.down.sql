@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS marketplace_listing_syncs;
+
+ALTER TABLE sales_channels
+    DROP COLUMN IF EXISTS stock_buffer,
+    DROP COLUMN IF EXISTS stock_sync_enabled;
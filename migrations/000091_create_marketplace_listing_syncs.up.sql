@@ -0,0 +1,43 @@
+-- ================================================
+-- Migration: Đồng bộ tồn kho + giá lên sàn (Shopee / Lazada)
+-- Purpose: Cấu hình đồng bộ theo kênh (bật/tắt, số lượng giữ lại) + trạng thái lần đẩy gần nhất theo từng sách
+-- Version: 000091
+-- ================================================
+
+-- WHY stock_buffer?
+-- Tồn trên sàn cập nhật trễ (job + rate limit của sàn): đẩy đủ số available thì 2 kênh có thể
+-- cùng bán cuốn cuối → oversell. Đẩy (available - buffer), tối thiểu 0
+--
+-- WHY marketplace_listing_syncs?
+-- 1. Job theo sự kiện chỉ đẩy khi tồn / giá khác lần đẩy trước → tiết kiệm quota API của sàn
+-- 2. SKU chưa đăng bán trên sàn (not_listed) bỏ qua ở job theo sự kiện, chỉ thử lại lúc reconcile đêm
+-- 3. Admin / CSKH xem được lỗi đẩy gần nhất của từng sách
+
+ALTER TABLE sales_channels
+    ADD COLUMN IF NOT EXISTS stock_sync_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS stock_buffer INT NOT NULL DEFAULT 0 CHECK (stock_buffer >= 0);
+
+CREATE TABLE IF NOT EXISTS marketplace_listing_syncs (
+    channel TEXT NOT NULL REFERENCES sales_channels(code) ON UPDATE CASCADE ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    sku TEXT NOT NULL,
+
+    -- Giá trị đã đẩy thành công gần nhất
+    synced_quantity INT,
+    synced_price NUMERIC(12, 2),
+
+    status TEXT NOT NULL CHECK (status IN ('synced', 'failed', 'not_listed')),
+    last_error TEXT,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    synced_at TIMESTAMPTZ,
+
+    PRIMARY KEY (channel, book_id)
+);
+
+-- USE CASE: Admin lọc các sách đẩy lỗi theo kênh
+CREATE INDEX IF NOT EXISTS idx_marketplace_listing_syncs_status
+ON marketplace_listing_syncs(channel, status)
+WHERE status <> 'synced';
+
+COMMENT ON COLUMN sales_channels.stock_buffer IS 'Quantity held back when pushing stock to the marketplace (oversell guard)';
+COMMENT ON TABLE marketplace_listing_syncs IS 'Last stock/price push per book per marketplace channel';
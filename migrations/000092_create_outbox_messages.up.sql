@@ -0,0 +1,55 @@
+-- ================================================
+-- Migration: Transactional outbox cho asynq task
+-- Purpose: Task hậu commit (email xác nhận, auto-release, inventory sync) ghi cùng transaction nghiệp vụ,
+--          relay trong worker đẩy sang asynq với retry + dedup
+-- Version: 000092
+-- ================================================
+
+-- WHY outbox (không enqueue thẳng sau commit)?
+-- 1. Redis down / timeout ngay sau commit → task mất im lặng (chỉ log), đơn không có email,
+--    tồn trên Redis không được sync, đơn chưa thanh toán không bao giờ auto-release
+-- 2. Ghi cùng transaction: đơn commit ⇔ task được ghi, rollback thì không phát task thừa
+--
+-- WHY dedup 2 lớp?
+-- 1. dedup_key UNIQUE: caller chủ động chặn ghi trùng (VD: 1 email xác nhận / đơn)
+-- 2. Relay enqueue với asynq TaskID = outbox id: relay crash sau khi enqueue nhưng trước khi
+--    đánh dấu dispatched → lần drain sau gặp TaskIDConflict, coi như đã phát
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id UUID PRIMARY KEY,
+    task_type TEXT NOT NULL,
+    payload BYTEA NOT NULL,
+
+    -- Option của asynq task (NULL = mặc định của asynq)
+    queue TEXT NOT NULL,
+    max_retry INT,
+    timeout_seconds INT,
+    process_at TIMESTAMPTZ,
+
+    dedup_key TEXT,
+
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dispatched', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    -- Lần relay kế tiếp được thử (backoff khi Redis lỗi)
+    available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    dispatched_at TIMESTAMPTZ
+);
+
+-- USE CASE: Relay lấy message chờ phát (FOR UPDATE SKIP LOCKED, nhiều worker chạy song song)
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending
+ON outbox_messages(available_at)
+WHERE status = 'pending';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_messages_dedup_key
+ON outbox_messages(dedup_key)
+WHERE dedup_key IS NOT NULL;
+
+-- USE CASE: Dọn message đã phát
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_dispatched
+ON outbox_messages(dispatched_at)
+WHERE status = 'dispatched';
+
+COMMENT ON TABLE outbox_messages IS 'Asynq tasks written in the business transaction, drained into Redis by the worker relay';
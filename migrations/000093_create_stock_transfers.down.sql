@@ -0,0 +1,14 @@
+-- Giữ lịch sử tồn, chỉ đổi về action cũ
+UPDATE inventory_audit_log SET action = 'ADJUSTMENT' WHERE action = 'TRANSFER_OUT';
+UPDATE inventory_audit_log SET action = 'RESTOCK' WHERE action = 'TRANSFER_IN';
+
+ALTER TABLE inventory_audit_log DROP CONSTRAINT IF EXISTS inventory_audit_log_action_check;
+ALTER TABLE inventory_audit_log ADD CONSTRAINT inventory_audit_log_action_check
+    CHECK (action IN (
+        'RESTOCK', 'RESERVE', 'RELEASE', 'ADJUSTMENT', 'SALE',
+        'ADJUSTMENT_REQUESTED', 'ADJUSTMENT_APPROVED', 'ADJUSTMENT_REJECTED',
+        'RTV'
+    ));
+
+DROP TABLE IF EXISTS stock_transfer_items;
+DROP TABLE IF EXISTS stock_transfers;
@@ -0,0 +1,77 @@
+-- ================================================
+-- Migration: Stock Transfers (chuyển kho)
+-- Purpose: Điều chuyển tồn giữa 2 kho có phiếu liên kết thay vì admin sửa tay 2 dòng tồn
+-- Version: 000093
+-- ================================================
+
+-- WHY?
+-- 1. Trước đây chuyển kho = ADJUSTMENT giảm ở kho A + ADJUSTMENT tăng ở kho B, không liên kết
+--    → báo cáo hao hụt bị đội số, không biết hàng đang trên đường
+-- 2. Vòng đời: pending → in_transit → received, huỷ được khi pending / in_transit
+-- 3. Xuất kho (in_transit): trừ kho nguồn, audit TRANSFER_OUT
+--    Nhận hàng (received): cộng kho đích, audit TRANSFER_IN
+--    Hàng đang trên đường không bán được ở kho nào
+-- 4. Huỷ khi in_transit: hoàn lại kho nguồn (audit TRANSFER_IN, reason ghi số phiếu)
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transfer_number TEXT NOT NULL UNIQUE,                     -- TRF-YYYYMMDD-XXXXXXXX
+    source_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+    destination_warehouse_id UUID NOT NULL REFERENCES warehouses(id),
+
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'in_transit', 'received', 'cancelled')),
+
+    total_quantity INT NOT NULL CHECK (total_quantity > 0),
+    note TEXT,
+    cancel_reason TEXT,
+
+    created_by UUID NOT NULL REFERENCES users(id),
+    shipped_by UUID REFERENCES users(id),
+    shipped_at TIMESTAMPTZ,
+    received_by UUID REFERENCES users(id),
+    received_at TIMESTAMPTZ,
+    cancelled_by UUID REFERENCES users(id),
+    cancelled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_stock_transfer_warehouses_differ
+        CHECK (source_warehouse_id <> destination_warehouse_id)
+);
+
+-- USE CASE: Lịch sử chuyển kho theo kho (xuất đi / nhận về)
+CREATE INDEX idx_stock_transfers_source ON stock_transfers(source_warehouse_id, created_at DESC);
+CREATE INDEX idx_stock_transfers_destination ON stock_transfers(destination_warehouse_id, created_at DESC);
+
+-- USE CASE: Phiếu đang chờ xuất / đang trên đường
+CREATE INDEX idx_stock_transfers_open ON stock_transfers(status, created_at DESC)
+    WHERE status IN ('pending', 'in_transit');
+
+CREATE TRIGGER trg_stock_transfers_updated_at
+    BEFORE UPDATE ON stock_transfers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transfer_id UUID NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+
+    CONSTRAINT uq_stock_transfer_items_book UNIQUE (transfer_id, book_id)
+);
+
+-- ================================================
+-- Audit log: allow transfer actions
+-- ================================================
+ALTER TABLE inventory_audit_log DROP CONSTRAINT IF EXISTS inventory_audit_log_action_check;
+ALTER TABLE inventory_audit_log ADD CONSTRAINT inventory_audit_log_action_check
+    CHECK (action IN (
+        'RESTOCK', 'RESERVE', 'RELEASE', 'ADJUSTMENT', 'SALE',
+        'ADJUSTMENT_REQUESTED', 'ADJUSTMENT_APPROVED', 'ADJUSTMENT_REJECTED',
+        'RTV', 'TRANSFER_OUT', 'TRANSFER_IN'
+    ));
+
+COMMENT ON TABLE stock_transfers IS
+'Stock transfers between warehouses: source decremented when shipped, destination incremented when received.';
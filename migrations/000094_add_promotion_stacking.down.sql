@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_promotions_automatic;
+
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS chk_promotions_automatic_scope;
+
+ALTER TABLE promotions
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS stackable,
+    DROP COLUMN IF EXISTS is_automatic;
@@ -0,0 +1,38 @@
+-- ================================================
+-- Migration: Promotion Stacking & Automatic Promotions
+-- Purpose: Promotion tự áp khi giỏ đạt điều kiện (không cần nhập mã) + luật cộng dồn
+-- Version: 000094
+-- ================================================
+
+-- WHY AUTOMATIC + STACKING RULES?
+-- 1. Chương trình "giảm 10% đơn từ 500k" không cần khách nhập mã → is_automatic = true,
+--    order service tự áp lúc tạo đơn (code vẫn giữ làm định danh nội bộ / báo cáo)
+-- 2. stackable quyết định cộng dồn:
+--    - Mã nhập tay stackable → cộng thêm mọi promotion tự động stackable
+--    - Mã nhập tay không stackable → chỉ áp mã, bỏ toàn bộ promotion tự động
+--    - Không có mã → chọn phương án giảm nhiều nhất giữa (tất cả tự động stackable) và
+--      (từng promotion tự động không stackable áp riêng)
+-- 3. priority: thứ tự hiển thị / tie-break khi 2 phương án giảm bằng nhau (cao xét trước)
+-- 4. Tự động + mã cá nhân / first_order_only không hỗ trợ: order service không có lịch sử
+--    gán / đơn hoàn thành của user lúc tính tự động → chặn bằng CHECK thay vì áp sai
+
+ALTER TABLE promotions
+    ADD COLUMN IF NOT EXISTS is_automatic BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+
+ALTER TABLE promotions
+    ADD CONSTRAINT chk_promotions_automatic_scope
+    CHECK (NOT (is_automatic AND (is_targeted OR first_order_only)));
+
+-- USE CASE: Lấy promotion tự động đang chạy khi áp mã / tạo đơn
+CREATE INDEX IF NOT EXISTS idx_promotions_automatic
+ON promotions(priority DESC, starts_at, expires_at)
+    WHERE is_active = true AND is_automatic = true;
+
+COMMENT ON COLUMN promotions.is_automatic IS
+'TRUE = applied without a code whenever the cart meets the promotion conditions.';
+COMMENT ON COLUMN promotions.stackable IS
+'TRUE = may combine with other stackable promotions (code + automatic, or several automatic).';
+COMMENT ON COLUMN promotions.priority IS
+'Higher first; breaks ties between equally good automatic promotion sets.';
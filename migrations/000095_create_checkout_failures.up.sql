@@ -0,0 +1,42 @@
+-- ================================================
+-- Migration: Checkout Failures (chẩn đoán checkout thất bại)
+-- Purpose: Lưu nguyên CheckoutResponse (phases, errors, warnings) của lần checkout thất bại
+-- Version: 000095
+-- ================================================
+
+-- WHY LƯU RESPONSE THẤT BẠI?
+-- 1. Khách gọi CSKH "không đặt được hàng" sau khi đã đóng app → response đã mất,
+--    log rải rác theo request khó tìm theo user
+-- 2. Lưu nguyên JSON response: thêm phase / mã lỗi mới không cần đổi schema
+-- 3. failed_phase + error_codes tách cột để lọc / thống kê nhanh không cần đọc JSONB
+-- 4. Retention: job dọn hằng ngày xoá bản ghi quá CheckoutFailureRetention (30 ngày)
+
+CREATE TABLE IF NOT EXISTS checkout_failures (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    cart_id UUID NOT NULL, -- không FK: cart bị xoá / hết hạn vẫn giữ chẩn đoán
+
+    failed_phase VARCHAR(50), -- CART_VALIDATION, ADDRESS_VALIDATION, PRICING_CALCULATION, WAREHOUSE_SELECTION, ORDER_CREATION
+    error_codes TEXT[] NOT NULL DEFAULT '{}',
+    payment_method VARCHAR(50),
+    placed_by UUID REFERENCES users(id) ON DELETE SET NULL, -- nhân viên đặt thay khách
+
+    response JSONB, -- CheckoutResponse đầy đủ (NULL khi lỗi hạ tầng trước khi có response)
+    error_message TEXT, -- lỗi trả về thay vì response (breaker mở, timeout...)
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: CSKH tra theo khách / giỏ, mới nhất trước
+CREATE INDEX IF NOT EXISTS idx_checkout_failures_user
+ON checkout_failures(user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_checkout_failures_cart
+ON checkout_failures(cart_id, created_at DESC);
+
+-- USE CASE: Job retention xoá theo created_at
+CREATE INDEX IF NOT EXISTS idx_checkout_failures_created_at
+ON checkout_failures(created_at);
+
+COMMENT ON TABLE checkout_failures IS
+'Full checkout responses of failed checkouts, kept for support diagnostics (pruned daily by retention job).';
@@ -0,0 +1,37 @@
+-- ================================================
+-- Migration: Customer Activity Events (lịch sử giỏ hàng / đơn hàng cho khách)
+-- Purpose: Feed "hoạt động" trong trang tài khoản: thêm / bỏ sách, áp / gỡ / hết hạn mã,
+--          đặt đơn, đổi trạng thái, huỷ đơn
+-- Version: 000096
+-- ================================================
+
+-- WHY BẢNG RIÊNG, KHÔNG DÙNG analytics_events?
+-- 1. analytics_events là dữ liệu funnel nội bộ (có cả session ẩn danh), không lộ cho khách
+-- 2. Feed cần message thân thiện + chỉ event khách nhìn thấy được
+-- 3. Retention theo từng user: mỗi (user, domain) chỉ giữ ActivityRetentionPerUser event mới nhất,
+--    cắt ngay lúc ghi → bảng không phình theo khách hoạt động nhiều
+-- 4. Ghi qua task analytics:record_activity (asynq) → không làm chậm request giỏ / đơn
+
+CREATE TABLE IF NOT EXISTS customer_activity_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    domain VARCHAR(20) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+
+    cart_id UUID,  -- không FK: giỏ bị xoá / hết hạn vẫn giữ lịch sử
+    order_id UUID, -- không FK: feed chỉ đọc, đơn có order_number trong properties
+    book_id UUID,
+
+    properties JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_customer_activity_domain CHECK (domain IN ('cart', 'order'))
+);
+
+-- USE CASE: Feed của khách theo domain, mới nhất trước + cắt retention theo (user, domain)
+CREATE INDEX IF NOT EXISTS idx_customer_activity_user_domain
+ON customer_activity_events(user_id, domain, occurred_at DESC);
+
+COMMENT ON TABLE customer_activity_events IS
+'Customer-facing cart/order activity feed, trimmed to the latest N events per user and domain on insert.';
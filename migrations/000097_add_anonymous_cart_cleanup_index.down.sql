@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_carts_anonymous_updated;
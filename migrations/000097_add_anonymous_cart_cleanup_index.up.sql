@@ -0,0 +1,14 @@
+-- ================================================
+-- Migration: Index cho job dọn giỏ ẩn danh rỗng
+-- Purpose: cart:cleanup_anonymous_carts quét giỏ user_id IS NULL theo updated_at mỗi giờ
+-- Version: 000097
+-- ================================================
+
+-- WHY PARTIAL INDEX?
+-- 1. Mỗi request ẩn danh chưa có giỏ tạo 1 row carts → bảng phình theo lượng bot / khách vãng lai
+-- 2. Job chỉ quan tâm giỏ ẩn danh cũ, giỏ của user đăng nhập không bao giờ bị dọn
+-- 3. Partial index nhỏ hơn index full bảng và không tốn chi phí ghi cho giỏ của user
+
+CREATE INDEX IF NOT EXISTS idx_carts_anonymous_updated
+    ON carts(updated_at)
+    WHERE user_id IS NULL;
@@ -0,0 +1,50 @@
+-- ================================================
+-- Migration: Cart Price Overrides (giữ giá wishlist khi chuyển vào giỏ)
+-- Purpose: Sách thêm vào wishlist trong vòng N giờ, chuyển sang giỏ khi giá đã tăng
+--          → giỏ + đơn dùng giá lúc thêm wishlist trong thời gian giữ giá
+-- Version: 000098
+-- ================================================
+
+-- WHY BẢNG RIÊNG, KHÔNG GHI THẲNG cart_items.price?
+-- 1. cart_items.price bị tính lại mỗi lần đổi số lượng (bậc giá) → mất giá cam kết
+-- 2. Order service tính lại giá từ books, cần nguồn giá đặc biệt đã được server duyệt
+--    (giống PriceOverrides của flash sale) thay vì tin giá trong giỏ
+-- 3. Giới hạn lạm dụng đếm theo user trong khoảng thời gian → giữ lại cả dòng đã dùng / hết hạn
+--
+-- WHY cart_id KHÔNG CÓ FK?
+-- Checkout xoá carts trong transaction tạo đơn; dòng override phải còn để đếm quota + đối soát đơn
+
+CREATE TABLE IF NOT EXISTS cart_price_overrides (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cart_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL DEFAULT 'wishlist',
+
+    -- Giá cam kết + giá bìa lúc cấp (đối soát mức giảm)
+    unit_price NUMERIC(10,2) NOT NULL CHECK (unit_price >= 0),
+    list_price NUMERIC(10,2) NOT NULL,
+    -- Số lượng tối đa được hưởng giá (vượt → cả dòng tính giá thường)
+    max_quantity INT NOT NULL CHECK (max_quantity > 0),
+
+    -- active → consumed (đã đặt đơn) | expired (hết giờ giữ giá, giỏ đã tính lại)
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMPTZ NOT NULL,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_cart_price_overrides_status CHECK (status IN ('active', 'consumed', 'expired'))
+);
+
+-- 1 giá cam kết đang hiệu lực / sách / giỏ
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_price_overrides_active
+    ON cart_price_overrides(cart_id, book_id)
+    WHERE status = 'active';
+
+-- USE CASE: Đếm số lần được giữ giá của user (quota chống lạm dụng)
+CREATE INDEX IF NOT EXISTS idx_cart_price_overrides_user_created
+    ON cart_price_overrides(user_id, created_at DESC);
+
+COMMENT ON TABLE cart_price_overrides IS
+'Server-granted unit prices for cart lines (wishlist price guarantee). Applied by cart pricing and order creation while active.';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS order_import_rows;
+DROP TABLE IF EXISTS order_imports;
+-- Giữ kênh 'offline' nếu đã có đơn gắn kênh (FK orders.channel)
+DELETE FROM sales_channels s
+WHERE s.code = 'offline' AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.channel = s.code);
@@ -0,0 +1,68 @@
+-- ================================================
+-- Migration: Order imports (nhập đơn offline / điện thoại / lịch sử từ CSV)
+-- Purpose: Admin upload CSV → worker tạo đơn qua luồng createOrderFromItems (reserve tồn + fulfillment),
+--          kết quả + lỗi lưu theo từng dòng CSV để admin sửa file và nhập lại phần lỗi
+-- Version: 000099
+-- ================================================
+
+-- WHY lưu dòng vào DB thay vì gửi cả file trong payload task?
+-- 1. File vài nghìn dòng → payload Redis lớn, retry gửi lại toàn bộ
+-- 2. Job retry chỉ xử lý dòng còn pending, dòng đã tạo đơn không chạy lại
+-- 3. Lỗi theo dòng (line_no) trả cho admin qua API, không phải đọc log worker
+--
+-- WHY kênh 'offline' + external_order_id = order_ref?
+-- UNIQUE(channel, external_order_id) có sẵn trên orders → nhập lại cùng file / retry sau crash
+-- không tạo đơn trùng (order_ref trùng → dòng trỏ về đơn đã có)
+
+INSERT INTO sales_channels (code, name, channel_type, payment_methods) VALUES
+    ('offline', 'Offline / phone sales', 'direct', '{}')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS order_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    file_name TEXT NOT NULL,
+    -- pending → processing → completed | failed (lỗi hệ thống, không phải lỗi dòng)
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_rows INT NOT NULL DEFAULT 0,
+    total_orders INT NOT NULL DEFAULT 0,
+    created_orders INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_order_imports_status CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
+
+CREATE TABLE IF NOT EXISTS order_import_rows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    import_id UUID NOT NULL REFERENCES order_imports(id) ON DELETE CASCADE,
+    line_no INT NOT NULL,
+    order_ref TEXT NOT NULL,
+    customer_email TEXT NOT NULL,
+    isbn TEXT NOT NULL,
+    quantity INT NOT NULL,
+    unit_price NUMERIC(10,2),
+    payment_method VARCHAR(20) NOT NULL,
+    paid BOOLEAN NOT NULL DEFAULT FALSE,
+    address_id UUID,
+    note TEXT,
+
+    -- pending → created | failed
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    error TEXT,
+    processed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_order_import_rows_status CHECK (status IN ('pending', 'created', 'failed'))
+);
+
+-- USE CASE: Kết quả theo dòng của 1 lần import (lọc dòng lỗi)
+CREATE INDEX IF NOT EXISTS idx_order_import_rows_import
+    ON order_import_rows(import_id, status, line_no);
+
+-- USE CASE: Danh sách lần import mới nhất
+CREATE INDEX IF NOT EXISTS idx_order_imports_created
+    ON order_imports(created_at DESC);
@@ -0,0 +1,42 @@
+-- ================================================
+-- Migration: User devices (device token push FCM / APNs)
+-- Purpose: App đăng ký token sau khi login → push trạng thái đơn (confirmed, shipping, delivered, cancelled)
+-- Version: 000100
+-- ================================================
+
+-- WHY token UNIQUE toàn bảng (không theo user)?
+-- 1 máy đăng nhập tài khoản khác → cùng token chuyển sang user mới (upsert), user cũ không còn nhận push
+--
+-- WHY failure_count + disabled_at thay vì xoá ngay khi gửi lỗi?
+-- 1. Token provider báo không hợp lệ (UNREGISTERED / 410) → xoá ngay
+-- 2. Lỗi tạm (timeout, 5xx) không chứng minh token chết → chỉ tắt sau N lần lỗi liên tiếp,
+--    app đăng ký lại (mở app) thì bật lại + reset bộ đếm
+
+CREATE TABLE IF NOT EXISTS user_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL,
+    -- fcm: Android / web / iOS qua Firebase; apns: iOS gửi thẳng Apple
+    provider VARCHAR(10) NOT NULL CHECK (provider IN ('fcm', 'apns')),
+    platform VARCHAR(10) NOT NULL CHECK (platform IN ('ios', 'android', 'web')),
+    app_version VARCHAR(32),
+
+    failure_count INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    last_sent_at TIMESTAMPTZ,
+    disabled_at TIMESTAMPTZ,
+
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_user_devices_token UNIQUE (token)
+);
+
+-- Gửi push: device đang bật của user
+CREATE INDEX IF NOT EXISTS idx_user_devices_user_active
+    ON user_devices(user_id) WHERE disabled_at IS NULL;
+
+COMMENT ON TABLE user_devices IS 'Push device tokens (FCM / APNs) per user';
+COMMENT ON COLUMN user_devices.failure_count IS 'Consecutive transient send failures, reset on success or re-register';
+COMMENT ON COLUMN user_devices.disabled_at IS 'Set after too many consecutive failures (NULL = active)';
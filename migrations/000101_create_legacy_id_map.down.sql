@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS legacy_id_map;
+-- Giữ kênh 'legacy' nếu đã có đơn gắn kênh (FK orders.channel)
+DELETE FROM sales_channels s
+WHERE s.code = 'legacy' AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.channel = s.code);
@@ -0,0 +1,39 @@
+-- ================================================
+-- Migration: Legacy import map (backfill khách hàng / địa chỉ / đơn từ nền tảng cũ)
+-- Purpose: cmd/migrate legacy-import đọc file export của nền tảng cũ, ghi users / addresses /
+--          orders + lịch sử trạng thái; chạy lại cùng file không tạo bản ghi trùng
+-- Version: 000101
+-- ================================================
+
+-- WHY bảng map riêng thay vì cột legacy_id trên từng bảng?
+-- 1. users / addresses không có cột nguồn ngoài, thêm cột chỉ để backfill 1 lần là cột chết
+-- 2. Khách đã đăng ký lại bên mình (trùng email) → map legacy id vào user có sẵn, không tạo user mới
+-- 3. 1 chỗ tra ngược "đơn / khách này từ id nào bên hệ thống cũ" cho CSKH
+--
+-- WHY kênh 'legacy' cho đơn?
+-- UNIQUE(channel, external_order_id) có sẵn trên orders: external_order_id = id đơn bên cũ
+-- → chốt chặn cuối nếu map bị xoá tay; báo cáo doanh thu tách được đơn lịch sử
+
+INSERT INTO sales_channels (code, name, channel_type, payment_methods, is_active) VALUES
+    ('legacy', 'Legacy platform (imported history)', 'direct', '{}', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS legacy_id_map (
+    -- user | address | order
+    entity_type VARCHAR(20) NOT NULL,
+    legacy_id TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    -- TRUE: trỏ vào bản ghi đã có sẵn (user trùng email), không phải bản ghi do import tạo
+    linked_existing BOOLEAN NOT NULL DEFAULT FALSE,
+    imported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (entity_type, legacy_id),
+    CONSTRAINT chk_legacy_id_map_entity CHECK (entity_type IN ('user', 'address', 'order'))
+);
+
+-- USE CASE: Tra ngược id cũ từ bản ghi mới
+CREATE INDEX IF NOT EXISTS idx_legacy_id_map_entity
+    ON legacy_id_map(entity_type, entity_id);
+
+COMMENT ON TABLE legacy_id_map IS
+'Legacy platform id → new id for backfilled users, addresses and orders; makes legacy-import re-runs idempotent.';
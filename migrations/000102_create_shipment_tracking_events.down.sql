@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS shipment_tracking_events;
+
+ALTER TABLE shipments
+    DROP COLUMN IF EXISTS status_updated_at,
+    DROP COLUMN IF EXISTS tracking_status,
+    DROP COLUMN IF EXISTS carrier_status;
@@ -0,0 +1,38 @@
+-- ================================================
+-- Migration: Shipment tracking events (webhook trạng thái vận đơn GHN / GHTK)
+-- Purpose: Hãng báo đã lấy hàng / đã giao / đã hoàn → cập nhật shipments + tự chuyển trạng thái đơn
+-- Version: 000102
+-- ================================================
+
+-- WHY lưu từng event thay vì chỉ cột trạng thái trên shipments?
+-- 1. Hãng retry webhook (timeout, 5xx) → UNIQUE (carrier, tracking_number, carrier_status, occurred_at) chặn xử lý lặp
+-- 2. Event có thể tới lệch thứ tự → shipments chỉ nhận trạng thái có occurred_at mới hơn
+-- 3. CSKH tra được hành trình kiện (giao lỗi, hoãn giao, lý do) khi khách khiếu nại
+--
+-- WHY status (chuẩn hoá) tách carrier_status (mã gốc)?
+-- Mỗi hãng 1 bộ mã (GHN: "delivering", GHTK: 4) → order service chỉ cần in_transit / delivered / returned
+
+ALTER TABLE shipments
+    ADD COLUMN IF NOT EXISTS carrier_status TEXT,
+    ADD COLUMN IF NOT EXISTS tracking_status TEXT,
+    ADD COLUMN IF NOT EXISTS status_updated_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS shipment_tracking_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    carrier TEXT NOT NULL,
+    tracking_number TEXT NOT NULL,
+
+    carrier_status TEXT NOT NULL,
+    status TEXT NOT NULL,
+    reason TEXT,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_shipment_tracking_events UNIQUE (carrier, tracking_number, carrier_status, occurred_at),
+    CONSTRAINT chk_shipment_tracking_events_status
+        CHECK (status IN ('in_transit', 'delivered', 'returned', 'cancelled', 'other'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_tracking_events_shipment
+ON shipment_tracking_events(shipment_id, occurred_at DESC);
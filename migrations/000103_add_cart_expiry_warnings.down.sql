@@ -0,0 +1,6 @@
+DELETE FROM notification_templates WHERE code = 'cart_expiring';
+
+DROP INDEX IF EXISTS idx_carts_expiry_warning;
+
+ALTER TABLE carts
+    DROP COLUMN IF EXISTS expiry_warned_at;
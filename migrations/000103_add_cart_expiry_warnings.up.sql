@@ -0,0 +1,55 @@
+-- ================================================
+-- Migration: Cart expiry warnings
+-- Purpose: Nhắc khách có giỏ còn hàng trước khi giỏ hết hạn (TTL giỏ khách / user cấu hình riêng)
+-- Version: 000103
+-- ================================================
+
+-- WHY cột expiry_warned_at trên carts?
+-- 1. Job nhắc chạy mỗi giờ → cần mốc "đã nhắc" để mỗi chu kỳ hạn chỉ nhắc 1 lần
+-- 2. Khách quay lại → gia hạn expires_at + xoá mốc → lần sắp hết hạn sau được nhắc lại
+-- 3. Job nhận giỏ bằng UPDATE ... WHERE expires_at = <hạn lúc đọc> → giỏ vừa gia hạn không bị nhắc nhầm
+
+ALTER TABLE carts
+    ADD COLUMN IF NOT EXISTS expiry_warned_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN carts.expiry_warned_at IS
+'When the pre-expiry notification was sent for the current expires_at; reset on renewal.';
+
+-- USE CASE: Job tìm giỏ user còn hàng sắp hết hạn, chưa nhắc
+CREATE INDEX IF NOT EXISTS idx_carts_expiry_warning
+    ON carts(expires_at)
+    WHERE user_id IS NOT NULL AND expiry_warned_at IS NULL;
+
+-- ================================================
+-- SEED: cart_expiring template (vi + en)
+-- ================================================
+
+INSERT INTO notification_templates (code, name, category, language, email_subject, email_body_html, in_app_title, in_app_body, required_variables, default_channels, default_priority)
+VALUES
+(
+    'cart_expiring',
+    'Cart Expiring',
+    'transactional',
+    'vi',
+    'Giỏ hàng của bạn sắp hết hạn',
+    '<p>Giỏ hàng của bạn còn <strong>{{items_count}}</strong> sản phẩm (tạm tính <strong>{{subtotal}} VNĐ</strong>) và sẽ hết hạn lúc <strong>{{expires_at}}</strong>.</p><p>Quay lại giỏ hàng trước thời điểm này để giữ các sản phẩm đã chọn.</p>',
+    'Giỏ hàng sắp hết hạn',
+    'Giỏ hàng còn {{items_count}} sản phẩm sẽ hết hạn lúc {{expires_at}}',
+    ARRAY['items_count', 'subtotal', 'expires_at'],
+    ARRAY['in_app', 'email'],
+    2
+),
+(
+    'cart_expiring',
+    'Cart Expiring',
+    'transactional',
+    'en',
+    'Your cart is about to expire',
+    '<p>Your cart still has <strong>{{items_count}}</strong> item(s) (subtotal <strong>{{subtotal}} VND</strong>) and will expire at <strong>{{expires_at}}</strong>.</p><p>Come back to your cart before then to keep your selection.</p>',
+    'Cart expiring soon',
+    'Your cart with {{items_count}} item(s) expires at {{expires_at}}',
+    ARRAY['items_count', 'subtotal', 'expires_at'],
+    ARRAY['in_app', 'email'],
+    2
+)
+ON CONFLICT (code, language) DO NOTHING;
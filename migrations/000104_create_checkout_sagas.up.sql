@@ -0,0 +1,44 @@
+-- ================================================
+-- Migration: Checkout Sagas (bù trừ checkout lỗi giữa chừng)
+-- Purpose: Ghi lại mỗi lần checkout đi qua ORDER_CREATION để bù trừ (huỷ đơn, trả tồn đã giữ,
+--          hoàn lượt promotion, khôi phục giỏ) khi bước tạo đơn lỗi
+-- Version: 000104
+-- ================================================
+
+-- WHY BẢNG SAGA RIÊNG?
+-- 1. CreateOrder trả lỗi chưa chắc transaction đã rollback (timeout lúc COMMIT, client huỷ request)
+--    → order_id đặt trước lưu ở đây để bước bù trừ tìm đúng đơn có thể đã được tạo
+-- 2. Process chết giữa chừng (deploy, OOM) → saga kẹt ở 'started'; job đối soát định kỳ bù trừ
+--    saga quá hạn thay vì để tồn bị giữ vĩnh viễn
+-- 3. Bù trừ lỗi → 'compensation_failed' + last_error, job thử lại tới khi hết attempts
+-- 4. Saga xong (completed / compensated) chỉ giữ để tra cứu, job dọn theo retention
+
+CREATE TABLE IF NOT EXISTS checkout_sagas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    cart_id UUID NOT NULL, -- không FK: giỏ bị xoá khi tạo đơn thành công
+    order_id UUID NOT NULL UNIQUE, -- id đặt trước, không FK: đơn có thể chưa (hoặc không bao giờ) được tạo
+
+    status VARCHAR(30) NOT NULL DEFAULT 'started'
+        CHECK (status IN ('started', 'completed', 'compensated', 'compensation_failed')),
+    step VARCHAR(50) NOT NULL DEFAULT 'ORDER_CREATION', -- bước đang chạy / bước bù trừ lỗi
+    attempts INT NOT NULL DEFAULT 0, -- số lần bù trừ đã chạy
+    last_error TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+-- USE CASE: Job đối soát tìm saga kẹt / bù trừ lỗi, cũ nhất trước
+CREATE INDEX IF NOT EXISTS idx_checkout_sagas_pending
+ON checkout_sagas(created_at)
+WHERE status IN ('started', 'compensation_failed');
+
+-- USE CASE: Job retention xoá saga đã xong
+CREATE INDEX IF NOT EXISTS idx_checkout_sagas_completed_at
+ON checkout_sagas(completed_at)
+WHERE completed_at IS NOT NULL;
+
+COMMENT ON TABLE checkout_sagas IS
+'Checkout orchestration log: pre-assigned order id and compensation state, reconciled by cart:reconcile_checkouts.';
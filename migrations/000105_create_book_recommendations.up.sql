@@ -0,0 +1,31 @@
+-- ================================================
+-- Migration: Book recommendations ("customers also bought")
+-- Purpose: Lưu cặp sách hay được mua cùng nhau (tính sẵn từ order_items mỗi đêm)
+-- Version: 000105
+-- ================================================
+
+-- WHY TÍNH SẴN?
+-- 1. Đếm cặp sách cùng đơn trên toàn bộ order_items là self-join nặng → không chạy theo request
+-- 2. Job đêm (recommendation:compute_co_purchases) xoá + ghi lại toàn bộ trong 1 transaction:
+--    request đọc luôn thấy bộ cũ hoặc bộ mới, không thấy bảng rỗng giữa chừng
+-- 3. score = cosine (support / sqrt(số đơn có A * số đơn có B)): sách bán cực chạy không lấn át
+--    mọi cặp chỉ vì xuất hiện ở nhiều đơn
+-- 4. Mỗi sách giữ top N cặp (theo score) → bảng không phình theo bình phương catalog
+
+CREATE TABLE IF NOT EXISTS book_recommendations (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    related_book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    support INT NOT NULL,            -- số đơn có cả 2 sách
+    score NUMERIC(8, 6) NOT NULL,    -- cosine similarity (0..1]
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (book_id, related_book_id),
+    CHECK (book_id <> related_book_id)
+);
+
+-- USE CASE: GET /books/:id/related + gợi ý cá nhân từ sách user đã mua
+CREATE INDEX IF NOT EXISTS idx_book_recommendations_book_score
+ON book_recommendations(book_id, score DESC);
+
+COMMENT ON TABLE book_recommendations IS
+'Precomputed co-purchase pairs (customers also bought), rebuilt nightly from order_items.';
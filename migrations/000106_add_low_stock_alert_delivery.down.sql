@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS low_stock_alert_recipients;
+
+DROP INDEX IF EXISTS idx_low_stock_alerts_pending_digest;
+DROP INDEX IF EXISTS idx_low_stock_alerts_pending_notify;
+
+ALTER TABLE low_stock_alerts
+    DROP COLUMN IF EXISTS digested_at,
+    DROP COLUMN IF EXISTS notified_at;
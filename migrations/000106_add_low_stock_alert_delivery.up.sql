@@ -0,0 +1,56 @@
+-- ================================================
+-- Migration: Low stock alert delivery (email / Slack webhook)
+-- Purpose: Người nhận cảnh báo tồn thấp theo kho + đánh dấu cảnh báo đã gửi
+-- Version: 000106
+-- ================================================
+
+-- WHY?
+-- 1. Trigger check_low_stock chỉ ghi low_stock_alerts → nhân viên kho phải tự mở dashboard mới biết
+-- 2. Worker (inventory:dispatch_low_stock_alerts) nhận cảnh báo mới bằng UPDATE ... SET notified_at
+--    trước khi gửi: job chạy chồng / retry không gửi trùng
+-- 3. Digest (inventory:send_low_stock_digest) dùng cột riêng digested_at → cùng 1 cảnh báo vừa gửi
+--    ngay cho người nhận immediate, vừa vào bản tổng hợp theo giờ cho người nhận digest
+-- 4. warehouse_id NULL = nhận cảnh báo của mọi kho (quản lý vùng / kênh Slack chung)
+
+ALTER TABLE low_stock_alerts
+    ADD COLUMN IF NOT EXISTS notified_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS digested_at TIMESTAMPTZ;
+
+-- Cảnh báo có sẵn trước migration coi như đã gửi (không bắn hàng loạt lúc deploy)
+UPDATE low_stock_alerts
+SET notified_at = COALESCE(created_at, NOW()),
+    digested_at = COALESCE(created_at, NOW())
+WHERE notified_at IS NULL OR digested_at IS NULL;
+
+-- USE CASE: worker quét cảnh báo chưa gửi (index nhỏ, chỉ chứa hàng đang chờ)
+CREATE INDEX IF NOT EXISTS idx_low_stock_alerts_pending_notify
+ON low_stock_alerts(created_at) WHERE notified_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_low_stock_alerts_pending_digest
+ON low_stock_alerts(created_at) WHERE digested_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS low_stock_alert_recipients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    warehouse_id UUID REFERENCES warehouses(id) ON DELETE CASCADE, -- NULL: mọi kho
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'slack')),
+    target TEXT NOT NULL,                                          -- email hoặc Slack incoming webhook URL
+    mode VARCHAR(20) NOT NULL DEFAULT 'immediate' CHECK (mode IN ('immediate', 'digest')),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 1 target chỉ đăng ký 1 lần cho mỗi kho + kênh + chế độ (NULL kho gộp về 1 giá trị)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_low_stock_alert_recipients_unique
+ON low_stock_alert_recipients(
+    COALESCE(warehouse_id, '00000000-0000-0000-0000-000000000000'::uuid), channel, target, mode
+);
+
+CREATE INDEX IF NOT EXISTS idx_low_stock_alert_recipients_active
+ON low_stock_alert_recipients(mode, warehouse_id) WHERE is_active = true;
+
+COMMENT ON TABLE low_stock_alert_recipients IS
+    'Per-warehouse recipients of low stock alerts (email / Slack webhook, immediate or hourly digest)';
+COMMENT ON COLUMN low_stock_alerts.notified_at IS 'Set when the alert dispatcher claimed the alert for immediate delivery';
+COMMENT ON COLUMN low_stock_alerts.digested_at IS 'Set when the alert was included in an hourly digest';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_inventory_keyset;
+DROP INDEX IF EXISTS idx_books_keyset;
+DROP INDEX IF EXISTS idx_orders_user_keyset;
+
+ALTER TABLE warehouse_inventory
+    DROP COLUMN IF EXISTS created_at;
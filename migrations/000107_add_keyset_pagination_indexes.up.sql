@@ -0,0 +1,36 @@
+-- ================================================
+-- Migration: Keyset (cursor) pagination indexes
+-- Purpose: Index theo đúng thứ tự (created_at DESC, id DESC) cho cursor mode của
+--          GET /orders, GET /inventories, GET /books
+-- Version: 000107
+-- ================================================
+
+-- WHY?
+-- 1. OFFSET trang sâu quét + bỏ N hàng, hàng mới chèn làm lệch trang → cursor mode lọc
+--    (created_at, id) < (cursor) và ORDER BY created_at DESC, id DESC
+-- 2. So sánh tuple chỉ dùng được index khi index có đủ cột theo cùng thứ tự (kể cả id làm tie-breaker)
+-- 3. warehouse_inventory chưa có created_at (updated_at đổi mỗi lần ghi → không làm cursor được):
+--    thêm cột, hàng cũ lấy updated_at làm mốc
+
+ALTER TABLE warehouse_inventory
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+
+UPDATE warehouse_inventory
+SET created_at = COALESCE(updated_at, NOW())
+WHERE created_at IS NULL;
+
+ALTER TABLE warehouse_inventory
+    ALTER COLUMN created_at SET DEFAULT NOW(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+-- USE CASE: khách xem lịch sử đơn (lọc user_id)
+CREATE INDEX IF NOT EXISTS idx_orders_user_keyset
+ON orders(user_id, created_at DESC, id DESC);
+
+-- USE CASE: danh sách sách mới nhất (storefront)
+CREATE INDEX IF NOT EXISTS idx_books_keyset
+ON books(created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+-- USE CASE: admin / kho duyệt toàn bộ tồn kho
+CREATE INDEX IF NOT EXISTS idx_inventory_keyset
+ON warehouse_inventory(created_at DESC, warehouse_id DESC, book_id DESC);
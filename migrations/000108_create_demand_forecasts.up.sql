@@ -0,0 +1,36 @@
+-- ================================================
+-- Migration: Demand forecasts (dự báo nhu cầu theo tuần)
+-- Purpose: Lưu dự báo số lượng bán N tuần tới cho từng (sách, kho), kèm khoảng tin cậy
+-- Version: 000108
+-- ================================================
+
+-- WHY TÍNH SẴN?
+-- 1. Fit model cho mọi (sách, kho) từ order_items 2 năm gần nhất là việc nặng → job hàng tuần
+--    (inventory:compute_demand_forecasts) chạy sau khi tuần cũ khép lại
+-- 2. Model đơn giản, giải thích được cho nhân viên kho: mức nền (exponential smoothing)
+--    × chỉ số mùa vụ theo tháng (chỉ khi đủ 52 tuần lịch sử, vd mùa tựu trường / Tết)
+-- 3. lower / upper = khoảng tin cậy 95% từ sai số dự báo 1 bước của chính chuỗi đó
+--    → gợi ý nhập hàng dùng upper (đủ hàng cả khi bán chạy hơn dự kiến)
+-- 4. Job xoá + ghi lại toàn bộ trong 1 transaction: request luôn thấy bộ cũ hoặc bộ mới
+
+CREATE TABLE IF NOT EXISTS demand_forecasts (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    warehouse_id UUID NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,                 -- thứ 2 của tuần dự báo
+    predicted NUMERIC(10, 2) NOT NULL,
+    lower_bound NUMERIC(10, 2) NOT NULL,
+    upper_bound NUMERIC(10, 2) NOT NULL,
+    model VARCHAR(20) NOT NULL,               -- 'seasonal' | 'level' (chưa đủ lịch sử cho mùa vụ)
+    history_weeks INT NOT NULL,               -- số tuần lịch sử dùng để fit
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (book_id, warehouse_id, week_start),
+    CHECK (lower_bound >= 0 AND lower_bound <= predicted AND predicted <= upper_bound)
+);
+
+-- USE CASE: gợi ý nhập hàng theo kho (cộng dồn các tuần trong horizon)
+CREATE INDEX IF NOT EXISTS idx_demand_forecasts_warehouse_week
+ON demand_forecasts(warehouse_id, week_start);
+
+COMMENT ON TABLE demand_forecasts IS
+'Weekly demand forecast per book per warehouse (seasonal model with 95% interval), rebuilt weekly from order_items.';
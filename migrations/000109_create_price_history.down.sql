@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS trg_books_price_history ON books;
+DROP FUNCTION IF EXISTS log_book_price_change();
+
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS price_schedules;
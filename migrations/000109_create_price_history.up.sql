@@ -0,0 +1,107 @@
+-- ================================================
+-- Migration: Price history + scheduled price changes
+-- Purpose: Lưu mọi lần đổi giá sách, admin hẹn giờ đổi price / compare_at_price, worker áp đúng giờ
+-- Version: 000109
+-- ================================================
+
+-- WHY TRIGGER?
+-- 1. Giá đổi qua nhiều đường: tạo / sửa sách, bulk import, job áp lịch đổi giá, SQL tay
+--    → trigger trên books là nguồn đầy đủ duy nhất (giống log_inventory_change())
+-- 2. Người gọi đánh dấu nguồn bằng set_config(..., true) trong transaction của mình:
+--    app.price_change_source ('scheduled') + app.price_schedule_id
+--    Không đánh dấu → 'initial' khi tạo sách, 'manual' khi sửa
+-- 3. Sách mới: ghi 1 dòng giá khởi tạo (old_* NULL) → luôn biết giá tại đầu mọi khoảng thời gian
+
+-- WHY "LOWEST PRICE IN 30 DAYS"?
+-- Storefront hiện giá thấp nhất 30 ngày bên cạnh giá giảm (khách biết giảm thật hay nâng rồi hạ):
+-- MIN(giá có hiệu lực lúc đầu khoảng, mọi giá đặt trong khoảng) → cần dòng cuối trước khoảng + index (book_id, changed_at)
+
+CREATE TABLE IF NOT EXISTS price_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    price NUMERIC(10, 2) CHECK (price IS NULL OR price >= 0),          -- NULL = giữ giá hiện tại
+    compare_at_price NUMERIC(10, 2) CHECK (compare_at_price IS NULL OR compare_at_price >= 0),
+    set_compare_at_price BOOLEAN NOT NULL DEFAULT false,               -- true: ghi compare_at_price (NULL = bỏ giá gạch)
+    effective_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'applied', 'cancelled', 'failed')),
+    note TEXT,
+    failure_reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    applied_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (price IS NOT NULL OR set_compare_at_price)
+);
+
+-- USE CASE: worker lấy lịch đến hạn mỗi phút
+CREATE INDEX IF NOT EXISTS idx_price_schedules_due
+ON price_schedules(effective_at) WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_price_schedules_book
+ON price_schedules(book_id, effective_at DESC);
+
+CREATE TRIGGER trg_price_schedules_updated_at
+    BEFORE UPDATE ON price_schedules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    old_price NUMERIC(10, 2),
+    new_price NUMERIC(10, 2) NOT NULL,
+    old_compare_at_price NUMERIC(10, 2),
+    new_compare_at_price NUMERIC(10, 2),
+    source VARCHAR(20) NOT NULL DEFAULT 'manual',   -- initial | manual | scheduled
+    schedule_id UUID REFERENCES price_schedules(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: lịch sử giá 1 sách + giá thấp nhất N ngày
+CREATE INDEX IF NOT EXISTS idx_price_history_book_changed
+ON price_history(book_id, changed_at DESC);
+
+CREATE OR REPLACE FUNCTION log_book_price_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.price IS NOT DISTINCT FROM OLD.price
+        AND NEW.compare_at_price IS NOT DISTINCT FROM OLD.compare_at_price THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO price_history (
+        book_id, old_price, new_price, old_compare_at_price, new_compare_at_price, source, schedule_id
+    ) VALUES (
+        NEW.id,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.price END,
+        NEW.price,
+        CASE WHEN TG_OP = 'UPDATE' THEN OLD.compare_at_price END,
+        NEW.compare_at_price,
+        COALESCE(NULLIF(current_setting('app.price_change_source', true), ''),
+            CASE WHEN TG_OP = 'INSERT' THEN 'initial' ELSE 'manual' END),
+        NULLIF(current_setting('app.price_schedule_id', true), '')::uuid
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_books_price_history
+    AFTER INSERT OR UPDATE OF price, compare_at_price ON books
+    FOR EACH ROW
+    EXECUTE FUNCTION log_book_price_change();
+
+-- Giá hiện tại của sách có sẵn = dòng khởi tạo (không biết lịch sử trước migration)
+INSERT INTO price_history (book_id, new_price, new_compare_at_price, source, changed_at)
+SELECT id, price, compare_at_price, 'initial', created_at
+FROM books;
+
+COMMENT ON TABLE price_history IS
+'Every book price / compare_at_price change (written by trigger trg_books_price_history).';
+COMMENT ON TABLE price_schedules IS
+'Admin-scheduled future price changes, applied by worker job book:apply_price_schedules.';
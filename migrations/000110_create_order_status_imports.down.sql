@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_status_import_rows;
+DROP TABLE IF EXISTS order_status_imports;
@@ -0,0 +1,56 @@
+-- ================================================
+-- Migration: Order status imports (cập nhật trạng thái đơn hàng loạt từ CSV)
+-- Purpose: Staff kho upload CSV (order_number, status, tracking_number) → worker chuyển trạng thái
+--          từng đơn qua workflow đang cấu hình, kết quả + lỗi lưu theo từng dòng
+-- Version: 000110
+-- ================================================
+
+-- WHY bảng riêng thay vì dùng lại order_imports?
+-- order_import_rows mang cột của đơn mới (email, isbn, số lượng, thanh toán), dòng đổi trạng thái chỉ có
+-- mã đơn + trạng thái đích + mã vận đơn; kết quả là trạng thái trước/sau chứ không phải đơn được tạo
+--
+-- WHY lưu from_status?
+-- Admin xem lại file đã chuyển đơn từ đâu sang đâu (đơn bị đổi tiếp sau đó vẫn còn dấu vết trong file)
+
+CREATE TABLE IF NOT EXISTS order_status_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    file_name TEXT NOT NULL,
+    -- pending → processing → completed | failed (lỗi hệ thống, không phải lỗi dòng)
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_rows INT NOT NULL DEFAULT 0,
+    updated_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_order_status_imports_status CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
+
+CREATE TABLE IF NOT EXISTS order_status_import_rows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    import_id UUID NOT NULL REFERENCES order_status_imports(id) ON DELETE CASCADE,
+    line_no INT NOT NULL,
+    order_number TEXT NOT NULL,
+    target_status VARCHAR(20) NOT NULL,
+    tracking_number TEXT,
+
+    -- pending → updated | failed
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    from_status VARCHAR(20),
+    error TEXT,
+    processed_at TIMESTAMPTZ,
+
+    CONSTRAINT chk_order_status_import_rows_status CHECK (status IN ('pending', 'updated', 'failed'))
+);
+
+-- USE CASE: Kết quả theo dòng của 1 lần import (lọc dòng lỗi)
+CREATE INDEX IF NOT EXISTS idx_order_status_import_rows_import
+    ON order_status_import_rows(import_id, status, line_no);
+
+-- USE CASE: Danh sách lần import mới nhất
+CREATE INDEX IF NOT EXISTS idx_order_status_imports_created
+    ON order_status_imports(created_at DESC);
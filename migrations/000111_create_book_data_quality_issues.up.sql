@@ -0,0 +1,30 @@
+-- ================================================
+-- Migration: Book data quality issues (sách thiếu dữ liệu / không có tồn)
+-- Purpose: Job book:scan_data_quality quét hằng đêm, ghi mỗi vấn đề của 1 sách thành 1 dòng;
+--          catalog manager xem báo cáo kèm link sửa thay vì tự viết query
+-- Version: 000111
+-- ================================================
+
+-- WHY lưu kết quả thay vì tính khi gọi API?
+-- 1. Quét toàn bộ books + book_images + warehouse_inventory mỗi lần mở báo cáo tốn kém, lọc / phân trang khó
+-- 2. detected_at giữ từ lần đầu phát hiện → biết sách "thiếu ảnh từ 3 tuần" để ưu tiên sửa
+--
+-- WHY xoá dòng khi đã sửa (không giữ resolved_at)?
+-- Báo cáo chỉ cần vấn đề còn tồn tại; lịch sử sửa đã có ở updated_at của sách / audit tồn kho
+
+CREATE TABLE IF NOT EXISTS book_data_quality_issues (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    -- missing_cover | missing_description | missing_category | no_inventory
+    issue VARCHAR(30) NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (book_id, issue),
+    CONSTRAINT chk_book_data_quality_issue CHECK (
+        issue IN ('missing_cover', 'missing_description', 'missing_category', 'no_inventory')
+    )
+);
+
+-- USE CASE: Lọc báo cáo theo loại vấn đề, vấn đề lâu nhất trước
+CREATE INDEX IF NOT EXISTS idx_book_data_quality_issue
+    ON book_data_quality_issues(issue, detected_at);
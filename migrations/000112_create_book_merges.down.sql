@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS book_duplicate_dismissals;
+DROP TABLE IF EXISTS book_redirects;
+DROP TABLE IF EXISTS book_merges;
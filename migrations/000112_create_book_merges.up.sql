@@ -0,0 +1,62 @@
+-- ================================================
+-- Migration: Book merges (gộp sách trùng) + redirect slug / id cũ
+-- Purpose: Admin gộp sách trùng (cùng ISBN / tên + tác giả gần giống) vào 1 sách giữ lại:
+--          chuyển giỏ hàng, dòng đơn, review, tồn kho trong 1 transaction, sách bị gộp xoá mềm
+-- Version: 000112
+-- ================================================
+
+-- WHY xoá mềm sách bị gộp thay vì xoá hẳn?
+-- Các bảng không chuyển (price_history, analytics, đề xuất...) vẫn trỏ về sách cũ; FK RESTRICT ở
+-- inventory_audit_log / stock transfer không cho xoá, và cần giữ lại để tra cứu lịch sử
+--
+-- WHY book_redirects lưu cả old_book_id lẫn old_slug?
+-- 1. Link / SEO cũ theo slug → storefront resolve sang sách giữ lại
+-- 2. App / email cũ gọi GET /books/:id bằng id sách đã gộp → 301 sang sách giữ lại
+-- 3. Gộp tiếp sách giữ lại vào sách khác → redirect cũ được trỏ lại, không tạo chuỗi redirect
+
+CREATE TABLE IF NOT EXISTS book_merges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_book_id UUID NOT NULL REFERENCES books(id),
+    target_book_id UUID NOT NULL REFERENCES books(id),
+
+    -- Số dòng đã chuyển (combined = cộng dồn vào dòng sẵn có của sách giữ lại)
+    cart_items_moved INT NOT NULL DEFAULT 0,
+    cart_items_combined INT NOT NULL DEFAULT 0,
+    order_items_moved INT NOT NULL DEFAULT 0,
+    reviews_moved INT NOT NULL DEFAULT 0,
+    -- Khách đã review cả 2 sách (UNIQUE user_id, book_id) → review trên sách cũ giữ nguyên
+    reviews_skipped INT NOT NULL DEFAULT 0,
+    inventory_rows_moved INT NOT NULL DEFAULT 0,
+    inventory_rows_combined INT NOT NULL DEFAULT 0,
+
+    merged_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_book_merges_source UNIQUE (source_book_id),
+    CONSTRAINT chk_book_merges_distinct CHECK (source_book_id <> target_book_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_merges_target ON book_merges(target_book_id);
+
+CREATE TABLE IF NOT EXISTS book_redirects (
+    old_book_id UUID PRIMARY KEY REFERENCES books(id),
+    old_slug TEXT NOT NULL UNIQUE,
+    book_id UUID NOT NULL REFERENCES books(id),
+    merge_id UUID REFERENCES book_merges(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: Trỏ lại redirect khi sách giữ lại bị gộp tiếp
+CREATE INDEX IF NOT EXISTS idx_book_redirects_book ON book_redirects(book_id);
+
+-- Cặp admin đã xác nhận không trùng (không hiện lại trong danh sách nghi trùng)
+-- book_a_id < book_b_id để mỗi cặp chỉ có 1 dòng
+CREATE TABLE IF NOT EXISTS book_duplicate_dismissals (
+    book_a_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    book_b_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    dismissed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (book_a_id, book_b_id),
+    CONSTRAINT chk_book_duplicate_dismissals_order CHECK (book_a_id < book_b_id)
+);
@@ -0,0 +1,34 @@
+DROP TRIGGER IF EXISTS author_aliases_search_vector_trigger ON author_aliases;
+DROP FUNCTION IF EXISTS author_aliases_refresh_books_search_vector();
+
+-- Khôi phục trigger function của 000090 (chỉ tên tác giả)
+CREATE OR REPLACE FUNCTION books_search_vector_update()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_author TEXT;
+BEGIN
+    SELECT name INTO v_author FROM authors WHERE id = NEW.author_id;
+    NEW.search_vector := books_build_search_vector(NEW.title, v_author, NEW.description);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION authors_refresh_books_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE books
+    SET search_vector = books_build_search_vector(title, NEW.name, description)
+    WHERE author_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS author_search_text(UUID);
+DROP TABLE IF EXISTS author_aliases;
+DROP INDEX IF EXISTS idx_authors_normalized_name;
+DROP FUNCTION IF EXISTS normalize_author_name(TEXT);
+
+UPDATE books b
+SET search_vector = books_build_search_vector(b.title, a.name, b.description)
+FROM authors a
+WHERE a.id = b.author_id;
@@ -0,0 +1,103 @@
+-- ================================================
+-- Migration: Author aliases (bút danh, tên phiên âm / không dấu) + gộp tác giả trùng
+-- Purpose: 1 tác giả chuẩn (authors) + nhiều alias; search / listing theo alias ra tác giả chuẩn,
+--          admin gộp tác giả trùng → chuyển sách, tên + slug cũ thành alias
+-- Version: 000113
+-- ================================================
+
+-- WHY normalized_name?
+-- "Nguyễn Nhật Ánh", "nguyen nhat anh", "Nguyễn  Nhật Ánh" là cùng 1 người → so khớp trên
+-- tên đã bỏ dấu + lowercase + gộp khoảng trắng; UNIQUE để 1 alias chỉ thuộc 1 tác giả
+--
+-- WHY slug trên alias?
+-- Tác giả bị gộp bị xoá khỏi authors; link cũ /authors/slug/:slug vẫn phải ra tác giả chuẩn
+--
+-- WHY đưa alias vào books.search_vector?
+-- Khách tìm "Haruki" hay "村上春樹" đều ra sách của tác giả chuẩn, không cần sửa query search
+
+CREATE OR REPLACE FUNCTION normalize_author_name(input TEXT)
+RETURNS TEXT AS $$
+    SELECT regexp_replace(btrim(immutable_unaccent(lower(input))), '\s+', ' ', 'g')
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE TABLE IF NOT EXISTS author_aliases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    author_id UUID NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    normalized_name TEXT NOT NULL GENERATED ALWAYS AS (normalize_author_name(name)) STORED,
+    -- Chỉ có với alias sinh ra khi gộp (slug của tác giả bị gộp)
+    slug TEXT UNIQUE,
+    -- manual: admin thêm | merge: tên tác giả bị gộp
+    source TEXT NOT NULL DEFAULT 'manual',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_author_aliases_normalized UNIQUE (normalized_name),
+    CONSTRAINT chk_author_aliases_source CHECK (source IN ('manual', 'merge'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_author_aliases_author ON author_aliases(author_id);
+
+-- USE CASE: Tìm gần đúng theo alias (giống idx_authors_name_trgm)
+CREATE INDEX IF NOT EXISTS idx_author_aliases_name_trgm
+ON author_aliases USING GIN (normalized_name gin_trgm_ops);
+
+-- USE CASE: Bulk import / tạo tác giả khớp tên chuẩn đã bỏ dấu
+CREATE INDEX IF NOT EXISTS idx_authors_normalized_name ON authors(normalize_author_name(name));
+
+-- ================================================
+-- search_vector: tên tác giả + toàn bộ alias (weight B)
+-- ================================================
+
+CREATE OR REPLACE FUNCTION author_search_text(p_author_id UUID)
+RETURNS TEXT AS $$
+    SELECT a.name || COALESCE(' ' || (
+        SELECT string_agg(aa.name, ' ') FROM author_aliases aa WHERE aa.author_id = a.id
+    ), '')
+    FROM authors a
+    WHERE a.id = p_author_id
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION books_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := books_build_search_vector(NEW.title, author_search_text(NEW.author_id), NEW.description);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION authors_refresh_books_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE books
+    SET search_vector = books_build_search_vector(title, author_search_text(NEW.id), description)
+    WHERE author_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Thêm / xoá / chuyển alias → cập nhật lại vector sách của tác giả liên quan
+CREATE OR REPLACE FUNCTION author_aliases_refresh_books_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE books
+        SET search_vector = books_build_search_vector(title, author_search_text(OLD.author_id), description)
+        WHERE author_id = OLD.author_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND (TG_OP = 'INSERT' OR NEW.author_id <> OLD.author_id) THEN
+        UPDATE books
+        SET search_vector = books_build_search_vector(title, author_search_text(NEW.author_id), description)
+        WHERE author_id = NEW.author_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER author_aliases_search_vector_trigger
+    AFTER INSERT OR UPDATE OF author_id, name OR DELETE ON author_aliases
+    FOR EACH ROW
+    EXECUTE FUNCTION author_aliases_refresh_books_search_vector();
+
+COMMENT ON TABLE author_aliases IS 'Author pen names / transliteration variants resolving to the canonical author';
+COMMENT ON COLUMN author_aliases.slug IS 'Slug of an author merged into author_id (old links resolve here)';
@@ -0,0 +1,12 @@
+-- Sách đang lưu trữ quay về deleted_at như trước 000114
+UPDATE books
+SET deleted_at = archived_at
+WHERE archived_at IS NOT NULL AND deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_books_archived_at;
+
+ALTER TABLE books
+    DROP CONSTRAINT IF EXISTS chk_books_replacement_not_self,
+    DROP COLUMN IF EXISTS replacement_book_id,
+    DROP COLUMN IF EXISTS archived_by,
+    DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,34 @@
+-- ================================================
+-- Migration: Vòng đời ngừng bán sách (archived_at) + khôi phục
+-- Purpose: DELETE /books/:id chuyển sang lưu trữ (archived) thay vì deleted_at: sách vẫn đọc được
+--          để giỏ hàng / đơn báo ITEM_DISCONTINUED kèm sách thay thế, admin khôi phục được
+-- Version: 000114
+-- ================================================
+
+-- WHY không dùng deleted_at?
+-- 1. deleted_at loại sách khỏi mọi query (GetBookByID, GetBooksCheckout) → cart chỉ nhận "book not found",
+--    còn query cart join books không lọc deleted_at → sách đã xoá vẫn được coi là đang bán
+-- 2. deleted_at giữ cho sách bị gộp (book_merges) – đã có redirect, không khôi phục
+--
+-- WHY replacement_book_id?
+-- Admin chọn sách thay thế khi ngừng bán (tái bản, bản bìa cứng...) → cart / reorder gợi ý thay thế
+
+ALTER TABLE books
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS archived_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS replacement_book_id UUID REFERENCES books(id) ON DELETE SET NULL,
+    ADD CONSTRAINT chk_books_replacement_not_self CHECK (replacement_book_id IS NULL OR replacement_book_id <> id);
+
+-- Sách đã "xoá" bằng DeleteBook trước đây (không phải do gộp) → lưu trữ, khôi phục được
+UPDATE books
+SET archived_at = deleted_at,
+    is_active = false,
+    deleted_at = NULL
+WHERE deleted_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM book_merges bm WHERE bm.source_book_id = books.id);
+
+-- USE CASE: Admin xem danh sách sách đã ngừng bán
+CREATE INDEX IF NOT EXISTS idx_books_archived_at ON books(archived_at DESC) WHERE archived_at IS NOT NULL;
+
+COMMENT ON COLUMN books.archived_at IS 'Discontinued (soft-deleted, restorable); is_active is false while archived';
+COMMENT ON COLUMN books.replacement_book_id IS 'Suggested replacement shown when a cart/order hits ITEM_DISCONTINUED';
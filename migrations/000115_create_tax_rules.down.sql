@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS order_tax_lines;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_amount;
+
+DROP TRIGGER IF EXISTS trg_tax_rules_updated_at ON tax_rules;
+DROP TABLE IF EXISTS tax_rules;
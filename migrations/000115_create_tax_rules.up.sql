@@ -0,0 +1,57 @@
+-- ================================================
+-- Migration: Thuế cấu hình theo tỉnh / danh mục
+-- Purpose: Thay thuế cứng 0% ở checkout (cart) và CalculateOrderAmounts (order) bằng rule cấu hình được,
+--          lưu dòng thuế của từng đơn cho pricing breakdown + hoá đơn
+-- Version: 000115
+-- ================================================
+
+-- WHY rule theo tỉnh VÀ/HOẶC danh mục?
+-- Thuế suất khác nhau theo loại hàng (sách 0% / văn phòng phẩm 10%...) và có thể theo địa bàn giao hàng.
+-- Rule cụ thể nhất thắng: tỉnh + danh mục > chỉ danh mục > chỉ tỉnh > mặc định (cả 2 NULL).
+-- Không có rule nào khớp → 0% (giữ hành vi cũ khi chưa cấu hình)
+--
+-- WHY price_includes_tax?
+-- Giá niêm yết VN thường đã gồm VAT (inclusive): thuế tách ra để hiển thị, không cộng vào tổng.
+-- Exclusive (B2B, giá chưa thuế): thuế cộng thêm vào tổng đơn
+
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,                                              -- hiển thị trên dòng thuế: "VAT 10%"
+    province TEXT,                                                   -- NULL = mọi tỉnh (so khớp không phân biệt hoa thường)
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,    -- NULL = mọi danh mục
+    rate NUMERIC(6, 4) NOT NULL CHECK (rate >= 0 AND rate < 1),      -- 0.1000 = 10%
+    price_includes_tax BOOLEAN NOT NULL DEFAULT true,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 1 rule đang hoạt động cho mỗi phạm vi (tỉnh, danh mục) → kết quả tính thuế không mơ hồ
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_rules_scope
+ON tax_rules (lower(COALESCE(province, '')), COALESCE(category_id, '00000000-0000-0000-0000-000000000000'::uuid))
+WHERE is_active;
+
+CREATE TRIGGER trg_tax_rules_updated_at
+    BEFORE UPDATE ON tax_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- orders chưa từng có cột thuế (Order.TaxAmount không được lưu)
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- Dòng thuế của đơn (snapshot lúc đặt: đổi rule sau không làm đổi hoá đơn cũ)
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    tax_rule_id UUID REFERENCES tax_rules(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    rate NUMERIC(6, 4) NOT NULL,
+    price_includes_tax BOOLEAN NOT NULL,
+    taxable_amount NUMERIC(12, 2) NOT NULL,
+    tax_amount NUMERIC(12, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- USE CASE: invoice / chi tiết đơn load dòng thuế theo đơn
+CREATE INDEX IF NOT EXISTS idx_order_tax_lines_order
+ON order_tax_lines(order_id);
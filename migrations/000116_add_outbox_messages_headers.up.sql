@@ -0,0 +1,14 @@
+-- ================================================
+-- Migration: Header của asynq task trong outbox
+-- Purpose: Giữ trace context (traceparent, baggage) của request ghi outbox để relay enqueue task kèm header,
+--          worker tiếp tục trace mà không đổi payload
+-- Version: 000116
+-- ================================================
+
+-- WHY cột riêng (không nhét vào payload)?
+-- 1. Payload là JSON nghiệp vụ handler đọc trực tiếp, thêm field lạ làm đổi hợp đồng của mọi task
+-- 2. asynq truyền header tách khỏi payload (NewTaskWithHeaders) → relay chỉ cần chuyển tiếp
+
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS headers JSONB;
+
+COMMENT ON COLUMN outbox_messages.headers IS 'Asynq task headers (W3C trace context), NULL when the writer was not traced';
@@ -0,0 +1,8 @@
+-- ================================================
+-- Migration: Index expires_at của idempotency_keys
+-- Purpose: Job order:cleanup_idempotency_keys xoá key hết hạn hằng ngày không phải quét cả bảng
+-- Version: 000117
+-- ================================================
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+ON idempotency_keys(expires_at);
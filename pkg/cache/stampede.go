@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ========================================
+// CACHE STAMPEDE PROTECTION
+// ========================================
+// Key nóng (category tree, danh sách bán chạy, book detail) hết hạn giữa lúc traffic cao
+// → hàng trăm request cùng miss và cùng query Postgres (dogpile).
+//
+// WHY 2 lớp:
+// - singleflight: trong 1 instance chỉ 1 request load khi miss, các request khác chờ dùng chung kết quả
+// - Probabilistic early refresh (XFetch): trước khi hết hạn, mỗi lần hit có xác suất refresh nền
+//   tăng dần theo thời gian load + độ gần hạn → key nóng được làm mới trước khi Redis xoá,
+//   không instance nào phải chờ DB
+
+// LoadFunc tải dữ liệu gốc (thường là query DB) khi cache miss / refresh sớm
+type LoadFunc func(ctx context.Context) (interface{}, error)
+
+// entry bọc giá trị kèm metadata cho early refresh.
+// Key chỉ nên đọc / ghi qua Loader (format khác Cache.Set thuần); entry cũ không đúng format = miss
+type entry struct {
+	Value     json.RawMessage `json:"v"`
+	Delta     time.Duration   `json:"d"` // thời gian load lần gần nhất
+	ExpiresAt time.Time       `json:"e"`
+}
+
+const (
+	// DefaultEarlyRefreshBeta > 1 refresh sớm hơn, < 1 muộn hơn (XFetch beta)
+	DefaultEarlyRefreshBeta = 1.0
+
+	// Load dùng chung cho nhiều request: tách khỏi ctx của request đầu tiên (client huỷ không làm lỗi cả nhóm)
+	loadTimeout = 10 * time.Second
+)
+
+// Loader get-or-load có coalescing + early refresh trên Cache
+type Loader struct {
+	cache Cache
+	group singleflight.Group
+	beta  float64
+}
+
+// NewLoader tạo Loader dùng chung cho cả process (singleflight theo key chỉ có tác dụng khi share instance)
+func NewLoader(c Cache) *Loader {
+	return &Loader{cache: c, beta: DefaultEarlyRefreshBeta}
+}
+
+// GetOrLoad đọc key vào dest; miss → load (coalesced) rồi lưu với ttl.
+// Lỗi của load trả về cho mọi request đang chờ, không cache lỗi
+func (l *Loader) GetOrLoad(ctx context.Context, key string, dest interface{}, ttl time.Duration, load LoadFunc) error {
+	var cached entry
+	found, err := l.cache.Get(ctx, key, &cached)
+	if err == nil && found && len(cached.Value) > 0 {
+		if err := json.Unmarshal(cached.Value, dest); err == nil {
+			if l.shouldRefreshEarly(cached, time.Now()) {
+				l.refreshAsync(key, ttl, load)
+			}
+			return nil
+		}
+	}
+
+	raw, err, _ := l.group.Do(key, func() (interface{}, error) {
+		return l.loadAndStore(ctx, key, ttl, load)
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw.([]byte), dest)
+}
+
+// shouldRefreshEarly XFetch: now - delta*beta*ln(rand) >= expiry
+func (l *Loader) shouldRefreshEarly(e entry, now time.Time) bool {
+	if e.ExpiresAt.IsZero() {
+		return false
+	}
+	gap := time.Duration(-float64(e.Delta) * l.beta * math.Log(1-rand.Float64())) // rand ∈ [0,1) → 1-rand ∈ (0,1]
+	return !now.Add(gap).Before(e.ExpiresAt)
+}
+
+// refreshAsync làm mới nền; trùng key với load đang chạy thì dùng chung (DoChan)
+func (l *Loader) refreshAsync(key string, ttl time.Duration, load LoadFunc) {
+	l.group.DoChan(key, func() (interface{}, error) {
+		return l.loadAndStore(context.Background(), key, ttl, load)
+	})
+}
+
+func (l *Loader) loadAndStore(ctx context.Context, key string, ttl time.Duration, load LoadFunc) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+	defer cancel()
+
+	start := time.Now()
+	value, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delta := time.Since(start)
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("cache loader: marshal %s: %w", key, err)
+	}
+
+	// Lỗi ghi cache không làm hỏng request: dữ liệu đã load xong
+	_ = l.cache.Set(ctx, key, entry{
+		Value:     raw,
+		Delta:     delta,
+		ExpiresAt: time.Now().Add(ttl),
+	}, ttl)
+
+	return raw, nil
+}
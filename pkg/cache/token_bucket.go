@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ========================================
+// TOKEN BUCKET (RATE LIMIT PHÂN TÁN)
+// ========================================
+// Bucket lưu trong Redis (hash tokens + ts) → mọi instance API dùng chung 1 hạn mức.
+//
+// WHY Lua thay vì GET + SET:
+// - Đọc / nạp lại / trừ token trong 1 lệnh atomic, 2 request song song không cùng lấy token cuối
+// WHY TIME của Redis thay vì giờ của instance:
+// - Lệch đồng hồ giữa các instance không làm bucket nạp sai
+
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry_after = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+else
+	retry_after = (cost - tokens) / rate
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+-- Bucket đầy lại thì không cần giữ key (đầy = trạng thái mặc định khi key không tồn tại)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens), tostring(retry_after)}
+`)
+
+// TokenBucketResult kết quả lấy 1 token
+type TokenBucketResult struct {
+	Allowed    bool
+	Remaining  int           // số token còn lại (làm tròn xuống)
+	RetryAfter time.Duration // > 0 khi bị chặn: chờ bao lâu thì đủ 1 token
+}
+
+// TakeToken lấy 1 token khỏi bucket key (capacity = burst tối đa, refillPerSecond = tốc độ nạp)
+func TakeToken(ctx context.Context, client redis.Scripter, key string, capacity int, refillPerSecond float64) (*TokenBucketResult, error) {
+	if capacity <= 0 || refillPerSecond <= 0 {
+		return nil, fmt.Errorf("token bucket: capacity and refill rate must be positive")
+	}
+
+	values, err := tokenBucketScript.Run(ctx, client, []string{key}, capacity, refillPerSecond, 1).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("token bucket: %w", err)
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("token bucket: unexpected reply %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	tokens, err := parseScriptFloat(values[1])
+	if err != nil {
+		return nil, err
+	}
+	retryAfter, err := parseScriptFloat(values[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenBucketResult{
+		Allowed:    allowed == 1,
+		Remaining:  int(math.Floor(tokens)),
+		RetryAfter: time.Duration(retryAfter * float64(time.Second)),
+	}, nil
+}
+
+// parseScriptFloat - Lua number trả về Redis bị cắt thành integer → script trả số thực dạng chuỗi
+func parseScriptFloat(v interface{}) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("token bucket: unexpected value %v", v)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("token bucket: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/maintnotifications"
+)
+
+// ========================================
+// REDIS TOPOLOGY (STANDALONE / SENTINEL / CLUSTER)
+// ========================================
+// Deploy HA: 1 node Redis chết = cache + queue dừng theo.
+//
+// WHY client theo topology thay vì 1 địa chỉ:
+// - Sentinel: FailoverClient hỏi sentinel master hiện tại, tự nối lại khi sentinel báo switch-master
+// - Cluster: ClusterClient giữ slot map, theo MOVED / ASK khi slot chuyển node (resharding / failover)
+// Cache, asynq client / server / scheduler / inspector cùng đọc 1 RedisOptions → không lệch topology
+
+type RedisMode string
+
+const (
+	RedisModeStandalone RedisMode = "standalone"
+	RedisModeSentinel   RedisMode = "sentinel"
+	RedisModeCluster    RedisMode = "cluster"
+)
+
+var ErrInvalidRedisOptions = errors.New("invalid redis options")
+
+// RedisOptions cấu hình kết nối Redis cho mọi topology
+// Addrs: standalone = 1 địa chỉ node, sentinel = danh sách sentinel, cluster = seed nodes
+type RedisOptions struct {
+	Mode       RedisMode
+	Addrs      []string
+	MasterName string // sentinel: tên master đăng ký với sentinel
+
+	Username         string
+	Password         string
+	SentinelPassword string // sentinel có thể đặt mật khẩu riêng (requirepass của sentinel)
+	DB               int    // cluster chỉ có DB 0
+
+	PoolSize     int
+	MinIdleConns int
+	MaxRetries   int
+	MaxRedirects int // cluster: số lần theo MOVED / ASK tối đa
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// Conn idle lâu hơn ngưỡng bị đóng khi lấy ra khỏi pool → không dùng lại conn tới master cũ sau failover
+	ConnMaxIdleTime time.Duration
+}
+
+// Validate kiểm tra options khớp với topology
+func (o RedisOptions) Validate() error {
+	if len(o.Addrs) == 0 {
+		return fmt.Errorf("%w: at least one address is required", ErrInvalidRedisOptions)
+	}
+	for _, addr := range o.Addrs {
+		if strings.TrimSpace(addr) == "" {
+			return fmt.Errorf("%w: empty address", ErrInvalidRedisOptions)
+		}
+	}
+	if o.DB < 0 {
+		return fmt.Errorf("%w: db must not be negative", ErrInvalidRedisOptions)
+	}
+
+	switch o.Mode {
+	case RedisModeStandalone:
+		if len(o.Addrs) > 1 {
+			return fmt.Errorf("%w: standalone mode takes exactly one address (got %d)", ErrInvalidRedisOptions, len(o.Addrs))
+		}
+	case RedisModeSentinel:
+		if o.MasterName == "" {
+			return fmt.Errorf("%w: sentinel mode requires a master name", ErrInvalidRedisOptions)
+		}
+	case RedisModeCluster:
+		if o.DB != 0 {
+			return fmt.Errorf("%w: cluster mode only supports db 0 (got %d)", ErrInvalidRedisOptions, o.DB)
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q (standalone, sentinel, cluster)", ErrInvalidRedisOptions, o.Mode)
+	}
+	return nil
+}
+
+// NewRedisClient tạo client theo topology (gọi Validate trước)
+func NewRedisClient(o RedisOptions) redis.UniversalClient {
+	switch o.Mode {
+	case RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       o.MasterName,
+			SentinelAddrs:    o.Addrs,
+			SentinelPassword: o.SentinelPassword,
+			Username:         o.Username,
+			Password:         o.Password,
+			DB:               o.DB,
+			PoolSize:         o.PoolSize,
+			MinIdleConns:     o.MinIdleConns,
+			MaxRetries:       o.MaxRetries,
+			DialTimeout:      o.DialTimeout,
+			ReadTimeout:      o.ReadTimeout,
+			WriteTimeout:     o.WriteTimeout,
+			ConnMaxIdleTime:  o.ConnMaxIdleTime,
+		})
+	case RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           o.Addrs,
+			Username:        o.Username,
+			Password:        o.Password,
+			PoolSize:        o.PoolSize,
+			MinIdleConns:    o.MinIdleConns,
+			MaxRetries:      o.MaxRetries,
+			MaxRedirects:    o.MaxRedirects,
+			DialTimeout:     o.DialTimeout,
+			ReadTimeout:     o.ReadTimeout,
+			WriteTimeout:    o.WriteTimeout,
+			ConnMaxIdleTime: o.ConnMaxIdleTime,
+			MaintNotificationsConfig: &maintnotifications.Config{
+				Mode: maintnotifications.ModeDisabled,
+			},
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:            o.Addrs[0],
+			Username:        o.Username,
+			Password:        o.Password,
+			DB:              o.DB,
+			PoolSize:        o.PoolSize,
+			MinIdleConns:    o.MinIdleConns,
+			MaxRetries:      o.MaxRetries,
+			DialTimeout:     o.DialTimeout,
+			ReadTimeout:     o.ReadTimeout,
+			WriteTimeout:    o.WriteTimeout,
+			ConnMaxIdleTime: o.ConnMaxIdleTime,
+			MaintNotificationsConfig: &maintnotifications.Config{
+				Mode: maintnotifications.ModeDisabled, // ✅ No warnings
+			},
+		})
+	}
+}
+
+// PingRedis health check theo topology: cluster ping từng shard (1 shard chết = degraded),
+// sentinel / standalone ping master hiện tại
+func PingRedis(ctx context.Context, client redis.UniversalClient) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+			if err := shard.Ping(ctx).Err(); err != nil {
+				return fmt.Errorf("shard %s: %w", shard.Options().Addr, err)
+			}
+			return nil
+		})
+	}
+	return client.Ping(ctx).Err()
+}
+
+// ForEachMaster chạy fn trên từng master (cluster) hoặc chính client (sentinel / standalone).
+// Lệnh theo keyspace (SCAN, FLUSHDB) trên ClusterClient chỉ chạm 1 node → phải chạy từng master
+func ForEachMaster(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, node redis.Cmdable) error) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return fn(ctx, master)
+		})
+	}
+	return fn(ctx, client)
+}
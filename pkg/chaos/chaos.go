@@ -0,0 +1,173 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// =====================================================
+// CHAOS / FAULT INJECTION (chỉ dev, staging)
+// =====================================================
+// Kiểm chứng checkout compensation + saga coordinator khi hạ tầng lỗi:
+// - DB latency: mỗi query Postgres chậm thêm N ms (PgxTracer) → lộ timeout WithReadTimeout/WithWriteTimeout
+// - reserve_stock fail: X% lần giữ hàng trả ErrInjected (Fault) → saga phải release phần đã giữ
+// - enqueue delay: lệnh Redis của asynq client chậm thêm N ms (RedisHook) → task đến muộn / request treo
+//
+// Cấu hình chung qua CHAOS_* (xem config.ChaosConfig), ghi đè từng request bằng header X-Chaos
+// (middleware.Chaos). Tắt (mặc định, bắt buộc ở production): mọi hook là no-op.
+
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Point vị trí cài lỗi trong code (Fault)
+type Point string
+
+const (
+	PointReserveStock Point = "reserve_stock"
+)
+
+// Config xác suất tính theo % (0-100)
+type Config struct {
+	Enabled bool
+
+	DBLatency        time.Duration
+	DBLatencyPercent int
+
+	ReserveStockFailPercent int
+
+	EnqueueDelay        time.Duration
+	EnqueueDelayPercent int
+}
+
+var faultsInjected = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "chaos_faults_injected_total",
+	Help: "Faults injected by the chaos hooks (dev/staging only).",
+}, []string{"fault"})
+
+var global atomic.Pointer[Config]
+
+type overrideKey struct{}
+
+// Init bật / tắt chaos cho cả process (gọi 1 lần lúc khởi động, trước khi kết nối DB / Redis)
+func Init(cfg Config) {
+	global.Store(&cfg)
+}
+
+// Enabled chaos đang bật (mặc định tắt khi chưa Init)
+func Enabled() bool {
+	cfg := global.Load()
+	return cfg != nil && cfg.Enabled
+}
+
+// WithOverride gắn cấu hình riêng cho 1 request (bỏ qua khi chaos tắt)
+func WithOverride(ctx context.Context, cfg Config) context.Context {
+	return context.WithValue(ctx, overrideKey{}, &cfg)
+}
+
+// Current cấu hình áp dụng cho ctx: override của request, nếu không có thì cấu hình chung
+func Current(ctx context.Context) Config {
+	cfg := global.Load()
+	if cfg == nil || !cfg.Enabled {
+		return Config{}
+	}
+	if override, ok := ctx.Value(overrideKey{}).(*Config); ok {
+		return *override
+	}
+	return *cfg
+}
+
+// Fault trả ErrInjected theo xác suất cấu hình cho point (nil khi chaos tắt)
+func Fault(ctx context.Context, point Point) error {
+	cfg := Current(ctx)
+
+	var percent int
+	switch point {
+	case PointReserveStock:
+		percent = cfg.ReserveStockFailPercent
+	}
+	if !roll(percent) {
+		return nil
+	}
+
+	faultsInjected.WithLabelValues(string(point)).Inc()
+	return fmt.Errorf("%w: %s", ErrInjected, point)
+}
+
+// ParseOverride đọc header X-Chaos, các khoá không ghi giữ giá trị của base:
+//
+//	db_latency=300ms,db_latency_percent=50,reserve_stock_fail=100,enqueue_delay=2s,enqueue_delay_percent=100
+func ParseOverride(header string, base Config) (Config, error) {
+	cfg := base
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("invalid chaos setting %q (want key=value)", part)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "db_latency":
+			cfg.DBLatency, err = time.ParseDuration(value)
+		case "db_latency_percent":
+			cfg.DBLatencyPercent, err = parsePercent(value)
+		case "reserve_stock_fail":
+			cfg.ReserveStockFailPercent, err = parsePercent(value)
+		case "enqueue_delay":
+			cfg.EnqueueDelay, err = time.ParseDuration(value)
+		case "enqueue_delay_percent":
+			cfg.EnqueueDelayPercent, err = parsePercent(value)
+		default:
+			return Config{}, fmt.Errorf("unknown chaos setting %q", key)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid chaos setting %s: %w", key, err)
+		}
+	}
+	return cfg, nil
+}
+
+func parsePercent(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 || n > 100 {
+		return 0, fmt.Errorf("percent must be 0-100 (got %d)", n)
+	}
+	return n, nil
+}
+
+func roll(percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	return percent >= 100 || rand.IntN(100) < percent
+}
+
+// delay ngủ d theo xác suất (trả sớm khi ctx huỷ), đếm vào metric fault
+func delay(ctx context.Context, fault string, d time.Duration, percent int) {
+	if d <= 0 || !roll(percent) {
+		return
+	}
+	faultsInjected.WithLabelValues(fault).Inc()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,57 @@
+package chaos
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// PgxTracer bọc tracer sẵn có của pool: chậm thêm DBLatency trước khi query chạy.
+// Ngủ theo ctx của query → deadline WithReadTimeout / WithWriteTimeout vẫn cắt như khi DB chậm thật.
+type PgxTracer struct {
+	Next pgx.QueryTracer
+}
+
+var _ pgx.QueryTracer = PgxTracer{}
+
+func (t PgxTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	cfg := Current(ctx)
+	delay(ctx, "db_latency", cfg.DBLatency, cfg.DBLatencyPercent)
+
+	if t.Next == nil {
+		return ctx
+	}
+	return t.Next.TraceQueryStart(ctx, conn, data)
+}
+
+func (t PgxTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if t.Next != nil {
+		t.Next.TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// RedisHook gắn vào Redis client riêng của asynq.Client: mỗi lần enqueue chậm thêm EnqueueDelay
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		cfg := Current(ctx)
+		delay(ctx, "enqueue_delay", cfg.EnqueueDelay, cfg.EnqueueDelayPercent)
+		return next(ctx, cmd)
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		cfg := Current(ctx)
+		delay(ctx, "enqueue_delay", cfg.EnqueueDelay, cfg.EnqueueDelayPercent)
+		return next(ctx, cmds)
+	}
+}
@@ -2,21 +2,29 @@ package container
 
 import (
 	"bookstore-backend/internal/config"
+	"bookstore-backend/internal/infrastructure/breaker"
 	infraCache "bookstore-backend/internal/infrastructure/cache"
+	"bookstore-backend/internal/infrastructure/captcha"
 	"bookstore-backend/internal/infrastructure/database"
 	"bookstore-backend/internal/infrastructure/email"
 	"bookstore-backend/internal/infrastructure/push"
+	"bookstore-backend/internal/infrastructure/sentry"
 	"bookstore-backend/internal/infrastructure/sms"
 	"bookstore-backend/internal/infrastructure/storage"
+	"bookstore-backend/internal/shared/stockdisplay"
 	"bookstore-backend/internal/shared/utils"
 	"bookstore-backend/pkg/cache"
+	"bookstore-backend/pkg/chaos"
 	"bookstore-backend/pkg/jwt"
 	"bookstore-backend/pkg/logger"
+	"bookstore-backend/pkg/tracing"
 	"context"
 	"fmt"
 	"log"
 	"time"
 
+	sentrygo "github.com/getsentry/sentry-go"
+
 	// Domain imports
 
 	"bookstore-backend/internal/domains/category"
@@ -24,54 +32,96 @@ import (
 
 	// Handlers
 	addressHandler "bookstore-backend/internal/domains/address/handler"
+	affiliateHandler "bookstore-backend/internal/domains/affiliate/handler"
+	analyticsHandler "bookstore-backend/internal/domains/analytics/handler"
 	authorHandler "bookstore-backend/internal/domains/author/handler"
 	bookHandler "bookstore-backend/internal/domains/book/handler"
 	cartHandler "bookstore-backend/internal/domains/cart/handler"
+	cartModel "bookstore-backend/internal/domains/cart/model"
 	categoryHandler "bookstore-backend/internal/domains/category/handler"
+	einvoiceHandler "bookstore-backend/internal/domains/einvoice/handler"
+	flashSaleHandler "bookstore-backend/internal/domains/flashsale/handler"
+	fraudHandler "bookstore-backend/internal/domains/fraud/handler"
 	inventoryHandler "bookstore-backend/internal/domains/inventory/handler"
 	notificationHandler "bookstore-backend/internal/domains/notification/handler"
 	orderHandler "bookstore-backend/internal/domains/order/handler"
+	orderModel "bookstore-backend/internal/domains/order/model"
 	paymentHandler "bookstore-backend/internal/domains/payment/handler"
 	promotionHandler "bookstore-backend/internal/domains/promotion/handler"
 	publisherHandler "bookstore-backend/internal/domains/publisher/handler"
+	questionHandler "bookstore-backend/internal/domains/question/handler"
+	quoteHandler "bookstore-backend/internal/domains/quote/handler"
+	recommendationHandler "bookstore-backend/internal/domains/recommendation/handler"
 	reviewHandler "bookstore-backend/internal/domains/review/handler"
+	shippingHandler "bookstore-backend/internal/domains/shipping/handler"
+	taxHandler "bookstore-backend/internal/domains/tax/handler"
+	ticketHandler "bookstore-backend/internal/domains/ticket/handler"
 	userHandler "bookstore-backend/internal/domains/user/handler"
 	warehouseHandler "bookstore-backend/internal/domains/warehouse/handler"
+	wishlistHandler "bookstore-backend/internal/domains/wishlist/handler"
 
 	// Repositories
 	addressRepo "bookstore-backend/internal/domains/address/repository"
+	affiliateRepo "bookstore-backend/internal/domains/affiliate/repository"
+	analyticsRepo "bookstore-backend/internal/domains/analytics/repository"
 	authorRepository "bookstore-backend/internal/domains/author/repository"
 	bookRepo "bookstore-backend/internal/domains/book/repository"
 	cartRepo "bookstore-backend/internal/domains/cart/repository"
 	categoryRepo "bookstore-backend/internal/domains/category/repository"
+	einvoiceRepo "bookstore-backend/internal/domains/einvoice/repository"
+	flashSaleRepo "bookstore-backend/internal/domains/flashsale/repository"
+	fraudRepo "bookstore-backend/internal/domains/fraud/repository"
 	inventoryRepo "bookstore-backend/internal/domains/inventory/repository"
 	notificationRepo "bookstore-backend/internal/domains/notification/repository"
 	orderRepo "bookstore-backend/internal/domains/order/repository"
 	paymentRepo "bookstore-backend/internal/domains/payment/repository"
 	promotionRepo "bookstore-backend/internal/domains/promotion/repository"
 	publisherRepo "bookstore-backend/internal/domains/publisher/repository"
+	questionRepo "bookstore-backend/internal/domains/question/repository"
+	quoteRepo "bookstore-backend/internal/domains/quote/repository"
+	recommendationRepo "bookstore-backend/internal/domains/recommendation/repository"
 	reviewRepo "bookstore-backend/internal/domains/review/repository"
+	shippingRepo "bookstore-backend/internal/domains/shipping/repository"
+	taxRepo "bookstore-backend/internal/domains/tax/repository"
+	ticketRepo "bookstore-backend/internal/domains/ticket/repository"
 	userRepo "bookstore-backend/internal/domains/user/repository"
 	warehouseRepo "bookstore-backend/internal/domains/warehouse/repository"
+	wishlistRepo "bookstore-backend/internal/domains/wishlist/repository"
 
 	// Services
 	addressService "bookstore-backend/internal/domains/address/service"
+	affiliateService "bookstore-backend/internal/domains/affiliate/service"
+	analyticsService "bookstore-backend/internal/domains/analytics/service"
 	authorService "bookstore-backend/internal/domains/author/service"
 	bookService "bookstore-backend/internal/domains/book/service"
 	cartService "bookstore-backend/internal/domains/cart/service"
 	categoryService "bookstore-backend/internal/domains/category/service"
+	einvoiceService "bookstore-backend/internal/domains/einvoice/service"
+	flashSaleService "bookstore-backend/internal/domains/flashsale/service"
+	fraudService "bookstore-backend/internal/domains/fraud/service"
 	inventoryService "bookstore-backend/internal/domains/inventory/service"
 	notificationService "bookstore-backend/internal/domains/notification/service"
 	orderService "bookstore-backend/internal/domains/order/service"
 	paymentService "bookstore-backend/internal/domains/payment/service"
 	promotionService "bookstore-backend/internal/domains/promotion/service"
 	publisherService "bookstore-backend/internal/domains/publisher/service"
+	questionService "bookstore-backend/internal/domains/question/service"
+	quoteService "bookstore-backend/internal/domains/quote/service"
+	recommendationService "bookstore-backend/internal/domains/recommendation/service"
 	reviewService "bookstore-backend/internal/domains/review/service"
+	shippingService "bookstore-backend/internal/domains/shipping/service"
+	taxService "bookstore-backend/internal/domains/tax/service"
+	ticketService "bookstore-backend/internal/domains/ticket/service"
 	userService "bookstore-backend/internal/domains/user/service"
 	warehouseService "bookstore-backend/internal/domains/warehouse/service"
+	wishlistService "bookstore-backend/internal/domains/wishlist/service"
 
+	"bookstore-backend/internal/domains/book/metadata"
+	"bookstore-backend/internal/domains/einvoice/provider"
+	"bookstore-backend/internal/domains/order/marketplace"
 	"bookstore-backend/internal/domains/payment/gateway"
 	"bookstore-backend/internal/domains/payment/gateway/vnpay"
+	"bookstore-backend/internal/domains/shipping/carrier"
 
 	"github.com/hibiken/asynq"
 )
@@ -84,86 +134,187 @@ type Container struct {
 	VNPayGateway   gateway.VNPayGateway
 	MomoGateway    gateway.MomoGateway
 	AsynqClient    *asynq.Client
+	AsynqInspector *asynq.Inspector // metrics: độ sâu queue
 	MinIOStorage   *storage.MinIOStorage
 	ImageProcessor *storage.ImageProcessor
 	JobConfig      config.JobConfig
 
+	// Cổng VNPay giả lập (chỉ khi PAYMENT_PROVIDER_MODE=fake)
+	FakeVNPayGateway *vnpay.FakeGateway
+
 	// Infrastructure Services
 	EmailService              email.EmailService
 	SMSService                *sms.MockSMSService
 	NotificationEmailProvider *email.NotificationEmailProvider // ✅ For notification domain (adapter)
 
-	PushService *push.MockPushService
+	PushService *push.Router
+
+	// Inventory change stream (LISTEN inventory_events → SSE)
+	InventoryEvents *inventoryService.EventStream
+	stopBackground  context.CancelFunc
+
+	// CAPTCHA verifier cho AntiBot middleware (nil khi tắt)
+	CaptchaVerifier *captcha.TurnstileVerifier
+
+	// Book metadata providers (Google Books, OpenLibrary)
+	BookMetadataProviders []metadata.Provider
+
+	// Carrier label APIs (GHTK, GHN; mock khi SHIPPING_PROVIDER_MODE=fake)
+	ShippingCarriers []carrier.Carrier
+
+	// E-invoice providers (MISA meInvoice; mock khi USE_MOCK_EINVOICE)
+	EInvoiceProviders []provider.Provider
+
+	// Marketplace connector (đẩy tồn + giá lên sàn; mock khi USE_MOCK_MARKETPLACE, nil khi chưa cấu hình → không đồng bộ)
+	MarketplaceConnector marketplace.Connector
+
+	// Sentry (panic + stack trace của HTTP handler; nil khi chưa cấu hình SENTRY_DSN)
+	SentryClient *sentrygo.Client
+
+	// Storefront circuit breaker (book browsing + checkout): DB / inventory lỗi → degraded mode
+	StorefrontBreaker *breaker.Breaker
 
 	// Repositories
-	UserRepo         user.Repository
-	CategoryRepo     category.CategoryRepository
-	AuthorRepo       authorRepository.RepositoryInterface
-	PublisherRepo    publisherRepo.RepositoryInterface
-	AddressRepo      addressRepo.RepositoryInterface
-	BookRepo         bookRepo.RepositoryInterface
-	InventoryRepo    inventoryRepo.RepositoryInterface
-	CartRepo         cartRepo.RepositoryInterface
-	PromotionRepo    promotionRepo.PromotionRepository
-	OrderRepo        orderRepo.OrderRepository
-	PaymentRepo      paymentRepo.PaymentRepoInteface
-	RefundRepo       paymentRepo.RefundRepoInterface
-	WebHookRepo      paymentRepo.WebhookRepoInterface
-	TxManager        paymentRepo.TransactionManager
-	ReviewRepo       reviewRepo.ReviewRepository
-	ImageBookRepo    bookRepo.BookImageRepository
-	BulkImportRepo   bookRepo.BulkImportRepoI
-	WarehouseRepo    warehouseRepo.Repository
-	NotificationRepo notificationRepo.NotificationRepository
-	PreferencesRepo  notificationRepo.PreferencesRepository
-	TemplateRepo     notificationRepo.TemplateRepository
-	DeliveryLogRepo  notificationRepo.DeliveryLogRepository
-	CampaignRepo     notificationRepo.CampaignRepository
-	RateLimitRepo    notificationRepo.RateLimitRepository
+	UserRepo            user.Repository
+	CategoryRepo        category.CategoryRepository
+	AuthorRepo          authorRepository.RepositoryInterface
+	PublisherRepo       publisherRepo.RepositoryInterface
+	AddressRepo         addressRepo.RepositoryInterface
+	ServiceableAreaRepo addressRepo.ServiceableAreaRepository
+	BookRepo            bookRepo.RepositoryInterface
+	InventoryRepo       inventoryRepo.RepositoryInterface
+	CartRepo            cartRepo.RepositoryInterface
+	PromotionRepo       promotionRepo.PromotionRepository
+	OrderRepo           orderRepo.OrderRepository
+	IdempotencyRepo     orderRepo.IdempotencyRepository
+	PaymentRepo         paymentRepo.PaymentRepoInteface
+	RefundRepo          paymentRepo.RefundRepoInterface
+	LedgerRepo          paymentRepo.LedgerRepoInterface
+	CODRepo             paymentRepo.CODRemittanceRepoInterface
+	WebHookRepo         paymentRepo.WebhookRepoInterface
+	TxManager           paymentRepo.TransactionManager
+	ReviewRepo          reviewRepo.ReviewRepository
+	QuestionRepo        questionRepo.QuestionRepository
+	QuoteRepo           quoteRepo.QuoteRepository
+	FlashSaleRepo       flashSaleRepo.FlashSaleRepository
+	RecommendRepo       recommendationRepo.Repository
+	AnalyticsRepo       analyticsRepo.Repository
+	FraudRepo           fraudRepo.Repository
+	ShippingRepo        shippingRepo.ShippingRepository
+	EInvoiceRepo        einvoiceRepo.EInvoiceRepository
+	TicketRepo          ticketRepo.TicketRepository
+	AffiliateRepo       affiliateRepo.AffiliateRepository
+	WishlistRepo        wishlistRepo.WishlistRepository
+	ImageBookRepo       bookRepo.BookImageRepository
+	BulkImportRepo      bookRepo.BulkImportRepoI
+	MetadataRepo        bookRepo.MetadataSuggestionRepository
+	PriceTierRepo       bookRepo.PriceTierRepository
+	PricingRepo         bookRepo.PricingRepository
+	DataQualityRepo     bookRepo.DataQualityRepository
+	MergeRepo           bookRepo.MergeRepository
+	WarehouseRepo       warehouseRepo.Repository
+	HolidayRepo         warehouseRepo.HolidayRepository
+	TaxRepo             taxRepo.Repository
+	NotificationRepo    notificationRepo.NotificationRepository
+	PreferencesRepo     notificationRepo.PreferencesRepository
+	TemplateRepo        notificationRepo.TemplateRepository
+	DeliveryLogRepo     notificationRepo.DeliveryLogRepository
+	CampaignRepo        notificationRepo.CampaignRepository
+	RateLimitRepo       notificationRepo.RateLimitRepository
+	EmailEventRepo      notificationRepo.EmailEventRepository
+	DeviceRepo          notificationRepo.DeviceRepository
 
 	// Services
-	UserService         user.Service
-	CategoryService     category.CategoryService
-	AuthorService       authorService.ServiceInterface
-	PublisherService    publisherService.ServiceInterface
-	AddressService      addressService.ServiceInterface
-	BookService         bookService.ServiceInterface
-	InventoryService    inventoryService.ServiceInterface
-	CartService         cartService.ServiceInterface
-	PromotionService    promotionService.ServiceInterface
-	OrderService        orderService.OrderService
-	PaymentService      paymentService.PaymentService
-	RefundService       paymentService.RefundInterface
-	ReviewService       reviewService.ServiceInterface
-	ImageBookService    bookService.BookImageService
-	BulkImportService   bookService.BulkImportServiceInterface
-	WarehouseService    warehouseService.Service
-	NotificationService notificationService.NotificationService
-	PreferencesService  notificationService.PreferencesService
-	TemplateService     notificationService.TemplateService
-	DeliveryService     notificationService.DeliveryService
-	CampaignService     notificationService.CampaignService
+	UserService           user.Service
+	CategoryService       category.CategoryService
+	AuthorService         authorService.ServiceInterface
+	PublisherService      publisherService.ServiceInterface
+	AddressService        addressService.ServiceInterface
+	ServiceabilityService addressService.ServiceabilityService
+	BookService           bookService.ServiceInterface
+	InventoryService      inventoryService.ServiceInterface
+	CartService           cartService.ServiceInterface
+	PromotionService      promotionService.ServiceInterface
+	OrderService          orderService.OrderService
+	PaymentService        paymentService.PaymentService
+	RefundService         paymentService.RefundInterface
+	LedgerService         paymentService.LedgerInterface
+	CODService            paymentService.CODRemittanceInterface
+	ReviewService         reviewService.ServiceInterface
+	QuestionService       questionService.ServiceInterface
+	QuoteService          quoteService.ServiceInterface
+	FlashSaleService      flashSaleService.ServiceInterface
+	RecommendService      recommendationService.ServiceInterface
+	AnalyticsService      analyticsService.ServiceInterface
+	FraudService          fraudService.ServiceInterface
+	ShippingService       shippingService.ServiceInterface
+	EInvoiceService       einvoiceService.ServiceInterface
+	MarketplaceSync       orderService.MarketplaceSyncService
+	TicketService         ticketService.ServiceInterface
+	AffiliateService      affiliateService.ServiceInterface
+	WishlistService       wishlistService.ServiceInterface
+	ImageBookService      bookService.BookImageService
+	BulkImportService     bookService.BulkImportServiceInterface
+	MetadataService       bookService.MetadataEnrichmentService
+	PriceTierService      bookService.PriceTierService
+	PricingService        bookService.PricingService
+	DataQualityService    bookService.DataQualityService
+	MergeService          bookService.MergeService
+	WarehouseService      warehouseService.Service
+	HolidayService        warehouseService.HolidayService
+	TaxService            taxService.Service
+	NotificationService   notificationService.NotificationService
+	PreferencesService    notificationService.PreferencesService
+	TemplateService       notificationService.TemplateService
+	DeliveryService       notificationService.DeliveryService
+	CampaignService       notificationService.CampaignService
+	EmailEventService     notificationService.EmailEventService
+	DeviceService         notificationService.DeviceService
 
 	// Handlers
-	UserHandler         *userHandler.UserHandler
-	CategoryHandler     *categoryHandler.CategoryHandler
-	AuthorHandler       *authorHandler.AuthorHandler
-	PublisherHandler    *publisherHandler.PublisherHandler
-	AddressHandler      *addressHandler.AddressHandler
-	BookHandler         *bookHandler.Handler
-	InventoryHandler    *inventoryHandler.Handler
-	CartHandler         *cartHandler.Handler
-	PublicProHandler    *promotionHandler.PublicHandler
-	AdminProHandler     *promotionHandler.AdminHandler
-	OrderHandler        *orderHandler.OrderHandler
-	PaymentHandler      *paymentHandler.PaymentHandler
-	ReviewHandler       *reviewHandler.ReviewHandler
-	BulkImportHandler   *bookHandler.BulkImportHandler
-	WarehouseHandler    *warehouseHandler.Handler
-	NotificationHandler notificationHandler.NotificationHandler
-	PreferencesHandler  notificationHandler.PreferencesHandler
-	TemplateHandler     notificationHandler.TemplateHandler
-	CampaignHandler     notificationHandler.CampaignHandler
+	UserHandler           *userHandler.UserHandler
+	CategoryHandler       *categoryHandler.CategoryHandler
+	AuthorHandler         *authorHandler.AuthorHandler
+	PublisherHandler      *publisherHandler.PublisherHandler
+	AddressHandler        *addressHandler.AddressHandler
+	ServiceabilityHandler *addressHandler.ServiceabilityHandler
+	BookHandler           *bookHandler.Handler
+	InventoryHandler      *inventoryHandler.Handler
+	CartHandler           *cartHandler.Handler
+	PublicProHandler      *promotionHandler.PublicHandler
+	AdminProHandler       *promotionHandler.AdminHandler
+	OrderHandler          *orderHandler.OrderHandler
+	PaymentHandler        *paymentHandler.PaymentHandler
+	LedgerHandler         *paymentHandler.LedgerHandler
+	CODHandler            *paymentHandler.CODRemittanceHandler
+	SandboxHandler        *paymentHandler.SandboxHandler
+	ReviewHandler         *reviewHandler.ReviewHandler
+	QuestionHandler       *questionHandler.QuestionHandler
+	QuoteHandler          *quoteHandler.QuoteHandler
+	FlashSaleHandler      *flashSaleHandler.FlashSaleHandler
+	RecommendHandler      *recommendationHandler.Handler
+	AnalyticsHandler      *analyticsHandler.Handler
+	FraudHandler          *fraudHandler.Handler
+	BulkImportHandler     *bookHandler.BulkImportHandler
+	MetadataHandler       *bookHandler.MetadataEnrichmentHandler
+	PriceTierHandler      *bookHandler.PriceTierHandler
+	PricingHandler        *bookHandler.PricingHandler
+	DataQualityHandler    *bookHandler.DataQualityHandler
+	MergeHandler          *bookHandler.MergeHandler
+	WarehouseHandler      *warehouseHandler.Handler
+	HolidayHandler        *warehouseHandler.HolidayHandler
+	TaxHandler            *taxHandler.Handler
+	ShippingHandler       *shippingHandler.ShippingHandler
+	EInvoiceHandler       *einvoiceHandler.EInvoiceHandler
+	TicketHandler         *ticketHandler.TicketHandler
+	AffiliateHandler      *affiliateHandler.AffiliateHandler
+	WishlistHandler       *wishlistHandler.WishlistHandler
+	NotificationHandler   notificationHandler.NotificationHandler
+	PreferencesHandler    notificationHandler.PreferencesHandler
+	TemplateHandler       notificationHandler.TemplateHandler
+	CampaignHandler       notificationHandler.CampaignHandler
+	EmailWebhookHandler   notificationHandler.EmailWebhookHandler
+	DeviceHandler         notificationHandler.DeviceHandler
 }
 
 // ========================================
@@ -202,6 +353,17 @@ func NewContainer() (*Container, error) {
 		return nil, fmt.Errorf("failed to init handlers: %w", err)
 	}
 
+	// Step 6b: Metrics (pgxpool, asynq queue) cho /metrics của api + worker
+	if err := c.initMetrics(); err != nil {
+		return nil, fmt.Errorf("failed to init metrics: %w", err)
+	}
+
+	// Step 7: Kiểm tra wiring + cấu hình, gom toàn bộ lỗi 1 lần thay vì panic lúc chạy
+	if err := c.validateDependencies(); err != nil {
+		c.Cleanup()
+		return nil, fmt.Errorf("dependency validation failed:\n%w", err)
+	}
+
 	log.Println("✅ Container initialized successfully")
 	return c, nil
 }
@@ -219,6 +381,34 @@ func (c *Container) initInfrastructure() error {
 	log.Println("✅ Config loaded")
 	c.JobConfig = cfg.Job
 
+	// Tracing: bật trước Database/Redis để pgx tracer + redis hook có provider ngay từ đầu
+	if err := tracing.Init(tracing.Config{
+		ServiceName:   cfg.Tracing.ServiceName,
+		Exporter:      cfg.Tracing.Exporter,
+		OTLPEndpoint:  cfg.Tracing.OTLPEndpoint,
+		SamplePercent: cfg.Tracing.SamplePercent,
+	}); err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+	if tracing.Enabled() {
+		log.Printf("✅ Tracing enabled (exporter=%s, sample=%d%%)", cfg.Tracing.Exporter, cfg.Tracing.SamplePercent)
+	}
+
+	// Chaos: cũng phải bật trước Database/Redis (pgx tracer + hook Redis của asynq client)
+	chaos.Init(chaos.Config{
+		Enabled:                 cfg.Chaos.Enabled,
+		DBLatency:               time.Duration(cfg.Chaos.DBLatencyMs) * time.Millisecond,
+		DBLatencyPercent:        cfg.Chaos.DBLatencyPercent,
+		ReserveStockFailPercent: cfg.Chaos.ReserveStockFailPercent,
+		EnqueueDelay:            time.Duration(cfg.Chaos.EnqueueDelayMs) * time.Millisecond,
+		EnqueueDelayPercent:     cfg.Chaos.EnqueueDelayPercent,
+	})
+	if chaos.Enabled() {
+		log.Printf("⚠️  Chaos enabled (db_latency=%dms@%d%%, reserve_stock_fail=%d%%, enqueue_delay=%dms@%d%%)",
+			cfg.Chaos.DBLatencyMs, cfg.Chaos.DBLatencyPercent, cfg.Chaos.ReserveStockFailPercent,
+			cfg.Chaos.EnqueueDelayMs, cfg.Chaos.EnqueueDelayPercent)
+	}
+
 	// Database
 	dbConfig, err := config.LoadDatabaseConfig()
 	if err != nil {
@@ -240,12 +430,8 @@ func (c *Container) initInfrastructure() error {
 	c.DB = db
 	log.Println("✅ Database connected")
 
-	// Redis Cache
-	redisCache := infraCache.NewRedisCache(
-		cfg.Redis.Host,
-		cfg.Redis.Password,
-		cfg.Redis.DB,
-	)
+	// Redis Cache (standalone / sentinel / cluster theo REDIS_MODE)
+	redisCache := infraCache.NewRedisCache(cfg.Redis.ClientOptions())
 
 	if rc, ok := redisCache.(*infraCache.RedisCache); ok {
 		if err := rc.Connect(context.Background()); err != nil {
@@ -260,13 +446,14 @@ func (c *Container) initInfrastructure() error {
 	c.JWTManager = jwt.NewManager(cfg.JWT.Secret)
 	log.Println("✅ JWT Manager initialized")
 
-	// Asynq Client
-	redisOpt := asynq.RedisClientOpt{
-		Addr:     cfg.Redis.Host,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
+	// Asynq Client (cùng topology với cache)
+	c.AsynqClient = asynq.NewClient(asynqRedisOpt(cfg.Redis))
+	if chaos.Enabled() {
+		// Redis client riêng có hook trễ enqueue (asynq.RedisConnOpt không nhận hook)
+		rdb := cache.NewRedisClient(cfg.Redis.ClientOptions())
+		rdb.AddHook(chaos.RedisHook{})
+		c.AsynqClient = asynq.NewClientFromRedisClient(rdb)
 	}
-	c.AsynqClient = asynq.NewClient(redisOpt)
 	log.Println("✅ Asynq Client initialized")
 
 	// MinIO Storage
@@ -311,6 +498,13 @@ func (c *Container) initGateways() error {
 	}
 	c.VNPayGateway = vnpClient
 	log.Println("✅ VNPay Gateway initialized")
+
+	// Mode fake: client thật trỏ APIUrl về cổng giả lập chạy trong chính API
+	if c.Config.Provider.PaymentMode == config.ProviderModeFake {
+		hang := time.Duration(c.Config.Provider.FakeTimeoutSeconds) * time.Second
+		c.FakeVNPayGateway = vnpay.NewFakeGateway(vnpCfg, hang)
+		log.Printf("⚠️  VNPay Gateway (Fake) serving at %s", c.Config.VNPay.APIURL)
+	}
 	logger.Info("Init gateway:", map[string]interface{}{
 		"vnpCfg": vnpCfg,
 	})
@@ -330,6 +524,12 @@ func (c *Container) initProviders() error {
 	c.EmailService = email.NewDevEmailService(smtpHost, smtpPort)
 	log.Println("✅ Email Service (SMTP) initialized")
 
+	// Suppression list (hard bounce / complaint từ provider webhook) bọc mọi đường gửi email
+	// Repo tạo sớm ở đây vì providers khởi tạo trước bước repositories
+	c.EmailEventRepo = notificationRepo.NewEmailEventRepository(c.DB.Pool)
+	c.EmailService = email.NewSuppressingEmailService(c.EmailService, c.EmailEventRepo)
+	log.Println("✅ Email suppression enabled")
+
 	// Create Notification Email Adapter (for notification domain)
 	c.NotificationEmailProvider = email.NewNotificationEmailProvider(c.EmailService)
 	log.Println("✅ Notification Email Provider (Adapter) initialized")
@@ -347,17 +547,118 @@ func (c *Container) initProviders() error {
 		log.Println("✅ SMS Service (Twilio) initialized")
 	}
 
-	// Push Service (mock for dev, FCM for prod)
-	useMockPush := utils.GetEnvVariable("USE_MOCK_PUSH", "true") == "true"
-	if useMockPush {
-		c.PushService = push.NewMockPushService()
+	// Push: router theo provider của device token; mock (dev) nhận cả FCM lẫn APNs,
+	// production chỉ đăng ký provider đã cấu hình (token provider còn lại bị bỏ qua)
+	c.PushService = push.NewRouter()
+	if c.Config.Push.UseMock {
+		mockPush := push.NewMockPushService()
+		c.PushService.Register(push.ProviderFCM, mockPush).Register(push.ProviderAPNs, mockPush)
 		log.Println("✅ Push Service (Mock) initialized")
 	} else {
-		// fcmKey := utils.GetEnvVariable("FCM_SERVER_KEY", "")
-		// c.PushService = push.NewFCMPushService(fcmKey)
-		log.Println("✅ Push Service (FCM) initialized")
+		if c.Config.Push.FCMCredentialsFile != "" {
+			fcm, err := push.NewFCMSender(c.Config.Push.FCMCredentialsFile)
+			if err != nil {
+				return fmt.Errorf("push fcm: %w", err)
+			}
+			c.PushService.Register(push.ProviderFCM, fcm)
+			log.Println("✅ Push Service (FCM) initialized")
+		}
+		if c.Config.Push.APNsKeyFile != "" {
+			apns, err := push.NewAPNsSender(push.APNsConfig{
+				KeyFile:    c.Config.Push.APNsKeyFile,
+				KeyID:      c.Config.Push.APNsKeyID,
+				TeamID:     c.Config.Push.APNsTeamID,
+				Topic:      c.Config.Push.APNsTopic,
+				Production: c.Config.Push.APNsProduction,
+			})
+			if err != nil {
+				return fmt.Errorf("push apns: %w", err)
+			}
+			c.PushService.Register(push.ProviderAPNs, apns)
+			log.Println("✅ Push Service (APNs) initialized")
+		}
+	}
+
+	// CAPTCHA (Cloudflare Turnstile) cho AntiBot middleware, chỉ khi bật ANTIBOT_ENABLED
+	if c.Config.AntiBot.Enabled {
+		c.CaptchaVerifier = captcha.NewTurnstileVerifier(
+			c.Config.AntiBot.TurnstileSecretKey,
+			c.Config.AntiBot.TurnstileVerifyURL,
+		)
+		log.Println("✅ Captcha Verifier (Turnstile) initialized")
+	}
+
+	// Book metadata providers: thứ tự = thứ tự gọi khi enrich
+	c.BookMetadataProviders = []metadata.Provider{
+		metadata.NewGoogleBooksProvider(c.Config.BookMeta.GoogleBooksAPIKey),
+		metadata.NewOpenLibraryProvider(),
+	}
+	log.Println("✅ Book Metadata Providers initialized")
+
+	// Carriers: fake giả lập hãng mặc định (kịch bản theo giá trị khai báo), live / sandbox chỉ đăng ký
+	// hãng có token (base URL đã được điền theo mode, xem config.applyProviderEndpoints)
+	if c.Config.Provider.ShippingMode == config.ProviderModeFake {
+		hang := time.Duration(c.Config.Provider.FakeTimeoutSeconds) * time.Second
+		c.ShippingCarriers = []carrier.Carrier{carrier.NewMockCarrier(c.Config.Shipping.DefaultCarrier, hang)}
+		log.Println("✅ Shipping Carrier (Fake) initialized")
+	} else {
+		if c.Config.Shipping.GHTKToken != "" {
+			c.ShippingCarriers = append(c.ShippingCarriers,
+				carrier.NewGHTKCarrier(c.Config.Shipping.GHTKToken, c.Config.Shipping.GHTKBaseURL))
+			log.Println("✅ Shipping Carrier (GHTK) initialized")
+		}
+		if c.Config.Shipping.GHNToken != "" && c.Config.Shipping.GHNShopID > 0 {
+			c.ShippingCarriers = append(c.ShippingCarriers,
+				carrier.NewGHNCarrier(c.Config.Shipping.GHNToken, int64(c.Config.Shipping.GHNShopID), c.Config.Shipping.GHNBaseURL))
+			log.Println("✅ Shipping Carrier (GHN) initialized")
+		}
+	}
+	log.Printf("✅ Provider modes: payment=%s shipping=%s", c.Config.Provider.PaymentMode, c.Config.Provider.ShippingMode)
+
+	// E-invoice: mock giả lập nhà cung cấp (dev), production chỉ đăng ký khi có tài khoản tích hợp
+	if c.Config.EInvoice.UseMock {
+		c.EInvoiceProviders = []provider.Provider{provider.NewMockProvider(c.Config.EInvoice.Provider)}
+		log.Println("✅ E-Invoice Provider (Mock) initialized")
+	} else if c.Config.EInvoice.MISAAppID != "" {
+		c.EInvoiceProviders = append(c.EInvoiceProviders, provider.NewMISAProvider(provider.MISAConfig{
+			BaseURL:  c.Config.EInvoice.MISABaseURL,
+			AppID:    c.Config.EInvoice.MISAAppID,
+			TaxCode:  c.Config.EInvoice.MISATaxCode,
+			Username: c.Config.EInvoice.MISAUsername,
+			Password: c.Config.EInvoice.MISAPassword,
+		}))
+		log.Println("✅ E-Invoice Provider (MISA) initialized")
 	}
 
+	// Marketplace connector: mock (dev), production chỉ khi đã cấu hình service connector
+	if c.Config.Market.UseMock {
+		c.MarketplaceConnector = marketplace.NewMockConnector()
+		log.Println("✅ Marketplace Connector (Mock) initialized")
+	} else if c.Config.Market.ConnectorBaseURL != "" {
+		c.MarketplaceConnector = marketplace.NewHTTPConnector(c.Config.Market.ConnectorBaseURL, c.Config.Market.ConnectorSecret)
+		log.Println("✅ Marketplace Connector initialized")
+	}
+
+	if c.Config.Sentry.DSN != "" && c.Config.Sentry.SamplePercent > 0 {
+		client, err := sentry.NewClient(sentry.Config{
+			DSN:           c.Config.Sentry.DSN,
+			Environment:   c.Config.App.Environment,
+			Release:       c.Config.App.Version,
+			SamplePercent: c.Config.Sentry.SamplePercent,
+		})
+		if err != nil {
+			return err
+		}
+		c.SentryClient = client
+		log.Println("✅ Sentry Client initialized")
+	}
+
+	c.StorefrontBreaker = breaker.New("storefront", breaker.Config{
+		FailureThreshold: c.Config.Breaker.StorefrontFailureThreshold,
+		OpenTimeout:      time.Duration(c.Config.Breaker.StorefrontOpenSeconds) * time.Second,
+	})
+	log.Println("✅ Storefront Circuit Breaker initialized")
+
 	return nil
 }
 
@@ -372,18 +673,41 @@ func (c *Container) initRepositories() error {
 	c.AuthorRepo = authorRepository.NewPostgresRepository(pool, c.Cache)
 	c.PublisherRepo = publisherRepo.NewPostgresRepository(pool, c.Cache)
 	c.AddressRepo = addressRepo.NewPostgresRepository(pool)
+	c.ServiceableAreaRepo = addressRepo.NewServiceableAreaRepository(pool)
 	c.BookRepo = bookRepo.NewPostgresRepository(pool, c.Cache)
 	c.InventoryRepo = inventoryRepo.NewRepository(pool)
 	c.CartRepo = cartRepo.NewPostgresRepository(pool, c.Cache)
 	c.PromotionRepo = promotionRepo.NewPostgresRepository(pool)
 	c.OrderRepo = orderRepo.NewPostgresOrderRepository(pool)
+	c.IdempotencyRepo = orderRepo.NewIdempotencyRepository(pool)
 	c.PaymentRepo = paymentRepo.NewppRepository(pool)
+	c.WebHookRepo = paymentRepo.NewWebhookRepository(pool)
 	c.RefundRepo = paymentRepo.NewRefundRepository(pool)
+	c.LedgerRepo = paymentRepo.NewLedgerRepository(pool)
+	c.CODRepo = paymentRepo.NewCODRemittanceRepository(pool)
 	c.TxManager = paymentRepo.NewPostgresTransactionManager(pool)
 	c.ReviewRepo = reviewRepo.NewPostgresReviewRepository(pool)
+	c.QuestionRepo = questionRepo.NewPostgresQuestionRepository(pool)
+	c.QuoteRepo = quoteRepo.NewPostgresQuoteRepository(pool)
+	c.FlashSaleRepo = flashSaleRepo.NewPostgresFlashSaleRepository(pool)
+	c.RecommendRepo = recommendationRepo.NewPostgresRepository(pool)
+	c.AnalyticsRepo = analyticsRepo.NewPostgresRepository(pool)
+	c.FraudRepo = fraudRepo.NewPostgresRepository(pool)
+	c.ShippingRepo = shippingRepo.NewPostgresShippingRepository(pool)
+	c.EInvoiceRepo = einvoiceRepo.NewPostgresEInvoiceRepository(pool)
+	c.TicketRepo = ticketRepo.NewPostgresTicketRepository(pool)
+	c.AffiliateRepo = affiliateRepo.NewPostgresAffiliateRepository(pool)
+	c.WishlistRepo = wishlistRepo.NewPostgresWishlistRepository(pool)
 	c.ImageBookRepo = bookRepo.NewBookImageRepository(pool)
 	c.BulkImportRepo = bookRepo.NewBulkImportRepository(pool)
+	c.MetadataRepo = bookRepo.NewMetadataSuggestionRepository(pool)
+	c.PriceTierRepo = bookRepo.NewPriceTierRepository(pool)
+	c.PricingRepo = bookRepo.NewPricingRepository(pool)
+	c.DataQualityRepo = bookRepo.NewDataQualityRepository(pool)
+	c.MergeRepo = bookRepo.NewMergeRepository(pool)
 	c.WarehouseRepo = warehouseRepo.NewRepository(pool)
+	c.HolidayRepo = warehouseRepo.NewHolidayRepository(pool)
+	c.TaxRepo = taxRepo.NewPostgresRepository(pool)
 
 	// Notification Repositories
 	c.NotificationRepo = notificationRepo.NewNotificationRepository(pool)
@@ -392,6 +716,7 @@ func (c *Container) initRepositories() error {
 	c.DeliveryLogRepo = notificationRepo.NewDeliveryLogRepository(pool)
 	c.CampaignRepo = notificationRepo.NewCampaignRepository(pool)
 	c.RateLimitRepo = notificationRepo.NewRateLimitRepository(pool)
+	c.DeviceRepo = notificationRepo.NewDeviceRepository(pool)
 
 	log.Println("✅ All repositories initialized")
 	return nil
@@ -426,6 +751,20 @@ func (c *Container) initServices() error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	// PHASE 6: Workflow trạng thái đơn lưu trong DB phải hợp lệ (reachability) trước khi nhận request
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.OrderService.ValidateStatusWorkflow(ctx); err != nil {
+		return fmt.Errorf("order workflow validation failed: %w", err)
+	}
+	log.Println("  ✓ Order status workflow validated")
+
+	// Rule huỷ đơn nạp sẵn vào bộ nhớ (CancelOrder / order detail không query mỗi request)
+	if err := c.OrderService.LoadCancellationPolicy(ctx); err != nil {
+		return fmt.Errorf("order cancellation rules load failed: %w", err)
+	}
+	log.Println("  ✓ Order cancellation rules loaded")
+
 	log.Println("✅ All services initialized and validated")
 	return nil
 }
@@ -444,7 +783,7 @@ func (c *Container) initIndependentServices() error {
 	)
 	log.Println("  ✓ UserService")
 
-	c.CategoryService = categoryService.NewCategoryService(c.CategoryRepo)
+	c.CategoryService = categoryService.NewCategoryService(c.CategoryRepo, c.Cache)
 	log.Println("  ✓ CategoryService")
 
 	c.AuthorService = authorService.NewAuthorService(c.AuthorRepo)
@@ -453,15 +792,80 @@ func (c *Container) initIndependentServices() error {
 	c.PublisherService = publisherService.NewPublisherService(c.PublisherRepo)
 	log.Println("  ✓ PublisherService")
 
-	c.AddressService = addressService.NewAddressService(c.AddressRepo)
+	c.ServiceabilityService = addressService.NewServiceabilityService(c.ServiceableAreaRepo)
+	log.Println("  ✓ ServiceabilityService")
+
+	c.AddressService = addressService.NewAddressService(c.AddressRepo, c.ServiceabilityService)
 	log.Println("  ✓ AddressService")
 
 	c.WarehouseService = warehouseService.NewService(c.WarehouseRepo)
 	log.Println("  ✓ WarehouseService")
 
+	c.HolidayService = warehouseService.NewHolidayService(c.HolidayRepo, c.Config.Order.ProcessingSLADays)
+	log.Println("  ✓ HolidayService")
+
+	c.TaxService = taxService.NewService(c.TaxRepo)
+	log.Println("  ✓ TaxService")
+
 	c.ReviewService = reviewService.NewReviewService(c.ReviewRepo)
 	log.Println("  ✓ ReviewService")
 
+	c.QuestionService = questionService.NewQuestionService(c.QuestionRepo)
+	log.Println("  ✓ QuestionService")
+
+	c.RecommendService = recommendationService.NewService(c.RecommendRepo, c.Cache)
+	log.Println("  ✓ RecommendService")
+
+	c.AnalyticsService = analyticsService.NewService(c.AnalyticsRepo, c.AsynqClient)
+	log.Println("  ✓ AnalyticsService")
+
+	c.FraudService = fraudService.NewService(c.FraudRepo, c.AsynqClient)
+	log.Println("  ✓ FraudService")
+
+	c.ShippingService = shippingService.NewShippingService(
+		c.ShippingRepo,
+		c.DB.Pool,
+		c.ShippingCarriers,
+		c.MinIOStorage,
+		c.AsynqClient,
+		shippingService.Config{
+			DefaultCarrier:     c.Config.Shipping.DefaultCarrier,
+			DefaultLabelFormat: c.Config.Shipping.DefaultLabelFormat,
+			Sender: carrier.Address{
+				Name:     c.Config.Shipping.SenderName,
+				Phone:    c.Config.Shipping.SenderPhone,
+				Street:   c.Config.Shipping.SenderStreet,
+				Ward:     c.Config.Shipping.SenderWard,
+				District: c.Config.Shipping.SenderDistrict,
+				Province: c.Config.Shipping.SenderProvince,
+			},
+			QuoteRates:      c.Config.Shipping.QuoteRates,
+			CreateOnConfirm: c.Config.Shipping.CreateOnConfirm,
+		},
+	)
+	log.Println("  ✓ ShippingService")
+
+	c.EInvoiceService = einvoiceService.NewEInvoiceService(
+		c.EInvoiceRepo,
+		c.EInvoiceProviders,
+		c.AsynqClient,
+		einvoiceService.Config{
+			Provider:     c.Config.EInvoice.Provider,
+			TemplateCode: c.Config.EInvoice.TemplateCode,
+			Series:       c.Config.EInvoice.Series,
+			VATRate:      c.Config.EInvoice.VATRate,
+		},
+	)
+	log.Println("  ✓ EInvoiceService")
+
+	c.MarketplaceSync = orderService.NewMarketplaceSyncService(
+		c.OrderRepo,
+		c.MarketplaceConnector,
+		c.Cache,
+		c.Config.Market.SyncRequestsPerMinute,
+	)
+	log.Println("  ✓ MarketplaceSyncService")
+
 	c.ImageBookService = bookService.NewBookImageService(
 		c.ImageBookRepo,
 		c.MinIOStorage,
@@ -469,12 +873,41 @@ func (c *Container) initIndependentServices() error {
 	)
 	log.Println("  ✓ ImageBookService")
 
+	c.MetadataService = bookService.NewMetadataEnrichmentService(
+		c.MetadataRepo,
+		c.BookMetadataProviders,
+		c.Cache,
+		c.AsynqClient,
+	)
+	log.Println("  ✓ MetadataService")
+
+	c.PriceTierService = bookService.NewPriceTierService(c.PriceTierRepo)
+	log.Println("  ✓ PriceTierService")
+
+	c.PricingService = bookService.NewPricingService(c.PricingRepo, c.Cache, c.AsynqClient)
+	log.Println("  ✓ PricingService")
+
+	c.DataQualityService = bookService.NewDataQualityService(c.DataQualityRepo)
+	log.Println("  ✓ DataQualityService")
+
+	c.MergeService = bookService.NewMergeService(c.MergeRepo, c.Cache, c.AsynqClient)
+	log.Println("  ✓ MergeService")
+
 	c.InventoryService = inventoryService.NewService(
 		c.InventoryRepo,
 		c.AsynqClient,
+		c.Config.Inventory.AdjustmentApprovalThreshold,
+		c.HolidayService,
 	)
 	log.Println("  ✓ InventoryService")
 
+	// Listener chạy tới khi Cleanup() cancel (trước khi đóng pool)
+	var backgroundCtx context.Context
+	backgroundCtx, c.stopBackground = context.WithCancel(context.Background())
+	c.InventoryEvents = inventoryService.NewEventStream(c.DB.Pool)
+	go c.InventoryEvents.Run(backgroundCtx)
+	log.Println("  ✓ InventoryEventStream")
+
 	// Preferences Service (independent)
 	c.PreferencesService = notificationService.NewPreferencesService(c.PreferencesRepo)
 	log.Println("  ✓ PreferencesService")
@@ -483,6 +916,10 @@ func (c *Container) initIndependentServices() error {
 	c.TemplateService = notificationService.NewTemplateService(c.TemplateRepo)
 	log.Println("  ✓ TemplateService")
 
+	// Email Event Service (independent) - provider webhooks delivered/bounced/complained
+	c.EmailEventService = notificationService.NewEmailEventService(c.EmailEventRepo)
+	log.Println("  ✓ EmailEventService")
+
 	return nil
 }
 
@@ -499,6 +936,7 @@ func (c *Container) initDependentServices() error {
 		c.MinIOStorage,
 		c.ImageBookRepo,
 		c.AsynqClient,
+		c.StorefrontBreaker,
 	)
 	log.Println("  ✓ BookService")
 
@@ -516,6 +954,18 @@ func (c *Container) initDependentServices() error {
 	)
 	log.Println("  ✓ BulkImportService")
 
+	c.LedgerService = paymentService.NewLedgerService(c.LedgerRepo)
+	log.Println("  ✓ LedgerService")
+
+	// CODService chỉ cần repo - OrderService dùng để ghi kiện COD đã giao + tiền đã thu
+	c.CODService = paymentService.NewCODRemittanceService(
+		c.CODRepo,
+		c.LedgerRepo,
+		c.TxManager,
+		c.Config.COD.MarkPaidOnRemittance,
+	)
+	log.Println("  ✓ CODService")
+
 	// OrderService - Initialize WITHOUT CartService (will be wired later)
 	c.OrderService = orderService.NewOrderService(
 		c.OrderRepo,
@@ -525,14 +975,163 @@ func (c *Container) initDependentServices() error {
 		c.CartRepo,
 		c.PromotionRepo,
 		c.BookService,
+		c.PriceTierService,
 		c.InventoryService,
 		c.AsynqClient,
+		c.CODService,
+		time.Duration(c.Config.Order.CreateTimeoutSeconds)*time.Second,
+		time.Duration(c.Config.Order.ModificationWindowMinutes)*time.Minute,
+		c.checkoutPolicy(),
 	)
 	log.Println("  ✓ OrderService (without CartService)")
 
+	// Hook trigger_shipment của workflow đơn → mua nhãn với hãng mặc định
+	if os, ok := c.OrderService.(interface {
+		SetShipmentTrigger(orderService.ShipmentTrigger)
+	}); ok {
+		os.SetShipmentTrigger(c.ShippingService)
+		log.Println("  ✓ OrderService shipment trigger wired")
+	}
+
+	// Checkout báo giá phí ship với hãng; webhook vận đơn chuyển trạng thái đơn (shipping → order)
+	if os, ok := c.OrderService.(interface {
+		SetShippingQuoter(orderService.ShippingQuoter)
+	}); ok {
+		os.SetShippingQuoter(c.ShippingService)
+		log.Println("  ✓ OrderService shipping quoter wired")
+	}
+
+	// Thuế theo tỉnh / danh mục khi tính tổng đơn
+	if os, ok := c.OrderService.(interface {
+		SetTaxCalculator(orderService.TaxCalculator)
+	}); ok {
+		os.SetTaxCalculator(c.TaxService)
+		log.Println("  ✓ OrderService tax calculator wired")
+	}
+	if sync, ok := c.OrderService.(shippingService.OrderStatusSync); ok {
+		if ss, ok := c.ShippingService.(interface {
+			SetOrderStatusSync(shippingService.OrderStatusSync)
+		}); ok {
+			ss.SetOrderStatusSync(sync)
+			log.Println("  ✓ ShippingService order status sync wired")
+		}
+	}
+
+	// QuoteService converts approved B2B quotes into orders
+	c.QuoteService = quoteService.NewQuoteService(c.QuoteRepo, c.OrderService)
+	log.Println("  ✓ QuoteService")
+
+	// FlashSaleService gates reserve_stock behind FIFO purchase tokens
+	c.FlashSaleService = flashSaleService.NewFlashSaleService(c.FlashSaleRepo, c.OrderService, c.Cache)
+	log.Println("  ✓ FlashSaleService")
+
 	return nil
 }
 
+// stockDisplayPolicy - policy hiển thị tồn kho cho response customer-facing (book + cart)
+func (c *Container) stockDisplayPolicy() stockdisplay.Policy {
+	return stockdisplay.Policy{
+		LowStockThreshold:  c.Config.Stock.LowStockThreshold,
+		MaxVisibleQuantity: c.Config.Stock.MaxVisibleQuantity,
+	}
+}
+
+// checkoutPolicy - đơn tối thiểu + phí ship / ngưỡng freeship / bậc giảm ship (order + cart progress dùng chung)
+func (c *Container) checkoutPolicy() orderModel.CheckoutPolicy {
+	return orderModel.NewCheckoutPolicy(
+		int64(c.Config.Order.MinOrderAmount),
+		int64(c.Config.Order.ShippingFee),
+		int64(c.Config.Order.FreeShippingThreshold),
+		orderModel.ParseShippingDiscountTiers(c.Config.Order.ShippingDiscountTiers),
+	)
+}
+
+// PayLinkPolicy link thanh toán cho đơn CSKH đặt thay khách (TTL không hợp lệ → 24h)
+// Worker dùng TTL để đối soát đơn pay_link quá hạn
+func (c *Container) PayLinkPolicy() cartModel.PayLinkPolicy {
+	ttlHours := c.Config.Order.PayLinkTTLHours
+	if ttlHours <= 0 {
+		ttlHours = 24
+	}
+	return cartModel.PayLinkPolicy{
+		BaseURL: c.Config.Order.PayLinkBaseURL,
+		TTL:     time.Duration(ttlHours) * time.Hour,
+	}
+}
+
+// AnonymousCartPolicy quota tạo giỏ ẩn danh theo IP + tuổi giỏ rỗng bị dọn (giá trị không hợp lệ → 24h)
+// Worker dùng EmptyMaxAge cho job cart:cleanup_anonymous_carts
+func (c *Container) AnonymousCartPolicy() cartModel.AnonymousCartPolicy {
+	maxAgeHours := c.Config.Cart.AnonymousEmptyMaxAgeHours
+	if maxAgeHours <= 0 {
+		maxAgeHours = 24
+	}
+	return cartModel.AnonymousCartPolicy{
+		MaxCreatesPerIP: c.Config.Cart.AnonymousCreatePerIP,
+		Window:          time.Duration(c.Config.Cart.AnonymousCreateWindowSeconds) * time.Second,
+		EmptyMaxAge:     time.Duration(maxAgeHours) * time.Hour,
+	}
+}
+
+// CartExpirationPolicy hạn giỏ khách / user + nhịp gia hạn + mốc nhắc (TTL không hợp lệ → mặc định)
+// Worker dùng WarnBefore cho job cart:warn_expiring_carts
+func (c *Container) CartExpirationPolicy() cartModel.CartExpirationPolicy {
+	cfg := c.Config.Cart
+	guestDays := cfg.GuestTTLDays
+	if guestDays <= 0 {
+		guestDays = cartModel.DefaultCartExpirationDays
+	}
+	userDays := cfg.UserTTLDays
+	if userDays <= 0 {
+		userDays = cartModel.DefaultUserCartExpirationDays
+	}
+	return cartModel.CartExpirationPolicy{
+		GuestTTL:      time.Duration(guestDays) * 24 * time.Hour,
+		UserTTL:       time.Duration(userDays) * 24 * time.Hour,
+		RenewInterval: time.Duration(cfg.RenewIntervalMinutes) * time.Minute,
+		WarnBefore:    time.Duration(cfg.ExpiryWarningHours) * time.Hour,
+	}
+}
+
+// CheckoutSagaPolicy đối soát checkout saga; saga kẹt tối thiểu gấp đôi timeout tạo đơn
+// (CreateOrder còn đang chạy không bị coi là kẹt)
+func (c *Container) CheckoutSagaPolicy() cartModel.CheckoutSagaPolicy {
+	cfg := c.Config.Cart
+	staleAfter := time.Duration(cfg.CheckoutSagaStaleMinutes) * time.Minute
+	if minStale := 2 * time.Duration(c.Config.Order.CreateTimeoutSeconds) * time.Second; staleAfter < minStale {
+		staleAfter = minStale
+	}
+	maxAttempts := cfg.CheckoutCompensationMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	return cartModel.CheckoutSagaPolicy{
+		StaleAfter:  staleAfter,
+		HoldGrace:   time.Duration(cfg.CheckoutHoldGraceMinutes) * time.Minute,
+		MaxAttempts: maxAttempts,
+	}
+}
+
+// WishlistPriceGuaranteePolicy giữ giá wishlist khi chuyển sang giỏ (giá trị không hợp lệ → mặc định)
+func (c *Container) WishlistPriceGuaranteePolicy() cartModel.WishlistPriceGuaranteePolicy {
+	cfg := c.Config.Cart
+	positive := func(v, fallback int) int {
+		if v <= 0 {
+			return fallback
+		}
+		return v
+	}
+	return cartModel.WishlistPriceGuaranteePolicy{
+		Enabled:            cfg.WishlistPriceGuaranteeEnabled,
+		Window:             time.Duration(positive(cfg.WishlistPriceWindowHours, 24)) * time.Hour,
+		HoldTTL:            time.Duration(positive(cfg.WishlistPriceHoldHours, 24)) * time.Hour,
+		MaxPerUser:         positive(cfg.WishlistPriceMaxPerUser, 5),
+		Period:             time.Duration(positive(cfg.WishlistPricePeriodDays, 30)) * 24 * time.Hour,
+		MaxQuantity:        positive(cfg.WishlistPriceMaxQuantity, 2),
+		MaxDiscountPercent: positive(cfg.WishlistPriceMaxDiscountPct, 30),
+	}
+}
+
 // ========================================
 // PHASE 3: CROSS-DEPENDENT SERVICES
 // ========================================
@@ -548,9 +1147,32 @@ func (c *Container) initCrossDependentServices() error {
 		c.BookService,
 		c.OrderService, // ✅ OrderService already exists
 		c.AsynqClient,
+		c.stockDisplayPolicy(),
+		c.PriceTierService,
+		c.checkoutPolicy(),
+		c.ServiceabilityService,
+		c.PayLinkPolicy(),
+		c.StorefrontBreaker,
+		c.Cache,
+		c.AnonymousCartPolicy(),
+		c.WishlistPriceGuaranteePolicy(),
+		c.CartExpirationPolicy(),
+		c.CheckoutSagaPolicy(),
 	)
 	log.Println("  ✓ CartService")
 
+	// Checkout breakdown dùng cùng engine thuế với order service
+	if cs, ok := c.CartService.(interface {
+		SetTaxCalculator(orderService.TaxCalculator)
+	}); ok {
+		cs.SetTaxCalculator(c.TaxService)
+		log.Println("  ✓ CartService tax calculator wired")
+	}
+
+	// WishlistService needs CartService (move-to-cart) + RecommendService (bỏ feed cache)
+	c.WishlistService = wishlistService.NewWishlistService(c.WishlistRepo, c.CartService, c.RecommendService)
+	log.Println("  ✓ WishlistService")
+
 	// PromotionService needs CartService
 	c.PromotionService = promotionService.NewPromotionService(
 		c.PromotionRepo,
@@ -564,10 +1186,13 @@ func (c *Container) initCrossDependentServices() error {
 		c.PaymentRepo,
 		c.WebHookRepo,
 		c.RefundRepo,
+		c.LedgerRepo,
 		c.TxManager,
 		c.VNPayGateway,
 		c.MomoGateway,
 		c.OrderService, // ✅ OrderService exists
+		c.InventoryRepo,
+		c.AsynqClient,
 	)
 	log.Println("  ✓ PaymentService")
 
@@ -575,6 +1200,7 @@ func (c *Container) initCrossDependentServices() error {
 	c.RefundService = paymentService.NewRefundService(
 		c.PaymentRepo,
 		c.RefundRepo,
+		c.LedgerRepo,
 		c.OrderRepo,
 		c.VNPayGateway,
 		c.MomoGateway,
@@ -582,6 +1208,13 @@ func (c *Container) initCrossDependentServices() error {
 	)
 	log.Println("  ✓ RefundService")
 
+	// TicketService bồi thường khiếu nại qua RefundService (hoàn tiền) + OrderService (gửi bù)
+	c.TicketService = ticketService.NewTicketService(c.TicketRepo, c.OrderService, c.RefundService)
+	log.Println("  ✓ TicketService")
+
+	c.AffiliateService = affiliateService.NewAffiliateService(c.AffiliateRepo)
+	log.Println("  ✓ AffiliateService")
+
 	return nil
 }
 
@@ -621,6 +1254,15 @@ func (c *Container) initNotificationServices() error {
 		log.Println("  ✓ NotificationService dependencies wired")
 	}
 
+	// Device Service (push theo trạng thái đơn, tôn trọng preferences)
+	c.DeviceService = notificationService.NewDeviceService(
+		c.DeviceRepo,
+		c.UserRepo,
+		c.PreferencesService,
+		c.PushService,
+	)
+	log.Println("  ✓ DeviceService")
+
 	// Campaign Service (depends on Notification, Template)
 	c.CampaignService = notificationService.NewCampaignService(
 		c.CampaignRepo,
@@ -637,6 +1279,30 @@ func (c *Container) initNotificationServices() error {
 		log.Println("  ✓ CampaignService dependencies wired")
 	}
 
+	// Review Service notifies reviewers when their review is published
+	if rs, ok := c.ReviewService.(interface {
+		SetNotificationService(notificationService.NotificationService)
+	}); ok {
+		rs.SetNotificationService(c.NotificationService)
+		log.Println("  ✓ ReviewService notification wired")
+	}
+
+	// Order Service notifies customers on status transitions with notify_customer hook
+	if os, ok := c.OrderService.(interface {
+		SetNotificationService(notificationService.NotificationService)
+	}); ok {
+		os.SetNotificationService(c.NotificationService)
+		log.Println("  ✓ OrderService notification wired")
+	}
+
+	// Question Service notifies askers when their question is answered
+	if qs, ok := c.QuestionService.(interface {
+		SetNotificationService(notificationService.NotificationService)
+	}); ok {
+		qs.SetNotificationService(c.NotificationService)
+		log.Println("  ✓ QuestionService notification wired")
+	}
+
 	return nil
 }
 
@@ -645,32 +1311,55 @@ func (c *Container) initNotificationServices() error {
 // ========================================
 func (c *Container) validateServices() error {
 	services := map[string]interface{}{
-		"UserService":         c.UserService,
-		"CategoryService":     c.CategoryService,
-		"AuthorService":       c.AuthorService,
-		"PublisherService":    c.PublisherService,
-		"AddressService":      c.AddressService,
-		"BookService":         c.BookService,
-		"InventoryService":    c.InventoryService,
-		"CartService":         c.CartService,
-		"PromotionService":    c.PromotionService,
-		"OrderService":        c.OrderService,
-		"PaymentService":      c.PaymentService,
-		"RefundService":       c.RefundService,
-		"ReviewService":       c.ReviewService,
-		"ImageBookService":    c.ImageBookService,
-		"BulkImportService":   c.BulkImportService,
-		"WarehouseService":    c.WarehouseService,
-		"NotificationService": c.NotificationService,
-		"PreferencesService":  c.PreferencesService,
-		"TemplateService":     c.TemplateService,
-		"DeliveryService":     c.DeliveryService,
-		"CampaignService":     c.CampaignService,
+		"UserService":           c.UserService,
+		"CategoryService":       c.CategoryService,
+		"AuthorService":         c.AuthorService,
+		"PublisherService":      c.PublisherService,
+		"AddressService":        c.AddressService,
+		"ServiceabilityService": c.ServiceabilityService,
+		"BookService":           c.BookService,
+		"InventoryService":      c.InventoryService,
+		"CartService":           c.CartService,
+		"PromotionService":      c.PromotionService,
+		"OrderService":          c.OrderService,
+		"PaymentService":        c.PaymentService,
+		"RefundService":         c.RefundService,
+		"LedgerService":         c.LedgerService,
+		"CODService":            c.CODService,
+		"ReviewService":         c.ReviewService,
+		"QuestionService":       c.QuestionService,
+		"QuoteService":          c.QuoteService,
+		"FlashSaleService":      c.FlashSaleService,
+		"RecommendService":      c.RecommendService,
+		"AnalyticsService":      c.AnalyticsService,
+		"FraudService":          c.FraudService,
+		"ShippingService":       c.ShippingService,
+		"EInvoiceService":       c.EInvoiceService,
+		"MarketplaceSync":       c.MarketplaceSync,
+		"TicketService":         c.TicketService,
+		"AffiliateService":      c.AffiliateService,
+		"WishlistService":       c.WishlistService,
+		"ImageBookService":      c.ImageBookService,
+		"BulkImportService":     c.BulkImportService,
+		"MetadataService":       c.MetadataService,
+		"PriceTierService":      c.PriceTierService,
+		"PricingService":        c.PricingService,
+		"DataQualityService":    c.DataQualityService,
+		"MergeService":          c.MergeService,
+		"WarehouseService":      c.WarehouseService,
+		"HolidayService":        c.HolidayService,
+		"TaxService":            c.TaxService,
+		"NotificationService":   c.NotificationService,
+		"PreferencesService":    c.PreferencesService,
+		"TemplateService":       c.TemplateService,
+		"DeliveryService":       c.DeliveryService,
+		"CampaignService":       c.CampaignService,
+		"DeviceService":         c.DeviceService,
 	}
 
 	var nilServices []string
 	for name, svc := range services {
-		if svc == nil {
+		if isNilDependency(svc) {
 			nilServices = append(nilServices, name)
 		}
 	}
@@ -687,27 +1376,53 @@ func (c *Container) validateServices() error {
 // STEP 6: HANDLERS
 // ========================================
 func (c *Container) initHandlers() error {
-	c.UserHandler = userHandler.NewUserHandler(c.UserService, c.CartService, c.JWTManager)
+	c.UserHandler = userHandler.NewUserHandler(c.UserService, c.CartService, c.WishlistService, c.JWTManager)
 	c.CategoryHandler = categoryHandler.NewCategoryHandler(c.CategoryService)
 	c.AuthorHandler = authorHandler.NewAuthorHandler(c.AuthorService)
 	c.PublisherHandler = publisherHandler.NewPublisherHandler(c.PublisherService)
 	c.AddressHandler = addressHandler.NewAddressHandler(c.AddressService)
-	c.BookHandler = bookHandler.NewHandler(c.BookService, c.Cache, c.ImageProcessor)
-	c.InventoryHandler = inventoryHandler.NewHandler(c.InventoryService)
+	c.ServiceabilityHandler = addressHandler.NewServiceabilityHandler(c.ServiceabilityService)
+	c.BookHandler = bookHandler.NewHandler(c.BookService, c.Cache, c.ImageProcessor, c.stockDisplayPolicy(), c.StorefrontBreaker)
+	c.InventoryHandler = inventoryHandler.NewHandler(c.InventoryService, c.InventoryEvents)
 	c.ReviewHandler = reviewHandler.NewReviewHandler(c.ReviewService)
+	c.QuestionHandler = questionHandler.NewQuestionHandler(c.QuestionService)
+	c.QuoteHandler = quoteHandler.NewQuoteHandler(c.QuoteService)
+	c.FlashSaleHandler = flashSaleHandler.NewFlashSaleHandler(c.FlashSaleService)
+	c.RecommendHandler = recommendationHandler.NewHandler(c.RecommendService)
+	c.AnalyticsHandler = analyticsHandler.NewHandler(c.AnalyticsService)
+	c.FraudHandler = fraudHandler.NewHandler(c.FraudService)
 	c.CartHandler = cartHandler.NewHandler(c.CartService, c.PromotionService)
 	c.WarehouseHandler = warehouseHandler.NewHandler(c.WarehouseService)
+	c.HolidayHandler = warehouseHandler.NewHolidayHandler(c.HolidayService)
+	c.TaxHandler = taxHandler.NewHandler(c.TaxService)
 	c.BulkImportHandler = bookHandler.NewBulkImportHandler(c.BulkImportService)
+	c.MetadataHandler = bookHandler.NewMetadataEnrichmentHandler(c.MetadataService)
+	c.PriceTierHandler = bookHandler.NewPriceTierHandler(c.PriceTierService)
+	c.PricingHandler = bookHandler.NewPricingHandler(c.PricingService)
+	c.DataQualityHandler = bookHandler.NewDataQualityHandler(c.DataQualityService)
+	c.MergeHandler = bookHandler.NewMergeHandler(c.MergeService)
 	c.AdminProHandler = promotionHandler.NewAdminHandler(c.PromotionService)
 	c.PublicProHandler = promotionHandler.NewPublicHandler(c.PromotionService, c.CartService)
 	c.OrderHandler = orderHandler.NewOrderHandler(c.OrderService)
 	c.PaymentHandler = paymentHandler.NewPaymentHandler(c.PaymentService, c.RefundService)
+	c.LedgerHandler = paymentHandler.NewLedgerHandler(c.LedgerService)
+	c.CODHandler = paymentHandler.NewCODRemittanceHandler(c.CODService)
+	if c.FakeVNPayGateway != nil {
+		c.SandboxHandler = paymentHandler.NewSandboxHandler(c.FakeVNPayGateway, c.PaymentService)
+	}
+	c.ShippingHandler = shippingHandler.NewShippingHandler(c.ShippingService, c.Config.Shipping.WebhookSecret)
+	c.EInvoiceHandler = einvoiceHandler.NewEInvoiceHandler(c.EInvoiceService)
+	c.TicketHandler = ticketHandler.NewTicketHandler(c.TicketService)
+	c.AffiliateHandler = affiliateHandler.NewAffiliateHandler(c.AffiliateService)
+	c.WishlistHandler = wishlistHandler.NewWishlistHandler(c.WishlistService)
 
 	// Notification Handlers
 	c.NotificationHandler = notificationHandler.NewNotificationHandler(c.NotificationService)
 	c.PreferencesHandler = notificationHandler.NewPreferencesHandler(c.PreferencesService)
 	c.TemplateHandler = notificationHandler.NewTemplateHandler(c.TemplateService)
 	c.CampaignHandler = notificationHandler.NewCampaignHandler(c.CampaignService) // ✅ Should work now
+	c.EmailWebhookHandler = notificationHandler.NewEmailWebhookHandler(c.EmailEventService, c.Config.Email.WebhookSecret)
+	c.DeviceHandler = notificationHandler.NewDeviceHandler(c.DeviceService)
 
 	log.Println("✅ All handlers initialized")
 	return nil
@@ -719,6 +1434,11 @@ func (c *Container) initHandlers() error {
 func (c *Container) Cleanup() {
 	log.Println("🧹 Cleaning up container resources...")
 
+	if c.stopBackground != nil {
+		c.stopBackground()
+		log.Println("  ✓ Background listeners stopped")
+	}
+
 	if c.DB != nil && c.DB.Pool != nil {
 		c.DB.Pool.Close()
 		log.Println("  ✓ Database connections closed")
@@ -732,6 +1452,14 @@ func (c *Container) Cleanup() {
 		}
 	}
 
+	if c.AsynqInspector != nil {
+		if err := c.AsynqInspector.Close(); err != nil {
+			log.Printf("  ⚠️  AsynqInspector close failed: %v", err)
+		} else {
+			log.Println("  ✓ Asynq inspector closed")
+		}
+	}
+
 	if c.Cache != nil {
 		if rc, ok := c.Cache.(*infraCache.RedisCache); ok {
 			if err := rc.Close(); err != nil {
@@ -742,5 +1470,17 @@ func (c *Container) Cleanup() {
 		}
 	}
 
+	if c.SentryClient != nil {
+		sentry.Flush(c.SentryClient)
+		log.Println("  ✓ Sentry events flushed")
+	}
+
+	if tracing.Enabled() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		tracing.Shutdown(ctx)
+		cancel()
+		log.Println("  ✓ Trace spans flushed")
+	}
+
 	log.Println("✅ Container cleanup completed")
 }
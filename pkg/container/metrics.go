@@ -0,0 +1,152 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ========================================
+// METRICS (pgxpool + asynq queue)
+// ========================================
+// Đăng ký ở container để cmd/api và cmd/worker cùng xuất qua promhttp (pkg/metrics).
+// Số liệu đọc lúc scrape (Collect), không có goroutine nền.
+
+func (c *Container) initMetrics() error {
+	c.AsynqInspector = asynq.NewInspector(c.AsynqRedisOpt())
+
+	for _, collector := range []prometheus.Collector{
+		&poolCollector{pool: c.DB.Pool},
+		&queueCollector{inspector: c.AsynqInspector},
+	} {
+		if err := registerCollector(collector); err != nil {
+			return err
+		}
+	}
+
+	log.Println("✅ Metrics collectors registered")
+	return nil
+}
+
+// registerCollector - đã đăng ký (container khởi tạo lại trong cùng process) thì bỏ qua, không panic
+func registerCollector(collector prometheus.Collector) error {
+	if err := prometheus.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			return nil
+		}
+		return fmt.Errorf("register metrics collector: %w", err)
+	}
+	return nil
+}
+
+// ========================================
+// PGXPOOL
+// ========================================
+
+var (
+	dbPoolConnectionsDesc = prometheus.NewDesc(
+		"db_pool_connections",
+		"Postgres pool connections by state (acquired, idle, constructing, total, max).",
+		[]string{"state"}, nil,
+	)
+	// EmptyAcquire tăng nhanh = pool thường xuyên hết connection, cần tăng MaxConns hoặc tìm query chậm
+	dbPoolAcquiresDesc = prometheus.NewDesc(
+		"db_pool_acquires_total",
+		"Postgres pool connection acquires by result (ok = all, empty = had to wait, canceled).",
+		[]string{"result"}, nil,
+	)
+	dbPoolAcquireDurationDesc = prometheus.NewDesc(
+		"db_pool_acquire_duration_seconds_total",
+		"Total time spent waiting to acquire Postgres pool connections.",
+		nil, nil,
+	)
+)
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbPoolConnectionsDesc
+	ch <- dbPoolAcquiresDesc
+	ch <- dbPoolAcquireDurationDesc
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := p.pool.Stat()
+
+	for state, n := range map[string]int32{
+		"acquired":     stat.AcquiredConns(),
+		"idle":         stat.IdleConns(),
+		"constructing": stat.ConstructingConns(),
+		"total":        stat.TotalConns(),
+		"max":          stat.MaxConns(),
+	} {
+		ch <- prometheus.MustNewConstMetric(dbPoolConnectionsDesc, prometheus.GaugeValue, float64(n), state)
+	}
+
+	for result, n := range map[string]int64{
+		"ok":       stat.AcquireCount(),
+		"empty":    stat.EmptyAcquireCount(),
+		"canceled": stat.CanceledAcquireCount(),
+	} {
+		ch <- prometheus.MustNewConstMetric(dbPoolAcquiresDesc, prometheus.CounterValue, float64(n), result)
+	}
+
+	ch <- prometheus.MustNewConstMetric(dbPoolAcquireDurationDesc, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
+
+// ========================================
+// ASYNQ QUEUES
+// ========================================
+
+var (
+	asynqQueueSizeDesc = prometheus.NewDesc(
+		"asynq_queue_size",
+		"Asynq tasks per queue and state (pending, active, scheduled, retry, archived).",
+		[]string{"queue", "state"}, nil,
+	)
+	asynqQueueLatencyDesc = prometheus.NewDesc(
+		"asynq_queue_latency_seconds",
+		"Age of the oldest pending task per asynq queue.",
+		[]string{"queue"}, nil,
+	)
+)
+
+type queueCollector struct {
+	inspector *asynq.Inspector
+}
+
+func (q *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- asynqQueueSizeDesc
+	ch <- asynqQueueLatencyDesc
+}
+
+// Collect - Redis lỗi lúc scrape → bỏ qua queue đó, không làm hỏng cả /metrics
+func (q *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	queues, err := q.inspector.Queues()
+	if err != nil {
+		return
+	}
+	for _, queue := range queues {
+		info, err := q.inspector.GetQueueInfo(queue)
+		if err != nil {
+			continue
+		}
+		for state, n := range map[string]int{
+			"pending":   info.Pending,
+			"active":    info.Active,
+			"scheduled": info.Scheduled,
+			"retry":     info.Retry,
+			"archived":  info.Archived,
+		} {
+			ch <- prometheus.MustNewConstMetric(asynqQueueSizeDesc, prometheus.GaugeValue, float64(n), info.Queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(asynqQueueLatencyDesc, prometheus.GaugeValue, info.Latency.Seconds(), info.Queue)
+	}
+}
@@ -0,0 +1,35 @@
+package container
+
+import (
+	"testing"
+
+	addressModel "bookstore-backend/internal/domains/address/model"
+	cartModel "bookstore-backend/internal/domains/cart/model"
+	orderModel "bookstore-backend/internal/domains/order/model"
+	orderRepo "bookstore-backend/internal/domains/order/repository"
+	paymentModel "bookstore-backend/internal/domains/payment/model"
+	"bookstore-backend/internal/domains/user"
+	"bookstore-backend/pkg/logger"
+)
+
+// logPayloadTypes type chứa PII từng được đưa nguyên struct vào logger.Info / task payload
+// Thêm type mới vào đây; field PII thiếu tag `pii` làm test fail (logger.CheckPIITags)
+var logPayloadTypes = []interface{}{
+	cartModel.Cart{},
+	cartModel.SendOrderConfirmationPayload{},
+	cartModel.SendPaymentLinkPayload{},
+	orderModel.OrderGift{},
+	orderModel.MarketplaceRecipient{},
+	orderModel.OrderAddressResponse{},
+	orderRepo.Address{},
+	addressModel.AddressResponse{},
+	addressModel.AddressWithUserResponse{},
+	paymentModel.PaidOrder{},
+	user.User{},
+}
+
+func TestLogPayloadTypesHavePIITags(t *testing.T) {
+	for _, err := range logger.CheckPIITags(logPayloadTypes...) {
+		t.Error(err)
+	}
+}
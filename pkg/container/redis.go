@@ -0,0 +1,58 @@
+package container
+
+import (
+	"bookstore-backend/internal/config"
+	"bookstore-backend/pkg/cache"
+
+	"github.com/hibiken/asynq"
+)
+
+// ========================================
+// REDIS TOPOLOGY (asynq)
+// ========================================
+// asynq tự tạo client từ RedisConnOpt → mỗi topology 1 loại opt tương ứng.
+// Cache dùng cache.NewRedisClient với cùng options (config.RedisConfig.ClientOptions).
+// PoolSize giữ mặc định của asynq (10 / CPU): server chạy Concurrency lớn hơn pool của cache
+
+// AsynqRedisOpt kết nối asynq theo topology, dùng chung cho client / inspector (api) và server / scheduler (worker)
+func (c *Container) AsynqRedisOpt() asynq.RedisConnOpt {
+	return asynqRedisOpt(c.Config.Redis)
+}
+
+func asynqRedisOpt(cfg config.RedisConfig) asynq.RedisConnOpt {
+	opts := cfg.ClientOptions()
+	switch opts.Mode {
+	case cache.RedisModeSentinel:
+		return asynq.RedisFailoverClientOpt{
+			MasterName:       opts.MasterName,
+			SentinelAddrs:    opts.Addrs,
+			SentinelPassword: opts.SentinelPassword,
+			Username:         opts.Username,
+			Password:         opts.Password,
+			DB:               opts.DB,
+			DialTimeout:      opts.DialTimeout,
+			ReadTimeout:      opts.ReadTimeout,
+			WriteTimeout:     opts.WriteTimeout,
+		}
+	case cache.RedisModeCluster:
+		return asynq.RedisClusterClientOpt{
+			Addrs:        opts.Addrs,
+			MaxRedirects: opts.MaxRedirects,
+			Username:     opts.Username,
+			Password:     opts.Password,
+			DialTimeout:  opts.DialTimeout,
+			ReadTimeout:  opts.ReadTimeout,
+			WriteTimeout: opts.WriteTimeout,
+		}
+	default:
+		return asynq.RedisClientOpt{
+			Addr:         opts.Addrs[0],
+			Username:     opts.Username,
+			Password:     opts.Password,
+			DB:           opts.DB,
+			DialTimeout:  opts.DialTimeout,
+			ReadTimeout:  opts.ReadTimeout,
+			WriteTimeout: opts.WriteTimeout,
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package container
+
+import (
+	"bookstore-backend/internal/config"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// ========================================
+// STEP 7: DEPENDENCY VALIDATION
+// ========================================
+// WHY kiểm tra ở container thay vì panic trong constructor?
+// - Constructor domain nhận dependency nil không có cách báo lỗi ngoài panic → crash ở dòng đầu tiên,
+//   sửa xong chạy lại mới thấy lỗi tiếp theo
+// - Container biết toàn bộ wiring + cấu hình → gom hết lỗi, báo 1 lần lúc khởi động
+
+// optionalDependencies được phép nil (tính năng tắt theo cấu hình)
+var optionalDependencies = map[string]string{
+	"MomoGateway":          "MoMo chưa tích hợp (phase 2)",
+	"CaptchaVerifier":      "chỉ khởi tạo khi ANTIBOT_ENABLED=true",
+	"MarketplaceConnector": "không cấu hình connector → bỏ qua đồng bộ sàn",
+	"SentryClient":         "không có SENTRY_DSN → chỉ log panic",
+	"FakeVNPayGateway":     "chỉ khởi tạo khi PAYMENT_PROVIDER_MODE=fake",
+	"SandboxHandler":       "chỉ khởi tạo khi PAYMENT_PROVIDER_MODE=fake",
+}
+
+// dependencyHints gợi ý biến môi trường khi field bắt buộc bị nil
+var dependencyHints = map[string]string{
+	"SMSService": "USE_MOCK_SMS=false nhưng chưa có SMS provider thật, đặt USE_MOCK_SMS=true",
+}
+
+// validateDependencies kiểm tra mọi field exported của Container + cấu hình provider
+// Trả về errors.Join của tất cả lỗi (nil nếu hợp lệ)
+func (c *Container) validateDependencies() error {
+	var errs []error
+
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if _, ok := optionalDependencies[field.Name]; ok {
+			continue
+		}
+		// Slice provider (carrier, e-invoice) kiểm tra theo cấu hình bên dưới
+		if !isNilDependency(v.Field(i).Interface()) {
+			continue
+		}
+		if hint, ok := dependencyHints[field.Name]; ok {
+			errs = append(errs, fmt.Errorf("dependency %s is nil: %s", field.Name, hint))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("dependency %s is nil", field.Name))
+	}
+
+	errs = append(errs, c.validateProviderConfig()...)
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	log.Println("  ✓ All dependencies validated")
+	return nil
+}
+
+// validateProviderConfig provider tắt mock nhưng thiếu thông tin tích hợp thật
+func (c *Container) validateProviderConfig() []error {
+	if c.Config == nil {
+		return nil
+	}
+
+	var errs []error
+	if c.Config.Provider.ShippingMode != config.ProviderModeFake && len(c.ShippingCarriers) == 0 {
+		errs = append(errs, errors.New("no shipping carrier registered: set GHTK_API_TOKEN / GHN_API_TOKEN or SHIPPING_PROVIDER_MODE=fake"))
+	}
+	if !c.Config.EInvoice.UseMock && len(c.EInvoiceProviders) == 0 {
+		errs = append(errs, errors.New("no e-invoice provider registered: set MISA_EINVOICE_APP_ID or USE_MOCK_EINVOICE=true"))
+	}
+	if !c.Config.Market.UseMock && c.Config.Market.ConnectorBaseURL != "" && c.Config.Market.ConnectorSecret == "" {
+		errs = append(errs, errors.New("MARKETPLACE_CONNECTOR_SECRET is required when MARKETPLACE_CONNECTOR_BASE_URL is set"))
+	}
+	return errs
+}
+
+// isNilDependency bắt cả typed nil (interface chứa con trỏ nil), `== nil` không bắt được
+func isNilDependency(dep interface{}) bool {
+	if dep == nil {
+		return true
+	}
+	rv := reflect.ValueOf(dep)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Deadline chuẩn theo loại thao tác DB
+// context.WithTimeout chỉ rút ngắn deadline: nếu request/caller đã có deadline sớm hơn thì giữ nguyên
+const (
+	ReadTimeout  = 2 * time.Second  // SELECT theo key/index, listing có phân trang
+	WriteTimeout = 5 * time.Second  // INSERT/UPDATE/DELETE đơn lẻ, transaction của 1 request
+	BatchTimeout = 30 * time.Second // bulk import, job tổng hợp, thao tác trên nhiều row
+
+	// RollbackTimeout - thời gian tối đa gửi ROLLBACK sau khi ctx gốc đã bị cancel
+	RollbackTimeout = 2 * time.Second
+)
+
+// WithReadTimeout gắn deadline ReadTimeout cho thao tác đọc
+func WithReadTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, ReadTimeout)
+}
+
+// WithWriteTimeout gắn deadline WriteTimeout cho thao tác ghi
+func WithWriteTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, WriteTimeout)
+}
+
+// WithBatchTimeout gắn deadline BatchTimeout cho thao tác batch
+func WithBatchTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, BatchTimeout)
+}
+
+// Rollback rollback transaction kể cả khi ctx đã bị cancel/hết hạn
+//
+// WHY: tx.Rollback(ctx) với ctx đã cancel không gửi được ROLLBACK → pgx đóng connection,
+// transaction chỉ kết thúc khi server phát hiện connection chết (lock giữ lâu hơn cần thiết).
+// Dùng context.WithoutCancel để ROLLBACK luôn được gửi, giới hạn bởi RollbackTimeout.
+// Rollback sau Commit là no-op (pgx.ErrTxClosed được bỏ qua) → an toàn để defer.
+func Rollback(ctx context.Context, tx pgx.Tx) error {
+	rbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), RollbackTimeout)
+	defer cancel()
+
+	if err := tx.Rollback(rbCtx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		return fmt.Errorf("failed to rollback transaction: %w", err)
+	}
+	return nil
+}
@@ -32,11 +32,11 @@ func WithTransaction(ctx context.Context, pool *pgxpool.Pool, fn TxFunc) error {
 	defer func() {
 		if p := recover(); p != nil {
 			// Có panic → rollback
-			tx.Rollback(ctx)
+			Rollback(ctx, tx)
 			panic(p) // Re-throw panic
 		} else if err != nil {
-			// Có error → rollback
-			tx.Rollback(ctx)
+			// Có error → rollback (kể cả khi ctx đã cancel)
+			Rollback(ctx, tx)
 		}
 	}()
 
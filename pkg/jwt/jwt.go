@@ -10,7 +10,7 @@ import (
 // Claims represents JWT claims structure
 type Claims struct {
 	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	Email  string `json:"email" pii:"email"`
 	Role   string `json:"role"`
 	Type   string `json:"type"` // "access" or "refresh"
 	jwt.RegisteredClaims
@@ -17,13 +17,18 @@ func Init(env string) {
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 }
 
+// Info ghi log kèm fields đã mask PII (xem sanitize.go)
 func Info(msg string, fields map[string]interface{}) {
-	log.Info().Fields(fields).Msg(msg)
+	log.Info().Fields(Sanitize(fields)).Msg(msg)
 }
 func Debug(msg string) {
 	log.Debug().Msg(msg)
 }
 
 func Error(msg string, err error) {
-	log.Error().Err(err).Msg(msg)
+	if err == nil {
+		log.Error().Msg(msg)
+		return
+	}
+	log.Error().Str("error", MaskString(err.Error())).Msg(msg)
 }
@@ -0,0 +1,284 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// =====================================================
+// PII MASKING
+// =====================================================
+// Mọi field truyền vào Info() đi qua Sanitize trước khi ghi log:
+// - Struct: field gắn tag `pii:"email|phone|address|name"` bị mask theo loại
+// - Map key trùng tên PII quen thuộc (email, phone, street...) bị mask
+// - Chuỗi bất kỳ: email nằm lẫn trong text (message lỗi, recipient) bị mask
+//
+// Type mới chứa PII dùng làm payload log phải gắn tag; CheckPIITags báo field thiếu tag
+// (pkg/container/pii_tags_test.go chạy cho danh sách payload type).
+
+// Loại PII (giá trị tag `pii`)
+const (
+	PIIEmail   = "email"
+	PIIPhone   = "phone"
+	PIIAddress = "address"
+	PIIName    = "name"
+)
+
+const maxSanitizeDepth = 8
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// piiKeys map key (snake_case) → loại PII, dùng cho fields map của Info()
+var piiKeys = map[string]string{
+	"email":           PIIEmail,
+	"user_email":      PIIEmail,
+	"customer_email":  PIIEmail,
+	"phone":           PIIPhone,
+	"phone_number":    PIIPhone,
+	"recipient_phone": PIIPhone,
+	"address":         PIIAddress,
+	"full_address":    PIIAddress,
+	"street":          PIIAddress,
+	"ward":            PIIAddress,
+	"district":        PIIAddress,
+	"full_name":       PIIName,
+	"recipient_name":  PIIName,
+	"receiver_name":   PIIName,
+}
+
+// piiFieldHints tên field (lowercase, bỏ "_") trông giống PII → CheckPIITags yêu cầu tag
+var piiFieldHints = []string{
+	"email", "phone", "street", "ward", "district", "fulladdress",
+	"fullname", "recipientname", "receivername",
+}
+
+// Sanitize trả bản copy của fields đã mask PII (fields gốc không bị sửa)
+func Sanitize(fields map[string]interface{}) map[string]interface{} {
+	if len(fields) == 0 {
+		return fields
+	}
+	out := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		out[key] = sanitizeKeyed(key, value, 0)
+	}
+	return out
+}
+
+// MaskString mask email nằm lẫn trong text tự do
+func MaskString(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, maskEmail)
+}
+
+// CheckPIITags báo field trông giống PII nhưng không có tag `pii` trong các payload type
+// samples: giá trị mẫu (struct hoặc con trỏ struct), duyệt cả struct lồng nhau
+func CheckPIITags(samples ...interface{}) []error {
+	var errs []error
+	seen := make(map[reflect.Type]bool)
+	for _, sample := range samples {
+		errs = append(errs, checkType(reflect.TypeOf(sample), seen)...)
+	}
+	return errs
+}
+
+func checkType(t reflect.Type, seen map[reflect.Type]bool) []error {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		if _, tagged := field.Tag.Lookup("pii"); !tagged && looksLikePII(field.Name) && isTextKind(field.Type) {
+			errs = append(errs, fmt.Errorf("%s.%s looks like PII but has no `pii` tag", t.String(), field.Name))
+		}
+		errs = append(errs, checkType(field.Type, seen)...)
+	}
+	return errs
+}
+
+func looksLikePII(fieldName string) bool {
+	name := strings.ToLower(fieldName)
+	if strings.HasSuffix(name, "id") {
+		return false
+	}
+	for _, hint := range piiFieldHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTextKind(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// =====================================================
+// VALUE WALK
+// =====================================================
+
+func sanitizeKeyed(key string, value interface{}, depth int) interface{} {
+	if kind, ok := piiKeys[strings.ToLower(key)]; ok {
+		return maskValue(kind, value)
+	}
+	return sanitizeValue(value, depth)
+}
+
+func sanitizeValue(value interface{}, depth int) interface{} {
+	switch v := value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return MaskString(v)
+	case error:
+		return MaskString(v.Error())
+	case json.Marshaler, encoding.TextMarshaler:
+		// uuid, time, decimal...: giữ nguyên để encoder format như cũ
+		return v
+	}
+	if depth >= maxSanitizeDepth {
+		return "[truncated]"
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return sanitizeValue(rv.Elem().Interface(), depth+1)
+	case reflect.Struct:
+		return sanitizeStruct(rv, depth)
+	case reflect.Map:
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			out[key] = sanitizeKeyed(key, iter.Value().Interface(), depth+1)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			out[i] = sanitizeValue(rv.Index(i).Interface(), depth+1)
+		}
+		return out
+	case reflect.String:
+		return MaskString(rv.String())
+	}
+	return value
+}
+
+// sanitizeStruct struct → map theo tên json, field có tag `pii` bị mask
+func sanitizeStruct(rv reflect.Value, depth int) map[string]interface{} {
+	t := rv.Type()
+	out := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+
+		value := rv.Field(i).Interface()
+		if kind, ok := field.Tag.Lookup("pii"); ok {
+			out[name] = maskValue(kind, value)
+			continue
+		}
+		out[name] = sanitizeKeyed(name, value, depth+1)
+	}
+	return out
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// =====================================================
+// MASKS
+// =====================================================
+
+func maskValue(kind string, value interface{}) interface{} {
+	rv := reflect.ValueOf(value)
+	for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	if rv.Kind() != reflect.String {
+		return "[REDACTED]"
+	}
+
+	s := rv.String()
+	if s == "" {
+		return s
+	}
+	switch kind {
+	case PIIEmail:
+		return MaskString(s)
+	case PIIPhone:
+		return maskPhone(s)
+	case PIIName:
+		return maskName(s)
+	default:
+		return "[REDACTED]"
+	}
+}
+
+// maskEmail "nguyenvana@gmail.com" → "n***@gmail.com"
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain
+}
+
+// maskPhone giữ 3 số cuối để CSKH đối chiếu: "0912345678" → "*******678"
+func maskPhone(phone string) string {
+	if len(phone) <= 3 {
+		return strings.Repeat("*", len(phone))
+	}
+	return strings.Repeat("*", len(phone)-3) + phone[len(phone)-3:]
+}
+
+// maskName giữ ký tự đầu mỗi từ: "Nguyễn Văn A" → "N*** V*** A***"
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = string(r[:1]) + "***"
+	}
+	return strings.Join(words, " ")
+}
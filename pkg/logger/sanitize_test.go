@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMaskString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"no email", "order 123 confirmed", "order 123 confirmed"},
+		{"email only", "nguyenvana@gmail.com", "n***@gmail.com"},
+		{"email in text", "send to nguyenvana@gmail.com failed", "send to n***@gmail.com failed"},
+		{"multiple emails", "a.b@x.vn, c+tag@y.com.vn", "a***@x.vn, c***@y.com.vn"},
+		{"not an email", "user@localhost", "user@localhost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskString(tt.in); got != tt.want {
+				t.Errorf("MaskString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+type sanitizeRecipient struct {
+	Name  string  `json:"name" pii:"name"`
+	Phone *string `json:"phone" pii:"phone"`
+	Email string  `json:"email" pii:"email"`
+	City  string  `json:"city"`
+	Note  string  `json:"-"`
+	Count int     `json:"count"`
+}
+
+func TestSanitize(t *testing.T) {
+	phone := "0912345678"
+
+	tests := []struct {
+		name string
+		in   map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "nil map",
+			in:   nil,
+			want: nil,
+		},
+		{
+			name: "non-PII keys unchanged",
+			in:   map[string]interface{}{"order_id": "ORD-1", "total": 150000, "paid": true},
+			want: map[string]interface{}{"order_id": "ORD-1", "total": 150000, "paid": true},
+		},
+		{
+			name: "PII keys masked by kind",
+			in: map[string]interface{}{
+				"email":          "nguyenvana@gmail.com",
+				"phone":          "0912345678",
+				"recipient_name": "Nguyễn Văn A",
+				"street":         "12 Lý Thường Kiệt",
+			},
+			want: map[string]interface{}{
+				"email":          "n***@gmail.com",
+				"phone":          "*******678",
+				"recipient_name": "N*** V*** A***",
+				"street":         "[REDACTED]",
+			},
+		},
+		{
+			name: "key match is case-insensitive",
+			in:   map[string]interface{}{"Email": "abc@shop.vn"},
+			want: map[string]interface{}{"Email": "a***@shop.vn"},
+		},
+		{
+			name: "email inside free text and errors",
+			in: map[string]interface{}{
+				"message": "bounce from abc@shop.vn",
+				"error":   errors.New("smtp: rejected abc@shop.vn"),
+			},
+			want: map[string]interface{}{
+				"message": "bounce from a***@shop.vn",
+				"error":   "smtp: rejected a***@shop.vn",
+			},
+		},
+		{
+			name: "struct fields masked by pii tag",
+			in: map[string]interface{}{
+				"recipient": sanitizeRecipient{Name: "Trần B", Phone: &phone, Email: "tranb@mail.vn", City: "Hà Nội", Note: "secret", Count: 2},
+			},
+			want: map[string]interface{}{
+				"recipient": map[string]interface{}{
+					"name":  "T*** B***",
+					"phone": "*******678",
+					"email": "t***@mail.vn",
+					"city":  "Hà Nội",
+					"count": 2,
+				},
+			},
+		},
+		{
+			name: "pointer, slice and nested map",
+			in: map[string]interface{}{
+				"recipients": []*sanitizeRecipient{{Name: "Lê C"}, nil},
+				"meta":       map[string]string{"phone_number": "0987", "source": "web"},
+			},
+			want: map[string]interface{}{
+				"recipients": []interface{}{
+					map[string]interface{}{"name": "L*** C***", "phone": nil, "email": "", "city": "", "count": 0},
+					nil,
+				},
+				"meta": map[string]interface{}{"phone_number": "*987", "source": "web"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sanitize() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeDoesNotModifyInput(t *testing.T) {
+	in := map[string]interface{}{"email": "nguyenvana@gmail.com"}
+	Sanitize(in)
+	if in["email"] != "nguyenvana@gmail.com" {
+		t.Errorf("input map modified: %v", in)
+	}
+}
+
+type untaggedPayload struct {
+	OrderID       string
+	CustomerEmail string
+	Shipping      struct {
+		ReceiverName string `pii:"name"`
+		Street       string
+	}
+}
+
+func TestCheckPIITags(t *testing.T) {
+	if errs := CheckPIITags(sanitizeRecipient{}, &sanitizeRecipient{}); len(errs) != 0 {
+		t.Errorf("tagged struct: unexpected errors %v", errs)
+	}
+
+	errs := CheckPIITags(untaggedPayload{})
+	if len(errs) != 2 {
+		t.Fatalf("untagged struct: got %d errors %v, want 2 (CustomerEmail, Street)", len(errs), errs)
+	}
+}
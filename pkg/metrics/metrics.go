@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// =====================================================
+// METRICS LISTENER (Prometheus scrape)
+// =====================================================
+// Collector khai báo bằng promauto ở package domain (default registry của client_golang),
+// pgxpool / asynq queue đăng ký ở container. Server này chỉ xuất /metrics.
+//
+// WHY listener riêng thay vì route trên router public:
+// - /metrics lộ tên route, số đơn, độ sâu queue → chỉ bind port nội bộ (không qua ingress / LB public)
+// - Không đi qua middleware của API (rate limit, auth, timeout) nên scrape không bị chặn
+
+// Serve mở server /metrics tại addr (chạy nền). addr rỗng → không mở, trả nil
+func Serve(addr string) *http.Server {
+	if addr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Printf("[Metrics] Listening on %s/metrics", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[Metrics] ⚠️ Server stopped: %v", err)
+		}
+	}()
+
+	return srv
+}
+
+// Shutdown dừng server /metrics (nil-safe)
+func Shutdown(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("[Metrics] ⚠️ Shutdown failed: %v", err)
+	}
+}
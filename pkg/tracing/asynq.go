@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers trace context của ctx dạng header (traceparent, baggage) để gắn vào task asynq
+// nil khi không trace → task như cũ
+func Headers(ctx context.Context) map[string]string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// NewTask task kèm trace context trong header (payload giữ nguyên, handler không cần biết)
+func NewTask(ctx context.Context, typename string, payload []byte, opts ...asynq.Option) *asynq.Task {
+	return asynq.NewTaskWithHeaders(typename, payload, Headers(ctx), opts...)
+}
+
+// AsynqMiddleware mỗi task là 1 span consumer, tiếp tục trace của request đã enqueue
+// (task không có header trace → root trace mới, vd task từ scheduler)
+func AsynqMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		if !Enabled() {
+			return next.ProcessTask(ctx, task)
+		}
+
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(task.Headers()))
+
+		attrs := []attribute.KeyValue{
+			attribute.String("messaging.system", "asynq"),
+			attribute.String("messaging.operation", "process"),
+		}
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			attrs = append(attrs, attribute.String("messaging.message.id", id))
+		}
+		if queue, ok := asynq.GetQueueName(ctx); ok {
+			attrs = append(attrs, attribute.String("messaging.destination.name", queue))
+		}
+		if retry, ok := asynq.GetRetryCount(ctx); ok {
+			attrs = append(attrs, attribute.Int("messaging.retry_count", retry))
+		}
+
+		ctx, span := Start(ctx, "task "+task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		err := next.ProcessTask(ctx, task)
+		RecordError(span, err)
+		return err
+	})
+}
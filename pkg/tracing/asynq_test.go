@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNewTaskCarriesTraceContextInHeaders(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	provider := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	payload := []byte(`{"order_id":"ORD-1"}`)
+	task := NewTask(ctx, "order:test", payload)
+
+	if !bytes.Equal(task.Payload(), payload) {
+		t.Errorf("payload = %s, want unchanged %s", task.Payload(), payload)
+	}
+	traceparent := task.Headers()["traceparent"]
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Errorf("traceparent header = %q, want trace id %s", traceparent, span.SpanContext().TraceID())
+	}
+
+	if headers := NewTask(context.Background(), "order:test", payload).Headers(); len(headers) != 0 {
+		t.Errorf("untraced ctx: headers = %v, want none", headers)
+	}
+}
+
+// TestNoDirectAsynqNewTask task tạo bằng asynq.NewTask không mang trace context của caller →
+// mọi chỗ tạo task phải đi qua tracing.NewTask / utils.MarshalTaskContext
+func TestNoDirectAsynqNewTask(t *testing.T) {
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+
+	for _, dir := range []string{"cmd", "internal", "pkg"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				return err
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "NewTask" {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "asynq" {
+					t.Errorf("%s: asynq.NewTask drops trace context, use tracing.NewTask(ctx, ...)", fset.Position(sel.Pos()))
+				}
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walk %s: %v", dir, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLen cắt SQL dài (query báo cáo) để span không phình
+const maxStatementLen = 1024
+
+type pgxSpanKey struct{}
+
+// PgxTracer gắn vào pgxpool.Config.ConnConfig.Tracer (pgx.QueryTracer, cùng cách otelpgx):
+// mỗi query là 1 span client con của span đang active (HTTP request, task asynq).
+// Không ghi args (có thể chứa PII).
+type PgxTracer struct{}
+
+var _ pgx.QueryTracer = PgxTracer{}
+
+func (PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !Enabled() {
+		return ctx
+	}
+
+	statement := compactSQL(data.SQL)
+	operation := statement
+	if i := strings.IndexByte(statement, ' '); i > 0 {
+		operation = statement[:i]
+	}
+	operation = strings.ToUpper(operation)
+	if len(statement) > maxStatementLen {
+		statement = statement[:maxStatementLen] + "..."
+	}
+
+	spanCtx, span, ok := StartChild(ctx, "db "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", statement),
+		),
+	)
+	if !ok {
+		return ctx
+	}
+
+	// key riêng: TraceQueryEnd không được End nhầm span cha khi query không có span
+	return context.WithValue(spanCtx, pgxSpanKey{}, span)
+}
+
+func (PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, _ := ctx.Value(pgxSpanKey{}).(trace.Span)
+	if span == nil {
+		return
+	}
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		RecordError(span, data.Err)
+	} else {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	span.End()
+}
+
+// compactSQL gộp whitespace/xuống dòng của query viết nhiều dòng
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
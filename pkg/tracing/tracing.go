@@ -0,0 +1,151 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// =====================================================
+// TRACING (OpenTelemetry)
+// =====================================================
+// Init cấu hình TracerProvider + propagator global của OTel SDK, exporter:
+// - "log": span ghi ra stdout (dev)
+// - "otlp": OTLP/HTTP tới collector (Jaeger, Tempo, otel-collector)
+//
+// Propagation (W3C traceparent + baggage):
+// - HTTP: otelgin (router)
+// - asynq: header của task (NewTask / AsynqMiddleware), payload giữ nguyên
+// - Postgres, Redis: span con của span đang active trong ctx (không tạo root span)
+//
+// Tắt (TRACING_EXPORTER rỗng): provider global là no-op của OTel, span không được ghi.
+
+// instrumentationName tên tracer của code trong repo (pgx, asynq, Redis, checkout)
+const instrumentationName = "bookstore-backend"
+
+type Config struct {
+	ServiceName string
+	// "" (tắt) | "log" | "otlp"
+	Exporter string
+	// Base URL OTLP/HTTP collector, vd http://otel-collector:4318 (gửi tới /v1/traces)
+	OTLPEndpoint string
+	// % root trace được ghi (0-100); span con theo quyết định của root
+	SamplePercent int
+}
+
+var provider atomic.Pointer[sdktrace.TracerProvider]
+
+// Init gắn TracerProvider global; Exporter rỗng → không làm gì (tracing tắt)
+func Init(cfg Config) error {
+	if cfg.Exporter == "" {
+		return nil
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+	))
+	if err != nil {
+		return fmt.Errorf("tracing resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(cfg.SamplePercent)/100))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	provider.Store(tp)
+	return nil
+}
+
+func newExporter(cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "log":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		var opts []otlptracehttp.Option
+		// Rỗng: exporter tự đọc OTEL_EXPORTER_OTLP_ENDPOINT / mặc định localhost:4318
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(strings.TrimRight(cfg.OTLPEndpoint, "/")+"/v1/traces"))
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q (want \"log\" or \"otlp\")", cfg.Exporter)
+	}
+}
+
+// Shutdown flush span còn trong batch rồi đóng exporter
+func Shutdown(ctx context.Context) {
+	tp := provider.Load()
+	if tp == nil {
+		return
+	}
+	if err := tp.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  Tracing shutdown failed: %v", err)
+	}
+}
+
+// Enabled true khi tracing đã Init với exporter
+func Enabled() bool {
+	return provider.Load() != nil
+}
+
+// Tracer tracer dùng chung cho instrumentation trong repo (no-op khi tracing tắt)
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start span con của span trong ctx (hoặc root span mới)
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// StartChild chỉ tạo span khi ctx đã có trace (query DB/cache của job nền không thành root trace rời rạc)
+// ok = false: ctx trả về nguyên vẹn, span là no-op
+func StartChild(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span, bool) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(context.Background()), false
+	}
+	ctx, span := Tracer().Start(ctx, name, opts...)
+	return ctx, span, true
+}
+
+// TraceIDFromContext trace id dạng hex cho log/response header ("" khi không trace)
+func TraceIDFromContext(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// RecordError đánh dấu span lỗi (err nil → bỏ qua)
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,103 @@
+#!/bin/bash
+
+set -e  # Exit on error
+
+# Benchmark cart items query: query cũ (view books_total_stock + COUNT(*) OVER())
+# vs query mới (full không window / lean checkout) và simple vs prepared protocol.
+#
+# Usage: ./scripts/bench-cart-items.sh [cart_id] [duration_seconds]
+#   cart_id mặc định: cart có nhiều item nhất
+
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m' # No Color
+
+DB_USER="bookstore"
+DB_NAME="bookstore_dev"
+CONTAINER="bookstore_postgres"
+DURATION="${2:-15}"
+
+PSQL="docker exec -i $CONTAINER psql -U $DB_USER -d $DB_NAME -X -q"
+
+CART_ID="$1"
+if [ -z "$CART_ID" ]; then
+    CART_ID=$($PSQL -t -A -c "SELECT cart_id FROM cart_items GROUP BY cart_id ORDER BY COUNT(*) DESC LIMIT 1")
+fi
+if [ -z "$CART_ID" ]; then
+    echo "No cart_items found, seed data first (./scripts/run_seed.sh)"
+    exit 1
+fi
+echo -e "${GREEN}Cart: $CART_ID${NC}"
+
+TMP_DIR=$(mktemp -d)
+trap 'rm -rf "$TMP_DIR"' EXIT
+
+# Query trước tối ưu (GetItemsWithBooks, fetch all)
+cat > "$TMP_DIR/old.sql" <<SQL
+SELECT ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price, ci.created_at, ci.updated_at,
+       b.title, b.slug, b.cover_url, a.name, b.price, b.is_active, b.compare_at_price,
+       COALESCE(bts.available, 0), c.name, c.id, COUNT(*) OVER()
+FROM cart_items ci
+LEFT JOIN books b ON ci.book_id = b.id
+LEFT JOIN authors a ON b.author_id = a.id
+LEFT JOIN books_total_stock bts ON b.id = bts.book_id
+LEFT JOIN categories c ON b.category_id = c.id
+WHERE ci.cart_id = '$CART_ID'
+ORDER BY ci.created_at DESC;
+SQL
+
+# Query full sau tối ưu (fetch all, LATERAL stock, không window)
+cat > "$TMP_DIR/full.sql" <<SQL
+SELECT ci.id, ci.cart_id, ci.book_id, ci.quantity, ci.price, ci.created_at, ci.updated_at,
+       b.title, b.slug, b.cover_url, a.name, b.price, b.is_active, b.compare_at_price,
+       COALESCE(st.available, 0), c.name, c.id
+FROM cart_items ci
+LEFT JOIN books b ON ci.book_id = b.id
+LEFT JOIN authors a ON b.author_id = a.id
+LEFT JOIN LATERAL (
+    SELECT SUM(wi.quantity - wi.reserved) AS available
+    FROM warehouse_inventory wi WHERE wi.book_id = ci.book_id
+) st ON TRUE
+LEFT JOIN categories c ON b.category_id = c.id
+WHERE ci.cart_id = '$CART_ID'
+ORDER BY ci.created_at DESC;
+SQL
+
+# Query lean cho validate/checkout/merge (GetCheckoutItems)
+cat > "$TMP_DIR/lean.sql" <<SQL
+SELECT ci.id, ci.book_id, ci.quantity, ci.price,
+       b.title, b.price, COALESCE(b.is_active, false), COALESCE(st.available, 0)
+FROM cart_items ci
+JOIN books b ON b.id = ci.book_id
+LEFT JOIN LATERAL (
+    SELECT SUM(wi.quantity - wi.reserved) AS available
+    FROM warehouse_inventory wi WHERE wi.book_id = ci.book_id
+) st ON TRUE
+WHERE ci.cart_id = '$CART_ID'
+ORDER BY ci.created_at DESC;
+SQL
+
+# cart_id được inline vào SQL: pgbench -M simple không quote biến :var
+docker cp "$TMP_DIR/old.sql" $CONTAINER:/tmp/bench_old.sql
+docker cp "$TMP_DIR/full.sql" $CONTAINER:/tmp/bench_full.sql
+docker cp "$TMP_DIR/lean.sql" $CONTAINER:/tmp/bench_lean.sql
+
+# ===== EXPLAIN ANALYZE =====
+for name in old full lean; do
+    echo -e "\n${YELLOW}===== EXPLAIN (ANALYZE, BUFFERS): $name =====${NC}"
+    { echo -n "EXPLAIN (ANALYZE, BUFFERS) "; cat "$TMP_DIR/$name.sql"; } | $PSQL
+done
+
+# ===== pgbench: simple (parse+plan mỗi lần) vs prepared (giống pgx CacheStatement) =====
+for name in old full lean; do
+    for mode in simple prepared; do
+        echo -e "\n${YELLOW}===== pgbench: $name / $mode (${DURATION}s) =====${NC}"
+        docker exec -i $CONTAINER pgbench -U $DB_USER -n -c 8 -j 4 -T "$DURATION" \
+            -M $mode -f /tmp/bench_$name.sql $DB_NAME \
+            | grep -E "tps|latency average"
+    done
+done
+
+docker exec -i $CONTAINER rm -f /tmp/bench_old.sql /tmp/bench_full.sql /tmp/bench_lean.sql
+
+echo -e "\n${GREEN}✅ Done. So sánh tps/latency giữa old vs full vs lean, simple vs prepared${NC}"
@@ -0,0 +1,100 @@
+#!/bin/bash
+# ========================================
+# Sinh OpenAPI spec + client SDK (TypeScript / Go)
+# ========================================
+# Usage: scripts/generate-sdk.sh [spec|ts|go|all]   (mặc định: all)
+#
+# 1. spec: swag đọc annotation (@Router, @Param...) của handler → api/openapi/swagger.json
+#          rồi tách 2 spec:
+#          - storefront.json: API cho web/app khách (bỏ /admin/*, /webhooks/*)
+#          - platform.json:   toàn bộ API, cho service nội bộ / tool admin
+# 2. ts:   openapi-generator (typescript-fetch) → sdk/typescript/src/generated/{storefront,platform}
+# 3. go:   openapi-generator (go)               → sdk/go/{storefront,platform}
+#
+# Auth helper + Idempotency-Key viết tay nằm ngoài thư mục generated (sdk/typescript/src/*.ts,
+# sdk/go/bookstore) → sinh lại không mất
+#
+# Yêu cầu: go, jq, node (npx), java (openapi-generator-cli chạy bằng JVM)
+
+set -euo pipefail
+
+ROOT="$(cd "$(dirname "$0")/.." && pwd)"
+SPEC_DIR="$ROOT/api/openapi"
+SWAG_VERSION="${SWAG_VERSION:-v1.16.4}"
+OPENAPI_GENERATOR_VERSION="${OPENAPI_GENERATOR_VERSION:-7.10.0}"
+GO_SDK_MODULE="github.com/duclm31099/bookstore-backend/sdk/go"
+
+TARGET="${1:-all}"
+
+generator() {
+	OPENAPI_GENERATOR_VERSION="$OPENAPI_GENERATOR_VERSION" \
+		npx --yes @openapitools/openapi-generator-cli@2.15.3 "$@"
+}
+
+gen_spec() {
+	echo "📄 Generating OpenAPI spec..."
+	cd "$ROOT"
+	go run "github.com/swaggo/swag/cmd/swag@$SWAG_VERSION" init \
+		--generalInfo cmd/api/main.go \
+		--dir ./ \
+		--output "$SPEC_DIR" \
+		--outputTypes json,yaml \
+		--parseInternal \
+		--parseDependency
+
+	# Annotation cũ khai báo path kèm /v1 (/v1/orders) trong khi BasePath đã là /api/v1 → bỏ prefix thừa
+	jq '.paths |= with_entries(.key |= sub("^/v1/"; "/"))' \
+		"$SPEC_DIR/swagger.json" > "$SPEC_DIR/platform.json"
+
+	# Storefront: không có route admin / webhook của cổng thanh toán, hãng vận chuyển
+	jq '.paths |= with_entries(select(.key | test("^/(admin|webhooks)(/|$)") | not))' \
+		"$SPEC_DIR/platform.json" > "$SPEC_DIR/storefront.json"
+
+	echo "✅ Spec: $SPEC_DIR/{platform,storefront}.json"
+}
+
+gen_ts() {
+	echo "📦 Generating TypeScript SDK..."
+	for api in storefront platform; do
+		rm -rf "$ROOT/sdk/typescript/src/generated/$api"
+		generator generate \
+			-i "$SPEC_DIR/$api.json" \
+			-g typescript-fetch \
+			-o "$ROOT/sdk/typescript/src/generated/$api" \
+			--additional-properties=supportsES6=true,withInterfaces=true,modelPropertyNaming=original
+	done
+	echo "✅ TypeScript SDK: sdk/typescript/src/generated"
+}
+
+gen_go() {
+	echo "📦 Generating Go SDK..."
+	for api in storefront platform; do
+		rm -rf "$ROOT/sdk/go/$api"
+		generator generate \
+			-i "$SPEC_DIR/$api.json" \
+			-g go \
+			-o "$ROOT/sdk/go/$api" \
+			--package-name "$api" \
+			--additional-properties=isGoSubmodule=true,withGoMod=false,generateInterfaces=true \
+			--global-property=apiTests=false,modelTests=false,apiDocs=false,modelDocs=false
+		# Client generated đi kèm go.mod / script git riêng, SDK dùng chung module sdk/go
+		rm -f "$ROOT/sdk/go/$api/go.mod" "$ROOT/sdk/go/$api/go.sum" "$ROOT/sdk/go/$api/git_push.sh" "$ROOT/sdk/go/$api/.travis.yml"
+	done
+	(cd "$ROOT/sdk/go" && gofmt -w . && go mod tidy && go build ./...)
+	echo "✅ Go SDK: $GO_SDK_MODULE/{storefront,platform}"
+}
+
+case "$TARGET" in
+spec) gen_spec ;;
+ts) gen_ts ;;
+go) gen_go ;;
+all)
+	gen_spec
+	gen_ts
+	gen_go
+	;;
+*)
+	echo "Usage: $0 [spec|ts|go|all]" >&2
+	exit 1
+	;;
+esac
@@ -0,0 +1,115 @@
+package bookstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNoRefreshToken session không có refresh token (chỉ có access token)
+var ErrNoRefreshToken = errors.New("bookstore: no refresh token")
+
+// TokenSource cấp access token cho mỗi request
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// Refresher TokenSource đổi được token mới khi API trả 401 (access token hết hạn)
+type Refresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// StaticToken access token cố định (service nội bộ dùng token cấp sẵn)
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Session access token + refresh token của 1 user, refresh qua POST /auth/refresh
+//
+// API trả refresh token trong cookie (không có trong body) → Session gửi / nhận lại cookie refresh_token
+type Session struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+}
+
+// NewSession baseURL gồm cả prefix API (vd https://api.example.com/api/v1)
+func NewSession(baseURL, accessToken, refreshToken string) *Session {
+	return &Session{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		httpClient:   http.DefaultClient,
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+	}
+}
+
+// Token implements TokenSource
+func (s *Session) Token(context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accessToken, nil
+}
+
+// Tokens access token + refresh token hiện tại (lưu lại để dùng cho lần chạy sau)
+func (s *Session) Tokens() (accessToken, refreshToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accessToken, s.refreshToken
+}
+
+// Refresh implements Refresher
+func (s *Session) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.refreshToken == "" {
+		return ErrNoRefreshToken
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/auth/refresh", nil)
+	if err != nil {
+		return err
+	}
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: s.refreshToken})
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("bookstore: refresh token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bookstore: refresh token: status %d", resp.StatusCode)
+	}
+
+	// Envelope chuẩn của API: {"success": true, "data": {"access_token": "..."}}
+	var body struct {
+		Data struct {
+			AccessToken string `json:"access_token"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("bookstore: decode refresh response: %w", err)
+	}
+	if body.Data.AccessToken == "" {
+		return errors.New("bookstore: refresh response has no access token")
+	}
+
+	s.accessToken = body.Data.AccessToken
+	// Refresh token xoay vòng: API set cookie mới mỗi lần refresh
+	for _, c := range resp.Cookies() {
+		if c.Name == "refresh_token" && c.Value != "" {
+			s.refreshToken = c.Value
+		}
+	}
+	return nil
+}
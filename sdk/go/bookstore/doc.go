@@ -0,0 +1,15 @@
+// Package bookstore - helper dùng chung cho client generated (storefront, platform):
+// gắn access token, tự refresh khi 401, gắn Idempotency-Key và retry an toàn.
+//
+// Client generated nhận *http.Client qua Configuration, helper chỉ là http.RoundTripper
+// nên không phụ thuộc code generated:
+//
+//	session := bookstore.NewSession("https://api.example.com/api/v1", accessToken, refreshToken)
+//	cfg := storefront.NewConfiguration()
+//	cfg.Servers = storefront.ServerConfigurations{{URL: "https://api.example.com/api/v1"}}
+//	cfg.HTTPClient = bookstore.NewHTTPClient(bookstore.Options{Tokens: session})
+//	client := storefront.NewAPIClient(cfg)
+//
+//	// Checkout: cùng key cho mọi lần thử lại (kể cả sau khi process khởi động lại)
+//	ctx = bookstore.WithIdempotencyKey(ctx, "checkout-"+cartID)
+package bookstore
@@ -0,0 +1,36 @@
+package bookstore
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// HeaderIdempotencyKey header API dùng để trả lại response cũ khi request bị gửi lại
+// (POST /cart/checkout, POST /orders...)
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey gắn key cố định cho request dùng ctx này.
+// Không gắn → Transport tự sinh key cho mỗi lần gọi (chỉ chống gửi trùng khi retry trong cùng lần gọi)
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// IdempotencyKeyFrom key đã gắn bằng WithIdempotencyKey
+func IdempotencyKeyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key, ok && key != ""
+}
+
+// NewIdempotencyKey UUID v4 ngẫu nhiên
+func NewIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("bookstore: generate idempotency key: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,190 @@
+package bookstore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 2
+	defaultRetryDelay = 300 * time.Millisecond
+)
+
+// Options cấu hình http.Client cho client generated
+type Options struct {
+	// Tokens cấp access token (nil: request không gắn Authorization)
+	// Tokens implement Refresher (vd *Session) → 401 thì refresh rồi gửi lại 1 lần
+	Tokens TokenSource
+	// Base transport (nil: http.DefaultTransport)
+	Base http.RoundTripper
+	// Timeout tổng của 1 lần gọi, gồm cả retry (0: 30s)
+	Timeout time.Duration
+	// Số lần gửi lại khi lỗi mạng / 502, 503, 504 (0: 2, < 0: không retry)
+	// Chỉ retry request an toàn: GET/HEAD/OPTIONS hoặc request ghi có key từ caller
+	// (header / WithIdempotencyKey) - server chỉ khử trùng trên route có idempotency (checkout, tạo đơn)
+	MaxRetries int
+	// Khoảng chờ lần retry đầu, nhân đôi mỗi lần (0: 300ms)
+	RetryDelay time.Duration
+}
+
+// NewHTTPClient http.Client gắn auth + Idempotency-Key + retry, truyền vào Configuration.HTTPClient
+func NewHTTPClient(opts Options) *http.Client {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{
+		Transport: NewTransport(opts),
+		Timeout:   timeout,
+	}
+}
+
+// Transport http.RoundTripper của SDK
+type Transport struct {
+	tokens     TokenSource
+	base       http.RoundTripper
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// NewTransport dùng khi đã có http.Client riêng (chỉ thay Transport)
+func NewTransport(opts Options) *Transport {
+	t := &Transport{
+		tokens:     opts.Tokens,
+		base:       opts.Base,
+		maxRetries: opts.MaxRetries,
+		retryDelay: opts.RetryDelay,
+	}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	if t.maxRetries == 0 {
+		t.maxRetries = defaultMaxRetries
+	}
+	if t.maxRetries < 0 {
+		t.maxRetries = 0
+	}
+	if t.retryDelay <= 0 {
+		t.retryDelay = defaultRetryDelay
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+//
+// Request ghi (POST/PUT/PATCH/DELETE) luôn có Idempotency-Key: key từ WithIdempotencyKey,
+// không có thì sinh mới - gửi lại sau refresh / retry dùng chung 1 key → server trả lại
+// response cũ thay vì tạo đơn / trừ tồn 2 lần
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper không được sửa request của caller
+	req = req.Clone(req.Context())
+
+	explicitKey := req.Header.Get(HeaderIdempotencyKey) != ""
+	if isWrite(req.Method) && !explicitKey {
+		key, ok := IdempotencyKeyFrom(req.Context())
+		if !ok {
+			key = NewIdempotencyKey()
+		}
+		explicitKey = ok
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		if err := t.authorize(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(req)
+
+		// Access token hết hạn → refresh rồi gửi lại 1 lần (không tính vào retry)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && !refreshed {
+			if refresher, ok := t.tokens.(Refresher); ok {
+				refreshed = true
+				if refreshErr := refresher.Refresh(req.Context()); refreshErr == nil {
+					if rewindErr := rewind(req); rewindErr == nil {
+						resp.Body.Close()
+						attempt--
+						continue
+					}
+				}
+			}
+		}
+
+		if attempt >= t.maxRetries || !t.shouldRetry(req, explicitKey, resp, err) {
+			return resp, err
+		}
+		if rewindErr := rewind(req); rewindErr != nil {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(t.retryDelay << attempt):
+		}
+	}
+}
+
+func (t *Transport) authorize(req *http.Request) error {
+	if t.tokens == nil {
+		return nil
+	}
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		return fmt.Errorf("bookstore: get access token: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// shouldRetry lỗi mạng / gateway; request ghi chỉ retry khi caller chủ động gắn key
+// (server xoá key khi handler trả 5xx → gửi lại không bị 409)
+func (t *Transport) shouldRetry(req *http.Request, explicitKey bool, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if isWrite(req.Method) && !explicitKey {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rewind đặt lại body để gửi lại request (client generated luôn set GetBody)
+func rewind(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody == nil {
+		return errors.New("bookstore: request body cannot be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
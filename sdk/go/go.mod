@@ -0,0 +1,3 @@
+module github.com/duclm31099/bookstore-backend/sdk/go
+
+go 1.22
@@ -0,0 +1,115 @@
+import { type Middleware, withHeader } from "./middleware.js";
+
+// ========================================
+// Auth: access token + refresh khi 401
+// ========================================
+// - Access token gửi qua header Authorization: Bearer <token>
+// - Refresh token nằm trong cookie httpOnly (API không trả trong body) → trình duyệt gọi
+//   POST /auth/refresh với credentials: "include"; server-side tự gắn cookie qua refreshCookie
+
+export interface TokenStore {
+  getAccessToken(): string | undefined | Promise<string | undefined>;
+  setAccessToken(token: string): void | Promise<void>;
+  // Server-side (Node): cookie refresh_token tự quản lý; trình duyệt để trống
+  getRefreshCookie?(): string | undefined | Promise<string | undefined>;
+  setRefreshCookie?(value: string): void | Promise<void>;
+}
+
+// MemoryTokenStore lưu token trong bộ nhớ (mặc định)
+export class MemoryTokenStore implements TokenStore {
+  constructor(
+    private accessToken?: string,
+    private refreshCookie?: string,
+  ) {}
+
+  getAccessToken() {
+    return this.accessToken;
+  }
+
+  setAccessToken(token: string) {
+    this.accessToken = token;
+  }
+
+  getRefreshCookie() {
+    return this.refreshCookie;
+  }
+
+  setRefreshCookie(value: string) {
+    this.refreshCookie = value;
+  }
+}
+
+export interface AuthOptions {
+  // Base URL gồm cả prefix API, vd https://api.example.com/api/v1
+  basePath: string;
+  tokens: TokenStore;
+  fetchApi?: typeof fetch;
+  // Refresh thất bại (refresh token hết hạn) → vd chuyển về trang đăng nhập
+  onSessionExpired?: () => void;
+}
+
+export function authMiddleware(opts: AuthOptions): Middleware {
+  const fetchApi = opts.fetchApi ?? fetch;
+  // Nhiều request cùng nhận 401 → chỉ refresh 1 lần
+  let refreshing: Promise<string | undefined> | undefined;
+
+  const refresh = async (): Promise<string | undefined> => {
+    const headers: Record<string, string> = {};
+    const cookie = await opts.tokens.getRefreshCookie?.();
+    if (cookie) {
+      headers["Cookie"] = `refresh_token=${cookie}`;
+    }
+
+    const response = await fetchApi(`${opts.basePath.replace(/\/$/, "")}/auth/refresh`, {
+      method: "POST",
+      credentials: "include",
+      headers,
+    });
+    if (!response.ok) {
+      return undefined;
+    }
+
+    // Refresh token xoay vòng: lấy cookie mới (chỉ đọc được ở server-side)
+    const setCookie = response.headers.get("set-cookie");
+    const rotated = setCookie?.match(/refresh_token=([^;]+)/)?.[1];
+    if (rotated) {
+      await opts.tokens.setRefreshCookie?.(rotated);
+    }
+
+    // Envelope chuẩn: { success, message, data: { access_token, expires_at, user } }
+    const body = (await response.json()) as { data?: { access_token?: string } };
+    const token = body.data?.access_token;
+    if (token) {
+      await opts.tokens.setAccessToken(token);
+    }
+    return token;
+  };
+
+  return {
+    async pre({ url, init }) {
+      const token = await opts.tokens.getAccessToken();
+      if (!token) {
+        return;
+      }
+      return { url, init: withHeader(init, "Authorization", `Bearer ${token}`) };
+    },
+
+    async post({ url, init, response }) {
+      if (response.status !== 401 || url.endsWith("/auth/refresh") || url.endsWith("/auth/login")) {
+        return;
+      }
+
+      refreshing ??= refresh().finally(() => {
+        refreshing = undefined;
+      });
+      const token = await refreshing.catch(() => undefined);
+      if (!token) {
+        opts.onSessionExpired?.();
+        return;
+      }
+
+      // Gửi lại 1 lần với token mới (Idempotency-Key giữ nguyên trong init)
+      return fetchApi(url, withHeader(init, "Authorization", `Bearer ${token}`));
+    },
+  };
+}
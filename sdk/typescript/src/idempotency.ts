@@ -0,0 +1,34 @@
+import {
+  HEADER_IDEMPOTENCY_KEY,
+  type Middleware,
+  getHeader,
+  isWrite,
+  withHeader,
+} from "./middleware.js";
+
+// ========================================
+// Idempotency-Key
+// ========================================
+// API trả lại đúng response cũ khi nhận lại request cùng key (POST /cart/checkout, POST /orders...)
+// → khách bấm "Đặt hàng" 2 lần / mạng chập chờn không tạo 2 đơn.
+//
+// Request ghi chưa có header → gắn key mới. Muốn retry an toàn (kể cả sau khi reload trang)
+// thì tự giữ key và truyền qua initOverrides:
+//
+//   const key = newIdempotencyKey();
+//   await cart.checkout({ checkoutRequest }, { headers: { "Idempotency-Key": key } });
+
+export function newIdempotencyKey(): string {
+  return crypto.randomUUID();
+}
+
+export function idempotencyMiddleware(): Middleware {
+  return {
+    async pre({ url, init }) {
+      if (!isWrite(init) || getHeader(init, HEADER_IDEMPOTENCY_KEY)) {
+        return;
+      }
+      return { url, init: withHeader(init, HEADER_IDEMPOTENCY_KEY, newIdempotencyKey()) };
+    },
+  };
+}
@@ -0,0 +1,31 @@
+// ========================================
+// @bookstore/sdk
+// ========================================
+// Client generated (scripts/generate-sdk.sh) + helper viết tay (auth, Idempotency-Key).
+//
+//   import { storefront, sdkMiddleware, MemoryTokenStore } from "@bookstore/sdk";
+//
+//   const basePath = "https://api.example.com/api/v1";
+//   const config = new storefront.Configuration({
+//     basePath,
+//     middleware: sdkMiddleware({ basePath, tokens: new MemoryTokenStore(accessToken) }),
+//   });
+//   const cart = new storefront.CartApi(config);
+
+import { type AuthOptions, authMiddleware } from "./auth.js";
+import { idempotencyMiddleware } from "./idempotency.js";
+import type { Middleware } from "./middleware.js";
+
+export * as storefront from "./generated/storefront/index.js";
+export * as platform from "./generated/platform/index.js";
+
+export { MemoryTokenStore, authMiddleware } from "./auth.js";
+export type { AuthOptions, TokenStore } from "./auth.js";
+export { idempotencyMiddleware, newIdempotencyKey } from "./idempotency.js";
+export { HEADER_IDEMPOTENCY_KEY } from "./middleware.js";
+export type { Middleware } from "./middleware.js";
+
+// sdkMiddleware Idempotency-Key trước auth: request gửi lại sau refresh giữ nguyên key
+export function sdkMiddleware(opts: AuthOptions): Middleware[] {
+  return [idempotencyMiddleware(), authMiddleware(opts)];
+}
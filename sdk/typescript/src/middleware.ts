@@ -0,0 +1,46 @@
+// ========================================
+// Middleware cho client generated (typescript-fetch)
+// ========================================
+// Khai báo lại kiểu Middleware của runtime generated (storefront / platform sinh 2 runtime riêng,
+// cùng cấu trúc) → helper dùng được cho cả 2 client mà không import code generated.
+
+export interface FetchParams {
+  url: string;
+  init: RequestInit;
+}
+
+export interface RequestContext {
+  fetch: typeof fetch;
+  url: string;
+  init: RequestInit;
+}
+
+export interface ResponseContext {
+  fetch: typeof fetch;
+  url: string;
+  init: RequestInit;
+  response: Response;
+}
+
+export interface Middleware {
+  pre?(context: RequestContext): Promise<FetchParams | void>;
+  post?(context: ResponseContext): Promise<Response | void>;
+}
+
+export const HEADER_IDEMPOTENCY_KEY = "Idempotency-Key";
+
+const WRITE_METHODS = new Set(["POST", "PUT", "PATCH", "DELETE"]);
+
+export function isWrite(init: RequestInit): boolean {
+  return WRITE_METHODS.has((init.method ?? "GET").toUpperCase());
+}
+
+export function withHeader(init: RequestInit, name: string, value: string): RequestInit {
+  const headers = new Headers(init.headers);
+  headers.set(name, value);
+  return { ...init, headers };
+}
+
+export function getHeader(init: RequestInit, name: string): string | null {
+  return new Headers(init.headers).get(name);
+}